@@ -0,0 +1,313 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package ar
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// unix ar format: a global header followed by the entries, each one with an ascii header
+// of 60 bytes and the content padded to 2 bytes. The long names are read with the GNU (// table)
+// and BSD (#1/ prefix) variants, and written with the BSD variant.
+const (
+	magic      = "!<arch>\n"
+	headerSize = 60
+	headerEnd  = "`\n"
+
+	maxShortName = 16
+	maxSize      = 9999999999
+	maxID        = 999999
+
+	prefixBSD   = "#1/"
+	nameGNU     = "//"
+	nameSymbol  = "/"
+	nameSym64   = "/SYM64/"
+	prefixSymBS = "__.SYMDEF"
+)
+
+var (
+	ErrHeader      = errors.New("ar: invalid header")
+	ErrTooLarge    = errors.New("ar: entry too large for the ar format")
+	ErrSize        = errors.New("ar: content size does not match the header")
+	ErrUnsupported = errors.New("ar: only regular files can be stored")
+)
+
+type header struct {
+	name string
+	mode uint32 // unix mode
+	uid  int
+	gid  int
+	mtim time.Time
+	size int64
+}
+
+func (h *header) Name() string {
+	return path.Base(h.name)
+}
+
+func (h *header) Size() int64 {
+	return h.size
+}
+
+func (h *header) Mode() fs.FileMode {
+	return fs.FileMode(h.mode & 0777)
+}
+
+func (h *header) ModTime() time.Time {
+	return h.mtim
+}
+
+func (h *header) IsDir() bool {
+	return false
+}
+
+func (h *header) Sys() any {
+	return nil
+}
+
+// isShortName return true if the name can be stored into the name field of the header.
+func isShortName(n string) bool {
+	return len(n) <= maxShortName && !strings.ContainsAny(n, " /") && !strings.HasPrefix(n, "#1")
+}
+
+func clampID(n int) int {
+	if n < 0 || n > maxID {
+		return 0
+	}
+
+	return n
+}
+
+// marshal return the header and the BSD long name to write before the content if any.
+func (h *header) marshal() ([]byte, error) {
+	var (
+		n = h.name
+		s = h.size
+		l []byte
+	)
+
+	if !isShortName(n) {
+		l = []byte(n)
+		n = prefixBSD + strconv.Itoa(len(l))
+		s += int64(len(l))
+	}
+
+	if s > maxSize {
+		return nil, ErrTooLarge
+	}
+
+	var t = h.mtim.Unix()
+
+	if t < 0 {
+		t = 0
+	}
+
+	b := fmt.Sprintf("%-16s%-12d%-6d%-6d%-8o%-10d%s", n, t, clampID(h.uid), clampID(h.gid), h.mode, s, headerEnd)
+
+	return append([]byte(b), l...), nil
+}
+
+func parseField(b []byte, base int) (int64, error) {
+	if s := strings.TrimSpace(string(b)); len(s) < 1 {
+		return 0, nil
+	} else {
+		return strconv.ParseInt(s, base, 64)
+	}
+}
+
+// stream read the entries of an ar archive sequentially.
+type stream struct {
+	r io.Reader
+	n int64  // remaining bytes of the current content
+	p int64  // padding after the current content
+	g bool   // global header read
+	t []byte // GNU long names table
+}
+
+func newStream(r io.Reader) *stream {
+	return &stream{
+		r: r,
+	}
+}
+
+func (s *stream) skip() error {
+	if _, e := io.CopyN(io.Discard, s.r, s.n+s.p); e != nil {
+		return e
+	}
+
+	s.n, s.p = 0, 0
+	return nil
+}
+
+// next skip the remaining content of the current entry and return the header of the next one,
+// or io.EOF at the end of the archive. The symbol tables are skipped.
+func (s *stream) next() (*header, error) {
+	if !s.g {
+		var b = make([]byte, len(magic))
+
+		if _, e := io.ReadFull(s.r, b); e != nil {
+			return nil, e
+		} else if string(b) != magic {
+			return nil, ErrHeader
+		}
+
+		s.g = true
+	}
+
+	for {
+		if e := s.skip(); e != nil {
+			return nil, e
+		}
+
+		var b = make([]byte, headerSize)
+
+		if _, e := io.ReadFull(s.r, b); errors.Is(e, io.EOF) {
+			return nil, io.EOF
+		} else if e != nil {
+			return nil, e
+		} else if string(b[58:60]) != headerEnd {
+			return nil, ErrHeader
+		}
+
+		h, e := s.parse(b)
+
+		if e != nil {
+			return nil, e
+		} else if h != nil {
+			return h, nil
+		}
+	}
+}
+
+// parse return the header of the given buffer, or nil for an internal entry (symbol or name table).
+func (s *stream) parse(b []byte) (*header, error) {
+	var (
+		e error
+		v int64
+		h = &header{}
+		n = strings.TrimRight(string(b[0:16]), " ")
+	)
+
+	if v, e = parseField(b[48:58], 10); e != nil || v < 0 {
+		return nil, ErrHeader
+	}
+
+	h.size = v
+	s.n, s.p = v, v%2
+
+	switch {
+	case n == nameSymbol || n == nameSym64 || strings.HasPrefix(n, prefixSymBS):
+		return nil, nil
+	case n == nameGNU:
+		s.t = make([]byte, h.size)
+		if _, e = io.ReadFull(s, s.t); e != nil {
+			return nil, e
+		}
+		return nil, nil
+	case strings.HasPrefix(n, prefixBSD):
+		if v, e = strconv.ParseInt(n[len(prefixBSD):], 10, 64); e != nil || v < 0 || v > h.size {
+			return nil, ErrHeader
+		}
+
+		var l = make([]byte, v)
+
+		if _, e = io.ReadFull(s, l); e != nil {
+			return nil, e
+		}
+
+		h.name = string(bytes.TrimRight(l, "\x00"))
+		h.size -= v
+	case strings.HasPrefix(n, "/"):
+		if v, e = strconv.ParseInt(n[1:], 10, 64); e != nil || v < 0 || v >= int64(len(s.t)) {
+			return nil, ErrHeader
+		}
+
+		l := s.t[v:]
+
+		if i := bytes.Index(l, []byte("/\n")); i >= 0 {
+			l = l[:i]
+		}
+
+		h.name = string(l)
+	default:
+		h.name = strings.TrimSuffix(n, "/")
+	}
+
+	if v, e = parseField(b[16:28], 10); e != nil {
+		return nil, ErrHeader
+	}
+
+	h.mtim = time.Unix(v, 0)
+
+	if v, e = parseField(b[28:34], 10); e != nil {
+		return nil, ErrHeader
+	}
+
+	h.uid = int(v)
+
+	if v, e = parseField(b[34:40], 10); e != nil {
+		return nil, ErrHeader
+	}
+
+	h.gid = int(v)
+
+	if v, e = parseField(b[40:48], 8); e != nil {
+		return nil, ErrHeader
+	}
+
+	h.mode = uint32(v)
+
+	return h, nil
+}
+
+// Read read the content of the current entry.
+func (s *stream) Read(p []byte) (int, error) {
+	if s.n <= 0 {
+		return 0, io.EOF
+	} else if int64(len(p)) > s.n {
+		p = p[:s.n]
+	}
+
+	n, e := s.r.Read(p)
+	s.n -= int64(n)
+
+	if errors.Is(e, io.EOF) && s.n > 0 {
+		return n, io.ErrUnexpectedEOF
+	} else if e == nil && s.n <= 0 {
+		return n, io.EOF
+	}
+
+	return n, e
+}
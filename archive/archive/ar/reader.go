@@ -0,0 +1,170 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package ar
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+
+	arctps "github.com/nabbar/golib/archive/archive/types"
+)
+
+type reset interface {
+	Reset() bool
+}
+
+type rdr struct {
+	r io.ReadCloser
+	z *stream
+	w arctps.FuncWarning
+}
+
+func (o *rdr) RegisterFuncWarning(fct arctps.FuncWarning) {
+	o.w = fct
+}
+
+func (o *rdr) Reset() bool {
+	if r, k := o.r.(reset); k {
+		return r.Reset()
+	}
+
+	return false
+}
+
+func (o *rdr) Close() error {
+	return o.r.Close()
+}
+
+func (o *rdr) rewind() {
+	if o.Reset() {
+		o.z = newStream(o.r)
+	}
+}
+
+// find return the header of the given path, with the stream positioned on its content.
+func (o *rdr) find(s string) (*header, bool) {
+	o.rewind()
+
+	for {
+		if h, e := o.z.next(); e != nil {
+			return nil, false
+		} else if h.name == s {
+			return h, true
+		}
+	}
+}
+
+func (o *rdr) List() ([]string, error) {
+	var l = make([]string, 0)
+
+	o.rewind()
+
+	for {
+		if h, e := o.z.next(); errors.Is(e, io.EOF) {
+			return l, nil
+		} else if e != nil {
+			return l, e
+		} else {
+			l = append(l, h.name)
+		}
+	}
+}
+
+func (o *rdr) Info(s string) (fs.FileInfo, error) {
+	if h, k := o.find(s); k {
+		return h, nil
+	}
+
+	return nil, fs.ErrNotExist
+}
+
+func (o *rdr) Get(s string) (io.ReadCloser, error) {
+	if _, k := o.find(s); k {
+		return io.NopCloser(o.z), nil
+	}
+
+	return nil, fs.ErrNotExist
+}
+
+func (o *rdr) Has(s string) bool {
+	_, k := o.find(s)
+	return k
+}
+
+func (o *rdr) Walk(fct arctps.FuncExtract) {
+	o.rewind()
+
+	for {
+		h, e := o.z.next()
+
+		if e != nil {
+			return
+		}
+
+		if !fct(h, io.NopCloser(o.z), h.name, "") {
+			return
+		}
+	}
+}
+
+// ExtractAll read the entries sequentially and buffer the small files in memory
+// to write them in background while reading the next entries.
+func (o *rdr) ExtractAll(destination string, opt arctps.ExtractOptions) error {
+	o.rewind()
+	return arctps.Extract(destination, opt, o.w, o.nextEntry(opt.BufferSize()))
+}
+
+func (o *rdr) nextEntry(max int64) arctps.FuncNextEntry {
+	return func() (arctps.ExtractEntry, bool, error) {
+		h, e := o.z.next()
+
+		if errors.Is(e, io.EOF) {
+			return arctps.ExtractEntry{}, false, nil
+		} else if e != nil {
+			return arctps.ExtractEntry{}, false, e
+		}
+
+		var ent = arctps.ExtractEntry{
+			Info: h,
+			Path: h.name,
+		}
+
+		if h.size <= max {
+			ent.Open, e = arctps.BufferEntry(o.z, h.size)
+			return ent, true, e
+		}
+
+		// streamed entry: must be written before reading the next header
+		ent.Sync = true
+		ent.Open = func() (io.ReadCloser, error) {
+			return io.NopCloser(o.z), nil
+		}
+
+		return ent, true, nil
+	}
+}
@@ -0,0 +1,220 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package ar
+
+import (
+	"archive/tar"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	arctps "github.com/nabbar/golib/archive/archive/types"
+)
+
+const modeReg = 0100000
+
+type wrt struct {
+	w io.WriteCloser
+	r *arctps.Reproducible
+	s arctps.Spool
+	g bool // global header written
+}
+
+func (o *wrt) Close() error {
+	if o.r != nil {
+		if e := o.s.Walk(o.add); e != nil {
+			return e
+		}
+	}
+
+	if e := o.magic(); e != nil {
+		return e
+	} else if e = o.w.Close(); e != nil {
+		return e
+	}
+
+	return nil
+}
+
+// Add adds a regular file to the ar archive, the directories are ignored.
+//
+// It takes in the file information, the file reader, and a not used link target.
+// It returns an error if any operation fails, or if the file is not a regular file.
+func (o *wrt) Add(i fs.FileInfo, r io.ReadCloser, forcePath, notUse string) error {
+	if o.r == nil || r == nil {
+		return o.add(i, r, forcePath, notUse)
+	}
+
+	defer func() {
+		_ = r.Close()
+	}()
+
+	// reproducible mode: the entries are written sorted by name on close
+	return o.s.Add(i, r, forcePath, notUse)
+}
+
+// AddReader adds a regular file to the ar archive from the given stream of the given size.
+func (o *wrt) AddReader(name string, size int64, mode fs.FileMode, r io.Reader) error {
+	if len(name) < 1 || size < 0 || r == nil {
+		return fs.ErrInvalid
+	}
+
+	return o.Add(arctps.NewFileInfo(name, size, mode&^fs.ModeType, time.Now()), arctps.NewSizedReader(r, size), name, "")
+}
+
+func (o *wrt) magic() error {
+	if o.g {
+		return nil
+	} else if _, e := o.w.Write([]byte(magic)); e != nil {
+		return e
+	}
+
+	o.g = true
+	return nil
+}
+
+func (o *wrt) add(i fs.FileInfo, r io.ReadCloser, forcePath, notUse string) error {
+	defer func() {
+		if r != nil {
+			_ = r.Close()
+		}
+	}()
+
+	if i == nil {
+		return fs.ErrInvalid
+	} else if i.IsDir() {
+		return nil
+	} else if !i.Mode().IsRegular() {
+		return ErrUnsupported
+	}
+
+	var h = &header{
+		name: i.Name(),
+		mode: modeReg | uint32(i.Mode().Perm()),
+		mtim: i.ModTime(),
+		size: i.Size(),
+	}
+
+	if len(forcePath) > 0 {
+		h.name = forcePath
+	}
+
+	// the owner is only given by the system dependent information of the file
+	if t, e := tar.FileInfoHeader(i, ""); e == nil {
+		h.uid, h.gid = t.Uid, t.Gid
+	}
+
+	if o.r != nil {
+		h.mtim = o.r.Time()
+		h.uid = o.r.Uid
+		h.gid = o.r.Gid
+	}
+
+	p, e := h.marshal()
+
+	if e != nil {
+		return e
+	} else if e = o.magic(); e != nil {
+		return e
+	} else if _, e = o.w.Write(p); e != nil {
+		return e
+	}
+
+	// the long name is written before the content and counted into the size
+	var s = h.size + int64(len(p)-headerSize)
+
+	if h.size > 0 {
+		if r == nil {
+			return ErrSize
+		} else if n, err := io.CopyN(o.w, r, h.size); err != nil || n != h.size {
+			return ErrSize
+		}
+	}
+
+	if s%2 != 0 {
+		if _, e = o.w.Write([]byte{'\n'}); e != nil {
+			return e
+		}
+	}
+
+	return nil
+}
+
+func (o *wrt) FromPath(source string, filter string, fct arctps.ReplaceName) error {
+	if i, e := os.Stat(source); e == nil && !i.IsDir() {
+		return o.addFiltering(source, filter, fct, i)
+	}
+
+	return filepath.Walk(source, func(path string, info fs.FileInfo, e error) error {
+		if e != nil {
+			return e
+		}
+
+		return o.addFiltering(path, filter, fct, info)
+	})
+}
+
+func (o *wrt) addFiltering(source string, filter string, fct arctps.ReplaceName, info fs.FileInfo) error {
+	var (
+		ok  bool
+		err error
+		hdf *os.File
+	)
+
+	if len(filter) < 1 {
+		filter = "*"
+	}
+
+	if fct == nil {
+		fct = func(source string) string {
+			return source
+		}
+	}
+
+	if ok, err = filepath.Match(filter, source); err != nil {
+		return err
+	} else if !ok {
+		return nil
+	}
+
+	if info == nil {
+		return fs.ErrInvalid
+	} else if !info.Mode().IsRegular() {
+		// only the regular files can be stored into an ar archive
+		return nil
+	} else if hdf, err = os.Open(source); err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = hdf.Close()
+	}()
+
+	return o.Add(info, hdf, fct(source), "")
+}
@@ -0,0 +1,327 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package cpio
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strconv"
+	"time"
+)
+
+// newc format: an ascii header of 110 bytes followed by the name terminated by a NUL byte,
+// padded to 4 bytes, then the content padded to 4 bytes. The archive ends with the trailer entry.
+const (
+	magicNewc  = "070701"
+	magicCRC   = "070702"
+	headerSize = 110
+	trailer    = "TRAILER!!!"
+	maxField   = 0xFFFFFFFF
+
+	modeType  = 0170000
+	modeSock  = 0140000
+	modeLink  = 0120000
+	modeReg   = 0100000
+	modeBlock = 0060000
+	modeDir   = 0040000
+	modeChar  = 0020000
+	modeFifo  = 0010000
+	modeSUID  = 0004000
+	modeSGID  = 0002000
+	modeStick = 0001000
+)
+
+var (
+	ErrHeader   = errors.New("cpio: invalid header")
+	ErrTooLarge = errors.New("cpio: entry too large for the newc format")
+	ErrSize     = errors.New("cpio: content size does not match the header")
+)
+
+type header struct {
+	name string
+	link string
+	mode uint32 // unix mode
+	uid  int
+	gid  int
+	nlnk int
+	mtim time.Time
+	size int64
+	ino  uint32
+}
+
+func (h *header) Name() string {
+	return path.Base(h.name)
+}
+
+func (h *header) Size() int64 {
+	return h.size
+}
+
+func (h *header) Mode() fs.FileMode {
+	var m = fs.FileMode(h.mode & 0777)
+
+	switch h.mode & modeType {
+	case modeDir:
+		m |= fs.ModeDir
+	case modeLink:
+		m |= fs.ModeSymlink
+	case modeFifo:
+		m |= fs.ModeNamedPipe
+	case modeSock:
+		m |= fs.ModeSocket
+	case modeChar:
+		m |= fs.ModeDevice | fs.ModeCharDevice
+	case modeBlock:
+		m |= fs.ModeDevice
+	}
+
+	if h.mode&modeSUID != 0 {
+		m |= fs.ModeSetuid
+	}
+
+	if h.mode&modeSGID != 0 {
+		m |= fs.ModeSetgid
+	}
+
+	if h.mode&modeStick != 0 {
+		m |= fs.ModeSticky
+	}
+
+	return m
+}
+
+func (h *header) ModTime() time.Time {
+	return h.mtim
+}
+
+func (h *header) IsDir() bool {
+	return h.mode&modeType == modeDir
+}
+
+func (h *header) Sys() any {
+	return nil
+}
+
+// unixMode return the unix mode of the given file mode.
+func unixMode(m fs.FileMode) uint32 {
+	var u = uint32(m.Perm())
+
+	switch {
+	case m&fs.ModeDir != 0:
+		u |= modeDir
+	case m&fs.ModeSymlink != 0:
+		u |= modeLink
+	case m&fs.ModeNamedPipe != 0:
+		u |= modeFifo
+	case m&fs.ModeSocket != 0:
+		u |= modeSock
+	case m&fs.ModeCharDevice != 0:
+		u |= modeChar
+	case m&fs.ModeDevice != 0:
+		u |= modeBlock
+	default:
+		u |= modeReg
+	}
+
+	if m&fs.ModeSetuid != 0 {
+		u |= modeSUID
+	}
+
+	if m&fs.ModeSetgid != 0 {
+		u |= modeSGID
+	}
+
+	if m&fs.ModeSticky != 0 {
+		u |= modeStick
+	}
+
+	return u
+}
+
+// pad4 return the number of bytes needed to align n on 4 bytes.
+func pad4(n int64) int64 {
+	return (4 - n%4) % 4
+}
+
+func clamp(n int64) uint32 {
+	if n < 0 {
+		return 0
+	} else if n > maxField {
+		return maxField
+	}
+
+	return uint32(n)
+}
+
+func (h *header) marshal() ([]byte, error) {
+	if h.size > maxField {
+		return nil, ErrTooLarge
+	}
+
+	var (
+		n = len(h.name) + 1
+		b = fmt.Sprintf("%s%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X",
+			magicNewc, h.ino, h.mode, clamp(int64(h.uid)), clamp(int64(h.gid)), clamp(int64(h.nlnk)),
+			clamp(h.mtim.Unix()), uint32(h.size), 0, 0, 0, 0, n, 0)
+	)
+
+	p := make([]byte, 0, headerSize+n+int(pad4(int64(headerSize+n))))
+	p = append(p, b...)
+	p = append(p, h.name...)
+	p = append(p, 0)
+
+	for i := pad4(int64(headerSize + n)); i > 0; i-- {
+		p = append(p, 0)
+	}
+
+	return p, nil
+}
+
+func unmarshal(b []byte) (*header, int64, error) {
+	if len(b) < headerSize {
+		return nil, 0, ErrHeader
+	} else if m := string(b[:6]); m != magicNewc && m != magicCRC {
+		return nil, 0, ErrHeader
+	}
+
+	var f [13]uint32
+
+	for i := range f {
+		if v, e := strconv.ParseUint(string(b[6+i*8:14+i*8]), 16, 32); e != nil {
+			return nil, 0, ErrHeader
+		} else {
+			f[i] = uint32(v)
+		}
+	}
+
+	return &header{
+		ino:  f[0],
+		mode: f[1],
+		uid:  int(f[2]),
+		gid:  int(f[3]),
+		nlnk: int(f[4]),
+		mtim: time.Unix(int64(f[5]), 0),
+		size: int64(f[6]),
+	}, int64(f[11]), nil
+}
+
+// stream read the entries of a cpio archive sequentially.
+type stream struct {
+	r io.Reader
+	n int64 // remaining bytes of the current content
+	p int64 // padding after the current content
+	d bool  // trailer reached
+}
+
+func newStream(r io.Reader) *stream {
+	return &stream{
+		r: r,
+	}
+}
+
+// next skip the remaining content of the current entry and return the header of the next one,
+// or io.EOF at the end of the archive.
+func (s *stream) next() (*header, error) {
+	if s.d {
+		return nil, io.EOF
+	} else if _, e := io.CopyN(io.Discard, s.r, s.n+s.p); e != nil {
+		return nil, e
+	}
+
+	s.n, s.p = 0, 0
+
+	var b = make([]byte, headerSize)
+
+	if _, e := io.ReadFull(s.r, b); errors.Is(e, io.EOF) {
+		s.d = true
+		return nil, io.EOF
+	} else if e != nil {
+		return nil, e
+	}
+
+	h, n, e := unmarshal(b)
+
+	if e != nil {
+		return nil, e
+	} else if n < 1 {
+		return nil, ErrHeader
+	}
+
+	b = make([]byte, n+pad4(headerSize+n))
+
+	if _, e = io.ReadFull(s.r, b); e != nil {
+		return nil, e
+	} else if b[n-1] != 0 {
+		return nil, ErrHeader
+	}
+
+	h.name = string(b[:n-1])
+
+	if h.name == trailer {
+		s.d = true
+		return nil, io.EOF
+	}
+
+	s.n, s.p = h.size, pad4(h.size)
+
+	// the target of a symlink is the content of the entry
+	if h.mode&modeType == modeLink {
+		var l = make([]byte, h.size)
+
+		if _, e = io.ReadFull(s, l); e != nil {
+			return nil, e
+		}
+
+		h.link = string(l)
+		h.size = 0
+	}
+
+	return h, nil
+}
+
+// Read read the content of the current entry.
+func (s *stream) Read(p []byte) (int, error) {
+	if s.n <= 0 {
+		return 0, io.EOF
+	} else if int64(len(p)) > s.n {
+		p = p[:s.n]
+	}
+
+	n, e := s.r.Read(p)
+	s.n -= int64(n)
+
+	if errors.Is(e, io.EOF) && s.n > 0 {
+		return n, io.ErrUnexpectedEOF
+	} else if e == nil && s.n <= 0 {
+		return n, io.EOF
+	}
+
+	return n, e
+}
@@ -0,0 +1,58 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package cpio
+
+import (
+	"io"
+
+	arctps "github.com/nabbar/golib/archive/archive/types"
+)
+
+// NewReader return a reader of a cpio archive in the newc format (with or without checksum),
+// as used by the Linux initramfs.
+func NewReader(r io.ReadCloser) (arctps.Reader, error) {
+	return &rdr{
+		r: r,
+		z: newStream(r),
+	}, nil
+}
+
+// NewWriter return a writer of a cpio archive in the newc format.
+func NewWriter(w io.WriteCloser) (arctps.Writer, error) {
+	return &wrt{
+		w: w,
+	}, nil
+}
+
+// NewWriterReproducible return a writer in reproducible mode: the entries are normalized with the given
+// reproducible definition, and kept into a temporary file to be written sorted by name on close.
+func NewWriterReproducible(w io.WriteCloser, r arctps.Reproducible) (arctps.Writer, error) {
+	return &wrt{
+		w: w,
+		r: &r,
+	}, nil
+}
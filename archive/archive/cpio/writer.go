@@ -0,0 +1,232 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package cpio
+
+import (
+	"archive/tar"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	arctps "github.com/nabbar/golib/archive/archive/types"
+)
+
+type wrt struct {
+	w io.WriteCloser
+	r *arctps.Reproducible
+	s arctps.Spool
+	i uint32 // last inode number
+}
+
+func (o *wrt) Close() error {
+	if o.r != nil {
+		if e := o.s.Walk(o.add); e != nil {
+			return e
+		}
+	}
+
+	if e := o.write(&header{name: trailer, nlnk: 1, mtim: time.Unix(0, 0)}, nil); e != nil {
+		return e
+	} else if e = o.w.Close(); e != nil {
+		return e
+	}
+
+	return nil
+}
+
+// Add adds a file to the cpio archive.
+//
+// It takes in the file information, the file reader, and the target path if the new file is a symlink.
+// It returns an error if any operation fails.
+func (o *wrt) Add(i fs.FileInfo, r io.ReadCloser, forcePath, target string) error {
+	if o.r == nil {
+		return o.add(i, r, forcePath, target)
+	}
+
+	defer func() {
+		if r != nil {
+			_ = r.Close()
+		}
+	}()
+
+	// reproducible mode: the entries are written sorted by name on close
+	return o.s.Add(i, r, forcePath, target)
+}
+
+// AddReader adds a regular file to the cpio archive from the given stream of the given size.
+func (o *wrt) AddReader(name string, size int64, mode fs.FileMode, r io.Reader) error {
+	if len(name) < 1 || size < 0 || r == nil {
+		return fs.ErrInvalid
+	}
+
+	return o.Add(arctps.NewFileInfo(name, size, mode&^fs.ModeType, time.Now()), arctps.NewSizedReader(r, size), name, "")
+}
+
+func (o *wrt) add(i fs.FileInfo, r io.ReadCloser, forcePath, target string) error {
+	defer func() {
+		if r != nil {
+			_ = r.Close()
+		}
+	}()
+
+	if i == nil {
+		return fs.ErrInvalid
+	}
+
+	o.i++
+
+	var h = &header{
+		name: i.Name(),
+		mode: unixMode(i.Mode()),
+		nlnk: 1,
+		mtim: i.ModTime(),
+		ino:  o.i,
+	}
+
+	if len(forcePath) > 0 {
+		h.name = forcePath
+	}
+
+	// the owner is only given by the system dependent information of the file
+	if t, e := tar.FileInfoHeader(i, target); e == nil {
+		h.uid, h.gid = t.Uid, t.Gid
+	}
+
+	if o.r != nil {
+		h.mtim = o.r.Time()
+		h.uid = o.r.Uid
+		h.gid = o.r.Gid
+	}
+
+	switch h.mode & modeType {
+	case modeReg:
+		h.size = i.Size()
+		return o.write(h, r)
+	case modeLink:
+		h.size = int64(len(target))
+		return o.write(h, strings.NewReader(target))
+	case modeDir:
+		h.nlnk = 2
+	}
+
+	return o.write(h, nil)
+}
+
+func (o *wrt) write(h *header, r io.Reader) error {
+	p, e := h.marshal()
+
+	if e != nil {
+		return e
+	} else if _, e = o.w.Write(p); e != nil {
+		return e
+	}
+
+	if h.size > 0 {
+		if r == nil {
+			return ErrSize
+		} else if n, err := io.CopyN(o.w, r, h.size); err != nil || n != h.size {
+			return ErrSize
+		}
+	}
+
+	if n := pad4(h.size); n > 0 {
+		if _, e = o.w.Write(make([]byte, n)); e != nil {
+			return e
+		}
+	}
+
+	return nil
+}
+
+func (o *wrt) FromPath(source string, filter string, fct arctps.ReplaceName) error {
+	if i, e := os.Lstat(source); e == nil && !i.IsDir() {
+		return o.addFiltering(source, filter, fct, i)
+	}
+
+	return filepath.Walk(source, func(path string, info fs.FileInfo, e error) error {
+		if e != nil {
+			return e
+		}
+
+		return o.addFiltering(path, filter, fct, info)
+	})
+}
+
+func (o *wrt) addFiltering(source string, filter string, fct arctps.ReplaceName, info fs.FileInfo) error {
+	var (
+		ok     bool
+		err    error
+		hdf    *os.File
+		target string
+	)
+
+	if len(filter) < 1 {
+		filter = "*"
+	}
+
+	if fct == nil {
+		fct = func(source string) string {
+			return source
+		}
+	}
+
+	if ok, err = filepath.Match(filter, source); err != nil {
+		return err
+	} else if !ok {
+		return nil
+	}
+
+	if info == nil {
+		return fs.ErrInvalid
+	} else if info.IsDir() {
+		// the initramfs need the directories before their content
+		return o.Add(info, nil, fct(source), "")
+	} else if info.Mode()&os.ModeSymlink != 0 {
+		if target, err = os.Readlink(source); err != nil {
+			return err
+		}
+	} else if info.Mode().IsRegular() {
+		if hdf, err = os.Open(source); err != nil {
+			return err
+		} else {
+			defer func() {
+				_ = hdf.Close()
+			}()
+		}
+	} else {
+		return fs.ErrInvalid
+	}
+
+	if hdf == nil {
+		return o.Add(info, nil, fct(source), target)
+	}
+
+	return o.Add(info, hdf, fct(source), target)
+}
@@ -47,6 +47,10 @@ func (a *Algorithm) UnmarshalText(b []byte) error {
 		*a = Tar
 	case strings.EqualFold(s, Zip.String()):
 		*a = Zip
+	case strings.EqualFold(s, Cpio.String()):
+		*a = Cpio
+	case strings.EqualFold(s, Ar.String()):
+		*a = Ar
 	default:
 		*a = None
 	}
@@ -27,6 +27,7 @@ package archive
 
 import (
 	"bufio"
+	"errors"
 	"io"
 
 	arctps "github.com/nabbar/golib/archive/archive/types"
@@ -68,7 +69,8 @@ func Detect(r io.ReadCloser) (Algorithm, arctps.Reader, io.ReadCloser, error) {
 		}
 	)
 
-	if buf, err = bfr.Peek(265); err != nil {
+	// a stream shorter than the header cannot be an archive
+	if buf, err = bfr.Peek(265); err != nil && !errors.Is(err, io.EOF) {
 		return None, nil, nil, err
 	}
 
@@ -80,6 +82,20 @@ func Detect(r io.ReadCloser) (Algorithm, arctps.Reader, io.ReadCloser, error) {
 			return Tar, t, bfr, nil
 		}
 
+	case Cpio.DetectHeader(buf): // cpio
+		if c, e := Cpio.Reader(bfr); e != nil {
+			return None, nil, nil, e
+		} else {
+			return Cpio, c, bfr, nil
+		}
+
+	case Ar.DetectHeader(buf): // ar
+		if a, e := Ar.Reader(bfr); e != nil {
+			return None, nil, nil, e
+		} else {
+			return Ar, a, bfr, nil
+		}
+
 	case Zip.DetectHeader(buf): // zip
 		bfr.b = nil // do not use buffer (using ReaderAt)
 		if z, e := Zip.Reader(bfr); e != nil {
@@ -29,6 +29,8 @@ import (
 	"errors"
 	"io"
 
+	arcarr "github.com/nabbar/golib/archive/archive/ar"
+	arccpi "github.com/nabbar/golib/archive/archive/cpio"
 	arctar "github.com/nabbar/golib/archive/archive/tar"
 	arctps "github.com/nabbar/golib/archive/archive/types"
 	arczip "github.com/nabbar/golib/archive/archive/zip"
@@ -44,6 +46,10 @@ func (a Algorithm) Reader(r io.ReadCloser) (arctps.Reader, error) {
 		return arctar.NewReader(r)
 	case Zip:
 		return arczip.NewReader(r)
+	case Cpio:
+		return arccpi.NewReader(r)
+	case Ar:
+		return arcarr.NewReader(r)
 	default:
 		return nil, ErrInvalidAlgorithm
 	}
@@ -55,6 +61,26 @@ func (a Algorithm) Writer(w io.WriteCloser) (arctps.Writer, error) {
 		return arctar.NewWriter(w)
 	case Zip:
 		return arczip.NewWriter(w)
+	case Cpio:
+		return arccpi.NewWriter(w)
+	case Ar:
+		return arcarr.NewWriter(w)
+	default:
+		return nil, ErrInvalidAlgorithm
+	}
+}
+
+// WriterReproducible return a writer in reproducible mode, see arctps.Reproducible.
+func (a Algorithm) WriterReproducible(w io.WriteCloser, r arctps.Reproducible) (arctps.Writer, error) {
+	switch a {
+	case Tar:
+		return arctar.NewWriterReproducible(w, r)
+	case Zip:
+		return arczip.NewWriterReproducible(w, r)
+	case Cpio:
+		return arccpi.NewWriterReproducible(w, r)
+	case Ar:
+		return arcarr.NewWriterReproducible(w, r)
 	default:
 		return nil, ErrInvalidAlgorithm
 	}
@@ -0,0 +1,147 @@
+//go:build linux
+// +build linux
+
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package tar
+
+import (
+	"bytes"
+	"errors"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+const supportedFeature = FeatureAll
+
+// readXattr return the extended attributes of the given file filtered by the given features.
+// A file system without extended attributes support return an empty list.
+func readXattr(f *os.File, feat Feature) (map[string]string, error) {
+	var (
+		res = make(map[string]string)
+		fd  = int(f.Fd())
+		buf []byte
+	)
+
+	if !feat.Has(FeatureXattr) && !feat.Has(FeatureACL) {
+		return res, nil
+	}
+
+	if n, e := unix.Flistxattr(fd, nil); isNotSupported(e) {
+		return res, nil
+	} else if e != nil {
+		return nil, e
+	} else if n < 1 {
+		return res, nil
+	} else {
+		buf = make([]byte, n)
+	}
+
+	if n, e := unix.Flistxattr(fd, buf); e != nil {
+		return nil, e
+	} else {
+		buf = buf[:n]
+	}
+
+	for _, b := range bytes.Split(buf, []byte{0}) {
+		var name = string(b)
+
+		if len(name) < 1 {
+			continue
+		} else if isACL(name) && !feat.Has(FeatureACL) {
+			continue
+		} else if !isACL(name) && !feat.Has(FeatureXattr) {
+			continue
+		}
+
+		n, e := unix.Fgetxattr(fd, name, nil)
+
+		if errors.Is(e, unix.ENODATA) {
+			continue
+		} else if e != nil {
+			return nil, e
+		}
+
+		var val = make([]byte, n)
+
+		if n, e = unix.Fgetxattr(fd, name, val); e != nil {
+			return nil, e
+		}
+
+		res[name] = string(val[:n])
+	}
+
+	return res, nil
+}
+
+// setXattr set the extended attribute on the given path without following symbolic links.
+func setXattr(path, name string, value []byte) error {
+	return unix.Lsetxattr(path, name, value, 0)
+}
+
+// dataRegions return the data regions of the given file, between the holes.
+// A file system not supporting the hole detection return a single region covering the whole file.
+func dataRegions(f *os.File, size int64) ([]region, error) {
+	var (
+		res = make([]region, 0)
+		fd  = int(f.Fd())
+		off int64
+	)
+
+	for off < size {
+		d, e := unix.Seek(fd, off, unix.SEEK_DATA)
+
+		if errors.Is(e, unix.ENXIO) {
+			// no more data until the end of file
+			break
+		} else if isNotSupported(e) {
+			return []region{{Offset: 0, Length: size}}, nil
+		} else if e != nil {
+			return nil, e
+		} else if d >= size {
+			break
+		}
+
+		h, e := unix.Seek(fd, d, unix.SEEK_HOLE)
+
+		if e != nil {
+			return nil, e
+		} else if h > size {
+			h = size
+		}
+
+		res = append(res, region{Offset: d, Length: h - d})
+		off = h
+	}
+
+	return res, nil
+}
+
+func isNotSupported(e error) bool {
+	return errors.Is(e, unix.ENOTSUP) || errors.Is(e, unix.EOPNOTSUPP) || errors.Is(e, unix.EINVAL)
+}
@@ -0,0 +1,194 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package tar
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+)
+
+// Feature is a bit mask of the extended information stored into and restored from the archive.
+type Feature uint8
+
+const (
+	// FeatureXattr store and restore the extended attributes, as SCHILY.xattr PAX records.
+	FeatureXattr Feature = 1 << iota
+	// FeatureACL store and restore the POSIX ACLs, as the system.posix_acl_access
+	// and system.posix_acl_default extended attributes.
+	FeatureACL
+	// FeatureSparse store the sparse files as PAX 1.0 sparse entries and restore the holes on extract.
+	FeatureSparse
+
+	// FeatureNone disable all the extended information.
+	FeatureNone Feature = 0
+	// FeatureAll enable all the extended information supported by the platform.
+	FeatureAll = FeatureXattr | FeatureACL | FeatureSparse
+)
+
+const (
+	paxXattr        = "SCHILY.xattr."
+	paxXattrLibArc  = "LIBARCHIVE.xattr."
+	paxSparse       = "GNU.sparse."
+	paxSparseMajor  = "GNU.sparse.major"
+	paxSparseMinor  = "GNU.sparse.minor"
+	paxSparseName   = "GNU.sparse.name"
+	paxSparseSize   = "GNU.sparse.realsize"
+	sparseDirectory = "GNUSparseFile.0"
+	aclAccess       = "system.posix_acl_access"
+	aclDefault      = "system.posix_acl_default"
+)
+
+// DefaultFeature return the features supported by the current platform.
+func DefaultFeature() Feature {
+	return supportedFeature
+}
+
+// Has return true if all the given features are enabled.
+func (f Feature) Has(i Feature) bool {
+	return f&i == i
+}
+
+// Supported return the given features without the ones not supported by the current platform.
+func (f Feature) Supported() Feature {
+	return f & supportedFeature
+}
+
+func (f Feature) String() string {
+	var l = make([]string, 0)
+
+	if f.Has(FeatureXattr) {
+		l = append(l, "xattr")
+	}
+
+	if f.Has(FeatureACL) {
+		l = append(l, "acl")
+	}
+
+	if f.Has(FeatureSparse) {
+		l = append(l, "sparse")
+	}
+
+	if len(l) < 1 {
+		return "none"
+	}
+
+	return strings.Join(l, ",")
+}
+
+// IsSparse return true if the given file info is a tar entry stored as a sparse file.
+func IsSparse(i fs.FileInfo) bool {
+	if i == nil {
+		return false
+	} else if h, k := i.Sys().(*tar.Header); !k || h == nil {
+		return false
+	} else {
+		return isSparse(h)
+	}
+}
+
+// Restore apply on the extracted file the extended attributes and the ACLs found into the given tar entry.
+// Only the attributes covered by the given features are restored, the others are ignored.
+// The returned error joins all the attributes that cannot be restored.
+func Restore(path string, i fs.FileInfo, f Feature) error {
+	if i == nil {
+		return nil
+	}
+
+	h, k := i.Sys().(*tar.Header)
+
+	if !k || h == nil {
+		return nil
+	}
+
+	var err = make([]error, 0)
+
+	for n, v := range xattrs(h, f.Supported()) {
+		if e := setXattr(path, n, []byte(v)); e != nil {
+			err = append(err, fmt.Errorf("xattr '%s': %w", n, e))
+		}
+	}
+
+	return errors.Join(err...)
+}
+
+// Skipped return true if the given tar entry has extended attributes not covered by the given features.
+func Skipped(i fs.FileInfo, f Feature) bool {
+	if i == nil {
+		return false
+	} else if h, k := i.Sys().(*tar.Header); !k || h == nil {
+		return false
+	} else {
+		return skipped(h, f)
+	}
+}
+
+func skipped(h *tar.Header, f Feature) bool {
+	for k := range h.PAXRecords {
+		// libarchive records are encoded and never restored
+		if strings.HasPrefix(k, paxXattrLibArc) {
+			return true
+		}
+	}
+
+	return len(xattrs(h, FeatureXattr|FeatureACL)) > len(xattrs(h, f.Supported()))
+}
+
+// xattrs return the extended attributes of the header filtered by the given features.
+func xattrs(h *tar.Header, f Feature) map[string]string {
+	var res = make(map[string]string)
+
+	add := func(n, v string) {
+		if isACL(n) {
+			if f.Has(FeatureACL) {
+				res[n] = v
+			}
+		} else if f.Has(FeatureXattr) {
+			res[n] = v
+		}
+	}
+
+	// Xattrs is deprecated but still filled by the reader for old archives
+	// nolint: staticcheck
+	for n, v := range h.Xattrs {
+		add(n, v)
+	}
+
+	for k, v := range h.PAXRecords {
+		if strings.HasPrefix(k, paxXattr) {
+			add(strings.TrimPrefix(k, paxXattr), v)
+		}
+	}
+
+	return res
+}
+
+func isACL(name string) bool {
+	return name == aclAccess || name == aclDefault
+}
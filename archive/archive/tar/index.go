@@ -0,0 +1,277 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package tar
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// IndexVersion is the version of the index format.
+const IndexVersion = 1
+
+// IndexExtension is the extension added to the archive path for the sidecar index file.
+const IndexExtension = ".idx"
+
+var (
+	ErrInvalidIndex = errors.New("invalid tar index")
+	ErrStaleIndex   = errors.New("tar index does not match the archive")
+	ErrNotSeekable  = errors.New("tar index need a seekable input")
+)
+
+// IndexEntry is the header of one entry of a tar archive and the offset of its data.
+type IndexEntry struct {
+	Name     string    `json:"name"`
+	Linkname string    `json:"linkname,omitempty"`
+	Typeflag byte      `json:"typeflag"`
+	Mode     int64     `json:"mode"`
+	Uid      int       `json:"uid,omitempty"`
+	Gid      int       `json:"gid,omitempty"`
+	Uname    string    `json:"uname,omitempty"`
+	Gname    string    `json:"gname,omitempty"`
+	Size     int64     `json:"size"`
+	ModTime  time.Time `json:"mod_time"`
+
+	// Offset is the position of the first byte of the data into the archive.
+	// A negative offset means the data cannot be read directly (sparse file).
+	Offset int64 `json:"offset"`
+}
+
+func (e IndexEntry) header() *tar.Header {
+	return &tar.Header{
+		Typeflag: e.Typeflag,
+		Name:     e.Name,
+		Linkname: e.Linkname,
+		Size:     e.Size,
+		Mode:     e.Mode,
+		Uid:      e.Uid,
+		Gid:      e.Gid,
+		Uname:    e.Uname,
+		Gname:    e.Gname,
+		ModTime:  e.ModTime,
+	}
+}
+
+// Index record the entries of a tar archive to find them without scanning the whole archive.
+type Index struct {
+	Version int `json:"version"`
+	// Size is the size of the indexed archive, used to detect a stale index.
+	Size    int64        `json:"size"`
+	Entries []IndexEntry `json:"entries"`
+
+	m map[string]int // position of the last entry of each name
+}
+
+// BuildIndex scan once the given tar archive and record the offset of each entry.
+// The input is seeked to the start of the archive before and after the scan.
+func BuildIndex(r io.ReadSeeker) (*Index, error) {
+	var (
+		e error
+		n int64
+		h *tar.Header
+		z *tar.Reader
+		i = &Index{
+			Version: IndexVersion,
+			Entries: make([]IndexEntry, 0),
+		}
+	)
+
+	if r == nil {
+		return nil, ErrNotSeekable
+	} else if i.Size, e = r.Seek(0, io.SeekEnd); e != nil {
+		return nil, e
+	} else if _, e = r.Seek(0, io.SeekStart); e != nil {
+		return nil, e
+	}
+
+	// the tar reader does not buffer and use the seeker to skip the data,
+	// so the current position after each header is the offset of its data.
+	z = tar.NewReader(r)
+
+	for {
+		if h, e = z.Next(); errors.Is(e, io.EOF) {
+			break
+		} else if e != nil {
+			return nil, e
+		} else if n, e = r.Seek(0, io.SeekCurrent); e != nil {
+			return nil, e
+		}
+
+		if isSparse(h) {
+			n = -1
+		}
+
+		i.Entries = append(i.Entries, IndexEntry{
+			Name:     h.Name,
+			Linkname: h.Linkname,
+			Typeflag: h.Typeflag,
+			Mode:     h.Mode,
+			Uid:      h.Uid,
+			Gid:      h.Gid,
+			Uname:    h.Uname,
+			Gname:    h.Gname,
+			Size:     h.Size,
+			ModTime:  h.ModTime,
+			Offset:   n,
+		})
+	}
+
+	if _, e = r.Seek(0, io.SeekStart); e != nil {
+		return nil, e
+	}
+
+	i.init()
+	return i, nil
+}
+
+// ReadIndex decode an index previously written with Index.Write.
+func ReadIndex(r io.Reader) (*Index, error) {
+	var i = &Index{}
+
+	if e := json.NewDecoder(r).Decode(i); e != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIndex, e)
+	} else if i.Version < 1 || i.Version > IndexVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidIndex, i.Version)
+	}
+
+	i.init()
+	return i, nil
+}
+
+// Write encode the index as json into the given writer.
+func (i *Index) Write(w io.Writer) error {
+	return json.NewEncoder(w).Encode(i)
+}
+
+// Lookup return the entry registered with the given name.
+func (i *Index) Lookup(name string) (IndexEntry, bool) {
+	if i.m == nil {
+		i.init()
+	}
+
+	if p, ok := i.m[name]; ok {
+		return i.Entries[p], true
+	}
+
+	return IndexEntry{}, false
+}
+
+func (i *Index) init() {
+	i.m = make(map[string]int, len(i.Entries))
+
+	// like a sequential scan, the last entry of a name override the previous ones
+	for p, e := range i.Entries {
+		i.m[e.Name] = p
+	}
+}
+
+// LoadIndex read the sidecar index of the given archive path (path + IndexExtension).
+// If the sidecar is missing, invalid or stale, the index is built from the archive and
+// saved into the sidecar file. A failure to save the sidecar is not an error: the built index is returned.
+func LoadIndex(path string) (*Index, error) {
+	var (
+		e error
+		i *Index
+		h *os.File
+		s os.FileInfo
+	)
+
+	if h, e = os.Open(path); e != nil {
+		return nil, e
+	}
+
+	defer func() {
+		_ = h.Close()
+	}()
+
+	if s, e = h.Stat(); e != nil {
+		return nil, e
+	}
+
+	if i, e = readIndexFile(path + IndexExtension); e == nil && i.Size == s.Size() {
+		return i, nil
+	}
+
+	if i, e = BuildIndex(h); e != nil {
+		return nil, e
+	}
+
+	_ = SaveIndex(path, i)
+	return i, nil
+}
+
+// SaveIndex write the given index into the sidecar file of the given archive path.
+func SaveIndex(path string, idx *Index) error {
+	var (
+		e error
+		h *os.File
+	)
+
+	if idx == nil {
+		return ErrInvalidIndex
+	} else if h, e = os.Create(path + IndexExtension); e != nil {
+		return e
+	} else if e = idx.Write(h); e != nil {
+		_ = h.Close()
+		return e
+	}
+
+	return h.Close()
+}
+
+func readIndexFile(path string) (*Index, error) {
+	h, e := os.Open(path)
+
+	if e != nil {
+		return nil, e
+	}
+
+	defer func() {
+		_ = h.Close()
+	}()
+
+	return ReadIndex(h)
+}
+
+func isSparse(h *tar.Header) bool {
+	if h.Typeflag == tar.TypeGNUSparse {
+		return true
+	}
+
+	for k := range h.PAXRecords {
+		if strings.HasPrefix(k, "GNU.sparse.") {
+			return true
+		}
+	}
+
+	return false
+}
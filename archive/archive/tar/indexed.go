@@ -0,0 +1,153 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package tar
+
+import (
+	"archive/tar"
+	"io"
+	"io/fs"
+
+	arctps "github.com/nabbar/golib/archive/archive/types"
+)
+
+type readSeekCloser interface {
+	io.ReadCloser
+	io.Seeker
+}
+
+// idx is a tar reader using an Index to find the entries without scanning the archive.
+type idx struct {
+	r readSeekCloser
+	i *Index
+	w arctps.FuncWarning
+}
+
+func (o *idx) RegisterFuncWarning(fct arctps.FuncWarning) {
+	o.w = fct
+}
+
+func (o *idx) Close() error {
+	return o.r.Close()
+}
+
+func (o *idx) List() ([]string, error) {
+	var l = make([]string, 0, len(o.i.Entries))
+
+	for _, e := range o.i.Entries {
+		l = append(l, e.Name)
+	}
+
+	return l, nil
+}
+
+func (o *idx) Info(s string) (fs.FileInfo, error) {
+	if e, ok := o.i.Lookup(s); ok {
+		return e.header().FileInfo(), nil
+	}
+
+	return nil, fs.ErrNotExist
+}
+
+func (o *idx) Get(s string) (io.ReadCloser, error) {
+	e, ok := o.i.Lookup(s)
+
+	if !ok {
+		return nil, fs.ErrNotExist
+	} else if e.Offset < 0 {
+		return o.seq().Get(s)
+	}
+
+	// a ReaderAt allow to read several entries at the same time
+	if r, k := o.r.(io.ReaderAt); k {
+		return io.NopCloser(io.NewSectionReader(r, e.Offset, e.Size)), nil
+	} else if _, err := o.r.Seek(e.Offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	return io.NopCloser(io.LimitReader(o.r, e.Size)), nil
+}
+
+func (o *idx) Has(s string) bool {
+	_, ok := o.i.Lookup(s)
+	return ok
+}
+
+func (o *idx) Walk(fct arctps.FuncExtract) {
+	o.seq().Walk(fct)
+}
+
+// ExtractAll read each entry by a worker with the index if the input allow random access,
+// otherwise like a sequential tar reader.
+func (o *idx) ExtractAll(destination string, opt arctps.ExtractOptions) error {
+	r, ok := o.r.(io.ReaderAt)
+
+	if !ok {
+		return o.seq().ExtractAll(destination, opt)
+	}
+
+	var i = 0
+
+	return arctps.Extract(destination, opt, o.w, func() (arctps.ExtractEntry, bool, error) {
+		if i >= len(o.i.Entries) {
+			return arctps.ExtractEntry{}, false, nil
+		}
+
+		e := o.i.Entries[i]
+		i++
+
+		h := e.header()
+		ent := arctps.ExtractEntry{
+			Info:   h.FileInfo(),
+			Path:   e.Name,
+			Target: e.Linkname,
+		}
+
+		if e.Offset < 0 {
+			// sparse entry cannot be read from its offset
+			ent.Sync = true
+			ent.Open = func() (io.ReadCloser, error) {
+				return o.seq().Get(e.Name)
+			}
+		} else {
+			ent.Open = func() (io.ReadCloser, error) {
+				return io.NopCloser(io.NewSectionReader(r, e.Offset, e.Size)), nil
+			}
+		}
+
+		return ent, true, nil
+	})
+}
+
+// seq return a sequential reader starting at the beginning of the archive.
+func (o *idx) seq() *rdr {
+	_, _ = o.r.Seek(0, io.SeekStart)
+
+	return &rdr{
+		r: io.NopCloser(o.r),
+		z: tar.NewReader(o.r),
+		w: o.w,
+	}
+}
@@ -33,15 +33,68 @@ import (
 )
 
 func NewReader(r io.ReadCloser) (arctps.Reader, error) {
+	return NewReaderFeature(r, FeatureNone)
+}
+
+// NewReaderFeature return a reader restoring the extended information given by the features
+// on ExtractAll. The features not supported by the current platform are skipped.
+func NewReaderFeature(r io.ReadCloser, f Feature) (arctps.Reader, error) {
 	return &rdr{
 		r: r,
 		z: tar.NewReader(r),
+		f: f.Supported(),
+	}, nil
+}
+
+// NewIndexedReader return a reader using the given index to get an entry in near constant time,
+// instead of scanning the archive for each call. The input must be seekable.
+// A nil index is built by scanning once the archive, see BuildIndex and LoadIndex to reuse an index.
+func NewIndexedReader(r io.ReadCloser, i *Index) (arctps.Reader, error) {
+	s, ok := r.(readSeekCloser)
+
+	if !ok {
+		return nil, ErrNotSeekable
+	}
+
+	if i == nil {
+		var e error
+		if i, e = BuildIndex(s); e != nil {
+			return nil, e
+		}
+	} else if n, e := s.Seek(0, io.SeekEnd); e != nil {
+		return nil, e
+	} else if n != i.Size {
+		return nil, ErrStaleIndex
+	}
+
+	return &idx{
+		r: s,
+		i: i,
 	}, nil
 }
 
 func NewWriter(w io.WriteCloser) (arctps.Writer, error) {
+	return NewWriterFeature(w, FeatureNone)
+}
+
+// NewWriterFeature return a writer storing the extended information given by the features,
+// for the files added from an *os.File (see FromPath). The features not supported
+// by the current platform are skipped.
+func NewWriterFeature(w io.WriteCloser, f Feature) (arctps.Writer, error) {
+	return &wrt{
+		w: w,
+		z: tar.NewWriter(w),
+		f: f.Supported(),
+	}, nil
+}
+
+// NewWriterReproducible return a writer in reproducible mode: the entries are normalized with the given
+// reproducible definition, and kept into a temporary file to be written sorted by name on close.
+func NewWriterReproducible(w io.WriteCloser, r arctps.Reproducible) (arctps.Writer, error) {
 	return &wrt{
 		w: w,
 		z: tar.NewWriter(w),
+		f: FeatureNone,
+		r: &r,
 	}, nil
 }
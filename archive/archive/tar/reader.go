@@ -27,6 +27,7 @@ package tar
 
 import (
 	"archive/tar"
+	"errors"
 	"io"
 	"io/fs"
 
@@ -40,6 +41,12 @@ type reset interface {
 type rdr struct {
 	r io.ReadCloser
 	z *tar.Reader
+	w arctps.FuncWarning
+	f Feature
+}
+
+func (o *rdr) RegisterFuncWarning(fct arctps.FuncWarning) {
+	o.w = fct
 }
 
 func (o *rdr) Reset() bool {
@@ -160,6 +167,8 @@ func (o *rdr) Walk(fct arctps.FuncExtract) {
 			continue
 		}
 
+		o.warn(h, FeatureNone)
+
 		if !fct(h.FileInfo(), io.NopCloser(o.z), h.Name, h.Linkname) {
 			return
 		}
@@ -168,3 +177,78 @@ func (o *rdr) Walk(fct arctps.FuncExtract) {
 		_, _ = io.Copy(io.Discard, o.z)
 	}
 }
+
+// ExtractAll read the entries sequentially and buffer the small files in memory
+// to write them in background while reading the next entries.
+func (o *rdr) ExtractAll(destination string, opt arctps.ExtractOptions) error {
+	if o.Reset() {
+		o.z = tar.NewReader(o.r)
+	}
+
+	return arctps.Extract(destination, opt, o.w, o.nextEntry(opt.BufferSize()))
+}
+
+func (o *rdr) nextEntry(max int64) arctps.FuncNextEntry {
+	return func() (arctps.ExtractEntry, bool, error) {
+		h, e := o.z.Next()
+
+		if errors.Is(e, io.EOF) {
+			return arctps.ExtractEntry{}, false, nil
+		} else if e != nil {
+			return arctps.ExtractEntry{}, false, e
+		}
+
+		o.warn(h, o.f)
+
+		var ent = arctps.ExtractEntry{
+			Info:   h.FileInfo(),
+			Path:   h.Name,
+			Target: h.Linkname,
+			Sparse: o.f.Has(FeatureSparse) && isSparse(h),
+		}
+
+		if x := xattrs(h, o.f); len(x) > 0 {
+			var i = ent.Info
+			ent.Restore = func(path string) error {
+				return Restore(path, i, o.f)
+			}
+		}
+
+		if h.Typeflag != tar.TypeReg && h.Typeflag != tar.TypeRegA {
+			return ent, true, nil
+		} else if h.Size <= max {
+			ent.Open, e = arctps.BufferEntry(o.z, h.Size)
+			return ent, true, e
+		}
+
+		// streamed entry: must be written before reading the next header
+		ent.Sync = true
+		ent.Open = func() (io.ReadCloser, error) {
+			return io.NopCloser(o.z), nil
+		}
+
+		return ent, true, nil
+	}
+}
+
+// warn report the information of the header lost by the fs.FileInfo given to the walk function,
+// or not restored with the given features.
+func (o *rdr) warn(h *tar.Header, f Feature) {
+	if o.w == nil {
+		return
+	}
+
+	switch h.Typeflag {
+	case tar.TypeReg, tar.TypeRegA, tar.TypeDir, tar.TypeSymlink, tar.TypeLink:
+	case tar.TypeChar, tar.TypeBlock:
+		o.w.Call(arctps.WarnUnsupported, h.Name, "device entry", nil)
+	case tar.TypeFifo:
+		o.w.Call(arctps.WarnUnsupported, h.Name, "fifo entry", nil)
+	default:
+		o.w.Call(arctps.WarnUnsupported, h.Name, "type flag '"+string(h.Typeflag)+"'", nil)
+	}
+
+	if skipped(h, f) {
+		o.w.Call(arctps.WarnXattr, h.Name, "", nil)
+	}
+}
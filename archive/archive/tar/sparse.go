@@ -0,0 +1,217 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package tar
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"time"
+)
+
+const (
+	blockSize   = 512
+	offChecksum = 148
+	offTypeflag = 156
+	paxUname    = "uname"
+	paxGname    = "gname"
+	paxMtime    = "mtime"
+	paxAtime    = "atime"
+	paxCtime    = "ctime"
+)
+
+// region is a data segment of a sparse file.
+type region struct {
+	Offset int64
+	Length int64
+}
+
+// sparseMap return the PAX 1.0 sparse map of the given regions, padded to the tar block size.
+func sparseMap(reg []region) []byte {
+	var buf = bytes.NewBuffer(make([]byte, 0, blockSize))
+
+	buf.WriteString(strconv.Itoa(len(reg)) + "\n")
+
+	for _, r := range reg {
+		buf.WriteString(strconv.FormatInt(r.Offset, 10) + "\n")
+		buf.WriteString(strconv.FormatInt(r.Length, 10) + "\n")
+	}
+
+	if n := buf.Len() % blockSize; n > 0 {
+		buf.Write(make([]byte, blockSize-n))
+	}
+
+	return buf.Bytes()
+}
+
+// writeSparse write the given file as a PAX 1.0 sparse entry if it has holes.
+// It return false if the file is not sparse and must be written as a regular file.
+func (o *wrt) writeSparse(h *tar.Header, f *os.File) (bool, error) {
+	if !o.f.Has(FeatureSparse) || h.Typeflag != tar.TypeReg || h.Size < 1 {
+		return false, nil
+	}
+
+	reg, e := dataRegions(f, h.Size)
+
+	if e != nil {
+		return false, e
+	}
+
+	var siz int64
+
+	for _, r := range reg {
+		siz += r.Length
+	}
+
+	if siz >= h.Size {
+		return false, nil
+	} else if n := len(reg); n < 1 || reg[n-1].Offset+reg[n-1].Length < h.Size {
+		// a trailing hole is given by an empty region at the end of file
+		reg = append(reg, region{Offset: h.Size, Length: 0})
+	}
+
+	var (
+		smp = sparseMap(reg)
+		hdr = *h
+		dir = path.Dir(h.Name)
+		nam = path.Base(h.Name)
+		rec = make(map[string]string, len(h.PAXRecords)+4)
+		raw []byte
+	)
+
+	for k, v := range h.PAXRecords {
+		rec[k] = v
+	}
+
+	rec[paxSparseMajor] = "1"
+	rec[paxSparseMinor] = "0"
+	rec[paxSparseName] = h.Name
+	rec[paxSparseSize] = strconv.FormatInt(h.Size, 10)
+
+	// the times are given with their full precision into the extended header
+	rec[paxMtime] = paxTime(h.ModTime)
+
+	if !h.AccessTime.IsZero() {
+		rec[paxAtime] = paxTime(h.AccessTime)
+	}
+
+	if !h.ChangeTime.IsZero() {
+		rec[paxCtime] = paxTime(h.ChangeTime)
+	}
+
+	hdr.Name = path.Join(dir, sparseDirectory, nam)
+	hdr.Size = int64(len(smp)) + siz
+	hdr.ModTime = h.ModTime.Truncate(time.Second)
+	hdr.AccessTime = time.Time{}
+	hdr.ChangeTime = time.Time{}
+	hdr.PAXRecords = nil
+	hdr.Format = tar.FormatUSTAR
+
+	if tar.NewWriter(io.Discard).WriteHeader(&hdr) != nil {
+		// the extended header hold the information not supported by the ustar header
+		rec[paxUname] = hdr.Uname
+		rec[paxGname] = hdr.Gname
+		hdr.Name = path.Join(sparseDirectory, strconv.FormatInt(hdr.ModTime.Unix(), 10))
+		hdr.Uname = ""
+		hdr.Gname = ""
+	}
+
+	if raw, e = paxHeader(path.Join(dir, "PaxHeaders.0", nam), rec); e != nil {
+		return true, e
+	} else if e = o.z.Flush(); e != nil {
+		return true, e
+	} else if _, e = o.w.Write(raw); e != nil {
+		return true, e
+	}
+
+	// the sparse entry is written with its own writer, the main writer being at the boundary of an entry
+	var z = tar.NewWriter(o.w)
+
+	if e = z.WriteHeader(&hdr); e != nil {
+		return true, e
+	} else if _, e = z.Write(smp); e != nil {
+		return true, e
+	}
+
+	for _, r := range reg {
+		if _, e = io.Copy(z, io.NewSectionReader(f, r.Offset, r.Length)); e != nil {
+			return true, e
+		}
+	}
+
+	return true, z.Flush()
+}
+
+func paxTime(t time.Time) string {
+	return fmt.Sprintf("%d.%09d", t.Unix(), t.Nanosecond())
+}
+
+// paxHeader return the raw PAX extended header holding the given records.
+// The standard writer drops the sparse records of a local header but not of a global one,
+// so a global header is written and turned into a local one.
+func paxHeader(name string, rec map[string]string) ([]byte, error) {
+	var (
+		buf = bytes.NewBuffer(make([]byte, 0, 2*blockSize))
+		z   = tar.NewWriter(buf)
+	)
+
+	if e := z.WriteHeader(&tar.Header{
+		Typeflag:   tar.TypeXGlobalHeader,
+		Name:       name,
+		PAXRecords: rec,
+		Format:     tar.FormatPAX,
+	}); e != nil {
+		return nil, e
+	} else if e = z.Flush(); e != nil {
+		return nil, e
+	}
+
+	var raw = buf.Bytes()
+
+	if len(raw) < blockSize {
+		return nil, io.ErrShortWrite
+	}
+
+	raw[offTypeflag] = tar.TypeXHeader
+
+	// compute the checksum with the checksum field filled with spaces
+	var sum int64
+
+	copy(raw[offChecksum:offChecksum+8], "        ")
+
+	for _, c := range raw[:blockSize] {
+		sum += int64(c)
+	}
+
+	copy(raw[offChecksum:offChecksum+8], fmt.Sprintf("%06o\x00 ", sum))
+
+	return raw, nil
+}
@@ -31,6 +31,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"time"
 
 	arctps "github.com/nabbar/golib/archive/archive/types"
 )
@@ -38,9 +39,18 @@ import (
 type wrt struct {
 	w io.WriteCloser
 	z *tar.Writer
+	f Feature
+	r *arctps.Reproducible
+	s arctps.Spool
 }
 
 func (o *wrt) Close() error {
+	if o.r != nil {
+		if e := o.s.Walk(o.add); e != nil {
+			return e
+		}
+	}
+
 	if e := o.z.Flush(); e != nil {
 		return e
 	} else if e = o.z.Close(); e != nil {
@@ -57,6 +67,30 @@ func (o *wrt) Close() error {
 // It takes in the file information, the file reader, and the target path if the new file is a link.
 // It returns an error if any operation fails.
 func (o *wrt) Add(i fs.FileInfo, r io.ReadCloser, forcePath, target string) error {
+	if o.r == nil {
+		return o.add(i, r, forcePath, target)
+	}
+
+	defer func() {
+		if r != nil {
+			_ = r.Close()
+		}
+	}()
+
+	// reproducible mode: the entries are written sorted by name on close
+	return o.s.Add(i, r, forcePath, target)
+}
+
+// AddReader adds a regular file to the tar archive from the given stream of the given size.
+func (o *wrt) AddReader(name string, size int64, mode fs.FileMode, r io.Reader) error {
+	if len(name) < 1 || size < 0 || r == nil {
+		return fs.ErrInvalid
+	}
+
+	return o.Add(arctps.NewFileInfo(name, size, mode&^fs.ModeType, time.Now()), arctps.NewSizedReader(r, size), name, "")
+}
+
+func (o *wrt) add(i fs.FileInfo, r io.ReadCloser, forcePath, target string) error {
 	var (
 		e error
 		h *tar.Header
@@ -78,6 +112,18 @@ func (o *wrt) Add(i fs.FileInfo, r io.ReadCloser, forcePath, target string) erro
 		h.Name = forcePath
 	}
 
+	if o.r != nil {
+		o.normalize(h)
+	}
+
+	if f, k := r.(*os.File); k && f != nil {
+		if ok, err := o.addExtended(h, f); err != nil {
+			return err
+		} else if ok {
+			return nil
+		}
+	}
+
 	if e = o.z.WriteHeader(h); e != nil {
 		return e
 	}
@@ -91,6 +137,37 @@ func (o *wrt) Add(i fs.FileInfo, r io.ReadCloser, forcePath, target string) erro
 	return nil
 }
 
+// normalize apply the reproducible mode on the header.
+func (o *wrt) normalize(h *tar.Header) {
+	h.ModTime = o.r.Time()
+	h.AccessTime = time.Time{}
+	h.ChangeTime = time.Time{}
+	h.Uid = o.r.Uid
+	h.Gid = o.r.Gid
+	h.Uname = ""
+	h.Gname = ""
+}
+
+// addExtended add the extended attributes of the file to the header and write it as a sparse entry if it has holes.
+// It return true if the entry has been written.
+func (o *wrt) addExtended(h *tar.Header, f *os.File) (bool, error) {
+	if x, e := readXattr(f, o.f); e != nil {
+		return false, e
+	} else if len(x) > 0 {
+		if h.PAXRecords == nil {
+			h.PAXRecords = make(map[string]string)
+		}
+
+		for n, v := range x {
+			h.PAXRecords[paxXattr+n] = v
+		}
+
+		h.Format = tar.FormatPAX
+	}
+
+	return o.writeSparse(h, f)
+}
+
 func (o *wrt) FromPath(source string, filter string, fct arctps.ReplaceName) error {
 	if i, e := os.Stat(source); e == nil && !i.IsDir() {
 		return o.addFiltering(source, filter, fct, i)
@@ -153,5 +230,9 @@ func (o *wrt) addFiltering(source string, filter string, fct arctps.ReplaceName,
 		return fs.ErrInvalid
 	}
 
+	if hdf == nil {
+		return o.Add(info, nil, fct(source), target)
+	}
+
 	return o.Add(info, hdf, fct(source), target)
 }
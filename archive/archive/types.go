@@ -33,8 +33,21 @@ const (
 	None Algorithm = iota
 	Tar
 	Zip
+	Cpio
+	Ar
 )
 
+// List return the list of the supported archive algorithms, with None first.
+func List() []Algorithm {
+	return []Algorithm{
+		None,
+		Tar,
+		Zip,
+		Cpio,
+		Ar,
+	}
+}
+
 func (a Algorithm) IsNone() bool {
 	return a == None
 }
@@ -45,6 +58,10 @@ func (a Algorithm) String() string {
 		return "tar"
 	case Zip:
 		return "zip"
+	case Cpio:
+		return "cpio"
+	case Ar:
+		return "ar"
 	default:
 		return "none"
 	}
@@ -56,24 +73,41 @@ func (a Algorithm) Extension() string {
 		return ".tar"
 	case Zip:
 		return ".zip"
+	case Cpio:
+		return ".cpio"
+	case Ar:
+		return ".ar"
 	default:
 		return ""
 	}
 }
 
 func (a Algorithm) DetectHeader(h []byte) bool {
-	if len(h) < 263 {
-		return false
-	}
-
 	switch a {
 	case Tar:
+		if len(h) < 263 {
+			return false
+		}
 		exp := append([]byte("ustar"), 0x00)
 		val := h[257:263]
 		return bytes.Equal(val, exp)
 	case Zip:
+		if len(h) < 4 {
+			return false
+		}
 		exp := []byte{0x50, 0x4b, 0x03, 0x04}
 		return bytes.Equal(h[0:4], exp)
+	case Cpio:
+		// newc format, without or with checksum
+		if len(h) < 6 {
+			return false
+		}
+		return bytes.Equal(h[0:6], []byte("070701")) || bytes.Equal(h[0:6], []byte("070702"))
+	case Ar:
+		if len(h) < 8 {
+			return false
+		}
+		return bytes.Equal(h[0:8], []byte("!<arch>\n"))
 	default:
 		return false
 	}
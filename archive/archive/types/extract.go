@@ -0,0 +1,413 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package types
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// DefaultExtractBuffer is the default max size of an entry read in memory to be written by a worker
+// while reading the next entries of a sequential archive.
+const DefaultExtractBuffer = 1024 * 1024
+
+type ErrorPolicy uint8
+
+const (
+	// ErrorAbort stop the extraction at the first error and return it.
+	ErrorAbort ErrorPolicy = iota
+	// ErrorSkip skip the entry in error and continue the extraction.
+	ErrorSkip
+)
+
+// FuncProgress is called each time an entry has been extracted, with its path into the archive
+// and the number of bytes written. It may be called concurrently.
+type FuncProgress func(path string, size int64)
+
+// FuncError is called for each entry that cannot be extracted and return the policy to apply.
+// It may be called concurrently.
+type FuncError func(path string, err error) ErrorPolicy
+
+// ExtractOptions define the behavior of Reader.ExtractAll.
+type ExtractOptions struct {
+	// Workers is the number of concurrent file writers. Default is the number of CPU.
+	Workers int
+
+	// Buffer is the max size of an entry of a sequential archive (tar) read in memory to be written
+	// in background while reading the next entries. Bigger entries are written before reading the next one.
+	// Default is DefaultExtractBuffer.
+	Buffer int64
+
+	// Policy is the error policy applied when OnError is not defined.
+	Policy ErrorPolicy
+
+	// OnError when defined, is called for each entry in error and return the policy to apply.
+	OnError FuncError
+
+	// Progress is called for each extracted entry.
+	Progress FuncProgress
+}
+
+func (o ExtractOptions) workers() int {
+	if o.Workers > 0 {
+		return o.Workers
+	}
+
+	return runtime.NumCPU()
+}
+
+// BufferSize return the max size of an entry read in memory.
+func (o ExtractOptions) BufferSize() int64 {
+	if o.Buffer > 0 {
+		return o.Buffer
+	}
+
+	return DefaultExtractBuffer
+}
+
+func (o ExtractOptions) policy(path string, err error) ErrorPolicy {
+	if o.OnError != nil {
+		return o.OnError(path, err)
+	}
+
+	return o.Policy
+}
+
+// ExtractEntry is one entry of an archive to extract.
+type ExtractEntry struct {
+	Info   fs.FileInfo
+	Path   string
+	Target string
+
+	// Open return the content of the entry. It's not called for directories and links.
+	Open func() (io.ReadCloser, error)
+
+	// Sync force the entry to be extracted before getting the next one, for a streamed content.
+	Sync bool
+
+	// Sparse write the zero blocks of the content as holes into the extracted file.
+	Sparse bool
+
+	// Restore when defined, is called with the extracted path to restore the extended information
+	// of the entry (extended attributes, ACLs). A failure is reported as a WarnXattr warning.
+	Restore func(path string) error
+}
+
+// FuncNextEntry return the next entry to extract, false at the end of the archive,
+// or an error if the archive cannot be read.
+type FuncNextEntry func() (ExtractEntry, bool, error)
+
+// BufferEntry read the content of the given reader in memory and return an Open function for it.
+func BufferEntry(r io.Reader, size int64) (func() (io.ReadCloser, error), error) {
+	var buf = bytes.NewBuffer(make([]byte, 0, size))
+
+	if _, e := io.Copy(buf, r); e != nil {
+		return nil, e
+	}
+
+	return func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(buf.Bytes())), nil
+	}, nil
+}
+
+// Extract extract all the entries given by the next function into the destination directory,
+// using a pool of workers. Directories, links and Sync entries are extracted by the caller goroutine
+// to keep the order needed by a sequential archive.
+func Extract(destination string, opt ExtractOptions, wrn FuncWarning, next FuncNextEntry) error {
+	var (
+		wgp sync.WaitGroup
+		pnd sync.WaitGroup
+		mux sync.Mutex
+		err error
+		job = make(chan ExtractEntry, opt.workers())
+		stp = make(chan struct{})
+	)
+
+	if next == nil {
+		return fs.ErrInvalid
+	} else if e := os.MkdirAll(destination, 0755); e != nil {
+		return e
+	}
+
+	abort := func(e error) {
+		mux.Lock()
+		defer mux.Unlock()
+
+		if err == nil {
+			err = e
+			close(stp)
+		}
+	}
+
+	// fail return true if the extraction must be aborted
+	fail := func(path string, e error) bool {
+		if opt.policy(path, e) != ErrorAbort {
+			return false
+		}
+
+		abort(e)
+		return true
+	}
+
+	run := func(ent ExtractEntry) bool {
+		if n, e := extractEntry(destination, ent, wrn); e != nil {
+			return !fail(ent.Path, e)
+		} else if opt.Progress != nil {
+			opt.Progress(ent.Path, n)
+		}
+
+		return true
+	}
+
+	for i := 0; i < opt.workers(); i++ {
+		wgp.Add(1)
+
+		go func() {
+			defer wgp.Done()
+
+			for ent := range job {
+				select {
+				case <-stp:
+				default:
+					run(ent)
+				}
+				pnd.Done()
+			}
+		}()
+	}
+
+loop:
+	for {
+		select {
+		case <-stp:
+			break loop
+		default:
+		}
+
+		ent, ok, e := next()
+
+		if e != nil {
+			abort(e)
+			break
+		} else if !ok {
+			break
+		} else if isHardLink(ent) {
+			// the target of a hard link may still be written by a worker
+			pnd.Wait()
+		}
+
+		if ent.Sync || ent.Open == nil || ent.Info.IsDir() || ent.Info.Mode()&os.ModeSymlink != 0 || isHardLink(ent) {
+			if !run(ent) {
+				break
+			}
+			continue
+		}
+
+		pnd.Add(1)
+
+		select {
+		case <-stp:
+			pnd.Done()
+			break loop
+		case job <- ent:
+		}
+	}
+
+	close(job)
+	wgp.Wait()
+
+	return err
+}
+
+// SafePath return the given archive path cleaned and joined to the destination,
+// without any parent reference to never write outside of the destination.
+func SafePath(destination, path string) string {
+	var sep = string(filepath.Separator)
+	return filepath.Join(destination, strings.TrimPrefix(filepath.Clean(sep+filepath.FromSlash(path)), sep))
+}
+
+func extractEntry(destination string, ent ExtractEntry, wrn FuncWarning) (int64, error) {
+	var (
+		dst = SafePath(destination, ent.Path)
+		mod = ent.Info.Mode()
+	)
+
+	if ent.Info.IsDir() {
+		if e := os.MkdirAll(dst, 0755); e != nil {
+			return 0, e
+		} else if e = os.Chmod(dst, mod.Perm()); e != nil {
+			return 0, e
+		}
+		restore(dst, ent, wrn)
+		return 0, nil
+	} else if e := os.MkdirAll(filepath.Dir(dst), 0755); e != nil {
+		return 0, e
+	}
+
+	if mod&os.ModeSymlink != 0 {
+		return 0, os.Symlink(ent.Target, dst)
+	} else if isHardLink(ent) {
+		// hard link target is a path into the archive
+		return 0, os.Link(SafePath(destination, ent.Target), dst)
+	} else if !mod.IsRegular() {
+		wrn.Call(WarnUnsupported, ent.Path, "mode '"+mod.Type().String()+"'", nil)
+		return 0, nil
+	} else if ent.Open == nil {
+		return 0, fs.ErrInvalid
+	}
+
+	r, e := ent.Open()
+
+	if e != nil {
+		return 0, e
+	}
+
+	defer func() {
+		_ = r.Close()
+	}()
+
+	h, e := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+
+	if e != nil {
+		return 0, e
+	}
+
+	var n int64
+
+	if ent.Sparse {
+		n, e = CopySparse(h, r)
+	} else {
+		n, e = io.Copy(h, r)
+	}
+
+	if c := h.Close(); e == nil {
+		e = c
+	}
+
+	if e != nil {
+		_ = os.Remove(dst)
+		return n, e
+	}
+
+	if mod&(os.ModeSetuid|os.ModeSetgid|os.ModeSticky) != 0 {
+		wrn.Call(WarnPermission, ent.Path, "setuid, setgid and sticky bits not restored", nil)
+	}
+
+	if e = os.Chmod(dst, mod.Perm()); e != nil {
+		return n, e
+	}
+
+	if t := ent.Info.ModTime(); !t.IsZero() {
+		if e = os.Chtimes(dst, t, t); e != nil {
+			wrn.Call(WarnTimestamp, ent.Path, "", e)
+		}
+	}
+
+	restore(dst, ent, wrn)
+
+	return n, nil
+}
+
+// restore call the restore function of the entry and report its error as a warning.
+func restore(dst string, ent ExtractEntry, wrn FuncWarning) {
+	if ent.Restore == nil {
+		return
+	} else if e := ent.Restore(dst); e != nil {
+		wrn.Call(WarnXattr, ent.Path, "", e)
+	}
+}
+
+// CopySparse copy the given reader into the file, skipping the blocks full of zero to leave holes
+// into the file, and return the number of bytes of the content.
+func CopySparse(f *os.File, r io.Reader) (int64, error) {
+	var (
+		buf = make([]byte, 32*1024)
+		siz int64
+		hol bool
+	)
+
+	for {
+		n, e := io.ReadFull(r, buf)
+
+		for i := 0; i < n; i += sparseBlock {
+			var b = buf[i:min(i+sparseBlock, n)]
+
+			if isZero(b) {
+				if _, k := f.Seek(int64(len(b)), io.SeekCurrent); k != nil {
+					return siz, k
+				}
+				hol = true
+			} else if _, k := f.Write(b); k != nil {
+				return siz, k
+			} else {
+				hol = false
+			}
+
+			siz += int64(len(b))
+		}
+
+		if errors.Is(e, io.EOF) || errors.Is(e, io.ErrUnexpectedEOF) {
+			break
+		} else if e != nil {
+			return siz, e
+		}
+	}
+
+	if hol {
+		// a trailing hole need to set the file size
+		return siz, f.Truncate(siz)
+	}
+
+	return siz, nil
+}
+
+const sparseBlock = 4096
+
+func isZero(b []byte) bool {
+	for _, c := range b {
+		if c != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isHardLink return true for a link entry not being a symbolic link.
+func isHardLink(ent ExtractEntry) bool {
+	if ent.Info.Mode()&os.ModeDevice != 0 {
+		return true
+	}
+
+	return len(ent.Target) > 0 && ent.Info.Mode()&os.ModeSymlink == 0
+}
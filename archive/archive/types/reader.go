@@ -74,4 +74,17 @@ type Reader interface {
 	// - string: the path of the embedded file into the archive.
 	// - string: the link target of the embedded file if it is a link or a symlink.
 	Walk(FuncExtract)
+	// ExtractAll extract all the entries of the archive into the given destination directory,
+	// using concurrent workers to write the files.
+	//
+	// Parameters:
+	// - string: the destination directory, created if needed.
+	// - ExtractOptions: the number of workers, the error policy and the progress function.
+	//
+	// Returns:
+	// - error: the first error if the extraction has been aborted.
+	ExtractAll(string, ExtractOptions) error
+	// RegisterFuncWarning define the function called for each non-fatal anomaly found during the Walk
+	// (extended attributes, unsupported entry, ...). A nil function ignores the warnings.
+	RegisterFuncWarning(FuncWarning)
 }
@@ -0,0 +1,206 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package types
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"time"
+)
+
+type fileInfo struct {
+	n string
+	s int64
+	m fs.FileMode
+	t time.Time
+}
+
+// NewFileInfo return a file information for an entry without any file on disk.
+func NewFileInfo(name string, size int64, mode fs.FileMode, modTime time.Time) fs.FileInfo {
+	return &fileInfo{
+		n: path.Base(name),
+		s: size,
+		m: mode,
+		t: modTime,
+	}
+}
+
+func (o *fileInfo) Name() string {
+	return o.n
+}
+
+func (o *fileInfo) Size() int64 {
+	return o.s
+}
+
+func (o *fileInfo) Mode() fs.FileMode {
+	return o.m
+}
+
+func (o *fileInfo) ModTime() time.Time {
+	return o.t
+}
+
+func (o *fileInfo) IsDir() bool {
+	return o.m.IsDir()
+}
+
+func (o *fileInfo) Sys() any {
+	return nil
+}
+
+type spoolEntry struct {
+	i fs.FileInfo
+	n string // pathname into the archive
+	t string // link target
+	o int64  // offset of the content into the spool file
+	s int64  // size of the content
+	c bool   // has content
+}
+
+// Spool keep the entries added to a writer in reproducible mode, with their content
+// into a temporary file, to write them sorted by name when the writer is closed.
+type Spool struct {
+	f *os.File
+	o int64
+	l []spoolEntry
+}
+
+// Add store the entry and its content if any. The pathname of the entry is the given name,
+// or the name of the file information if empty.
+func (s *Spool) Add(i fs.FileInfo, r io.Reader, name, target string) error {
+	var ent = spoolEntry{
+		i: i,
+		n: name,
+		t: target,
+		o: s.o,
+	}
+
+	if len(ent.n) < 1 {
+		ent.n = i.Name()
+	}
+
+	if r != nil {
+		if s.f == nil {
+			var e error
+			if s.f, e = os.CreateTemp("", "golib-archive-spool-"); e != nil {
+				return e
+			}
+		}
+
+		n, e := io.Copy(s.f, r)
+		s.o += n
+
+		if e != nil {
+			return e
+		}
+
+		ent.s = n
+		ent.c = true
+	}
+
+	s.l = append(s.l, ent)
+	return nil
+}
+
+// Walk call the function for each stored entry, sorted by name (keeping the order of the entries
+// with the same name) and stop at the first error. The spool is cleaned after.
+func (s *Spool) Walk(fct func(i fs.FileInfo, r io.ReadCloser, name, target string) error) error {
+	defer func() {
+		_ = s.Close()
+	}()
+
+	sort.SliceStable(s.l, func(i, j int) bool {
+		return s.l[i].n < s.l[j].n
+	})
+
+	for _, ent := range s.l {
+		var r io.ReadCloser
+
+		if ent.c {
+			r = io.NopCloser(io.NewSectionReader(s.f, ent.o, ent.s))
+		}
+
+		if e := fct(ent.i, r, ent.n, ent.t); e != nil {
+			return e
+		}
+	}
+
+	return nil
+}
+
+// Close remove the stored entries and the temporary file.
+func (s *Spool) Close() error {
+	s.l = nil
+	s.o = 0
+
+	if s.f == nil {
+		return nil
+	}
+
+	var (
+		f = s.f
+		n = f.Name()
+	)
+
+	s.f = nil
+	_ = f.Close()
+
+	return os.Remove(n)
+}
+
+type sizedReader struct {
+	r io.Reader
+	s int64
+}
+
+// NewSizedReader return a reader giving exactly the given size of the stream:
+// the remaining content is ignored and a shorter stream return io.ErrUnexpectedEOF.
+func NewSizedReader(r io.Reader, size int64) io.ReadCloser {
+	return &sizedReader{
+		r: io.LimitReader(r, size),
+		s: size,
+	}
+}
+
+func (o *sizedReader) Read(p []byte) (int, error) {
+	n, e := o.r.Read(p)
+	o.s -= int64(n)
+
+	if e == io.EOF && o.s > 0 {
+		return n, io.ErrUnexpectedEOF
+	}
+
+	return n, e
+}
+
+func (o *sizedReader) Close() error {
+	return nil
+}
@@ -0,0 +1,98 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package types
+
+import "fmt"
+
+type WarningKind uint8
+
+const (
+	// WarnUnsupported is reported for an entry with a type that cannot be extracted (fifo, socket, unknown type, ...).
+	// The entry is skipped.
+	WarnUnsupported WarningKind = iota + 1
+	// WarnXattr is reported for an entry with extended attributes, which are not restored or cannot be restored.
+	WarnXattr
+	// WarnTimestamp is reported when the modification time of an entry cannot be restored
+	// or is truncated by the destination file system.
+	WarnTimestamp
+	// WarnPermission is reported when the permission of an entry is restored with less bits than into the archive.
+	WarnPermission
+)
+
+func (k WarningKind) String() string {
+	switch k {
+	case WarnUnsupported:
+		return "unsupported entry"
+	case WarnXattr:
+		return "extended attributes skipped"
+	case WarnTimestamp:
+		return "timestamp not preserved"
+	case WarnPermission:
+		return "permission downgraded"
+	default:
+		return "unknown warning"
+	}
+}
+
+// Warning is a non-fatal anomaly found while walking or extracting an archive.
+type Warning struct {
+	Kind    WarningKind
+	Path    string
+	Message string
+	Err     error
+}
+
+func (w Warning) String() string {
+	var s = w.Kind.String() + " '" + w.Path + "'"
+
+	if len(w.Message) > 0 {
+		s += ": " + w.Message
+	}
+
+	if w.Err != nil {
+		s += fmt.Sprintf(" (%v)", w.Err)
+	}
+
+	return s
+}
+
+// FuncWarning is called for each warning. A nil function ignores the warnings.
+type FuncWarning func(w Warning)
+
+// Call send the warning to the function if not nil.
+func (f FuncWarning) Call(kind WarningKind, path, msg string, err error) {
+	if f == nil {
+		return
+	}
+
+	f(Warning{
+		Kind:    kind,
+		Path:    path,
+		Message: msg,
+		Err:     err,
+	})
+}
@@ -28,8 +28,14 @@ package types
 import (
 	"io"
 	"io/fs"
+	"os"
+	"strconv"
+	"time"
 )
 
+// EnvSourceDateEpoch is the environment variable giving the timestamp of a reproducible build.
+const EnvSourceDateEpoch = "SOURCE_DATE_EPOCH"
+
 type ReplaceName func(string) string
 
 type Writer interface {
@@ -53,4 +59,42 @@ type Writer interface {
 	//   - ReplaceName: a function to replace the name of the embedded file, if needed.
 	// Returns error if triggered
 	FromPath(string, string, ReplaceName) error
+
+	// AddReader will add a regular file into the archive from the given stream, without any file on disk.
+	//
+	// Parameter(s):
+	//   - string: the pathname of the embedded file into the archive.
+	//   - int64: the size of the content, the stream must give exactly this size.
+	//   - fs.FileMode: the permission of the embedded file.
+	//   - io.Reader: the content of the embedded file, not closed.
+	// Return type: error
+	AddReader(string, int64, fs.FileMode, io.Reader) error
+}
+
+// Reproducible define the normalization applied by a writer in reproducible mode, to build the same archive
+// from the same content: all entries have the same timestamp and owner, without access and change time,
+// and are written sorted by name when the writer is closed.
+type Reproducible struct {
+	// ModTime is the modification time of all entries. If zero, the SOURCE_DATE_EPOCH environment variable
+	// is used if defined, otherwise the Unix epoch.
+	ModTime time.Time
+
+	// Uid is the owner user id of all entries (not used by zip).
+	Uid int
+
+	// Gid is the owner group id of all entries (not used by zip).
+	Gid int
+}
+
+// Time return the normalized modification time of the entries.
+func (r Reproducible) Time() time.Time {
+	if !r.ModTime.IsZero() {
+		return r.ModTime.UTC().Truncate(time.Second)
+	} else if s := os.Getenv(EnvSourceDateEpoch); len(s) > 0 {
+		if n, e := strconv.ParseInt(s, 10, 64); e == nil {
+			return time.Unix(n, 0).UTC()
+		}
+	}
+
+	return time.Unix(0, 0).UTC()
 }
@@ -73,3 +73,13 @@ func NewWriter(w io.WriteCloser) (arctps.Writer, error) {
 		z: zip.NewWriter(w),
 	}, nil
 }
+
+// NewWriterReproducible return a writer in reproducible mode: the entries are normalized with the given
+// reproducible definition, and kept into a temporary file to be written sorted by name on close.
+func NewWriterReproducible(w io.WriteCloser, r arctps.Reproducible) (arctps.Writer, error) {
+	return &wrt{
+		w: w,
+		z: zip.NewWriter(w),
+		r: &r,
+	}, nil
+}
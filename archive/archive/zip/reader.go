@@ -36,6 +36,11 @@ import (
 type rdr struct {
 	r io.ReadCloser
 	z *zip.Reader
+	w arctps.FuncWarning
+}
+
+func (o *rdr) RegisterFuncWarning(fct arctps.FuncWarning) {
+	o.w = fct
 }
 
 func (o *rdr) Close() error {
@@ -84,9 +89,36 @@ func (o *rdr) Has(s string) bool {
 
 func (o *rdr) Walk(fct arctps.FuncExtract) {
 	for _, f := range o.z.File {
-		r, _ := f.Open()
+		r, e := f.Open()
+
+		if e != nil {
+			// like an unknown compression method, the entry cannot be read
+			o.w.Call(arctps.WarnUnsupported, f.Name, "", e)
+			continue
+		}
+
 		if !fct(f.FileInfo(), r, f.Name, "") {
 			return
 		}
 	}
 }
+
+// ExtractAll open each entry by a worker, using the random access of the zip archive.
+func (o *rdr) ExtractAll(destination string, opt arctps.ExtractOptions) error {
+	var i = 0
+
+	return arctps.Extract(destination, opt, o.w, func() (arctps.ExtractEntry, bool, error) {
+		if i >= len(o.z.File) {
+			return arctps.ExtractEntry{}, false, nil
+		}
+
+		f := o.z.File[i]
+		i++
+
+		return arctps.ExtractEntry{
+			Info: f.FileInfo(),
+			Path: f.Name,
+			Open: f.Open,
+		}, true, nil
+	})
+}
@@ -31,6 +31,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"time"
 
 	arctps "github.com/nabbar/golib/archive/archive/types"
 )
@@ -38,9 +39,17 @@ import (
 type wrt struct {
 	w io.WriteCloser
 	z *zip.Writer
+	r *arctps.Reproducible
+	s arctps.Spool
 }
 
 func (o *wrt) Close() error {
+	if o.r != nil {
+		if e := o.s.Walk(o.add); e != nil {
+			return e
+		}
+	}
+
 	if e := o.z.Flush(); e != nil {
 		return e
 	} else if e = o.z.Close(); e != nil {
@@ -53,6 +62,28 @@ func (o *wrt) Close() error {
 }
 
 func (o *wrt) Add(i fs.FileInfo, r io.ReadCloser, forcePath, notUse string) error {
+	if o.r == nil || r == nil {
+		return o.add(i, r, forcePath, notUse)
+	}
+
+	defer func() {
+		_ = r.Close()
+	}()
+
+	// reproducible mode: the entries are written sorted by name on close
+	return o.s.Add(i, r, forcePath, notUse)
+}
+
+// AddReader adds a regular file to the zip archive from the given stream of the given size.
+func (o *wrt) AddReader(name string, size int64, mode fs.FileMode, r io.Reader) error {
+	if len(name) < 1 || size < 0 || r == nil {
+		return fs.ErrInvalid
+	}
+
+	return o.Add(arctps.NewFileInfo(name, size, mode&^fs.ModeType, time.Now()), arctps.NewSizedReader(r, size), name, "")
+}
+
+func (o *wrt) add(i fs.FileInfo, r io.ReadCloser, forcePath, notUse string) error {
 	var (
 		e error
 		h *zip.FileHeader
@@ -75,6 +106,10 @@ func (o *wrt) Add(i fs.FileInfo, r io.ReadCloser, forcePath, notUse string) erro
 		h.Name = forcePath
 	}
 
+	if o.r != nil {
+		o.normalize(h)
+	}
+
 	if w, e = o.z.CreateHeader(h); e != nil {
 		return e
 	} else if _, e = io.Copy(w, r); e != nil {
@@ -84,6 +119,21 @@ func (o *wrt) Add(i fs.FileInfo, r io.ReadCloser, forcePath, notUse string) erro
 	return nil
 }
 
+// normalize apply the reproducible mode on the header.
+// The zip timestamp cannot be before 1980, the first valid date is used instead.
+func (o *wrt) normalize(h *zip.FileHeader) {
+	var (
+		t = o.r.Time()
+		m = time.Date(1980, time.January, 1, 0, 0, 0, 0, time.UTC)
+	)
+
+	if t.Before(m) {
+		t = m
+	}
+
+	h.Modified = t
+}
+
 func (o *wrt) FromPath(source string, filter string, fct arctps.ReplaceName) error {
 	if i, e := os.Stat(source); e == nil && !i.IsDir() {
 		return o.addFiltering(source, filter, fct, i)
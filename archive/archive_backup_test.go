@@ -0,0 +1,171 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package archive_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	arcarc "github.com/nabbar/golib/archive/archive"
+	arctps "github.com/nabbar/golib/archive/archive/types"
+	arcbck "github.com/nabbar/golib/archive/backup"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type nopReadCloser struct {
+	*bytes.Reader
+}
+
+func (nopReadCloser) Close() error {
+	return nil
+}
+
+var _ = Describe("archive/backup", func() {
+	var (
+		src string
+		ref *arcbck.Manifest
+	)
+
+	backup := func(alg arcarc.Algorithm, r *arcbck.Manifest, opt arcbck.Options) (*arcbck.Manifest, []string) {
+		var (
+			buf = bytes.NewBuffer(make([]byte, 0))
+			wrt arctps.Writer
+			rdr arctps.Reader
+			res *arcbck.Manifest
+			fnd []string
+		)
+
+		wrt, err = alg.Writer(nopWriteCloser{Writer: buf})
+		Expect(err).ToNot(HaveOccurred())
+
+		res, err = arcbck.Run(context.Background(), src, r, wrt, opt)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).ToNot(BeNil())
+		Expect(wrt.Close()).ToNot(HaveOccurred())
+
+		rdr, err = alg.Reader(nopReadCloser{Reader: bytes.NewReader(buf.Bytes())})
+		Expect(err).ToNot(HaveOccurred())
+
+		fnd, err = rdr.List()
+		Expect(err).ToNot(HaveOccurred())
+
+		return res, fnd
+	}
+
+	BeforeEach(func() {
+		src, err = os.MkdirTemp("", "golib-archive-backup-")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(os.MkdirAll(filepath.Join(src, "sub"), 0755)).ToNot(HaveOccurred())
+		Expect(os.MkdirAll(filepath.Join(src, "skip"), 0755)).ToNot(HaveOccurred())
+		Expect(os.WriteFile(filepath.Join(src, "a.txt"), []byte("file a"), 0644)).ToNot(HaveOccurred())
+		Expect(os.WriteFile(filepath.Join(src, "sub", "b.txt"), []byte("file b"), 0644)).ToNot(HaveOccurred())
+		Expect(os.WriteFile(filepath.Join(src, "sub", "c.txt"), []byte("file c"), 0644)).ToNot(HaveOccurred())
+		Expect(os.WriteFile(filepath.Join(src, "skip", "d.txt"), []byte("file d"), 0644)).ToNot(HaveOccurred())
+
+		ref = nil
+	})
+
+	AfterEach(func() {
+		_ = os.RemoveAll(src)
+	})
+
+	Context("Full then incremental backup", func() {
+		It("must write only changed files", func() {
+			var (
+				fnd []string
+				opt = arcbck.Options{
+					Exclude:      []string{"skip"},
+					ManifestName: ".manifest.json",
+				}
+			)
+
+			ref, fnd = backup(arcarc.Tar, nil, opt)
+			Expect(ref.Kind).To(Equal(arcbck.Full))
+			Expect(ref.Written).To(Equal([]string{"a.txt", "sub/b.txt", "sub/c.txt"}))
+			Expect(fnd).To(ConsistOf("a.txt", "sub/b.txt", "sub/c.txt", ".manifest.json"))
+			Expect(ref.Files["a.txt"].Hash).To(HaveLen(64))
+
+			old := time.Now().Add(time.Hour)
+			Expect(os.WriteFile(filepath.Join(src, "sub", "b.txt"), []byte("file b updated"), 0644)).ToNot(HaveOccurred())
+			Expect(os.Chtimes(filepath.Join(src, "a.txt"), old, old)).ToNot(HaveOccurred())
+			Expect(os.Remove(filepath.Join(src, "sub", "c.txt"))).ToNot(HaveOccurred())
+			Expect(os.WriteFile(filepath.Join(src, "e.txt"), []byte("file e"), 0644)).ToNot(HaveOccurred())
+
+			inc, fnd := backup(arcarc.Zip, ref, opt)
+			Expect(inc.Kind).To(Equal(arcbck.Incremental))
+			Expect(inc.Parent.Equal(ref.Created)).To(BeTrue())
+			Expect(inc.Written).To(Equal([]string{"a.txt", "e.txt", "sub/b.txt"}))
+			Expect(inc.Deleted).To(Equal([]string{"sub/c.txt"}))
+			Expect(inc.Stats.Added).To(Equal(1))
+			Expect(inc.Stats.Modified).To(Equal(2))
+			Expect(inc.Stats.Deleted).To(Equal(1))
+			Expect(fnd).To(ConsistOf("a.txt", "e.txt", "sub/b.txt", ".manifest.json"))
+			Expect(inc.Files).To(HaveLen(3))
+		})
+
+		It("must skip files with only a new modification time when comparing hash", func() {
+			ref, _ = backup(arcarc.Tar, nil, arcbck.Options{})
+
+			old := time.Now().Add(time.Hour)
+			Expect(os.Chtimes(filepath.Join(src, "a.txt"), old, old)).ToNot(HaveOccurred())
+
+			inc, fnd := backup(arcarc.Tar, ref, arcbck.Options{
+				Kind:    arcbck.Differential,
+				Compare: arcbck.CompareHash,
+			})
+
+			Expect(inc.Kind).To(Equal(arcbck.Differential))
+			Expect(inc.Written).To(BeEmpty())
+			Expect(fnd).To(BeEmpty())
+			Expect(inc.Stats.Unchanged).To(Equal(4))
+		})
+	})
+
+	Context("Manifest", func() {
+		It("must be written and read back", func() {
+			var buf = bytes.NewBuffer(make([]byte, 0))
+
+			ref, _ = backup(arcarc.Tar, nil, arcbck.Options{})
+			Expect(ref.Write(buf)).ToNot(HaveOccurred())
+
+			res, e := arcbck.ReadManifest(buf)
+			Expect(e).ToNot(HaveOccurred())
+			Expect(res.Kind).To(Equal(arcbck.Full))
+			Expect(res.Paths()).To(Equal(ref.Paths()))
+			Expect(res.Files["sub/b.txt"].Hash).To(Equal(ref.Files["sub/b.txt"].Hash))
+		})
+
+		It("must fail with an invalid manifest", func() {
+			_, e := arcbck.ReadManifest(bytes.NewBufferString(`{"version": 0}`))
+			Expect(e).To(MatchError(arcbck.ErrInvalidManifest))
+		})
+	})
+})
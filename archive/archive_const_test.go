@@ -91,11 +91,23 @@ var _ = Describe("archive/archive/algorithm", func() {
 		It("xz must succeed", func() {
 			testingCompress(arccmp.XZ, "xz", ".xz")
 		})
+		It("snappy must succeed", func() {
+			testingCompress(arccmp.Snappy, "snappy", ".sz")
+		})
+		It("brotli must succeed", func() {
+			testingCompress(arccmp.Brotli, "brotli", ".br")
+		})
 		It("tar must succeed", func() {
 			testingArchive(arcarc.Tar, "tar", ".tar")
 		})
 		It("zip must succeed", func() {
 			testingArchive(arcarc.Zip, "zip", ".zip")
 		})
+		It("cpio must succeed", func() {
+			testingArchive(arcarc.Cpio, "cpio", ".cpio")
+		})
+		It("ar must succeed", func() {
+			testingArchive(arcarc.Ar, "ar", ".ar")
+		})
 	})
 })
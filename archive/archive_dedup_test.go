@@ -0,0 +1,243 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package archive_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	sdksss "github.com/aws/aws-sdk-go-v2/service/s3"
+	sdktps "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	arccmp "github.com/nabbar/golib/archive/compress"
+	arcddp "github.com/nabbar/golib/archive/dedup"
+	ddps3 "github.com/nabbar/golib/archive/dedup/s3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// s3Fake is an in memory bucket implementing the dedup s3 client.
+type s3Fake struct {
+	m sync.Mutex
+	o map[string][]byte
+}
+
+func (f *s3Fake) ListPrefix(_ string, prefix string) ([]sdktps.Object, string, int64, error) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	var l = make([]sdktps.Object, 0)
+
+	for k := range f.o {
+		if strings.HasPrefix(k, prefix) {
+			var n = k
+			l = append(l, sdktps.Object{Key: &n})
+		}
+	}
+
+	return l, "", int64(len(l)), nil
+}
+
+func (f *s3Fake) Get(object string) (*sdksss.GetObjectOutput, error) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	if p, ok := f.o[object]; !ok {
+		return nil, &sdktps.NoSuchKey{}
+	} else {
+		return &sdksss.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(p))}, nil
+	}
+}
+
+func (f *s3Fake) Put(object string, body io.Reader) error {
+	p, e := io.ReadAll(body)
+
+	if e != nil {
+		return e
+	}
+
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	f.o[object] = p
+	return nil
+}
+
+func chunkList(p []byte, opt arcddp.ChunkOptions) []arcddp.ID {
+	var (
+		c = arcddp.NewChunker(bytes.NewReader(p), opt)
+		l = make([]arcddp.ID, 0)
+	)
+
+	for {
+		b, e := c.Next()
+		if errors.Is(e, io.EOF) {
+			return l
+		}
+		Expect(e).ToNot(HaveOccurred())
+		l = append(l, arcddp.Sum(b))
+	}
+}
+
+var _ = Describe("archive/dedup", func() {
+	var (
+		dir string
+		src string
+		opt = arcddp.ChunkOptions{MinSize: 2 * 1024, AvgSize: 8 * 1024, MaxSize: 32 * 1024}
+		rnd = func(n int, seed int64) []byte {
+			var b = make([]byte, n)
+			_, _ = rand.New(rand.NewSource(seed)).Read(b)
+			return b
+		}
+	)
+
+	BeforeEach(func() {
+		dir, err = os.MkdirTemp("", "golib-archive-dedup-")
+		Expect(err).ToNot(HaveOccurred())
+
+		src = filepath.Join(dir, "src")
+		Expect(os.MkdirAll(filepath.Join(src, "sub", "empty"), 0755)).ToNot(HaveOccurred())
+		Expect(os.WriteFile(filepath.Join(src, "big.bin"), rnd(512*1024, 1), 0644)).ToNot(HaveOccurred())
+		Expect(os.WriteFile(filepath.Join(src, "sub", "copy.bin"), rnd(512*1024, 1), 0600)).ToNot(HaveOccurred())
+		Expect(os.WriteFile(filepath.Join(src, "small.txt"), []byte("small file"), 0644)).ToNot(HaveOccurred())
+		Expect(os.Symlink("small.txt", filepath.Join(src, "link"))).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		_ = os.RemoveAll(dir)
+	})
+
+	It("must cut the same chunks around a change", func() {
+		var (
+			org = rnd(1024*1024, 2)
+			mod = append(append(append([]byte{}, org[:300000]...), []byte("inserted data")...), org[300000:]...)
+			one = chunkList(org, opt)
+			two = chunkList(mod, opt)
+			kno = make(map[arcddp.ID]bool)
+			shr = 0
+		)
+
+		Expect(chunkList(org, opt)).To(Equal(one))
+
+		for _, i := range one {
+			kno[i] = true
+		}
+
+		for _, i := range two {
+			if kno[i] {
+				shr++
+			}
+		}
+
+		Expect(len(one)).To(BeNumerically(">", 32))
+		Expect(shr).To(BeNumerically(">=", len(two)-3))
+	})
+
+	It("must store the shared chunks once and restore the files", func() {
+		st, e := arcddp.NewStoreFS(filepath.Join(dir, "store"))
+		Expect(e).ToNot(HaveOccurred())
+
+		one, e := arcddp.Archive(context.Background(), src, st, arcddp.Options{Chunk: opt, Compression: arccmp.Gzip})
+		Expect(e).ToNot(HaveOccurred())
+		Expect(one.Stats.Files).To(Equal(6))
+		Expect(one.Stats.NewChunks).To(BeNumerically("<", one.Stats.Chunks))
+		Expect(one.Stats.NewBytes).To(BeNumerically("<", one.Stats.Bytes*6/10))
+
+		// second snapshot with a small change
+		h, e := os.OpenFile(filepath.Join(src, "big.bin"), os.O_WRONLY, 0)
+		Expect(e).ToNot(HaveOccurred())
+		_, e = h.WriteAt([]byte("changed"), 200000)
+		Expect(e).ToNot(HaveOccurred())
+		Expect(h.Close()).ToNot(HaveOccurred())
+
+		two, e := arcddp.Archive(context.Background(), src, st, arcddp.Options{Chunk: opt, Compression: arccmp.Gzip})
+		Expect(e).ToNot(HaveOccurred())
+		Expect(two.Stats.NewChunks).To(BeNumerically("<=", 2))
+
+		var buf = bytes.NewBuffer(make([]byte, 0))
+		Expect(two.Write(buf)).ToNot(HaveOccurred())
+		two, e = arcddp.ReadSnapshot(buf)
+		Expect(e).ToNot(HaveOccurred())
+
+		out := filepath.Join(dir, "out")
+		Expect(arcddp.Restore(context.Background(), two, st, out)).ToNot(HaveOccurred())
+
+		for _, n := range []string{"big.bin", "sub/copy.bin", "small.txt"} {
+			a, e := os.ReadFile(filepath.Join(src, n))
+			Expect(e).ToNot(HaveOccurred())
+			b, e := os.ReadFile(filepath.Join(out, n))
+			Expect(e).ToNot(HaveOccurred())
+			Expect(b).To(Equal(a))
+		}
+
+		i, e := os.Stat(filepath.Join(out, "sub", "copy.bin"))
+		Expect(e).ToNot(HaveOccurred())
+		Expect(i.Mode().Perm()).To(Equal(os.FileMode(0600)))
+
+		l, e := os.Readlink(filepath.Join(out, "link"))
+		Expect(e).ToNot(HaveOccurred())
+		Expect(l).To(Equal("small.txt"))
+
+		i, e = os.Stat(filepath.Join(out, "sub", "empty"))
+		Expect(e).ToNot(HaveOccurred())
+		Expect(i.IsDir()).To(BeTrue())
+	})
+
+	It("must detect a corrupted chunk", func() {
+		st, e := arcddp.NewStoreFS(filepath.Join(dir, "store"))
+		Expect(e).ToNot(HaveOccurred())
+
+		snp, e := arcddp.Archive(context.Background(), src, st, arcddp.Options{Chunk: opt})
+		Expect(e).ToNot(HaveOccurred())
+
+		id := snp.ChunkIDs()[0]
+		Expect(st.Put(context.Background(), id, []byte("corrupted"))).ToNot(HaveOccurred())
+		Expect(arcddp.Restore(context.Background(), snp, st, filepath.Join(dir, "out"))).To(MatchError(arcddp.ErrCorruptChunk))
+	})
+
+	It("must archive and restore with the s3 store", func() {
+		st, e := ddps3.New(&s3Fake{o: make(map[string][]byte)}, "dedup")
+		Expect(e).ToNot(HaveOccurred())
+
+		_, e = st.Get(context.Background(), arcddp.Sum([]byte("missing")))
+		Expect(e).To(MatchError(arcddp.ErrChunkNotFound))
+
+		snp, e := arcddp.Archive(context.Background(), src, st, arcddp.Options{Chunk: opt, Compression: arccmp.Zstd})
+		Expect(e).ToNot(HaveOccurred())
+		Expect(arcddp.Restore(context.Background(), snp, st, filepath.Join(dir, "out"))).ToNot(HaveOccurred())
+
+		b, e := os.ReadFile(filepath.Join(dir, "out", "big.bin"))
+		Expect(e).ToNot(HaveOccurred())
+		Expect(b).To(Equal(rnd(512*1024, 1)))
+	})
+})
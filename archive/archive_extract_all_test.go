@@ -0,0 +1,205 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package archive_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	arcarc "github.com/nabbar/golib/archive/archive"
+	arctps "github.com/nabbar/golib/archive/archive/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("archive/archive extract all", func() {
+	const nbr = 200
+
+	var (
+		dir string
+		ctn = func(i int) string {
+			if i%50 == 0 {
+				// bigger than the buffer to be streamed
+				return strings.Repeat(fmt.Sprintf("%d", i), 2048)
+			}
+			return fmt.Sprintf("content of file %d", i)
+		}
+		name = func(i int) string {
+			return fmt.Sprintf("dir%d/file%d.txt", i%10, i)
+		}
+	)
+
+	makeTar := func() []byte {
+		var (
+			buf = bytes.NewBuffer(make([]byte, 0))
+			wrt = tar.NewWriter(buf)
+		)
+
+		Expect(wrt.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: "dir0/", Mode: 0750})).ToNot(HaveOccurred())
+
+		for i := 0; i < nbr; i++ {
+			c := ctn(i)
+			Expect(wrt.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: name(i), Mode: 0644, Size: int64(len(c))})).ToNot(HaveOccurred())
+			_, e := wrt.Write([]byte(c))
+			Expect(e).ToNot(HaveOccurred())
+		}
+
+		Expect(wrt.WriteHeader(&tar.Header{Typeflag: tar.TypeSymlink, Name: "link", Linkname: name(1)})).ToNot(HaveOccurred())
+		Expect(wrt.WriteHeader(&tar.Header{Typeflag: tar.TypeLink, Name: "hard", Linkname: name(nbr - 1)})).ToNot(HaveOccurred())
+		Expect(wrt.Close()).ToNot(HaveOccurred())
+
+		return buf.Bytes()
+	}
+
+	makeZip := func() []byte {
+		var (
+			buf = bytes.NewBuffer(make([]byte, 0))
+			wrt = zip.NewWriter(buf)
+		)
+
+		for i := 0; i < nbr; i++ {
+			w, e := wrt.Create(name(i))
+			Expect(e).ToNot(HaveOccurred())
+			_, e = w.Write([]byte(ctn(i)))
+			Expect(e).ToNot(HaveOccurred())
+		}
+
+		Expect(wrt.Close()).ToNot(HaveOccurred())
+		return buf.Bytes()
+	}
+
+	check := func() {
+		for i := 0; i < nbr; i++ {
+			b, e := os.ReadFile(filepath.Join(dir, name(i)))
+			Expect(e).ToNot(HaveOccurred())
+			Expect(string(b)).To(Equal(ctn(i)))
+		}
+	}
+
+	BeforeEach(func() {
+		dir, err = os.MkdirTemp("", "golib-archive-extractall-")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		_ = os.RemoveAll(dir)
+	})
+
+	It("must extract a tar archive with workers", func() {
+		var (
+			cnt = new(atomic.Int64)
+			opt = arctps.ExtractOptions{
+				Workers: 4,
+				Buffer:  1024,
+				Progress: func(path string, size int64) {
+					cnt.Add(1)
+				},
+			}
+		)
+
+		rdr, e := arcarc.Tar.Reader(io.NopCloser(bytes.NewReader(makeTar())))
+		Expect(e).ToNot(HaveOccurred())
+		Expect(rdr.ExtractAll(dir, opt)).ToNot(HaveOccurred())
+		check()
+
+		Expect(cnt.Load()).To(BeEquivalentTo(nbr + 3))
+
+		i, e := os.Stat(filepath.Join(dir, "dir0"))
+		Expect(e).ToNot(HaveOccurred())
+		Expect(i.Mode().Perm()).To(Equal(os.FileMode(0750)))
+
+		t, e := os.Readlink(filepath.Join(dir, "link"))
+		Expect(e).ToNot(HaveOccurred())
+		Expect(t).To(Equal(name(1)))
+
+		b, e := os.ReadFile(filepath.Join(dir, "hard"))
+		Expect(e).ToNot(HaveOccurred())
+		Expect(string(b)).To(Equal(ctn(nbr - 1)))
+	})
+
+	It("must extract a zip archive with workers", func() {
+		var (
+			src = makeZip()
+			tmp = filepath.Join(dir, "src.zip")
+		)
+
+		Expect(os.WriteFile(tmp, src, 0644)).ToNot(HaveOccurred())
+
+		hdf, e := os.Open(tmp)
+		Expect(e).ToNot(HaveOccurred())
+
+		alg, rdr, _, e := arcarc.Detect(hdf)
+		Expect(e).ToNot(HaveOccurred())
+		Expect(alg).To(Equal(arcarc.Zip))
+
+		defer func() {
+			_ = rdr.Close()
+		}()
+
+		Expect(rdr.ExtractAll(dir, arctps.ExtractOptions{Workers: 8})).ToNot(HaveOccurred())
+		check()
+	})
+
+	It("must apply the error policy", func() {
+		// a directory with the name of a file make this file fail
+		Expect(os.MkdirAll(filepath.Join(dir, name(3)), 0755)).ToNot(HaveOccurred())
+
+		rdr, e := arcarc.Tar.Reader(io.NopCloser(bytes.NewReader(makeTar())))
+		Expect(e).ToNot(HaveOccurred())
+
+		var (
+			mux sync.Mutex
+			bad = make([]string, 0)
+		)
+
+		Expect(rdr.ExtractAll(dir, arctps.ExtractOptions{
+			Workers: 2,
+			OnError: func(path string, err error) arctps.ErrorPolicy {
+				mux.Lock()
+				defer mux.Unlock()
+				bad = append(bad, path)
+				return arctps.ErrorSkip
+			},
+		})).ToNot(HaveOccurred())
+		Expect(bad).To(Equal([]string{name(3)}))
+
+		b, e := os.ReadFile(filepath.Join(dir, name(4)))
+		Expect(e).ToNot(HaveOccurred())
+		Expect(string(b)).To(Equal(ctn(4)))
+
+		rdr, e = arcarc.Tar.Reader(io.NopCloser(bytes.NewReader(makeTar())))
+		Expect(e).ToNot(HaveOccurred())
+		Expect(rdr.ExtractAll(dir, arctps.ExtractOptions{Policy: arctps.ErrorAbort})).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,189 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package archive_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	libarc "github.com/nabbar/golib/archive"
+	arcarc "github.com/nabbar/golib/archive/archive"
+	arccmp "github.com/nabbar/golib/archive/compress"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("archive/facade", func() {
+	var (
+		dir string
+		src string
+	)
+
+	BeforeEach(func() {
+		dir, err = os.MkdirTemp("", "golib-archive-facade-")
+		Expect(err).ToNot(HaveOccurred())
+
+		src = filepath.Join(dir, "src")
+		Expect(os.MkdirAll(filepath.Join(src, "sub"), 0755)).ToNot(HaveOccurred())
+		Expect(os.WriteFile(filepath.Join(src, "a.txt"), []byte("file a"), 0644)).ToNot(HaveOccurred())
+		Expect(os.WriteFile(filepath.Join(src, "b.log"), []byte("file b"), 0644)).ToNot(HaveOccurred())
+		Expect(os.WriteFile(filepath.Join(src, "sub", "c.txt"), []byte("file c"), 0644)).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		_ = os.RemoveAll(dir)
+	})
+
+	for _, ext := range []string{".tar", ".tar.gz", ".zip", ".tar.zst"} {
+		ext := ext
+
+		It("must create, list and extract a '"+ext+"' archive", func() {
+			var (
+				out = filepath.Join(dir, "out"+ext)
+				dst = filepath.Join(dir, "dst")
+				siz int64
+				lst []string
+			)
+
+			Expect(libarc.Create(out, []string{src}, libarc.Options{
+				Filter: "*.txt",
+				Progress: func(n int64) {
+					siz += n
+				},
+			})).ToNot(HaveOccurred())
+			Expect(siz).To(BeEquivalentTo(12))
+
+			lst, err = libarc.List(out)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lst).To(ConsistOf("src/a.txt", "src/sub/c.txt"))
+
+			Expect(libarc.Extract(out, dst, libarc.Options{
+				Filter: "src/sub/*",
+				Limits: libarc.Limits{MaxOutputBytes: 1024},
+			})).ToNot(HaveOccurred())
+
+			_, err = os.Stat(filepath.Join(dst, "src", "a.txt"))
+			Expect(os.IsNotExist(err)).To(BeTrue())
+
+			b, e := os.ReadFile(filepath.Join(dst, "src", "sub", "c.txt"))
+			Expect(e).ToNot(HaveOccurred())
+			Expect(string(b)).To(Equal("file c"))
+		})
+	}
+
+	It("must only compress a single file without archive extension", func() {
+		var out = filepath.Join(dir, "a.txt.gz")
+
+		Expect(libarc.Create(out, []string{filepath.Join(src, "a.txt")}, libarc.Options{})).ToNot(HaveOccurred())
+
+		lst, e := libarc.List(out)
+		Expect(e).ToNot(HaveOccurred())
+		Expect(lst).To(Equal([]string{"a.txt"}))
+	})
+
+	It("must use the given algorithms and refuse to overwrite", func() {
+		var out = filepath.Join(dir, "out.bin")
+
+		opt := libarc.Options{
+			Archive:     arcarc.Zip,
+			Compression: arccmp.None,
+		}
+
+		Expect(libarc.Create(out, []string{src}, opt)).ToNot(HaveOccurred())
+
+		lst, e := libarc.List(out)
+		Expect(e).ToNot(HaveOccurred())
+		Expect(lst).To(HaveLen(3))
+
+		Expect(libarc.Create(out, []string{src}, opt)).To(MatchError(os.ErrExist))
+
+		opt.Overwrite = true
+		Expect(libarc.Create(out, []string{src}, opt)).ToNot(HaveOccurred())
+	})
+	It("must open archives with a detection cache", func() {
+		var (
+			cch = libarc.NewDetectCache(0)
+			opt = libarc.Options{Cache: cch}
+		)
+
+		for _, ext := range []string{".tar.gz", ".zip"} {
+			var out = filepath.Join(dir, "out"+ext)
+			Expect(libarc.Create(out, []string{src}, libarc.Options{})).ToNot(HaveOccurred())
+
+			for i := 0; i < 2; i++ {
+				lst, r, e := libarc.Open(out, opt)
+				Expect(e).ToNot(HaveOccurred())
+				Expect(lst).ToNot(BeNil())
+
+				l, e := lst.List()
+				Expect(e).ToNot(HaveOccurred())
+				Expect(l).To(HaveLen(3))
+				Expect(r.Close()).ToNot(HaveOccurred())
+			}
+		}
+
+		Expect(cch.Len()).To(Equal(2))
+
+		var out = filepath.Join(dir, "a.txt.gz")
+		Expect(libarc.Create(out, []string{filepath.Join(src, "a.txt")}, libarc.Options{})).ToNot(HaveOccurred())
+
+		lst, r, e := libarc.Open(out, opt)
+		Expect(e).ToNot(HaveOccurred())
+		Expect(lst).To(BeNil())
+
+		b, e := io.ReadAll(r)
+		Expect(e).ToNot(HaveOccurred())
+		Expect(string(b)).To(Equal("file a"))
+		Expect(r.Close()).ToNot(HaveOccurred())
+
+		i, e := os.Stat(out)
+		Expect(e).ToNot(HaveOccurred())
+
+		d, ok := cch.Load(out, i)
+		Expect(ok).To(BeTrue())
+		Expect(d).To(Equal(libarc.Detected{Compression: arccmp.Gzip, Archive: arcarc.None}))
+
+		// a cached chain is used without detection
+		cch.Store(out, i, libarc.Detected{Compression: arccmp.Gzip, Archive: arcarc.Tar})
+		lst, r, e = libarc.Open(out, opt)
+		Expect(e).ToNot(HaveOccurred())
+		Expect(lst).ToNot(BeNil())
+		Expect(r.Close()).ToNot(HaveOccurred())
+
+		// a modified file is detected again
+		Expect(libarc.Create(out, []string{src}, libarc.Options{Overwrite: true, Archive: arcarc.Tar, Compression: arccmp.Gzip})).ToNot(HaveOccurred())
+		lst, r, e = libarc.Open(out, opt)
+		Expect(e).ToNot(HaveOccurred())
+		Expect(lst).ToNot(BeNil())
+		Expect(r.Close()).ToNot(HaveOccurred())
+
+		cch.Delete(out)
+		Expect(cch.Len()).To(Equal(2))
+		cch.Clean()
+		Expect(cch.Len()).To(BeZero())
+	})
+})
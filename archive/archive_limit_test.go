@@ -0,0 +1,119 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package archive_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+
+	libarc "github.com/nabbar/golib/archive"
+	arccmp "github.com/nabbar/golib/archive/compress"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func bombTarGz(size int) []byte {
+	var (
+		buf = bytes.NewBuffer(make([]byte, 0))
+		gzw = gzip.NewWriter(buf)
+		wrt = tar.NewWriter(gzw)
+	)
+
+	Expect(wrt.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     "zero.bin",
+		Mode:     0644,
+		Size:     int64(size),
+	})).ToNot(HaveOccurred())
+
+	_, err := wrt.Write(make([]byte, size))
+	Expect(err).ToNot(HaveOccurred())
+	Expect(wrt.Close()).ToNot(HaveOccurred())
+	Expect(gzw.Close()).ToNot(HaveOccurred())
+
+	return buf.Bytes()
+}
+
+var _ = Describe("archive/extract limits", func() {
+	var (
+		dir string
+		src = bombTarGz(8 * 1024 * 1024)
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "archive-limit-")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		_ = os.RemoveAll(dir)
+	})
+
+	Context("Extracting a highly compressed archive", func() {
+		It("must fail with a BombError when the ratio is exceeded", func() {
+			err := libarc.ExtractAllLimit(io.NopCloser(bytes.NewReader(src)), "bomb.tar.gz", dir, libarc.Limits{MaxRatio: 100}, nil)
+
+			var bmb *libarc.BombError
+			Expect(errors.As(err, &bmb)).To(BeTrue())
+			Expect(bmb.Reason).To(Equal("max ratio"))
+			Expect(errors.Is(err, libarc.ErrDecompressionBomb)).To(BeTrue())
+
+			_, err = os.Stat(filepath.Join(dir, "zero.bin"))
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+
+		It("must fail with a BombError when the output size is exceeded", func() {
+			err := libarc.ExtractAllLimit(io.NopCloser(bytes.NewReader(src)), "bomb.tar.gz", dir, libarc.Limits{MaxOutputBytes: 1024 * 1024}, nil)
+			Expect(errors.Is(err, libarc.ErrDecompressionBomb)).To(BeTrue())
+		})
+
+		It("must succeed within the limits", func() {
+			err := libarc.ExtractAllLimit(io.NopCloser(bytes.NewReader(src)), "bomb.tar.gz", dir, libarc.Limits{MaxOutputBytes: 16 * 1024 * 1024}, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			i, err := os.Stat(filepath.Join(dir, "zero.bin"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(i.Size()).To(BeEquivalentTo(8 * 1024 * 1024))
+		})
+	})
+
+	Context("Reading a compressed stream with limits", func() {
+		It("must fail with a BombError when the ratio is exceeded", func() {
+			alg, rdr, err := libarc.DetectCompressionLimit(bytes.NewReader(src), arccmp.Limits{MaxRatio: 100})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(alg).To(Equal(arccmp.Gzip))
+
+			_, err = io.Copy(io.Discard, rdr)
+			Expect(errors.Is(err, arccmp.ErrDecompressionBomb)).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,145 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package archive_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	libarc "github.com/nabbar/golib/archive"
+	arcarc "github.com/nabbar/golib/archive/archive"
+	arctps "github.com/nabbar/golib/archive/archive/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("archive/archive reproducible writer", func() {
+	var (
+		rep = arctps.Reproducible{
+			ModTime: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+			Uid:     1000,
+			Gid:     1000,
+		}
+		ctn = map[string]string{
+			"b.txt":     "file b",
+			"a.txt":     "file a",
+			"sub/c.txt": strings.Repeat("c", 1500),
+		}
+	)
+
+	build := func(alg arcarc.Algorithm, order []string) []byte {
+		var buf = bytes.NewBuffer(make([]byte, 0))
+
+		wrt, e := alg.WriterReproducible(nopWriteCloser{buf}, rep)
+		Expect(e).ToNot(HaveOccurred())
+
+		for _, n := range order {
+			Expect(wrt.AddReader(n, int64(len(ctn[n])), 0644, strings.NewReader(ctn[n]))).ToNot(HaveOccurred())
+		}
+
+		Expect(wrt.Close()).ToNot(HaveOccurred())
+		return buf.Bytes()
+	}
+
+	It("must build the same tar whatever the order of the entries", func() {
+		one := build(arcarc.Tar, []string{"b.txt", "a.txt", "sub/c.txt"})
+		two := build(arcarc.Tar, []string{"sub/c.txt", "a.txt", "b.txt"})
+		Expect(one).To(Equal(two))
+
+		var (
+			rdr = tar.NewReader(bytes.NewReader(one))
+			lst = make([]string, 0)
+		)
+
+		for {
+			h, e := rdr.Next()
+			if e == io.EOF {
+				break
+			}
+
+			Expect(e).ToNot(HaveOccurred())
+			Expect(h.ModTime.Equal(rep.ModTime)).To(BeTrue())
+			Expect(h.AccessTime.IsZero()).To(BeTrue())
+			Expect(h.Uid).To(Equal(rep.Uid))
+			Expect(h.Gid).To(Equal(rep.Gid))
+
+			b, e := io.ReadAll(rdr)
+			Expect(e).ToNot(HaveOccurred())
+			Expect(string(b)).To(Equal(ctn[h.Name]))
+
+			lst = append(lst, h.Name)
+		}
+
+		Expect(lst).To(Equal([]string{"a.txt", "b.txt", "sub/c.txt"}))
+	})
+
+	It("must build the same zip whatever the order of the entries", func() {
+		one := build(arcarc.Zip, []string{"b.txt", "a.txt", "sub/c.txt"})
+		two := build(arcarc.Zip, []string{"sub/c.txt", "a.txt", "b.txt"})
+		Expect(one).To(Equal(two))
+	})
+
+	It("must fail to add a stream shorter than its size", func() {
+		wrt, e := arcarc.Tar.Writer(nopWriteCloser{io.Discard})
+		Expect(e).ToNot(HaveOccurred())
+		Expect(wrt.AddReader("short.txt", 10, 0644, strings.NewReader("short"))).To(MatchError(io.ErrUnexpectedEOF))
+	})
+
+	It("must create the same archive from files with different timestamps", func() {
+		dir, e := os.MkdirTemp("", "golib-archive-reproducible-")
+		Expect(e).ToNot(HaveOccurred())
+
+		defer func() {
+			_ = os.RemoveAll(dir)
+		}()
+
+		src := filepath.Join(dir, "src")
+
+		for n, c := range ctn {
+			Expect(os.MkdirAll(filepath.Dir(filepath.Join(src, n)), 0755)).ToNot(HaveOccurred())
+			Expect(os.WriteFile(filepath.Join(src, n), []byte(c), 0644)).ToNot(HaveOccurred())
+		}
+
+		opt := libarc.Options{Reproducible: &rep}
+		Expect(libarc.Create(filepath.Join(dir, "one.tar.gz"), []string{src}, opt)).ToNot(HaveOccurred())
+
+		t := time.Now().Add(-time.Hour)
+		Expect(os.Chtimes(filepath.Join(src, "a.txt"), t, t)).ToNot(HaveOccurred())
+		Expect(libarc.Create(filepath.Join(dir, "two.tar.gz"), []string{src}, opt)).ToNot(HaveOccurred())
+
+		one, e := os.ReadFile(filepath.Join(dir, "one.tar.gz"))
+		Expect(e).ToNot(HaveOccurred())
+		two, e := os.ReadFile(filepath.Join(dir, "two.tar.gz"))
+		Expect(e).ToNot(HaveOccurred())
+		Expect(one).To(Equal(two))
+	})
+})
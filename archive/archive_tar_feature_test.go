@@ -0,0 +1,151 @@
+//go:build linux
+// +build linux
+
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package archive_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	arctar "github.com/nabbar/golib/archive/archive/tar"
+	arctps "github.com/nabbar/golib/archive/archive/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"golang.org/x/sys/unix"
+)
+
+var _ = Describe("archive/archive/tar extended information", func() {
+	const (
+		size = 4 * 1024 * 1024
+		data = "data in the middle of a sparse file"
+	)
+
+	var (
+		dir string
+		src string
+		pth string
+		xat bool
+	)
+
+	BeforeEach(func() {
+		dir, err = os.MkdirTemp("", "golib-archive-feature-")
+		Expect(err).ToNot(HaveOccurred())
+
+		src = filepath.Join(dir, "src")
+		pth = filepath.Join(dir, "test.tar")
+		Expect(os.MkdirAll(src, 0755)).ToNot(HaveOccurred())
+
+		hdf, e := os.Create(filepath.Join(src, "sparse.bin"))
+		Expect(e).ToNot(HaveOccurred())
+		Expect(hdf.Truncate(size)).ToNot(HaveOccurred())
+		_, e = hdf.WriteAt([]byte(data), size/2)
+		Expect(e).ToNot(HaveOccurred())
+		Expect(hdf.Close()).ToNot(HaveOccurred())
+
+		// the file system of the temporary directory may not support the user extended attributes
+		xat = unix.Setxattr(filepath.Join(src, "sparse.bin"), "user.golib", []byte("value"), 0) == nil
+
+		hdf, e = os.Create(pth)
+		Expect(e).ToNot(HaveOccurred())
+
+		wrt, e := arctar.NewWriterFeature(hdf, arctar.FeatureAll)
+		Expect(e).ToNot(HaveOccurred())
+		Expect(wrt.FromPath(src, filepath.Join(src, "*"), func(s string) string {
+			return strings.TrimPrefix(s, dir+string(filepath.Separator))
+		})).ToNot(HaveOccurred())
+		Expect(wrt.Close()).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		_ = os.RemoveAll(dir)
+	})
+
+	It("must store the holes and restore the sparse file", func() {
+		i, e := os.Stat(pth)
+		Expect(e).ToNot(HaveOccurred())
+		Expect(i.Size()).To(BeNumerically("<", size))
+
+		hdf, e := os.Open(pth)
+		Expect(e).ToNot(HaveOccurred())
+
+		rdr, e := arctar.NewReaderFeature(hdf, arctar.FeatureAll)
+		Expect(e).ToNot(HaveOccurred())
+
+		var wrn = make([]arctps.WarningKind, 0)
+		rdr.RegisterFuncWarning(func(w arctps.Warning) {
+			wrn = append(wrn, w.Kind)
+		})
+
+		out := filepath.Join(dir, "out")
+		Expect(rdr.ExtractAll(out, arctps.ExtractOptions{})).ToNot(HaveOccurred())
+		Expect(rdr.Close()).ToNot(HaveOccurred())
+		Expect(wrn).To(BeEmpty())
+
+		b, e := os.ReadFile(filepath.Join(out, "src", "sparse.bin"))
+		Expect(e).ToNot(HaveOccurred())
+		Expect(b).To(HaveLen(size))
+		Expect(string(b[size/2 : size/2+len(data)])).To(Equal(data))
+		Expect(bytes.Count(b, []byte{0})).To(Equal(size - len(data)))
+
+		i, e = os.Stat(filepath.Join(out, "src", "sparse.bin"))
+		Expect(e).ToNot(HaveOccurred())
+		Expect(i.Sys().(*syscall.Stat_t).Blocks * 512).To(BeNumerically("<", size))
+
+		if xat {
+			v := make([]byte, 16)
+			n, e := unix.Getxattr(filepath.Join(out, "src", "sparse.bin"), "user.golib", v)
+			Expect(e).ToNot(HaveOccurred())
+			Expect(string(v[:n])).To(Equal("value"))
+		}
+	})
+
+	It("must report the extended attributes not restored", func() {
+		if !xat {
+			Skip("user extended attributes not supported")
+		}
+
+		hdf, e := os.Open(pth)
+		Expect(e).ToNot(HaveOccurred())
+
+		rdr, e := arctar.NewReaderFeature(hdf, arctar.FeatureSparse)
+		Expect(e).ToNot(HaveOccurred())
+
+		var wrn = make([]arctps.WarningKind, 0)
+		rdr.RegisterFuncWarning(func(w arctps.Warning) {
+			wrn = append(wrn, w.Kind)
+		})
+
+		Expect(rdr.ExtractAll(filepath.Join(dir, "out"), arctps.ExtractOptions{})).ToNot(HaveOccurred())
+		Expect(rdr.Close()).ToNot(HaveOccurred())
+		Expect(wrn).To(ContainElement(arctps.WarnXattr))
+	})
+})
@@ -0,0 +1,169 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package archive_test
+
+import (
+	"archive/tar"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	arctar "github.com/nabbar/golib/archive/archive/tar"
+	arctps "github.com/nabbar/golib/archive/archive/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("archive/archive/tar index", func() {
+	var (
+		dir string
+		pth string
+		ctn = map[string]string{
+			"a.txt":                           "file a",
+			"sub/b.txt":                       strings.Repeat("b", 1500),
+			strings.Repeat("long/", 40) + "c": "file with a pax long name",
+		}
+	)
+
+	BeforeEach(func() {
+		dir, err = os.MkdirTemp("", "golib-archive-index-")
+		Expect(err).ToNot(HaveOccurred())
+
+		pth = filepath.Join(dir, "test.tar")
+
+		hdf, e := os.Create(pth)
+		Expect(e).ToNot(HaveOccurred())
+
+		wrt := tar.NewWriter(hdf)
+
+		for n, c := range ctn {
+			Expect(wrt.WriteHeader(&tar.Header{
+				Typeflag: tar.TypeReg,
+				Name:     n,
+				Mode:     0644,
+				Size:     int64(len(c)),
+			})).ToNot(HaveOccurred())
+
+			_, e = wrt.Write([]byte(c))
+			Expect(e).ToNot(HaveOccurred())
+		}
+
+		Expect(wrt.Close()).ToNot(HaveOccurred())
+		Expect(hdf.Close()).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		_ = os.RemoveAll(dir)
+	})
+
+	It("must get each entry with the index", func() {
+		idx, e := arctar.LoadIndex(pth)
+		Expect(e).ToNot(HaveOccurred())
+		Expect(idx.Entries).To(HaveLen(len(ctn)))
+
+		_, e = os.Stat(pth + arctar.IndexExtension)
+		Expect(e).ToNot(HaveOccurred())
+
+		hdf, e := os.Open(pth)
+		Expect(e).ToNot(HaveOccurred())
+
+		rdr, e := arctar.NewIndexedReader(hdf, idx)
+		Expect(e).ToNot(HaveOccurred())
+
+		defer func() {
+			_ = rdr.Close()
+		}()
+
+		for n, c := range ctn {
+			Expect(rdr.Has(n)).To(BeTrue())
+
+			i, e := rdr.Info(n)
+			Expect(e).ToNot(HaveOccurred())
+			Expect(i.Size()).To(BeEquivalentTo(len(c)))
+
+			r, e := rdr.Get(n)
+			Expect(e).ToNot(HaveOccurred())
+
+			b, e := io.ReadAll(r)
+			Expect(e).ToNot(HaveOccurred())
+			Expect(string(b)).To(Equal(c))
+		}
+
+		Expect(rdr.Has("missing")).To(BeFalse())
+
+		var cnt = 0
+		rdr.Walk(func(_ os.FileInfo, _ io.ReadCloser, _, _ string) bool {
+			cnt++
+			return true
+		})
+		Expect(cnt).To(Equal(len(ctn)))
+	})
+
+	It("must extract all entries with the index", func() {
+		var out = filepath.Join(dir, "out")
+
+		hdf, e := os.Open(pth)
+		Expect(e).ToNot(HaveOccurred())
+
+		rdr, e := arctar.NewIndexedReader(hdf, nil)
+		Expect(e).ToNot(HaveOccurred())
+
+		defer func() {
+			_ = rdr.Close()
+		}()
+
+		Expect(rdr.ExtractAll(out, arctps.ExtractOptions{Workers: 3})).ToNot(HaveOccurred())
+
+		for n, c := range ctn {
+			b, e := os.ReadFile(filepath.Join(out, filepath.FromSlash(n)))
+			Expect(e).ToNot(HaveOccurred())
+			Expect(string(b)).To(Equal(c))
+		}
+	})
+
+	It("must reuse the sidecar index and rebuild a stale one", func() {
+		idx, e := arctar.LoadIndex(pth)
+		Expect(e).ToNot(HaveOccurred())
+
+		h, e := os.OpenFile(pth, os.O_APPEND|os.O_WRONLY, 0)
+		Expect(e).ToNot(HaveOccurred())
+		_, e = h.Write(make([]byte, 512))
+		Expect(e).ToNot(HaveOccurred())
+		Expect(h.Close()).ToNot(HaveOccurred())
+
+		hdf, e := os.Open(pth)
+		Expect(e).ToNot(HaveOccurred())
+		_, e = arctar.NewIndexedReader(hdf, idx)
+		Expect(e).To(MatchError(arctar.ErrStaleIndex))
+		_ = hdf.Close()
+
+		res, e := arctar.LoadIndex(pth)
+		Expect(e).ToNot(HaveOccurred())
+		Expect(res.Size).To(Equal(idx.Size + 512))
+		Expect(res.Entries).To(HaveLen(len(ctn)))
+	})
+})
@@ -0,0 +1,107 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package archive_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"os"
+	"time"
+
+	libarc "github.com/nabbar/golib/archive"
+	arctps "github.com/nabbar/golib/archive/archive/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("archive/extract warnings", func() {
+	Context("Extracting a tar archive with lossy entries", func() {
+		It("must report each anomaly as a warning and extract the supported entries", func() {
+			var (
+				buf = bytes.NewBuffer(make([]byte, 0))
+				wrt = tar.NewWriter(buf)
+				mod = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+				dir string
+				wrn = make([]arctps.Warning, 0)
+			)
+
+			Expect(wrt.WriteHeader(&tar.Header{
+				Typeflag: tar.TypeReg,
+				Name:     "setuid.txt",
+				Mode:     04755,
+				Size:     5,
+				ModTime:  mod,
+				PAXRecords: map[string]string{
+					"SCHILY.xattr.user.test": "value",
+				},
+				Format: tar.FormatPAX,
+			})).ToNot(HaveOccurred())
+			_, err = wrt.Write([]byte("hello"))
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(wrt.WriteHeader(&tar.Header{
+				Typeflag: tar.TypeFifo,
+				Name:     "fifo",
+				Mode:     0644,
+				ModTime:  mod,
+			})).ToNot(HaveOccurred())
+			Expect(wrt.Close()).ToNot(HaveOccurred())
+
+			dir, err = os.MkdirTemp("", "archive-warning-")
+			Expect(err).ToNot(HaveOccurred())
+
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+
+			err = libarc.ExtractAllWarning(io.NopCloser(buf), "warning.tar", dir, func(w libarc.Warning) {
+				wrn = append(wrn, w)
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			var knd = make(map[arctps.WarningKind]string)
+
+			for _, w := range wrn {
+				knd[w.Kind] = w.Path
+			}
+
+			Expect(knd).To(HaveKeyWithValue(arctps.WarnXattr, "setuid.txt"))
+			Expect(knd).To(HaveKeyWithValue(arctps.WarnPermission, "setuid.txt"))
+			Expect(knd).To(HaveKeyWithValue(arctps.WarnUnsupported, "fifo"))
+
+			i, e := os.Stat(dir + "/setuid.txt")
+			Expect(e).ToNot(HaveOccurred())
+			Expect(i.Mode().Perm()).To(Equal(os.FileMode(0755)))
+			Expect(i.Mode() & os.ModeSetuid).To(BeZero())
+			Expect(i.ModTime().Equal(mod)).To(BeTrue())
+
+			_, e = os.Stat(dir + "/fifo")
+			Expect(os.IsNotExist(e)).To(BeTrue())
+		})
+	})
+})
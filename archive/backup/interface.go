@@ -0,0 +1,110 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package backup
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	arctps "github.com/nabbar/golib/archive/archive/types"
+)
+
+var (
+	ErrInvalidSource   = errors.New("invalid backup source path")
+	ErrInvalidWriter   = errors.New("invalid archive writer")
+	ErrInvalidManifest = errors.New("invalid backup manifest")
+)
+
+type Compare uint8
+
+const (
+	// CompareMeta consider a file as changed if its size, mode or modification time differs from the reference manifest.
+	CompareMeta Compare = iota
+	// CompareHash consider a file as changed if its size, mode or content hash differs from the reference manifest.
+	// Each unchanged file is read to compute its hash.
+	CompareHash
+)
+
+func ParseCompare(s string) Compare {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case CompareHash.String():
+		return CompareHash
+	default:
+		return CompareMeta
+	}
+}
+
+func (c Compare) String() string {
+	switch c {
+	case CompareHash:
+		return "hash"
+	default:
+		return "meta"
+	}
+}
+
+// Options define how a backup is processed.
+type Options struct {
+	// Kind is the kind of backup when a reference manifest is given: Incremental or Differential.
+	// It's only a label stored into the manifest: the caller give the last manifest for an incremental
+	// backup or the last full manifest for a differential backup. Without reference manifest, the backup is always Full.
+	Kind Kind
+
+	// Compare is the mode used to detect changed files.
+	Compare Compare
+
+	// Exclude is a list of patterns (see path.Match) of the relative slash paths to skip.
+	// A pattern matching a directory skip the whole directory.
+	Exclude []string
+
+	// ManifestName when not empty, store the new manifest into the archive with this path.
+	ManifestName string
+}
+
+// Run walk the source path, compare each file with the reference manifest and add the new
+// or changed files into the given archive writer, with their path relative to the source.
+// The writer is not closed. A nil reference manifest process a full backup.
+// The returned manifest describe the whole source and can be used as reference for the next backup.
+func Run(ctx context.Context, source string, ref *Manifest, w arctps.Writer, opt Options) (*Manifest, error) {
+	if w == nil {
+		return nil, ErrInvalidWriter
+	} else if len(source) < 1 {
+		return nil, ErrInvalidSource
+	} else if ctx == nil {
+		ctx = context.Background()
+	}
+
+	b := &bck{
+		x: ctx,
+		s: source,
+		r: ref,
+		w: w,
+		o: opt,
+	}
+
+	return b.run()
+}
@@ -0,0 +1,154 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ManifestVersion is the version of the manifest format.
+const ManifestVersion = 1
+
+type Kind uint8
+
+const (
+	// Full is a backup with all the files of the source.
+	Full Kind = iota
+	// Incremental is a backup with the files changed since the previous backup, whatever its kind.
+	Incremental
+	// Differential is a backup with the files changed since the last full backup.
+	Differential
+)
+
+func ParseKind(s string) Kind {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case Incremental.String():
+		return Incremental
+	case Differential.String():
+		return Differential
+	default:
+		return Full
+	}
+}
+
+func (k Kind) String() string {
+	switch k {
+	case Incremental:
+		return "incremental"
+	case Differential:
+		return "differential"
+	default:
+		return "full"
+	}
+}
+
+func (k Kind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+func (k *Kind) UnmarshalText(p []byte) error {
+	*k = ParseKind(string(p))
+	return nil
+}
+
+// Entry is the state of one file of the source.
+type Entry struct {
+	// Path is the slash separated path of the file, relative to the source.
+	Path    string      `json:"path"`
+	Size    int64       `json:"size"`
+	ModTime time.Time   `json:"mod_time"`
+	Mode    fs.FileMode `json:"mode"`
+	// Link is the target of a symbolic link.
+	Link string `json:"link,omitempty"`
+	// Hash is the hexadecimal sha256 of the content of a regular file.
+	Hash string `json:"hash,omitempty"`
+}
+
+// Stats is the summary of a backup.
+type Stats struct {
+	Added     int   `json:"added"`
+	Modified  int   `json:"modified"`
+	Unchanged int   `json:"unchanged"`
+	Deleted   int   `json:"deleted"`
+	Bytes     int64 `json:"bytes"`
+}
+
+// Manifest describe the state of the source at the time of a backup, and the files written into its archive.
+// The manifest of a backup is the reference of the next incremental backup.
+type Manifest struct {
+	Version int       `json:"version"`
+	Kind    Kind      `json:"kind"`
+	Created time.Time `json:"created"`
+	// Parent is the creation time of the reference manifest, zero for a full backup.
+	Parent time.Time `json:"parent,omitempty"`
+	// Files is the state of all the files of the source, by path.
+	Files map[string]Entry `json:"files"`
+	// Written is the list of the files written into the archive of this backup.
+	Written []string `json:"written"`
+	// Deleted is the list of the files of the reference manifest not found anymore into the source.
+	Deleted []string `json:"deleted,omitempty"`
+	Stats   Stats    `json:"stats"`
+}
+
+// ReadManifest decode a json manifest from the given reader.
+func ReadManifest(r io.Reader) (*Manifest, error) {
+	var m = &Manifest{}
+
+	if e := json.NewDecoder(r).Decode(m); e != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidManifest, e)
+	} else if m.Version < 1 || m.Version > ManifestVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidManifest, m.Version)
+	} else if m.Files == nil {
+		m.Files = make(map[string]Entry)
+	}
+
+	return m, nil
+}
+
+// Write encode the manifest as json into the given writer.
+func (m *Manifest) Write(w io.Writer) error {
+	var enc = json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(m)
+}
+
+// Paths return the sorted list of the paths of the files of the manifest.
+func (m *Manifest) Paths() []string {
+	var res = make([]string, 0, len(m.Files))
+
+	for p := range m.Files {
+		res = append(res, p)
+	}
+
+	sort.Strings(res)
+	return res
+}
@@ -0,0 +1,327 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package backup
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+
+	arctps "github.com/nabbar/golib/archive/archive/types"
+)
+
+type bck struct {
+	x context.Context // context
+	s string          // source path
+	r *Manifest       // reference manifest
+	w arctps.Writer   // archive writer
+	o Options         // options
+	m *Manifest       // new manifest
+}
+
+func (o *bck) run() (*Manifest, error) {
+	var (
+		e error
+		i fs.FileInfo
+	)
+
+	if i, e = os.Stat(o.s); e != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSource, e)
+	} else if !i.IsDir() {
+		return nil, fmt.Errorf("%w: '%s' is not a directory", ErrInvalidSource, o.s)
+	}
+
+	o.m = &Manifest{
+		Version: ManifestVersion,
+		Kind:    Full,
+		Created: time.Now(),
+		Files:   make(map[string]Entry),
+		Written: make([]string, 0),
+	}
+
+	if o.r != nil {
+		if o.r.Files == nil {
+			return nil, ErrInvalidManifest
+		}
+
+		o.m.Parent = o.r.Created
+
+		if o.o.Kind == Differential {
+			o.m.Kind = Differential
+		} else {
+			o.m.Kind = Incremental
+		}
+	}
+
+	if e = filepath.WalkDir(o.s, o.walk); e != nil {
+		return nil, e
+	}
+
+	if o.r != nil {
+		for _, p := range o.r.Paths() {
+			if _, ok := o.m.Files[p]; !ok {
+				o.m.Deleted = append(o.m.Deleted, p)
+			}
+		}
+	}
+
+	o.m.Stats.Deleted = len(o.m.Deleted)
+	sort.Strings(o.m.Written)
+
+	if len(o.o.ManifestName) > 0 {
+		if e = o.addManifest(); e != nil {
+			return nil, e
+		}
+	}
+
+	return o.m, nil
+}
+
+func (o *bck) walk(pth string, d fs.DirEntry, err error) error {
+	if err != nil {
+		return err
+	} else if e := o.x.Err(); e != nil {
+		return e
+	}
+
+	rel, err := filepath.Rel(o.s, pth)
+
+	if err != nil {
+		return err
+	} else if rel == "." {
+		return nil
+	}
+
+	rel = filepath.ToSlash(rel)
+
+	if o.isExcluded(rel) {
+		if d.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	} else if d.IsDir() {
+		return nil
+	}
+
+	inf, err := d.Info()
+
+	if err != nil {
+		return err
+	}
+
+	ent := Entry{
+		Path:    rel,
+		Size:    inf.Size(),
+		ModTime: inf.ModTime(),
+		Mode:    inf.Mode(),
+	}
+
+	if inf.Mode()&os.ModeSymlink != 0 {
+		ent.Size = 0
+		if ent.Link, err = os.Readlink(pth); err != nil {
+			return err
+		}
+	} else if !inf.Mode().IsRegular() {
+		// skip devices, sockets, pipes, ...
+		return nil
+	}
+
+	ref, exist := o.refEntry(rel)
+	changed := !exist || ref.Size != ent.Size || ref.Mode != ent.Mode || ref.Link != ent.Link
+
+	if !changed {
+		if o.o.Compare == CompareHash && ent.Mode.IsRegular() {
+			if ent.Hash, err = hashFile(pth); err != nil {
+				return err
+			}
+			changed = ent.Hash != ref.Hash
+		} else {
+			ent.Hash = ref.Hash
+			changed = !ent.ModTime.Equal(ref.ModTime)
+		}
+	}
+
+	if changed {
+		if err = o.add(pth, inf, &ent); err != nil {
+			return err
+		}
+
+		o.m.Written = append(o.m.Written, rel)
+		o.m.Stats.Bytes += ent.Size
+
+		if exist {
+			o.m.Stats.Modified++
+		} else {
+			o.m.Stats.Added++
+		}
+	} else {
+		o.m.Stats.Unchanged++
+	}
+
+	o.m.Files[rel] = ent
+	return nil
+}
+
+func (o *bck) refEntry(rel string) (Entry, bool) {
+	if o.r == nil {
+		return Entry{}, false
+	}
+
+	e, ok := o.r.Files[rel]
+	return e, ok
+}
+
+func (o *bck) isExcluded(rel string) bool {
+	for _, p := range o.o.Exclude {
+		if ok, _ := path.Match(p, rel); ok {
+			return true
+		} else if ok, _ = path.Match(p, path.Base(rel)); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// add write the file into the archive and compute its hash while reading it.
+func (o *bck) add(pth string, inf fs.FileInfo, ent *Entry) error {
+	if len(ent.Link) > 0 {
+		return o.w.Add(inf, nil, ent.Path, ent.Link)
+	}
+
+	h, err := os.Open(pth)
+
+	if err != nil {
+		return err
+	}
+
+	r := &hashReader{
+		f: h,
+		h: sha256.New(),
+	}
+
+	if err = o.w.Add(inf, r, ent.Path, ""); err != nil {
+		return err
+	}
+
+	ent.Hash = hex.EncodeToString(r.h.Sum(nil))
+	return nil
+}
+
+func (o *bck) addManifest() error {
+	var buf = bytes.NewBuffer(make([]byte, 0))
+
+	if e := o.m.Write(buf); e != nil {
+		return e
+	}
+
+	inf := &manifestInfo{
+		n: path.Base(o.o.ManifestName),
+		s: int64(buf.Len()),
+		t: o.m.Created,
+	}
+
+	return o.w.Add(inf, io.NopCloser(buf), o.o.ManifestName, "")
+}
+
+func hashFile(pth string) (string, error) {
+	h, err := os.Open(pth)
+
+	if err != nil {
+		return "", err
+	}
+
+	defer func() {
+		_ = h.Close()
+	}()
+
+	s := sha256.New()
+
+	if _, err = io.Copy(s, h); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(s.Sum(nil)), nil
+}
+
+type hashReader struct {
+	f *os.File
+	h hash.Hash
+}
+
+func (o *hashReader) Read(p []byte) (n int, err error) {
+	n, err = o.f.Read(p)
+
+	if n > 0 {
+		_, _ = o.h.Write(p[:n])
+	}
+
+	return n, err
+}
+
+func (o *hashReader) Close() error {
+	return o.f.Close()
+}
+
+type manifestInfo struct {
+	n string    // name
+	s int64     // size
+	t time.Time // modification time
+}
+
+func (o *manifestInfo) Name() string {
+	return o.n
+}
+
+func (o *manifestInfo) Size() int64 {
+	return o.s
+}
+
+func (o *manifestInfo) Mode() fs.FileMode {
+	return 0644
+}
+
+func (o *manifestInfo) ModTime() time.Time {
+	return o.t
+}
+
+func (o *manifestInfo) IsDir() bool {
+	return false
+}
+
+func (o *manifestInfo) Sys() any {
+	return nil
+}
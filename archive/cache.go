@@ -0,0 +1,180 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package archive
+
+import (
+	"container/list"
+	"io/fs"
+	"path/filepath"
+	"sync"
+	"time"
+
+	arcarc "github.com/nabbar/golib/archive/archive"
+	arccmp "github.com/nabbar/golib/archive/compress"
+)
+
+// DefaultDetectCacheSize is the number of files kept by a DetectCache if not defined.
+const DefaultDetectCacheSize = 1024
+
+// Detected is the chain of algorithms detected for an archive file: its compression, then the archive
+// format of the decompressed content. Both could be None.
+type Detected struct {
+	Compression arccmp.Algorithm
+	Archive     arcarc.Algorithm
+}
+
+// DetectCache keep the Detected algorithms of the files opened by the facade, keyed by the path of the file,
+// its size and its modification time, so a file opened again without being modified skip the detection.
+// A DetectCache is safe for concurrent use and can be shared between several Options.
+type DetectCache interface {
+	// Load return the Detected algorithms of the given file if it is cached with the same size and modification time.
+	Load(path string, info fs.FileInfo) (Detected, bool)
+
+	// Store add or replace the Detected algorithms of the given file. The least recently used file is
+	// removed when the cache is full.
+	Store(path string, info fs.FileInfo, det Detected)
+
+	// Delete remove the given file from the cache.
+	Delete(path string)
+
+	// Clean remove all files from the cache.
+	Clean()
+
+	// Len return the number of files into the cache.
+	Len() int
+}
+
+// NewDetectCache return a new DetectCache keeping at most the given number of files.
+// If zero or negative, DefaultDetectCacheSize is used.
+func NewDetectCache(size int) DetectCache {
+	if size < 1 {
+		size = DefaultDetectCacheSize
+	}
+
+	return &dtc{
+		m: sync.Mutex{},
+		s: size,
+		l: list.New(),
+		k: make(map[string]*list.Element),
+	}
+}
+
+type dtcItem struct {
+	p string    // path
+	s int64     // size
+	t time.Time // modification time
+	d Detected
+}
+
+type dtc struct {
+	m sync.Mutex
+	s int                      // max size
+	l *list.List               // items, most recently used first
+	k map[string]*list.Element // items by path
+}
+
+func (o *dtc) key(path string) string {
+	if p, e := filepath.Abs(path); e == nil {
+		return p
+	}
+
+	return filepath.Clean(path)
+}
+
+func (o *dtc) Load(path string, info fs.FileInfo) (Detected, bool) {
+	if info == nil {
+		return Detected{}, false
+	}
+
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if e, k := o.k[o.key(path)]; !k {
+		return Detected{}, false
+	} else if i := e.Value.(*dtcItem); i.s != info.Size() || !i.t.Equal(info.ModTime()) {
+		return Detected{}, false
+	} else {
+		o.l.MoveToFront(e)
+		return i.d, true
+	}
+}
+
+func (o *dtc) Store(path string, info fs.FileInfo, det Detected) {
+	if info == nil {
+		return
+	}
+
+	var i = &dtcItem{
+		p: o.key(path),
+		s: info.Size(),
+		t: info.ModTime(),
+		d: det,
+	}
+
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if e, k := o.k[i.p]; k {
+		e.Value = i
+		o.l.MoveToFront(e)
+		return
+	}
+
+	o.k[i.p] = o.l.PushFront(i)
+
+	for o.l.Len() > o.s {
+		if e := o.l.Back(); e != nil {
+			delete(o.k, e.Value.(*dtcItem).p)
+			o.l.Remove(e)
+		}
+	}
+}
+
+func (o *dtc) Delete(path string) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if e, k := o.k[o.key(path)]; k {
+		delete(o.k, e.Value.(*dtcItem).p)
+		o.l.Remove(e)
+	}
+}
+
+func (o *dtc) Clean() {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.l.Init()
+	o.k = make(map[string]*list.Element)
+}
+
+func (o *dtc) Len() int {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	return o.l.Len()
+}
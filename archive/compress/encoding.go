@@ -51,6 +51,12 @@ func (a *Algorithm) UnmarshalText(b []byte) error {
 		*a = LZ4
 	case strings.EqualFold(s, XZ.String()):
 		*a = XZ
+	case strings.EqualFold(s, Zstd.String()):
+		*a = Zstd
+	case strings.EqualFold(s, Snappy.String()):
+		*a = Snappy
+	case strings.EqualFold(s, Brotli.String()):
+		*a = Brotli
 	default:
 		*a = None
 	}
@@ -63,10 +63,13 @@ func DetectOnly(r io.Reader) (Algorithm, io.ReadCloser, error) {
 		buf []byte
 	)
 
-	if buf, err = bfr.Peek(6); err != nil {
+	// the snappy stream identifier is 10 bytes long, but shorter contents must still be detected
+	if buf, err = bfr.Peek(10); err != nil && len(buf) < 6 {
 		return None, nil, err
 	}
 
+	err = nil
+
 	// Vérifier le type de compression
 	switch {
 	case Gzip.DetectHeader(buf): // gzip
@@ -77,6 +80,10 @@ func DetectOnly(r io.Reader) (Algorithm, io.ReadCloser, error) {
 		alg = LZ4
 	case XZ.DetectHeader(buf): // xz
 		alg = XZ
+	case Zstd.DetectHeader(buf): // zstd
+		alg = Zstd
+	case Snappy.DetectHeader(buf): // snappy framed
+		alg = Snappy
 	default:
 		alg = None
 	}
@@ -30,7 +30,10 @@ import (
 	"compress/gzip"
 	"io"
 
+	"github.com/andybalholm/brotli"
 	bz2 "github.com/dsnet/compress/bzip2"
+	"github.com/klauspost/compress/s2"
+	"github.com/klauspost/compress/zstd"
 	"github.com/pierrec/lz4/v4"
 	"github.com/ulikunitz/xz"
 )
@@ -46,6 +49,16 @@ func (a Algorithm) Reader(r io.Reader) (io.ReadCloser, error) {
 	case XZ:
 		c, e := xz.NewReader(r)
 		return io.NopCloser(c), e
+	case Zstd:
+		c, e := zstd.NewReader(r)
+		if e != nil {
+			return nil, e
+		}
+		return c.IOReadCloser(), nil
+	case Snappy:
+		return io.NopCloser(s2.NewReader(r)), nil
+	case Brotli:
+		return io.NopCloser(brotli.NewReader(r)), nil
 	default:
 		return io.NopCloser(r), nil
 	}
@@ -61,6 +74,12 @@ func (a Algorithm) Writer(w io.WriteCloser) (io.WriteCloser, error) {
 		return lz4.NewWriter(w), nil
 	case XZ:
 		return xz.NewWriter(w)
+	case Zstd:
+		return zstd.NewWriter(w)
+	case Snappy:
+		return s2.NewWriter(w, s2.WriterSnappyCompat()), nil
+	case Brotli:
+		return brotli.NewWriter(w), nil
 	default:
 		return w, nil
 	}
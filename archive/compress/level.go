@@ -0,0 +1,122 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package compress
+
+import (
+	"compress/gzip"
+	"io"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	bz2 "github.com/dsnet/compress/bzip2"
+	"github.com/klauspost/compress/s2"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+	"github.com/ulikunitz/xz"
+)
+
+// Level is a compression level, translated to the levels of each algorithm.
+type Level uint8
+
+const (
+	// LevelDefault is the default level of the algorithm, as used by Writer.
+	LevelDefault Level = iota
+	// LevelFastest favor the speed over the ratio.
+	LevelFastest
+	// LevelBest favor the ratio over the speed.
+	LevelBest
+)
+
+func ParseLevel(s string) Level {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case LevelFastest.String():
+		return LevelFastest
+	case LevelBest.String():
+		return LevelBest
+	default:
+		return LevelDefault
+	}
+}
+
+func (l Level) String() string {
+	switch l {
+	case LevelFastest:
+		return "fastest"
+	case LevelBest:
+		return "best"
+	default:
+		return "default"
+	}
+}
+
+// WriterLevel return a compression writer like Writer, using the given level.
+// The XZ algorithm has no level and always use its default settings.
+func (a Algorithm) WriterLevel(w io.WriteCloser, lvl Level) (io.WriteCloser, error) {
+	if lvl == LevelDefault {
+		return a.Writer(w)
+	}
+
+	var best = lvl == LevelBest
+
+	switch a {
+	case Bzip2:
+		if best {
+			return bz2.NewWriter(w, &bz2.WriterConfig{Level: bz2.BestCompression})
+		}
+		return bz2.NewWriter(w, &bz2.WriterConfig{Level: bz2.BestSpeed})
+	case Gzip:
+		if best {
+			return gzip.NewWriterLevel(w, gzip.BestCompression)
+		}
+		return gzip.NewWriterLevel(w, gzip.BestSpeed)
+	case LZ4:
+		c := lz4.NewWriter(w)
+		if best {
+			return c, c.Apply(lz4.CompressionLevelOption(lz4.Level9))
+		}
+		return c, c.Apply(lz4.CompressionLevelOption(lz4.Fast))
+	case XZ:
+		return xz.NewWriter(w)
+	case Zstd:
+		if best {
+			return zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.SpeedBestCompression))
+		}
+		return zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.SpeedFastest))
+	case Snappy:
+		if best {
+			return s2.NewWriter(w, s2.WriterSnappyCompat(), s2.WriterBestCompression()), nil
+		}
+		return s2.NewWriter(w, s2.WriterSnappyCompat()), nil
+	case Brotli:
+		if best {
+			return brotli.NewWriterLevel(w, brotli.BestCompression), nil
+		}
+		return brotli.NewWriterLevel(w, brotli.BestSpeed), nil
+	default:
+		return w, nil
+	}
+}
@@ -0,0 +1,228 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package compress
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultRatioGrace is the default amount of decompressed bytes read before checking the ratio,
+// to not reject small but highly compressible contents.
+const DefaultRatioGrace = 1024 * 1024
+
+// ErrDecompressionBomb is the error wrapped by any BombError.
+var ErrDecompressionBomb = errors.New("decompression bomb detected")
+
+// Limits define the max decompressed output accepted from an untrusted compressed input.
+type Limits struct {
+	// MaxOutputBytes is the max number of decompressed bytes. Zero means no limit.
+	MaxOutputBytes int64 `mapstructure:"max_output_bytes" json:"max_output_bytes" yaml:"max_output_bytes" toml:"max_output_bytes" validate:"gte=0"`
+
+	// MaxRatio is the max ratio between the decompressed bytes and the compressed bytes. Zero means no limit.
+	MaxRatio float64 `mapstructure:"max_ratio" json:"max_ratio" yaml:"max_ratio" toml:"max_ratio" validate:"gte=0"`
+
+	// RatioGrace is the amount of decompressed bytes read before checking the ratio. Default is 1 MiB.
+	RatioGrace int64 `mapstructure:"ratio_grace" json:"ratio_grace" yaml:"ratio_grace" toml:"ratio_grace" validate:"gte=0"`
+}
+
+// IsEnabled return true if at least one limit is defined.
+func (l Limits) IsEnabled() bool {
+	return l.MaxOutputBytes > 0 || l.MaxRatio > 0
+}
+
+func (l Limits) getRatioGrace() int64 {
+	if l.RatioGrace > 0 {
+		return l.RatioGrace
+	}
+
+	return DefaultRatioGrace
+}
+
+// BombError is returned when a decompressed output exceeds the Limits.
+type BombError struct {
+	// Reason is the limit reached: "max output bytes" or "max ratio".
+	Reason string
+	// Input is the number of compressed bytes read when the limit has been reached.
+	Input int64
+	// Output is the number of decompressed bytes read when the limit has been reached.
+	Output int64
+	// Limits are the limits applied.
+	Limits Limits
+}
+
+func (e *BombError) Error() string {
+	return fmt.Sprintf("%s: %s reached with %d bytes decompressed from %d bytes", ErrDecompressionBomb.Error(), e.Reason, e.Output, e.Input)
+}
+
+func (e *BombError) Unwrap() error {
+	return ErrDecompressionBomb
+}
+
+// Meter count the compressed input and the decompressed output of a stream and check them against Limits.
+// The same Meter can be shared by several output readers (each file of an archive for example).
+type Meter struct {
+	l Limits
+	i *atomic.Int64 // compressed bytes
+	o *atomic.Int64 // decompressed bytes
+	m sync.Mutex
+	e error // first limit error
+}
+
+func NewMeter(lim Limits) *Meter {
+	return &Meter{
+		l: lim,
+		i: new(atomic.Int64),
+		o: new(atomic.Int64),
+	}
+}
+
+// Input return the given compressed reader counting the bytes read.
+func (m *Meter) Input(r io.Reader) io.Reader {
+	return &cnr{r: r, n: m.i}
+}
+
+// Output return the given decompressed reader counting the bytes read and failing with a BombError
+// as soon as the limits are exceeded.
+func (m *Meter) Output(r io.Reader) io.Reader {
+	return &grd{r: r, m: m}
+}
+
+// AddInput count the given number of compressed bytes read outside of an Input reader,
+// like the size of a file read with random access.
+func (m *Meter) AddInput(n int64) {
+	m.i.Add(n)
+}
+
+// Check return the BombError if the limits have been exceeded.
+func (m *Meter) Check() error {
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	if m.e != nil {
+		return m.e
+	}
+
+	var (
+		i = m.i.Load()
+		o = m.o.Load()
+	)
+
+	if m.l.MaxOutputBytes > 0 && o > m.l.MaxOutputBytes {
+		m.e = &BombError{Reason: "max output bytes", Input: i, Output: o, Limits: m.l}
+	} else if m.l.MaxRatio > 0 && o >= m.l.getRatioGrace() && float64(o) > float64(max(i, 1))*m.l.MaxRatio {
+		m.e = &BombError{Reason: "max ratio", Input: i, Output: o, Limits: m.l}
+	}
+
+	return m.e
+}
+
+// BytesIn return the number of compressed bytes read.
+func (m *Meter) BytesIn() int64 {
+	return m.i.Load()
+}
+
+// BytesOut return the number of decompressed bytes read.
+func (m *Meter) BytesOut() int64 {
+	return m.o.Load()
+}
+
+type cnr struct {
+	r io.Reader
+	n *atomic.Int64
+}
+
+func (o *cnr) Read(p []byte) (int, error) {
+	n, e := o.r.Read(p)
+	o.n.Add(int64(n))
+	return n, e
+}
+
+type grd struct {
+	r io.Reader
+	m *Meter
+}
+
+func (o *grd) Read(p []byte) (int, error) {
+	if e := o.m.Check(); e != nil {
+		return 0, e
+	}
+
+	n, e := o.r.Read(p)
+	o.m.o.Add(int64(n))
+
+	if c := o.m.Check(); c != nil {
+		return 0, c
+	}
+
+	return n, e
+}
+
+type grc struct {
+	io.Reader
+	c io.Closer
+}
+
+func (o *grc) Close() error {
+	return o.c.Close()
+}
+
+// ReaderLimit return a decompression reader like Reader, failing with a BombError
+// as soon as the decompressed output exceeds the given limits.
+func (a Algorithm) ReaderLimit(r io.Reader, lim Limits) (io.ReadCloser, error) {
+	if !lim.IsEnabled() {
+		return a.Reader(r)
+	}
+
+	var m = NewMeter(lim)
+
+	if c, e := a.Reader(m.Input(r)); e != nil {
+		return nil, e
+	} else {
+		return &grc{Reader: m.Output(c), c: c}, nil
+	}
+}
+
+// DetectLimit is like Detect, but the returned reader fails with a BombError
+// as soon as the decompressed output exceeds the given limits.
+func DetectLimit(r io.Reader, lim Limits) (Algorithm, io.ReadCloser, error) {
+	var (
+		err error
+		alg Algorithm
+		rdr io.ReadCloser
+	)
+
+	if alg, rdr, err = DetectOnly(r); err != nil {
+		return None, nil, err
+	} else if rdr, err = alg.ReaderLimit(rdr, lim); err != nil {
+		return None, nil, err
+	} else {
+		return alg, rdr, nil
+	}
+}
@@ -0,0 +1,176 @@
+/*
+ *  MIT License
+ *
+ *  Copyright (c) 2024 Nicolas JUHEL
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all
+ *  copies or substantial portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *  AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *  LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *  OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ *  SOFTWARE.
+ *
+ */
+
+package compress
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Stats expose the live counters of a compression stream.
+type Stats interface {
+	// BytesIn return the number of uncompressed bytes written into the stream.
+	BytesIn() int64
+
+	// BytesOut return the number of compressed bytes written to the destination.
+	BytesOut() int64
+
+	// Ratio return the ratio between output and input bytes (lower is better).
+	// It returns 0 if nothing has been written yet.
+	Ratio() float64
+
+	// Throughput return the number of input bytes processed per second since the first write.
+	Throughput() float64
+
+	// Elapsed return the duration since the first write, until the close of the stream if closed.
+	Elapsed() time.Duration
+}
+
+// WriteCloserStats is a compression writer exposing the Stats of the stream.
+type WriteCloserStats interface {
+	io.WriteCloser
+	Stats
+}
+
+// WriterStats return a compression writer like Writer, wrapped to track input and output byte counts.
+// Closing the returned writer close the compressor and then the given destination.
+func (a Algorithm) WriterStats(w io.WriteCloser) (WriteCloserStats, error) {
+	var (
+		e error
+		o = &wst{
+			m: sync.Mutex{},
+			i: new(atomic.Int64),
+			o: new(atomic.Int64),
+			d: w,
+		}
+	)
+
+	o.w = &cnt{w: w, n: o.o}
+
+	if o.c, e = a.Writer(o.w); e != nil {
+		return nil, e
+	}
+
+	return o, nil
+}
+
+type cnt struct {
+	w io.WriteCloser
+	n *atomic.Int64
+}
+
+func (o *cnt) Write(p []byte) (n int, err error) {
+	n, err = o.w.Write(p)
+	o.n.Add(int64(n))
+	return n, err
+}
+
+func (o *cnt) Close() error {
+	return o.w.Close()
+}
+
+type wst struct {
+	m sync.Mutex
+	i *atomic.Int64  // bytes in
+	o *atomic.Int64  // bytes out
+	d io.WriteCloser // destination
+	w *cnt           // counter on destination
+	c io.WriteCloser // compressor
+	s time.Time      // first write
+	t time.Time      // close time
+}
+
+func (o *wst) Write(p []byte) (n int, err error) {
+	o.m.Lock()
+	if o.s.IsZero() {
+		o.s = time.Now()
+	}
+	o.m.Unlock()
+
+	n, err = o.c.Write(p)
+	o.i.Add(int64(n))
+
+	return n, err
+}
+
+func (o *wst) Close() error {
+	o.m.Lock()
+	if o.t.IsZero() {
+		o.t = time.Now()
+	}
+	o.m.Unlock()
+
+	if o.c == io.WriteCloser(o.w) {
+		return o.w.Close()
+	}
+
+	e := o.c.Close()
+
+	if err := o.d.Close(); e == nil {
+		e = err
+	}
+
+	return e
+}
+
+func (o *wst) BytesIn() int64 {
+	return o.i.Load()
+}
+
+func (o *wst) BytesOut() int64 {
+	return o.o.Load()
+}
+
+func (o *wst) Ratio() float64 {
+	if i := o.i.Load(); i < 1 {
+		return 0
+	} else {
+		return float64(o.o.Load()) / float64(i)
+	}
+}
+
+func (o *wst) Elapsed() time.Duration {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if o.s.IsZero() {
+		return 0
+	} else if o.t.IsZero() {
+		return time.Since(o.s)
+	}
+
+	return o.t.Sub(o.s)
+}
+
+func (o *wst) Throughput() float64 {
+	if d := o.Elapsed(); d <= 0 {
+		return 0
+	} else {
+		return float64(o.i.Load()) / d.Seconds()
+	}
+}
@@ -0,0 +1,264 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package compress
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"sync"
+)
+
+const (
+	// SuggestSampleSize is the amount of data sampled by Suggest and WriterAuto.
+	SuggestSampleSize = 64 * 1024
+
+	// EntropyIncompressible is the entropy, in bits per byte, from which a content is considered incompressible.
+	EntropyIncompressible = 7.5
+
+	// EntropyLow is the entropy, in bits per byte, under which a content is compressed with the default level.
+	// Between EntropyLow and EntropyIncompressible, the gain is small and a fast algorithm is suggested.
+	EntropyLow = 6.0
+)
+
+// Suggestion is the compression recommended for a content from a sample.
+type Suggestion struct {
+	// Algorithm is the algorithm to use. None means to store the content without compression.
+	Algorithm Algorithm
+	// Level is the level to use with the algorithm.
+	Level Level
+	// Entropy is the estimated entropy of the sample, in bits per byte, between 0 and 8.
+	Entropy float64
+	// Compressed is true if the sample starts with the magic number of a compressed format
+	// (compression, archive, image, audio or video).
+	Compressed bool
+}
+
+// magic numbers of common formats with compressed contents, as offset and value.
+// The algorithms of this package are detected with DetectHeader.
+var cmpMagic = []struct {
+	o int
+	m []byte
+}{
+	{0, []byte("PK\x03\x04")},         // zip, jar, docx, apk
+	{0, []byte("7z\xBC\xAF\x27\x1C")}, // 7z
+	{0, []byte("Rar!\x1A\x07")},       // rar
+	{0, []byte("\x89PNG\r\n\x1A\n")},  // png
+	{0, []byte("\xFF\xD8\xFF")},       // jpeg
+	{0, []byte("GIF8")},               // gif
+	{8, []byte("WEBP")},               // webp
+	{4, []byte("ftyp")},               // mp4, mov, heic
+	{0, []byte("\x1A\x45\xDF\xA3")},   // mkv, webm
+	{0, []byte("OggS")},               // ogg
+	{0, []byte("fLaC")},               // flac
+	{0, []byte("ID3")},                // mp3
+	{0, []byte("wOFF")},               // woff
+	{0, []byte("wOF2")},               // woff2
+	{0, []byte("\x5D\x00\x00")},       // lzma
+}
+
+// Suggest read a sample of at most SuggestSampleSize bytes from the given reader and recommend
+// the algorithm and level to compress the content. The content already compressed or with a high
+// entropy is not worth compressing and the suggested algorithm is None.
+// The sample is consumed from the reader.
+func Suggest(sample io.Reader) (Suggestion, error) {
+	var (
+		buf = make([]byte, SuggestSampleSize)
+		n   int
+		err error
+	)
+
+	if n, err = io.ReadFull(sample, buf); err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return Suggestion{}, err
+	}
+
+	return suggest(buf[:n]), nil
+}
+
+func suggest(p []byte) Suggestion {
+	var res = Suggestion{
+		Algorithm: None,
+		Level:     LevelDefault,
+	}
+
+	if len(p) < 1 {
+		return res
+	}
+
+	res.Entropy = entropy(p)
+
+	for _, m := range cmpMagic {
+		if len(p) >= m.o+len(m.m) && bytes.Equal(p[m.o:m.o+len(m.m)], m.m) {
+			res.Compressed = true
+			return res
+		}
+	}
+
+	for _, a := range List() {
+		if a.DetectHeader(p) {
+			res.Compressed = true
+			return res
+		}
+	}
+
+	switch {
+	case res.Entropy >= EntropyIncompressible:
+		res.Algorithm = None
+	case res.Entropy >= EntropyLow:
+		res.Algorithm = LZ4
+		res.Level = LevelFastest
+	default:
+		res.Algorithm = Zstd
+		res.Level = LevelDefault
+	}
+
+	return res
+}
+
+// entropy return the shannon entropy of the bytes distribution, in bits per byte.
+func entropy(p []byte) float64 {
+	var (
+		cnt [256]int
+		res float64
+		siz = float64(len(p))
+	)
+
+	for _, b := range p {
+		cnt[b]++
+	}
+
+	for _, c := range cnt {
+		if c > 0 {
+			f := float64(c) / siz
+			res -= f * math.Log2(f)
+		}
+	}
+
+	return res
+}
+
+// WriteCloserAuto is a writer choosing its compression from the first written bytes.
+type WriteCloserAuto interface {
+	io.WriteCloser
+
+	// Suggestion return the compression applied to the stream and true,
+	// or false if the sample is not complete yet.
+	Suggestion() (Suggestion, bool)
+}
+
+// WriterAuto return a writer buffering the first SuggestSampleSize bytes written to choose the compression
+// with Suggest. An incompressible stream is written as is into the destination (passthrough mode).
+// The stream can be read back with Detect, except for the Brotli algorithm that is never suggested.
+// Closing the returned writer close the compressor and then the given destination.
+func WriterAuto(w io.WriteCloser) WriteCloserAuto {
+	return &aut{
+		m: sync.Mutex{},
+		d: w,
+		b: make([]byte, 0, SuggestSampleSize),
+	}
+}
+
+type aut struct {
+	m sync.Mutex
+	d io.WriteCloser // destination
+	b []byte         // sample
+	c io.WriteCloser // compressor, nil until the sample is complete
+	s Suggestion
+}
+
+func (o *aut) Write(p []byte) (n int, err error) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if o.c != nil {
+		return o.c.Write(p)
+	}
+
+	o.b = append(o.b, p...)
+
+	if len(o.b) >= SuggestSampleSize {
+		if err = o.decide(); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(p), nil
+}
+
+// decide apply the suggested compression and write the sample into the compressor.
+func (o *aut) decide() error {
+	var e error
+
+	o.s = suggest(o.b)
+
+	if o.s.Algorithm.IsNone() {
+		o.c = nopWriteCloser{o.d}
+	} else if o.c, e = o.s.Algorithm.WriterLevel(o.d, o.s.Level); e != nil {
+		o.c = nil
+		return e
+	}
+
+	_, e = o.c.Write(o.b)
+	o.b = nil
+
+	return e
+}
+
+func (o *aut) Close() error {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if o.c == nil {
+		if e := o.decide(); e != nil {
+			return e
+		}
+	}
+
+	e := o.c.Close()
+
+	if err := o.d.Close(); e == nil {
+		e = err
+	}
+
+	return e
+}
+
+func (o *aut) Suggestion() (Suggestion, bool) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	return o.s, o.c != nil
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
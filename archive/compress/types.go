@@ -35,6 +35,9 @@ const (
 	Gzip
 	LZ4
 	XZ
+	Zstd
+	Snappy
+	Brotli
 )
 
 func List() []Algorithm {
@@ -44,6 +47,9 @@ func List() []Algorithm {
 		Gzip,
 		LZ4,
 		XZ,
+		Zstd,
+		Snappy,
+		Brotli,
 	}
 }
 
@@ -72,6 +78,12 @@ func (a Algorithm) String() string {
 		return "lz4"
 	case XZ:
 		return "xz"
+	case Zstd:
+		return "zstd"
+	case Snappy:
+		return "snappy"
+	case Brotli:
+		return "brotli"
 	default:
 		return "none"
 	}
@@ -87,11 +99,20 @@ func (a Algorithm) Extension() string {
 		return ".lz4"
 	case XZ:
 		return ".xz"
+	case Zstd:
+		return ".zst"
+	case Snappy:
+		return ".sz"
+	case Brotli:
+		return ".br"
 	default:
 		return ""
 	}
 }
 
+// DetectHeader return true if the given header is the magic number of the algorithm.
+// The Snappy framed format needs 10 bytes to be detected. The Brotli format has no magic number
+// and is never detected: it must be selected from the file extension or the content encoding.
 func (a Algorithm) DetectHeader(h []byte) bool {
 	if len(h) < 6 {
 		return false
@@ -111,6 +132,12 @@ func (a Algorithm) DetectHeader(h []byte) bool {
 		exp := []byte{0xFD, 0x37, 0x7A, 0x58, 0x5A, 0x00}
 		alt := []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}
 		return bytes.Equal(h[0:6], exp) || bytes.Equal(h[0:6], alt)
+	case Zstd:
+		exp := []byte{0x28, 0xB5, 0x2F, 0xFD}
+		return bytes.Equal(h[0:4], exp)
+	case Snappy:
+		exp := []byte{0xFF, 0x06, 0x00, 0x00, 's', 'N', 'a', 'P', 'p', 'Y'}
+		return len(h) >= 10 && bytes.Equal(h[0:10], exp)
 	default:
 		return false
 	}
@@ -0,0 +1,77 @@
+/*
+ *  MIT License
+ *
+ *  Copyright (c) 2024 Nicolas JUHEL
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all
+ *  copies or substantial portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *  AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *  LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *  OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ *  SOFTWARE.
+ *
+ */
+
+package archive_test
+
+import (
+	"bytes"
+	"io"
+
+	arccmp "github.com/nabbar/golib/archive/compress"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+var _ = Describe("Compress Stats Test", func() {
+	for _, algo := range arccmp.List() {
+		Context("For the algo '"+algo.String()+"'", func() {
+			It("should count input and output bytes", func() {
+				var (
+					siz = len(loremIpsum)
+					buf = bytes.NewBuffer(make([]byte, 0))
+				)
+
+				w, e := algo.WriterStats(nopWriteCloser{Writer: buf})
+				Expect(e).NotTo(HaveOccurred())
+				Expect(w).NotTo(BeNil())
+				Expect(w.Ratio()).To(BeNumerically("==", 0))
+
+				n, e := io.Copy(w, bytes.NewReader([]byte(loremIpsum)))
+				Expect(e).NotTo(HaveOccurred())
+				Expect(n).To(BeNumerically("==", siz))
+				Expect(w.Close()).NotTo(HaveOccurred())
+
+				Expect(w.BytesIn()).To(BeNumerically("==", siz))
+				Expect(w.BytesOut()).To(BeNumerically("==", buf.Len()))
+				Expect(w.Ratio()).To(BeNumerically(">", 0))
+				Expect(w.Elapsed()).To(BeNumerically(">", 0))
+
+				if algo.IsNone() {
+					Expect(w.Ratio()).To(BeNumerically("==", 1))
+				} else {
+					Expect(w.Ratio()).To(BeNumerically("<", 1))
+				}
+			})
+		})
+	}
+})
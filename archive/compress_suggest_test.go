@@ -0,0 +1,153 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package archive_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"strings"
+
+	arccmp "github.com/nabbar/golib/archive/compress"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Compress Suggest Test", func() {
+	var rnd = func(n int) []byte {
+		p := make([]byte, n)
+		_, e := rand.Read(p)
+		Expect(e).NotTo(HaveOccurred())
+		return p
+	}
+
+	It("should suggest a compression for a text", func() {
+		s, e := arccmp.Suggest(strings.NewReader(loremIpsum))
+		Expect(e).NotTo(HaveOccurred())
+		Expect(s.Compressed).To(BeFalse())
+		Expect(s.Entropy).To(BeNumerically("<", arccmp.EntropyLow))
+		Expect(s.Algorithm).To(Equal(arccmp.Zstd))
+	})
+
+	It("should not suggest a compression for a random content", func() {
+		s, e := arccmp.Suggest(bytes.NewReader(rnd(arccmp.SuggestSampleSize)))
+		Expect(e).NotTo(HaveOccurred())
+		Expect(s.Compressed).To(BeFalse())
+		Expect(s.Entropy).To(BeNumerically(">=", arccmp.EntropyIncompressible))
+		Expect(s.Algorithm).To(Equal(arccmp.None))
+	})
+
+	It("should not suggest a compression for a compressed content", func() {
+		var buf = bytes.NewBuffer(make([]byte, 0))
+
+		w, e := arccmp.Gzip.Writer(nopWriteCloser{Writer: buf})
+		Expect(e).NotTo(HaveOccurred())
+		_, e = w.Write([]byte(loremIpsum))
+		Expect(e).NotTo(HaveOccurred())
+		Expect(w.Close()).NotTo(HaveOccurred())
+
+		s, e := arccmp.Suggest(buf)
+		Expect(e).NotTo(HaveOccurred())
+		Expect(s.Compressed).To(BeTrue())
+		Expect(s.Algorithm).To(Equal(arccmp.None))
+
+		s, e = arccmp.Suggest(bytes.NewReader(append([]byte("\x89PNG\r\n\x1A\n"), loremIpsum...)))
+		Expect(e).NotTo(HaveOccurred())
+		Expect(s.Compressed).To(BeTrue())
+		Expect(s.Algorithm).To(Equal(arccmp.None))
+	})
+
+	It("should compress a text with the auto writer", func() {
+		var (
+			src = []byte(strings.Repeat(loremIpsum, 1+arccmp.SuggestSampleSize/len(loremIpsum)))
+			buf = bytes.NewBuffer(make([]byte, 0))
+		)
+
+		w := arccmp.WriterAuto(nopWriteCloser{Writer: buf})
+
+		n, e := io.Copy(w, bytes.NewReader(src))
+		Expect(e).NotTo(HaveOccurred())
+		Expect(n).To(BeNumerically("==", len(src)))
+
+		s, k := w.Suggestion()
+		Expect(k).To(BeTrue())
+		Expect(s.Algorithm).To(Equal(arccmp.Zstd))
+		Expect(w.Close()).NotTo(HaveOccurred())
+		Expect(buf.Len()).To(BeNumerically("<", len(src)))
+
+		a, r, e := arccmp.Detect(buf)
+		Expect(e).NotTo(HaveOccurred())
+		Expect(a).To(Equal(arccmp.Zstd))
+
+		p, e := io.ReadAll(r)
+		Expect(e).NotTo(HaveOccurred())
+		Expect(p).To(Equal(src))
+	})
+
+	It("should pass through a random content with the auto writer", func() {
+		var (
+			src = rnd(3 * arccmp.SuggestSampleSize / 2)
+			buf = bytes.NewBuffer(make([]byte, 0))
+		)
+
+		w := arccmp.WriterAuto(nopWriteCloser{Writer: buf})
+
+		_, k := w.Suggestion()
+		Expect(k).To(BeFalse())
+
+		n, e := io.Copy(w, bytes.NewReader(src))
+		Expect(e).NotTo(HaveOccurred())
+		Expect(n).To(BeNumerically("==", len(src)))
+		Expect(w.Close()).NotTo(HaveOccurred())
+
+		s, k := w.Suggestion()
+		Expect(k).To(BeTrue())
+		Expect(s.Algorithm).To(Equal(arccmp.None))
+		Expect(buf.Bytes()).To(Equal(src))
+	})
+
+	for _, algo := range arccmp.List() {
+		for _, lvl := range []arccmp.Level{arccmp.LevelFastest, arccmp.LevelBest} {
+			It("should compress with the algo '"+algo.String()+"' and the level '"+lvl.String()+"'", func() {
+				var buf = bytes.NewBuffer(make([]byte, 0))
+
+				w, e := algo.WriterLevel(nopWriteCloser{Writer: buf}, lvl)
+				Expect(e).NotTo(HaveOccurred())
+				_, e = w.Write([]byte(loremIpsum))
+				Expect(e).NotTo(HaveOccurred())
+				Expect(w.Close()).NotTo(HaveOccurred())
+
+				r, e := algo.Reader(buf)
+				Expect(e).NotTo(HaveOccurred())
+
+				p, e := io.ReadAll(r)
+				Expect(e).NotTo(HaveOccurred())
+				Expect(string(p)).To(Equal(loremIpsum))
+			})
+		}
+	}
+})
@@ -0,0 +1,177 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package dedup
+
+import (
+	"errors"
+	"io"
+	"math/bits"
+)
+
+const (
+	// DefaultMinSize is the default minimal size of a chunk.
+	DefaultMinSize = 64 * 1024
+	// DefaultAvgSize is the default average size of a chunk.
+	DefaultAvgSize = 256 * 1024
+	// DefaultMaxSize is the default maximal size of a chunk.
+	DefaultMaxSize = 1024 * 1024
+)
+
+// ChunkOptions define the sizes of the content defined chunks. Changing the sizes change the
+// chunk boundaries, so the chunks of the previous snapshots are not shared anymore.
+type ChunkOptions struct {
+	// MinSize is the minimal size of a chunk, except the last one of a file. Default is DefaultMinSize.
+	MinSize int `json:"min_size"`
+	// AvgSize is the expected average size of a chunk, rounded to a power of 2. Default is DefaultAvgSize.
+	AvgSize int `json:"avg_size"`
+	// MaxSize is the maximal size of a chunk. Default is DefaultMaxSize.
+	MaxSize int `json:"max_size"`
+}
+
+func (o ChunkOptions) normalize() ChunkOptions {
+	if o.AvgSize <= 0 {
+		o.AvgSize = DefaultAvgSize
+	}
+
+	if o.MinSize <= 0 || o.MinSize > o.AvgSize {
+		o.MinSize = min(DefaultMinSize, o.AvgSize/4)
+	}
+
+	if o.MaxSize <= 0 || o.MaxSize < o.AvgSize {
+		o.MaxSize = max(DefaultMaxSize, o.AvgSize*4)
+	}
+
+	return o
+}
+
+// gear is the table of random values of the gear hash, generated with a fixed seed
+// to give the same chunks on every run.
+var gear = func() [256]uint64 {
+	var (
+		t [256]uint64
+		s uint64 = 0x676f6c6962646564 // seed
+	)
+
+	// splitmix64
+	for i := range t {
+		s += 0x9e3779b97f4a7c15
+		z := s
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		t[i] = z ^ (z >> 31)
+	}
+
+	return t
+}()
+
+// Chunker split a stream into content defined chunks with the FastCDC algorithm:
+// a gear rolling hash find the cut points, with a harder condition before the average size
+// and an easier one after (normalized chunking), so the chunk sizes stay close to the average.
+// An insertion or a deletion into the stream only change the chunks around the change.
+type Chunker struct {
+	r io.Reader
+	o ChunkOptions
+	s uint64 // mask used before the average size
+	l uint64 // mask used after the average size
+	b []byte // buffer
+	n int    // size of the data into the buffer
+	c int    // size of the last chunk returned
+	e error  // read error
+}
+
+// NewChunker return a chunker reading the given stream.
+func NewChunker(r io.Reader, opt ChunkOptions) *Chunker {
+	opt = opt.normalize()
+
+	var n = bits.Len(uint(opt.AvgSize)) - 1
+
+	return &Chunker{
+		r: r,
+		o: opt,
+		s: ^uint64(0) << (64 - (n + 1)),
+		l: ^uint64(0) << (64 - (n - 1)),
+		b: make([]byte, opt.MaxSize),
+	}
+}
+
+// Next return the next chunk, or io.EOF at the end of the stream.
+// The returned slice is only valid until the next call.
+func (o *Chunker) Next() ([]byte, error) {
+	if o.c > 0 {
+		// drop the last chunk
+		o.n = copy(o.b, o.b[o.c:o.n])
+		o.c = 0
+	}
+
+	for o.n < len(o.b) && o.e == nil {
+		var i int
+		i, o.e = o.r.Read(o.b[o.n:])
+		o.n += i
+	}
+
+	if o.e != nil && !errors.Is(o.e, io.EOF) {
+		return nil, o.e
+	} else if o.n < 1 {
+		return nil, io.EOF
+	}
+
+	o.c = o.cut(o.b[:o.n])
+
+	return o.b[:o.c], nil
+}
+
+// cut return the size of the next chunk of the given data.
+func (o *Chunker) cut(p []byte) int {
+	var (
+		n = len(p)
+		m = o.o.AvgSize
+		h uint64
+		i = o.o.MinSize
+	)
+
+	if n <= o.o.MinSize {
+		return n
+	} else if n < m {
+		m = n
+	}
+
+	for ; i < m; i++ {
+		h = (h << 1) + gear[p[i]]
+		if h&o.s == 0 {
+			return i + 1
+		}
+	}
+
+	for ; i < n; i++ {
+		h = (h << 1) + gear[p[i]]
+		if h&o.l == 0 {
+			return i + 1
+		}
+	}
+
+	return n
+}
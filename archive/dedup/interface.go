@@ -0,0 +1,157 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package dedup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+
+	arccmp "github.com/nabbar/golib/archive/compress"
+)
+
+var (
+	ErrInvalidSource   = errors.New("invalid dedup source path")
+	ErrInvalidStore    = errors.New("invalid chunk store")
+	ErrInvalidSnapshot = errors.New("invalid dedup snapshot")
+	ErrInvalidID       = errors.New("invalid chunk id")
+	ErrChunkNotFound   = errors.New("chunk not found")
+	ErrCorruptChunk    = errors.New("chunk content does not match its id")
+)
+
+// ID is the identifier of a chunk: the sha256 hash of its uncompressed content.
+type ID [sha256.Size]byte
+
+// Sum return the ID of the given chunk content.
+func Sum(p []byte) ID {
+	return sha256.Sum256(p)
+}
+
+// ParseID return the ID of the given hexadecimal string.
+func ParseID(s string) (ID, error) {
+	var i ID
+
+	if b, e := hex.DecodeString(s); e != nil || len(b) != len(i) {
+		return i, ErrInvalidID
+	} else {
+		copy(i[:], b)
+	}
+
+	return i, nil
+}
+
+func (i ID) String() string {
+	return hex.EncodeToString(i[:])
+}
+
+func (i ID) MarshalText() ([]byte, error) {
+	return []byte(i.String()), nil
+}
+
+func (i *ID) UnmarshalText(p []byte) error {
+	v, e := ParseID(string(p))
+
+	if e != nil {
+		return e
+	}
+
+	*i = v
+	return nil
+}
+
+// Store is a content addressed storage of chunks, shared by all the snapshots.
+// The implementations must be safe for concurrent use.
+type Store interface {
+	// Has return true if the chunk is already stored.
+	Has(ctx context.Context, id ID) (bool, error)
+
+	// Put store the given chunk data. Storing an existing chunk is not an error.
+	// The data must not be retained after the call.
+	Put(ctx context.Context, id ID, data []byte) error
+
+	// Get return the stored chunk data, or ErrChunkNotFound.
+	Get(ctx context.Context, id ID) ([]byte, error)
+}
+
+// Options define how a source is archived into a store.
+type Options struct {
+	// Chunk define the sizes of the content defined chunks.
+	Chunk ChunkOptions
+
+	// Compression is the algorithm used to compress each new chunk into the store.
+	Compression arccmp.Algorithm
+
+	// Exclude is a list of patterns (see path.Match) of the relative slash paths to skip.
+	// A pattern matching a directory skip the whole directory.
+	Exclude []string
+}
+
+// Archive walk the source path, split each regular file into content defined chunks and put
+// the chunks not already stored into the store. The chunks shared with previous snapshots,
+// or between the files, are stored only once.
+// The returned snapshot list the files with their chunks and must be kept to restore the source.
+func Archive(ctx context.Context, source string, st Store, opt Options) (*Snapshot, error) {
+	if st == nil {
+		return nil, ErrInvalidStore
+	} else if len(source) < 1 {
+		return nil, ErrInvalidSource
+	} else if ctx == nil {
+		ctx = context.Background()
+	}
+
+	a := &arc{
+		x: ctx,
+		s: source,
+		t: st,
+		o: opt,
+		k: make(map[ID]struct{}),
+	}
+
+	return a.run()
+}
+
+// Restore write all the files of the snapshot into the destination path, with the chunks of the store.
+// Each chunk is checked against its id.
+func Restore(ctx context.Context, snp *Snapshot, st Store, destination string) error {
+	if st == nil {
+		return ErrInvalidStore
+	} else if snp == nil {
+		return ErrInvalidSnapshot
+	} else if ctx == nil {
+		ctx = context.Background()
+	}
+
+	r := &rst{
+		x: ctx,
+		n: snp,
+		t: st,
+		d: destination,
+	}
+
+	return r.run()
+}
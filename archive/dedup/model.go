@@ -0,0 +1,332 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package dedup
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	arctps "github.com/nabbar/golib/archive/archive/types"
+)
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+type arc struct {
+	x context.Context
+	s string          // source path
+	t Store           // chunk store
+	o Options         // options
+	n *Snapshot       // new snapshot
+	k map[ID]struct{} // chunks known into the store
+}
+
+func (o *arc) run() (*Snapshot, error) {
+	if i, e := os.Stat(o.s); e != nil {
+		return nil, e
+	} else if !i.IsDir() {
+		return nil, ErrInvalidSource
+	}
+
+	o.n = &Snapshot{
+		Version:     SnapshotVersion,
+		Created:     time.Now(),
+		Compression: o.o.Compression,
+		Chunk:       o.o.Chunk.normalize(),
+		Files:       make([]File, 0),
+	}
+
+	if e := filepath.WalkDir(o.s, o.walk); e != nil {
+		return nil, e
+	}
+
+	return o.n, nil
+}
+
+func (o *arc) walk(pth string, d fs.DirEntry, err error) error {
+	if err != nil {
+		return err
+	} else if e := o.x.Err(); e != nil {
+		return e
+	}
+
+	rel, err := filepath.Rel(o.s, pth)
+
+	if err != nil {
+		return err
+	} else if rel == "." {
+		return nil
+	}
+
+	rel = filepath.ToSlash(rel)
+
+	if o.isExcluded(rel) {
+		if d.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	}
+
+	inf, err := d.Info()
+
+	if err != nil {
+		return err
+	}
+
+	f := File{
+		Path:    rel,
+		Mode:    inf.Mode(),
+		ModTime: inf.ModTime(),
+	}
+
+	if inf.IsDir() {
+		// keep the empty directories and the permissions
+	} else if inf.Mode()&os.ModeSymlink != 0 {
+		if f.Link, err = os.Readlink(pth); err != nil {
+			return err
+		}
+	} else if !inf.Mode().IsRegular() {
+		// skip devices, sockets, pipes, ...
+		return nil
+	} else if err = o.chunkFile(pth, &f); err != nil {
+		return err
+	}
+
+	o.n.Files = append(o.n.Files, f)
+	o.n.Stats.Files++
+	o.n.Stats.Bytes += f.Size
+
+	return nil
+}
+
+func (o *arc) isExcluded(rel string) bool {
+	for _, p := range o.o.Exclude {
+		if ok, _ := path.Match(p, rel); ok {
+			return true
+		} else if ok, _ = path.Match(p, path.Base(rel)); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// chunkFile split the file into chunks and put the unknown chunks into the store.
+func (o *arc) chunkFile(pth string, f *File) error {
+	h, err := os.Open(pth)
+
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = h.Close()
+	}()
+
+	var c = NewChunker(h, o.n.Chunk)
+
+	f.Chunks = make([]ID, 0)
+
+	for {
+		p, e := c.Next()
+
+		if errors.Is(e, io.EOF) {
+			return nil
+		} else if e != nil {
+			return e
+		} else if e = o.x.Err(); e != nil {
+			return e
+		}
+
+		id := Sum(p)
+		f.Chunks = append(f.Chunks, id)
+		f.Size += int64(len(p))
+		o.n.Stats.Chunks++
+
+		if e = o.putChunk(id, p); e != nil {
+			return e
+		}
+	}
+}
+
+func (o *arc) putChunk(id ID, p []byte) error {
+	if _, ok := o.k[id]; ok {
+		return nil
+	}
+
+	if ok, e := o.t.Has(o.x, id); e != nil {
+		return e
+	} else if ok {
+		o.k[id] = struct{}{}
+		return nil
+	}
+
+	var buf = bytes.NewBuffer(make([]byte, 0, len(p)))
+
+	if w, e := o.o.Compression.Writer(nopCloser{buf}); e != nil {
+		return e
+	} else if _, e = w.Write(p); e != nil {
+		return e
+	} else if e = w.Close(); e != nil {
+		return e
+	} else if e = o.t.Put(o.x, id, buf.Bytes()); e != nil {
+		return e
+	}
+
+	o.k[id] = struct{}{}
+	o.n.Stats.NewChunks++
+	o.n.Stats.NewBytes += int64(len(p))
+	o.n.Stats.Stored += int64(buf.Len())
+
+	return nil
+}
+
+type rst struct {
+	x context.Context
+	n *Snapshot // snapshot to restore
+	t Store     // chunk store
+	d string    // destination path
+}
+
+func (o *rst) run() error {
+	if e := os.MkdirAll(o.d, 0755); e != nil {
+		return e
+	}
+
+	var dir = make([]File, 0)
+
+	for _, f := range o.n.Files {
+		if e := o.x.Err(); e != nil {
+			return e
+		}
+
+		var dst = arctps.SafePath(o.d, f.Path)
+
+		if e := os.MkdirAll(filepath.Dir(dst), 0755); e != nil {
+			return e
+		}
+
+		if f.Mode.IsDir() {
+			if e := os.MkdirAll(dst, 0755); e != nil {
+				return e
+			}
+			// the permissions are applied after the content, for the read only directories
+			dir = append(dir, f)
+		} else if f.Mode&os.ModeSymlink != 0 {
+			_ = os.Remove(dst)
+			if e := os.Symlink(f.Link, dst); e != nil {
+				return e
+			}
+		} else if e := o.restoreFile(dst, f); e != nil {
+			return e
+		}
+	}
+
+	for i := len(dir) - 1; i >= 0; i-- {
+		var dst = arctps.SafePath(o.d, dir[i].Path)
+
+		if e := os.Chmod(dst, dir[i].Mode.Perm()); e != nil {
+			return e
+		}
+
+		_ = os.Chtimes(dst, dir[i].ModTime, dir[i].ModTime)
+	}
+
+	return nil
+}
+
+func (o *rst) restoreFile(dst string, f File) error {
+	h, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+
+	if err != nil {
+		return err
+	}
+
+	for _, id := range f.Chunks {
+		var p []byte
+
+		if p, err = o.getChunk(id); err != nil {
+			break
+		} else if _, err = h.Write(p); err != nil {
+			break
+		}
+	}
+
+	if e := h.Close(); err == nil {
+		err = e
+	}
+
+	if err != nil {
+		_ = os.Remove(dst)
+		return err
+	} else if err = os.Chmod(dst, f.Mode.Perm()); err != nil {
+		return err
+	}
+
+	_ = os.Chtimes(dst, f.ModTime, f.ModTime)
+
+	return nil
+}
+
+// getChunk return the uncompressed chunk, checked against its id.
+func (o *rst) getChunk(id ID) ([]byte, error) {
+	p, e := o.t.Get(o.x, id)
+
+	if e != nil {
+		return nil, e
+	}
+
+	r, e := o.n.Compression.Reader(bytes.NewReader(p))
+
+	if e != nil {
+		return nil, e
+	}
+
+	defer func() {
+		_ = r.Close()
+	}()
+
+	if p, e = io.ReadAll(r); e != nil {
+		return nil, e
+	} else if Sum(p) != id {
+		return nil, ErrCorruptChunk
+	}
+
+	return p, nil
+}
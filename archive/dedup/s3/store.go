@@ -0,0 +1,126 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package s3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"path"
+
+	sdksss "github.com/aws/aws-sdk-go-v2/service/s3"
+	sdktps "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	arcddp "github.com/nabbar/golib/archive/dedup"
+	awsobj "github.com/nabbar/golib/aws/object"
+)
+
+// Client is the subset of the golib aws object client (github.com/nabbar/golib/aws/object) used by the store.
+type Client interface {
+	ListPrefix(continuationToken string, prefix string) ([]sdktps.Object, string, int64, error)
+	Get(object string) (*sdksss.GetObjectOutput, error)
+	Put(object string, body io.Reader) error
+}
+
+var _ Client = awsobj.Object(nil)
+
+type sss struct {
+	c Client
+	p string // key prefix
+}
+
+// New return a store keeping each chunk as an object of the bucket of the given client,
+// with the key <prefix>/<first two characters of the id>/<id>.
+func New(cli Client, prefix string) (arcddp.Store, error) {
+	if cli == nil {
+		return nil, arcddp.ErrInvalidStore
+	}
+
+	return &sss{
+		c: cli,
+		p: prefix,
+	}, nil
+}
+
+func (o *sss) key(id arcddp.ID) string {
+	var s = id.String()
+	return path.Join(o.p, s[:2], s)
+}
+
+func (o *sss) Has(ctx context.Context, id arcddp.ID) (bool, error) {
+	if e := ctx.Err(); e != nil {
+		return false, e
+	}
+
+	var k = o.key(id)
+
+	l, _, _, e := o.c.ListPrefix("", k)
+
+	if e != nil {
+		return false, e
+	}
+
+	for _, i := range l {
+		if i.Key != nil && *i.Key == k {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (o *sss) Put(ctx context.Context, id arcddp.ID, data []byte) error {
+	if e := ctx.Err(); e != nil {
+		return e
+	}
+
+	return o.c.Put(o.key(id), bytes.NewReader(data))
+}
+
+func (o *sss) Get(ctx context.Context, id arcddp.ID) ([]byte, error) {
+	if e := ctx.Err(); e != nil {
+		return nil, e
+	}
+
+	out, e := o.c.Get(o.key(id))
+
+	var nsk *sdktps.NoSuchKey
+
+	if errors.As(e, &nsk) {
+		return nil, arcddp.ErrChunkNotFound
+	} else if e != nil {
+		return nil, e
+	} else if out == nil || out.Body == nil {
+		return nil, arcddp.ErrChunkNotFound
+	}
+
+	defer func() {
+		_ = out.Body.Close()
+	}()
+
+	return io.ReadAll(out.Body)
+}
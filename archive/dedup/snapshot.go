@@ -0,0 +1,130 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package dedup
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"time"
+
+	arccmp "github.com/nabbar/golib/archive/compress"
+)
+
+// SnapshotVersion is the version of the snapshot format.
+const SnapshotVersion = 1
+
+// File is a file of a snapshot.
+type File struct {
+	// Path is the slash path relative to the source.
+	Path string `json:"path"`
+	// Size is the size of the file content.
+	Size int64 `json:"size"`
+	// Mode is the mode and permission of the file.
+	Mode fs.FileMode `json:"mode"`
+	// ModTime is the modification time of the file.
+	ModTime time.Time `json:"mod_time"`
+	// Link is the target of a symbolic link.
+	Link string `json:"link,omitempty"`
+	// Chunks is the ordered list of chunks of the file content.
+	Chunks []ID `json:"chunks,omitempty"`
+}
+
+// Stats are the statistics of a snapshot.
+type Stats struct {
+	// Files is the number of files, directories and links.
+	Files int `json:"files"`
+	// Chunks is the number of chunks of all files.
+	Chunks int `json:"chunks"`
+	// NewChunks is the number of chunks put into the store by this snapshot.
+	NewChunks int `json:"new_chunks"`
+	// Bytes is the total size of the files content.
+	Bytes int64 `json:"bytes"`
+	// NewBytes is the size of the new chunks content, before compression.
+	NewBytes int64 `json:"new_bytes"`
+	// Stored is the size of the new chunks into the store, after compression.
+	Stored int64 `json:"stored"`
+}
+
+// Ratio return the deduplication ratio: the size of the files content divided by the size of its new chunks.
+func (s Stats) Ratio() float64 {
+	if s.NewBytes < 1 {
+		return 0
+	}
+
+	return float64(s.Bytes) / float64(s.NewBytes)
+}
+
+// Snapshot describe a source archived into a store.
+type Snapshot struct {
+	Version     int              `json:"version"`
+	Created     time.Time        `json:"created"`
+	Compression arccmp.Algorithm `json:"compression"`
+	Chunk       ChunkOptions     `json:"chunk"`
+	Files       []File           `json:"files"`
+	Stats       Stats            `json:"stats"`
+}
+
+// ReadSnapshot decode a snapshot from the given reader.
+func ReadSnapshot(r io.Reader) (*Snapshot, error) {
+	var s = &Snapshot{}
+
+	if e := json.NewDecoder(r).Decode(s); e != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSnapshot, e)
+	} else if s.Version != SnapshotVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidSnapshot, s.Version)
+	}
+
+	return s, nil
+}
+
+// Write encode the snapshot into the given writer.
+func (s *Snapshot) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+// ChunkIDs return the list of the distinct chunks used by the snapshot.
+func (s *Snapshot) ChunkIDs() []ID {
+	var (
+		k = make(map[ID]struct{})
+		l = make([]ID, 0)
+	)
+
+	for _, f := range s.Files {
+		for _, c := range f.Chunks {
+			if _, ok := k[c]; !ok {
+				k[c] = struct{}{}
+				l = append(l, c)
+			}
+		}
+	}
+
+	return l
+}
@@ -0,0 +1,114 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package dedup
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+type sfs struct {
+	r string // root path
+}
+
+// NewStoreFS return a store keeping each chunk as a file into the given root directory,
+// with a sub directory named with the first two characters of the chunk id.
+func NewStoreFS(root string) (Store, error) {
+	if len(root) < 1 {
+		return nil, ErrInvalidStore
+	} else if e := os.MkdirAll(root, 0750); e != nil {
+		return nil, e
+	}
+
+	return &sfs{
+		r: root,
+	}, nil
+}
+
+func (o *sfs) path(id ID) string {
+	var s = id.String()
+	return filepath.Join(o.r, s[:2], s)
+}
+
+func (o *sfs) Has(ctx context.Context, id ID) (bool, error) {
+	if _, e := os.Stat(o.path(id)); errors.Is(e, fs.ErrNotExist) {
+		return false, nil
+	} else if e != nil {
+		return false, e
+	}
+
+	return true, nil
+}
+
+func (o *sfs) Put(ctx context.Context, id ID, data []byte) error {
+	var (
+		p = o.path(id)
+		d = filepath.Dir(p)
+	)
+
+	if e := os.MkdirAll(d, 0750); e != nil {
+		return e
+	}
+
+	// written into a temporary file renamed on success, so a chunk is never partial
+	h, e := os.CreateTemp(d, ".tmp-")
+
+	if e != nil {
+		return e
+	}
+
+	var n = h.Name()
+
+	if _, e = h.Write(data); e == nil {
+		e = h.Sync()
+	}
+
+	if c := h.Close(); e == nil {
+		e = c
+	}
+
+	if e == nil {
+		e = os.Rename(n, p)
+	}
+
+	if e != nil {
+		_ = os.Remove(n)
+	}
+
+	return e
+}
+
+func (o *sfs) Get(ctx context.Context, id ID) ([]byte, error) {
+	if p, e := os.ReadFile(o.path(id)); errors.Is(e, fs.ErrNotExist) {
+		return nil, ErrChunkNotFound
+	} else {
+		return p, e
+	}
+}
@@ -26,18 +26,64 @@
 package archive
 
 import (
+	"errors"
 	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	arcarc "github.com/nabbar/golib/archive/archive"
 	arctps "github.com/nabbar/golib/archive/archive/types"
 	arccmp "github.com/nabbar/golib/archive/compress"
+	libfpg "github.com/nabbar/golib/file/progress"
 )
 
 func ExtractAll(r io.ReadCloser, archiveName, destination string) error {
+	return ExtractAllWarning(r, archiveName, destination, nil)
+}
+
+// ExtractAllWarning is like ExtractAll, but reports each non-fatal anomaly to the given function:
+// unsupported entries skipped, extended attributes not restored, timestamps not preserved and
+// permissions downgraded (setuid, setgid and sticky bits are never restored).
+func ExtractAllWarning(r io.ReadCloser, archiveName, destination string, fct FuncWarning) error {
+	return extractAll(r, archiveName, destination, extract{w: fct})
+}
+
+// ExtractAllLimit is like ExtractAllWarning, but aborts with a BombError as soon as the total of
+// the extracted bytes exceeds the given limits, compared to the bytes read from the given reader.
+// It must be used for any untrusted archive.
+func ExtractAllLimit(r io.ReadCloser, archiveName, destination string, lim Limits, fct FuncWarning) error {
+	if r == nil {
+		return fs.ErrInvalid
+	} else if !lim.IsEnabled() {
+		return extractAll(r, archiveName, destination, extract{w: fct})
+	}
+
+	var m = arccmp.NewMeter(lim)
+
+	return extractAll(&meterReadCloser{Reader: m.Input(r), c: r}, archiveName, destination, extract{w: fct, m: m})
+}
+
+// extract hold the options of an extraction.
+type extract struct {
+	w FuncWarning         // warning function
+	m *arccmp.Meter       // limits meter, nil if disabled
+	f string              // filter pattern, empty for all files
+	p libfpg.FctIncrement // progress function of the extracted bytes
+}
+
+type meterReadCloser struct {
+	io.Reader
+	c io.Closer
+}
+
+func (o *meterReadCloser) Close() error {
+	return o.c.Close()
+}
+
+func extractAll(r io.ReadCloser, archiveName, destination string, x extract) error {
 	var (
 		e error
 		n string
@@ -57,23 +103,31 @@ func ExtractAll(r io.ReadCloser, archiveName, destination string) error {
 		}
 
 		n = strings.TrimSuffix(filepath.Base(archiveName), a.Extension())
-		return ExtractAll(o, n, destination)
+		return extractAll(o, n, destination, x)
 	}
 
+	return extractArchive(o, archiveName, destination, x)
+}
+
+// extractArchive detect the archive format of the given uncompressed reader and extract it.
+// A reader not being an archive is written as a single file.
+func extractArchive(r io.ReadCloser, archiveName, destination string, x extract) error {
 	var (
+		e error
 		b arcarc.Algorithm
 		z arctps.Reader
 	)
 
-	if b, z, r, e = DetectArchive(o); e != nil {
+	if b, z, r, e = DetectArchive(r); e != nil {
 		return e
 	} else if b.IsNone() {
-		return writeFile(archiveName, destination, r, nil)
+		return writeFile(archiveName, destination, r, nil, x)
 	} else if z == nil {
 		return fs.ErrInvalid
 	} else {
 		var err error
 
+		z.RegisterFuncWarning(x.w)
 		z.Walk(func(info fs.FileInfo, closer io.ReadCloser, dst, target string) bool {
 			defer func() {
 				if closer != nil {
@@ -86,6 +140,9 @@ func ExtractAll(r io.ReadCloser, archiveName, destination string) error {
 					err = e
 					return false
 				}
+			} else if !matchFilter(x.f, dst) {
+				_, _ = io.Copy(io.Discard, closer)
+				return true
 			} else if info.Mode()&os.ModeSymlink != 0 {
 				if e = writeSymLink(true, dst, target, destination); e != nil {
 					err = e
@@ -97,14 +154,22 @@ func ExtractAll(r io.ReadCloser, archiveName, destination string) error {
 					return false
 				}
 			} else if info.Mode().IsRegular() {
-				if e = writeFile(dst, destination, closer, info); e != nil {
+				if e = writeFile(dst, destination, closer, info, x); e != nil {
 					err = e
 					return false
 				}
+			} else {
+				x.w.Call(arctps.WarnUnsupported, dst, "mode '"+info.Mode().Type().String()+"'", nil)
 			}
 
 			// prevent file cursor not at EOF of current file for TAPE Archive
-			_, _ = io.Copy(io.Discard, closer)
+			if x.m == nil {
+				_, _ = io.Copy(io.Discard, closer)
+			} else if _, e = io.Copy(io.Discard, x.m.Output(closer)); errors.Is(e, ErrDecompressionBomb) {
+				err = e
+				return false
+			}
+
 			return true
 		})
 
@@ -112,12 +177,11 @@ func ExtractAll(r io.ReadCloser, archiveName, destination string) error {
 	}
 }
 
+// cleanPath return the given path cleaned and relative, without any parent reference,
+// to never write outside of the destination.
 func cleanPath(path string) string {
-	for strings.Contains(path, ".."+string(filepath.Separator)) {
-		path = filepath.Clean(path)
-	}
-
-	return path
+	var sep = string(filepath.Separator)
+	return strings.TrimPrefix(filepath.Clean(sep+filepath.FromSlash(path)), sep)
 }
 
 func createPath(dest string, info os.FileMode) error {
@@ -140,13 +204,22 @@ func createPath(dest string, info os.FileMode) error {
 	}
 }
 
-func writeFile(name, dest string, r io.ReadCloser, i fs.FileInfo) error {
+func writeFile(name, dest string, r io.ReadCloser, i fs.FileInfo, x extract) error {
 	var (
 		dst = filepath.Join(dest, cleanPath(name))
 		hdf *os.File
 		err error
+		src io.Reader = r
 	)
 
+	if x.m != nil {
+		src = x.m.Output(r)
+	}
+
+	if x.p != nil {
+		src = &progressReader{r: src, f: x.p}
+	}
+
 	defer func() {
 		if hdf != nil {
 			_ = hdf.Sync()
@@ -158,17 +231,43 @@ func writeFile(name, dest string, r io.ReadCloser, i fs.FileInfo) error {
 		return err
 	} else if hdf, err = os.Create(dst); err != nil {
 		return err
-	} else if _, err = io.Copy(hdf, r); err != nil {
+	} else if _, err = io.Copy(hdf, src); errors.Is(err, ErrDecompressionBomb) {
+		_ = hdf.Close()
+		hdf = nil
+		_ = os.Remove(dst)
+		return err
+	} else if err != nil {
 		return err
 	} else if i != nil {
-		if err = os.Chmod(dst, i.Mode()); err != nil {
+		var prm = i.Mode().Perm()
+
+		if i.Mode()&(os.ModeSetuid|os.ModeSetgid|os.ModeSticky) != 0 {
+			x.w.Call(arctps.WarnPermission, name, "setuid, setgid and sticky bits not restored", nil)
+		}
+
+		if err = os.Chmod(dst, prm); err != nil {
 			return err
 		}
+
+		restoreTime(dst, name, i.ModTime(), x.w)
 	}
 
 	return nil
 }
 
+// restoreTime set the modification time of the extracted file and report the time lost by the file system.
+func restoreTime(dst, name string, mod time.Time, fct FuncWarning) {
+	if mod.IsZero() {
+		return
+	} else if e := os.Chtimes(dst, mod, mod); e != nil {
+		fct.Call(arctps.WarnTimestamp, name, "", e)
+	} else if i, e := os.Stat(dst); e != nil {
+		fct.Call(arctps.WarnTimestamp, name, "", e)
+	} else if !i.ModTime().Equal(mod) {
+		fct.Call(arctps.WarnTimestamp, name, "truncated to "+i.ModTime().Sub(mod.Truncate(0)).String()+" of difference", nil)
+	}
+}
+
 func writeSymLink(isSymLink bool, name, target, dest string) error {
 	var (
 		dst = filepath.Join(dest, cleanPath(name))
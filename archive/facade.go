@@ -0,0 +1,548 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package archive
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	arcarc "github.com/nabbar/golib/archive/archive"
+	arctps "github.com/nabbar/golib/archive/archive/types"
+	arccmp "github.com/nabbar/golib/archive/compress"
+	libfpg "github.com/nabbar/golib/file/progress"
+	iotprg "github.com/nabbar/golib/ioutils/ioprogress"
+)
+
+// Options define the behavior of Create and Extract.
+type Options struct {
+	// Archive is the archive format used by Create.
+	// When both Archive and Compression are None, they are detected from the destination extension.
+	// Without archive extension, a single regular file source is only compressed, otherwise Tar is used.
+	Archive arcarc.Algorithm
+
+	// Compression is the compression algorithm used by Create.
+	Compression arccmp.Algorithm
+
+	// Filter is a pattern (see path.Match) to accept only certain files, matched against
+	// the base name and the slash path of each file into the archive. Empty means all files.
+	Filter string
+
+	// Overwrite allow Create to replace an existing destination file.
+	Overwrite bool
+
+	// Limits define the max decompressed output accepted by Extract. Must be defined for any untrusted archive.
+	Limits Limits
+
+	// Warning is called by Extract for each non-fatal anomaly.
+	Warning FuncWarning
+
+	// Progress is called with the number of bytes read from each source file by Create,
+	// or written into each extracted file by Extract.
+	Progress libfpg.FctIncrement
+
+	// Reproducible when defined, make Create build the same archive from the same content,
+	// whatever the timestamps and owners of the source files (see arctps.Reproducible).
+	Reproducible *arctps.Reproducible
+
+	// Cache when defined, is used by Open to skip the detection of the compression and the archive format
+	// of the files already opened and not modified since.
+	Cache DetectCache
+}
+
+// Create build a new archive file at the given destination path from the given list of source paths.
+// Each directory source is added recursively with its base name as root path into the archive.
+// The archive is first written into a temporary file renamed on success, so a failure never leave
+// a partial or a truncated destination file.
+func Create(destination string, sources []string, opt Options) (err error) {
+	var (
+		arc = opt.Archive
+		cmp = opt.Compression
+		dst string
+		tmp *os.File
+		wrt io.WriteCloser
+	)
+
+	if len(destination) < 1 || len(sources) < 1 {
+		return fs.ErrInvalid
+	} else if dst, err = filepath.Abs(destination); err != nil {
+		return err
+	} else if _, err = os.Stat(dst); err == nil && !opt.Overwrite {
+		return fmt.Errorf("%w: '%s'", fs.ErrExist, destination)
+	} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	if arc.IsNone() && cmp.IsNone() {
+		arc, cmp = detectExtension(dst)
+	}
+
+	if arc.IsNone() && (cmp.IsNone() || !isSingleFile(sources)) {
+		arc = arcarc.Tar
+	}
+
+	if tmp, err = os.CreateTemp(filepath.Dir(dst), "."+filepath.Base(dst)+".*"); err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	if cmp.IsNone() {
+		wrt = tmp
+	} else if wrt, err = cmp.Writer(tmp); err != nil {
+		return err
+	} else {
+		wrt = &chainWriteCloser{WriteCloser: wrt, c: tmp}
+	}
+
+	if arc.IsNone() {
+		err = compressFile(sources[0], wrt, opt.Progress)
+	} else {
+		err = createArchive(arc, sources, wrt, opt, dst, tmp.Name())
+	}
+
+	if err != nil {
+		return err
+	} else if err = os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), dst)
+}
+
+// Extract extract the given archive file into the destination directory.
+// The compression and the archive format are detected from the content.
+// A compressed file not being an archive is extracted as a single file without its compression extension.
+func Extract(source, destination string, opt Options) error {
+	var (
+		err error
+		hdf *os.File
+		alg arccmp.Algorithm
+		x   = extract{w: opt.Warning, f: opt.Filter, p: opt.Progress}
+	)
+
+	if hdf, alg, err = openSource(source); err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = hdf.Close()
+	}()
+
+	if err = createPath(destination, 0); err != nil {
+		return err
+	}
+
+	if opt.Limits.IsEnabled() {
+		x.m = arccmp.NewMeter(opt.Limits)
+	}
+
+	if !alg.IsNone() {
+		var r io.ReadCloser = hdf
+
+		if x.m != nil {
+			r = &meterReadCloser{Reader: x.m.Input(hdf), c: hdf}
+		}
+
+		return extractAll(r, filepath.Base(source), destination, x)
+	}
+
+	// uncompressed archive: given as file to allow random access for zip
+	if x.m != nil {
+		if i, e := hdf.Stat(); e != nil {
+			return e
+		} else {
+			x.m.AddInput(i.Size())
+		}
+	}
+
+	return extractArchive(hdf, filepath.Base(source), destination, x)
+}
+
+// List return the path of all the files stored into the given archive file.
+// A compressed file not being an archive return its name without the compression extension.
+func List(source string) ([]string, error) {
+	var (
+		err error
+		hdf *os.File
+		alg arccmp.Algorithm
+		rdr io.ReadCloser
+		arc arcarc.Algorithm
+		lst arctps.Reader
+	)
+
+	if hdf, alg, err = openSource(source); err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = hdf.Close()
+	}()
+
+	if alg.IsNone() {
+		rdr = hdf
+	} else if rdr, err = alg.Reader(hdf); err != nil {
+		return nil, err
+	}
+
+	if arc, lst, _, err = DetectArchive(rdr); err != nil {
+		return nil, err
+	} else if arc.IsNone() || lst == nil {
+		return []string{strings.TrimSuffix(filepath.Base(source), alg.Extension())}, nil
+	}
+
+	return lst.List()
+}
+
+// Open open the given archive file and return the reader of its decompressed content, with the archive reader
+// if this content is an archive (nil otherwise). Closing the returned reader close the file.
+// The Limits are applied to the decompressed content. With a Cache, the detected algorithms are stored and
+// reused on the next Open of the same file.
+func Open(source string, opt Options) (arctps.Reader, io.ReadCloser, error) {
+	var (
+		err error
+		hit bool
+		det Detected
+		hdf *os.File
+		inf fs.FileInfo
+		rdr io.ReadCloser
+		lst arctps.Reader
+	)
+
+	if hdf, err = os.Open(source); err != nil {
+		return nil, nil, err
+	} else if inf, err = hdf.Stat(); err != nil {
+		_ = hdf.Close()
+		return nil, nil, err
+	}
+
+	if opt.Cache != nil {
+		det, hit = opt.Cache.Load(source, inf)
+	}
+
+	if !hit {
+		if det.Compression, err = detectHeader(hdf); err != nil {
+			_ = hdf.Close()
+			return nil, nil, err
+		}
+	}
+
+	if det.Compression.IsNone() {
+		rdr = &sizedFile{File: hdf, s: inf.Size()}
+	} else if rdr, err = det.Compression.ReaderLimit(hdf, opt.Limits); err != nil {
+		_ = hdf.Close()
+		return nil, nil, err
+	} else {
+		rdr = &chainReadCloser{ReadCloser: rdr, c: hdf}
+	}
+
+	if hit && det.Archive.IsNone() {
+		return nil, rdr, nil
+	} else if hit {
+		if lst, err = det.Archive.Reader(rdr); err != nil {
+			_ = rdr.Close()
+			return nil, nil, err
+		}
+
+		return lst, rdr, nil
+	}
+
+	var res io.ReadCloser
+
+	if det.Archive, lst, res, err = DetectArchive(rdr); err != nil {
+		_ = rdr.Close()
+		return nil, nil, err
+	} else if opt.Cache != nil {
+		opt.Cache.Store(source, inf, det)
+	}
+
+	return lst, res, nil
+}
+
+func createArchive(alg arcarc.Algorithm, sources []string, w io.WriteCloser, opt Options, skip ...string) error {
+	var (
+		err error
+		wrt arctps.Writer
+	)
+
+	if opt.Reproducible != nil {
+		wrt, err = alg.WriterReproducible(w, *opt.Reproducible)
+	} else {
+		wrt, err = alg.Writer(w)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	for _, src := range sources {
+		var root = filepath.Dir(filepath.Clean(src))
+
+		err = filepath.Walk(src, func(pth string, info fs.FileInfo, e error) error {
+			if e != nil {
+				return e
+			} else if info.IsDir() || isSkipped(pth, skip) {
+				return nil
+			}
+
+			rel, e := filepath.Rel(root, pth)
+
+			if e != nil {
+				return e
+			} else if rel = filepath.ToSlash(rel); !matchFilter(opt.Filter, rel) {
+				return nil
+			}
+
+			return addFile(wrt, pth, rel, info, opt.Progress)
+		})
+
+		if err != nil {
+			_ = wrt.Close()
+			return err
+		}
+	}
+
+	return wrt.Close()
+}
+
+func addFile(w arctps.Writer, pth, name string, info fs.FileInfo, fct libfpg.FctIncrement) error {
+	if info.Mode()&os.ModeSymlink != 0 {
+		if target, e := os.Readlink(pth); e != nil {
+			return e
+		} else {
+			return w.Add(info, nil, name, target)
+		}
+	} else if !info.Mode().IsRegular() {
+		return nil
+	}
+
+	hdf, err := os.Open(pth)
+
+	if err != nil {
+		return err
+	}
+
+	var r = iotprg.NewReadCloser(hdf)
+	r.RegisterFctIncrement(fct)
+
+	return w.Add(info, r, name, "")
+}
+
+func compressFile(source string, w io.WriteCloser, fct libfpg.FctIncrement) error {
+	hdf, err := os.Open(source)
+
+	if err != nil {
+		_ = w.Close()
+		return err
+	}
+
+	var r = iotprg.NewReadCloser(hdf)
+	r.RegisterFctIncrement(fct)
+
+	defer func() {
+		_ = r.Close()
+	}()
+
+	if _, err = io.Copy(w, r); err != nil {
+		_ = w.Close()
+		return err
+	}
+
+	return w.Close()
+}
+
+// openSource open the given file and detect its compression from the header, without consuming it.
+func openSource(source string) (*os.File, arccmp.Algorithm, error) {
+	hdf, err := os.Open(source)
+
+	if err != nil {
+		return nil, arccmp.None, err
+	}
+
+	alg, err := detectHeader(hdf)
+
+	if err != nil {
+		_ = hdf.Close()
+		return nil, arccmp.None, err
+	}
+
+	return hdf, alg, nil
+}
+
+// detectHeader detect the compression of the given file from its header and rewind it.
+func detectHeader(hdf *os.File) (arccmp.Algorithm, error) {
+	var (
+		buf = make([]byte, 10)
+		err error
+		n   int
+	)
+
+	if n, err = io.ReadFull(hdf, buf); err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return arccmp.None, err
+	} else if _, err = hdf.Seek(0, io.SeekStart); err != nil {
+		return arccmp.None, err
+	}
+
+	for _, a := range arccmp.List() {
+		if a.DetectHeader(buf[:n]) {
+			return a, nil
+		}
+	}
+
+	return arccmp.None, nil
+}
+
+// detectExtension return the archive and compression algorithm from the extensions of the given file name.
+func detectExtension(name string) (arcarc.Algorithm, arccmp.Algorithm) {
+	var (
+		arc = arcarc.None
+		cmp = arccmp.None
+		ext = strings.ToLower(filepath.Ext(name))
+	)
+
+	for _, a := range arccmp.List() {
+		if !a.IsNone() && ext == a.Extension() {
+			cmp = a
+			name = strings.TrimSuffix(name, filepath.Ext(name))
+			ext = strings.ToLower(filepath.Ext(name))
+			break
+		}
+	}
+
+	for _, a := range arcarc.List() {
+		if !a.IsNone() && ext == a.Extension() {
+			arc = a
+		}
+	}
+
+	return arc, cmp
+}
+
+func isSingleFile(sources []string) bool {
+	if len(sources) != 1 {
+		return false
+	} else if i, e := os.Stat(sources[0]); e != nil {
+		return false
+	} else {
+		return i.Mode().IsRegular()
+	}
+}
+
+func isSkipped(pth string, skip []string) bool {
+	if abs, e := filepath.Abs(pth); e == nil {
+		for _, s := range skip {
+			if abs == s {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// matchFilter return true if the filter is empty or match the base name or the slash path of the given name.
+func matchFilter(filter, name string) bool {
+	if len(filter) < 1 {
+		return true
+	}
+
+	name = filepath.ToSlash(name)
+
+	if ok, _ := path.Match(filter, path.Base(name)); ok {
+		return true
+	} else if ok, _ = path.Match(filter, strings.TrimPrefix(name, "/")); ok {
+		return true
+	}
+
+	return false
+}
+
+// chainWriteCloser close the given writer and then the underlying closer.
+type chainWriteCloser struct {
+	io.WriteCloser
+	c io.Closer
+}
+
+func (o *chainWriteCloser) Close() error {
+	var e = o.WriteCloser.Close()
+
+	if c := o.c.Close(); e == nil {
+		e = c
+	}
+
+	return e
+}
+
+// sizedFile give the size of the file to the zip reader, needing random access.
+type sizedFile struct {
+	*os.File
+	s int64
+}
+
+func (o *sizedFile) Size() int64 {
+	return o.s
+}
+
+type chainReadCloser struct {
+	io.ReadCloser
+	c io.Closer
+}
+
+func (o *chainReadCloser) Close() error {
+	var e = o.ReadCloser.Close()
+
+	if c := o.c.Close(); e == nil {
+		e = c
+	}
+
+	return e
+}
+
+// progressReader call the progress function with the number of bytes read.
+type progressReader struct {
+	r io.Reader
+	f libfpg.FctIncrement
+}
+
+func (o *progressReader) Read(p []byte) (int, error) {
+	n, e := o.r.Read(p)
+
+	if n > 0 {
+		o.f(int64(n))
+	}
+
+	return n, e
+}
@@ -33,6 +33,21 @@ import (
 	arccmp "github.com/nabbar/golib/archive/compress"
 )
 
+// Warning is a non-fatal anomaly found while extracting an archive.
+type Warning = arctps.Warning
+
+// FuncWarning is called for each Warning found while extracting an archive.
+type FuncWarning = arctps.FuncWarning
+
+// Limits define the max decompressed output accepted while extracting an untrusted archive.
+type Limits = arccmp.Limits
+
+// BombError is returned when the decompressed output of an archive exceeds the Limits.
+type BombError = arccmp.BombError
+
+// ErrDecompressionBomb is the error wrapped by any BombError.
+var ErrDecompressionBomb = arccmp.ErrDecompressionBomb
+
 func ParseCompression(s string) arccmp.Algorithm {
 	return arccmp.Parse(s)
 }
@@ -41,6 +56,12 @@ func DetectCompression(r io.Reader) (arccmp.Algorithm, io.ReadCloser, error) {
 	return arccmp.Detect(r)
 }
 
+// DetectCompressionLimit is like DetectCompression, but the returned reader fails with a BombError
+// as soon as the decompressed output exceeds the given limits.
+func DetectCompressionLimit(r io.Reader, lim Limits) (arccmp.Algorithm, io.ReadCloser, error) {
+	return arccmp.DetectLimit(r, lim)
+}
+
 func ParseArchive(s string) arcarc.Algorithm {
 	return arcarc.Parse(s)
 }
@@ -27,6 +27,7 @@
 package atomic
 
 import (
+	"container/list"
 	"sync"
 	"sync/atomic"
 )
@@ -73,6 +74,52 @@ type MapTyped[K comparable, V any] interface {
 	Range(f func(key K, value V) bool)
 }
 
+// MapOrdered is a typed map keeping the insertion order of its keys.
+// Store on an existing key keeps its original position.
+type MapOrdered[K comparable, V any] interface {
+	Load(key K) (value V, ok bool)
+	Store(key K, value V)
+
+	LoadOrStore(key K, value V) (actual V, loaded bool)
+	LoadAndDelete(key K) (value V, loaded bool)
+
+	Delete(key K)
+	Swap(key K, value V) (previous V, loaded bool)
+
+	// Len returns the number of keys stored.
+	Len() int
+	// Keys returns the keys in insertion order.
+	Keys() []K
+	// Values returns the values in key insertion order.
+	Values() []V
+
+	// Range calls f on a snapshot of the map in insertion order, f can safely update or delete keys.
+	Range(f func(key K, value V) bool)
+	// DeleteFunc removes each key for which f returns true and returns the count of removed keys.
+	DeleteFunc(f func(key K, value V) bool) int
+}
+
+// Slice is a copy-on-write slice: readers get an immutable snapshot without locking,
+// each writer builds a new backing array.
+type Slice[T any] interface {
+	// Load returns the current snapshot, the returned slice must not be modified.
+	Load() []T
+	// Store replaces the whole content with a copy of val.
+	Store(val []T)
+
+	Len() int
+	Get(idx int) (val T, ok bool)
+	Set(idx int, val T) bool
+
+	Append(val ...T)
+	Clear()
+
+	// Range calls f on the snapshot taken at call time, f can safely update the slice.
+	Range(f func(idx int, val T) bool)
+	// DeleteFunc removes each item for which f returns true and returns the count of removed items.
+	DeleteFunc(f func(val T) bool) int
+}
+
 func NewValue[T any]() Value[T] {
 	var (
 		tmp1 T
@@ -106,3 +153,16 @@ func NewMapTyped[K comparable, V any]() MapTyped[K, V] {
 		m: NewMapAny[K](),
 	}
 }
+
+func NewMapOrdered[K comparable, V any]() MapOrdered[K, V] {
+	return &mo[K, V]{
+		m: make(map[K]*list.Element),
+		l: list.New(),
+	}
+}
+
+func NewSlice[T any](val ...T) Slice[T] {
+	o := &sl[T]{}
+	o.Store(val)
+	return o
+}
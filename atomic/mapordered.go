@@ -0,0 +1,193 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package atomic
+
+import (
+	"container/list"
+	"sync"
+)
+
+type moItem[K comparable, V any] struct {
+	k K
+	v V
+}
+
+type mo[K comparable, V any] struct {
+	s sync.RWMutex
+	m map[K]*list.Element // element by key
+	l *list.List          // items in insertion order
+}
+
+func (o *mo[K, V]) Load(key K) (value V, ok bool) {
+	o.s.RLock()
+	defer o.s.RUnlock()
+
+	if e, k := o.m[key]; k {
+		return e.Value.(*moItem[K, V]).v, true
+	}
+
+	return value, false
+}
+
+func (o *mo[K, V]) Store(key K, value V) {
+	o.s.Lock()
+	defer o.s.Unlock()
+
+	o.store(key, value)
+}
+
+func (o *mo[K, V]) store(key K, value V) {
+	if e, k := o.m[key]; k {
+		e.Value.(*moItem[K, V]).v = value
+	} else {
+		o.m[key] = o.l.PushBack(&moItem[K, V]{k: key, v: value})
+	}
+}
+
+func (o *mo[K, V]) delete(key K) (value V, loaded bool) {
+	if e, k := o.m[key]; k {
+		delete(o.m, key)
+		o.l.Remove(e)
+		return e.Value.(*moItem[K, V]).v, true
+	}
+
+	return value, false
+}
+
+func (o *mo[K, V]) LoadOrStore(key K, value V) (actual V, loaded bool) {
+	o.s.Lock()
+	defer o.s.Unlock()
+
+	if e, k := o.m[key]; k {
+		return e.Value.(*moItem[K, V]).v, true
+	}
+
+	o.store(key, value)
+	return value, false
+}
+
+func (o *mo[K, V]) LoadAndDelete(key K) (value V, loaded bool) {
+	o.s.Lock()
+	defer o.s.Unlock()
+
+	return o.delete(key)
+}
+
+func (o *mo[K, V]) Delete(key K) {
+	o.s.Lock()
+	defer o.s.Unlock()
+
+	_, _ = o.delete(key)
+}
+
+func (o *mo[K, V]) Swap(key K, value V) (previous V, loaded bool) {
+	o.s.Lock()
+	defer o.s.Unlock()
+
+	if e, k := o.m[key]; k {
+		previous, loaded = e.Value.(*moItem[K, V]).v, true
+	}
+
+	o.store(key, value)
+	return previous, loaded
+}
+
+func (o *mo[K, V]) Len() int {
+	o.s.RLock()
+	defer o.s.RUnlock()
+
+	return len(o.m)
+}
+
+func (o *mo[K, V]) snapshot() []moItem[K, V] {
+	o.s.RLock()
+	defer o.s.RUnlock()
+
+	var res = make([]moItem[K, V], 0, len(o.m))
+
+	for e := o.l.Front(); e != nil; e = e.Next() {
+		res = append(res, *e.Value.(*moItem[K, V]))
+	}
+
+	return res
+}
+
+func (o *mo[K, V]) Keys() []K {
+	var (
+		s = o.snapshot()
+		r = make([]K, 0, len(s))
+	)
+
+	for _, i := range s {
+		r = append(r, i.k)
+	}
+
+	return r
+}
+
+func (o *mo[K, V]) Values() []V {
+	var (
+		s = o.snapshot()
+		r = make([]V, 0, len(s))
+	)
+
+	for _, i := range s {
+		r = append(r, i.v)
+	}
+
+	return r
+}
+
+func (o *mo[K, V]) Range(f func(key K, value V) bool) {
+	for _, i := range o.snapshot() {
+		if !f(i.k, i.v) {
+			return
+		}
+	}
+}
+
+func (o *mo[K, V]) DeleteFunc(f func(key K, value V) bool) int {
+	o.s.Lock()
+	defer o.s.Unlock()
+
+	var n int
+
+	for e := o.l.Front(); e != nil; {
+		i := e.Value.(*moItem[K, V])
+		x := e.Next()
+
+		if f(i.k, i.v) {
+			delete(o.m, i.k)
+			o.l.Remove(e)
+			n++
+		}
+
+		e = x
+	}
+
+	return n
+}
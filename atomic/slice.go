@@ -0,0 +1,135 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package atomic
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+type sl[T any] struct {
+	s sync.Mutex          // serialize writers
+	p atomic.Pointer[[]T] // current snapshot
+}
+
+func (o *sl[T]) Load() []T {
+	if p := o.p.Load(); p != nil {
+		return *p
+	}
+
+	return nil
+}
+
+func (o *sl[T]) store(val []T) {
+	o.p.Store(&val)
+}
+
+func (o *sl[T]) Store(val []T) {
+	o.s.Lock()
+	defer o.s.Unlock()
+
+	o.store(append(make([]T, 0, len(val)), val...))
+}
+
+func (o *sl[T]) Len() int {
+	return len(o.Load())
+}
+
+func (o *sl[T]) Get(idx int) (val T, ok bool) {
+	if s := o.Load(); idx >= 0 && idx < len(s) {
+		return s[idx], true
+	}
+
+	return val, false
+}
+
+func (o *sl[T]) Set(idx int, val T) bool {
+	o.s.Lock()
+	defer o.s.Unlock()
+
+	s := o.Load()
+
+	if idx < 0 || idx >= len(s) {
+		return false
+	}
+
+	n := append(make([]T, 0, len(s)), s...)
+	n[idx] = val
+	o.store(n)
+
+	return true
+}
+
+func (o *sl[T]) Append(val ...T) {
+	if len(val) < 1 {
+		return
+	}
+
+	o.s.Lock()
+	defer o.s.Unlock()
+
+	s := o.Load()
+	n := make([]T, 0, len(s)+len(val))
+	n = append(n, s...)
+	o.store(append(n, val...))
+}
+
+func (o *sl[T]) Clear() {
+	o.s.Lock()
+	defer o.s.Unlock()
+
+	o.store(make([]T, 0))
+}
+
+func (o *sl[T]) Range(f func(idx int, val T) bool) {
+	for i, v := range o.Load() {
+		if !f(i, v) {
+			return
+		}
+	}
+}
+
+func (o *sl[T]) DeleteFunc(f func(val T) bool) int {
+	o.s.Lock()
+	defer o.s.Unlock()
+
+	s := o.Load()
+	n := make([]T, 0, len(s))
+
+	for _, v := range s {
+		if !f(v) {
+			n = append(n, v)
+		}
+	}
+
+	if len(n) == len(s) {
+		return 0
+	}
+
+	o.store(n)
+	return len(s) - len(n)
+}
@@ -33,6 +33,7 @@ import (
 	sdkcfg "github.com/aws/aws-sdk-go-v2/config"
 	sdkcrd "github.com/aws/aws-sdk-go-v2/credentials"
 	libaws "github.com/nabbar/golib/aws"
+	awshlp "github.com/nabbar/golib/aws/helper"
 	libhtc "github.com/nabbar/golib/httpcli"
 )
 
@@ -83,6 +84,7 @@ func (c *awsModel) Clone() libaws.Config {
 			AccessKey: c.AccessKey,
 			SecretKey: c.SecretKey,
 			Bucket:    c.Bucket,
+			Object:    c.Object,
 		},
 		retryer: c.retryer,
 	}
@@ -122,6 +124,14 @@ func (c *awsModel) SetBucketName(bucket string) {
 	c.Bucket = bucket
 }
 
+func (c *awsModel) GetObjectOptions() awshlp.ObjectOptions {
+	return c.Object
+}
+
+func (c *awsModel) SetObjectOptions(opt awshlp.ObjectOptions) {
+	c.Object = opt
+}
+
 func (c *awsModel) JSON() ([]byte, error) {
 	return json.MarshalIndent(c, "", " ")
 }
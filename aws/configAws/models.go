@@ -34,6 +34,7 @@ import (
 
 	sdkaws "github.com/aws/aws-sdk-go-v2/aws"
 	libval "github.com/go-playground/validator/v10"
+	awshlp "github.com/nabbar/golib/aws/helper"
 	libhtc "github.com/nabbar/golib/httpcli"
 	libreq "github.com/nabbar/golib/request"
 )
@@ -43,6 +44,8 @@ type Model struct {
 	AccessKey string `mapstructure:"accesskey" json:"accesskey" yaml:"accesskey" toml:"accesskey" validate:"printascii,required"`
 	SecretKey string `mapstructure:"secretkey" json:"secretkey" yaml:"secretkey" toml:"secretkey" validate:"printascii,required"`
 	Bucket    string `mapstructure:"bucket" json:"bucket" yaml:"bucket" toml:"bucket" validate:"printascii,omitempty,bucket-s3"`
+
+	Object awshlp.ObjectOptions `mapstructure:"object" json:"object" yaml:"object" toml:"object"`
 }
 
 type ModelStatus struct {
@@ -84,6 +87,10 @@ func (c *awsModel) Validate() error {
 		}
 	}
 
+	if e := c.Object.Validate(); e != nil {
+		err.Add(e)
+	}
+
 	if err.HasParent() {
 		return err
 	}
@@ -34,6 +34,7 @@ import (
 	sdkaws "github.com/aws/aws-sdk-go-v2/aws"
 	sdkcrd "github.com/aws/aws-sdk-go-v2/credentials"
 	libaws "github.com/nabbar/golib/aws"
+	awshlp "github.com/nabbar/golib/aws/helper"
 	libhtc "github.com/nabbar/golib/httpcli"
 )
 
@@ -96,6 +97,7 @@ func (c *awsModel) Clone() libaws.Config {
 			AccessKey: c.AccessKey,
 			SecretKey: c.SecretKey,
 			Bucket:    c.Bucket,
+			Object:    c.Object,
 		},
 		retryer:   c.retryer,
 		endpoint:  c.endpoint,
@@ -133,6 +135,14 @@ func (c *awsModel) SetBucketName(bucket string) {
 	c.Bucket = bucket
 }
 
+func (c *awsModel) GetObjectOptions() awshlp.ObjectOptions {
+	return c.Object
+}
+
+func (c *awsModel) SetObjectOptions(opt awshlp.ObjectOptions) {
+	c.Object = opt
+}
+
 func (c *awsModel) JSON() ([]byte, error) {
 	return json.MarshalIndent(c, "", " ")
 }
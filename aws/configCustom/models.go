@@ -35,6 +35,7 @@ import (
 
 	sdkaws "github.com/aws/aws-sdk-go-v2/aws"
 	libval "github.com/go-playground/validator/v10"
+	awshlp "github.com/nabbar/golib/aws/helper"
 	libhtc "github.com/nabbar/golib/httpcli"
 	libreq "github.com/nabbar/golib/request"
 )
@@ -45,6 +46,8 @@ type Model struct {
 	AccessKey string `mapstructure:"accesskey" json:"accesskey" yaml:"accesskey" toml:"accesskey" validate:"omitempty,printascii"`
 	SecretKey string `mapstructure:"secretkey" json:"secretkey" yaml:"secretkey" toml:"secretkey" validate:"omitempty,printascii"`
 	Bucket    string `mapstructure:"bucket" json:"bucket" yaml:"bucket" toml:"bucket" validate:"omitempty,bucket-s3"`
+
+	Object awshlp.ObjectOptions `mapstructure:"object" json:"object" yaml:"object" toml:"object"`
 }
 
 type ModelStatus struct {
@@ -89,6 +92,10 @@ func (c *awsModel) Validate() error {
 		}
 	}
 
+	if e := c.Object.Validate(); e != nil {
+		err.Add(e)
+	}
+
 	if c.Endpoint != "" && c.endpoint == nil {
 		var e error
 		if c.endpoint, e = url.Parse(c.Endpoint); e != nil {
@@ -39,6 +39,7 @@ const (
 	ErrorAws
 	ErrorBucketNotFound
 	ErrorParamsEmpty
+	ErrorOptionsInvalid
 )
 
 func init() {
@@ -62,6 +63,8 @@ func getMessage(code liberr.CodeError) string {
 		return "the specified bucket is not found"
 	case ErrorParamsEmpty:
 		return "at least one parameters needed is empty"
+	case ErrorOptionsInvalid:
+		return "the object options are invalid"
 	}
 
 	return liberr.NullMessage
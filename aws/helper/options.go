@@ -0,0 +1,210 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package helper
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	sdkaws "github.com/aws/aws-sdk-go-v2/aws"
+	sdksss "github.com/aws/aws-sdk-go-v2/service/s3"
+	sdktps "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	libdur "github.com/nabbar/golib/duration"
+)
+
+const (
+	// EncryptionS3 is the server side encryption with the keys managed by S3 (SSE-S3, AES256).
+	EncryptionS3 = "SSE-S3"
+	// EncryptionKMS is the server side encryption with a KMS key (SSE-KMS).
+	EncryptionKMS = "SSE-KMS"
+)
+
+// ObjectOptions are the declarative options applied to each object stored with the put and multipart helpers:
+// server side encryption, object lock and storage class.
+type ObjectOptions struct {
+	// StorageClass is the storage class of the stored objects (STANDARD, STANDARD_IA, GLACIER, ...).
+	// If empty, the default storage class of the bucket is used.
+	StorageClass string `mapstructure:"storageClass" json:"storageClass" yaml:"storageClass" toml:"storageClass"`
+
+	// Encryption is the server side encryption of the stored objects: SSE-S3 or SSE-KMS (AES256 and aws:kms are also accepted).
+	// If empty, the default encryption of the bucket is used.
+	Encryption string `mapstructure:"encryption" json:"encryption" yaml:"encryption" toml:"encryption"`
+
+	// KMSKeyId is the id or the ARN of the KMS key used with the SSE-KMS encryption.
+	// If empty, the aws managed key is used.
+	KMSKeyId string `mapstructure:"kmsKeyId" json:"kmsKeyId" yaml:"kmsKeyId" toml:"kmsKeyId"`
+
+	// BucketKey enable the S3 bucket key with the SSE-KMS encryption to reduce the calls to KMS.
+	BucketKey bool `mapstructure:"bucketKey" json:"bucketKey" yaml:"bucketKey" toml:"bucketKey"`
+
+	// LockMode is the object lock retention mode: GOVERNANCE or COMPLIANCE. The bucket must have the object lock enabled.
+	LockMode string `mapstructure:"lockMode" json:"lockMode" yaml:"lockMode" toml:"lockMode"`
+
+	// LockRetention is the retention duration of the object lock, starting when the object is stored.
+	// It is required with a LockMode.
+	LockRetention libdur.Duration `mapstructure:"lockRetention" json:"lockRetention" yaml:"lockRetention" toml:"lockRetention"`
+
+	// LegalHold apply a legal hold on the stored objects. The bucket must have the object lock enabled.
+	LegalHold bool `mapstructure:"legalHold" json:"legalHold" yaml:"legalHold" toml:"legalHold"`
+}
+
+// IsEmpty return true if no option is defined.
+func (o ObjectOptions) IsEmpty() bool {
+	return o == ObjectOptions{}
+}
+
+// Validate check the consistency of the options and return an ErrorOptionsInvalid error with all the issues as parent.
+func (o ObjectOptions) Validate() error {
+	var err = ErrorOptionsInvalid.Error(nil)
+
+	if len(o.StorageClass) > 0 && len(o.storageClass()) < 1 {
+		err.Add(fmt.Errorf("storage class '%s' is unknown", o.StorageClass))
+	}
+
+	switch enc := o.encryption(); {
+	case len(o.Encryption) > 0 && len(enc) < 1:
+		err.Add(fmt.Errorf("encryption '%s' is unknown", o.Encryption))
+	case enc != sdktps.ServerSideEncryptionAwsKms && len(o.KMSKeyId) > 0:
+		err.Add(fmt.Errorf("kms key id is only allowed with the %s encryption", EncryptionKMS))
+	case enc != sdktps.ServerSideEncryptionAwsKms && o.BucketKey:
+		err.Add(fmt.Errorf("bucket key is only allowed with the %s encryption", EncryptionKMS))
+	}
+
+	if len(o.LockMode) > 0 && len(o.lockMode()) < 1 {
+		err.Add(fmt.Errorf("lock mode '%s' is unknown", o.LockMode))
+	} else if len(o.LockMode) > 0 && o.LockRetention <= 0 {
+		err.Add(fmt.Errorf("lock retention must be greater than zero with a lock mode"))
+	} else if len(o.LockMode) < 1 && o.LockRetention != 0 {
+		err.Add(fmt.Errorf("lock retention is only allowed with a lock mode"))
+	}
+
+	if err.HasParent() {
+		return err
+	}
+
+	return nil
+}
+
+func (o ObjectOptions) storageClass() sdktps.StorageClass {
+	for _, c := range sdktps.StorageClass("").Values() {
+		if strings.EqualFold(string(c), strings.TrimSpace(o.StorageClass)) {
+			return c
+		}
+	}
+
+	return ""
+}
+
+func (o ObjectOptions) encryption() sdktps.ServerSideEncryption {
+	switch strings.ToLower(strings.TrimSpace(o.Encryption)) {
+	case strings.ToLower(EncryptionS3), strings.ToLower(string(sdktps.ServerSideEncryptionAes256)):
+		return sdktps.ServerSideEncryptionAes256
+	case strings.ToLower(EncryptionKMS), string(sdktps.ServerSideEncryptionAwsKms):
+		return sdktps.ServerSideEncryptionAwsKms
+	default:
+		return ""
+	}
+}
+
+func (o ObjectOptions) lockMode() sdktps.ObjectLockMode {
+	for _, m := range sdktps.ObjectLockMode("").Values() {
+		if strings.EqualFold(string(m), strings.TrimSpace(o.LockMode)) {
+			return m
+		}
+	}
+
+	return ""
+}
+
+func (o ObjectOptions) kmsKeyId() *string {
+	if len(o.KMSKeyId) > 0 && o.encryption() == sdktps.ServerSideEncryptionAwsKms {
+		return sdkaws.String(o.KMSKeyId)
+	}
+
+	return nil
+}
+
+func (o ObjectOptions) bucketKey() *bool {
+	if o.BucketKey && o.encryption() == sdktps.ServerSideEncryptionAwsKms {
+		return sdkaws.Bool(true)
+	}
+
+	return nil
+}
+
+func (o ObjectOptions) retainUntil() *time.Time {
+	if len(o.lockMode()) > 0 && o.LockRetention > 0 {
+		return sdkaws.Time(time.Now().Add(o.LockRetention.Time()))
+	}
+
+	return nil
+}
+
+func (o ObjectOptions) legalHold() sdktps.ObjectLockLegalHoldStatus {
+	if o.LegalHold {
+		return sdktps.ObjectLockLegalHoldStatusOn
+	}
+
+	return ""
+}
+
+// ApplyPut set the options into the given PutObject input.
+func (o ObjectOptions) ApplyPut(in *sdksss.PutObjectInput) {
+	if in == nil {
+		return
+	}
+
+	in.StorageClass = o.storageClass()
+	in.ServerSideEncryption = o.encryption()
+	in.SSEKMSKeyId = o.kmsKeyId()
+	in.BucketKeyEnabled = o.bucketKey()
+	in.ObjectLockLegalHoldStatus = o.legalHold()
+
+	if t := o.retainUntil(); t != nil {
+		in.ObjectLockMode = o.lockMode()
+		in.ObjectLockRetainUntilDate = t
+	}
+}
+
+// ApplyMultipart set the options into the given CreateMultipartUpload input.
+func (o ObjectOptions) ApplyMultipart(in *sdksss.CreateMultipartUploadInput) {
+	if in == nil {
+		return
+	}
+
+	in.StorageClass = o.storageClass()
+	in.ServerSideEncryption = o.encryption()
+	in.SSEKMSKeyId = o.kmsKeyId()
+	in.BucketKeyEnabled = o.bucketKey()
+	in.ObjectLockLegalHoldStatus = o.legalHold()
+
+	if t := o.retainUntil(); t != nil {
+		in.ObjectLockMode = o.lockMode()
+		in.ObjectLockRetainUntilDate = t
+	}
+}
@@ -73,6 +73,9 @@ type Config interface {
 
 	GetBucketName() string
 	SetBucketName(bucket string)
+
+	GetObjectOptions() awshlp.ObjectOptions
+	SetObjectOptions(opt awshlp.ObjectOptions)
 }
 
 type AWS interface {
@@ -347,7 +347,10 @@ func (c *client) Object() awsobj.Object {
 	c.m.Lock()
 	defer c.m.Unlock()
 
-	return awsobj.New(c.x, c.c.GetBucketName(), c.c.GetRegion(), c.i, c.s)
+	o := awsobj.New(c.x, c.c.GetBucketName(), c.c.GetRegion(), c.i, c.s)
+	o.SetObjectOptions(c.c.GetObjectOptions())
+
+	return o
 }
 
 func (c *client) Policy() awspol.Policy {
@@ -0,0 +1,128 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package multipart
+
+import (
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	sdksss "github.com/aws/aws-sdk-go-v2/service/s3"
+	libiop "github.com/nabbar/golib/ioutils/ioprogress"
+	iospb "github.com/nabbar/golib/ioutils/spillBuffer"
+	libsiz "github.com/nabbar/golib/size"
+)
+
+const (
+	// DefaultRetry is the number of retry of a failed range by a Downloader if not defined.
+	DefaultRetry = 3
+	// DefaultDownloadMemory is the size of a downloaded object kept in memory before spilling it to a temporary file.
+	DefaultDownloadMemory = 64 * libsiz.SizeMega
+)
+
+// DownloaderClient is the part of the aws S3 client used by the Downloader.
+// The aws sdk S3 client implements this interface.
+type DownloaderClient interface {
+	HeadObject(ctx context.Context, params *sdksss.HeadObjectInput, optFns ...func(*sdksss.Options)) (*sdksss.HeadObjectOutput, error)
+	GetObject(ctx context.Context, params *sdksss.GetObjectInput, optFns ...func(*sdksss.Options)) (*sdksss.GetObjectOutput, error)
+}
+
+var _ DownloaderClient = &sdksss.Client{}
+
+// DownloaderOptions define how a Downloader split and fetch the object.
+type DownloaderOptions struct {
+	// PartSize is the size of each range. If zero, DefaultPartSize is used.
+	PartSize libsiz.Size
+
+	// Concurrency is the number of ranges fetched in parallel. If zero or negative, DefaultConcurrency is used.
+	Concurrency int
+
+	// Retry is the number of retry of a failed range. If zero, DefaultRetry is used, if negative no retry is done.
+	Retry int
+
+	// Checksum allow to verify the content with the ETag of the object when it is a md5 sum: single upload,
+	// or multipart upload with parts of the same size. The objects encrypted with SSE-C or SSE-KMS have
+	// no md5 ETag and cannot be verified.
+	Checksum bool
+
+	// Memory is the size kept in memory by Download before spilling the content to a temporary file.
+	// If zero, DefaultDownloadMemory is used.
+	Memory libsiz.Size
+
+	// TempDir is the directory of the temporary file used by Download. If empty, the default
+	// temporary directory is used.
+	TempDir string
+}
+
+// Downloader fetch an object with parallel ranged requests. Each range is fetched only if the object
+// is not changed since the start of the download, and retried on error.
+// The progress functions are called with the size of each range fetched, the reset function with the
+// size of the object on each start, and the EOF function when the download is completed.
+type Downloader interface {
+	libiop.Progress
+
+	// Download fetch the object into a buffer kept in memory or spilled to a temporary file.
+	// The buffer must be closed by the caller.
+	Download(ctx context.Context) (iospb.Buffer, error)
+
+	// DownloadTo fetch the object at its offset into the given writer and return its size.
+	// To verify the checksum, the writer must implement io.ReaderAt to read back the content.
+	DownloadTo(ctx context.Context, w io.WriterAt) (int64, error)
+}
+
+// NewDownloader return a ranged parallel downloader for the given object into the given bucket.
+func NewDownloader(cli DownloaderClient, bucket, object string, opt DownloaderOptions) Downloader {
+	if opt.Concurrency < 1 {
+		opt.Concurrency = DefaultConcurrency
+	}
+
+	if opt.Retry == 0 {
+		opt.Retry = DefaultRetry
+	} else if opt.Retry < 0 {
+		opt.Retry = 0
+	}
+
+	if opt.PartSize < 1 {
+		opt.PartSize = DefaultPartSize
+	}
+
+	if opt.Memory == 0 {
+		opt.Memory = DefaultDownloadMemory
+	}
+
+	return &dwn{
+		m:  sync.Mutex{},
+		c:  cli,
+		b:  bucket,
+		o:  object,
+		p:  opt,
+		fi: new(atomic.Value),
+		fr: new(atomic.Value),
+		fe: new(atomic.Value),
+	}
+}
@@ -0,0 +1,374 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package multipart
+
+import (
+	"context"
+	/* #nosec */
+	//nolint #nosec
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	sdkaws "github.com/aws/aws-sdk-go-v2/aws"
+	sdksss "github.com/aws/aws-sdk-go-v2/service/s3"
+	sdktps "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	libfpg "github.com/nabbar/golib/file/progress"
+	iospb "github.com/nabbar/golib/ioutils/spillBuffer"
+)
+
+// downloadRetryDelay is the delay before the first retry of a range, multiplied by the attempt number.
+const downloadRetryDelay = 100 * time.Millisecond
+
+type dwn struct {
+	m sync.Mutex // serialize the progress functions
+	c DownloaderClient
+	b string // bucket name
+	o string // object name
+	p DownloaderOptions
+
+	fi *atomic.Value // increment function
+	fr *atomic.Value // reset function
+	fe *atomic.Value // eof function
+}
+
+// dwnInfo is the description of the object to download.
+type dwnInfo struct {
+	size int64  // object size
+	etag string // object etag, used to check the object is not changed
+	part int64  // part size of a multipart etag, used for the checksum
+}
+
+func (d *dwn) RegisterFctIncrement(fct libfpg.FctIncrement) {
+	if fct == nil {
+		fct = func(size int64) {}
+	}
+
+	d.fi.Store(fct)
+}
+
+func (d *dwn) RegisterFctReset(fct libfpg.FctReset) {
+	if fct == nil {
+		fct = func(size, current int64) {}
+	}
+
+	d.fr.Store(fct)
+}
+
+func (d *dwn) RegisterFctEOF(fct libfpg.FctEOF) {
+	if fct == nil {
+		fct = func() {}
+	}
+
+	d.fe.Store(fct)
+}
+
+func (d *dwn) Reset(max int64) {
+	if f, k := d.fr.Load().(libfpg.FctReset); k && f != nil {
+		f(max, 0)
+	}
+}
+
+func (d *dwn) inc(n int64) {
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	if f, k := d.fi.Load().(libfpg.FctIncrement); k && f != nil {
+		f(n)
+	}
+}
+
+func (d *dwn) finish() {
+	if f, k := d.fe.Load().(libfpg.FctEOF); k && f != nil {
+		f()
+	}
+}
+
+func (d *dwn) Download(ctx context.Context) (iospb.Buffer, error) {
+	var b = iospb.New(d.p.Memory.Int64(), d.p.TempDir)
+
+	if _, e := d.DownloadTo(ctx, b); e != nil {
+		_ = b.Close()
+		return nil, e
+	}
+
+	return b, nil
+}
+
+func (d *dwn) DownloadTo(ctx context.Context, w io.WriterAt) (int64, error) {
+	if d.c == nil {
+		return 0, ErrInvalidClient
+	} else if w == nil {
+		return 0, ErrInvalidSource
+	}
+
+	var r, canRead = w.(io.ReaderAt)
+
+	if d.p.Checksum && !canRead {
+		return 0, ErrChecksumUnavailable
+	}
+
+	inf, e := d.head(ctx)
+
+	if e != nil {
+		return 0, e
+	}
+
+	if t, k := w.(interface{ Truncate(size int64) error }); k {
+		if e = t.Truncate(inf.size); e != nil {
+			return 0, e
+		}
+	}
+
+	d.Reset(inf.size)
+
+	if e = d.fetch(ctx, w, inf); e != nil {
+		return 0, e
+	} else if d.p.Checksum {
+		if e = checkETag(r, inf); e != nil {
+			return 0, e
+		}
+	}
+
+	d.finish()
+	return inf.size, nil
+}
+
+func (d *dwn) head(ctx context.Context) (dwnInfo, error) {
+	var inf dwnInfo
+
+	res, e := d.c.HeadObject(ctx, &sdksss.HeadObjectInput{
+		Bucket: sdkaws.String(d.b),
+		Key:    sdkaws.String(d.o),
+	})
+
+	if e != nil {
+		return inf, e
+	} else if res == nil || res.ContentLength == nil || res.ETag == nil {
+		return inf, ErrInvalidResponse
+	}
+
+	inf.size = *res.ContentLength
+	inf.etag = *res.ETag
+
+	// the ETag of the objects encrypted with SSE-KMS or SSE-C is not a md5 sum
+	if d.p.Checksum && (res.SSECustomerAlgorithm != nil || strings.HasPrefix(string(res.ServerSideEncryption), string(sdktps.ServerSideEncryptionAwsKms))) {
+		return inf, ErrChecksumUnavailable
+	}
+
+	if !d.p.Checksum || !strings.Contains(inf.etag, "-") {
+		return inf, nil
+	}
+
+	// the size of the first part is the size of all parts except the last one
+	res, e = d.c.HeadObject(ctx, &sdksss.HeadObjectInput{
+		Bucket:     sdkaws.String(d.b),
+		Key:        sdkaws.String(d.o),
+		IfMatch:    sdkaws.String(inf.etag),
+		PartNumber: sdkaws.Int32(1),
+	})
+
+	if e != nil {
+		return inf, e
+	} else if res == nil || res.ContentLength == nil {
+		return inf, ErrInvalidResponse
+	}
+
+	inf.part = *res.ContentLength
+	return inf, nil
+}
+
+// fetch get in parallel all ranges of the object.
+func (d *dwn) fetch(ctx context.Context, w io.WriterAt, inf dwnInfo) error {
+	var (
+		siz = d.p.PartSize.Int64()
+		job = make(chan int64)
+		wgp sync.WaitGroup
+		err error
+		erm sync.Mutex
+	)
+
+	x, cnl := context.WithCancel(ctx)
+	defer cnl()
+
+	for i := 0; i < d.p.Concurrency; i++ {
+		wgp.Add(1)
+		go func() {
+			defer wgp.Done()
+
+			for off := range job {
+				if e := d.fetchRange(x, w, inf.etag, off, min(siz, inf.size-off)); e != nil {
+					erm.Lock()
+					if err == nil {
+						err = e
+					}
+					erm.Unlock()
+					cnl()
+				}
+			}
+		}()
+	}
+
+	for off := int64(0); off < inf.size && x.Err() == nil; off += siz {
+		select {
+		case job <- off:
+		case <-x.Done():
+		}
+	}
+
+	close(job)
+	wgp.Wait()
+
+	if err != nil {
+		return err
+	}
+
+	return ctx.Err()
+}
+
+// fetchRange get one range of the object, retrying on error.
+func (d *dwn) fetchRange(ctx context.Context, w io.WriterAt, etag string, off, siz int64) error {
+	var err error
+
+	for i := 0; i <= d.p.Retry; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(i) * downloadRetryDelay):
+			}
+		}
+
+		if err = d.getRange(ctx, w, etag, off, siz); err == nil {
+			d.inc(siz)
+			return nil
+		} else if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+
+	return err
+}
+
+func (d *dwn) getRange(ctx context.Context, w io.WriterAt, etag string, off, siz int64) error {
+	res, e := d.c.GetObject(ctx, &sdksss.GetObjectInput{
+		Bucket:  sdkaws.String(d.b),
+		Key:     sdkaws.String(d.o),
+		IfMatch: sdkaws.String(etag),
+		Range:   sdkaws.String(fmt.Sprintf("bytes=%d-%d", off, off+siz-1)),
+	})
+
+	if e != nil {
+		return e
+	} else if res == nil || res.Body == nil {
+		return ErrInvalidResponse
+	}
+
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	n, e := io.Copy(io.NewOffsetWriter(w, off), io.LimitReader(res.Body, siz))
+
+	if e != nil {
+		return e
+	} else if n != siz {
+		return io.ErrUnexpectedEOF
+	}
+
+	return nil
+}
+
+// checkETag verify the content with the ETag if it is a md5 sum of a single or a multipart upload.
+func checkETag(r io.ReaderAt, inf dwnInfo) error {
+	var (
+		tag = strings.Trim(inf.etag, "\"")
+		num int
+		lst []byte
+	)
+
+	if i := strings.Index(tag, "-"); i > 0 {
+		if n, e := strconv.Atoi(tag[i+1:]); e != nil {
+			return nil
+		} else {
+			num = n
+			tag = tag[:i]
+		}
+	}
+
+	if len(tag) != md5.Size*2 {
+		return nil
+	} else if _, e := hex.DecodeString(tag); e != nil {
+		return nil
+	}
+
+	if num < 1 {
+		if s, e := md5Section(r, 0, inf.size); e != nil {
+			return e
+		} else if hex.EncodeToString(s) != tag {
+			return ErrInvalidChecksum
+		}
+		return nil
+	} else if inf.part < 1 {
+		return ErrInvalidChecksum
+	}
+
+	for off := int64(0); off < inf.size; off += inf.part {
+		if s, e := md5Section(r, off, min(inf.part, inf.size-off)); e != nil {
+			return e
+		} else {
+			lst = append(lst, s...)
+		}
+	}
+
+	/* #nosec */
+	//nolint #nosec
+	if s := md5.Sum(lst); len(lst) != num*md5.Size || hex.EncodeToString(s[:]) != tag {
+		return ErrInvalidChecksum
+	}
+
+	return nil
+}
+
+func md5Section(r io.ReaderAt, off, siz int64) ([]byte, error) {
+	/* #nosec */
+	//nolint #nosec
+	var h = md5.New()
+
+	if _, e := io.Copy(h, io.NewSectionReader(r, off, siz)); e != nil && !errors.Is(e, io.EOF) {
+		return nil, e
+	}
+
+	return h.Sum(nil), nil
+}
@@ -34,4 +34,10 @@ var (
 	ErrInvalidUploadID           = fmt.Errorf("invalid aws s3 MPU Upload ID")
 	ErrInvalidTMPFile            = fmt.Errorf("invalid working or temporary file")
 	ErrWorkingPartFileExceedSize = fmt.Errorf("working or temporary file used exceed the aws S3 size limits")
+	ErrInvalidSource             = fmt.Errorf("invalid source or source size")
+	ErrInvalidState              = fmt.Errorf("invalid or not matching upload state")
+	ErrUploadRunning             = fmt.Errorf("upload is already running")
+	ErrUploadPaused              = fmt.Errorf("upload is paused")
+	ErrInvalidChecksum           = fmt.Errorf("content does not match the checksum of the object")
+	ErrChecksumUnavailable       = fmt.Errorf("checksum cannot be verified: content cannot be read back or etag is not a md5 sum")
 )
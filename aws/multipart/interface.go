@@ -32,6 +32,7 @@ import (
 	"sync"
 
 	sdksss "github.com/aws/aws-sdk-go-v2/service/s3"
+	awshlp "github.com/nabbar/golib/aws/helper"
 	libctx "github.com/nabbar/golib/context"
 	libsiz "github.com/nabbar/golib/size"
 )
@@ -50,6 +51,7 @@ type MultiPart interface {
 
 	RegisterContext(fct libctx.FuncContext)
 	RegisterClientS3(fct FuncClientS3)
+	RegisterObjectOptions(opt awshlp.ObjectOptions)
 	RegisterMultipartID(id string)
 	RegisterWorkingFile(file string, truncate bool) error
 	RegisterFuncOnPushPart(fct func(eTag string, e error))
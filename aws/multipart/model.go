@@ -32,6 +32,7 @@ import (
 
 	sdksss "github.com/aws/aws-sdk-go-v2/service/s3"
 	sdktyp "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	awshlp "github.com/nabbar/golib/aws/helper"
 	libctx "github.com/nabbar/golib/context"
 	libfpg "github.com/nabbar/golib/file/progress"
 	libsiz "github.com/nabbar/golib/size"
@@ -48,6 +49,7 @@ type mpu struct {
 	n int32                  // part counter
 	l []sdktyp.CompletedPart // slice of sent part to prepare complete MPU
 	w libfpg.Progress        // working file or temporary file
+	p awshlp.ObjectOptions   // object options
 
 	// trigger function
 	fc func(nPart int, obj string, e error) // on complete
@@ -111,6 +113,28 @@ func (m *mpu) getClient() *sdksss.Client {
 	}
 }
 
+func (m *mpu) RegisterObjectOptions(opt awshlp.ObjectOptions) {
+	if m == nil {
+		return
+	}
+
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	m.p = opt
+}
+
+func (m *mpu) getObjectOptions() awshlp.ObjectOptions {
+	if m == nil {
+		return awshlp.ObjectOptions{}
+	}
+
+	m.m.RLock()
+	defer m.m.RUnlock()
+
+	return m.p
+}
+
 func (m *mpu) RegisterMultipartID(id string) {
 	if m == nil {
 		return
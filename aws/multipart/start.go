@@ -44,7 +44,11 @@ func (m *mpu) IsStarted() bool {
 }
 
 func (m *mpu) getMimeType() string {
-	if t := mime.TypeByExtension(filepath.Ext(m.getObject())); t == "" {
+	return mimeType(m.getObject())
+}
+
+func mimeType(object string) string {
+	if t := mime.TypeByExtension(filepath.Ext(object)); t == "" {
 		return "application/octet-stream"
 	} else {
 		return t
@@ -64,17 +68,23 @@ func (m *mpu) StartMPU() error {
 		ctx = m.getContext()
 		obj = m.getObject()
 		bck = m.getBucket()
+		opt = m.getObjectOptions()
 	)
 
 	if cli = m.getClient(); cli == nil {
 		return ErrInvalidClient
+	} else if err = opt.Validate(); err != nil {
+		return err
 	}
 
-	res, err = cli.CreateMultipartUpload(ctx, &sdksss.CreateMultipartUploadInput{
+	var in = &sdksss.CreateMultipartUploadInput{
 		Key:         sdkaws.String(obj),
 		Bucket:      sdkaws.String(bck),
 		ContentType: sdkaws.String(tpe),
-	})
+	}
+
+	opt.ApplyMultipart(in)
+	res, err = cli.CreateMultipartUpload(ctx, in)
 
 	if err != nil {
 		return err
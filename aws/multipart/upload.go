@@ -0,0 +1,117 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package multipart
+
+import (
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	sdksss "github.com/aws/aws-sdk-go-v2/service/s3"
+	awshlp "github.com/nabbar/golib/aws/helper"
+	libiop "github.com/nabbar/golib/ioutils/ioprogress"
+	libsiz "github.com/nabbar/golib/size"
+)
+
+// DefaultConcurrency is the number of parts sent in parallel by an Uploader if not defined.
+const DefaultConcurrency = 4
+
+// UploaderClient is the part of the aws S3 client used by the Uploader.
+// The aws sdk S3 client implements this interface.
+type UploaderClient interface {
+	CreateMultipartUpload(ctx context.Context, params *sdksss.CreateMultipartUploadInput, optFns ...func(*sdksss.Options)) (*sdksss.CreateMultipartUploadOutput, error)
+	UploadPart(ctx context.Context, params *sdksss.UploadPartInput, optFns ...func(*sdksss.Options)) (*sdksss.UploadPartOutput, error)
+	ListParts(ctx context.Context, params *sdksss.ListPartsInput, optFns ...func(*sdksss.Options)) (*sdksss.ListPartsOutput, error)
+	CompleteMultipartUpload(ctx context.Context, params *sdksss.CompleteMultipartUploadInput, optFns ...func(*sdksss.Options)) (*sdksss.CompleteMultipartUploadOutput, error)
+	AbortMultipartUpload(ctx context.Context, params *sdksss.AbortMultipartUploadInput, optFns ...func(*sdksss.Options)) (*sdksss.AbortMultipartUploadOutput, error)
+}
+
+var _ UploaderClient = &sdksss.Client{}
+
+// UploaderOptions define how an Uploader split and send the source.
+type UploaderOptions struct {
+	// PartSize is the size of each part. It is adjusted to respect the aws S3 limits of parts.
+	// If lower than the minimum size of part, DefaultPartSize is used.
+	PartSize libsiz.Size
+
+	// Concurrency is the number of parts sent in parallel. If zero or negative, DefaultConcurrency is used.
+	// Each part in progress is loaded in memory.
+	Concurrency int
+
+	// StateFile is the path of the file used to persist the state of the upload after each part.
+	// An upload paused, cancelled or failed is resumed from this file by the next Upload.
+	// The file is removed when the upload is completed or aborted. If empty, the upload cannot be resumed.
+	StateFile string
+
+	// Object is the encryption, object lock and storage class options applied to the uploaded object.
+	Object awshlp.ObjectOptions
+}
+
+// Uploader is a managed multipart upload, sending the parts of a random access source in parallel.
+// The progress functions are called with the size of each part sent, the reset function with the total
+// size and the size already sent on each start or resume, and the EOF function when the upload is completed.
+type Uploader interface {
+	libiop.Progress
+
+	// Upload send the source of the given size, resuming the upload described into the state file if any.
+	// It returns ErrUploadPaused if Pause is called before the end of the upload. On any error, the
+	// multipart upload is kept on the bucket to be resumed later: call Abort to release it.
+	Upload(ctx context.Context, src io.ReaderAt, size int64) error
+
+	// Pause stop the upload after the parts in progress. The state is kept to resume the upload.
+	Pause()
+
+	// Abort cancel the multipart upload on the bucket and remove the state file.
+	Abort(ctx context.Context) error
+
+	// State return a copy of the current state of the upload.
+	State() State
+}
+
+// NewUploader return a managed multipart uploader for the given object into the given bucket.
+func NewUploader(cli UploaderClient, bucket, object string, opt UploaderOptions) Uploader {
+	if opt.Concurrency < 1 {
+		opt.Concurrency = DefaultConcurrency
+	}
+
+	return &upl{
+		m: sync.Mutex{},
+		c: cli,
+		o: opt,
+		s: State{
+			Bucket: bucket,
+			Object: object,
+			Parts:  make([]StatePart, 0),
+		},
+		r:  new(atomic.Bool),
+		p:  new(atomic.Bool),
+		fi: new(atomic.Value),
+		fr: new(atomic.Value),
+		fe: new(atomic.Value),
+	}
+}
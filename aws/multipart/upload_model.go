@@ -0,0 +1,442 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package multipart
+
+import (
+	"bytes"
+	"context"
+	/* #nosec */
+	//nolint #nosec
+	"crypto/md5"
+	"encoding/base64"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	sdkaws "github.com/aws/aws-sdk-go-v2/aws"
+	sdksss "github.com/aws/aws-sdk-go-v2/service/s3"
+	sdktyp "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	libfpg "github.com/nabbar/golib/file/progress"
+	libsiz "github.com/nabbar/golib/size"
+)
+
+type upl struct {
+	m sync.Mutex
+	n sync.Mutex // serialize the progress functions
+	c UploaderClient
+	o UploaderOptions
+	s State        // current state
+	r *atomic.Bool // running
+	p *atomic.Bool // pause requested
+
+	fi *atomic.Value // increment function
+	fr *atomic.Value // reset function
+	fe *atomic.Value // eof function
+}
+
+func (u *upl) RegisterFctIncrement(fct libfpg.FctIncrement) {
+	if fct == nil {
+		fct = func(size int64) {}
+	}
+
+	u.fi.Store(fct)
+}
+
+func (u *upl) RegisterFctReset(fct libfpg.FctReset) {
+	if fct == nil {
+		fct = func(size, current int64) {}
+	}
+
+	u.fr.Store(fct)
+}
+
+func (u *upl) RegisterFctEOF(fct libfpg.FctEOF) {
+	if fct == nil {
+		fct = func() {}
+	}
+
+	u.fe.Store(fct)
+}
+
+func (u *upl) Reset(max int64) {
+	if f, k := u.fr.Load().(libfpg.FctReset); k && f != nil {
+		f(max, u.State().Sent())
+	}
+}
+
+func (u *upl) inc(n int64) {
+	u.n.Lock()
+	defer u.n.Unlock()
+
+	if f, k := u.fi.Load().(libfpg.FctIncrement); k && f != nil {
+		f(n)
+	}
+}
+
+func (u *upl) finish() {
+	if f, k := u.fe.Load().(libfpg.FctEOF); k && f != nil {
+		f()
+	}
+}
+
+func (u *upl) Pause() {
+	if u.r.Load() {
+		u.p.Store(true)
+	}
+}
+
+func (u *upl) State() State {
+	u.m.Lock()
+	defer u.m.Unlock()
+
+	return u.s.clone()
+}
+
+func (u *upl) Abort(ctx context.Context) error {
+	if u.r.Load() {
+		return ErrUploadRunning
+	}
+
+	u.m.Lock()
+	defer u.m.Unlock()
+
+	if len(u.s.UploadID) < 1 {
+		if s, k, e := loadState(u.o.StateFile); e == nil && k && s.Bucket == u.s.Bucket && s.Object == u.s.Object {
+			u.s = s
+		}
+	}
+
+	if len(u.s.UploadID) > 0 {
+		if _, e := u.c.AbortMultipartUpload(ctx, &sdksss.AbortMultipartUploadInput{
+			Bucket:   sdkaws.String(u.s.Bucket),
+			Key:      sdkaws.String(u.s.Object),
+			UploadId: sdkaws.String(u.s.UploadID),
+		}); e != nil && !isNoSuchUpload(e) {
+			return e
+		}
+	}
+
+	u.s.UploadID = ""
+	u.s.Parts = make([]StatePart, 0)
+
+	return removeState(u.o.StateFile)
+}
+
+func (u *upl) Upload(ctx context.Context, src io.ReaderAt, size int64) error {
+	if u.c == nil {
+		return ErrInvalidClient
+	} else if src == nil || size < 0 {
+		return ErrInvalidSource
+	} else if size > MaxObjectSize.Int64() {
+		return ErrWorkingPartFileExceedSize
+	} else if e := u.o.Object.Validate(); e != nil {
+		return e
+	} else if !u.r.CompareAndSwap(false, true) {
+		return ErrUploadRunning
+	}
+
+	defer u.r.Store(false)
+	u.p.Store(false)
+
+	if e := u.prepare(ctx, size); e != nil {
+		return e
+	}
+
+	u.Reset(size)
+
+	if e := u.send(ctx, src); e != nil {
+		return e
+	} else if s := u.State(); u.p.Load() && int32(len(s.Parts)) < s.NumberParts() {
+		return ErrUploadPaused
+	} else if e = u.complete(ctx); e != nil {
+		return e
+	}
+
+	u.finish()
+	return nil
+}
+
+// prepare load the state to resume or start a new multipart upload.
+func (u *upl) prepare(ctx context.Context, size int64) error {
+	u.m.Lock()
+	defer u.m.Unlock()
+
+	if s, k, e := loadState(u.o.StateFile); e != nil {
+		return e
+	} else if k {
+		if s.Bucket != u.s.Bucket || s.Object != u.s.Object || s.Size != size {
+			return ErrInvalidState
+		}
+
+		u.s = s
+	}
+
+	if len(u.s.UploadID) > 0 && u.s.Size == size && u.s.PartSize > 0 {
+		if e := u.listParts(ctx); e == nil {
+			return saveState(u.o.StateFile, u.s)
+		} else if !isNoSuchUpload(e) {
+			return e
+		}
+	}
+
+	prt, e := GetOptimalPartSize(libsiz.SizeFromInt64(size), u.o.PartSize)
+
+	if e != nil {
+		return e
+	}
+
+	var in = &sdksss.CreateMultipartUploadInput{
+		Bucket:      sdkaws.String(u.s.Bucket),
+		Key:         sdkaws.String(u.s.Object),
+		ContentType: sdkaws.String(mimeType(u.s.Object)),
+	}
+
+	u.o.Object.ApplyMultipart(in)
+	res, e := u.c.CreateMultipartUpload(ctx, in)
+
+	if e != nil {
+		return e
+	} else if res == nil || res.UploadId == nil || len(*res.UploadId) < 1 {
+		return ErrInvalidResponse
+	}
+
+	u.s.UploadID = *res.UploadId
+	u.s.Size = size
+	u.s.PartSize = prt.Int64()
+	u.s.Parts = make([]StatePart, 0)
+
+	return saveState(u.o.StateFile, u.s)
+}
+
+// listParts replace the parts of the state by the parts really stored on the bucket
+// with the expected size. Must be called with the lock held.
+func (u *upl) listParts(ctx context.Context) error {
+	var (
+		lst = make([]StatePart, 0)
+		mrk *string
+	)
+
+	for {
+		res, e := u.c.ListParts(ctx, &sdksss.ListPartsInput{
+			Bucket:           sdkaws.String(u.s.Bucket),
+			Key:              sdkaws.String(u.s.Object),
+			UploadId:         sdkaws.String(u.s.UploadID),
+			PartNumberMarker: mrk,
+		})
+
+		if e != nil {
+			return e
+		} else if res == nil {
+			return ErrInvalidResponse
+		}
+
+		for _, p := range res.Parts {
+			if p.PartNumber == nil || p.ETag == nil || p.Size == nil {
+				continue
+			} else if n := *p.PartNumber; n < 1 || n > u.s.NumberParts() {
+				continue
+			} else if _, s := u.s.partRange(n); s != *p.Size {
+				continue
+			} else {
+				lst = append(lst, StatePart{
+					Number: n,
+					ETag:   strings.Replace(*p.ETag, "\"", "", -1),
+					Size:   s,
+				})
+			}
+		}
+
+		if res.IsTruncated == nil || !*res.IsTruncated || res.NextPartNumberMarker == nil {
+			break
+		}
+
+		mrk = res.NextPartNumberMarker
+	}
+
+	u.s.Parts = lst
+	u.s.sortParts()
+
+	return nil
+}
+
+// send upload in parallel all parts not already sent.
+func (u *upl) send(ctx context.Context, src io.ReaderAt) error {
+	var (
+		s   = u.State()
+		don = make(map[int32]bool, len(s.Parts))
+		job = make(chan int32)
+		wgp sync.WaitGroup
+		err error
+		erm sync.Mutex
+	)
+
+	x, cnl := context.WithCancel(ctx)
+	defer cnl()
+
+	for _, p := range s.Parts {
+		don[p.Number] = true
+	}
+
+	for i := 0; i < u.o.Concurrency; i++ {
+		wgp.Add(1)
+		go func() {
+			defer wgp.Done()
+
+			for n := range job {
+				if e := u.sendPart(x, src, s, n); e != nil {
+					erm.Lock()
+					if err == nil {
+						err = e
+					}
+					erm.Unlock()
+					cnl()
+				}
+			}
+		}()
+	}
+
+	for n := int32(1); n <= s.NumberParts(); n++ {
+		if don[n] {
+			continue
+		} else if u.p.Load() || x.Err() != nil {
+			break
+		}
+
+		select {
+		case job <- n:
+		case <-x.Done():
+		}
+	}
+
+	close(job)
+	wgp.Wait()
+
+	if err != nil {
+		return err
+	}
+
+	return ctx.Err()
+}
+
+func (u *upl) sendPart(ctx context.Context, src io.ReaderAt, s State, num int32) error {
+	if e := ctx.Err(); e != nil {
+		return e
+	}
+
+	var (
+		off, siz = s.partRange(num)
+		buf      = make([]byte, siz)
+
+		/* #nosec */
+		//nolint #nosec
+		hsh = md5.New()
+	)
+
+	if n, e := src.ReadAt(buf, off); int64(n) < siz {
+		if e == nil || errors.Is(e, io.EOF) {
+			e = ErrInvalidSource
+		}
+		return e
+	}
+
+	_, _ = hsh.Write(buf)
+
+	res, e := u.c.UploadPart(ctx, &sdksss.UploadPartInput{
+		Bucket:        sdkaws.String(s.Bucket),
+		Key:           sdkaws.String(s.Object),
+		UploadId:      sdkaws.String(s.UploadID),
+		PartNumber:    sdkaws.Int32(num),
+		ContentLength: sdkaws.Int64(siz),
+		Body:          bytes.NewReader(buf),
+		RequestPayer:  sdktyp.RequestPayerRequester,
+		ContentMD5:    sdkaws.String(base64.StdEncoding.EncodeToString(hsh.Sum(nil))),
+	})
+
+	if e != nil {
+		return e
+	} else if res == nil || res.ETag == nil || len(*res.ETag) < 1 {
+		return ErrInvalidResponse
+	}
+
+	u.m.Lock()
+	u.s.Parts = append(u.s.Parts, StatePart{
+		Number: num,
+		ETag:   strings.Replace(*res.ETag, "\"", "", -1),
+		Size:   siz,
+	})
+	u.s.sortParts()
+	e = saveState(u.o.StateFile, u.s)
+	u.m.Unlock()
+
+	u.inc(siz)
+
+	return e
+}
+
+func (u *upl) complete(ctx context.Context) error {
+	u.m.Lock()
+	defer u.m.Unlock()
+
+	if int32(len(u.s.Parts)) != u.s.NumberParts() {
+		return ErrInvalidState
+	}
+
+	var lst = make([]sdktyp.CompletedPart, 0, len(u.s.Parts))
+
+	for _, p := range u.s.Parts {
+		lst = append(lst, sdktyp.CompletedPart{
+			ETag:       sdkaws.String(p.ETag),
+			PartNumber: sdkaws.Int32(p.Number),
+		})
+	}
+
+	res, e := u.c.CompleteMultipartUpload(ctx, &sdksss.CompleteMultipartUploadInput{
+		Bucket:   sdkaws.String(u.s.Bucket),
+		Key:      sdkaws.String(u.s.Object),
+		UploadId: sdkaws.String(u.s.UploadID),
+		MultipartUpload: &sdktyp.CompletedMultipartUpload{
+			Parts: lst,
+		},
+		RequestPayer: sdktyp.RequestPayerRequester,
+	})
+
+	if e != nil {
+		return e
+	} else if res == nil {
+		return ErrInvalidResponse
+	}
+
+	u.s.UploadID = ""
+	return removeState(u.o.StateFile)
+}
+
+func isNoSuchUpload(e error) bool {
+	var n *sdktyp.NoSuchUpload
+	return errors.As(e, &n)
+}
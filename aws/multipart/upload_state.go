@@ -0,0 +1,147 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package multipart
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// StatePart is a part already sent of a managed multipart upload.
+type StatePart struct {
+	Number int32  `json:"number"`
+	ETag   string `json:"etag"`
+	Size   int64  `json:"size"`
+}
+
+// State is the persisted state of a managed multipart upload, used to resume it.
+type State struct {
+	Bucket   string      `json:"bucket"`
+	Object   string      `json:"object"`
+	UploadID string      `json:"upload_id"`
+	Size     int64       `json:"size"`
+	PartSize int64       `json:"part_size"`
+	Parts    []StatePart `json:"parts"`
+}
+
+// Sent return the number of bytes of the parts already sent.
+func (s State) Sent() int64 {
+	var n int64
+
+	for _, p := range s.Parts {
+		n += p.Size
+	}
+
+	return n
+}
+
+// NumberParts return the total number of parts of the upload.
+func (s State) NumberParts() int32 {
+	if s.PartSize < 1 || s.Size < 1 {
+		return 1
+	}
+
+	return int32((s.Size + s.PartSize - 1) / s.PartSize)
+}
+
+func (s State) partRange(num int32) (off int64, siz int64) {
+	off = int64(num-1) * s.PartSize
+	siz = s.PartSize
+
+	if off+siz > s.Size {
+		siz = s.Size - off
+	}
+
+	return off, siz
+}
+
+func (s State) clone() State {
+	var r = s
+	r.Parts = make([]StatePart, len(s.Parts))
+	copy(r.Parts, s.Parts)
+	return r
+}
+
+func (s *State) sortParts() {
+	sort.Slice(s.Parts, func(i, j int) bool {
+		return s.Parts[i].Number < s.Parts[j].Number
+	})
+}
+
+// loadState read the state file. A missing state file give an empty state without error.
+func loadState(path string) (State, bool, error) {
+	var s State
+
+	if len(path) < 1 {
+		return s, false, nil
+	} else if p, e := os.ReadFile(filepath.Clean(path)); errors.Is(e, os.ErrNotExist) {
+		return s, false, nil
+	} else if e != nil {
+		return s, false, e
+	} else if e = json.Unmarshal(p, &s); e != nil {
+		return s, false, ErrInvalidState
+	}
+
+	return s, true, nil
+}
+
+// saveState write the state file atomically.
+func saveState(path string, s State) error {
+	if len(path) < 1 {
+		return nil
+	}
+
+	p, e := json.Marshal(s)
+
+	if e != nil {
+		return e
+	}
+
+	var tmp = filepath.Clean(path) + ".tmp"
+
+	if e = os.WriteFile(tmp, p, 0600); e != nil {
+		return e
+	} else if e = os.Rename(tmp, filepath.Clean(path)); e != nil {
+		_ = os.Remove(tmp)
+		return e
+	}
+
+	return nil
+}
+
+func removeState(path string) error {
+	if len(path) < 1 {
+		return nil
+	} else if e := os.Remove(filepath.Clean(path)); e != nil && !errors.Is(e, os.ErrNotExist) {
+		return e
+	}
+
+	return nil
+}
@@ -44,6 +44,7 @@ type client struct {
 	libhlp.Helper
 	iam *sdkiam.Client
 	s3  *sdksss.Client
+	opt libhlp.ObjectOptions
 }
 
 type WalkFunc func(err error, obj sdktps.Object) error
@@ -51,6 +52,11 @@ type VersionWalkFunc func(err error, obj sdktps.ObjectVersion) error
 type DelMakWalkFunc func(err error, del sdktps.DeleteMarkerEntry) error
 
 type Object interface {
+	// SetObjectOptions define the encryption, object lock and storage class options applied by the put and multipart helpers.
+	SetObjectOptions(opt libhlp.ObjectOptions)
+	// GetObjectOptions return the options applied by the put and multipart helpers.
+	GetObjectOptions() libhlp.ObjectOptions
+
 	Find(regex string) ([]string, error)
 	Size(object string) (size int64, err error)
 
@@ -75,6 +81,8 @@ type Object interface {
 	MultipartPut(object string, body io.Reader) error
 	MultipartPutCustom(partSize libsiz.Size, object string, body io.Reader) error
 	MultipartCancel(uploadId, key string) error
+	MultipartUploader(object string, opt libmpu.UploaderOptions) libmpu.Uploader
+	MultipartDownloader(object string, opt libmpu.DownloaderOptions) libmpu.Downloader
 
 	UpdateMetadata(meta *sdksss.CopyObjectInput) error
 	SetWebsite(object, redirect string) error
@@ -78,10 +78,28 @@ func (cli *client) MultipartNew(partSize libsiz.Size, bucket, object string) lib
 	m.RegisterClientS3(func() *sdksss.Client {
 		return cli.s3
 	})
+	m.RegisterObjectOptions(cli.opt)
 
 	return m
 }
 
+// MultipartUploader return a managed multipart uploader sending the parts in parallel,
+// able to pause and resume the upload with a state file. The object options of the client are used if the
+// given options have none.
+func (cli *client) MultipartUploader(object string, opt libmpu.UploaderOptions) libmpu.Uploader {
+	if opt.Object.IsEmpty() {
+		opt.Object = cli.opt
+	}
+
+	return libmpu.NewUploader(cli.s3, cli.GetBucketName(), object, opt)
+}
+
+// MultipartDownloader return a downloader fetching the object with parallel ranged requests,
+// retrying the failed ranges and verifying the content with the ETag.
+func (cli *client) MultipartDownloader(object string, opt libmpu.DownloaderOptions) libmpu.Downloader {
+	return libmpu.NewDownloader(cli.s3, cli.GetBucketName(), object, opt)
+}
+
 func (cli *client) MultipartPut(object string, body io.Reader) error {
 	return cli.MultipartPutCustom(libmpu.DefaultPartSize, object, body)
 }
@@ -36,6 +36,14 @@ import (
 	libhlp "github.com/nabbar/golib/aws/helper"
 )
 
+func (cli *client) SetObjectOptions(opt libhlp.ObjectOptions) {
+	cli.opt = opt
+}
+
+func (cli *client) GetObjectOptions() libhlp.ObjectOptions {
+	return cli.opt
+}
+
 func (cli *client) List(continuationToken string) ([]sdktps.Object, string, int64, error) {
 	return cli.ListPrefix(continuationToken, "")
 }
@@ -151,12 +159,19 @@ func (cli *client) Put(object string, body io.Reader) error {
 		tpe = sdkaws.String(t)
 	}
 
-	out, err := cli.s3.PutObject(cli.GetContext(), &sdksss.PutObjectInput{
+	if err := cli.opt.Validate(); err != nil {
+		return err
+	}
+
+	in := &sdksss.PutObjectInput{
 		Bucket:      cli.GetBucketAws(),
 		Key:         sdkaws.String(object),
 		Body:        body,
 		ContentType: tpe,
-	})
+	}
+
+	cli.opt.ApplyPut(in)
+	out, err := cli.s3.PutObject(cli.GetContext(), in)
 
 	if err != nil {
 		return cli.GetError(err)
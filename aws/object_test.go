@@ -28,6 +28,9 @@ package aws_test
 import (
 	"bytes"
 
+	awshlp "github.com/nabbar/golib/aws/helper"
+	libmpu "github.com/nabbar/golib/aws/multipart"
+	libdur "github.com/nabbar/golib/duration"
 	libsiz "github.com/nabbar/golib/size"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -77,6 +80,48 @@ var _ = Describe("Object", func() {
 		})
 	})
 
+	Context("Multipart uploader", func() {
+		It("Must fail as the bucket doesn't exists - 5", func() {
+			var (
+				b = make([]byte, 10*libsiz.SizeMega.Int64())
+				u = cli.Object().MultipartUploader("object", libmpu.UploaderOptions{Concurrency: 2})
+			)
+
+			err := u.Upload(ctx, bytes.NewReader(b), int64(len(b)))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("Multipart downloader", func() {
+		It("Must fail as the bucket doesn't exists - 5", func() {
+			b, err := cli.Object().MultipartDownloader("object", libmpu.DownloaderOptions{Concurrency: 2}).Download(ctx)
+
+			defer func() {
+				if b != nil {
+					_ = b.Close()
+				}
+			}()
+
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("Object options", func() {
+		It("Must reject inconsistent options", func() {
+			Expect(awshlp.ObjectOptions{Encryption: awshlp.EncryptionKMS, KMSKeyId: "key", LockMode: "governance", LockRetention: libdur.Days(1)}.Validate()).ToNot(HaveOccurred())
+			Expect(awshlp.ObjectOptions{StorageClass: "unknown"}.Validate()).To(HaveOccurred())
+			Expect(awshlp.ObjectOptions{Encryption: awshlp.EncryptionS3, KMSKeyId: "key"}.Validate()).To(HaveOccurred())
+			Expect(awshlp.ObjectOptions{LockMode: "compliance"}.Validate()).To(HaveOccurred())
+		})
+
+		It("Must fail to put with invalid options", func() {
+			o := cli.Object()
+			o.SetObjectOptions(awshlp.ObjectOptions{Encryption: "unknown"})
+			Expect(o.GetObjectOptions().Encryption).To(Equal("unknown"))
+			Expect(o.Put("object", bytes.NewReader([]byte("Hello")))).To(HaveOccurred())
+		})
+	})
+
 	Context("Delete object no check", func() {
 		It("Must fail as the bucket doesn't exists - 6", func() {
 			err := cli.Object().Delete(false, "object")
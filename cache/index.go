@@ -0,0 +1,168 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package cache
+
+import (
+	"container/heap"
+	"container/list"
+	"time"
+)
+
+type entry[K comparable, V any] struct {
+	k K
+	v V
+	x time.Time     // expiration, zero if none
+	z int64         // size, zero without size function
+	f uint64        // frequency of use, lfu only
+	t uint64        // last use sequence, lfu only
+	i int           // heap index, lfu only
+	e *list.Element // list element, lru only
+}
+
+func (e *entry[K, V]) expired(now time.Time) bool {
+	return !e.x.IsZero() && !now.Before(e.x)
+}
+
+// index keeps the order of eviction of the entries.
+type index[K comparable, V any] interface {
+	push(e *entry[K, V])
+	touch(e *entry[K, V])
+	remove(e *entry[K, V])
+	victim() *entry[K, V]
+	clean()
+}
+
+func newIndex[K comparable, V any](p Policy) index[K, V] {
+	if p == PolicyLFU {
+		return &lfu[K, V]{}
+	}
+
+	return &lru[K, V]{
+		l: list.New(),
+	}
+}
+
+// lru is the index of the least recently used entries, the most recently used first.
+type lru[K comparable, V any] struct {
+	l *list.List
+}
+
+func (o *lru[K, V]) push(e *entry[K, V]) {
+	e.e = o.l.PushFront(e)
+}
+
+func (o *lru[K, V]) touch(e *entry[K, V]) {
+	o.l.MoveToFront(e.e)
+}
+
+func (o *lru[K, V]) remove(e *entry[K, V]) {
+	o.l.Remove(e.e)
+	e.e = nil
+}
+
+func (o *lru[K, V]) victim() *entry[K, V] {
+	if b := o.l.Back(); b != nil {
+		return b.Value.(*entry[K, V])
+	}
+
+	return nil
+}
+
+func (o *lru[K, V]) clean() {
+	o.l.Init()
+}
+
+// lfu is the index of the least frequently used entries, a min heap on the frequency then the last use.
+type lfu[K comparable, V any] struct {
+	h []*entry[K, V]
+	s uint64 // use sequence
+}
+
+func (o *lfu[K, V]) Len() int {
+	return len(o.h)
+}
+
+func (o *lfu[K, V]) Less(i, j int) bool {
+	if o.h[i].f != o.h[j].f {
+		return o.h[i].f < o.h[j].f
+	}
+
+	return o.h[i].t < o.h[j].t
+}
+
+func (o *lfu[K, V]) Swap(i, j int) {
+	o.h[i], o.h[j] = o.h[j], o.h[i]
+	o.h[i].i = i
+	o.h[j].i = j
+}
+
+func (o *lfu[K, V]) Push(x any) {
+	e := x.(*entry[K, V])
+	e.i = len(o.h)
+	o.h = append(o.h, e)
+}
+
+func (o *lfu[K, V]) Pop() any {
+	n := len(o.h) - 1
+	e := o.h[n]
+	o.h[n] = nil
+	o.h = o.h[:n]
+	e.i = -1
+	return e
+}
+
+func (o *lfu[K, V]) push(e *entry[K, V]) {
+	o.s++
+	e.f = 1
+	e.t = o.s
+	heap.Push(o, e)
+}
+
+func (o *lfu[K, V]) touch(e *entry[K, V]) {
+	o.s++
+	e.f++
+	e.t = o.s
+	heap.Fix(o, e.i)
+}
+
+func (o *lfu[K, V]) remove(e *entry[K, V]) {
+	if e.i >= 0 && e.i < len(o.h) {
+		heap.Remove(o, e.i)
+	}
+}
+
+func (o *lfu[K, V]) victim() *entry[K, V] {
+	if len(o.h) > 0 {
+		return o.h[0]
+	}
+
+	return nil
+}
+
+func (o *lfu[K, V]) clean() {
+	o.h = nil
+}
@@ -0,0 +1,81 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package cache
+
+import "time"
+
+// Policy is the eviction policy of a typed cache when its maximum number of entries is reached.
+type Policy uint8
+
+const (
+	// PolicyLRU evicts the least recently used entry.
+	PolicyLRU Policy = iota
+	// PolicyLFU evicts the least frequently used entry, the least recently used between equals.
+	PolicyLFU
+)
+
+func (p Policy) String() string {
+	switch p {
+	case PolicyLFU:
+		return "lfu"
+	default:
+		return "lru"
+	}
+}
+
+// Options define the behavior of a typed cache.
+type Options struct {
+	// TTL is the default duration of the entries, zero means no expiration.
+	TTL time.Duration
+
+	// MaxEntries is the maximum number of entries, zero means no limit.
+	MaxEntries int
+
+	// Policy is the eviction policy used when the maximum number of entries is reached.
+	Policy Policy
+}
+
+// Stats are the counters of a typed cache since its creation.
+type Stats struct {
+	Hits        uint64
+	Misses      uint64
+	Loads       uint64
+	LoadErrors  uint64
+	Evictions   uint64
+	Expirations uint64
+	Entries     int
+	Size        int64 // cumulated size of the values, zero without size function
+}
+
+// HitRatio returns the ratio of hits over all lookups, between 0 and 1.
+func (s Stats) HitRatio() float64 {
+	if t := s.Hits + s.Misses; t > 0 {
+		return float64(s.Hits) / float64(t)
+	}
+
+	return 0
+}
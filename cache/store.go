@@ -0,0 +1,330 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrInvalidLoader = errors.New("invalid load function")
+
+// call is a load in flight of a key.
+type call[V any] struct {
+	d chan struct{}
+	v V
+	e error
+}
+
+type typed[K comparable, V any] struct {
+	m sync.Mutex
+	o Options
+	k map[K]*entry[K, V]
+	x index[K, V]
+	l map[K]*call[V] // loads in flight
+	s Stats
+	z func(val V) int64  // size of the values, nil if not measured
+	n int64              // maximum cumulated size, zero means no limit
+	c int64              // current cumulated size
+	f func(key K, val V) // called for each entry evicted or expired, under lock
+}
+
+func (o *typed[K, V]) Get(key K) (V, bool) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	return o.get(key)
+}
+
+// get returns the value of the key, must be called with the lock held.
+func (o *typed[K, V]) get(key K) (V, bool) {
+	var v V
+
+	if e, k := o.k[key]; !k {
+		o.s.Misses++
+		return v, false
+	} else if e.expired(time.Now()) {
+		o.drop(e, true)
+		o.s.Misses++
+		return v, false
+	} else {
+		o.x.touch(e)
+		o.s.Hits++
+		return e.v, true
+	}
+}
+
+func (o *typed[K, V]) Set(key K, val V) {
+	o.SetTTL(key, val, 0)
+}
+
+func (o *typed[K, V]) SetTTL(key K, val V, ttl time.Duration) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.set(key, val, ttl)
+}
+
+// set adds or replaces the value of the key, must be called with the lock held.
+func (o *typed[K, V]) set(key K, val V, ttl time.Duration) {
+	var exp time.Time
+
+	if ttl <= 0 {
+		ttl = o.o.TTL
+	}
+
+	if ttl > 0 {
+		exp = time.Now().Add(ttl)
+	}
+
+	var siz int64
+
+	if o.z != nil {
+		siz = o.z(val)
+	}
+
+	if e, k := o.k[key]; k {
+		o.c += siz - e.z
+		e.v = val
+		e.x = exp
+		e.z = siz
+		o.x.touch(e)
+		o.shrink(e)
+		return
+	}
+
+	if o.o.MaxEntries > 0 && len(o.k) >= o.o.MaxEntries {
+		o.evict()
+	}
+
+	e := &entry[K, V]{
+		k: key,
+		v: val,
+		x: exp,
+		z: siz,
+	}
+
+	o.k[key] = e
+	o.c += siz
+	o.x.push(e)
+	o.shrink(e)
+}
+
+// evict removes the victim of the eviction policy.
+func (o *typed[K, V]) evict() {
+	if e := o.x.victim(); e != nil {
+		o.drop(e, e.expired(time.Now()))
+	}
+}
+
+// shrink removes the victims of the eviction policy over the maximum size, the given entry last set is always kept.
+func (o *typed[K, V]) shrink(last *entry[K, V]) {
+	for o.z != nil && o.n > 0 && o.c > o.n {
+		if e := o.x.victim(); e == nil || e == last {
+			return
+		} else {
+			o.drop(e, e.expired(time.Now()))
+		}
+	}
+}
+
+// drop removes an entry evicted or expired and calls the eviction function.
+func (o *typed[K, V]) drop(e *entry[K, V], expired bool) {
+	o.del(e)
+
+	if expired {
+		o.s.Expirations++
+	} else {
+		o.s.Evictions++
+	}
+
+	if o.f != nil {
+		o.f(e.k, e.v)
+	}
+}
+
+func (o *typed[K, V]) del(e *entry[K, V]) {
+	delete(o.k, e.k)
+	o.c -= e.z
+	o.x.remove(e)
+}
+
+func (o *typed[K, V]) Delete(key K) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if e, k := o.k[key]; k {
+		o.del(e)
+	}
+}
+
+func (o *typed[K, V]) GetOrLoad(ctx context.Context, key K, fct FuncLoad[K, V]) (V, error) {
+	o.m.Lock()
+
+	if v, k := o.get(key); k {
+		o.m.Unlock()
+		return v, nil
+	} else if fct == nil {
+		o.m.Unlock()
+		return v, ErrInvalidLoader
+	}
+
+	if c, k := o.l[key]; k {
+		o.m.Unlock()
+
+		select {
+		case <-c.d:
+			return c.v, c.e
+		case <-ctx.Done():
+			var v V
+			return v, ctx.Err()
+		}
+	}
+
+	c := &call[V]{
+		d: make(chan struct{}),
+	}
+
+	o.l[key] = c
+	o.m.Unlock()
+
+	var ttl time.Duration
+
+	defer func() {
+		o.m.Lock()
+		delete(o.l, key)
+		o.s.Loads++
+
+		if c.e != nil {
+			o.s.LoadErrors++
+		} else {
+			o.set(key, c.v, ttl)
+		}
+
+		o.m.Unlock()
+		close(c.d)
+	}()
+
+	c.v, ttl, c.e = fct(ctx, key)
+
+	return c.v, c.e
+}
+
+func (o *typed[K, V]) Walk(fct func(key K, val V, exp time.Duration) bool) {
+	type kv struct {
+		k K
+		v V
+		x time.Time
+	}
+
+	o.m.Lock()
+
+	var (
+		now = time.Now()
+		lst = make([]kv, 0, len(o.k))
+	)
+
+	for _, e := range o.k {
+		if !e.expired(now) {
+			lst = append(lst, kv{k: e.k, v: e.v, x: e.x})
+		}
+	}
+
+	o.m.Unlock()
+
+	for _, i := range lst {
+		var d time.Duration
+
+		if !i.x.IsZero() {
+			d = i.x.Sub(now)
+		}
+
+		if !fct(i.k, i.v, d) {
+			return
+		}
+	}
+}
+
+func (o *typed[K, V]) Purge() int {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	return o.purge()
+}
+
+// purge removes the expired entries, must be called with the lock held.
+func (o *typed[K, V]) purge() int {
+	var (
+		now = time.Now()
+		res int
+	)
+
+	for _, e := range o.k {
+		if e.expired(now) {
+			o.drop(e, true)
+			res++
+		}
+	}
+
+	return res
+}
+
+func (o *typed[K, V]) Clean() {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.k = make(map[K]*entry[K, V])
+	o.c = 0
+	o.x.clean()
+}
+
+func (o *typed[K, V]) RegisterFuncEvict(fct func(key K, val V)) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.f = fct
+}
+
+func (o *typed[K, V]) Len() int {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	return len(o.k)
+}
+
+func (o *typed[K, V]) Stats() Stats {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	var s = o.s
+	s.Entries = len(o.k)
+	s.Size = o.c
+
+	return s
+}
@@ -0,0 +1,114 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// FuncLoad loads the value of a key missing into a typed cache. The returned ttl is the duration
+// of the entry, the default ttl of the cache is used if zero or negative.
+type FuncLoad[K comparable, V any] func(ctx context.Context, key K) (val V, ttl time.Duration, err error)
+
+// Typed is a typed cache safe for concurrent use, with expiration of the entries
+// and eviction over a maximum number of entries or a maximum cumulated size.
+type Typed[K comparable, V any] interface {
+	// Get returns the value of the key if it exists and is not expired.
+	Get(key K) (V, bool)
+
+	// Set adds or replaces the value of the key with the default ttl.
+	Set(key K, val V)
+
+	// SetTTL adds or replaces the value of the key with the given ttl, the default ttl is used if zero or negative.
+	SetTTL(key K, val V, ttl time.Duration)
+
+	// Delete removes the key.
+	Delete(key K)
+
+	// GetOrLoad returns the value of the key, or loads it with the function if missing. The concurrent
+	// loads of a same key are deduplicated: only one function is called and its result is shared.
+	// The errors are returned but not cached.
+	GetOrLoad(ctx context.Context, key K, fct FuncLoad[K, V]) (V, error)
+
+	// Walk calls the function for each entry not expired with its remaining duration
+	// (zero if no expiration), until the function returns false.
+	Walk(fct func(key K, val V, exp time.Duration) bool)
+
+	// Purge removes the expired entries and returns their number.
+	Purge() int
+
+	// Clean removes all entries.
+	Clean()
+
+	// Len returns the number of entries, including the expired entries not yet removed.
+	Len() int
+
+	// Stats returns the counters of the cache.
+	Stats() Stats
+
+	// RegisterFuncEvict registers a function called for each entry evicted or expired, but not for the
+	// entries deleted, replaced or cleaned. The function is called under the lock and must not use the cache.
+	RegisterFuncEvict(fct func(key K, val V))
+}
+
+// NewTyped returns a typed cache with the given options.
+func NewTyped[K comparable, V any](opt Options) Typed[K, V] {
+	if opt.TTL < 0 {
+		opt.TTL = 0
+	}
+
+	if opt.MaxEntries < 0 {
+		opt.MaxEntries = 0
+	}
+
+	return &typed[K, V]{
+		m: sync.Mutex{},
+		o: opt,
+		k: make(map[K]*entry[K, V]),
+		x: newIndex[K, V](opt.Policy),
+		l: make(map[K]*call[V]),
+	}
+}
+
+// NewTypedSize returns a typed cache with the given options, also limited to the given cumulated size
+// of the values measured with the size function. A zero or negative size means no limit, the values
+// are still measured for the stats. The entry last set is always kept, even if its size alone is over the limit.
+func NewTypedSize[K comparable, V any](opt Options, maxSize int64, size func(val V) int64) Typed[K, V] {
+	var o = NewTyped[K, V](opt).(*typed[K, V])
+
+	if size != nil {
+		o.z = size
+	}
+
+	if maxSize > 0 {
+		o.n = maxSize
+	}
+
+	return o
+}
@@ -0,0 +1,260 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2022 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	libcch "github.com/nabbar/golib/cache"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func found[V any](val V, ok bool) V {
+	Expect(ok).To(BeTrue())
+	return val
+}
+
+var _ = Describe("Typed Cache", func() {
+	Context("entries", func() {
+		It("must store, load and delete values", func() {
+			c := libcch.NewTyped[string, int](libcch.Options{})
+
+			_, ok := c.Get("a")
+			Expect(ok).To(BeFalse())
+
+			c.Set("a", 1)
+			c.Set("b", 2)
+			Expect(found(c.Get("a"))).To(Equal(1))
+			Expect(c.Len()).To(Equal(2))
+
+			c.Set("a", 3)
+			Expect(found(c.Get("a"))).To(Equal(3))
+
+			c.Delete("a")
+			_, ok = c.Get("a")
+			Expect(ok).To(BeFalse())
+
+			c.Clean()
+			Expect(c.Len()).To(BeZero())
+
+			s := c.Stats()
+			Expect(s.Hits).To(Equal(uint64(2)))
+			Expect(s.Misses).To(Equal(uint64(2)))
+			Expect(s.HitRatio()).To(Equal(0.5))
+		})
+
+		It("must expire the values", func() {
+			c := libcch.NewTyped[string, int](libcch.Options{TTL: 50 * time.Millisecond})
+
+			c.Set("a", 1)
+			c.SetTTL("b", 2, time.Hour)
+			Expect(found(c.Get("a"))).To(Equal(1))
+
+			Eventually(func() bool {
+				_, ok := c.Get("a")
+				return ok
+			}).Should(BeFalse())
+
+			Expect(found(c.Get("b"))).To(Equal(2))
+
+			c.SetTTL("c", 3, 10*time.Millisecond)
+			time.Sleep(20 * time.Millisecond)
+			Expect(c.Purge()).To(Equal(1))
+
+			var n int
+			c.Walk(func(key string, val int, exp time.Duration) bool {
+				n++
+				Expect(key).To(Equal("b"))
+				Expect(exp).To(BeNumerically(">", 50*time.Minute))
+				return true
+			})
+
+			Expect(n).To(Equal(1))
+			Expect(c.Stats().Expirations).To(Equal(uint64(2)))
+		})
+	})
+
+	Context("eviction", func() {
+		It("must evict the least recently used", func() {
+			c := libcch.NewTyped[int, int](libcch.Options{MaxEntries: 2, Policy: libcch.PolicyLRU})
+
+			c.Set(1, 1)
+			c.Set(2, 2)
+			_, _ = c.Get(1)
+			c.Set(3, 3)
+
+			_, ok := c.Get(2)
+			Expect(ok).To(BeFalse())
+			Expect(found(c.Get(1))).To(Equal(1))
+			Expect(found(c.Get(3))).To(Equal(3))
+			Expect(c.Stats().Evictions).To(Equal(uint64(1)))
+		})
+
+		It("must evict the least frequently used", func() {
+			c := libcch.NewTyped[int, int](libcch.Options{MaxEntries: 2, Policy: libcch.PolicyLFU})
+
+			c.Set(1, 1)
+			c.Set(2, 2)
+			_, _ = c.Get(1)
+			_, _ = c.Get(1)
+			_, _ = c.Get(2)
+			c.Set(3, 3)
+
+			_, ok := c.Get(2)
+			Expect(ok).To(BeFalse())
+			Expect(found(c.Get(1))).To(Equal(1))
+			Expect(found(c.Get(3))).To(Equal(3))
+			Expect(c.Len()).To(Equal(2))
+		})
+
+		It("must evict over the maximum size and keep the last set", func() {
+			c := libcch.NewTypedSize[string, []byte](libcch.Options{}, 5, func(v []byte) int64 {
+				return int64(len(v))
+			})
+
+			c.Set("a", []byte("12"))
+			c.Set("b", []byte("34"))
+			Expect(c.Stats().Size).To(Equal(int64(4)))
+
+			c.Set("c", []byte("56"))
+			_, ok := c.Get("a")
+			Expect(ok).To(BeFalse())
+			Expect(c.Stats().Size).To(Equal(int64(4)))
+
+			c.Set("b", []byte("7"))
+			Expect(c.Stats().Size).To(Equal(int64(3)))
+
+			c.Set("d", []byte("1234567"))
+			Expect(c.Len()).To(Equal(1))
+			Expect(found(c.Get("d"))).To(Equal([]byte("1234567")))
+			Expect(c.Stats().Size).To(Equal(int64(7)))
+
+			c.Delete("d")
+			Expect(c.Stats().Size).To(BeZero())
+		})
+
+		It("must call the eviction function for the evicted and expired entries only", func() {
+			var (
+				c = libcch.NewTyped[int, int](libcch.Options{MaxEntries: 2})
+				e []int
+			)
+
+			c.RegisterFuncEvict(func(key int, _ int) {
+				e = append(e, key)
+			})
+
+			c.Set(1, 1)
+			c.Set(2, 2)
+			c.Delete(2)
+			c.Set(3, 3)
+			c.Set(3, 4)
+			c.Set(4, 4)
+			Expect(e).To(Equal([]int{1}))
+
+			c.SetTTL(5, 5, time.Millisecond)
+			time.Sleep(5 * time.Millisecond)
+			Expect(c.Purge()).To(Equal(1))
+			Expect(e).To(Equal([]int{1, 3, 5}))
+
+			c.Clean()
+			Expect(e).To(HaveLen(3))
+		})
+	})
+
+	Context("loader", func() {
+		It("must deduplicate the concurrent loads", func() {
+			var (
+				c = libcch.NewTyped[string, string](libcch.Options{})
+				n atomic.Int32
+				w sync.WaitGroup
+				r = make(chan struct{})
+			)
+
+			fct := func(ctx context.Context, key string) (string, time.Duration, error) {
+				n.Add(1)
+				<-r
+				return "value-" + key, 0, nil
+			}
+
+			for i := 0; i < 10; i++ {
+				w.Add(1)
+				go func() {
+					defer GinkgoRecover()
+					defer w.Done()
+
+					v, e := c.GetOrLoad(context.Background(), "k", fct)
+					Expect(e).ToNot(HaveOccurred())
+					Expect(v).To(Equal("value-k"))
+				}()
+			}
+
+			Eventually(n.Load).Should(Equal(int32(1)))
+			time.Sleep(20 * time.Millisecond)
+			close(r)
+			w.Wait()
+
+			Expect(n.Load()).To(Equal(int32(1)))
+			Expect(found(c.Get("k"))).To(Equal("value-k"))
+			Expect(c.Stats().Loads).To(Equal(uint64(1)))
+		})
+
+		It("must not cache the load errors", func() {
+			var (
+				c = libcch.NewTyped[string, int](libcch.Options{})
+				n atomic.Int32
+			)
+
+			fct := func(ctx context.Context, key string) (int, time.Duration, error) {
+				if n.Add(1) == 1 {
+					return 0, 0, errors.New("failure")
+				}
+
+				return 1, time.Hour, nil
+			}
+
+			_, e := c.GetOrLoad(context.Background(), "k", fct)
+			Expect(e).To(HaveOccurred())
+
+			Expect(c.GetOrLoad(context.Background(), "k", fct)).To(Equal(1))
+			Expect(c.GetOrLoad(context.Background(), "k", fct)).To(Equal(1))
+			Expect(n.Load()).To(Equal(int32(2)))
+
+			s := c.Stats()
+			Expect(s.Loads).To(Equal(uint64(2)))
+			Expect(s.LoadErrors).To(Equal(uint64(1)))
+
+			_, e = c.GetOrLoad(context.Background(), "x", nil)
+			Expect(e).To(MatchError(libcch.ErrInvalidLoader))
+		})
+	})
+})
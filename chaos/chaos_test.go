@@ -0,0 +1,267 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package chaos_test
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/nabbar/golib/chaos"
+	libdur "github.com/nabbar/golib/duration"
+	libsck "github.com/nabbar/golib/socket"
+	sckmem "github.com/nabbar/golib/socket/memtest"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var body = []byte("0123456789abcdefghijklmnopqrstuvwxyz")
+
+func newChaos(f ...chaos.Fault) chaos.Chaos {
+	c, e := chaos.New(chaos.Config{
+		Enable:  true,
+		Rate:    1,
+		Faults:  f,
+		Latency: libdur.ParseDuration(100 * time.Millisecond),
+		Paths:   []string{"/api/*"},
+	})
+
+	Expect(e).ToNot(HaveOccurred())
+	return c
+}
+
+func newServer(c chaos.Chaos) *httptest.Server {
+	return httptest.NewServer(c.HttpMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(body)
+	})))
+}
+
+func get(srv *httptest.Server, path string) (int, []byte, error) {
+	r, e := srv.Client().Get(srv.URL + path)
+
+	if e != nil {
+		return 0, nil, e
+	}
+
+	defer func() {
+		_ = r.Body.Close()
+	}()
+
+	b, e := io.ReadAll(r.Body)
+	return r.StatusCode, b, e
+}
+
+func Handler(request libsck.Reader, response libsck.Writer) {
+	defer func() {
+		_ = request.Close()
+		_ = response.Close()
+	}()
+
+	if l, e := bufio.NewReader(request).ReadBytes('\n'); e == nil {
+		_, _ = response.Write(l)
+	}
+}
+
+func exchange(c chaos.Chaos, msg string) (string, error) {
+	sck, err := sckmem.New(nil, c.SocketMiddleware(Handler), sckmem.Options{})
+	Expect(err).ToNot(HaveOccurred())
+
+	go func() {
+		defer GinkgoRecover()
+		_ = sck.Listen(ctx)
+	}()
+
+	Eventually(sck.IsRunning, 5*time.Second, 10*time.Millisecond).Should(BeTrue())
+	defer func() {
+		Expect(sck.Close()).ToNot(HaveOccurred())
+	}()
+
+	con, err := sck.Dial(ctx)
+	Expect(err).ToNot(HaveOccurred())
+
+	defer func() {
+		_ = con.Close()
+	}()
+
+	_ = con.SetDeadline(time.Now().Add(5 * time.Second))
+
+	// the in-memory connection has no buffer: the request is written while the response is read.
+	go func() {
+		_, _ = con.Write([]byte(msg))
+	}()
+
+	b, err := io.ReadAll(con)
+	return string(b), err
+}
+
+var _ = Describe("chaos", func() {
+	Context("validating the config", func() {
+		It("must reject the invalid values", func() {
+			Expect(chaos.Config{Rate: 2}.Validate()).To(MatchError(chaos.ErrInvalidRate))
+			Expect(chaos.Config{Faults: []chaos.Fault{"boom"}}.Validate()).To(MatchError(chaos.ErrInvalidFault))
+			Expect(chaos.Config{ErrorStatus: 200}.Validate()).To(MatchError(chaos.ErrInvalidStatus))
+			Expect(chaos.Config{Paths: []string{"["}}.Validate()).To(MatchError(chaos.ErrInvalidPattern))
+			Expect(chaos.Config{Rate: 0.5, Faults: chaos.Faults()}.Validate()).ToNot(HaveOccurred())
+
+			_, e := chaos.New(chaos.Config{Rate: -1})
+			Expect(e).To(MatchError(chaos.ErrInvalidRate))
+		})
+
+		It("must apply the default values", func() {
+			c, e := chaos.New(chaos.Config{})
+			Expect(e).ToNot(HaveOccurred())
+
+			cfg := c.GetConfig()
+			Expect(cfg.Faults).To(Equal(chaos.Faults()))
+			Expect(cfg.Latency.Time()).To(Equal(chaos.DefaultLatency))
+			Expect(cfg.ErrorStatus).To(Equal(chaos.DefaultErrorStatus))
+			Expect(cfg.ErrorMessage).To(Equal(chaos.DefaultErrorMessage))
+		})
+	})
+
+	Context("using the http middleware", func() {
+		It("must not affect the requests when disabled or not selected", func() {
+			c := newChaos(chaos.FaultError)
+			srv := newServer(c)
+			defer srv.Close()
+
+			s, b, e := get(srv, "/other")
+			Expect(e).ToNot(HaveOccurred())
+			Expect(s).To(Equal(http.StatusOK))
+			Expect(b).To(Equal(body))
+
+			c.SetEnable(false)
+			s, _, e = get(srv, "/api/test")
+			Expect(e).ToNot(HaveOccurred())
+			Expect(s).To(Equal(http.StatusOK))
+
+			Expect(c.Stats()).To(Equal(chaos.Stats{Requests: 1}))
+		})
+
+		It("must send the error status", func() {
+			c := newChaos(chaos.FaultError)
+			srv := newServer(c)
+			defer srv.Close()
+
+			s, b, e := get(srv, "/api/test")
+			Expect(e).ToNot(HaveOccurred())
+			Expect(s).To(Equal(chaos.DefaultErrorStatus))
+			Expect(string(b)).To(ContainSubstring(chaos.DefaultErrorMessage))
+			Expect(c.Stats().Error).To(Equal(uint64(1)))
+		})
+
+		It("must delay the request", func() {
+			c := newChaos(chaos.FaultLatency)
+			srv := newServer(c)
+			defer srv.Close()
+
+			t := time.Now()
+			s, _, e := get(srv, "/api/test")
+			Expect(e).ToNot(HaveOccurred())
+			Expect(s).To(Equal(http.StatusOK))
+			Expect(time.Since(t)).To(BeNumerically(">=", 100*time.Millisecond))
+		})
+
+		It("must disconnect the request", func() {
+			c := newChaos(chaos.FaultDisconnect)
+			srv := newServer(c)
+			defer srv.Close()
+
+			_, _, e := get(srv, "/api/test")
+			Expect(e).To(HaveOccurred())
+		})
+
+		It("must truncate the response", func() {
+			c := newChaos(chaos.FaultTruncate)
+			srv := newServer(c)
+			defer srv.Close()
+
+			_, b, e := get(srv, "/api/test")
+			Expect(e).To(HaveOccurred())
+			Expect(len(b)).To(BeNumerically("<", len(body)))
+		})
+
+		It("must apply a new config at runtime", func() {
+			c := newChaos(chaos.FaultError)
+			srv := newServer(c)
+			defer srv.Close()
+
+			cfg := c.GetConfig()
+			cfg.Rate = 0
+			Expect(c.SetConfig(cfg)).ToNot(HaveOccurred())
+
+			s, _, e := get(srv, "/api/test")
+			Expect(e).ToNot(HaveOccurred())
+			Expect(s).To(Equal(http.StatusOK))
+
+			c.ResetStats()
+			Expect(c.Stats()).To(Equal(chaos.Stats{}))
+		})
+	})
+
+	Context("using the socket middleware", func() {
+		It("must not affect the connection when disabled", func() {
+			c := newChaos(chaos.FaultError)
+			c.SetEnable(false)
+
+			r, e := exchange(c, "hello\n")
+			Expect(e).ToNot(HaveOccurred())
+			Expect(r).To(Equal("hello\n"))
+		})
+
+		It("must send the error message", func() {
+			r, e := exchange(newChaos(chaos.FaultError), "hello\n")
+			Expect(e).ToNot(HaveOccurred())
+			Expect(r).To(Equal(chaos.DefaultErrorMessage + "\n"))
+		})
+
+		It("must disconnect the connection", func() {
+			r, _ := exchange(newChaos(chaos.FaultDisconnect), "hello\n")
+			Expect(r).To(BeEmpty())
+		})
+
+		It("must truncate the response", func() {
+			r, _ := exchange(newChaos(chaos.FaultTruncate), "0123456789\n")
+			Expect(r).To(Equal("01234"))
+		})
+
+		It("must only select the connections with the tags", func() {
+			c := newChaos(chaos.FaultError)
+			cfg := c.GetConfig()
+			cfg.Tags = map[string]string{"tenant": ""}
+			Expect(c.SetConfig(cfg)).ToNot(HaveOccurred())
+
+			r, e := exchange(c, "hello\n")
+			Expect(e).ToNot(HaveOccurred())
+			Expect(r).To(Equal("hello\n"))
+			Expect(c.Stats().Requests).To(BeZero())
+		})
+	})
+
+})
@@ -0,0 +1,170 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package chaos
+
+import (
+	"fmt"
+	"math/rand"
+	"path"
+	"time"
+
+	libdur "github.com/nabbar/golib/duration"
+	libsck "github.com/nabbar/golib/socket"
+)
+
+// Config define which requests are affected by the chaos layer and how.
+type Config struct {
+	// Enable activate the injection of the faults. When false, the requests are only counted.
+	Enable bool `mapstructure:"enable" json:"enable" yaml:"enable" toml:"enable"`
+
+	// Rate is the probability, between 0 and 1, for a selected request or connection to get a fault.
+	Rate float64 `mapstructure:"rate" json:"rate" yaml:"rate" toml:"rate"`
+
+	// Faults is the list of the faults randomly picked for an affected request. If empty, all the faults are used.
+	Faults []Fault `mapstructure:"faults" json:"faults" yaml:"faults" toml:"faults"`
+
+	// Latency is the delay added by the latency fault. If zero, DefaultLatency is used.
+	Latency libdur.Duration `mapstructure:"latency" json:"latency" yaml:"latency" toml:"latency"`
+
+	// Jitter is the max random delay added to the latency.
+	Jitter libdur.Duration `mapstructure:"jitter" json:"jitter" yaml:"jitter" toml:"jitter"`
+
+	// ErrorStatus is the http status sent by the error fault. If zero, DefaultErrorStatus is used.
+	ErrorStatus int `mapstructure:"error_status" json:"error_status" yaml:"error_status" toml:"error_status"`
+
+	// ErrorMessage is the body sent by the error fault, followed by a new line on sockets.
+	// If empty, DefaultErrorMessage is used.
+	ErrorMessage string `mapstructure:"error_message" json:"error_message" yaml:"error_message" toml:"error_message"`
+
+	// Paths is the list of the http routes selected, as path.Match patterns. If empty, all the routes are selected.
+	Paths []string `mapstructure:"paths" json:"paths" yaml:"paths" toml:"paths"`
+
+	// Tags is the list of the tags a socket connection must have to be selected (see socket.Context).
+	// An empty value match any value of the tag. If empty, all the connections are selected.
+	Tags map[string]string `mapstructure:"tags" json:"tags" yaml:"tags" toml:"tags"`
+}
+
+// Validate return an error if a field of the config has an invalid value.
+func (o Config) Validate() error {
+	if o.Rate < 0 || o.Rate > 1 {
+		return ErrInvalidRate
+	} else if o.Latency < 0 || o.Jitter < 0 {
+		return ErrInvalidDuration
+	} else if o.ErrorStatus != 0 && (o.ErrorStatus < 400 || o.ErrorStatus > 599) {
+		return ErrInvalidStatus
+	}
+
+	for _, f := range o.Faults {
+		if !f.IsValid() {
+			return fmt.Errorf("%w: '%s'", ErrInvalidFault, f)
+		}
+	}
+
+	for _, p := range o.Paths {
+		if _, e := path.Match(p, ""); e != nil {
+			return fmt.Errorf("%w: '%s'", ErrInvalidPattern, p)
+		}
+	}
+
+	return nil
+}
+
+// normalize return a copy of the config with the default values applied.
+func (o Config) normalize() Config {
+	if len(o.Faults) < 1 {
+		o.Faults = Faults()
+	} else {
+		o.Faults = append(make([]Fault, 0, len(o.Faults)), o.Faults...)
+	}
+
+	if o.Latency == 0 {
+		o.Latency = libdur.ParseDuration(DefaultLatency)
+	}
+
+	if o.ErrorStatus == 0 {
+		o.ErrorStatus = DefaultErrorStatus
+	}
+
+	if len(o.ErrorMessage) < 1 {
+		o.ErrorMessage = DefaultErrorMessage
+	}
+
+	if len(o.Paths) > 0 {
+		o.Paths = append(make([]string, 0, len(o.Paths)), o.Paths...)
+	}
+
+	if len(o.Tags) > 0 {
+		var t = make(map[string]string, len(o.Tags))
+
+		for k, v := range o.Tags {
+			t[k] = v
+		}
+
+		o.Tags = t
+	}
+
+	return o
+}
+
+func (o Config) delay() time.Duration {
+	var d = o.Latency.Time()
+
+	if j := o.Jitter.Time(); j > 0 {
+		d += time.Duration(rand.Int63n(int64(j)))
+	}
+
+	return d
+}
+
+func (o Config) matchPath(p string) bool {
+	if len(o.Paths) < 1 {
+		return true
+	}
+
+	for _, m := range o.Paths {
+		if ok, _ := path.Match(m, p); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (o Config) matchTags(ctx libsck.Context) bool {
+	if len(o.Tags) < 1 {
+		return true
+	} else if ctx == nil {
+		return false
+	}
+
+	for k, v := range o.Tags {
+		if t, ok := ctx.Tag(k); !ok || (len(v) > 0 && t != v) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,107 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package chaos
+
+import (
+	"net/http"
+)
+
+func (o *mdl) HttpMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var c = o.config()
+
+		if !c.matchPath(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		f, ok := o.pick(c)
+
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		switch f {
+		case FaultLatency:
+			if !sleep(r.Context().Done(), c.delay()) {
+				return
+			}
+
+			w.Header().Set(HeaderFault, f.String())
+			next.ServeHTTP(w, r)
+
+		case FaultDisconnect:
+			abort(w)
+
+		case FaultTruncate:
+			w.Header().Set(HeaderFault, f.String())
+			next.ServeHTTP(&httpTrunc{ResponseWriter: w}, r)
+			abort(w)
+
+		case FaultError:
+			w.Header().Set(HeaderFault, f.String())
+			http.Error(w, c.ErrorMessage, c.ErrorStatus)
+		}
+	})
+}
+
+// abort flush the response already written and close the connection of the request.
+// If the connection cannot be hijacked (http/2), the handler is aborted with http.ErrAbortHandler.
+func abort(w http.ResponseWriter) {
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+
+	if h, ok := w.(http.Hijacker); ok {
+		if c, _, e := h.Hijack(); e == nil {
+			_ = c.Close()
+			return
+		}
+	}
+
+	panic(http.ErrAbortHandler)
+}
+
+// httpTrunc write only the first half of the first write of the handler and drop the next writes.
+type httpTrunc struct {
+	http.ResponseWriter
+	d bool
+}
+
+func (o *httpTrunc) Write(p []byte) (int, error) {
+	if o.d {
+		return len(p), nil
+	}
+
+	o.d = true
+
+	if _, e := o.ResponseWriter.Write(p[:len(p)/2]); e != nil {
+		return 0, e
+	}
+
+	return len(p), nil
+}
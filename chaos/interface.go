@@ -0,0 +1,135 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package chaos
+
+import (
+	"net/http"
+	"time"
+
+	libsck "github.com/nabbar/golib/socket"
+)
+
+// Fault is a kind of failure injected by the chaos layer.
+type Fault string
+
+const (
+	// FaultLatency delay the request before running the handler.
+	FaultLatency Fault = "latency"
+	// FaultDisconnect close the connection without running the handler.
+	FaultDisconnect Fault = "disconnect"
+	// FaultTruncate run the handler but cut its response in the middle of the first write and close the connection.
+	FaultTruncate Fault = "truncate"
+	// FaultError send an error response without running the handler: the error status on http,
+	// the error message on a socket before closing the connection.
+	FaultError Fault = "error"
+)
+
+const (
+	// HeaderFault is the http response header carrying the injected fault, when the response headers can be sent.
+	HeaderFault = "X-Chaos-Fault"
+
+	// DefaultLatency is the delay added by the latency fault if not defined.
+	DefaultLatency = time.Second
+	// DefaultErrorStatus is the http status sent by the error fault if not defined.
+	DefaultErrorStatus = http.StatusServiceUnavailable
+	// DefaultErrorMessage is the message sent by the error fault if not defined.
+	DefaultErrorMessage = "chaos: injected fault"
+)
+
+// Faults return the list of all the faults.
+func Faults() []Fault {
+	return []Fault{
+		FaultLatency,
+		FaultDisconnect,
+		FaultTruncate,
+		FaultError,
+	}
+}
+
+// IsValid return true if the fault is a known fault.
+func (f Fault) IsValid() bool {
+	switch f {
+	case FaultLatency, FaultDisconnect, FaultTruncate, FaultError:
+		return true
+	}
+
+	return false
+}
+
+func (f Fault) String() string {
+	return string(f)
+}
+
+// Stats is the count of the requests seen by the chaos layer and of the injected faults.
+type Stats struct {
+	// Requests is the number of the requests (http) and connections (socket) selected by the config.
+	Requests uint64 `json:"requests"`
+	// Injected is the number of the requests affected by a fault.
+	Injected uint64 `json:"injected"`
+
+	Latency    uint64 `json:"latency"`
+	Disconnect uint64 `json:"disconnect"`
+	Truncate   uint64 `json:"truncate"`
+	Error      uint64 `json:"error"`
+}
+
+// Chaos is a fault injection layer for the http and socket servers, to test the resilience of their consumers.
+// The config can be changed at any time and is applied to the next requests.
+type Chaos interface {
+	// SetConfig validate and apply the given config.
+	SetConfig(cfg Config) error
+	// GetConfig return the current config.
+	GetConfig() Config
+
+	// SetEnable activate or deactivate the injection of the faults, without changing the other config values.
+	SetEnable(enable bool)
+	// IsEnable return true if the injection of the faults is active.
+	IsEnable() bool
+
+	// Stats return the counters of the requests and the injected faults.
+	Stats() Stats
+	// ResetStats set all the counters to zero.
+	ResetStats()
+
+	// HttpMiddleware return the given handler wrapped by the chaos layer.
+	// Only the requests with a path matching the config are selected.
+	HttpMiddleware(next http.Handler) http.Handler
+
+	// SocketMiddleware return the given handler wrapped by the chaos layer, see socket.Middleware.
+	// Only the connections with the tags of the config are selected.
+	SocketMiddleware(next libsck.Handler) libsck.Handler
+}
+
+// New return a chaos layer using the given config, or an error if the config is invalid.
+func New(cfg Config) (Chaos, error) {
+	var o = &mdl{}
+
+	if e := o.SetConfig(cfg); e != nil {
+		return nil, e
+	}
+
+	return o, nil
+}
@@ -0,0 +1,143 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package chaos
+
+import (
+	"math/rand"
+	"sync/atomic"
+	"time"
+)
+
+type mdl struct {
+	c atomic.Pointer[Config]
+	e atomic.Bool
+
+	r atomic.Uint64 // requests
+	i atomic.Uint64 // injected
+	l atomic.Uint64 // latency
+	d atomic.Uint64 // disconnect
+	t atomic.Uint64 // truncate
+	x atomic.Uint64 // error
+}
+
+func (o *mdl) SetConfig(cfg Config) error {
+	if e := cfg.Validate(); e != nil {
+		return e
+	}
+
+	var c = cfg.normalize()
+	o.c.Store(&c)
+	o.e.Store(cfg.Enable)
+
+	return nil
+}
+
+func (o *mdl) GetConfig() Config {
+	var c = o.config().normalize()
+	c.Enable = o.e.Load()
+	return c
+}
+
+func (o *mdl) SetEnable(enable bool) {
+	o.e.Store(enable)
+}
+
+func (o *mdl) IsEnable() bool {
+	return o.e.Load()
+}
+
+func (o *mdl) Stats() Stats {
+	return Stats{
+		Requests:   o.r.Load(),
+		Injected:   o.i.Load(),
+		Latency:    o.l.Load(),
+		Disconnect: o.d.Load(),
+		Truncate:   o.t.Load(),
+		Error:      o.x.Load(),
+	}
+}
+
+func (o *mdl) ResetStats() {
+	o.r.Store(0)
+	o.i.Store(0)
+	o.l.Store(0)
+	o.d.Store(0)
+	o.t.Store(0)
+	o.x.Store(0)
+}
+
+func (o *mdl) config() Config {
+	if c := o.c.Load(); c != nil {
+		return *c
+	}
+
+	return Config{}.normalize()
+}
+
+// pick count the selected request and return the fault to inject, or false if the request is not affected.
+func (o *mdl) pick(c Config) (Fault, bool) {
+	o.r.Add(1)
+
+	if !o.e.Load() || c.Rate <= 0 || len(c.Faults) < 1 {
+		return "", false
+	} else if c.Rate < 1 && rand.Float64() >= c.Rate {
+		return "", false
+	}
+
+	var f = c.Faults[rand.Intn(len(c.Faults))]
+
+	o.i.Add(1)
+
+	switch f {
+	case FaultLatency:
+		o.l.Add(1)
+	case FaultDisconnect:
+		o.d.Add(1)
+	case FaultTruncate:
+		o.t.Add(1)
+	case FaultError:
+		o.x.Add(1)
+	}
+
+	return f, true
+}
+
+// sleep wait for the given delay and return true, or return false if the done channel is closed before.
+func sleep(done <-chan struct{}, dly time.Duration) bool {
+	if dly <= 0 {
+		return true
+	}
+
+	var t = time.NewTimer(dly)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return true
+	case <-done:
+		return false
+	}
+}
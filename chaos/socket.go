@@ -0,0 +1,89 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package chaos
+
+import (
+	"sync/atomic"
+
+	libsck "github.com/nabbar/golib/socket"
+)
+
+func (o *mdl) SocketMiddleware(next libsck.Handler) libsck.Handler {
+	return func(request libsck.Reader, response libsck.Writer) {
+		var c = o.config()
+
+		if !c.matchTags(request.Context()) {
+			next(request, response)
+			return
+		}
+
+		f, ok := o.pick(c)
+
+		if !ok {
+			next(request, response)
+			return
+		}
+
+		switch f {
+		case FaultLatency:
+			if sleep(request.Done(), c.delay()) {
+				next(request, response)
+			}
+
+		case FaultDisconnect:
+			_ = request.Close()
+			_ = response.Close()
+
+		case FaultTruncate:
+			next(request, &sckTrunc{Writer: response, r: request})
+
+		case FaultError:
+			_, _ = response.Write(append([]byte(c.ErrorMessage), '\n'))
+			_ = request.Close()
+			_ = response.Close()
+		}
+	}
+}
+
+// sckTrunc write only the first half of the first write of the handler and close the connection.
+type sckTrunc struct {
+	libsck.Writer
+	r libsck.Reader
+	d atomic.Bool
+}
+
+func (o *sckTrunc) Write(p []byte) (int, error) {
+	if o.d.Swap(true) {
+		return 0, ErrTruncated
+	}
+
+	n, _ := o.Writer.Write(p[:len(p)/2])
+
+	_ = o.r.Close()
+	_ = o.Writer.Close()
+
+	return n, ErrTruncated
+}
@@ -0,0 +1,125 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	spfcbr "github.com/spf13/cobra"
+)
+
+func (o *svc) AddCommands(defaultConfig func() io.Reader) {
+	o.AddCommandServe()
+	o.AddCommandValidate()
+	o.AddCommandPrintConfig(defaultConfig)
+	o.AddCommandVersion()
+}
+
+func (o *svc) AddCommandServe() {
+	o.c.AddCommand(&spfcbr.Command{
+		Use:     "serve",
+		Example: "serve -c <config file>",
+		Short:   "Start all servers",
+		Long: `Validates the configuration, starts all registered servers and waits for
+a signal (SIGINT, SIGTERM, SIGQUIT) to gracefully stop them.`,
+		RunE: func(cmd *spfcbr.Command, args []string) error {
+			var ctx = cmd.Context()
+
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			return o.Serve(ctx)
+		},
+	})
+}
+
+func (o *svc) AddCommandValidate() {
+	o.c.AddCommand(&spfcbr.Command{
+		Use:     "validate-config",
+		Example: "validate-config -c <config file>",
+		Short:   "Check the config file",
+		Long:    "Loads the configuration and checks it is valid, without starting any server.",
+		RunE: func(cmd *spfcbr.Command, args []string) error {
+			if e := o.Validate(); e != nil {
+				return e
+			}
+
+			_, _ = fmt.Fprintln(cmd.OutOrStdout(), "config is valid")
+			return nil
+		},
+	})
+}
+
+func (o *svc) AddCommandPrintConfig(defaultConfig func() io.Reader) {
+	if defaultConfig == nil {
+		return
+	}
+
+	o.c.AddCommand(&spfcbr.Command{
+		Use:     "print-default-config",
+		Example: "print-default-config > config.json",
+		Short:   "Print the default config",
+		Long:    "Prints the default configuration in json format on the standard output.",
+		RunE: func(cmd *spfcbr.Command, args []string) error {
+			var w io.Writer = cmd.OutOrStdout()
+
+			if w == nil {
+				w = os.Stdout
+			}
+
+			if r := defaultConfig(); r == nil {
+				return nil
+			} else if _, e := io.Copy(w, r); e != nil {
+				return e
+			}
+
+			_, _ = fmt.Fprintln(w)
+			return nil
+		},
+	})
+}
+
+func (o *svc) AddCommandVersion() {
+	o.c.AddCommand(&spfcbr.Command{
+		Use:     "version",
+		Example: "version",
+		Short:   "Print the version",
+		Long:    "Prints the version, build and license information.",
+		Run: func(cmd *spfcbr.Command, args []string) {
+			if o.v == nil {
+				return
+			}
+
+			_, _ = fmt.Fprintln(cmd.OutOrStdout(), o.v.GetHeader())
+			_, _ = fmt.Fprintln(cmd.OutOrStdout(), o.v.GetInfo())
+		},
+	})
+}
@@ -0,0 +1,93 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package service
+
+import (
+	"context"
+
+	libhtp "github.com/nabbar/golib/httpserver/pool"
+	srvtps "github.com/nabbar/golib/httpserver/types"
+	liblog "github.com/nabbar/golib/logger"
+	logcfg "github.com/nabbar/golib/logger/config"
+	libsrv "github.com/nabbar/golib/server"
+	libsck "github.com/nabbar/golib/socket"
+	sckcfg "github.com/nabbar/golib/socket/config"
+)
+
+// ValidateLogger return a validate function for the logger options returned by the given function.
+func ValidateLogger(fct func() *logcfg.Options) FuncValidate {
+	return func() error {
+		if fct == nil {
+			return nil
+		} else if o := fct(); o == nil {
+			return nil
+		} else if e := o.Validate(); e != nil {
+			return e
+		}
+
+		return nil
+	}
+}
+
+// ValidateHttp return a validate function for the http server pool config returned by the given function.
+func ValidateHttp(fct func() libhtp.Config) FuncValidate {
+	return func() error {
+		if fct == nil {
+			return nil
+		}
+
+		return fct().Validate()
+	}
+}
+
+// ServerHttp return a function building a http server pool from the config returned by the given function.
+func ServerHttp(fct func() libhtp.Config, hdl srvtps.FuncHandler, log liblog.FuncLog) FuncServer {
+	return func() (libsrv.Server, error) {
+		if fct == nil {
+			return nil, nil
+		}
+
+		p, e := fct().Pool(context.Background, hdl, log)
+
+		if e != nil {
+			return nil, e
+		}
+
+		return p, nil
+	}
+}
+
+// ServerSocket return a function building a socket server from the config returned by the given function.
+func ServerSocket(fct func() sckcfg.ServerConfig, upd libsck.UpdateConn, hdl libsck.Handler) FuncSocket {
+	return func() (libsck.Server, error) {
+		if fct == nil {
+			return nil, nil
+		}
+
+		return fct().New(upd, hdl)
+	}
+}
@@ -0,0 +1,107 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package service
+
+import (
+	"context"
+	"io"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+
+	libcbr "github.com/nabbar/golib/cobra"
+	libsrv "github.com/nabbar/golib/server"
+	libsck "github.com/nabbar/golib/socket"
+	libver "github.com/nabbar/golib/version"
+)
+
+// DefaultShutdownTimeout is the default delay given to all servers to stop gracefully.
+const DefaultShutdownTimeout = 30 * time.Second
+
+// FuncValidate is used to validate one part of the loaded configuration.
+type FuncValidate func() error
+
+// FuncServer is used to build a server from the loaded configuration.
+type FuncServer func() (libsrv.Server, error)
+
+// FuncSocket is used to build a socket server from the loaded configuration.
+type FuncSocket func() (libsck.Server, error)
+
+type Service interface {
+	// SetShutdownTimeout define the delay given to all servers to stop gracefully.
+	SetShutdownTimeout(dur time.Duration)
+
+	// SetSignals define the signals triggering the graceful shutdown. Default are SIGINT, SIGTERM and SIGQUIT.
+	SetSignals(sig ...os.Signal)
+
+	// RegisterValidate add a function called by the validate-config and serve commands.
+	RegisterValidate(name string, fct FuncValidate)
+
+	// RegisterServer add a function building a server started by the serve command.
+	RegisterServer(name string, fct FuncServer)
+
+	// RegisterSocket add a function building a socket server listening with the serve command.
+	RegisterSocket(name string, fct FuncSocket)
+
+	// Validate call all registered validate functions and return all errors joined.
+	Validate() error
+
+	// Serve validate the config, start all servers, wait for a signal or the end of the context
+	// and stop all servers in the reverse order of their registration.
+	Serve(ctx context.Context) error
+
+	// AddCommandServe add the "serve" command.
+	AddCommandServe()
+
+	// AddCommandValidate add the "validate-config" command.
+	AddCommandValidate()
+
+	// AddCommandPrintConfig add the "print-default-config" command.
+	AddCommandPrintConfig(defaultConfig func() io.Reader)
+
+	// AddCommandVersion add the "version" command.
+	AddCommandVersion()
+
+	// AddCommands add the serve, validate-config, print-default-config and version commands.
+	AddCommands(defaultConfig func() io.Reader)
+}
+
+// New return a service registering its commands into the given cobra instance.
+// The cobra instance must have been initialized before adding the commands.
+func New(cbr libcbr.Cobra, vrs libver.Version) Service {
+	return &svc{
+		m: sync.RWMutex{},
+		c: cbr,
+		v: vrs,
+		t: DefaultShutdownTimeout,
+		g: []os.Signal{syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT},
+		f: make([]item[FuncValidate], 0),
+		s: make([]item[FuncServer], 0),
+		k: make([]item[FuncSocket], 0),
+	}
+}
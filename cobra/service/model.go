@@ -0,0 +1,245 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	libcbr "github.com/nabbar/golib/cobra"
+	libsrv "github.com/nabbar/golib/server"
+	libsck "github.com/nabbar/golib/socket"
+	libver "github.com/nabbar/golib/version"
+)
+
+type item[T any] struct {
+	n string // name
+	f T      // function
+}
+
+type svc struct {
+	m sync.RWMutex
+	c libcbr.Cobra
+	v libver.Version
+	t time.Duration // shutdown timeout
+	g []os.Signal   // shutdown signals
+
+	f []item[FuncValidate] // validate functions
+	s []item[FuncServer]   // server functions
+	k []item[FuncSocket]   // socket functions
+}
+
+// running is a started server with its name, used to stop them in reverse order.
+type running struct {
+	n string
+	s libsrv.Server
+	k libsck.Server
+}
+
+func (o *svc) SetShutdownTimeout(dur time.Duration) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if dur <= 0 {
+		dur = DefaultShutdownTimeout
+	}
+
+	o.t = dur
+}
+
+func (o *svc) SetSignals(sig ...os.Signal) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.g = sig
+}
+
+func (o *svc) RegisterValidate(name string, fct FuncValidate) {
+	if fct == nil {
+		return
+	}
+
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.f = append(o.f, item[FuncValidate]{n: name, f: fct})
+}
+
+func (o *svc) RegisterServer(name string, fct FuncServer) {
+	if fct == nil {
+		return
+	}
+
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.s = append(o.s, item[FuncServer]{n: name, f: fct})
+}
+
+func (o *svc) RegisterSocket(name string, fct FuncSocket) {
+	if fct == nil {
+		return
+	}
+
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.k = append(o.k, item[FuncSocket]{n: name, f: fct})
+}
+
+func (o *svc) Validate() error {
+	o.m.RLock()
+	var lst = make([]item[FuncValidate], len(o.f))
+	copy(lst, o.f)
+	o.m.RUnlock()
+
+	var err = make([]error, 0)
+
+	for _, i := range lst {
+		if e := i.f(); e != nil {
+			err = append(err, fmt.Errorf("config '%s': %w", i.n, e))
+		}
+	}
+
+	return errors.Join(err...)
+}
+
+func (o *svc) Serve(ctx context.Context) error {
+	if e := o.Validate(); e != nil {
+		return e
+	}
+
+	o.m.RLock()
+	var (
+		srv = make([]item[FuncServer], len(o.s))
+		sck = make([]item[FuncSocket], len(o.k))
+		tmo = o.t
+		sig = make([]os.Signal, len(o.g))
+	)
+	copy(srv, o.s)
+	copy(sck, o.k)
+	copy(sig, o.g)
+	o.m.RUnlock()
+
+	var (
+		x, n = context.WithCancel(ctx)
+		run  = make([]running, 0, len(srv)+len(sck))
+		err  error
+	)
+
+	defer n()
+
+	for _, i := range srv {
+		if s, e := i.f(); e != nil {
+			err = fmt.Errorf("building server '%s': %w", i.n, e)
+			break
+		} else if s == nil {
+			continue
+		} else if e = s.Start(x); e != nil {
+			err = fmt.Errorf("starting server '%s': %w", i.n, e)
+			break
+		} else {
+			run = append(run, running{n: i.n, s: s})
+		}
+	}
+
+	var lst = make(chan error, len(sck))
+
+	for _, i := range sck {
+		if err != nil {
+			break
+		} else if s, e := i.f(); e != nil {
+			err = fmt.Errorf("building socket '%s': %w", i.n, e)
+		} else if s != nil {
+			run = append(run, running{n: i.n, k: s})
+
+			go func(name string, s libsck.Server) {
+				if e := s.Listen(x); e != nil && !s.IsClosed() {
+					lst <- fmt.Errorf("listening socket '%s': %w", name, e)
+				}
+			}(i.n, s)
+		}
+	}
+
+	if err == nil {
+		err = o.wait(x, sig, lst)
+	}
+
+	n()
+
+	return errors.Join(err, o.stop(run, tmo))
+}
+
+// wait block until a signal is received, the context is done or a socket failed to listen.
+func (o *svc) wait(ctx context.Context, sig []os.Signal, lst <-chan error) error {
+	var q = make(chan os.Signal, 1)
+
+	if len(sig) > 0 {
+		signal.Notify(q, sig...)
+		defer signal.Stop(q)
+	}
+
+	select {
+	case <-q:
+		return nil
+	case <-ctx.Done():
+		return nil
+	case e := <-lst:
+		return e
+	}
+}
+
+// stop shutdown all running servers in the reverse order of their start.
+func (o *svc) stop(run []running, tmo time.Duration) error {
+	var (
+		x, n = context.WithTimeout(context.Background(), tmo)
+		err  = make([]error, 0)
+	)
+
+	defer n()
+
+	for i := len(run) - 1; i >= 0; i-- {
+		var e error
+
+		if run[i].s != nil {
+			e = run[i].s.Stop(x)
+		} else if run[i].k != nil {
+			e = run[i].k.Shutdown(x)
+		}
+
+		if e != nil {
+			err = append(err, fmt.Errorf("stopping '%s': %w", run[i].n, e))
+		}
+	}
+
+	return errors.Join(err...)
+}
@@ -40,7 +40,16 @@ var _defaultConfigStandard = []byte(`{
   "accesskey": "",
   "secretkey": "",
   "region": "",
-  "endpoint": ""
+  "endpoint": "",
+  "object": {
+    "storageClass": "",
+    "encryption": "",
+    "kmsKeyId": "",
+    "bucketKey": false,
+    "lockMode": "",
+    "lockRetention": "0s",
+    "legalHold": false
+  }
 }`)
 
 var _defaultConfigStandardWithStatus = []byte(`{
@@ -53,7 +62,16 @@ var _defaultConfigCustom = []byte(`{
   "accesskey": "",
   "secretkey": "",
   "region": "",
-  "endpoint": ""
+  "endpoint": "",
+  "object": {
+    "storageClass": "",
+    "encryption": "",
+    "kmsKeyId": "",
+    "bucketKey": false,
+    "lockMode": "",
+    "lockRetention": "0s",
+    "legalHold": false
+  }
 }`)
 
 var _defaultConfigCustomWithStatus = []byte(`{
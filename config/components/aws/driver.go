@@ -128,6 +128,7 @@ func (a ConfigDriver) NewFromModel(i interface{}) (libaws.Config, error) {
 				return nil, ErrorConfigInvalid.Error(err)
 			} else {
 				cfg := cfgcus.NewConfig(o.Bucket, o.AccessKey, o.SecretKey, edp, o.Region)
+				cfg.SetObjectOptions(o.Object)
 
 				if e := cfg.RegisterRegionAws(edp); e != nil {
 					return cfg, e
@@ -141,7 +142,10 @@ func (a ConfigDriver) NewFromModel(i interface{}) (libaws.Config, error) {
 		if o, ok := i.(cfgstd.Model); !ok {
 			return nil, ErrorConfigInvalid.Error(nil)
 		} else {
-			return cfgstd.NewConfig(o.Bucket, o.AccessKey, o.SecretKey, o.Region), nil
+			cfg := cfgstd.NewConfig(o.Bucket, o.AccessKey, o.SecretKey, o.Region)
+			cfg.SetObjectOptions(o.Object)
+
+			return cfg, nil
 		}
 	}
 }
@@ -65,9 +65,9 @@ func (o *componentAws) _registerMonitor(opt *libreq.OptionsHealth, aws libaws.Co
 	res["runtime"] = runtime.Version()[2:]
 
 	if vrs != nil {
-		res["release"] = vrs.GetRelease()
-		res["build"] = vrs.GetBuild()
-		res["date"] = vrs.GetDate()
+		for k, v := range vrs.GetInfoMap() {
+			res[k] = v
+		}
 		res["endpoint"] = aws.GetEndpoint().Host
 		res["region"] = aws.GetRegion()
 		res["health"] = o._getEndpoint(opt, aws)
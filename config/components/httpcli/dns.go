@@ -33,6 +33,7 @@ import (
 	"time"
 
 	htcdns "github.com/nabbar/golib/httpcli/dns-mapper"
+	libres "github.com/nabbar/golib/network/resolver"
 )
 
 func (o *componentHttpClient) Close() error {
@@ -72,6 +73,12 @@ func (o *componentHttpClient) DialContext(ctx context.Context, network, address
 	return nil, ErrorComponentNotInitialized.Error()
 }
 
+func (o *componentHttpClient) SetResolver(r libres.Resolver) {
+	if d := o.getDNSMapper(); d != nil {
+		d.SetResolver(r)
+	}
+}
+
 func (o *componentHttpClient) Transport(cfg htcdns.TransportConfig) *http.Transport {
 	if d := o.getDNSMapper(); d != nil {
 		return d.Transport(cfg)
@@ -27,9 +27,12 @@
 package log
 
 import (
+	"fmt"
+
 	cfgtps "github.com/nabbar/golib/config/types"
 	liblog "github.com/nabbar/golib/logger"
 	logcfg "github.com/nabbar/golib/logger/config"
+	loglvl "github.com/nabbar/golib/logger/level"
 	libver "github.com/nabbar/golib/version"
 	libvpr "github.com/nabbar/golib/viper"
 	spfvbr "github.com/spf13/viper"
@@ -176,6 +179,11 @@ func (o *componentLog) _runCli() error {
 		return prt.Error(e)
 	}
 
+	// startup banner with the version and build info of the application
+	if vrs := o._getVersion(); vrs != nil && prt == ErrorStartLog {
+		o.l.Entry(loglvl.InfoLevel, fmt.Sprintf("starting %s", vrs.GetHeader())).DataSet(vrs.GetInfoMap()).Log()
+	}
+
 	return nil
 }
 
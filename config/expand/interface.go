@@ -0,0 +1,88 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package expand
+
+import (
+	"context"
+	"sync"
+)
+
+const (
+	// SchemeEnv is the scheme of environment variable reference: ${env://NAME}
+	SchemeEnv = "env"
+	// SchemeFile is the scheme of file reference: ${file:///run/secrets/name}
+	SchemeFile = "file"
+	// SchemeVault is the scheme of hashicorp vault reference: ${vault://secret/data/path#key}
+	SchemeVault = "vault"
+)
+
+// Resolver is used to retrieve the value of a reference for a given scheme.
+// The reference is given without the scheme prefix (ie: for "file:///run/secret", ref is "/run/secret").
+type Resolver interface {
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// FuncResolver is a function that implement the Resolver interface.
+type FuncResolver func(ctx context.Context, ref string) (string, error)
+
+func (f FuncResolver) Resolve(ctx context.Context, ref string) (string, error) {
+	return f(ctx, ref)
+}
+
+// Expander expand the placeholders found into strings. The supported syntax is:
+//   - ${NAME} or ${NAME:-default} to use an environment variable, with an optional default value
+//   - ${scheme://ref} to use the resolver registered for the scheme (env, file, vault, ...)
+//   - $${ to write a literal "${"
+type Expander interface {
+	// Register add or replace the resolver of the given scheme. A nil resolver remove the scheme.
+	Register(scheme string, res Resolver)
+
+	// Schemes return the list of registered schemes.
+	Schemes() []string
+
+	// Expand return the given string with all placeholders replaced by their values.
+	Expand(ctx context.Context, str string) (string, error)
+
+	// ExpandStruct walk the given pointer and expand all exported strings fields, including
+	// strings into slices, arrays and maps.
+	ExpandStruct(ctx context.Context, ptr interface{}) error
+}
+
+// New return an expander with the env, file and vault resolvers registered.
+// The vault resolver use the VAULT_ADDR and VAULT_TOKEN environment variables.
+func New() Expander {
+	e := &exp{
+		m: sync.RWMutex{},
+		r: make(map[string]Resolver),
+	}
+
+	e.Register(SchemeEnv, ResolverEnv())
+	e.Register(SchemeFile, ResolverFile())
+	e.Register(SchemeVault, ResolverVault("", ""))
+
+	return e
+}
@@ -0,0 +1,150 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package expand
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type exp struct {
+	m sync.RWMutex
+	r map[string]Resolver // resolvers by scheme
+}
+
+func (o *exp) Register(scheme string, res Resolver) {
+	scheme = strings.ToLower(strings.TrimSpace(scheme))
+
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if res == nil {
+		delete(o.r, scheme)
+	} else {
+		o.r[scheme] = res
+	}
+}
+
+func (o *exp) Schemes() []string {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	var res = make([]string, 0, len(o.r))
+
+	for k := range o.r {
+		res = append(res, k)
+	}
+
+	sort.Strings(res)
+	return res
+}
+
+func (o *exp) getResolver(scheme string) Resolver {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	return o.r[strings.ToLower(scheme)]
+}
+
+func (o *exp) Expand(ctx context.Context, str string) (string, error) {
+	if o == nil {
+		return str, ErrInvalidInstance
+	} else if !strings.Contains(str, "${") {
+		return str, nil
+	}
+
+	var (
+		buf = strings.Builder{}
+		idx int
+	)
+
+	for idx < len(str) {
+		var i = strings.Index(str[idx:], "${")
+
+		if i < 0 {
+			buf.WriteString(str[idx:])
+			break
+		}
+
+		i += idx
+
+		// escaped placeholder: $${ => ${
+		if i > 0 && str[i-1] == '$' {
+			buf.WriteString(str[idx : i-1])
+			buf.WriteString("${")
+			idx = i + 2
+			continue
+		}
+
+		buf.WriteString(str[idx:i])
+
+		var j = strings.IndexByte(str[i+2:], '}')
+
+		if j < 0 {
+			return str, fmt.Errorf("%w at position %d", ErrUnclosed, i)
+		}
+
+		v, e := o.resolve(ctx, str[i+2:i+2+j])
+
+		if e != nil {
+			return str, e
+		}
+
+		buf.WriteString(v)
+		idx = i + 2 + j + 1
+	}
+
+	return buf.String(), nil
+}
+
+func (o *exp) resolve(ctx context.Context, ref string) (string, error) {
+	if s, r, ok := strings.Cut(ref, "://"); ok {
+		if res := o.getResolver(s); res == nil {
+			return "", fmt.Errorf("%w '%s'", ErrUnknownScheme, s)
+		} else if v, e := res.Resolve(ctx, r); e != nil {
+			return "", fmt.Errorf("resolving '%s://': %w", s, e)
+		} else {
+			return v, nil
+		}
+	}
+
+	var (
+		k, d, h = strings.Cut(ref, ":-")
+	)
+
+	if v, ok := os.LookupEnv(strings.TrimSpace(k)); ok && (len(v) > 0 || !h) {
+		return v, nil
+	} else if h {
+		return d, nil
+	}
+
+	return "", nil
+}
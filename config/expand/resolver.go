@@ -0,0 +1,153 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package expand
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// VaultTimeout is the timeout of each request sent to the vault server by ResolverVault.
+const VaultTimeout = 10 * time.Second
+
+// ResolverEnv return a resolver using the environment variable named by the reference.
+// A missing variable is an error.
+func ResolverEnv() Resolver {
+	return FuncResolver(func(ctx context.Context, ref string) (string, error) {
+		if v, ok := os.LookupEnv(ref); ok {
+			return v, nil
+		}
+
+		return "", fmt.Errorf("%w: environment variable '%s'", ErrNotFound, ref)
+	})
+}
+
+// ResolverFile return a resolver reading the file given by the reference, like a docker or kubernetes secret.
+// The trailing line feed are removed.
+func ResolverFile() Resolver {
+	return FuncResolver(func(ctx context.Context, ref string) (string, error) {
+		if p, e := os.ReadFile(ref); e != nil {
+			return "", e
+		} else {
+			return strings.TrimRight(string(p), "\r\n"), nil
+		}
+	})
+}
+
+// ResolverVault return a resolver reading a secret from a hashicorp vault server with the http api.
+// The reference is the secret path followed by the key of the value: secret/data/myapp#password.
+// Both kv version 1 and 2 engines are supported. If the address or token are empty, the
+// VAULT_ADDR and VAULT_TOKEN environment variables are used when resolving.
+// Each request is limited by VaultTimeout, in addition to the deadline of the given context.
+func ResolverVault(addr, token string) Resolver {
+	var cli = &http.Client{
+		Timeout: VaultTimeout,
+	}
+
+	return FuncResolver(func(ctx context.Context, ref string) (string, error) {
+		var (
+			a = addr
+			t = token
+		)
+
+		if len(a) < 1 {
+			a = os.Getenv("VAULT_ADDR")
+		}
+
+		if len(t) < 1 {
+			t = os.Getenv("VAULT_TOKEN")
+		}
+
+		if len(a) < 1 {
+			return "", fmt.Errorf("vault address is not defined")
+		}
+
+		pth, key, ok := strings.Cut(ref, "#")
+
+		if !ok || len(key) < 1 {
+			return "", fmt.Errorf("missing key into vault reference '%s'", ref)
+		}
+
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(a, "/")+"/v1/"+strings.TrimLeft(pth, "/"), nil)
+
+		if err != nil {
+			return "", err
+		} else if len(t) > 0 {
+			req.Header.Set("X-Vault-Token", t)
+		}
+
+		rsp, err := cli.Do(req)
+
+		if err != nil {
+			return "", err
+		}
+
+		defer func() {
+			_ = rsp.Body.Close()
+		}()
+
+		if rsp.StatusCode == http.StatusNotFound {
+			return "", fmt.Errorf("%w: vault secret '%s'", ErrNotFound, pth)
+		} else if rsp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("vault server respond with status '%s'", rsp.Status)
+		}
+
+		var res = struct {
+			Data map[string]interface{} `json:"data"`
+		}{}
+
+		if err = json.NewDecoder(rsp.Body).Decode(&res); err != nil {
+			return "", err
+		}
+
+		var dat = res.Data
+
+		// kv version 2 engine nest the secret into data.data
+		if i, k := dat["data"].(map[string]interface{}); k {
+			if _, f := dat["metadata"]; f {
+				dat = i
+			}
+		}
+
+		if v, k := dat[key]; !k {
+			return "", fmt.Errorf("%w: key '%s' into vault secret '%s'", ErrNotFound, key, pth)
+		} else if s, k := v.(string); k {
+			return s, nil
+		} else {
+			return fmt.Sprint(v), nil
+		}
+	})
+}
@@ -0,0 +1,116 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package expand
+
+import (
+	"context"
+	"reflect"
+)
+
+func (o *exp) ExpandStruct(ctx context.Context, ptr interface{}) error {
+	if o == nil {
+		return ErrInvalidInstance
+	}
+
+	var v = reflect.ValueOf(ptr)
+
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return ErrInvalidPointer
+	}
+
+	return o.walk(ctx, v.Elem())
+}
+
+func (o *exp) walk(ctx context.Context, v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		return o.walk(ctx, v.Elem())
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+
+		// value into interface are not addressable: work on a copy and set it back
+		var c = reflect.New(v.Elem().Type()).Elem()
+		c.Set(v.Elem())
+
+		if e := o.walk(ctx, c); e != nil {
+			return e
+		} else if v.CanSet() {
+			v.Set(c)
+		}
+
+	case reflect.Struct:
+		var t = v.Type()
+
+		for i := 0; i < v.NumField(); i++ {
+			if !t.Field(i).IsExported() {
+				continue
+			} else if e := o.walk(ctx, v.Field(i)); e != nil {
+				return e
+			}
+		}
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if e := o.walk(ctx, v.Index(i)); e != nil {
+				return e
+			}
+		}
+
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+
+		for _, k := range v.MapKeys() {
+			var c = reflect.New(v.Type().Elem()).Elem()
+			c.Set(v.MapIndex(k))
+
+			if e := o.walk(ctx, c); e != nil {
+				return e
+			}
+
+			v.SetMapIndex(k, c)
+		}
+
+	case reflect.String:
+		if !v.CanSet() {
+			return nil
+		} else if s, e := o.Expand(ctx, v.String()); e != nil {
+			return e
+		} else {
+			v.SetString(s)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,168 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package watch
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Diff return the list of changes between the old and new value. Struct fields are compared
+// recursively and named by their json tag (or their name if no tag is set). Slices and values
+// of other kinds are compared as a whole. Unexported fields are ignored, except for
+// struct without any exported field (like time.Time) compared as a whole.
+func Diff(old, new interface{}) []Change {
+	var res = make([]Change, 0)
+	diffValue(&res, "", reflect.ValueOf(old), reflect.ValueOf(new))
+	return res
+}
+
+func diffValue(res *[]Change, path string, o, n reflect.Value) {
+	o = indirect(o)
+	n = indirect(n)
+
+	if !o.IsValid() || !n.IsValid() {
+		if o.IsValid() != n.IsValid() {
+			*res = append(*res, Change{Path: path, Old: valueOf(o), New: valueOf(n)})
+		}
+		return
+	} else if o.Type() != n.Type() {
+		*res = append(*res, Change{Path: path, Old: valueOf(o), New: valueOf(n)})
+		return
+	}
+
+	switch o.Kind() {
+	case reflect.Struct:
+		var t = o.Type()
+
+		if !hasExportedField(t) {
+			if !reflect.DeepEqual(valueOf(o), valueOf(n)) {
+				*res = append(*res, Change{Path: path, Old: valueOf(o), New: valueOf(n)})
+			}
+			return
+		}
+
+		for i := 0; i < t.NumField(); i++ {
+			var f = t.Field(i)
+
+			if !f.IsExported() {
+				continue
+			}
+
+			var name = fieldName(f)
+
+			if name == "-" {
+				continue
+			} else if f.Anonymous && len(f.Tag.Get("json")) < 1 {
+				diffValue(res, path, o.Field(i), n.Field(i))
+			} else {
+				diffValue(res, joinPath(path, name), o.Field(i), n.Field(i))
+			}
+		}
+
+	case reflect.Map:
+		var key = make(map[string]reflect.Value)
+
+		for _, k := range o.MapKeys() {
+			key[fmt.Sprint(k.Interface())] = k
+		}
+
+		for _, k := range n.MapKeys() {
+			key[fmt.Sprint(k.Interface())] = k
+		}
+
+		var lst = make([]string, 0, len(key))
+
+		for k := range key {
+			lst = append(lst, k)
+		}
+
+		sort.Strings(lst)
+
+		for _, k := range lst {
+			diffValue(res, joinPath(path, k), o.MapIndex(key[k]), n.MapIndex(key[k]))
+		}
+
+	default:
+		if !reflect.DeepEqual(valueOf(o), valueOf(n)) {
+			*res = append(*res, Change{Path: path, Old: valueOf(o), New: valueOf(n)})
+		}
+	}
+}
+
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+
+	return v
+}
+
+func hasExportedField(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).IsExported() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func valueOf(v reflect.Value) interface{} {
+	if !v.IsValid() || !v.CanInterface() {
+		return nil
+	}
+
+	return v.Interface()
+}
+
+func fieldName(f reflect.StructField) string {
+	var tag = f.Tag.Get("json")
+
+	if i := strings.Index(tag, ","); i >= 0 {
+		tag = tag[:i]
+	}
+
+	if len(tag) > 0 {
+		return tag
+	}
+
+	return f.Name
+}
+
+func joinPath(path, name string) string {
+	if len(path) < 1 {
+		return name
+	}
+
+	return path + "." + name
+}
@@ -0,0 +1,151 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package watch
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	cfgexp "github.com/nabbar/golib/config/expand"
+	libsrv "github.com/nabbar/golib/server"
+)
+
+const (
+	// DefaultInterval is the default polling interval when the file system notification is not available.
+	DefaultInterval = 5 * time.Second
+	// DefaultDebounce is the default delay used to merge several file events into one reload.
+	DefaultDebounce = 250 * time.Millisecond
+	// DefaultExpandTimeout is the default deadline to expand the references of the config, like the vault secrets.
+	DefaultExpandTimeout = 30 * time.Second
+)
+
+// Change describe the change of one field between the old and the new config.
+// The path is composed of the json name of each field, joined by a dot.
+type Change struct {
+	Path string
+	Old  interface{}
+	New  interface{}
+}
+
+// FuncValidate is used to validate a config before swapping it.
+type FuncValidate[T any] func(cfg T) error
+
+// FuncChange is called with the old and new config and the list of changes after each successful swap.
+// The returned error is reported to the error function but does not revert the swap.
+type FuncChange[T any] func(old, new T, chg []Change) error
+
+// FuncError is used to report errors while watching or loading the config file.
+type FuncError func(err error)
+
+type Options struct {
+	// Format define the format of the file. If FormatAuto, the format is detected from the file extension.
+	Format Format
+
+	// Poll force the polling mode instead of the file system notification.
+	Poll bool
+
+	// Interval define the polling interval. Default is 5 seconds.
+	Interval time.Duration
+
+	// Debounce define the delay used to merge several file events into one reload. Default is 250 ms.
+	Debounce time.Duration
+
+	// Expand if defined is used to expand the environment variables and secret references
+	// of all strings fields after decoding the file and before validating it.
+	Expand cfgexp.Expander
+
+	// ExpandTimeout define the deadline of the expansion of one load. Default is 30 seconds.
+	ExpandTimeout time.Duration
+}
+
+type Watcher[T any] interface {
+	// Start load the file and start watching it. Stop end the watching but keep the last config.
+	libsrv.Server
+
+	// Path return the path of the watched file.
+	Path() string
+
+	// Get return the current config.
+	Get() T
+
+	// Load read, decode and validate the file, then swap the config and notify the subscribers
+	// if the config has changed. On error, the current config is kept.
+	Load() error
+
+	// RegisterValidator define an additional validation function, called after the struct validation.
+	RegisterValidator(fct FuncValidate[T])
+
+	// RegisterFuncError define the function used to report errors while watching the file.
+	RegisterFuncError(fct FuncError)
+
+	// Subscribe register a function called after each successful swap of the config.
+	Subscribe(fct FuncChange[T])
+
+	// IsPolling return true if the watcher use the polling mode.
+	IsPolling() bool
+}
+
+// New return a watcher for the given file path. The file is not read until Load or Start is called.
+func New[T any](path string, opt Options) (Watcher[T], error) {
+	if len(path) < 1 {
+		return nil, ErrInvalidPath
+	}
+
+	if opt.Format == FormatAuto {
+		opt.Format = FormatFromPath(path)
+	}
+
+	if opt.Format == FormatAuto {
+		return nil, ErrUnknownFormat
+	}
+
+	if opt.Interval <= 0 {
+		opt.Interval = DefaultInterval
+	}
+
+	if opt.Debounce <= 0 {
+		opt.Debounce = DefaultDebounce
+	}
+
+	if opt.ExpandTimeout <= 0 {
+		opt.ExpandTimeout = DefaultExpandTimeout
+	}
+
+	return &wtc[T]{
+		m: sync.RWMutex{},
+		p: path,
+		o: opt,
+		c: new(atomic.Pointer[T]),
+		s: make([]FuncChange[T], 0),
+		v: make([]FuncValidate[T], 0),
+		e: nil,
+		r: new(atomic.Bool),
+		w: new(atomic.Bool),
+		n: nil,
+	}, nil
+}
@@ -0,0 +1,251 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package watch
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	libval "github.com/go-playground/validator/v10"
+	liberr "github.com/nabbar/golib/errors"
+	iowtch "github.com/nabbar/golib/ioutils/watcher"
+)
+
+type wtc[T any] struct {
+	m sync.RWMutex
+	p string             // path
+	o Options            // options
+	c *atomic.Pointer[T] // current config
+	s []FuncChange[T]    // subscribers
+	v []FuncValidate[T]  // additional validators
+	e FuncError          // error function
+	r *atomic.Bool       // is running
+	w *atomic.Bool       // polling mode in use
+	n iowtch.Watcher     // file watcher
+	t time.Time          // start time
+	h [sha256.Size]byte  // hash of the last loaded content
+	l sync.Mutex         // load lock, to serialize the reload
+}
+
+func (o *wtc[T]) Path() string {
+	return o.p
+}
+
+func (o *wtc[T]) Get() T {
+	if p := o.c.Load(); p != nil {
+		return *p
+	}
+
+	var t T
+	return t
+}
+
+func (o *wtc[T]) IsPolling() bool {
+	return o.w.Load()
+}
+
+func (o *wtc[T]) RegisterValidator(fct FuncValidate[T]) {
+	if fct == nil {
+		return
+	}
+
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.v = append(o.v, fct)
+}
+
+func (o *wtc[T]) RegisterFuncError(fct FuncError) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.e = fct
+}
+
+func (o *wtc[T]) Subscribe(fct FuncChange[T]) {
+	if fct == nil {
+		return
+	}
+
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.s = append(o.s, fct)
+}
+
+func (o *wtc[T]) Load() error {
+	o.l.Lock()
+	defer o.l.Unlock()
+
+	p, e := os.ReadFile(o.p)
+
+	if e != nil {
+		return e
+	}
+
+	var h = sha256.Sum256(p)
+
+	if o.c.Load() != nil && bytes.Equal(h[:], o.h[:]) {
+		return nil
+	}
+
+	var n = new(T)
+
+	if e = o.o.Format.Decode(p, n); e != nil {
+		return fmt.Errorf("decoding config file '%s': %w", o.p, e)
+	}
+
+	if o.o.Expand != nil {
+		x, c := context.WithTimeout(context.Background(), o.o.ExpandTimeout)
+		e = o.o.Expand.ExpandStruct(x, n)
+		c()
+
+		if e != nil {
+			return fmt.Errorf("expanding config file '%s': %w", o.p, e)
+		}
+	}
+
+	if e = o.validate(n); e != nil {
+		return e
+	}
+
+	var (
+		old = o.c.Load()
+		chg []Change
+	)
+
+	if old != nil {
+		if chg = Diff(*old, *n); len(chg) < 1 {
+			o.h = h
+			return nil
+		}
+	} else {
+		old = new(T)
+		chg = Diff(*old, *n)
+	}
+
+	o.c.Store(n)
+	o.h = h
+
+	o.m.RLock()
+	var fct = make([]FuncChange[T], len(o.s))
+	copy(fct, o.s)
+	o.m.RUnlock()
+
+	var err = make([]error, 0)
+
+	for _, f := range fct {
+		if r := o.callChange(f, *old, *n, chg); r != nil {
+			err = append(err, r)
+		}
+	}
+
+	return errors.Join(err...)
+}
+
+func (o *wtc[T]) callChange(f FuncChange[T], old, new T, chg []Change) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("recovered panic in config subscriber: %v", r)
+		}
+	}()
+
+	return f(old, new, chg)
+}
+
+func (o *wtc[T]) validate(n *T) error {
+	var err error
+
+	switch v := interface{}(n).(type) {
+	case interface{ Validate() liberr.Error }:
+		if e := v.Validate(); e != nil {
+			err = e
+		}
+	case interface{ Validate() error }:
+		err = v.Validate()
+	default:
+		if e := libval.New().Struct(n); e != nil {
+			var ve libval.ValidationErrors
+
+			if errors.As(e, &ve) {
+				var l = make([]error, 0, len(ve))
+
+				for _, i := range ve {
+					//nolint #goerr113
+					l = append(l, fmt.Errorf("config field '%s' is not validated by constraint '%s'", i.Namespace(), i.ActualTag()))
+				}
+
+				err = errors.Join(l...)
+			} else if !isInvalidValidation(e) {
+				err = e
+			}
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+
+	o.m.RLock()
+	var fct = make([]FuncValidate[T], len(o.v))
+	copy(fct, o.v)
+	o.m.RUnlock()
+
+	for _, f := range fct {
+		if e := f(*n); e != nil {
+			return fmt.Errorf("%w: %w", ErrValidation, e)
+		}
+	}
+
+	return nil
+}
+
+func isInvalidValidation(e error) bool {
+	var i *libval.InvalidValidationError
+	return errors.As(e, &i)
+}
+
+func (o *wtc[T]) onError(e error) {
+	if e == nil {
+		return
+	}
+
+	o.m.RLock()
+	var f = o.e
+	o.m.RUnlock()
+
+	if f != nil {
+		f(e)
+	}
+}
@@ -0,0 +1,114 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package watch
+
+import (
+	"context"
+	"time"
+
+	iowtch "github.com/nabbar/golib/ioutils/watcher"
+)
+
+func (o *wtc[T]) Start(ctx context.Context) error {
+	if o.r.Load() {
+		_ = o.Stop(ctx)
+	}
+
+	if e := o.Load(); e != nil {
+		return e
+	}
+
+	n, e := iowtch.WatchOptions(o.p, iowtch.Options{
+		Poll:      o.o.Poll,
+		Interval:  o.o.Interval,
+		Debounce:  o.o.Debounce,
+		FuncError: o.onError,
+	}, iowtch.EventCreate|iowtch.EventWrite, func(_ string, _ iowtch.Event) {
+		o.onError(o.Load())
+	})
+
+	if e != nil {
+		return e
+	}
+
+	o.m.Lock()
+	o.n = n
+	o.t = time.Now()
+	o.m.Unlock()
+
+	o.r.Store(true)
+	o.w.Store(n.IsPolling())
+
+	return nil
+}
+
+func (o *wtc[T]) Stop(ctx context.Context) error {
+	o.m.Lock()
+	var n = o.n
+	o.n = nil
+	o.t = time.Time{}
+	o.m.Unlock()
+
+	o.r.Store(false)
+
+	if n == nil {
+		return nil
+	}
+
+	var c = make(chan error, 1)
+
+	go func() {
+		c <- n.Close()
+	}()
+
+	select {
+	case e := <-c:
+		return e
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (o *wtc[T]) Restart(ctx context.Context) error {
+	_ = o.Stop(ctx)
+	return o.Start(ctx)
+}
+
+func (o *wtc[T]) IsRunning() bool {
+	return o.r.Load()
+}
+
+func (o *wtc[T]) Uptime() time.Duration {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	if o.t.IsZero() {
+		return 0
+	}
+
+	return time.Since(o.t)
+}
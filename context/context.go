@@ -24,10 +24,91 @@ SOFTWARE.
 
 package context
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 func IsolateParent(parent context.Context) context.Context {
 	//nolint #govet
 	x, _ := context.WithCancel(parent)
 	return x
 }
+
+// Detach returns a context carrying the values of parent but never canceled,
+// with no deadline: useful to hand a request or connection context to a background worker.
+func Detach(parent context.Context) context.Context {
+	if parent == nil {
+		parent = context.Background()
+	}
+
+	return context.WithoutCancel(parent)
+}
+
+// Merge returns a context done as soon as one of ctx1 or ctx2 is done.
+// Values are resolved from ctx1 first and then from ctx2, the deadline is the earliest one.
+// The returned cancel func must be called to release resources once the context is no more used.
+func Merge(ctx1, ctx2 context.Context) (context.Context, context.CancelFunc) {
+	if ctx1 == nil {
+		ctx1 = context.Background()
+	}
+
+	if ctx2 == nil {
+		ctx2 = context.Background()
+	}
+
+	x, n := context.WithCancelCause(ctx1)
+	s := context.AfterFunc(ctx2, func() {
+		n(context.Cause(ctx2))
+	})
+
+	m := &merged{
+		Context: x,
+		c1:      ctx1,
+		c2:      ctx2,
+	}
+
+	return m, func() {
+		s()
+		n(context.Canceled)
+	}
+}
+
+type merged struct {
+	context.Context
+	c1 context.Context
+	c2 context.Context
+}
+
+func (o *merged) Deadline() (deadline time.Time, ok bool) {
+	d1, k1 := o.c1.Deadline()
+	d2, k2 := o.c2.Deadline()
+
+	if !k1 {
+		return d2, k2
+	} else if !k2 || d1.Before(d2) {
+		return d1, k1
+	}
+
+	return d2, k2
+}
+
+func (o *merged) Err() error {
+	if e := o.Context.Err(); e == nil {
+		return nil
+	} else if e = o.c1.Err(); e != nil {
+		return e
+	} else if e = o.c2.Err(); e != nil {
+		return e
+	} else {
+		return o.Context.Err()
+	}
+}
+
+func (o *merged) Value(key any) any {
+	if v := o.Context.Value(key); v != nil {
+		return v
+	}
+
+	return o.c2.Value(key)
+}
@@ -0,0 +1,228 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package context_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	libctx "github.com/nabbar/golib/context"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type ctxKey string
+
+var errCause = errors.New("cause of the cancel")
+
+var _ = Describe("Context Merge", func() {
+	Context("canceling one of the parents", func() {
+		DescribeTable("must cancel the merged context with the cause of the parent",
+			func(first bool) {
+				var (
+					p1, n1 = context.WithCancelCause(ctx)
+					p2, n2 = context.WithCancelCause(ctx)
+				)
+
+				defer n1(nil)
+				defer n2(nil)
+
+				x, n := libctx.Merge(p1, p2)
+				defer n()
+
+				Expect(x.Err()).ToNot(HaveOccurred())
+				Consistently(x.Done(), 100*time.Millisecond).ShouldNot(BeClosed())
+
+				if first {
+					n1(errCause)
+				} else {
+					n2(errCause)
+				}
+
+				Eventually(x.Done()).Should(BeClosed())
+				Expect(x.Err()).To(MatchError(context.Canceled))
+				Expect(context.Cause(x)).To(MatchError(errCause))
+			},
+			Entry("the first parent", true),
+			Entry("the second parent", false),
+		)
+
+		It("must report the deadline exceeded of a parent", func() {
+			p2, n2 := context.WithTimeout(ctx, 50*time.Millisecond)
+			defer n2()
+
+			x, n := libctx.Merge(ctx, p2)
+			defer n()
+
+			Eventually(x.Done()).Should(BeClosed())
+			Expect(x.Err()).To(MatchError(context.DeadlineExceeded))
+			Expect(context.Cause(x)).To(MatchError(context.DeadlineExceeded))
+		})
+
+		It("must be done immediately with an already canceled parent", func() {
+			p2, n2 := context.WithCancelCause(ctx)
+			n2(errCause)
+
+			x, n := libctx.Merge(ctx, p2)
+			defer n()
+
+			Eventually(x.Done()).Should(BeClosed())
+			Expect(context.Cause(x)).To(MatchError(errCause))
+		})
+
+		It("must cancel the merged context with its cancel func and not the parents", func() {
+			p1, n1 := context.WithCancel(ctx)
+			defer n1()
+
+			p2, n2 := context.WithCancel(ctx)
+			defer n2()
+
+			x, n := libctx.Merge(p1, p2)
+			n()
+
+			Expect(x.Done()).To(BeClosed())
+			Expect(x.Err()).To(MatchError(context.Canceled))
+			Expect(p1.Err()).ToNot(HaveOccurred())
+			Expect(p2.Err()).ToNot(HaveOccurred())
+		})
+
+		It("must accept nil parents", func() {
+			//nolint staticcheck
+			x, n := libctx.Merge(nil, nil)
+			defer n()
+
+			Expect(x.Err()).ToNot(HaveOccurred())
+			_, ok := x.Deadline()
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Context("getting the deadline", func() {
+		DescribeTable("must return the earliest deadline of the parents",
+			func(d1, d2 time.Duration, expect int) {
+				var (
+					now    = time.Now()
+					p1, p2 = ctx, ctx
+				)
+
+				if d1 > 0 {
+					var n context.CancelFunc
+					p1, n = context.WithDeadline(ctx, now.Add(d1))
+					defer n()
+				}
+
+				if d2 > 0 {
+					var n context.CancelFunc
+					p2, n = context.WithDeadline(ctx, now.Add(d2))
+					defer n()
+				}
+
+				x, n := libctx.Merge(p1, p2)
+				defer n()
+
+				d, ok := x.Deadline()
+
+				switch expect {
+				case 1:
+					Expect(ok).To(BeTrue())
+					Expect(d).To(Equal(now.Add(d1)))
+				case 2:
+					Expect(ok).To(BeTrue())
+					Expect(d).To(Equal(now.Add(d2)))
+				default:
+					Expect(ok).To(BeFalse())
+				}
+			},
+			Entry("no deadline", time.Duration(0), time.Duration(0), 0),
+			Entry("only the first parent", time.Hour, time.Duration(0), 1),
+			Entry("only the second parent", time.Duration(0), time.Hour, 2),
+			Entry("the first parent earlier", time.Minute, time.Hour, 1),
+			Entry("the second parent earlier", time.Hour, time.Minute, 2),
+		)
+	})
+
+	Context("getting a value", func() {
+		DescribeTable("must resolve the values from the first parent and then from the second",
+			func(key ctxKey, expect any) {
+				var (
+					p1 = context.WithValue(context.WithValue(ctx, ctxKey("both"), "first"), ctxKey("first"), "first")
+					p2 = context.WithValue(context.WithValue(ctx, ctxKey("both"), "second"), ctxKey("second"), "second")
+				)
+
+				x, n := libctx.Merge(p1, p2)
+				defer n()
+
+				if expect == nil {
+					Expect(x.Value(key)).To(BeNil())
+				} else {
+					Expect(x.Value(key)).To(Equal(expect))
+				}
+			},
+			Entry("a key of both parents", ctxKey("both"), "first"),
+			Entry("a key of the first parent", ctxKey("first"), "first"),
+			Entry("a key of the second parent", ctxKey("second"), "second"),
+			Entry("a missing key", ctxKey("missing"), nil),
+		)
+	})
+})
+
+var _ = Describe("Context Detach", func() {
+	It("must keep the values and ignore the cancel and the deadline of the parent", func() {
+		p, n := context.WithTimeout(context.WithValue(ctx, ctxKey("key"), "value"), 50*time.Millisecond)
+		defer n()
+
+		x := libctx.Detach(p)
+
+		_, ok := x.Deadline()
+		Expect(ok).To(BeFalse())
+
+		Eventually(p.Done()).Should(BeClosed())
+		Expect(p.Err()).To(MatchError(context.DeadlineExceeded))
+
+		Expect(x.Done()).To(BeNil())
+		Expect(x.Err()).ToNot(HaveOccurred())
+		Expect(x.Value(ctxKey("key"))).To(Equal("value"))
+	})
+
+	It("must be canceled by its own cancel func when derived", func() {
+		p, n := context.WithCancel(ctx)
+		n()
+
+		x, c := context.WithCancel(libctx.Detach(p))
+		Expect(x.Err()).ToNot(HaveOccurred())
+
+		c()
+		Expect(x.Err()).To(MatchError(context.Canceled))
+	})
+
+	It("must accept a nil parent", func() {
+		//nolint staticcheck
+		x := libctx.Detach(nil)
+		Expect(x).ToNot(BeNil())
+		Expect(x.Err()).ToNot(HaveOccurred())
+	})
+})
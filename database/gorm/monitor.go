@@ -29,7 +29,6 @@ package gorm
 import (
 	"context"
 	"fmt"
-	"runtime"
 
 	libctx "github.com/nabbar/golib/context"
 	libmon "github.com/nabbar/golib/monitor"
@@ -60,10 +59,9 @@ func (d *database) Monitor(vrs libver.Version) (montps.Monitor, error) {
 		return nil, fmt.Errorf("cannot load config")
 	}
 
-	res["runtime"] = runtime.Version()[2:]
-	res["release"] = vrs.GetRelease()
-	res["build"] = vrs.GetBuild()
-	res["date"] = vrs.GetDate()
+	for k, v := range vrs.GetInfoMap() {
+		res[k] = v
+	}
 
 	if inf, e = moninf.New(defaultNameMonitor); e != nil {
 		return nil, e
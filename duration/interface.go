@@ -58,6 +58,10 @@ func Days(i int64) Duration {
 	return Duration(time.Duration(i) * time.Hour * 24)
 }
 
+func Weeks(i int64) Duration {
+	return Duration(time.Duration(i) * time.Hour * 24 * 7)
+}
+
 func ParseDuration(d time.Duration) Duration {
 	return Duration(d)
 }
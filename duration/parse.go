@@ -26,21 +26,208 @@
 package duration
 
 import (
+	"errors"
+	"fmt"
 	"strings"
 	"time"
 )
 
+// Based on ParseDuration from time package, extended with day & week units
+
+const (
+	Day  = 24 * time.Hour
+	Week = 7 * Day
+)
+
+var unitMap = map[string]uint64{
+	"ns": uint64(time.Nanosecond),
+	"us": uint64(time.Microsecond),
+	"µs": uint64(time.Microsecond), // U+00B5 = micro symbol
+	"μs": uint64(time.Microsecond), // U+03BC = Greek letter mu
+	"ms": uint64(time.Millisecond),
+	"s":  uint64(time.Second),
+	"m":  uint64(time.Minute),
+	"h":  uint64(time.Hour),
+	"d":  uint64(Day),
+	"w":  uint64(Week),
+}
+
 func parseString(s string) (Duration, error) {
+	// [-+]?([0-9]*(\.[0-9]*)?[a-z]+)+
+
 	s = strings.Replace(s, "\"", "", -1)
 	s = strings.Replace(s, "'", "", -1)
+	s = strings.TrimSpace(s)
 
-	// err: 99d55h44m33s123ms
+	var (
+		orig = s
+		neg  bool
 
-	if v, e := time.ParseDuration(s); e != nil {
-		return 0, e
-	} else {
-		return Duration(v), nil
+		d          uint64
+		errInvalid = fmt.Errorf("duration: invalid duration '%s'", orig)
+		errUnit    = fmt.Errorf("duration: missing unit in duration '%s'", orig)
+		errUnkUnit = fmt.Errorf("duration: unknown unit in duration '%s'", orig)
+	)
+
+	// Consume [-+]?
+	if s != "" {
+		c := s[0]
+		if c == '-' || c == '+' {
+			neg = c == '-'
+			s = s[1:]
+		}
+	}
+
+	// Special case: if all that is left is "0", this is zero.
+	if s == "0" {
+		return 0, nil
+	}
+
+	if s == "" {
+		return 0, errInvalid
+	}
+
+	for s != "" {
+		var (
+			v, f  uint64      // integers before, after decimal point
+			scale float64 = 1 // value = v + f/scale
+		)
+
+		var err error
+
+		// The next character must be [0-9.]
+		if !(s[0] == '.' || '0' <= s[0] && s[0] <= '9') {
+			return 0, errInvalid
+		}
+
+		// Consume [0-9]*
+		pl := len(s)
+		v, s, err = leadingInt(s)
+		if err != nil {
+			return 0, errInvalid
+		}
+		pre := pl != len(s) // whether we consumed anything before a period
+
+		// Consume (\.[0-9]*)?
+		post := false
+		if s != "" && s[0] == '.' {
+			s = s[1:]
+			pl := len(s)
+			f, scale, s = leadingFraction(s)
+			post = pl != len(s)
+		}
+		if !pre && !post {
+			// no digits (e.g. ".s" or "-.s")
+			return 0, errInvalid
+		}
+
+		// Consume unit.
+		i := 0
+
+		for ; i < len(s); i++ {
+			c := s[i]
+			if c == '.' || '0' <= c && c <= '9' {
+				break
+			}
+		}
+
+		if i == 0 {
+			return 0, errUnit
+		}
+
+		u := s[:i]
+		s = s[i:]
+		unit, ok := unitMap[u]
+
+		if !ok {
+			return 0, errUnkUnit
+		}
+
+		if v > 1<<63/unit {
+			// overflow
+			return 0, errInvalid
+		}
+
+		v *= unit
+		if f > 0 {
+			// float64 is needed to be nanosecond accurate for fractions of weeks.
+			v += uint64(float64(f) * (float64(unit) / scale))
+
+			if v > 1<<63 {
+				// overflow
+				return 0, errInvalid
+			}
+		}
+		d += v
+
+		if d > 1<<63 {
+			return 0, errInvalid
+		}
+	}
+
+	if neg {
+		return -Duration(d), nil
+	}
+
+	if d > 1<<63-1 {
+		return 0, errInvalid
+	}
+
+	return Duration(d), nil
+}
+
+// leadingInt consumes the leading [0-9]* from s.
+func leadingInt(s string) (x uint64, rem string, err error) {
+	var errLeadingInt = errors.New("duration: bad [0-9]*") // never printed
+
+	i := 0
+	for ; i < len(s); i++ {
+		c := s[i]
+		if c < '0' || c > '9' {
+			break
+		}
+		if x > 1<<63/10 {
+			// overflow
+			return 0, "", errLeadingInt
+		}
+		x = x*10 + uint64(c) - '0'
+		if x > 1<<63 {
+			// overflow
+			return 0, "", errLeadingInt
+		}
+	}
+	return x, s[i:], nil
+}
+
+// leadingFraction consumes the leading [0-9]* from s.
+// It is used only for fractions, so does not return an error on overflow,
+// it just stops accumulating precision.
+func leadingFraction(s string) (x uint64, scale float64, rem string) {
+	i := 0
+	scale = 1
+	overflow := false
+	for ; i < len(s); i++ {
+		c := s[i]
+		if c < '0' || c > '9' {
+			break
+		}
+		if overflow {
+			continue
+		}
+		if x > (1<<63-1)/10 {
+			// It's possible for overflow to give a positive number, so take care.
+			overflow = true
+			continue
+		}
+		y := x*10 + uint64(c) - '0'
+		if y > 1<<63 {
+			overflow = true
+			continue
+		}
+		x = y
+		scale *= 10
 	}
+	return x, scale, s[i:]
 }
 
 func (d *Duration) parseString(s string) error {
@@ -0,0 +1,95 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package duration_test
+
+import (
+	"math"
+	"time"
+
+	libdur "github.com/nabbar/golib/duration"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("duration parse", func() {
+	DescribeTable("valid duration",
+		func(s string, exp time.Duration) {
+			d, e := libdur.Parse(s)
+			Expect(e).ToNot(HaveOccurred())
+			Expect(d.Time()).To(Equal(exp))
+		},
+		Entry("zero", "0", time.Duration(0)),
+		Entry("negative zero", "-0", time.Duration(0)),
+		Entry("nanoseconds", "15ns", 15*time.Nanosecond),
+		Entry("microseconds", "15us", 15*time.Microsecond),
+		Entry("micro symbol", "15µs", 15*time.Microsecond),
+		Entry("hours and minutes", "1h30m", 90*time.Minute),
+		Entry("day", "1d", 24*time.Hour),
+		Entry("week", "2w", 14*24*time.Hour),
+		Entry("all units", "1w2d3h4m5s6ms7us8ns", libdur.Week+2*libdur.Day+3*time.Hour+4*time.Minute+5*time.Second+6*time.Millisecond+7*time.Microsecond+8*time.Nanosecond),
+		Entry("fraction of day", "1.5d", 36*time.Hour),
+		Entry("fraction of week", "0.5w", 84*time.Hour),
+		Entry("fraction without integer", ".5h", 30*time.Minute),
+		Entry("integer without fraction", "2.d", 48*time.Hour),
+		Entry("nanosecond fraction", "1.000000001s", time.Second+time.Nanosecond),
+		Entry("plus sign", "+2h", 2*time.Hour),
+		Entry("minus sign", "-1d12h", -36*time.Hour),
+		Entry("double quotes", `"3d"`, 72*time.Hour),
+		Entry("single quotes", "'3d'", 72*time.Hour),
+		Entry("spaces", " 4d ", 96*time.Hour),
+		Entry("max weeks", "15250w", 15250*libdur.Week),
+		Entry("max days", "106751d", 106751*libdur.Day),
+		Entry("max int64", "9223372036854775807ns", time.Duration(math.MaxInt64)),
+		Entry("min int64", "-9223372036854775808ns", time.Duration(math.MinInt64)),
+	)
+
+	DescribeTable("invalid duration",
+		func(s string) {
+			_, e := libdur.Parse(s)
+			Expect(e).To(HaveOccurred())
+		},
+		Entry("empty", ""),
+		Entry("sign only", "-"),
+		Entry("unit only", "d"),
+		Entry("missing unit", "1"),
+		Entry("missing last unit", "1d2"),
+		Entry("unknown unit", "1x"),
+		Entry("upper case unit", "1D"),
+		Entry("year unit", "1y"),
+		Entry("space into the value", "1 d"),
+		Entry("trailing sign", "1d-"),
+		Entry("dot without digit", ".d"),
+		Entry("trailing dot", "1h."),
+		Entry("letters", "abc"),
+		Entry("overflow of integer", "99999999999999999999s"),
+		Entry("overflow of int64", "9223372036854775808ns"),
+		Entry("overflow of weeks", "15251w"),
+		Entry("overflow of days", "106752d"),
+		Entry("overflow of fraction", "15250.5w"),
+		Entry("overflow of sum", "15250w1w"),
+	)
+})
@@ -0,0 +1,99 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package duration
+
+import (
+	"reflect"
+
+	libval "github.com/go-playground/validator/v10"
+)
+
+const (
+	// ValidatorTag checks a string field can be parsed as a duration.
+	ValidatorTag = "duration"
+	// ValidatorTagMin checks a duration or string field is at least the duration given as param, e.g. `validate:"duration_min=1s"`.
+	ValidatorTagMin = "duration_min"
+	// ValidatorTagMax checks a duration or string field is at most the duration given as param, e.g. `validate:"duration_max=2w"`.
+	ValidatorTagMax = "duration_max"
+)
+
+// RegisterValidation adds the duration tags to the given validator instance.
+func RegisterValidation(v *libval.Validate) error {
+	if e := v.RegisterValidation(ValidatorTag, validateDuration); e != nil {
+		return e
+	} else if e = v.RegisterValidation(ValidatorTagMin, validateDurationMin); e != nil {
+		return e
+	} else if e = v.RegisterValidation(ValidatorTagMax, validateDurationMax); e != nil {
+		return e
+	}
+
+	return nil
+}
+
+func fieldDuration(fl libval.FieldLevel) (Duration, bool) {
+	f := fl.Field()
+
+	switch f.Kind() {
+	case reflect.String:
+		if s := f.String(); len(s) < 1 {
+			return 0, true
+		} else if d, e := parseString(s); e != nil {
+			return 0, false
+		} else {
+			return d, true
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Duration(f.Int()), true
+	}
+
+	return 0, false
+}
+
+func validateDuration(fl libval.FieldLevel) bool {
+	_, ok := fieldDuration(fl)
+	return ok
+}
+
+func validateDurationMin(fl libval.FieldLevel) bool {
+	if d, ok := fieldDuration(fl); !ok {
+		return false
+	} else if p, e := parseString(fl.Param()); e != nil {
+		return false
+	} else {
+		return d >= p
+	}
+}
+
+func validateDurationMax(fl libval.FieldLevel) bool {
+	if d, ok := fieldDuration(fl); !ok {
+		return false
+	} else if p, e := parseString(fl.Param()); e != nil {
+		return false
+	} else {
+		return d <= p
+	}
+}
@@ -0,0 +1,111 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package duration_test
+
+import (
+	libval "github.com/go-playground/validator/v10"
+	libdur "github.com/nabbar/golib/duration"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("duration validator", func() {
+	var val = libval.New()
+
+	BeforeEach(func() {
+		Expect(libdur.RegisterValidation(val)).ToNot(HaveOccurred())
+	})
+
+	DescribeTable("validated struct",
+		func(obj interface{}, tag string) {
+			e := val.Struct(obj)
+
+			if len(tag) < 1 {
+				Expect(e).ToNot(HaveOccurred())
+				return
+			}
+
+			Expect(e).To(HaveOccurred())
+
+			l, k := e.(libval.ValidationErrors)
+			Expect(k).To(BeTrue())
+			Expect(l).To(HaveLen(1))
+			Expect(l[0].Tag()).To(Equal(tag))
+		},
+		Entry("valid string", struct {
+			V string `validate:"duration"`
+		}{"1w2d"}, ""),
+		Entry("empty string", struct {
+			V string `validate:"duration"`
+		}{""}, ""),
+		Entry("malformed string", struct {
+			V string `validate:"duration"`
+		}{"1x"}, libdur.ValidatorTag),
+		Entry("unsupported kind", struct {
+			V bool `validate:"duration"`
+		}{true}, libdur.ValidatorTag),
+		Entry("duration above min", struct {
+			V libdur.Duration `validate:"duration_min=1h"`
+		}{libdur.Hours(2)}, ""),
+		Entry("duration equal to min", struct {
+			V libdur.Duration `validate:"duration_min=1h"`
+		}{libdur.Hours(1)}, ""),
+		Entry("duration below min", struct {
+			V libdur.Duration `validate:"duration_min=1h"`
+		}{libdur.Minutes(30)}, libdur.ValidatorTagMin),
+		Entry("string below min", struct {
+			V string `validate:"duration_min=1d"`
+		}{"23h"}, libdur.ValidatorTagMin),
+		Entry("malformed string with min", struct {
+			V string `validate:"duration_min=1d"`
+		}{"1x"}, libdur.ValidatorTagMin),
+		Entry("malformed min", struct {
+			V libdur.Duration `validate:"duration_min=abc"`
+		}{libdur.Hours(1)}, libdur.ValidatorTagMin),
+		Entry("string below max", struct {
+			V string `validate:"duration_max=2w"`
+		}{"1w"}, ""),
+		Entry("string equal to max", struct {
+			V string `validate:"duration_max=2w"`
+		}{"14d"}, ""),
+		Entry("string above max", struct {
+			V string `validate:"duration_max=2w"`
+		}{"3w"}, libdur.ValidatorTagMax),
+		Entry("duration above max", struct {
+			V libdur.Duration `validate:"duration_max=2w"`
+		}{libdur.Weeks(2) + 1}, libdur.ValidatorTagMax),
+		Entry("malformed max", struct {
+			V libdur.Duration `validate:"duration_max=2y"`
+		}{libdur.Weeks(1)}, libdur.ValidatorTagMax),
+		Entry("duration into the range", struct {
+			V libdur.Duration `validate:"duration_min=1s,duration_max=1m"`
+		}{libdur.Seconds(30)}, ""),
+		Entry("negative duration below the range", struct {
+			V libdur.Duration `validate:"duration_min=1s,duration_max=1m"`
+		}{libdur.Seconds(-30)}, libdur.ValidatorTagMin),
+	)
+})
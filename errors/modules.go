@@ -49,9 +49,13 @@ const (
 	MinPkgFTPClient        = baseInc + MinPkgFileProgress
 	MinPkgHttpCli          = baseInc + MinPkgFTPClient
 	MinPkgHttpCliDNSMapper = baseSub + MinPkgHttpCli
+	MinPkgHttpCliPolicy    = baseSub + MinPkgHttpCliDNSMapper
+	MinPkgHttpCliCache     = baseSub + MinPkgHttpCliPolicy
 
 	MinPkgHttpServer     = baseInc + MinPkgHttpCliDNSMapper
 	MinPkgHttpServerPool = baseSub + MinPkgHttpServer
+	MinPkgGrpcServer     = baseSub + MinPkgHttpServerPool
+	MinPkgGrpcServerPool = baseSub + MinPkgGrpcServer
 
 	MinPkgIOUtils    = baseInc + MinPkgHttpServer
 	MinPkgLDAP       = baseInc + MinPkgIOUtils
@@ -60,11 +64,18 @@ const (
 	MinPkgMailer     = baseInc + MinPkgMail
 	MinPkgMailPooler = baseInc + MinPkgMailer
 
-	MinPkgMonitor     = baseInc + MinPkgMailPooler
-	MinPkgMonitorCfg  = baseSub + MinPkgMonitor
-	MinPkgMonitorPool = baseSub + MinPkgMonitorCfg
+	MinPkgMonitor       = baseInc + MinPkgMailPooler
+	MinPkgMonitorCfg    = baseSub + MinPkgMonitor
+	MinPkgMonitorPool   = baseSub + MinPkgMonitorCfg
+	MinPkgMonitorGroup  = baseSub + MinPkgMonitorPool
+	MinPkgMonitorWatch  = baseSub + MinPkgMonitorGroup
+	MinPkgMonitorProbe  = baseSub + MinPkgMonitorWatch
+	MinPkgMonitorReport = baseSub + MinPkgMonitorProbe
+	MinPkgMonitorCheck  = baseSub + MinPkgMonitorReport
+
+	MinPkgNetwork         = baseInc + MinPkgMonitor
+	MinPkgNetworkResolver = baseSub + MinPkgNetwork
 
-	MinPkgNetwork   = baseInc + MinPkgMonitor
 	MinPkgNats      = baseInc + MinPkgNetwork
 	MinPkgNutsDB    = baseInc + MinPkgNats
 	MinPkgOAuth     = baseInc + MinPkgNutsDB
@@ -82,5 +93,20 @@ const (
 	MinPkgVersion = baseInc + MinPkgSocket
 	MinPkgViper   = baseInc + MinPkgVersion
 
-	MinAvailable = baseInc + MinPkgViper
+	MinPkgQueue       = baseInc + MinPkgViper
+	MinPkgQueueNats   = baseSub + MinPkgQueue
+	MinPkgQueueMemory = baseSub + MinPkgQueueNats
+	MinPkgQueueKafka  = baseSub + MinPkgQueueMemory
+
+	MinPkgScheduler     = baseInc + MinPkgQueue
+	MinPkgSchedulerCron = baseSub + MinPkgScheduler
+
+	MinPkgLeader     = baseInc + MinPkgScheduler
+	MinPkgLeaderFile = baseSub + MinPkgLeader
+
+	MinPkgKVStore = baseInc + MinPkgLeader
+
+	MinPkgShellConsole = baseInc + MinPkgKVStore
+
+	MinAvailable = baseInc + MinPkgShellConsole
 )
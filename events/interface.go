@@ -0,0 +1,105 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package events
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// TopicAll is the topic pattern matching all topics.
+const TopicAll = "*"
+
+// FuncHandler is the function called for each event received by a subscription.
+type FuncHandler func(ctx context.Context, topic string, evt any)
+
+// FuncError is called with the topic and the error when a handler panic or when an event cannot be delivered.
+type FuncError func(topic string, err error)
+
+type Subscription interface {
+	// Pattern return the topic pattern of the subscription.
+	Pattern() string
+
+	// Unsubscribe remove the subscription from the bus and stop its worker.
+	// Events still in the buffer are dropped. Use Done to wait the end of the worker.
+	Unsubscribe()
+
+	// Done return a channel closed when the subscription is stopped.
+	Done() <-chan struct{}
+
+	// Received return the number of events delivered to the handler.
+	Received() uint64
+}
+
+type Bus interface {
+	// Publish send the event to all subscriptions matching the topic.
+	// For unbuffered subscriptions or when a buffer is full, Publish blocks until
+	// the event is queued, the subscription is stopped or the given context is done.
+	Publish(ctx context.Context, topic string, evt any) error
+
+	// TryPublish send the event to all subscriptions matching the topic without blocking.
+	// The event is dropped for each subscription not ready to queue it (unbuffered or full buffer):
+	// each drop is reported to the error function and ErrEventDropped is returned.
+	TryPublish(topic string, evt any) error
+
+	// Subscribe register a handler for all topics matching the pattern.
+	// A pattern is either an exact topic, TopicAll or a prefix followed by ".*" (ex: "socket.*").
+	// The size define the buffer of the subscription: zero means unbuffered.
+	// The subscription is stopped when the given context is done.
+	Subscribe(ctx context.Context, pattern string, size int, fct FuncHandler) (Subscription, error)
+
+	// RegisterFuncError register a function used to report handler panic and delivery errors.
+	RegisterFuncError(fct FuncError)
+
+	// Len return the number of active subscriptions.
+	Len() int
+
+	// Close stop all subscriptions and refuse any new subscription or event.
+	Close() error
+
+	// IsClosed return true if the bus has been closed.
+	IsClosed() bool
+}
+
+// New return a new empty bus. All subscriptions are stopped when the given context is done.
+func New(ctx context.Context) Bus {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	x, n := context.WithCancel(ctx)
+
+	return &bus{
+		m: sync.RWMutex{},
+		x: x,
+		n: n,
+		s: make(map[uint64]*sub),
+		i: new(atomic.Uint64),
+		f: new(atomic.Value),
+	}
+}
@@ -0,0 +1,253 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package events
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+type bus struct {
+	m sync.RWMutex
+	x context.Context    // bus context
+	n context.CancelFunc // bus cancel
+	s map[uint64]*sub    // subscriptions
+	i *atomic.Uint64     // last subscription id
+	f *atomic.Value      // function error
+}
+
+func (o *bus) Publish(ctx context.Context, topic string, evt any) error {
+	if o == nil {
+		return ErrInvalidInstance
+	} else if o.IsClosed() {
+		return ErrBusClosed
+	} else if ctx == nil {
+		ctx = context.Background()
+	}
+
+	for _, s := range o.match(topic) {
+		select {
+		case s.c <- item{t: topic, e: evt}:
+		case <-s.x.Done():
+			// subscription stopped meanwhile
+		case <-o.x.Done():
+			return ErrBusClosed
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+func (o *bus) TryPublish(topic string, evt any) error {
+	if o == nil {
+		return ErrInvalidInstance
+	} else if o.IsClosed() {
+		return ErrBusClosed
+	}
+
+	var err error
+
+	for _, s := range o.match(topic) {
+		select {
+		case s.c <- item{t: topic, e: evt}:
+		case <-s.x.Done():
+			// subscription stopped meanwhile
+		default:
+			err = ErrEventDropped
+			o.fctError(topic, ErrEventDropped)
+		}
+	}
+
+	return err
+}
+
+func (o *bus) Subscribe(ctx context.Context, pattern string, size int, fct FuncHandler) (Subscription, error) {
+	if o == nil {
+		return nil, ErrInvalidInstance
+	} else if fct == nil {
+		return nil, ErrInvalidHandler
+	} else if !validPattern(pattern) {
+		return nil, ErrInvalidPattern
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if size < 0 {
+		size = 0
+	}
+
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if o.x.Err() != nil {
+		return nil, ErrBusClosed
+	}
+
+	x, n := context.WithCancel(ctx)
+
+	s := &sub{
+		i: o.i.Add(1),
+		p: pattern,
+		b: o,
+		f: fct,
+		c: make(chan item, size),
+		d: make(chan struct{}),
+		x: x,
+		n: n,
+		r: new(atomic.Uint64),
+	}
+
+	o.s[s.i] = s
+	go s.run()
+
+	return s, nil
+}
+
+func (o *bus) RegisterFuncError(fct FuncError) {
+	if o == nil {
+		return
+	}
+
+	o.f.Store(fct)
+}
+
+func (o *bus) Len() int {
+	if o == nil {
+		return 0
+	}
+
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	return len(o.s)
+}
+
+func (o *bus) Close() error {
+	if o == nil {
+		return ErrInvalidInstance
+	}
+
+	o.n()
+
+	o.m.RLock()
+	var l = make([]*sub, 0, len(o.s))
+	for _, s := range o.s {
+		l = append(l, s)
+	}
+	o.m.RUnlock()
+
+	for _, s := range l {
+		s.Unsubscribe()
+	}
+
+	return nil
+}
+
+func (o *bus) IsClosed() bool {
+	if o == nil {
+		return true
+	}
+
+	return o.x.Err() != nil
+}
+
+func (o *bus) del(id uint64) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	delete(o.s, id)
+}
+
+// match return the subscriptions matching the topic, ordered by subscription.
+func (o *bus) match(topic string) []*sub {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	var res = make([]*sub, 0, len(o.s))
+
+	for _, s := range o.s {
+		if matchPattern(s.p, topic) {
+			res = append(res, s)
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].i < res[j].i
+	})
+
+	return res
+}
+
+func (o *bus) fctError(topic string, err error) {
+	if o == nil || err == nil {
+		return
+	}
+
+	if i := o.f.Load(); i != nil {
+		if f, k := i.(FuncError); k && f != nil {
+			f(topic, err)
+		}
+	}
+}
+
+func (o *bus) recovery(topic string, rec any) {
+	if rec == nil {
+		return
+	}
+
+	o.fctError(topic, fmt.Errorf("%w: %v", ErrHandlerPanic, rec))
+}
+
+func validPattern(pattern string) bool {
+	if len(pattern) < 1 {
+		return false
+	} else if pattern == TopicAll {
+		return true
+	} else if strings.Contains(strings.TrimSuffix(pattern, ".*"), "*") {
+		return false
+	}
+
+	return pattern != ".*"
+}
+
+func matchPattern(pattern, topic string) bool {
+	if pattern == TopicAll || pattern == topic {
+		return true
+	} else if p, ok := strings.CutSuffix(pattern, ".*"); ok {
+		return strings.HasPrefix(topic, p+".")
+	}
+
+	return false
+}
@@ -0,0 +1,93 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package events
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type item struct {
+	t string // topic
+	e any    // event
+}
+
+type sub struct {
+	i uint64             // id
+	p string             // pattern
+	b *bus               // parent bus
+	f FuncHandler        // handler
+	c chan item          // queue
+	d chan struct{}      // done
+	x context.Context    // subscription context
+	n context.CancelFunc // subscription cancel
+	r *atomic.Uint64     // received counter
+}
+
+func (o *sub) Pattern() string {
+	return o.p
+}
+
+func (o *sub) Unsubscribe() {
+	o.n()
+}
+
+func (o *sub) Done() <-chan struct{} {
+	return o.d
+}
+
+func (o *sub) Received() uint64 {
+	return o.r.Load()
+}
+
+func (o *sub) run() {
+	defer func() {
+		o.n()
+		o.b.del(o.i)
+		close(o.d)
+	}()
+
+	for {
+		select {
+		case <-o.x.Done():
+			return
+		case <-o.b.x.Done():
+			return
+		case i := <-o.c:
+			o.call(i)
+		}
+	}
+}
+
+func (o *sub) call(i item) {
+	defer func() {
+		o.b.recovery(i.t, recover())
+	}()
+
+	o.r.Add(1)
+	o.f(o.x, i.t, i.e)
+}
@@ -0,0 +1,102 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package events
+
+import (
+	"context"
+)
+
+// FuncTyped is the function called for each event of type T received by a typed subscription.
+type FuncTyped[T any] func(ctx context.Context, topic string, evt T)
+
+// Topic is a typed view of a bus topic.
+type Topic[T any] interface {
+	// Name return the topic name.
+	Name() string
+
+	// Publish send the event on the topic.
+	Publish(ctx context.Context, evt T) error
+
+	// TryPublish send the event on the topic without blocking, see Bus.TryPublish.
+	TryPublish(evt T) error
+
+	// Subscribe register a typed handler on the topic.
+	Subscribe(ctx context.Context, size int, fct FuncTyped[T]) (Subscription, error)
+}
+
+// NewTopic return a typed view of the given topic on the bus.
+func NewTopic[T any](b Bus, name string) Topic[T] {
+	return &topic[T]{
+		b: b,
+		n: name,
+	}
+}
+
+// Subscribe register a typed handler for all topics matching the pattern.
+// Events with another type than T are ignored, allowing a typed subscription on a pattern shared by several types.
+func Subscribe[T any](ctx context.Context, b Bus, pattern string, size int, fct FuncTyped[T]) (Subscription, error) {
+	if b == nil {
+		return nil, ErrInvalidInstance
+	} else if fct == nil {
+		return nil, ErrInvalidHandler
+	}
+
+	return b.Subscribe(ctx, pattern, size, func(ctx context.Context, topic string, evt any) {
+		if v, ok := evt.(T); ok {
+			fct(ctx, topic, v)
+		}
+	})
+}
+
+type topic[T any] struct {
+	b Bus
+	n string
+}
+
+func (o *topic[T]) Name() string {
+	return o.n
+}
+
+func (o *topic[T]) Publish(ctx context.Context, evt T) error {
+	if o.b == nil {
+		return ErrInvalidInstance
+	}
+
+	return o.b.Publish(ctx, o.n, evt)
+}
+
+func (o *topic[T]) TryPublish(evt T) error {
+	if o.b == nil {
+		return ErrInvalidInstance
+	}
+
+	return o.b.TryPublish(o.n, evt)
+}
+
+func (o *topic[T]) Subscribe(ctx context.Context, size int, fct FuncTyped[T]) (Subscription, error) {
+	return Subscribe[T](ctx, o.b, o.n, size, fct)
+}
@@ -0,0 +1,341 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package grpcserver
+
+import (
+	"fmt"
+	"net"
+
+	libval "github.com/go-playground/validator/v10"
+	libtls "github.com/nabbar/golib/certificates"
+	libctx "github.com/nabbar/golib/context"
+	libdur "github.com/nabbar/golib/duration"
+	liblog "github.com/nabbar/golib/logger"
+	logcfg "github.com/nabbar/golib/logger/config"
+	moncfg "github.com/nabbar/golib/monitor/types"
+	libsiz "github.com/nabbar/golib/size"
+)
+
+const (
+	cfgConfig       = "cfgConfig"
+	cfgName         = "cfgName"
+	cfgListen       = "cfgListen"
+	cfgExpose       = "cfgExpose"
+	cfgDisabled     = "cfgDisabled"
+	cfgTLS          = "cfgTLS"
+	cfgTLSMandatory = "cfgTLSMandatory"
+)
+
+// nolint #maligned
+type Config struct {
+
+	// Name is the name of the current srv
+	// the configuration allow multiple srv, which each one must be identify by a name
+	Name string `mapstructure:"name" json:"name" yaml:"name" toml:"name" validate:"required"`
+
+	// Listen is the local address (ip, hostname, ...) with a port
+	// The srv will bind with this address only and listen for the port defined
+	Listen string `mapstructure:"listen" json:"listen" yaml:"listen" toml:"listen" validate:"required,hostname_port"`
+
+	// Expose is the address use to call this srv. If not defined, the listen address is used.
+	Expose string `mapstructure:"expose" json:"expose" yaml:"expose" toml:"expose" validate:"omitempty,hostname_port"`
+
+	//private
+	getTLSDefault libtls.FctTLSDefault
+
+	//private
+	getParentContext libctx.FuncContext
+
+	//private
+	getRegisterFunc []FuncRegister
+
+	// Enabled allow to disable a srv without clean his configuration
+	Disabled bool `mapstructure:"disabled" json:"disabled" yaml:"disabled" toml:"disabled"`
+
+	// Monitor defined the monitoring options to monitor the status & metrics about the health of this srv
+	Monitor moncfg.Config `mapstructure:"monitor" json:"monitor" yaml:"monitor" toml:"monitor"`
+
+	// TLSMandatory is a flag to defined that TLS must be valid to start current srv.
+	TLSMandatory bool `mapstructure:"tls_mandatory" json:"tls_mandatory" yaml:"tls_mandatory" toml:"tls_mandatory"`
+
+	// TLS is the tls configuration for this srv.
+	// To allow tls on this srv, at least the TLS Config option InheritDefault must be at true and the default TLS config must be set.
+	// If you don't want any tls config, just omit or set an empty struct.
+	TLS libtls.Config `mapstructure:"tls" json:"tls" yaml:"tls" toml:"tls"`
+
+	/*** grpc options ***/
+
+	// MaxRecvMsgSize is the max message size the srv can receive. If zero, the grpc default (4MB) is used.
+	MaxRecvMsgSize libsiz.Size `mapstructure:"max_recv_msg_size" json:"max_recv_msg_size" yaml:"max_recv_msg_size" toml:"max_recv_msg_size"`
+
+	// MaxSendMsgSize is the max message size the srv can send. If zero, the grpc default is used.
+	MaxSendMsgSize libsiz.Size `mapstructure:"max_send_msg_size" json:"max_send_msg_size" yaml:"max_send_msg_size" toml:"max_send_msg_size"`
+
+	// MaxConcurrentStreams limits the number of concurrent streams to each client. If zero, no limit.
+	MaxConcurrentStreams uint32 `mapstructure:"max_concurrent_streams" json:"max_concurrent_streams" yaml:"max_concurrent_streams" toml:"max_concurrent_streams"`
+
+	// ConnectionTimeout is the timeout for connection establishment (up to and including HTTP/2 handshaking).
+	ConnectionTimeout libdur.Duration `mapstructure:"connection_timeout" json:"connection_timeout" yaml:"connection_timeout" toml:"connection_timeout"`
+
+	// KeepAliveTime is the duration after which, if the srv doesn't see any activity, it pings the client.
+	KeepAliveTime libdur.Duration `mapstructure:"keep_alive_time" json:"keep_alive_time" yaml:"keep_alive_time" toml:"keep_alive_time"`
+
+	// KeepAliveTimeout is the duration the srv waits for the ping ack before closing the connection.
+	KeepAliveTimeout libdur.Duration `mapstructure:"keep_alive_timeout" json:"keep_alive_timeout" yaml:"keep_alive_timeout" toml:"keep_alive_timeout"`
+
+	// GracefulTimeout is the max duration given to the running rpc to end when stopping the srv.
+	// After this delay, all connections are closed. Default is 5 seconds.
+	GracefulTimeout libdur.Duration `mapstructure:"graceful_timeout" json:"graceful_timeout" yaml:"graceful_timeout" toml:"graceful_timeout"`
+
+	// DisableHealth disable the built-in grpc_health_v1 service.
+	DisableHealth bool `mapstructure:"disable_health" json:"disable_health" yaml:"disable_health" toml:"disable_health"`
+
+	// EnableReflection enable the grpc server reflection service.
+	EnableReflection bool `mapstructure:"enable_reflection" json:"enable_reflection" yaml:"enable_reflection" toml:"enable_reflection"`
+
+	// Logger is used to define the logger options.
+	Logger logcfg.Options `mapstructure:"logger" json:"logger" yaml:"logger" toml:"logger"`
+}
+
+func (c *Config) Clone() Config {
+	var reg = make([]FuncRegister, len(c.getRegisterFunc))
+	copy(reg, c.getRegisterFunc)
+
+	return Config{
+		Name:                 c.Name,
+		Listen:               c.Listen,
+		Expose:               c.Expose,
+		getTLSDefault:        c.getTLSDefault,
+		getParentContext:     c.getParentContext,
+		getRegisterFunc:      reg,
+		Disabled:             c.Disabled,
+		Monitor:              c.Monitor.Clone(),
+		TLSMandatory:         c.TLSMandatory,
+		TLS:                  c.TLS,
+		MaxRecvMsgSize:       c.MaxRecvMsgSize,
+		MaxSendMsgSize:       c.MaxSendMsgSize,
+		MaxConcurrentStreams: c.MaxConcurrentStreams,
+		ConnectionTimeout:    c.ConnectionTimeout,
+		KeepAliveTime:        c.KeepAliveTime,
+		KeepAliveTimeout:     c.KeepAliveTimeout,
+		GracefulTimeout:      c.GracefulTimeout,
+		DisableHealth:        c.DisableHealth,
+		EnableReflection:     c.EnableReflection,
+		Logger:               c.Logger.Clone(),
+	}
+}
+
+// RegisterService add a function used to register the grpc services on the srv at each start.
+func (c *Config) RegisterService(fct ...FuncRegister) {
+	for _, f := range fct {
+		if f != nil {
+			c.getRegisterFunc = append(c.getRegisterFunc, f)
+		}
+	}
+}
+
+func (c *Config) SetDefaultTLS(f libtls.FctTLSDefault) {
+	c.getTLSDefault = f
+}
+
+func (c *Config) SetContext(f libctx.FuncContext) {
+	c.getParentContext = f
+}
+
+func (c *Config) GetTLS() (libtls.TLSConfig, error) {
+	var def libtls.TLSConfig
+
+	if c.TLS.InheritDefault && c.getTLSDefault != nil {
+		def = c.getTLSDefault()
+	}
+
+	if cfg := c.TLS.NewFrom(def); cfg != nil {
+		return cfg, nil
+	}
+
+	return nil, fmt.Errorf("no tls configuration found")
+}
+
+func (c *Config) CheckTLS() (libtls.TLSConfig, error) {
+	if ssl, err := c.GetTLS(); err != nil {
+		return nil, err
+	} else if ssl == nil || ssl.LenCertificatePair() < 1 {
+		return nil, ErrorServerValidate.Error(fmt.Errorf("not certificates defined"))
+	} else {
+		return ssl, nil
+	}
+}
+
+func (c *Config) IsTLS() bool {
+	if _, err := c.CheckTLS(); err == nil {
+		return true
+	}
+
+	return false
+}
+
+func (c *Config) GetListen() string {
+	if h, p, e := net.SplitHostPort(c.Listen); e == nil {
+		return net.JoinHostPort(h, p)
+	}
+
+	return c.Listen
+}
+
+func (c *Config) GetExpose() string {
+	if len(c.Expose) > 0 {
+		return c.Expose
+	}
+
+	return c.GetListen()
+}
+
+func (c *Config) Validate() error {
+	err := ErrorServerValidate.Error(nil)
+
+	if er := libval.New().Struct(c); er != nil {
+		if e, ok := er.(*libval.InvalidValidationError); ok {
+			err.Add(e)
+		}
+
+		for _, e := range er.(libval.ValidationErrors) {
+			//nolint goerr113
+			err.Add(fmt.Errorf("config field '%s' is not validated by constraint '%s'", e.Namespace(), e.ActualTag()))
+		}
+	}
+
+	if err.HasParent() {
+		return err
+	}
+
+	return nil
+}
+
+func (c *Config) Server(defLog liblog.FuncLog) (Server, error) {
+	return New(*c, defLog)
+}
+
+func (o *srv) GetConfig() *Config {
+	if i, l := o.c.Load(cfgConfig); !l {
+		return nil
+	} else if v, k := i.(Config); !k {
+		return nil
+	} else {
+		return &v
+	}
+}
+
+func (o *srv) SetConfig(cfg Config, defLog liblog.FuncLog) error {
+	if e := o.cfgSetTLS(&cfg); e != nil {
+		return e
+	} else if e = o.setLogger(defLog, cfg.Logger); e != nil {
+		return e
+	}
+
+	o.c.Store(cfgName, cfg.Name)
+	o.c.Store(cfgListen, cfg.GetListen())
+	o.c.Store(cfgExpose, cfg.GetExpose())
+	o.c.Store(cfgDisabled, cfg.Disabled)
+	o.c.Store(cfgConfig, cfg)
+
+	return nil
+}
+
+func (o *srv) setLogger(def liblog.FuncLog, opt logcfg.Options) error {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	var l liblog.Logger
+
+	if def != nil {
+		if n := def(); n != nil {
+			l = n
+		}
+	}
+
+	if l == nil {
+		l = liblog.New(o.c.GetContext)
+	}
+
+	o.l = func() liblog.Logger {
+		return l
+	}
+
+	return l.SetOptions(&opt)
+}
+
+func (o *srv) logger() liblog.Logger {
+	o.m.RLock()
+	var f = o.l
+	o.m.RUnlock()
+
+	var log liblog.Logger
+
+	if f != nil {
+		log = f()
+	}
+
+	if log == nil {
+		log = liblog.New(o.c.GetContext)
+	}
+
+	log.SetFields(log.GetFields().Add("bind", o.GetBindable()))
+	return log
+}
+
+func (o *srv) cfgSetTLS(cfg *Config) error {
+	o.c.Store(cfgTLSMandatory, cfg.TLSMandatory)
+	if t, e := cfg.CheckTLS(); e != nil && cfg.TLSMandatory {
+		return e
+	} else if e != nil {
+		o.c.Delete(cfgTLS)
+		return nil
+	} else {
+		o.c.Store(cfgTLS, t)
+		return nil
+	}
+}
+
+func (o *srv) cfgGetTLS() libtls.TLSConfig {
+	if i, l := o.c.Load(cfgTLS); !l {
+		return nil
+	} else if v, k := i.(libtls.TLSConfig); !k {
+		return nil
+	} else {
+		return v
+	}
+}
+
+func (o *srv) cfgTLSMandatory() bool {
+	if i, l := o.c.Load(cfgTLSMandatory); !l {
+		return false
+	} else if v, k := i.(bool); !k {
+		return false
+	} else {
+		return v
+	}
+}
@@ -0,0 +1,109 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package grpcserver
+
+import (
+	"sync"
+
+	libctx "github.com/nabbar/golib/context"
+	liblog "github.com/nabbar/golib/logger"
+	montps "github.com/nabbar/golib/monitor/types"
+	libsrv "github.com/nabbar/golib/server"
+	libver "github.com/nabbar/golib/version"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+)
+
+// FuncRegister is called with the new grpc server at each start to register the grpc services.
+type FuncRegister func(s grpc.ServiceRegistrar)
+
+type Info interface {
+	// GetName return the name of the current srv
+	GetName() string
+
+	// GetBindable return the local address (ip, hostname, ...) with a port where the srv listen
+	GetBindable() string
+
+	// GetExpose return the address use to call this srv
+	GetExpose() string
+
+	// IsDisable allow to know if the current srv is disabled
+	IsDisable() bool
+
+	// IsTLS return true if the srv use a TLS credential
+	IsTLS() bool
+}
+
+type Server interface {
+	libsrv.Server
+	Info
+
+	// RegisterService add a function used to register the grpc services at each start.
+	// A restart is needed to apply new registered services on a running srv.
+	RegisterService(fct ...FuncRegister)
+
+	// SetServingStatus update the status of the given service into the built-in health service.
+	// An empty service name is the status of the whole srv.
+	// The status is reset to serving at each start and changes are ignored while the srv is stopped.
+	SetServingStatus(service string, serving bool)
+
+	// GetConfig return the current config of the srv
+	GetConfig() *Config
+
+	// SetConfig allow to update the config of the srv. A restart is needed to apply the new config.
+	SetConfig(cfg Config, defLog liblog.FuncLog) error
+
+	// Merge update the config of the srv with the config of the given srv.
+	Merge(s Server, def liblog.FuncLog) error
+
+	// Monitor return a new monitor instance for the srv.
+	Monitor(vrs libver.Version) (montps.Monitor, error)
+
+	// MonitorName return the name of the monitor of the srv.
+	MonitorName() string
+}
+
+// New return a new grpc srv based on the given config.
+// The health service (grpc_health_v1) and the reflection service are registered
+// at each start following the config.
+func New(cfg Config, defLog liblog.FuncLog) (Server, error) {
+	s := &srv{
+		m: sync.RWMutex{},
+		r: nil,
+		c: libctx.NewConfig[string](cfg.getParentContext),
+		h: health.NewServer(),
+		f: make([]FuncRegister, 0),
+	}
+
+	s.RegisterService(cfg.getRegisterFunc...)
+
+	if e := s.SetConfig(cfg, defLog); e != nil {
+		return nil, e
+	}
+
+	return s, nil
+}
@@ -0,0 +1,72 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package grpcserver
+
+import (
+	"sync"
+
+	libctx "github.com/nabbar/golib/context"
+	liblog "github.com/nabbar/golib/logger"
+	librun "github.com/nabbar/golib/server/runner/startStop"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+)
+
+type srv struct {
+	m sync.RWMutex
+	l liblog.FuncLog
+	c libctx.Config[string]
+	r librun.StartStop
+	s *grpc.Server
+	h *health.Server
+	f []FuncRegister
+}
+
+func (o *srv) Merge(s Server, def liblog.FuncLog) error {
+	return o.SetConfig(*s.GetConfig(), def)
+}
+
+func (o *srv) RegisterService(fct ...FuncRegister) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	for _, f := range fct {
+		if f != nil {
+			o.f = append(o.f, f)
+		}
+	}
+}
+
+func (o *srv) getRegister() []FuncRegister {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	var res = make([]FuncRegister, len(o.f))
+	copy(res, o.f)
+
+	return res
+}
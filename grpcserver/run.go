@@ -0,0 +1,259 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	loglvl "github.com/nabbar/golib/logger/level"
+	libptc "github.com/nabbar/golib/network/protocol"
+	librun "github.com/nabbar/golib/server/runner/startStop"
+	"google.golang.org/grpc"
+)
+
+func (o *srv) newRun(ctx context.Context) error {
+	if o == nil {
+		return ErrorServerValidate.Error(nil)
+	}
+
+	o.m.RLock()
+	r := o.r
+	o.m.RUnlock()
+
+	if r != nil {
+		if e := r.Stop(ctx); e != nil {
+			return e
+		}
+	}
+
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.r = librun.New(o.runFuncStart, o.runFuncStop)
+	return nil
+}
+
+func (o *srv) runStart(ctx context.Context) error {
+	if o == nil {
+		return ErrorServerValidate.Error(nil)
+	}
+
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	if o.r == nil {
+		return ErrorServerValidate.Error(nil)
+	}
+
+	if e := o.r.Start(ctx); e != nil {
+		return e
+	}
+
+	var x, n = context.WithTimeout(ctx, 30*time.Second)
+
+	defer n()
+
+	for !o.r.IsRunning() {
+		select {
+		case <-x.Done():
+			return errNotRunning
+		default:
+			time.Sleep(100 * time.Millisecond)
+			if o.r.IsRunning() {
+				return o.GetError()
+			}
+		}
+	}
+
+	return o.GetError()
+}
+
+func (o *srv) runFuncStart(ctx context.Context) (err error) {
+	var (
+		tls = o.IsTLS()
+		ser *grpc.Server
+		lis net.Listener
+	)
+
+	defer func() {
+		o.h.Shutdown()
+
+		if tls {
+			ent := o.logger().Entry(loglvl.InfoLevel, "TLS gRPC Server stopped")
+			ent.ErrorAdd(true, err)
+			ent.Log()
+		} else {
+			ent := o.logger().Entry(loglvl.InfoLevel, "gRPC Server stopped")
+			ent.ErrorAdd(true, err)
+			ent.Log()
+		}
+	}()
+
+	if ser = o.getServer(); ser == nil {
+		if err = o.setServer(ctx); err != nil {
+			ent := o.logger().Entry(loglvl.ErrorLevel, "starting grpc server")
+			ent.ErrorAdd(true, err)
+			ent.Log()
+			return err
+		} else if ser = o.getServer(); ser == nil {
+			err = ErrorServerStart.Error(fmt.Errorf("cannot create new server, cannot retrieve server"))
+			ent := o.logger().Entry(loglvl.ErrorLevel, "starting grpc server")
+			ent.ErrorAdd(true, err)
+			ent.Log()
+			return err
+		}
+	}
+
+	if lis, err = net.Listen(libptc.NetworkTCP.Code(), o.GetBindable()); err != nil {
+		ent := o.logger().Entry(loglvl.ErrorLevel, "starting grpc server")
+		ent.ErrorAdd(true, err)
+		ent.Log()
+		return err
+	}
+
+	o.h.Resume()
+
+	if tls {
+		o.logger().Entry(loglvl.InfoLevel, "TLS gRPC Server is starting").Log()
+	} else {
+		o.logger().Entry(loglvl.InfoLevel, "gRPC Server is starting").Log()
+	}
+
+	err = ser.Serve(lis)
+
+	if errors.Is(err, grpc.ErrServerStopped) {
+		err = nil
+	}
+
+	return err
+}
+
+func (o *srv) runFuncStop(ctx context.Context) (err error) {
+	var (
+		tls = o.IsTLS()
+		ser *grpc.Server
+		tmo = DefaultGracefulTimeout
+	)
+
+	if cfg := o.GetConfig(); cfg != nil && cfg.GracefulTimeout > 0 {
+		tmo = cfg.GracefulTimeout.Time()
+	}
+
+	var x, n = context.WithTimeout(ctx, tmo)
+	defer n()
+
+	defer func() {
+		o.delServer()
+		if tls {
+			ent := o.logger().Entry(loglvl.InfoLevel, "Shutdown of TLS gRPC Server has been called")
+			ent.ErrorAdd(true, err)
+			ent.Log()
+		} else {
+			ent := o.logger().Entry(loglvl.InfoLevel, "Shutdown of gRPC Server has been called")
+			ent.ErrorAdd(true, err)
+			ent.Log()
+		}
+	}()
+
+	if ser = o.getServer(); ser == nil {
+		err = ErrorServerStart.Error(fmt.Errorf("cannot retrieve server"))
+		ent := o.logger().Entry(loglvl.ErrorLevel, "stopping grpc server")
+		ent.ErrorAdd(true, err)
+		ent.Log()
+		return err
+	}
+
+	// the health service must report NOT_SERVING while the running rpc are draining
+	o.h.Shutdown()
+
+	if tls {
+		o.logger().Entry(loglvl.InfoLevel, "Calling TLS gRPC Server graceful stop").Log()
+	} else {
+		o.logger().Entry(loglvl.InfoLevel, "Calling gRPC Server graceful stop").Log()
+	}
+
+	var d = make(chan struct{})
+
+	go func() {
+		ser.GracefulStop()
+		close(d)
+	}()
+
+	select {
+	case <-d:
+		return nil
+	case <-x.Done():
+		ser.Stop()
+		return x.Err()
+	}
+}
+
+func (o *srv) Uptime() time.Duration {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	if o.r != nil {
+		return o.r.Uptime()
+	}
+
+	return 0
+}
+
+func (o *srv) IsError() bool {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	if o.r == nil {
+		return false
+	}
+
+	for _, e := range o.r.ErrorsList() {
+		if e != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (o *srv) GetError() error {
+	if o.r == nil {
+		return nil
+	}
+
+	var err = ErrorServerStart.Error(o.r.ErrorsList()...)
+
+	if err.HasParent() {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,268 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	loglvl "github.com/nabbar/golib/logger/level"
+	libptc "github.com/nabbar/golib/network/protocol"
+	libsrv "github.com/nabbar/golib/server"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/reflection"
+)
+
+const (
+	// DefaultGracefulTimeout is the max duration given to running rpc to end when stopping the srv.
+	DefaultGracefulTimeout = 5 * time.Second
+
+	// TimeoutWaitingPortFreeing is the timeout used to check if the listen port is still in use.
+	TimeoutWaitingPortFreeing = 250 * time.Microsecond
+)
+
+var errInvalid = errors.New("invalid instance")
+
+func (o *srv) getServer() *grpc.Server {
+	if o == nil {
+		return nil
+	}
+
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	return o.s
+}
+
+func (o *srv) delServer() {
+	if o == nil {
+		return
+	}
+
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.s = nil
+}
+
+func (o *srv) serverOptions(cfg *Config) ([]grpc.ServerOption, error) {
+	var (
+		ssl = o.cfgGetTLS()
+		opt = make([]grpc.ServerOption, 0)
+	)
+
+	if o.cfgTLSMandatory() && (ssl == nil || ssl.LenCertificatePair() < 1) {
+		return nil, ErrorServerValidate.Error(fmt.Errorf("TLS Config is not well defined"))
+	} else if ssl != nil && ssl.LenCertificatePair() > 0 {
+		opt = append(opt, grpc.Creds(credentials.NewTLS(ssl.TlsConfig(""))))
+	}
+
+	if cfg == nil {
+		return opt, nil
+	}
+
+	if cfg.MaxRecvMsgSize > 0 {
+		opt = append(opt, grpc.MaxRecvMsgSize(cfg.MaxRecvMsgSize.Int()))
+	}
+
+	if cfg.MaxSendMsgSize > 0 {
+		opt = append(opt, grpc.MaxSendMsgSize(cfg.MaxSendMsgSize.Int()))
+	}
+
+	if cfg.MaxConcurrentStreams > 0 {
+		opt = append(opt, grpc.MaxConcurrentStreams(cfg.MaxConcurrentStreams))
+	}
+
+	if cfg.ConnectionTimeout > 0 {
+		opt = append(opt, grpc.ConnectionTimeout(cfg.ConnectionTimeout.Time()))
+	}
+
+	if cfg.KeepAliveTime > 0 || cfg.KeepAliveTimeout > 0 {
+		opt = append(opt, grpc.KeepaliveParams(keepalive.ServerParameters{
+			Time:    cfg.KeepAliveTime.Time(),
+			Timeout: cfg.KeepAliveTimeout.Time(),
+		}))
+	}
+
+	return opt, nil
+}
+
+func (o *srv) setServer(ctx context.Context) error {
+	if o == nil {
+		return errInvalid
+	}
+
+	var (
+		cfg = o.GetConfig()
+
+		fctStop = func() {
+			_ = o.Stop(ctx)
+		}
+	)
+
+	opt, err := o.serverOptions(cfg)
+
+	if err != nil {
+		ent := o.logger().Entry(loglvl.ErrorLevel, "starting grpc server")
+		ent.ErrorAdd(true, err)
+		ent.Log()
+		return err
+	}
+
+	s := grpc.NewServer(opt...)
+
+	for _, f := range o.getRegister() {
+		f(s)
+	}
+
+	if cfg == nil || !cfg.DisableHealth {
+		grpc_health_v1.RegisterHealthServer(s, o.h)
+	}
+
+	if cfg != nil && cfg.EnableReflection {
+		reflection.Register(s)
+	}
+
+	if e := o.RunIfPortInUse(ctx, o.GetBindable(), 5, fctStop); e != nil {
+		return e
+	}
+
+	o.m.Lock()
+	o.s = s
+	o.m.Unlock()
+
+	return nil
+}
+
+func (o *srv) Start(ctx context.Context) error {
+	// Register Server to runner
+	if o.getServer() != nil {
+		if e := o.Stop(ctx); e != nil {
+			return e
+		}
+	}
+
+	if e := o.newRun(ctx); e != nil {
+		return e
+	} else if e = o.runStart(ctx); e != nil {
+		return e
+	}
+
+	return nil
+}
+
+func (o *srv) Stop(ctx context.Context) error {
+	if o == nil {
+		return errInvalid
+	}
+
+	o.m.RLock()
+	r := o.r
+	o.m.RUnlock()
+
+	if r == nil {
+		return nil
+	}
+
+	return r.Stop(ctx)
+}
+
+func (o *srv) Restart(ctx context.Context) error {
+	_ = o.Stop(ctx)
+	return o.Start(ctx)
+}
+
+func (o *srv) IsRunning() bool {
+	if o == nil {
+		return false
+	}
+
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	if o.r == nil {
+		return false
+	}
+
+	return o.r.IsRunning()
+}
+
+func (o *srv) PortInUse(ctx context.Context, listen string) error {
+	if e := o.PortNotUse(ctx, listen); e != nil {
+		return nil
+	}
+
+	return ErrorPortUse.Error(nil)
+}
+
+func (o *srv) PortNotUse(ctx context.Context, listen string) error {
+	var (
+		err error
+
+		cnl context.CancelFunc
+		con net.Conn
+		dia = net.Dialer{}
+	)
+
+	if h, p, e := net.SplitHostPort(listen); e == nil {
+		if h == "" || h == "0.0.0.0" || h == "::" || h == "::1" {
+			listen = net.JoinHostPort("127.0.0.1", p)
+		}
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		ctx, cnl = context.WithTimeout(ctx, TimeoutWaitingPortFreeing)
+		defer cnl()
+	}
+
+	con, err = dia.DialContext(ctx, libptc.NetworkTCP.Code(), listen)
+	defer func() {
+		if con != nil {
+			_ = con.Close()
+		}
+	}()
+
+	return err
+}
+
+func (o *srv) RunIfPortInUse(ctx context.Context, listen string, nbr uint8, fct func()) error {
+	chk := func() bool {
+		return o.PortInUse(ctx, listen) == nil
+	}
+
+	if !libsrv.RunNbr(nbr, chk, fct) {
+		return ErrorPortUse.Error(nil)
+	}
+
+	return nil
+}
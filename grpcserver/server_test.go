@@ -0,0 +1,232 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package grpcserver_test
+
+import (
+	"context"
+	"net"
+	"time"
+
+	libdur "github.com/nabbar/golib/duration"
+	libgrp "github.com/nabbar/golib/grpcserver"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+type echoServer interface{}
+
+// echoService is a test service using the health messages: Say answer SERVING to the "ping" service,
+// and Tick send a message every 10ms until the end of the stream, reported on the done channel.
+func echoService(done chan<- struct{}) grpc.ServiceDesc {
+	return grpc.ServiceDesc{
+		ServiceName: "test.Echo",
+		HandlerType: (*echoServer)(nil),
+		Methods: []grpc.MethodDesc{{
+			MethodName: "Say",
+			Handler: func(_ any, ctx context.Context, dec func(any) error, _ grpc.UnaryServerInterceptor) (any, error) {
+				var (
+					req = new(grpc_health_v1.HealthCheckRequest)
+					rsp = new(grpc_health_v1.HealthCheckResponse)
+				)
+
+				if e := dec(req); e != nil {
+					return nil, e
+				} else if req.GetService() == "ping" {
+					rsp.Status = grpc_health_v1.HealthCheckResponse_SERVING
+				}
+
+				return rsp, nil
+			},
+		}},
+		Streams: []grpc.StreamDesc{{
+			StreamName:    "Tick",
+			ServerStreams: true,
+			Handler: func(_ any, stream grpc.ServerStream) error {
+				defer func() {
+					done <- struct{}{}
+				}()
+
+				for {
+					select {
+					case <-stream.Context().Done():
+						return stream.Context().Err()
+					case <-time.After(10 * time.Millisecond):
+						if e := stream.SendMsg(&grpc_health_v1.HealthCheckResponse{}); e != nil {
+							return e
+						}
+					}
+				}
+			},
+		}},
+	}
+}
+
+func freeAddr() string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).ToNot(HaveOccurred())
+
+	defer func() {
+		_ = l.Close()
+	}()
+
+	return l.Addr().String()
+}
+
+func healthStatus(cli *grpc.ClientConn, service string) (grpc_health_v1.HealthCheckResponse_ServingStatus, error) {
+	var x, n = context.WithTimeout(ctx, 2*time.Second)
+	defer n()
+
+	rsp, err := grpc_health_v1.NewHealthClient(cli).Check(x, &grpc_health_v1.HealthCheckRequest{Service: service})
+
+	if err != nil {
+		return grpc_health_v1.HealthCheckResponse_UNKNOWN, err
+	}
+
+	return rsp.GetStatus(), nil
+}
+
+var _ = Describe("grpcserver", func() {
+	var (
+		adr string
+		srv libgrp.Server
+		cli *grpc.ClientConn
+		don chan struct{}
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		adr = freeAddr()
+		don = make(chan struct{}, 10)
+
+		srv, err = libgrp.New(libgrp.Config{
+			Name:            "test",
+			Listen:          adr,
+			GracefulTimeout: libdur.ParseDuration(3 * time.Second),
+		}, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		var dsc = echoService(don)
+
+		srv.RegisterService(func(s grpc.ServiceRegistrar) {
+			s.RegisterService(&dsc, nil)
+		})
+
+		Expect(srv.Start(ctx)).ToNot(HaveOccurred())
+		Eventually(srv.IsRunning, 5*time.Second, 50*time.Millisecond).Should(BeTrue())
+
+		cli, err = grpc.NewClient(adr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		if cli != nil {
+			_ = cli.Close()
+		}
+
+		if srv != nil {
+			_ = srv.Stop(ctx)
+		}
+	})
+
+	It("must serve the registered services and the health service", func() {
+		var rsp = new(grpc_health_v1.HealthCheckResponse)
+
+		Expect(cli.Invoke(ctx, "/test.Echo/Say", &grpc_health_v1.HealthCheckRequest{Service: "ping"}, rsp)).ToNot(HaveOccurred())
+		Expect(rsp.GetStatus()).To(Equal(grpc_health_v1.HealthCheckResponse_SERVING))
+
+		Eventually(func() (grpc_health_v1.HealthCheckResponse_ServingStatus, error) {
+			return healthStatus(cli, "")
+		}, 5*time.Second, 50*time.Millisecond).Should(Equal(grpc_health_v1.HealthCheckResponse_SERVING))
+
+		srv.SetServingStatus("test.Echo", false)
+		Expect(healthStatus(cli, "test.Echo")).To(Equal(grpc_health_v1.HealthCheckResponse_NOT_SERVING))
+
+		srv.SetServingStatus("test.Echo", true)
+		Expect(healthStatus(cli, "test.Echo")).To(Equal(grpc_health_v1.HealthCheckResponse_SERVING))
+	})
+
+	It("must end the stream of a disconnected client without blocking the stop", func() {
+		var (
+			x, n     = context.WithCancel(ctx)
+			str, err = cli.NewStream(x, &grpc.StreamDesc{StreamName: "Tick", ServerStreams: true}, "/test.Echo/Tick")
+		)
+
+		defer n()
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(str.CloseSend()).ToNot(HaveOccurred())
+		Expect(str.RecvMsg(new(grpc_health_v1.HealthCheckResponse))).ToNot(HaveOccurred())
+
+		n()
+		Eventually(don, 2*time.Second).Should(Receive())
+
+		var start = time.Now()
+		Expect(srv.Stop(ctx)).ToNot(HaveOccurred())
+		Expect(time.Since(start)).To(BeNumerically("<", 2*time.Second))
+		Expect(srv.IsRunning()).To(BeFalse())
+	})
+
+	It("must close the running streams after the graceful timeout when stopping", func() {
+		str, err := cli.NewStream(ctx, &grpc.StreamDesc{StreamName: "Tick", ServerStreams: true}, "/test.Echo/Tick")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(str.CloseSend()).ToNot(HaveOccurred())
+		Expect(str.RecvMsg(new(grpc_health_v1.HealthCheckResponse))).ToNot(HaveOccurred())
+
+		var start = time.Now()
+		_ = srv.Stop(ctx)
+		Expect(time.Since(start)).To(BeNumerically(">=", 2500*time.Millisecond))
+		Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+		Expect(srv.IsRunning()).To(BeFalse())
+		Eventually(don, 2*time.Second).Should(Receive())
+
+		// the messages sent before the stop are still buffered by the client
+		for err == nil {
+			err = str.RecvMsg(new(grpc_health_v1.HealthCheckResponse))
+		}
+
+		_, err = healthStatus(cli, "")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("must serve again after a restart with the health status reset", func() {
+		srv.SetServingStatus("", false)
+		Expect(healthStatus(cli, "")).To(Equal(grpc_health_v1.HealthCheckResponse_NOT_SERVING))
+
+		Expect(srv.Restart(ctx)).ToNot(HaveOccurred())
+		Eventually(srv.IsRunning, 5*time.Second, 50*time.Millisecond).Should(BeTrue())
+
+		Eventually(func() (grpc_health_v1.HealthCheckResponse_ServingStatus, error) {
+			return healthStatus(cli, "")
+		}, 5*time.Second, 50*time.Millisecond).Should(Equal(grpc_health_v1.HealthCheckResponse_SERVING))
+
+		var rsp = new(grpc_health_v1.HealthCheckResponse)
+		Expect(cli.Invoke(ctx, "/test.Echo/Say", &grpc_health_v1.HealthCheckRequest{Service: "ping"}, rsp)).ToNot(HaveOccurred())
+	})
+})
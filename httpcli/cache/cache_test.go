@@ -0,0 +1,296 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package cache_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	libdur "github.com/nabbar/golib/duration"
+	htccch "github.com/nabbar/golib/httpcli/cache"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func call(cli *http.Client, method, uri string, hdr ...string) (*http.Response, string) {
+	req, err := http.NewRequest(method, uri, nil)
+	Expect(err).ToNot(HaveOccurred())
+
+	for i := 0; i+1 < len(hdr); i += 2 {
+		req.Header.Set(hdr[i], hdr[i+1])
+	}
+
+	res, err := cli.Do(req)
+	Expect(err).ToNot(HaveOccurred())
+
+	b, err := io.ReadAll(res.Body)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(res.Body.Close()).ToNot(HaveOccurred())
+
+	return res, string(b)
+}
+
+var _ = Describe("httpcli/cache", func() {
+	var (
+		cnt atomic.Int32
+		srv *httptest.Server
+		hdl http.HandlerFunc
+		cli *http.Client
+	)
+
+	BeforeEach(func() {
+		cnt.Store(0)
+		srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cnt.Add(1)
+			hdl(w, r)
+		}))
+		cli = &http.Client{Transport: htccch.New(htccch.Config{}, nil, srv.Client().Transport)}
+	})
+
+	AfterEach(func() {
+		srv.Close()
+	})
+
+	Context("Config", func() {
+		It("must validate the default config", func() {
+			Expect(htccch.DefaultConfig("")).ToNot(BeEmpty())
+			Expect(htccch.Config{}.Validate()).To(BeNil())
+		})
+
+		It("must need a path for the disk storage", func() {
+			Expect(htccch.Config{Storage: htccch.StorageDisk}.Validate()).ToNot(BeNil())
+			Expect(htccch.Config{Storage: "other"}.Validate()).ToNot(BeNil())
+		})
+	})
+
+	Context("Freshness", func() {
+		It("must serve a fresh response from the cache", func() {
+			hdl = func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Cache-Control", "max-age=60")
+				_, _ = w.Write([]byte("hello"))
+			}
+
+			res, body := call(cli, http.MethodGet, srv.URL)
+			Expect(res.Header.Get(htccch.HeaderCacheStatus)).To(Equal(htccch.CacheMiss))
+			Expect(body).To(Equal("hello"))
+
+			res, body = call(cli, http.MethodGet, srv.URL)
+			Expect(res.Header.Get(htccch.HeaderCacheStatus)).To(Equal(htccch.CacheHit))
+			Expect(res.Header.Get("Age")).ToNot(BeEmpty())
+			Expect(body).To(Equal("hello"))
+
+			res, body = call(cli, http.MethodHead, srv.URL)
+			Expect(res.Header.Get(htccch.HeaderCacheStatus)).To(Equal(htccch.CacheHit))
+			Expect(body).To(BeEmpty())
+
+			Expect(cnt.Load()).To(BeEquivalentTo(1))
+		})
+
+		It("must not store a no-store response", func() {
+			hdl = func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Cache-Control", "no-store, max-age=60")
+				_, _ = w.Write([]byte("hello"))
+			}
+
+			call(cli, http.MethodGet, srv.URL)
+			call(cli, http.MethodGet, srv.URL)
+			Expect(cnt.Load()).To(BeEquivalentTo(2))
+		})
+
+		It("must bypass the cache with a no-cache request", func() {
+			hdl = func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Cache-Control", "max-age=60")
+				_, _ = w.Write([]byte("hello"))
+			}
+
+			call(cli, http.MethodGet, srv.URL)
+			res, _ := call(cli, http.MethodGet, srv.URL, "Cache-Control", "no-cache")
+			Expect(res.Header.Get(htccch.HeaderCacheStatus)).To(Equal(htccch.CacheMiss))
+			Expect(cnt.Load()).To(BeEquivalentTo(2))
+		})
+
+		It("must not store a response bigger than the max entry size", func() {
+			cli = &http.Client{Transport: htccch.New(htccch.Config{MaxEntrySize: 4}, nil, srv.Client().Transport)}
+			hdl = func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Cache-Control", "max-age=60")
+				w.(http.Flusher).Flush()
+				_, _ = w.Write([]byte("hello world"))
+			}
+
+			_, body := call(cli, http.MethodGet, srv.URL)
+			Expect(body).To(Equal("hello world"))
+			call(cli, http.MethodGet, srv.URL)
+			Expect(cnt.Load()).To(BeEquivalentTo(2))
+		})
+
+		It("must not serve a variant not matching the request", func() {
+			hdl = func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Cache-Control", "max-age=60")
+				w.Header().Set("Vary", "Accept-Language")
+				_, _ = w.Write([]byte(r.Header.Get("Accept-Language")))
+			}
+
+			call(cli, http.MethodGet, srv.URL, "Accept-Language", "fr")
+			_, body := call(cli, http.MethodGet, srv.URL, "Accept-Language", "en")
+			Expect(body).To(Equal("en"))
+			Expect(cnt.Load()).To(BeEquivalentTo(2))
+		})
+	})
+
+	Context("Validation", func() {
+		It("must revalidate a stale response with its etag", func() {
+			hdl = func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("ETag", `"v1"`)
+				w.Header().Set("Cache-Control", "no-cache")
+
+				if r.Header.Get("If-None-Match") == `"v1"` {
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+
+				_, _ = w.Write([]byte("hello"))
+			}
+
+			call(cli, http.MethodGet, srv.URL)
+			res, body := call(cli, http.MethodGet, srv.URL)
+			Expect(res.StatusCode).To(Equal(http.StatusOK))
+			Expect(res.Header.Get(htccch.HeaderCacheStatus)).To(Equal(htccch.CacheRevalidated))
+			Expect(body).To(Equal("hello"))
+			Expect(cnt.Load()).To(BeEquivalentTo(2))
+		})
+
+		It("must serve a stale response while revalidating it in background", func() {
+			cli = &http.Client{Transport: htccch.New(htccch.Config{
+				StaleWhileRevalidate: libdur.ParseDuration(time.Minute),
+			}, nil, srv.Client().Transport)}
+
+			hdl = func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Cache-Control", "max-age=0")
+				w.Header().Set("Last-Modified", time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat))
+				_, _ = w.Write([]byte("v" + strconv.Itoa(int(cnt.Load()))))
+			}
+
+			_, body := call(cli, http.MethodGet, srv.URL)
+			Expect(body).To(Equal("v1"))
+
+			res, body := call(cli, http.MethodGet, srv.URL)
+			Expect(res.Header.Get(htccch.HeaderCacheStatus)).To(Equal(htccch.CacheStale))
+			Expect(body).To(Equal("v1"))
+
+			Eventually(cnt.Load).Should(BeEquivalentTo(2))
+			Eventually(func() string {
+				_, b := call(cli, http.MethodGet, srv.URL)
+				return b
+			}).Should(Equal("v2"))
+		})
+
+		It("must invalidate the cached response on an unsafe request", func() {
+			hdl = func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Cache-Control", "max-age=60")
+				_, _ = w.Write([]byte(r.Method))
+			}
+
+			call(cli, http.MethodGet, srv.URL)
+			call(cli, http.MethodGet, srv.URL)
+			Expect(cnt.Load()).To(BeEquivalentTo(1))
+
+			call(cli, http.MethodPost, srv.URL)
+			_, body := call(cli, http.MethodGet, srv.URL)
+			Expect(body).To(Equal(http.MethodGet))
+			Expect(cnt.Load()).To(BeEquivalentTo(3))
+		})
+	})
+
+	Context("Storage", func() {
+		It("must evict the least recently used entries of the memory storage", func() {
+			sto := htccch.NewMemory(2, 0)
+			Expect(sto.Store("a", []byte("1"))).ToNot(HaveOccurred())
+			Expect(sto.Store("b", []byte("2"))).ToNot(HaveOccurred())
+			_, ok := sto.Load("a")
+			Expect(ok).To(BeTrue())
+			Expect(sto.Store("c", []byte("3"))).ToNot(HaveOccurred())
+
+			_, ok = sto.Load("b")
+			Expect(ok).To(BeFalse())
+			Expect(sto.Len()).To(Equal(2))
+			Expect(sto.Size()).To(BeEquivalentTo(2))
+
+			sto = htccch.NewMemory(0, 5)
+			Expect(sto.Store("a", []byte("123"))).ToNot(HaveOccurred())
+			Expect(sto.Store("b", []byte("456"))).ToNot(HaveOccurred())
+			Expect(sto.Len()).To(Equal(1))
+		})
+
+		It("must keep the entries of the disk storage across instances", func() {
+			dir := GinkgoT().TempDir()
+
+			sto, err := htccch.NewDisk(dir, 2, 0)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sto.Store("a", []byte("1"))).ToNot(HaveOccurred())
+			Expect(sto.Store("b", []byte("2"))).ToNot(HaveOccurred())
+			Expect(sto.Store("c", []byte("3"))).ToNot(HaveOccurred())
+			Expect(sto.Len()).To(Equal(2))
+
+			sto, err = htccch.NewDisk(dir, 2, 0)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sto.Len()).To(Equal(2))
+
+			val, ok := sto.Load("c")
+			Expect(ok).To(BeTrue())
+			Expect(string(val)).To(Equal("3"))
+
+			_, ok = sto.Load("a")
+			Expect(ok).To(BeFalse())
+
+			sto.Clean()
+			Expect(sto.Len()).To(BeZero())
+		})
+
+		It("must cache the responses into the disk storage", func() {
+			cfg := htccch.Config{Storage: htccch.StorageDisk, Path: GinkgoT().TempDir()}
+			Expect(cfg.Validate()).To(BeNil())
+
+			c, err := cfg.Client(srv.Client())
+			Expect(err).ToNot(HaveOccurred())
+
+			hdl = func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Cache-Control", "max-age=60")
+				_, _ = w.Write([]byte(strings.Repeat("x", 100)))
+			}
+
+			call(c, http.MethodGet, srv.URL)
+			res, body := call(c, http.MethodGet, srv.URL)
+			Expect(res.Header.Get(htccch.HeaderCacheStatus)).To(Equal(htccch.CacheHit))
+			Expect(body).To(HaveLen(100))
+			Expect(cnt.Load()).To(BeEquivalentTo(1))
+		})
+	})
+})
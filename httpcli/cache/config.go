@@ -0,0 +1,189 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package cache
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	libval "github.com/go-playground/validator/v10"
+	cfgcst "github.com/nabbar/golib/config/const"
+	libdur "github.com/nabbar/golib/duration"
+	liberr "github.com/nabbar/golib/errors"
+	libsiz "github.com/nabbar/golib/size"
+)
+
+const (
+	// StorageMemory keep the cached responses in memory.
+	StorageMemory = "memory"
+	// StorageDisk keep the cached responses into files of a directory.
+	StorageDisk = "disk"
+
+	// DefaultMaxEntries is the maximum number of cached responses if not defined.
+	DefaultMaxEntries = 1024
+	// DefaultMaxSize is the maximum cumulated size of the cached responses if not defined.
+	DefaultMaxSize = 64 * libsiz.SizeMega
+	// DefaultMaxEntrySize is the maximum size of the body of a cached response if not defined.
+	DefaultMaxEntrySize = libsiz.SizeMega
+)
+
+// Config is the caching policy of a RoundTripper, following the HTTP caching rules (RFC 9111).
+type Config struct {
+	// Storage is the type of storage: memory (default) or disk.
+	Storage string `json:"storage,omitempty" yaml:"storage,omitempty" toml:"storage,omitempty" mapstructure:"storage,omitempty" validate:"omitempty,oneof=memory disk"`
+
+	// Path is the directory of the disk storage.
+	Path string `json:"path,omitempty" yaml:"path,omitempty" toml:"path,omitempty" mapstructure:"path,omitempty"`
+
+	// MaxEntries is the maximum number of cached responses. If zero, DefaultMaxEntries is used.
+	MaxEntries int `json:"max-entries,omitempty" yaml:"max-entries,omitempty" toml:"max-entries,omitempty" mapstructure:"max-entries,omitempty" validate:"omitempty,min=0"`
+
+	// MaxSize is the maximum cumulated size of the cached responses. If zero, DefaultMaxSize is used.
+	MaxSize libsiz.Size `json:"max-size,omitempty" yaml:"max-size,omitempty" toml:"max-size,omitempty" mapstructure:"max-size,omitempty"`
+
+	// MaxEntrySize is the maximum size of the body of a cached response, bigger responses are not cached.
+	// If zero, DefaultMaxEntrySize is used.
+	MaxEntrySize libsiz.Size `json:"max-entry-size,omitempty" yaml:"max-entry-size,omitempty" toml:"max-entry-size,omitempty" mapstructure:"max-entry-size,omitempty"`
+
+	// Shared define the cache as shared between several users: the private responses are not cached,
+	// and the s-maxage directive is used.
+	Shared bool `json:"shared" yaml:"shared" toml:"shared" mapstructure:"shared"`
+
+	// StaleWhileRevalidate is the duration during which a stale response is still served while it is
+	// revalidated in background, if the response does not define its own stale-while-revalidate directive.
+	StaleWhileRevalidate libdur.Duration `json:"stale-while-revalidate,omitempty" yaml:"stale-while-revalidate,omitempty" toml:"stale-while-revalidate,omitempty" mapstructure:"stale-while-revalidate,omitempty" validate:"omitempty,min=0"`
+}
+
+func DefaultConfig(indent string) []byte {
+	var (
+		res = bytes.NewBuffer(make([]byte, 0))
+		def = []byte(`{
+  "storage": "memory",
+  "path": "",
+  "max-entries": 1024,
+  "max-size": "64MB",
+  "max-entry-size": "1MB",
+  "shared": false,
+  "stale-while-revalidate": "0s"
+}`)
+	)
+	if err := json.Indent(res, def, indent, cfgcst.JSONIndent); err != nil {
+		return def
+	} else {
+		return res.Bytes()
+	}
+}
+
+func (o Config) Validate() liberr.Error {
+	var e = ErrorValidatorError.Error(nil)
+
+	if err := libval.New().Struct(o); err != nil {
+		if er, ok := err.(*libval.InvalidValidationError); ok {
+			e.Add(er)
+		}
+
+		for _, er := range err.(libval.ValidationErrors) {
+			//nolint #goerr113
+			e.Add(fmt.Errorf("config field '%s' is not validated by constraint '%s'", er.Namespace(), er.ActualTag()))
+		}
+	}
+
+	if o.Storage == StorageDisk && len(o.Path) < 1 {
+		//nolint #goerr113
+		e.Add(fmt.Errorf("config field 'Config.Path' is required by the disk storage"))
+	}
+
+	if !e.HasParent() {
+		e = nil
+	}
+
+	return e
+}
+
+// NewStorage return a new storage of the type and limits of this config.
+func (o Config) NewStorage() (Storage, error) {
+	o = o.normalize()
+
+	if o.Storage == StorageDisk {
+		return NewDisk(o.Path, o.MaxEntries, o.MaxSize.Int64())
+	}
+
+	return NewMemory(o.MaxEntries, o.MaxSize.Int64()), nil
+}
+
+// New return a RoundTripper caching the responses of the given RoundTripper into a new storage, see New.
+func (o Config) New(next http.RoundTripper) (http.RoundTripper, error) {
+	if s, e := o.NewStorage(); e != nil {
+		return nil, e
+	} else {
+		return New(o, s, next), nil
+	}
+}
+
+// Client return a copy of the given client with its transport wrapped with a cache using a new storage.
+// If the client is nil, a new client with the default transport is returned.
+func (o Config) Client(cli *http.Client) (*http.Client, error) {
+	var res = &http.Client{}
+
+	if cli != nil {
+		*res = *cli
+	}
+
+	if t, e := o.New(res.Transport); e != nil {
+		return nil, e
+	} else {
+		res.Transport = t
+	}
+
+	return res, nil
+}
+
+// normalize return the config with the default values applied.
+func (o Config) normalize() Config {
+	if len(o.Storage) < 1 {
+		o.Storage = StorageMemory
+	}
+
+	if o.MaxEntries < 1 {
+		o.MaxEntries = DefaultMaxEntries
+	}
+
+	if o.MaxSize < 1 {
+		o.MaxSize = DefaultMaxSize
+	}
+
+	if o.MaxEntrySize < 1 {
+		o.MaxEntrySize = DefaultMaxEntrySize
+	}
+
+	if o.StaleWhileRevalidate < 0 {
+		o.StaleWhileRevalidate = 0
+	}
+
+	return o
+}
@@ -0,0 +1,273 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// heuristicStatus is the list of the status cacheable by default (RFC 9110 section 15.1).
+var heuristicStatus = map[int]bool{
+	http.StatusOK:                   true,
+	http.StatusNonAuthoritativeInfo: true,
+	http.StatusNoContent:            true,
+	http.StatusMultipleChoices:      true,
+	http.StatusMovedPermanently:     true,
+	http.StatusPermanentRedirect:    true,
+	http.StatusNotFound:             true,
+	http.StatusMethodNotAllowed:     true,
+	http.StatusGone:                 true,
+	http.StatusRequestURITooLong:    true,
+	http.StatusNotImplemented:       true,
+}
+
+// heuristicMax is the maximum freshness lifetime computed from the Last-Modified header.
+const heuristicMax = 24 * time.Hour
+
+// directives is a parsed Cache-Control header.
+type directives map[string]string
+
+func parseDirectives(h http.Header) directives {
+	var res = make(directives)
+
+	for _, l := range h.Values("Cache-Control") {
+		for _, p := range strings.Split(l, ",") {
+			p = strings.TrimSpace(p)
+
+			if len(p) < 1 {
+				continue
+			} else if k, v, ok := strings.Cut(p, "="); ok {
+				res[strings.ToLower(strings.TrimSpace(k))] = strings.Trim(strings.TrimSpace(v), `"`)
+			} else {
+				res[strings.ToLower(p)] = ""
+			}
+		}
+	}
+
+	return res
+}
+
+func (d directives) has(key string) bool {
+	_, k := d[key]
+	return k
+}
+
+// seconds return the duration of the given directive if it exists with a valid value.
+func (d directives) seconds(key string) (time.Duration, bool) {
+	if v, k := d[key]; !k {
+		return 0, false
+	} else if i, e := strconv.ParseInt(v, 10, 64); e != nil || i < 0 {
+		return 0, false
+	} else {
+		return time.Duration(i) * time.Second, true
+	}
+}
+
+// entry is a cached response.
+type entry struct {
+	Status    int
+	Proto     string
+	Header    http.Header
+	Body      []byte
+	Vary      map[string]string // request header values selected by the Vary header
+	Request   time.Time         // time of the request
+	Response  time.Time         // time of the response
+	Lifetime  time.Duration     // freshness lifetime
+	AgeOrigin time.Duration     // corrected initial age
+}
+
+func (e *entry) encode() ([]byte, error) {
+	var b = bytes.NewBuffer(make([]byte, 0, len(e.Body)+512))
+
+	if err := gob.NewEncoder(b).Encode(e); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+func decodeEntry(p []byte) (*entry, error) {
+	var e = &entry{}
+
+	if err := gob.NewDecoder(bytes.NewReader(p)).Decode(e); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// newEntry return the entry of the given response with its freshness lifetime and initial age
+// (RFC 9111 section 4.2). The body must be given as the response body is not read.
+func newEntry(req *http.Request, res *http.Response, body []byte, reqTime, resTime time.Time, shared bool) *entry {
+	var e = &entry{
+		Status:   res.StatusCode,
+		Proto:    res.Proto,
+		Header:   res.Header.Clone(),
+		Body:     body,
+		Vary:     make(map[string]string),
+		Request:  reqTime,
+		Response: resTime,
+	}
+
+	for _, v := range varyHeaders(res.Header) {
+		e.Vary[v] = strings.Join(req.Header.Values(v), ",")
+	}
+
+	e.update(res.Header, reqTime, resTime, shared)
+
+	return e
+}
+
+// update apply the freshness information of the given response header, used by a new response
+// and by a 304 response validating the entry.
+func (e *entry) update(h http.Header, reqTime, resTime time.Time, shared bool) {
+	var (
+		dir = parseDirectives(h)
+		dat = parseDate(h.Get("Date"), resTime)
+		age time.Duration
+	)
+
+	e.Request = reqTime
+	e.Response = resTime
+
+	if a, err := strconv.ParseInt(h.Get("Age"), 10, 64); err == nil && a > 0 {
+		age = time.Duration(a) * time.Second
+	}
+
+	// apparent age and corrected age
+	e.AgeOrigin = resTime.Sub(dat)
+
+	if e.AgeOrigin < 0 {
+		e.AgeOrigin = 0
+	}
+
+	if c := age + resTime.Sub(reqTime); c > e.AgeOrigin {
+		e.AgeOrigin = c
+	}
+
+	if d, k := dir.seconds("s-maxage"); shared && k {
+		e.Lifetime = d
+	} else if d, k = dir.seconds("max-age"); k {
+		e.Lifetime = d
+	} else if x := h.Get("Expires"); len(x) > 0 {
+		// an invalid Expires means already expired
+		e.Lifetime = parseDate(x, time.Time{}).Sub(dat)
+	} else if l := h.Get("Last-Modified"); len(l) > 0 && heuristicStatus[e.Status] {
+		e.Lifetime = dat.Sub(parseDate(l, dat)) / 10
+
+		if e.Lifetime > heuristicMax {
+			e.Lifetime = heuristicMax
+		}
+	} else {
+		e.Lifetime = 0
+	}
+
+	if e.Lifetime < 0 || dir.has("no-cache") {
+		e.Lifetime = 0
+	}
+}
+
+// age return the current age of the entry.
+func (e *entry) age(now time.Time) time.Duration {
+	return e.AgeOrigin + now.Sub(e.Response)
+}
+
+// stale return the duration since the entry is stale, negative if it is still fresh.
+func (e *entry) stale(now time.Time) time.Duration {
+	return e.age(now) - e.Lifetime
+}
+
+// matchVary return true if the given request select the same representation as the entry.
+func (e *entry) matchVary(req *http.Request) bool {
+	for k, v := range e.Vary {
+		if k == "*" || strings.Join(req.Header.Values(k), ",") != v {
+			return false
+		}
+	}
+
+	return true
+}
+
+// response return a new response of the entry for the given request.
+func (e *entry) response(req *http.Request, now time.Time) *http.Response {
+	var h = e.Header.Clone()
+
+	h.Set("Age", strconv.FormatInt(int64(e.age(now)/time.Second), 10))
+
+	res := &http.Response{
+		Status:        strconv.Itoa(e.Status) + " " + http.StatusText(e.Status),
+		StatusCode:    e.Status,
+		Proto:         e.Proto,
+		Header:        h,
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+
+	if a, b, ok := http.ParseHTTPVersion(e.Proto); ok {
+		res.ProtoMajor, res.ProtoMinor = a, b
+	} else {
+		res.Proto, res.ProtoMajor, res.ProtoMinor = "HTTP/1.1", 1, 1
+	}
+
+	if req.Method == http.MethodHead {
+		res.Body = http.NoBody
+	} else {
+		res.Body = io.NopCloser(bytes.NewReader(e.Body))
+	}
+
+	return res
+}
+
+func varyHeaders(h http.Header) []string {
+	var res = make([]string, 0)
+
+	for _, l := range h.Values("Vary") {
+		for _, v := range strings.Split(l, ",") {
+			if v = strings.TrimSpace(v); len(v) > 0 {
+				res = append(res, http.CanonicalHeaderKey(v))
+			}
+		}
+	}
+
+	return res
+}
+
+// parseDate return the given http date, or the default time if empty or invalid.
+func parseDate(s string, def time.Time) time.Time {
+	if len(s) < 1 {
+		return def
+	} else if t, e := http.ParseTime(s); e != nil {
+		return def
+	} else {
+		return t
+	}
+}
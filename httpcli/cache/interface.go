@@ -0,0 +1,73 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package cache
+
+import (
+	"net/http"
+	"sync"
+)
+
+const (
+	// HeaderCacheStatus is the header added to each response of a cached request with one of the CacheStatus.
+	HeaderCacheStatus = "X-Cache"
+
+	// CacheHit is a fresh response served from the cache.
+	CacheHit = "HIT"
+	// CacheMiss is a response sent by the origin.
+	CacheMiss = "MISS"
+	// CacheStale is a stale response served from the cache while it is revalidated in background.
+	CacheStale = "STALE"
+	// CacheRevalidated is a response served from the cache after its validation by the origin.
+	CacheRevalidated = "REVALIDATED"
+)
+
+// New return a RoundTripper middleware caching the responses of the given RoundTripper into the given storage,
+// following the HTTP caching rules (RFC 9111). If next is nil, http.DefaultTransport is used.
+// If the storage is nil, a memory storage with the limits of the config is used.
+//
+// Only the GET responses are stored, the HEAD requests are served from them. A fresh response is served
+// from the cache, a stale one is validated with its ETag or Last-Modified header. Into the stale-while-revalidate
+// window, the stale response is served while it is validated in background. A successful request with an unsafe
+// method invalidate the cached response of its url. The requests with their own conditional or range headers bypass the cache.
+// Only one variant (see the Vary header) is kept for each url.
+func New(cfg Config, sto Storage, next http.RoundTripper) http.RoundTripper {
+	cfg = cfg.normalize()
+
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	if sto == nil {
+		sto = NewMemory(cfg.MaxEntries, cfg.MaxSize.Int64())
+	}
+
+	return &rtp{
+		n: next,
+		c: cfg,
+		s: sto,
+		r: sync.Map{},
+	}
+}
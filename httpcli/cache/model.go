@@ -0,0 +1,331 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package cache
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+type rtp struct {
+	n http.RoundTripper
+	c Config
+	s Storage
+	r sync.Map // keys under background revalidation
+}
+
+func (o *rtp) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+		// cacheable
+	case http.MethodOptions, http.MethodTrace, http.MethodConnect:
+		return o.n.RoundTrip(req)
+	default:
+		res, err := o.n.RoundTrip(req)
+
+		if err == nil && res.StatusCode < http.StatusBadRequest {
+			o.invalidate(req.URL, res)
+		}
+
+		return res, err
+	}
+
+	var (
+		key = o.key(req.URL)
+		dir = parseDirectives(req.Header)
+		now = time.Now()
+		ent *entry
+	)
+
+	if dir.has("no-store") || isConditional(req) || len(req.Header.Get("Range")) > 0 {
+		return o.n.RoundTrip(req)
+	} else if !dir.has("no-cache") {
+		ent = o.load(key, req)
+	}
+
+	if ent != nil {
+		var stl = ent.stale(now)
+
+		if d, k := dir.seconds("max-age"); k && ent.age(now) > d {
+			stl = ent.age(now) - d
+		}
+
+		if stl <= 0 {
+			return o.cached(ent, req, now, CacheHit), nil
+		} else if stl <= o.staleWindow(ent) && req.Method == http.MethodGet {
+			var res = o.cached(ent, req, now, CacheStale)
+			o.background(key, req, ent)
+			return res, nil
+		}
+	}
+
+	if req.Method == http.MethodHead {
+		return o.n.RoundTrip(req)
+	}
+
+	return o.fetch(key, req, ent)
+}
+
+// fetch send the request to the origin, conditional if an entry is given, and store the response.
+func (o *rtp) fetch(key string, req *http.Request, ent *entry) (*http.Response, error) {
+	var (
+		out     = req
+		reqTime = time.Now()
+	)
+
+	if ent != nil {
+		out = conditional(req, ent)
+	}
+
+	res, err := o.n.RoundTrip(out)
+
+	if err != nil {
+		return res, err
+	}
+
+	var resTime = time.Now()
+
+	if ent != nil && res.StatusCode == http.StatusNotModified {
+		discard(res)
+
+		// the 304 response update the stored header (RFC 9111 section 4.3.4)
+		for k, v := range res.Header {
+			ent.Header[k] = v
+		}
+
+		ent.update(ent.Header, reqTime, resTime, o.c.Shared)
+		o.store(key, ent)
+
+		return o.cached(ent, req, resTime, CacheRevalidated), nil
+	}
+
+	if out != req {
+		res.Request = req
+	}
+
+	return o.keep(key, req, res, reqTime, resTime), nil
+}
+
+// keep store the given response if it is cacheable and return it with a readable body.
+func (o *rtp) keep(key string, req *http.Request, res *http.Response, reqTime, resTime time.Time) *http.Response {
+	res.Header.Set(HeaderCacheStatus, CacheMiss)
+
+	if !o.cacheable(req, res) {
+		return res
+	}
+
+	var (
+		max = o.c.MaxEntrySize.Int64()
+		buf = bytes.NewBuffer(make([]byte, 0))
+	)
+
+	if res.ContentLength > max {
+		return res
+	}
+
+	n, err := io.Copy(buf, io.LimitReader(res.Body, max+1))
+
+	if err != nil || n > max {
+		// too big or broken body: give back the read part followed by the rest, without caching
+		res.Body = &restBody{
+			Reader: io.MultiReader(bytes.NewReader(buf.Bytes()), &errReader{r: res.Body, e: err}),
+			Closer: res.Body,
+		}
+		return res
+	}
+
+	_ = res.Body.Close()
+
+	res.Body = io.NopCloser(bytes.NewReader(buf.Bytes()))
+	res.ContentLength = n
+
+	var ent = newEntry(req, res, buf.Bytes(), reqTime, resTime, o.c.Shared)
+	ent.Header.Del(HeaderCacheStatus)
+
+	if ent.Lifetime > 0 || len(ent.Header.Get("ETag")) > 0 || len(ent.Header.Get("Last-Modified")) > 0 {
+		o.store(key, ent)
+	}
+
+	return res
+}
+
+// cacheable return true if the response of the given request can be stored (RFC 9111 section 3).
+func (o *rtp) cacheable(req *http.Request, res *http.Response) bool {
+	var dir = parseDirectives(res.Header)
+
+	if req.Method != http.MethodGet || dir.has("no-store") {
+		return false
+	} else if o.c.Shared && dir.has("private") {
+		return false
+	} else if o.c.Shared && len(req.Header.Get("Authorization")) > 0 &&
+		!dir.has("public") && !dir.has("s-maxage") && !dir.has("must-revalidate") {
+		return false
+	}
+
+	for _, v := range varyHeaders(res.Header) {
+		if v == "*" {
+			return false
+		}
+	}
+
+	if heuristicStatus[res.StatusCode] {
+		return true
+	}
+
+	// other status need an explicit freshness
+	return res.StatusCode < http.StatusInternalServerError &&
+		(dir.has("max-age") || (o.c.Shared && dir.has("s-maxage")) || len(res.Header.Get("Expires")) > 0)
+}
+
+// staleWindow return the duration a stale entry can be served while revalidated.
+func (o *rtp) staleWindow(ent *entry) time.Duration {
+	var dir = parseDirectives(ent.Header)
+
+	if dir.has("must-revalidate") || dir.has("no-cache") || (o.c.Shared && dir.has("proxy-revalidate")) {
+		return 0
+	} else if d, k := dir.seconds("stale-while-revalidate"); k {
+		return d
+	}
+
+	return o.c.StaleWhileRevalidate.Time()
+}
+
+// background revalidate the given entry once at a time by key, without the cancellation of the request.
+// The entry must not be used anymore by the caller.
+func (o *rtp) background(key string, req *http.Request, ent *entry) {
+	if _, l := o.r.LoadOrStore(key, struct{}{}); l {
+		return
+	}
+
+	var r = req.Clone(context.WithoutCancel(req.Context()))
+
+	go func() {
+		defer o.r.Delete(key)
+
+		if res, err := o.fetch(key, r, ent); err == nil {
+			discard(res)
+		}
+	}()
+}
+
+func (o *rtp) cached(ent *entry, req *http.Request, now time.Time, status string) *http.Response {
+	var res = ent.response(req, now)
+	res.Header.Set(HeaderCacheStatus, status)
+	return res
+}
+
+func (o *rtp) key(u *url.URL) string {
+	return http.MethodGet + " " + u.String()
+}
+
+func (o *rtp) load(key string, req *http.Request) *entry {
+	if b, k := o.s.Load(key); !k {
+		return nil
+	} else if e, err := decodeEntry(b); err != nil {
+		o.s.Delete(key)
+		return nil
+	} else if !e.matchVary(req) {
+		return nil
+	} else {
+		return e
+	}
+}
+
+func (o *rtp) store(key string, ent *entry) {
+	if b, e := ent.encode(); e == nil {
+		_ = o.s.Store(key, b)
+	}
+}
+
+// invalidate remove the cached response of the url and of the Location and Content-Location of the response
+// on the same host (RFC 9111 section 4.4).
+func (o *rtp) invalidate(u *url.URL, res *http.Response) {
+	o.s.Delete(o.key(u))
+
+	for _, h := range []string{"Location", "Content-Location"} {
+		if l := res.Header.Get(h); len(l) < 1 {
+			continue
+		} else if r, e := u.Parse(l); e == nil && r.Host == u.Host {
+			o.s.Delete(o.key(r))
+		}
+	}
+}
+
+func isConditional(req *http.Request) bool {
+	for _, h := range []string{"If-None-Match", "If-Modified-Since", "If-Match", "If-Unmodified-Since", "If-Range"} {
+		if len(req.Header.Get(h)) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// conditional return a copy of the request validating the given entry.
+func conditional(req *http.Request, ent *entry) *http.Request {
+	var r = req.Clone(req.Context())
+
+	if t := ent.Header.Get("ETag"); len(t) > 0 {
+		r.Header.Set("If-None-Match", t)
+	}
+
+	if l := ent.Header.Get("Last-Modified"); len(l) > 0 {
+		r.Header.Set("If-Modified-Since", l)
+	}
+
+	return r
+}
+
+func discard(res *http.Response) {
+	if res != nil && res.Body != nil {
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+	}
+}
+
+type restBody struct {
+	io.Reader
+	io.Closer
+}
+
+// errReader return the given error if any instead of reading the given reader.
+type errReader struct {
+	r io.Reader
+	e error
+}
+
+func (o *errReader) Read(p []byte) (int, error) {
+	if o.e != nil {
+		return 0, o.e
+	}
+
+	return o.r.Read(p)
+}
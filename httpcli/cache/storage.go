@@ -0,0 +1,257 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	libcch "github.com/nabbar/golib/cache"
+	libiot "github.com/nabbar/golib/ioutils"
+)
+
+// Storage keep the encoded cached responses by key. A Storage is safe for concurrent use
+// and remove the least recently used entries to stay below its limits.
+type Storage interface {
+	// Load return the value of the given key if it exists.
+	Load(key string) ([]byte, bool)
+
+	// Store add or replace the value of the given key.
+	Store(key string, val []byte) error
+
+	// Delete remove the given key.
+	Delete(key string)
+
+	// Clean remove all the keys.
+	Clean()
+
+	// Len return the number of keys.
+	Len() int
+
+	// Size return the cumulated size of the values.
+	Size() int64
+}
+
+// NewMemory return a Storage keeping the values in memory, limited to the given number of entries
+// and cumulated size. A zero or negative limit means no limit.
+func NewMemory(maxEntries int, maxSize int64) Storage {
+	return &mem{
+		l: newLRU[[]byte](maxEntries, maxSize, func(val []byte) int64 {
+			return int64(len(val))
+		}),
+	}
+}
+
+// newLRU return the least recently used index of a storage, limited in number of entries and in cumulated size.
+func newLRU[V any](maxEntries int, maxSize int64, size func(val V) int64) libcch.Typed[string, V] {
+	return libcch.NewTypedSize[string, V](libcch.Options{
+		MaxEntries: maxEntries,
+		Policy:     libcch.PolicyLRU,
+	}, maxSize, size)
+}
+
+type mem struct {
+	l libcch.Typed[string, []byte]
+}
+
+func (o *mem) Load(key string) ([]byte, bool) {
+	return o.l.Get(key)
+}
+
+func (o *mem) Store(key string, val []byte) error {
+	o.l.Set(key, val)
+	return nil
+}
+
+func (o *mem) Delete(key string) {
+	o.l.Delete(key)
+}
+
+func (o *mem) Clean() {
+	o.l.Clean()
+}
+
+func (o *mem) Len() int {
+	return o.l.Len()
+}
+
+func (o *mem) Size() int64 {
+	return o.l.Stats().Size
+}
+
+// NewDisk return a Storage keeping each value into a file of the given directory, limited to the given
+// number of entries and cumulated size. A zero or negative limit means no limit.
+// The directory is created if needed, and the files already existing into it are loaded, the most
+// recently modified being the most recently used.
+func NewDisk(path string, maxEntries int, maxSize int64) (Storage, error) {
+	if len(path) < 1 {
+		return nil, ErrorParamEmpty.Error(nil)
+	} else if e := libiot.PathCheckCreate(false, path, 0600, 0700); e != nil {
+		return nil, ErrorStorageCreate.Error(e)
+	}
+
+	var o = &dsk{
+		p: filepath.Clean(path),
+		l: newLRU[int64](maxEntries, maxSize, func(val int64) int64 {
+			return val
+		}),
+	}
+
+	o.l.RegisterFuncEvict(func(key string, _ int64) {
+		_ = os.Remove(o.file(key))
+	})
+
+	if e := o.load(); e != nil {
+		return nil, ErrorStorageCreate.Error(e)
+	}
+
+	return o, nil
+}
+
+type dsk struct {
+	p string                      // directory
+	l libcch.Typed[string, int64] // size by file name
+}
+
+func (o *dsk) name(key string) string {
+	var h = sha256.Sum256([]byte(key))
+	return hex.EncodeToString(h[:])
+}
+
+func (o *dsk) file(name string) string {
+	return filepath.Join(o.p, name)
+}
+
+func (o *dsk) load() error {
+	var lst, err = os.ReadDir(o.p)
+
+	if err != nil {
+		return err
+	}
+
+	type fil struct {
+		n string
+		s int64
+		t int64
+	}
+
+	var res = make([]fil, 0, len(lst))
+
+	for _, e := range lst {
+		if !e.Type().IsRegular() || len(e.Name()) != sha256.Size*2 {
+			continue
+		} else if i, r := e.Info(); r == nil {
+			res = append(res, fil{n: e.Name(), s: i.Size(), t: i.ModTime().UnixNano()})
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].t < res[j].t
+	})
+
+	for _, f := range res {
+		o.l.Set(f.n, f.s)
+	}
+
+	return nil
+}
+
+func (o *dsk) Load(key string) ([]byte, bool) {
+	var n = o.name(key)
+
+	if _, k := o.l.Get(n); !k {
+		return nil, false
+	} else if b, e := os.ReadFile(o.file(n)); e != nil {
+		o.l.Delete(n)
+		return nil, false
+	} else {
+		return b, true
+	}
+}
+
+func (o *dsk) Store(key string, val []byte) error {
+	var (
+		n = o.name(key)
+		f = o.file(n)
+	)
+
+	t, e := os.CreateTemp(o.p, ".tmp-*")
+
+	if e != nil {
+		return e
+	}
+
+	defer func() {
+		_ = os.Remove(t.Name())
+	}()
+
+	if _, e = t.Write(val); e != nil {
+		_ = t.Close()
+		return e
+	} else if e = t.Close(); e != nil {
+		return e
+	} else if e = os.Rename(t.Name(), f); e != nil {
+		return e
+	}
+
+	o.l.Set(n, int64(len(val)))
+
+	return nil
+}
+
+func (o *dsk) Delete(key string) {
+	var n = o.name(key)
+
+	o.l.Delete(n)
+	_ = os.Remove(o.file(n))
+}
+
+func (o *dsk) Clean() {
+	var lst = make([]string, 0, o.l.Len())
+
+	o.l.Walk(func(key string, _ int64, _ time.Duration) bool {
+		lst = append(lst, key)
+		return true
+	})
+
+	o.l.Clean()
+
+	for _, n := range lst {
+		_ = os.Remove(o.file(n))
+	}
+}
+
+func (o *dsk) Len() int {
+	return o.l.Len()
+}
+
+func (o *dsk) Size() int64 {
+	return o.l.Stats().Size
+}
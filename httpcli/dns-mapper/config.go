@@ -39,6 +39,7 @@ import (
 	cfgcst "github.com/nabbar/golib/config/const"
 	libdur "github.com/nabbar/golib/duration"
 	liberr "github.com/nabbar/golib/errors"
+	libres "github.com/nabbar/golib/network/resolver"
 )
 
 type TransportConfig struct {
@@ -65,6 +66,9 @@ type Config struct {
 	DNSMapper  map[string]string `json:"dns-mapper,omitempty" yaml:"dns-mapper,omitempty" toml:"dns-mapper,omitempty" mapstructure:"dns-mapper,omitempty"`
 	TimerClean libdur.Duration   `json:"timer-clean,omitempty" yaml:"timer-clean,omitempty" toml:"timer-clean,omitempty" mapstructure:"timer-clean,omitempty"`
 	Transport  TransportConfig   `json:"transport,omitempty" yaml:"transport,omitempty" toml:"transport,omitempty" mapstructure:"transport,omitempty"`
+
+	// Resolver define the dns upstreams, timeout and cache used to resolve the hosts. If nil, the system resolver is used.
+	Resolver *libres.Config `json:"resolver,omitempty" yaml:"resolver,omitempty" toml:"resolver,omitempty" mapstructure:"resolver,omitempty"`
 }
 
 func DefaultConfig(indent string) []byte {
@@ -114,6 +118,12 @@ func (o Config) Validate() liberr.Error {
 		}
 	}
 
+	if o.Resolver != nil {
+		if err := o.Resolver.Validate(); err != nil {
+			e.Add(err)
+		}
+	}
+
 	if !e.HasParent() {
 		e = nil
 	}
@@ -28,6 +28,7 @@ package dns_mapper
 
 import (
 	"context"
+	"fmt"
 	"net"
 	"net/http"
 	"sync"
@@ -37,6 +38,7 @@ import (
 	libtls "github.com/nabbar/golib/certificates"
 	tlscas "github.com/nabbar/golib/certificates/ca"
 	libdur "github.com/nabbar/golib/duration"
+	libres "github.com/nabbar/golib/network/resolver"
 )
 
 type FuncMessage func(msg string)
@@ -53,6 +55,11 @@ type DNSMapper interface {
 
 	DialContext(ctx context.Context, network, address string) (net.Conn, error)
 	Transport(cfg TransportConfig) *http.Transport
+
+	// SetResolver define the resolver used by DialContext for the hosts not mapped to an ip address.
+	// A nil resolver restore the system resolver.
+	SetResolver(r libres.Resolver)
+
 	Client(cfg TransportConfig) *http.Client
 
 	DefaultTransport() *http.Transport
@@ -113,6 +120,14 @@ func New(ctx context.Context, cfg *Config, fct libtls.FctRootCACert, msg FuncMes
 		d.Add(edp, adr)
 	}
 
+	if cfg.Resolver != nil {
+		if r, e := libres.New(*cfg.Resolver); e != nil {
+			msg(fmt.Sprintf("cannot create dns resolver: %v", e))
+		} else {
+			d.SetResolver(r)
+		}
+	}
+
 	d.c.Store(cfg)
 	_ = d.DefaultTransport()
 	d.TimeCleaner(ctx, cfg.TimerClean.Time())
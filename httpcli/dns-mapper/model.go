@@ -30,10 +30,12 @@ import (
 	"context"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	libatm "github.com/nabbar/golib/atomic"
 	libtls "github.com/nabbar/golib/certificates"
+	libres "github.com/nabbar/golib/network/resolver"
 )
 
 type dmp struct {
@@ -45,6 +47,7 @@ type dmp struct {
 	x libatm.Value[context.Context]
 	f libtls.FctRootCACert
 	i func(msg string)
+	r atomic.Pointer[libres.Resolver]
 }
 
 func (o *dmp) Close() error {
@@ -37,6 +37,7 @@ import (
 
 	libtls "github.com/nabbar/golib/certificates"
 	libdur "github.com/nabbar/golib/duration"
+	libres "github.com/nabbar/golib/network/resolver"
 )
 
 func (o *dmp) dialer() *net.Dialer {
@@ -63,10 +64,23 @@ func (o *dmp) DialContext(ctx context.Context, network, address string) (net.Con
 	} else {
 		o.Message(fmt.Sprintf("Dialing '%s %s' => '%s %s'", network, address, network, dst))
 		o.CacheSet(address, dst)
+
+		if r := o.r.Load(); r != nil && *r != nil {
+			return (*r).DialContext(d)(ctx, network, dst)
+		}
+
 		return d.DialContext(ctx, network, dst)
 	}
 }
 
+func (o *dmp) SetResolver(r libres.Resolver) {
+	if r == nil {
+		o.r.Store(nil)
+	} else {
+		o.r.Store(&r)
+	}
+}
+
 func (o *dmp) Transport(cfg TransportConfig) *http.Transport {
 	var prx func(*http.Request) (*url.URL, error)
 	if cfg.Proxy == nil {
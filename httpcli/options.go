@@ -36,6 +36,7 @@ import (
 	libtls "github.com/nabbar/golib/certificates"
 	liberr "github.com/nabbar/golib/errors"
 	htcdns "github.com/nabbar/golib/httpcli/dns-mapper"
+	htcpol "github.com/nabbar/golib/httpcli/policy"
 	libptc "github.com/nabbar/golib/network/protocol"
 )
 
@@ -66,6 +67,7 @@ type Options struct {
 	TLS                OptionTLS     `json:"tls" yaml:"tls" toml:"tls" mapstructure:"tls"`
 	ForceIP            OptionForceIP `json:"force_ip" yaml:"force_ip" toml:"force_ip" mapstructure:"force_ip"`
 	Proxy              OptionProxy   `json:"proxy" yaml:"proxy" toml:"proxy" mapstructure:"proxy"`
+	Policy             htcpol.Config `json:"policy" yaml:"policy" toml:"policy" mapstructure:"policy"`
 }
 
 func DefaultConfig(indent string) []byte {
@@ -94,5 +96,5 @@ func (o Options) Validate() liberr.Error {
 }
 
 func (o Options) GetClient(def libtls.TLSConfig, servername string) (*http.Client, liberr.Error) {
-	return GetClient(), nil
+	return o.Policy.Client(GetClient()), nil
 }
@@ -0,0 +1,249 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	libval "github.com/go-playground/validator/v10"
+	cfgcst "github.com/nabbar/golib/config/const"
+	libdur "github.com/nabbar/golib/duration"
+	liberr "github.com/nabbar/golib/errors"
+)
+
+const (
+	// DefaultMaxAttempts is the number of attempts of a request, the first one included, if not defined.
+	DefaultMaxAttempts = 3
+	// DefaultBackoffMin is the delay before the first retry if not defined.
+	DefaultBackoffMin = 100 * time.Millisecond
+	// DefaultBackoffMax is the maximum delay between two attempts if not defined.
+	DefaultBackoffMax = 10 * time.Second
+	// DefaultMultiplier is the factor applied to the delay after each attempt if not defined.
+	DefaultMultiplier = 2.0
+	// DefaultHedgingDelay is the latency after which a hedged request is sent if not defined.
+	DefaultHedgingDelay = 200 * time.Millisecond
+	// DefaultHedgingRequests is the maximum number of concurrent requests, the first one included, if not defined.
+	DefaultHedgingRequests = 2
+)
+
+// DefaultStatus is the list of the response status retried if not defined.
+var DefaultStatus = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryConfig define when and how a failed request is sent again.
+// Only the idempotent methods, or the requests with an Idempotency-Key header, are retried,
+// and only if their body can be replayed (no body or a GetBody function).
+type RetryConfig struct {
+	// Enable activate the retry of the failed requests.
+	Enable bool `json:"enable" yaml:"enable" toml:"enable" mapstructure:"enable"`
+
+	// MaxAttempts is the number of attempts of a request, the first one included. If zero, DefaultMaxAttempts is used.
+	MaxAttempts int `json:"max-attempts,omitempty" yaml:"max-attempts,omitempty" toml:"max-attempts,omitempty" mapstructure:"max-attempts,omitempty" validate:"omitempty,min=1"`
+
+	// Status is the list of the response status retried. If empty, DefaultStatus is used.
+	Status []int `json:"status,omitempty" yaml:"status,omitempty" toml:"status,omitempty" mapstructure:"status,omitempty" validate:"omitempty,dive,min=100,max=599"`
+
+	// NetworkError allow to retry the requests failing with a network error (connection refused or reset, timeout, ...).
+	NetworkError bool `json:"network-error" yaml:"network-error" toml:"network-error" mapstructure:"network-error"`
+
+	// BackoffMin is the delay before the first retry. If zero, DefaultBackoffMin is used.
+	BackoffMin libdur.Duration `json:"backoff-min,omitempty" yaml:"backoff-min,omitempty" toml:"backoff-min,omitempty" mapstructure:"backoff-min,omitempty" validate:"omitempty,min=0"`
+
+	// BackoffMax is the maximum delay between two attempts, including the Retry-After delay given by the server.
+	// If zero, DefaultBackoffMax is used.
+	BackoffMax libdur.Duration `json:"backoff-max,omitempty" yaml:"backoff-max,omitempty" toml:"backoff-max,omitempty" mapstructure:"backoff-max,omitempty" validate:"omitempty,min=0"`
+
+	// Multiplier is the factor applied to the delay after each attempt. If zero, DefaultMultiplier is used.
+	Multiplier float64 `json:"multiplier,omitempty" yaml:"multiplier,omitempty" toml:"multiplier,omitempty" mapstructure:"multiplier,omitempty" validate:"omitempty,gte=1"`
+
+	// Jitter is the ratio, between 0 and 1, of the delay randomly removed from each delay to spread the retries.
+	Jitter float64 `json:"jitter,omitempty" yaml:"jitter,omitempty" toml:"jitter,omitempty" mapstructure:"jitter,omitempty" validate:"omitempty,gte=0,lte=1"`
+}
+
+// HedgingConfig define the hedging of the requests: when the response is not received after a delay,
+// the same request is sent again and the first response received is used, the other requests being cancelled.
+// Only the idempotent methods, or the requests with an Idempotency-Key header, are hedged.
+type HedgingConfig struct {
+	// Enable activate the hedging of the requests.
+	Enable bool `json:"enable" yaml:"enable" toml:"enable" mapstructure:"enable"`
+
+	// Delay is the latency after which a new request is sent. If zero, DefaultHedgingDelay is used.
+	Delay libdur.Duration `json:"delay,omitempty" yaml:"delay,omitempty" toml:"delay,omitempty" mapstructure:"delay,omitempty" validate:"omitempty,min=0"`
+
+	// MaxRequests is the maximum number of concurrent requests, the first one included.
+	// If zero, DefaultHedgingRequests is used.
+	MaxRequests int `json:"max-requests,omitempty" yaml:"max-requests,omitempty" toml:"max-requests,omitempty" mapstructure:"max-requests,omitempty" validate:"omitempty,min=2"`
+}
+
+// Config is the retry and hedging policy of a RoundTripper.
+type Config struct {
+	Retry   RetryConfig   `json:"retry" yaml:"retry" toml:"retry" mapstructure:"retry"`
+	Hedging HedgingConfig `json:"hedging" yaml:"hedging" toml:"hedging" mapstructure:"hedging"`
+
+	// MinBudget is the time which must remain before the deadline of the request context to send a new attempt
+	// or a hedged request, in addition to the delay to wait before it. Without deadline, there is no budget limit.
+	MinBudget libdur.Duration `json:"min-budget,omitempty" yaml:"min-budget,omitempty" toml:"min-budget,omitempty" mapstructure:"min-budget,omitempty" validate:"omitempty,min=0"`
+}
+
+func DefaultConfig(indent string) []byte {
+	var (
+		res = bytes.NewBuffer(make([]byte, 0))
+		def = []byte(`{
+  "retry": {
+    "enable": true,
+    "max-attempts": 3,
+    "status": [429, 502, 503, 504],
+    "network-error": true,
+    "backoff-min": "100ms",
+    "backoff-max": "10s",
+    "multiplier": 2,
+    "jitter": 0.2
+  },
+  "hedging": {
+    "enable": false,
+    "delay": "200ms",
+    "max-requests": 2
+  },
+  "min-budget": "0s"
+}`)
+	)
+	if err := json.Indent(res, def, indent, cfgcst.JSONIndent); err != nil {
+		return def
+	} else {
+		return res.Bytes()
+	}
+}
+
+func (o Config) Validate() liberr.Error {
+	var e = ErrorValidatorError.Error(nil)
+
+	if err := libval.New().Struct(o); err != nil {
+		if er, ok := err.(*libval.InvalidValidationError); ok {
+			e.Add(er)
+		}
+
+		for _, er := range err.(libval.ValidationErrors) {
+			//nolint #goerr113
+			e.Add(fmt.Errorf("config field '%s' is not validated by constraint '%s'", er.Namespace(), er.ActualTag()))
+		}
+	}
+
+	if o.Retry.BackoffMax > 0 && o.Retry.BackoffMin > o.Retry.BackoffMax {
+		//nolint #goerr113
+		e.Add(fmt.Errorf("config field 'Config.Retry.BackoffMin' must be lower than 'Config.Retry.BackoffMax'"))
+	}
+
+	if !e.HasParent() {
+		e = nil
+	}
+
+	return e
+}
+
+// New return a RoundTripper applying this policy to the given RoundTripper, see New.
+func (o Config) New(next http.RoundTripper) http.RoundTripper {
+	return New(o, next)
+}
+
+// Middleware return a function wrapping any RoundTripper with this policy.
+func (o Config) Middleware() func(next http.RoundTripper) http.RoundTripper {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return New(o, next)
+	}
+}
+
+// Client return a copy of the given client with its transport wrapped with this policy.
+// If the client is nil, a new client with the default transport is returned.
+func (o Config) Client(cli *http.Client) *http.Client {
+	var res = &http.Client{}
+
+	if cli != nil {
+		*res = *cli
+	}
+
+	res.Transport = New(o, res.Transport)
+	return res
+}
+
+// normalize return the config with the default values applied.
+func (o Config) normalize() Config {
+	if o.Retry.MaxAttempts < 1 {
+		o.Retry.MaxAttempts = DefaultMaxAttempts
+	}
+
+	if !o.Retry.Enable {
+		o.Retry.MaxAttempts = 1
+	}
+
+	if len(o.Retry.Status) < 1 {
+		o.Retry.Status = DefaultStatus
+	}
+
+	if o.Retry.BackoffMin <= 0 {
+		o.Retry.BackoffMin = libdur.ParseDuration(DefaultBackoffMin)
+	}
+
+	if o.Retry.BackoffMax <= 0 {
+		o.Retry.BackoffMax = libdur.ParseDuration(DefaultBackoffMax)
+	}
+
+	if o.Retry.BackoffMax < o.Retry.BackoffMin {
+		o.Retry.BackoffMax = o.Retry.BackoffMin
+	}
+
+	if o.Retry.Multiplier < 1 {
+		o.Retry.Multiplier = DefaultMultiplier
+	}
+
+	if o.Retry.Jitter < 0 {
+		o.Retry.Jitter = 0
+	} else if o.Retry.Jitter > 1 {
+		o.Retry.Jitter = 1
+	}
+
+	if o.Hedging.Delay <= 0 {
+		o.Hedging.Delay = libdur.ParseDuration(DefaultHedgingDelay)
+	}
+
+	if o.Hedging.MaxRequests < 2 {
+		o.Hedging.MaxRequests = DefaultHedgingRequests
+	}
+
+	if !o.Hedging.Enable {
+		o.Hedging.MaxRequests = 1
+	}
+
+	return o
+}
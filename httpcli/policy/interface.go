@@ -0,0 +1,52 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package policy
+
+import (
+	"net/http"
+)
+
+// HeaderIdempotencyKey is the header allowing to retry and hedge a request with a non idempotent method.
+const HeaderIdempotencyKey = "Idempotency-Key"
+
+// New return a RoundTripper middleware applying the retry and hedging policies of the given config
+// on the given RoundTripper. If next is nil, http.DefaultTransport is used.
+//
+// A request is retried with an exponential backoff and jitter, while the remaining time before the
+// deadline of its context allow it. When the attempts are exhausted, the last response or error is returned.
+// When hedging is enabled, each attempt send a new concurrent request each time the hedging delay elapse
+// without response, the first successful response is used and the other requests are cancelled.
+func New(cfg Config, next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &rtp{
+		n: next,
+		c: cfg.normalize(),
+	}
+}
@@ -0,0 +1,332 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package policy
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// maxDrainBody is the size read from the body of a discarded response to allow the reuse of its connection.
+const maxDrainBody = 4096
+
+type rtp struct {
+	n http.RoundTripper // next round tripper
+	c Config            // normalized config
+}
+
+type result struct {
+	r *http.Response
+	e error
+	i int // index of the request
+}
+
+func (o *rtp) RoundTrip(req *http.Request) (*http.Response, error) {
+	if o.c.Retry.MaxAttempts < 2 && o.c.Hedging.MaxRequests < 2 {
+		return o.n.RoundTrip(req)
+	} else if !isReplayable(req) {
+		return o.n.RoundTrip(req)
+	}
+
+	var (
+		res *http.Response
+		err error
+		ctx = req.Context()
+	)
+
+	for i := 1; ; i++ {
+		if o.c.Hedging.MaxRequests > 1 {
+			res, err = o.hedge(req, i == 1)
+		} else {
+			res, err = o.send(req, ctx, i == 1)
+		}
+
+		if i >= o.c.Retry.MaxAttempts || !o.isRetryable(ctx, res, err) {
+			return res, err
+		}
+
+		var wait = o.backoff(i, res)
+
+		if !o.hasBudget(ctx, wait) {
+			return res, err
+		}
+
+		discard(res)
+
+		if e := sleep(ctx, wait); e != nil {
+			return nil, e
+		}
+	}
+}
+
+// send send one request with the given context, the first request using the original body.
+func (o *rtp) send(req *http.Request, ctx context.Context, first bool) (*http.Response, error) {
+	if first && ctx == req.Context() {
+		return o.n.RoundTrip(req)
+	}
+
+	var r = req.Clone(ctx)
+
+	if !first && req.Body != nil && req.Body != http.NoBody {
+		if b, e := req.GetBody(); e != nil {
+			return nil, e
+		} else {
+			r.Body = b
+		}
+	}
+
+	return o.n.RoundTrip(r)
+}
+
+// hedge send the request, and a new concurrent one each time the hedging delay elapse without a
+// successful response. The first successful response is returned, the other requests are cancelled.
+// If no request succeed, the last response or error is returned.
+func (o *rtp) hedge(req *http.Request, first bool) (*http.Response, error) {
+	var (
+		ctx = req.Context()
+		dly = o.c.Hedging.Delay.Time()
+		chn = make(chan result, o.c.Hedging.MaxRequests)
+		cnl = make([]context.CancelFunc, 0, o.c.Hedging.MaxRequests)
+		tmr = time.NewTimer(dly)
+		lst result
+		rcv int
+	)
+
+	defer tmr.Stop()
+
+	launch := func() {
+		var (
+			x, c = context.WithCancel(ctx)
+			i    = len(cnl)
+		)
+
+		cnl = append(cnl, c)
+
+		go func() {
+			r, e := o.send(req, x, first && i == 0)
+			chn <- result{r: r, e: e, i: i}
+		}()
+	}
+
+	launch()
+
+	for rcv < len(cnl) {
+		select {
+		case <-tmr.C:
+			if len(cnl) < o.c.Hedging.MaxRequests && o.hasBudget(ctx, 0) {
+				launch()
+				tmr.Reset(dly)
+			}
+			continue
+
+		case r := <-chn:
+			if rcv > 0 {
+				discard(lst.r)
+				cnl[lst.i]()
+			}
+
+			rcv++
+			lst = r
+		}
+
+		if lst.e == nil && !o.isRetryStatus(lst.r) {
+			break
+		}
+	}
+
+	// cancel the other requests still running and release their responses
+	for i, c := range cnl {
+		if i != lst.i {
+			c()
+		}
+	}
+
+	go func(n int) {
+		for ; n > 0; n-- {
+			discard((<-chn).r)
+		}
+	}(len(cnl) - rcv)
+
+	if lst.r == nil || lst.r.Body == nil {
+		cnl[lst.i]()
+	} else {
+		lst.r.Body = &cancelBody{ReadCloser: lst.r.Body, c: cnl[lst.i]}
+	}
+
+	return lst.r, lst.e
+}
+
+// cancelBody cancel the context of the request when the body of its response is closed.
+type cancelBody struct {
+	io.ReadCloser
+	c context.CancelFunc
+}
+
+func (o *cancelBody) Close() error {
+	defer o.c()
+	return o.ReadCloser.Close()
+}
+
+// isReplayable return true if the request can be sent again: idempotent method or idempotency key,
+// and no body or a body which can be obtained again.
+func isReplayable(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	} else if len(req.Header.Get(HeaderIdempotencyKey)) > 0 {
+		return true
+	}
+
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+func (o *rtp) isRetryStatus(res *http.Response) bool {
+	if res == nil {
+		return false
+	}
+
+	for _, s := range o.c.Retry.Status {
+		if s == res.StatusCode {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (o *rtp) isRetryable(ctx context.Context, res *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	} else if err != nil {
+		return o.c.Retry.NetworkError && isNetworkError(err)
+	}
+
+	return o.isRetryStatus(res)
+}
+
+func isNetworkError(err error) bool {
+	var n net.Error
+
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return false
+	case errors.As(err, &n):
+		return true
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return true
+	case errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.EPIPE):
+		return true
+	default:
+		return false
+	}
+}
+
+// backoff return the delay to wait after the given attempt: the exponential delay with jitter,
+// or the Retry-After delay of the response if greater, capped with the maximum delay.
+func (o *rtp) backoff(attempt int, res *http.Response) time.Duration {
+	var (
+		min = float64(o.c.Retry.BackoffMin.Time())
+		max = float64(o.c.Retry.BackoffMax.Time())
+		dly = math.Min(min*math.Pow(o.c.Retry.Multiplier, float64(attempt-1)), max)
+	)
+
+	if o.c.Retry.Jitter > 0 {
+		// #nosec
+		/* #nosec */
+		//nolint #nosec
+		dly -= dly * o.c.Retry.Jitter * rand.Float64()
+	}
+
+	if r := retryAfter(res); float64(r) > dly {
+		dly = math.Min(float64(r), max)
+	}
+
+	return time.Duration(dly)
+}
+
+// retryAfter return the delay given by the Retry-After header of the response, as seconds or as http date.
+func retryAfter(res *http.Response) time.Duration {
+	if res == nil {
+		return 0
+	}
+
+	var h = res.Header.Get("Retry-After")
+
+	if len(h) < 1 {
+		return 0
+	} else if s, e := strconv.ParseInt(h, 10, 64); e == nil && s > 0 {
+		return time.Duration(s) * time.Second
+	} else if t, e := http.ParseTime(h); e == nil {
+		return time.Until(t)
+	}
+
+	return 0
+}
+
+// hasBudget return true if the remaining time before the deadline of the context allow to wait the
+// given delay and to send a new request.
+func (o *rtp) hasBudget(ctx context.Context, wait time.Duration) bool {
+	if d, ok := ctx.Deadline(); !ok {
+		return true
+	} else {
+		return time.Until(d) > wait+o.c.MinBudget.Time()
+	}
+}
+
+func sleep(ctx context.Context, dly time.Duration) error {
+	var t = time.NewTimer(dly)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// discard drain and close the body of a response not returned to allow the reuse of its connection.
+func discard(res *http.Response) {
+	if res == nil || res.Body == nil {
+		return
+	}
+
+	_, _ = io.CopyN(io.Discard, res.Body, maxDrainBody)
+	_ = res.Body.Close()
+}
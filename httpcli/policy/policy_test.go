@@ -0,0 +1,237 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package policy_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	libdur "github.com/nabbar/golib/duration"
+	htcpol "github.com/nabbar/golib/httpcli/policy"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type rtFunc func(req *http.Request) (*http.Response, error)
+
+func (f rtFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func retryConfig() htcpol.Config {
+	return htcpol.Config{
+		Retry: htcpol.RetryConfig{
+			Enable:       true,
+			MaxAttempts:  3,
+			NetworkError: true,
+			BackoffMin:   libdur.ParseDuration(5 * time.Millisecond),
+			BackoffMax:   libdur.ParseDuration(20 * time.Millisecond),
+			Jitter:       0.5,
+		},
+	}
+}
+
+var _ = Describe("httpcli/policy", func() {
+	var (
+		cnt atomic.Int32
+		srv *httptest.Server
+		hdl http.HandlerFunc
+	)
+
+	BeforeEach(func() {
+		cnt.Store(0)
+		srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hdl(w, r)
+		}))
+	})
+
+	AfterEach(func() {
+		srv.Close()
+	})
+
+	Context("Config", func() {
+		It("must validate the default config", func() {
+			Expect(htcpol.DefaultConfig("")).ToNot(BeEmpty())
+			Expect(retryConfig().Validate()).To(BeNil())
+		})
+
+		It("must reject invalid values", func() {
+			cfg := retryConfig()
+			cfg.Retry.Jitter = 2
+			cfg.Retry.BackoffMin = libdur.ParseDuration(time.Minute)
+			cfg.Hedging.MaxRequests = 1
+			Expect(cfg.Validate()).ToNot(BeNil())
+		})
+	})
+
+	Context("Retry", func() {
+		It("must retry the retryable status until success", func() {
+			hdl = func(w http.ResponseWriter, r *http.Request) {
+				if cnt.Add(1) < 3 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				_, _ = w.Write([]byte("ok"))
+			}
+
+			res, err := retryConfig().Client(srv.Client()).Get(srv.URL)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.StatusCode).To(Equal(http.StatusOK))
+			Expect(res.Body.Close()).ToNot(HaveOccurred())
+			Expect(cnt.Load()).To(BeEquivalentTo(3))
+		})
+
+		It("must return the last response when the attempts are exhausted", func() {
+			hdl = func(w http.ResponseWriter, r *http.Request) {
+				cnt.Add(1)
+				w.WriteHeader(http.StatusBadGateway)
+			}
+
+			res, err := retryConfig().Client(srv.Client()).Get(srv.URL)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.StatusCode).To(Equal(http.StatusBadGateway))
+			Expect(res.Body.Close()).ToNot(HaveOccurred())
+			Expect(cnt.Load()).To(BeEquivalentTo(3))
+		})
+
+		It("must not retry a non idempotent request without idempotency key", func() {
+			hdl = func(w http.ResponseWriter, r *http.Request) {
+				cnt.Add(1)
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+
+			cli := retryConfig().Client(srv.Client())
+
+			res, err := cli.Post(srv.URL, "text/plain", bytes.NewReader([]byte("body")))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.Body.Close()).ToNot(HaveOccurred())
+			Expect(cnt.Load()).To(BeEquivalentTo(1))
+		})
+
+		It("must replay the body of a request with an idempotency key", func() {
+			var bdy = make([]string, 0)
+
+			hdl = func(w http.ResponseWriter, r *http.Request) {
+				b, _ := io.ReadAll(r.Body)
+				bdy = append(bdy, string(b))
+
+				if cnt.Add(1) < 2 {
+					w.WriteHeader(http.StatusTooManyRequests)
+				}
+			}
+
+			req, err := http.NewRequest(http.MethodPost, srv.URL, bytes.NewReader([]byte("body")))
+			Expect(err).ToNot(HaveOccurred())
+			req.Header.Set(htcpol.HeaderIdempotencyKey, "key")
+
+			res, err := retryConfig().Client(srv.Client()).Do(req)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.StatusCode).To(Equal(http.StatusOK))
+			Expect(res.Body.Close()).ToNot(HaveOccurred())
+			Expect(bdy).To(Equal([]string{"body", "body"}))
+		})
+
+		It("must retry the network errors", func() {
+			var rt = htcpol.New(retryConfig(), rtFunc(func(req *http.Request) (*http.Response, error) {
+				if cnt.Add(1) < 3 {
+					return nil, syscall.ECONNREFUSED
+				}
+				return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+			}))
+
+			req, err := http.NewRequest(http.MethodGet, "http://localhost", nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			res, err := rt.RoundTrip(req)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.StatusCode).To(Equal(http.StatusOK))
+			Expect(cnt.Load()).To(BeEquivalentTo(3))
+		})
+
+		It("must stop retrying when the context deadline leave no budget", func() {
+			hdl = func(w http.ResponseWriter, r *http.Request) {
+				cnt.Add(1)
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+
+			cfg := retryConfig()
+			cfg.Retry.BackoffMin = libdur.ParseDuration(time.Second)
+			cfg.Retry.BackoffMax = libdur.ParseDuration(time.Second)
+
+			x, c := context.WithTimeout(context.Background(), 500*time.Millisecond)
+			defer c()
+
+			req, err := http.NewRequestWithContext(x, http.MethodGet, srv.URL, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			res, err := cfg.Client(srv.Client()).Do(req)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.StatusCode).To(Equal(http.StatusServiceUnavailable))
+			Expect(res.Body.Close()).ToNot(HaveOccurred())
+			Expect(cnt.Load()).To(BeEquivalentTo(1))
+		})
+	})
+
+	Context("Hedging", func() {
+		It("must use the first response of the hedged requests", func() {
+			hdl = func(w http.ResponseWriter, r *http.Request) {
+				if cnt.Add(1) == 1 {
+					select {
+					case <-r.Context().Done():
+					case <-time.After(2 * time.Second):
+					}
+					return
+				}
+				_, _ = w.Write([]byte("fast"))
+			}
+
+			cfg := htcpol.Config{
+				Hedging: htcpol.HedgingConfig{
+					Enable: true,
+					Delay:  libdur.ParseDuration(50 * time.Millisecond),
+				},
+			}
+
+			start := time.Now()
+			res, err := cfg.Client(srv.Client()).Get(srv.URL)
+			Expect(err).ToNot(HaveOccurred())
+
+			b, err := io.ReadAll(res.Body)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(b)).To(Equal("fast"))
+			Expect(res.Body.Close()).ToNot(HaveOccurred())
+			Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+			Expect(cnt.Load()).To(BeEquivalentTo(2))
+		})
+	})
+})
@@ -0,0 +1,121 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package trace
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	prmsdk "github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultNamespace is the prefix of the prometheus metrics if no namespace is given.
+const DefaultNamespace = "httpcli"
+
+const (
+	PhaseDNS       = "dns"
+	PhaseConnect   = "connect"
+	PhaseTLS       = "tls"
+	PhaseFirstByte = "first_byte"
+	PhaseTotal     = "total"
+)
+
+// Timing is the latency breakdown of one round trip, given to the OnDone hook.
+// A phase not done for the request (like DNS or TLS on a reused connection) is zero.
+type Timing struct {
+	// Host is the host with port of the request url.
+	Host string
+	// DNS is the duration of the name resolution.
+	DNS time.Duration
+	// Connect is the duration of the tcp connection.
+	Connect time.Duration
+	// TLS is the duration of the tls handshake.
+	TLS time.Duration
+	// FirstByte is the duration between the start of the round trip and the first byte of the response.
+	FirstByte time.Duration
+	// Total is the duration of the round trip until the response headers are received.
+	Total time.Duration
+	// Reused is true if the request was sent on a connection already opened.
+	Reused bool
+	// WasIdle is true if the reused connection was idle into the pool.
+	WasIdle bool
+	// Status is the status code of the response, zero on error.
+	Status int
+	// Error is the error returned by the round trip.
+	Error error
+}
+
+// Hooks are the optional callbacks called on each phase of a round trip.
+// The callbacks are called from the transport goroutines and must not block.
+type Hooks struct {
+	// OnDNS is called at the end of each name resolution.
+	OnDNS func(host string, dur time.Duration, err error)
+	// OnConnect is called at the end of each tcp connection with the remote address.
+	OnConnect func(host, addr string, dur time.Duration, err error)
+	// OnTLS is called at the end of each tls handshake.
+	OnTLS func(host string, dur time.Duration, err error)
+	// OnFirstByte is called when the first byte of the response is received.
+	OnFirstByte func(host string, dur time.Duration)
+	// OnDone is called at the end of each round trip with its complete timing.
+	OnDone func(t Timing)
+}
+
+// Tracer is a RoundTripper middleware instrumenting the requests with httptrace.
+// It is also a prometheus collector exposing the phase latencies, the requests and the connections by host.
+type Tracer interface {
+	prmsdk.Collector
+
+	// New return a RoundTripper tracing each request sent with the given RoundTripper.
+	// If next is nil, http.DefaultTransport is used.
+	New(next http.RoundTripper) http.RoundTripper
+
+	// Client return a copy of the given client with its transport traced.
+	// If the client is nil, a new client with the default transport is returned.
+	Client(cli *http.Client) *http.Client
+
+	// Stats return the connection pool and latency stats by host since the creation or the last Reset.
+	Stats() map[string]HostStats
+
+	// Reset clear the stats by host. The prometheus metrics are not reset.
+	Reset()
+}
+
+// New return a Tracer calling the given hooks. The prometheus metrics are named with the given
+// namespace, or DefaultNamespace if empty.
+func New(namespace string, hooks Hooks) Tracer {
+	if len(namespace) < 1 {
+		namespace = DefaultNamespace
+	}
+
+	return &trc{
+		m: sync.Mutex{},
+		h: hooks,
+		s: make(map[string]*hostStat),
+		t: time.Now(),
+		p: newMetrics(namespace),
+	}
+}
@@ -0,0 +1,122 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package trace
+
+import (
+	"strconv"
+
+	prmsdk "github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	connNew    = "new"
+	connReused = "reused"
+	connIdle   = "idle"
+	codeError  = "error"
+)
+
+type metrics struct {
+	d *prmsdk.HistogramVec // phase duration by host and phase
+	r *prmsdk.CounterVec   // requests by host and code
+	c *prmsdk.CounterVec   // connections by host and state
+	f *prmsdk.GaugeVec     // requests in flight by host
+}
+
+func newMetrics(namespace string) *metrics {
+	return &metrics{
+		d: prmsdk.NewHistogramVec(prmsdk.HistogramOpts{
+			Namespace: namespace,
+			Name:      "phase_duration_seconds",
+			Help:      "the duration of each phase of the client requests (dns, connect, tls, first_byte, total).",
+			Buckets:   prmsdk.DefBuckets,
+		}, []string{"host", "phase"}),
+		r: prmsdk.NewCounterVec(prmsdk.CounterOpts{
+			Namespace: namespace,
+			Name:      "requests_total",
+			Help:      "the number of client requests by host and status code.",
+		}, []string{"host", "code"}),
+		c: prmsdk.NewCounterVec(prmsdk.CounterOpts{
+			Namespace: namespace,
+			Name:      "connections_total",
+			Help:      "the number of connections used by host, opened (new) or taken from the pool (reused, idle).",
+		}, []string{"host", "state"}),
+		f: prmsdk.NewGaugeVec(prmsdk.GaugeOpts{
+			Namespace: namespace,
+			Name:      "requests_in_flight",
+			Help:      "the number of client requests waiting for their response headers by host.",
+		}, []string{"host"}),
+	}
+}
+
+func (m *metrics) describe(ch chan<- *prmsdk.Desc) {
+	m.d.Describe(ch)
+	m.r.Describe(ch)
+	m.c.Describe(ch)
+	m.f.Describe(ch)
+}
+
+func (m *metrics) collect(ch chan<- prmsdk.Metric) {
+	m.d.Collect(ch)
+	m.r.Collect(ch)
+	m.c.Collect(ch)
+	m.f.Collect(ch)
+}
+
+func (m *metrics) start(host string) {
+	m.f.WithLabelValues(host).Inc()
+}
+
+func (m *metrics) conn(host string, reused, idle bool) {
+	if !reused {
+		m.c.WithLabelValues(host, connNew).Inc()
+	} else if idle {
+		m.c.WithLabelValues(host, connIdle).Inc()
+	} else {
+		m.c.WithLabelValues(host, connReused).Inc()
+	}
+}
+
+func (m *metrics) done(t Timing) {
+	m.f.WithLabelValues(t.Host).Dec()
+
+	if t.Error != nil {
+		m.r.WithLabelValues(t.Host, codeError).Inc()
+	} else {
+		m.r.WithLabelValues(t.Host, strconv.Itoa(t.Status)).Inc()
+	}
+
+	for p, d := range map[string]float64{
+		PhaseDNS:       t.DNS.Seconds(),
+		PhaseConnect:   t.Connect.Seconds(),
+		PhaseTLS:       t.TLS.Seconds(),
+		PhaseFirstByte: t.FirstByte.Seconds(),
+		PhaseTotal:     t.Total.Seconds(),
+	} {
+		if d > 0 {
+			m.d.WithLabelValues(t.Host, p).Observe(d)
+		}
+	}
+}
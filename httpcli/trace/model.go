@@ -0,0 +1,262 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package trace
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	prmsdk "github.com/prometheus/client_golang/prometheus"
+)
+
+type trc struct {
+	m sync.Mutex
+	h Hooks
+	s map[string]*hostStat // stats by host
+	t time.Time            // since
+	p *metrics
+}
+
+type rtp struct {
+	n http.RoundTripper
+	t *trc
+}
+
+func (o *trc) Describe(ch chan<- *prmsdk.Desc) {
+	o.p.describe(ch)
+}
+
+func (o *trc) Collect(ch chan<- prmsdk.Metric) {
+	o.p.collect(ch)
+}
+
+func (o *trc) New(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &rtp{
+		n: next,
+		t: o,
+	}
+}
+
+func (o *trc) Client(cli *http.Client) *http.Client {
+	var res = &http.Client{}
+
+	if cli != nil {
+		*res = *cli
+	}
+
+	res.Transport = o.New(res.Transport)
+	return res
+}
+
+func (o *trc) Stats() map[string]HostStats {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	var res = make(map[string]HostStats, len(o.s))
+
+	for k, v := range o.s {
+		res[k] = v.stats(o.t)
+	}
+
+	return res
+}
+
+func (o *trc) Reset() {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	var s = make(map[string]*hostStat, len(o.s))
+
+	// keep the requests in flight to not count them negative when they end
+	for k, v := range o.s {
+		if v.f > 0 {
+			s[k] = &hostStat{f: v.f}
+		}
+	}
+
+	o.s = s
+	o.t = time.Now()
+}
+
+func (o *trc) host(host string) *hostStat {
+	if s, k := o.s[host]; k {
+		return s
+	}
+
+	var s = &hostStat{}
+	o.s[host] = s
+
+	return s
+}
+
+func (o *trc) start(host string) {
+	o.m.Lock()
+	o.host(host).f++
+	o.m.Unlock()
+
+	o.p.start(host)
+}
+
+func (o *trc) conn(host string, info httptrace.GotConnInfo) {
+	o.m.Lock()
+	o.host(host).conn(info.Reused, info.WasIdle, info.IdleTime)
+	o.m.Unlock()
+
+	o.p.conn(host, info.Reused, info.WasIdle)
+}
+
+func (o *trc) done(t Timing) {
+	o.m.Lock()
+	o.host(t.Host).done(t)
+	o.m.Unlock()
+
+	o.p.done(t)
+
+	if o.h.OnDone != nil {
+		o.h.OnDone(t)
+	}
+}
+
+// req is the timing state of one round trip, its trace callbacks may be called concurrently.
+type req struct {
+	m sync.Mutex
+	o *trc
+	r time.Time // round trip start
+	d time.Time // dns start
+	c time.Time // connect start
+	s time.Time // tls start
+	t Timing
+}
+
+func (o *rtp) RoundTrip(r *http.Request) (*http.Response, error) {
+	var q = &req{
+		o: o.t,
+		r: time.Now(),
+		t: Timing{
+			Host: r.URL.Host,
+		},
+	}
+
+	o.t.start(q.t.Host)
+
+	res, err := o.n.RoundTrip(r.WithContext(httptrace.WithClientTrace(r.Context(), q.trace())))
+
+	q.m.Lock()
+	q.t.Total = time.Since(q.r)
+	q.t.Error = err
+
+	if err == nil && res != nil {
+		q.t.Status = res.StatusCode
+	}
+
+	var t = q.t
+	q.m.Unlock()
+
+	o.t.done(t)
+
+	return res, err
+}
+
+func (q *req) trace() *httptrace.ClientTrace {
+	var h = q.o.h
+
+	return &httptrace.ClientTrace{
+		DNSStart: func(_ httptrace.DNSStartInfo) {
+			q.m.Lock()
+			q.d = time.Now()
+			q.m.Unlock()
+		},
+		DNSDone: func(i httptrace.DNSDoneInfo) {
+			q.m.Lock()
+			var d = time.Since(q.d)
+			q.t.DNS = d
+			q.m.Unlock()
+
+			if h.OnDNS != nil {
+				h.OnDNS(q.t.Host, d, i.Err)
+			}
+		},
+		ConnectStart: func(_, _ string) {
+			q.m.Lock()
+			if q.c.IsZero() {
+				q.c = time.Now()
+			}
+			q.m.Unlock()
+		},
+		ConnectDone: func(_, addr string, err error) {
+			q.m.Lock()
+			var d = time.Since(q.c)
+			if err == nil {
+				q.t.Connect = d
+			}
+			q.m.Unlock()
+
+			if h.OnConnect != nil {
+				h.OnConnect(q.t.Host, addr, d, err)
+			}
+		},
+		TLSHandshakeStart: func() {
+			q.m.Lock()
+			q.s = time.Now()
+			q.m.Unlock()
+		},
+		TLSHandshakeDone: func(_ tls.ConnectionState, err error) {
+			q.m.Lock()
+			var d = time.Since(q.s)
+			q.t.TLS = d
+			q.m.Unlock()
+
+			if h.OnTLS != nil {
+				h.OnTLS(q.t.Host, d, err)
+			}
+		},
+		GotConn: func(i httptrace.GotConnInfo) {
+			q.m.Lock()
+			q.t.Reused = i.Reused
+			q.t.WasIdle = i.WasIdle
+			q.m.Unlock()
+
+			q.o.conn(q.t.Host, i)
+		},
+		GotFirstResponseByte: func() {
+			q.m.Lock()
+			var d = time.Since(q.r)
+			q.t.FirstByte = d
+			q.m.Unlock()
+
+			if h.OnFirstByte != nil {
+				h.OnFirstByte(q.t.Host, d)
+			}
+		},
+	}
+}
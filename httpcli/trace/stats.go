@@ -0,0 +1,144 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package trace
+
+import (
+	"time"
+
+	libdur "github.com/nabbar/golib/duration"
+)
+
+// HostStats is the snapshot of the connection pool usage and of the mean phase latencies of one host.
+type HostStats struct {
+	// Requests is the number of round trips done.
+	Requests uint64 `json:"requests"`
+	// Errors is the number of round trips ended with an error.
+	Errors uint64 `json:"errors"`
+	// InFlight is the number of round trips waiting for their response headers.
+	InFlight int64 `json:"in_flight"`
+	// NewConns is the number of connections opened.
+	NewConns uint64 `json:"new_conns"`
+	// ReusedConns is the number of requests sent on an already opened connection, idle or not.
+	ReusedConns uint64 `json:"reused_conns"`
+	// IdleConns is the number of requests sent on a connection taken idle from the pool.
+	IdleConns uint64 `json:"idle_conns"`
+	// IdleTimeAvg is the mean time spent into the pool by the idle connections reused.
+	IdleTimeAvg libdur.Duration `json:"idle_time_avg"`
+	// DNSAvg is the mean duration of the name resolutions.
+	DNSAvg libdur.Duration `json:"dns_avg"`
+	// ConnectAvg is the mean duration of the tcp connections.
+	ConnectAvg libdur.Duration `json:"connect_avg"`
+	// TLSAvg is the mean duration of the tls handshakes.
+	TLSAvg libdur.Duration `json:"tls_avg"`
+	// FirstByteAvg is the mean duration until the first byte of the responses.
+	FirstByteAvg libdur.Duration `json:"first_byte_avg"`
+	// TotalAvg is the mean duration of the round trips.
+	TotalAvg libdur.Duration `json:"total_avg"`
+	// Since is the time of the creation or of the last reset of the stats.
+	Since time.Time `json:"since"`
+}
+
+type avg struct {
+	n uint64        // count
+	s time.Duration // sum
+}
+
+func (a *avg) add(d time.Duration) {
+	if d > 0 {
+		a.n++
+		a.s += d
+	}
+}
+
+func (a avg) get() libdur.Duration {
+	if a.n < 1 {
+		return 0
+	}
+
+	return libdur.ParseDuration(a.s / time.Duration(a.n))
+}
+
+type hostStat struct {
+	r uint64 // requests
+	e uint64 // errors
+	f int64  // in flight
+	n uint64 // new connections
+	u uint64 // reused connections
+	i uint64 // idle connections
+	w avg    // idle time
+	d avg    // dns
+	c avg    // connect
+	t avg    // tls
+	b avg    // first byte
+	a avg    // total
+}
+
+func (h *hostStat) conn(reused, idle bool, idleTime time.Duration) {
+	if !reused {
+		h.n++
+		return
+	}
+
+	h.u++
+
+	if idle {
+		h.i++
+		h.w.add(idleTime)
+	}
+}
+
+func (h *hostStat) done(t Timing) {
+	h.f--
+	h.r++
+
+	if t.Error != nil {
+		h.e++
+	}
+
+	h.d.add(t.DNS)
+	h.c.add(t.Connect)
+	h.t.add(t.TLS)
+	h.b.add(t.FirstByte)
+	h.a.add(t.Total)
+}
+
+func (h *hostStat) stats(since time.Time) HostStats {
+	return HostStats{
+		Requests:     h.r,
+		Errors:       h.e,
+		InFlight:     h.f,
+		NewConns:     h.n,
+		ReusedConns:  h.u,
+		IdleConns:    h.i,
+		IdleTimeAvg:  h.w.get(),
+		DNSAvg:       h.d.get(),
+		ConnectAvg:   h.c.get(),
+		TLSAvg:       h.t.get(),
+		FirstByteAvg: h.b.get(),
+		TotalAvg:     h.a.get(),
+		Since:        since,
+	}
+}
@@ -0,0 +1,163 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package trace_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	htctrc "github.com/nabbar/golib/httpcli/trace"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	prmsdk "github.com/prometheus/client_golang/prometheus"
+)
+
+func get(cli *http.Client, uri string) {
+	res, err := cli.Get(uri)
+	Expect(err).ToNot(HaveOccurred())
+	_, _ = io.Copy(io.Discard, res.Body)
+	Expect(res.Body.Close()).ToNot(HaveOccurred())
+}
+
+var _ = Describe("httpcli/trace", func() {
+	var (
+		srv *httptest.Server
+		hst string
+	)
+
+	BeforeEach(func() {
+		srv = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(5 * time.Millisecond)
+			_, _ = w.Write([]byte("ok"))
+		}))
+
+		u, e := url.Parse(srv.URL)
+		Expect(e).ToNot(HaveOccurred())
+		hst = u.Host
+	})
+
+	AfterEach(func() {
+		srv.Close()
+	})
+
+	It("must call the hooks with the phase latencies", func() {
+		var (
+			con atomic.Int32
+			hsk atomic.Int32
+			fbt atomic.Int32
+			don atomic.Int32
+		)
+
+		trc := htctrc.New("", htctrc.Hooks{
+			OnConnect: func(host, addr string, dur time.Duration, err error) {
+				con.Add(1)
+			},
+			OnTLS: func(host string, dur time.Duration, err error) {
+				Expect(err).ToNot(HaveOccurred())
+				hsk.Add(1)
+			},
+			OnFirstByte: func(host string, dur time.Duration) {
+				fbt.Add(1)
+			},
+			OnDone: func(t htctrc.Timing) {
+				Expect(t.Host).To(Equal(hst))
+				Expect(t.Status).To(Equal(http.StatusOK))
+				Expect(t.FirstByte).To(BeNumerically(">=", 5*time.Millisecond))
+				Expect(t.Total).To(BeNumerically(">=", t.FirstByte))
+				don.Add(1)
+			},
+		})
+
+		cli := trc.Client(srv.Client())
+		get(cli, srv.URL)
+		get(cli, srv.URL)
+
+		Expect(con.Load()).To(BeEquivalentTo(1))
+		Expect(hsk.Load()).To(BeEquivalentTo(1))
+		Expect(fbt.Load()).To(BeEquivalentTo(2))
+		Expect(don.Load()).To(BeEquivalentTo(2))
+	})
+
+	It("must count the connections of the pool by host", func() {
+		trc := htctrc.New("", htctrc.Hooks{})
+		cli := trc.Client(srv.Client())
+
+		for i := 0; i < 3; i++ {
+			get(cli, srv.URL)
+		}
+
+		sts := trc.Stats()
+		Expect(sts).To(HaveKey(hst))
+		Expect(sts[hst].Requests).To(BeEquivalentTo(3))
+		Expect(sts[hst].Errors).To(BeZero())
+		Expect(sts[hst].InFlight).To(BeZero())
+		Expect(sts[hst].NewConns).To(BeEquivalentTo(1))
+		Expect(sts[hst].ReusedConns).To(BeEquivalentTo(2))
+		Expect(sts[hst].IdleConns).To(BeEquivalentTo(2))
+		Expect(sts[hst].TLSAvg).To(BeNumerically(">", 0))
+		Expect(sts[hst].TotalAvg).To(BeNumerically(">=", 5*time.Millisecond))
+
+		trc.Reset()
+		Expect(trc.Stats()).To(BeEmpty())
+	})
+
+	It("must count the errors", func() {
+		trc := htctrc.New("", htctrc.Hooks{})
+		uri := srv.URL
+		srv.Close()
+
+		_, err := trc.Client(nil).Get(uri)
+		Expect(err).To(HaveOccurred())
+		Expect(trc.Stats()[hst].Errors).To(BeEquivalentTo(1))
+	})
+
+	It("must expose the prometheus metrics", func() {
+		trc := htctrc.New("test", htctrc.Hooks{})
+		reg := prmsdk.NewRegistry()
+		Expect(reg.Register(trc)).ToNot(HaveOccurred())
+
+		get(trc.Client(srv.Client()), srv.URL)
+
+		mfs, err := reg.Gather()
+		Expect(err).ToNot(HaveOccurred())
+
+		var names = make([]string, 0)
+		for _, m := range mfs {
+			names = append(names, m.GetName())
+		}
+
+		Expect(names).To(ContainElements(
+			"test_phase_duration_seconds",
+			"test_requests_total",
+			"test_connections_total",
+			"test_requests_in_flight",
+		))
+	})
+})
@@ -0,0 +1,157 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+type ctxKey uint8
+
+const ctxKeyClaims ctxKey = iota
+
+// Claims is the verified payload of a token.
+type Claims map[string]any
+
+func (c Claims) Get(key string) (any, bool) {
+	v, k := c[key]
+	return v, k
+}
+
+func (c Claims) GetString(key string) string {
+	if v, k := c[key].(string); k {
+		return v
+	}
+
+	return ""
+}
+
+func (c Claims) Issuer() string {
+	return c.GetString("iss")
+}
+
+func (c Claims) Subject() string {
+	return c.GetString("sub")
+}
+
+// Audience return the aud claim, given as a string or a list of strings.
+func (c Claims) Audience() []string {
+	return c.getStrings("aud")
+}
+
+// Scopes return the scopes of the token, given by the scope claim as a space separated string,
+// or by the scp claim as a string or a list of strings.
+func (c Claims) Scopes() []string {
+	if s := c.GetString("scope"); len(s) > 0 {
+		return strings.Fields(s)
+	} else if v, k := c["scp"].(string); k {
+		return strings.Fields(v)
+	}
+
+	return c.getStrings("scp")
+}
+
+func (c Claims) HasScope(scope string) bool {
+	for _, s := range c.Scopes() {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c Claims) ExpiresAt() time.Time {
+	return c.getTime("exp")
+}
+
+func (c Claims) NotBefore() time.Time {
+	return c.getTime("nbf")
+}
+
+func (c Claims) IssuedAt() time.Time {
+	return c.getTime("iat")
+}
+
+func (c Claims) getStrings(key string) []string {
+	switch v := c[key].(type) {
+	case string:
+		return []string{v}
+	case []any:
+		var res = make([]string, 0, len(v))
+
+		for _, i := range v {
+			if s, k := i.(string); k {
+				res = append(res, s)
+			}
+		}
+
+		return res
+	default:
+		return nil
+	}
+}
+
+func (c Claims) getTime(key string) time.Time {
+	var f float64
+
+	switch v := c[key].(type) {
+	case json.Number:
+		if n, e := v.Float64(); e != nil {
+			return time.Time{}
+		} else {
+			f = n
+		}
+	case float64:
+		f = v
+	default:
+		return time.Time{}
+	}
+
+	return time.Unix(int64(f), int64((f-float64(int64(f)))*float64(time.Second)))
+}
+
+// WithClaims return a copy of the given context holding the given claims.
+func WithClaims(ctx context.Context, c Claims) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return context.WithValue(ctx, ctxKeyClaims, c)
+}
+
+// FromContext return the claims stored into the given context by the auth handler.
+func FromContext(ctx context.Context) (Claims, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+
+	c, k := ctx.Value(ctxKeyClaims).(Claims)
+	return c, k
+}
@@ -0,0 +1,172 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package auth
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	libval "github.com/go-playground/validator/v10"
+	libtls "github.com/nabbar/golib/certificates"
+	libdur "github.com/nabbar/golib/duration"
+)
+
+const (
+	// DefaultCacheTTL is the default lifetime of a fetched json web key set.
+	DefaultCacheTTL = time.Hour
+	// DefaultRefreshMin is the default minimal interval between two fetches triggered by an unknown key id.
+	DefaultRefreshMin = 30 * time.Second
+	// DefaultTimeout is the default timeout to fetch the discovery document or the json web key set.
+	DefaultTimeout = 10 * time.Second
+)
+
+// wellKnownOIDC is the path of the OpenID Connect discovery document, relative to the issuer.
+const wellKnownOIDC = "/.well-known/openid-configuration"
+
+type ProviderConfig struct {
+	// Issuer is the expected value of the iss claim. If JWKSURL is empty, the issuer must be an url
+	// and the json web key set url is discovered from the OpenID Connect discovery document.
+	Issuer string `mapstructure:"issuer" json:"issuer" yaml:"issuer" toml:"issuer" validate:"required"`
+
+	// JWKSURL is the url of the json web key set of the issuer.
+	JWKSURL string `mapstructure:"jwks_url" json:"jwks_url" yaml:"jwks_url" toml:"jwks_url" validate:"omitempty,url"`
+
+	// Audience is the list of accepted values of the aud claim. The token must contain at least
+	// one of them. Not checked if empty.
+	Audience []string `mapstructure:"audience" json:"audience" yaml:"audience" toml:"audience"`
+}
+
+type Config struct {
+	// Providers is the list of trusted token issuers. The authentication is disabled if empty.
+	Providers []ProviderConfig `mapstructure:"providers" json:"providers" yaml:"providers" toml:"providers" validate:"dive"`
+
+	// Scopes is the list of scopes required in the scope or scp claim of each token.
+	Scopes []string `mapstructure:"scopes" json:"scopes" yaml:"scopes" toml:"scopes"`
+
+	// Algorithms restrict the accepted signing algorithms. All supported asymmetric algorithms
+	// (RS*, PS*, ES* and EdDSA) are accepted if empty.
+	Algorithms []string `mapstructure:"algorithms" json:"algorithms" yaml:"algorithms" toml:"algorithms" validate:"dive,oneof=RS256 RS384 RS512 PS256 PS384 PS512 ES256 ES384 ES512 EdDSA"`
+
+	// Leeway is the clock skew tolerated on the exp, nbf and iat claims.
+	Leeway libdur.Duration `mapstructure:"leeway" json:"leeway" yaml:"leeway" toml:"leeway"`
+
+	// CacheTTL is the lifetime of a fetched json web key set. Default is 1 hour.
+	CacheTTL libdur.Duration `mapstructure:"cache_ttl" json:"cache_ttl" yaml:"cache_ttl" toml:"cache_ttl"`
+
+	// RefreshMin is the minimal interval between two fetches of a json web key set triggered by
+	// a token signed with an unknown key (key rotation). Default is 30 seconds.
+	RefreshMin libdur.Duration `mapstructure:"refresh_min" json:"refresh_min" yaml:"refresh_min" toml:"refresh_min"`
+
+	// Timeout is the timeout to fetch the discovery document or the json web key set. Default is 10 seconds.
+	Timeout libdur.Duration `mapstructure:"timeout" json:"timeout" yaml:"timeout" toml:"timeout"`
+
+	// TLS is the optional tls config used to fetch the discovery document and the json web key set.
+	// The default http client is used if nil.
+	TLS *libtls.Config `mapstructure:"tls" json:"tls,omitempty" yaml:"tls,omitempty" toml:"tls,omitempty"`
+
+	// ExcludePaths is the list of path prefixes not requiring authentication (e.g. health checks).
+	ExcludePaths []string `mapstructure:"exclude_paths" json:"exclude_paths" yaml:"exclude_paths" toml:"exclude_paths"`
+}
+
+// IsEnabled return true if at least one provider is defined.
+func (c Config) IsEnabled() bool {
+	return len(c.Providers) > 0
+}
+
+func (c Config) Clone() Config {
+	var r = c
+
+	r.Providers = make([]ProviderConfig, 0, len(c.Providers))
+
+	for _, p := range c.Providers {
+		p.Audience = append(make([]string, 0, len(p.Audience)), p.Audience...)
+		r.Providers = append(r.Providers, p)
+	}
+
+	r.Scopes = append(make([]string, 0, len(c.Scopes)), c.Scopes...)
+	r.Algorithms = append(make([]string, 0, len(c.Algorithms)), c.Algorithms...)
+	r.ExcludePaths = append(make([]string, 0, len(c.ExcludePaths)), c.ExcludePaths...)
+
+	if c.TLS != nil {
+		var t = *c.TLS
+		r.TLS = &t
+	}
+
+	return r
+}
+
+func (c Config) Validate() error {
+	if er := libval.New().Struct(c); er != nil {
+		if e, ok := er.(*libval.InvalidValidationError); ok {
+			return e
+		}
+
+		for _, e := range er.(libval.ValidationErrors) {
+			//nolint goerr113
+			return fmt.Errorf("config field '%s' is not validated by constraint '%s'", e.Namespace(), e.ActualTag())
+		}
+	}
+
+	for _, p := range c.Providers {
+		if len(p.JWKSURL) > 0 {
+			continue
+		} else if u, e := url.Parse(p.Issuer); e != nil || (u.Scheme != "https" && u.Scheme != "http") || len(u.Host) < 1 {
+			return fmt.Errorf("%w: '%s' is not an url and no jwks url is defined", ErrInvalidIssuer, p.Issuer)
+		}
+	}
+
+	return nil
+}
+
+func (c Config) getCacheTTL() time.Duration {
+	if c.CacheTTL > 0 {
+		return c.CacheTTL.Time()
+	}
+
+	return DefaultCacheTTL
+}
+
+func (c Config) getRefreshMin() time.Duration {
+	if c.RefreshMin > 0 {
+		return c.RefreshMin.Time()
+	}
+
+	return DefaultRefreshMin
+}
+
+func (c Config) getTimeout() time.Duration {
+	if c.Timeout > 0 {
+		return c.Timeout.Time()
+	}
+
+	return DefaultTimeout
+}
+
+func (p ProviderConfig) discoveryURL() string {
+	return strings.TrimSuffix(p.Issuer, "/") + wellKnownOIDC
+}
@@ -0,0 +1,42 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package auth
+
+import "errors"
+
+var (
+	ErrInvalidInstance      = errors.New("invalid instance")
+	ErrInvalidIssuer        = errors.New("invalid or unknown token issuer")
+	ErrMissingToken         = errors.New("missing bearer token")
+	ErrInvalidToken         = errors.New("malformed token")
+	ErrUnsupportedAlgorithm = errors.New("unsupported token signing algorithm")
+	ErrInvalidSignature     = errors.New("invalid token signature")
+	ErrUnknownKey           = errors.New("no matching key found for token")
+	ErrExpiredToken         = errors.New("token is expired or not yet valid")
+	ErrInvalidAudience      = errors.New("invalid token audience")
+	ErrMissingScope         = errors.New("token is missing a required scope")
+	ErrJWKSFetch            = errors.New("cannot fetch json web key set")
+)
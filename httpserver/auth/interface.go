@@ -0,0 +1,92 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package auth
+
+import (
+	"context"
+	"net/http"
+
+	libcch "github.com/nabbar/golib/cache"
+	libhtc "github.com/nabbar/golib/httpcli"
+	htcdns "github.com/nabbar/golib/httpcli/dns-mapper"
+)
+
+type Auth interface {
+	// Handler return the given handler requiring a valid bearer token on each request, except for the
+	// excluded paths. The verified claims are stored into the request context (see FromContext).
+	// An invalid token is rejected with a 401 Unauthorized response, a token missing a required scope
+	// with a 403 Forbidden response, both with a WWW-Authenticate header.
+	Handler(next http.Handler) http.Handler
+
+	// Verify parse and verify the given raw token and return its claims.
+	Verify(ctx context.Context, raw string) (Claims, error)
+}
+
+// New return an Auth verifying the tokens of the configured providers. The json web key sets are
+// fetched with the given client, or if nil with a client of the default httpcli dns mapper using
+// the tls config of the given config.
+func New(cfg Config, cli libhtc.HttpClient) (Auth, error) {
+	if e := cfg.Validate(); e != nil {
+		return nil, e
+	}
+
+	if cli == nil {
+		if cfg.TLS != nil {
+			cli = libhtc.DefaultDNSMapper().Client(htcdns.TransportConfig{
+				TLSConfig: cfg.TLS,
+			})
+		} else {
+			cli = libhtc.GetClient()
+		}
+	}
+
+	var res = &ath{
+		c: cfg.Clone(),
+		a: make(map[string]bool),
+		p: make(map[string]*keySet, len(cfg.Providers)),
+	}
+
+	if len(cfg.Algorithms) > 0 {
+		for _, a := range cfg.Algorithms {
+			res.a[a] = true
+		}
+	} else {
+		for _, a := range Algorithms {
+			res.a[a] = true
+		}
+	}
+
+	var cch = libcch.NewTyped[string, map[string]pubKey](libcch.Options{
+		MaxEntries: len(cfg.Providers),
+		Policy:     libcch.PolicyLRU,
+	})
+
+	for _, p := range cfg.Providers {
+		res.p[p.Issuer] = newKeySet(cli, p, cch)
+	}
+
+	return res, nil
+}
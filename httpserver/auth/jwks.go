@@ -0,0 +1,326 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	libcch "github.com/nabbar/golib/cache"
+	libhtc "github.com/nabbar/golib/httpcli"
+)
+
+// maxDocumentSize is the maximum size of a discovery document or a json web key set.
+const maxDocumentSize = 1 << 20
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type pubKey struct {
+	a string           // algorithm, if defined into the key
+	k crypto.PublicKey // public key
+}
+
+// keySet is the json web key set of a provider, cached by issuer.
+type keySet struct {
+	m sync.Mutex
+	c libhtc.HttpClient
+	p ProviderConfig
+	k libcch.Typed[string, map[string]pubKey] // key sets by issuer, shared by the providers
+	u string                                  // jwks url, discovered if not defined into the config
+	s map[string]pubKey                       // keys of the last successful fetch
+	e error                                   // error of the last fetch attempt
+	t time.Time                               // time of last fetch attempt
+}
+
+func newKeySet(cli libhtc.HttpClient, p ProviderConfig, cch libcch.Typed[string, map[string]pubKey]) *keySet {
+	return &keySet{
+		m: sync.Mutex{},
+		c: cli,
+		p: p,
+		k: cch,
+		u: p.JWKSURL,
+	}
+}
+
+// keys return the key matching the given key id, or all keys if kid is empty. The set is fetched
+// if expired, or if the key id is unknown and the last fetch is older than the refresh minimal interval.
+func (o *keySet) keys(ctx context.Context, kid string, ttl, min, tmo time.Duration) ([]pubKey, error) {
+	var set, ok = o.k.Get(o.p.Issuer)
+
+	if _, k := set[kid]; ok && len(kid) > 0 && !k && o.retry(min) {
+		// unknown key id, the key set may have been rotated
+		o.k.Delete(o.p.Issuer)
+		ok = false
+	}
+
+	if !ok {
+		var err error
+
+		set, err = o.k.GetOrLoad(ctx, o.p.Issuer, func(ctx context.Context, _ string) (map[string]pubKey, time.Duration, error) {
+			return o.load(ctx, ttl, min, tmo)
+		})
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(set) < 1 {
+		if e := o.err(); e != nil {
+			return nil, e
+		}
+		return nil, ErrUnknownKey
+	}
+
+	if len(kid) > 0 {
+		if k, ok := set[kid]; ok {
+			return []pubKey{k}, nil
+		}
+		return nil, ErrUnknownKey
+	}
+
+	var res = make([]pubKey, 0, len(set))
+
+	for _, k := range set {
+		res = append(res, k)
+	}
+
+	return res, nil
+}
+
+// load fetch the json web key set to cache. On fetch error, the stale keys of the last successful
+// fetch are cached instead, or an empty set if none, until a new attempt after the refresh minimal interval.
+func (o *keySet) load(ctx context.Context, ttl, min, tmo time.Duration) (map[string]pubKey, time.Duration, error) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	res, err := o.fetch(ctx, tmo)
+	o.e = err
+
+	if err != nil {
+		return o.s, min, nil
+	}
+
+	o.s = res
+
+	return res, ttl, nil
+}
+
+// retry return true and reset the time of last fetch attempt if older than the refresh minimal interval.
+func (o *keySet) retry(min time.Duration) bool {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if time.Since(o.t) <= min {
+		return false
+	}
+
+	o.t = time.Now()
+
+	return true
+}
+
+func (o *keySet) err() error {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	return o.e
+}
+
+// fetch load the json web key set. Must be called with the lock held.
+func (o *keySet) fetch(ctx context.Context, tmo time.Duration) (map[string]pubKey, error) {
+	o.t = time.Now()
+
+	var x, n = context.WithTimeout(ctx, tmo)
+	defer n()
+
+	if len(o.u) < 1 {
+		var doc = struct {
+			Issuer  string `json:"issuer"`
+			JWKSURI string `json:"jwks_uri"`
+		}{}
+
+		if e := o.get(x, o.p.discoveryURL(), &doc); e != nil {
+			return nil, e
+		} else if len(doc.JWKSURI) < 1 {
+			return nil, fmt.Errorf("%w: no jwks_uri into discovery document of '%s'", ErrJWKSFetch, o.p.Issuer)
+		} else if len(doc.Issuer) > 0 && doc.Issuer != o.p.Issuer {
+			return nil, fmt.Errorf("%w: discovery document issuer '%s' mismatch", ErrInvalidIssuer, doc.Issuer)
+		}
+
+		o.u = doc.JWKSURI
+	}
+
+	var set = struct {
+		Keys []jwk `json:"keys"`
+	}{}
+
+	if e := o.get(x, o.u, &set); e != nil {
+		return nil, e
+	}
+
+	var res = make(map[string]pubKey, len(set.Keys))
+
+	for _, k := range set.Keys {
+		if len(k.Use) > 0 && k.Use != "sig" {
+			continue
+		} else if p, e := k.publicKey(); e != nil {
+			continue
+		} else {
+			res[k.Kid] = pubKey{a: k.Alg, k: p}
+		}
+	}
+
+	if len(res) < 1 {
+		return nil, fmt.Errorf("%w: no usable key into '%s'", ErrJWKSFetch, o.u)
+	}
+
+	return res, nil
+}
+
+func (o *keySet) get(ctx context.Context, uri string, model any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrJWKSFetch, err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	rsp, err := o.c.Do(req)
+
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrJWKSFetch, err)
+	}
+
+	defer func() {
+		_ = rsp.Body.Close()
+	}()
+
+	if rsp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: '%s' returned status %d", ErrJWKSFetch, uri, rsp.StatusCode)
+	} else if err = json.NewDecoder(io.LimitReader(rsp.Body, maxDocumentSize)).Decode(model); err != nil {
+		return fmt.Errorf("%w: %v", ErrJWKSFetch, err)
+	}
+
+	return nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, e := decodeBigInt(k.N)
+
+		if e != nil {
+			return nil, e
+		}
+
+		x, e := decodeBigInt(k.E)
+
+		if e != nil {
+			return nil, e
+		} else if !x.IsInt64() || x.Int64() < 3 || x.Int64() > 1<<31-1 {
+			return nil, ErrUnknownKey
+		}
+
+		return &rsa.PublicKey{N: n, E: int(x.Int64())}, nil
+
+	case "EC":
+		var crv elliptic.Curve
+
+		switch k.Crv {
+		case "P-256":
+			crv = elliptic.P256()
+		case "P-384":
+			crv = elliptic.P384()
+		case "P-521":
+			crv = elliptic.P521()
+		default:
+			return nil, ErrUnknownKey
+		}
+
+		x, e := decodeBigInt(k.X)
+
+		if e != nil {
+			return nil, e
+		}
+
+		y, e := decodeBigInt(k.Y)
+
+		if e != nil {
+			return nil, e
+		} else if !crv.IsOnCurve(x, y) {
+			return nil, ErrUnknownKey
+		}
+
+		return &ecdsa.PublicKey{Curve: crv, X: x, Y: y}, nil
+
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, ErrUnknownKey
+		} else if b, e := base64.RawURLEncoding.DecodeString(k.X); e != nil {
+			return nil, e
+		} else if len(b) != ed25519.PublicKeySize {
+			return nil, ErrUnknownKey
+		} else {
+			return ed25519.PublicKey(b), nil
+		}
+	}
+
+	return nil, ErrUnknownKey
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	if b, e := base64.RawURLEncoding.DecodeString(s); e != nil {
+		return nil, e
+	} else if len(b) < 1 {
+		return nil, ErrUnknownKey
+	} else {
+		return new(big.Int).SetBytes(b), nil
+	}
+}
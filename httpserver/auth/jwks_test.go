@@ -0,0 +1,353 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package auth_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	libdur "github.com/nabbar/golib/duration"
+	htpaut "github.com/nabbar/golib/httpserver/auth"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var (
+	rsaKey1 = newRSAKey()
+	rsaKey2 = newRSAKey()
+	ecKey1  = newECKey()
+)
+
+func newRSAKey() *rsa.PrivateKey {
+	k, e := rsa.GenerateKey(rand.Reader, 2048)
+	if e != nil {
+		panic(e)
+	}
+	return k
+}
+
+func newECKey() *ecdsa.PrivateKey {
+	k, e := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if e != nil {
+		panic(e)
+	}
+	return k
+}
+
+func b64(p []byte) string {
+	return base64.RawURLEncoding.EncodeToString(p)
+}
+
+func rsaJWK(kid, alg string, k *rsa.PublicKey) map[string]any {
+	var res = map[string]any{
+		"kty": "RSA",
+		"kid": kid,
+		"use": "sig",
+		"n":   b64(k.N.Bytes()),
+		"e":   b64(big.NewInt(int64(k.E)).Bytes()),
+	}
+
+	if len(alg) > 0 {
+		res["alg"] = alg
+	}
+
+	return res
+}
+
+func ecJWK(kid string, k *ecdsa.PublicKey) map[string]any {
+	return map[string]any{
+		"kty": "EC",
+		"kid": kid,
+		"use": "sig",
+		"crv": "P-256",
+		"x":   b64(k.X.FillBytes(make([]byte, 32))),
+		"y":   b64(k.Y.FillBytes(make([]byte, 32))),
+	}
+}
+
+// signToken return a compact token with the given header fields and claims, signed with the given key:
+// a rsa key (RS256 or PS256), an ecdsa key (ES256), a secret for HS256 or nil for an empty signature.
+func signToken(hdr map[string]any, clm map[string]any, key any) string {
+	p, err := json.Marshal(hdr)
+	Expect(err).ToNot(HaveOccurred())
+
+	var in = b64(p)
+
+	p, err = json.Marshal(clm)
+	Expect(err).ToNot(HaveOccurred())
+
+	in += "." + b64(p)
+
+	var (
+		sum = sha256.Sum256([]byte(in))
+		sig []byte
+	)
+
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		if hdr["alg"] == "PS256" {
+			sig, err = rsa.SignPSS(rand.Reader, k, crypto.SHA256, sum[:], &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		} else {
+			sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, sum[:])
+		}
+		Expect(err).ToNot(HaveOccurred())
+	case *ecdsa.PrivateKey:
+		r, s, e := ecdsa.Sign(rand.Reader, k, sum[:])
+		Expect(e).ToNot(HaveOccurred())
+		sig = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	case []byte:
+		m := hmac.New(sha256.New, k)
+		_, _ = m.Write([]byte(in))
+		sig = m.Sum(nil)
+	}
+
+	return in + "." + b64(sig)
+}
+
+// newClaims return valid claims of the given issuer, updated with the given values: a nil value remove the claim.
+func newClaims(iss string, upd map[string]any) map[string]any {
+	var res = map[string]any{
+		"iss":   iss,
+		"sub":   "user",
+		"aud":   "api",
+		"scope": "read",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"iat":   time.Now().Unix(),
+	}
+
+	for k, v := range upd {
+		if v == nil {
+			delete(res, k)
+		} else {
+			res[k] = v
+		}
+	}
+
+	return res
+}
+
+func rs256(kid string, key *rsa.PrivateKey, clm map[string]any) string {
+	return signToken(map[string]any{"alg": "RS256", "typ": "JWT", "kid": kid}, clm, key)
+}
+
+// keyServer is an OpenID Connect provider serving its discovery document and its json web key set.
+type keyServer struct {
+	m sync.Mutex
+	s *httptest.Server
+	k []map[string]any // keys
+	f *atomic.Int64    // number of key set requests
+	e *atomic.Bool     // key set requests fail
+}
+
+func newKeyServer(keys ...map[string]any) *keyServer {
+	var o = &keyServer{
+		k: keys,
+		f: new(atomic.Int64),
+		e: new(atomic.Bool),
+	}
+
+	var mux = http.NewServeMux()
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   o.URL(),
+			"jwks_uri": o.URL() + "/jwks",
+		})
+	})
+
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		o.f.Add(1)
+
+		if o.e.Load() {
+			http.Error(w, "unavailable", http.StatusInternalServerError)
+			return
+		}
+
+		o.m.Lock()
+		defer o.m.Unlock()
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": o.k})
+	})
+
+	o.s = httptest.NewServer(mux)
+
+	return o
+}
+
+func (o *keyServer) URL() string {
+	return o.s.URL
+}
+
+func (o *keyServer) SetKeys(keys ...map[string]any) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.k = keys
+}
+
+func (o *keyServer) Fetches() int64 {
+	return o.f.Load()
+}
+
+func (o *keyServer) Fail(b bool) {
+	o.e.Store(b)
+}
+
+func (o *keyServer) Close() {
+	o.s.Close()
+}
+
+func (o *keyServer) Auth(cfg htpaut.Config) htpaut.Auth {
+	if len(cfg.Providers) < 1 {
+		cfg.Providers = []htpaut.ProviderConfig{{Issuer: o.URL()}}
+	}
+
+	a, err := htpaut.New(cfg, o.s.Client())
+	Expect(err).ToNot(HaveOccurred())
+
+	return a
+}
+
+var _ = Describe("httpserver/auth json web key set", func() {
+	var ks *keyServer
+
+	BeforeEach(func() {
+		ks = newKeyServer(rsaJWK("kid1", "", &rsaKey1.PublicKey))
+	})
+
+	AfterEach(func() {
+		ks.Close()
+	})
+
+	It("must discover the key set url and cache the key set", func() {
+		var a = ks.Auth(htpaut.Config{})
+
+		for i := 0; i < 3; i++ {
+			c, err := a.Verify(ctx, rs256("kid1", rsaKey1, newClaims(ks.URL(), nil)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(c.Subject()).To(Equal("user"))
+		}
+
+		Expect(ks.Fetches()).To(BeNumerically("==", 1))
+	})
+
+	It("must use the configured key set url and all keys for a token without key id", func() {
+		ks.SetKeys(ecJWK("ec1", &ecKey1.PublicKey), rsaJWK("kid1", "", &rsaKey1.PublicKey))
+
+		var a = ks.Auth(htpaut.Config{
+			Providers: []htpaut.ProviderConfig{{Issuer: "issuer", JWKSURL: ks.URL() + "/jwks"}},
+		})
+
+		_, err := a.Verify(ctx, signToken(map[string]any{"alg": "RS256"}, newClaims("issuer", nil), rsaKey1))
+		Expect(err).ToNot(HaveOccurred())
+
+		// the error depends of the last key tried: the ecdsa key does not match the algorithm
+		_, err = a.Verify(ctx, signToken(map[string]any{"alg": "RS256"}, newClaims("issuer", nil), rsaKey2))
+		Expect(errors.Is(err, htpaut.ErrInvalidSignature) || errors.Is(err, htpaut.ErrUnknownKey)).To(BeTrue())
+	})
+
+	It("must fetch again the rotated key set for an unknown key id, at most once per refresh interval", func() {
+		var a = ks.Auth(htpaut.Config{
+			RefreshMin: libdur.ParseDuration(300 * time.Millisecond),
+		})
+
+		_, err := a.Verify(ctx, rs256("kid1", rsaKey1, newClaims(ks.URL(), nil)))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ks.Fetches()).To(BeNumerically("==", 1))
+
+		ks.SetKeys(rsaJWK("kid2", "", &rsaKey2.PublicKey))
+
+		// too early after the last fetch: the cached key set is used
+		_, err = a.Verify(ctx, rs256("kid2", rsaKey2, newClaims(ks.URL(), nil)))
+		Expect(errors.Is(err, htpaut.ErrUnknownKey)).To(BeTrue())
+		Expect(ks.Fetches()).To(BeNumerically("==", 1))
+
+		time.Sleep(350 * time.Millisecond)
+
+		_, err = a.Verify(ctx, rs256("kid2", rsaKey2, newClaims(ks.URL(), nil)))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ks.Fetches()).To(BeNumerically("==", 2))
+
+		// the old key is gone and the refresh interval is not expired
+		_, err = a.Verify(ctx, rs256("kid1", rsaKey1, newClaims(ks.URL(), nil)))
+		Expect(errors.Is(err, htpaut.ErrUnknownKey)).To(BeTrue())
+		Expect(ks.Fetches()).To(BeNumerically("==", 2))
+	})
+
+	It("must keep the last keys when the key set cannot be fetched after expiration", func() {
+		var a = ks.Auth(htpaut.Config{
+			CacheTTL:   libdur.ParseDuration(200 * time.Millisecond),
+			RefreshMin: libdur.ParseDuration(time.Hour),
+		})
+
+		_, err := a.Verify(ctx, rs256("kid1", rsaKey1, newClaims(ks.URL(), nil)))
+		Expect(err).ToNot(HaveOccurred())
+
+		ks.Fail(true)
+		time.Sleep(250 * time.Millisecond)
+
+		_, err = a.Verify(ctx, rs256("kid1", rsaKey1, newClaims(ks.URL(), nil)))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ks.Fetches()).To(BeNumerically("==", 2))
+
+		// the stale keys are cached until the refresh interval
+		_, err = a.Verify(ctx, rs256("kid1", rsaKey1, newClaims(ks.URL(), nil)))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ks.Fetches()).To(BeNumerically("==", 2))
+	})
+
+	It("must return a fetch error and a 503 response if no key set was ever fetched", func() {
+		ks.Fail(true)
+
+		var a = ks.Auth(htpaut.Config{})
+
+		_, err := a.Verify(ctx, rs256("kid1", rsaKey1, newClaims(ks.URL(), nil)))
+		Expect(errors.Is(err, htpaut.ErrJWKSFetch)).To(BeTrue())
+
+		var (
+			rec = httptest.NewRecorder()
+			req = httptest.NewRequest(http.MethodGet, "/api", nil)
+		)
+
+		req.Header.Set("Authorization", "Bearer "+rs256("kid1", rsaKey1, newClaims(ks.URL(), nil)))
+		a.Handler(http.NotFoundHandler()).ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusServiceUnavailable))
+	})
+})
@@ -0,0 +1,203 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package auth
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Algorithms is the list of supported signing algorithms.
+var Algorithms = []string{
+	"RS256", "RS384", "RS512",
+	"PS256", "PS384", "PS512",
+	"ES256", "ES384", "ES512",
+	"EdDSA",
+}
+
+type header struct {
+	Alg  string   `json:"alg"`
+	Kid  string   `json:"kid"`
+	Typ  string   `json:"typ"`
+	Crit []string `json:"crit"`
+}
+
+// token is a parsed but not yet verified compact JWS.
+type token struct {
+	h header
+	c Claims
+	i []byte // signing input
+	s []byte // signature
+}
+
+func parseToken(raw string) (*token, error) {
+	var p = strings.Split(raw, ".")
+
+	if len(p) != 3 {
+		return nil, ErrInvalidToken
+	}
+
+	var (
+		tkn = &token{
+			i: []byte(p[0] + "." + p[1]),
+		}
+		buf []byte
+		err error
+	)
+
+	if buf, err = base64.RawURLEncoding.DecodeString(p[0]); err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrInvalidToken, err)
+	} else if err = json.Unmarshal(buf, &tkn.h); err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrInvalidToken, err)
+	} else if len(tkn.h.Crit) > 0 {
+		return nil, fmt.Errorf("%w: unsupported critical header %v", ErrInvalidToken, tkn.h.Crit)
+	}
+
+	if buf, err = base64.RawURLEncoding.DecodeString(p[1]); err != nil {
+		return nil, fmt.Errorf("%w: payload: %v", ErrInvalidToken, err)
+	}
+
+	var dec = json.NewDecoder(bytes.NewReader(buf))
+	dec.UseNumber()
+
+	if err = dec.Decode(&tkn.c); err != nil || tkn.c == nil {
+		return nil, fmt.Errorf("%w: payload: %v", ErrInvalidToken, err)
+	}
+
+	if tkn.s, err = base64.RawURLEncoding.DecodeString(p[2]); err != nil || len(tkn.s) < 1 {
+		return nil, fmt.Errorf("%w: signature", ErrInvalidToken)
+	}
+
+	return tkn, nil
+}
+
+func algHash(alg string) crypto.Hash {
+	switch alg[2:] {
+	case "256":
+		return crypto.SHA256
+	case "384":
+		return crypto.SHA384
+	case "512":
+		return crypto.SHA512
+	default:
+		return 0
+	}
+}
+
+// keyMatch return true if the given public key can verify the given algorithm.
+func keyMatch(alg string, key crypto.PublicKey) bool {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return strings.HasPrefix(alg, "RS") || strings.HasPrefix(alg, "PS")
+	case *ecdsa.PublicKey:
+		switch alg {
+		case "ES256":
+			return k.Curve == elliptic.P256()
+		case "ES384":
+			return k.Curve == elliptic.P384()
+		case "ES512":
+			return k.Curve == elliptic.P521()
+		}
+	case ed25519.PublicKey:
+		return alg == "EdDSA"
+	}
+
+	return false
+}
+
+// verify check the signature of the token with the given key.
+func (t *token) verify(key crypto.PublicKey) error {
+	var alg = t.h.Alg
+
+	if !keyMatch(alg, key) {
+		return ErrUnknownKey
+	}
+
+	if alg == "EdDSA" {
+		if ed25519.Verify(key.(ed25519.PublicKey), t.i, t.s) {
+			return nil
+		}
+		return ErrInvalidSignature
+	}
+
+	var h = algHash(alg)
+
+	if h == 0 || !h.Available() {
+		return ErrUnsupportedAlgorithm
+	}
+
+	var d = h.New()
+	_, _ = d.Write(t.i)
+	var sum = d.Sum(nil)
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		var err error
+
+		if strings.HasPrefix(alg, "PS") {
+			err = rsa.VerifyPSS(k, h, sum, t.s, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		} else {
+			err = rsa.VerifyPKCS1v15(k, h, sum, t.s)
+		}
+
+		if err != nil {
+			return ErrInvalidSignature
+		}
+
+		return nil
+
+	case *ecdsa.PublicKey:
+		var n = (k.Curve.Params().BitSize + 7) / 8
+
+		if len(t.s) != 2*n {
+			return ErrInvalidSignature
+		}
+
+		var (
+			r = new(big.Int).SetBytes(t.s[:n])
+			s = new(big.Int).SetBytes(t.s[n:])
+		)
+
+		if !ecdsa.Verify(k, sum, r, s) {
+			return ErrInvalidSignature
+		}
+
+		return nil
+	}
+
+	return ErrUnknownKey
+}
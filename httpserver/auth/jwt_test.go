@@ -0,0 +1,210 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package auth_test
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"strings"
+	"time"
+
+	libdur "github.com/nabbar/golib/duration"
+	htpaut "github.com/nabbar/golib/httpserver/auth"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// rsaPublicPEM return the pem of the public key, as used by the hmac key confusion attack.
+func rsaPublicPEM() []byte {
+	p, err := x509.MarshalPKIXPublicKey(&rsaKey1.PublicKey)
+	Expect(err).ToNot(HaveOccurred())
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: p})
+}
+
+var _ = Describe("httpserver/auth token verification", func() {
+	var (
+		ks *keyServer
+		a  htpaut.Auth
+	)
+
+	BeforeEach(func() {
+		ks = newKeyServer(
+			rsaJWK("kid1", "", &rsaKey1.PublicKey),
+			rsaJWK("pinned", "RS256", &rsaKey2.PublicKey),
+			ecJWK("ec1", &ecKey1.PublicKey),
+		)
+	})
+
+	AfterEach(func() {
+		ks.Close()
+	})
+
+	Context("with a forged or confusing signing algorithm", func() {
+		JustBeforeEach(func() {
+			a = ks.Auth(htpaut.Config{})
+		})
+
+		DescribeTable("must reject the token",
+			func(fct func(iss string) string, exp error) {
+				_, err := a.Verify(ctx, fct(ks.URL()))
+
+				if exp == nil {
+					Expect(err).ToNot(HaveOccurred())
+				} else {
+					Expect(errors.Is(err, exp)).To(BeTrue(), "error: %v", err)
+				}
+			},
+			Entry("unless signed with RS256 by the key", func(iss string) string {
+				return rs256("kid1", rsaKey1, newClaims(iss, nil))
+			}, nil),
+			Entry("unless signed with PS256 by the key", func(iss string) string {
+				return signToken(map[string]any{"alg": "PS256", "kid": "kid1"}, newClaims(iss, nil), rsaKey1)
+			}, nil),
+			Entry("unless signed with ES256 by the key", func(iss string) string {
+				return signToken(map[string]any{"alg": "ES256", "kid": "ec1"}, newClaims(iss, nil), ecKey1)
+			}, nil),
+			Entry("with the none algorithm and no signature", func(iss string) string {
+				return signToken(map[string]any{"alg": "none", "kid": "kid1"}, newClaims(iss, nil), nil)
+			}, htpaut.ErrInvalidToken),
+			Entry("with the none algorithm and a signature", func(iss string) string {
+				return signToken(map[string]any{"alg": "none", "kid": "kid1"}, newClaims(iss, nil), []byte("secret"))
+			}, htpaut.ErrUnsupportedAlgorithm),
+			Entry("with HS256 signed by the rsa public key", func(iss string) string {
+				return signToken(map[string]any{"alg": "HS256", "kid": "kid1"}, newClaims(iss, nil), rsaPublicPEM())
+			}, htpaut.ErrUnsupportedAlgorithm),
+			Entry("with RS256 signed by another key", func(iss string) string {
+				return rs256("kid1", rsaKey2, newClaims(iss, nil))
+			}, htpaut.ErrInvalidSignature),
+			Entry("with ES256 on a rsa key", func(iss string) string {
+				return signToken(map[string]any{"alg": "ES256", "kid": "kid1"}, newClaims(iss, nil), ecKey1)
+			}, htpaut.ErrUnknownKey),
+			Entry("with RS256 on an ecdsa key", func(iss string) string {
+				return signToken(map[string]any{"alg": "RS256", "kid": "ec1"}, newClaims(iss, nil), rsaKey1)
+			}, htpaut.ErrUnknownKey),
+			Entry("with PS256 on a key pinned to RS256", func(iss string) string {
+				return signToken(map[string]any{"alg": "PS256", "kid": "pinned"}, newClaims(iss, nil), rsaKey2)
+			}, htpaut.ErrUnknownKey),
+			Entry("with a critical header", func(iss string) string {
+				return signToken(map[string]any{"alg": "RS256", "kid": "kid1", "crit": []string{"exp"}}, newClaims(iss, nil), rsaKey1)
+			}, htpaut.ErrInvalidToken),
+			Entry("with a modified payload", func(iss string) string {
+				var (
+					t = strings.Split(rs256("kid1", rsaKey1, newClaims(iss, nil)), ".")
+					o = strings.Split(rs256("kid1", rsaKey1, newClaims(iss, map[string]any{"sub": "admin"})), ".")
+				)
+				return t[0] + "." + o[1] + "." + t[2]
+			}, htpaut.ErrInvalidSignature),
+			Entry("with a malformed token", func(iss string) string {
+				return "abc.def"
+			}, htpaut.ErrInvalidToken),
+		)
+
+		It("must reject an algorithm not allowed by the config", func() {
+			a = ks.Auth(htpaut.Config{Algorithms: []string{"ES256"}})
+
+			_, err := a.Verify(ctx, rs256("kid1", rsaKey1, newClaims(ks.URL(), nil)))
+			Expect(errors.Is(err, htpaut.ErrUnsupportedAlgorithm)).To(BeTrue())
+
+			_, err = a.Verify(ctx, signToken(map[string]any{"alg": "ES256", "kid": "ec1"}, newClaims(ks.URL(), nil), ecKey1))
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	Context("with the claims of a token signed by a trusted key", func() {
+		JustBeforeEach(func() {
+			a = ks.Auth(htpaut.Config{
+				Providers: []htpaut.ProviderConfig{{Issuer: ks.URL(), Audience: []string{"api", "web"}}},
+				Scopes:    []string{"read"},
+				Leeway:    libdur.Seconds(30),
+			})
+		})
+
+		DescribeTable("must check the time, issuer, audience and scope claims",
+			func(upd func() map[string]any, exp error) {
+				_, err := a.Verify(ctx, rs256("kid1", rsaKey1, newClaims(ks.URL(), upd())))
+
+				if exp == nil {
+					Expect(err).ToNot(HaveOccurred())
+				} else {
+					Expect(errors.Is(err, exp)).To(BeTrue(), "error: %v", err)
+				}
+			},
+			Entry("valid claims", func() map[string]any {
+				return nil
+			}, nil),
+			Entry("missing exp", func() map[string]any {
+				return map[string]any{"exp": nil}
+			}, htpaut.ErrExpiredToken),
+			Entry("exp in the past beyond the leeway", func() map[string]any {
+				return map[string]any{"exp": time.Now().Add(-time.Minute).Unix()}
+			}, htpaut.ErrExpiredToken),
+			Entry("exp in the past within the leeway", func() map[string]any {
+				return map[string]any{"exp": time.Now().Add(-10 * time.Second).Unix()}
+			}, nil),
+			Entry("nbf in the future beyond the leeway", func() map[string]any {
+				return map[string]any{"nbf": time.Now().Add(time.Minute).Unix()}
+			}, htpaut.ErrExpiredToken),
+			Entry("nbf in the future within the leeway", func() map[string]any {
+				return map[string]any{"nbf": time.Now().Add(10 * time.Second).Unix()}
+			}, nil),
+			Entry("iat in the future beyond the leeway", func() map[string]any {
+				return map[string]any{"iat": time.Now().Add(time.Minute).Unix()}
+			}, htpaut.ErrExpiredToken),
+			Entry("unknown issuer", func() map[string]any {
+				return map[string]any{"iss": "https://other.example.com"}
+			}, htpaut.ErrInvalidIssuer),
+			Entry("missing issuer", func() map[string]any {
+				return map[string]any{"iss": nil}
+			}, htpaut.ErrInvalidIssuer),
+			Entry("audience not accepted", func() map[string]any {
+				return map[string]any{"aud": "other"}
+			}, htpaut.ErrInvalidAudience),
+			Entry("missing audience", func() map[string]any {
+				return map[string]any{"aud": nil}
+			}, htpaut.ErrInvalidAudience),
+			Entry("audience list with one accepted", func() map[string]any {
+				return map[string]any{"aud": []string{"other", "web"}}
+			}, nil),
+			Entry("missing scope", func() map[string]any {
+				return map[string]any{"scope": "write"}
+			}, htpaut.ErrMissingScope),
+			Entry("no scope claim", func() map[string]any {
+				return map[string]any{"scope": nil}
+			}, htpaut.ErrMissingScope),
+			Entry("scope string with several scopes", func() map[string]any {
+				return map[string]any{"scope": "write read"}
+			}, nil),
+			Entry("scp list", func() map[string]any {
+				return map[string]any{"scope": nil, "scp": []string{"write", "read"}}
+			}, nil),
+			Entry("scp string", func() map[string]any {
+				return map[string]any{"scope": nil, "scp": "read"}
+			}, nil),
+		)
+	})
+})
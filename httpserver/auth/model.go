@@ -0,0 +1,185 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	headerAuthorization   = "Authorization"
+	headerWWWAuthenticate = "WWW-Authenticate"
+	bearerPrefix          = "bearer "
+)
+
+type ath struct {
+	c Config             // config
+	a map[string]bool    // allowed algorithms
+	p map[string]*keySet // key sets by issuer
+}
+
+func (o *ath) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, p := range o.c.ExcludePaths {
+			if len(p) > 0 && strings.HasPrefix(r.URL.Path, p) {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		var raw = r.Header.Get(headerAuthorization)
+
+		if len(raw) <= len(bearerPrefix) || !strings.EqualFold(raw[:len(bearerPrefix)], bearerPrefix) {
+			o.reject(w, ErrMissingToken)
+			return
+		}
+
+		c, e := o.Verify(r.Context(), strings.TrimSpace(raw[len(bearerPrefix):]))
+
+		if e != nil {
+			o.reject(w, e)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(WithClaims(r.Context(), c)))
+	})
+}
+
+func (o *ath) reject(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrMissingToken):
+		w.Header().Set(headerWWWAuthenticate, `Bearer`)
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+	case errors.Is(err, ErrMissingScope):
+		w.Header().Set(headerWWWAuthenticate, fmt.Sprintf(`Bearer error="insufficient_scope", scope="%s"`, strings.Join(o.c.Scopes, " ")))
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+	case errors.Is(err, ErrJWKSFetch):
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+	default:
+		w.Header().Set(headerWWWAuthenticate, `Bearer error="invalid_token"`)
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+	}
+}
+
+func (o *ath) Verify(ctx context.Context, raw string) (Claims, error) {
+	if o == nil {
+		return nil, ErrInvalidInstance
+	} else if len(raw) < 1 {
+		return nil, ErrMissingToken
+	}
+
+	tkn, err := parseToken(raw)
+
+	if err != nil {
+		return nil, err
+	} else if !o.a[tkn.h.Alg] {
+		return nil, fmt.Errorf("%w: '%s'", ErrUnsupportedAlgorithm, tkn.h.Alg)
+	}
+
+	var iss = tkn.c.Issuer()
+
+	set, ok := o.p[iss]
+
+	if !ok {
+		return nil, fmt.Errorf("%w: '%s'", ErrInvalidIssuer, iss)
+	}
+
+	key, err := set.keys(ctx, tkn.h.Kid, o.c.getCacheTTL(), o.c.getRefreshMin(), o.c.getTimeout())
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = ErrUnknownKey
+
+	for _, k := range key {
+		if len(k.a) > 0 && k.a != tkn.h.Alg {
+			continue
+		} else if err = tkn.verify(k.k); err == nil {
+			break
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	} else if err = o.checkClaims(tkn.c, set.p); err != nil {
+		return nil, err
+	}
+
+	return tkn.c, nil
+}
+
+// checkClaims validate the time, audience and scope claims of a token with a verified signature.
+func (o *ath) checkClaims(c Claims, p ProviderConfig) error {
+	var (
+		now = time.Now()
+		lwy = o.c.Leeway.Time()
+	)
+
+	if _, k := c["exp"]; !k {
+		return fmt.Errorf("%w: missing exp claim", ErrExpiredToken)
+	} else if now.After(c.ExpiresAt().Add(lwy)) {
+		return ErrExpiredToken
+	}
+
+	if _, k := c["nbf"]; k && now.Add(lwy).Before(c.NotBefore()) {
+		return ErrExpiredToken
+	}
+
+	if _, k := c["iat"]; k && now.Add(lwy).Before(c.IssuedAt()) {
+		return ErrExpiredToken
+	}
+
+	if len(p.Audience) > 0 {
+		var ok = false
+
+		for _, a := range c.Audience() {
+			for _, i := range p.Audience {
+				if a == i {
+					ok = true
+					break
+				}
+			}
+		}
+
+		if !ok {
+			return ErrInvalidAudience
+		}
+	}
+
+	for _, s := range o.c.Scopes {
+		if !c.HasScope(s) {
+			return fmt.Errorf("%w: '%s'", ErrMissingScope, s)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package auth_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	htpaut "github.com/nabbar/golib/httpserver/auth"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// subjectHandler write the subject of the claims of the request context, or "anonymous".
+func subjectHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if c, ok := htpaut.FromContext(r.Context()); ok {
+			_, _ = w.Write([]byte(c.Subject()))
+		} else {
+			_, _ = w.Write([]byte("anonymous"))
+		}
+	})
+}
+
+var _ = Describe("httpserver/auth handler", func() {
+	var (
+		ks *keyServer
+		h  http.Handler
+	)
+
+	BeforeEach(func() {
+		ks = newKeyServer(rsaJWK("kid1", "", &rsaKey1.PublicKey))
+		h = ks.Auth(htpaut.Config{
+			Scopes:       []string{"read"},
+			ExcludePaths: []string{"", "/health", "/metrics/"},
+		}).Handler(subjectHandler())
+	})
+
+	AfterEach(func() {
+		ks.Close()
+	})
+
+	DescribeTable("must serve the request",
+		func(path, auth string, code int, body, challenge string) {
+			var (
+				rec = httptest.NewRecorder()
+				req = httptest.NewRequest(http.MethodGet, path, nil)
+			)
+
+			if len(auth) > 0 {
+				req.Header.Set("Authorization", auth)
+			}
+
+			h.ServeHTTP(rec, req)
+
+			Expect(rec.Code).To(Equal(code))
+			Expect(rec.Header().Get("WWW-Authenticate")).To(Equal(challenge))
+
+			if len(body) > 0 {
+				Expect(rec.Body.String()).To(Equal(body))
+			}
+		},
+		Entry("excluded path without token", "/health", "", http.StatusOK, "anonymous", ""),
+		Entry("excluded path prefix without token", "/health/live", "", http.StatusOK, "anonymous", ""),
+		Entry("excluded directory without token", "/metrics/app", "", http.StatusOK, "anonymous", ""),
+		Entry("excluded directory without its trailing slash", "/metrics", "", http.StatusUnauthorized, "", `Bearer`),
+		Entry("not excluded path without token", "/api", "", http.StatusUnauthorized, "", `Bearer`),
+		Entry("not excluded path with a basic authorization", "/api", "Basic dXNlcjpwYXNz", http.StatusUnauthorized, "", `Bearer`),
+		Entry("not excluded path with an empty bearer", "/api", "Bearer ", http.StatusUnauthorized, "", `Bearer`),
+		Entry("not excluded path with an invalid token", "/api", "Bearer abc.def.ghi", http.StatusUnauthorized, "", `Bearer error="invalid_token"`),
+	)
+
+	It("must store the claims of a valid token into the request context", func() {
+		for _, p := range []string{"Bearer ", "bearer ", "BEARER "} {
+			var (
+				rec = httptest.NewRecorder()
+				req = httptest.NewRequest(http.MethodGet, "/api", nil)
+			)
+
+			req.Header.Set("Authorization", p+rs256("kid1", rsaKey1, newClaims(ks.URL(), nil)))
+			h.ServeHTTP(rec, req)
+
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(rec.Body.String()).To(Equal("user"))
+		}
+	})
+
+	It("must reject a valid token missing a required scope with a 403 response", func() {
+		var (
+			rec = httptest.NewRecorder()
+			req = httptest.NewRequest(http.MethodGet, "/api", nil)
+		)
+
+		req.Header.Set("Authorization", "Bearer "+rs256("kid1", rsaKey1, newClaims(ks.URL(), map[string]any{"scope": "write"})))
+		h.ServeHTTP(rec, req)
+
+		Expect(rec.Code).To(Equal(http.StatusForbidden))
+		Expect(rec.Header().Get("WWW-Authenticate")).To(Equal(`Bearer error="insufficient_scope", scope="read"`))
+	})
+})
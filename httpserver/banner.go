@@ -0,0 +1,196 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package httpserver
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/url"
+	"sort"
+
+	loglvl "github.com/nabbar/golib/logger/level"
+)
+
+const redactedValue = "xxxxx"
+
+// effectiveConfig return the configuration really applied on the given server.
+// No secret value is included: certificates are only counted and credentials into url are redacted.
+func (o *srv) effectiveConfig(ser *http.Server) map[string]interface{} {
+	var (
+		opt = o.cfgGetServer()
+		res = map[string]interface{}{
+			"name":        o.GetName(),
+			"bind":        o.GetBindable(),
+			"expose":      o.getExposeRedacted(),
+			"handler_key": o.HandlerGetValidKey(),
+			"handlers":    o.handlerKeys(),
+			"disabled":    o.IsDisable(),
+			"tls":         false,
+		}
+	)
+
+	if ser == nil {
+		return res
+	}
+
+	if r := o.getRedirect(); r != nil {
+		res["redirect_http"] = r.Addr
+	}
+
+	if l := o.vhostNames(); len(l) > 0 {
+		res["virtual_hosts"] = l
+	}
+
+	if cfg := o.GetConfig(); cfg != nil && cfg.Guard.IsEnabled() {
+		res["guard_allow"] = len(cfg.Guard.Allow)
+		res["guard_deny"] = len(cfg.Guard.Deny)
+		res["guard_trusted_proxies"] = len(cfg.Guard.TrustedProxies)
+		res["guard_rate"] = cfg.Guard.RateLimit.Rate
+		res["guard_burst"] = cfg.Guard.RateLimit.Burst
+		res["guard_decompress"] = cfg.Guard.Decompress
+	}
+
+	if cfg := o.GetConfig(); cfg != nil && cfg.Headers.IsEnabled() {
+		res["cors_origins"] = len(cfg.Headers.CORS.AllowOrigins)
+		res["hsts"] = cfg.Headers.Security.HSTSMaxAge > 0
+	}
+
+	if cfg := o.GetConfig(); cfg != nil && cfg.DrainDelay > 0 {
+		res["drain_delay"] = cfg.DrainDelay.String()
+	}
+
+	if cfg := o.GetConfig(); cfg != nil && cfg.Preflight.Enabled {
+		res["preflight_connections"] = cfg.Preflight.Connections
+		res["preflight_backlog"] = cfg.Preflight.Backlog
+	}
+
+	if cfg := o.GetConfig(); cfg != nil && cfg.Auth.IsEnabled() {
+		res["auth_providers"] = len(cfg.Auth.Providers)
+	}
+
+	res["read_timeout"] = ser.ReadTimeout.String()
+	res["read_header_timeout"] = ser.ReadHeaderTimeout.String()
+	res["write_timeout"] = ser.WriteTimeout.String()
+	res["idle_timeout"] = ser.IdleTimeout.String()
+	res["max_header_bytes"] = ser.MaxHeaderBytes
+	res["disable_keep_alive"] = opt.DisableKeepAlive
+
+	res["http2_max_handlers"] = opt.MaxHandlers
+	res["http2_max_concurrent_streams"] = opt.MaxConcurrentStreams
+	res["http2_max_read_frame_size"] = opt.MaxReadFrameSize
+	res["http2_max_upload_buffer_per_connection"] = opt.MaxUploadBufferPerConnection
+	res["http2_max_upload_buffer_per_stream"] = opt.MaxUploadBufferPerStream
+	res["http2_permit_prohibited_cipher_suites"] = opt.PermitProhibitedCipherSuites
+
+	if ser.TLSConfig != nil && len(ser.TLSConfig.Certificates) > 0 {
+		res["tls"] = true
+		res["tls_mandatory"] = o.cfgTLSMandatory()
+		res["tls_certificates"] = len(ser.TLSConfig.Certificates)
+		res["tls_min_version"] = tlsVersionName(ser.TLSConfig.MinVersion)
+		res["tls_max_version"] = tlsVersionName(ser.TLSConfig.MaxVersion)
+		res["tls_client_auth"] = ser.TLSConfig.ClientAuth.String()
+		res["tls_cipher_suites"] = len(ser.TLSConfig.CipherSuites)
+
+		if st := o.TLSMigrationStats(); st.Mode != TLSMigrationDisabled {
+			res["tls_migration"] = st.Mode.String()
+			res["tls_migration_version_min"] = st.VersionMin
+			res["tls_migration_legacy_version_min"] = st.LegacyVersionMin
+
+			if l := o.t.getLegacy(); l != nil {
+				res["tls_migration_legacy_bind"] = l.Addr
+			}
+		}
+	}
+
+	return res
+}
+
+func (o *srv) getExposeRedacted() string {
+	if i, l := o.c.Load(cfgExpose); !l {
+		return ""
+	} else if v, k := i.(*url.URL); !k || v == nil {
+		return ""
+	} else if v.User == nil {
+		return v.String()
+	} else {
+		u := *v
+		u.User = url.UserPassword(v.User.Username(), redactedValue)
+		return u.String()
+	}
+}
+
+func (o *srv) handlerKeys() []string {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	if o.h == nil {
+		return make([]string, 0)
+	}
+
+	var (
+		l = o.h()
+		r = make([]string, 0, len(l))
+	)
+
+	for k := range l {
+		r = append(r, k)
+	}
+
+	sort.Strings(r)
+	return r
+}
+
+func (o *srv) logStartupBanner(ser *http.Server) {
+	if cfg := o.GetConfig(); cfg == nil || !cfg.StartupBanner {
+		return
+	}
+
+	ent := o.logger().Entry(loglvl.InfoLevel, "HTTP Server effective configuration")
+
+	for k, v := range o.effectiveConfig(ser) {
+		ent = ent.FieldAdd(k, v)
+	}
+
+	ent.Log()
+}
+
+func tlsVersionName(v uint16) string {
+	switch v {
+	case 0:
+		return "default"
+	case tls.VersionTLS10:
+		return "TLS 1.0"
+	case tls.VersionTLS11:
+		return "TLS 1.1"
+	case tls.VersionTLS12:
+		return "TLS 1.2"
+	case tls.VersionTLS13:
+		return "TLS 1.3"
+	default:
+		return tls.VersionName(v)
+	}
+}
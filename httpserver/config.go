@@ -37,6 +37,9 @@ import (
 	libtls "github.com/nabbar/golib/certificates"
 	libctx "github.com/nabbar/golib/context"
 	libdur "github.com/nabbar/golib/duration"
+	srvath "github.com/nabbar/golib/httpserver/auth"
+	srvgrd "github.com/nabbar/golib/httpserver/guard"
+	srvhdr "github.com/nabbar/golib/httpserver/headers"
 	srvtps "github.com/nabbar/golib/httpserver/types"
 	liblog "github.com/nabbar/golib/logger"
 	logcfg "github.com/nabbar/golib/logger/config"
@@ -55,6 +58,8 @@ const (
 	cfgTLS           = "cfgTLS"
 	cfgTLSMandatory  = "cfgTLSMandatory"
 	cfgServerOptions = "cfgServerOptions"
+	cfgPreflight     = "cfgPreflight"
+	cfgCerts         = "cfgCerts"
 )
 
 // nolint #maligned
@@ -74,6 +79,8 @@ type Config struct {
 
 	// HandlerKey is an options to associate current srv with a specifc handler defined by the key
 	// This key allow to defined multiple srv in only one config for different handler to start multiple api
+	// The key can be a fallback chain of keys separated by a comma, tried in order, like "api-v2,api,"
+	// where the empty item is the default handler, and each item can be a wildcard pattern like "api-*".
 	HandlerKey string `mapstructure:"handler_key" json:"handler_key" yaml:"handler_key" toml:"handler_key"`
 
 	//private
@@ -182,6 +189,42 @@ type Config struct {
 
 	// Logger is used to define the logger options.
 	Logger logcfg.Options `mapstructure:"logger" json:"logger" yaml:"logger" toml:"logger"`
+
+	// StartupBanner allow to log at each start a single entry with the effective configuration of the server
+	// (bind, timeouts, tls versions, handler keys, http2 options). Secrets like certificates are never logged.
+	StartupBanner bool `mapstructure:"startup_banner" json:"startup_banner" yaml:"startup_banner" toml:"startup_banner"`
+
+	// DrainDelay is the delay between the start of the draining and the shutdown of the server. During this delay,
+	// the server still serve the requests but the health check fails, to let the load balancers deregister it.
+	// The draining is notified to the handlers as soon as the stop is called (see DrainFromContext).
+	DrainDelay libdur.Duration `mapstructure:"drain_delay" json:"drain_delay" yaml:"drain_delay" toml:"drain_delay"`
+
+	// TLSMigration allow to accept a legacy TLS minimal version, in log only or on a dedicated bind,
+	// with per connection protocol metrics, to measure the impact of raising the TLS minimal version.
+	TLSMigration TLSMigration `mapstructure:"tls_migration" json:"tls_migration" yaml:"tls_migration" toml:"tls_migration"`
+
+	// Guard define the ip allow / deny lists and the per client rate limiting enforced before the handlers.
+	Guard srvgrd.Config `mapstructure:"guard" json:"guard" yaml:"guard" toml:"guard"`
+
+	// Headers define the CORS policy and the security headers (HSTS, X-Frame-Options, CSP, ...) added to each response.
+	Headers srvhdr.Config `mapstructure:"headers" json:"headers" yaml:"headers" toml:"headers"`
+
+	// Auth define the trusted JWT / OIDC providers and the rules required to accept a bearer token.
+	Auth srvath.Config `mapstructure:"auth" json:"auth" yaml:"auth" toml:"auth"`
+
+	// RedirectHTTP is the local address with a port, like ":80", of a companion plain http server redirecting
+	// each request to the Expose url of this server with a 301 status. The ACME HTTP-01 challenges are passed
+	// to the handler of this server. The redirect server is started and stopped with this server.
+	RedirectHTTP string `mapstructure:"redirect_http" json:"redirect_http" yaml:"redirect_http" toml:"redirect_http" validate:"omitempty,hostname_port"`
+
+	// VirtualHosts define the logical servers sharing the listener of this server, each one selected by the SNI
+	// and the Host header of the requests, with its own handler and TLS certificates. The requests for the other
+	// hosts are served by the handler of this server.
+	VirtualHosts []VirtualHost `mapstructure:"virtual_hosts" json:"virtual_hosts" yaml:"virtual_hosts" toml:"virtual_hosts" validate:"omitempty,dive"`
+
+	// Preflight define the expected capacity of the server, checked at each start against the open files limit,
+	// the somaxconn and the ephemeral ports of the host. The under provisioned limits are logged as warnings.
+	Preflight Preflight `mapstructure:"preflight" json:"preflight" yaml:"preflight" toml:"preflight"`
 }
 
 func (c *Config) Clone() Config {
@@ -201,6 +244,8 @@ func (c *Config) Clone() Config {
 		MaxUploadBufferPerConnection: c.MaxUploadBufferPerConnection,
 		MaxUploadBufferPerStream:     c.MaxUploadBufferPerStream,
 		DisableKeepAlive:             c.DisableKeepAlive,
+		StartupBanner:                c.StartupBanner,
+		DrainDelay:                   c.DrainDelay,
 		Name:                         c.Name,
 		Listen:                       c.Listen,
 		Expose:                       c.Expose,
@@ -219,10 +264,31 @@ func (c *Config) Clone() Config {
 			DynamicSizingDisable: c.TLS.DynamicSizingDisable,
 			SessionTicketDisable: c.TLS.SessionTicketDisable,
 		},
-		Monitor: c.Monitor.Clone(),
+		TLSMigration: c.TLSMigration,
+		Guard:        c.Guard.Clone(),
+		Headers:      c.Headers.Clone(),
+		Auth:         c.Auth.Clone(),
+		Preflight:    c.Preflight,
+		Monitor:      c.Monitor.Clone(),
+		RedirectHTTP: c.RedirectHTTP,
+		VirtualHosts: c.cloneVirtualHosts(),
 	}
 }
 
+func (c *Config) cloneVirtualHosts() []VirtualHost {
+	if len(c.VirtualHosts) < 1 {
+		return nil
+	}
+
+	var res = make([]VirtualHost, 0, len(c.VirtualHosts))
+
+	for _, v := range c.VirtualHosts {
+		res = append(res, v.Clone())
+	}
+
+	return res
+}
+
 func (c *Config) RegisterHandlerFunc(hdl srvtps.FuncHandler) {
 	c.getHandlerFunc = hdl
 }
@@ -351,6 +417,27 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	if e := c.TLSMigration.validate(c.Listen); e != nil {
+		err.Add(e)
+	}
+
+	if e := c.Headers.Validate(); e != nil {
+		err.Add(e)
+	}
+
+	if e := c.Auth.Validate(); e != nil {
+		err.Add(e)
+	}
+
+	if len(c.RedirectHTTP) > 0 && (sameBind(c.RedirectHTTP, c.Listen) || (len(c.TLSMigration.LegacyListen) > 0 && sameBind(c.RedirectHTTP, c.TLSMigration.LegacyListen))) {
+		//nolint goerr113
+		err.Add(fmt.Errorf("redirect http listen address must be different of the listen addresses"))
+	}
+
+	for _, e := range validateVirtualHosts(c.VirtualHosts) {
+		err.Add(e)
+	}
+
 	if err.HasParent() {
 		return err
 	}
@@ -403,12 +490,19 @@ func (o *srv) SetConfig(cfg Config, defLog liblog.FuncLog) error {
 		return ErrorServerValidate.Error(fmt.Errorf("handler is missing or not existing"))
 	}
 
+	for _, v := range cfg.VirtualHosts {
+		if !o.HandlerHas(v.HandlerKey) {
+			return ErrorServerValidate.Error(fmt.Errorf("handler of virtual host '%s' is missing or not existing", v.Name))
+		}
+	}
+
 	o.c.Store(cfgName, cfg.Name)
 	o.c.Store(cfgListen, cfg.GetListen())
 	o.c.Store(cfgExpose, cfg.GetExpose())
 	o.c.Store(cfgDisabled, cfg.Disabled)
 	o.c.Store(cfgServerOptions, o.makeOptServer(cfg))
 	o.c.Store(cfgConfig, cfg)
+	o.c.Store(cfgCerts, newVhostCerts(&cfg))
 
 	return nil
 }
@@ -498,6 +592,16 @@ func (o *srv) cfgGetTLS() libtls.TLSConfig {
 	}
 }
 
+func (o *srv) cfgGetCerts() *vhostCerts {
+	if i, l := o.c.Load(cfgCerts); !l {
+		return nil
+	} else if v, k := i.(*vhostCerts); !k {
+		return nil
+	} else {
+		return v
+	}
+}
+
 func (o *srv) cfgTLSMandatory() bool {
 	if i, l := o.c.Load(cfgTLSMandatory); !l {
 		return false
@@ -0,0 +1,145 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package diagnostics
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	libval "github.com/go-playground/validator/v10"
+	libhtp "github.com/nabbar/golib/httpserver"
+	srvgrd "github.com/nabbar/golib/httpserver/guard"
+	liblog "github.com/nabbar/golib/logger"
+)
+
+const (
+	// DefaultName is the default name of the diagnostics server.
+	DefaultName = "diagnostics"
+	// DefaultListen is the default bind address of the diagnostics server: only reachable from the local host.
+	DefaultListen = "127.0.0.1:6060"
+	// HandlerKey is the handler key of the diagnostics server.
+	HandlerKey = "diagnostics"
+)
+
+var (
+	ErrInvalidInstance = errors.New("invalid instance")
+	ErrBindUsed        = errors.New("bind address already used by a server of the pool")
+)
+
+type Config struct {
+	// Name is the name of the diagnostics server. Default is "diagnostics".
+	Name string `mapstructure:"name" json:"name" yaml:"name" toml:"name"`
+
+	// Listen is the bind address of the diagnostics server. Default is "127.0.0.1:6060".
+	Listen string `mapstructure:"listen" json:"listen" yaml:"listen" toml:"listen" validate:"omitempty,hostname_port"`
+
+	// Expose is the url used to reach the diagnostics server. Default is the listen address with the http scheme.
+	Expose string `mapstructure:"expose" json:"expose" yaml:"expose" toml:"expose" validate:"omitempty,url"`
+
+	// Allow is the list of ip or cidr networks allowed to reach the diagnostics. Default is the loopback networks.
+	Allow []string `mapstructure:"allow" json:"allow" yaml:"allow" toml:"allow" validate:"omitempty,dive,cidr|ip"`
+
+	// DisablePprof removes the net/http/pprof handlers.
+	DisablePprof bool `mapstructure:"disable_pprof" json:"disable_pprof" yaml:"disable_pprof" toml:"disable_pprof"`
+
+	// DisableExpvar removes the expvar handler.
+	DisableExpvar bool `mapstructure:"disable_expvar" json:"disable_expvar" yaml:"disable_expvar" toml:"disable_expvar"`
+
+	// DisableLogLevel removes the handler reading and changing the levels of the logger.
+	DisableLogLevel bool `mapstructure:"disable_log_level" json:"disable_log_level" yaml:"disable_log_level" toml:"disable_log_level"`
+
+	log liblog.FuncLog
+}
+
+// RegisterLogger registers the logger whose levels are served by the log level handler.
+func (c *Config) RegisterLogger(fct liblog.FuncLog) {
+	c.log = fct
+}
+
+func (c Config) Validate() error {
+	if er := libval.New().Struct(c); er != nil {
+		if e, ok := er.(*libval.InvalidValidationError); ok {
+			return e
+		}
+
+		for _, e := range er.(libval.ValidationErrors) {
+			//nolint goerr113
+			return fmt.Errorf("config field '%s' is not validated by constraint '%s'", e.Namespace(), e.ActualTag())
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) normalize() {
+	if len(c.Name) < 1 {
+		c.Name = DefaultName
+	}
+
+	if len(c.Listen) < 1 {
+		c.Listen = DefaultListen
+	}
+
+	if len(c.Expose) < 1 {
+		c.Expose = "http://" + c.Listen
+	}
+
+	if len(c.Allow) < 1 {
+		c.Allow = []string{"127.0.0.0/8", "::1/128"}
+	}
+}
+
+// ServerConfig returns the config of a dedicated http server serving the diagnostics handler,
+// with a guard rejecting the clients not in the allow list.
+func (c Config) ServerConfig() (libhtp.Config, error) {
+	if e := c.Validate(); e != nil {
+		return libhtp.Config{}, e
+	}
+
+	c.normalize()
+
+	cfg := libhtp.Config{
+		Name:       c.Name,
+		Listen:     c.Listen,
+		Expose:     c.Expose,
+		HandlerKey: HandlerKey,
+		Guard: srvgrd.Config{
+			Allow: append(make([]string, 0, len(c.Allow)), c.Allow...),
+		},
+	}
+
+	h := c.Handler()
+
+	cfg.RegisterHandlerFunc(func() map[string]http.Handler {
+		return map[string]http.Handler{
+			HandlerKey: h,
+		}
+	})
+
+	return cfg, nil
+}
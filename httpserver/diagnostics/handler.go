@@ -0,0 +1,170 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package diagnostics
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+const (
+	// PathPprof is the path of the pprof index, the profiles are served under this path.
+	PathPprof = "/debug/pprof/"
+	// PathExpvar is the path of the expvar variables.
+	PathExpvar = "/debug/vars"
+	// PathRuntime is the path of the runtime stats.
+	PathRuntime = "/debug/runtime"
+	// PathLogLevel is the path of the levels of the logger.
+	PathLogLevel = "/debug/loglevel"
+)
+
+var start = time.Now()
+
+type MemoryStats struct {
+	Alloc       uint64 `json:"alloc"`
+	TotalAlloc  uint64 `json:"total_alloc"`
+	Sys         uint64 `json:"sys"`
+	HeapAlloc   uint64 `json:"heap_alloc"`
+	HeapInuse   uint64 `json:"heap_inuse"`
+	HeapObjects uint64 `json:"heap_objects"`
+	StackInuse  uint64 `json:"stack_inuse"`
+}
+
+type GCStats struct {
+	NumGC         int64         `json:"num_gc"`
+	LastGC        time.Time     `json:"last_gc"`
+	PauseTotal    time.Duration `json:"pause_total"`
+	LastPause     time.Duration `json:"last_pause"`
+	NextGC        uint64        `json:"next_gc"`
+	GCCPUFraction float64       `json:"gc_cpu_fraction"`
+}
+
+type RuntimeStats struct {
+	GoVersion  string        `json:"go_version"`
+	Uptime     time.Duration `json:"uptime"`
+	NumCPU     int           `json:"num_cpu"`
+	GOMAXPROCS int           `json:"gomaxprocs"`
+	Goroutines int           `json:"goroutines"`
+	CGoCalls   int64         `json:"cgo_calls"`
+	// FileDescriptors is the number of open file descriptors, -1 if unknown on this os.
+	FileDescriptors int         `json:"file_descriptors"`
+	Memory          MemoryStats `json:"memory"`
+	GC              GCStats     `json:"gc"`
+}
+
+// Runtime returns the current stats of the go runtime. It stops the world to read the memory stats.
+func Runtime() RuntimeStats {
+	var (
+		m runtime.MemStats
+		g debug.GCStats
+	)
+
+	runtime.ReadMemStats(&m)
+	debug.ReadGCStats(&g)
+
+	res := RuntimeStats{
+		GoVersion:       runtime.Version(),
+		Uptime:          time.Since(start),
+		NumCPU:          runtime.NumCPU(),
+		GOMAXPROCS:      runtime.GOMAXPROCS(0),
+		Goroutines:      runtime.NumGoroutine(),
+		CGoCalls:        runtime.NumCgoCall(),
+		FileDescriptors: countFD(),
+		Memory: MemoryStats{
+			Alloc:       m.Alloc,
+			TotalAlloc:  m.TotalAlloc,
+			Sys:         m.Sys,
+			HeapAlloc:   m.HeapAlloc,
+			HeapInuse:   m.HeapInuse,
+			HeapObjects: m.HeapObjects,
+			StackInuse:  m.StackInuse,
+		},
+		GC: GCStats{
+			NumGC:         g.NumGC,
+			LastGC:        g.LastGC,
+			PauseTotal:    g.PauseTotal,
+			NextGC:        m.NextGC,
+			GCCPUFraction: m.GCCPUFraction,
+		},
+	}
+
+	if len(g.Pause) > 0 {
+		res.GC.LastPause = g.Pause[0]
+	}
+
+	return res
+}
+
+func countFD() int {
+	if l, e := os.ReadDir("/proc/self/fd"); e != nil {
+		return -1
+	} else {
+		return len(l)
+	}
+}
+
+func serveRuntime(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	_ = e.Encode(Runtime())
+}
+
+// Handler returns the handler serving the enabled diagnostics: the pprof profiles, the expvar variables,
+// the runtime stats and the levels of the registered logger. It must only be served on a protected server.
+func (c Config) Handler() http.Handler {
+	var m = http.NewServeMux()
+
+	if !c.DisablePprof {
+		// the pprof index only resolves the profiles under this fixed path
+		m.HandleFunc(PathPprof, pprof.Index)
+		m.HandleFunc(PathPprof+"cmdline", pprof.Cmdline)
+		m.HandleFunc(PathPprof+"profile", pprof.Profile)
+		m.HandleFunc(PathPprof+"symbol", pprof.Symbol)
+		m.HandleFunc(PathPprof+"trace", pprof.Trace)
+	}
+
+	if !c.DisableExpvar {
+		m.Handle(PathExpvar, expvar.Handler())
+	}
+
+	m.HandleFunc(PathRuntime, serveRuntime)
+
+	if !c.DisableLogLevel && c.log != nil {
+		m.HandleFunc(PathLogLevel, c.serveLogLevel)
+	}
+
+	return m
+}
@@ -0,0 +1,53 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package diagnostics
+
+import (
+	srvpool "github.com/nabbar/golib/httpserver/pool"
+	liblog "github.com/nabbar/golib/logger"
+)
+
+// EnableDiagnostics adds to the pool a dedicated http server serving the diagnostics handler,
+// restricted to the allow list of the config. The server is started with the other servers of the pool.
+// The given logger is used by the log level handler if no logger has been registered into the config.
+func EnableDiagnostics(pol srvpool.Pool, cfg Config, defLog liblog.FuncLog) error {
+	if pol == nil {
+		return ErrInvalidInstance
+	}
+
+	if cfg.log == nil {
+		cfg.RegisterLogger(defLog)
+	}
+
+	if c, e := cfg.ServerConfig(); e != nil {
+		return e
+	} else if l := c.GetListen(); l != nil && pol.Has(l.Host) {
+		return ErrBindUsed
+	} else {
+		return pol.StoreNew(c, defLog)
+	}
+}
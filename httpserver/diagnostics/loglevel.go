@@ -0,0 +1,97 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package diagnostics
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+
+	loglvl "github.com/nabbar/golib/logger/level"
+)
+
+// LogLevels is the level of the root logger and the levels set by name for the named loggers.
+type LogLevels struct {
+	Level string            `json:"level"`
+	Named map[string]string `json:"named"`
+}
+
+// serveLogLevel serves the levels of the logger with GET, sets a level with PUT or POST and the query
+// parameters level and name (root logger without name), and removes the level of a name with DELETE.
+func (c Config) serveLogLevel(w http.ResponseWriter, r *http.Request) {
+	var (
+		log = c.log()
+		nam = strings.TrimSpace(r.URL.Query().Get("name"))
+		lvl = strings.ToLower(strings.TrimSpace(r.URL.Query().Get("level")))
+	)
+
+	if log == nil {
+		http.Error(w, ErrInvalidInstance.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+	case http.MethodPut, http.MethodPost:
+		if !slices.Contains(loglvl.ListLevels(), lvl) {
+			http.Error(w, "invalid level '"+lvl+"'", http.StatusBadRequest)
+			return
+		} else if len(nam) < 1 {
+			log.SetLevel(loglvl.Parse(lvl))
+		} else {
+			log.SetNamedLevel(nam, loglvl.Parse(lvl))
+		}
+	case http.MethodDelete:
+		if len(nam) < 1 {
+			http.Error(w, "missing name", http.StatusBadRequest)
+			return
+		}
+
+		log.DelNamedLevel(nam)
+	default:
+		w.Header().Set("Allow", "GET, HEAD, PUT, POST, DELETE")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	var res = LogLevels{
+		Level: strings.ToLower(log.GetLevel().String()),
+		Named: make(map[string]string),
+	}
+
+	for k, v := range log.GetNamedLevels() {
+		res.Named[k] = strings.ToLower(v.String())
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	_ = e.Encode(res)
+}
@@ -0,0 +1,86 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package httpserver
+
+import (
+	"context"
+	"time"
+
+	loglvl "github.com/nabbar/golib/logger/level"
+)
+
+type ctxKey uint8
+
+const ctxKeyDrain ctxKey = iota
+
+// DrainFromContext return the drain channel of the server serving the request of the given context.
+// The channel is closed as soon as the server start draining, to let long-polling, websocket or sse
+// handlers end their streams early. A nil channel is returned if the context is not a request context
+// of a server, and so will block forever into a select.
+func DrainFromContext(ctx context.Context) <-chan struct{} {
+	if ctx == nil {
+		return nil
+	} else if c, k := ctx.Value(ctxKeyDrain).(<-chan struct{}); k {
+		return c
+	}
+
+	return nil
+}
+
+func (o *srv) Draining() <-chan struct{} {
+	o.y.m.Lock()
+	defer o.y.m.Unlock()
+
+	return o.y.d
+}
+
+// drainDelay wait the drain delay defined into the config, if any, before the shutdown of the server.
+func (o *srv) drainDelay(ctx context.Context) {
+	var cfg = o.GetConfig()
+
+	if cfg == nil || cfg.DrainDelay <= 0 {
+		return
+	}
+
+	o.logger().Entry(loglvl.InfoLevel, "HTTP Server is draining, waiting before shutdown").FieldAdd("drain_delay", cfg.DrainDelay.String()).Log()
+
+	var t = time.NewTimer(cfg.DrainDelay.Time())
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+	case <-ctx.Done():
+	}
+}
+
+func isClosed(c chan struct{}) bool {
+	select {
+	case <-c:
+		return true
+	default:
+		return false
+	}
+}
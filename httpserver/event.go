@@ -0,0 +1,78 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package httpserver
+
+import (
+	"time"
+
+	libevt "github.com/nabbar/golib/events"
+)
+
+// EventTopicState is the topic used to publish EventState on the registered event bus.
+const EventTopicState = "httpserver.state"
+
+// EventState is published on each state change of the server if an event bus is registered.
+// The event is published without blocking the server: it is dropped for a subscriber with a full buffer.
+type EventState struct {
+	Name     string
+	Bind     string
+	TLS      bool
+	Previous State
+	State    State
+	Error    error
+	Time     time.Time
+}
+
+func (o *srv) RegisterEventBus(bus libevt.Bus) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.e = bus
+}
+
+func (o *srv) getEventBus() libevt.Bus {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	return o.e
+}
+
+func (o *srv) publishState(trn StateTransition, tls bool) {
+	if b := o.getEventBus(); b == nil || b.IsClosed() {
+		return
+	} else {
+		_ = b.TryPublish(EventTopicState, EventState{
+			Name:     o.GetName(),
+			Bind:     o.GetBindable(),
+			TLS:      tls,
+			Previous: trn.Previous,
+			State:    trn.Current,
+			Error:    trn.Error,
+			Time:     trn.Time,
+		})
+	}
+}
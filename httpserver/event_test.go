@@ -0,0 +1,109 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package httpserver_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	libevt "github.com/nabbar/golib/events"
+	libhts "github.com/nabbar/golib/httpserver"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("httpserver events", func() {
+	Context("publishing the states on a bus with a subscriber never reading", func() {
+		It("must not block the start and the stop of the server and drop the events", func() {
+			var (
+				adr = freeAddr()
+				bus = libevt.New(ctx)
+				blk = make(chan struct{})
+				drp = new(atomic.Int64)
+				cfg = libhts.Config{
+					Name:       "events",
+					Listen:     adr,
+					Expose:     "http://" + adr,
+					HandlerKey: "default",
+				}
+			)
+
+			defer func() {
+				close(blk)
+				_ = bus.Close()
+			}()
+
+			bus.RegisterFuncError(func(topic string, err error) {
+				drp.Add(1)
+			})
+
+			_, err := bus.Subscribe(ctx, libhts.EventTopicState, 0, func(ctx context.Context, topic string, evt any) {
+				<-blk
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			cfg.RegisterHandlerFunc(func() map[string]http.Handler {
+				return map[string]http.Handler{
+					"default": bodyHandler("default"),
+				}
+			})
+
+			srv, err := libhts.New(cfg, nil)
+			Expect(err).ToNot(HaveOccurred())
+			srv.RegisterEventBus(bus)
+
+			var start = time.Now()
+			Expect(srv.Start(ctx)).ToNot(HaveOccurred())
+
+			Eventually(func() error {
+				c, e := net.Dial("tcp", adr)
+				if e == nil {
+					_ = c.Close()
+				}
+				return e
+			}, 5*time.Second, 50*time.Millisecond).ShouldNot(HaveOccurred())
+
+			cli := &http.Client{Timeout: 2 * time.Second}
+			defer cli.CloseIdleConnections()
+
+			rsp, err := cli.Get("http://" + adr + "/")
+			Expect(err).ToNot(HaveOccurred())
+			b, err := io.ReadAll(rsp.Body)
+			_ = rsp.Body.Close()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(b)).To(Equal("default"))
+
+			Expect(srv.Stop(ctx)).ToNot(HaveOccurred())
+			Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+			Expect(srv.State()).To(Equal(libhts.StateStopped))
+			Expect(drp.Load()).To(BeNumerically(">", 0))
+		})
+	})
+})
@@ -0,0 +1,69 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package httpserver
+
+import (
+	"net/http"
+
+	srvgrd "github.com/nabbar/golib/httpserver/guard"
+)
+
+// guardHandler return the given handler protected by the guard defined into the config, if any.
+func (o *srv) guardHandler(h http.Handler) (http.Handler, error) {
+	var cfg = o.GetConfig()
+
+	if cfg == nil || !cfg.Guard.IsEnabled() {
+		o.setGuard(nil)
+		return h, nil
+	}
+
+	g, e := srvgrd.New(cfg.Guard)
+
+	if e != nil {
+		return nil, ErrorServerValidate.Error(e)
+	}
+
+	o.setGuard(g)
+	return g.Handler(h), nil
+}
+
+func (o *srv) setGuard(g srvgrd.Guard) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.g = g
+}
+
+func (o *srv) GuardStats() srvgrd.Stats {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	if o.g == nil {
+		return srvgrd.Stats{}
+	}
+
+	return o.g.Stats()
+}
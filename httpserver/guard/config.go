@@ -0,0 +1,133 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package guard
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	libval "github.com/go-playground/validator/v10"
+	arccmp "github.com/nabbar/golib/archive/compress"
+	libdur "github.com/nabbar/golib/duration"
+)
+
+const (
+	DefaultIdleTimeout = 10 * time.Minute
+
+	// HeaderRetryAfter is the header sent with rate limited responses.
+	HeaderRetryAfter = "Retry-After"
+	// HeaderForwardedFor is the header read to find the client ip behind a trusted proxy.
+	HeaderForwardedFor = "X-Forwarded-For"
+	// HeaderRealIP is the header read to find the client ip behind a trusted proxy if no X-Forwarded-For is given.
+	HeaderRealIP = "X-Real-IP"
+)
+
+type RateConfig struct {
+	// Rate is the number of requests allowed by second for each client. Zero disable the rate limiting.
+	Rate float64 `mapstructure:"rate" json:"rate" yaml:"rate" toml:"rate" validate:"gte=0"`
+
+	// Burst is the max number of requests allowed at once for a client.
+	// If lower than 1, the rate rounded up is used.
+	Burst int `mapstructure:"burst" json:"burst" yaml:"burst" toml:"burst" validate:"gte=0"`
+
+	// KeyHeader is the request header used to identify a client (an api key header for example)
+	// instead of its ip. Requests without this header are identified by their client ip.
+	KeyHeader string `mapstructure:"key_header" json:"key_header" yaml:"key_header" toml:"key_header"`
+
+	// IdleTimeout is the duration after which the state of an idle client is dropped. Default is 10 minutes.
+	IdleTimeout libdur.Duration `mapstructure:"idle_timeout" json:"idle_timeout" yaml:"idle_timeout" toml:"idle_timeout"`
+}
+
+type Config struct {
+	// Allow is the list of ip or cidr networks allowed. If not empty, any other client is rejected.
+	Allow []string `mapstructure:"allow" json:"allow" yaml:"allow" toml:"allow" validate:"omitempty,dive,cidr|ip"`
+
+	// Deny is the list of ip or cidr networks rejected. Deny is checked before Allow.
+	Deny []string `mapstructure:"deny" json:"deny" yaml:"deny" toml:"deny" validate:"omitempty,dive,cidr|ip"`
+
+	// TrustedProxies is the list of ip or cidr networks of the proxies allowed to give the client ip
+	// with the X-Forwarded-For or X-Real-IP headers. If empty, these headers are ignored.
+	TrustedProxies []string `mapstructure:"trusted_proxies" json:"trusted_proxies" yaml:"trusted_proxies" toml:"trusted_proxies" validate:"omitempty,dive,cidr|ip"`
+
+	// RateLimit define the token bucket limiter applied for each client.
+	RateLimit RateConfig `mapstructure:"rate_limit" json:"rate_limit" yaml:"rate_limit" toml:"rate_limit"`
+
+	// Decompress enable the decompression of the request bodies sent with a Content-Encoding,
+	// limited by DecompressLimits to protect the handlers against decompression bombs.
+	Decompress bool `mapstructure:"decompress" json:"decompress" yaml:"decompress" toml:"decompress"`
+
+	// DecompressLimits define the max decompressed size and ratio of a request body.
+	DecompressLimits arccmp.Limits `mapstructure:"decompress_limits" json:"decompress_limits" yaml:"decompress_limits" toml:"decompress_limits"`
+}
+
+// IsEnabled return true if at least one filter, the rate limiting or the decompression is defined.
+func (c Config) IsEnabled() bool {
+	return len(c.Allow) > 0 || len(c.Deny) > 0 || c.RateLimit.Rate > 0 || c.Decompress
+}
+
+func (c Config) Clone() Config {
+	return Config{
+		Allow:          append(make([]string, 0, len(c.Allow)), c.Allow...),
+		Deny:           append(make([]string, 0, len(c.Deny)), c.Deny...),
+		TrustedProxies: append(make([]string, 0, len(c.TrustedProxies)), c.TrustedProxies...),
+		RateLimit:      c.RateLimit,
+
+		Decompress:       c.Decompress,
+		DecompressLimits: c.DecompressLimits,
+	}
+}
+
+func (c Config) Validate() error {
+	if er := libval.New().Struct(c); er != nil {
+		if e, ok := er.(*libval.InvalidValidationError); ok {
+			return e
+		}
+
+		for _, e := range er.(libval.ValidationErrors) {
+			//nolint goerr113
+			return fmt.Errorf("config field '%s' is not validated by constraint '%s'", e.Namespace(), e.ActualTag())
+		}
+	}
+
+	return nil
+}
+
+func (c RateConfig) getBurst() float64 {
+	if c.Burst > 0 {
+		return float64(c.Burst)
+	}
+
+	return math.Max(1, math.Ceil(c.Rate))
+}
+
+func (c RateConfig) getIdleTimeout() time.Duration {
+	if c.IdleTimeout > 0 {
+		return c.IdleTimeout.Time()
+	}
+
+	return DefaultIdleTimeout
+}
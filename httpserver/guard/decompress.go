@@ -0,0 +1,124 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package guard
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	arccmp "github.com/nabbar/golib/archive/compress"
+)
+
+const (
+	// HeaderContentEncoding is the request header giving the compression of the body.
+	HeaderContentEncoding = "Content-Encoding"
+)
+
+// Decompress return a http middleware decompressing the request bodies sent with a supported Content-Encoding
+// (gzip, bzip2, xz, lz4, zstd, br) with the given limits. Reading the body of a request exceeding the limits fails
+// with an error wrapping arccmp.ErrDecompressionBomb. Unsupported encodings are rejected with a
+// 415 Unsupported Media Type response.
+func Decompress(lim arccmp.Limits) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return decompress(next, lim, nil)
+	}
+}
+
+func decompress(next http.Handler, lim arccmp.Limits, bomb *atomic.Uint64) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var enc = strings.ToLower(strings.TrimSpace(r.Header.Get(HeaderContentEncoding)))
+
+		if len(enc) < 1 || enc == "identity" || r.Body == nil || r.Body == http.NoBody {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		alg, ok := parseEncoding(enc)
+
+		if !ok {
+			http.Error(w, http.StatusText(http.StatusUnsupportedMediaType), http.StatusUnsupportedMediaType)
+			return
+		}
+
+		rdr, err := alg.ReaderLimit(r.Body, lim)
+
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+
+		r.Body = &body{r: rdr, c: r.Body, b: bomb}
+		r.ContentLength = -1
+		r.Header.Del(HeaderContentEncoding)
+		r.Header.Del("Content-Length")
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func parseEncoding(enc string) (arccmp.Algorithm, bool) {
+	switch enc {
+	case "gzip", "x-gzip":
+		return arccmp.Gzip, true
+	case "bzip2", "x-bzip2":
+		return arccmp.Bzip2, true
+	case "xz", "x-xz":
+		return arccmp.XZ, true
+	case "lz4", "x-lz4":
+		return arccmp.LZ4, true
+	case "zstd":
+		return arccmp.Zstd, true
+	case "br":
+		return arccmp.Brotli, true
+	default:
+		return arccmp.None, false
+	}
+}
+
+type body struct {
+	r io.ReadCloser
+	c io.Closer
+	b *atomic.Uint64
+	f atomic.Bool
+}
+
+func (o *body) Read(p []byte) (int, error) {
+	n, e := o.r.Read(p)
+
+	if e != nil && o.b != nil && errors.Is(e, arccmp.ErrDecompressionBomb) && o.f.CompareAndSwap(false, true) {
+		o.b.Add(1)
+	}
+
+	return n, e
+}
+
+func (o *body) Close() error {
+	_ = o.r.Close()
+	return o.c.Close()
+}
@@ -0,0 +1,237 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package guard_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	libdur "github.com/nabbar/golib/duration"
+	htpgrd "github.com/nabbar/golib/httpserver/guard"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func newGuard(cfg htpgrd.Config) (htpgrd.Guard, http.Handler) {
+	g, err := htpgrd.New(cfg)
+	Expect(err).ToNot(HaveOccurred())
+
+	return g, g.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+}
+
+// serve return the recorded response of the handler for a request from the given remote address.
+func serve(h http.Handler, remote string, hdr map[string]string) *httptest.ResponseRecorder {
+	var (
+		rec = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodGet, "/", nil)
+	)
+
+	req.RemoteAddr = remote
+
+	for k, v := range hdr {
+		req.Header.Set(k, v)
+	}
+
+	h.ServeHTTP(rec, req)
+
+	return rec
+}
+
+var _ = Describe("httpserver/guard", func() {
+	Context("with invalid networks", func() {
+		It("must fail to create the guard", func() {
+			_, err := htpgrd.New(htpgrd.Config{Allow: []string{"10.0.0.0/33"}})
+			Expect(err).To(HaveOccurred())
+
+			_, err = htpgrd.New(htpgrd.Config{TrustedProxies: []string{"proxy"}})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("with allow and deny lists", func() {
+		var (
+			g htpgrd.Guard
+			h http.Handler
+		)
+
+		BeforeEach(func() {
+			g, h = newGuard(htpgrd.Config{
+				Allow: []string{"10.0.0.0/8", "192.168.1.1", "2001:db8::/32"},
+				Deny:  []string{"10.1.0.0/16"},
+			})
+		})
+
+		DescribeTable("must filter the client",
+			func(remote string, code int) {
+				Expect(serve(h, remote, nil).Code).To(Equal(code))
+			},
+			Entry("allowed network", "10.2.3.4:1234", http.StatusOK),
+			Entry("allowed ip", "192.168.1.1:1234", http.StatusOK),
+			Entry("allowed ipv6 network", "[2001:db8::1]:1234", http.StatusOK),
+			Entry("denied network into an allowed network", "10.1.2.3:1234", http.StatusForbidden),
+			Entry("not allowed ip", "192.168.1.2:1234", http.StatusForbidden),
+			Entry("not allowed ipv6", "[::1]:1234", http.StatusForbidden),
+			Entry("invalid remote address", "unknown", http.StatusForbidden),
+		)
+
+		It("must count the allowed and denied requests", func() {
+			serve(h, "10.2.3.4:1234", nil)
+			serve(h, "10.1.2.3:1234", nil)
+			serve(h, "8.8.8.8:1234", nil)
+
+			var s = g.Stats()
+			Expect(s.Allowed).To(BeNumerically("==", 1))
+			Expect(s.Denied).To(BeNumerically("==", 2))
+			Expect(s.Limited).To(BeNumerically("==", 0))
+		})
+	})
+
+	Context("with a deny list only", func() {
+		It("must allow any other client", func() {
+			_, h := newGuard(htpgrd.Config{Deny: []string{"10.0.0.0/8"}})
+
+			Expect(serve(h, "10.0.0.1:1234", nil).Code).To(Equal(http.StatusForbidden))
+			Expect(serve(h, "8.8.8.8:1234", nil).Code).To(Equal(http.StatusOK))
+		})
+	})
+
+	Context("with trusted proxies", func() {
+		var (
+			g htpgrd.Guard
+			h http.Handler
+		)
+
+		BeforeEach(func() {
+			g, h = newGuard(htpgrd.Config{
+				Deny:           []string{"9.9.9.9"},
+				TrustedProxies: []string{"10.0.0.1", "172.16.0.0/12"},
+			})
+		})
+
+		DescribeTable("must find the client ip",
+			func(remote string, hdr map[string]string, ip string) {
+				var req = httptest.NewRequest(http.MethodGet, "/", nil)
+				req.RemoteAddr = remote
+
+				for k, v := range hdr {
+					req.Header.Set(k, v)
+				}
+
+				Expect(g.ClientIP(req).String()).To(Equal(ip))
+			},
+			Entry("direct client", "1.2.3.4:1234", nil, "1.2.3.4"),
+			Entry("forwarded header of an untrusted client",
+				"1.2.3.4:1234", map[string]string{htpgrd.HeaderForwardedFor: "8.8.8.8"}, "1.2.3.4"),
+			Entry("real ip header of an untrusted client",
+				"1.2.3.4:1234", map[string]string{htpgrd.HeaderRealIP: "8.8.8.8"}, "1.2.3.4"),
+			Entry("forwarded header of a trusted proxy",
+				"10.0.0.1:1234", map[string]string{htpgrd.HeaderForwardedFor: "8.8.8.8"}, "8.8.8.8"),
+			Entry("forwarded chain with a spoofed first hop",
+				"10.0.0.1:1234", map[string]string{htpgrd.HeaderForwardedFor: "7.7.7.7, 8.8.8.8, 172.16.0.5"}, "8.8.8.8"),
+			Entry("forwarded chain of trusted proxies only",
+				"10.0.0.1:1234", map[string]string{htpgrd.HeaderForwardedFor: "172.16.0.2, 172.16.0.3"}, "172.16.0.2"),
+			Entry("forwarded chain with an invalid hop",
+				"10.0.0.1:1234", map[string]string{htpgrd.HeaderForwardedFor: "8.8.8.8, unknown"}, "10.0.0.1"),
+			Entry("real ip header of a trusted proxy",
+				"10.0.0.1:1234", map[string]string{htpgrd.HeaderRealIP: "8.8.8.8"}, "8.8.8.8"),
+			Entry("forwarded header before the real ip header",
+				"10.0.0.1:1234", map[string]string{htpgrd.HeaderForwardedFor: "8.8.8.8", htpgrd.HeaderRealIP: "7.7.7.7"}, "8.8.8.8"),
+		)
+
+		It("must join the forwarded headers given several times", func() {
+			var req = httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = "10.0.0.1:1234"
+			req.Header.Add(htpgrd.HeaderForwardedFor, "7.7.7.7")
+			req.Header.Add(htpgrd.HeaderForwardedFor, "8.8.8.8, 172.16.0.5")
+
+			Expect(g.ClientIP(req).String()).To(Equal("8.8.8.8"))
+		})
+
+		It("must filter the client ip given by a trusted proxy only", func() {
+			Expect(serve(h, "10.0.0.1:1234", map[string]string{htpgrd.HeaderForwardedFor: "9.9.9.9"}).Code).To(Equal(http.StatusForbidden))
+			Expect(serve(h, "10.0.0.1:1234", map[string]string{htpgrd.HeaderForwardedFor: "8.8.8.8"}).Code).To(Equal(http.StatusOK))
+			Expect(serve(h, "8.8.8.8:1234", map[string]string{htpgrd.HeaderForwardedFor: "1.1.1.1"}).Code).To(Equal(http.StatusOK))
+		})
+	})
+
+	Context("with a rate limit", func() {
+		It("must limit each client to its burst and refill at the rate", func() {
+			g, h := newGuard(htpgrd.Config{
+				RateLimit: htpgrd.RateConfig{Rate: 10, Burst: 2},
+			})
+
+			Expect(serve(h, "1.1.1.1:1234", nil).Code).To(Equal(http.StatusOK))
+			Expect(serve(h, "1.1.1.1:1234", nil).Code).To(Equal(http.StatusOK))
+
+			var rec = serve(h, "1.1.1.1:1234", nil)
+			Expect(rec.Code).To(Equal(http.StatusTooManyRequests))
+			Expect(rec.Header().Get(htpgrd.HeaderRetryAfter)).To(Equal("1"))
+
+			// another client has its own bucket
+			Expect(serve(h, "2.2.2.2:1234", nil).Code).To(Equal(http.StatusOK))
+
+			time.Sleep(150 * time.Millisecond)
+			Expect(serve(h, "1.1.1.1:1234", nil).Code).To(Equal(http.StatusOK))
+
+			var s = g.Stats()
+			Expect(s.Allowed).To(BeNumerically("==", 4))
+			Expect(s.Limited).To(BeNumerically("==", 1))
+			Expect(s.Clients).To(Equal(2))
+		})
+
+		It("must identify the clients by the key header if given", func() {
+			_, h := newGuard(htpgrd.Config{
+				RateLimit: htpgrd.RateConfig{Rate: 0.1, Burst: 1, KeyHeader: "X-Api-Key"},
+			})
+
+			Expect(serve(h, "1.1.1.1:1234", map[string]string{"X-Api-Key": "a"}).Code).To(Equal(http.StatusOK))
+			Expect(serve(h, "1.1.1.1:1234", map[string]string{"X-Api-Key": "a"}).Code).To(Equal(http.StatusTooManyRequests))
+			Expect(serve(h, "1.1.1.1:1234", map[string]string{"X-Api-Key": "b"}).Code).To(Equal(http.StatusOK))
+			Expect(serve(h, "1.1.1.1:1234", nil).Code).To(Equal(http.StatusOK))
+			Expect(serve(h, "1.1.1.1:1234", nil).Code).To(Equal(http.StatusTooManyRequests))
+		})
+
+		It("must reset the state of the clients idle since the idle timeout", func() {
+			g, h := newGuard(htpgrd.Config{
+				RateLimit: htpgrd.RateConfig{Rate: 0.1, Burst: 1, IdleTimeout: libdur.ParseDuration(100 * time.Millisecond)},
+			})
+
+			Expect(serve(h, "1.1.1.1:1234", nil).Code).To(Equal(http.StatusOK))
+			Expect(serve(h, "1.1.1.1:1234", nil).Code).To(Equal(http.StatusTooManyRequests))
+			Expect(g.Stats().Clients).To(Equal(1))
+
+			// the refill at this rate is far lower than one token: only the reset allows the request
+			time.Sleep(150 * time.Millisecond)
+			Expect(serve(h, "2.2.2.2:1234", nil).Code).To(Equal(http.StatusOK))
+			Expect(g.Stats().Clients).To(Equal(1))
+			Expect(serve(h, "1.1.1.1:1234", nil).Code).To(Equal(http.StatusOK))
+			Expect(g.Stats().Clients).To(Equal(2))
+		})
+	})
+})
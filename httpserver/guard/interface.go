@@ -0,0 +1,95 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package guard
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// Stats is the snapshot of the counters of a guard.
+type Stats struct {
+	// Allowed is the number of requests forwarded to the next handler.
+	Allowed uint64 `json:"allowed"`
+	// Denied is the number of requests rejected by the allow or deny lists.
+	Denied uint64 `json:"denied"`
+	// Limited is the number of requests rejected by the rate limiter.
+	Limited uint64 `json:"limited"`
+	// Clients is the number of clients currently tracked by the rate limiter.
+	Clients int `json:"clients"`
+	// Bombs is the number of request bodies rejected by the decompression limits.
+	Bombs uint64 `json:"bombs"`
+}
+
+type Guard interface {
+	// Handler return the given handler protected by the allow / deny lists and the rate limiter.
+	// Denied clients receive a 403 Forbidden response, limited clients a 429 Too Many Requests
+	// response with a Retry-After header. If enabled, compressed request bodies are decompressed
+	// with the configured limits (see Decompress).
+	Handler(next http.Handler) http.Handler
+
+	// ClientIP return the ip of the client of the given request, using the forwarded headers
+	// only if the request is coming from a trusted proxy.
+	ClientIP(r *http.Request) net.IP
+
+	// Stats return the current counters of the guard.
+	Stats() Stats
+}
+
+func New(cfg Config) (Guard, error) {
+	if e := cfg.Validate(); e != nil {
+		return nil, e
+	}
+
+	var (
+		err error
+		res = &grd{
+			r: cfg.RateLimit,
+			z: cfg.Decompress,
+			b: cfg.DecompressLimits,
+			l: &limiter{
+				m: sync.Mutex{},
+				b: make(map[string]*bucket),
+			},
+			na: new(atomic.Uint64),
+			nd: new(atomic.Uint64),
+			nl: new(atomic.Uint64),
+			nb: new(atomic.Uint64),
+		}
+	)
+
+	if res.a, err = parseNetworks(cfg.Allow); err != nil {
+		return nil, err
+	} else if res.d, err = parseNetworks(cfg.Deny); err != nil {
+		return nil, err
+	} else if res.p, err = parseNetworks(cfg.TrustedProxies); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
@@ -0,0 +1,94 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package guard
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+type bucket struct {
+	t float64   // tokens
+	l time.Time // last refill
+}
+
+type limiter struct {
+	m sync.Mutex
+	b map[string]*bucket // buckets by client key
+	p time.Time          // last purge
+}
+
+// take consume one token of the client bucket. If no token is available,
+// it returns false with the duration to wait for the next token.
+func (o *limiter) take(key string, rate, burst float64, idle time.Duration) (bool, time.Duration) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	var now = time.Now()
+
+	if now.Sub(o.p) > idle {
+		o.purge(now, idle)
+	}
+
+	b, ok := o.b[key]
+
+	if !ok {
+		b = &bucket{
+			t: burst,
+			l: now,
+		}
+		o.b[key] = b
+	} else {
+		b.t = math.Min(burst, b.t+now.Sub(b.l).Seconds()*rate)
+		b.l = now
+	}
+
+	if b.t < 1 {
+		return false, durationUntil(1-b.t, rate)
+	}
+
+	b.t--
+	return true, 0
+}
+
+// purge drop the buckets idle since the given duration. Must be called with the lock held.
+func (o *limiter) purge(now time.Time, idle time.Duration) {
+	o.p = now
+
+	for k, b := range o.b {
+		if now.Sub(b.l) > idle {
+			delete(o.b, k)
+		}
+	}
+}
+
+func (o *limiter) len() int {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	return len(o.b)
+}
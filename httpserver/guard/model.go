@@ -0,0 +1,192 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package guard
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	arccmp "github.com/nabbar/golib/archive/compress"
+)
+
+type grd struct {
+	a []*net.IPNet  // allow
+	d []*net.IPNet  // deny
+	p []*net.IPNet  // trusted proxies
+	r RateConfig    // rate limit config
+	l *limiter      // rate limiter
+	z bool          // decompress
+	b arccmp.Limits // decompress limits
+
+	na *atomic.Uint64 // allowed
+	nd *atomic.Uint64 // denied
+	nl *atomic.Uint64 // limited
+	nb *atomic.Uint64 // bombs
+}
+
+func (o *grd) Handler(next http.Handler) http.Handler {
+	if o.z {
+		next = decompress(next, o.b, o.nb)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var ip = o.ClientIP(r)
+
+		if !o.isAllowed(ip) {
+			o.nd.Add(1)
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+
+		if o.r.Rate > 0 {
+			if ok, wait := o.l.take(o.clientKey(r, ip), o.r.Rate, o.r.getBurst(), o.r.getIdleTimeout()); !ok {
+				o.nl.Add(1)
+				w.Header().Set(HeaderRetryAfter, strconv.FormatInt(int64(math.Ceil(wait.Seconds())), 10))
+				http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+				return
+			}
+		}
+
+		o.na.Add(1)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (o *grd) ClientIP(r *http.Request) net.IP {
+	var ip = parseHostIP(r.RemoteAddr)
+
+	if ip == nil || !contains(o.p, ip) {
+		return ip
+	}
+
+	// walk the forwarded chain from the nearest proxy: the first untrusted hop is the client
+	if h := r.Header.Values(HeaderForwardedFor); len(h) > 0 {
+		var hop = strings.Split(strings.Join(h, ","), ",")
+
+		for i := len(hop) - 1; i >= 0; i-- {
+			if p := net.ParseIP(strings.TrimSpace(hop[i])); p == nil {
+				return ip
+			} else if ip = p; !contains(o.p, p) {
+				return p
+			}
+		}
+
+		return ip
+	}
+
+	if p := net.ParseIP(strings.TrimSpace(r.Header.Get(HeaderRealIP))); p != nil {
+		return p
+	}
+
+	return ip
+}
+
+func (o *grd) Stats() Stats {
+	return Stats{
+		Allowed: o.na.Load(),
+		Denied:  o.nd.Load(),
+		Limited: o.nl.Load(),
+		Clients: o.l.len(),
+		Bombs:   o.nb.Load(),
+	}
+}
+
+func (o *grd) isAllowed(ip net.IP) bool {
+	if len(o.a) < 1 && len(o.d) < 1 {
+		return true
+	} else if ip == nil {
+		return false
+	} else if contains(o.d, ip) {
+		return false
+	} else if len(o.a) > 0 {
+		return contains(o.a, ip)
+	}
+
+	return true
+}
+
+func (o *grd) clientKey(r *http.Request, ip net.IP) string {
+	if len(o.r.KeyHeader) > 0 {
+		if k := r.Header.Get(o.r.KeyHeader); len(k) > 0 {
+			return "h:" + k
+		}
+	}
+
+	if ip == nil {
+		return "r:" + r.RemoteAddr
+	}
+
+	return "i:" + ip.String()
+}
+
+func parseHostIP(addr string) net.IP {
+	if h, _, e := net.SplitHostPort(addr); e == nil {
+		addr = h
+	}
+
+	return net.ParseIP(addr)
+}
+
+func parseNetworks(lst []string) ([]*net.IPNet, error) {
+	var res = make([]*net.IPNet, 0, len(lst))
+
+	for _, s := range lst {
+		s = strings.TrimSpace(s)
+
+		if _, n, e := net.ParseCIDR(s); e == nil {
+			res = append(res, n)
+		} else if ip := net.ParseIP(s); ip == nil {
+			return nil, fmt.Errorf("%w: '%s'", ErrInvalidNetwork, s)
+		} else if ip4 := ip.To4(); ip4 != nil {
+			res = append(res, &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)})
+		} else {
+			res = append(res, &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)})
+		}
+	}
+
+	return res, nil
+}
+
+func contains(lst []*net.IPNet, ip net.IP) bool {
+	for _, n := range lst {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// durationUntil return the duration needed to get the missing tokens at the given rate.
+func durationUntil(missing, rate float64) time.Duration {
+	return time.Duration(missing / rate * float64(time.Second))
+}
@@ -46,13 +46,19 @@ func (o *srv) HandlerGet(key string) http.Handler {
 		return srvtps.NewBadHandler()
 	} else if l := o.h(); len(l) < 1 {
 		return srvtps.NewBadHandler()
-	} else if h, k := l[key]; !k {
+	} else if r, k := srvtps.HandlerResolveKey(l, key); !k {
+		return srvtps.NewBadHandler()
+	} else if h := l[r]; h == nil {
 		return srvtps.NewBadHandler()
 	} else {
 		return h
 	}
 }
 
+// HandlerGetValidKey return the handler key really used by the server, resolved from the fallback chain
+// of the config handler key (see srvtps.HandlerResolveKey), or BadHandlerName if no handler is matching.
+// Since the fallback chain, this is the resolved key (ex: "api" for the chain "api-v2,api") and no more
+// the configured key, so the banner and the monitor report the handler really served.
 func (o *srv) HandlerGetValidKey() string {
 	if i, l := o.c.Load(cfgHandler); !l {
 		return srvtps.BadHandlerName
@@ -64,8 +70,10 @@ func (o *srv) HandlerGetValidKey() string {
 		return srvtps.BadHandlerName
 	} else if v, k := i.(string); !k {
 		return srvtps.BadHandlerName
+	} else if r, f := o.handlerResolve(v); !f {
+		return srvtps.BadHandlerName
 	} else {
-		return v
+		return r
 	}
 }
 
@@ -78,11 +86,23 @@ func (o *srv) HandlerHas(key string) bool {
 	} else if l := o.h(); len(l) < 1 {
 		return false
 	} else {
-		_, k := l[key]
+		_, k := srvtps.HandlerResolveKey(l, key)
 		return k
 	}
 }
 
+// handlerResolve return the handler key matching the given fallback chain into the current handler list.
+func (o *srv) handlerResolve(key string) (string, bool) {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	if o.h == nil {
+		return "", false
+	}
+
+	return srvtps.HandlerResolveKey(o.h(), key)
+}
+
 func (o *srv) HandlerStoreFct(key string) {
 	o.c.Store(cfgHandler, func() http.Handler {
 		return o.HandlerGet(key)
@@ -0,0 +1,141 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package headers
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	libval "github.com/go-playground/validator/v10"
+	libdur "github.com/nabbar/golib/duration"
+)
+
+type CORSConfig struct {
+	// AllowOrigins is the list of origins allowed to do cross origin requests. The CORS headers are
+	// not sent if empty. The value "*" allow any origin, and a "*." prefix on the host allow any subdomain
+	// (e.g. "https://*.example.com").
+	AllowOrigins []string `mapstructure:"allow_origins" json:"allow_origins" yaml:"allow_origins" toml:"allow_origins"`
+
+	// AllowMethods is the list of methods allowed for cross origin requests.
+	// Default is GET, HEAD, POST, PUT, PATCH and DELETE.
+	AllowMethods []string `mapstructure:"allow_methods" json:"allow_methods" yaml:"allow_methods" toml:"allow_methods"`
+
+	// AllowHeaders is the list of request headers allowed for cross origin requests.
+	// If empty, the headers requested by the preflight request are allowed.
+	AllowHeaders []string `mapstructure:"allow_headers" json:"allow_headers" yaml:"allow_headers" toml:"allow_headers"`
+
+	// ExposeHeaders is the list of response headers exposed to the cross origin clients.
+	ExposeHeaders []string `mapstructure:"expose_headers" json:"expose_headers" yaml:"expose_headers" toml:"expose_headers"`
+
+	// AllowCredentials allow the cross origin requests to send cookies and authorization headers.
+	// It cannot be used with the "*" origin.
+	AllowCredentials bool `mapstructure:"allow_credentials" json:"allow_credentials" yaml:"allow_credentials" toml:"allow_credentials"`
+
+	// MaxAge is the duration the result of a preflight request can be cached by the client.
+	MaxAge libdur.Duration `mapstructure:"max_age" json:"max_age" yaml:"max_age" toml:"max_age"`
+}
+
+type SecurityConfig struct {
+	// HSTSMaxAge is the max age of the Strict-Transport-Security header, only sent on TLS connections.
+	// The header is not sent if zero.
+	HSTSMaxAge libdur.Duration `mapstructure:"hsts_max_age" json:"hsts_max_age" yaml:"hsts_max_age" toml:"hsts_max_age"`
+
+	// HSTSIncludeSubdomains add the includeSubDomains directive to the Strict-Transport-Security header.
+	HSTSIncludeSubdomains bool `mapstructure:"hsts_include_subdomains" json:"hsts_include_subdomains" yaml:"hsts_include_subdomains" toml:"hsts_include_subdomains"`
+
+	// HSTSPreload add the preload directive to the Strict-Transport-Security header.
+	HSTSPreload bool `mapstructure:"hsts_preload" json:"hsts_preload" yaml:"hsts_preload" toml:"hsts_preload"`
+
+	// FrameOptions is the value of the X-Frame-Options header: DENY or SAMEORIGIN. Not sent if empty.
+	FrameOptions string `mapstructure:"frame_options" json:"frame_options" yaml:"frame_options" toml:"frame_options" validate:"omitempty,oneof=DENY SAMEORIGIN deny sameorigin"`
+
+	// ContentSecurityPolicy is the value of the Content-Security-Policy header. Not sent if empty.
+	ContentSecurityPolicy string `mapstructure:"content_security_policy" json:"content_security_policy" yaml:"content_security_policy" toml:"content_security_policy"`
+
+	// ReferrerPolicy is the value of the Referrer-Policy header. Not sent if empty.
+	ReferrerPolicy string `mapstructure:"referrer_policy" json:"referrer_policy" yaml:"referrer_policy" toml:"referrer_policy" validate:"omitempty,oneof=no-referrer no-referrer-when-downgrade origin origin-when-cross-origin same-origin strict-origin strict-origin-when-cross-origin unsafe-url"`
+
+	// ContentTypeNoSniff send the header X-Content-Type-Options with the nosniff value.
+	ContentTypeNoSniff bool `mapstructure:"content_type_nosniff" json:"content_type_nosniff" yaml:"content_type_nosniff" toml:"content_type_nosniff"`
+}
+
+type Config struct {
+	// CORS define the cross origin resource sharing policy.
+	CORS CORSConfig `mapstructure:"cors" json:"cors" yaml:"cors" toml:"cors"`
+
+	// Security define the security headers added to each response.
+	Security SecurityConfig `mapstructure:"security" json:"security" yaml:"security" toml:"security"`
+}
+
+// IsEnabled return true if the CORS policy or at least one security header is defined.
+func (c Config) IsEnabled() bool {
+	var s = c.Security
+
+	return len(c.CORS.AllowOrigins) > 0 || s.HSTSMaxAge > 0 || len(s.FrameOptions) > 0 ||
+		len(s.ContentSecurityPolicy) > 0 || len(s.ReferrerPolicy) > 0 || s.ContentTypeNoSniff
+}
+
+func (c Config) Clone() Config {
+	var r = c
+
+	r.CORS.AllowOrigins = append(make([]string, 0, len(c.CORS.AllowOrigins)), c.CORS.AllowOrigins...)
+	r.CORS.AllowMethods = append(make([]string, 0, len(c.CORS.AllowMethods)), c.CORS.AllowMethods...)
+	r.CORS.AllowHeaders = append(make([]string, 0, len(c.CORS.AllowHeaders)), c.CORS.AllowHeaders...)
+	r.CORS.ExposeHeaders = append(make([]string, 0, len(c.CORS.ExposeHeaders)), c.CORS.ExposeHeaders...)
+
+	return r
+}
+
+func (c Config) Validate() error {
+	if er := libval.New().Struct(c); er != nil {
+		if e, ok := er.(*libval.InvalidValidationError); ok {
+			return e
+		}
+
+		for _, e := range er.(libval.ValidationErrors) {
+			//nolint goerr113
+			return fmt.Errorf("config field '%s' is not validated by constraint '%s'", e.Namespace(), e.ActualTag())
+		}
+	}
+
+	for _, o := range c.CORS.AllowOrigins {
+		if o == "*" {
+			if c.CORS.AllowCredentials {
+				return ErrInvalidCredentials
+			}
+			continue
+		}
+
+		if u, e := url.Parse(o); e != nil || len(u.Scheme) < 1 || len(u.Host) < 1 || (len(u.Path) > 0 && u.Path != "/") {
+			return fmt.Errorf("%w: '%s'", ErrInvalidOrigin, o)
+		} else if strings.Contains(strings.TrimPrefix(u.Host, "*."), "*") {
+			return fmt.Errorf("%w: '%s'", ErrInvalidOrigin, o)
+		}
+	}
+
+	return nil
+}
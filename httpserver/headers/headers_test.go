@@ -0,0 +1,283 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package headers_test
+
+import (
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	libdur "github.com/nabbar/golib/duration"
+	htphdr "github.com/nabbar/golib/httpserver/headers"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// serve return the recorded response of the handler and true if the next handler was called.
+func serve(h htphdr.Headers, req *http.Request) (*httptest.ResponseRecorder, bool) {
+	var (
+		rec = httptest.NewRecorder()
+		nxt = false
+	)
+
+	h.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nxt = true
+		_, _ = w.Write([]byte("ok"))
+	})).ServeHTTP(rec, req)
+
+	return rec, nxt
+}
+
+func newHeaders(cfg htphdr.Config) htphdr.Headers {
+	h, err := htphdr.New(cfg)
+	Expect(err).ToNot(HaveOccurred())
+
+	return h
+}
+
+func newRequest(method, origin string) *http.Request {
+	var req = httptest.NewRequest(method, "/api", nil)
+
+	if len(origin) > 0 {
+		req.Header.Set(htphdr.HeaderOrigin, origin)
+	}
+
+	return req
+}
+
+func newPreflight(origin, method, headers string) *http.Request {
+	var req = newRequest(http.MethodOptions, origin)
+
+	req.Header.Set(htphdr.HeaderAccessControlRequestMethod, method)
+
+	if len(headers) > 0 {
+		req.Header.Set(htphdr.HeaderAccessControlRequestHeaders, headers)
+	}
+
+	return req
+}
+
+var _ = Describe("httpserver/headers", func() {
+	Context("validating the config", func() {
+		DescribeTable("must reject the invalid origins",
+			func(cfg htphdr.CORSConfig, exp error) {
+				_, err := htphdr.New(htphdr.Config{CORS: cfg})
+				Expect(errors.Is(err, exp)).To(BeTrue(), "error: %v", err)
+			},
+			Entry("any origin with credentials", htphdr.CORSConfig{AllowOrigins: []string{"*"}, AllowCredentials: true}, htphdr.ErrInvalidCredentials),
+			Entry("origin without scheme", htphdr.CORSConfig{AllowOrigins: []string{"example.com"}}, htphdr.ErrInvalidOrigin),
+			Entry("origin with a path", htphdr.CORSConfig{AllowOrigins: []string{"https://example.com/app"}}, htphdr.ErrInvalidOrigin),
+			Entry("origin with an inner wildcard", htphdr.CORSConfig{AllowOrigins: []string{"https://app*.example.com"}}, htphdr.ErrInvalidOrigin),
+		)
+
+		It("must reject an invalid frame option", func() {
+			_, err := htphdr.New(htphdr.Config{Security: htphdr.SecurityConfig{FrameOptions: "ALLOW"}})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("with allowed origins and credentials", func() {
+		var h htphdr.Headers
+
+		BeforeEach(func() {
+			h = newHeaders(htphdr.Config{
+				CORS: htphdr.CORSConfig{
+					AllowOrigins:     []string{"https://app.example.com/", "https://*.example.org"},
+					ExposeHeaders:    []string{"X-Request-Id", "X-Total"},
+					AllowCredentials: true,
+					MaxAge:           libdur.ParseDuration(10 * time.Minute),
+				},
+			})
+		})
+
+		DescribeTable("must reflect only the allowed origins",
+			func(origin string, allowed bool) {
+				rec, nxt := serve(h, newRequest(http.MethodGet, origin))
+
+				Expect(nxt).To(BeTrue())
+				Expect(rec.Header().Values(htphdr.HeaderVary)).To(ContainElement(htphdr.HeaderOrigin))
+				Expect(h.IsOriginAllowed(origin)).To(Equal(allowed))
+
+				if allowed {
+					Expect(rec.Header().Get(htphdr.HeaderAccessControlAllowOrigin)).To(Equal(origin))
+					Expect(rec.Header().Get(htphdr.HeaderAccessControlAllowCredentials)).To(Equal("true"))
+					Expect(rec.Header().Get(htphdr.HeaderAccessControlExposeHeaders)).To(Equal("X-Request-Id, X-Total"))
+				} else {
+					Expect(rec.Header().Get(htphdr.HeaderAccessControlAllowOrigin)).To(BeEmpty())
+					Expect(rec.Header().Get(htphdr.HeaderAccessControlAllowCredentials)).To(BeEmpty())
+					Expect(rec.Header().Get(htphdr.HeaderAccessControlExposeHeaders)).To(BeEmpty())
+				}
+			},
+			Entry("exact origin", "https://app.example.com", true),
+			Entry("exact origin with another case", "https://APP.example.com", true),
+			Entry("subdomain of a wildcard origin", "https://a.example.org", true),
+			Entry("nested subdomain of a wildcard origin", "https://a.b.example.org", true),
+			Entry("domain of a wildcard origin", "https://example.org", false),
+			Entry("other scheme", "http://app.example.com", false),
+			Entry("other scheme of a wildcard origin", "http://a.example.org", false),
+			Entry("other port", "https://app.example.com:8443", false),
+			Entry("suffix of an allowed domain", "https://a.example.org.evil.com", false),
+			Entry("path into the subdomain", "https://evil.com/.example.org", false),
+			Entry("user info into the subdomain", "https://evil.com@a.example.org", false),
+			Entry("null origin", "null", false),
+		)
+
+		It("must not add the cors headers to a request without origin", func() {
+			rec, nxt := serve(h, newRequest(http.MethodGet, ""))
+
+			Expect(nxt).To(BeTrue())
+			Expect(rec.Header().Get(htphdr.HeaderAccessControlAllowOrigin)).To(BeEmpty())
+			Expect(rec.Header().Values(htphdr.HeaderVary)).To(ContainElement(htphdr.HeaderOrigin))
+		})
+
+		It("must answer the preflight request of an allowed origin", func() {
+			rec, nxt := serve(h, newPreflight("https://a.example.org", http.MethodPut, "Content-Type, X-Custom"))
+
+			Expect(nxt).To(BeFalse())
+			Expect(rec.Code).To(Equal(http.StatusNoContent))
+			Expect(rec.Header().Get(htphdr.HeaderAccessControlAllowOrigin)).To(Equal("https://a.example.org"))
+			Expect(rec.Header().Get(htphdr.HeaderAccessControlAllowCredentials)).To(Equal("true"))
+			Expect(rec.Header().Get(htphdr.HeaderAccessControlAllowMethods)).To(Equal(strings.Join(htphdr.DefaultAllowMethods, ", ")))
+			Expect(rec.Header().Get(htphdr.HeaderAccessControlAllowHeaders)).To(Equal("Content-Type, X-Custom"))
+			Expect(rec.Header().Get(htphdr.HeaderAccessControlMaxAge)).To(Equal("600"))
+			Expect(rec.Header().Get(htphdr.HeaderAccessControlExposeHeaders)).To(BeEmpty())
+			Expect(rec.Header().Values(htphdr.HeaderVary)).To(ConsistOf(
+				htphdr.HeaderOrigin,
+				htphdr.HeaderAccessControlRequestMethod,
+				htphdr.HeaderAccessControlRequestHeaders,
+			))
+		})
+
+		It("must forward the preflight request of an origin not allowed", func() {
+			rec, nxt := serve(h, newPreflight("https://evil.com", http.MethodPut, ""))
+
+			Expect(nxt).To(BeTrue())
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(rec.Header().Get(htphdr.HeaderAccessControlAllowOrigin)).To(BeEmpty())
+			Expect(rec.Header().Get(htphdr.HeaderAccessControlAllowMethods)).To(BeEmpty())
+		})
+
+		It("must forward an options request without request method", func() {
+			rec, nxt := serve(h, newRequest(http.MethodOptions, "https://app.example.com"))
+
+			Expect(nxt).To(BeTrue())
+			Expect(rec.Header().Get(htphdr.HeaderAccessControlAllowOrigin)).To(Equal("https://app.example.com"))
+			Expect(rec.Header().Get(htphdr.HeaderAccessControlAllowMethods)).To(BeEmpty())
+		})
+	})
+
+	Context("with any origin and the configured methods and headers", func() {
+		It("must answer with the wildcard origin and the configured lists", func() {
+			var h = newHeaders(htphdr.Config{
+				CORS: htphdr.CORSConfig{
+					AllowOrigins: []string{"*"},
+					AllowMethods: []string{"get", "post"},
+					AllowHeaders: []string{"Authorization"},
+				},
+			})
+
+			rec, nxt := serve(h, newPreflight("https://any.example.net", http.MethodPost, "X-Custom"))
+
+			Expect(nxt).To(BeFalse())
+			Expect(rec.Code).To(Equal(http.StatusNoContent))
+			Expect(rec.Header().Get(htphdr.HeaderAccessControlAllowOrigin)).To(Equal("*"))
+			Expect(rec.Header().Get(htphdr.HeaderAccessControlAllowCredentials)).To(BeEmpty())
+			Expect(rec.Header().Get(htphdr.HeaderAccessControlAllowMethods)).To(Equal("GET, POST"))
+			Expect(rec.Header().Get(htphdr.HeaderAccessControlAllowHeaders)).To(Equal("Authorization"))
+			Expect(rec.Header().Get(htphdr.HeaderAccessControlMaxAge)).To(BeEmpty())
+		})
+	})
+
+	Context("with the security headers", func() {
+		var security = []string{
+			htphdr.HeaderStrictTransportSecurity,
+			htphdr.HeaderFrameOptions,
+			htphdr.HeaderContentSecurityPolicy,
+			htphdr.HeaderReferrerPolicy,
+			htphdr.HeaderContentTypeOptions,
+			htphdr.HeaderAccessControlAllowOrigin,
+			htphdr.HeaderVary,
+		}
+
+		It("must not add any header by default", func() {
+			var req = newRequest(http.MethodGet, "https://app.example.com")
+			req.TLS = &tls.ConnectionState{}
+
+			rec, nxt := serve(newHeaders(htphdr.Config{}), req)
+
+			Expect(nxt).To(BeTrue())
+
+			for _, k := range security {
+				Expect(rec.Header().Values(k)).To(BeEmpty(), "header %s", k)
+			}
+		})
+
+		It("must add the configured headers, and the hsts header only on tls connections", func() {
+			var h = newHeaders(htphdr.Config{
+				Security: htphdr.SecurityConfig{
+					HSTSMaxAge:            libdur.Days(365),
+					HSTSIncludeSubdomains: true,
+					HSTSPreload:           true,
+					FrameOptions:          "sameorigin",
+					ContentSecurityPolicy: "default-src 'self'",
+					ReferrerPolicy:        "no-referrer",
+					ContentTypeNoSniff:    true,
+				},
+			})
+
+			rec, _ := serve(h, newRequest(http.MethodGet, ""))
+
+			Expect(rec.Header().Get(htphdr.HeaderStrictTransportSecurity)).To(BeEmpty())
+			Expect(rec.Header().Get(htphdr.HeaderFrameOptions)).To(Equal("SAMEORIGIN"))
+			Expect(rec.Header().Get(htphdr.HeaderContentSecurityPolicy)).To(Equal("default-src 'self'"))
+			Expect(rec.Header().Get(htphdr.HeaderReferrerPolicy)).To(Equal("no-referrer"))
+			Expect(rec.Header().Get(htphdr.HeaderContentTypeOptions)).To(Equal("nosniff"))
+
+			var req = newRequest(http.MethodGet, "")
+			req.TLS = &tls.ConnectionState{}
+
+			rec, _ = serve(h, req)
+			Expect(rec.Header().Get(htphdr.HeaderStrictTransportSecurity)).To(Equal("max-age=31536000; includeSubDomains; preload"))
+		})
+
+		It("must add the security headers to the preflight responses", func() {
+			var h = newHeaders(htphdr.Config{
+				CORS:     htphdr.CORSConfig{AllowOrigins: []string{"https://app.example.com"}},
+				Security: htphdr.SecurityConfig{FrameOptions: "DENY", ContentTypeNoSniff: true},
+			})
+
+			rec, nxt := serve(h, newPreflight("https://app.example.com", http.MethodGet, ""))
+
+			Expect(nxt).To(BeFalse())
+			Expect(rec.Code).To(Equal(http.StatusNoContent))
+			Expect(rec.Header().Get(htphdr.HeaderFrameOptions)).To(Equal("DENY"))
+			Expect(rec.Header().Get(htphdr.HeaderContentTypeOptions)).To(Equal("nosniff"))
+		})
+	})
+})
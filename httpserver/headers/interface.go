@@ -0,0 +1,109 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package headers
+
+import (
+	"net/http"
+	"strings"
+)
+
+const (
+	HeaderOrigin                        = "Origin"
+	HeaderVary                          = "Vary"
+	HeaderAccessControlRequestMethod    = "Access-Control-Request-Method"
+	HeaderAccessControlRequestHeaders   = "Access-Control-Request-Headers"
+	HeaderAccessControlAllowOrigin      = "Access-Control-Allow-Origin"
+	HeaderAccessControlAllowMethods     = "Access-Control-Allow-Methods"
+	HeaderAccessControlAllowHeaders     = "Access-Control-Allow-Headers"
+	HeaderAccessControlAllowCredentials = "Access-Control-Allow-Credentials"
+	HeaderAccessControlExposeHeaders    = "Access-Control-Expose-Headers"
+	HeaderAccessControlMaxAge           = "Access-Control-Max-Age"
+	HeaderStrictTransportSecurity       = "Strict-Transport-Security"
+	HeaderFrameOptions                  = "X-Frame-Options"
+	HeaderContentSecurityPolicy         = "Content-Security-Policy"
+	HeaderReferrerPolicy                = "Referrer-Policy"
+	HeaderContentTypeOptions            = "X-Content-Type-Options"
+)
+
+// DefaultAllowMethods is the list of methods allowed for cross origin requests if not configured.
+var DefaultAllowMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+}
+
+type Headers interface {
+	// Handler return the given handler adding the security headers to each response and applying
+	// the CORS policy. Preflight requests from allowed origins are answered with a 204 No Content response.
+	Handler(next http.Handler) http.Handler
+
+	// IsOriginAllowed return true if the given origin is allowed by the CORS policy.
+	IsOriginAllowed(origin string) bool
+}
+
+func New(cfg Config) (Headers, error) {
+	if e := cfg.Validate(); e != nil {
+		return nil, e
+	}
+
+	var res = &hdr{
+		o: make([]string, 0, len(cfg.CORS.AllowOrigins)),
+		w: make([]string, 0),
+		c: cfg.CORS.AllowCredentials,
+		h: strings.Join(cfg.CORS.AllowHeaders, ", "),
+		e: strings.Join(cfg.CORS.ExposeHeaders, ", "),
+		s: securityHeaders(cfg.Security),
+		t: hstsHeader(cfg.Security),
+	}
+
+	if len(cfg.CORS.AllowMethods) > 0 {
+		res.m = strings.ToUpper(strings.Join(cfg.CORS.AllowMethods, ", "))
+	} else {
+		res.m = strings.Join(DefaultAllowMethods, ", ")
+	}
+
+	if cfg.CORS.MaxAge > 0 {
+		res.a = formatSeconds(cfg.CORS.MaxAge.Time())
+	}
+
+	for _, o := range cfg.CORS.AllowOrigins {
+		o = strings.ToLower(strings.TrimSuffix(o, "/"))
+
+		if o == "*" {
+			res.y = true
+		} else if i := strings.Index(o, "://*."); i > 0 {
+			// keep "scheme://" and ".domain" to match any subdomain
+			res.w = append(res.w, o[:i+3]+"\x00"+o[i+4:])
+		} else {
+			res.o = append(res.o, o)
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,198 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package headers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type hdr struct {
+	y bool              // any origin allowed
+	o []string          // origins allowed
+	w []string          // wildcard origins allowed as "scheme://\x00.domain"
+	c bool              // allow credentials
+	m string            // allowed methods
+	h string            // allowed headers
+	e string            // exposed headers
+	a string            // max age
+	s map[string]string // security headers
+	t string            // strict transport security
+}
+
+func (o *hdr) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var h = w.Header()
+
+		for k, v := range o.s {
+			h.Set(k, v)
+		}
+
+		if len(o.t) > 0 && r.TLS != nil {
+			h.Set(HeaderStrictTransportSecurity, o.t)
+		}
+
+		if o.cors(w, r) {
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// cors apply the CORS policy and return true if the request is a preflight request already answered.
+func (o *hdr) cors(w http.ResponseWriter, r *http.Request) bool {
+	if !o.y && len(o.o) < 1 && len(o.w) < 1 {
+		return false
+	}
+
+	var (
+		h   = w.Header()
+		org = r.Header.Get(HeaderOrigin)
+		pre = r.Method == http.MethodOptions && len(r.Header.Get(HeaderAccessControlRequestMethod)) > 0
+	)
+
+	h.Add(HeaderVary, HeaderOrigin)
+
+	if pre {
+		h.Add(HeaderVary, HeaderAccessControlRequestMethod)
+		h.Add(HeaderVary, HeaderAccessControlRequestHeaders)
+	}
+
+	if len(org) < 1 || !o.IsOriginAllowed(org) {
+		return false
+	}
+
+	if o.y && !o.c {
+		h.Set(HeaderAccessControlAllowOrigin, "*")
+	} else {
+		h.Set(HeaderAccessControlAllowOrigin, org)
+	}
+
+	if o.c {
+		h.Set(HeaderAccessControlAllowCredentials, "true")
+	}
+
+	if !pre {
+		if len(o.e) > 0 {
+			h.Set(HeaderAccessControlExposeHeaders, o.e)
+		}
+
+		return false
+	}
+
+	h.Set(HeaderAccessControlAllowMethods, o.m)
+
+	if len(o.h) > 0 {
+		h.Set(HeaderAccessControlAllowHeaders, o.h)
+	} else if q := r.Header.Get(HeaderAccessControlRequestHeaders); len(q) > 0 {
+		h.Set(HeaderAccessControlAllowHeaders, q)
+	}
+
+	if len(o.a) > 0 {
+		h.Set(HeaderAccessControlMaxAge, o.a)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return true
+}
+
+func (o *hdr) IsOriginAllowed(origin string) bool {
+	if o.y {
+		return true
+	}
+
+	origin = strings.ToLower(strings.TrimSuffix(origin, "/"))
+
+	for _, a := range o.o {
+		if a == origin {
+			return true
+		}
+	}
+
+	for _, a := range o.w {
+		var (
+			i = strings.IndexByte(a, 0)
+			p = a[:i]
+			s = a[i+1:]
+		)
+
+		if len(origin) > len(p)+len(s) && strings.HasPrefix(origin, p) && strings.HasSuffix(origin, s) {
+			// the subdomain part must not contain another scheme or a path
+			if sub := origin[len(p) : len(origin)-len(s)]; !strings.ContainsAny(sub, "/:@") {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func securityHeaders(c SecurityConfig) map[string]string {
+	var res = make(map[string]string)
+
+	if len(c.FrameOptions) > 0 {
+		res[HeaderFrameOptions] = strings.ToUpper(c.FrameOptions)
+	}
+
+	if len(c.ContentSecurityPolicy) > 0 {
+		res[HeaderContentSecurityPolicy] = c.ContentSecurityPolicy
+	}
+
+	if len(c.ReferrerPolicy) > 0 {
+		res[HeaderReferrerPolicy] = c.ReferrerPolicy
+	}
+
+	if c.ContentTypeNoSniff {
+		res[HeaderContentTypeOptions] = "nosniff"
+	}
+
+	return res
+}
+
+func hstsHeader(c SecurityConfig) string {
+	if c.HSTSMaxAge <= 0 {
+		return ""
+	}
+
+	var s = "max-age=" + formatSeconds(c.HSTSMaxAge.Time())
+
+	if c.HSTSIncludeSubdomains {
+		s += "; includeSubDomains"
+	}
+
+	if c.HSTSPreload {
+		s += "; preload"
+	}
+
+	return s
+}
+
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatInt(int64(d/time.Second), 10)
+}
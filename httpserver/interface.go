@@ -27,9 +27,12 @@
 package httpserver
 
 import (
+	"context"
 	"sync"
 
 	libctx "github.com/nabbar/golib/context"
+	libevt "github.com/nabbar/golib/events"
+	srvgrd "github.com/nabbar/golib/httpserver/guard"
 	srvtps "github.com/nabbar/golib/httpserver/types"
 	liblog "github.com/nabbar/golib/logger"
 	montps "github.com/nabbar/golib/monitor/types"
@@ -59,6 +62,39 @@ type Server interface {
 
 	Monitor(vrs libver.Version) (montps.Monitor, error)
 	MonitorName() string
+
+	// State return the current lifecycle state of the server.
+	State() State
+
+	// Subscribe return a channel receiving each state transition of the server until the context is done.
+	// The channel is closed with the context. The current state is not sent: subscribe first, then check
+	// State to await a given state without race. A slow subscriber lose the oldest transitions.
+	// The context must be cancellable: a nil context or a context never done (like context.Background)
+	// return a closed channel, as the subscription could never be released.
+	Subscribe(ctx context.Context) <-chan StateTransition
+
+	// Draining return a channel closed as soon as the server start draining on stop, and renewed on
+	// each start. Into the handlers, use DrainFromContext with the request context to get it.
+	Draining() <-chan struct{}
+
+	// RegisterEventBus define an event bus used to publish EventState on each state change of the server.
+	// A nil bus disable the publishing.
+	RegisterEventBus(bus libevt.Bus)
+
+	// TLSMigrationStats return the protocol metrics collected since the last start of the server
+	// when a TLS migration mode is enabled.
+	TLSMigrationStats() TLSMigrationStats
+
+	// GuardStats return the counters of the ip filter and rate limiter since the last start of the server.
+	GuardStats() srvgrd.Stats
+
+	// HandlerMetrics return the request metrics (count, errors, latency) by handler key
+	// since the last start of the server.
+	HandlerMetrics() map[string]HandlerStats
+
+	// PreflightWarnings return the process and host limits found lower than the expected capacity
+	// of the server at its last start. It is empty if the preflight checks are disabled.
+	PreflightWarnings() []PreflightWarning
 }
 
 func New(cfg Config, defLog liblog.FuncLog) (Server, error) {
@@ -66,6 +102,9 @@ func New(cfg Config, defLog liblog.FuncLog) (Server, error) {
 		m: sync.RWMutex{},
 		r: nil,
 		c: libctx.NewConfig[string](cfg.getParentContext),
+		t: newTLSMigration(),
+		y: newLifecycle(),
+		k: newHandlerMetrics(),
 	}
 
 	s.Handler(cfg.getHandlerFunc)
@@ -0,0 +1,314 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package httpserver
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	libdur "github.com/nabbar/golib/duration"
+)
+
+// handlerLatencyBounds are the upper bounds of the latency buckets of HandlerStats.
+// The last bucket, named HandlerLatencyOver, count the requests slower than the last bound.
+var handlerLatencyBounds = []time.Duration{
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// HandlerLatencyOver is the name of the latency bucket of the requests slower than the last bound.
+const HandlerLatencyOver = "+Inf"
+
+// HandlerStats is the snapshot of the request metrics of one handler key.
+type HandlerStats struct {
+	// Requests is the number of requests served.
+	Requests uint64 `json:"requests"`
+	// ClientErrors is the number of requests answered with a 4xx status code.
+	ClientErrors uint64 `json:"client_errors"`
+	// Errors is the number of requests answered with a 5xx status code.
+	Errors uint64 `json:"errors"`
+	// LatencyTotal is the cumulated latency of all the requests.
+	LatencyTotal libdur.Duration `json:"latency_total"`
+	// LatencyAvg is the mean latency of the requests.
+	LatencyAvg libdur.Duration `json:"latency_avg"`
+	// LatencyMin is the fastest request latency.
+	LatencyMin libdur.Duration `json:"latency_min"`
+	// LatencyMax is the slowest request latency.
+	LatencyMax libdur.Duration `json:"latency_max"`
+	// Latency is the number of requests by latency bucket, named by its upper bound (like "250ms").
+	// Each request is only counted into its own bucket (not cumulative).
+	Latency map[string]uint64 `json:"latency"`
+	// Since is the time of the last reset of the metrics (at each start of the server).
+	Since time.Time `json:"since"`
+}
+
+// Merge return the sum of the current and given stats, used to aggregate the same handler key
+// served by several servers. The oldest Since is kept.
+func (s HandlerStats) Merge(o HandlerStats) HandlerStats {
+	var res = HandlerStats{
+		Requests:     s.Requests + o.Requests,
+		ClientErrors: s.ClientErrors + o.ClientErrors,
+		Errors:       s.Errors + o.Errors,
+		LatencyTotal: s.LatencyTotal + o.LatencyTotal,
+		LatencyMin:   s.LatencyMin,
+		LatencyMax:   s.LatencyMax,
+		Latency:      make(map[string]uint64, len(s.Latency)),
+		Since:        s.Since,
+	}
+
+	if s.Requests < 1 || (o.Requests > 0 && o.LatencyMin < res.LatencyMin) {
+		res.LatencyMin = o.LatencyMin
+	}
+
+	if o.LatencyMax > res.LatencyMax {
+		res.LatencyMax = o.LatencyMax
+	}
+
+	if res.Requests > 0 {
+		res.LatencyAvg = res.LatencyTotal / libdur.Duration(res.Requests)
+	}
+
+	if res.Since.IsZero() || (!o.Since.IsZero() && o.Since.Before(res.Since)) {
+		res.Since = o.Since
+	}
+
+	for k, v := range s.Latency {
+		res.Latency[k] += v
+	}
+
+	for k, v := range o.Latency {
+		res.Latency[k] += v
+	}
+
+	return res
+}
+
+type hdlStat struct {
+	n uint64        // requests
+	c uint64        // 4xx
+	e uint64        // 5xx
+	s time.Duration // latency total
+	i time.Duration // latency min
+	x time.Duration // latency max
+	b []uint64      // latency buckets
+}
+
+type hdlMetrics struct {
+	m sync.Mutex
+	k map[string]*hdlStat // stats by handler key
+	t time.Time           // since
+}
+
+func newHandlerMetrics() *hdlMetrics {
+	return &hdlMetrics{
+		m: sync.Mutex{},
+		k: make(map[string]*hdlStat),
+		t: time.Now(),
+	}
+}
+
+func (h *hdlMetrics) reset() {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	h.k = make(map[string]*hdlStat)
+	h.t = time.Now()
+}
+
+func (h *hdlMetrics) add(key string, code int, dur time.Duration) {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	s, ok := h.k[key]
+
+	if !ok {
+		s = &hdlStat{
+			b: make([]uint64, len(handlerLatencyBounds)+1),
+		}
+		h.k[key] = s
+	}
+
+	s.n++
+	s.s += dur
+
+	if s.n == 1 || dur < s.i {
+		s.i = dur
+	}
+
+	if dur > s.x {
+		s.x = dur
+	}
+
+	if code >= http.StatusInternalServerError {
+		s.e++
+	} else if code >= http.StatusBadRequest {
+		s.c++
+	}
+
+	var i = 0
+
+	for i < len(handlerLatencyBounds) && dur > handlerLatencyBounds[i] {
+		i++
+	}
+
+	s.b[i]++
+}
+
+func (h *hdlMetrics) stats() map[string]HandlerStats {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	var res = make(map[string]HandlerStats, len(h.k))
+
+	for k, s := range h.k {
+		var r = HandlerStats{
+			Requests:     s.n,
+			ClientErrors: s.c,
+			Errors:       s.e,
+			LatencyTotal: libdur.ParseDuration(s.s),
+			LatencyMin:   libdur.ParseDuration(s.i),
+			LatencyMax:   libdur.ParseDuration(s.x),
+			Latency:      make(map[string]uint64, len(s.b)),
+			Since:        h.t,
+		}
+
+		if s.n > 0 {
+			r.LatencyAvg = libdur.ParseDuration(s.s / time.Duration(s.n))
+		}
+
+		for i, n := range s.b {
+			if i < len(handlerLatencyBounds) {
+				r.Latency[handlerLatencyBounds[i].String()] = n
+			} else {
+				r.Latency[HandlerLatencyOver] = n
+			}
+		}
+
+		res[k] = r
+	}
+
+	return res
+}
+
+func (o *srv) HandlerMetrics() map[string]HandlerStats {
+	if o == nil || o.k == nil {
+		return make(map[string]HandlerStats)
+	}
+
+	return o.k.stats()
+}
+
+// metricsHandler return the given handler recording the count, status and latency of each request
+// under the handler key currently served. Metrics are reset at each call (each start of the server).
+func (o *srv) metricsHandler(h http.Handler) http.Handler {
+	o.k.reset()
+
+	var key = o.HandlerGetValidKey()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var (
+			t = time.Now()
+			m = &metricsWriter{w: w}
+		)
+
+		defer func() {
+			o.k.add(key, m.status(), time.Since(t))
+		}()
+
+		h.ServeHTTP(m, r)
+	})
+}
+
+// metricsWriter capture the status code of the response. It keeps the flush and hijack
+// capabilities of the original writer, needed by the streams and websockets.
+type metricsWriter struct {
+	w http.ResponseWriter
+	c int
+}
+
+func (m *metricsWriter) status() int {
+	if m.c == 0 {
+		return http.StatusOK
+	}
+
+	return m.c
+}
+
+func (m *metricsWriter) Header() http.Header {
+	return m.w.Header()
+}
+
+func (m *metricsWriter) Write(p []byte) (int, error) {
+	if m.c == 0 {
+		m.c = http.StatusOK
+	}
+
+	return m.w.Write(p)
+}
+
+func (m *metricsWriter) WriteHeader(code int) {
+	if m.c == 0 && code >= http.StatusOK {
+		m.c = code
+	}
+
+	m.w.WriteHeader(code)
+}
+
+func (m *metricsWriter) Flush() {
+	if f, k := m.w.(http.Flusher); k {
+		f.Flush()
+	}
+}
+
+func (m *metricsWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, k := m.w.(http.Hijacker); k {
+		if m.c == 0 {
+			m.c = http.StatusSwitchingProtocols
+		}
+
+		return h.Hijack()
+	}
+
+	return nil, nil, errors.New("response writer does not support hijacking")
+}
+
+// Unwrap return the original writer, used by http.ResponseController.
+func (m *metricsWriter) Unwrap() http.ResponseWriter {
+	return m.w
+}
@@ -31,6 +31,8 @@ import (
 	"sync"
 
 	libctx "github.com/nabbar/golib/context"
+	libevt "github.com/nabbar/golib/events"
+	srvgrd "github.com/nabbar/golib/httpserver/guard"
 	srvtps "github.com/nabbar/golib/httpserver/types"
 	liblog "github.com/nabbar/golib/logger"
 	librun "github.com/nabbar/golib/server/runner/startStop"
@@ -43,6 +45,12 @@ type srv struct {
 	c libctx.Config[string]
 	r librun.StartStop
 	s *http.Server
+	d *http.Server // http redirect server
+	e libevt.Bus
+	t *tlsMig
+	g srvgrd.Guard
+	y *lifecycle
+	k *hdlMetrics
 }
 
 func (o *srv) Merge(s Server, def liblog.FuncLog) error {
@@ -31,7 +31,6 @@ import (
 	"errors"
 	"fmt"
 	"net"
-	"runtime"
 
 	logent "github.com/nabbar/golib/logger/entry"
 	loglvl "github.com/nabbar/golib/logger/level"
@@ -87,7 +86,7 @@ func (o *srv) runAndHealthy(ctx context.Context) error {
 	o.m.RLock()
 	defer o.m.RUnlock()
 
-	if !o.r.IsRunning() {
+	if o.State() != StateRunning {
 		return errNotRunning
 	} else if e := o.PortNotUse(ctx, o.GetBindable()); e != nil {
 		return e
@@ -120,10 +119,9 @@ func (o *srv) Monitor(vrs libver.Version) (montps.Monitor, error) {
 		return nil, fmt.Errorf("cannot load config")
 	}
 
-	res["runtime"] = runtime.Version()[2:]
-	res["release"] = vrs.GetRelease()
-	res["build"] = vrs.GetBuild()
-	res["date"] = vrs.GetDate()
+	for k, v := range vrs.GetInfoMap() {
+		res[k] = v
+	}
 	res["handler"] = o.HandlerGetValidKey()
 
 	if inf, e = moninf.New(DefaultNameMonitor); e != nil {
@@ -133,7 +131,21 @@ func (o *srv) Monitor(vrs libver.Version) (montps.Monitor, error) {
 			return o.MonitorName(), nil
 		})
 		inf.RegisterInfo(func() (map[string]interface{}, error) {
-			return res, nil
+			var r = make(map[string]interface{}, len(res)+1)
+
+			for k, v := range res {
+				r[k] = v
+			}
+
+			if m := o.HandlerMetrics(); len(m) > 0 {
+				r["handlers"] = m
+			}
+
+			if w := o.PreflightWarnings(); len(w) > 0 {
+				r["preflight_warnings"] = w
+			}
+
+			return r, nil
 		})
 	}
 
@@ -40,6 +40,7 @@ const (
 	ErrorPoolStop
 	ErrorPoolRestart
 	ErrorPoolMonitor
+	ErrorPoolDependency
 )
 
 func init() {
@@ -65,6 +66,8 @@ func getMessage(code liberr.CodeError) (message string) {
 		return "at least one server has restart error"
 	case ErrorPoolMonitor:
 		return "at least one server has monitor error"
+	case ErrorPoolDependency:
+		return "invalid dependency between servers of pool"
 	}
 
 	return liberr.NullMessage
@@ -29,6 +29,7 @@ package pool
 import (
 	"context"
 	"sync"
+	"time"
 
 	liblog "github.com/nabbar/golib/logger"
 
@@ -65,16 +66,37 @@ type Filter interface {
 	Filter(field srvtps.FieldType, pattern, regex string) Pool
 }
 
+type Order interface {
+	// DependsOn declare the servers (by bind address) needed by the given server.
+	// The dependencies are started before and stopped after the given server.
+	// An error is returned if a server is not found or if a dependency cycle is detected.
+	DependsOn(bindAddress string, deps ...string) error
+
+	// Stages return the bind address of the servers grouped by start stage: each stage only depends
+	// on the previous stages. Into a stage, the servers are sorted by their last start order.
+	// Stop and Restart process the stages and the servers of each stage in reverse order.
+	Stages() [][]string
+
+	// SetStageTimeout define the max duration to stop all the servers of one stage. Zero means no limit
+	// except the deadline of the context given to Stop.
+	SetStageTimeout(dur time.Duration)
+}
+
 type Pool interface {
 	libsrv.Server
 
 	Manage
 	Filter
+	Order
 
 	Clone(ctx context.Context) Pool
 	Merge(p Pool, def liblog.FuncLog) error
 	Handler(fct srvtps.FuncHandler)
 	Monitor(vrs libver.Version) ([]montps.Monitor, liberr.Error)
+
+	// HandlerMetrics return the request metrics by handler key of all the servers,
+	// the same handler key served by several servers being merged.
+	HandlerMetrics() map[string]libhtp.HandlerStats
 }
 
 func New(ctx libctx.FuncContext, hdl srvtps.FuncHandler, srv ...libhtp.Server) Pool {
@@ -82,6 +104,8 @@ func New(ctx libctx.FuncContext, hdl srvtps.FuncHandler, srv ...libhtp.Server) P
 		m: sync.RWMutex{},
 		p: libctx.NewConfig[string](ctx),
 		h: hdl,
+		d: make(map[string][]string),
+		s: make([]string, 0),
 	}
 
 	for _, s := range srv {
@@ -38,6 +38,12 @@ import (
 
 func (o *pool) Clean() {
 	o.p.Clean()
+
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.d = make(map[string][]string)
+	o.s = make([]string, 0)
 }
 
 func (o *pool) Walk(fct FuncWalk) bool {
@@ -83,12 +89,19 @@ func (o *pool) StoreNew(cfg libhtp.Config, defLog liblog.FuncLog) error {
 
 func (o *pool) Delete(bindAddress string) {
 	o.p.Delete(bindAddress)
+	o.delOrder(bindAddress)
 }
 
 func (o *pool) LoadAndDelete(bindAddress string) (val libhtp.Server, loaded bool) {
-	if i, l := o.p.LoadAndDelete(bindAddress); !l {
+	i, l := o.p.LoadAndDelete(bindAddress)
+
+	if !l {
 		return nil, false
-	} else if v, k := i.(libhtp.Server); !k {
+	}
+
+	o.delOrder(bindAddress)
+
+	if v, k := i.(libhtp.Server); !k {
 		return nil, false
 	} else {
 		return v, true
@@ -30,6 +30,7 @@ import (
 	"context"
 	"net/http"
 	"sync"
+	"time"
 
 	liblog "github.com/nabbar/golib/logger"
 
@@ -45,13 +46,28 @@ type pool struct {
 	m sync.RWMutex
 	p libctx.Config[string]
 	h srvtps.FuncHandler
+	d map[string][]string // dependencies by bind address
+	s []string            // bind address in start order
+	t time.Duration       // stop timeout of each stage
 }
 
 func (o *pool) Clone(ctx context.Context) Pool {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	var d = make(map[string][]string, len(o.d))
+
+	for k, v := range o.d {
+		d[k] = append(make([]string, 0, len(v)), v...)
+	}
+
 	return &pool{
 		m: sync.RWMutex{},
 		p: o.p.Clone(ctx),
 		h: o.h,
+		d: d,
+		s: append(make([]string, 0, len(o.s)), o.s...),
+		t: o.t,
 	}
 }
 
@@ -97,7 +113,9 @@ func (o *pool) handler(name string) http.Handler {
 		return srvtps.NewBadHandler()
 	} else if h := o.h(); h == nil {
 		return srvtps.NewBadHandler()
-	} else if f, k := h[name]; !k {
+	} else if r, k := srvtps.HandlerResolveKey(h, name); !k {
+		return srvtps.NewBadHandler()
+	} else if f := h[r]; f == nil {
 		return srvtps.NewBadHandler()
 	} else {
 		return f
@@ -141,3 +159,17 @@ func (o *pool) Monitor(vrs libver.Version) ([]montps.Monitor, liberr.Error) {
 
 	return res, err
 }
+
+func (o *pool) HandlerMetrics() map[string]libhtp.HandlerStats {
+	var res = make(map[string]libhtp.HandlerStats)
+
+	o.Walk(func(bindAddress string, srv libhtp.Server) bool {
+		for k, v := range srv.HandlerMetrics() {
+			res[k] = res[k].Merge(v)
+		}
+
+		return true
+	})
+
+	return res
+}
@@ -0,0 +1,265 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package pool
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	libhtp "github.com/nabbar/golib/httpserver"
+)
+
+func (o *pool) DependsOn(bindAddress string, deps ...string) error {
+	if !o.Has(bindAddress) {
+		return ErrorPoolDependency.Error(fmt.Errorf("server '%s' not found", bindAddress))
+	}
+
+	for _, d := range deps {
+		if !o.Has(d) {
+			return ErrorPoolDependency.Error(fmt.Errorf("dependency '%s' not found", d))
+		} else if d == bindAddress {
+			return ErrorPoolDependency.Error(fmt.Errorf("server '%s' cannot depend on itself", d))
+		}
+	}
+
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	var old = o.d[bindAddress]
+
+	for _, d := range deps {
+		if !stringInSlice(o.d[bindAddress], d) {
+			o.d[bindAddress] = append(o.d[bindAddress], d)
+		}
+	}
+
+	if e := o.checkCycle(); e != nil {
+		if len(old) < 1 {
+			delete(o.d, bindAddress)
+		} else {
+			o.d[bindAddress] = old
+		}
+		return e
+	}
+
+	return nil
+}
+
+func (o *pool) SetStageTimeout(dur time.Duration) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if dur < 0 {
+		dur = 0
+	}
+
+	o.t = dur
+}
+
+func (o *pool) getStageTimeout() time.Duration {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	return o.t
+}
+
+func (o *pool) Stages() [][]string {
+	var (
+		key = make([]string, 0)
+		lvl = make(map[string]int)
+		rnk = make(map[string]int)
+		res = make([][]string, 0)
+	)
+
+	o.Walk(func(bindAddress string, _ libhtp.Server) bool {
+		key = append(key, bindAddress)
+		return true
+	})
+
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	for i, k := range o.s {
+		rnk[k] = i
+	}
+
+	var level func(k string, path map[string]bool) int
+
+	level = func(k string, path map[string]bool) int {
+		if l, ok := lvl[k]; ok {
+			return l
+		} else if path[k] {
+			return 0
+		}
+
+		path[k] = true
+		var l = 0
+
+		for _, d := range o.d[k] {
+			if !stringInSlice(key, d) {
+				continue
+			} else if n := level(d, path) + 1; n > l {
+				l = n
+			}
+		}
+
+		delete(path, k)
+		lvl[k] = l
+		return l
+	}
+
+	for _, k := range key {
+		var l = level(k, make(map[string]bool))
+
+		for len(res) <= l {
+			res = append(res, make([]string, 0))
+		}
+
+		res[l] = append(res[l], k)
+	}
+
+	for _, s := range res {
+		sort.Slice(s, func(i, j int) bool {
+			ri, ki := rnk[s[i]]
+			rj, kj := rnk[s[j]]
+
+			if ki && kj {
+				return ri < rj
+			} else if ki != kj {
+				// servers never started come last
+				return ki
+			}
+
+			return s[i] < s[j]
+		})
+	}
+
+	return res
+}
+
+// started register the given server as the last started.
+func (o *pool) started(bindAddress string) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.s = append(removeInSlice(o.s, bindAddress), bindAddress)
+}
+
+// stopped remove the given server from the start order.
+func (o *pool) stopped(bindAddress string) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.s = removeInSlice(o.s, bindAddress)
+}
+
+// delOrder remove the given server from the start order and from all dependencies.
+func (o *pool) delOrder(bindAddress string) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.s = removeInSlice(o.s, bindAddress)
+	delete(o.d, bindAddress)
+
+	for k, v := range o.d {
+		o.d[k] = removeInSlice(v, bindAddress)
+	}
+}
+
+// checkCycle return an error if a dependency cycle exists. Must be called with the lock held.
+func (o *pool) checkCycle() error {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	var (
+		state = make(map[string]int, len(o.d))
+		visit func(name string, path []string) error
+	)
+
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case visiting:
+			return ErrorPoolDependency.Error(fmt.Errorf("dependency cycle %v -> %s", path, name))
+		case visited:
+			return nil
+		}
+
+		state[name] = visiting
+
+		for _, d := range o.d[name] {
+			if e := visit(d, append(path, name)); e != nil {
+				return e
+			}
+		}
+
+		state[name] = visited
+		return nil
+	}
+
+	var key = make([]string, 0, len(o.d))
+
+	for k := range o.d {
+		key = append(key, k)
+	}
+
+	sort.Strings(key)
+
+	for _, k := range key {
+		if state[k] == unvisited {
+			if e := visit(k, make([]string, 0)); e != nil {
+				return e
+			}
+		}
+	}
+
+	return nil
+}
+
+func stringInSlice(list []string, s string) bool {
+	for _, i := range list {
+		if i == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+func removeInSlice(list []string, s string) []string {
+	var res = make([]string, 0, len(list))
+
+	for _, i := range list {
+		if i != s {
+			res = append(res, i)
+		}
+	}
+
+	return res
+}
@@ -28,23 +28,19 @@ package pool
 
 import (
 	"context"
+	"fmt"
 	"time"
 
+	liberr "github.com/nabbar/golib/errors"
 	libhtp "github.com/nabbar/golib/httpserver"
 )
 
+// Start start the servers stage by stage, each server after its dependencies.
+// A server with a dependency not started is not started.
 func (o *pool) Start(ctx context.Context) error {
 	var err = ErrorPoolStart.Error(nil)
 
-	o.Walk(func(bindAddress string, srv libhtp.Server) bool {
-		if e := srv.Start(ctx); e != nil {
-			err.Add(e)
-		} else {
-			o.Store(srv)
-		}
-
-		return true
-	})
+	o.start(ctx, err)
 
 	if !err.HasParent() {
 		err = nil
@@ -53,18 +49,12 @@ func (o *pool) Start(ctx context.Context) error {
 	return err
 }
 
+// Stop stop the servers in the reverse order of Start: the last stage first and,
+// into a stage, the last started server first. Each stage is limited by the stage timeout.
 func (o *pool) Stop(ctx context.Context) error {
 	var err = ErrorPoolStop.Error(nil)
 
-	o.Walk(func(bindAddress string, srv libhtp.Server) bool {
-		if e := srv.Stop(ctx); e != nil {
-			err.Add(e)
-		} else {
-			o.Store(srv)
-		}
-
-		return true
-	})
+	o.stop(ctx, err)
 
 	if !err.HasParent() {
 		err = nil
@@ -73,24 +63,87 @@ func (o *pool) Stop(ctx context.Context) error {
 	return err
 }
 
+// Restart stop all the servers in reverse order and start them again in order.
 func (o *pool) Restart(ctx context.Context) error {
 	var err = ErrorPoolRestart.Error(nil)
 
-	o.Walk(func(bindAddress string, srv libhtp.Server) bool {
-		if e := srv.Restart(ctx); e != nil {
+	o.stop(ctx, err)
+	o.start(ctx, err)
+
+	if !err.HasParent() {
+		err = nil
+	}
+
+	return err
+}
+
+func (o *pool) start(ctx context.Context, err liberr.Error) {
+	var bad = make(map[string]bool)
+
+	for _, stg := range o.Stages() {
+		for _, k := range stg {
+			var srv = o.Load(k)
+
+			if srv == nil {
+				continue
+			} else if d := o.failedDependency(k, bad); len(d) > 0 {
+				bad[k] = true
+				err.Add(fmt.Errorf("server '%s' not started: dependency '%s' not started", k, d))
+			} else if e := srv.Start(ctx); e != nil {
+				bad[k] = true
+				err.Add(e)
+			} else {
+				o.Store(srv)
+				o.started(k)
+			}
+		}
+	}
+}
+
+func (o *pool) stop(ctx context.Context, err liberr.Error) {
+	var (
+		stg = o.Stages()
+		tmo = o.getStageTimeout()
+	)
+
+	for i := len(stg) - 1; i >= 0; i-- {
+		o.stopStage(ctx, stg[i], tmo, err)
+	}
+}
+
+func (o *pool) stopStage(ctx context.Context, stg []string, tmo time.Duration, err liberr.Error) {
+	if tmo > 0 {
+		var cnl context.CancelFunc
+		ctx, cnl = context.WithTimeout(ctx, tmo)
+		defer cnl()
+	}
+
+	for i := len(stg) - 1; i >= 0; i-- {
+		var srv = o.Load(stg[i])
+
+		if srv == nil {
+			continue
+		} else if e := srv.Stop(ctx); e != nil {
 			err.Add(e)
 		} else {
 			o.Store(srv)
+			o.stopped(stg[i])
 		}
+	}
+}
 
-		return true
-	})
+// failedDependency return the first dependency of the given server found into the given failed list.
+func (o *pool) failedDependency(bindAddress string, bad map[string]bool) string {
+	o.m.RLock()
+	defer o.m.RUnlock()
 
-	if !err.HasParent() {
-		err = nil
+	for _, d := range o.d[bindAddress] {
+		if bad[d] {
+			return d
+		}
 	}
 
-	return err
+	return ""
 }
 
 func (o *pool) IsRunning() bool {
@@ -0,0 +1,164 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package httpserver
+
+import (
+	"fmt"
+	"runtime"
+
+	loglvl "github.com/nabbar/golib/logger/level"
+)
+
+const (
+	// PreflightOpenFiles is the check of the soft limit of open files of the process (ulimit -n).
+	PreflightOpenFiles = "open_files"
+	// PreflightSomaxconn is the check of the maximum listen backlog of the host (net.core.somaxconn).
+	PreflightSomaxconn = "somaxconn"
+	// PreflightEphemeralPorts is the check of the local port range used by the outgoing connections.
+	PreflightEphemeralPorts = "ephemeral_ports"
+	// PreflightGoMaxProcs is the check of the number of cpu usable by the go runtime.
+	PreflightGoMaxProcs = "gomaxprocs"
+
+	// preflightReservedFiles is the number of file descriptors kept for everything else than the connections.
+	preflightReservedFiles = 64
+)
+
+// Preflight define the expected capacity of the server, compared at each start with the limits of the
+// process and of the host. Each under provisioned limit is logged as a warning and reported by the monitor.
+type Preflight struct {
+	// Enabled allow to run the checks at each start of the server.
+	Enabled bool `mapstructure:"enabled" json:"enabled" yaml:"enabled" toml:"enabled"`
+
+	// Connections is the number of concurrent connections expected on the server.
+	// It is compared with the open files limit and with the number of ephemeral ports.
+	Connections int `mapstructure:"connections" json:"connections" yaml:"connections" toml:"connections" validate:"gte=0"`
+
+	// Backlog is the listen backlog expected on the server, compared with the somaxconn of the host.
+	// If zero, the backlog is not checked.
+	Backlog int `mapstructure:"backlog" json:"backlog" yaml:"backlog" toml:"backlog" validate:"gte=0"`
+}
+
+// PreflightWarning is a process or host limit lower than the expected capacity of the server.
+type PreflightWarning struct {
+	Check    string `json:"check"`
+	Current  int64  `json:"current"`
+	Expected int64  `json:"expected"`
+	Message  string `json:"message"`
+}
+
+func (w PreflightWarning) String() string {
+	return fmt.Sprintf("%s: %s (current: %d, expected: %d)", w.Check, w.Message, w.Current, w.Expected)
+}
+
+// preflightLimits are the limits of the process and of the host. A negative value means unknown.
+type preflightLimits struct {
+	openFiles int64
+	somaxconn int64
+	ephemeral int64
+}
+
+// check compare the given limits with the expected capacity and return the warnings.
+func (p Preflight) check(lim preflightLimits) []PreflightWarning {
+	var res = make([]PreflightWarning, 0)
+
+	if !p.Enabled {
+		return res
+	}
+
+	if p.Connections > 0 {
+		if exp := int64(p.Connections + preflightReservedFiles); lim.openFiles >= 0 && lim.openFiles < exp {
+			res = append(res, PreflightWarning{
+				Check:    PreflightOpenFiles,
+				Current:  lim.openFiles,
+				Expected: exp,
+				Message:  "open files limit is lower than the expected connections",
+			})
+		}
+
+		if exp := int64(p.Connections); lim.ephemeral >= 0 && lim.ephemeral < exp {
+			res = append(res, PreflightWarning{
+				Check:    PreflightEphemeralPorts,
+				Current:  lim.ephemeral,
+				Expected: exp,
+				Message:  "ephemeral ports range is smaller than the expected connections",
+			})
+		}
+	}
+
+	if exp := int64(p.Backlog); exp > 0 && lim.somaxconn >= 0 && lim.somaxconn < exp {
+		res = append(res, PreflightWarning{
+			Check:    PreflightSomaxconn,
+			Current:  lim.somaxconn,
+			Expected: exp,
+			Message:  "listen backlog is capped by the host",
+		})
+	}
+
+	if cur, cpu := runtime.GOMAXPROCS(0), runtime.NumCPU(); cur < cpu {
+		res = append(res, PreflightWarning{
+			Check:    PreflightGoMaxProcs,
+			Current:  int64(cur),
+			Expected: int64(cpu),
+			Message:  "go runtime use less cpu than available",
+		})
+	}
+
+	return res
+}
+
+// PreflightWarnings return the warnings of the checks run at the last start of the server.
+func (o *srv) PreflightWarnings() []PreflightWarning {
+	if i, l := o.c.Load(cfgPreflight); !l {
+		return make([]PreflightWarning, 0)
+	} else if v, k := i.([]PreflightWarning); !k {
+		return make([]PreflightWarning, 0)
+	} else {
+		var res = make([]PreflightWarning, len(v))
+		copy(res, v)
+		return res
+	}
+}
+
+func (o *srv) runPreflight() {
+	var cfg = o.GetConfig()
+
+	if cfg == nil || !cfg.Preflight.Enabled {
+		o.c.Delete(cfgPreflight)
+		return
+	}
+
+	var res = cfg.Preflight.check(getPreflightLimits())
+	o.c.Store(cfgPreflight, res)
+
+	for _, w := range res {
+		o.logger().Entry(loglvl.WarnLevel, "HTTP Server preflight: "+w.Message).
+			FieldAdd("check", w.Check).
+			FieldAdd("current", w.Current).
+			FieldAdd("expected", w.Expected).
+			Log()
+	}
+}
@@ -0,0 +1,86 @@
+//go:build linux
+// +build linux
+
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package httpserver
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	preflightProcSomaxconn = "/proc/sys/net/core/somaxconn"
+	preflightProcPortRange = "/proc/sys/net/ipv4/ip_local_port_range"
+)
+
+func getPreflightLimits() preflightLimits {
+	var (
+		lim = preflightLimits{
+			openFiles: -1,
+			somaxconn: -1,
+			ephemeral: -1,
+		}
+		rlm unix.Rlimit
+	)
+
+	if e := unix.Getrlimit(unix.RLIMIT_NOFILE, &rlm); e == nil && rlm.Cur != unix.RLIM_INFINITY {
+		lim.openFiles = int64(rlm.Cur)
+	}
+
+	if v := readProcInts(preflightProcSomaxconn); len(v) == 1 {
+		lim.somaxconn = v[0]
+	}
+
+	if v := readProcInts(preflightProcPortRange); len(v) == 2 && v[1] >= v[0] {
+		lim.ephemeral = v[1] - v[0] + 1
+	}
+
+	return lim
+}
+
+func readProcInts(path string) []int64 {
+	var res = make([]int64, 0)
+
+	if p, e := os.ReadFile(path); e != nil {
+		return res
+	} else {
+		for _, f := range strings.Fields(string(p)) {
+			if i, e := strconv.ParseInt(f, 10, 64); e != nil {
+				return make([]int64, 0)
+			} else {
+				res = append(res, i)
+			}
+		}
+	}
+
+	return res
+}
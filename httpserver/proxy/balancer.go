@@ -0,0 +1,154 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package proxy
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"net"
+	"net/http"
+)
+
+// candidates return the upstreams able to receive the request, excluding the given ones.
+// If all enabled upstreams are down, they are all returned: trying a down upstream is better than failing every request.
+func (o *prx) candidates(exclude map[*upstream]bool) []*upstream {
+	var (
+		all = make([]*upstream, 0, len(o.u))
+		liv = make([]*upstream, 0, len(o.u))
+	)
+
+	for _, u := range o.u {
+		if exclude[u] || u.isDisabled() {
+			continue
+		}
+
+		all = append(all, u)
+
+		if u.a.Load() {
+			liv = append(liv, u)
+		}
+	}
+
+	if len(liv) > 0 {
+		return liv
+	}
+
+	return all
+}
+
+// pick return the upstream to use for the request, or nil if none is available.
+func (o *prx) pick(r *http.Request, exclude map[*upstream]bool) *upstream {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	var lst = o.candidates(exclude)
+
+	if len(lst) < 1 {
+		return nil
+	} else if len(lst) == 1 {
+		return lst[0]
+	}
+
+	switch o.c.Strategy {
+	case StrategyRandom:
+		return pickRandom(lst)
+	case StrategyLeastConn:
+		return pickLeastConn(lst)
+	case StrategyIPHash:
+		return pickIPHash(r, lst)
+	default:
+		return pickRoundRobin(lst)
+	}
+}
+
+// pickRoundRobin is the smooth weighted round-robin: each upstream is chosen following its weight, without burst.
+func pickRoundRobin(lst []*upstream) *upstream {
+	var (
+		res *upstream
+		tot int
+	)
+
+	for _, u := range lst {
+		u.g += u.w
+		tot += u.w
+
+		if res == nil || u.g > res.g {
+			res = u
+		}
+	}
+
+	res.g -= tot
+	return res
+}
+
+func pickRandom(lst []*upstream) *upstream {
+	var tot int
+
+	for _, u := range lst {
+		tot += u.w
+	}
+
+	// #nosec
+	var n = rand.Intn(tot)
+
+	for _, u := range lst {
+		if n < u.w {
+			return u
+		}
+		n -= u.w
+	}
+
+	return lst[len(lst)-1]
+}
+
+func pickLeastConn(lst []*upstream) *upstream {
+	var res *upstream
+
+	for _, u := range lst {
+		// comparing active/weight without division
+		if res == nil || u.c.Load()*int64(res.w) < res.c.Load()*int64(u.w) {
+			res = u
+		}
+	}
+
+	return res
+}
+
+func pickIPHash(r *http.Request, lst []*upstream) *upstream {
+	var (
+		h = fnv.New32a()
+		a = r.RemoteAddr
+	)
+
+	if i, _, e := net.SplitHostPort(a); e == nil {
+		a = i
+	}
+
+	_, _ = h.Write([]byte(a))
+
+	return lst[int(h.Sum32()%uint32(len(lst)))]
+}
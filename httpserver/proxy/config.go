@@ -0,0 +1,250 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	libval "github.com/go-playground/validator/v10"
+	libtls "github.com/nabbar/golib/certificates"
+	libdur "github.com/nabbar/golib/duration"
+)
+
+const (
+	DefaultRetries        = 1
+	DefaultHealthInterval = 10 * time.Second
+	DefaultHealthTimeout  = 2 * time.Second
+	DefaultHealthFall     = 3
+	DefaultHealthRise     = 2
+)
+
+// DefaultRetryStatus is the list of upstream response status retried on another upstream if none is configured.
+var DefaultRetryStatus = []int{
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+type UpstreamConfig struct {
+	// Name is the unique name of the upstream into the proxy.
+	Name string `mapstructure:"name" json:"name" yaml:"name" toml:"name" validate:"required"`
+
+	// URL is the base url of the upstream. The path of the url is prefixed to the path of the requests.
+	URL string `mapstructure:"url" json:"url" yaml:"url" toml:"url" validate:"required,url"`
+
+	// Weight is the relative weight of the upstream for the load balancing. Zero is replaced by 1.
+	Weight int `mapstructure:"weight" json:"weight" yaml:"weight" toml:"weight" validate:"gte=0"`
+
+	// Disabled keep the upstream into the pool without sending any request to it.
+	Disabled bool `mapstructure:"disabled" json:"disabled" yaml:"disabled" toml:"disabled"`
+}
+
+type HealthConfig struct {
+	// Disabled disable the active health checks. Without health checks, all upstreams are always considered alive.
+	Disabled bool `mapstructure:"disabled" json:"disabled" yaml:"disabled" toml:"disabled"`
+
+	// Path is the path requested with a GET on each upstream. Default is the upstream base url.
+	Path string `mapstructure:"path" json:"path" yaml:"path" toml:"path"`
+
+	// Interval is the delay between two checks. Default is 10 seconds.
+	Interval libdur.Duration `mapstructure:"interval" json:"interval" yaml:"interval" toml:"interval"`
+
+	// Timeout is the max duration of one check. Default is 2 seconds.
+	Timeout libdur.Duration `mapstructure:"timeout" json:"timeout" yaml:"timeout" toml:"timeout"`
+
+	// ExpectStatus is the list of status codes of an healthy upstream. Default is any 2xx or 3xx status.
+	ExpectStatus []int `mapstructure:"expect_status" json:"expect_status" yaml:"expect_status" toml:"expect_status"`
+
+	// Fall is the number of consecutive failures (checks or proxied requests) to mark an upstream as down. Default is 3.
+	Fall uint8 `mapstructure:"fall" json:"fall" yaml:"fall" toml:"fall"`
+
+	// Rise is the number of consecutive successful checks to mark an upstream as up again. Default is 2.
+	Rise uint8 `mapstructure:"rise" json:"rise" yaml:"rise" toml:"rise"`
+}
+
+type HeaderConfig struct {
+	// Set define headers replacing any existing value.
+	Set map[string]string `mapstructure:"set" json:"set" yaml:"set" toml:"set"`
+
+	// Add define headers values added to the existing values.
+	Add map[string]string `mapstructure:"add" json:"add" yaml:"add" toml:"add"`
+
+	// Remove is the list of headers removed.
+	Remove []string `mapstructure:"remove" json:"remove" yaml:"remove" toml:"remove"`
+}
+
+type Config struct {
+	// Upstreams is the list of upstreams of the pool.
+	Upstreams []UpstreamConfig `mapstructure:"upstreams" json:"upstreams" yaml:"upstreams" toml:"upstreams" validate:"dive"`
+
+	// Strategy is the load balancing strategy: round-robin, random, least-conn or ip-hash. Default is round-robin.
+	Strategy Strategy `mapstructure:"strategy" json:"strategy" yaml:"strategy" toml:"strategy"`
+
+	// Health is the configuration of the active health checks.
+	Health HealthConfig `mapstructure:"health" json:"health" yaml:"health" toml:"health"`
+
+	// Retries is the number of other upstreams tried for an idempotent request without body,
+	// on a connection error or a retry status. Zero is replaced by 1, a negative value disables the retries.
+	Retries int `mapstructure:"retries" json:"retries" yaml:"retries" toml:"retries"`
+
+	// RetryStatus is the list of upstream response status retried. Default is 502, 503 and 504.
+	RetryStatus []int `mapstructure:"retry_status" json:"retry_status" yaml:"retry_status" toml:"retry_status"`
+
+	// PreserveHost send the Host header of the client request to the upstream instead of the host of the upstream.
+	PreserveHost bool `mapstructure:"preserve_host" json:"preserve_host" yaml:"preserve_host" toml:"preserve_host"`
+
+	// StripPrefix is the path prefix removed from the requests before sending them to the upstream.
+	StripPrefix string `mapstructure:"strip_prefix" json:"strip_prefix" yaml:"strip_prefix" toml:"strip_prefix"`
+
+	// RequestHeader define the rewriting of the headers sent to the upstream.
+	RequestHeader HeaderConfig `mapstructure:"request_header" json:"request_header" yaml:"request_header" toml:"request_header"`
+
+	// ResponseHeader define the rewriting of the headers sent back to the client.
+	ResponseHeader HeaderConfig `mapstructure:"response_header" json:"response_header" yaml:"response_header" toml:"response_header"`
+
+	// FlushInterval is the interval of the flush of the response to the client while copying the body.
+	// A negative value flush after each write. Streaming responses (like server-sent events) are always flushed immediately.
+	FlushInterval libdur.Duration `mapstructure:"flush_interval" json:"flush_interval" yaml:"flush_interval" toml:"flush_interval"`
+
+	// ResponseTimeout is the max duration waiting for the response headers of an upstream. Zero means no timeout.
+	ResponseTimeout libdur.Duration `mapstructure:"response_timeout" json:"response_timeout" yaml:"response_timeout" toml:"response_timeout"`
+
+	// TLS is the tls configuration used to connect the https upstreams.
+	TLS libtls.Config `mapstructure:"tls" json:"tls" yaml:"tls" toml:"tls"`
+}
+
+func (c Config) Validate() error {
+	if er := libval.New().Struct(c); er != nil {
+		if e, ok := er.(*libval.InvalidValidationError); ok {
+			return e
+		}
+
+		for _, e := range er.(libval.ValidationErrors) {
+			//nolint goerr113
+			return fmt.Errorf("config field '%s' is not validated by constraint '%s'", e.Namespace(), e.ActualTag())
+		}
+	}
+
+	var n = make(map[string]bool)
+
+	for _, u := range c.Upstreams {
+		if n[u.Name] {
+			return fmt.Errorf("%w: '%s'", ErrUpstreamExists, u.Name)
+		}
+		n[u.Name] = true
+	}
+
+	return nil
+}
+
+func (c Config) getRetries() int {
+	if c.Retries < 0 {
+		return 0
+	} else if c.Retries > 0 {
+		return c.Retries
+	}
+
+	return DefaultRetries
+}
+
+func (c Config) isRetryStatus(code int) bool {
+	var lst = c.RetryStatus
+
+	if len(lst) < 1 {
+		lst = DefaultRetryStatus
+	}
+
+	for _, i := range lst {
+		if i == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c HealthConfig) getInterval() time.Duration {
+	if c.Interval > 0 {
+		return c.Interval.Time()
+	}
+
+	return DefaultHealthInterval
+}
+
+func (c HealthConfig) getTimeout() time.Duration {
+	if c.Timeout > 0 {
+		return c.Timeout.Time()
+	}
+
+	return DefaultHealthTimeout
+}
+
+func (c HealthConfig) getFall() uint8 {
+	if c.Fall > 0 {
+		return c.Fall
+	}
+
+	return DefaultHealthFall
+}
+
+func (c HealthConfig) getRise() uint8 {
+	if c.Rise > 0 {
+		return c.Rise
+	}
+
+	return DefaultHealthRise
+}
+
+func (c HealthConfig) isExpected(code int) bool {
+	if len(c.ExpectStatus) < 1 {
+		return code >= 200 && code < 400
+	}
+
+	for _, i := range c.ExpectStatus {
+		if i == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c HeaderConfig) apply(h http.Header) {
+	for _, k := range c.Remove {
+		h.Del(k)
+	}
+
+	for k, v := range c.Set {
+		h.Set(k, v)
+	}
+
+	for k, v := range c.Add {
+		h.Add(k, v)
+	}
+}
@@ -0,0 +1,100 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// check run one health check of all upstreams, concurrently.
+func (o *prx) check(ctx context.Context) {
+	var (
+		cfg = o.cfg().Health
+		wgp = sync.WaitGroup{}
+	)
+
+	if cfg.Disabled {
+		return
+	}
+
+	for _, u := range o.list() {
+		if u.isDisabled() {
+			continue
+		}
+
+		wgp.Add(1)
+
+		go func(u *upstream) {
+			defer wgp.Done()
+
+			if e := o.checkUpstream(ctx, u, cfg); e != nil {
+				u.failure(e, cfg.getFall(), true)
+			} else {
+				u.success(cfg.getRise(), true)
+			}
+		}(u)
+	}
+
+	wgp.Wait()
+}
+
+func (o *prx) checkUpstream(ctx context.Context, u *upstream, cfg HealthConfig) error {
+	x, n := context.WithTimeout(ctx, cfg.getTimeout())
+	defer n()
+
+	var (
+		adr = *u.u
+		req *http.Request
+		rsp *http.Response
+		err error
+	)
+
+	if len(cfg.Path) > 0 {
+		adr.Path, adr.RawPath = joinURLPath(u.u.Path, u.u.EscapedPath(), cfg.Path, "")
+	}
+
+	if req, err = http.NewRequestWithContext(x, http.MethodGet, adr.String(), nil); err != nil {
+		return err
+	} else if rsp, err = o.getTransport().RoundTrip(req); err != nil {
+		return err
+	}
+
+	defer func() {
+		_, _ = io.Copy(io.Discard, io.LimitReader(rsp.Body, 4096))
+		_ = rsp.Body.Close()
+	}()
+
+	if !cfg.isExpected(rsp.StatusCode) {
+		return fmt.Errorf("%w: %d", ErrHealthCheckStatus, rsp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package proxy
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httputil"
+	"sync"
+	"time"
+
+	srvtps "github.com/nabbar/golib/httpserver/types"
+	libsrv "github.com/nabbar/golib/server"
+)
+
+// UpstreamStatus is the state of one upstream of the pool.
+type UpstreamStatus struct {
+	Name      string    `json:"name"`
+	URL       string    `json:"url"`
+	Weight    int       `json:"weight"`
+	Disabled  bool      `json:"disabled"`
+	Alive     bool      `json:"alive"`
+	Active    int64     `json:"active"`
+	Requests  uint64    `json:"requests"`
+	Failures  uint64    `json:"failures"`
+	LastCheck time.Time `json:"last_check"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+type Proxy interface {
+	http.Handler
+
+	// Server start and stop the active health checks of the upstreams.
+	libsrv.Server
+
+	// Register return a FuncHandler adding this handler with the given key to the handlers returned by fct.
+	// The result can be given to the httpserver Handler function, to proxy the requests of the servers using this HandlerKey.
+	Register(key string, fct srvtps.FuncHandler) srvtps.FuncHandler
+
+	// Add register a new upstream into the pool.
+	Add(cfg UpstreamConfig) error
+
+	// Delete remove the upstream from the pool. The requests in progress are not interrupted.
+	Delete(name string)
+
+	// SetDisabled enable or disable an upstream without removing it from the pool.
+	SetDisabled(name string, disabled bool) error
+
+	// Upstreams return the state of all upstreams, in the order of the pool.
+	Upstreams() []UpstreamStatus
+
+	// SetTransport define the base transport used to send the requests and the health checks.
+	// By default, a clone of http.DefaultTransport using the tls config is used.
+	SetTransport(rt http.RoundTripper)
+
+	// GetConfig return the config used to create the proxy, with the current list of upstreams.
+	GetConfig() Config
+}
+
+// New return a reverse proxy sending the requests to the upstreams of the config.
+func New(cfg Config) (Proxy, error) {
+	if e := cfg.Validate(); e != nil {
+		return nil, e
+	}
+
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.ResponseHeaderTimeout = cfg.ResponseTimeout.Time()
+
+	if c := cfg.TLS.New().TlsConfig(""); c != nil {
+		t.TLSClientConfig = c
+	} else {
+		t.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	o := &prx{
+		m: sync.RWMutex{},
+		c: cfg,
+		u: make([]*upstream, 0, len(cfg.Upstreams)),
+		t: t,
+	}
+
+	for _, u := range cfg.Upstreams {
+		if i, e := newUpstream(u); e != nil {
+			return nil, e
+		} else {
+			o.u = append(o.u, i)
+		}
+	}
+
+	o.p = &httputil.ReverseProxy{
+		Rewrite:        o.rewrite,
+		Transport:      &transport{p: o},
+		FlushInterval:  cfg.FlushInterval.Time(),
+		ModifyResponse: o.modifyResponse,
+		ErrorHandler:   o.errorHandler,
+	}
+
+	return o, nil
+}
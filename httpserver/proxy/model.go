@@ -0,0 +1,225 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"strings"
+	"sync"
+
+	srvtps "github.com/nabbar/golib/httpserver/types"
+	librun "github.com/nabbar/golib/server/runner/ticker"
+)
+
+type prx struct {
+	m sync.RWMutex
+	c Config
+	u []*upstream
+	t http.RoundTripper
+	p *httputil.ReverseProxy
+	r librun.Ticker // health checks runner
+}
+
+func (o *prx) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	o.p.ServeHTTP(w, r)
+}
+
+func (o *prx) Register(key string, fct srvtps.FuncHandler) srvtps.FuncHandler {
+	return func() map[string]http.Handler {
+		var res = make(map[string]http.Handler)
+
+		if fct != nil {
+			for k, v := range fct() {
+				res[k] = v
+			}
+		}
+
+		res[key] = o
+		return res
+	}
+}
+
+func (o *prx) Add(cfg UpstreamConfig) error {
+	if o == nil {
+		return ErrInvalidInstance
+	}
+
+	u, e := newUpstream(cfg)
+
+	if e != nil {
+		return e
+	}
+
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	for _, i := range o.u {
+		if i.n == cfg.Name {
+			return fmt.Errorf("%w: '%s'", ErrUpstreamExists, cfg.Name)
+		}
+	}
+
+	o.u = append(o.u, u)
+
+	return nil
+}
+
+func (o *prx) Delete(name string) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	var lst = make([]*upstream, 0, len(o.u))
+
+	for _, u := range o.u {
+		if u.n != name {
+			lst = append(lst, u)
+		}
+	}
+
+	o.u = lst
+}
+
+func (o *prx) SetDisabled(name string, disabled bool) error {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	for _, u := range o.u {
+		if u.n == name {
+			u.setDisabled(disabled)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: '%s'", ErrUpstreamNotFound, name)
+}
+
+func (o *prx) list() []*upstream {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	var res = make([]*upstream, len(o.u))
+	copy(res, o.u)
+
+	return res
+}
+
+func (o *prx) Upstreams() []UpstreamStatus {
+	var (
+		lst = o.list()
+		res = make([]UpstreamStatus, 0, len(lst))
+	)
+
+	for _, u := range lst {
+		res = append(res, u.status())
+	}
+
+	return res
+}
+
+func (o *prx) SetTransport(rt http.RoundTripper) {
+	if rt == nil {
+		return
+	}
+
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.t = rt
+}
+
+func (o *prx) getTransport() http.RoundTripper {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	return o.t
+}
+
+func (o *prx) GetConfig() Config {
+	var (
+		lst = o.list()
+		res = make([]UpstreamConfig, 0, len(lst))
+	)
+
+	for _, u := range lst {
+		res = append(res, u.config())
+	}
+
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	var cfg = o.c
+	cfg.Upstreams = res
+
+	return cfg
+}
+
+// cfg return the config without building the list of upstreams.
+func (o *prx) cfg() Config {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	return o.c
+}
+
+func (o *prx) rewrite(r *httputil.ProxyRequest) {
+	var cfg = o.cfg()
+
+	r.SetXForwarded()
+
+	if p := strings.TrimSuffix(cfg.StripPrefix, "/"); len(p) > 0 {
+		if r.Out.URL.Path == p || strings.HasPrefix(r.Out.URL.Path, p+"/") {
+			r.Out.URL.Path = "/" + strings.TrimPrefix(strings.TrimPrefix(r.Out.URL.Path, p), "/")
+			r.Out.URL.RawPath = ""
+		}
+	}
+
+	cfg.RequestHeader.apply(r.Out.Header)
+}
+
+func (o *prx) modifyResponse(r *http.Response) error {
+	o.cfg().ResponseHeader.apply(r.Header)
+
+	return nil
+}
+
+func (o *prx) errorHandler(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, context.Canceled):
+		// client gone, nothing to answer
+		return
+	case errors.Is(err, ErrNoUpstream):
+		w.WriteHeader(http.StatusServiceUnavailable)
+	case errors.Is(err, context.DeadlineExceeded):
+		w.WriteHeader(http.StatusGatewayTimeout)
+	default:
+		w.WriteHeader(http.StatusBadGateway)
+	}
+}
@@ -0,0 +1,328 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package proxy_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	libdur "github.com/nabbar/golib/duration"
+	htppxy "github.com/nabbar/golib/httpserver/proxy"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// echo is the request received by an echo upstream.
+type echo struct {
+	Name   string      `json:"name"`
+	Method string      `json:"method"`
+	Host   string      `json:"host"`
+	Path   string      `json:"path"`
+	Query  string      `json:"query"`
+	Header http.Header `json:"header"`
+}
+
+// newEcho return an upstream answering the request received encoded in json.
+func newEcho(name string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Connection", "X-Resp-Hop")
+		w.Header().Set("X-Resp-Hop", "1")
+		w.Header().Set("X-Upstream", name)
+		w.Header().Set("X-Internal", "secret")
+
+		_ = json.NewEncoder(w).Encode(echo{
+			Name:   name,
+			Method: r.Method,
+			Host:   r.Host,
+			Path:   r.URL.Path,
+			Query:  r.URL.RawQuery,
+			Header: r.Header,
+		})
+	}))
+}
+
+// newStatus return an upstream answering the given status, after the given delay or the end of the request.
+func newStatus(code int, delay time.Duration) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+
+		w.WriteHeader(code)
+		_, _ = w.Write([]byte(http.StatusText(code)))
+	}))
+}
+
+func newProxy(cfg htppxy.Config, upstreams ...*httptest.Server) (htppxy.Proxy, *httptest.Server) {
+	cfg.Health.Disabled = true
+
+	for i, u := range upstreams {
+		cfg.Upstreams = append(cfg.Upstreams, htppxy.UpstreamConfig{
+			Name: "up" + string(rune('a'+i)),
+			URL:  u.URL + cfg.StripPrefix,
+		})
+	}
+
+	p, err := htppxy.New(cfg)
+	Expect(err).ToNot(HaveOccurred())
+
+	return p, httptest.NewServer(p)
+}
+
+func doRequest(req *http.Request) (*http.Response, []byte) {
+	rsp, err := http.DefaultClient.Do(req)
+	Expect(err).ToNot(HaveOccurred())
+
+	defer func() {
+		_ = rsp.Body.Close()
+	}()
+
+	b, err := io.ReadAll(rsp.Body)
+	Expect(err).ToNot(HaveOccurred())
+
+	return rsp, b
+}
+
+func doEcho(req *http.Request) (*http.Response, echo) {
+	var (
+		res    echo
+		rsp, b = doRequest(req)
+	)
+
+	Expect(rsp.StatusCode).To(Equal(http.StatusOK))
+	Expect(json.Unmarshal(b, &res)).ToNot(HaveOccurred())
+
+	return rsp, res
+}
+
+var _ = Describe("httpserver/proxy", func() {
+	Context("forwarding the requests to an upstream", func() {
+		var (
+			up  *httptest.Server
+			srv *httptest.Server
+		)
+
+		BeforeEach(func() {
+			up = newEcho("a")
+			_, srv = newProxy(htppxy.Config{
+				RequestHeader: htppxy.HeaderConfig{
+					Set:    map[string]string{"X-Set": "set"},
+					Add:    map[string]string{"X-Add": "add"},
+					Remove: []string{"X-Remove"},
+				},
+				ResponseHeader: htppxy.HeaderConfig{
+					Remove: []string{"X-Internal"},
+				},
+			}, up)
+		})
+
+		AfterEach(func() {
+			srv.Close()
+			up.Close()
+		})
+
+		It("must strip the hop-by-hop headers of the request and of the response", func() {
+			req, err := http.NewRequest(http.MethodGet, srv.URL+"/path", nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			req.Header.Set("Connection", "X-Hop")
+			req.Header.Set("X-Hop", "1")
+			req.Header.Set("Keep-Alive", "timeout=5")
+			req.Header.Set("Proxy-Authorization", "Basic dXNlcjpwYXNz")
+			req.Header.Set("Proxy-Connection", "keep-alive")
+			req.Header.Set("X-End-To-End", "1")
+
+			rsp, res := doEcho(req)
+
+			for _, k := range []string{"X-Hop", "Keep-Alive", "Proxy-Authorization", "Proxy-Connection"} {
+				Expect(res.Header.Values(k)).To(BeEmpty(), "request header %s", k)
+			}
+
+			Expect(res.Header.Get("X-End-To-End")).To(Equal("1"))
+			Expect(rsp.Header.Get("X-Resp-Hop")).To(BeEmpty())
+			Expect(rsp.Header.Get("X-Upstream")).To(Equal("a"))
+		})
+
+		It("must replace the forwarded headers given by the client", func() {
+			req, err := http.NewRequest(http.MethodGet, srv.URL+"/path", nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			req.Host = "front.example.com"
+			req.Header.Set("X-Forwarded-For", "6.6.6.6")
+			req.Header.Set("X-Forwarded-Host", "evil.example.com")
+			req.Header.Set("X-Forwarded-Proto", "https")
+			req.Header.Set("Forwarded", "for=6.6.6.6")
+
+			_, res := doEcho(req)
+
+			Expect(res.Header.Values("X-Forwarded-For")).To(Equal([]string{"127.0.0.1"}))
+			Expect(res.Header.Get("X-Forwarded-Host")).To(Equal("front.example.com"))
+			Expect(res.Header.Get("X-Forwarded-Proto")).To(Equal("http"))
+			Expect(res.Header.Get("Forwarded")).To(BeEmpty())
+			Expect(res.Host).To(Equal(strings.TrimPrefix(up.URL, "http://")))
+		})
+
+		It("must rewrite the configured request and response headers", func() {
+			req, err := http.NewRequest(http.MethodGet, srv.URL+"/path", nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			req.Header.Set("X-Set", "client")
+			req.Header.Set("X-Add", "client")
+			req.Header.Set("X-Remove", "client")
+
+			rsp, res := doEcho(req)
+
+			Expect(res.Header.Values("X-Set")).To(Equal([]string{"set"}))
+			Expect(res.Header.Values("X-Add")).To(Equal([]string{"client", "add"}))
+			Expect(res.Header.Values("X-Remove")).To(BeEmpty())
+			Expect(rsp.Header.Get("X-Internal")).To(BeEmpty())
+		})
+	})
+
+	Context("forwarding the requests with a prefix and the host of the client", func() {
+		It("must strip the prefix, join the upstream path and keep the host", func() {
+			var up = newEcho("a")
+			defer up.Close()
+
+			_, srv := newProxy(htppxy.Config{
+				StripPrefix:  "/api",
+				PreserveHost: true,
+			}, up)
+			defer srv.Close()
+
+			req, err := http.NewRequest(http.MethodGet, srv.URL+"/api/v1/items?id=1", nil)
+			Expect(err).ToNot(HaveOccurred())
+			req.Host = "front.example.com"
+
+			_, res := doEcho(req)
+
+			Expect(res.Path).To(Equal("/api/v1/items"))
+			Expect(res.Query).To(Equal("id=1"))
+			Expect(res.Host).To(Equal("front.example.com"))
+
+			req, err = http.NewRequest(http.MethodGet, srv.URL+"/apix", nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, res = doEcho(req)
+			Expect(res.Path).To(Equal("/api/apix"))
+		})
+	})
+
+	Context("with upstreams failing", func() {
+		It("must answer 502 if the upstream is not reachable", func() {
+			var up = newEcho("a")
+			up.Close()
+
+			_, srv := newProxy(htppxy.Config{}, up)
+			defer srv.Close()
+
+			req, err := http.NewRequest(http.MethodGet, srv.URL+"/", nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			rsp, _ := doRequest(req)
+			Expect(rsp.StatusCode).To(Equal(http.StatusBadGateway))
+		})
+
+		It("must answer 503 if no upstream is enabled", func() {
+			var up = newEcho("a")
+			defer up.Close()
+
+			p, srv := newProxy(htppxy.Config{}, up)
+			defer srv.Close()
+
+			Expect(p.SetDisabled("upa", true)).ToNot(HaveOccurred())
+
+			req, err := http.NewRequest(http.MethodGet, srv.URL+"/", nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			rsp, _ := doRequest(req)
+			Expect(rsp.StatusCode).To(Equal(http.StatusServiceUnavailable))
+		})
+
+		It("must retry the idempotent requests only on another upstream", func() {
+			var (
+				bad = newStatus(http.StatusServiceUnavailable, 0)
+				god = newEcho("b")
+			)
+
+			defer bad.Close()
+			defer god.Close()
+
+			p, srv := newProxy(htppxy.Config{}, bad, god)
+			defer srv.Close()
+
+			for i := 0; i < 4; i++ {
+				req, err := http.NewRequest(http.MethodGet, srv.URL+"/", nil)
+				Expect(err).ToNot(HaveOccurred())
+
+				rsp, _ := doRequest(req)
+				Expect(rsp.StatusCode).To(Equal(http.StatusOK))
+			}
+
+			var codes = make([]int, 0)
+
+			for i := 0; i < 2; i++ {
+				req, err := http.NewRequest(http.MethodPost, srv.URL+"/", strings.NewReader("body"))
+				Expect(err).ToNot(HaveOccurred())
+
+				rsp, _ := doRequest(req)
+				codes = append(codes, rsp.StatusCode)
+			}
+
+			Expect(codes).To(ConsistOf(http.StatusOK, http.StatusServiceUnavailable))
+
+			var sts = p.Upstreams()
+			Expect(sts).To(HaveLen(2))
+			Expect(sts[0].Failures).To(BeNumerically(">=", 3))
+			Expect(sts[1].Failures).To(BeNumerically("==", 0))
+		})
+
+		It("must answer 504 if the upstream does not respond before the response timeout", func() {
+			var up = newStatus(http.StatusOK, 5*time.Second)
+			defer up.Close()
+
+			_, srv := newProxy(htppxy.Config{
+				ResponseTimeout: libdur.ParseDuration(200 * time.Millisecond),
+			}, up)
+			defer srv.Close()
+
+			req, err := http.NewRequest(http.MethodGet, srv.URL+"/", nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			var start = time.Now()
+			rsp, _ := doRequest(req)
+			Expect(rsp.StatusCode).To(Equal(http.StatusGatewayTimeout))
+			Expect(time.Since(start)).To(BeNumerically("<", 2*time.Second))
+		})
+	})
+})
@@ -0,0 +1,109 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package proxy
+
+import (
+	"context"
+	"time"
+
+	librun "github.com/nabbar/golib/server/runner/ticker"
+)
+
+func (o *prx) getRunner() librun.Ticker {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	return o.r
+}
+
+func (o *prx) Start(ctx context.Context) error {
+	if o == nil {
+		return ErrInvalidInstance
+	} else if o.IsRunning() {
+		if e := o.Stop(ctx); e != nil {
+			return e
+		}
+	}
+
+	// first check without waiting for the first tick
+	o.check(ctx)
+
+	var r = librun.New(o.cfg().Health.getInterval(), func(ctx context.Context, tck *time.Ticker) error {
+		o.check(ctx)
+		return nil
+	})
+
+	o.m.Lock()
+	o.r = r
+	o.m.Unlock()
+
+	return r.Start(ctx)
+}
+
+func (o *prx) Stop(ctx context.Context) error {
+	if o == nil {
+		return ErrInvalidInstance
+	}
+
+	if r := o.getRunner(); r == nil {
+		return nil
+	} else if e := r.Stop(ctx); e != nil {
+		return e
+	}
+
+	o.m.Lock()
+	o.r = nil
+	o.m.Unlock()
+
+	return nil
+}
+
+func (o *prx) Restart(ctx context.Context) error {
+	if e := o.Stop(ctx); e != nil {
+		return e
+	}
+
+	return o.Start(ctx)
+}
+
+func (o *prx) IsRunning() bool {
+	if o == nil {
+		return false
+	} else if r := o.getRunner(); r == nil {
+		return false
+	} else {
+		return r.IsRunning()
+	}
+}
+
+func (o *prx) Uptime() time.Duration {
+	if r := o.getRunner(); r == nil {
+		return 0
+	} else {
+		return r.Uptime()
+	}
+}
@@ -0,0 +1,79 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package proxy
+
+import (
+	"strings"
+)
+
+type Strategy uint8
+
+const (
+	// StrategyRoundRobin send the requests to each upstream in turn, following their weight.
+	StrategyRoundRobin Strategy = iota
+	// StrategyRandom send each request to a random upstream, following their weight.
+	StrategyRandom
+	// StrategyLeastConn send each request to the upstream with the less active requests by weight.
+	StrategyLeastConn
+	// StrategyIPHash send all requests of a client address to the same upstream while the pool is unchanged.
+	StrategyIPHash
+)
+
+func ParseStrategy(s string) Strategy {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case StrategyRandom.String():
+		return StrategyRandom
+	case StrategyLeastConn.String():
+		return StrategyLeastConn
+	case StrategyIPHash.String():
+		return StrategyIPHash
+	default:
+		return StrategyRoundRobin
+	}
+}
+
+func (s Strategy) String() string {
+	switch s {
+	case StrategyRandom:
+		return "random"
+	case StrategyLeastConn:
+		return "least-conn"
+	case StrategyIPHash:
+		return "ip-hash"
+	default:
+		return "round-robin"
+	}
+}
+
+func (s Strategy) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *Strategy) UnmarshalText(data []byte) error {
+	*s = ParseStrategy(string(data))
+	return nil
+}
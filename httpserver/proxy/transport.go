@@ -0,0 +1,218 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package proxy
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// transport send the request to an upstream chosen by the balancer,
+// and retry on the other upstreams the idempotent requests without body.
+type transport struct {
+	p *prx
+}
+
+func isIdempotent(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return r.Body == nil || r.Body == http.NoBody
+	default:
+		return false
+	}
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var (
+		p   = t.p
+		cfg = p.cfg()
+		try = 1
+		fal = p.passiveFall(cfg)
+		exc = make(map[*upstream]bool)
+		err error
+	)
+
+	if isIdempotent(req) {
+		try += cfg.getRetries()
+	}
+
+	for i := 0; i < try; i++ {
+		var u = p.pick(req, exc)
+
+		if u == nil {
+			break
+		}
+
+		exc[u] = true
+
+		rsp, e := t.send(u, req, cfg)
+
+		if e != nil {
+			err = e
+			u.failure(e, fal, false)
+
+			if req.Context().Err() != nil {
+				return nil, req.Context().Err()
+			}
+
+			continue
+		}
+
+		if cfg.isRetryStatus(rsp.StatusCode) {
+			u.failure(nil, fal, false)
+
+			if i < try-1 && p.hasCandidate(exc) {
+				_, _ = io.Copy(io.Discard, io.LimitReader(rsp.Body, 4096))
+				_ = rsp.Body.Close()
+				continue
+			}
+		} else {
+			u.success(cfg.Health.getRise(), false)
+		}
+
+		return rsp, nil
+	}
+
+	if err == nil {
+		err = ErrNoUpstream
+	}
+
+	return nil, err
+}
+
+func (t *transport) send(u *upstream, req *http.Request, cfg Config) (*http.Response, error) {
+	var out = req.Clone(req.Context())
+
+	out.URL.Scheme = u.u.Scheme
+	out.URL.Host = u.u.Host
+	out.URL.Path, out.URL.RawPath = joinURLPath(u.u.Path, u.u.EscapedPath(), req.URL.Path, req.URL.EscapedPath())
+
+	if len(u.u.RawQuery) > 0 {
+		if len(out.URL.RawQuery) > 0 {
+			out.URL.RawQuery = u.u.RawQuery + "&" + out.URL.RawQuery
+		} else {
+			out.URL.RawQuery = u.u.RawQuery
+		}
+	}
+
+	if !cfg.PreserveHost {
+		out.Host = ""
+	}
+
+	u.r.Add(1)
+	u.c.Add(1)
+
+	rsp, err := t.p.getTransport().RoundTrip(out)
+
+	if err != nil {
+		u.c.Add(-1)
+		return nil, err
+	}
+
+	// the request stays active until the end of the body copy, for the least-conn strategy
+	rsp.Body = &activeBody{ReadCloser: rsp.Body, u: u}
+
+	return rsp, nil
+}
+
+// passiveFall return the number of failed requests marking an upstream as down.
+// The failed requests only mark an upstream as down if the active checks are running to bring it up again.
+func (o *prx) passiveFall(cfg Config) uint8 {
+	if cfg.Health.Disabled || !o.IsRunning() {
+		return 0
+	}
+
+	return cfg.Health.getFall()
+}
+
+func (o *prx) hasCandidate(exclude map[*upstream]bool) bool {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	return len(o.candidates(exclude)) > 0
+}
+
+type activeBody struct {
+	io.ReadCloser
+	o sync.Once
+	u *upstream
+}
+
+func (b *activeBody) Close() error {
+	b.o.Do(func() {
+		b.u.c.Add(-1)
+	})
+
+	return b.ReadCloser.Close()
+}
+
+// Write forward the writes for the switching protocol responses (like websocket), whose body is writable.
+func (b *activeBody) Write(p []byte) (int, error) {
+	if w, k := b.ReadCloser.(io.Writer); k {
+		return w.Write(p)
+	}
+
+	return 0, http.ErrNotSupported
+}
+
+func singleJoiningSlash(a, b string) string {
+	var (
+		as = strings.HasSuffix(a, "/")
+		bs = strings.HasPrefix(b, "/")
+	)
+
+	switch {
+	case as && bs:
+		return a + b[1:]
+	case !as && !bs:
+		return a + "/" + b
+	}
+
+	return a + b
+}
+
+func joinURLPath(ap, arp, bp, brp string) (path, rawpath string) {
+	if len(ap) < 1 {
+		return bp, brp
+	}
+
+	if len(arp) < 1 {
+		arp = ap
+	}
+
+	if len(brp) < 1 {
+		brp = bp
+	}
+
+	if arp == ap && brp == bp {
+		return singleJoiningSlash(ap, bp), ""
+	}
+
+	return singleJoiningSlash(ap, bp), singleJoiningSlash(arp, brp)
+}
@@ -0,0 +1,186 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package proxy
+
+import (
+	"fmt"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type upstream struct {
+	m sync.Mutex
+	n string   // name
+	u *url.URL // base url
+	w int      // weight
+	d bool     // disabled
+	g int      // current weight of the smooth weighted round-robin, protected by the proxy lock
+
+	a *atomic.Bool   // alive
+	c *atomic.Int64  // active requests
+	r *atomic.Uint64 // requests
+	f *atomic.Uint64 // failures
+
+	hf uint8     // consecutive failures
+	hr uint8     // consecutive successes while down
+	lc time.Time // last check
+	le string    // last error
+}
+
+func newUpstream(cfg UpstreamConfig) (*upstream, error) {
+	u, e := url.Parse(cfg.URL)
+
+	if e != nil {
+		return nil, fmt.Errorf("%w: '%s': %v", ErrUpstreamInvalid, cfg.Name, e)
+	} else if (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) < 1 {
+		return nil, fmt.Errorf("%w: '%s'", ErrUpstreamInvalid, cfg.Name)
+	}
+
+	var w = cfg.Weight
+
+	if w < 1 {
+		w = 1
+	}
+
+	o := &upstream{
+		m: sync.Mutex{},
+		n: cfg.Name,
+		u: u,
+		w: w,
+		d: cfg.Disabled,
+		a: new(atomic.Bool),
+		c: new(atomic.Int64),
+		r: new(atomic.Uint64),
+		f: new(atomic.Uint64),
+	}
+
+	o.a.Store(true)
+
+	return o, nil
+}
+
+func (o *upstream) config() UpstreamConfig {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	return UpstreamConfig{
+		Name:     o.n,
+		URL:      o.u.String(),
+		Weight:   o.w,
+		Disabled: o.d,
+	}
+}
+
+func (o *upstream) status() UpstreamStatus {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	return UpstreamStatus{
+		Name:      o.n,
+		URL:       o.u.String(),
+		Weight:    o.w,
+		Disabled:  o.d,
+		Alive:     o.a.Load(),
+		Active:    o.c.Load(),
+		Requests:  o.r.Load(),
+		Failures:  o.f.Load(),
+		LastCheck: o.lc,
+		LastError: o.le,
+	}
+}
+
+func (o *upstream) isDisabled() bool {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	return o.d
+}
+
+func (o *upstream) setDisabled(flag bool) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.d = flag
+}
+
+// failure count a failed check or request. The upstream is marked as down after fall consecutive failures.
+// A zero fall never marks the upstream as down.
+func (o *upstream) failure(err error, fall uint8, check bool) {
+	o.f.Add(1)
+
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if check {
+		o.lc = time.Now()
+	}
+
+	if err != nil {
+		o.le = err.Error()
+	}
+
+	o.hr = 0
+
+	if o.hf < 255 {
+		o.hf++
+	}
+
+	if fall > 0 && o.hf >= fall {
+		o.a.Store(false)
+	}
+}
+
+// success count a successful check or request. A down upstream is marked as up after rise consecutive successful checks.
+func (o *upstream) success(rise uint8, check bool) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.hf = 0
+
+	if check {
+		o.lc = time.Now()
+	}
+
+	if o.a.Load() {
+		o.le = ""
+		return
+	} else if !check {
+		return
+	}
+
+	if o.hr < 255 {
+		o.hr++
+	}
+
+	if o.hr >= rise {
+		o.hr = 0
+		o.le = ""
+		o.a.Store(true)
+	}
+}
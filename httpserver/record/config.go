@@ -0,0 +1,101 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package record
+
+import (
+	"net/http"
+	"time"
+)
+
+const (
+	// DefaultMaxBody is the max size of a request or response body kept in an entry.
+	DefaultMaxBody = 1024 * 1024
+
+	// entryExt is the extension of the entries in the archive.
+	entryExt = ".json"
+)
+
+// DefaultRedactHeaders are the headers masked in the entries if Config.RedactHeaders is nil.
+var DefaultRedactHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "Proxy-Authorization"}
+
+// RedactMask is the value of the masked headers.
+const RedactMask = "[REDACTED]"
+
+// Request is the recorded part of a request.
+type Request struct {
+	Method    string      `json:"method"`
+	URL       string      `json:"url"`
+	Host      string      `json:"host,omitempty"`
+	Header    http.Header `json:"header,omitempty"`
+	Body      []byte      `json:"body,omitempty"`
+	Truncated bool        `json:"truncated,omitempty"`
+}
+
+// Response is the recorded part of a response.
+type Response struct {
+	Status    int         `json:"status"`
+	Header    http.Header `json:"header,omitempty"`
+	Body      []byte      `json:"body,omitempty"`
+	Truncated bool        `json:"truncated,omitempty"`
+}
+
+// Entry is an exchange between a client and the handler, stored as one JSON file in the archive.
+type Entry struct {
+	Index    int           `json:"index"`
+	Time     time.Time     `json:"time"`
+	Duration time.Duration `json:"duration"`
+	Request  Request       `json:"request"`
+	Response Response      `json:"response"`
+}
+
+// Config is the configuration of a recorder.
+type Config struct {
+	// MaxBody is the max size of a body kept in an entry. Default is DefaultMaxBody.
+	// The bodies bigger than this size are truncated in the entry, not in the exchange.
+	MaxBody int64
+
+	// RedactHeaders are the headers masked in the entries. Nil means DefaultRedactHeaders.
+	RedactHeaders []string
+
+	// Filter select the requests to record. Nil means all the requests.
+	Filter func(r *http.Request) bool
+}
+
+func (c Config) getMaxBody() int64 {
+	if c.MaxBody > 0 {
+		return c.MaxBody
+	}
+
+	return DefaultMaxBody
+}
+
+func (c Config) getRedact() []string {
+	if c.RedactHeaders == nil {
+		return DefaultRedactHeaders
+	}
+
+	return c.RedactHeaders
+}
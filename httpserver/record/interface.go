@@ -0,0 +1,96 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package record
+
+import (
+	"io"
+	"net/http"
+	"sync"
+
+	arctar "github.com/nabbar/golib/archive/archive/tar"
+)
+
+type Recorder interface {
+	// Handler return the given handler recording each exchange as a JSON entry of the archive.
+	// The exchange is unchanged: the bodies are only copied up to the max body size.
+	Handler(next http.Handler) http.Handler
+
+	// Count return the number of entries recorded.
+	Count() int
+
+	// Close flush the archive and close the underlying writer. The next exchanges are not recorded.
+	Close() error
+}
+
+// NewRecorder return a recorder writing the exchanges as a tar archive of JSON entries into the given writer.
+func NewRecorder(w io.WriteCloser, cfg Config) (Recorder, error) {
+	if w == nil {
+		return nil, ErrInvalidInstance
+	}
+
+	a, e := arctar.NewWriter(w)
+
+	if e != nil {
+		return nil, e
+	}
+
+	return &rec{
+		m: sync.Mutex{},
+		w: a,
+		c: cfg,
+	}, nil
+}
+
+// Load return the entries of the given archive, sorted by their index.
+func Load(r io.ReadCloser) ([]Entry, error) {
+	if r == nil {
+		return nil, ErrInvalidInstance
+	}
+
+	a, e := arctar.NewReader(r)
+
+	if e != nil {
+		return nil, e
+	}
+
+	defer func() {
+		_ = a.Close()
+	}()
+
+	return load(a)
+}
+
+// Replay send the request of each entry to the given handler and compare the responses
+// with the recorded ones. The result of each entry is returned in the same order.
+func Replay(h http.Handler, entries []Entry, opt ReplayOptions) []Result {
+	var res = make([]Result, 0, len(entries))
+
+	for _, e := range entries {
+		res = append(res, replay(h, e, opt))
+	}
+
+	return res
+}
@@ -0,0 +1,238 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package record
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	arctps "github.com/nabbar/golib/archive/archive/types"
+)
+
+type rec struct {
+	m sync.Mutex
+	w arctps.Writer
+	c Config
+	n int
+	x bool // closed
+}
+
+// capture return a copy of the first max bytes of the body and a reader giving the whole body.
+func capture(r io.ReadCloser, max int64) ([]byte, bool, io.ReadCloser) {
+	if r == nil || r == http.NoBody {
+		return nil, false, r
+	}
+
+	var buf = bytes.NewBuffer(make([]byte, 0))
+
+	_, _ = io.CopyN(buf, r, max+1)
+
+	p := buf.Bytes()
+	t := int64(len(p)) > max
+
+	if t {
+		p = p[:max]
+	}
+
+	return bytes.Clone(p), t, &body{
+		Reader: io.MultiReader(bytes.NewReader(buf.Bytes()), r),
+		c:      r,
+	}
+}
+
+type body struct {
+	io.Reader
+	c io.Closer
+}
+
+func (b *body) Close() error {
+	return b.c.Close()
+}
+
+func (o *rec) header(h http.Header) http.Header {
+	var res = h.Clone()
+
+	for _, k := range o.c.getRedact() {
+		if _, ok := res[http.CanonicalHeaderKey(k)]; ok {
+			res.Set(k, RedactMask)
+		}
+	}
+
+	return res
+}
+
+func (o *rec) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if o.isClosed() || (o.c.Filter != nil && !o.c.Filter(r)) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var (
+			max = o.c.getMaxBody()
+			now = time.Now()
+			ent = Entry{
+				Time: now,
+				Request: Request{
+					Method: r.Method,
+					URL:    r.URL.RequestURI(),
+					Host:   r.Host,
+					Header: o.header(r.Header),
+				},
+			}
+			rsp = &writer{
+				ResponseWriter: w,
+				b:              bytes.NewBuffer(make([]byte, 0)),
+				m:              max,
+			}
+		)
+
+		ent.Request.Body, ent.Request.Truncated, r.Body = capture(r.Body, max)
+
+		next.ServeHTTP(rsp, r)
+
+		ent.Duration = time.Since(now)
+		ent.Response = Response{
+			Status:    rsp.status(),
+			Header:    o.header(w.Header()),
+			Body:      rsp.b.Bytes(),
+			Truncated: rsp.t,
+		}
+
+		_ = o.add(ent)
+	})
+}
+
+func (o *rec) add(ent Entry) error {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if o.x {
+		return ErrClosed
+	}
+
+	o.n++
+	ent.Index = o.n
+
+	p, e := json.Marshal(ent)
+
+	if e != nil {
+		return e
+	}
+
+	return o.w.AddReader(fmt.Sprintf("%06d%s", ent.Index, entryExt), int64(len(p)), 0644, bytes.NewReader(p))
+}
+
+func (o *rec) isClosed() bool {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	return o.x
+}
+
+func (o *rec) Count() int {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	return o.n
+}
+
+func (o *rec) Close() error {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if o.x {
+		return nil
+	}
+
+	o.x = true
+	return o.w.Close()
+}
+
+// writer keep a copy of the status and the first bytes of the body of the response.
+type writer struct {
+	http.ResponseWriter
+
+	s int           // status
+	b *bytes.Buffer // body copy
+	m int64         // max body copy
+	t bool          // body copy truncated
+}
+
+func (w *writer) status() int {
+	if w.s == 0 {
+		return http.StatusOK
+	}
+
+	return w.s
+}
+
+func (w *writer) WriteHeader(code int) {
+	if w.s == 0 {
+		w.s = code
+	}
+
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	if w.s == 0 {
+		w.s = http.StatusOK
+	}
+
+	if r := w.m - int64(w.b.Len()); r > 0 {
+		if int64(len(p)) > r {
+			w.b.Write(p[:r])
+			w.t = true
+		} else {
+			w.b.Write(p)
+		}
+	} else if len(p) > 0 {
+		w.t = true
+	}
+
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *writer) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *writer) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func isEntry(name string) bool {
+	return strings.HasSuffix(name, entryExt)
+}
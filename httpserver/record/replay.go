@@ -0,0 +1,164 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package record
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+
+	arctps "github.com/nabbar/golib/archive/archive/types"
+)
+
+// ReplayOptions define how the responses are compared with the recorded ones.
+type ReplayOptions struct {
+	// IgnoreBody skip the comparison of the bodies.
+	IgnoreBody bool
+
+	// CompareHeaders are the response headers compared with the recorded ones.
+	// By default, the headers are not compared.
+	CompareHeaders []string
+
+	// Prepare is called with each request before sending it to the handler (ex: to add the masked credentials).
+	Prepare func(r *http.Request)
+}
+
+// Result is the result of the replay of an entry.
+type Result struct {
+	// Entry is the recorded entry.
+	Entry Entry
+	// Response is the response of the handler to the replayed request.
+	Response Response
+	// Diff is the list of the differences between the recorded response and the replayed one.
+	Diff []string
+}
+
+// Match return true if the replayed response is the same as the recorded one.
+func (r Result) Match() bool {
+	return len(r.Diff) < 1
+}
+
+func (r Result) String() string {
+	var s = fmt.Sprintf("#%d %s %s", r.Entry.Index, r.Entry.Request.Method, r.Entry.Request.URL)
+
+	if r.Match() {
+		return s + ": OK"
+	}
+
+	return s + ": " + strings.Join(r.Diff, ", ")
+}
+
+func load(a arctps.Reader) ([]Entry, error) {
+	var (
+		err error
+		res = make([]Entry, 0)
+	)
+
+	a.Walk(func(i fs.FileInfo, r io.ReadCloser, name string, _ string) bool {
+		if i.IsDir() || !isEntry(name) {
+			return true
+		}
+
+		var ent Entry
+
+		if e := json.NewDecoder(r).Decode(&ent); e != nil {
+			err = fmt.Errorf("%w '%s': %v", ErrInvalidEntry, name, e)
+			return false
+		}
+
+		res = append(res, ent)
+		return true
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(res, func(a, b Entry) int {
+		return a.Index - b.Index
+	})
+
+	return res, nil
+}
+
+func replay(h http.Handler, ent Entry, opt ReplayOptions) Result {
+	var (
+		res = Result{Entry: ent}
+		req = httptest.NewRequest(ent.Request.Method, ent.Request.URL, bytes.NewReader(ent.Request.Body))
+		rec = httptest.NewRecorder()
+	)
+
+	req.Host = ent.Request.Host
+
+	for k, v := range ent.Request.Header {
+		req.Header[k] = slices.Clone(v)
+	}
+
+	if opt.Prepare != nil {
+		opt.Prepare(req)
+	}
+
+	h.ServeHTTP(rec, req)
+
+	res.Response = Response{
+		Status: rec.Code,
+		Header: rec.Header().Clone(),
+		Body:   rec.Body.Bytes(),
+	}
+
+	if res.Response.Status != ent.Response.Status {
+		res.Diff = append(res.Diff, fmt.Sprintf("status %d instead of %d", res.Response.Status, ent.Response.Status))
+	}
+
+	for _, k := range opt.CompareHeaders {
+		if a, b := res.Response.Header.Get(k), ent.Response.Header.Get(k); a != b {
+			res.Diff = append(res.Diff, fmt.Sprintf("header '%s' is '%s' instead of '%s'", k, a, b))
+		}
+	}
+
+	if opt.IgnoreBody {
+		return res
+	}
+
+	var b = res.Response.Body
+
+	// a truncated record is compared only with the same first bytes
+	if ent.Response.Truncated && len(b) > len(ent.Response.Body) {
+		b = b[:len(ent.Response.Body)]
+	}
+
+	if !bytes.Equal(b, ent.Response.Body) {
+		res.Diff = append(res.Diff, "body differs")
+	}
+
+	return res
+}
@@ -0,0 +1,213 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	loglvl "github.com/nabbar/golib/logger/level"
+	libptc "github.com/nabbar/golib/network/protocol"
+)
+
+// ACMEChallengePath is the path prefix of the ACME HTTP-01 challenges. These requests are not redirected
+// by the http redirect server but passed to the handler of the server, to allow the renewal of the certificates.
+const ACMEChallengePath = "/.well-known/acme-challenge/"
+
+// sameBind return true if the two listen addresses collide: same port and same host, an empty
+// or unspecified host (0.0.0.0, ::) matching any host, and localhost matching any loopback address.
+func sameBind(a, b string) bool {
+	ha, pa, ea := net.SplitHostPort(a)
+	hb, pb, eb := net.SplitHostPort(b)
+
+	if ea != nil || eb != nil {
+		return a == b
+	} else if pa != pb {
+		return false
+	}
+
+	var key = func(h string) string {
+		h = strings.ToLower(strings.TrimSuffix(h, "."))
+
+		if h == "localhost" {
+			return "loopback"
+		} else if ip := net.ParseIP(h); ip == nil {
+			return h
+		} else if ip.IsLoopback() {
+			return "loopback"
+		} else {
+			return ip.String()
+		}
+	}
+
+	var wild = func(h string) bool {
+		ip := net.ParseIP(h)
+		return len(h) < 1 || (ip != nil && ip.IsUnspecified())
+	}
+
+	return wild(ha) || wild(hb) || key(ha) == key(hb)
+}
+
+func (o *srv) getExposeURL() *url.URL {
+	if i, l := o.c.Load(cfgExpose); !l {
+		return nil
+	} else if v, k := i.(*url.URL); !k {
+		return nil
+	} else {
+		return v
+	}
+}
+
+func (o *srv) setRedirect(r *http.Server) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.d = r
+}
+
+func (o *srv) getRedirect() *http.Server {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	return o.d
+}
+
+// redirectTarget return the url of the given request on the exposed url of the server.
+// An unspecified exposed host (empty, 0.0.0.0, ::) is replaced by the host requested by the client.
+func redirectTarget(exp *url.URL, r *http.Request) string {
+	if exp == nil {
+		exp = &url.URL{}
+	}
+
+	var u = url.URL{
+		Scheme:   exp.Scheme,
+		Host:     exp.Host,
+		Path:     strings.TrimSuffix(exp.Path, "/") + r.URL.Path,
+		RawQuery: r.URL.RawQuery,
+	}
+
+	if len(u.Scheme) < 1 {
+		u.Scheme = "https"
+	}
+
+	if ip := net.ParseIP(exp.Hostname()); len(exp.Hostname()) < 1 || (ip != nil && ip.IsUnspecified()) {
+		var h = r.Host
+
+		if n, _, e := net.SplitHostPort(h); e == nil {
+			h = n
+		}
+
+		if p := exp.Port(); len(p) > 0 {
+			h = net.JoinHostPort(h, p)
+		}
+
+		u.Host = h
+	}
+
+	return u.String()
+}
+
+// newRedirect return the http redirect server if defined into the config, or nil.
+// The ACME HTTP-01 challenges are passed to the given handler. Must be called after the initialisation of the given server.
+func (o *srv) newRedirect(s *http.Server, acme http.Handler) *http.Server {
+	var cfg = o.GetConfig()
+
+	if cfg == nil || len(cfg.RedirectHTTP) < 1 {
+		return nil
+	}
+
+	var exp = o.getExposeURL()
+
+	// #nosec
+	return &http.Server{
+		Addr: cfg.RedirectHTTP,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasPrefix(r.URL.Path, ACMEChallengePath) {
+				acme.ServeHTTP(w, r)
+				return
+			}
+
+			http.Redirect(w, r, redirectTarget(exp, r), http.StatusMovedPermanently)
+		}),
+		ReadTimeout:       s.ReadTimeout,
+		ReadHeaderTimeout: s.ReadHeaderTimeout,
+		WriteTimeout:      s.WriteTimeout,
+		IdleTimeout:       s.IdleTimeout,
+		MaxHeaderBytes:    s.MaxHeaderBytes,
+		ErrorLog:          s.ErrorLog,
+	}
+}
+
+// runRedirect open the listener of the http redirect server if any and serve it.
+// An error is returned if the listener cannot be opened.
+func (o *srv) runRedirect(ctx context.Context) error {
+	var r = o.getRedirect()
+
+	if r == nil {
+		return nil
+	}
+
+	lis, err := net.Listen(libptc.NetworkTCP.Code(), r.Addr)
+
+	if err != nil {
+		return err
+	}
+
+	r.BaseContext = func(listener net.Listener) context.Context {
+		return ctx
+	}
+
+	go func() {
+		ent := o.logger().Entry(loglvl.InfoLevel, "HTTP redirect server is starting")
+		ent.FieldAdd("redirect_bind", r.Addr)
+		ent.Log()
+
+		if e := r.Serve(lis); e != nil && !errors.Is(e, http.ErrServerClosed) {
+			ent = o.logger().Entry(loglvl.ErrorLevel, "HTTP redirect server stopped")
+			ent.FieldAdd("redirect_bind", r.Addr)
+			ent.ErrorAdd(true, e)
+			ent.Log()
+		}
+	}()
+
+	return nil
+}
+
+// stopRedirect shutdown the http redirect server if any.
+func (o *srv) stopRedirect(ctx context.Context) error {
+	var r = o.getRedirect()
+
+	if r == nil {
+		return nil
+	}
+
+	o.setRedirect(nil)
+	return r.Shutdown(ctx)
+}
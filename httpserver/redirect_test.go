@@ -0,0 +1,271 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package httpserver_test
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	libhts "github.com/nabbar/golib/httpserver"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// redirectClient return an http client not following the redirections.
+func redirectClient() *http.Client {
+	return &http.Client{
+		Timeout: 5 * time.Second,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// redirectGet call the given path on the redirect server with the given Host header
+// and return the status, the location and the body of the response.
+func redirectGet(adr, host, pth string) (int, string, string) {
+	cli := redirectClient()
+	defer cli.CloseIdleConnections()
+
+	req, err := http.NewRequest(http.MethodGet, "http://"+adr+pth, nil)
+	Expect(err).ToNot(HaveOccurred())
+
+	if len(host) > 0 {
+		req.Host = host
+	}
+
+	rsp, err := cli.Do(req)
+	Expect(err).ToNot(HaveOccurred())
+
+	defer func() {
+		_ = rsp.Body.Close()
+	}()
+
+	b, err := io.ReadAll(rsp.Body)
+	Expect(err).ToNot(HaveOccurred())
+
+	return rsp.StatusCode, rsp.Header.Get("Location"), string(b)
+}
+
+func waitListen(adr string) {
+	Eventually(func() error {
+		c, e := net.Dial("tcp", adr)
+		if e == nil {
+			_ = c.Close()
+		}
+		return e
+	}, 5*time.Second, 50*time.Millisecond).ShouldNot(HaveOccurred())
+}
+
+// newRedirectServer return a tls server listening on adr with a http redirect server on red.
+func newRedirectServer(adr, red, expose string) libhts.Server {
+	crt, _ := newCert("localhost")
+
+	cfg := libhts.Config{
+		Name:         "redirect",
+		Listen:       adr,
+		Expose:       expose,
+		HandlerKey:   "default",
+		TLS:          newTLS(crt),
+		RedirectHTTP: red,
+	}
+
+	cfg.RegisterHandlerFunc(func() map[string]http.Handler {
+		return map[string]http.Handler{
+			"default": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte("default " + r.URL.Path))
+			}),
+		}
+	})
+
+	srv, err := libhts.New(cfg, nil)
+	Expect(err).ToNot(HaveOccurred())
+
+	return srv
+}
+
+var _ = Describe("httpserver http redirect", func() {
+	Context("validating the redirect address", func() {
+		DescribeTable("must refuse a redirect address colliding with the listen address",
+			func(listen, redirect string, collide bool) {
+				cfg := libhts.Config{
+					Name:         "redirect",
+					Listen:       listen,
+					Expose:       "https://localhost",
+					HandlerKey:   "default",
+					RedirectHTTP: redirect,
+				}
+
+				if collide {
+					Expect(cfg.Validate()).To(HaveOccurred())
+				} else {
+					Expect(cfg.Validate()).ToNot(HaveOccurred())
+				}
+			},
+			Entry("same address", "127.0.0.1:8443", "127.0.0.1:8443", true),
+			Entry("other port", "127.0.0.1:8443", "127.0.0.1:8080", false),
+			Entry("other host on the same port", "10.0.0.1:8443", "10.0.0.2:8443", false),
+			Entry("empty host matching any host", ":8443", "10.0.0.2:8443", true),
+			Entry("ipv4 unspecified matching any host", "0.0.0.0:8443", "10.0.0.2:8443", true),
+			Entry("ipv6 unspecified matching any host", "10.0.0.1:8443", "[::]:8443", true),
+			Entry("localhost matching a loopback address", "localhost:8443", "127.0.0.1:8443", true),
+			Entry("ipv6 loopback matching an ipv4 loopback address", "[::1]:8443", "127.0.0.2:8443", true),
+			Entry("host case and trailing dot", "LocalHost.:8443", "localhost:8443", true),
+			Entry("loopback not matching another address", "localhost:8443", "10.0.0.1:8443", false),
+		)
+
+		It("must refuse a redirect address colliding with the legacy tls listen address", func() {
+			cfg := libhts.Config{
+				Name:         "redirect",
+				Listen:       "127.0.0.1:8443",
+				Expose:       "https://localhost",
+				HandlerKey:   "default",
+				RedirectHTTP: "0.0.0.0:9443",
+				TLSMigration: libhts.TLSMigration{
+					LegacyListen: "127.0.0.1:9443",
+				},
+			}
+
+			Expect(cfg.Validate()).To(HaveOccurred())
+		})
+	})
+
+	Context("redirecting the requests to the exposed url", func() {
+		DescribeTable("must redirect with a 301 to the exposed url",
+			func(expose, host, pth, location string) {
+				var (
+					adr = freeAddr()
+					red = freeAddr()
+					srv = newRedirectServer(adr, red, expose)
+				)
+
+				Expect(srv.Start(ctx)).ToNot(HaveOccurred())
+				defer func() {
+					_ = srv.Stop(ctx)
+				}()
+
+				waitListen(red)
+
+				sts, loc, _ := redirectGet(red, host, pth)
+				Expect(sts).To(Equal(http.StatusMovedPermanently))
+				Expect(loc).To(Equal(location))
+			},
+			Entry("a fixed exposed host", "https://secure.example.com", "www.example.com", "/a/b?x=1", "https://secure.example.com/a/b?x=1"),
+			Entry("a fixed exposed host with a port and a path", "https://secure.example.com:8443/base/", "", "/a", "https://secure.example.com:8443/base/a"),
+			Entry("an unspecified ipv4 host replaced by the requested host", "https://0.0.0.0", "www.example.com:8080", "/a", "https://www.example.com/a"),
+			Entry("an unspecified ipv4 host keeping the exposed port", "https://0.0.0.0:8443", "www.example.com:8080", "/a?x=1", "https://www.example.com:8443/a?x=1"),
+			Entry("an unspecified ipv6 host replaced by the requested host", "https://[::]:8443", "www.example.com", "/a", "https://www.example.com:8443/a"),
+			Entry("an unspecified host with an ipv6 requested host", "https://0.0.0.0:8443", "[::1]:8080", "/a", "https://[::1]:8443/a"),
+		)
+
+		It("must pass the acme challenges to the handler of the server", func() {
+			var (
+				adr = freeAddr()
+				red = freeAddr()
+				srv = newRedirectServer(adr, red, "https://secure.example.com")
+			)
+
+			Expect(srv.Start(ctx)).ToNot(HaveOccurred())
+			defer func() {
+				_ = srv.Stop(ctx)
+			}()
+
+			waitListen(red)
+
+			sts, loc, bdy := redirectGet(red, "", libhts.ACMEChallengePath+"token")
+			Expect(sts).To(Equal(http.StatusOK))
+			Expect(loc).To(BeEmpty())
+			Expect(bdy).To(Equal("default " + libhts.ACMEChallengePath + "token"))
+
+			// only the challenge path is passed
+			sts, loc, _ = redirectGet(red, "", "/.well-known/other")
+			Expect(sts).To(Equal(http.StatusMovedPermanently))
+			Expect(loc).To(Equal("https://secure.example.com/.well-known/other"))
+		})
+	})
+
+	Context("stopping the server", func() {
+		It("must stop and restart the redirect server with the main server", func() {
+			var (
+				adr = freeAddr()
+				red = freeAddr()
+				srv = newRedirectServer(adr, red, "https://secure.example.com")
+			)
+
+			Expect(srv.Start(ctx)).ToNot(HaveOccurred())
+			defer func() {
+				_ = srv.Stop(ctx)
+			}()
+
+			waitListen(red)
+
+			Expect(srv.Stop(ctx)).ToNot(HaveOccurred())
+			Expect(srv.IsRunning()).To(BeFalse())
+
+			_, err := net.DialTimeout("tcp", red, time.Second)
+			Expect(err).To(HaveOccurred())
+
+			Expect(srv.Start(ctx)).ToNot(HaveOccurred())
+			waitListen(red)
+
+			sts, loc, _ := redirectGet(red, "", "/a")
+			Expect(sts).To(Equal(http.StatusMovedPermanently))
+			Expect(loc).To(Equal("https://secure.example.com/a"))
+		})
+
+		It("must fail the start and release the listen address if the redirect address is in use", func() {
+			var (
+				adr = freeAddr()
+				red = freeAddr()
+				srv = newRedirectServer(adr, red, "https://secure.example.com")
+			)
+
+			lis, err := net.Listen("tcp", red)
+			Expect(err).ToNot(HaveOccurred())
+			defer func() {
+				_ = lis.Close()
+			}()
+
+			Expect(srv.Start(ctx)).To(HaveOccurred())
+			defer func() {
+				_ = srv.Stop(ctx)
+			}()
+
+			Expect(srv.IsRunning()).To(BeFalse())
+
+			// the main listener is closed, the address can be opened again
+			Eventually(func() error {
+				l, e := net.Listen("tcp", adr)
+				if e == nil {
+					_ = l.Close()
+				}
+				return e
+			}, 2*time.Second, 50*time.Millisecond).ShouldNot(HaveOccurred())
+		})
+	})
+})
@@ -28,6 +28,7 @@ package httpserver
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
@@ -35,6 +36,7 @@ import (
 
 	srvtps "github.com/nabbar/golib/httpserver/types"
 	loglvl "github.com/nabbar/golib/logger/level"
+	libptc "github.com/nabbar/golib/network/protocol"
 	librun "github.com/nabbar/golib/server/runner/startStop"
 )
 
@@ -74,39 +76,62 @@ func (o *srv) delRun(ctx context.Context) error {
 	return nil
 }
 
-func (o *srv) runStart(ctx context.Context) error {
+func (o *srv) getRun() librun.StartStop {
 	if o == nil {
-		return ErrorServerValidate.Error(nil)
+		return nil
 	}
 
 	o.m.RLock()
 	defer o.m.RUnlock()
 
-	if o.r == nil {
+	return o.r
+}
+
+func (o *srv) runStart(ctx context.Context) error {
+	if o == nil {
 		return ErrorServerValidate.Error(nil)
 	}
 
-	if e := o.r.Start(ctx); e != nil {
-		return e
+	var r = o.getRun()
+
+	if r == nil {
+		return ErrorServerValidate.Error(nil)
 	}
 
 	var x, n = context.WithTimeout(ctx, 30*time.Second)
-
 	defer n()
 
-	for !o.r.IsRunning() {
+	// subscribe before starting to not miss any transition
+	var sub = o.Subscribe(x)
+
+	if e := r.Start(ctx); e != nil {
+		return e
+	}
+
+	for {
 		select {
 		case <-x.Done():
 			return errNotRunning
-		default:
-			time.Sleep(100 * time.Millisecond)
-			if o.r.IsRunning() {
+		case t, ok := <-sub:
+			if !ok {
+				return errNotRunning
+			}
+
+			switch t.Current {
+			case StateRunning:
 				return o.GetError()
+			case StateFailed:
+				if t.Error != nil {
+					return ErrorServerStart.Error(t.Error)
+				} else if e := o.GetError(); e != nil {
+					return e
+				}
+				return errNotRunning
+			case StateStopped:
+				return errNotRunning
 			}
 		}
 	}
-
-	return o.GetError()
 }
 
 func (o *srv) runStop(ctx context.Context) error {
@@ -114,14 +139,13 @@ func (o *srv) runStop(ctx context.Context) error {
 		return ErrorServerValidate.Error(nil)
 	}
 
-	o.m.RLock()
-	defer o.m.RUnlock()
+	var r = o.getRun()
 
-	if o.r == nil {
+	if r == nil {
 		return ErrorServerValidate.Error(nil)
 	}
 
-	return o.r.Stop(ctx)
+	return r.Stop(ctx)
 }
 
 func (o *srv) runRestart(ctx context.Context) error {
@@ -129,14 +153,13 @@ func (o *srv) runRestart(ctx context.Context) error {
 		return ErrorServerValidate.Error(nil)
 	}
 
-	o.m.RLock()
-	defer o.m.RUnlock()
+	var r = o.getRun()
 
-	if o.r == nil {
+	if r == nil {
 		return ErrorServerValidate.Error(nil)
 	}
 
-	return o.r.Restart(ctx)
+	return r.Restart(ctx)
 }
 
 func (o *srv) runIsRunning() bool {
@@ -144,23 +167,25 @@ func (o *srv) runIsRunning() bool {
 		return false
 	}
 
-	o.m.RLock()
-	defer o.m.RUnlock()
-
-	if o.r == nil {
-		return false
-	}
-
-	return o.r.IsRunning()
+	var s = o.State()
+	return s == StateRunning || s == StateDraining
 }
 
 func (o *srv) runFuncStart(ctx context.Context) (err error) {
 	var (
 		tls = false
 		ser *http.Server
+		lis net.Listener
 	)
 
+	o.setState(StateStarting, false, nil)
+
 	defer func() {
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			_ = o.stopRedirect(context.Background())
+			o.setState(StateFailed, tls, err)
+		}
+
 		if tls {
 			ent := o.logger().Entry(loglvl.InfoLevel, "TLS HTTP Server stopped")
 			ent.ErrorAdd(true, err)
@@ -187,36 +212,82 @@ func (o *srv) runFuncStart(ctx context.Context) (err error) {
 		}
 	}
 
-	if ser.TLSConfig != nil && len(ser.TLSConfig.Certificates) > 0 {
+	if isTLSServer(ser) {
 		tls = true
 	}
 
+	var drn = o.Draining()
+
 	ser.BaseContext = func(listener net.Listener) context.Context {
-		return ctx
+		return context.WithValue(ctx, ctxKeyDrain, drn)
+	}
+
+	o.logStartupBanner(ser)
+	o.runPreflight()
+
+	if lis, err = net.Listen(libptc.NetworkTCP.Code(), ser.Addr); err != nil {
+		ent := o.logger().Entry(loglvl.ErrorLevel, "opening http server listener")
+		ent.ErrorAdd(true, err)
+		ent.Log()
+		return err
+	}
+
+	if err = o.runRedirect(ctx); err != nil {
+		_ = lis.Close()
+		ent := o.logger().Entry(loglvl.ErrorLevel, "opening http redirect server listener")
+		ent.ErrorAdd(true, err)
+		ent.Log()
+		return err
 	}
 
+	o.setState(StateRunning, tls, nil)
+
 	if tls {
 		o.logger().Entry(loglvl.InfoLevel, "TLS HTTP Server is starting").Log()
-		err = ser.ListenAndServeTLS("", "")
+		o.runTLSMigration(ctx)
+		err = ser.ServeTLS(lis, "", "")
 	} else {
 		o.logger().Entry(loglvl.InfoLevel, "HTTP Server is starting").Log()
-		err = ser.ListenAndServe()
+		err = ser.Serve(lis)
 	}
 
 	return err
 }
 
 func (o *srv) runFuncStop(ctx context.Context) (err error) {
-	var x, n = context.WithTimeout(ctx, srvtps.TimeoutWaitingStop)
-	defer n()
-
 	var (
 		tls = false
 		ser *http.Server
 	)
 
+	if ser = o.getServer(); ser == nil {
+		err = ErrorServerStart.Error(fmt.Errorf("cannot retrieve server"))
+		ent := o.logger().Entry(loglvl.ErrorLevel, "starting http server")
+		ent.ErrorAdd(true, err)
+		ent.Log()
+		return err
+	} else if isTLSServer(ser) {
+		tls = true
+	}
+
+	var fld = o.State() == StateFailed
+
+	// a shutdown is already in progress by another call
+	if !fld && !o.setState(StateDraining, tls, nil) {
+		return nil
+	}
+
 	defer func() {
 		o.delServer()
+
+		if !fld {
+			if err != nil {
+				o.setState(StateFailed, tls, err)
+			} else {
+				o.setState(StateStopped, tls, nil)
+			}
+		}
+
 		if tls {
 			ent := o.logger().Entry(loglvl.InfoLevel, "Shutdown of TLS HTTP Server has been called")
 			ent.ErrorAdd(true, err)
@@ -228,14 +299,15 @@ func (o *srv) runFuncStop(ctx context.Context) (err error) {
 		}
 	}()
 
-	if ser = o.getServer(); ser == nil {
-		err = ErrorServerStart.Error(fmt.Errorf("cannot retrieve server"))
-		ent := o.logger().Entry(loglvl.ErrorLevel, "starting http server")
-		ent.ErrorAdd(true, err)
-		ent.Log()
-		return err
-	} else if ser.TLSConfig != nil && len(ser.TLSConfig.Certificates) > 0 {
-		tls = true
+	if !fld {
+		o.drainDelay(ctx)
+	}
+
+	var x, n = context.WithTimeout(ctx, srvtps.TimeoutWaitingStop)
+	defer n()
+
+	if !fld {
+		o.setState(StateStopping, tls, nil)
 	}
 
 	if tls {
@@ -246,6 +318,14 @@ func (o *srv) runFuncStop(ctx context.Context) (err error) {
 
 	err = ser.Shutdown(x)
 
+	if e := o.stopTLSMigration(x); e != nil && err == nil {
+		err = e
+	}
+
+	if e := o.stopRedirect(x); e != nil && err == nil {
+		err = e
+	}
+
 	return err
 }
 
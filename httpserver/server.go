@@ -28,6 +28,7 @@ package httpserver
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"log"
@@ -93,10 +94,44 @@ func (o *srv) setServer(ctx context.Context) error {
 
 	var stdlog = o.logger()
 
+	var (
+		vhs = o.vhostList(o.GetConfig())
+		def = o.vhostHandler(o.HandlerLoadFct(), vhs)
+	)
+
+	hdl, err := o.authHandler(def)
+
+	if err != nil {
+		ent := o.logger().Entry(loglvl.ErrorLevel, "init http server authentication")
+		ent.ErrorAdd(true, err)
+		ent.Log()
+		return err
+	}
+
+	hdl, err = o.headersHandler(hdl)
+
+	if err != nil {
+		ent := o.logger().Entry(loglvl.ErrorLevel, "init http server headers")
+		ent.ErrorAdd(true, err)
+		ent.Log()
+		return err
+	}
+
+	hdl, err = o.guardHandler(hdl)
+
+	if err != nil {
+		ent := o.logger().Entry(loglvl.ErrorLevel, "init http server guard")
+		ent.ErrorAdd(true, err)
+		ent.Log()
+		return err
+	}
+
+	hdl = o.metricsHandler(hdl)
+
 	// #nosec
 	s := &http.Server{
 		Addr:    bind,
-		Handler: o.HandlerLoadFct(),
+		Handler: hdl,
 	}
 
 	if ssl != nil && ssl.LenCertificatePair() > 0 {
@@ -113,10 +148,31 @@ func (o *srv) setServer(ctx context.Context) error {
 
 	s.ErrorLog = stdlog.GetStdLogger(loglvl.ErrorLevel, log.LstdFlags|log.Lmicroseconds)
 
+	if e := o.setTLSMigration(s); e != nil {
+		ent := o.logger().Entry(loglvl.ErrorLevel, "init tls migration legacy server")
+		ent.ErrorAdd(true, e)
+		ent.Log()
+		return e
+	}
+
+	if o.cfgGetCerts().hasCerts() {
+		if s.TLSConfig == nil {
+			s.TLSConfig = &tls.Config{} // #nosec
+		}
+
+		o.vhostTLS(s.TLSConfig)
+
+		if l := o.t.getLegacy(); l != nil {
+			o.vhostTLS(l.TLSConfig)
+		}
+	}
+
 	if e := o.RunIfPortInUse(ctx, o.GetBindable(), 5, fctStop); e != nil {
 		return e
 	}
 
+	o.setRedirect(o.newRedirect(s, def))
+
 	o.m.Lock()
 	o.s = s
 	o.m.Unlock()
@@ -146,14 +202,12 @@ func (o *srv) Stop(ctx context.Context) error {
 		return errInvalid
 	}
 
-	o.m.RLock()
-	defer o.m.RUnlock()
-
-	if o.r == nil {
+	// the runner must not be called under lock as the stop function release the server
+	if r := o.getRun(); r == nil {
 		return nil
+	} else {
+		return r.Stop(ctx)
 	}
-
-	return o.r.Stop(ctx)
 }
 
 func (o *srv) Restart(ctx context.Context) error {
@@ -161,19 +215,10 @@ func (o *srv) Restart(ctx context.Context) error {
 	return o.Start(ctx)
 }
 
+// IsRunning return true if the server is accepting or draining requests.
+// Use State to get the exact lifecycle state.
 func (o *srv) IsRunning() bool {
-	if o == nil {
-		return false
-	}
-
-	o.m.RLock()
-	defer o.m.RUnlock()
-
-	if o.r == nil {
-		return false
-	}
-
-	return o.r.IsRunning()
+	return o.runIsRunning()
 }
 
 func (o *srv) PortInUse(ctx context.Context, listen string) liberr.Error {
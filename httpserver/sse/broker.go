@@ -0,0 +1,366 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package sse
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	libevt "github.com/nabbar/golib/events"
+	libhtp "github.com/nabbar/golib/httpserver"
+)
+
+type brk struct {
+	m sync.RWMutex
+	x context.Context    // broker context
+	n context.CancelFunc // broker context cancel
+	g Config
+	i *atomic.Uint64 // last client id
+	s uint64         // last event id
+	c *atomic.Bool   // is closed
+	l map[uint64]*client
+	r *replay
+	w sync.WaitGroup
+
+	fc FuncClient // on connect
+	fx FuncClient // on close
+}
+
+func (o *brk) OnConnect(fct FuncClient) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	o.fc = fct
+}
+
+func (o *brk) OnClose(fct FuncClient) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	o.fx = fct
+}
+
+func (o *brk) onConnect(c Client) {
+	o.m.RLock()
+	f := o.fc
+	o.m.RUnlock()
+
+	if f != nil {
+		f(c)
+	}
+}
+
+func (o *brk) onClose(c Client) {
+	o.m.RLock()
+	f := o.fx
+	o.m.RUnlock()
+
+	if f != nil {
+		f(c)
+	}
+}
+
+func (o *brk) Len() int {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	return len(o.l)
+}
+
+func (o *brk) LastID() uint64 {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	return o.s
+}
+
+func (o *brk) IsClosed() bool {
+	return o.c.Load()
+}
+
+func (o *brk) Publish(evt Event) (uint64, error) {
+	if o == nil {
+		return 0, ErrInvalidInstance
+	} else if e := evt.validate(); e != nil {
+		return 0, e
+	}
+
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if o.c.Load() {
+		return 0, ErrBrokerClosed
+	}
+
+	o.s++
+	evt.ID = o.s
+
+	if len(evt.Data) > 0 {
+		var b = make([]byte, len(evt.Data))
+		copy(b, evt.Data)
+		evt.Data = b
+	}
+
+	o.r.add(evt)
+
+	// sending under lock keep the same order for all clients and no gap with the replay of new clients
+	for _, c := range o.l {
+		if c.match(evt.Topic) {
+			c.push(evt)
+		}
+	}
+
+	return evt.ID, nil
+}
+
+func (o *brk) PublishData(topic string, data []byte) (uint64, error) {
+	return o.Publish(Event{
+		Topic: topic,
+		Data:  data,
+	})
+}
+
+func (o *brk) Shutdown(ctx context.Context) error {
+	if o == nil {
+		return ErrInvalidInstance
+	}
+
+	o.m.Lock()
+	o.c.Store(true)
+	o.n()
+	o.m.Unlock()
+
+	var d = make(chan struct{})
+
+	go func() {
+		o.w.Wait()
+		close(d)
+	}()
+
+	select {
+	case <-d:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (o *brk) Attach(ctx context.Context, bus libevt.Bus, bind string) (libevt.Subscription, error) {
+	if o == nil {
+		return nil, ErrInvalidInstance
+	}
+
+	return bus.Subscribe(ctx, libhtp.EventTopicState, 1, func(_ context.Context, _ string, evt any) {
+		if e, k := evt.(libhtp.EventState); !k || e.State != libhtp.StateDraining {
+			return
+		} else if len(bind) > 0 && e.Bind != bind {
+			return
+		}
+
+		x, n := context.WithTimeout(context.Background(), o.g.getCloseTimeout())
+		defer n()
+
+		_ = o.Shutdown(x)
+	})
+}
+
+func (o *brk) Handler(topics ...string) http.Handler {
+	var t = make([]string, 0, len(topics))
+
+	for _, i := range topics {
+		if i = strings.TrimSpace(i); len(i) > 0 {
+			t = append(t, i)
+		}
+	}
+
+	if len(t) < 1 {
+		return o
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		o.serve(w, r, t)
+	})
+}
+
+func (o *brk) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var t = make([]string, 0)
+
+	for _, v := range r.URL.Query()[QueryTopic] {
+		for _, i := range strings.Split(v, ",") {
+			if i = strings.TrimSpace(i); len(i) > 0 {
+				t = append(t, i)
+			}
+		}
+	}
+
+	o.serve(w, r, t)
+}
+
+func (o *brk) register(r *http.Request, topics []string, last uint64) (*client, []Event) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if o.c.Load() {
+		return nil, nil
+	}
+
+	x, n := context.WithCancel(o.x)
+
+	c := &client{
+		i: o.i.Add(1),
+		q: r,
+		t: topics,
+		x: x,
+		n: n,
+		e: make(chan Event, o.g.getSendBuffer()),
+	}
+
+	var rpl []Event
+
+	if last > 0 {
+		rpl = o.r.since(last, c.match)
+	}
+
+	o.l[c.i] = c
+	o.w.Add(1)
+
+	return c, rpl
+}
+
+func (o *brk) unregister(c *client) {
+	o.m.Lock()
+	delete(o.l, c.i)
+	o.m.Unlock()
+
+	c.n()
+}
+
+func lastEventID(r *http.Request) uint64 {
+	var s = r.Header.Get(HeaderLastEventID)
+
+	if len(s) < 1 {
+		s = r.URL.Query().Get(QueryLastEventID)
+	}
+
+	if i, e := strconv.ParseUint(strings.TrimSpace(s), 10, 64); e == nil {
+		return i
+	}
+
+	return 0
+}
+
+func (o *brk) serve(w http.ResponseWriter, r *http.Request, topics []string) {
+	if o.IsClosed() {
+		http.Error(w, ErrBrokerClosed.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	f, ok := w.(http.Flusher)
+
+	if !ok {
+		http.Error(w, ErrStreamUnsupported.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	c, rpl := o.register(r, topics, lastEventID(r))
+
+	if c == nil {
+		http.Error(w, ErrBrokerClosed.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	// the close callback is done before releasing the wait group, so Shutdown return after all callbacks
+	defer func() {
+		o.unregister(c)
+		o.onClose(c)
+		o.w.Done()
+	}()
+
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	h.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if o.g.Retry > 0 {
+		if _, e := w.Write([]byte("retry: " + strconv.FormatInt(o.g.Retry.Time().Milliseconds(), 10) + "\n\n")); e != nil {
+			return
+		}
+	}
+
+	for _, evt := range rpl {
+		if !writeEvent(w, evt) {
+			return
+		}
+	}
+
+	f.Flush()
+	o.onConnect(c)
+
+	var (
+		t = time.NewTicker(o.g.getHeartbeat())
+		d = libhtp.DrainFromContext(r.Context())
+	)
+
+	defer t.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+
+		case <-d:
+			// the server is draining, end the stream to let the client reconnect elsewhere
+			return
+
+		case <-c.x.Done():
+			return
+
+		case <-t.C:
+			if _, e := w.Write([]byte(": heartbeat\n\n")); e != nil {
+				return
+			}
+			f.Flush()
+
+		case evt := <-c.e:
+			if !writeEvent(w, evt) {
+				return
+			}
+			f.Flush()
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, evt Event) bool {
+	p, _ := evt.MarshalText()
+	_, e := w.Write(p)
+	return e == nil
+}
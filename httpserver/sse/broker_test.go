@@ -0,0 +1,335 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package sse_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	libdur "github.com/nabbar/golib/duration"
+	libevt "github.com/nabbar/golib/events"
+	libhtp "github.com/nabbar/golib/httpserver"
+	libsse "github.com/nabbar/golib/httpserver/sse"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// stream is a minimal text/event-stream client, sending each block of lines ended by a blank line.
+type stream struct {
+	r *http.Response
+	n context.CancelFunc
+	b chan []string
+}
+
+func open(url string, hdr map[string]string) *stream {
+	x, n := context.WithCancel(ctx)
+
+	req, err := http.NewRequestWithContext(x, http.MethodGet, url, nil)
+	Expect(err).ToNot(HaveOccurred())
+
+	for k, v := range hdr {
+		req.Header.Set(k, v)
+	}
+
+	rsp, err := http.DefaultClient.Do(req)
+	Expect(err).ToNot(HaveOccurred())
+
+	s := &stream{
+		r: rsp,
+		n: n,
+		b: make(chan []string, 64),
+	}
+
+	go func() {
+		defer close(s.b)
+
+		var (
+			scn = bufio.NewScanner(rsp.Body)
+			blk = make([]string, 0)
+		)
+
+		for scn.Scan() {
+			if l := scn.Text(); len(l) > 0 {
+				blk = append(blk, l)
+			} else if len(blk) > 0 {
+				s.b <- blk
+				blk = make([]string, 0)
+			}
+		}
+	}()
+
+	return s
+}
+
+func (s *stream) close() {
+	s.n()
+	_ = s.r.Body.Close()
+}
+
+// next return the next block, skipping the heartbeat comments.
+func (s *stream) next() []string {
+	var t = time.After(2 * time.Second)
+
+	for {
+		select {
+		case b, ok := <-s.b:
+			if !ok {
+				return nil
+			} else if strings.HasPrefix(b[0], ":") {
+				continue
+			}
+			return b
+		case <-t:
+			return nil
+		}
+	}
+}
+
+var _ = Describe("SSE Broker", func() {
+	var (
+		brk libsse.Broker
+		htp *httptest.Server
+		onc *atomic.Int32
+		onx *atomic.Int32
+	)
+
+	newBroker := func(cfg libsse.Config) {
+		var err error
+
+		brk, err = libsse.New(func() context.Context { return ctx }, cfg)
+		Expect(err).ToNot(HaveOccurred())
+
+		onc = new(atomic.Int32)
+		onx = new(atomic.Int32)
+
+		brk.OnConnect(func(c libsse.Client) {
+			onc.Add(1)
+		})
+		brk.OnClose(func(c libsse.Client) {
+			onx.Add(1)
+		})
+
+		var mux = http.NewServeMux()
+		mux.Handle("/all", brk)
+		mux.Handle("/news", brk.Handler("news"))
+
+		htp = httptest.NewServer(mux)
+	}
+
+	AfterEach(func() {
+		x, n := context.WithTimeout(ctx, 5*time.Second)
+		defer n()
+
+		_ = brk.Shutdown(x)
+		htp.Close()
+	})
+
+	Context("streaming events", func() {
+		BeforeEach(func() {
+			newBroker(libsse.Config{
+				Retry: libdur.ParseDuration(3 * time.Second),
+			})
+		})
+
+		It("must send the retry delay and the events of the subscribed topics", func() {
+			s := open(htp.URL+"/news", nil)
+			defer s.close()
+
+			Expect(s.r.StatusCode).To(Equal(http.StatusOK))
+			Expect(s.r.Header.Get("Content-Type")).To(Equal("text/event-stream"))
+			Expect(s.next()).To(Equal([]string{"retry: 3000"}))
+
+			Eventually(onc.Load).Should(Equal(int32(1)))
+			Expect(brk.Len()).To(Equal(1))
+
+			_, err := brk.PublishData("sport", []byte("skipped"))
+			Expect(err).ToNot(HaveOccurred())
+
+			id, err := brk.Publish(libsse.Event{Topic: "news", Name: "flash", Data: []byte("line 1\nline 2")})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(id).To(Equal(uint64(2)))
+
+			Expect(s.next()).To(Equal([]string{"id: 2", "event: flash", "data: line 1", "data: line 2"}))
+		})
+
+		It("must select the topics from the query parameter", func() {
+			s := open(htp.URL+"/all?"+libsse.QueryTopic+"=a,b", nil)
+			defer s.close()
+
+			Expect(s.next()).To(Equal([]string{"retry: 3000"}))
+			Eventually(brk.Len).Should(Equal(1))
+
+			for _, t := range []string{"a", "c", "b"} {
+				_, err := brk.PublishData(t, []byte(t))
+				Expect(err).ToNot(HaveOccurred())
+			}
+
+			Expect(s.next()).To(Equal([]string{"id: 1", "data: a"}))
+			Expect(s.next()).To(Equal([]string{"id: 3", "data: b"}))
+		})
+
+		It("must replay the events published after the last event id", func() {
+			for _, t := range []string{"news", "sport", "news", "news"} {
+				_, err := brk.PublishData(t, []byte(t))
+				Expect(err).ToNot(HaveOccurred())
+			}
+
+			s := open(htp.URL+"/news", map[string]string{libsse.HeaderLastEventID: "1"})
+			defer s.close()
+
+			Expect(s.next()).To(Equal([]string{"retry: 3000"}))
+			Expect(s.next()).To(Equal([]string{"id: 3", "data: news"}))
+			Expect(s.next()).To(Equal([]string{"id: 4", "data: news"}))
+
+			// no gap between the replay and the live events
+			_, err := brk.PublishData("news", []byte("live"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(s.next()).To(Equal([]string{"id: 5", "data: live"}))
+		})
+	})
+
+	Context("keeping the stream alive", func() {
+		BeforeEach(func() {
+			newBroker(libsse.Config{
+				Heartbeat: libdur.ParseDuration(100 * time.Millisecond),
+			})
+		})
+
+		It("must send heartbeat comments", func() {
+			s := open(htp.URL+"/all", nil)
+			defer s.close()
+
+			var b []string
+			Eventually(s.b, time.Second).Should(Receive(&b))
+			Expect(b).To(Equal([]string{": heartbeat"}))
+		})
+	})
+
+	Context("when the client disconnect", func() {
+		BeforeEach(func() {
+			newBroker(libsse.Config{})
+		})
+
+		It("must end the stream and call the close callback", func() {
+			var cnx = make(chan libsse.Client, 1)
+
+			brk.OnConnect(func(c libsse.Client) {
+				cnx <- c
+			})
+
+			s := open(htp.URL+"/news", nil)
+
+			var c libsse.Client
+			Eventually(cnx).Should(Receive(&c))
+			Expect(c.Topics()).To(Equal([]string{"news"}))
+			Expect(brk.Len()).To(Equal(1))
+
+			s.close()
+
+			Eventually(c.Context().Done(), 2*time.Second).Should(BeClosed())
+			Eventually(brk.Len).Should(Equal(0))
+			Eventually(onx.Load).Should(Equal(int32(1)))
+
+			// publishing without any client must still feed the replay buffer
+			_, err := brk.PublishData("news", []byte("after"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(brk.LastID()).To(Equal(uint64(1)))
+		})
+	})
+
+	Context("stopping the broker", func() {
+		BeforeEach(func() {
+			newBroker(libsse.Config{})
+		})
+
+		It("must end the streams on shutdown and refuse new ones", func() {
+			// a slow close callback must be ended before Shutdown return
+			brk.OnClose(func(c libsse.Client) {
+				time.Sleep(200 * time.Millisecond)
+				onx.Add(1)
+			})
+
+			s := open(htp.URL+"/all", nil)
+			defer s.close()
+
+			Eventually(onc.Load).Should(Equal(int32(1)))
+
+			x, n := context.WithTimeout(ctx, 5*time.Second)
+			defer n()
+
+			var t = time.Now()
+			Expect(brk.Shutdown(x)).To(Succeed())
+			Expect(time.Since(t)).To(BeNumerically("<", 2*time.Second))
+
+			Expect(brk.IsClosed()).To(BeTrue())
+			Expect(brk.Len()).To(Equal(0))
+			Expect(onx.Load()).To(Equal(int32(1)))
+
+			// the stream is ended by the server
+			Eventually(s.b, 2*time.Second).Should(BeClosed())
+
+			_, err := brk.PublishData("news", []byte("closed"))
+			Expect(err).To(MatchError(libsse.ErrBrokerClosed))
+
+			r, e := http.Get(htp.URL + "/all")
+			Expect(e).ToNot(HaveOccurred())
+			Expect(r.StatusCode).To(Equal(http.StatusServiceUnavailable))
+			_ = r.Body.Close()
+		})
+
+		It("must shutdown when the attached http server start draining", func() {
+			var bus = libevt.New(ctx)
+			defer func() {
+				_ = bus.Close()
+			}()
+
+			sub, err := brk.Attach(ctx, bus, "127.0.0.1:8080")
+			Expect(err).ToNot(HaveOccurred())
+			defer sub.Unsubscribe()
+
+			s := open(htp.URL+"/all", nil)
+			defer s.close()
+
+			Eventually(brk.Len).Should(Equal(1))
+
+			// another bind or another state must be ignored
+			Expect(bus.Publish(ctx, libhtp.EventTopicState, libhtp.EventState{Bind: "127.0.0.1:9090", State: libhtp.StateDraining})).To(Succeed())
+			Expect(bus.Publish(ctx, libhtp.EventTopicState, libhtp.EventState{Bind: "127.0.0.1:8080", State: libhtp.StateRunning})).To(Succeed())
+			Consistently(brk.IsClosed, 300*time.Millisecond).Should(BeFalse())
+			Expect(brk.Len()).To(Equal(1))
+
+			Expect(bus.Publish(ctx, libhtp.EventTopicState, libhtp.EventState{Bind: "127.0.0.1:8080", State: libhtp.StateDraining})).To(Succeed())
+			Eventually(brk.IsClosed).Should(BeTrue())
+			Eventually(brk.Len).Should(Equal(0))
+			Eventually(s.b, 2*time.Second).Should(BeClosed())
+		})
+	})
+})
@@ -0,0 +1,82 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package sse
+
+import (
+	"context"
+	"net/http"
+)
+
+type client struct {
+	i uint64             // id
+	q *http.Request      // request
+	t []string           // topics
+	x context.Context    // client context
+	n context.CancelFunc // client context cancel
+	e chan Event         // events to send
+}
+
+func (c *client) ID() uint64 {
+	return c.i
+}
+
+func (c *client) Request() *http.Request {
+	return c.q
+}
+
+func (c *client) Topics() []string {
+	var res = make([]string, len(c.t))
+	copy(res, c.t)
+	return res
+}
+
+func (c *client) Context() context.Context {
+	return c.x
+}
+
+func (c *client) match(topic string) bool {
+	if len(c.t) < 1 {
+		return true
+	}
+
+	for _, t := range c.t {
+		if t == topic {
+			return true
+		}
+	}
+
+	return false
+}
+
+// push queue the event without blocking. A client unable to follow is ended, to reconnect with its last event id.
+func (c *client) push(evt Event) {
+	select {
+	case c.e <- evt:
+	default:
+		c.n()
+	}
+}
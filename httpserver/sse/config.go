@@ -0,0 +1,122 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package sse
+
+import (
+	"fmt"
+	"time"
+
+	libval "github.com/go-playground/validator/v10"
+	libdur "github.com/nabbar/golib/duration"
+)
+
+const (
+	DefaultReplay       = 256
+	DefaultSendBuffer   = 64
+	DefaultHeartbeat    = 15 * time.Second
+	DefaultCloseTimeout = 5 * time.Second
+
+	// QueryTopic is the query parameter used by clients to select topics (repeated or comma separated).
+	QueryTopic = "topic"
+
+	// QueryLastEventID is the query parameter used as fallback of the Last-Event-ID header,
+	// for clients not able to set headers on reconnection.
+	QueryLastEventID = "lastEventId"
+
+	// HeaderLastEventID is the header sent by browsers on reconnection.
+	HeaderLastEventID = "Last-Event-ID"
+)
+
+type Config struct {
+	// Replay is the number of last events kept to be sent again to reconnecting clients
+	// giving a Last-Event-ID. A negative value disable the replay. Default is 256.
+	Replay int `mapstructure:"replay" json:"replay" yaml:"replay" toml:"replay"`
+
+	// SendBuffer is the number of events waiting to be written for each client.
+	// A client with a full buffer is disconnected to reconnect and catch up with the replay. Default is 64.
+	SendBuffer int `mapstructure:"send_buffer" json:"send_buffer" yaml:"send_buffer" toml:"send_buffer" validate:"gte=0"`
+
+	// Heartbeat is the delay between two comment lines sent to keep the stream alive through proxies.
+	// Default is 15 seconds.
+	Heartbeat libdur.Duration `mapstructure:"heartbeat" json:"heartbeat" yaml:"heartbeat" toml:"heartbeat"`
+
+	// Retry is the reconnection delay sent to clients at the beginning of the stream. Not sent if zero.
+	Retry libdur.Duration `mapstructure:"retry" json:"retry" yaml:"retry" toml:"retry"`
+
+	// CloseTimeout is the max duration given to the streams to end when the broker is shut down
+	// by an httpserver stopping event. Default is 5 seconds.
+	CloseTimeout libdur.Duration `mapstructure:"close_timeout" json:"close_timeout" yaml:"close_timeout" toml:"close_timeout"`
+}
+
+func (c Config) Validate() error {
+	if er := libval.New().Struct(c); er != nil {
+		if e, ok := er.(*libval.InvalidValidationError); ok {
+			return e
+		}
+
+		for _, e := range er.(libval.ValidationErrors) {
+			//nolint goerr113
+			return fmt.Errorf("config field '%s' is not validated by constraint '%s'", e.Namespace(), e.ActualTag())
+		}
+	}
+
+	return nil
+}
+
+func (c Config) getReplay() int {
+	if c.Replay < 0 {
+		return 0
+	} else if c.Replay > 0 {
+		return c.Replay
+	}
+
+	return DefaultReplay
+}
+
+func (c Config) getSendBuffer() int {
+	if c.SendBuffer > 0 {
+		return c.SendBuffer
+	}
+
+	return DefaultSendBuffer
+}
+
+func (c Config) getHeartbeat() time.Duration {
+	if c.Heartbeat > 0 {
+		return c.Heartbeat.Time()
+	}
+
+	return DefaultHeartbeat
+}
+
+func (c Config) getCloseTimeout() time.Duration {
+	if c.CloseTimeout > 0 {
+		return c.CloseTimeout.Time()
+	}
+
+	return DefaultCloseTimeout
+}
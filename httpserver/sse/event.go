@@ -0,0 +1,82 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package sse
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// Event is one message of the stream.
+type Event struct {
+	// ID is assigned by the broker on publish, as an increasing sequence used for the replay.
+	ID uint64
+
+	// Topic is the topic the event is published on. Clients receive only the events of their topics.
+	Topic string
+
+	// Name is the event type sent as the "event" field. If empty, the client receive a "message" event.
+	Name string
+
+	// Data is the payload of the event. Each line is sent as a "data" field.
+	Data []byte
+}
+
+func (e Event) validate() error {
+	if len(strings.TrimSpace(e.Topic)) < 1 {
+		return ErrInvalidEventTopic
+	} else if strings.ContainsAny(e.Name, "\r\n") {
+		return ErrInvalidEventName
+	}
+
+	return nil
+}
+
+// MarshalText return the event encoded into the text/event-stream format, including the blank line ending the event.
+func (e Event) MarshalText() ([]byte, error) {
+	var buf = bytes.NewBuffer(make([]byte, 0, len(e.Data)+32))
+
+	if e.ID > 0 {
+		buf.WriteString("id: " + strconv.FormatUint(e.ID, 10) + "\n")
+	}
+
+	if len(e.Name) > 0 {
+		buf.WriteString("event: " + e.Name + "\n")
+	}
+
+	var d = strings.ReplaceAll(string(e.Data), "\r\n", "\n")
+	d = strings.ReplaceAll(d, "\r", "\n")
+
+	for _, l := range strings.Split(d, "\n") {
+		buf.WriteString("data: " + l + "\n")
+	}
+
+	buf.WriteString("\n")
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,125 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package sse
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	libctx "github.com/nabbar/golib/context"
+	libevt "github.com/nabbar/golib/events"
+)
+
+// Client is the view of a connected stream given to the registered functions.
+type Client interface {
+	// ID return the unique id of the client into its broker.
+	ID() uint64
+
+	// Request return the http request of the stream.
+	Request() *http.Request
+
+	// Topics return the topics the client is subscribed to. Empty means all topics.
+	Topics() []string
+
+	// Context return the context of the client, canceled when the stream ends.
+	Context() context.Context
+}
+
+// FuncClient is called on client events (connect, close).
+type FuncClient func(c Client)
+
+type Broker interface {
+	// Handler return an http.Handler streaming the events of the given topics.
+	// If no topic is given, the topics are read from the query parameter QueryTopic, and all topics are streamed if none.
+	Handler(topics ...string) http.Handler
+
+	// ServeHTTP stream the events of the topics given by the query parameter QueryTopic.
+	ServeHTTP(w http.ResponseWriter, r *http.Request)
+
+	// Publish assign an id to the event, keep it into the replay buffer and send it to all clients subscribed to its topic.
+	Publish(evt Event) (uint64, error)
+
+	// PublishData is a helper to publish the data as an unnamed event on the topic.
+	PublishData(topic string, data []byte) (uint64, error)
+
+	// LastID return the id of the last published event.
+	LastID() uint64
+
+	// OnConnect register a function called when a stream starts.
+	OnConnect(fct FuncClient)
+
+	// OnClose register a function called when a stream ends.
+	OnClose(fct FuncClient)
+
+	// Len return the number of open streams.
+	Len() int
+
+	// Attach subscribe to the state events of the httpserver published on the bus,
+	// to shutdown the broker when the http server is stopping.
+	// If bind is not empty, only the events of the http server listening on this address are used.
+	Attach(ctx context.Context, bus libevt.Bus, bind string) (libevt.Subscription, error)
+
+	// Shutdown refuse any new stream, end all open streams and wait for them until the context is done.
+	Shutdown(ctx context.Context) error
+
+	// IsClosed return true if Shutdown has been called.
+	IsClosed() bool
+}
+
+// New return a new event broker.
+func New(ctx libctx.FuncContext, cfg Config) (Broker, error) {
+	if e := cfg.Validate(); e != nil {
+		return nil, e
+	}
+
+	var x context.Context
+
+	if ctx != nil {
+		x = ctx()
+	}
+
+	if x == nil {
+		x = context.Background()
+	}
+
+	x, n := context.WithCancel(x)
+
+	return &brk{
+		m: sync.RWMutex{},
+		x: x,
+		n: n,
+		g: cfg,
+		i: new(atomic.Uint64),
+		s: 0,
+		c: new(atomic.Bool),
+		l: make(map[uint64]*client),
+		r: newReplay(cfg.getReplay()),
+		w: sync.WaitGroup{},
+	}, nil
+}
@@ -0,0 +1,69 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package sse
+
+// replay is a ring buffer of the last published events. It is not safe for concurrent use, the broker lock protects it.
+type replay struct {
+	s int     // size
+	h int     // index of the oldest event
+	l []Event // events
+}
+
+func newReplay(size int) *replay {
+	return &replay{
+		s: size,
+		h: 0,
+		l: make([]Event, 0, size),
+	}
+}
+
+func (o *replay) add(evt Event) {
+	if o.s < 1 {
+		return
+	} else if len(o.l) < o.s {
+		o.l = append(o.l, evt)
+		return
+	}
+
+	o.l[o.h] = evt
+	o.h = (o.h + 1) % o.s
+}
+
+// since return the kept events with an id greater than the given id and matching the filter, oldest first.
+func (o *replay) since(id uint64, fct func(topic string) bool) []Event {
+	var res = make([]Event, 0)
+
+	for i := 0; i < len(o.l); i++ {
+		e := o.l[(o.h+i)%len(o.l)]
+
+		if e.ID > id && fct(e.Topic) {
+			res = append(res, e)
+		}
+	}
+
+	return res
+}
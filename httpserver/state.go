@@ -0,0 +1,230 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package httpserver
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// stateBufferSize is the capacity of the channels returned by Subscribe.
+const stateBufferSize = 16
+
+// State is the lifecycle state of the server.
+type State uint8
+
+const (
+	// StateStopped is the initial state, and the state after a graceful shutdown.
+	StateStopped State = iota
+	// StateStarting is set while the server is configured and its listener is opened.
+	StateStarting
+	// StateRunning is set once the listener is opened and the server accept connections.
+	StateRunning
+	// StateDraining is set when the stop is called: during the drain delay, the server still
+	// serve the requests but the health check fails.
+	StateDraining
+	// StateStopping is set when the shutdown is called, after the drain delay: new connections
+	// are refused and the server wait for the active requests to finish.
+	StateStopping
+	// StateFailed is set if the server cannot start, stop unexpectedly or cannot shut down cleanly.
+	StateFailed
+)
+
+func (s State) String() string {
+	switch s {
+	case StateStopped:
+		return "stopped"
+	case StateStarting:
+		return "starting"
+	case StateRunning:
+		return "running"
+	case StateDraining:
+		return "draining"
+	case StateStopping:
+		return "stopping"
+	case StateFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+func (s State) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// canTransit return true if the state can change from the current state to the given state.
+func (s State) canTransit(to State) bool {
+	switch to {
+	case StateStarting:
+		return s == StateStopped || s == StateFailed
+	case StateRunning:
+		return s == StateStarting
+	case StateDraining:
+		return s == StateStarting || s == StateRunning
+	case StateStopping:
+		return s == StateDraining
+	case StateStopped:
+		return s == StateStopping
+	case StateFailed:
+		return s != StateStopped && s != StateFailed
+	default:
+		return false
+	}
+}
+
+// StateTransition is sent to the subscribers on each state change of the server.
+type StateTransition struct {
+	Previous State     `json:"previous"`
+	Current  State     `json:"current"`
+	Error    error     `json:"-"`
+	Time     time.Time `json:"time"`
+}
+
+type lifecycle struct {
+	m sync.Mutex
+	s State                           // current state
+	t time.Time                       // time of last transition
+	i uint64                          // last subscriber id
+	c map[uint64]chan StateTransition // subscribers
+	d chan struct{}                   // drain channel, closed when the server stop serving
+}
+
+func newLifecycle() *lifecycle {
+	return &lifecycle{
+		m: sync.Mutex{},
+		s: StateStopped,
+		t: time.Now(),
+		c: make(map[uint64]chan StateTransition),
+		d: make(chan struct{}),
+	}
+}
+
+func (l *lifecycle) get() State {
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	return l.s
+}
+
+// set apply the transition to the given state if allowed and notify the subscribers.
+// The transition is returned with false if the transition is not allowed.
+func (l *lifecycle) set(to State, err error) (StateTransition, bool) {
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	if !l.s.canTransit(to) {
+		return StateTransition{}, false
+	}
+
+	var trn = StateTransition{
+		Previous: l.s,
+		Current:  to,
+		Error:    err,
+		Time:     time.Now(),
+	}
+
+	l.s = to
+	l.t = trn.Time
+
+	switch to {
+	case StateStarting:
+		if isClosed(l.d) {
+			l.d = make(chan struct{})
+		}
+	case StateDraining, StateStopping, StateStopped, StateFailed:
+		if !isClosed(l.d) {
+			close(l.d)
+		}
+	}
+
+	for _, c := range l.c {
+		select {
+		case c <- trn:
+		default:
+			// slow subscriber: drop the oldest transition to keep the latest one
+			select {
+			case <-c:
+			default:
+			}
+			select {
+			case c <- trn:
+			default:
+			}
+		}
+	}
+
+	return trn, true
+}
+
+// subscribe register a subscriber removed when the context is done. A nil context or a context never done
+// (like context.Background) is refused with a closed channel, as the subscriber could never be removed.
+func (l *lifecycle) subscribe(ctx context.Context) <-chan StateTransition {
+	var c = make(chan StateTransition, stateBufferSize)
+
+	if ctx == nil || ctx.Done() == nil {
+		close(c)
+		return c
+	}
+
+	l.m.Lock()
+	l.i++
+	var i = l.i
+	l.c[i] = c
+	l.m.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		l.m.Lock()
+		defer l.m.Unlock()
+
+		delete(l.c, i)
+		close(c)
+	}()
+
+	return c
+}
+
+func (o *srv) State() State {
+	return o.y.get()
+}
+
+func (o *srv) Subscribe(ctx context.Context) <-chan StateTransition {
+	return o.y.subscribe(ctx)
+}
+
+// setState change the lifecycle state and publish the transition on the event bus.
+// It return false if the transition is not allowed from the current state.
+func (o *srv) setState(to State, tls bool, err error) bool {
+	if trn, ok := o.y.set(to, err); !ok {
+		return false
+	} else {
+		o.publishState(trn, tls)
+		return true
+	}
+}
@@ -0,0 +1,107 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package httpserver_test
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	libhts "github.com/nabbar/golib/httpserver"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("httpserver state", func() {
+	var srv libhts.Server
+
+	BeforeEach(func() {
+		var (
+			err error
+			adr = freeAddr()
+			cfg = libhts.Config{
+				Name:       "state",
+				Listen:     adr,
+				Expose:     "http://" + adr,
+				HandlerKey: "default",
+			}
+		)
+
+		cfg.RegisterHandlerFunc(func() map[string]http.Handler {
+			return map[string]http.Handler{
+				"default": bodyHandler("default"),
+			}
+		})
+
+		srv, err = libhts.New(cfg, nil)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		_ = srv.Stop(ctx)
+	})
+
+	Context("subscribing to the state transitions", func() {
+		It("must send the transitions until the context is canceled", func() {
+			x, n := context.WithCancel(ctx)
+			defer n()
+
+			sub := srv.Subscribe(x)
+
+			Expect(srv.Start(ctx)).ToNot(HaveOccurred())
+
+			var trn libhts.StateTransition
+			Eventually(sub).Should(Receive(&trn))
+			Expect(trn.Previous).To(Equal(libhts.StateStopped))
+			Expect(trn.Current).To(Equal(libhts.StateStarting))
+
+			Eventually(sub).Should(Receive(&trn))
+			Expect(trn.Current).To(Equal(libhts.StateRunning))
+
+			n()
+			Eventually(sub, time.Second).Should(BeClosed())
+		})
+
+		DescribeTable("must refuse a context never done with a closed channel",
+			func(fct func() context.Context) {
+				var sub <-chan libhts.StateTransition
+
+				Expect(func() {
+					sub = srv.Subscribe(fct())
+				}).ToNot(Panic())
+
+				Expect(sub).To(BeClosed())
+
+				// the refused subscription must not block the transitions
+				Expect(srv.Start(ctx)).ToNot(HaveOccurred())
+				Expect(srv.State()).To(Equal(libhts.StateRunning))
+			},
+			Entry("a nil context", func() context.Context { return nil }),
+			Entry("a background context", context.Background),
+			Entry("a context without cancel", func() context.Context { return context.WithoutCancel(ctx) }),
+		)
+	})
+})
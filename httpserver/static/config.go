@@ -0,0 +1,220 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package static
+
+import (
+	"fmt"
+	"strings"
+
+	libval "github.com/go-playground/validator/v10"
+	arccmp "github.com/nabbar/golib/archive/compress"
+	libsiz "github.com/nabbar/golib/size"
+)
+
+type IndexPolicy uint8
+
+const (
+	// IndexFile serve the first index file found into the directory, or a not found error.
+	IndexFile IndexPolicy = iota
+	// IndexList serve the first index file found into the directory, or a listing of the directory.
+	IndexList
+	// IndexDeny refuse any request on a directory with a forbidden error.
+	IndexDeny
+)
+
+func ParseIndexPolicy(s string) IndexPolicy {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case IndexList.String():
+		return IndexList
+	case IndexDeny.String():
+		return IndexDeny
+	default:
+		return IndexFile
+	}
+}
+
+func (p IndexPolicy) String() string {
+	switch p {
+	case IndexList:
+		return "list"
+	case IndexDeny:
+		return "deny"
+	default:
+		return "file"
+	}
+}
+
+func (p IndexPolicy) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+func (p *IndexPolicy) UnmarshalText(data []byte) error {
+	*p = ParseIndexPolicy(string(data))
+	return nil
+}
+
+const (
+	DefaultCacheControl    = "public, max-age=0, must-revalidate"
+	DefaultMinCompressSize = libsiz.SizeKilo
+	DefaultMaxCompressSize = 8 * libsiz.SizeMega
+)
+
+var (
+	// DefaultIndex is the list of index files used if none is configured.
+	DefaultIndex = []string{"index.html"}
+
+	// DefaultCompress is the list of compression used if none is configured, by order of preference.
+	DefaultCompress = []arccmp.Algorithm{arccmp.Zstd, arccmp.Brotli, arccmp.Gzip}
+
+	// DefaultCompressTypes is the list of content type prefixes compressed if none is configured.
+	DefaultCompressTypes = []string{
+		"text/",
+		"application/json",
+		"application/javascript",
+		"application/xml",
+		"application/wasm",
+		"image/svg+xml",
+	}
+)
+
+type Config struct {
+	// Root is the directory served. It is not used if a file system is given to New.
+	Root string `mapstructure:"root" json:"root" yaml:"root" toml:"root"`
+
+	// Prefix is the path prefix of the route, removed from the request path before looking up the file.
+	Prefix string `mapstructure:"prefix" json:"prefix" yaml:"prefix" toml:"prefix"`
+
+	// Index is the list of index file names looked up into a directory. Default is index.html.
+	Index []string `mapstructure:"index" json:"index" yaml:"index" toml:"index"`
+
+	// IndexPolicy define how a request on a directory is processed: file, list or deny. Default is file.
+	IndexPolicy IndexPolicy `mapstructure:"index_policy" json:"index_policy" yaml:"index_policy" toml:"index_policy"`
+
+	// CacheControl is the Cache-Control header sent with each file.
+	// Default is to revalidate each time with the ETag and Last-Modified headers.
+	CacheControl string `mapstructure:"cache_control" json:"cache_control" yaml:"cache_control" toml:"cache_control"`
+
+	// Compress is the list of compression algorithms negotiated with the client, by order of preference.
+	// Only gzip, zstd and brotli are used. Default is zstd, brotli and gzip. Set DisableCompress to disable.
+	Compress []arccmp.Algorithm `mapstructure:"compress" json:"compress" yaml:"compress" toml:"compress"`
+
+	// DisableCompress disable the compression of the responses.
+	DisableCompress bool `mapstructure:"disable_compress" json:"disable_compress" yaml:"disable_compress" toml:"disable_compress"`
+
+	// CompressTypes is the list of content type prefixes to compress. Default is texts, json, javascript, xml, wasm and svg.
+	CompressTypes []string `mapstructure:"compress_types" json:"compress_types" yaml:"compress_types" toml:"compress_types"`
+
+	// MinCompressSize is the minimum size of a file to be compressed. Default is 1KB.
+	MinCompressSize libsiz.Size `mapstructure:"min_compress_size" json:"min_compress_size" yaml:"min_compress_size" toml:"min_compress_size"`
+
+	// MaxCompressSize is the maximum size of a file to be compressed, compressed contents are kept into memory.
+	// Default is 8MB.
+	MaxCompressSize libsiz.Size `mapstructure:"max_compress_size" json:"max_compress_size" yaml:"max_compress_size" toml:"max_compress_size"`
+
+	// AllowHidden allow to serve the files and directories starting with a dot.
+	AllowHidden bool `mapstructure:"allow_hidden" json:"allow_hidden" yaml:"allow_hidden" toml:"allow_hidden"`
+
+	// FollowSymlinks allow the symbolic links of the root directory to target files outside of the root.
+	FollowSymlinks bool `mapstructure:"follow_symlinks" json:"follow_symlinks" yaml:"follow_symlinks" toml:"follow_symlinks"`
+}
+
+func (c Config) Validate() error {
+	if er := libval.New().Struct(c); er != nil {
+		if e, ok := er.(*libval.InvalidValidationError); ok {
+			return e
+		}
+
+		for _, e := range er.(libval.ValidationErrors) {
+			//nolint goerr113
+			return fmt.Errorf("config field '%s' is not validated by constraint '%s'", e.Namespace(), e.ActualTag())
+		}
+	}
+
+	return nil
+}
+
+func (c Config) getIndex() []string {
+	if len(c.Index) > 0 {
+		return c.Index
+	}
+
+	return DefaultIndex
+}
+
+func (c Config) getCacheControl() string {
+	if len(c.CacheControl) > 0 {
+		return c.CacheControl
+	}
+
+	return DefaultCacheControl
+}
+
+func (c Config) getCompress() []arccmp.Algorithm {
+	if c.DisableCompress {
+		return nil
+	}
+
+	var lst = c.Compress
+
+	if len(lst) < 1 {
+		lst = DefaultCompress
+	}
+
+	var res = make([]arccmp.Algorithm, 0, len(lst))
+
+	for _, a := range lst {
+		if a == arccmp.Gzip || a == arccmp.Zstd || a == arccmp.Brotli {
+			res = append(res, a)
+		}
+	}
+
+	return res
+}
+
+func (c Config) getCompressTypes() []string {
+	if len(c.CompressTypes) > 0 {
+		return c.CompressTypes
+	}
+
+	return DefaultCompressTypes
+}
+
+func (c Config) getMinCompressSize() int64 {
+	if c.MinCompressSize > 0 {
+		return c.MinCompressSize.Int64()
+	}
+
+	return DefaultMinCompressSize.Int64()
+}
+
+func (c Config) getMaxCompressSize() int64 {
+	if c.MaxCompressSize > 0 {
+		return c.MaxCompressSize.Int64()
+	}
+
+	return DefaultMaxCompressSize.Int64()
+}
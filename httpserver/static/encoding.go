@@ -0,0 +1,196 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package static
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"net/http"
+	"strconv"
+	"strings"
+
+	arccmp "github.com/nabbar/golib/archive/compress"
+)
+
+type cmpContent struct {
+	t string // etag of the source
+	p []byte // compressed content
+}
+
+// etag return a strong etag from the size and modification time of the file,
+// or from a hash of its content if the modification time is unknown (like for an embedded file system).
+func (o *sth) etag(name string, inf fs.FileInfo, r io.ReadSeeker) (string, error) {
+	if t := modTime(inf); !t.IsZero() {
+		return `"` + strconv.FormatInt(inf.Size(), 16) + "-" + strconv.FormatInt(t.UnixNano(), 16) + `"`, nil
+	} else if v, k := o.e.Load(name); k {
+		return v.(string), nil
+	}
+
+	var h = sha256.New()
+
+	if _, e := io.Copy(h, r); e != nil {
+		return "", e
+	} else if _, e = r.Seek(0, io.SeekStart); e != nil {
+		return "", e
+	}
+
+	var t = `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+	o.e.Store(name, t)
+
+	return t, nil
+}
+
+func (o *sth) compressible(typ string) bool {
+	typ = strings.ToLower(typ)
+
+	for _, p := range o.c.getCompressTypes() {
+		if strings.HasPrefix(typ, strings.ToLower(p)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// negotiate return the compression to use for the response, or None.
+// Range requests are never compressed, to keep the offsets of resumed downloads on the identity content.
+func (o *sth) negotiate(r *http.Request, typ string, size int64) arccmp.Algorithm {
+	var lst = o.c.getCompress()
+
+	if len(lst) < 1 || len(r.Header.Get("Range")) > 0 {
+		return arccmp.None
+	} else if size < o.c.getMinCompressSize() || size > o.c.getMaxCompressSize() {
+		return arccmp.None
+	} else if !o.compressible(typ) {
+		return arccmp.None
+	}
+
+	var (
+		acc = acceptEncoding(r.Header.Values("Accept-Encoding"))
+		res = arccmp.None
+		bst = 0.0
+	)
+
+	// highest quality first, the order of the config is used for equal qualities
+	for _, a := range lst {
+		q, k := acc[coding(a)]
+
+		if !k {
+			q = acc["*"]
+		}
+
+		if q > bst {
+			res = a
+			bst = q
+		}
+	}
+
+	return res
+}
+
+// coding return the http content coding token of the compression algorithm.
+func coding(a arccmp.Algorithm) string {
+	if a == arccmp.Brotli {
+		return "br"
+	}
+
+	return a.String()
+}
+
+// acceptEncoding return the quality of each coding of the Accept-Encoding headers.
+func acceptEncoding(val []string) map[string]float64 {
+	var res = make(map[string]float64)
+
+	for _, v := range val {
+		for _, i := range strings.Split(v, ",") {
+			var (
+				p = strings.Split(i, ";")
+				n = strings.ToLower(strings.TrimSpace(p[0]))
+				q = 1.0
+			)
+
+			if len(n) < 1 {
+				continue
+			}
+
+			for _, a := range p[1:] {
+				a = strings.TrimSpace(a)
+
+				if strings.HasPrefix(a, "q=") {
+					if f, e := strconv.ParseFloat(strings.TrimPrefix(a, "q="), 64); e == nil {
+						q = f
+					}
+				}
+			}
+
+			res[n] = q
+		}
+	}
+
+	return res
+}
+
+// compressed return the compressed content of the file, from the cache if the etag of the source is unchanged.
+func (o *sth) compressed(name, tag string, alg arccmp.Algorithm, r io.ReadSeeker) ([]byte, error) {
+	var key = alg.String() + ":" + name
+
+	if v, k := o.z.Load(key); k {
+		if c := v.(cmpContent); c.t == tag {
+			return c.p, nil
+		}
+	}
+
+	var buf = bytes.NewBuffer(make([]byte, 0))
+
+	w, e := alg.Writer(nopCloser{buf})
+
+	if e != nil {
+		return nil, e
+	} else if _, e = io.Copy(w, r); e != nil {
+		_ = w.Close()
+		return nil, e
+	} else if e = w.Close(); e != nil {
+		return nil, e
+	} else if _, e = r.Seek(0, io.SeekStart); e != nil {
+		return nil, e
+	}
+
+	o.z.Store(key, cmpContent{t: tag, p: buf.Bytes()})
+
+	return buf.Bytes(), nil
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
@@ -0,0 +1,84 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package static
+
+import (
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	srvtps "github.com/nabbar/golib/httpserver/types"
+)
+
+type Static interface {
+	http.Handler
+
+	// Register return a FuncHandler adding this handler with the given key to the handlers returned by fct.
+	// The result can be given to the httpserver Handler function, to serve the files for the servers using this HandlerKey.
+	Register(key string, fct srvtps.FuncHandler) srvtps.FuncHandler
+
+	// Purge drop the cached ETags and compressed contents, to be called after an update of the files.
+	Purge()
+}
+
+// New return a static file handler serving the given file system, or the root directory of the config if fsys is nil.
+// An embedded file system (embed.FS) can be given as fsys, with fs.Sub to select a sub directory.
+func New(cfg Config, fsys fs.FS) (Static, error) {
+	if e := cfg.Validate(); e != nil {
+		return nil, e
+	}
+
+	var root string
+
+	if fsys == nil {
+		if len(cfg.Root) < 1 {
+			return nil, ErrMissingRoot
+		} else if r, e := filepath.Abs(cfg.Root); e != nil {
+			return nil, e
+		} else if r, e = filepath.EvalSymlinks(r); e != nil {
+			return nil, e
+		} else if i, e := os.Stat(r); e != nil {
+			return nil, e
+		} else if !i.IsDir() {
+			return nil, ErrMissingRoot
+		} else {
+			root = r
+		}
+
+		fsys = os.DirFS(root)
+	}
+
+	return &sth{
+		c: cfg,
+		f: fsys,
+		r: root,
+		e: sync.Map{},
+		z: sync.Map{},
+	}, nil
+}
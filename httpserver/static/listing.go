@@ -0,0 +1,122 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package static
+
+import (
+	"html/template"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+)
+
+var tplList = template.Must(template.New("list").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Index of {{.Path}}</title></head>
+<body>
+<h1>Index of {{.Path}}</h1>
+<ul>
+{{- if .Parent}}
+<li><a href="../">../</a></li>
+{{- end}}
+{{- range .Items}}
+<li><a href="{{.Link}}">{{.Name}}</a></li>
+{{- end}}
+</ul>
+</body>
+</html>
+`))
+
+type listItem struct {
+	Name string
+	Link string
+	dir  bool
+}
+
+func (o *sth) serveList(w http.ResponseWriter, r *http.Request, name string) {
+	lst, err := fs.ReadDir(o.f, name)
+
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	var itm = make([]listItem, 0, len(lst))
+
+	for _, i := range lst {
+		var n = i.Name()
+
+		if !o.c.AllowHidden && strings.HasPrefix(n, ".") {
+			continue
+		} else if o.checkRoot(path.Join(name, n)) != nil {
+			continue
+		}
+
+		var l = (&url.URL{Path: n}).String()
+
+		if i.IsDir() {
+			n += "/"
+			l += "/"
+		}
+
+		itm = append(itm, listItem{
+			Name: n,
+			Link: l,
+			dir:  i.IsDir(),
+		})
+	}
+
+	// directories first, then files, each by name
+	sort.SliceStable(itm, func(i, j int) bool {
+		if itm[i].dir != itm[j].dir {
+			return itm[i].dir
+		}
+		return itm[i].Name < itm[j].Name
+	})
+
+	h := w.Header()
+	h.Set("Content-Type", "text/html; charset=utf-8")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("X-Content-Type-Options", "nosniff")
+
+	if r.Method == http.MethodHead {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	_ = tplList.Execute(w, struct {
+		Path   string
+		Parent bool
+		Items  []listItem
+	}{
+		Path:   r.URL.Path,
+		Parent: name != ".",
+		Items:  itm,
+	})
+}
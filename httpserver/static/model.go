@@ -0,0 +1,281 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package static
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	srvtps "github.com/nabbar/golib/httpserver/types"
+)
+
+type sth struct {
+	c Config
+	f fs.FS
+	r string   // resolved root directory, empty for a given file system
+	e sync.Map // etag by file name, for file without modification time
+	z sync.Map // compressed content by file name, etag and algorithm
+}
+
+func (o *sth) Register(key string, fct srvtps.FuncHandler) srvtps.FuncHandler {
+	return func() map[string]http.Handler {
+		var res = make(map[string]http.Handler)
+
+		if fct != nil {
+			for k, v := range fct() {
+				res[k] = v
+			}
+		}
+
+		res[key] = o
+		return res
+	}
+}
+
+func (o *sth) Purge() {
+	o.e.Range(func(k, _ any) bool {
+		o.e.Delete(k)
+		return true
+	})
+
+	o.z.Range(func(k, _ any) bool {
+		o.z.Delete(k)
+		return true
+	})
+}
+
+func (o *sth) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", http.MethodGet+", "+http.MethodHead)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	name, err := o.clean(r.URL.Path)
+
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	} else if err = o.checkRoot(name); err != nil {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	inf, err := fs.Stat(o.f, name)
+
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	} else if inf.IsDir() {
+		o.serveDir(w, r, name)
+		return
+	}
+
+	o.serveFile(w, r, name, inf)
+}
+
+// clean return the name of the file into the file system for the request path.
+// The name is rejected if it contains a hidden element and hidden files are not allowed.
+func (o *sth) clean(p string) (string, error) {
+	if strings.ContainsAny(p, "\x00\\") {
+		return "", ErrInvalidPath
+	}
+
+	if len(o.c.Prefix) > 0 {
+		var pfx = "/" + strings.Trim(o.c.Prefix, "/")
+
+		if p == pfx {
+			p = "/"
+		} else if strings.HasPrefix(p, pfx+"/") {
+			p = strings.TrimPrefix(p, pfx)
+		} else {
+			return "", ErrInvalidPath
+		}
+	}
+
+	p = strings.TrimPrefix(path.Clean("/"+p), "/")
+
+	if len(p) < 1 {
+		return ".", nil
+	} else if !fs.ValidPath(p) {
+		return "", ErrInvalidPath
+	}
+
+	if !o.c.AllowHidden {
+		for _, i := range strings.Split(p, "/") {
+			if strings.HasPrefix(i, ".") {
+				return "", ErrInvalidPath
+			}
+		}
+	}
+
+	return p, nil
+}
+
+// checkRoot refuse the symbolic links of the root directory targeting a file outside of the root.
+func (o *sth) checkRoot(name string) error {
+	if len(o.r) < 1 || o.c.FollowSymlinks {
+		return nil
+	}
+
+	p, e := filepath.EvalSymlinks(filepath.Join(o.r, filepath.FromSlash(name)))
+
+	if e != nil {
+		// not existing file, reported by the stat
+		return nil
+	} else if p != o.r && !strings.HasPrefix(p, o.r+string(filepath.Separator)) {
+		return ErrOutsideRoot
+	}
+
+	return nil
+}
+
+func (o *sth) serveDir(w http.ResponseWriter, r *http.Request, name string) {
+	if o.c.IndexPolicy == IndexDeny {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	} else if !strings.HasSuffix(r.URL.Path, "/") {
+		var u = *r.URL
+		u.Path += "/"
+		http.Redirect(w, r, u.String(), http.StatusMovedPermanently)
+		return
+	}
+
+	for _, i := range o.c.getIndex() {
+		var f = path.Join(name, i)
+
+		if o.checkRoot(f) != nil {
+			continue
+		} else if inf, e := fs.Stat(o.f, f); e == nil && !inf.IsDir() {
+			o.serveFile(w, r, f, inf)
+			return
+		}
+	}
+
+	if o.c.IndexPolicy == IndexList {
+		o.serveList(w, r, name)
+		return
+	}
+
+	http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+}
+
+func (o *sth) serveFile(w http.ResponseWriter, r *http.Request, name string, inf fs.FileInfo) {
+	f, err := o.f.Open(name)
+
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	defer func() {
+		_ = f.Close()
+	}()
+
+	var (
+		h   = w.Header()
+		rsk io.ReadSeeker
+		typ string
+		tag string
+	)
+
+	if s, k := f.(io.ReadSeeker); k {
+		rsk = s
+	} else if p, e := io.ReadAll(f); e != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	} else {
+		rsk = bytes.NewReader(p)
+	}
+
+	if typ, err = contentType(name, rsk); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	if tag, err = o.etag(name, inf, rsk); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	h.Set("Content-Type", typ)
+	h.Set("Cache-Control", o.c.getCacheControl())
+	h.Set("X-Content-Type-Options", "nosniff")
+
+	if alg := o.negotiate(r, typ, inf.Size()); !alg.IsNone() {
+		if p, e := o.compressed(name, tag, alg, rsk); e == nil {
+			h.Add("Vary", "Accept-Encoding")
+			h.Set("Content-Encoding", coding(alg))
+			h.Set("ETag", strings.TrimSuffix(tag, `"`)+"-"+coding(alg)+`"`)
+			http.ServeContent(w, r, name, modTime(inf), bytes.NewReader(p))
+			return
+		}
+	}
+
+	if len(o.c.getCompress()) > 0 && o.compressible(typ) {
+		h.Add("Vary", "Accept-Encoding")
+	}
+
+	h.Set("ETag", tag)
+	http.ServeContent(w, r, name, modTime(inf), rsk)
+}
+
+func modTime(inf fs.FileInfo) time.Time {
+	if t := inf.ModTime(); !t.IsZero() && t.Unix() > 0 {
+		return t
+	}
+
+	return time.Time{}
+}
+
+func contentType(name string, r io.ReadSeeker) (string, error) {
+	if t := mime.TypeByExtension(path.Ext(name)); len(t) > 0 {
+		return t, nil
+	}
+
+	var (
+		b = make([]byte, 512)
+		n int
+		e error
+	)
+
+	if n, e = io.ReadFull(r, b); e != nil && e != io.EOF && e != io.ErrUnexpectedEOF {
+		return "", e
+	} else if _, e = r.Seek(0, io.SeekStart); e != nil {
+		return "", e
+	}
+
+	return http.DetectContentType(b[:n]), nil
+}
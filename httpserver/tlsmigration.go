@@ -0,0 +1,389 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package httpserver
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tlsvrs "github.com/nabbar/golib/certificates/tlsversion"
+	loglvl "github.com/nabbar/golib/logger/level"
+)
+
+const (
+	// TLSListenerMain is the listener name used into TLSMigrationStats for the main bind of the server.
+	TLSListenerMain = "main"
+	// TLSListenerLegacy is the listener name used into TLSMigrationStats for the legacy bind of the server.
+	TLSListenerLegacy = "legacy"
+)
+
+type TLSMigrationMode uint8
+
+const (
+	// TLSMigrationDisabled keep the server with only its TLS minimal version.
+	TLSMigrationDisabled TLSMigrationMode = iota
+	// TLSMigrationLogOnly lower the minimal version of the server to the legacy floor,
+	// and log / count each connection negotiated below the TLS minimal version of the server.
+	TLSMigrationLogOnly
+	// TLSMigrationDual keep the TLS minimal version of the server on its main bind,
+	// and start a second listener on the legacy bind accepting the legacy floor.
+	TLSMigrationDual
+)
+
+func ParseTLSMigrationMode(s string) TLSMigrationMode {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.Replace(s, "_", "-", -1)
+
+	switch s {
+	case TLSMigrationLogOnly.String():
+		return TLSMigrationLogOnly
+	case TLSMigrationDual.String():
+		return TLSMigrationDual
+	default:
+		return TLSMigrationDisabled
+	}
+}
+
+func (m TLSMigrationMode) String() string {
+	switch m {
+	case TLSMigrationLogOnly:
+		return "log-only"
+	case TLSMigrationDual:
+		return "dual"
+	default:
+		return "disabled"
+	}
+}
+
+func (m TLSMigrationMode) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(m.String())), nil
+}
+
+func (m *TLSMigrationMode) UnmarshalJSON(data []byte) error {
+	var str = string(data)
+
+	if s, e := strconv.Unquote(str); e == nil {
+		str = s
+	}
+
+	*m = ParseTLSMigrationMode(str)
+	return nil
+}
+
+func (m TLSMigrationMode) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+func (m *TLSMigrationMode) UnmarshalText(data []byte) error {
+	*m = ParseTLSMigrationMode(string(data))
+	return nil
+}
+
+// TLSMigration define a migration mode used to measure the impact of raising the TLS minimal version
+// before really applying it. It is only used if the server is started with TLS.
+type TLSMigration struct {
+	// Mode is the migration mode: disabled, log-only or dual.
+	Mode TLSMigrationMode `mapstructure:"mode" json:"mode" yaml:"mode" toml:"mode"`
+
+	// LegacyVersionMin is the TLS minimal version still accepted during the migration.
+	// If not defined, TLS 1.0 is used.
+	LegacyVersionMin tlsvrs.Version `mapstructure:"legacy_version_min" json:"legacy_version_min" yaml:"legacy_version_min" toml:"legacy_version_min"`
+
+	// LegacyListen is the local address with a port used for the legacy listener in dual mode.
+	LegacyListen string `mapstructure:"legacy_listen" json:"legacy_listen" yaml:"legacy_listen" toml:"legacy_listen" validate:"omitempty,hostname_port"`
+}
+
+func (t TLSMigration) validate(listen string) error {
+	switch t.Mode {
+	case TLSMigrationDual:
+		if len(t.LegacyListen) < 1 {
+			return errors.New("tls migration dual mode need a legacy listen address")
+		} else if t.LegacyListen == listen {
+			return errors.New("tls migration legacy listen address must be different of the listen address")
+		}
+	}
+
+	return nil
+}
+
+// TLSMigrationStats is the snapshot of the protocol metrics collected during a TLS migration.
+type TLSMigrationStats struct {
+	// Mode is the current migration mode.
+	Mode TLSMigrationMode `json:"mode"`
+	// VersionMin is the TLS minimal version targeted by the server.
+	VersionMin string `json:"version_min"`
+	// LegacyVersionMin is the TLS minimal version accepted during the migration.
+	LegacyVersionMin string `json:"legacy_version_min"`
+	// Connections is the number of connections by listener and by negotiated TLS version.
+	Connections map[string]map[string]uint64 `json:"connections"`
+	// BelowMin is the number of connections negotiated below the TLS minimal version of the server.
+	BelowMin uint64 `json:"below_min"`
+	// Since is the time of the last reset of the metrics (at each start of the server).
+	Since time.Time `json:"since"`
+}
+
+type tlsMig struct {
+	m sync.Mutex
+	d TLSMigrationMode             // mode
+	f uint16                       // strict floor
+	g uint16                       // legacy floor
+	c map[string]map[uint16]uint64 // connections by listener and version
+	b uint64                       // connections below strict floor
+	t time.Time                    // since
+	k sync.Map                     // connections already counted
+	s *http.Server                 // legacy server
+}
+
+func newTLSMigration() *tlsMig {
+	return &tlsMig{
+		m: sync.Mutex{},
+		c: make(map[string]map[uint16]uint64),
+		t: time.Now(),
+	}
+}
+
+func (t *tlsMig) reset(mode TLSMigrationMode, floor, legacy uint16) {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	t.d = mode
+	t.f = floor
+	t.g = legacy
+	t.c = make(map[string]map[uint16]uint64)
+	t.b = 0
+	t.t = time.Now()
+	t.s = nil
+	t.k.Range(func(key, value any) bool {
+		t.k.Delete(key)
+		return true
+	})
+}
+
+func (t *tlsMig) add(listener string, version uint16) bool {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	if _, ok := t.c[listener]; !ok {
+		t.c[listener] = make(map[uint16]uint64)
+	}
+
+	t.c[listener][version]++
+
+	if version < t.f {
+		t.b++
+		return true
+	}
+
+	return false
+}
+
+func (t *tlsMig) stats() TLSMigrationStats {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	var res = TLSMigrationStats{
+		Mode:             t.d,
+		VersionMin:       tlsVersionName(t.f),
+		LegacyVersionMin: tlsVersionName(t.g),
+		Connections:      make(map[string]map[string]uint64, len(t.c)),
+		BelowMin:         t.b,
+		Since:            t.t,
+	}
+
+	for l, m := range t.c {
+		res.Connections[l] = make(map[string]uint64, len(m))
+
+		for v, n := range m {
+			res.Connections[l][tlsVersionName(v)] = n
+		}
+	}
+
+	return res
+}
+
+func (t *tlsMig) setLegacy(s *http.Server) {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	t.s = s
+}
+
+func (t *tlsMig) getLegacy() *http.Server {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	return t.s
+}
+
+func (o *srv) TLSMigrationStats() TLSMigrationStats {
+	if o == nil || o.t == nil {
+		return TLSMigrationStats{
+			Connections: make(map[string]map[string]uint64),
+		}
+	}
+
+	return o.t.stats()
+}
+
+// connState return the http.Server ConnState function counting the negotiated TLS version
+// of each connection at its first request and logging connections below the strict floor.
+func (o *srv) connState(listener string) func(net.Conn, http.ConnState) {
+	return func(c net.Conn, s http.ConnState) {
+		switch s {
+		case http.StateActive:
+			t, ok := c.(*tls.Conn)
+
+			if !ok {
+				return
+			} else if _, l := o.t.k.LoadOrStore(c, struct{}{}); l {
+				return
+			}
+
+			var cs = t.ConnectionState()
+
+			if !o.t.add(listener, cs.Version) {
+				return
+			}
+
+			ent := o.logger().Entry(loglvl.WarnLevel, "TLS connection negotiated below the server minimal version")
+			ent.FieldAdd("listener", listener)
+			ent.FieldAdd("remote", c.RemoteAddr().String())
+			ent.FieldAdd("server_name", cs.ServerName)
+			ent.FieldAdd("tls_version", tlsVersionName(cs.Version))
+			ent.Log()
+
+		case http.StateClosed, http.StateHijacked:
+			o.t.k.Delete(c)
+		}
+	}
+}
+
+// setTLSMigration apply the migration mode on the given server and create the legacy server if needed.
+// Must be called after the initialisation of the given server.
+func (o *srv) setTLSMigration(s *http.Server) error {
+	var (
+		cfg = o.GetConfig()
+		mig TLSMigration
+	)
+
+	if cfg != nil {
+		mig = cfg.TLSMigration
+	}
+
+	if s.TLSConfig == nil || len(s.TLSConfig.Certificates) < 1 {
+		o.t.reset(TLSMigrationDisabled, 0, 0)
+		return nil
+	}
+
+	var (
+		floor  = s.TLSConfig.MinVersion
+		legacy = mig.LegacyVersionMin.TLS()
+	)
+
+	if floor == 0 {
+		floor = tls.VersionTLS12
+	}
+
+	if legacy == 0 {
+		legacy = tls.VersionTLS10
+	}
+
+	if mig.Mode == TLSMigrationDisabled || legacy >= floor {
+		o.t.reset(TLSMigrationDisabled, floor, floor)
+		return nil
+	}
+
+	o.t.reset(mig.Mode, floor, legacy)
+	s.ConnState = o.connState(TLSListenerMain)
+
+	if mig.Mode == TLSMigrationLogOnly {
+		s.TLSConfig.MinVersion = legacy
+		return nil
+	}
+
+	// #nosec
+	l := &http.Server{
+		Addr:      mig.LegacyListen,
+		Handler:   s.Handler,
+		TLSConfig: s.TLSConfig.Clone(),
+		ErrorLog:  s.ErrorLog,
+		ConnState: o.connState(TLSListenerLegacy),
+	}
+
+	l.TLSConfig.MinVersion = legacy
+
+	if e := o.cfgGetServer().initServer(l); e != nil {
+		return e
+	}
+
+	o.t.setLegacy(l)
+	return nil
+}
+
+// runTLSMigration start the legacy server if any.
+func (o *srv) runTLSMigration(ctx context.Context) {
+	var l = o.t.getLegacy()
+
+	if l == nil {
+		return
+	}
+
+	l.BaseContext = func(listener net.Listener) context.Context {
+		return ctx
+	}
+
+	go func() {
+		ent := o.logger().Entry(loglvl.InfoLevel, "TLS HTTP legacy listener is starting")
+		ent.FieldAdd("legacy_bind", l.Addr)
+		ent.Log()
+
+		if e := l.ListenAndServeTLS("", ""); e != nil && !errors.Is(e, http.ErrServerClosed) {
+			ent = o.logger().Entry(loglvl.ErrorLevel, "TLS HTTP legacy listener stopped")
+			ent.FieldAdd("legacy_bind", l.Addr)
+			ent.ErrorAdd(true, e)
+			ent.Log()
+		}
+	}()
+}
+
+// stopTLSMigration shutdown the legacy server if any.
+func (o *srv) stopTLSMigration(ctx context.Context) error {
+	var l = o.t.getLegacy()
+
+	if l == nil {
+		return nil
+	}
+
+	o.t.setLegacy(nil)
+	return l.Shutdown(ctx)
+}
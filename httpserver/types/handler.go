@@ -26,7 +26,15 @@
 
 package types
 
-import "net/http"
+import (
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+)
+
+// HandlerKeySeparator is the separator of the keys into a handler key fallback chain.
+const HandlerKeySeparator = ","
 
 type FuncHandler func() map[string]http.Handler
 
@@ -39,3 +47,39 @@ type BadHandler struct{}
 func (o BadHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
 	writer.WriteHeader(http.StatusInternalServerError)
 }
+
+// HandlerResolveKey return the first key of the given fallback chain existing into the handler list.
+// The chain is a list of keys separated by a comma, tried in order, like "api-v2,api,".
+// An empty item of the chain is the default handler (empty key).
+// An item can be a wildcard pattern (see path.Match) like "api-*": the first matching key in
+// alphabetical order is used. A chain without comma and wildcard is a single strict key.
+func HandlerResolveKey(list map[string]http.Handler, chain string) (string, bool) {
+	if len(list) < 1 {
+		return "", false
+	}
+
+	for _, k := range strings.Split(chain, HandlerKeySeparator) {
+		k = strings.TrimSpace(k)
+
+		if _, ok := list[k]; ok {
+			return k, true
+		} else if !strings.ContainsAny(k, "*?[") {
+			continue
+		}
+
+		var keys = make([]string, 0, len(list))
+
+		for i := range list {
+			if m, e := path.Match(k, i); e == nil && m {
+				keys = append(keys, i)
+			}
+		}
+
+		if len(keys) > 0 {
+			sort.Strings(keys)
+			return keys[0], true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,73 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package types_test
+
+import (
+	"net/http"
+
+	srvtps "github.com/nabbar/golib/httpserver/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func handlerList(keys ...string) map[string]http.Handler {
+	var res = make(map[string]http.Handler, len(keys))
+
+	for _, k := range keys {
+		res[k] = srvtps.NewBadHandler()
+	}
+
+	return res
+}
+
+var _ = Describe("httpserver handler key", func() {
+	DescribeTable("resolving a handler key fallback chain",
+		func(list map[string]http.Handler, chain string, key string, found bool) {
+			k, f := srvtps.HandlerResolveKey(list, chain)
+			Expect(f).To(Equal(found))
+			Expect(k).To(Equal(key))
+		},
+		Entry("a strict key", handlerList("api", "web"), "web", "web", true),
+		Entry("a missing strict key", handlerList("api", "web"), "admin", "", false),
+		Entry("an empty list", handlerList(), "api", "", false),
+		Entry("the empty key as default handler", handlerList("", "api"), "", "", true),
+		Entry("the first existing key of the chain", handlerList("api", "api-v2", "web"), "api-v2,api", "api-v2", true),
+		Entry("the chain order before the list order", handlerList("api", "api-v2"), "api,api-v2", "api", true),
+		Entry("the next key if the first is missing", handlerList("api", "web"), "api-v2,api", "api", true),
+		Entry("the spaces around the keys", handlerList("api"), " api-v2 , api ", "api", true),
+		Entry("the empty item of the chain as default handler", handlerList("", "web"), "api-v2,", "", true),
+		Entry("no default handler for a trailing empty item", handlerList("web"), "api-v2,", "", false),
+		Entry("the first matching key in alphabetical order", handlerList("api-b", "api-a", "api-c"), "api-*", "api-a", true),
+		Entry("a single character wildcard", handlerList("api-v1", "api-v10", "api-v2"), "api-v?", "api-v1", true),
+		Entry("a character class wildcard", handlerList("api-v1", "api-v2", "api-v3"), "api-v[23]", "api-v2", true),
+		Entry("a key existing with the pattern name before the matching keys", handlerList("api-*", "api-a"), "api-*", "api-*", true),
+		Entry("an earlier wildcard before a later strict key", handlerList("api", "web-a"), "web-*,api", "web-a", true),
+		Entry("an earlier strict key before a later wildcard", handlerList("api", "web-a"), "api,web-*", "api", true),
+		Entry("the next key if the wildcard match nothing", handlerList("api", "web"), "adm-*,api", "api", true),
+		Entry("a wildcard not matching across the path separator", handlerList("api/v1"), "api*", "", false),
+		Entry("an invalid pattern ignored", handlerList("api", "[web"), "[web-*,api", "api", true),
+	)
+})
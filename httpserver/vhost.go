@@ -0,0 +1,357 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package httpserver
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	libtls "github.com/nabbar/golib/certificates"
+)
+
+// VirtualHost is a logical server sharing the listener of its server, selected by the SNI of the TLS handshake
+// and by the Host header of the requests, with its own handler and TLS certificates.
+// It allows to serve several tenants on a single port.
+type VirtualHost struct {
+	// Name identify the virtual host into the logs and the startup banner.
+	Name string `mapstructure:"name" json:"name" yaml:"name" toml:"name" validate:"required"`
+
+	// Hosts is the list of the host names served by the virtual host, like "api.example.com",
+	// or a wildcard like "*.example.com" matching any sub domain. An exact name is preferred to a wildcard.
+	Hosts []string `mapstructure:"hosts" json:"hosts" yaml:"hosts" toml:"hosts" validate:"required,min=1"`
+
+	// HandlerKey is the handler of the virtual host, with the same fallback chain syntax as the server HandlerKey.
+	HandlerKey string `mapstructure:"handler_key" json:"handler_key" yaml:"handler_key" toml:"handler_key"`
+
+	// TLS is the tls configuration used for the TLS handshakes with a SNI matching the hosts.
+	// With InheritDefault, the tls configuration of the server is used as default (versions, ciphers, client CA, ...).
+	// Without certificates, the tls configuration of the server is used.
+	TLS libtls.Config `mapstructure:"tls" json:"tls" yaml:"tls" toml:"tls"`
+}
+
+func (v VirtualHost) Clone() VirtualHost {
+	return VirtualHost{
+		Name:       v.Name,
+		Hosts:      append(make([]string, 0, len(v.Hosts)), v.Hosts...),
+		HandlerKey: strings.ToLower(v.HandlerKey),
+		TLS:        v.TLS,
+	}
+}
+
+func validateVirtualHosts(l []VirtualHost) []error {
+	var (
+		err = make([]error, 0)
+		nam = make(map[string]bool)
+		hst = make(map[string]string)
+	)
+
+	for _, v := range l {
+		if nam[v.Name] {
+			//nolint goerr113
+			err = append(err, fmt.Errorf("virtual host '%s' is defined more than once", v.Name))
+		}
+
+		nam[v.Name] = true
+
+		for _, h := range v.Hosts {
+			var n = vhostName(h)
+
+			if len(n) < 1 || strings.Contains(strings.TrimPrefix(n, "*."), "*") {
+				//nolint goerr113
+				err = append(err, fmt.Errorf("virtual host '%s' has an invalid host '%s'", v.Name, h))
+			} else if p, ok := hst[n]; ok {
+				//nolint goerr113
+				err = append(err, fmt.Errorf("host '%s' is served by virtual host '%s' and '%s'", h, p, v.Name))
+			} else {
+				hst[n] = v.Name
+			}
+		}
+	}
+
+	return err
+}
+
+// vhostName return the host name in lower case, without the port and the final dot.
+func vhostName(host string) string {
+	if h, _, e := net.SplitHostPort(host); e == nil {
+		host = h
+	}
+
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+}
+
+type vhost struct {
+	n string       // name
+	k string       // handler key
+	h []string     // host names
+	t *tls.Config  // tls config, nil to use the server one
+	r http.Handler // handler
+}
+
+type vhostList []*vhost
+
+// match return the index of the virtual host serving the given host, or -1 if none.
+// An exact host name is preferred to a wildcard, and a longer wildcard to a shorter one.
+func (l vhostList) match(host string) int {
+	var (
+		n = vhostName(host)
+		r = -1
+		s = 0
+	)
+
+	if len(n) < 1 {
+		return -1
+	}
+
+	for i, v := range l {
+		for _, h := range v.h {
+			if h == n {
+				return i
+			} else if strings.HasPrefix(h, "*.") && strings.HasSuffix(n, h[1:]) && len(h) > s {
+				r = i
+				s = len(h)
+			}
+		}
+	}
+
+	return r
+}
+
+// vhostList return the virtual hosts of the config with their handler.
+func (o *srv) vhostList(cfg *Config) vhostList {
+	if cfg == nil || len(cfg.VirtualHosts) < 1 {
+		return nil
+	}
+
+	var res = make(vhostList, 0, len(cfg.VirtualHosts))
+
+	for _, v := range cfg.VirtualHosts {
+		var h = &vhost{
+			n: v.Name,
+			k: v.HandlerKey,
+			h: make([]string, 0, len(v.Hosts)),
+			r: o.HandlerGet(v.HandlerKey),
+		}
+
+		for _, n := range v.Hosts {
+			h.h = append(h.h, vhostName(n))
+		}
+
+		res = append(res, h)
+	}
+
+	return res
+}
+
+// vhostCerts is the snapshot of the tls configs of the server and of its virtual hosts,
+// built on each SetConfig and used by the TLS handshakes of the running server.
+type vhostCerts struct {
+	d *tls.Config // server tls config, nil without certificates
+	l vhostList   // virtual hosts with their tls config, nil to use the server one
+}
+
+// newVhostCerts return the tls configs of the server and of the virtual hosts of the given config.
+func newVhostCerts(cfg *Config) *vhostCerts {
+	var (
+		res = &vhostCerts{}
+		def libtls.TLSConfig
+	)
+
+	if s, e := cfg.GetTLS(); e == nil {
+		def = s
+	}
+
+	if def != nil && def.LenCertificatePair() > 0 {
+		res.d = def.TlsConfig("")
+	}
+
+	for _, v := range cfg.VirtualHosts {
+		var (
+			h = &vhost{
+				n: v.Name,
+				h: make([]string, 0, len(v.Hosts)),
+			}
+			t libtls.TLSConfig
+		)
+
+		for _, n := range v.Hosts {
+			h.h = append(h.h, vhostName(n))
+		}
+
+		if v.TLS.InheritDefault {
+			t = v.TLS.NewFrom(def)
+		} else {
+			t = v.TLS.NewFrom(nil)
+		}
+
+		if t != nil && t.LenCertificatePair() > 0 {
+			h.t = t.TlsConfig("")
+		}
+
+		res.l = append(res.l, h)
+	}
+
+	return res
+}
+
+// hasCerts return true if the server or one of the virtual hosts has certificates.
+func (c *vhostCerts) hasCerts() bool {
+	if c == nil {
+		return false
+	} else if c.d != nil {
+		return true
+	}
+
+	for _, v := range c.l {
+		if v.t != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// listener return a copy of the tls configs with the protocols of the given listener config
+// and with its minimal version only for a config without any: a minimal version set by a vhost is never lowered.
+func (c *vhostCerts) listener(b *tls.Config) *vhostCerts {
+	var (
+		res = &vhostCerts{
+			l: make(vhostList, 0, len(c.l)),
+		}
+		fct = func(t *tls.Config) *tls.Config {
+			if t == nil {
+				return nil
+			}
+
+			t = t.Clone()
+			t.NextProtos = append(make([]string, 0, len(b.NextProtos)), b.NextProtos...)
+
+			if t.MinVersion == 0 {
+				t.MinVersion = b.MinVersion
+			}
+
+			return t
+		}
+	)
+
+	res.d = fct(c.d)
+
+	for _, v := range c.l {
+		res.l = append(res.l, &vhost{n: v.n, h: v.h, t: fct(v.t)})
+	}
+
+	return res
+}
+
+// vhostHandler return a handler routing each request to the virtual host matching its Host header,
+// or to the given default handler. A request with a SNI and a Host header served by different
+// virtual hosts is rejected with the status 421 Misdirected Request.
+func (o *srv) vhostHandler(def http.Handler, l vhostList) http.Handler {
+	if len(l) < 1 {
+		return def
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var i = l.match(r.Host)
+
+		if r.TLS != nil && len(r.TLS.ServerName) > 0 && l.match(r.TLS.ServerName) != i {
+			http.Error(w, http.StatusText(http.StatusMisdirectedRequest), http.StatusMisdirectedRequest)
+			return
+		}
+
+		if i < 0 {
+			def.ServeHTTP(w, r)
+		} else {
+			l[i].r.ServeHTTP(w, r)
+		}
+	})
+}
+
+// vhostTLS select on each TLS handshake of the given listener config, the tls config of the virtual host
+// matching the SNI or else the tls config of the server, both from the last SetConfig.
+// It must be called after the http2 configuration and the tls migration to share their protocols and floor.
+func (o *srv) vhostTLS(b *tls.Config) {
+	if b == nil {
+		return
+	}
+
+	type cache struct {
+		s *vhostCerts // source snapshot
+		r *vhostCerts // listener configs
+	}
+
+	var (
+		bse = b.Clone()
+		cur = new(atomic.Pointer[cache])
+	)
+
+	b.GetConfigForClient = func(h *tls.ClientHelloInfo) (*tls.Config, error) {
+		var s = o.cfgGetCerts()
+
+		if s == nil {
+			return nil, nil
+		}
+
+		var c = cur.Load()
+
+		if c == nil || c.s != s {
+			c = &cache{s: s, r: s.listener(bse)}
+			cur.Store(c)
+		}
+
+		if i := c.r.l.match(h.ServerName); i >= 0 && c.r.l[i].t != nil {
+			return c.r.l[i].t, nil
+		}
+
+		return c.r.d, nil
+	}
+}
+
+// isTLSServer return true if the given server has certificates, its own or the ones of its virtual hosts.
+func isTLSServer(s *http.Server) bool {
+	if s == nil || s.TLSConfig == nil {
+		return false
+	}
+
+	return len(s.TLSConfig.Certificates) > 0 || s.TLSConfig.GetConfigForClient != nil
+}
+
+func (o *srv) vhostNames() []string {
+	var res = make([]string, 0)
+
+	if cfg := o.GetConfig(); cfg != nil {
+		for _, v := range cfg.VirtualHosts {
+			res = append(res, v.Name)
+		}
+	}
+
+	return res
+}
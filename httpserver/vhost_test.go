@@ -0,0 +1,337 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package httpserver_test
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"time"
+
+	libtls "github.com/nabbar/golib/certificates"
+	tlscrt "github.com/nabbar/golib/certificates/certs"
+	tlscpr "github.com/nabbar/golib/certificates/cipher"
+	tlsvrs "github.com/nabbar/golib/certificates/tlsversion"
+	libhts "github.com/nabbar/golib/httpserver"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// newCert generate an ephemeral self-signed certificate for the given name and return its config and its der.
+func newCert(name string) (tlscrt.Certif, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).ToNot(HaveOccurred())
+
+	tpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		DNSNames:              []string{name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, &key.PublicKey, key)
+	Expect(err).ToNot(HaveOccurred())
+
+	pkc, err := x509.MarshalECPrivateKey(key)
+	Expect(err).ToNot(HaveOccurred())
+
+	p, err := json.Marshal(&tlscrt.ConfigPair{
+		Key: string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: pkc})),
+		Pub: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+	})
+	Expect(err).ToNot(HaveOccurred())
+
+	var crt tlscrt.Certif
+	Expect(crt.UnmarshalJSON(p)).ToNot(HaveOccurred())
+
+	return crt, der
+}
+
+func newTLS(crt tlscrt.Certif) libtls.Config {
+	return libtls.Config{
+		Certs:      []tlscrt.Certif{crt},
+		VersionMin: tlsvrs.VersionTLS13,
+	}
+}
+
+func freeAddr() string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).ToNot(HaveOccurred())
+	defer func() {
+		_ = l.Close()
+	}()
+
+	return l.Addr().String()
+}
+
+// peerCert return the der of the certificate presented by the server for the given SNI.
+func peerCert(adr, sni string) []byte {
+	// #nosec
+	con, err := tls.Dial("tcp", adr, &tls.Config{
+		ServerName:         sni,
+		InsecureSkipVerify: true,
+		MinVersion:         tls.VersionTLS13,
+	})
+	Expect(err).ToNot(HaveOccurred())
+
+	defer func() {
+		_ = con.Close()
+	}()
+
+	Expect(con.Handshake()).ToNot(HaveOccurred())
+	return con.ConnectionState().PeerCertificates[0].Raw
+}
+
+// hostBody return the body of a https request with the given SNI and Host header.
+func hostBody(adr, sni string) string {
+	// #nosec
+	cli := &http.Client{
+		Timeout: 5 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				ServerName:         sni,
+				InsecureSkipVerify: true,
+				MinVersion:         tls.VersionTLS13,
+			},
+		},
+	}
+
+	defer cli.CloseIdleConnections()
+
+	req, err := http.NewRequest(http.MethodGet, "https://"+adr+"/", nil)
+	Expect(err).ToNot(HaveOccurred())
+	req.Host = sni
+
+	rsp, err := cli.Do(req)
+	Expect(err).ToNot(HaveOccurred())
+
+	defer func() {
+		_ = rsp.Body.Close()
+	}()
+
+	b, err := io.ReadAll(rsp.Body)
+	Expect(err).ToNot(HaveOccurred())
+
+	return string(b)
+}
+
+func bodyHandler(s string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(s))
+	})
+}
+
+var _ = Describe("httpserver virtual hosts", func() {
+	Context("using a tls server with a virtual host", func() {
+		var (
+			adr string
+			srv libhts.Server
+			cfg libhts.Config
+
+			srvCrt, srvDer = newCert("localhost")
+			vhsCrt, vhsDer = newCert("app.example.com")
+		)
+
+		BeforeEach(func() {
+			var err error
+
+			adr = freeAddr()
+			cfg = libhts.Config{
+				Name:       "vhost",
+				Listen:     adr,
+				Expose:     "https://" + adr,
+				HandlerKey: "default",
+				TLS:        newTLS(srvCrt),
+				VirtualHosts: []libhts.VirtualHost{
+					{
+						Name:       "app",
+						Hosts:      []string{"app.example.com"},
+						HandlerKey: "app",
+						TLS:        newTLS(vhsCrt),
+					},
+				},
+			}
+
+			cfg.RegisterHandlerFunc(func() map[string]http.Handler {
+				return map[string]http.Handler{
+					"default": bodyHandler("default"),
+					"app":     bodyHandler("app"),
+				}
+			})
+
+			srv, err = libhts.New(cfg, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(srv.Start(ctx)).ToNot(HaveOccurred())
+
+			Eventually(func() error {
+				c, e := net.Dial("tcp", adr)
+				if e == nil {
+					_ = c.Close()
+				}
+				return e
+			}, 5*time.Second, 50*time.Millisecond).ShouldNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			if srv != nil {
+				_ = srv.Stop(ctx)
+			}
+		})
+
+		It("must select the certificate and the handler by SNI and Host header", func() {
+			Expect(bytes.Equal(peerCert(adr, "localhost"), srvDer)).To(BeTrue())
+			Expect(bytes.Equal(peerCert(adr, "app.example.com"), vhsDer)).To(BeTrue())
+			Expect(hostBody(adr, "localhost")).To(Equal("default"))
+			Expect(hostBody(adr, "app.example.com")).To(Equal("app"))
+		})
+
+		It("must use the tls configs updated at runtime without restart", func() {
+			var (
+				newSrv, newSrvDer = newCert("localhost")
+				newVhs, newVhsDer = newCert("app.example.com")
+				upd               = cfg.Clone()
+			)
+
+			upd.TLS = newTLS(newSrv)
+			Expect(srv.SetConfig(upd, nil)).ToNot(HaveOccurred())
+
+			Expect(bytes.Equal(peerCert(adr, "localhost"), newSrvDer)).To(BeTrue())
+			Expect(bytes.Equal(peerCert(adr, "app.example.com"), vhsDer)).To(BeTrue())
+
+			upd.VirtualHosts[0].TLS = newTLS(newVhs)
+			Expect(srv.SetConfig(upd, nil)).ToNot(HaveOccurred())
+
+			Expect(bytes.Equal(peerCert(adr, "localhost"), newSrvDer)).To(BeTrue())
+			Expect(bytes.Equal(peerCert(adr, "app.example.com"), newVhsDer)).To(BeTrue())
+			Expect(hostBody(adr, "app.example.com")).To(Equal("app"))
+		})
+	})
+
+	Context("using a virtual host with a higher tls minimal version than the server", func() {
+		var (
+			adr string
+			srv libhts.Server
+
+			srvCrt, _ = newCert("localhost")
+			vhsCrt, _ = newCert("app.example.com")
+		)
+
+		BeforeEach(func() {
+			var err error
+
+			adr = freeAddr()
+			cfg := libhts.Config{
+				Name:       "vhost-min",
+				Listen:     adr,
+				Expose:     "https://" + adr,
+				HandlerKey: "default",
+				TLS: libtls.Config{
+					Certs:      []tlscrt.Certif{srvCrt},
+					CipherList: []tlscpr.Cipher{tlscpr.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256},
+					VersionMin: tlsvrs.VersionTLS12,
+				},
+				VirtualHosts: []libhts.VirtualHost{
+					{
+						Name:       "app",
+						Hosts:      []string{"app.example.com"},
+						HandlerKey: "app",
+						TLS: libtls.Config{
+							Certs:      []tlscrt.Certif{vhsCrt},
+							CipherList: []tlscpr.Cipher{tlscpr.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256},
+							VersionMin: tlsvrs.VersionTLS13,
+						},
+					},
+				},
+			}
+
+			cfg.RegisterHandlerFunc(func() map[string]http.Handler {
+				return map[string]http.Handler{
+					"default": bodyHandler("default"),
+					"app":     bodyHandler("app"),
+				}
+			})
+
+			srv, err = libhts.New(cfg, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(srv.Start(ctx)).ToNot(HaveOccurred())
+
+			Eventually(func() error {
+				c, e := net.Dial("tcp", adr)
+				if e == nil {
+					_ = c.Close()
+				}
+				return e
+			}, 5*time.Second, 50*time.Millisecond).ShouldNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			if srv != nil {
+				_ = srv.Stop(ctx)
+			}
+		})
+
+		It("must keep the minimal version of the virtual host", func() {
+			dial := func(sni string) error {
+				// #nosec
+				con, err := tls.Dial("tcp", adr, &tls.Config{
+					ServerName:         sni,
+					InsecureSkipVerify: true,
+					MinVersion:         tls.VersionTLS12,
+					MaxVersion:         tls.VersionTLS12,
+				})
+
+				if err != nil {
+					return err
+				}
+
+				defer func() {
+					_ = con.Close()
+				}()
+
+				return con.Handshake()
+			}
+
+			Expect(dial("localhost")).ToNot(HaveOccurred())
+			Expect(dial("app.example.com")).To(HaveOccurred())
+			Expect(hostBody(adr, "app.example.com")).To(Equal("app"))
+		})
+	})
+})
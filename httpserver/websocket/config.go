@@ -0,0 +1,134 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package websocket
+
+import (
+	"fmt"
+	"time"
+
+	libval "github.com/go-playground/validator/v10"
+	libdur "github.com/nabbar/golib/duration"
+	libsiz "github.com/nabbar/golib/size"
+)
+
+const (
+	DefaultReadLimit    = libsiz.SizeMega
+	DefaultSendBuffer   = 64
+	DefaultPingInterval = 30 * time.Second
+	DefaultWriteTimeout = 10 * time.Second
+	DefaultCloseTimeout = 5 * time.Second
+)
+
+type Config struct {
+	// ReadLimit is the max size of a message received from a client.
+	// A bigger message is reported as ErrMessageTooLarge and close the connection. Default is 1MB.
+	ReadLimit libsiz.Size `mapstructure:"read_limit" json:"read_limit" yaml:"read_limit" toml:"read_limit"`
+
+	// SendBuffer is the number of messages waiting to be written for each connection.
+	// When the buffer is full, Send return ErrSendBufferFull. Default is 64.
+	SendBuffer int `mapstructure:"send_buffer" json:"send_buffer" yaml:"send_buffer" toml:"send_buffer" validate:"gte=0"`
+
+	// PingInterval is the delay between two ping sent to the client. Default is 30 seconds.
+	PingInterval libdur.Duration `mapstructure:"ping_interval" json:"ping_interval" yaml:"ping_interval" toml:"ping_interval"`
+
+	// PongTimeout is the max delay without any frame received from the client (pong or message)
+	// before the connection is closed. Default is twice the ping interval.
+	PongTimeout libdur.Duration `mapstructure:"pong_timeout" json:"pong_timeout" yaml:"pong_timeout" toml:"pong_timeout"`
+
+	// WriteTimeout is the max duration of one write. Default is 10 seconds.
+	WriteTimeout libdur.Duration `mapstructure:"write_timeout" json:"write_timeout" yaml:"write_timeout" toml:"write_timeout"`
+
+	// CloseTimeout is the max duration given to a connection to end after the close frame is sent. Default is 5 seconds.
+	CloseTimeout libdur.Duration `mapstructure:"close_timeout" json:"close_timeout" yaml:"close_timeout" toml:"close_timeout"`
+
+	// Origins is the list of allowed origin host, as path.Match patterns (ex: "*.example.com").
+	// If empty, only request without origin or with an origin matching the request host are allowed.
+	Origins []string `mapstructure:"origins" json:"origins" yaml:"origins" toml:"origins"`
+}
+
+func (c Config) Validate() error {
+	if er := libval.New().Struct(c); er != nil {
+		if e, ok := er.(*libval.InvalidValidationError); ok {
+			return e
+		}
+
+		for _, e := range er.(libval.ValidationErrors) {
+			//nolint goerr113
+			return fmt.Errorf("config field '%s' is not validated by constraint '%s'", e.Namespace(), e.ActualTag())
+		}
+	}
+
+	return nil
+}
+
+func (c Config) getReadLimit() int64 {
+	if c.ReadLimit > 0 {
+		return c.ReadLimit.Int64()
+	}
+
+	return DefaultReadLimit.Int64()
+}
+
+func (c Config) getSendBuffer() int {
+	if c.SendBuffer > 0 {
+		return c.SendBuffer
+	}
+
+	return DefaultSendBuffer
+}
+
+func (c Config) getPingInterval() time.Duration {
+	if c.PingInterval > 0 {
+		return c.PingInterval.Time()
+	}
+
+	return DefaultPingInterval
+}
+
+func (c Config) getPongTimeout() time.Duration {
+	if c.PongTimeout > 0 {
+		return c.PongTimeout.Time()
+	}
+
+	return 2 * c.getPingInterval()
+}
+
+func (c Config) getWriteTimeout() time.Duration {
+	if c.WriteTimeout > 0 {
+		return c.WriteTimeout.Time()
+	}
+
+	return DefaultWriteTimeout
+}
+
+func (c Config) getCloseTimeout() time.Duration {
+	if c.CloseTimeout > 0 {
+		return c.CloseTimeout.Time()
+	}
+
+	return DefaultCloseTimeout
+}
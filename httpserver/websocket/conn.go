@@ -0,0 +1,241 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package websocket
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	xnetws "golang.org/x/net/websocket"
+)
+
+type conn struct {
+	i uint64
+	s *srv
+	w *xnetws.Conn
+	x context.Context
+	n context.CancelFunc
+	q chan Message
+	d chan struct{} // closed when the write pump is ended
+}
+
+func newConn(s *srv, id uint64, ws *xnetws.Conn) *conn {
+	x, n := context.WithCancel(s.x)
+
+	return &conn{
+		i: id,
+		s: s,
+		w: ws,
+		x: x,
+		n: n,
+		q: make(chan Message, s.g.getSendBuffer()),
+		d: make(chan struct{}),
+	}
+}
+
+func (c *conn) ID() uint64 {
+	return c.i
+}
+
+func (c *conn) Context() context.Context {
+	return c.x
+}
+
+func (c *conn) Request() *http.Request {
+	return c.w.Request()
+}
+
+func (c *conn) RemoteAddr() net.Addr {
+	if r := c.w.Request(); r != nil {
+		if a, e := net.ResolveTCPAddr("tcp", r.RemoteAddr); e == nil {
+			return a
+		}
+	}
+
+	return c.w.RemoteAddr()
+}
+
+func (c *conn) Send(msg Message) error {
+	if c.x.Err() != nil {
+		return ErrConnClosed
+	}
+
+	select {
+	case c.q <- msg:
+		return nil
+	case <-c.x.Done():
+		return ErrConnClosed
+	default:
+		return ErrSendBufferFull
+	}
+}
+
+func (c *conn) SendText(s string) error {
+	return c.Send(Message{
+		Type: MessageText,
+		Data: []byte(s),
+	})
+}
+
+func (c *conn) SendBinary(p []byte) error {
+	return c.Send(Message{
+		Type: MessageBinary,
+		Data: p,
+	})
+}
+
+func (c *conn) Close() error {
+	c.n()
+	return nil
+}
+
+func (c *conn) Done() <-chan struct{} {
+	return c.d
+}
+
+func (c *conn) write(typ byte, p []byte) error {
+	_ = c.w.SetWriteDeadline(time.Now().Add(c.s.g.getWriteTimeout()))
+
+	// the write pump is the only writer, the payload type can be changed safely
+	c.w.PayloadType = typ
+	_, e := c.w.Write(p)
+
+	return e
+}
+
+func (c *conn) writeMessage(msg Message) error {
+	if msg.Type == MessageBinary {
+		return c.write(xnetws.BinaryFrame, msg.Data)
+	}
+
+	return c.write(xnetws.TextFrame, msg.Data)
+}
+
+func (c *conn) writePump() {
+	var t = time.NewTicker(c.s.g.getPingInterval())
+
+	defer func() {
+		t.Stop()
+
+		// flushing the pending messages before sending the close frame
+		for {
+			select {
+			case m := <-c.q:
+				if c.writeMessage(m) == nil {
+					continue
+				}
+			default:
+			}
+			break
+		}
+
+		_ = c.w.SetWriteDeadline(time.Now().Add(c.s.g.getCloseTimeout()))
+		_ = c.w.Close()
+
+		close(c.d)
+	}()
+
+	for {
+		select {
+		case <-c.x.Done():
+			return
+		case m := <-c.q:
+			if e := c.writeMessage(m); e != nil {
+				c.s.onError(c, e)
+				c.n()
+				return
+			}
+		case <-t.C:
+			if e := c.write(xnetws.PingFrame, nil); e != nil {
+				c.s.onError(c, e)
+				c.n()
+				return
+			}
+		}
+	}
+}
+
+func (c *conn) readPump() {
+	var (
+		lim = c.s.g.getReadLimit()
+		tmo = c.s.g.getPongTimeout()
+	)
+
+	_ = c.w.SetReadDeadline(time.Now().Add(tmo))
+
+	for {
+		f, e := c.w.NewFrameReader()
+
+		if e != nil {
+			c.readError(e)
+			return
+		}
+
+		// any frame received, including pong, keep the connection alive
+		_ = c.w.SetReadDeadline(time.Now().Add(tmo))
+
+		r, e := c.w.HandleFrame(f)
+
+		if e != nil {
+			c.readError(e)
+			return
+		} else if r == nil {
+			// control frame already processed
+			continue
+		}
+
+		p, e := io.ReadAll(io.LimitReader(r, lim+1))
+
+		if e != nil {
+			c.readError(e)
+			return
+		} else if int64(len(p)) > lim {
+			c.s.onError(c, ErrMessageTooLarge)
+			return
+		} else if t := r.TrailerReader(); t != nil {
+			_, _ = io.Copy(io.Discard, t)
+		}
+
+		if r.PayloadType() == xnetws.BinaryFrame {
+			c.s.onMessage(c, Message{Type: MessageBinary, Data: p})
+		} else {
+			c.s.onMessage(c, Message{Type: MessageText, Data: p})
+		}
+	}
+}
+
+func (c *conn) readError(e error) {
+	if c.x.Err() != nil || errors.Is(e, io.EOF) || errors.Is(e, net.ErrClosed) {
+		return
+	}
+
+	c.s.onError(c, e)
+}
@@ -0,0 +1,133 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package websocket
+
+import (
+	"io"
+	"sync"
+
+	sckmlt "github.com/nabbar/golib/socket/multi"
+)
+
+type hub struct {
+	m sync.Mutex
+	t MessageType
+	w sckmlt.Multi
+	c map[uint64]Conn
+}
+
+// hubWriter send each write as a message to the connection.
+// It never returns an error: a slow or closed connection must not stop the broadcast to others.
+type hubWriter struct {
+	t MessageType
+	c Conn
+}
+
+func (w hubWriter) Write(p []byte) (n int, err error) {
+	var b = make([]byte, len(p))
+	copy(b, p)
+
+	_ = w.c.Send(Message{
+		Type: w.t,
+		Data: b,
+	})
+
+	return len(p), nil
+}
+
+func (o *hub) rebuild() {
+	var l = make([]io.Writer, 0, len(o.c))
+
+	for _, c := range o.c {
+		l = append(l, hubWriter{t: o.t, c: c})
+	}
+
+	o.w.Clean()
+	o.w.AddWriter(l...)
+}
+
+func (o *hub) Join(c Conn) {
+	if c == nil {
+		return
+	}
+
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if _, k := o.c[c.ID()]; k {
+		return
+	}
+
+	o.c[c.ID()] = c
+	o.rebuild()
+
+	go func() {
+		<-c.Done()
+		o.Leave(c)
+	}()
+}
+
+func (o *hub) Leave(c Conn) {
+	if c == nil {
+		return
+	}
+
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if _, k := o.c[c.ID()]; !k {
+		return
+	}
+
+	delete(o.c, c.ID())
+	o.rebuild()
+}
+
+func (o *hub) Len() int {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	return len(o.c)
+}
+
+func (o *hub) Write(p []byte) (n int, err error) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	return o.w.Write(p)
+}
+
+func (o *hub) WriteString(s string) (n int, err error) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	return o.w.WriteString(s)
+}
+
+func (o *hub) Broadcast(p []byte) {
+	_, _ = o.Write(p)
+}
@@ -0,0 +1,208 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package websocket
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	libctx "github.com/nabbar/golib/context"
+	libevt "github.com/nabbar/golib/events"
+	sckmlt "github.com/nabbar/golib/socket/multi"
+)
+
+type MessageType uint8
+
+const (
+	MessageText MessageType = iota + 1
+	MessageBinary
+)
+
+func (t MessageType) String() string {
+	switch t {
+	case MessageText:
+		return "text"
+	case MessageBinary:
+		return "binary"
+	default:
+		return "unknown"
+	}
+}
+
+// Message is a complete websocket data frame, received or to be sent.
+type Message struct {
+	Type MessageType
+	Data []byte
+}
+
+// FuncConn is called on connection events (connect, close).
+type FuncConn func(c Conn)
+
+// FuncMessage is called for each message received from a client.
+// The calls are sequential for one connection.
+type FuncMessage func(c Conn, msg Message)
+
+// FuncError is called for each error on a connection, the connection can be nil.
+type FuncError func(c Conn, err error)
+
+type Conn interface {
+	// ID return the unique id of the connection into its server.
+	ID() uint64
+
+	// Context return the context of the connection,
+	// canceled when the connection is closed or when the server is shut down.
+	Context() context.Context
+
+	// Request return the http request used to upgrade the connection.
+	Request() *http.Request
+
+	// RemoteAddr return the address of the client.
+	RemoteAddr() net.Addr
+
+	// Send queue the message to be written by the write pump.
+	// It returns ErrSendBufferFull if the send buffer is full and ErrConnClosed if the connection is closed.
+	Send(msg Message) error
+
+	// SendText is a helper to send a text message.
+	SendText(s string) error
+
+	// SendBinary is a helper to send a binary message.
+	SendBinary(p []byte) error
+
+	// Close send a close frame to the client and close the connection.
+	Close() error
+
+	// Done return a channel closed when the connection is closed.
+	Done() <-chan struct{}
+}
+
+type Server interface {
+	http.Handler
+
+	// OnConnect register a function called after each successful upgrade.
+	OnConnect(fct FuncConn)
+
+	// OnMessage register a function called for each message received.
+	OnMessage(fct FuncMessage)
+
+	// OnClose register a function called when a connection is closed.
+	OnClose(fct FuncConn)
+
+	// OnError register a function called for each error on a connection.
+	OnError(fct FuncError)
+
+	// Len return the number of open connections.
+	Len() int
+
+	// Walk call the given function for each open connection until it returns false.
+	Walk(fct FuncConn)
+
+	// Broadcast send the message to all open connections.
+	// Connections with a full send buffer miss the message.
+	Broadcast(msg Message)
+
+	// Attach subscribe to the state events of the httpserver published on the bus,
+	// to shutdown the websocket server when the http server is stopping.
+	// If bind is not empty, only the events of the http server listening on this address are used.
+	Attach(ctx context.Context, bus libevt.Bus, bind string) (libevt.Subscription, error)
+
+	// Shutdown refuse any new connection, close all open connections with a close frame
+	// and wait for them to end until the context is done.
+	Shutdown(ctx context.Context) error
+
+	// IsClosed return true if Shutdown has been called.
+	IsClosed() bool
+}
+
+type Hub interface {
+	io.Writer
+	io.StringWriter
+
+	// Join add the connection to the hub. The connection leaves the hub automatically when closed.
+	Join(c Conn)
+
+	// Leave remove the connection from the hub.
+	Leave(c Conn)
+
+	// Len return the number of connections into the hub.
+	Len() int
+
+	// Broadcast send the data to all connections of the hub, with the message type of the hub.
+	Broadcast(p []byte)
+}
+
+// New return a new websocket server, to be registered as an http.Handler on the upgrade route.
+func New(ctx libctx.FuncContext, cfg Config) (Server, error) {
+	if e := cfg.Validate(); e != nil {
+		return nil, e
+	}
+
+	var x context.Context
+
+	if ctx != nil {
+		x = ctx()
+	}
+
+	if x == nil {
+		x = context.Background()
+	}
+
+	x, n := context.WithCancel(x)
+
+	return &srv{
+		m: sync.RWMutex{},
+		x: x,
+		n: n,
+		g: cfg,
+		i: new(atomic.Uint64),
+		c: new(atomic.Bool),
+		l: make(map[uint64]*conn),
+		w: sync.WaitGroup{},
+	}, nil
+}
+
+// NewHub return a new broadcast hub sending messages of the given type.
+// The hub is built on a socket/multi writer: each write is sent to all joined connections.
+func NewHub(typ MessageType) Hub {
+	if typ != MessageBinary {
+		typ = MessageText
+	}
+
+	m := sckmlt.New()
+	m.Clean()
+
+	return &hub{
+		m: sync.Mutex{},
+		t: typ,
+		w: m,
+		c: make(map[uint64]Conn),
+	}
+}
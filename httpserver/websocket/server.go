@@ -0,0 +1,295 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package websocket
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	libevt "github.com/nabbar/golib/events"
+	libhtp "github.com/nabbar/golib/httpserver"
+	xnetws "golang.org/x/net/websocket"
+)
+
+type srv struct {
+	m sync.RWMutex
+	x context.Context    // server context
+	n context.CancelFunc // server context cancel
+	g Config
+	i *atomic.Uint64 // last connection id
+	c *atomic.Bool   // is closed
+	l map[uint64]*conn
+	w sync.WaitGroup
+
+	fc FuncConn    // on connect
+	fm FuncMessage // on message
+	fx FuncConn    // on close
+	fe FuncError   // on error
+}
+
+func (o *srv) OnConnect(fct FuncConn) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	o.fc = fct
+}
+
+func (o *srv) OnMessage(fct FuncMessage) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	o.fm = fct
+}
+
+func (o *srv) OnClose(fct FuncConn) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	o.fx = fct
+}
+
+func (o *srv) OnError(fct FuncError) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	o.fe = fct
+}
+
+func (o *srv) onConnect(c Conn) {
+	o.m.RLock()
+	f := o.fc
+	o.m.RUnlock()
+
+	if f != nil {
+		f(c)
+	}
+}
+
+func (o *srv) onMessage(c Conn, msg Message) {
+	o.m.RLock()
+	f := o.fm
+	o.m.RUnlock()
+
+	if f != nil {
+		f(c, msg)
+	}
+}
+
+func (o *srv) onClose(c Conn) {
+	o.m.RLock()
+	f := o.fx
+	o.m.RUnlock()
+
+	if f != nil {
+		f(c)
+	}
+}
+
+func (o *srv) onError(c Conn, err error) {
+	o.m.RLock()
+	f := o.fe
+	o.m.RUnlock()
+
+	if f != nil && err != nil {
+		f(c, err)
+	}
+}
+
+func (o *srv) Len() int {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	return len(o.l)
+}
+
+func (o *srv) Walk(fct FuncConn) {
+	if fct == nil {
+		return
+	}
+
+	o.m.RLock()
+	var lst = make([]*conn, 0, len(o.l))
+	for _, c := range o.l {
+		lst = append(lst, c)
+	}
+	o.m.RUnlock()
+
+	for _, c := range lst {
+		fct(c)
+	}
+}
+
+func (o *srv) Broadcast(msg Message) {
+	o.Walk(func(c Conn) {
+		_ = c.Send(msg)
+	})
+}
+
+func (o *srv) IsClosed() bool {
+	return o.c.Load()
+}
+
+func (o *srv) Shutdown(ctx context.Context) error {
+	if o == nil {
+		return ErrInvalidInstance
+	}
+
+	o.m.Lock()
+	o.c.Store(true)
+	o.n()
+	o.m.Unlock()
+
+	var d = make(chan struct{})
+
+	go func() {
+		o.w.Wait()
+		close(d)
+	}()
+
+	select {
+	case <-d:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (o *srv) Attach(ctx context.Context, bus libevt.Bus, bind string) (libevt.Subscription, error) {
+	if o == nil {
+		return nil, ErrInvalidInstance
+	}
+
+	return bus.Subscribe(ctx, libhtp.EventTopicState, 1, func(_ context.Context, _ string, evt any) {
+		if e, k := evt.(libhtp.EventState); !k || e.State != libhtp.StateDraining {
+			return
+		} else if len(bind) > 0 && e.Bind != bind {
+			return
+		}
+
+		x, n := context.WithTimeout(context.Background(), o.g.getCloseTimeout())
+		defer n()
+
+		_ = o.Shutdown(x)
+	})
+}
+
+func (o *srv) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if o.IsClosed() {
+		http.Error(w, ErrServerClosed.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	s := xnetws.Server{
+		Handshake: o.handshake,
+		Handler:   o.handle,
+	}
+
+	s.ServeHTTP(w, r)
+}
+
+func (o *srv) handshake(cfg *xnetws.Config, r *http.Request) error {
+	var err error
+
+	if cfg.Origin, err = xnetws.Origin(cfg, r); err != nil {
+		return err
+	} else if cfg.Origin == nil {
+		// not a browser, no origin to check
+		return nil
+	}
+
+	var host = strings.ToLower(cfg.Origin.Host)
+
+	if len(o.g.Origins) < 1 {
+		if strings.EqualFold(host, r.Host) {
+			return nil
+		}
+		return ErrOriginRefused
+	}
+
+	for _, p := range o.g.Origins {
+		if ok, e := path.Match(strings.ToLower(p), host); e == nil && ok {
+			return nil
+		}
+	}
+
+	return ErrOriginRefused
+}
+
+func (o *srv) register(ws *xnetws.Conn) *conn {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if o.c.Load() {
+		return nil
+	}
+
+	c := newConn(o, o.i.Add(1), ws)
+	o.l[c.i] = c
+	o.w.Add(1)
+
+	return c
+}
+
+func (o *srv) unregister(c *conn) {
+	o.m.Lock()
+	delete(o.l, c.i)
+	o.m.Unlock()
+}
+
+func (o *srv) handle(ws *xnetws.Conn) {
+	var c = o.register(ws)
+
+	if c == nil {
+		_ = ws.Close()
+		return
+	}
+
+	// the close callback is done before releasing the wait group, so Shutdown return after all callbacks
+	defer func() {
+		o.unregister(c)
+		o.onClose(c)
+		o.w.Done()
+	}()
+
+	go c.writePump()
+
+	// close the connection as soon as the server is draining
+	go func(d <-chan struct{}) {
+		select {
+		case <-d:
+			c.n()
+		case <-c.x.Done():
+		}
+	}(libhtp.DrainFromContext(ws.Request().Context()))
+
+	o.onConnect(c)
+	c.readPump()
+
+	// ending the write pump, who send the close frame and close the connection
+	c.n()
+	<-c.d
+}
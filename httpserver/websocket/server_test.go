@@ -0,0 +1,251 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package websocket_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	libevt "github.com/nabbar/golib/events"
+	libhtp "github.com/nabbar/golib/httpserver"
+	libws "github.com/nabbar/golib/httpserver/websocket"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	xnetws "golang.org/x/net/websocket"
+)
+
+// dial open a websocket client connection on the test server, with an origin matching the server host.
+func dial(h *httptest.Server, origin string) (*xnetws.Conn, error) {
+	if len(origin) < 1 {
+		origin = h.URL
+	}
+
+	return xnetws.Dial("ws"+strings.TrimPrefix(h.URL, "http"), "", origin)
+}
+
+func receive(ws *xnetws.Conn) (string, error) {
+	var s string
+
+	_ = ws.SetReadDeadline(time.Now().Add(2 * time.Second))
+	e := xnetws.Message.Receive(ws, &s)
+
+	return s, e
+}
+
+var _ = Describe("Websocket Server", func() {
+	var (
+		srv libws.Server
+		htp *httptest.Server
+		onc *atomic.Int32
+		onx *atomic.Int32
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		srv, err = libws.New(func() context.Context { return ctx }, libws.Config{})
+		Expect(err).ToNot(HaveOccurred())
+
+		onc = new(atomic.Int32)
+		onx = new(atomic.Int32)
+
+		srv.OnConnect(func(c libws.Conn) {
+			onc.Add(1)
+		})
+		srv.OnClose(func(c libws.Conn) {
+			onx.Add(1)
+		})
+		srv.OnMessage(func(c libws.Conn, msg libws.Message) {
+			_ = c.SendText("echo: " + string(msg.Data))
+		})
+
+		htp = httptest.NewServer(srv)
+	})
+
+	AfterEach(func() {
+		x, n := context.WithTimeout(ctx, 5*time.Second)
+		defer n()
+
+		_ = srv.Shutdown(x)
+		htp.Close()
+	})
+
+	Context("serving a client", func() {
+		It("must echo the messages and count the connection", func() {
+			ws, err := dial(htp, "")
+			Expect(err).ToNot(HaveOccurred())
+			defer func() {
+				_ = ws.Close()
+			}()
+
+			Eventually(srv.Len).Should(Equal(1))
+			Eventually(onc.Load).Should(Equal(int32(1)))
+
+			for _, m := range []string{"hello", "world"} {
+				Expect(xnetws.Message.Send(ws, m)).To(Succeed())
+
+				s, e := receive(ws)
+				Expect(e).ToNot(HaveOccurred())
+				Expect(s).To(Equal("echo: " + m))
+			}
+		})
+
+		It("must broadcast a message to all connections", func() {
+			var lst = make([]*xnetws.Conn, 0, 3)
+
+			defer func() {
+				for _, ws := range lst {
+					_ = ws.Close()
+				}
+			}()
+
+			for i := 0; i < 3; i++ {
+				ws, err := dial(htp, "")
+				Expect(err).ToNot(HaveOccurred())
+				lst = append(lst, ws)
+			}
+
+			Eventually(srv.Len).Should(Equal(3))
+			srv.Broadcast(libws.Message{Type: libws.MessageText, Data: []byte("news")})
+
+			for _, ws := range lst {
+				s, e := receive(ws)
+				Expect(e).ToNot(HaveOccurred())
+				Expect(s).To(Equal("news"))
+			}
+		})
+
+		It("must refuse an origin not matching the request host", func() {
+			_, err := dial(htp, "http://evil.example.com")
+			Expect(err).To(HaveOccurred())
+			Expect(srv.Len()).To(Equal(0))
+			Expect(onc.Load()).To(Equal(int32(0)))
+		})
+	})
+
+	Context("when the client disconnect", func() {
+		It("must end the connection and call the close callback", func() {
+			var cnx = make(chan libws.Conn, 1)
+
+			srv.OnConnect(func(c libws.Conn) {
+				cnx <- c
+			})
+
+			ws, err := dial(htp, "")
+			Expect(err).ToNot(HaveOccurred())
+
+			var c libws.Conn
+			Eventually(cnx).Should(Receive(&c))
+			Expect(srv.Len()).To(Equal(1))
+
+			Expect(ws.Close()).To(Succeed())
+
+			Eventually(c.Done(), 2*time.Second).Should(BeClosed())
+			Eventually(srv.Len).Should(Equal(0))
+			Eventually(onx.Load).Should(Equal(int32(1)))
+
+			Expect(c.Context().Err()).To(HaveOccurred())
+			Expect(c.SendText("too late")).To(MatchError(libws.ErrConnClosed))
+		})
+	})
+
+	Context("stopping the server", func() {
+		It("must close the connections on shutdown and refuse new ones", func() {
+			// a slow close callback must be ended before Shutdown return
+			srv.OnClose(func(c libws.Conn) {
+				time.Sleep(200 * time.Millisecond)
+				onx.Add(1)
+			})
+
+			ws, err := dial(htp, "")
+			Expect(err).ToNot(HaveOccurred())
+			defer func() {
+				_ = ws.Close()
+			}()
+
+			Eventually(srv.Len).Should(Equal(1))
+
+			x, n := context.WithTimeout(ctx, 5*time.Second)
+			defer n()
+
+			var t = time.Now()
+			Expect(srv.Shutdown(x)).To(Succeed())
+			Expect(time.Since(t)).To(BeNumerically("<", 2*time.Second))
+
+			Expect(srv.IsClosed()).To(BeTrue())
+			Expect(srv.Len()).To(Equal(0))
+			Expect(onx.Load()).To(Equal(int32(1)))
+
+			_, e := receive(ws)
+			Expect(e).To(MatchError(io.EOF))
+
+			_, err = dial(htp, "")
+			Expect(err).To(HaveOccurred())
+
+			r, e := http.Get(htp.URL)
+			Expect(e).ToNot(HaveOccurred())
+			Expect(r.StatusCode).To(Equal(http.StatusServiceUnavailable))
+			_ = r.Body.Close()
+		})
+
+		It("must shutdown when the attached http server start draining", func() {
+			var bus = libevt.New(ctx)
+			defer func() {
+				_ = bus.Close()
+			}()
+
+			sub, err := srv.Attach(ctx, bus, "127.0.0.1:8080")
+			Expect(err).ToNot(HaveOccurred())
+			defer sub.Unsubscribe()
+
+			ws, err := dial(htp, "")
+			Expect(err).ToNot(HaveOccurred())
+			defer func() {
+				_ = ws.Close()
+			}()
+
+			Eventually(srv.Len).Should(Equal(1))
+
+			// another bind or another state must be ignored
+			Expect(bus.Publish(ctx, libhtp.EventTopicState, libhtp.EventState{Bind: "127.0.0.1:9090", State: libhtp.StateDraining})).To(Succeed())
+			Expect(bus.Publish(ctx, libhtp.EventTopicState, libhtp.EventState{Bind: "127.0.0.1:8080", State: libhtp.StateRunning})).To(Succeed())
+			Consistently(srv.IsClosed, 300*time.Millisecond).Should(BeFalse())
+			Expect(srv.Len()).To(Equal(1))
+
+			Expect(bus.Publish(ctx, libhtp.EventTopicState, libhtp.EventState{Bind: "127.0.0.1:8080", State: libhtp.StateDraining})).To(Succeed())
+			Eventually(srv.IsClosed).Should(BeTrue())
+			Eventually(srv.Len).Should(Equal(0))
+
+			_, e := receive(ws)
+			Expect(e).To(MatchError(io.EOF))
+		})
+	})
+})
@@ -0,0 +1,194 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package bufferWriter_test
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"time"
+
+	iotbfw "github.com/nabbar/golib/ioutils/bufferWriter"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// dest is a thread-safe destination counting the writes and failing on demand.
+type dest struct {
+	m sync.Mutex
+	b bytes.Buffer
+	n int
+	e error
+	c bool
+}
+
+func (d *dest) Write(p []byte) (int, error) {
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	if d.e != nil {
+		return 0, d.e
+	}
+
+	d.n++
+	return d.b.Write(p)
+}
+
+func (d *dest) Close() error {
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	d.c = true
+	return nil
+}
+
+func (d *dest) String() string {
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	return d.b.String()
+}
+
+func (d *dest) Count() int {
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	return d.n
+}
+
+func (d *dest) Fail(e error) {
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	d.e = e
+}
+
+var _ = Describe("ioutils/bufferWriter", func() {
+	It("must buffer the small writes until the buffer is full", func() {
+		var d = &dest{}
+
+		w, e := iotbfw.New(d, iotbfw.Options{Size: 10, MaxAge: -1})
+		Expect(e).ToNot(HaveOccurred())
+
+		for i := 0; i < 3; i++ {
+			_, e = w.Write([]byte("abcd"))
+			Expect(e).ToNot(HaveOccurred())
+		}
+
+		Expect(d.Count()).To(Equal(1))
+		Expect(d.String()).To(Equal("abcdabcd"))
+		Expect(w.Buffered()).To(Equal(4))
+
+		_, e = w.Write([]byte("a big write of more than the size"))
+		Expect(e).ToNot(HaveOccurred())
+		Expect(d.String()).To(Equal("abcdabcdabcda big write of more than the size"))
+		Expect(w.Buffered()).To(Equal(0))
+
+		Expect(w.Close()).ToNot(HaveOccurred())
+		Expect(d.c).To(BeTrue())
+
+		_, e = w.Write([]byte("closed"))
+		Expect(e).To(MatchError(iotbfw.ErrClosed))
+	})
+
+	It("must flush the buffered data on the max age deadline", func() {
+		var d = &dest{}
+
+		w, e := iotbfw.New(d, iotbfw.Options{MaxAge: 50 * time.Millisecond})
+		Expect(e).ToNot(HaveOccurred())
+
+		_, e = w.Write([]byte("data"))
+		Expect(e).ToNot(HaveOccurred())
+		Expect(d.String()).To(BeEmpty())
+
+		Eventually(d.String, time.Second).Should(Equal("data"))
+		Expect(w.Buffered()).To(Equal(0))
+		Expect(w.Close()).ToNot(HaveOccurred())
+	})
+
+	It("must report the background flush errors and keep the data", func() {
+		var (
+			d = &dest{}
+			m sync.Mutex
+			l = make([]error, 0)
+			x = errors.New("write failed")
+		)
+
+		d.Fail(x)
+
+		w, e := iotbfw.New(d, iotbfw.Options{
+			MaxAge: 20 * time.Millisecond,
+			OnFlushError: func(err error) {
+				m.Lock()
+				defer m.Unlock()
+				l = append(l, err)
+			},
+		})
+		Expect(e).ToNot(HaveOccurred())
+
+		_, e = w.Write([]byte("data"))
+		Expect(e).ToNot(HaveOccurred())
+
+		Eventually(func() int {
+			m.Lock()
+			defer m.Unlock()
+			return len(l)
+		}, time.Second).Should(BeNumerically(">=", 1))
+
+		Expect(w.Buffered()).To(Equal(4))
+		Expect(w.Flush()).To(MatchError(x))
+
+		d.Fail(nil)
+
+		Eventually(d.String, time.Second).Should(Equal("data"))
+		Expect(w.Close()).ToNot(HaveOccurred())
+	})
+
+	It("must be safe for concurrent writes", func() {
+		var (
+			d = &dest{}
+			g sync.WaitGroup
+		)
+
+		w, e := iotbfw.New(d, iotbfw.Options{Size: 64})
+		Expect(e).ToNot(HaveOccurred())
+
+		for i := 0; i < 10; i++ {
+			g.Add(1)
+			go func() {
+				defer g.Done()
+				for j := 0; j < 100; j++ {
+					_, _ = w.Write([]byte("0123456789"))
+				}
+			}()
+		}
+
+		g.Wait()
+		Expect(w.Close()).ToNot(HaveOccurred())
+		Expect(d.String()).To(HaveLen(10 * 100 * 10))
+	})
+})
@@ -0,0 +1,94 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package bufferWriter
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultSize is the default size of the buffer flushed when full.
+	DefaultSize = 32 * 1024
+	// DefaultMaxAge is the default max age of the buffered data before a flush.
+	DefaultMaxAge = time.Second
+)
+
+// FuncFlushError is called with the error of a flush made in background on the max age deadline.
+// The errors of a flush made by Write, Flush or Close are returned to the caller.
+type FuncFlushError func(err error)
+
+type Options struct {
+	// Size is the size of the buffer: the buffered data are flushed before exceeding it,
+	// and a bigger write is given directly to the underlying writer. Default is DefaultSize.
+	Size int
+
+	// MaxAge is the max age of the oldest buffered data: a flush is made in background when reached.
+	// A negative value disable the deadline flush. Default is DefaultMaxAge.
+	MaxAge time.Duration
+
+	// OnFlushError if defined is called with the errors of the background flushes.
+	// The data not written are kept into the buffer for the next flush.
+	OnFlushError FuncFlushError
+}
+
+// Writer is a thread-safe buffered writer flushed when its buffer is full or when its oldest data
+// reach the max age.
+type Writer interface {
+	// Write buffer the given data, flushing the buffer first if the data do not fit into it.
+	// Close flush the buffer and close the underlying writer if it is an io.Closer.
+	io.WriteCloser
+
+	// Flush write immediately all the buffered data to the underlying writer.
+	Flush() error
+
+	// Buffered return the size of the buffered data not yet written.
+	Buffered() int
+}
+
+// New return a buffered writer on the given writer with the given options.
+func New(w io.Writer, opt Options) (Writer, error) {
+	if w == nil {
+		return nil, ErrInvalidWriter
+	}
+
+	if opt.Size <= 0 {
+		opt.Size = DefaultSize
+	}
+
+	if opt.MaxAge == 0 {
+		opt.MaxAge = DefaultMaxAge
+	}
+
+	return &bfw{
+		m: sync.Mutex{},
+		w: w,
+		o: opt,
+		b: make([]byte, 0, opt.Size),
+	}, nil
+}
@@ -0,0 +1,170 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package bufferWriter
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+type bfw struct {
+	m sync.Mutex
+	w io.Writer   // underlying writer
+	o Options     // options
+	b []byte      // buffered data
+	t *time.Timer // max age deadline, armed while the buffer is not empty
+	c bool        // closed
+}
+
+func (o *bfw) Write(p []byte) (int, error) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if o.c {
+		return 0, ErrClosed
+	} else if len(p) < 1 {
+		return 0, nil
+	}
+
+	if len(o.b)+len(p) > o.o.Size {
+		if e := o.flush(); e != nil {
+			return 0, e
+		}
+	}
+
+	if len(p) >= o.o.Size {
+		// too big to be buffered
+		return o.w.Write(p)
+	}
+
+	if len(o.b) < 1 {
+		o.arm()
+	}
+
+	o.b = append(o.b, p...)
+
+	return len(p), nil
+}
+
+func (o *bfw) Flush() error {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if o.c {
+		return ErrClosed
+	}
+
+	return o.flush()
+}
+
+func (o *bfw) Buffered() int {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	return len(o.b)
+}
+
+func (o *bfw) Close() error {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if o.c {
+		return ErrClosed
+	}
+
+	o.c = true
+	e := o.flush()
+
+	if c, k := o.w.(io.Closer); k {
+		if err := c.Close(); e == nil {
+			e = err
+		}
+	}
+
+	return e
+}
+
+// arm start the max age deadline of the buffered data. Must be called with the lock held.
+func (o *bfw) arm() {
+	if o.o.MaxAge < 0 {
+		return
+	} else if o.t == nil {
+		o.t = time.AfterFunc(o.o.MaxAge, o.deadline)
+	} else {
+		o.t.Reset(o.o.MaxAge)
+	}
+}
+
+// deadline flush the buffer when the max age of the oldest data is reached.
+func (o *bfw) deadline() {
+	o.m.Lock()
+
+	if o.c || len(o.b) < 1 {
+		o.m.Unlock()
+		return
+	}
+
+	var (
+		e = o.flush()
+		f = o.o.OnFlushError
+	)
+
+	o.m.Unlock()
+
+	if e != nil && f != nil {
+		f(e)
+	}
+}
+
+// flush write the buffered data and keep the ones not written on error. Must be called with the lock held.
+func (o *bfw) flush() error {
+	if o.t != nil {
+		o.t.Stop()
+	}
+
+	if len(o.b) < 1 {
+		return nil
+	}
+
+	n, e := o.w.Write(o.b)
+
+	if n > 0 {
+		o.b = o.b[:copy(o.b, o.b[n:])]
+	}
+
+	if len(o.b) > 0 {
+		if e == nil {
+			e = io.ErrShortWrite
+		}
+
+		// retry on the next deadline
+		o.arm()
+	}
+
+	return e
+}
@@ -0,0 +1,75 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package spillBuffer
+
+import (
+	"io"
+	"sync"
+)
+
+// DefaultMemory is the default size kept in memory before spilling the buffer to a temporary file.
+const DefaultMemory = 32 * 1024 * 1024
+
+// Buffer is a thread-safe random access buffer kept in memory up to a size limit,
+// and moved to a temporary file when written beyond this limit.
+// It allows many goroutines to write parts of a content at their offset and to read it once complete.
+type Buffer interface {
+	io.ReaderAt
+	io.WriterAt
+
+	// Close release the memory and remove the temporary file if any.
+	io.Closer
+
+	// Truncate change the size of the buffer, spilling it to the temporary file if the size exceed
+	// the memory limit. It is used to allocate the full size before writing parts in parallel.
+	Truncate(size int64) error
+
+	// Size return the size of the buffer: the biggest end of written part or the truncated size.
+	Size() int64
+
+	// Spilled return true if the buffer has been moved to a temporary file.
+	Spilled() bool
+
+	// Reader return a reader on the whole content of the buffer, from the beginning to the current size.
+	Reader() io.ReadSeeker
+}
+
+// New return a buffer kept in memory up to the given size, and spilled to a temporary file created
+// into the given directory (or the default temporary directory if empty) beyond it.
+// A zero memory size means DefaultMemory, a negative memory size means always spilled.
+func New(memory int64, dir string) Buffer {
+	if memory == 0 {
+		memory = DefaultMemory
+	}
+
+	return &spb{
+		m: sync.RWMutex{},
+		l: memory,
+		d: dir,
+		b: make([]byte, 0),
+	}
+}
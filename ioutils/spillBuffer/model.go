@@ -0,0 +1,239 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package spillBuffer
+
+import (
+	"io"
+	"os"
+	"sync"
+)
+
+const tempPattern = "spill-*"
+
+type spb struct {
+	m sync.RWMutex
+	l int64    // memory limit
+	d string   // temporary directory
+	b []byte   // memory buffer
+	f *os.File // temporary file
+	s int64    // size
+	c bool     // closed
+}
+
+func (o *spb) ReadAt(p []byte, off int64) (n int, err error) {
+	if off < 0 {
+		return 0, ErrInvalidOffset
+	}
+
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	if o.c {
+		return 0, ErrClosed
+	} else if off >= o.s {
+		return 0, io.EOF
+	}
+
+	if int64(len(p)) > o.s-off {
+		p = p[:o.s-off]
+		err = io.EOF
+	}
+
+	if o.f != nil {
+		if n, e := o.f.ReadAt(p, off); e != nil {
+			return n, e
+		} else {
+			return n, err
+		}
+	}
+
+	return copy(p, o.b[off:]), err
+}
+
+func (o *spb) WriteAt(p []byte, off int64) (n int, err error) {
+	if off < 0 {
+		return 0, ErrInvalidOffset
+	}
+
+	var end = off + int64(len(p))
+
+	// the fast path under the read lock: the parts written in parallel do not overlap
+	if ok, n, err := o.writeAt(p, off, end); ok {
+		return n, err
+	}
+
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if o.c {
+		return 0, ErrClosed
+	} else if e := o.grow(end); e != nil {
+		return 0, e
+	}
+
+	if o.f != nil {
+		return o.f.WriteAt(p, off)
+	}
+
+	return copy(o.b[off:end], p), nil
+}
+
+// writeAt write the given part without changing the size of the buffer. It returns false
+// if the buffer need to grow.
+func (o *spb) writeAt(p []byte, off, end int64) (bool, int, error) {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	if o.c {
+		return true, 0, ErrClosed
+	} else if end > o.s {
+		return false, 0, nil
+	} else if o.f != nil {
+		n, e := o.f.WriteAt(p, off)
+		return true, n, e
+	} else {
+		return true, copy(o.b[off:end], p), nil
+	}
+}
+
+func (o *spb) Truncate(size int64) error {
+	if size < 0 {
+		return ErrInvalidSize
+	}
+
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if o.c {
+		return ErrClosed
+	} else if size >= o.s {
+		return o.grow(size)
+	} else if o.f != nil {
+		if e := o.f.Truncate(size); e != nil {
+			return e
+		}
+	} else {
+		o.b = o.b[:size]
+	}
+
+	o.s = size
+	return nil
+}
+
+// grow extend the buffer to the given size, spilling it if needed. Must be called with the lock held.
+func (o *spb) grow(size int64) error {
+	if size <= o.s {
+		return nil
+	}
+
+	if o.f == nil && size > o.l {
+		if e := o.spill(); e != nil {
+			return e
+		}
+	}
+
+	if o.f != nil {
+		if e := o.f.Truncate(size); e != nil {
+			return e
+		}
+	} else if int64(cap(o.b)) >= size {
+		o.b = o.b[:size]
+	} else {
+		var b = make([]byte, size, max(size, 2*int64(cap(o.b))))
+		copy(b, o.b)
+		o.b = b
+	}
+
+	o.s = size
+	return nil
+}
+
+// spill move the memory buffer to a temporary file. Must be called with the lock held.
+func (o *spb) spill() error {
+	f, e := os.CreateTemp(o.d, tempPattern)
+
+	if e != nil {
+		return e
+	} else if _, e = f.Write(o.b); e != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return e
+	}
+
+	o.f = f
+	o.b = nil
+
+	return nil
+}
+
+func (o *spb) Size() int64 {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	return o.s
+}
+
+func (o *spb) Spilled() bool {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	return o.f != nil
+}
+
+func (o *spb) Reader() io.ReadSeeker {
+	return io.NewSectionReader(o, 0, o.Size())
+}
+
+func (o *spb) Close() error {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if o.c {
+		return nil
+	}
+
+	o.c = true
+	o.b = nil
+	o.s = 0
+
+	if o.f == nil {
+		return nil
+	}
+
+	var (
+		n = o.f.Name()
+		e = o.f.Close()
+	)
+
+	o.f = nil
+
+	if r := os.Remove(n); e == nil {
+		e = r
+	}
+
+	return e
+}
@@ -0,0 +1,157 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package spillBuffer_test
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"os"
+	"sync"
+
+	iospb "github.com/nabbar/golib/ioutils/spillBuffer"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// writeParts write the given content by parts of the given size, in parallel and in reverse order.
+func writeParts(b iospb.Buffer, p []byte, size int) {
+	var wg sync.WaitGroup
+
+	for off := ((len(p) - 1) / size) * size; off >= 0; off -= size {
+		wg.Add(1)
+		go func(off int) {
+			defer GinkgoRecover()
+			defer wg.Done()
+
+			end := min(off+size, len(p))
+			n, e := b.WriteAt(p[off:end], int64(off))
+			Expect(e).ToNot(HaveOccurred())
+			Expect(n).To(Equal(end - off))
+		}(off)
+	}
+
+	wg.Wait()
+}
+
+var _ = Describe("ioutils/spillBuffer", func() {
+	var (
+		dir string
+		err error
+		src = make([]byte, 100*1024+7)
+	)
+
+	BeforeEach(func() {
+		_, _ = rand.New(rand.NewSource(1)).Read(src)
+		dir, err = os.MkdirTemp("", "golib-spill-")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		_ = os.RemoveAll(dir)
+	})
+
+	It("must keep in memory a content smaller than the limit", func() {
+		b := iospb.New(1024*1024, dir)
+		defer func() {
+			Expect(b.Close()).ToNot(HaveOccurred())
+		}()
+
+		writeParts(b, src, 4096)
+		Expect(b.Spilled()).To(BeFalse())
+		Expect(b.Size()).To(Equal(int64(len(src))))
+
+		res, e := io.ReadAll(b.Reader())
+		Expect(e).ToNot(HaveOccurred())
+		Expect(res).To(Equal(src))
+	})
+
+	It("must spill a content bigger than the limit to a temporary file removed on close", func() {
+		b := iospb.New(10*1024, dir)
+
+		writeParts(b, src, 4096)
+		Expect(b.Spilled()).To(BeTrue())
+		Expect(b.Size()).To(Equal(int64(len(src))))
+
+		res, e := io.ReadAll(b.Reader())
+		Expect(e).ToNot(HaveOccurred())
+		Expect(res).To(Equal(src))
+
+		l, e := os.ReadDir(dir)
+		Expect(e).ToNot(HaveOccurred())
+		Expect(l).To(HaveLen(1))
+
+		Expect(b.Close()).ToNot(HaveOccurred())
+
+		l, e = os.ReadDir(dir)
+		Expect(e).ToNot(HaveOccurred())
+		Expect(l).To(BeEmpty())
+
+		_, e = b.ReadAt(make([]byte, 1), 0)
+		Expect(e).To(MatchError(iospb.ErrClosed))
+	})
+
+	It("must allocate the size on truncate and read the unwritten parts as zero", func() {
+		b := iospb.New(0, dir)
+		defer func() {
+			_ = b.Close()
+		}()
+
+		Expect(b.Truncate(10)).ToNot(HaveOccurred())
+		_, e := b.WriteAt([]byte("abc"), 2)
+		Expect(e).ToNot(HaveOccurred())
+
+		p := make([]byte, 20)
+		n, e := b.ReadAt(p, 0)
+		Expect(e).To(Equal(io.EOF))
+		Expect(p[:n]).To(Equal([]byte("\x00\x00abc\x00\x00\x00\x00\x00")))
+
+		Expect(b.Truncate(4)).ToNot(HaveOccurred())
+		res, e := io.ReadAll(b.Reader())
+		Expect(e).ToNot(HaveOccurred())
+		Expect(res).To(Equal([]byte("\x00\x00ab")))
+
+		Expect(b.Truncate(-1)).To(MatchError(iospb.ErrInvalidSize))
+		_, e = b.WriteAt([]byte("x"), -1)
+		Expect(e).To(MatchError(iospb.ErrInvalidOffset))
+	})
+
+	It("must always spill with a negative memory size", func() {
+		b := iospb.New(-1, dir)
+		defer func() {
+			_ = b.Close()
+		}()
+
+		_, e := b.WriteAt([]byte("abc"), 0)
+		Expect(e).ToNot(HaveOccurred())
+		Expect(b.Spilled()).To(BeTrue())
+
+		res, e := io.ReadAll(b.Reader())
+		Expect(e).ToNot(HaveOccurred())
+		Expect(bytes.Equal(res, []byte("abc"))).To(BeTrue())
+	})
+})
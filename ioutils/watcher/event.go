@@ -0,0 +1,73 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package watcher
+
+import "strings"
+
+type Event uint8
+
+const (
+	// EventCreate is sent when the file appears.
+	EventCreate Event = 1 << iota
+	// EventWrite is sent when the content, the size or the target of the file change.
+	EventWrite
+	// EventRemove is sent when the file disappears.
+	EventRemove
+	// EventRename is sent when the file is moved away.
+	EventRename
+	// EventChmod is sent when the permissions of the file change.
+	EventChmod
+
+	EventAll = EventCreate | EventWrite | EventRemove | EventRename | EventChmod
+)
+
+// Has return true if all given events are set.
+func (e Event) Has(evt Event) bool {
+	return e&evt == evt
+}
+
+func (e Event) String() string {
+	var res = make([]string, 0)
+
+	if e.Has(EventCreate) {
+		res = append(res, "create")
+	}
+	if e.Has(EventWrite) {
+		res = append(res, "write")
+	}
+	if e.Has(EventRemove) {
+		res = append(res, "remove")
+	}
+	if e.Has(EventRename) {
+		res = append(res, "rename")
+	}
+	if e.Has(EventChmod) {
+		res = append(res, "chmod")
+	}
+
+	return strings.Join(res, "|")
+}
@@ -0,0 +1,118 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package watcher
+
+import (
+	"io"
+	"time"
+)
+
+const (
+	// DefaultInterval is the default polling interval when the file system notification is not available.
+	DefaultInterval = 5 * time.Second
+	// DefaultDebounce is the default delay used to merge several file events into one call.
+	DefaultDebounce = 250 * time.Millisecond
+)
+
+// FuncEvent is called after the debounce delay with the watched path and the merged events.
+type FuncEvent func(path string, evt Event)
+
+// FuncError is used to report errors while watching the path.
+type FuncError func(err error)
+
+type Options struct {
+	// Poll force the polling mode instead of the file system notification (inotify, kqueue, ...).
+	Poll bool
+
+	// Interval define the polling interval. Default is 5 seconds.
+	Interval time.Duration
+
+	// Debounce define the delay used to merge several events into one call. Default is 250 ms.
+	Debounce time.Duration
+
+	// FuncError if defined is called with errors occurring while watching.
+	FuncError FuncError
+}
+
+type Watcher interface {
+	// Close stop watching the path. The function will not be called anymore after Close return.
+	// Close must not be called from the event function as it wait for the watching goroutine.
+	io.Closer
+
+	// Path return the watched path.
+	Path() string
+
+	// IsPolling return true if the polling mode is used, by option or because the
+	// file system notification is not available.
+	IsPolling() bool
+
+	// Done return a channel closed when the watcher is stopped.
+	Done() <-chan struct{}
+}
+
+// Watch start watching the given file with the default options and call the function for each
+// matching event. The parent directory is watched to follow the atomic replacement of the file
+// (rename of a temporary file, symlink swap of kubernetes config maps), so the file may not exist.
+func Watch(path string, evt Event, fct FuncEvent) (Watcher, error) {
+	return WatchOptions(path, Options{}, evt, fct)
+}
+
+// WatchOptions is the same as Watch with the given options.
+func WatchOptions(path string, opt Options, evt Event, fct FuncEvent) (Watcher, error) {
+	if len(path) < 1 {
+		return nil, ErrInvalidPath
+	} else if fct == nil {
+		return nil, ErrInvalidFunc
+	}
+
+	if evt == 0 {
+		evt = EventAll
+	}
+
+	if opt.Interval <= 0 {
+		opt.Interval = DefaultInterval
+	}
+
+	if opt.Debounce <= 0 {
+		opt.Debounce = DefaultDebounce
+	}
+
+	w := &wtc{
+		p: path,
+		o: opt,
+		e: evt,
+		f: fct,
+		d: make(chan struct{}),
+		x: make(chan struct{}),
+	}
+
+	if e := w.start(); e != nil {
+		return nil, e
+	}
+
+	return w, nil
+}
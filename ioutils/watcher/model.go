@@ -0,0 +1,265 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	libnot "github.com/fsnotify/fsnotify"
+	libsrv "github.com/nabbar/golib/server"
+)
+
+type wtc struct {
+	p string        // path
+	o Options       // options
+	e Event         // events filter
+	f FuncEvent     // event function
+	n atomic.Bool   // polling mode
+	c sync.Once     // close once
+	d chan struct{} // closed when the watching goroutine is ended
+	x chan struct{} // closed to stop the watching goroutine
+	l string        // directory of the symlink target watched in addition of the parent directory
+}
+
+// state is the snapshot of the watched file used to detect the changes.
+type state struct {
+	i os.FileInfo // file info, following symlinks
+	t string      // resolved target path
+}
+
+func (o *wtc) Path() string {
+	return o.p
+}
+
+func (o *wtc) IsPolling() bool {
+	return o.n.Load()
+}
+
+func (o *wtc) Done() <-chan struct{} {
+	return o.d
+}
+
+func (o *wtc) Close() error {
+	o.c.Do(func() {
+		close(o.x)
+	})
+
+	<-o.d
+	return nil
+}
+
+func (o *wtc) start() error {
+	var n *libnot.Watcher
+
+	if !o.o.Poll {
+		if w, e := libnot.NewWatcher(); e != nil {
+			o.onError(e)
+		} else if e = w.Add(filepath.Dir(o.p)); e != nil {
+			_ = w.Close()
+			o.onError(e)
+		} else {
+			n = w
+		}
+	}
+
+	if n == nil {
+		if _, e := os.Stat(filepath.Dir(o.p)); e != nil {
+			return e
+		}
+	}
+
+	var s = o.stat()
+
+	o.n.Store(n == nil)
+	o.follow(n, s)
+
+	go o.run(n, s)
+
+	return nil
+}
+
+func (o *wtc) run(n *libnot.Watcher, s state) {
+	var (
+		p Event
+		t = time.NewTimer(o.o.Debounce)
+		k *time.Ticker
+		c <-chan time.Time
+		v <-chan libnot.Event
+		r <-chan error
+	)
+
+	defer func() {
+		libsrv.RecoveryCaller("golib/ioutils/watcher/run", recover())
+
+		t.Stop()
+
+		if k != nil {
+			k.Stop()
+		}
+
+		if n != nil {
+			_ = n.Close()
+		}
+
+		close(o.d)
+	}()
+
+	if !t.Stop() {
+		<-t.C
+	}
+
+	if n != nil {
+		v = n.Events
+		r = n.Errors
+	} else {
+		k = time.NewTicker(o.o.Interval)
+		c = k.C
+	}
+
+	var check = func(ren bool) {
+		var (
+			i = o.stat()
+			e = diff(s, i, ren)
+		)
+
+		s = i
+		o.follow(n, i)
+
+		if e != 0 {
+			p |= e
+			t.Reset(o.o.Debounce)
+		}
+	}
+
+	for {
+		select {
+		case <-o.x:
+			return
+
+		case e, ok := <-r:
+			if !ok {
+				return
+			}
+			o.onError(e)
+
+		case e, ok := <-v:
+			if !ok {
+				return
+			}
+			check(e.Op.Has(libnot.Rename) && filepath.Clean(e.Name) == filepath.Clean(o.p))
+
+		case <-c:
+			check(false)
+
+		case <-t.C:
+			if e := p & o.e; e != 0 {
+				o.call(e)
+			}
+			p = 0
+		}
+	}
+}
+
+// follow watch the directory of the symlink target to catch the write on the target file.
+func (o *wtc) follow(n *libnot.Watcher, i state) {
+	var f = filepath.Dir(o.p)
+
+	if n == nil || len(i.t) < 1 {
+		return
+	} else if t := filepath.Dir(i.t); t == o.l {
+		return
+	} else {
+		if len(o.l) > 0 && o.l != f {
+			_ = n.Remove(o.l)
+		}
+
+		o.l = t
+
+		if o.l != f {
+			o.onError(n.Add(o.l))
+		}
+	}
+}
+
+func (o *wtc) call(e Event) {
+	defer func() {
+		libsrv.RecoveryCaller("golib/ioutils/watcher/call", recover())
+	}()
+
+	o.f(o.p, e)
+}
+
+func (o *wtc) onError(e error) {
+	if e != nil && o.o.FuncError != nil {
+		o.o.FuncError(e)
+	}
+}
+
+func (o *wtc) stat() state {
+	var s state
+
+	if i, e := os.Stat(o.p); e == nil {
+		s.i = i
+	}
+
+	if t, e := filepath.EvalSymlinks(o.p); e == nil {
+		s.t = t
+	}
+
+	return s
+}
+
+func diff(old, new state, ren bool) Event {
+	switch {
+	case old.i == nil && new.i == nil:
+		return 0
+	case old.i == nil:
+		return EventCreate
+	case new.i == nil && ren:
+		return EventRename
+	case new.i == nil:
+		return EventRemove
+	}
+
+	var e Event
+
+	if old.t != new.t || !os.SameFile(old.i, new.i) {
+		e |= EventWrite
+	} else if !old.i.ModTime().Equal(new.i.ModTime()) || old.i.Size() != new.i.Size() {
+		e |= EventWrite
+	}
+
+	if old.i.Mode().Perm() != new.i.Mode().Perm() {
+		e |= EventChmod
+	}
+
+	return e
+}
@@ -0,0 +1,148 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package watcher_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	iowtch "github.com/nabbar/golib/ioutils/watcher"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const (
+	tstInterval = 30 * time.Millisecond
+	tstDebounce = 50 * time.Millisecond
+)
+
+func expectEvent(c <-chan iowtch.Event, evt iowtch.Event) {
+	var e iowtch.Event
+	Eventually(c, 2*time.Second).Should(Receive(&e))
+	Expect(e.Has(evt)).To(BeTrue(), "event '%s' must contains '%s'", e.String(), evt.String())
+}
+
+func describeWatcher(poll bool) {
+	var (
+		dir string
+		pth string
+		evt chan iowtch.Event
+		wtc iowtch.Watcher
+	)
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		pth = filepath.Join(dir, "config.json")
+		evt = make(chan iowtch.Event, 10)
+
+		Expect(os.MkdirAll(filepath.Join(dir, "v1"), 0755)).ToNot(HaveOccurred())
+		Expect(os.MkdirAll(filepath.Join(dir, "v2"), 0755)).ToNot(HaveOccurred())
+		Expect(os.WriteFile(filepath.Join(dir, "v1", "config.json"), []byte("v1"), 0644)).ToNot(HaveOccurred())
+		Expect(os.WriteFile(filepath.Join(dir, "v2", "config.json"), []byte("v2-content"), 0644)).ToNot(HaveOccurred())
+		Expect(os.Symlink("v1", filepath.Join(dir, "data"))).ToNot(HaveOccurred())
+		Expect(os.Symlink(filepath.Join("data", "config.json"), pth)).ToNot(HaveOccurred())
+
+		var err error
+		wtc, err = iowtch.WatchOptions(pth, iowtch.Options{
+			Poll:     poll,
+			Interval: tstInterval,
+			Debounce: tstDebounce,
+		}, iowtch.EventAll, func(path string, e iowtch.Event) {
+			Expect(path).To(Equal(pth))
+			evt <- e
+		})
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(wtc).ToNot(BeNil())
+		Expect(wtc.IsPolling()).To(Equal(poll))
+	})
+
+	AfterEach(func() {
+		Expect(wtc.Close()).ToNot(HaveOccurred())
+		Eventually(wtc.Done(), time.Second).Should(BeClosed())
+	})
+
+	It("must report a write on the file", func() {
+		Expect(os.WriteFile(pth, []byte("new content"), 0644)).ToNot(HaveOccurred())
+		expectEvent(evt, iowtch.EventWrite)
+	})
+
+	It("must report a swap of the symlink target", func() {
+		Expect(os.Symlink("v2", filepath.Join(dir, "tmp"))).ToNot(HaveOccurred())
+		Expect(os.Rename(filepath.Join(dir, "tmp"), filepath.Join(dir, "data"))).ToNot(HaveOccurred())
+		expectEvent(evt, iowtch.EventWrite)
+
+		Expect(os.WriteFile(filepath.Join(dir, "v2", "config.json"), []byte("v2-updated"), 0644)).ToNot(HaveOccurred())
+		expectEvent(evt, iowtch.EventWrite)
+	})
+
+	It("must report an atomic replacement of the file", func() {
+		Expect(os.WriteFile(filepath.Join(dir, "config.tmp"), []byte("replaced"), 0644)).ToNot(HaveOccurred())
+		Expect(os.Rename(filepath.Join(dir, "config.tmp"), pth)).ToNot(HaveOccurred())
+		expectEvent(evt, iowtch.EventWrite)
+	})
+
+	It("must report the removal and the creation of the file", func() {
+		Expect(os.Remove(pth)).ToNot(HaveOccurred())
+		expectEvent(evt, iowtch.EventRemove)
+
+		Expect(os.WriteFile(pth, []byte("created"), 0644)).ToNot(HaveOccurred())
+		expectEvent(evt, iowtch.EventCreate)
+	})
+
+	It("must merge events occurring into the debounce delay", func() {
+		for i := 0; i < 5; i++ {
+			Expect(os.WriteFile(pth, []byte(time.Now().String()), 0644)).ToNot(HaveOccurred())
+		}
+
+		expectEvent(evt, iowtch.EventWrite)
+		Consistently(evt, 4*tstDebounce).ShouldNot(Receive())
+	})
+}
+
+var _ = Describe("ioutils/watcher", func() {
+	Context("creating a watcher with invalid parameters", func() {
+		It("must fail with an empty path", func() {
+			_, err := iowtch.Watch("", iowtch.EventAll, func(path string, evt iowtch.Event) {})
+			Expect(err).To(MatchError(iowtch.ErrInvalidPath))
+		})
+
+		It("must fail with a nil function", func() {
+			_, err := iowtch.Watch(filepath.Join(os.TempDir(), "file"), iowtch.EventAll, nil)
+			Expect(err).To(MatchError(iowtch.ErrInvalidFunc))
+		})
+	})
+
+	Context("watching a file with the file system notification", func() {
+		describeWatcher(false)
+	})
+
+	Context("watching a file with the polling mode", func() {
+		describeWatcher(true)
+	})
+})
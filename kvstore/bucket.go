@@ -0,0 +1,165 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package kvstore
+
+import (
+	"encoding/json"
+)
+
+// Codec encodes and decodes the values of a typed bucket.
+type Codec[V any] interface {
+	Marshal(val V) ([]byte, error)
+	Unmarshal(p []byte) (V, error)
+}
+
+// JSONCodec returns a codec encoding the values in json.
+func JSONCodec[V any]() Codec[V] {
+	return cdcJSON[V]{}
+}
+
+type cdcJSON[V any] struct{}
+
+func (cdcJSON[V]) Marshal(val V) ([]byte, error) {
+	return json.Marshal(val)
+}
+
+func (cdcJSON[V]) Unmarshal(p []byte) (V, error) {
+	var v V
+	e := json.Unmarshal(p, &v)
+	return v, e
+}
+
+// Bucket is a typed access to a bucket of a store, the keys are strings and the values are encoded with a codec.
+type Bucket[V any] interface {
+	// Name returns the name of the bucket.
+	Name() string
+
+	// Get returns the value of the key, false if the key does not exist.
+	Get(key string) (V, bool, error)
+
+	// Put adds or replaces the value of the key.
+	Put(key string, val V) error
+
+	// Delete removes the key.
+	Delete(key string) error
+
+	// Walk calls the function for each key starting with the prefix, in the order of the keys,
+	// until the function returns false. The function must not write into the store.
+	Walk(prefix string, fct func(key string, val V) bool) error
+
+	// Count returns the number of keys.
+	Count() (int, error)
+
+	// Clear removes all keys.
+	Clear() error
+}
+
+// NewBucket returns a typed access to the bucket of the store, with the values encoded in json.
+func NewBucket[V any](s Store, name string) (Bucket[V], error) {
+	return NewBucketCodec[V](s, name, JSONCodec[V]())
+}
+
+// NewBucketCodec returns a typed access to the bucket of the store, with the values encoded by the codec.
+func NewBucketCodec[V any](s Store, name string, cdc Codec[V]) (Bucket[V], error) {
+	if s == nil || len(name) < 1 || cdc == nil {
+		return nil, ErrorParamEmpty.Error(nil)
+	}
+
+	return &bkt[V]{
+		s: s,
+		n: name,
+		c: cdc,
+	}, nil
+}
+
+type bkt[V any] struct {
+	s Store
+	n string
+	c Codec[V]
+}
+
+func (o *bkt[V]) Name() string {
+	return o.n
+}
+
+func (o *bkt[V]) Get(key string) (V, bool, error) {
+	var v V
+
+	if p, e := o.s.Get(o.n, []byte(key)); e != nil {
+		return v, false, e
+	} else if p == nil {
+		return v, false, nil
+	} else if v, e = o.c.Unmarshal(p); e != nil {
+		return v, false, ErrorDecode.Error(e)
+	} else {
+		return v, true, nil
+	}
+}
+
+func (o *bkt[V]) Put(key string, val V) error {
+	if p, e := o.c.Marshal(val); e != nil {
+		return ErrorEncode.Error(e)
+	} else {
+		return o.s.Put(o.n, []byte(key), p)
+	}
+}
+
+func (o *bkt[V]) Delete(key string) error {
+	return o.s.Delete(o.n, []byte(key))
+}
+
+func (o *bkt[V]) Walk(prefix string, fct func(key string, val V) bool) error {
+	if fct == nil {
+		return ErrorParamEmpty.Error(nil)
+	}
+
+	var err error
+
+	e := o.s.Walk(o.n, []byte(prefix), func(key, val []byte) bool {
+		v, er := o.c.Unmarshal(val)
+
+		if er != nil {
+			err = ErrorDecode.Error(er)
+			return false
+		}
+
+		return fct(string(key), v)
+	})
+
+	if e != nil {
+		return e
+	}
+
+	return err
+}
+
+func (o *bkt[V]) Count() (int, error) {
+	return o.s.Count(o.n)
+}
+
+func (o *bkt[V]) Clear() error {
+	return o.s.DeleteBucket(o.n)
+}
@@ -0,0 +1,95 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package kvstore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"io"
+
+	encaes "github.com/nabbar/golib/encoding/aes"
+)
+
+func newCipher(hexKey string) (cipher.AEAD, error) {
+	k, e := encaes.GetHexKey(hexKey)
+
+	if e != nil {
+		return nil, e
+	}
+
+	b, e := aes.NewCipher(k[:])
+
+	if e != nil {
+		return nil, e
+	}
+
+	return cipher.NewGCM(b)
+}
+
+// additional returns the data authenticated with a value: a value cannot be moved to another key.
+func additional(bucket string, key []byte) []byte {
+	var res = make([]byte, 0, len(bucket)+len(key)+1)
+
+	res = append(res, bucket...)
+	res = append(res, 0)
+	res = append(res, key...)
+
+	return res
+}
+
+// encrypt encrypts the value with a random nonce written before the encrypted value.
+func (o *kvs) encrypt(bucket string, key, val []byte) ([]byte, error) {
+	if o.a == nil {
+		return val, nil
+	}
+
+	var n = make([]byte, o.a.NonceSize(), o.a.NonceSize()+len(val)+o.a.Overhead())
+
+	if _, e := io.ReadFull(rand.Reader, n); e != nil {
+		return nil, ErrorCipher.Error(e)
+	}
+
+	return o.a.Seal(n, n, val, additional(bucket, key)), nil
+}
+
+func (o *kvs) decrypt(bucket string, key, val []byte) ([]byte, error) {
+	if o.a == nil || val == nil {
+		return val, nil
+	}
+
+	var s = o.a.NonceSize()
+
+	if len(val) < s {
+		return nil, ErrorCipher.Error(nil)
+	}
+
+	if r, e := o.a.Open(nil, val[:s], val[s:], additional(bucket, key)); e != nil {
+		return nil, ErrorCipher.Error(e)
+	} else {
+		return r, nil
+	}
+}
@@ -0,0 +1,103 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package kvstore
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	libval "github.com/go-playground/validator/v10"
+	libdur "github.com/nabbar/golib/duration"
+)
+
+const (
+	// DefaultFileMode is the default permission of the store file.
+	DefaultFileMode os.FileMode = 0600
+	// DefaultOpenTimeout is the default duration to wait for the file lock of the store on opening.
+	DefaultOpenTimeout = time.Second
+	// DefaultCompactTxSize is the default maximum size of a transaction while compacting the store.
+	DefaultCompactTxSize int64 = 64 * 1024
+)
+
+type Config struct {
+	// Path is the path of the store file, created if missing.
+	Path string `json:"path" yaml:"path" toml:"path" mapstructure:"path" validate:"required"`
+
+	// FileMode is the permission of the store file. By default, 0600.
+	FileMode os.FileMode `json:"file-mode,omitempty" yaml:"file-mode,omitempty" toml:"file-mode,omitempty" mapstructure:"file-mode,omitempty"`
+
+	// OpenTimeout is the duration to wait for the file lock of the store, held by another process. By default, 1s.
+	OpenTimeout libdur.Duration `json:"open-timeout,omitempty" yaml:"open-timeout,omitempty" toml:"open-timeout,omitempty" mapstructure:"open-timeout,omitempty"`
+
+	// NoSync disables the fsync after each write transaction, faster but not safe on crash.
+	NoSync bool `json:"no-sync,omitempty" yaml:"no-sync,omitempty" toml:"no-sync,omitempty" mapstructure:"no-sync,omitempty"`
+
+	// EncryptionKey is the hexadecimal AES-256 key used to encrypt the values at rest. The keys are not encrypted.
+	// The key cannot be changed for an existing store.
+	EncryptionKey string `json:"encryption-key,omitempty" yaml:"encryption-key,omitempty" toml:"encryption-key,omitempty" mapstructure:"encryption-key,omitempty" validate:"omitempty,hexadecimal,len=64"`
+
+	// CompactOnOpen compacts the store file on opening to reclaim the free pages.
+	CompactOnOpen bool `json:"compact-on-open,omitempty" yaml:"compact-on-open,omitempty" toml:"compact-on-open,omitempty" mapstructure:"compact-on-open,omitempty"`
+
+	// CompactTxSize is the maximum size of a transaction while compacting the store. By default, 64KB.
+	CompactTxSize int64 `json:"compact-tx-size,omitempty" yaml:"compact-tx-size,omitempty" toml:"compact-tx-size,omitempty" mapstructure:"compact-tx-size,omitempty" validate:"gte=0"`
+}
+
+func (c Config) Validate() error {
+	err := ErrorValidatorError.Error(nil)
+
+	if er := libval.New().Struct(c); er != nil {
+		if e, ok := er.(*libval.InvalidValidationError); ok {
+			err.Add(e)
+		}
+
+		for _, e := range er.(libval.ValidationErrors) {
+			//nolint goerr113
+			err.Add(fmt.Errorf("config field '%s' is not validated by constraint '%s'", e.Namespace(), e.ActualTag()))
+		}
+	}
+
+	if err.HasParent() {
+		return err
+	}
+
+	return nil
+}
+
+func (c *Config) normalize() {
+	if c.FileMode == 0 {
+		c.FileMode = DefaultFileMode
+	}
+
+	if c.OpenTimeout <= 0 {
+		c.OpenTimeout = libdur.ParseDuration(DefaultOpenTimeout)
+	}
+
+	if c.CompactTxSize <= 0 {
+		c.CompactTxSize = DefaultCompactTxSize
+	}
+}
@@ -0,0 +1,85 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package kvstore
+
+import (
+	"fmt"
+
+	liberr "github.com/nabbar/golib/errors"
+)
+
+const (
+	ErrorParamEmpty liberr.CodeError = iota + liberr.MinPkgKVStore
+	ErrorValidatorError
+	ErrorOpen
+	ErrorClosed
+	ErrorBucket
+	ErrorRead
+	ErrorWrite
+	ErrorEncode
+	ErrorDecode
+	ErrorCipher
+	ErrorCompact
+	ErrorBackup
+)
+
+func init() {
+	if liberr.ExistInMapMessage(ErrorParamEmpty) {
+		panic(fmt.Errorf("error code collision with package golib/kvstore"))
+	}
+	liberr.RegisterIdFctMessage(ErrorParamEmpty, getMessage)
+}
+
+func getMessage(code liberr.CodeError) (message string) {
+	switch code {
+	case ErrorParamEmpty:
+		return "given parameters is empty"
+	case ErrorValidatorError:
+		return "kvstore config seems to be invalid"
+	case ErrorOpen:
+		return "cannot open the kvstore file"
+	case ErrorClosed:
+		return "kvstore is closed"
+	case ErrorBucket:
+		return "cannot create or delete the kvstore bucket"
+	case ErrorRead:
+		return "cannot read from the kvstore"
+	case ErrorWrite:
+		return "cannot write into the kvstore"
+	case ErrorEncode:
+		return "cannot encode the value to store"
+	case ErrorDecode:
+		return "cannot decode the stored value"
+	case ErrorCipher:
+		return "cannot encrypt or decrypt the stored value"
+	case ErrorCompact:
+		return "cannot compact the kvstore file"
+	case ErrorBackup:
+		return "cannot backup the kvstore"
+	}
+
+	return liberr.NullMessage
+}
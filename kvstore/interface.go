@@ -0,0 +1,122 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package kvstore
+
+import (
+	"io"
+
+	arctps "github.com/nabbar/golib/archive/archive/types"
+)
+
+// FuncWalk is called for each key of a bucket with its raw value, the walk stops if it returns false.
+type FuncWalk func(key, val []byte) bool
+
+// Stats are the statistics of the store file.
+type Stats struct {
+	// Size is the size of the store file.
+	Size int64
+	// FreePages is the number of free pages, reclaimed by a compaction.
+	FreePages int
+	// FreeAlloc is the size of the free pages.
+	FreeAlloc int
+}
+
+// Store is a local embedded key value store, organized into buckets. A Store is safe for concurrent use.
+// The values are encrypted at rest if an encryption key is defined. Use NewBucket for a typed access to a bucket.
+type Store interface {
+	io.Closer
+
+	// Path returns the path of the store file.
+	Path() string
+
+	// Buckets returns the name of all buckets.
+	Buckets() ([]string, error)
+
+	// DeleteBucket removes the bucket and all its keys.
+	DeleteBucket(bucket string) error
+
+	// Get returns the raw value of the key, or nil if the key does not exist.
+	Get(bucket string, key []byte) ([]byte, error)
+
+	// Put adds or replaces the raw value of the key, the bucket is created if needed.
+	Put(bucket string, key, val []byte) error
+
+	// Delete removes the key.
+	Delete(bucket string, key []byte) error
+
+	// Walk calls the function for each key of the bucket starting with the prefix, in the order of the keys.
+	// The function is called into a read transaction and must not write into the store.
+	Walk(bucket string, prefix []byte, fct FuncWalk) error
+
+	// Count returns the number of keys of the bucket.
+	Count(bucket string) (int, error)
+
+	// Compact rewrites the store file to reclaim the free pages. The store is locked during the compaction.
+	Compact() error
+
+	// Stats returns the statistics of the store file.
+	Stats() Stats
+
+	// Backup writes a consistent snapshot of the store file into the writer, without blocking the writes.
+	Backup(w io.Writer) (int64, error)
+
+	// BackupArchive adds a consistent snapshot of the store file into the archive with the given path.
+	BackupArchive(w arctps.Writer, name string) error
+}
+
+// New opens or creates the store file of the config.
+func New(cfg Config) (Store, error) {
+	if e := cfg.Validate(); e != nil {
+		return nil, e
+	}
+
+	cfg.normalize()
+
+	o := &kvs{
+		c: cfg,
+	}
+
+	if len(cfg.EncryptionKey) > 0 {
+		if a, e := newCipher(cfg.EncryptionKey); e != nil {
+			return nil, ErrorCipher.Error(e)
+		} else {
+			o.a = a
+		}
+	}
+
+	if e := o.open(); e != nil {
+		return nil, e
+	}
+
+	if cfg.CompactOnOpen {
+		if e := o.Compact(); e != nil {
+			_ = o.Close()
+			return nil, e
+		}
+	}
+
+	return o, nil
+}
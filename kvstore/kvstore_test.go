@@ -0,0 +1,210 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package kvstore_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	arctar "github.com/nabbar/golib/archive/archive/tar"
+	libkvs "github.com/nabbar/golib/kvstore"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type user struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+const encKey = "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+var _ = Describe("KVStore", func() {
+	var (
+		dir string
+		cfg libkvs.Config
+	)
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		cfg = libkvs.Config{
+			Path: filepath.Join(dir, "store.db"),
+		}
+	})
+
+	It("must validate the config", func() {
+		_, e := libkvs.New(libkvs.Config{})
+		Expect(e).To(HaveOccurred())
+
+		cfg.EncryptionKey = "1234"
+		_, e = libkvs.New(cfg)
+		Expect(e).To(HaveOccurred())
+	})
+
+	It("must store typed values into buckets", func() {
+		s, e := libkvs.New(cfg)
+		Expect(e).ToNot(HaveOccurred())
+		defer func() { _ = s.Close() }()
+
+		b, e := libkvs.NewBucket[user](s, "users")
+		Expect(e).ToNot(HaveOccurred())
+
+		_, ok, e := b.Get("alice")
+		Expect(e).ToNot(HaveOccurred())
+		Expect(ok).To(BeFalse())
+
+		Expect(b.Put("user/alice", user{Name: "alice", Age: 30})).ToNot(HaveOccurred())
+		Expect(b.Put("user/bob", user{Name: "bob", Age: 40})).ToNot(HaveOccurred())
+		Expect(b.Put("admin/root", user{Name: "root"})).ToNot(HaveOccurred())
+
+		u, ok, e := b.Get("user/alice")
+		Expect(e).ToNot(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(u).To(Equal(user{Name: "alice", Age: 30}))
+
+		var keys []string
+		Expect(b.Walk("user/", func(key string, val user) bool {
+			keys = append(keys, key)
+			return true
+		})).ToNot(HaveOccurred())
+		Expect(keys).To(Equal([]string{"user/alice", "user/bob"}))
+
+		Expect(b.Count()).To(Equal(3))
+		Expect(b.Delete("user/bob")).ToNot(HaveOccurred())
+		Expect(b.Count()).To(Equal(2))
+		Expect(s.Buckets()).To(Equal([]string{"users"}))
+
+		Expect(b.Clear()).ToNot(HaveOccurred())
+		Expect(b.Count()).To(Equal(0))
+		Expect(s.Buckets()).To(BeEmpty())
+	})
+
+	It("must keep the values after reopening", func() {
+		s, e := libkvs.New(cfg)
+		Expect(e).ToNot(HaveOccurred())
+		Expect(s.Put("raw", []byte("key"), []byte("value"))).ToNot(HaveOccurred())
+		Expect(s.Close()).ToNot(HaveOccurred())
+
+		_, e = s.Get("raw", []byte("key"))
+		Expect(e).To(HaveOccurred())
+
+		s, e = libkvs.New(cfg)
+		Expect(e).ToNot(HaveOccurred())
+		defer func() { _ = s.Close() }()
+
+		Expect(s.Get("raw", []byte("key"))).To(Equal([]byte("value")))
+	})
+
+	It("must encrypt the values at rest", func() {
+		cfg.EncryptionKey = encKey
+
+		s, e := libkvs.New(cfg)
+		Expect(e).ToNot(HaveOccurred())
+		Expect(s.Put("secret", []byte("key"), []byte("plain-text-value"))).ToNot(HaveOccurred())
+		Expect(s.Get("secret", []byte("key"))).To(Equal([]byte("plain-text-value")))
+		Expect(s.Close()).ToNot(HaveOccurred())
+
+		raw, e := os.ReadFile(cfg.Path)
+		Expect(e).ToNot(HaveOccurred())
+		Expect(bytes.Contains(raw, []byte("plain-text-value"))).To(BeFalse())
+
+		cfg.EncryptionKey = strings.Repeat("ff", 32)
+		s, e = libkvs.New(cfg)
+		Expect(e).ToNot(HaveOccurred())
+		defer func() { _ = s.Close() }()
+
+		_, e = s.Get("secret", []byte("key"))
+		Expect(e).To(HaveOccurred())
+	})
+
+	It("must compact the store file", func() {
+		s, e := libkvs.New(cfg)
+		Expect(e).ToNot(HaveOccurred())
+		defer func() { _ = s.Close() }()
+
+		var val = bytes.Repeat([]byte("x"), 4096)
+
+		for i := 0; i < 500; i++ {
+			Expect(s.Put("data", []byte(fmt.Sprintf("key-%04d", i)), val)).ToNot(HaveOccurred())
+		}
+
+		Expect(s.DeleteBucket("data")).ToNot(HaveOccurred())
+		Expect(s.Put("data", []byte("kept"), []byte("value"))).ToNot(HaveOccurred())
+
+		before := s.Stats()
+		Expect(before.FreePages).To(BeNumerically(">", 0))
+
+		Expect(s.Compact()).ToNot(HaveOccurred())
+
+		after := s.Stats()
+		Expect(after.Size).To(BeNumerically("<", before.Size))
+		Expect(s.Get("data", []byte("kept"))).To(Equal([]byte("value")))
+	})
+
+	It("must backup the store", func() {
+		s, e := libkvs.New(cfg)
+		Expect(e).ToNot(HaveOccurred())
+		defer func() { _ = s.Close() }()
+
+		Expect(s.Put("raw", []byte("key"), []byte("value"))).ToNot(HaveOccurred())
+
+		var buf = bytes.NewBuffer(nil)
+		n, e := s.Backup(buf)
+		Expect(e).ToNot(HaveOccurred())
+		Expect(n).To(Equal(int64(buf.Len())))
+
+		cpy := filepath.Join(dir, "copy.db")
+		Expect(os.WriteFile(cpy, buf.Bytes(), 0600)).ToNot(HaveOccurred())
+
+		c, e := libkvs.New(libkvs.Config{Path: cpy})
+		Expect(e).ToNot(HaveOccurred())
+		Expect(c.Get("raw", []byte("key"))).To(Equal([]byte("value")))
+		Expect(c.Close()).ToNot(HaveOccurred())
+
+		var arc = bytes.NewBuffer(nil)
+		w, e := arctar.NewWriter(nopCloser{arc})
+		Expect(e).ToNot(HaveOccurred())
+		Expect(s.BackupArchive(w, "backup/store.db")).ToNot(HaveOccurred())
+		Expect(w.Close()).ToNot(HaveOccurred())
+
+		r, e := arctar.NewReader(io.NopCloser(arc))
+		Expect(e).ToNot(HaveOccurred())
+		Expect(r.List()).To(Equal([]string{"backup/store.db"}))
+	})
+})
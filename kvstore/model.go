@@ -0,0 +1,497 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package kvstore
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	arctps "github.com/nabbar/golib/archive/archive/types"
+	bolt "go.etcd.io/bbolt"
+)
+
+type kvs struct {
+	m sync.RWMutex
+	c Config
+	a cipher.AEAD // nil if not encrypted
+	d *bolt.DB
+}
+
+func (o *kvs) options() *bolt.Options {
+	return &bolt.Options{
+		Timeout:      o.c.OpenTimeout.Time(),
+		NoSync:       o.c.NoSync,
+		FreelistType: bolt.FreelistMapType,
+	}
+}
+
+func (o *kvs) open() error {
+	d, e := bolt.Open(o.c.Path, o.c.FileMode, o.options())
+
+	if e != nil {
+		return ErrorOpen.Error(e)
+	}
+
+	o.d = d
+	return nil
+}
+
+// db returns the store under read lock, the returned function must be called to release the lock.
+func (o *kvs) db() (*bolt.DB, func(), error) {
+	o.m.RLock()
+
+	if o.d == nil {
+		o.m.RUnlock()
+		return nil, nil, ErrorClosed.Error(nil)
+	}
+
+	return o.d, o.m.RUnlock, nil
+}
+
+func (o *kvs) Close() error {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if o.d == nil {
+		return nil
+	}
+
+	e := o.d.Close()
+	o.d = nil
+
+	return e
+}
+
+func (o *kvs) Path() string {
+	return o.c.Path
+}
+
+func (o *kvs) Buckets() ([]string, error) {
+	d, u, e := o.db()
+
+	if e != nil {
+		return nil, e
+	}
+
+	defer u()
+
+	var res = make([]string, 0)
+
+	e = d.View(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, _ *bolt.Bucket) error {
+			res = append(res, string(name))
+			return nil
+		})
+	})
+
+	if e != nil {
+		return nil, ErrorRead.Error(e)
+	}
+
+	return res, nil
+}
+
+func (o *kvs) DeleteBucket(bucket string) error {
+	if len(bucket) < 1 {
+		return ErrorParamEmpty.Error(nil)
+	}
+
+	d, u, e := o.db()
+
+	if e != nil {
+		return e
+	}
+
+	defer u()
+
+	e = d.Update(func(tx *bolt.Tx) error {
+		if er := tx.DeleteBucket([]byte(bucket)); er != nil && !errors.Is(er, bolt.ErrBucketNotFound) {
+			return er
+		}
+
+		return nil
+	})
+
+	if e != nil {
+		return ErrorBucket.Error(e)
+	}
+
+	return nil
+}
+
+func (o *kvs) Get(bucket string, key []byte) ([]byte, error) {
+	if len(bucket) < 1 || len(key) < 1 {
+		return nil, ErrorParamEmpty.Error(nil)
+	}
+
+	d, u, e := o.db()
+
+	if e != nil {
+		return nil, e
+	}
+
+	defer u()
+
+	var val []byte
+
+	e = d.View(func(tx *bolt.Tx) error {
+		if b := tx.Bucket([]byte(bucket)); b != nil {
+			if v := b.Get(key); v != nil {
+				// the value is only valid during the transaction
+				val = bytes.Clone(v)
+			}
+		}
+
+		return nil
+	})
+
+	if e != nil {
+		return nil, ErrorRead.Error(e)
+	}
+
+	return o.decrypt(bucket, key, val)
+}
+
+func (o *kvs) Put(bucket string, key, val []byte) error {
+	if len(bucket) < 1 || len(key) < 1 {
+		return ErrorParamEmpty.Error(nil)
+	}
+
+	val, e := o.encrypt(bucket, key, val)
+
+	if e != nil {
+		return e
+	}
+
+	d, u, e := o.db()
+
+	if e != nil {
+		return e
+	}
+
+	defer u()
+
+	e = d.Update(func(tx *bolt.Tx) error {
+		if b, er := tx.CreateBucketIfNotExists([]byte(bucket)); er != nil {
+			return er
+		} else {
+			return b.Put(key, val)
+		}
+	})
+
+	if e != nil {
+		return ErrorWrite.Error(e)
+	}
+
+	return nil
+}
+
+func (o *kvs) Delete(bucket string, key []byte) error {
+	if len(bucket) < 1 || len(key) < 1 {
+		return ErrorParamEmpty.Error(nil)
+	}
+
+	d, u, e := o.db()
+
+	if e != nil {
+		return e
+	}
+
+	defer u()
+
+	e = d.Update(func(tx *bolt.Tx) error {
+		if b := tx.Bucket([]byte(bucket)); b != nil {
+			return b.Delete(key)
+		}
+
+		return nil
+	})
+
+	if e != nil {
+		return ErrorWrite.Error(e)
+	}
+
+	return nil
+}
+
+func (o *kvs) Walk(bucket string, prefix []byte, fct FuncWalk) error {
+	if len(bucket) < 1 || fct == nil {
+		return ErrorParamEmpty.Error(nil)
+	}
+
+	d, u, e := o.db()
+
+	if e != nil {
+		return e
+	}
+
+	defer u()
+
+	return d.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+
+		if b == nil {
+			return nil
+		}
+
+		c := b.Cursor()
+
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			if v == nil {
+				// nested bucket
+				continue
+			}
+
+			r, er := o.decrypt(bucket, k, bytes.Clone(v))
+
+			if er != nil {
+				return er
+			} else if !fct(bytes.Clone(k), r) {
+				return nil
+			}
+		}
+
+		return nil
+	})
+}
+
+func (o *kvs) Count(bucket string) (int, error) {
+	if len(bucket) < 1 {
+		return 0, ErrorParamEmpty.Error(nil)
+	}
+
+	d, u, e := o.db()
+
+	if e != nil {
+		return 0, e
+	}
+
+	defer u()
+
+	var n int
+
+	e = d.View(func(tx *bolt.Tx) error {
+		if b := tx.Bucket([]byte(bucket)); b != nil {
+			n = b.Stats().KeyN
+		}
+
+		return nil
+	})
+
+	if e != nil {
+		return 0, ErrorRead.Error(e)
+	}
+
+	return n, nil
+}
+
+// Compact copies the store into a new file, then replaces the store file with it.
+func (o *kvs) Compact() error {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if o.d == nil {
+		return ErrorClosed.Error(nil)
+	}
+
+	var tmp = o.c.Path + ".compact"
+
+	_ = os.Remove(tmp)
+
+	dst, e := bolt.Open(tmp, o.c.FileMode, o.options())
+
+	if e != nil {
+		return ErrorCompact.Error(e)
+	}
+
+	if e = bolt.Compact(dst, o.d, o.c.CompactTxSize); e != nil {
+		_ = dst.Close()
+		_ = os.Remove(tmp)
+		return ErrorCompact.Error(e)
+	} else if e = dst.Close(); e != nil {
+		_ = os.Remove(tmp)
+		return ErrorCompact.Error(e)
+	}
+
+	if e = o.d.Close(); e != nil {
+		_ = os.Remove(tmp)
+		return ErrorCompact.Error(e)
+	}
+
+	o.d = nil
+
+	if e = os.Rename(tmp, o.c.Path); e != nil {
+		_ = os.Remove(tmp)
+
+		// keep the store usable with the previous file
+		if er := o.open(); er != nil {
+			return er
+		}
+
+		return ErrorCompact.Error(e)
+	}
+
+	return o.open()
+}
+
+func (o *kvs) Stats() Stats {
+	d, u, e := o.db()
+
+	if e != nil {
+		return Stats{}
+	}
+
+	defer u()
+
+	var (
+		s = d.Stats()
+		r = Stats{
+			FreePages: s.FreePageN,
+			FreeAlloc: s.FreeAlloc,
+		}
+	)
+
+	if i, er := os.Stat(o.c.Path); er == nil {
+		r.Size = i.Size()
+	}
+
+	return r
+}
+
+func (o *kvs) Backup(w io.Writer) (int64, error) {
+	if w == nil {
+		return 0, ErrorParamEmpty.Error(nil)
+	}
+
+	d, u, e := o.db()
+
+	if e != nil {
+		return 0, e
+	}
+
+	defer u()
+
+	var n int64
+
+	e = d.View(func(tx *bolt.Tx) error {
+		var er error
+		n, er = tx.WriteTo(w)
+		return er
+	})
+
+	if e != nil {
+		return n, ErrorBackup.Error(e)
+	}
+
+	return n, nil
+}
+
+func (o *kvs) BackupArchive(w arctps.Writer, name string) error {
+	if w == nil || len(name) < 1 {
+		return ErrorParamEmpty.Error(nil)
+	}
+
+	d, u, e := o.db()
+
+	if e != nil {
+		return e
+	}
+
+	defer u()
+
+	e = d.View(func(tx *bolt.Tx) error {
+		var (
+			r, p = io.Pipe()
+			c    = make(chan error, 1)
+		)
+
+		go func() {
+			_, er := tx.WriteTo(p)
+			_ = p.CloseWithError(er)
+			c <- er
+		}()
+
+		er := w.Add(&fileInfo{
+			n: path.Base(name),
+			s: tx.Size(),
+			m: o.c.FileMode,
+			t: time.Now(),
+		}, r, name, "")
+
+		// unblock the snapshot if the archive has not read all the data
+		_ = r.CloseWithError(io.ErrClosedPipe)
+
+		if e := <-c; er == nil && e != nil && !errors.Is(e, io.ErrClosedPipe) {
+			er = e
+		}
+
+		return er
+	})
+
+	if e != nil {
+		return ErrorBackup.Error(e)
+	}
+
+	return nil
+}
+
+type fileInfo struct {
+	n string      // name
+	s int64       // size
+	m os.FileMode // mode
+	t time.Time   // modification time
+}
+
+func (o *fileInfo) Name() string {
+	return o.n
+}
+
+func (o *fileInfo) Size() int64 {
+	return o.s
+}
+
+func (o *fileInfo) Mode() fs.FileMode {
+	return o.m
+}
+
+func (o *fileInfo) ModTime() time.Time {
+	return o.t
+}
+
+func (o *fileInfo) IsDir() bool {
+	return false
+}
+
+func (o *fileInfo) Sys() any {
+	return nil
+}
@@ -51,6 +51,9 @@ const (
 	ErrorLDAPAttributeEmpty
 	ErrorLDAPValidatorError
 	ErrorLDAPGroupNotFound
+	ErrorLDAPPoolClosed
+	ErrorLDAPPoolServer
+	ErrorLDAPOperation
 )
 
 func init() {
@@ -98,6 +101,12 @@ func getMessage(code liberr.CodeError) (message string) {
 		return "invalid validation config"
 	case ErrorLDAPGroupNotFound:
 		return "group not found"
+	case ErrorLDAPPoolClosed:
+		return "LDAP connection pool is closed"
+	case ErrorLDAPPoolServer:
+		return "no LDAP server of the pool is available"
+	case ErrorLDAPOperation:
+		return "LDAP operation occurs error"
 	}
 
 	return liberr.NullMessage
@@ -54,6 +54,7 @@ type HelperLDAP struct {
 	bindPass   string
 	ctx        context.Context
 	log        liblog.FuncLog
+	pool       *PoolLDAP
 }
 
 // NewLDAP build a new LDAP helper based on config struct given.
@@ -86,6 +87,7 @@ func (lc *HelperLDAP) Clone() *HelperLDAP {
 		bindPass:   lc.bindPass,
 		ctx:        lc.ctx,
 		log:        lc.log,
+		pool:       lc.pool,
 	}
 }
 
@@ -179,7 +181,7 @@ func (lc *HelperLDAP) ForceTLSMode(tlsMode TLSMode, tlsConfig *tls.Config) {
 	}
 }
 
-func (lc *HelperLDAP) dialTLS() (*ldap.Conn, liberr.Error) {
+func (lc *HelperLDAP) dialTLS(ctx context.Context) (*ldap.Conn, liberr.Error) {
 	d := net.Dialer{}
 	adr := lc.config.ServerAddr(true)
 
@@ -187,7 +189,7 @@ func (lc *HelperLDAP) dialTLS() (*ldap.Conn, liberr.Error) {
 		return nil, ErrorLDAPServerTLS.Error(fmt.Errorf("invalid port for LDAPS"))
 	}
 
-	c, err := d.DialContext(lc.ctx, "tcp", adr)
+	c, err := d.DialContext(ctx, "tcp", adr)
 
 	if err != nil {
 		if c != nil {
@@ -221,7 +223,7 @@ func (lc *HelperLDAP) dialTLS() (*ldap.Conn, liberr.Error) {
 	return l, nil
 }
 
-func (lc *HelperLDAP) dial() (*ldap.Conn, liberr.Error) {
+func (lc *HelperLDAP) dial(ctx context.Context) (*ldap.Conn, liberr.Error) {
 	d := net.Dialer{}
 	adr := lc.config.ServerAddr(false)
 
@@ -229,7 +231,7 @@ func (lc *HelperLDAP) dial() (*ldap.Conn, liberr.Error) {
 		return nil, ErrorLDAPServerTLS.Error(fmt.Errorf("invalid port for LDAP / LDAP+STARTLS"))
 	}
 
-	c, err := d.DialContext(lc.ctx, "tcp", adr)
+	c, err := d.DialContext(ctx, "tcp", adr)
 
 	if err != nil {
 		if c != nil {
@@ -267,7 +269,7 @@ func (lc *HelperLDAP) starttls(l *ldap.Conn) liberr.Error {
 	return nil
 }
 
-func (lc *HelperLDAP) tryConnect() (TLSMode, liberr.Error) {
+func (lc *HelperLDAP) tryConnect(ctx context.Context) (TLSMode, liberr.Error) {
 	if lc == nil {
 		return TLSModeNone, ErrorParamEmpty.Error(nil)
 	}
@@ -284,7 +286,7 @@ func (lc *HelperLDAP) tryConnect() (TLSMode, liberr.Error) {
 	}()
 
 	if lc.config.Portldaps != 0 {
-		l, err = lc.dialTLS()
+		l, err = lc.dialTLS(ctx)
 
 		lc.getLogEntryErr(loglvl.DebugLevel, err, "connecting ldap with tls mode '%s'", TLSModeTLS.String()).Check(loglvl.DebugLevel)
 
@@ -297,7 +299,7 @@ func (lc *HelperLDAP) tryConnect() (TLSMode, liberr.Error) {
 		return _TLSModeInit, ErrorLDAPServerConfig.Error(nil)
 	}
 
-	l, err = lc.dial()
+	l, err = lc.dial(ctx)
 	lc.getLogEntryErr(loglvl.DebugLevel, err, "connecting ldap with tls mode '%s'", TLSModeNone.String()).Check(loglvl.DebugLevel)
 
 	if err != nil {
@@ -314,59 +316,70 @@ func (lc *HelperLDAP) tryConnect() (TLSMode, liberr.Error) {
 	return TLSModeNone, nil
 }
 
-func (lc *HelperLDAP) connect() liberr.Error {
-	if lc == nil || lc.ctx == nil {
-		return ErrorLDAPContext.Error(ErrorParamEmpty.Error(nil))
-	}
+// open dial a new connection to the server following the tls mode, detecting it if not yet defined.
+func (lc *HelperLDAP) open(ctx context.Context) (*ldap.Conn, liberr.Error) {
+	var (
+		l   *ldap.Conn
+		err liberr.Error
+	)
 
-	if err := lc.ctx.Err(); err != nil {
-		return ErrorLDAPContext.Error(err)
-	}
+	if lc.tlsMode == _TLSModeInit {
+		m, e := lc.tryConnect(ctx)
 
-	if lc.conn == nil {
-		var (
-			l   *ldap.Conn
-			err liberr.Error
-		)
+		if e != nil {
+			return nil, e
+		}
 
-		if lc.tlsMode == _TLSModeInit {
-			m, e := lc.tryConnect()
+		lc.tlsMode = m
+	}
 
-			if e != nil {
-				return e
+	if lc.tlsMode == TLSModeTLS {
+		l, err = lc.dialTLS(ctx)
+		if err != nil {
+			if l != nil {
+				_ = l.Close()
 			}
-
-			lc.tlsMode = m
+			return nil, err
 		}
+	}
 
-		if lc.tlsMode == TLSModeTLS {
-			l, err = lc.dialTLS()
-			if err != nil {
-				if l != nil {
-					_ = l.Close()
-				}
-				return err
+	if lc.tlsMode == TLSModeNone || lc.tlsMode == TLSModeStarttls {
+		l, err = lc.dial(ctx)
+		if err != nil {
+			if l != nil {
+				_ = l.Close()
 			}
+			return nil, err
 		}
+	}
 
-		if lc.tlsMode == TLSModeNone || lc.tlsMode == TLSModeStarttls {
-			l, err = lc.dial()
-			if err != nil {
-				if l != nil {
-					_ = l.Close()
-				}
-				return err
+	if lc.tlsMode == TLSModeStarttls {
+		err = lc.starttls(l)
+		if err != nil {
+			if l != nil {
+				_ = l.Close()
 			}
+			return nil, err
 		}
+	}
 
-		if lc.tlsMode == TLSModeStarttls {
-			err = lc.starttls(l)
-			if err != nil {
-				if l != nil {
-					_ = l.Close()
-				}
-				return err
-			}
+	return l, nil
+}
+
+func (lc *HelperLDAP) connect() liberr.Error {
+	if lc == nil || lc.ctx == nil {
+		return ErrorLDAPContext.Error(ErrorParamEmpty.Error(nil))
+	}
+
+	if err := lc.ctx.Err(); err != nil {
+		return ErrorLDAPContext.Error(err)
+	}
+
+	if lc.conn == nil {
+		l, err := lc.open(lc.ctx)
+
+		if err != nil {
+			return err
 		}
 
 		lc.getLogEntry(loglvl.DebugLevel, "ldap connected").Log()
@@ -380,6 +393,8 @@ func (lc *HelperLDAP) connect() liberr.Error {
 func (lc *HelperLDAP) Check() liberr.Error {
 	if lc == nil {
 		return ErrorParamEmpty.Error(nil)
+	} else if lc.pool != nil {
+		return lc.pool.Check(lc.ctx)
 	}
 
 	if lc.conn == nil {
@@ -417,6 +432,17 @@ func (lc *HelperLDAP) AuthUser(username, password string) liberr.Error {
 		return ErrorParamEmpty.Error(nil)
 	}
 
+	return lc.AuthUserContext(lc.ctx, username, password)
+}
+
+// AuthUserContext used to test bind given user uid and password, the bind is aborted if the given context is done.
+func (lc *HelperLDAP) AuthUserContext(ctx context.Context, username, password string) liberr.Error {
+	if lc == nil || ctx == nil {
+		return ErrorParamEmpty.Error(nil)
+	} else if lc.pool != nil {
+		return lc.pool.AuthUser(ctx, username, password)
+	}
+
 	if err := lc.connect(); err != nil {
 		return err
 	}
@@ -425,7 +451,7 @@ func (lc *HelperLDAP) AuthUser(username, password string) liberr.Error {
 		return ErrorParamEmpty.Error(nil)
 	}
 
-	err := lc.conn.Bind(username, password)
+	err := bindContext(ctx, lc.conn, username, password)
 
 	return ErrorLDAPBind.IfError(err)
 }
@@ -436,7 +462,20 @@ func (lc *HelperLDAP) Connect() liberr.Error {
 		return ErrorParamEmpty.Error(nil)
 	}
 
-	if err := lc.AuthUser(lc.bindDN, lc.bindPass); err != nil {
+	return lc.ConnectContext(lc.ctx)
+}
+
+// ConnectContext used to connect and bind to server, the bind is aborted if the given context is done.
+func (lc *HelperLDAP) ConnectContext(ctx context.Context) liberr.Error {
+	if lc == nil || ctx == nil {
+		return ErrorParamEmpty.Error(nil)
+	} else if lc.pool != nil {
+		return lc.pool.Do(ctx, func(l *ldap.Conn) error {
+			return nil
+		})
+	}
+
+	if err := lc.AuthUserContext(ctx, lc.bindDN, lc.bindPass); err != nil {
 		return err
 	}
 
@@ -450,12 +489,6 @@ func (lc *HelperLDAP) runSearch(filter string, attributes []string) (*ldap.Searc
 		src *ldap.SearchResult
 	)
 
-	if e := lc.Connect(); e != nil {
-		return nil, e
-	}
-
-	defer lc.Close()
-
 	searchRequest := ldap.NewSearchRequest(
 		lc.config.Basedn,
 		ldap.ScopeWholeSubtree,
@@ -466,6 +499,16 @@ func (lc *HelperLDAP) runSearch(filter string, attributes []string) (*ldap.Searc
 		nil,
 	)
 
+	if lc.pool != nil {
+		return lc.pool.Search(lc.ctx, searchRequest)
+	}
+
+	if e := lc.Connect(); e != nil {
+		return nil, e
+	}
+
+	defer lc.Close()
+
 	if src, err = lc.conn.Search(searchRequest); err != nil {
 		return nil, ErrorLDAPSearch.Error(err)
 	}
@@ -0,0 +1,663 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package ldap
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/go-playground/validator/v10"
+	libctx "github.com/nabbar/golib/context"
+	libdur "github.com/nabbar/golib/duration"
+	liberr "github.com/nabbar/golib/errors"
+	liblog "github.com/nabbar/golib/logger"
+	loglvl "github.com/nabbar/golib/logger/level"
+)
+
+const (
+	// DefaultPoolMaxIdle is the default number of idle connections kept for each server.
+	DefaultPoolMaxIdle = 4
+	// DefaultPoolDialTimeout is the default timeout to dial and bind a new connection.
+	DefaultPoolDialTimeout = 10 * time.Second
+	// DefaultPoolHealthInterval is the default delay between two health probes and the delay a failed server is set aside.
+	DefaultPoolHealthInterval = 30 * time.Second
+)
+
+// PoolConfig defined a list of servers of the same directory used in failover order by the connection pool.
+type PoolConfig struct {
+	// Servers is the list of server of the directory, the first healthy one in the list is used.
+	Servers []Config `cloud:"servers" mapstructure:"servers" json:"servers" yaml:"servers" toml:"servers" validate:"required,min=1,dive"`
+	// MaxIdle is the number of idle connections kept for each server. By default, 4.
+	MaxIdle int `cloud:"max-idle" mapstructure:"max-idle" json:"max-idle" yaml:"max-idle" toml:"max-idle" validate:"gte=0"`
+	// Retries is the number of attempts made on the next servers for an operation failing on a network error.
+	Retries int `cloud:"retries" mapstructure:"retries" json:"retries" yaml:"retries" toml:"retries" validate:"gte=0"`
+	// DialTimeout is the timeout to dial and bind a new connection. By default, 10s.
+	DialTimeout libdur.Duration `cloud:"dial-timeout" mapstructure:"dial-timeout" json:"dial-timeout" yaml:"dial-timeout" toml:"dial-timeout"`
+	// HealthInterval is the delay between two health probes of the servers. By default, 30s.
+	HealthInterval libdur.Duration `cloud:"health-interval" mapstructure:"health-interval" json:"health-interval" yaml:"health-interval" toml:"health-interval"`
+}
+
+func (cnf PoolConfig) Validate() liberr.Error {
+	var e = ErrorLDAPValidatorError.Error(nil)
+
+	if err := validator.New().Struct(cnf); err != nil {
+		if er, ok := err.(*validator.InvalidValidationError); ok {
+			e.Add(er)
+		}
+
+		for _, er := range err.(validator.ValidationErrors) {
+			//nolint #goerr113
+			e.Add(fmt.Errorf("config field '%s' is not validated by constraint '%s'", er.Namespace(), er.ActualTag()))
+		}
+	}
+
+	if !e.HasParent() {
+		e = nil
+	}
+
+	return e
+}
+
+func (cnf PoolConfig) normalize() PoolConfig {
+	if cnf.MaxIdle < 1 {
+		cnf.MaxIdle = DefaultPoolMaxIdle
+	}
+
+	if cnf.DialTimeout <= 0 {
+		cnf.DialTimeout = libdur.ParseDuration(DefaultPoolDialTimeout)
+	}
+
+	if cnf.HealthInterval <= 0 {
+		cnf.HealthInterval = libdur.ParseDuration(DefaultPoolHealthInterval)
+	}
+
+	return cnf
+}
+
+// PoolStatus is the health status of one server of the pool.
+type PoolStatus struct {
+	Server    string    `json:"server"`
+	TLSMode   string    `json:"tls-mode"`
+	Healthy   bool      `json:"healthy"`
+	Idle      int       `json:"idle"`
+	DownUntil time.Time `json:"down-until,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+type poolServer struct {
+	m sync.Mutex
+	h *HelperLDAP // helper used to dial the server
+	i []*ldap.Conn
+	d atomic.Int64 // unix nano time until the server is set aside
+	e atomic.Value // last error as string
+}
+
+func (s *poolServer) healthy() bool {
+	return s.d.Load() <= time.Now().UnixNano()
+}
+
+func (s *poolServer) fail(err error, dly time.Duration) {
+	s.d.Store(time.Now().Add(dly).UnixNano())
+	s.e.Store(err.Error())
+	s.flush()
+}
+
+func (s *poolServer) success() {
+	s.d.Store(0)
+	s.e.Store("")
+}
+
+func (s *poolServer) get() *ldap.Conn {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	for len(s.i) > 0 {
+		l := s.i[len(s.i)-1]
+		s.i = s.i[:len(s.i)-1]
+
+		if !l.IsClosing() {
+			return l
+		}
+	}
+
+	return nil
+}
+
+func (s *poolServer) put(l *ldap.Conn, max int) {
+	if l == nil || l.IsClosing() {
+		return
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if len(s.i) >= max {
+		_ = l.Close()
+		return
+	}
+
+	s.i = append(s.i, l)
+}
+
+func (s *poolServer) flush() {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	for _, l := range s.i {
+		_ = l.Close()
+	}
+
+	s.i = s.i[:0]
+}
+
+func (s *poolServer) warn(err error, msg string) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.h.getLogEntryErr(loglvl.WarnLevel, err, msg).Check(loglvl.NilLevel)
+}
+
+func (s *poolServer) status() PoolStatus {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	var r = PoolStatus{
+		Server:  s.h.config.ServerAddr(s.h.tlsMode == TLSModeTLS),
+		TLSMode: s.h.tlsMode.String(),
+		Healthy: s.healthy(),
+		Idle:    len(s.i),
+	}
+
+	if !r.Healthy {
+		r.DownUntil = time.Unix(0, s.d.Load())
+	}
+
+	if i := s.e.Load(); i != nil {
+		r.Error = i.(string)
+	}
+
+	return r
+}
+
+// PoolLDAP struct use to manage a pool of bound connections over a list of servers of the same directory.
+// Each operation is run on the first healthy server, a server failing on a network error is set aside until
+// the next health probe and the operation is retried on the next server.
+type PoolLDAP struct {
+	m sync.RWMutex
+	c PoolConfig
+	s []*poolServer
+	u string // bind dn
+	p string // bind password
+	x context.Context
+	n context.CancelFunc
+	a []string // attributes
+	f atomic.Bool
+}
+
+// NewPool build a new LDAP connection pool based on config struct given and start the health probe.
+func NewPool(ctx context.Context, cnf *PoolConfig, attributes []string) (*PoolLDAP, liberr.Error) {
+	if cnf == nil || len(cnf.Servers) < 1 {
+		return nil, ErrorParamEmpty.Error(nil)
+	}
+
+	var (
+		x, n = context.WithCancel(libctx.IsolateParent(ctx))
+		p    = &PoolLDAP{
+			c: cnf.normalize(),
+			s: make([]*poolServer, 0, len(cnf.Servers)),
+			x: x,
+			n: n,
+			a: attributes,
+		}
+	)
+
+	for i := range p.c.Servers {
+		h, e := NewLDAP(x, &p.c.Servers[i], attributes)
+
+		if e != nil {
+			n()
+			return nil, e
+		}
+
+		p.s = append(p.s, &poolServer{h: h, i: make([]*ldap.Conn, 0, p.c.MaxIdle)})
+	}
+
+	go p.probe()
+
+	return p, nil
+}
+
+// SetLogger is used to specify the logger to be used for debug message.
+func (p *PoolLDAP) SetLogger(fct liblog.FuncLog) {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	for _, s := range p.s {
+		s.m.Lock()
+		s.h.SetLogger(fct)
+		s.m.Unlock()
+	}
+}
+
+// SetCredentials used to defined the BindDN and password for the pooled connections.
+// The idle connections bound with the previous credentials are closed.
+func (p *PoolLDAP) SetCredentials(user, pass string) {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	p.u = user
+	p.p = pass
+
+	for _, s := range p.s {
+		s.m.Lock()
+		s.h.SetCredentials(user, pass)
+		s.m.Unlock()
+		s.flush()
+	}
+}
+
+// ForceTLSMode used to force tls mode and defined tls condition of all servers.
+func (p *PoolLDAP) ForceTLSMode(tlsMode TLSMode, tlsConfig *tls.Config) {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	for _, s := range p.s {
+		s.m.Lock()
+		s.h.ForceTLSMode(tlsMode, tlsConfig)
+		s.m.Unlock()
+		s.flush()
+	}
+}
+
+// Helper returns a LDAP helper running all its requests and binds through the pool.
+func (p *PoolLDAP) Helper(ctx context.Context) *HelperLDAP {
+	p.m.RLock()
+	defer p.m.RUnlock()
+
+	if ctx == nil {
+		ctx = p.x
+	}
+
+	p.s[0].m.Lock()
+	h := p.s[0].h.Clone()
+	p.s[0].m.Unlock()
+
+	h.Attributes = append(make([]string, 0, len(p.a)), p.a...)
+	h.ctx = ctx
+	h.pool = p
+
+	return h
+}
+
+// Status returns the health status of each server in failover order.
+func (p *PoolLDAP) Status() []PoolStatus {
+	var r = make([]PoolStatus, 0, len(p.s))
+
+	for _, s := range p.s {
+		r = append(r, s.status())
+	}
+
+	return r
+}
+
+// Close stops the health probe and closes all idle connections.
+func (p *PoolLDAP) Close() {
+	if !p.f.CompareAndSwap(false, true) {
+		return
+	}
+
+	p.n()
+
+	for _, s := range p.s {
+		s.flush()
+	}
+}
+
+// Check probes all servers and returns an error if none of them is reachable.
+func (p *PoolLDAP) Check(ctx context.Context) liberr.Error {
+	if p.f.Load() {
+		return ErrorLDAPPoolClosed.Error(nil)
+	}
+
+	var (
+		err = ErrorLDAPPoolServer.Error(nil)
+		one bool
+	)
+
+	for _, s := range p.s {
+		if e := p.check(ctx, s); e != nil {
+			err.Add(e)
+		} else {
+			one = true
+		}
+	}
+
+	if one {
+		return nil
+	}
+
+	return err
+}
+
+// Do run the given function with a bound connection of the first healthy server.
+// If the function fails on a network error, the server is set aside and the function is run again
+// on the next server up to the number of retries. The connection is aborted if the context is done.
+// Any other error is returned with its own code if it's a liberr.Error, or as parent of an ErrorLDAPOperation.
+func (p *PoolLDAP) Do(ctx context.Context, fct func(l *ldap.Conn) error) liberr.Error {
+	if p.f.Load() {
+		return ErrorLDAPPoolClosed.Error(nil)
+	} else if ctx == nil || fct == nil {
+		return ErrorParamEmpty.Error(nil)
+	}
+
+	var (
+		lst = p.order()
+		err = ErrorLDAPPoolServer.Error(nil)
+	)
+
+	for i := 0; i <= p.c.Retries; i++ {
+		if e := ctx.Err(); e != nil {
+			return ErrorLDAPContext.Error(e)
+		}
+
+		s := lst[i%len(lst)]
+		l, e := p.conn(ctx, s)
+
+		if e != nil {
+			err.Add(e)
+			continue
+		}
+
+		if r := runContext(ctx, l, fct); r == nil {
+			s.put(l, p.c.MaxIdle)
+			return nil
+		} else if ctx.Err() != nil {
+			return ErrorLDAPContext.Error(r)
+		} else if !isNetworkError(l, r) {
+			s.put(l, p.c.MaxIdle)
+			return operationError(r)
+		} else {
+			_ = l.Close()
+			s.fail(r, p.c.HealthInterval.Time())
+			s.warn(r, "ldap server set aside after a network error")
+			err.Add(r)
+		}
+	}
+
+	return err
+}
+
+// Search run the given search request through the pool.
+func (p *PoolLDAP) Search(ctx context.Context, req *ldap.SearchRequest) (*ldap.SearchResult, liberr.Error) {
+	var res *ldap.SearchResult
+
+	if req == nil {
+		return nil, ErrorParamEmpty.Error(nil)
+	}
+
+	e := p.Do(ctx, func(l *ldap.Conn) error {
+		var err error
+		res, err = l.Search(req)
+		return err
+	})
+
+	if e != nil && e.IsCode(ErrorLDAPOperation) {
+		return nil, ErrorLDAPSearch.Error(e)
+	} else if e != nil {
+		return nil, e
+	}
+
+	return res, nil
+}
+
+// AuthUser used to test bind given user dn and password on a dedicated connection with failover on the servers.
+func (p *PoolLDAP) AuthUser(ctx context.Context, username, password string) liberr.Error {
+	if p.f.Load() {
+		return ErrorLDAPPoolClosed.Error(nil)
+	} else if ctx == nil || username == "" || password == "" {
+		return ErrorParamEmpty.Error(nil)
+	}
+
+	var (
+		lst = p.order()
+		err = ErrorLDAPPoolServer.Error(nil)
+	)
+
+	for i := 0; i <= p.c.Retries; i++ {
+		if e := ctx.Err(); e != nil {
+			return ErrorLDAPContext.Error(e)
+		}
+
+		s := lst[i%len(lst)]
+		l, e := p.open(ctx, s)
+
+		if e != nil {
+			s.fail(e, p.c.HealthInterval.Time())
+			err.Add(e)
+			continue
+		}
+
+		r := bindContext(ctx, l, username, password)
+		_ = l.Close()
+
+		if r == nil {
+			return nil
+		} else if ctx.Err() != nil {
+			return ErrorLDAPContext.Error(r)
+		} else if !isNetworkError(l, r) {
+			return ErrorLDAPBind.Error(r)
+		}
+
+		s.fail(r, p.c.HealthInterval.Time())
+		err.Add(r)
+	}
+
+	return err
+}
+
+// order returns the servers in failover order: healthy servers first, then the servers set aside.
+func (p *PoolLDAP) order() []*poolServer {
+	var (
+		r = make([]*poolServer, 0, len(p.s))
+		d = make([]*poolServer, 0)
+	)
+
+	for _, s := range p.s {
+		if s.healthy() {
+			r = append(r, s)
+		} else {
+			d = append(d, s)
+		}
+	}
+
+	return append(r, d...)
+}
+
+// conn returns an idle connection of the server or a new bound connection.
+func (p *PoolLDAP) conn(ctx context.Context, s *poolServer) (*ldap.Conn, liberr.Error) {
+	if l := s.get(); l != nil {
+		return l, nil
+	}
+
+	l, e := p.open(ctx, s)
+
+	if e != nil {
+		s.fail(e, p.c.HealthInterval.Time())
+		return nil, e
+	}
+
+	p.m.RLock()
+	u, w := p.u, p.p
+	p.m.RUnlock()
+
+	if u != "" || w != "" {
+		if r := bindContext(ctx, l, u, w); r != nil {
+			_ = l.Close()
+			return nil, ErrorLDAPBind.Error(r)
+		}
+	}
+
+	s.success()
+	return l, nil
+}
+
+// open dial a new connection to the server within the dial timeout.
+func (p *PoolLDAP) open(ctx context.Context, s *poolServer) (*ldap.Conn, liberr.Error) {
+	x, n := context.WithTimeout(ctx, p.c.DialTimeout.Time())
+	defer n()
+
+	// the dial is done with a copy of the helper, to not block the other callers of the server during the dial.
+	s.m.Lock()
+	h := s.h.Clone()
+	s.m.Unlock()
+
+	l, e := h.open(x)
+
+	if e != nil {
+		return nil, e
+	}
+
+	// publish the tls mode detected by the first dial, unless it has been forced meanwhile.
+	s.m.Lock()
+	if s.h.tlsMode == _TLSModeInit {
+		s.h.tlsMode = h.tlsMode
+	}
+	s.m.Unlock()
+
+	return l, nil
+}
+
+// check probes the server with a root DSE search on an idle or new connection.
+func (p *PoolLDAP) check(ctx context.Context, s *poolServer) liberr.Error {
+	x, n := context.WithTimeout(ctx, p.c.DialTimeout.Time())
+	defer n()
+
+	l, e := p.conn(x, s)
+
+	if e != nil {
+		return e
+	}
+
+	r := runContext(x, l, func(c *ldap.Conn) error {
+		_, err := c.Search(ldap.NewSearchRequest("", ldap.ScopeBaseObject, ldap.NeverDerefAliases, 0, 0, false, "(objectClass=*)", []string{"1.1"}, nil))
+		return err
+	})
+
+	if r != nil && isNetworkError(l, r) {
+		_ = l.Close()
+		s.fail(r, p.c.HealthInterval.Time())
+		return ErrorLDAPServerConnection.Error(r)
+	}
+
+	s.success()
+	s.put(l, p.c.MaxIdle)
+
+	return nil
+}
+
+func (p *PoolLDAP) probe() {
+	var t = time.NewTicker(p.c.HealthInterval.Time())
+	defer t.Stop()
+
+	for {
+		select {
+		case <-p.x.Done():
+			return
+		case <-t.C:
+			for _, s := range p.s {
+				if e := p.check(p.x, s); e != nil {
+					s.warn(e, "ldap server health probe failed")
+				}
+			}
+		}
+	}
+}
+
+// isNetworkError returns true if the error is a connection failure and not a result of the server.
+func isNetworkError(l *ldap.Conn, err error) bool {
+	var (
+		e *ldap.Error
+		c *errCallback
+	)
+
+	if err == nil || errors.As(err, &c) {
+		return false
+	} else if l != nil && l.IsClosing() {
+		return true
+	} else if errors.As(err, &e) {
+		return e.ResultCode == ldap.ErrorNetwork || e.ResultCode == ldap.LDAPResultUnavailable || e.ResultCode == ldap.LDAPResultBusy
+	}
+
+	return true
+}
+
+// operationError return the error of an operation run through the pool. A liberr.Error is returned as is
+// to keep its code, any other error is the parent of an ErrorLDAPOperation.
+func operationError(err error) liberr.Error {
+	var e liberr.Error
+
+	if errors.As(err, &e) {
+		return e
+	}
+
+	return ErrorLDAPOperation.Error(err)
+}
+
+// runContext run the function with the connection and closes the connection if the context is done before the end.
+func runContext(ctx context.Context, l *ldap.Conn, fct func(l *ldap.Conn) error) error {
+	if d, ok := ctx.Deadline(); ok {
+		l.SetTimeout(time.Until(d))
+		defer l.SetTimeout(ldap.DefaultTimeout)
+	}
+
+	var c = make(chan error, 1)
+
+	go func() {
+		c <- fct(l)
+	}()
+
+	select {
+	case err := <-c:
+		return err
+	case <-ctx.Done():
+		_ = l.Close()
+		<-c
+		return ctx.Err()
+	}
+}
+
+// bindContext bind the connection with the given credentials, the bind is aborted if the context is done.
+func bindContext(ctx context.Context, l *ldap.Conn, username, password string) error {
+	return runContext(ctx, l, func(c *ldap.Conn) error {
+		return c.Bind(username, password)
+	})
+}
@@ -0,0 +1,319 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package ldap
+
+import (
+	"context"
+
+	"github.com/go-ldap/ldap/v3"
+	liberr "github.com/nabbar/golib/errors"
+	loglvl "github.com/nabbar/golib/logger/level"
+)
+
+const (
+	// DefaultPageSize is the page size used by SearchPaged if none is given.
+	DefaultPageSize uint32 = 500
+	// syncBufferSize is the number of messages buffered while the sync callbacks are running.
+	syncBufferSize = 64
+)
+
+// FuncEntry is called for each entry of a paged search, returning an error stops the search.
+type FuncEntry func(entry *ldap.Entry) error
+
+// FuncSyncEntry is called for each change of a sync search, returning an error stops the sync.
+type FuncSyncEntry func(evt SyncEvent) error
+
+// FuncSyncCookie is called each time the server sends a new sync cookie. The cookie can be stored
+// to resume the sync later, returning an error stops the sync.
+type FuncSyncCookie func(cookie []byte) error
+
+// SyncEvent is a change sent by the server during a sync search.
+// The entry is nil for the delete events sent as a list of uuid during the refresh phase.
+type SyncEvent struct {
+	State ldap.ControlSyncStateState
+	UUID  string
+	Entry *ldap.Entry
+}
+
+// SyncRequest defined a RFC 4533 content synchronization (syncrepl) search.
+type SyncRequest struct {
+	// Filter is the search filter of the synchronized entries.
+	Filter string
+	// Attributes is the list of attributes returned for each entry.
+	Attributes []string
+	// Persist keep the search open after the refresh phase to receive the changes until the context is done.
+	Persist bool
+	// Cookie is the cookie of a previous sync to receive only the changes since this one.
+	Cookie []byte
+	// OnEntry is called for each change.
+	OnEntry FuncSyncEntry
+	// OnCookie is called for each new cookie.
+	OnCookie FuncSyncCookie
+}
+
+// errCallback wraps an error returned by a callback to not take it for a network error.
+type errCallback struct {
+	e error
+}
+
+func (e *errCallback) Error() string {
+	return e.e.Error()
+}
+
+func (e *errCallback) Unwrap() error {
+	return e.e
+}
+
+// run call the function with a bound connection of the pool or of the helper, aborting it if the context is done.
+func (lc *HelperLDAP) run(ctx context.Context, fct func(l *ldap.Conn) error) liberr.Error {
+	if lc.pool != nil {
+		if e := lc.pool.Do(ctx, fct); e != nil && e.IsCode(ErrorLDAPOperation) {
+			return ErrorLDAPSearch.Error(e)
+		} else {
+			return e
+		}
+	}
+
+	if e := lc.ConnectContext(ctx); e != nil {
+		return e
+	}
+
+	defer lc.Close()
+
+	if err := runContext(ctx, lc.conn, fct); err != nil {
+		if ctx.Err() != nil {
+			return ErrorLDAPContext.Error(err)
+		}
+
+		return ErrorLDAPSearch.Error(err)
+	}
+
+	return nil
+}
+
+// SearchPaged used to run a search with the RFC 2696 paged results control and call the function for each entry.
+// Only one page of entries is kept in memory, a page size of 0 means DefaultPageSize.
+func (lc *HelperLDAP) SearchPaged(ctx context.Context, filter string, attributes []string, pageSize uint32, fct FuncEntry) liberr.Error {
+	if lc == nil || ctx == nil || fct == nil {
+		return ErrorParamEmpty.Error(nil)
+	}
+
+	if pageSize < 1 {
+		pageSize = DefaultPageSize
+	}
+
+	var nbr int
+
+	err := lc.run(ctx, func(l *ldap.Conn) error {
+		nbr = 0
+
+		req := ldap.NewSearchRequest(
+			lc.config.Basedn,
+			ldap.ScopeWholeSubtree,
+			ldap.NeverDerefAliases,
+			0, 0, false,
+			filter,
+			attributes,
+			nil,
+		)
+
+		return searchPaged(l, req, pageSize, func(entry *ldap.Entry) error {
+			nbr++
+			return fct(entry)
+		})
+	})
+
+	if err != nil {
+		return err
+	}
+
+	lc.getLogEntry(loglvl.DebugLevel, "ldap paged search success").FieldAdd("ldap.filter", filter).FieldAdd("ldap.entries", nbr).Log()
+	return nil
+}
+
+// Sync used to run a RFC 4533 content synchronization search and call the callbacks for each change and cookie.
+// In persist mode, the sync runs until the context is done and returns nil. With a pool, the sync is resumed
+// on the next server with the last received cookie if the connection is lost.
+func (lc *HelperLDAP) Sync(ctx context.Context, req SyncRequest) liberr.Error {
+	if lc == nil || ctx == nil || req.OnEntry == nil {
+		return ErrorParamEmpty.Error(nil)
+	}
+
+	var (
+		cok = req.Cookie
+		nbr int
+	)
+
+	err := lc.run(ctx, func(l *ldap.Conn) error {
+		r := ldap.NewSearchRequest(
+			lc.config.Basedn,
+			ldap.ScopeWholeSubtree,
+			ldap.NeverDerefAliases,
+			0, 0, false,
+			req.Filter,
+			req.Attributes,
+			nil,
+		)
+
+		return syncRepl(ctx, l, r, req.Persist, cok, func(evt SyncEvent) error {
+			nbr++
+			return req.OnEntry(evt)
+		}, func(cookie []byte) error {
+			cok = cookie
+
+			if req.OnCookie != nil {
+				return req.OnCookie(cookie)
+			}
+
+			return nil
+		})
+	})
+
+	if err != nil && !(req.Persist && ctx.Err() != nil) {
+		return err
+	}
+
+	lc.getLogEntry(loglvl.DebugLevel, "ldap sync done").FieldAdd("ldap.filter", req.Filter).FieldAdd("ldap.changes", nbr).Log()
+	return nil
+}
+
+// searchPaged run the search page by page. If the callback stops the search, the server is notified
+// with an empty page to release the paging cursor.
+func searchPaged(l *ldap.Conn, req *ldap.SearchRequest, size uint32, fct FuncEntry) error {
+	var ctl = ldap.NewControlPaging(size)
+	req.Controls = append(req.Controls, ctl)
+
+	for {
+		res, err := l.Search(req)
+
+		if err != nil {
+			return err
+		}
+
+		var cok []byte
+
+		if p, k := ldap.FindControl(res.Controls, ldap.ControlTypePaging).(*ldap.ControlPaging); k {
+			cok = p.Cookie
+		}
+
+		for _, e := range res.Entries {
+			if err = fct(e); err != nil {
+				if len(cok) > 0 {
+					ctl.PagingSize = 0
+					ctl.SetCookie(cok)
+					_, _ = l.Search(req)
+				}
+
+				return &errCallback{e: err}
+			}
+		}
+
+		if len(cok) < 1 {
+			return nil
+		}
+
+		ctl.SetCookie(cok)
+	}
+}
+
+// syncRepl run the sync search and dispatch the entries, the sync info messages and the sync done cookie.
+func syncRepl(ctx context.Context, l *ldap.Conn, req *ldap.SearchRequest, persist bool, cookie []byte, fct FuncSyncEntry, cok FuncSyncCookie) error {
+	var mod = ldap.SyncRequestModeRefreshOnly
+
+	if persist {
+		mod = ldap.SyncRequestModeRefreshAndPersist
+	}
+
+	res := l.Syncrepl(ctx, req, syncBufferSize, mod, cookie, false)
+
+	for res.Next() {
+		if e := res.Entry(); e != nil {
+			var evt = SyncEvent{
+				State: ldap.SyncStateAdd,
+				Entry: e,
+			}
+
+			if s, k := ldap.FindControl(res.Controls(), ldap.ControlTypeSyncState).(*ldap.ControlSyncState); k {
+				evt.State = s.State
+				evt.UUID = s.EntryUUID.String()
+
+				if err := fct(evt); err != nil {
+					return &errCallback{e: err}
+				} else if len(s.Cookie) > 0 {
+					if err = cok(s.Cookie); err != nil {
+						return &errCallback{e: err}
+					}
+				}
+			} else if err := fct(evt); err != nil {
+				return &errCallback{e: err}
+			}
+
+			continue
+		}
+
+		for _, c := range res.Controls() {
+			if err := syncControl(c, fct, cok); err != nil {
+				return &errCallback{e: err}
+			}
+		}
+	}
+
+	return res.Err()
+}
+
+func syncControl(c ldap.Control, fct FuncSyncEntry, cok FuncSyncCookie) error {
+	var nwc []byte
+
+	switch v := c.(type) {
+	case *ldap.ControlSyncDone:
+		nwc = v.Cookie
+	case *ldap.ControlSyncInfo:
+		switch {
+		case v.NewCookie != nil:
+			nwc = v.NewCookie.Cookie
+		case v.RefreshDelete != nil:
+			nwc = v.RefreshDelete.Cookie
+		case v.RefreshPresent != nil:
+			nwc = v.RefreshPresent.Cookie
+		case v.SyncIdSet != nil:
+			nwc = v.SyncIdSet.Cookie
+
+			if v.SyncIdSet.RefreshDeletes {
+				for _, u := range v.SyncIdSet.SyncUUIDs {
+					if err := fct(SyncEvent{State: ldap.SyncStateDelete, UUID: u.String()}); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+
+	if len(nwc) > 0 {
+		return cok(nwc)
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package leader
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	libval "github.com/go-playground/validator/v10"
+	libdur "github.com/nabbar/golib/duration"
+)
+
+const (
+	// DefaultTTL is the default duration of the leadership lease.
+	DefaultTTL = 15 * time.Second
+)
+
+type Config struct {
+	// Identity is the unique identity of the candidate. By default, the hostname followed by the process id.
+	Identity string `json:"identity,omitempty" yaml:"identity,omitempty" toml:"identity,omitempty" mapstructure:"identity,omitempty" validate:"omitempty,printascii"`
+
+	// TTL is the duration of the leadership lease: a leader not able to renew its lease
+	// during this duration loses the leadership. By default, 15s.
+	TTL libdur.Duration `json:"ttl,omitempty" yaml:"ttl,omitempty" toml:"ttl,omitempty" mapstructure:"ttl,omitempty"`
+
+	// RenewInterval is the duration between two attempts to acquire or renew the lease,
+	// it must be lower than the TTL. By default, a third of the TTL.
+	RenewInterval libdur.Duration `json:"renew-interval,omitempty" yaml:"renew-interval,omitempty" toml:"renew-interval,omitempty" mapstructure:"renew-interval,omitempty"`
+}
+
+func (c Config) Validate() error {
+	err := ErrorValidatorError.Error(nil)
+
+	if er := libval.New().Struct(c); er != nil {
+		if e, ok := er.(*libval.InvalidValidationError); ok {
+			err.Add(e)
+		}
+
+		for _, e := range er.(libval.ValidationErrors) {
+			//nolint goerr113
+			err.Add(fmt.Errorf("config field '%s' is not validated by constraint '%s'", e.Namespace(), e.ActualTag()))
+		}
+	}
+
+	if c.TTL < 0 || c.RenewInterval < 0 {
+		//nolint goerr113
+		err.Add(fmt.Errorf("config durations must not be negative"))
+	} else if c.TTL > 0 && c.RenewInterval >= c.TTL {
+		//nolint goerr113
+		err.Add(fmt.Errorf("config field 'RenewInterval' must be lower than field 'TTL'"))
+	}
+
+	if err.HasParent() {
+		return err
+	}
+
+	return nil
+}
+
+func (c *Config) normalize() {
+	if len(c.Identity) < 1 {
+		if h, e := os.Hostname(); e == nil && len(h) > 0 {
+			c.Identity = fmt.Sprintf("%s-%d", h, os.Getpid())
+		} else {
+			c.Identity = fmt.Sprintf("pid-%d", os.Getpid())
+		}
+	}
+
+	if c.TTL <= 0 {
+		c.TTL = libdur.ParseDuration(DefaultTTL)
+	}
+
+	if c.RenewInterval <= 0 || c.RenewInterval >= c.TTL {
+		c.RenewInterval = c.TTL / 3
+	}
+}
@@ -0,0 +1,98 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package file_test
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	libdur "github.com/nabbar/golib/duration"
+	libldr "github.com/nabbar/golib/leader"
+	ldrfil "github.com/nabbar/golib/leader/file"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Leader File", func() {
+	var (
+		ctx context.Context
+		pth string
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		pth = filepath.Join(GinkgoT().TempDir(), "leader.lock")
+	})
+
+	It("must require a path", func() {
+		_, e := ldrfil.New("")
+		Expect(e).To(HaveOccurred())
+	})
+
+	It("must allow only one holder of the file", func() {
+		one, e := ldrfil.New(pth)
+		Expect(e).ToNot(HaveOccurred())
+		two, e := ldrfil.New(pth)
+		Expect(e).ToNot(HaveOccurred())
+
+		Expect(two.Leader(ctx)).To(BeEmpty())
+
+		Expect(one.Acquire(ctx, "one", time.Second)).To(BeTrue())
+		Expect(one.Acquire(ctx, "one", time.Second)).To(BeTrue())
+		Expect(two.Acquire(ctx, "two", time.Second)).To(BeFalse())
+		Expect(two.Leader(ctx)).To(Equal("one"))
+
+		Expect(two.Release(ctx, "two")).ToNot(HaveOccurred())
+		Expect(one.Release(ctx, "one")).ToNot(HaveOccurred())
+		Expect(two.Leader(ctx)).To(BeEmpty())
+
+		Expect(two.Acquire(ctx, "two", time.Second)).To(BeTrue())
+		Expect(one.Leader(ctx)).To(Equal("two"))
+		Expect(two.Release(ctx, "two")).ToNot(HaveOccurred())
+	})
+
+	It("must elect a leader with the file lock", func() {
+		lck, e := ldrfil.New(pth)
+		Expect(e).ToNot(HaveOccurred())
+
+		l, e := libldr.New(libldr.Config{
+			Identity:      "elector",
+			TTL:           libdur.ParseDuration(300 * time.Millisecond),
+			RenewInterval: libdur.ParseDuration(20 * time.Millisecond),
+		}, lck)
+		Expect(e).ToNot(HaveOccurred())
+
+		Expect(l.Start(ctx)).ToNot(HaveOccurred())
+		Eventually(l.IsLeader).Should(BeTrue())
+		Expect(l.Leader(ctx)).To(Equal("elector"))
+
+		Expect(l.Stop(ctx)).ToNot(HaveOccurred())
+		Expect(l.IsLeader()).To(BeFalse())
+		Expect(lck.Leader(ctx)).To(BeEmpty())
+	})
+})
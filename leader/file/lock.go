@@ -0,0 +1,158 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package file
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	libldr "github.com/nabbar/golib/leader"
+)
+
+// New returns a leader lock using an advisory exclusive lock (flock) on the given file, to elect
+// a leader between the processes of a single host. The lease is held until released or until
+// the end of the process, so the ttl is not used. The identity of the leader is written into the file.
+func New(path string) (libldr.Lock, error) {
+	if len(path) < 1 {
+		return nil, ErrorParamEmpty.Error(nil)
+	}
+
+	return &lck{
+		p: path,
+	}, nil
+}
+
+type lck struct {
+	m sync.Mutex
+	p string
+	f *os.File // file locked while leader
+	i string   // identity holding the lock
+}
+
+func (o *lck) Acquire(ctx context.Context, id string, ttl time.Duration) (bool, error) {
+	if len(id) < 1 {
+		return false, ErrorParamEmpty.Error(nil)
+	}
+
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if o.f != nil {
+		return o.i == id, nil
+	}
+
+	f, e := os.OpenFile(o.p, os.O_RDWR|os.O_CREATE, 0600)
+
+	if e != nil {
+		return false, ErrorFileOpen.Error(e)
+	}
+
+	if k, e := lockExclusive(f); e != nil {
+		_ = f.Close()
+		return false, ErrorFileLock.Error(e)
+	} else if !k {
+		_ = f.Close()
+		return false, nil
+	}
+
+	if e = f.Truncate(0); e == nil {
+		_, e = f.WriteAt([]byte(id), 0)
+	}
+
+	if e != nil {
+		_ = unlock(f)
+		_ = f.Close()
+		return false, ErrorFileWrite.Error(e)
+	}
+
+	_ = f.Sync()
+
+	o.f = f
+	o.i = id
+
+	return true, nil
+}
+
+func (o *lck) Release(ctx context.Context, id string) error {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if o.f == nil || o.i != id {
+		return nil
+	}
+
+	_ = o.f.Truncate(0)
+	e := unlock(o.f)
+	_ = o.f.Close()
+
+	o.f = nil
+	o.i = ""
+
+	if e != nil {
+		return ErrorFileLock.Error(e)
+	}
+
+	return nil
+}
+
+func (o *lck) Leader(ctx context.Context) (string, error) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if o.f != nil {
+		return o.i, nil
+	}
+
+	f, e := os.Open(o.p)
+
+	if os.IsNotExist(e) {
+		return "", nil
+	} else if e != nil {
+		return "", ErrorFileOpen.Error(e)
+	}
+
+	defer func() {
+		_ = f.Close()
+	}()
+
+	// the file is free if a shared lock can be acquired
+	if k, e := lockShared(f); e != nil {
+		return "", ErrorFileLock.Error(e)
+	} else if k {
+		_ = unlock(f)
+		return "", nil
+	}
+
+	if b, e := io.ReadAll(f); e != nil {
+		return "", ErrorFileOpen.Error(e)
+	} else {
+		return strings.TrimSpace(string(b)), nil
+	}
+}
@@ -0,0 +1,67 @@
+//go:build !windows
+// +build !windows
+
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package file
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// lockExclusive tries to apply an exclusive advisory lock on the file without waiting,
+// it returns false if the file is already locked.
+func lockExclusive(f *os.File) (bool, error) {
+	return tryLock(f, syscall.LOCK_EX)
+}
+
+// lockShared tries to apply a shared advisory lock on the file without waiting,
+// it returns false if the file is exclusively locked.
+func lockShared(f *os.File) (bool, error) {
+	return tryLock(f, syscall.LOCK_SH)
+}
+
+func tryLock(f *os.File, how int) (bool, error) {
+	for {
+		e := syscall.Flock(int(f.Fd()), how|syscall.LOCK_NB)
+
+		if e == nil {
+			return true, nil
+		} else if errors.Is(e, syscall.EINTR) {
+			continue
+		} else if errors.Is(e, syscall.EWOULDBLOCK) {
+			return false, nil
+		}
+
+		return false, e
+	}
+}
+
+func unlock(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
@@ -0,0 +1,112 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package leader
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	liblog "github.com/nabbar/golib/logger"
+	libsrv "github.com/nabbar/golib/server"
+	librun "github.com/nabbar/golib/server/runner/startStop"
+)
+
+// Lock is the backend of the leader election: a lease held by only one candidate at a time.
+// It's the adapter interface to implement for distributed backends as etcd or consul.
+type Lock interface {
+	// Acquire tries to acquire or renew the lease for the identity during the ttl. It returns
+	// false without error if the lease is held by another identity.
+	Acquire(ctx context.Context, id string, ttl time.Duration) (bool, error)
+
+	// Release releases the lease if held by the identity.
+	Release(ctx context.Context, id string) error
+
+	// Leader returns the identity holding the lease, or an empty string if the lease is free.
+	Leader(ctx context.Context) (string, error)
+}
+
+// FuncGain is called when the leadership is gained, the context is cancelled when the leadership is lost.
+type FuncGain func(ctx context.Context)
+
+// FuncLoss is called when the leadership is lost.
+type FuncLoss func()
+
+// Elector is a candidate to the leadership: once started, it tries to acquire the lease
+// and renews it while leader. On stop, the lease is released if held.
+type Elector interface {
+	libsrv.Server
+
+	// Identity returns the identity of the candidate.
+	Identity() string
+
+	// IsLeader returns true if the candidate holds the leadership.
+	IsLeader() bool
+
+	// Leader returns the identity of the current leader, or an empty string if none.
+	Leader(ctx context.Context) (string, error)
+
+	// OnGain registers a function called into a new goroutine each time the leadership is gained.
+	OnGain(fct FuncGain)
+
+	// OnLoss registers a function called each time the leadership is lost.
+	OnLoss(fct FuncLoss)
+
+	// SetLogger is used to define the logger used to trace the changes of leadership.
+	SetLogger(fct liblog.FuncLog)
+
+	// HealthCheck returns an error if the election is not running or if the lock backend has failed.
+	HealthCheck(ctx context.Context) error
+}
+
+// New returns a candidate to the leadership using the given lock backend.
+func New(cfg Config, lck Lock) (Elector, error) {
+	if lck == nil {
+		return nil, ErrorParamEmpty.Error(nil)
+	} else if e := cfg.Validate(); e != nil {
+		return nil, e
+	}
+
+	cfg.normalize()
+
+	o := &elc{
+		m: sync.RWMutex{},
+		c: cfg,
+		k: lck,
+		g: make([]FuncGain, 0),
+		s: make([]FuncLoss, 0),
+	}
+
+	o.r = librun.New(o.runStart, o.runStop)
+
+	return o, nil
+}
+
+// NewMemory returns a Lock kept into the memory of the process,
+// useful to elect a leader between several components of a same process.
+func NewMemory() Lock {
+	return &mem{}
+}
@@ -0,0 +1,161 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package leader_test
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	libdur "github.com/nabbar/golib/duration"
+	libldr "github.com/nabbar/golib/leader"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func newElector(id string, lck libldr.Lock) libldr.Elector {
+	e, err := libldr.New(libldr.Config{
+		Identity:      id,
+		TTL:           libdur.ParseDuration(300 * time.Millisecond),
+		RenewInterval: libdur.ParseDuration(20 * time.Millisecond),
+	}, lck)
+
+	Expect(err).ToNot(HaveOccurred())
+	Expect(e).ToNot(BeNil())
+
+	return e
+}
+
+var _ = Describe("Leader", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	Context("config", func() {
+		It("must validate the config", func() {
+			_, e := libldr.New(libldr.Config{}, nil)
+			Expect(e).To(HaveOccurred())
+
+			_, e = libldr.New(libldr.Config{
+				TTL:           libdur.ParseDuration(time.Second),
+				RenewInterval: libdur.ParseDuration(2 * time.Second),
+			}, libldr.NewMemory())
+			Expect(e).To(HaveOccurred())
+
+			l, e := libldr.New(libldr.Config{}, libldr.NewMemory())
+			Expect(e).ToNot(HaveOccurred())
+			Expect(l.Identity()).ToNot(BeEmpty())
+		})
+	})
+
+	Context("memory lock", func() {
+		It("must hold the lease until expiration", func() {
+			lck := libldr.NewMemory()
+
+			Expect(lck.Acquire(ctx, "a", 50*time.Millisecond)).To(BeTrue())
+			Expect(lck.Acquire(ctx, "b", 50*time.Millisecond)).To(BeFalse())
+			Expect(lck.Leader(ctx)).To(Equal("a"))
+
+			Eventually(func() (bool, error) {
+				return lck.Acquire(ctx, "b", time.Second)
+			}).Should(BeTrue())
+
+			Expect(lck.Release(ctx, "a")).ToNot(HaveOccurred())
+			Expect(lck.Leader(ctx)).To(Equal("b"))
+			Expect(lck.Release(ctx, "b")).ToNot(HaveOccurred())
+			Expect(lck.Leader(ctx)).To(BeEmpty())
+		})
+	})
+
+	Context("election", func() {
+		It("must elect only one leader and fail over on stop", func() {
+			var (
+				lck = libldr.NewMemory()
+				one = newElector("one", lck)
+				two = newElector("two", lck)
+				gan atomic.Int32
+				los atomic.Int32
+				cnc atomic.Bool
+			)
+
+			one.OnGain(func(ctx context.Context) {
+				gan.Add(1)
+				<-ctx.Done()
+				cnc.Store(true)
+			})
+			one.OnLoss(func() {
+				los.Add(1)
+			})
+
+			Expect(one.HealthCheck(ctx)).To(HaveOccurred())
+			Expect(one.Start(ctx)).ToNot(HaveOccurred())
+			Eventually(one.IsLeader).Should(BeTrue())
+			Expect(one.HealthCheck(ctx)).ToNot(HaveOccurred())
+
+			Expect(two.Start(ctx)).ToNot(HaveOccurred())
+			defer func() { _ = two.Stop(ctx) }()
+
+			Consistently(two.IsLeader, 100*time.Millisecond).Should(BeFalse())
+			Expect(two.Leader(ctx)).To(Equal("one"))
+			Expect(gan.Load()).To(Equal(int32(1)))
+
+			Expect(one.Stop(ctx)).ToNot(HaveOccurred())
+			Expect(one.IsLeader()).To(BeFalse())
+			Expect(los.Load()).To(Equal(int32(1)))
+			Eventually(cnc.Load).Should(BeTrue())
+
+			Eventually(two.IsLeader).Should(BeTrue())
+			Expect(two.Leader(ctx)).To(Equal("two"))
+		})
+
+		It("must lose the leadership when the lease is taken", func() {
+			var (
+				lck = libldr.NewMemory()
+				one = newElector("one", lck)
+				los atomic.Int32
+			)
+
+			one.OnLoss(func() {
+				los.Add(1)
+			})
+
+			Expect(one.Start(ctx)).ToNot(HaveOccurred())
+			defer func() { _ = one.Stop(ctx) }()
+
+			Eventually(one.IsLeader).Should(BeTrue())
+
+			// simulate a lease taken by another candidate after expiration
+			Expect(lck.Release(ctx, "one")).ToNot(HaveOccurred())
+			Expect(lck.Acquire(ctx, "other", time.Minute)).To(BeTrue())
+
+			Eventually(one.IsLeader).Should(BeFalse())
+			Expect(los.Load()).To(Equal(int32(1)))
+		})
+	})
+})
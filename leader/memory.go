@@ -0,0 +1,79 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package leader
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type mem struct {
+	m sync.Mutex
+	i string    // identity holding the lease
+	t time.Time // expiration of the lease
+}
+
+func (o *mem) Acquire(ctx context.Context, id string, ttl time.Duration) (bool, error) {
+	if len(id) < 1 {
+		return false, ErrorParamEmpty.Error(nil)
+	}
+
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if o.i != "" && o.i != id && time.Now().Before(o.t) {
+		return false, nil
+	}
+
+	o.i = id
+	o.t = time.Now().Add(ttl)
+
+	return true, nil
+}
+
+func (o *mem) Release(ctx context.Context, id string) error {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if o.i == id {
+		o.i = ""
+		o.t = time.Time{}
+	}
+
+	return nil
+}
+
+func (o *mem) Leader(ctx context.Context) (string, error) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if o.i == "" || time.Now().After(o.t) {
+		return "", nil
+	}
+
+	return o.i, nil
+}
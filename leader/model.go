@@ -0,0 +1,283 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package leader
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	liblog "github.com/nabbar/golib/logger"
+	loglvl "github.com/nabbar/golib/logger/level"
+	librun "github.com/nabbar/golib/server/runner/startStop"
+)
+
+const (
+	defaultTimeoutRelease = 5 * time.Second
+)
+
+type elc struct {
+	m sync.RWMutex
+	c Config
+	k Lock
+	r librun.StartStop
+	l liblog.FuncLog
+	g []FuncGain
+	s []FuncLoss
+	d bool               // leader
+	n context.CancelFunc // leadership cancel
+	t time.Time          // last successful renew
+	e error              // last lock error
+}
+
+func (o *elc) SetLogger(fct liblog.FuncLog) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.l = fct
+}
+
+func (o *elc) logger() liblog.Logger {
+	o.m.RLock()
+	f := o.l
+	o.m.RUnlock()
+
+	if f != nil {
+		if l := f(); l != nil {
+			return l
+		}
+	}
+
+	return liblog.New(context.Background)
+}
+
+func (o *elc) Identity() string {
+	return o.c.Identity
+}
+
+func (o *elc) IsLeader() bool {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	return o.d
+}
+
+func (o *elc) Leader(ctx context.Context) (string, error) {
+	if i, e := o.k.Leader(ctx); e != nil {
+		return "", ErrorLock.Error(e)
+	} else {
+		return i, nil
+	}
+}
+
+func (o *elc) OnGain(fct FuncGain) {
+	if fct == nil {
+		return
+	}
+
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.g = append(o.g, fct)
+}
+
+func (o *elc) OnLoss(fct FuncLoss) {
+	if fct == nil {
+		return
+	}
+
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.s = append(o.s, fct)
+}
+
+func (o *elc) HealthCheck(ctx context.Context) error {
+	if !o.IsRunning() {
+		return ErrorNotRunning.Error(nil)
+	}
+
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	if o.e != nil {
+		return ErrorLock.Error(o.e)
+	}
+
+	return nil
+}
+
+func (o *elc) Start(ctx context.Context) error {
+	return o.r.Start(ctx)
+}
+
+func (o *elc) Stop(ctx context.Context) error {
+	return o.r.Stop(ctx)
+}
+
+func (o *elc) Restart(ctx context.Context) error {
+	return o.r.Restart(ctx)
+}
+
+func (o *elc) IsRunning() bool {
+	return o.r.IsRunning()
+}
+
+func (o *elc) Uptime() time.Duration {
+	return o.r.Uptime()
+}
+
+// runStart campaigns for the leadership on each renew interval until the context is done,
+// then releases the lease if held.
+func (o *elc) runStart(ctx context.Context) error {
+	var tck = time.NewTicker(o.c.RenewInterval.Time())
+
+	defer func() {
+		tck.Stop()
+		o.resign()
+	}()
+
+	for {
+		o.campaign(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-tck.C:
+		}
+	}
+}
+
+func (o *elc) runStop(ctx context.Context) error {
+	return nil
+}
+
+// campaign tries to acquire or renew the lease. On lock error, the leadership is kept
+// until the end of the lease as the other candidates cannot acquire it before.
+func (o *elc) campaign(ctx context.Context) {
+	k, e := o.k.Acquire(ctx, o.c.Identity, o.c.TTL.Time())
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	o.m.Lock()
+	o.e = e
+	ldr := o.d
+	lst := o.t
+
+	if e == nil && k {
+		o.t = time.Now()
+	}
+
+	o.m.Unlock()
+
+	if e != nil {
+		ent := o.logger().Entry(loglvl.WarnLevel, "acquiring leadership lease for '%s'", o.c.Identity)
+		ent.ErrorAdd(true, e)
+		ent.Log()
+
+		if ldr && time.Since(lst) >= o.c.TTL.Time() {
+			o.lose()
+		}
+	} else if k && !ldr {
+		o.gain(ctx)
+	} else if !k && ldr {
+		o.lose()
+	}
+}
+
+func (o *elc) gain(ctx context.Context) {
+	x, n := context.WithCancel(ctx)
+
+	o.m.Lock()
+	o.d = true
+	o.n = n
+	lst := append(make([]FuncGain, 0, len(o.g)), o.g...)
+	o.m.Unlock()
+
+	o.logger().Entry(loglvl.InfoLevel, "leadership gained by '%s'", o.c.Identity).Log()
+
+	for _, f := range lst {
+		go func(fct FuncGain) {
+			defer func() {
+				if r := recover(); r != nil {
+					o.logger().Entry(loglvl.ErrorLevel, "leadership gain function has panic").ErrorAdd(true, fmt.Errorf("%v", r)).Log()
+				}
+			}()
+
+			fct(x)
+		}(f)
+	}
+}
+
+func (o *elc) lose() {
+	o.m.Lock()
+
+	if !o.d {
+		o.m.Unlock()
+		return
+	}
+
+	o.d = false
+
+	if o.n != nil {
+		o.n()
+		o.n = nil
+	}
+
+	lst := append(make([]FuncLoss, 0, len(o.s)), o.s...)
+	o.m.Unlock()
+
+	o.logger().Entry(loglvl.InfoLevel, "leadership lost by '%s'", o.c.Identity).Log()
+
+	for _, f := range lst {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					o.logger().Entry(loglvl.ErrorLevel, "leadership loss function has panic").ErrorAdd(true, fmt.Errorf("%v", r)).Log()
+				}
+			}()
+
+			f()
+		}()
+	}
+}
+
+// resign loses the leadership if held and releases the lease.
+func (o *elc) resign() {
+	o.lose()
+
+	x, n := context.WithTimeout(context.Background(), defaultTimeoutRelease)
+	defer n()
+
+	if e := o.k.Release(x, o.c.Identity); e != nil {
+		ent := o.logger().Entry(loglvl.WarnLevel, "releasing leadership lease for '%s'", o.c.Identity)
+		ent.ErrorAdd(true, e)
+		ent.Log()
+	}
+}
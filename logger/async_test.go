@@ -0,0 +1,195 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package logger_test
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	liblog "github.com/nabbar/golib/logger"
+	logcfg "github.com/nabbar/golib/logger/config"
+	logasy "github.com/nabbar/golib/logger/hookasync"
+	loglvl "github.com/nabbar/golib/logger/level"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+// memHook is a hook keeping the messages of the entries in memory.
+type memHook struct {
+	m sync.Mutex
+	l []string
+	c bool
+}
+
+func (o *memHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (o *memHook) Fire(entry *logrus.Entry) error {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.l = append(o.l, entry.Message)
+	return nil
+}
+
+func (o *memHook) Messages() []string {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	return append(make([]string, 0, len(o.l)), o.l...)
+}
+
+func (o *memHook) Closed() bool {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	return o.c
+}
+
+func (o *memHook) Write(p []byte) (n int, err error) {
+	return len(p), nil
+}
+
+func (o *memHook) Close() error {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.c = true
+	return nil
+}
+
+func (o *memHook) RegisterHook(log *logrus.Logger) {
+	log.AddHook(o)
+}
+
+func (o *memHook) Run(ctx context.Context) {}
+
+func fireAsync(h logasy.HookAsync, msg ...string) {
+	for _, m := range msg {
+		Expect(h.Fire(&logrus.Entry{Level: logrus.InfoLevel, Message: m, Data: logrus.Fields{}})).To(Succeed())
+	}
+}
+
+var _ = Describe("Logger Async", func() {
+	Context("Queuing entries with a full queue", func() {
+		It("Must drop the new entries with the dropNew policy and flush the queue on close", func() {
+			var (
+				m = &memHook{}
+				h = logasy.New(m, &logcfg.OptionsAsync{Enable: true, QueueSize: 2, Policy: logcfg.AsyncPolicyDropNew})
+			)
+
+			fireAsync(h, "1", "2", "3", "4", "5")
+			Expect(h.Dropped()).To(BeEquivalentTo(3))
+			Expect(m.Messages()).To(BeEmpty())
+
+			Expect(h.Close()).To(Succeed())
+			Expect(m.Messages()).To(Equal([]string{"1", "2"}))
+			Expect(m.Closed()).To(BeTrue())
+		})
+
+		It("Must drop the oldest entries with the dropOldest policy", func() {
+			var (
+				m = &memHook{}
+				h = logasy.New(m, &logcfg.OptionsAsync{Enable: true, QueueSize: 2, Policy: logcfg.AsyncPolicyDropOldest})
+			)
+
+			fireAsync(h, "1", "2", "3", "4", "5")
+			Expect(h.Dropped()).To(BeEquivalentTo(3))
+
+			Expect(h.Close()).To(Succeed())
+			Expect(m.Messages()).To(Equal([]string{"4", "5"}))
+		})
+
+		It("Must write all the entries in order with the worker and the block policy", func() {
+			var (
+				m = &memHook{}
+				h = logasy.New(m, &logcfg.OptionsAsync{Enable: true, QueueSize: 2})
+				c = make(chan struct{})
+			)
+
+			go func() {
+				defer close(c)
+				h.Run(GetContext())
+			}()
+
+			fireAsync(h, "1", "2", "3", "4", "5")
+			Eventually(m.Messages, 5*time.Second, 10*time.Millisecond).Should(Equal([]string{"1", "2", "3", "4", "5"}))
+			Expect(h.Dropped()).To(BeZero())
+
+			Expect(h.Close()).To(Succeed())
+			Eventually(c, 5*time.Second).Should(BeClosed())
+		})
+	})
+
+	Context("Logging with async options", func() {
+		It("Must write the entries to the outputs", func() {
+			fsp, err := GetTempFile()
+			Expect(err).ToNot(HaveOccurred())
+
+			defer func() {
+				_ = DelTempFile(fsp)
+			}()
+
+			log := liblog.New(GetContext)
+			defer func() {
+				Expect(log.Close()).ToNot(HaveOccurred())
+			}()
+
+			log.SetLevel(loglvl.InfoLevel)
+			Expect(log.SetOptions(&logcfg.Options{
+				Async: &logcfg.OptionsAsync{
+					Enable:    true,
+					QueueSize: 16,
+					Policy:    logcfg.AsyncPolicyBlock,
+				},
+				LogFile: []logcfg.OptionsFile{
+					{
+						Filepath:   fsp,
+						Create:     true,
+						CreatePath: true,
+					},
+				},
+			})).ToNot(HaveOccurred())
+
+			// waiting the file hook is running
+			time.Sleep(500 * time.Millisecond)
+
+			log.Info("async info message", nil)
+
+			Eventually(func() string {
+				b, _ := os.ReadFile(fsp)
+				return string(b)
+			}, 5*time.Second, 50*time.Millisecond).Should(ContainSubstring("async info message"))
+
+			Expect(log.DroppedEntries()).To(BeZero())
+		})
+	})
+})
@@ -0,0 +1,93 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package logger
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+
+	loglvl "github.com/nabbar/golib/logger/level"
+)
+
+// getCallerUncached is the resolution of the caller without cache, used as reference.
+func getCallerUncached() runtime.Frame {
+	pcs := make([]uintptr, 10, 255)
+	n := runtime.Callers(1, pcs)
+
+	if n > 0 {
+		frames := runtime.CallersFrames(pcs[:n])
+		more := true
+
+		for more {
+			var frame runtime.Frame
+			frame, more = frames.Next()
+
+			if strings.Contains(frame.Function, _selfPackage) {
+				continue
+			}
+
+			return frame
+		}
+	}
+
+	return runtime.Frame{Function: "unknown", File: "unknown", Line: 0}
+}
+
+func newBenchLogger() *logger {
+	return New(context.Background).(*logger)
+}
+
+func BenchmarkCallerUncached(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		_ = getCallerUncached()
+	}
+}
+
+func BenchmarkCallerCached(b *testing.B) {
+	var l = newBenchLogger()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = l.getCaller()
+	}
+}
+
+func BenchmarkEntryTrace(b *testing.B) {
+	var l = newBenchLogger()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = l.newEntry(loglvl.InfoLevel, "benchmark", nil, nil, nil)
+	}
+}
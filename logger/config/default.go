@@ -38,6 +38,16 @@ var _defaultConfig = []byte(`
 {
    "inheritDefault": false,
    "traceFilter":"",
+   "async":{
+     "enable":false,
+     "queueSize":1024,
+     "policy":"block"
+   },
+   "redact":{
+     "fields":[],
+     "values":[],
+     "mask":"[REDACTED]"
+   },
    "stdout":{
      "disableStandard":false,
      "disableStack":false,
@@ -62,6 +72,7 @@ var _defaultConfig = []byte(`
          "fileMode":"0644",
          "pathMode":"0755",
          "file-buffer-size": "32KB",
+         "fileLock": false,
          "disableStack":false,
          "disableTimestamp":false,
          "enableTrace":true,
@@ -80,7 +91,6 @@ var _defaultConfig = []byte(`
          ],
          "network":"tcp",
          "host":"",
-         "severity":"Error",
          "facility":"local0",
          "tag":"",
          "disableStack":false,
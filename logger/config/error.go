@@ -36,6 +36,9 @@ import (
 const (
 	ErrorParamEmpty liberr.CodeError = iota + liberr.MinPkgLogger
 	ErrorValidatorError
+	ErrorRoutesInvalid
+	ErrorConfigDecode
+	ErrorRedactInvalid
 )
 
 func init() {
@@ -51,6 +54,12 @@ func getMessage(code liberr.CodeError) (message string) {
 		return "given parameters is empty"
 	case ErrorValidatorError:
 		return "logger : invalid config"
+	case ErrorRoutesInvalid:
+		return "logger : invalid routes syntax"
+	case ErrorConfigDecode:
+		return "logger : cannot decode config"
+	case ErrorRedactInvalid:
+		return "logger : invalid redaction rule"
 	}
 
 	return liberr.NullMessage
@@ -43,6 +43,10 @@ type Options struct {
 	// TraceFilter define the path to clean for trace.
 	TraceFilter string `json:"traceFilter,omitempty" yaml:"traceFilter,omitempty" toml:"traceFilter,omitempty" mapstructure:"traceFilter,omitempty"`
 
+	// TraceSkip define the number of frames to skip after the logger frames to find the caller of trace,
+	// used when the logger is called through helper functions.
+	TraceSkip int `json:"traceSkip,omitempty" yaml:"traceSkip,omitempty" toml:"traceSkip,omitempty" mapstructure:"traceSkip,omitempty" validate:"min=0"`
+
 	// Stdout define the options for stdout/stderr log.
 	Stdout *OptionsStd `json:"stdout,omitempty" yaml:"stdout,omitempty" toml:"stdout,omitempty" mapstructure:"stdout,omitempty"`
 
@@ -50,7 +54,7 @@ type Options struct {
 	LogFileExtend bool `json:"logFileExtend,omitempty" yaml:"logFileExtend,omitempty" toml:"logFileExtend,omitempty" mapstructure:"logFileExtend,omitempty"`
 
 	// LogFile define a list of log file configuration to allow log to files.
-	LogFile OptionsFiles `json:"logFile,omitempty" yaml:"logFile,omitempty" toml:"logFile,omitempty" mapstructure:"logFile,omitempty"`
+	LogFile OptionsFiles `json:"logFile,omitempty" yaml:"logFile,omitempty" toml:"logFile,omitempty" mapstructure:"logFile,omitempty" validate:"dive"`
 
 	// LogSyslogExtend define if the logFile given is in addition of default LogSyslog or a replacement.
 	LogSyslogExtend bool `json:"logSyslogExtend,omitempty" yaml:"logSyslogExtend,omitempty" toml:"logSyslogExtend,omitempty" mapstructure:"logSyslogExtend,omitempty"`
@@ -58,6 +62,25 @@ type Options struct {
 	// LogSyslog define a list of syslog configuration to allow log to syslog.
 	LogSyslog OptionsSyslogs `json:"logSyslog,omitempty" yaml:"logSyslog,omitempty" toml:"logSyslog,omitempty" mapstructure:"logSyslog,omitempty"`
 
+	// LogGelfExtend define if the logGelf given is in addition of default LogGelf or a replacement.
+	LogGelfExtend bool `json:"logGelfExtend,omitempty" yaml:"logGelfExtend,omitempty" toml:"logGelfExtend,omitempty" mapstructure:"logGelfExtend,omitempty"`
+
+	// LogGelf define a list of GELF server configuration to allow log to Graylog.
+	LogGelf OptionsGelfs `json:"logGelf,omitempty" yaml:"logGelf,omitempty" toml:"logGelf,omitempty" mapstructure:"logGelf,omitempty" validate:"dive"`
+
+	// LogJournald define the options to log to the systemd journal (linux only).
+	LogJournald *OptionsJournald `json:"logJournald,omitempty" yaml:"logJournald,omitempty" toml:"logJournald,omitempty" mapstructure:"logJournald,omitempty"`
+
+	// Routes define the outputs by level with a compact syntax, in addition of Stdout, LogFile and LogSyslog.
+	// Example: "error+ -> file:/var/log/err.log, syslog; debug -> stdout". See ParseRoutes for the full syntax.
+	Routes string `json:"routes,omitempty" yaml:"routes,omitempty" toml:"routes,omitempty" mapstructure:"routes,omitempty"`
+
+	// Async define the options to write the entries asynchronously to each output, protecting the caller of slow outputs.
+	Async *OptionsAsync `json:"async,omitempty" yaml:"async,omitempty" toml:"async,omitempty" mapstructure:"async,omitempty"`
+
+	// Redact define the rules to mask the sensitive fields and values of the entries before writing them to any output.
+	Redact *OptionsRedact `json:"redact,omitempty" yaml:"redact,omitempty" toml:"redact,omitempty" mapstructure:"redact,omitempty"`
+
 	// default options
 	opts FuncOpt
 }
@@ -84,6 +107,18 @@ func (o *Options) Validate() liberr.Error {
 		}
 	}
 
+	if len(o.Routes) > 0 {
+		if _, err := ParseRoutes(o.Routes); err != nil {
+			e.Add(err)
+		}
+	}
+
+	if o.Redact != nil {
+		if _, err := o.Redact.Compile(); err != nil {
+			e.Add(err)
+		}
+	}
+
 	if !e.HasParent() {
 		e = nil
 	}
@@ -92,18 +127,41 @@ func (o *Options) Validate() liberr.Error {
 }
 
 func (o *Options) Clone() Options {
-	var s *OptionsStd
+	var (
+		s *OptionsStd
+		a *OptionsAsync
+		r *OptionsRedact
+		j *OptionsJournald
+	)
 
 	if o.Stdout != nil {
 		s = o.Stdout.Clone()
 	}
 
+	if o.Async != nil {
+		a = o.Async.Clone()
+	}
+
+	if o.Redact != nil {
+		r = o.Redact.Clone()
+	}
+
+	if o.LogJournald != nil {
+		j = o.LogJournald.Clone()
+	}
+
 	return Options{
 		InheritDefault: o.InheritDefault,
 		TraceFilter:    o.TraceFilter,
+		TraceSkip:      o.TraceSkip,
 		Stdout:         s,
 		LogFile:        o.LogFile.Clone(),
 		LogSyslog:      o.LogSyslog.Clone(),
+		LogGelf:        o.LogGelf.Clone(),
+		LogJournald:    j,
+		Routes:         o.Routes,
+		Async:          a,
+		Redact:         r,
 	}
 }
 
@@ -112,6 +170,10 @@ func (o *Options) Merge(opt *Options) {
 		o.TraceFilter = opt.TraceFilter
 	}
 
+	if opt.TraceSkip > 0 {
+		o.TraceSkip = opt.TraceSkip
+	}
+
 	if opt.Stdout != nil {
 		if o.Stdout == nil {
 			o.Stdout = &OptionsStd{}
@@ -134,6 +196,29 @@ func (o *Options) Merge(opt *Options) {
 		if opt.Stdout.EnableAccessLog {
 			o.Stdout.EnableAccessLog = opt.Stdout.EnableAccessLog
 		}
+		if len(opt.Stdout.Format) > 0 {
+			o.Stdout.Format = opt.Stdout.Format
+		}
+		if len(opt.Stdout.LogLevelStdout) > 0 || len(opt.Stdout.LogLevelStderr) > 0 {
+			o.Stdout.LogLevelStdout = opt.Stdout.LogLevelStdout
+			o.Stdout.LogLevelStderr = opt.Stdout.LogLevelStderr
+		}
+	}
+
+	if len(opt.Routes) > 0 {
+		o.Routes = opt.Routes
+	}
+
+	if opt.Async != nil {
+		o.Async = opt.Async.Clone()
+	}
+
+	if opt.Redact != nil {
+		o.Redact = opt.Redact.Clone()
+	}
+
+	if opt.LogJournald != nil {
+		o.LogJournald = opt.LogJournald.Clone()
 	}
 
 	if opt.LogFileExtend {
@@ -148,6 +233,12 @@ func (o *Options) Merge(opt *Options) {
 		o.LogSyslog = opt.LogSyslog
 	}
 
+	if opt.LogGelfExtend {
+		o.LogGelf = append(o.LogGelf, opt.LogGelf...)
+	} else {
+		o.LogGelf = opt.LogGelf
+	}
+
 	if opt.opts != nil {
 		o.opts = opt.opts
 	}
@@ -164,6 +255,10 @@ func (o *Options) Options() *Options {
 		no.TraceFilter = o.TraceFilter
 	}
 
+	if o.TraceSkip > 0 {
+		no.TraceSkip = o.TraceSkip
+	}
+
 	if o.Stdout != nil {
 		if no.Stdout == nil {
 			no.Stdout = &OptionsStd{}
@@ -186,6 +281,29 @@ func (o *Options) Options() *Options {
 		if o.Stdout.EnableAccessLog {
 			no.Stdout.EnableAccessLog = o.Stdout.EnableAccessLog
 		}
+		if len(o.Stdout.Format) > 0 {
+			no.Stdout.Format = o.Stdout.Format
+		}
+		if len(o.Stdout.LogLevelStdout) > 0 || len(o.Stdout.LogLevelStderr) > 0 {
+			no.Stdout.LogLevelStdout = o.Stdout.LogLevelStdout
+			no.Stdout.LogLevelStderr = o.Stdout.LogLevelStderr
+		}
+	}
+
+	if len(o.Routes) > 0 {
+		no.Routes = o.Routes
+	}
+
+	if o.Async != nil {
+		no.Async = o.Async.Clone()
+	}
+
+	if o.Redact != nil {
+		no.Redact = o.Redact.Clone()
+	}
+
+	if o.LogJournald != nil {
+		no.LogJournald = o.LogJournald.Clone()
 	}
 
 	if o.LogFileExtend {
@@ -200,5 +318,11 @@ func (o *Options) Options() *Options {
 		no.LogSyslog = o.LogSyslog
 	}
 
+	if o.LogGelfExtend {
+		no.LogGelf = append(no.LogGelf, o.LogGelf...)
+	} else {
+		no.LogGelf = o.LogGelf
+	}
+
 	return &no
 }
@@ -0,0 +1,77 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package config
+
+const (
+	// AsyncPolicyBlock wait for a free place into the queue, the entries are never dropped.
+	AsyncPolicyBlock = "block"
+	// AsyncPolicyDropOldest remove the oldest entry of the queue to add the new one.
+	AsyncPolicyDropOldest = "dropOldest"
+	// AsyncPolicyDropNew drop the new entry.
+	AsyncPolicyDropNew = "dropNew"
+
+	// AsyncDefaultQueueSize is the size of the queue of each output if not defined.
+	AsyncDefaultQueueSize = 1024
+)
+
+type OptionsAsync struct {
+	// Enable allow to write the entries asynchronously: each entry is queued and written by a background worker for each output.
+	Enable bool `json:"enable,omitempty" yaml:"enable,omitempty" toml:"enable,omitempty" mapstructure:"enable,omitempty"`
+
+	// QueueSize define the max number of entries waiting to be written for each output. Zero means AsyncDefaultQueueSize.
+	QueueSize int `json:"queueSize,omitempty" yaml:"queueSize,omitempty" toml:"queueSize,omitempty" mapstructure:"queueSize,omitempty" validate:"min=0"`
+
+	// Policy define what to do with a new entry when the queue is full: block (default), dropOldest or dropNew.
+	Policy string `json:"policy,omitempty" yaml:"policy,omitempty" toml:"policy,omitempty" mapstructure:"policy,omitempty" validate:"omitempty,oneof=block dropOldest dropNew"`
+}
+
+func (o *OptionsAsync) Clone() *OptionsAsync {
+	return &OptionsAsync{
+		Enable:    o.Enable,
+		QueueSize: o.QueueSize,
+		Policy:    o.Policy,
+	}
+}
+
+// GetQueueSize return the size of the queue, or AsyncDefaultQueueSize if not defined.
+func (o *OptionsAsync) GetQueueSize() int {
+	if o.QueueSize > 0 {
+		return o.QueueSize
+	}
+
+	return AsyncDefaultQueueSize
+}
+
+// GetPolicy return the policy, or AsyncPolicyBlock if not defined.
+func (o *OptionsAsync) GetPolicy() string {
+	switch o.Policy {
+	case AsyncPolicyDropOldest, AsyncPolicyDropNew:
+		return o.Policy
+	default:
+		return AsyncPolicyBlock
+	}
+}
@@ -32,11 +32,17 @@ import (
 	libsiz "github.com/nabbar/golib/size"
 )
 
+// FileLevelPlaceholder is the placeholder of the level into the file path of a log file,
+// replaced by the lower case name of the level of each entry (ex: "/var/log/app/{level}.log").
+const FileLevelPlaceholder = "{level}"
+
 type OptionsFile struct {
 	// LogLevel define the allowed level of log for this file.
 	LogLevel []string `json:"logLevel,omitempty" yaml:"logLevel,omitempty" toml:"logLevel,omitempty" mapstructure:"logLevel,omitempty"`
 
 	// Filepath define the file path for log to file.
+	// If the path contains the FileLevelPlaceholder "{level}", each level is written into its own file
+	// (ex: "/var/log/app/{level}.log" write the errors into error.log and the debug into debug.log).
 	Filepath string `json:"filepath,omitempty" yaml:"filepath,omitempty" toml:"filepath,omitempty" mapstructure:"filepath,omitempty"`
 
 	// Create define if the log file must exist or can create it.
@@ -65,6 +71,24 @@ type OptionsFile struct {
 
 	// FileBufferSize define the size for buffer size (by default the buffer size is set to 32KB).
 	FileBufferSize libsiz.Size `json:"file-buffer-size,omitempty" yaml:"file-buffer-size,omitempty" toml:"file-buffer-size,omitempty" mapstructure:"file-buffer-size,omitempty"`
+
+	// FileLock enable an advisory exclusive lock (flock) on the log file for each buffer flush.
+	// This allows multiple processes to append to the same log file without interleaving partial lines.
+	// Each flush cost an additional lock/unlock syscall and may wait for others processes holding the lock.
+	// If the filesystem does not support locking, the lock is disabled and writes continue without lock.
+	// The lock is only available on linux, darwin and bsd systems, the option is ignored on others platforms.
+	FileLock bool `json:"fileLock,omitempty" yaml:"fileLock,omitempty" toml:"fileLock,omitempty" mapstructure:"fileLock,omitempty"`
+
+	// Format define the format of the messages: text (default), json or ecs (Elastic Common Schema).
+	Format string `json:"format,omitempty" yaml:"format,omitempty" toml:"format,omitempty" mapstructure:"format,omitempty" validate:"omitempty,oneof=text json ecs"`
+
+	// RotateCompress define the algorithm used to compress the rotated file when a rotation of the log file is detected
+	// (gzip, bzip2, lz4, xz, zstd, snappy, brotli). Empty or none disable the compression.
+	RotateCompress string `json:"rotateCompress,omitempty" yaml:"rotateCompress,omitempty" toml:"rotateCompress,omitempty" mapstructure:"rotateCompress,omitempty"`
+
+	// RotateKeep define the max number of rotated files kept when a rotation of the log file is detected, the oldest are removed.
+	// Zero keep all the rotated files.
+	RotateKeep int `json:"rotateKeep,omitempty" yaml:"rotateKeep,omitempty" toml:"rotateKeep,omitempty" mapstructure:"rotateKeep,omitempty" validate:"min=0"`
 }
 
 type OptionsFiles []OptionsFile
@@ -81,6 +105,11 @@ func (o OptionsFile) Clone() OptionsFile {
 		DisableTimestamp: o.DisableTimestamp,
 		EnableTrace:      o.EnableTrace,
 		EnableAccessLog:  o.EnableAccessLog,
+		FileBufferSize:   o.FileBufferSize,
+		FileLock:         o.FileLock,
+		Format:           o.Format,
+		RotateCompress:   o.RotateCompress,
+		RotateKeep:       o.RotateKeep,
 	}
 }
 
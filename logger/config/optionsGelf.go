@@ -0,0 +1,98 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package config
+
+const (
+	// GelfDefaultChunkSize is the max size of the udp datagrams if not defined, compatible with the WAN.
+	GelfDefaultChunkSize = 1420
+	// GelfMinChunkSize is the min size of the udp datagrams.
+	GelfMinChunkSize = 512
+	// GelfMaxChunkSize is the max size of the udp datagrams, only for LAN.
+	GelfMaxChunkSize = 8192
+)
+
+type OptionsGelf struct {
+	// LogLevel define the allowed level of log for this GELF server.
+	LogLevel []string `json:"logLevel,omitempty" yaml:"logLevel,omitempty" toml:"logLevel,omitempty" mapstructure:"logLevel,omitempty"`
+
+	// Network define the network used to connect to the GELF server: udp (default) or tcp.
+	Network string `json:"network,omitempty" yaml:"network,omitempty" toml:"network,omitempty" mapstructure:"network,omitempty" validate:"omitempty,oneof=udp udp4 udp6 tcp tcp4 tcp6"`
+
+	// Host define the address of the GELF server (host:port), required to use this output.
+	Host string `json:"host,omitempty" yaml:"host,omitempty" toml:"host,omitempty" mapstructure:"host,omitempty" validate:"omitempty,hostname_port"`
+
+	// Hostname define the source host of the messages. Empty means the hostname of the system.
+	Hostname string `json:"hostname,omitempty" yaml:"hostname,omitempty" toml:"hostname,omitempty" mapstructure:"hostname,omitempty"`
+
+	// ChunkSize define the max size of the udp datagrams, bigger messages are chunked. Zero means GelfDefaultChunkSize.
+	ChunkSize int `json:"chunkSize,omitempty" yaml:"chunkSize,omitempty" toml:"chunkSize,omitempty" mapstructure:"chunkSize,omitempty" validate:"omitempty,min=512,max=8192"`
+
+	// Compress allow to compress with gzip the udp messages.
+	Compress bool `json:"compress,omitempty" yaml:"compress,omitempty" toml:"compress,omitempty" mapstructure:"compress,omitempty"`
+
+	// DisableStack allow to disable the goroutine id field of each message.
+	DisableStack bool `json:"disableStack,omitempty" yaml:"disableStack,omitempty" toml:"disableStack,omitempty" mapstructure:"disableStack,omitempty"`
+
+	// EnableTrace allow to add the origin caller/file/line fields of each message.
+	EnableTrace bool `json:"enableTrace,omitempty" yaml:"enableTrace,omitempty" toml:"enableTrace,omitempty" mapstructure:"enableTrace,omitempty"`
+}
+
+type OptionsGelfs []OptionsGelf
+
+func (o OptionsGelf) Clone() OptionsGelf {
+	return OptionsGelf{
+		LogLevel:     o.LogLevel,
+		Network:      o.Network,
+		Host:         o.Host,
+		Hostname:     o.Hostname,
+		ChunkSize:    o.ChunkSize,
+		Compress:     o.Compress,
+		DisableStack: o.DisableStack,
+		EnableTrace:  o.EnableTrace,
+	}
+}
+
+// GetChunkSize return the max size of the udp datagrams, or GelfDefaultChunkSize if not defined.
+func (o OptionsGelf) GetChunkSize() int {
+	if o.ChunkSize < 1 {
+		return GelfDefaultChunkSize
+	} else if o.ChunkSize < GelfMinChunkSize {
+		return GelfMinChunkSize
+	} else if o.ChunkSize > GelfMaxChunkSize {
+		return GelfMaxChunkSize
+	}
+
+	return o.ChunkSize
+}
+
+func (o OptionsGelfs) Clone() OptionsGelfs {
+	var c = make([]OptionsGelf, 0)
+	for _, i := range o {
+		c = append(c, i.Clone())
+	}
+	return c
+}
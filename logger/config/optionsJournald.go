@@ -0,0 +1,66 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package config
+
+// JournaldDefaultSocket is the socket of the systemd journal native protocol.
+const JournaldDefaultSocket = "/run/systemd/journal/socket"
+
+type OptionsJournald struct {
+	// LogLevel define the allowed level of log for the journal.
+	LogLevel []string `json:"logLevel,omitempty" yaml:"logLevel,omitempty" toml:"logLevel,omitempty" mapstructure:"logLevel,omitempty"`
+
+	// Socket define the unix datagram socket of the journal. Empty means JournaldDefaultSocket.
+	Socket string `json:"socket,omitempty" yaml:"socket,omitempty" toml:"socket,omitempty" mapstructure:"socket,omitempty"`
+
+	// Identifier define the SYSLOG_IDENTIFIER field of the entries. Empty means the name of the running program.
+	Identifier string `json:"identifier,omitempty" yaml:"identifier,omitempty" toml:"identifier,omitempty" mapstructure:"identifier,omitempty"`
+
+	// DisableStack allow to disable the goroutine id field of each entry.
+	DisableStack bool `json:"disableStack,omitempty" yaml:"disableStack,omitempty" toml:"disableStack,omitempty" mapstructure:"disableStack,omitempty"`
+
+	// EnableTrace allow to add the origin caller/file/line of each entry as CODE_FUNC, CODE_FILE and CODE_LINE fields.
+	EnableTrace bool `json:"enableTrace,omitempty" yaml:"enableTrace,omitempty" toml:"enableTrace,omitempty" mapstructure:"enableTrace,omitempty"`
+}
+
+func (o *OptionsJournald) Clone() *OptionsJournald {
+	return &OptionsJournald{
+		LogLevel:     o.LogLevel,
+		Socket:       o.Socket,
+		Identifier:   o.Identifier,
+		DisableStack: o.DisableStack,
+		EnableTrace:  o.EnableTrace,
+	}
+}
+
+// GetSocket return the socket of the journal, or JournaldDefaultSocket if not defined.
+func (o *OptionsJournald) GetSocket() string {
+	if len(o.Socket) > 0 {
+		return o.Socket
+	}
+
+	return JournaldDefaultSocket
+}
@@ -0,0 +1,86 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package config
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+)
+
+// RedactDefaultMask is the replacement of the redacted values if not defined.
+const RedactDefaultMask = "[REDACTED]"
+
+type OptionsRedact struct {
+	// Fields define the patterns of the field names to redact, with the syntax of path.Match and case-insensitive (ex: "password", "*token*").
+	// The names are matched at any depth of the map values.
+	Fields []string `json:"fields,omitempty" yaml:"fields,omitempty" toml:"fields,omitempty" mapstructure:"fields,omitempty"`
+
+	// Values define the regular expressions of the parts of the string values to redact, in any field (ex: "Bearer [A-Za-z0-9._-]+").
+	Values []string `json:"values,omitempty" yaml:"values,omitempty" toml:"values,omitempty" mapstructure:"values,omitempty"`
+
+	// Mask define the replacement of the redacted values. Empty means RedactDefaultMask.
+	Mask string `json:"mask,omitempty" yaml:"mask,omitempty" toml:"mask,omitempty" mapstructure:"mask,omitempty"`
+}
+
+func (o *OptionsRedact) Clone() *OptionsRedact {
+	return &OptionsRedact{
+		Fields: append(make([]string, 0, len(o.Fields)), o.Fields...),
+		Values: append(make([]string, 0, len(o.Values)), o.Values...),
+		Mask:   o.Mask,
+	}
+}
+
+// GetMask return the replacement of the redacted values, or RedactDefaultMask if not defined.
+func (o *OptionsRedact) GetMask() string {
+	if len(o.Mask) > 0 {
+		return o.Mask
+	}
+
+	return RedactDefaultMask
+}
+
+// Compile checks the patterns of the fields and returns the compiled regular expressions of the values.
+func (o *OptionsRedact) Compile() ([]*regexp.Regexp, error) {
+	var res = make([]*regexp.Regexp, 0, len(o.Values))
+
+	for _, p := range o.Fields {
+		if _, e := path.Match(p, ""); e != nil {
+			return nil, ErrorRedactInvalid.Error(fmt.Errorf("invalid field pattern '%s': %v", p, e))
+		}
+	}
+
+	for _, p := range o.Values {
+		if r, e := regexp.Compile(p); e != nil {
+			return nil, ErrorRedactInvalid.Error(fmt.Errorf("invalid value pattern '%s': %v", p, e))
+		} else {
+			res = append(res, r)
+		}
+	}
+
+	return res, nil
+}
@@ -46,6 +46,17 @@ type OptionsStd struct {
 
 	// EnableAccessLog allow to add all message from api router for access log and error log.
 	EnableAccessLog bool `json:"enableAccessLog,omitempty" yaml:"enableAccessLog,omitempty" toml:"enableAccessLog,omitempty" mapstructure:"enableAccessLog,omitempty"`
+
+	// LogLevelStdout define the allowed level of log for stdout.
+	// If LogLevelStdout and LogLevelStderr are both empty, info and debug levels are sent to stdout
+	// and all others levels to stderr. Otherwise, each output receive only its own listed levels.
+	LogLevelStdout []string `json:"logLevelStdout,omitempty" yaml:"logLevelStdout,omitempty" toml:"logLevelStdout,omitempty" mapstructure:"logLevelStdout,omitempty"`
+
+	// LogLevelStderr define the allowed level of log for stderr. See LogLevelStdout.
+	LogLevelStderr []string `json:"logLevelStderr,omitempty" yaml:"logLevelStderr,omitempty" toml:"logLevelStderr,omitempty" mapstructure:"logLevelStderr,omitempty"`
+
+	// Format define the format of the messages: text (default), json or ecs (Elastic Common Schema).
+	Format string `json:"format,omitempty" yaml:"format,omitempty" toml:"format,omitempty" mapstructure:"format,omitempty" validate:"omitempty,oneof=text json ecs"`
 }
 
 func (o *OptionsStd) Clone() *OptionsStd {
@@ -56,5 +67,8 @@ func (o *OptionsStd) Clone() *OptionsStd {
 		EnableTrace:      o.EnableTrace,
 		DisableColor:     o.DisableColor,
 		EnableAccessLog:  o.EnableAccessLog,
+		LogLevelStdout:   o.LogLevelStdout,
+		LogLevelStderr:   o.LogLevelStderr,
+		Format:           o.Format,
 	}
 }
@@ -0,0 +1,381 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	loglvl "github.com/nabbar/golib/logger/level"
+)
+
+const (
+	RouteStdout = "stdout"
+	RouteStderr = "stderr"
+	RouteFile   = "file"
+	RouteSyslog = "syslog"
+)
+
+// Route is one rule of the routing syntax: the levels sent to the destinations.
+type Route struct {
+	Levels       []loglvl.Level
+	Destinations []string
+}
+
+// ParseRoutes parse the compact routing syntax used into the Routes options.
+//
+// The rules are separated by ';' or new lines, each rule is "<levels> -> <destinations>".
+// The arrow can be "->", "=>" or "→".
+// Levels are separated by ',', '|' or spaces, each level is either:
+//   - a level name (critical, fatal, error, warning, info, debug),
+//   - a level name followed by '+' for this level and all more severe levels (ex: "error+"),
+//   - a level name followed by '-' for this level and all less severe levels (ex: "info-"),
+//   - '*' for all levels.
+//
+// Destinations are separated by ',', each destination is either:
+//   - "stdout" or "stderr",
+//   - "file:<path>[?<flags>]", the file and its path are created if missing,
+//   - "syslog" for the local syslog or "syslog:<network>://<host>[?<flags>]" for a remote syslog.
+//
+// Flags are separated by '&' and can be: trace, access, nostack, notimestamp, lock (file only),
+// tag=<tag> and facility=<facility> (syslog only).
+//
+// Example: "error+ -> file:/var/log/err.log, syslog; debug -> stdout"
+func ParseRoutes(s string) ([]Route, error) {
+	var res = make([]Route, 0)
+
+	for _, r := range strings.FieldsFunc(s, func(c rune) bool {
+		return c == ';' || c == '\n'
+	}) {
+		if r = strings.TrimSpace(r); len(r) < 1 {
+			continue
+		}
+
+		lvl, dst, ok := cutArrow(r)
+		if !ok {
+			return nil, ErrorRoutesInvalid.Error(fmt.Errorf("missing arrow in rule '%s'", r))
+		}
+
+		var rte = Route{
+			Levels:       make([]loglvl.Level, 0),
+			Destinations: make([]string, 0),
+		}
+
+		for _, l := range strings.FieldsFunc(lvl, func(c rune) bool {
+			return c == ',' || c == '|' || c == ' ' || c == '\t'
+		}) {
+			if p, e := parseRouteLevel(l); e != nil {
+				return nil, ErrorRoutesInvalid.Error(e)
+			} else {
+				rte.Levels = appendLevels(rte.Levels, p...)
+			}
+		}
+
+		for _, d := range strings.Split(dst, ",") {
+			if d = strings.TrimSpace(d); len(d) < 1 {
+				continue
+			} else if e := checkRouteDestination(d); e != nil {
+				return nil, ErrorRoutesInvalid.Error(e)
+			} else {
+				rte.Destinations = append(rte.Destinations, d)
+			}
+		}
+
+		if len(rte.Levels) < 1 {
+			return nil, ErrorRoutesInvalid.Error(fmt.Errorf("missing level in rule '%s'", r))
+		} else if len(rte.Destinations) < 1 {
+			return nil, ErrorRoutesInvalid.Error(fmt.Errorf("missing destination in rule '%s'", r))
+		}
+
+		res = append(res, rte)
+	}
+
+	return res, nil
+}
+
+// CompileRoutes return a copy of the options with the Routes compiled into
+// the stdout, file and syslog options. The current options are not modified.
+//
+// Levels routed to the same file or the same syslog are merged into one output.
+// If at least one rule target stdout or stderr, the levels of the standard
+// outputs are only the routed ones.
+func (o *Options) CompileRoutes() (*Options, error) {
+	var n = o.Clone()
+	n.opts = o.opts
+
+	if len(o.Routes) < 1 {
+		return &n, nil
+	}
+
+	rte, err := ParseRoutes(o.Routes)
+	if err != nil {
+		return nil, err
+	}
+
+	n.Routes = ""
+
+	var (
+		std    = false
+		out    = make([]loglvl.Level, 0)
+		stderr = make([]loglvl.Level, 0)
+		fil    = make(map[string]int)
+		sys    = make(map[string]int)
+	)
+
+	for _, r := range rte {
+		for _, d := range r.Destinations {
+			typ, ref, flg := splitRouteDestination(d)
+
+			switch typ {
+			case RouteStdout:
+				std = true
+				out = appendLevels(out, r.Levels...)
+			case RouteStderr:
+				std = true
+				stderr = appendLevels(stderr, r.Levels...)
+			case RouteFile:
+				if i, k := fil[ref]; k {
+					n.LogFile[i].LogLevel = appendLevelString(n.LogFile[i].LogLevel, r.Levels...)
+				} else {
+					f := OptionsFile{
+						LogLevel:   appendLevelString(nil, r.Levels...),
+						Filepath:   ref,
+						Create:     true,
+						CreatePath: true,
+					}
+					applyFileFlags(&f, flg)
+					fil[ref] = len(n.LogFile)
+					n.LogFile = append(n.LogFile, f)
+				}
+			case RouteSyslog:
+				var s = OptionsSyslog{}
+
+				if len(ref) > 0 {
+					if u, e := url.Parse(ref); e == nil {
+						s.Network = u.Scheme
+						s.Host = u.Host
+					}
+				}
+
+				applySyslogFlags(&s, flg)
+
+				var key = s.Network + "|" + s.Host + "|" + s.Tag + "|" + s.Facility
+
+				if i, k := sys[key]; k {
+					n.LogSyslog[i].LogLevel = appendLevelString(n.LogSyslog[i].LogLevel, r.Levels...)
+				} else {
+					s.LogLevel = appendLevelString(nil, r.Levels...)
+					sys[key] = len(n.LogSyslog)
+					n.LogSyslog = append(n.LogSyslog, s)
+				}
+			}
+		}
+	}
+
+	if std {
+		if n.Stdout == nil {
+			n.Stdout = &OptionsStd{}
+		}
+
+		n.Stdout.DisableStandard = false
+		n.Stdout.LogLevelStdout = appendLevelString(make([]string, 0), out...)
+		n.Stdout.LogLevelStderr = appendLevelString(make([]string, 0), stderr...)
+	}
+
+	return &n, nil
+}
+
+func cutArrow(s string) (lvl, dst string, ok bool) {
+	for _, a := range []string{"->", "=>", "→"} {
+		if l, d, k := strings.Cut(s, a); k {
+			return strings.TrimSpace(l), strings.TrimSpace(d), true
+		}
+	}
+
+	return "", "", false
+}
+
+func parseRouteLevel(s string) ([]loglvl.Level, error) {
+	var (
+		res = make([]loglvl.Level, 0)
+		mod byte
+	)
+
+	if s = strings.TrimSpace(s); s == "*" {
+		return []loglvl.Level{loglvl.PanicLevel, loglvl.FatalLevel, loglvl.ErrorLevel, loglvl.WarnLevel, loglvl.InfoLevel, loglvl.DebugLevel}, nil
+	} else if strings.HasSuffix(s, "+") || strings.HasSuffix(s, "-") {
+		mod = s[len(s)-1]
+		s = s[:len(s)-1]
+	}
+
+	var lvl loglvl.Level
+
+	switch strings.ToLower(s) {
+	case "critical", "panic":
+		lvl = loglvl.PanicLevel
+	case "fatal":
+		lvl = loglvl.FatalLevel
+	case "error", "err":
+		lvl = loglvl.ErrorLevel
+	case "warning", "warn":
+		lvl = loglvl.WarnLevel
+	case "info":
+		lvl = loglvl.InfoLevel
+	case "debug":
+		lvl = loglvl.DebugLevel
+	default:
+		return nil, fmt.Errorf("unknown level '%s'", s)
+	}
+
+	for l := loglvl.PanicLevel; l <= loglvl.DebugLevel; l++ {
+		if l == lvl || (mod == '+' && l < lvl) || (mod == '-' && l > lvl) {
+			res = append(res, l)
+		}
+	}
+
+	return res, nil
+}
+
+func checkRouteDestination(s string) error {
+	typ, ref, _ := splitRouteDestination(s)
+
+	switch typ {
+	case RouteStdout, RouteStderr:
+		if len(ref) > 0 {
+			return fmt.Errorf("destination '%s' does not accept parameters", s)
+		}
+	case RouteFile:
+		if len(ref) < 1 {
+			return fmt.Errorf("missing file path in destination '%s'", s)
+		}
+	case RouteSyslog:
+		if len(ref) < 1 {
+			return nil
+		} else if u, e := url.Parse(ref); e != nil {
+			return fmt.Errorf("invalid syslog destination '%s': %v", s, e)
+		} else if len(u.Scheme) < 1 || len(u.Host) < 1 {
+			return fmt.Errorf("invalid syslog destination '%s', awaiting 'syslog:<network>://<host>'", s)
+		}
+	default:
+		return fmt.Errorf("unknown destination '%s'", s)
+	}
+
+	return nil
+}
+
+// splitRouteDestination return the destination type, the reference (path or url) and the flags.
+func splitRouteDestination(s string) (typ, ref string, flg []string) {
+	base, q, k := strings.Cut(strings.TrimSpace(s), "?")
+
+	if k {
+		flg = strings.Split(q, "&")
+	}
+
+	typ, ref, _ = strings.Cut(base, ":")
+
+	return strings.ToLower(strings.TrimSpace(typ)), strings.TrimSpace(ref), flg
+}
+
+func applyFileFlags(f *OptionsFile, flg []string) {
+	for _, i := range flg {
+		switch strings.ToLower(strings.TrimSpace(i)) {
+		case "trace":
+			f.EnableTrace = true
+		case "access":
+			f.EnableAccessLog = true
+		case "nostack":
+			f.DisableStack = true
+		case "notimestamp":
+			f.DisableTimestamp = true
+		case "lock":
+			f.FileLock = true
+		}
+	}
+}
+
+func applySyslogFlags(s *OptionsSyslog, flg []string) {
+	for _, i := range flg {
+		k, v, _ := strings.Cut(strings.TrimSpace(i), "=")
+
+		switch strings.ToLower(k) {
+		case "trace":
+			s.EnableTrace = true
+		case "access":
+			s.EnableAccessLog = true
+		case "nostack":
+			s.DisableStack = true
+		case "notimestamp":
+			s.DisableTimestamp = true
+		case "tag":
+			s.Tag = v
+		case "facility":
+			s.Facility = v
+		}
+	}
+}
+
+func appendLevels(lst []loglvl.Level, lvl ...loglvl.Level) []loglvl.Level {
+	for _, l := range lvl {
+		var found = false
+
+		for _, i := range lst {
+			if i == l {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			lst = append(lst, l)
+		}
+	}
+
+	return lst
+}
+
+func appendLevelString(lst []string, lvl ...loglvl.Level) []string {
+	for _, l := range lvl {
+		var (
+			s     = strings.ToLower(l.String())
+			found = false
+		)
+
+		for _, i := range lst {
+			if strings.EqualFold(i, s) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			lst = append(lst, s)
+		}
+	}
+
+	return lst
+}
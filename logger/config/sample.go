@@ -0,0 +1,231 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// SampleFormat is the format of a sample config generated by Sample.
+type SampleFormat uint8
+
+const (
+	// SampleJSON is a json sample config, without comment as json does not allow it.
+	SampleJSON SampleFormat = iota
+	// SampleYAML is a yaml sample config with the description of each field as comment.
+	SampleYAML
+	// SampleTOML is a toml sample config with the description of each field as comment.
+	SampleTOML
+)
+
+func (f SampleFormat) String() string {
+	switch f {
+	case SampleYAML:
+		return "yaml"
+	case SampleTOML:
+		return "toml"
+	default:
+		return "json"
+	}
+}
+
+// ParseSampleFormat return the SampleFormat matching the given name or file extension, SampleJSON by default.
+func ParseSampleFormat(s string) SampleFormat {
+	switch strings.ToLower(strings.TrimPrefix(strings.TrimSpace(s), ".")) {
+	case "yaml", "yml":
+		return SampleYAML
+	case "toml":
+		return SampleTOML
+	default:
+		return SampleJSON
+	}
+}
+
+// Sample return a sample config of all options in the given format, built from the JSON Schema:
+// the values are the examples of the schema (the default config) or the zero values.
+func Sample(format SampleFormat) []byte {
+	var (
+		buf = bytes.NewBuffer(make([]byte, 0))
+		sch = JSONSchema()
+	)
+
+	switch format {
+	case SampleYAML:
+		sampleComment(buf, "", "#", sch.Description)
+		sampleYAML(buf, sch, "")
+	case SampleTOML:
+		sampleComment(buf, "", "#", sch.Description)
+		sampleTOML(buf, sch, "")
+	default:
+		sampleJSON(buf, sch, "")
+		buf.WriteByte('\n')
+	}
+
+	return buf.Bytes()
+}
+
+func sampleComment(buf *bytes.Buffer, indent, mark, desc string) {
+	if len(desc) < 1 {
+		return
+	}
+
+	buf.WriteString(indent + mark + " " + desc + "\n")
+}
+
+// sampleValue return the sample value of a scalar or of an array of scalars.
+func (s *Schema) sampleValue() interface{} {
+	if len(s.Examples) > 0 {
+		return s.Examples[0]
+	}
+
+	switch s.Type {
+	case "boolean":
+		return false
+	case "integer", "number":
+		return float64(0)
+	case "array":
+		return make([]interface{}, 0)
+	default:
+		return ""
+	}
+}
+
+func (s *Schema) isObjectArray() bool {
+	return s.Type == "array" && s.Items != nil && s.Items.Type == "object"
+}
+
+func sampleScalar(v interface{}) string {
+	switch i := v.(type) {
+	case bool:
+		return strconv.FormatBool(i)
+	case float64:
+		if i == math.Trunc(i) {
+			return strconv.FormatInt(int64(i), 10)
+		}
+		return strconv.FormatFloat(i, 'f', -1, 64)
+	case string:
+		return strconv.Quote(i)
+	case []interface{}:
+		var l = make([]string, 0, len(i))
+		for _, k := range i {
+			l = append(l, sampleScalar(k))
+		}
+		return "[" + strings.Join(l, ", ") + "]"
+	default:
+		return strconv.Quote(fmt.Sprint(i))
+	}
+}
+
+func sampleJSON(buf *bytes.Buffer, s *Schema, indent string) {
+	var sub = indent + "  "
+
+	buf.WriteString("{\n")
+
+	for i, n := range s.order {
+		var p = s.Properties[n]
+
+		buf.WriteString(sub + strconv.Quote(n) + ": ")
+
+		if p.Type == "object" {
+			sampleJSON(buf, p, sub)
+		} else if p.isObjectArray() {
+			buf.WriteString("[\n" + sub + "  ")
+			sampleJSON(buf, p.Items, sub+"  ")
+			buf.WriteString("\n" + sub + "]")
+		} else {
+			buf.WriteString(sampleScalar(p.sampleValue()))
+		}
+
+		if i < len(s.order)-1 {
+			buf.WriteByte(',')
+		}
+
+		buf.WriteByte('\n')
+	}
+
+	buf.WriteString(indent + "}")
+}
+
+func sampleYAML(buf *bytes.Buffer, s *Schema, indent string) {
+	for _, n := range s.order {
+		var p = s.Properties[n]
+
+		sampleComment(buf, indent, "#", p.Description)
+
+		if p.Type == "object" {
+			buf.WriteString(indent + n + ":\n")
+			sampleYAML(buf, p, indent+"  ")
+		} else if p.isObjectArray() {
+			buf.WriteString(indent + n + ":\n" + indent + "  -\n")
+			sampleYAML(buf, p.Items, indent+"    ")
+		} else if l, k := p.sampleValue().([]interface{}); k && len(l) > 0 {
+			buf.WriteString(indent + n + ":\n")
+			for _, i := range l {
+				buf.WriteString(indent + "  - " + sampleScalar(i) + "\n")
+			}
+		} else {
+			buf.WriteString(indent + n + ": " + sampleScalar(p.sampleValue()) + "\n")
+		}
+	}
+}
+
+// sampleTOML write the scalars of the given table, then its sub tables and its arrays of tables.
+func sampleTOML(buf *bytes.Buffer, s *Schema, path string) {
+	for _, n := range s.order {
+		if p := s.Properties[n]; p.Type != "object" && !p.isObjectArray() {
+			sampleComment(buf, "", "#", p.Description)
+			buf.WriteString(n + " = " + sampleScalar(p.sampleValue()) + "\n")
+		}
+	}
+
+	for _, n := range s.order {
+		var (
+			p = s.Properties[n]
+			k = n
+		)
+
+		if len(path) > 0 {
+			k = path + "." + n
+		}
+
+		if p.Type == "object" {
+			buf.WriteByte('\n')
+			sampleComment(buf, "", "#", p.Description)
+			buf.WriteString("[" + k + "]\n")
+			sampleTOML(buf, p, k)
+		} else if p.isObjectArray() {
+			buf.WriteByte('\n')
+			sampleComment(buf, "", "#", p.Description)
+			buf.WriteString("[[" + k + "]]\n")
+			sampleTOML(buf, p.Items, k)
+		}
+	}
+}
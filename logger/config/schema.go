@@ -0,0 +1,305 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+
+	cfgcst "github.com/nabbar/golib/config/const"
+	liberr "github.com/nabbar/golib/errors"
+	libprm "github.com/nabbar/golib/file/perm"
+	loglvl "github.com/nabbar/golib/logger/level"
+	libsiz "github.com/nabbar/golib/size"
+)
+
+// JSONSchemaDraft is the JSON Schema dialect of the schema returned by JSONSchema.
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema node describing the logger options.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Minimum              *int64             `json:"minimum,omitempty"`
+	Examples             []interface{}      `json:"examples,omitempty"`
+
+	// order is the properties name in the order of the struct fields.
+	order []string
+}
+
+// schemaDescription is the description of each field of the options, by struct name and field name.
+var schemaDescription = map[string]string{
+	"Options":                 "Logger options.",
+	"Options.InheritDefault":  "Define if the current options will override a default options.",
+	"Options.TraceFilter":     "Define the path to clean for trace.",
+	"Options.TraceSkip":       "Define the number of frames to skip after the logger frames to find the caller of trace.",
+	"Options.Stdout":          "Define the options for stdout/stderr log.",
+	"Options.LogFileExtend":   "Define if the logFile given is in addition of default logFile or a replacement.",
+	"Options.LogFile":         "Define a list of log file configuration to allow log to files.",
+	"Options.LogSyslogExtend": "Define if the logSyslog given is in addition of default logSyslog or a replacement.",
+	"Options.LogSyslog":       "Define a list of syslog configuration to allow log to syslog.",
+	"Options.LogGelfExtend":   "Define if the logGelf given is in addition of default logGelf or a replacement.",
+	"Options.LogGelf":         "Define a list of GELF server configuration to allow log to Graylog.",
+	"Options.LogJournald":     "Define the options to log to the systemd journal (linux only).",
+	"Options.Routes":          "Define the outputs by level with a compact syntax, ex: \"error+ -> file:/var/log/err.log, syslog; debug -> stdout\".",
+	"Options.Async":           "Define the options to write the entries asynchronously to each output.",
+	"Options.Redact":          "Define the rules to mask the sensitive fields and values before writing the entries.",
+
+	"OptionsAsync.Enable":    "Allow to write the entries asynchronously with a background worker for each output.",
+	"OptionsAsync.QueueSize": "Define the max number of entries waiting to be written for each output (default 1024).",
+	"OptionsAsync.Policy":    "Define what to do with a new entry when the queue is full: block (default), dropOldest or dropNew.",
+
+	"OptionsRedact.Fields": "Define the case-insensitive patterns of the field names to redact (ex: \"password\", \"*token*\").",
+	"OptionsRedact.Values": "Define the regular expressions of the parts of the string values to redact.",
+	"OptionsRedact.Mask":   "Define the replacement of the redacted values (default [REDACTED]).",
+
+	"OptionsStd.DisableStandard":  "Allow disabling to write log to standard output stdout/stderr.",
+	"OptionsStd.DisableStack":     "Allow to disable the goroutine id before each message.",
+	"OptionsStd.DisableTimestamp": "Allow to disable the timestamp before each message.",
+	"OptionsStd.EnableTrace":      "Allow to add the origin caller/file/line of each message.",
+	"OptionsStd.DisableColor":     "Define if color could be use or not in messages format.",
+	"OptionsStd.EnableAccessLog":  "Allow to add all message from api router for access log and error log.",
+	"OptionsStd.LogLevelStdout":   "Define the allowed level of log for stdout.",
+	"OptionsStd.LogLevelStderr":   "Define the allowed level of log for stderr.",
+	"OptionsStd.Format":           "Define the format of the messages: text (default), json or ecs (Elastic Common Schema).",
+
+	"OptionsFile.LogLevel":         "Define the allowed level of log for this file.",
+	"OptionsFile.Filepath":         "Define the file path for log to file, the placeholder {level} allows one file by level (ex: /var/log/app/{level}.log).",
+	"OptionsFile.Create":           "Define if the log file must exist or can create it.",
+	"OptionsFile.CreatePath":       "Define if the path of the log file must exist or can try to create it.",
+	"OptionsFile.FileMode":         "Define mode to be used for the log file if the create it.",
+	"OptionsFile.PathMode":         "Define mode to be used for the path of the log file if create it.",
+	"OptionsFile.DisableStack":     "Allow to disable the goroutine id before each message.",
+	"OptionsFile.DisableTimestamp": "Allow to disable the timestamp before each message.",
+	"OptionsFile.EnableTrace":      "Allow to add the origin caller/file/line of each message.",
+	"OptionsFile.EnableAccessLog":  "Allow to add all message from api router for access log and error log.",
+	"OptionsFile.FileBufferSize":   "Define the size for buffer size, with an unit (ex: 32KB).",
+	"OptionsFile.FileLock":         "Enable an advisory exclusive lock (flock) on the log file for each buffer flush.",
+	"OptionsFile.Format":           "Define the format of the messages: text (default), json or ecs (Elastic Common Schema).",
+	"OptionsFile.RotateCompress":   "Define the algorithm used to compress the rotated file when a rotation is detected (gzip, bzip2, lz4, xz, zstd, ...).",
+	"OptionsFile.RotateKeep":       "Define the max number of rotated files kept when a rotation is detected, the oldest are removed (0 keep all).",
+
+	"OptionsSyslog.LogLevel":         "Define the allowed level of log for this syslog.",
+	"OptionsSyslog.Network":          "Define the network used to connect to this syslog (tcp, udp, or any other to a local connection).",
+	"OptionsSyslog.Host":             "Define the remote syslog to use. If host and network are empty, local syslog will be used.",
+	"OptionsSyslog.Facility":         "Define the facility syslog to be used.",
+	"OptionsSyslog.Tag":              "Define the syslog tag used in linux syslog system or name of logger for windows event logger.",
+	"OptionsSyslog.DisableStack":     "Allow to disable the goroutine id before each message.",
+	"OptionsSyslog.DisableTimestamp": "Allow to disable the timestamp before each message.",
+	"OptionsSyslog.EnableTrace":      "Allow to add the origin caller/file/line of each message.",
+	"OptionsSyslog.EnableAccessLog":  "Allow to add all message from api router for access log and error log.",
+
+	"OptionsGelf.LogLevel":     "Define the allowed level of log for this GELF server.",
+	"OptionsGelf.Network":      "Define the network used to connect to the GELF server: udp (default) or tcp.",
+	"OptionsGelf.Host":         "Define the address of the GELF server (host:port), required to use this output.",
+	"OptionsGelf.Hostname":     "Define the source host of the messages. Empty means the hostname of the system.",
+	"OptionsGelf.ChunkSize":    "Define the max size of the udp datagrams, bigger messages are chunked (default 1420).",
+	"OptionsGelf.Compress":     "Allow to compress with gzip the udp messages.",
+	"OptionsGelf.DisableStack": "Allow to disable the goroutine id field of each message.",
+	"OptionsGelf.EnableTrace":  "Allow to add the origin caller/file/line fields of each message.",
+
+	"OptionsJournald.LogLevel":     "Define the allowed level of log for the journal.",
+	"OptionsJournald.Socket":       "Define the unix datagram socket of the journal (default /run/systemd/journal/socket).",
+	"OptionsJournald.Identifier":   "Define the SYSLOG_IDENTIFIER field of the entries. Empty means the name of the running program.",
+	"OptionsJournald.DisableStack": "Allow to disable the goroutine id field of each entry.",
+	"OptionsJournald.EnableTrace":  "Allow to add the origin caller/file/line of each entry as CODE_FUNC, CODE_FILE and CODE_LINE fields.",
+}
+
+// JSONSchema return the JSON Schema of the Options struct, built from the struct fields and their json tags.
+// The unknown properties are rejected. The examples are the values of the default config.
+func JSONSchema() *Schema {
+	var s = schemaOf(reflect.TypeOf(Options{}))
+
+	s.Schema = JSONSchemaDraft
+	s.Title = "golib logger options"
+	s.Description = schemaDescription["Options"]
+
+	var def = make(map[string]interface{})
+
+	if e := json.Unmarshal(DefaultConfig(""), &def); e == nil {
+		s.example(def)
+	}
+
+	return s
+}
+
+// JSONSchemaIndent return the JSON Schema of the Options struct, indented with the given prefix.
+func JSONSchemaIndent(prefix string) []byte {
+	p, _ := json.MarshalIndent(JSONSchema(), prefix, cfgcst.JSONIndent)
+	return p
+}
+
+// ValidateJSON decode the given json config, rejecting the unknown properties, and validate the options.
+func ValidateJSON(p []byte) liberr.Error {
+	var (
+		opt Options
+		dec = json.NewDecoder(bytes.NewReader(p))
+	)
+
+	dec.DisallowUnknownFields()
+
+	if e := dec.Decode(&opt); e != nil {
+		return ErrorConfigDecode.Error(e)
+	}
+
+	return opt.Validate()
+}
+
+func schemaOf(t reflect.Type) *Schema {
+	switch t {
+	case reflect.TypeOf(libprm.Perm(0)):
+		return &Schema{
+			Type:    "string",
+			Pattern: "^0?[0-7]{3,4}$",
+		}
+	case reflect.TypeOf(libsiz.Size(0)):
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return schemaOf(t.Elem())
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{
+			Type:  "array",
+			Items: schemaOf(t.Elem()),
+		}
+	case reflect.Struct:
+		return schemaStruct(t)
+	default:
+		return &Schema{}
+	}
+}
+
+func schemaStruct(t reflect.Type) *Schema {
+	var (
+		fls = false
+		res = &Schema{
+			Type:                 "object",
+			Properties:           make(map[string]*Schema),
+			AdditionalProperties: &fls,
+			order:                make([]string, 0),
+		}
+	)
+
+	for i := 0; i < t.NumField(); i++ {
+		var (
+			f = t.Field(i)
+			n = strings.Split(f.Tag.Get("json"), ",")[0]
+		)
+
+		if !f.IsExported() || n == "-" {
+			continue
+		} else if len(n) < 1 {
+			n = f.Name
+		}
+
+		var (
+			k = t.Name() + "." + f.Name
+			s = schemaOf(f.Type)
+		)
+
+		s.Description = schemaDescription[k]
+
+		if strings.HasPrefix(f.Name, "LogLevel") && s.Items != nil {
+			s.Items.Enum = schemaLevels()
+		}
+
+		for _, v := range strings.Split(f.Tag.Get("validate"), ",") {
+			if m, ok := strings.CutPrefix(v, "min="); ok {
+				if i, e := strconv.ParseInt(m, 10, 64); e == nil {
+					s.Minimum = &i
+				}
+			} else if m, ok := strings.CutPrefix(v, "oneof="); ok {
+				s.Enum = strings.Fields(m)
+			}
+		}
+
+		res.Properties[n] = s
+		res.order = append(res.order, n)
+	}
+
+	return res
+}
+
+// schemaLevels return the level names accepted into the levels list, as written by the logger or in lower case.
+func schemaLevels() []string {
+	var res = make([]string, 0)
+
+	for _, l := range loglvl.ListLevels() {
+		if n := loglvl.Parse(l).String(); n != l {
+			res = append(res, n)
+		}
+
+		res = append(res, l)
+	}
+
+	return res
+}
+
+// example set the examples of the schema and its properties from the given value.
+func (s *Schema) example(v interface{}) {
+	switch s.Type {
+	case "object":
+		if m, k := v.(map[string]interface{}); k {
+			for n, p := range s.Properties {
+				if i, ok := m[n]; ok {
+					p.example(i)
+				}
+			}
+		}
+	case "array":
+		if l, k := v.([]interface{}); k && len(l) > 0 {
+			if s.Items != nil && s.Items.Type == "object" {
+				s.Items.example(l[0])
+			} else {
+				s.Examples = []interface{}{l}
+			}
+		}
+	default:
+		s.Examples = []interface{}{v}
+	}
+}
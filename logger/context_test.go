@@ -0,0 +1,114 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package logger_test
+
+import (
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	liblog "github.com/nabbar/golib/logger"
+	logcfg "github.com/nabbar/golib/logger/config"
+	logfld "github.com/nabbar/golib/logger/fields"
+	loglvl "github.com/nabbar/golib/logger/level"
+	libtrc "github.com/nabbar/golib/tracing"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Logger Context", func() {
+	Context("Carrying fields into a context", func() {
+		It("Must merge the fields without updating the parent context", func() {
+			par := logfld.ContextAdd(context.Background(), "user", "bob")
+			ctx := logfld.WithContext(par, map[string]interface{}{"tenant": "acme", "user": "alice"})
+
+			Expect(logfld.FromContext(context.Background())).To(BeNil())
+			Expect(logfld.FromContext(par)).To(Equal(map[string]interface{}{"user": "bob"}))
+			Expect(logfld.FromContext(ctx)).To(Equal(map[string]interface{}{"user": "alice", "tenant": "acme"}))
+		})
+	})
+
+	Context("Logging with a context", func() {
+		It("Must add the fields and the trace of the context to each entry", func() {
+			fsp, err := GetTempFile()
+			Expect(err).ToNot(HaveOccurred())
+
+			defer func() {
+				_ = DelTempFile(fsp)
+			}()
+
+			log := liblog.New(GetContext)
+			defer func() {
+				Expect(log.Close()).ToNot(HaveOccurred())
+			}()
+
+			log.SetLevel(loglvl.InfoLevel)
+			Expect(log.SetOptions(&logcfg.Options{
+				Stdout: &logcfg.OptionsStd{DisableStandard: true},
+				LogFile: []logcfg.OptionsFile{
+					{
+						Filepath:   fsp,
+						Create:     true,
+						CreatePath: true,
+					},
+				},
+			})).ToNot(HaveOccurred())
+
+			// waiting the file hook is running
+			time.Sleep(500 * time.Millisecond)
+
+			trc := libtrc.New()
+			ctx := libtrc.WithContext(context.Background(), trc)
+			ctx = logfld.ContextAdd(ctx, "user", "ctx-user")
+
+			req := log.WithContext(ctx)
+			req.Named("handler").Info("context info message", nil)
+			Expect(req.Close()).ToNot(HaveOccurred())
+
+			log.Info("root info message", nil)
+
+			Eventually(func() string {
+				b, _ := os.ReadFile(fsp)
+				return string(b)
+			}, 5*time.Second, 50*time.Millisecond).Should(And(
+				ContainSubstring("context info message"),
+				ContainSubstring("root info message"),
+				ContainSubstring("ctx-user"),
+				ContainSubstring(trc.RequestID),
+				ContainSubstring(trc.TraceID),
+			))
+
+			b, _ := os.ReadFile(fsp)
+			for _, l := range strings.Split(string(b), "\n") {
+				if strings.Contains(l, "root info message") {
+					Expect(l).ToNot(ContainSubstring("ctx-user"))
+				}
+			}
+		})
+	})
+})
@@ -28,6 +28,7 @@
 package entry
 
 import (
+	"context"
 	"time"
 
 	ginsdk "github.com/gin-gonic/gin"
@@ -43,6 +44,11 @@ type Entry interface {
 	SetEntryContext(etime time.Time, stack uint64, caller, file string, line uint64, msg string) Entry
 	SetGinContext(ctx *ginsdk.Context) Entry
 
+	// SetContext register a request context: the correlation ids (request id, trace id, span id)
+	// and the fields (see fields.WithContext) carried by this context are added as fields of the entry.
+	// If not set, the request context of the gin context is used.
+	SetContext(ctx context.Context) Entry
+
 	DataSet(data interface{}) Entry
 	Check(lvlNoErr loglvl.Level) bool
 	Log()
@@ -61,6 +67,7 @@ func New(lvl loglvl.Level) Entry {
 	return &entry{
 		log:    nil,
 		gin:    nil,
+		ctx:    nil,
 		clean:  false,
 		Level:  lvl,
 		Time:   time.Now(),
@@ -28,6 +28,7 @@
 package entry
 
 import (
+	"context"
 	"os"
 	"strings"
 	"time"
@@ -36,12 +37,14 @@ import (
 	logfld "github.com/nabbar/golib/logger/fields"
 	loglvl "github.com/nabbar/golib/logger/level"
 	logtps "github.com/nabbar/golib/logger/types"
+	libtrc "github.com/nabbar/golib/tracing"
 	"github.com/sirupsen/logrus"
 )
 
 type entry struct {
 	log   func() *logrus.Logger
 	gin   *ginsdk.Context
+	ctx   context.Context
 	clean bool
 
 	//Time is the time of the event (can be empty time if disabled timestamp)
@@ -128,6 +131,33 @@ func (e *entry) SetGinContext(ctx *ginsdk.Context) Entry {
 	return e
 }
 
+func (e *entry) SetContext(ctx context.Context) Entry {
+	if e == nil {
+		return nil
+	}
+
+	e.ctx = ctx
+	return e
+}
+
+func (e *entry) getContext() context.Context {
+	if e.ctx != nil {
+		return e.ctx
+	} else if e.gin != nil && e.gin.Request != nil {
+		return e.gin.Request.Context()
+	}
+
+	return nil
+}
+
+func (e *entry) getTrace() (libtrc.Trace, bool) {
+	if c := e.getContext(); c != nil {
+		return libtrc.FromContext(c)
+	}
+
+	return libtrc.Trace{}, false
+}
+
 func (e *entry) DataSet(data interface{}) Entry {
 	if e == nil {
 		return nil
@@ -229,6 +259,16 @@ func (e *entry) Log() {
 		tag = tag.Add(logtps.FieldData, e.Data)
 	}
 
+	if t, ok := e.getTrace(); ok {
+		for k, v := range t.Fields() {
+			tag = tag.Add(k, v)
+		}
+	}
+
+	for k, v := range logfld.FromContext(e.getContext()) {
+		tag = tag.Add(k, v)
+	}
+
 	tag.Merge(e.Fields)
 
 	if e.log == nil {
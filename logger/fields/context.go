@@ -0,0 +1,80 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package fields
+
+import (
+	"context"
+)
+
+type ctxKey struct{}
+
+// WithContext return a copy of the given context carrying the given fields in addition of the fields already
+// carried by the context. The fields carried by a context are added to each entry logged with this context.
+func WithContext(ctx context.Context, fields map[string]interface{}) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	var res = FromContext(ctx)
+
+	if res == nil {
+		res = make(map[string]interface{}, len(fields))
+	}
+
+	for k, v := range fields {
+		res[k] = v
+	}
+
+	return context.WithValue(ctx, ctxKey{}, res)
+}
+
+// ContextAdd return a copy of the given context carrying the given field in addition of the fields already
+// carried by the context.
+func ContextAdd(ctx context.Context, key string, val interface{}) context.Context {
+	return WithContext(ctx, map[string]interface{}{key: val})
+}
+
+// FromContext return a copy of the fields carried by the given context, or nil if none.
+func FromContext(ctx context.Context) map[string]interface{} {
+	if ctx == nil {
+		return nil
+	}
+
+	f, ok := ctx.Value(ctxKey{}).(map[string]interface{})
+
+	if !ok || f == nil {
+		return nil
+	}
+
+	var res = make(map[string]interface{}, len(f))
+
+	for k, v := range f {
+		res[k] = v
+	}
+
+	return res
+}
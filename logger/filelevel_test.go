@@ -0,0 +1,100 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package logger_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	liblog "github.com/nabbar/golib/logger"
+	logcfg "github.com/nabbar/golib/logger/config"
+	loglvl "github.com/nabbar/golib/logger/level"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Logger File by Level", func() {
+	Context("Logging with a level placeholder into the file path", func() {
+		It("Must write each level into its own file with one hook", func() {
+			dir, err := os.MkdirTemp("", "logger-level")
+			Expect(err).ToNot(HaveOccurred())
+
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+
+			log := liblog.New(GetContext)
+			defer func() {
+				Expect(log.Close()).ToNot(HaveOccurred())
+			}()
+
+			log.SetLevel(loglvl.DebugLevel)
+			Expect(log.SetOptions(&logcfg.Options{
+				Stdout: &logcfg.OptionsStd{DisableStandard: true},
+				LogFile: []logcfg.OptionsFile{
+					{
+						LogLevel:   []string{"error", "info", "debug"},
+						Filepath:   filepath.Join(dir, "sub", logcfg.FileLevelPlaceholder+".log"),
+						Create:     true,
+						CreatePath: true,
+					},
+				},
+			})).ToNot(HaveOccurred())
+
+			for _, f := range []string{"error.log", "info.log", "debug.log"} {
+				Expect(filepath.Join(dir, "sub", f)).To(BeAnExistingFile())
+			}
+
+			Expect(filepath.Join(dir, "sub", "warning.log")).ToNot(BeAnExistingFile())
+
+			// waiting the file hook is running
+			time.Sleep(500 * time.Millisecond)
+
+			log.Error("level error message", nil)
+			log.Info("level info message", nil)
+			log.Debug("level debug message", nil)
+			log.Warning("level warning message", nil)
+
+			read := func(f string) func() string {
+				return func() string {
+					b, _ := os.ReadFile(filepath.Join(dir, "sub", f))
+					return string(b)
+				}
+			}
+
+			Eventually(read("error.log"), 5*time.Second, 50*time.Millisecond).Should(ContainSubstring("level error message"))
+			Eventually(read("info.log"), 5*time.Second, 50*time.Millisecond).Should(ContainSubstring("level info message"))
+			Eventually(read("debug.log"), 5*time.Second, 50*time.Millisecond).Should(ContainSubstring("level debug message"))
+
+			Expect(read("error.log")()).ToNot(ContainSubstring("level info message"))
+			Expect(read("info.log")()).ToNot(ContainSubstring("level debug message"))
+			Expect(read("debug.log")()).ToNot(ContainSubstring("level error message"))
+			Expect(filepath.Join(dir, "sub", "warning.log")).ToNot(BeAnExistingFile())
+		})
+	})
+})
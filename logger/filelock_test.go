@@ -0,0 +1,108 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+// +build linux darwin freebsd netbsd openbsd dragonfly
+
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package logger_test
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	liblog "github.com/nabbar/golib/logger"
+	logcfg "github.com/nabbar/golib/logger/config"
+	loglvl "github.com/nabbar/golib/logger/level"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Logger File Lock", func() {
+	Context("Writing a log file locked by another process", func() {
+		It("Must wait the release of the lock before writing", func() {
+			dir, err := os.MkdirTemp("", "logger-lock")
+			Expect(err).ToNot(HaveOccurred())
+
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+
+			var fsp = filepath.Join(dir, "app.log")
+
+			log := liblog.New(GetContext)
+			defer func() {
+				Expect(log.Close()).ToNot(HaveOccurred())
+			}()
+
+			log.SetLevel(loglvl.InfoLevel)
+
+			Expect(log.SetOptions(&logcfg.Options{
+				Stdout: &logcfg.OptionsStd{DisableStandard: true},
+				LogFile: []logcfg.OptionsFile{
+					{
+						Filepath:   fsp,
+						Create:     true,
+						CreatePath: true,
+						FileLock:   true,
+					},
+				},
+			})).ToNot(HaveOccurred())
+
+			// waiting the file hook is running
+			time.Sleep(500 * time.Millisecond)
+
+			content := func() string {
+				b, _ := os.ReadFile(fsp)
+				return string(b)
+			}
+
+			log.Info("before lock", nil)
+			Eventually(content, 5*time.Second, 50*time.Millisecond).Should(ContainSubstring("before lock"))
+
+			// #nosec
+			h, err := os.OpenFile(fsp, os.O_RDWR, 0)
+			Expect(err).ToNot(HaveOccurred())
+
+			defer func() {
+				_ = h.Close()
+			}()
+
+			Expect(syscall.Flock(int(h.Fd()), syscall.LOCK_EX)).To(Succeed())
+
+			// the buffer is flushed every second, the write must stay blocked after a flush
+			log.Info("while locked", nil)
+			Consistently(content, 2500*time.Millisecond, 50*time.Millisecond).ShouldNot(ContainSubstring("while locked"))
+
+			Expect(syscall.Flock(int(h.Fd()), syscall.LOCK_UN)).To(Succeed())
+			Eventually(content, 5*time.Second, 50*time.Millisecond).Should(ContainSubstring("while locked"))
+
+			// the lock is released after each write
+			Expect(syscall.Flock(int(h.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)).To(Succeed())
+			Expect(syscall.Flock(int(h.Fd()), syscall.LOCK_UN)).To(Succeed())
+		})
+	})
+})
@@ -0,0 +1,101 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package formatter
+
+import (
+	"strings"
+	"time"
+
+	logtps "github.com/nabbar/golib/logger/types"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	ecsTimestamp = "@timestamp"
+	ecsLevel     = "log.level"
+	ecsMessage   = "message"
+	ecsLogger    = "log.logger"
+	ecsFunction  = "log.origin.function"
+	ecsFile      = "log.origin.file.name"
+	ecsLine      = "log.origin.file.line"
+	ecsError     = "error.message"
+	ecsThread    = "process.thread.id"
+	ecsVersion   = "ecs.version"
+)
+
+// ecsFields is the ECS name of the fields of the logger.
+var ecsFields = map[string]string{
+	logtps.FieldTime:    ecsTimestamp,
+	logtps.FieldLevel:   ecsLevel,
+	logtps.FieldMessage: ecsMessage,
+	logtps.FieldLogger:  ecsLogger,
+	logtps.FieldCaller:  ecsFunction,
+	logtps.FieldFile:    ecsFile,
+	logtps.FieldLine:    ecsLine,
+	logtps.FieldError:   ecsError,
+	logtps.FieldStack:   ecsThread,
+}
+
+type fmtECS struct{}
+
+func (o *fmtECS) Format(entry *logrus.Entry) ([]byte, error) {
+	var res = make(map[string]interface{}, len(entry.Data)+3)
+
+	for k, v := range entry.Data {
+		if n, ok := ecsFields[k]; ok {
+			res[n] = jsonValue(v)
+		} else {
+			res[k] = jsonValue(v)
+		}
+	}
+
+	// the ECS levels are lower case
+	if s, k := res[ecsLevel].(string); k {
+		res[ecsLevel] = strings.ToLower(s)
+	}
+
+	// the timestamp, the level and the message are required by ECS
+	if _, k := res[ecsTimestamp]; !k {
+		if entry.Time.IsZero() {
+			res[ecsTimestamp] = time.Now().UTC().Format(time.RFC3339Nano)
+		} else {
+			res[ecsTimestamp] = entry.Time.UTC().Format(time.RFC3339Nano)
+		}
+	}
+
+	if _, k := res[ecsLevel]; !k {
+		res[ecsLevel] = entry.Level.String()
+	}
+
+	if _, k := res[ecsMessage]; !k {
+		res[ecsMessage] = entry.Message
+	}
+
+	res[ecsVersion] = ECSVersion
+
+	return marshal(res)
+}
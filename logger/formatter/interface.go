@@ -0,0 +1,47 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package formatter
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+// ECSVersion is the version of the Elastic Common Schema of the entries formatted by NewECS.
+const ECSVersion = "8.11.0"
+
+// NewJSON return a formatter writing each entry as a json object on one line, with the fields of the entry as is.
+// The level and the message of the entry are added if they are not already into the fields.
+func NewJSON() logrus.Formatter {
+	return &fmtJSON{}
+}
+
+// NewECS return a formatter writing each entry as a json object on one line following the Elastic Common Schema
+// (ecs-logging): the fields of the logger are renamed to their ECS name (@timestamp, log.level, message, log.logger,
+// log.origin.*, error.message, process.thread.id) and the others fields are kept as custom fields.
+func NewECS() logrus.Formatter {
+	return &fmtECS{}
+}
@@ -0,0 +1,78 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package formatter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	logtps "github.com/nabbar/golib/logger/types"
+	"github.com/sirupsen/logrus"
+)
+
+type fmtJSON struct{}
+
+func (o *fmtJSON) Format(entry *logrus.Entry) ([]byte, error) {
+	var res = make(map[string]interface{}, len(entry.Data)+2)
+
+	for k, v := range entry.Data {
+		res[k] = jsonValue(v)
+	}
+
+	if _, k := res[logtps.FieldLevel]; !k {
+		res[logtps.FieldLevel] = entry.Level.String()
+	}
+
+	if _, k := res[logtps.FieldMessage]; !k && len(entry.Message) > 0 {
+		res[logtps.FieldMessage] = entry.Message
+	}
+
+	return marshal(res)
+}
+
+// jsonValue return the message of the errors, encoding/json writes them as an empty object.
+func jsonValue(val interface{}) interface{} {
+	if e, k := val.(error); k && e != nil {
+		return e.Error()
+	}
+
+	return val
+}
+
+func marshal(val map[string]interface{}) ([]byte, error) {
+	var buf = bytes.NewBuffer(make([]byte, 0, 256))
+
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+
+	if e := enc.Encode(val); e != nil {
+		return nil, fmt.Errorf("failed to marshal fields to JSON, %w", e)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,268 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package logger_test
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	liblog "github.com/nabbar/golib/logger"
+	logcfg "github.com/nabbar/golib/logger/config"
+	logfmt "github.com/nabbar/golib/logger/formatter"
+	loglvl "github.com/nabbar/golib/logger/level"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// readGelfUDP reads the datagrams of one GELF message, reassembling the chunks and decompressing it.
+func readGelfUDP(con net.PacketConn) map[string]interface{} {
+	var (
+		buf = make([]byte, 65535)
+		chk = make(map[int][]byte)
+		msg []byte
+	)
+
+	Expect(con.SetReadDeadline(time.Now().Add(5 * time.Second))).To(Succeed())
+
+	for {
+		n, _, err := con.ReadFrom(buf)
+		Expect(err).ToNot(HaveOccurred())
+
+		p := append(make([]byte, 0, n), buf[:n]...)
+
+		if len(p) < 2 || p[0] != 0x1e || p[1] != 0x0f {
+			msg = p
+			break
+		}
+
+		chk[int(p[10])] = p[12:]
+
+		if len(chk) == int(p[11]) {
+			var idx = make([]int, 0, len(chk))
+			for i := range chk {
+				idx = append(idx, i)
+			}
+			sort.Ints(idx)
+			for _, i := range idx {
+				msg = append(msg, chk[i]...)
+			}
+			break
+		}
+	}
+
+	if len(msg) > 2 && msg[0] == 0x1f && msg[1] == 0x8b {
+		r, err := gzip.NewReader(bytes.NewReader(msg))
+		Expect(err).ToNot(HaveOccurred())
+		msg, err = io.ReadAll(r)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	var res map[string]interface{}
+	Expect(json.Unmarshal(msg, &res)).To(Succeed())
+
+	return res
+}
+
+func newGelfLogger(opt logcfg.OptionsGelf) liblog.Logger {
+	log := liblog.New(GetContext)
+	log.SetLevel(loglvl.InfoLevel)
+
+	Expect(log.SetOptions(&logcfg.Options{
+		Stdout:  &logcfg.OptionsStd{DisableStandard: true},
+		LogGelf: logcfg.OptionsGelfs{opt},
+	})).ToNot(HaveOccurred())
+
+	return log
+}
+
+var _ = Describe("Logger GELF and ECS", func() {
+	Context("Sending to a GELF server", func() {
+		It("Must send a GELF message over udp", func() {
+			con, err := net.ListenPacket("udp", "127.0.0.1:0")
+			Expect(err).ToNot(HaveOccurred())
+			defer func() {
+				_ = con.Close()
+			}()
+
+			log := newGelfLogger(logcfg.OptionsGelf{
+				Host:     con.LocalAddr().String(),
+				Hostname: "gelf-host",
+			})
+			defer func() {
+				Expect(log.Close()).ToNot(HaveOccurred())
+			}()
+
+			log.Warning("gelf udp message", map[string]interface{}{"user": "bob", "id": 42})
+
+			msg := readGelfUDP(con)
+			Expect(msg).To(HaveKeyWithValue("version", "1.1"))
+			Expect(msg).To(HaveKeyWithValue("host", "gelf-host"))
+			Expect(msg).To(HaveKeyWithValue("short_message", "gelf udp message"))
+			Expect(msg).To(HaveKeyWithValue("level", BeNumerically("==", 4)))
+			Expect(msg).To(HaveKeyWithValue("_data_user", "bob"))
+			Expect(msg).To(HaveKeyWithValue("_data_id", BeNumerically("==", 42)))
+			Expect(msg).To(HaveKey("timestamp"))
+		})
+
+		It("Must chunk the big compressed GELF messages over udp", func() {
+			con, err := net.ListenPacket("udp", "127.0.0.1:0")
+			Expect(err).ToNot(HaveOccurred())
+			defer func() {
+				_ = con.Close()
+			}()
+
+			log := newGelfLogger(logcfg.OptionsGelf{
+				Host:      con.LocalAddr().String(),
+				ChunkSize: logcfg.GelfMinChunkSize,
+				Compress:  true,
+			})
+			defer func() {
+				Expect(log.Close()).ToNot(HaveOccurred())
+			}()
+
+			// random like data, not reduced by the compression under the chunk size
+			var big = make([]string, 0, 800)
+			for i := 0; i < 800; i++ {
+				big = append(big, time.Now().Add(time.Duration(i*7919)*time.Nanosecond).Format(time.RFC3339Nano))
+			}
+
+			log.Info("gelf big message", map[string]interface{}{"big": strings.Join(big, " ")})
+
+			msg := readGelfUDP(con)
+			Expect(msg).To(HaveKeyWithValue("short_message", "gelf big message"))
+			Expect(msg).To(HaveKeyWithValue("_data_big", strings.Join(big, " ")))
+		})
+
+		It("Must send null byte delimited GELF messages over tcp", func() {
+			lis, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).ToNot(HaveOccurred())
+			defer func() {
+				_ = lis.Close()
+			}()
+
+			res := make(chan []byte, 2)
+
+			go func() {
+				c, e := lis.Accept()
+				if e != nil {
+					return
+				}
+				defer func() {
+					_ = c.Close()
+				}()
+
+				r := bufio.NewReader(c)
+				for {
+					p, e := r.ReadBytes(0)
+					if e != nil {
+						return
+					}
+					res <- p[:len(p)-1]
+				}
+			}()
+
+			log := newGelfLogger(logcfg.OptionsGelf{
+				Network: "tcp",
+				Host:    lis.Addr().String(),
+			})
+			defer func() {
+				Expect(log.Close()).ToNot(HaveOccurred())
+			}()
+
+			log.Info("gelf tcp message 1", nil)
+			log.Error("gelf tcp message 2", nil)
+
+			for i := 1; i <= 2; i++ {
+				var (
+					p   []byte
+					msg map[string]interface{}
+				)
+
+				Eventually(res, 5*time.Second).Should(Receive(&p))
+				Expect(json.Unmarshal(p, &msg)).To(Succeed())
+				Expect(msg["short_message"]).To(HaveSuffix(string(rune('0' + i))))
+			}
+		})
+	})
+
+	Context("Writing to a file with the ECS format", func() {
+		It("Must write one ECS json object by line", func() {
+			fsp, err := GetTempFile()
+			Expect(err).ToNot(HaveOccurred())
+
+			defer func() {
+				_ = DelTempFile(fsp)
+			}()
+
+			log := liblog.New(GetContext)
+			defer func() {
+				Expect(log.Close()).ToNot(HaveOccurred())
+			}()
+
+			log.SetLevel(loglvl.InfoLevel)
+			Expect(log.SetOptions(&logcfg.Options{
+				Stdout: &logcfg.OptionsStd{DisableStandard: true},
+				LogFile: []logcfg.OptionsFile{
+					{
+						Filepath:   fsp,
+						Create:     true,
+						CreatePath: true,
+						Format:     logcfg.FormatECS,
+					},
+				},
+			})).ToNot(HaveOccurred())
+
+			// waiting the file hook is running
+			time.Sleep(500 * time.Millisecond)
+
+			log.Named("ecs").Info("ecs info message", nil)
+
+			var lin string
+			Eventually(func() string {
+				b, _ := os.ReadFile(fsp)
+				lin = strings.TrimSpace(string(b))
+				return lin
+			}, 5*time.Second, 50*time.Millisecond).Should(ContainSubstring("ecs info message"))
+
+			var msg map[string]interface{}
+			Expect(json.Unmarshal([]byte(lin), &msg)).To(Succeed())
+			Expect(msg).To(HaveKeyWithValue("message", "ecs info message"))
+			Expect(msg).To(HaveKeyWithValue("log.level", "info"))
+			Expect(msg).To(HaveKeyWithValue("log.logger", "ecs"))
+			Expect(msg).To(HaveKeyWithValue("ecs.version", logfmt.ECSVersion))
+			Expect(msg).To(HaveKey("@timestamp"))
+		})
+	})
+})
@@ -0,0 +1,64 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package hookasync
+
+import (
+	"sync"
+	"sync/atomic"
+
+	logcfg "github.com/nabbar/golib/logger/config"
+	logtps "github.com/nabbar/golib/logger/types"
+	"github.com/sirupsen/logrus"
+)
+
+// HookAsync is a hook queuing the entries to write them with the given hook into a background worker.
+type HookAsync interface {
+	logtps.Hook
+
+	// Dropped return the number of entries dropped because the queue was full.
+	Dropped() uint64
+}
+
+// New return a hook writing asynchronously the entries with the given hook, with a bounded queue
+// and the policy of the given options. The worker is started by Run, the queue is flushed on Close
+// or at the end of the Run context.
+func New(h logtps.Hook, opt *logcfg.OptionsAsync) HookAsync {
+	if opt == nil {
+		opt = &logcfg.OptionsAsync{}
+	}
+
+	return &hka{
+		h: h,
+		p: opt.GetPolicy(),
+		q: make(chan *logrus.Entry, opt.GetQueueSize()),
+		s: make(chan struct{}),
+		d: new(atomic.Uint64),
+		r: new(atomic.Bool),
+		w: sync.WaitGroup{},
+		o: sync.Once{},
+	}
+}
@@ -0,0 +1,168 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package hookasync
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	logcfg "github.com/nabbar/golib/logger/config"
+	logtps "github.com/nabbar/golib/logger/types"
+	"github.com/sirupsen/logrus"
+)
+
+type hka struct {
+	h logtps.Hook        // hook writing the entries
+	p string             // policy of full queue
+	q chan *logrus.Entry // queue
+	s chan struct{}      // stop signal
+	d *atomic.Uint64     // dropped entries
+	r *atomic.Bool       // worker is running
+	w sync.WaitGroup     // worker
+	o sync.Once          // stop once
+}
+
+func (o *hka) Dropped() uint64 {
+	return o.d.Load()
+}
+
+func (o *hka) Levels() []logrus.Level {
+	return o.h.Levels()
+}
+
+func (o *hka) RegisterHook(log *logrus.Logger) {
+	log.AddHook(o)
+}
+
+func (o *hka) Fire(entry *logrus.Entry) error {
+	// the entry is reused by logrus after the hooks
+	ent := entry.Dup()
+	ent.Level = entry.Level
+	ent.Message = entry.Message
+	ent.Caller = entry.Caller
+
+	select {
+	case <-o.s:
+		// stopped: no more worker, write directly
+		return o.h.Fire(ent)
+	default:
+	}
+
+	switch o.p {
+	case logcfg.AsyncPolicyDropNew:
+		select {
+		case o.q <- ent:
+		default:
+			o.d.Add(1)
+		}
+
+	case logcfg.AsyncPolicyDropOldest:
+		for {
+			select {
+			case o.q <- ent:
+				return nil
+			default:
+			}
+
+			select {
+			case <-o.q:
+				o.d.Add(1)
+			default:
+			}
+		}
+
+	default:
+		select {
+		case o.q <- ent:
+		case <-o.s:
+			return o.h.Fire(ent)
+		}
+	}
+
+	return nil
+}
+
+func (o *hka) Run(ctx context.Context) {
+	if !o.r.CompareAndSwap(false, true) {
+		return
+	}
+
+	o.w.Add(1)
+	defer o.w.Done()
+
+	// the hook is stopped after the flush of the queue
+	var x, n = context.WithCancel(context.WithoutCancel(ctx))
+
+	defer n()
+	go o.h.Run(x)
+
+	for {
+		select {
+		case e := <-o.q:
+			_ = o.h.Fire(e)
+		case <-ctx.Done():
+			o.stop()
+			o.flush()
+			return
+		case <-o.s:
+			o.flush()
+			return
+		}
+	}
+}
+
+// flush writes the entries still into the queue.
+func (o *hka) flush() {
+	for {
+		select {
+		case e := <-o.q:
+			_ = o.h.Fire(e)
+		default:
+			return
+		}
+	}
+}
+
+func (o *hka) stop() {
+	o.o.Do(func() {
+		close(o.s)
+	})
+}
+
+func (o *hka) Write(p []byte) (n int, err error) {
+	return o.h.Write(p)
+}
+
+// Close stops the worker once the queue is flushed, then closes the hook.
+func (o *hka) Close() error {
+	o.stop()
+	o.w.Wait()
+	o.flush()
+
+	return o.h.Close()
+}
@@ -29,7 +29,7 @@ package hookfile
 
 var (
 	closeStruct = make(chan struct{})
-	closeByte   = make(chan []byte)
+	closeByte   = make(chan data)
 )
 
 func init() {
@@ -38,7 +38,7 @@ func init() {
 }
 
 func (o *hkf) prepareChan() {
-	o.d.Store(make(chan []byte))
+	o.d.Store(make(chan data))
 	o.s.Store(make(chan struct{}))
 }
 
@@ -52,11 +52,11 @@ func (o *hkf) Done() <-chan struct{} {
 	return closeStruct
 }
 
-func (o *hkf) Data() <-chan []byte {
+func (o *hkf) Data() <-chan data {
 	c := o.d.Load()
 
 	if c != nil {
-		return c.(chan []byte)
+		return c.(chan data)
 	}
 
 	return closeByte
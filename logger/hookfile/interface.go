@@ -30,8 +30,10 @@ package hookfile
 import (
 	"io"
 	"os"
+	"strings"
 	"sync/atomic"
 
+	arccmp "github.com/nabbar/golib/archive/compress"
 	libiot "github.com/nabbar/golib/ioutils"
 	logcfg "github.com/nabbar/golib/logger/config"
 	loglvl "github.com/nabbar/golib/logger/level"
@@ -44,6 +46,10 @@ type HookFile interface {
 	logtps.Hook
 
 	Done() <-chan struct{}
+
+	// RegisterFuncRotation register a function called when a rotation of the log file is detected,
+	// after the first write into the new file. The function is called into a new goroutine.
+	RegisterFuncRotation(fct FuncRotation)
 }
 
 func New(opt logcfg.OptionsFile, format logrus.Formatter) (HookFile, error) {
@@ -80,6 +86,9 @@ func New(opt logcfg.OptionsFile, format logrus.Formatter) (HookFile, error) {
 		s: new(atomic.Value),
 		d: new(atomic.Value),
 		b: new(atomic.Int64),
+		l: new(atomic.Bool),
+		r: new(atomic.Value),
+		i: make(map[string]uint64),
 		o: ohkf{
 			format:           format,
 			flags:            flags,
@@ -90,34 +99,56 @@ func New(opt logcfg.OptionsFile, format logrus.Formatter) (HookFile, error) {
 			enableAccessLog:  opt.EnableAccessLog,
 			createPath:       opt.CreatePath,
 			filepath:         opt.Filepath,
+			template:         strings.Contains(opt.Filepath, logcfg.FileLevelPlaceholder),
 			fileMode:         opt.FileMode.FileMode(),
 			pathMode:         opt.PathMode.FileMode(),
+			compress:         arccmp.Parse(opt.RotateCompress),
+			keep:             opt.RotateKeep,
 		},
 	}
 
+	n.l.Store(opt.FileLock)
+
 	if opt.FileBufferSize <= libsiz.SizeKilo {
 		n.b.Store(opt.FileBufferSize.Int64())
 	} else {
 		n.b.Store(sizeBuffer)
 	}
 
-	if opt.CreatePath {
-		if e := libiot.PathCheckCreate(true, opt.Filepath, opt.FileMode.FileMode(), opt.PathMode.FileMode()); e != nil {
+	if !n.o.template {
+		if e := checkFile(opt, opt.Filepath, flags); e != nil {
+			return nil, e
+		}
+
+		return n, nil
+	}
+
+	// one file by level of the hook
+	for _, l := range LVLs {
+		if e := checkFile(opt, levelPath(opt.Filepath, l), flags); e != nil {
 			return nil, e
 		}
 	}
 
+	return n, nil
+}
+
+func checkFile(opt logcfg.OptionsFile, path string, flags int) error {
+	if opt.CreatePath {
+		if e := libiot.PathCheckCreate(true, path, opt.FileMode.FileMode(), opt.PathMode.FileMode()); e != nil {
+			return e
+		}
+	}
+
 	// #nosec
-	h, e := os.OpenFile(opt.Filepath, flags, opt.FileMode.FileMode())
+	h, e := os.OpenFile(path, flags, opt.FileMode.FileMode())
 
 	if e != nil {
-		return nil, e
+		return e
 	} else if _, e = h.Seek(0, io.SeekEnd); e != nil {
 		_ = h.Close()
-		return nil, e
-	} else if e = h.Close(); e != nil {
-		return nil, e
+		return e
 	}
 
-	return n, nil
+	return h.Close()
 }
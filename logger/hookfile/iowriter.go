@@ -30,19 +30,26 @@ package hookfile
 import (
 	"fmt"
 	"time"
+
+	"github.com/sirupsen/logrus"
 )
 
+// Write writes the given message into the file of the info level if the path contains the level placeholder.
 func (o *hkf) Write(p []byte) (n int, err error) {
+	return o.write(o.getLevelPath(logrus.InfoLevel), p)
+}
+
+func (o *hkf) write(f string, p []byte) (n int, err error) {
 	c := o.d.Load()
 
 	if c != nil {
-		if c.(chan []byte) != closeByte {
-			c.(chan []byte) <- p
+		if c.(chan data) != closeByte {
+			c.(chan data) <- newData(f, p)
 			return len(p), nil
 		}
 	}
 
-	return 0, fmt.Errorf("%v, path: %s", errStreamClosed, o.getFilepath())
+	return 0, fmt.Errorf("%v, path: %s", errStreamClosed, f)
 
 }
 
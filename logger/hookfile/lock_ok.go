@@ -0,0 +1,77 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+// +build linux darwin freebsd netbsd openbsd dragonfly
+
+/***********************************************************************************************************************
+ *
+ *   MIT License
+ *
+ *   Copyright (c) 2024 Nicolas JUHEL
+ *
+ *   Permission is hereby granted, free of charge, to any person obtaining a copy
+ *   of this software and associated documentation files (the "Software"), to deal
+ *   in the Software without restriction, including without limitation the rights
+ *   to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *   copies of the Software, and to permit persons to whom the Software is
+ *   furnished to do so, subject to the following conditions:
+ *
+ *   The above copyright notice and this permission notice shall be included in all
+ *   copies or substantial portions of the Software.
+ *
+ *   THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *   IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *   FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *   AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *   LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *   OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ *   SOFTWARE.
+ *
+ *
+ **********************************************************************************************************************/
+
+package hookfile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// lock apply an exclusive advisory lock on the given file if the file lock option is enabled.
+// If the filesystem does not support locking, the option is disabled and no error is returned.
+func (o *hkf) lock(h *os.File) error {
+	if !o.getFileLock() {
+		return nil
+	}
+
+	for {
+		e := syscall.Flock(int(h.Fd()), syscall.LOCK_EX)
+
+		if e == nil {
+			return nil
+		} else if errors.Is(e, syscall.EINTR) {
+			continue
+		} else if isLockUnsupported(e) {
+			o.l.Store(false)
+			_, _ = fmt.Fprintf(os.Stderr, "file lock not supported for log file '%s', disabling lock: %v\n", o.getFilepath(), e)
+			return nil
+		}
+
+		return e
+	}
+}
+
+func (o *hkf) unlock(h *os.File) {
+	if !o.getFileLock() {
+		return
+	}
+
+	_ = syscall.Flock(int(h.Fd()), syscall.LOCK_UN)
+}
+
+func isLockUnsupported(e error) bool {
+	return errors.Is(e, syscall.ENOTSUP) ||
+		errors.Is(e, syscall.EOPNOTSUPP) ||
+		errors.Is(e, syscall.ENOLCK) ||
+		errors.Is(e, syscall.EINVAL)
+}
@@ -32,6 +32,7 @@ import (
 	"strings"
 	"sync/atomic"
 
+	arccmp "github.com/nabbar/golib/archive/compress"
 	logtps "github.com/nabbar/golib/logger/types"
 	"github.com/sirupsen/logrus"
 )
@@ -46,15 +47,21 @@ type ohkf struct {
 	enableAccessLog  bool
 	createPath       bool
 	filepath         string
+	template         bool
 	fileMode         os.FileMode
 	pathMode         os.FileMode
+	compress         arccmp.Algorithm
+	keep             int
 }
 
 type hkf struct {
-	s *atomic.Value // channel stop struct{}
-	d *atomic.Value // channel data []byte
-	o ohkf          // config data
-	b *atomic.Int64 // buffer size
+	s *atomic.Value     // channel stop struct{}
+	d *atomic.Value     // channel data []byte
+	o ohkf              // config data
+	b *atomic.Int64     // buffer size
+	l *atomic.Bool      // file lock enabled
+	r *atomic.Value     // function rotation
+	i map[string]uint64 // inode of the last write by path, used only by Run
 }
 
 func (o *hkf) Levels() []logrus.Level {
@@ -113,7 +120,7 @@ func (o *hkf) Fire(entry *logrus.Entry) error {
 		}
 	}
 
-	if _, e = o.Write(p); e != nil {
+	if _, e = o.write(o.getLevelPath(ent.Level), p); e != nil {
 		return e
 	}
 
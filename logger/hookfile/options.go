@@ -29,7 +29,9 @@ package hookfile
 
 import (
 	"os"
+	"strings"
 
+	logcfg "github.com/nabbar/golib/logger/config"
 	"github.com/sirupsen/logrus"
 )
 
@@ -69,6 +71,19 @@ func (o *hkf) getFilepath() string {
 	return o.o.filepath
 }
 
+// getLevelPath return the path of the file of the given level, the file path if it does not contain the level placeholder.
+func (o *hkf) getLevelPath(lvl logrus.Level) string {
+	if !o.o.template {
+		return o.o.filepath
+	}
+
+	return levelPath(o.o.filepath, lvl)
+}
+
+func levelPath(path string, lvl logrus.Level) string {
+	return strings.ReplaceAll(path, logcfg.FileLevelPlaceholder, lvl.String())
+}
+
 func (o *hkf) getFileMode() os.FileMode {
 	return o.o.fileMode
 }
@@ -76,3 +91,7 @@ func (o *hkf) getFileMode() os.FileMode {
 func (o *hkf) getPathMode() os.FileMode {
 	return o.o.pathMode
 }
+
+func (o *hkf) getFileLock() bool {
+	return o.l.Load()
+}
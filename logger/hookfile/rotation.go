@@ -0,0 +1,203 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package hookfile
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	arccmp "github.com/nabbar/golib/archive/compress"
+	libsrv "github.com/nabbar/golib/server"
+)
+
+// FuncRotation is called when a rotation of the log file at the given path is detected: the inode of the
+// opened file is not the inode of the previous write. The oldPath is the path of the file with the old
+// inode into the same directory, or empty if not found (removed or moved into another directory).
+type FuncRotation func(path, oldPath string, oldInode, newInode uint64)
+
+func (o *hkf) RegisterFuncRotation(fct FuncRotation) {
+	o.r.Store(fct)
+}
+
+func (o *hkf) getFuncRotation() FuncRotation {
+	if i := o.r.Load(); i == nil {
+		return nil
+	} else if f, k := i.(FuncRotation); !k {
+		return nil
+	} else {
+		return f
+	}
+}
+
+// checkRotation compares the inode of the opened file with the inode of the previous write of the same path.
+// It must be called by the Run goroutine only.
+func (o *hkf) checkRotation(path string, h *os.File) {
+	var i, e = h.Stat()
+
+	if e != nil {
+		return
+	}
+
+	var n = fileInode(i)
+
+	if n == 0 {
+		return
+	}
+
+	old, k := o.i[path]
+	o.i[path] = n
+
+	if !k || old == n {
+		return
+	}
+
+	go o.rotated(path, old, n)
+}
+
+// rotated runs the post rotate actions: the callback, the compression of the rotated file and the cleaning of the
+// oldest rotated files.
+func (o *hkf) rotated(path string, oldInode, newInode uint64) {
+	defer func() {
+		libsrv.RecoveryCaller("golib/logger/hookfile/rotation", recover(), fmt.Sprintf("log file: %s", path))
+	}()
+
+	var old = findInode(filepath.Dir(path), oldInode)
+
+	if f := o.getFuncRotation(); f != nil {
+		f(path, old, oldInode, newInode)
+	}
+
+	if len(old) > 0 && !o.o.compress.IsNone() {
+		if e := compressFile(old, o.o.compress, o.getFileMode()); e != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "cannot compress rotated log file '%s': %v\n", old, e)
+		}
+	}
+
+	if o.o.keep > 0 {
+		cleanRotated(path, o.o.keep)
+	}
+}
+
+// findInode return the path of the file with the given inode into the given directory, empty if not found.
+func findInode(dir string, inode uint64) string {
+	l, e := os.ReadDir(dir)
+
+	if e != nil {
+		return ""
+	}
+
+	for _, f := range l {
+		if !f.Type().IsRegular() {
+			continue
+		} else if i, e := f.Info(); e != nil {
+			continue
+		} else if fileInode(i) == inode {
+			return filepath.Join(dir, f.Name())
+		}
+	}
+
+	return ""
+}
+
+// compressFile writes the given file compressed with the algorithm into the same path with the extension
+// of the algorithm, then removes the given file.
+func compressFile(path string, alg arccmp.Algorithm, mode os.FileMode) error {
+	// #nosec
+	src, e := os.Open(path)
+
+	if e != nil {
+		return e
+	}
+
+	defer func() {
+		_ = src.Close()
+	}()
+
+	// #nosec
+	dst, e := os.OpenFile(path+alg.Extension(), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+
+	if e != nil {
+		return e
+	}
+
+	w, e := alg.Writer(dst)
+
+	if e != nil {
+		_ = dst.Close()
+		return e
+	} else if _, e = io.Copy(w, src); e != nil {
+		_ = w.Close()
+		return e
+	} else if e = w.Close(); e != nil {
+		return e
+	}
+
+	_ = src.Close()
+	return os.Remove(path)
+}
+
+// cleanRotated removes the oldest rotated files of the given path (same directory, name starting with the name
+// of the path followed by a dot or a dash), to keep only the given number of rotated files.
+func cleanRotated(path string, keep int) {
+	var (
+		dir = filepath.Dir(path)
+		bas = filepath.Base(path)
+		lst = make([]os.FileInfo, 0)
+	)
+
+	l, e := os.ReadDir(dir)
+
+	if e != nil {
+		return
+	}
+
+	for _, f := range l {
+		if !f.Type().IsRegular() || f.Name() == bas {
+			continue
+		} else if !strings.HasPrefix(f.Name(), bas+".") && !strings.HasPrefix(f.Name(), bas+"-") {
+			continue
+		} else if i, e := f.Info(); e == nil {
+			lst = append(lst, i)
+		}
+	}
+
+	if len(lst) <= keep {
+		return
+	}
+
+	sort.Slice(lst, func(i, j int) bool {
+		return lst[i].ModTime().After(lst[j].ModTime())
+	})
+
+	for _, i := range lst[keep:] {
+		_ = os.Remove(filepath.Join(dir, i.Name()))
+	}
+}
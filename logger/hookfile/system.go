@@ -64,11 +64,10 @@ func (o *hkf) newBuffer(size int) *bytes.Buffer {
 	return bytes.NewBuffer(make([]byte, 0, o.getBufferSize()))
 }
 
-func (o *hkf) writeBuffer(buf *bytes.Buffer) error {
+func (o *hkf) writeBuffer(p string, buf *bytes.Buffer) error {
 	var (
 		e error
 		h *os.File
-		p = o.getFilepath()
 		m = o.getFileMode()
 		n = o.getPathMode()
 		f = o.getFlags()
@@ -84,6 +83,7 @@ func (o *hkf) writeBuffer(buf *bytes.Buffer) error {
 	defer func() {
 		libsrv.RecoveryCaller("golib/logger/hookfile/system", recover())
 		if h != nil {
+			// closing the file release also the advisory lock
 			_ = h.Close()
 		}
 	}()
@@ -93,6 +93,12 @@ func (o *hkf) writeBuffer(buf *bytes.Buffer) error {
 
 	if e != nil {
 		return e
+	}
+
+	o.checkRotation(p, h)
+
+	if e = o.lock(h); e != nil {
+		return e
 	} else if _, e = h.Seek(0, io.SeekEnd); e != nil {
 		return e
 	} else if _, e = h.Write(buf.Bytes()); e != nil {
@@ -100,15 +106,16 @@ func (o *hkf) writeBuffer(buf *bytes.Buffer) error {
 	}
 
 	*buf = *b
+	o.unlock(h)
 	e = h.Close()
 	h = nil
 
 	return e
 }
 
-func (o *hkf) freeBuffer(buf *bytes.Buffer, size int) *bytes.Buffer {
+func (o *hkf) freeBuffer(p string, buf *bytes.Buffer, size int) *bytes.Buffer {
 	defer func() {
-		libsrv.RecoveryCaller("golib/logger/hookfile/system", recover(), fmt.Sprintf("log file: %s", o.getFilepath()))
+		libsrv.RecoveryCaller("golib/logger/hookfile/system", recover(), fmt.Sprintf("log file: %s", p))
 	}()
 
 	var a = o.newBuffer(o.getBufferSize())
@@ -121,11 +128,12 @@ func (o *hkf) freeBuffer(buf *bytes.Buffer, size int) *bytes.Buffer {
 	return a
 }
 
+// Run writes the entries into their file every second, with one buffer by file
+// if the path contains the level placeholder.
 func (o *hkf) Run(ctx context.Context) {
 	var (
-		b = o.newBuffer(0)
+		b = make(map[string]*bytes.Buffer)
 		t = time.NewTicker(time.Second)
-		e error
 	)
 	defer t.Stop()
 
@@ -134,12 +142,7 @@ func (o *hkf) Run(ctx context.Context) {
 			_, _ = fmt.Fprintf(os.Stderr, "recovering panic thread on run function in golib/logger/hookfile/system.\nfor log file '%s'\n%v\n", o.getFilepath(), rec)
 		}
 		//flush buffer before exit function
-		if b.Len() > 0 {
-			if e = o.writeBuffer(b); e != nil {
-				fmt.Println(e.Error())
-			}
-			b.Reset()
-		}
+		o.flush(b)
 	}()
 
 	o.prepareChan()
@@ -154,22 +157,36 @@ func (o *hkf) Run(ctx context.Context) {
 			return
 
 		case <-t.C:
-			if b.Len() < 1 {
-				continue
-			} else if e = o.writeBuffer(b); e != nil {
-				fmt.Println(e.Error())
+			o.flush(b)
+
+		case d := <-o.Data():
+			var f, k = b[d.f]
+
+			if !k {
+				f = o.newBuffer(0)
+				b[d.f] = f
 			}
 
-		case p := <-o.Data():
 			// prevent buffer overflow
-			if b.Len()+len(p) >= b.Cap() {
-				if a := o.freeBuffer(b, len(p)); a != nil {
-					b = a
-					b.Write(p)
+			if f.Len()+len(d.p) >= f.Cap() {
+				if a := o.freeBuffer(d.f, f, len(d.p)); a != nil {
+					b[d.f] = a
+					a.Write(d.p)
 				}
 			} else {
-				_, _ = b.Write(p)
+				_, _ = f.Write(d.p)
 			}
 		}
 	}
 }
+
+// flush writes the buffers not empty into their file.
+func (o *hkf) flush(b map[string]*bytes.Buffer) {
+	for p, f := range b {
+		if f.Len() < 1 {
+			continue
+		} else if e := o.writeBuffer(p, f); e != nil {
+			fmt.Println(e.Error())
+		}
+	}
+}
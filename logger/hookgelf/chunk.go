@@ -0,0 +1,115 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package hookgelf
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+
+	libptc "github.com/nabbar/golib/network/protocol"
+	libsck "github.com/nabbar/golib/socket"
+)
+
+const (
+	chunkHeaderSize = 12
+	chunkMax        = 128
+)
+
+// chunkMagic is the magic bytes of a chunked GELF message.
+var chunkMagic = []byte{0x1e, 0x0f}
+
+func (o *hkg) isUDP() bool {
+	switch o.o.network {
+	case libptc.NetworkUDP, libptc.NetworkUDP4, libptc.NetworkUDP6:
+		return true
+	default:
+		return false
+	}
+}
+
+// send writes the message with the client: null byte delimited for tcp,
+// optionally compressed and chunked if bigger than the chunk size for udp.
+func (o *hkg) send(cli libsck.Client, p []byte) error {
+	if !o.isUDP() {
+		_, e := cli.Write(append(p, 0))
+		return e
+	}
+
+	if o.o.compress {
+		var buf = bytes.NewBuffer(make([]byte, 0, len(p)/2))
+
+		w := gzip.NewWriter(buf)
+
+		if _, e := w.Write(p); e != nil {
+			return e
+		} else if e = w.Close(); e != nil {
+			return e
+		}
+
+		p = buf.Bytes()
+	}
+
+	if len(p) <= o.o.chunk {
+		_, e := cli.Write(p)
+		return e
+	}
+
+	var (
+		siz = o.o.chunk - chunkHeaderSize
+		nbr = (len(p) + siz - 1) / siz
+		id  = make([]byte, 8)
+	)
+
+	if nbr > chunkMax {
+		return errTooManyChunks
+	} else if _, e := rand.Read(id); e != nil {
+		return e
+	}
+
+	for i := 0; i < nbr; i++ {
+		var (
+			end = (i + 1) * siz
+			buf = make([]byte, 0, o.o.chunk)
+		)
+
+		if end > len(p) {
+			end = len(p)
+		}
+
+		buf = append(buf, chunkMagic...)
+		buf = append(buf, id...)
+		buf = append(buf, byte(i), byte(nbr))
+		buf = append(buf, p[i*siz:end]...)
+
+		if _, e := cli.Write(buf); e != nil {
+			return e
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package hookgelf
+
+import (
+	"os"
+	"strings"
+	"sync"
+
+	logcfg "github.com/nabbar/golib/logger/config"
+	loglvl "github.com/nabbar/golib/logger/level"
+	logtps "github.com/nabbar/golib/logger/types"
+	libptc "github.com/nabbar/golib/network/protocol"
+	"github.com/sirupsen/logrus"
+)
+
+type HookGelf interface {
+	logtps.Hook
+
+	Done() <-chan struct{}
+}
+
+// New return a hook sending the entries as GELF 1.1 messages to the Graylog server of the given options,
+// with the udp (chunked and optionally compressed) or tcp (null byte delimited) client of the socket package.
+// The connection is opened by Run and reopened after a write error.
+func New(opt logcfg.OptionsGelf) (HookGelf, error) {
+	var (
+		LVLs = make([]logrus.Level, 0)
+		prot = libptc.NetworkUDP
+	)
+
+	if len(opt.LogLevel) > 0 {
+		for _, ls := range opt.LogLevel {
+			LVLs = append(LVLs, loglvl.Parse(ls).Logrus())
+		}
+	} else {
+		LVLs = logrus.AllLevels
+	}
+
+	if len(opt.Network) > 0 {
+		prot = libptc.Parse(opt.Network)
+	}
+
+	switch prot {
+	case libptc.NetworkUDP, libptc.NetworkUDP4, libptc.NetworkUDP6:
+	case libptc.NetworkTCP, libptc.NetworkTCP4, libptc.NetworkTCP6:
+	default:
+		return nil, errInvalidNetwork
+	}
+
+	if len(strings.TrimSpace(opt.Host)) < 1 {
+		return nil, errInvalidHost
+	}
+
+	if len(opt.Hostname) < 1 {
+		opt.Hostname, _ = os.Hostname()
+	}
+
+	return &hkg{
+		o: ohkg{
+			levels:       LVLs,
+			disableStack: opt.DisableStack,
+			enableTrace:  opt.EnableTrace,
+			network:      prot,
+			endpoint:     opt.Host,
+			hostname:     opt.Hostname,
+			chunk:        opt.GetChunkSize(),
+			compress:     opt.Compress,
+		},
+		d: make(chan []byte),
+		s: make(chan struct{}),
+		c: sync.Once{},
+	}, nil
+}
@@ -0,0 +1,194 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package hookgelf
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	logtps "github.com/nabbar/golib/logger/types"
+	libptc "github.com/nabbar/golib/network/protocol"
+	"github.com/sirupsen/logrus"
+)
+
+const gelfVersion = "1.1"
+
+// gelfKey is the invalid characters of the additional field names.
+var gelfKey = regexp.MustCompile(`[^\w.\-]`)
+
+type ohkg struct {
+	levels       []logrus.Level
+	disableStack bool
+	enableTrace  bool
+
+	network  libptc.NetworkProtocol
+	endpoint string
+	hostname string
+	chunk    int
+	compress bool
+}
+
+type hkg struct {
+	o ohkg          // config data
+	d chan []byte   // messages
+	s chan struct{} // stop signal
+	c sync.Once     // stop once
+}
+
+func (o *hkg) Levels() []logrus.Level {
+	return o.o.levels
+}
+
+func (o *hkg) RegisterHook(log *logrus.Logger) {
+	log.AddHook(o)
+}
+
+func (o *hkg) Fire(entry *logrus.Entry) error {
+	var msg = map[string]interface{}{
+		"version": gelfVersion,
+		"host":    o.o.hostname,
+		"level":   severity(entry.Level),
+	}
+
+	if s, k := entry.Data[logtps.FieldMessage].(string); k && len(s) > 0 {
+		msg["short_message"] = s
+	} else if len(entry.Message) > 0 {
+		msg["short_message"] = entry.Message
+	} else {
+		// the short message is required by GELF
+		msg["short_message"] = "-"
+	}
+
+	msg["timestamp"] = timestamp(entry)
+
+	for k, v := range entry.Data {
+		switch k {
+		case logtps.FieldMessage, logtps.FieldTime, logtps.FieldLevel:
+			continue
+		case logtps.FieldStack:
+			if o.o.disableStack {
+				continue
+			}
+		case logtps.FieldCaller, logtps.FieldFile, logtps.FieldLine:
+			if !o.o.enableTrace {
+				continue
+			}
+		}
+
+		additional(msg, k, v)
+	}
+
+	p, e := json.Marshal(msg)
+	if e != nil {
+		return e
+	}
+
+	_, e = o.Write(p)
+	return e
+}
+
+// severity return the syslog severity of the level, used as GELF level.
+func severity(lvl logrus.Level) int {
+	switch lvl {
+	case logrus.PanicLevel:
+		return 1
+	case logrus.FatalLevel:
+		return 2
+	case logrus.ErrorLevel:
+		return 3
+	case logrus.WarnLevel:
+		return 4
+	case logrus.InfoLevel:
+		return 6
+	default:
+		return 7
+	}
+}
+
+// timestamp return the time of the entry as seconds since epoch with decimal milliseconds.
+func timestamp(entry *logrus.Entry) float64 {
+	var t = entry.Time
+
+	if s, k := entry.Data[logtps.FieldTime].(string); k {
+		if v, e := time.Parse(time.RFC3339Nano, s); e == nil {
+			t = v
+		}
+	}
+
+	if t.IsZero() {
+		t = time.Now()
+	}
+
+	return float64(t.UnixMilli()) / 1000
+}
+
+// additional adds the field as GELF additional field, prefixed by an underscore.
+// The maps are flattened with the underscore as separator, the values are strings or numbers.
+func additional(msg map[string]interface{}, key string, val interface{}) {
+	key = gelfKey.ReplaceAllString(key, "_")
+
+	switch v := val.(type) {
+	case nil:
+		return
+	case map[string]interface{}:
+		for k, i := range v {
+			additional(msg, key+"_"+k, i)
+		}
+		return
+	case map[string]string:
+		for k, i := range v {
+			additional(msg, key+"_"+k, i)
+		}
+		return
+	case string:
+		msg["_"+gelfName(key)] = v
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		msg["_"+gelfName(key)] = v
+	case error:
+		msg["_"+gelfName(key)] = v.Error()
+	case fmt.Stringer:
+		msg["_"+gelfName(key)] = v.String()
+	default:
+		if p, e := json.Marshal(v); e == nil {
+			msg["_"+gelfName(key)] = string(p)
+		} else {
+			msg["_"+gelfName(key)] = fmt.Sprint(v)
+		}
+	}
+}
+
+// gelfName return the name of an additional field, the field "_id" is reserved by GELF.
+func gelfName(key string) string {
+	if key == "id" {
+		return "_id"
+	}
+
+	return key
+}
@@ -0,0 +1,123 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package hookgelf
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	libsrv "github.com/nabbar/golib/server"
+	libsck "github.com/nabbar/golib/socket"
+	sckcli "github.com/nabbar/golib/socket/client"
+)
+
+func (o *hkg) Run(ctx context.Context) {
+	var (
+		c libsck.Client
+		e error
+	)
+
+	defer func() {
+		libsrv.RecoveryCaller("golib/logger/hookgelf/system", recover())
+		// no more worker to send the messages
+		_ = o.Close()
+		if c != nil {
+			_ = c.Close()
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-o.Done():
+			return
+
+		case p := <-o.d:
+			if c == nil {
+				if c, e = o.connect(ctx); e != nil {
+					_, _ = fmt.Fprintf(os.Stderr, "cannot connect to %s: %v\n", o.getGelfInfo(), e)
+					c = nil
+					continue
+				}
+			}
+
+			if e = o.send(c, p); e != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "cannot write to %s: %v\n", o.getGelfInfo(), e)
+				// reconnect for the next message
+				_ = c.Close()
+				c = nil
+			}
+		}
+	}
+}
+
+func (o *hkg) connect(ctx context.Context) (libsck.Client, error) {
+	c, e := sckcli.New(o.o.network, o.o.endpoint)
+
+	if e != nil {
+		return nil, e
+	} else if e = c.Connect(ctx); e != nil {
+		return nil, e
+	}
+
+	return c, nil
+}
+
+func (o *hkg) getGelfInfo() string {
+	return fmt.Sprintf("GELF server '%s %s'", o.o.network.Code(), o.o.endpoint)
+}
+
+func (o *hkg) Done() <-chan struct{} {
+	return o.s
+}
+
+// Write sends the given GELF message to the worker started by Run.
+func (o *hkg) Write(p []byte) (n int, err error) {
+	select {
+	case <-o.s:
+		return 0, errStreamClosed
+	default:
+	}
+
+	select {
+	case o.d <- p:
+		return len(p), nil
+	case <-o.s:
+		return 0, errStreamClosed
+	}
+}
+
+func (o *hkg) Close() error {
+	o.c.Do(func() {
+		close(o.s)
+	})
+
+	return nil
+}
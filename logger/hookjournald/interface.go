@@ -0,0 +1,84 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package hookjournald
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+
+	logcfg "github.com/nabbar/golib/logger/config"
+	loglvl "github.com/nabbar/golib/logger/level"
+	logtps "github.com/nabbar/golib/logger/types"
+	"github.com/sirupsen/logrus"
+)
+
+type HookJournald interface {
+	logtps.Hook
+
+	Done() <-chan struct{}
+}
+
+// New return a hook writing the entries to the systemd journal with its native protocol, sending each entry
+// as one datagram with the unixgram client of the socket package. The levels are mapped to the PRIORITY field
+// and the fields of the entries to upper case journal fields. An error is returned on others systems than linux.
+func New(opt logcfg.OptionsJournald) (HookJournald, error) {
+	var (
+		LVLs = make([]logrus.Level, 0)
+	)
+
+	if len(opt.LogLevel) > 0 {
+		for _, ls := range opt.LogLevel {
+			LVLs = append(LVLs, loglvl.Parse(ls).Logrus())
+		}
+	} else {
+		LVLs = logrus.AllLevels
+	}
+
+	if len(opt.Identifier) < 1 {
+		opt.Identifier = filepath.Base(os.Args[0])
+	}
+
+	if c, e := newClient(opt.GetSocket()); e != nil {
+		return nil, e
+	} else {
+		_ = c.Close()
+	}
+
+	return &hkj{
+		o: ohkj{
+			levels:       LVLs,
+			disableStack: opt.DisableStack,
+			enableTrace:  opt.EnableTrace,
+			socket:       opt.GetSocket(),
+			identifier:   opt.Identifier,
+		},
+		d: make(chan []byte),
+		s: make(chan struct{}),
+		c: sync.Once{},
+	}, nil
+}
@@ -0,0 +1,199 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package hookjournald
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	logtps "github.com/nabbar/golib/logger/types"
+	"github.com/sirupsen/logrus"
+)
+
+// fieldMaxLen is the max length of the name of a journal field.
+const fieldMaxLen = 64
+
+// fieldKey is the invalid characters of the journal field names.
+var fieldKey = regexp.MustCompile(`[^A-Z0-9_]`)
+
+type ohkj struct {
+	levels       []logrus.Level
+	disableStack bool
+	enableTrace  bool
+
+	socket     string
+	identifier string
+}
+
+type hkj struct {
+	o ohkj          // config data
+	d chan []byte   // entries
+	s chan struct{} // stop signal
+	c sync.Once     // stop once
+}
+
+func (o *hkj) Levels() []logrus.Level {
+	return o.o.levels
+}
+
+func (o *hkj) RegisterHook(log *logrus.Logger) {
+	log.AddHook(o)
+}
+
+func (o *hkj) Fire(entry *logrus.Entry) error {
+	var (
+		buf = bytes.NewBuffer(make([]byte, 0, 512))
+		msg = entry.Message
+	)
+
+	if s, k := entry.Data[logtps.FieldMessage].(string); k && len(s) > 0 {
+		msg = s
+	}
+
+	writeField(buf, "MESSAGE", msg)
+	writeField(buf, "PRIORITY", strconv.Itoa(priority(entry.Level)))
+	writeField(buf, "SYSLOG_IDENTIFIER", o.o.identifier)
+
+	for k, v := range entry.Data {
+		switch k {
+		case logtps.FieldMessage, logtps.FieldTime, logtps.FieldLevel:
+			continue
+		case logtps.FieldStack:
+			if o.o.disableStack {
+				continue
+			}
+		case logtps.FieldCaller:
+			if o.o.enableTrace {
+				writeField(buf, "CODE_FUNC", fmt.Sprint(v))
+			}
+			continue
+		case logtps.FieldFile:
+			if o.o.enableTrace {
+				writeField(buf, "CODE_FILE", fmt.Sprint(v))
+			}
+			continue
+		case logtps.FieldLine:
+			if o.o.enableTrace {
+				writeField(buf, "CODE_LINE", fmt.Sprint(v))
+			}
+			continue
+		}
+
+		additional(buf, k, v)
+	}
+
+	return o.send(buf.Bytes())
+}
+
+// priority return the syslog priority of the level.
+func priority(lvl logrus.Level) int {
+	switch lvl {
+	case logrus.PanicLevel:
+		return 1
+	case logrus.FatalLevel:
+		return 2
+	case logrus.ErrorLevel:
+		return 3
+	case logrus.WarnLevel:
+		return 4
+	case logrus.InfoLevel:
+		return 6
+	default:
+		return 7
+	}
+}
+
+// additional adds the field with its journal name, the maps are flattened with the underscore as separator.
+func additional(buf *bytes.Buffer, key string, val interface{}) {
+	switch v := val.(type) {
+	case nil:
+		return
+	case map[string]interface{}:
+		for k, i := range v {
+			additional(buf, key+"_"+k, i)
+		}
+	case map[string]string:
+		for k, i := range v {
+			additional(buf, key+"_"+k, i)
+		}
+	case string:
+		writeField(buf, fieldName(key), v)
+	case error:
+		writeField(buf, fieldName(key), v.Error())
+	case fmt.Stringer:
+		writeField(buf, fieldName(key), v.String())
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		writeField(buf, fieldName(key), fmt.Sprint(v))
+	default:
+		if p, e := json.Marshal(v); e == nil {
+			writeField(buf, fieldName(key), string(p))
+		} else {
+			writeField(buf, fieldName(key), fmt.Sprint(v))
+		}
+	}
+}
+
+// fieldName return a valid journal field name: upper case letters, digits and underscores,
+// not starting with an underscore (reserved to the trusted fields) or a digit, 64 chars max.
+func fieldName(key string) string {
+	key = fieldKey.ReplaceAllString(strings.ToUpper(key), "_")
+	key = strings.TrimLeft(key, "_")
+
+	if len(key) < 1 || (key[0] >= '0' && key[0] <= '9') {
+		key = "F_" + key
+	}
+
+	if len(key) > fieldMaxLen {
+		key = key[:fieldMaxLen]
+	}
+
+	return key
+}
+
+// writeField writes a field with the native protocol: "NAME=value\n", or for the values with a new line
+// "NAME\n" followed by the size of the value as little endian uint64, the value and "\n".
+func writeField(buf *bytes.Buffer, name, val string) {
+	if !strings.Contains(val, "\n") {
+		buf.WriteString(name)
+		buf.WriteByte('=')
+		buf.WriteString(val)
+		buf.WriteByte('\n')
+		return
+	}
+
+	buf.WriteString(name)
+	buf.WriteByte('\n')
+	_ = binary.Write(buf, binary.LittleEndian, uint64(len(val)))
+	buf.WriteString(val)
+	buf.WriteByte('\n')
+}
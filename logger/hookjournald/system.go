@@ -0,0 +1,149 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package hookjournald
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+
+	libsrv "github.com/nabbar/golib/server"
+	libsck "github.com/nabbar/golib/socket"
+	"github.com/sirupsen/logrus"
+)
+
+func (o *hkj) Run(ctx context.Context) {
+	var (
+		c libsck.Client
+		e error
+	)
+
+	defer func() {
+		libsrv.RecoveryCaller("golib/logger/hookjournald/system", recover())
+		// no more worker to send the entries
+		_ = o.Close()
+		if c != nil {
+			_ = c.Close()
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-o.Done():
+			return
+
+		case p := <-o.d:
+			if c == nil {
+				if c, e = o.connect(ctx); e != nil {
+					_, _ = fmt.Fprintf(os.Stderr, "cannot connect to %s: %v\n", o.getJournalInfo(), e)
+					c = nil
+					continue
+				}
+			}
+
+			if _, e = c.Write(p); e == nil {
+				continue
+			} else if errors.Is(e, syscall.EMSGSIZE) {
+				// the big entries need a memfd passed to the journal, not available with the socket client
+				_, _ = fmt.Fprintf(os.Stderr, "cannot write to %s: %v (%d bytes)\n", o.getJournalInfo(), errEntryTooLarge, len(p))
+				continue
+			}
+
+			_, _ = fmt.Fprintf(os.Stderr, "cannot write to %s: %v\n", o.getJournalInfo(), e)
+			// reconnect for the next entry
+			_ = c.Close()
+			c = nil
+		}
+	}
+}
+
+func (o *hkj) connect(ctx context.Context) (libsck.Client, error) {
+	c, e := newClient(o.o.socket)
+
+	if e != nil {
+		return nil, e
+	} else if e = c.Connect(ctx); e != nil {
+		return nil, e
+	}
+
+	return c, nil
+}
+
+func (o *hkj) getJournalInfo() string {
+	return fmt.Sprintf("systemd journal '%s'", o.o.socket)
+}
+
+func (o *hkj) Done() <-chan struct{} {
+	return o.s
+}
+
+// Write sends the given message as an entry with the info priority.
+func (o *hkj) Write(p []byte) (n int, err error) {
+	var buf = bytes.NewBuffer(make([]byte, 0, len(p)+64))
+
+	writeField(buf, "MESSAGE", strings.TrimSuffix(string(p), "\n"))
+	writeField(buf, "PRIORITY", strconv.Itoa(priority(logrus.InfoLevel)))
+	writeField(buf, "SYSLOG_IDENTIFIER", o.o.identifier)
+
+	if e := o.send(buf.Bytes()); e != nil {
+		return 0, e
+	}
+
+	return len(p), nil
+}
+
+// send sends the given entry, encoded with the native protocol, to the worker started by Run.
+func (o *hkj) send(p []byte) error {
+	select {
+	case <-o.s:
+		return errStreamClosed
+	default:
+	}
+
+	select {
+	case o.d <- p:
+		return nil
+	case <-o.s:
+		return errStreamClosed
+	}
+}
+
+func (o *hkj) Close() error {
+	o.c.Do(func() {
+		close(o.s)
+	})
+
+	return nil
+}
@@ -0,0 +1,75 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package hookredact
+
+import (
+	"strings"
+
+	logcfg "github.com/nabbar/golib/logger/config"
+	"github.com/sirupsen/logrus"
+)
+
+// FuncRedact is a custom redaction called for each field, at any depth of the map values, after the rules
+// of the options. It returns the value to write for the given field name.
+type FuncRedact func(key string, val interface{}) interface{}
+
+// HookRedact is a hook masking the sensitive fields and values of the entries. It must be registered
+// before the other hooks of the logger to update the entries before they are formatted by any output.
+type HookRedact interface {
+	logrus.Hook
+
+	// RegisterHook register the hook into the given logger.
+	RegisterHook(log *logrus.Logger)
+}
+
+// New return a hook applying the rules of the given options and the custom redaction returned by fct.
+// The function fct is called for each entry, allowing to change the custom redaction at runtime.
+// An error is returned if a pattern of the options is invalid.
+func New(opt *logcfg.OptionsRedact, fct func() FuncRedact) (HookRedact, error) {
+	var o = &hkr{
+		f: fct,
+		m: logcfg.RedactDefaultMask,
+	}
+
+	if opt == nil {
+		return o, nil
+	}
+
+	if r, e := opt.Compile(); e != nil {
+		return nil, e
+	} else {
+		o.r = r
+	}
+
+	for _, k := range opt.Fields {
+		o.k = append(o.k, strings.ToLower(k))
+	}
+
+	o.m = opt.GetMask()
+
+	return o, nil
+}
@@ -0,0 +1,168 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package hookredact
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+type hkr struct {
+	k []string          // lower case patterns of field names
+	r []*regexp.Regexp  // patterns of values
+	m string            // mask
+	f func() FuncRedact // custom redaction
+}
+
+func (o *hkr) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (o *hkr) RegisterHook(log *logrus.Logger) {
+	log.AddHook(o)
+}
+
+// Fire updates the entry in place: logrus gives to the hooks a copy of the fields of the caller,
+// the nested maps are copied before any change.
+func (o *hkr) Fire(entry *logrus.Entry) error {
+	var f FuncRedact
+
+	if o.f != nil {
+		f = o.f()
+	}
+
+	if len(o.k) < 1 && len(o.r) < 1 && f == nil {
+		return nil
+	}
+
+	if len(entry.Message) > 0 {
+		entry.Message = o.replace(entry.Message)
+	}
+
+	for k, v := range entry.Data {
+		entry.Data[k] = o.field(k, v, f)
+	}
+
+	return nil
+}
+
+func (o *hkr) field(key string, val interface{}, f FuncRedact) interface{} {
+	if o.match(key) {
+		val = o.m
+	} else {
+		val = o.value(val, f)
+	}
+
+	if f != nil {
+		val = f(key, val)
+	}
+
+	return val
+}
+
+func (o *hkr) value(val interface{}, f FuncRedact) interface{} {
+	switch v := val.(type) {
+	case string:
+		return o.replace(v)
+
+	case error:
+		if s := o.replace(v.Error()); s != v.Error() {
+			return s
+		}
+
+	case fmt.Stringer:
+		if s := o.replace(v.String()); s != v.String() {
+			return s
+		}
+
+	case map[string]interface{}:
+		var res = make(map[string]interface{}, len(v))
+
+		for k, i := range v {
+			res[k] = o.field(k, i, f)
+		}
+
+		return res
+
+	case map[string]string:
+		var res = make(map[string]string, len(v))
+
+		for k, i := range v {
+			res[k] = fmt.Sprint(o.field(k, i, f))
+		}
+
+		return res
+
+	case []interface{}:
+		var res = make([]interface{}, 0, len(v))
+
+		for _, i := range v {
+			res = append(res, o.value(i, f))
+		}
+
+		return res
+
+	case []string:
+		var res = make([]string, 0, len(v))
+
+		for _, i := range v {
+			res = append(res, o.replace(i))
+		}
+
+		return res
+	}
+
+	return val
+}
+
+func (o *hkr) match(key string) bool {
+	if len(o.k) < 1 {
+		return false
+	}
+
+	key = strings.ToLower(key)
+
+	for _, p := range o.k {
+		if ok, _ := path.Match(p, key); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (o *hkr) replace(s string) string {
+	for _, r := range o.r {
+		s = r.ReplaceAllLiteralString(s, o.m)
+	}
+
+	return s
+}
@@ -28,6 +28,7 @@
 package logger
 
 import (
+	"context"
 	"io"
 	"log"
 	"sync"
@@ -68,6 +69,20 @@ type Logger interface {
 	//GetOptions return the options for the logger
 	GetOptions() *logcfg.Options
 
+	//DroppedEntries return the number of entries dropped by the asynchronous outputs of the current options
+	// because their queue was full. See the Async options.
+	DroppedEntries() uint64
+
+	//RegisterFuncRedact allow to register a custom redaction called for each field of the entries, at any depth of the map values,
+	// after the rules of the Redact options. The function returns the value to write for the given field name.
+	// The function can be changed at any time, to remove it, just call RegisterFuncRedact with nil as param.
+	RegisterFuncRedact(fct func(key string, val interface{}) interface{})
+
+	//RegisterFuncRotation allow to register a function called when a rotation of a log file is detected, with the path of the
+	// log file, the path of the rotated file if found (empty otherwise), the inode of the rotated file and the inode of the new file.
+	// The function is called into a new goroutine, before the compression and the cleaning of the RotateCompress and RotateKeep options.
+	RegisterFuncRotation(fct func(path, oldPath string, oldInode, newInode uint64))
+
 	//SetFields allow to set or update the default fields for all logger entry
 	// Fields are custom information added into log message
 	SetFields(field logfld.Fields)
@@ -79,6 +94,29 @@ type Logger interface {
 	//Clone allow to duplicate the logger with a copy of the logger
 	Clone() Logger
 
+	//Named return a child logger sharing the options and outputs of this logger, adding its name into the field logger.
+	// The name of a child of a named logger is prefixed by the parent name with a dot ("httpserver.pool").
+	// The level of a named logger is the level set for its name or its nearest parent name, or the level of the root logger.
+	// Calling SetLevel on a named logger set the level of its name, Close does nothing.
+	Named(name string) Logger
+
+	//WithContext return a child logger sharing the options, outputs and level of this logger, adding to each entry
+	// the correlation ids (request id, trace id, span id) and the fields carried by the given context (see fields.WithContext).
+	// Calling Close on this logger does nothing.
+	WithContext(ctx context.Context) Logger
+
+	//Name return the name of the logger, empty for a root logger
+	Name() string
+
+	//SetNamedLevel allow to change at runtime the level of the named loggers with the given name and of their children
+	SetNamedLevel(name string, lvl loglvl.Level)
+
+	//DelNamedLevel remove the level of the given name, the named loggers use the level of their parent name again
+	DelNamedLevel(name string)
+
+	//GetNamedLevels return the levels set by name
+	GetNamedLevels() map[string]loglvl.Level
+
 	//SetSPF13Level allow to plus spf13 logger (jww) to this logger
 	SetSPF13Level(lvl loglvl.Level, log *jww.Notepad)
 
@@ -129,6 +167,7 @@ func New(ctx libctx.FuncContext) Logger {
 		x: libctx.NewConfig[uint8](ctx),
 		f: logfld.New(ctx),
 		c: new(atomic.Value),
+		n: newNamedLevel(),
 	}
 
 	l.SetLevel(loglvl.InfoLevel)
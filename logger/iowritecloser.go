@@ -35,7 +35,10 @@ import (
 )
 
 func (o *logger) Close() error {
-	if o != nil && o.hasCloser() {
+	if o != nil && (len(o.p) > 0 || o.t != nil) {
+		// the outputs are owned by the root logger
+		return nil
+	} else if o != nil && o.hasCloser() {
 		o.switchCloser(nil)
 	}
 
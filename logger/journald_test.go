@@ -0,0 +1,116 @@
+//go:build linux
+// +build linux
+
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package logger_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	liblog "github.com/nabbar/golib/logger"
+	logcfg "github.com/nabbar/golib/logger/config"
+	loglvl "github.com/nabbar/golib/logger/level"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// parseJournal decodes the fields of an entry of the journal native protocol.
+func parseJournal(p []byte) map[string]string {
+	var res = make(map[string]string)
+
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		Expect(i).To(BeNumerically(">", 0))
+
+		if j := bytes.IndexByte(p[:i], '='); j > 0 {
+			res[string(p[:j])] = string(p[j+1 : i])
+			p = p[i+1:]
+			continue
+		}
+
+		n := int(binary.LittleEndian.Uint64(p[i+1 : i+9]))
+		res[string(p[:i])] = string(p[i+9 : i+9+n])
+		p = p[i+9+n+1:]
+	}
+
+	return res
+}
+
+var _ = Describe("Logger Journald", func() {
+	Context("Writing to the systemd journal", func() {
+		It("Must send the entries with the native protocol", func() {
+			dir, err := os.MkdirTemp("", "journal")
+			Expect(err).ToNot(HaveOccurred())
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+
+			sck := filepath.Join(dir, "socket")
+			con, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: sck, Net: "unixgram"})
+			Expect(err).ToNot(HaveOccurred())
+			defer func() {
+				_ = con.Close()
+			}()
+
+			log := liblog.New(GetContext)
+			defer func() {
+				Expect(log.Close()).ToNot(HaveOccurred())
+			}()
+
+			log.SetLevel(loglvl.InfoLevel)
+			Expect(log.SetOptions(&logcfg.Options{
+				Stdout: &logcfg.OptionsStd{DisableStandard: true},
+				LogJournald: &logcfg.OptionsJournald{
+					Socket:      sck,
+					Identifier:  "golib-test",
+					EnableTrace: true,
+				},
+			})).ToNot(HaveOccurred())
+
+			log.Error("journal error message\nwith two lines", map[string]interface{}{"user": "bob", "_id": 7})
+
+			var buf = make([]byte, 65535)
+			Expect(con.SetReadDeadline(time.Now().Add(5 * time.Second))).To(Succeed())
+			n, _, err := con.ReadFrom(buf)
+			Expect(err).ToNot(HaveOccurred())
+
+			fld := parseJournal(buf[:n])
+			Expect(fld).To(HaveKeyWithValue("MESSAGE", "journal error message\nwith two lines"))
+			Expect(fld).To(HaveKeyWithValue("PRIORITY", "3"))
+			Expect(fld).To(HaveKeyWithValue("SYSLOG_IDENTIFIER", "golib-test"))
+			Expect(fld).To(HaveKeyWithValue("DATA_USER", "bob"))
+			Expect(fld).To(HaveKeyWithValue("DATA__ID", "7"))
+			Expect(fld).To(HaveKey("CODE_LINE"))
+		})
+	})
+})
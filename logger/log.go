@@ -116,7 +116,7 @@ func (o *logger) newEntry(lvl loglvl.Level, message string, err []error, fields
 	}
 
 	var (
-		fct = o.getLogrus
+		fct = o.getLogrusNamed
 		ent = logent.New(lvl)
 		frm = o.getCaller()
 		stk = o.getStack()
@@ -132,6 +132,11 @@ func (o *logger) newEntry(lvl loglvl.Level, message string, err []error, fields
 	ent.ErrorSet(err)
 	ent.DataSet(data)
 	ent.SetLogger(fct)
+
+	if o.t != nil {
+		ent.SetContext(o.t)
+	}
+
 	ent.SetEntryContext(time.Now(), stk, frm.Function, frm.File, uint64(frm.Line), message)
 	ent.FieldMerge(fields)
 
@@ -37,7 +37,11 @@ import (
 	iotclo "github.com/nabbar/golib/ioutils/mapCloser"
 	logcfg "github.com/nabbar/golib/logger/config"
 	logfld "github.com/nabbar/golib/logger/fields"
+	logasy "github.com/nabbar/golib/logger/hookasync"
 	logfil "github.com/nabbar/golib/logger/hookfile"
+	loggel "github.com/nabbar/golib/logger/hookgelf"
+	logjrn "github.com/nabbar/golib/logger/hookjournald"
+	logrdc "github.com/nabbar/golib/logger/hookredact"
 	logerr "github.com/nabbar/golib/logger/hookstderr"
 	logout "github.com/nabbar/golib/logger/hookstdout"
 	logsys "github.com/nabbar/golib/logger/hooksyslog"
@@ -111,11 +115,32 @@ func (o *logger) Clone() Logger {
 		x: o.x.Clone(nil),
 		f: o.f.FieldsClone(nil),
 		c: new(atomic.Value),
+		n: o.n.Clone(),
+		p: o.p,
+		t: o.t,
 	}
 
 	return l
 }
 
+func (o *logger) WithContext(ctx context.Context) Logger {
+	if o == nil {
+		return nil
+	} else if ctx == nil {
+		return o
+	}
+
+	return &logger{
+		m: sync.RWMutex{},
+		x: o.x,
+		f: o.GetFields(),
+		c: o.c,
+		n: o.n,
+		p: o.p,
+		t: ctx,
+	}
+}
+
 func (o *logger) RegisterFuncUpdateLogger(fct func(log Logger)) {
 	o.x.Store(keyFctUpdLog, fct)
 }
@@ -148,7 +173,44 @@ func (o *logger) runFuncUpdateLevel() {
 	}
 }
 
+func (o *logger) RegisterFuncRedact(fct func(key string, val interface{}) interface{}) {
+	o.x.Store(keyFctRedact, fct)
+}
+
+func (o *logger) getFuncRedact() logrdc.FuncRedact {
+	if i, l := o.x.Load(keyFctRedact); !l {
+		return nil
+	} else if f, k := i.(func(key string, val interface{}) interface{}); !k {
+		return nil
+	} else if f == nil {
+		return nil
+	} else {
+		return f
+	}
+}
+
+func (o *logger) RegisterFuncRotation(fct func(path, oldPath string, oldInode, newInode uint64)) {
+	o.x.Store(keyFctRotate, fct)
+}
+
+func (o *logger) runFuncRotation(path, oldPath string, oldInode, newInode uint64) {
+	if i, l := o.x.Load(keyFctRotate); !l {
+		return
+	} else if f, k := i.(func(path, oldPath string, oldInode, newInode uint64)); !k {
+		return
+	} else if f == nil {
+		return
+	} else {
+		f(path, oldPath, oldInode, newInode)
+	}
+}
+
 func (o *logger) SetLevel(lvl loglvl.Level) {
+	if len(o.p) > 0 {
+		o.SetNamedLevel(o.p, lvl)
+		return
+	}
+
 	o.x.Store(keyLevel, lvl)
 	o.setLogrusLevel(o.GetLevel())
 	o.runFuncUpdateLevel()
@@ -157,7 +219,18 @@ func (o *logger) SetLevel(lvl loglvl.Level) {
 func (o *logger) GetLevel() loglvl.Level {
 	if o == nil {
 		return loglvl.NilLevel
-	} else if o.x == nil {
+	} else if len(o.p) < 1 || o.n == nil {
+		return o.getLevel()
+	} else if l, k := o.n.Get(o.p); k {
+		return l
+	}
+
+	return o.getLevel()
+}
+
+// getLevel return the level of the root logger.
+func (o *logger) getLevel() loglvl.Level {
+	if o.x == nil {
 		return loglvl.NilLevel
 	} else if i, l := o.x.Load(keyLevel); !l {
 		return loglvl.NilLevel
@@ -192,29 +265,49 @@ func (o *logger) GetFields() logfld.Fields {
 
 func (o *logger) SetOptions(opt *logcfg.Options) error {
 	var (
-		lvl = o.GetLevel()
+		lvl = o.getLevel()
 		obj = logrus.New()
 		hkl = make([]logtps.Hook, 0)
 	)
 
 	o.optionsMerge(opt)
 
+	// routes are compiled into a copy used only to build the hooks,
+	// to keep the stored options mergeable with the next ones.
+	cmp, err := opt.CompileRoutes()
+	if err != nil {
+		return err
+	}
+
 	obj.SetLevel(lvl.Logrus())
 	obj.SetFormatter(o.defaultFormatter(nil))
 	obj.SetOutput(io.Discard) // Send all logs to nowhere by default
 
-	if opt.Stdout != nil && !opt.Stdout.DisableStandard {
-		f := o.defaultFormatter(opt.Stdout)
+	// the redaction is the first hook to update the entries before any output
+	if h, e := logrdc.New(cmp.Redact, o.getFuncRedact); e != nil {
+		return e
+	} else {
+		h.RegisterHook(obj)
+	}
+
+	if cmp.Stdout != nil && !cmp.Stdout.DisableStandard {
+		f := o.defaultFormatter(cmp.Stdout)
 		l := []logrus.Level{
 			logrus.InfoLevel,
 			logrus.DebugLevel,
 			logrus.TraceLevel,
 		}
 
-		if h, e := logout.New(opt.Stdout, l, f); e != nil {
-			return e
-		} else {
-			hkl = append(hkl, h)
+		if len(cmp.Stdout.LogLevelStdout) > 0 || len(cmp.Stdout.LogLevelStderr) > 0 {
+			l = stdLevels(cmp.Stdout.LogLevelStdout)
+		}
+
+		if len(l) > 0 {
+			if h, e := logout.New(cmp.Stdout, l, f); e != nil {
+				return e
+			} else {
+				hkl = append(hkl, h)
+			}
 		}
 
 		l = []logrus.Level{
@@ -224,25 +317,32 @@ func (o *logger) SetOptions(opt *logcfg.Options) error {
 			logrus.WarnLevel,
 		}
 
-		if h, e := logerr.New(opt.Stdout, l, f); e != nil {
-			return e
-		} else {
-			hkl = append(hkl, h)
+		if len(cmp.Stdout.LogLevelStdout) > 0 || len(cmp.Stdout.LogLevelStderr) > 0 {
+			l = stdLevels(cmp.Stdout.LogLevelStderr)
+		}
+
+		if len(l) > 0 {
+			if h, e := logerr.New(cmp.Stdout, l, f); e != nil {
+				return e
+			} else {
+				hkl = append(hkl, h)
+			}
 		}
 	}
 
-	if len(opt.LogFile) > 0 {
-		for _, f := range opt.LogFile {
-			if h, e := logfil.New(f, o.defaultFormatterNoColor()); e != nil {
+	if len(cmp.LogFile) > 0 {
+		for _, f := range cmp.LogFile {
+			if h, e := logfil.New(f, o.fileFormatter(f)); e != nil {
 				return e
 			} else {
+				h.RegisterFuncRotation(o.runFuncRotation)
 				hkl = append(hkl, h)
 			}
 		}
 	}
 
-	if len(opt.LogSyslog) > 0 {
-		for _, s := range opt.LogSyslog {
+	if len(cmp.LogSyslog) > 0 {
+		for _, s := range cmp.LogSyslog {
 			if h, e := logsys.New(s, o.defaultFormatterNoColor()); e != nil {
 				return e
 			} else {
@@ -251,6 +351,34 @@ func (o *logger) SetOptions(opt *logcfg.Options) error {
 		}
 	}
 
+	if len(cmp.LogGelf) > 0 {
+		for _, g := range cmp.LogGelf {
+			if h, e := loggel.New(g); e != nil {
+				return e
+			} else {
+				hkl = append(hkl, h)
+			}
+		}
+	}
+
+	if cmp.LogJournald != nil {
+		if h, e := logjrn.New(*cmp.LogJournald); e != nil {
+			return e
+		} else {
+			hkl = append(hkl, h)
+		}
+	}
+
+	var asy = make([]logasy.HookAsync, 0)
+
+	if cmp.Async != nil && cmp.Async.Enable {
+		for i, h := range hkl {
+			a := logasy.New(h, cmp.Async)
+			asy = append(asy, a)
+			hkl[i] = a
+		}
+	}
+
 	if len(hkl) > 0 {
 		var clo = o.newCloser()
 
@@ -267,11 +395,22 @@ func (o *logger) SetOptions(opt *logcfg.Options) error {
 
 	o.x.Store(keyOptions, opt)
 	o.x.Store(keyLogrus, obj)
+	o.x.Store(keyAsync, asy)
 	o.runFuncUpdateLogger()
 
 	return nil
 }
 
+func stdLevels(lst []string) []logrus.Level {
+	var res = make([]logrus.Level, 0, len(lst))
+
+	for _, l := range lst {
+		res = append(res, loglvl.Parse(l).Logrus())
+	}
+
+	return res
+}
+
 func (o *logger) GetOptions() *logcfg.Options {
 	if o == nil {
 		return &logcfg.Options{}
@@ -285,3 +424,21 @@ func (o *logger) GetOptions() *logcfg.Options {
 		return v
 	}
 }
+
+func (o *logger) DroppedEntries() uint64 {
+	var res uint64
+
+	if o == nil || o.x == nil {
+		return 0
+	} else if i, l := o.x.Load(keyAsync); !l {
+		return 0
+	} else if v, k := i.([]logasy.HookAsync); !k {
+		return 0
+	} else {
+		for _, h := range v {
+			res += h.Dropped()
+		}
+	}
+
+	return res
+}
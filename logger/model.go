@@ -43,6 +43,7 @@ import (
 	liberr "github.com/nabbar/golib/errors"
 	logcfg "github.com/nabbar/golib/logger/config"
 	logfld "github.com/nabbar/golib/logger/fields"
+	logfmt "github.com/nabbar/golib/logger/formatter"
 	loglvl "github.com/nabbar/golib/logger/level"
 	"github.com/sirupsen/logrus"
 )
@@ -57,9 +58,13 @@ const (
 	keyFilter
 	keyFctUpdLog
 	keyFctUpdLvl
+	keyAsync
+	keyFctRedact
+	keyFctRotate
 
 	_TraceFilterMod    = "/pkg/mod/"
 	_TraceFilterVendor = "/vendor/"
+	_TraceMaxDepth     = 32
 )
 
 var _selfPackage = path.Base(reflect.TypeOf(logger{}).PkgPath())
@@ -69,6 +74,9 @@ type logger struct {
 	x libctx.Config[uint8] // cf const key...
 	f logfld.Fields        // fields map
 	c *atomic.Value        // closer
+	n *namedLevel          // level overrides by name, shared with the named loggers
+	p string               // name of a named logger, empty for a root logger
+	t context.Context      // request context of a logger returned by WithContext, nil otherwise
 }
 
 func defaultFormatter() logrus.TextFormatter {
@@ -92,6 +100,12 @@ func defaultFormatter() logrus.TextFormatter {
 }
 
 func (o *logger) defaultFormatter(opt *logcfg.OptionsStd) logrus.Formatter {
+	if opt != nil {
+		if j := jsonFormatter(opt.Format); j != nil {
+			return j
+		}
+	}
+
 	f := defaultFormatter()
 
 	if opt != nil && opt.DisableColor {
@@ -114,6 +128,26 @@ func (o *logger) defaultFormatterNoColor() logrus.Formatter {
 	return &f
 }
 
+func (o *logger) fileFormatter(opt logcfg.OptionsFile) logrus.Formatter {
+	if j := jsonFormatter(opt.Format); j != nil {
+		return j
+	}
+
+	return o.defaultFormatterNoColor()
+}
+
+// jsonFormatter return the formatter of the json formats, or nil for the text format.
+func jsonFormatter(format string) logrus.Formatter {
+	switch strings.ToLower(format) {
+	case logcfg.FormatJSON:
+		return logfmt.NewJSON()
+	case logcfg.FormatECS:
+		return logfmt.NewECS()
+	default:
+		return nil
+	}
+}
+
 func (o *logger) contextNew() context.Context {
 	ctx, cnl := context.WithCancel(o.x.GetContext())
 	o.x.Store(KeyCancel, cnl)
@@ -184,29 +218,66 @@ func (o *logger) getStack() uint64 {
 }
 
 func (o *logger) getCaller() runtime.Frame {
-	// Set size to targetFrameIndex+2 to ensure we have room for one more caller than we need.
-	programCounters := make([]uintptr, 10, 255)
-	n := runtime.Callers(1, programCounters)
-
-	if n > 0 {
-		frames := runtime.CallersFrames(programCounters[:n])
-		more := true
-
-		for more {
-			var frame runtime.Frame
-			frame, more = frames.Next()
-
-			if strings.Contains(frame.Function, _selfPackage) {
+	var (
+		pcs [_TraceMaxDepth]uintptr
+		skp = o.GetOptions().TraceSkip
+		// skip runtime.Callers and getCaller
+		n = runtime.Callers(2, pcs[:])
+	)
+
+	for i := 0; i < n; i++ {
+		for _, f := range callerFrames(pcs[i]) {
+			if f.s {
+				continue
+			} else if skp > 0 {
+				skp--
 				continue
 			}
 
-			return frame
+			return f.f
 		}
 	}
 
 	return runtime.Frame{Function: "unknown", File: "unknown", Line: 0}
 }
 
+// callerFrame is a resolved frame of a program counter.
+type callerFrame struct {
+	f runtime.Frame // frame
+	s bool          // true if the frame is into the logger package
+}
+
+// _callerCache store the resolved frames by program counter (map[uintptr][]callerFrame).
+// The number of program counters being bounded by the size of the binary, the cache is never purged.
+var _callerCache sync.Map
+
+// callerFrames return the frames of the given program counter, including the inlined frames,
+// resolving them only at the first call.
+func callerFrames(pc uintptr) []callerFrame {
+	if i, l := _callerCache.Load(pc); l {
+		return i.([]callerFrame)
+	}
+
+	var (
+		res  = make([]callerFrame, 0, 1)
+		frm  = runtime.CallersFrames([]uintptr{pc})
+		more = true
+	)
+
+	for more {
+		var f runtime.Frame
+		f, more = frm.Next()
+
+		res = append(res, callerFrame{
+			f: f,
+			s: strings.Contains(f.Function, _selfPackage),
+		})
+	}
+
+	_callerCache.Store(pc, res)
+	return res
+}
+
 func (o *logger) filterPath(pathname string) string {
 	pathname = liberr.ConvPathFromLocal(pathname)
 
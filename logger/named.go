@@ -0,0 +1,181 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package logger
+
+import (
+	"strings"
+	"sync"
+
+	loglvl "github.com/nabbar/golib/logger/level"
+	logtps "github.com/nabbar/golib/logger/types"
+	"github.com/sirupsen/logrus"
+)
+
+// namedLevel store the level overrides by name of the named loggers of a same root logger.
+type namedLevel struct {
+	m sync.RWMutex
+	l map[string]loglvl.Level
+}
+
+func newNamedLevel() *namedLevel {
+	return &namedLevel{
+		l: make(map[string]loglvl.Level),
+	}
+}
+
+func (o *namedLevel) Set(name string, lvl loglvl.Level) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.l[name] = lvl
+}
+
+func (o *namedLevel) Del(name string) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	delete(o.l, name)
+}
+
+// Get return the override of the given name or of its nearest parent name ("a.b" for "a.b.c").
+func (o *namedLevel) Get(name string) (loglvl.Level, bool) {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	for len(name) > 0 {
+		if l, k := o.l[name]; k {
+			return l, true
+		} else if i := strings.LastIndex(name, "."); i < 0 {
+			break
+		} else {
+			name = name[:i]
+		}
+	}
+
+	return loglvl.NilLevel, false
+}
+
+func (o *namedLevel) List() map[string]loglvl.Level {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	var res = make(map[string]loglvl.Level, len(o.l))
+
+	for k, v := range o.l {
+		res[k] = v
+	}
+
+	return res
+}
+
+func (o *namedLevel) Clone() *namedLevel {
+	return &namedLevel{
+		l: o.List(),
+	}
+}
+
+func (o *logger) Named(name string) Logger {
+	if o == nil {
+		return nil
+	}
+
+	name = strings.Trim(strings.TrimSpace(name), ".")
+
+	if len(name) < 1 {
+		return o
+	} else if len(o.p) > 0 {
+		name = o.p + "." + name
+	}
+
+	return &logger{
+		m: sync.RWMutex{},
+		x: o.x,
+		f: o.GetFields().Add(logtps.FieldLogger, name),
+		c: o.c,
+		n: o.n,
+		p: name,
+		t: o.t,
+	}
+}
+
+func (o *logger) Name() string {
+	if o == nil {
+		return ""
+	}
+
+	return o.p
+}
+
+func (o *logger) SetNamedLevel(name string, lvl loglvl.Level) {
+	if o == nil || o.n == nil {
+		return
+	}
+
+	o.n.Set(strings.Trim(strings.TrimSpace(name), "."), lvl)
+}
+
+func (o *logger) DelNamedLevel(name string) {
+	if o == nil || o.n == nil {
+		return
+	}
+
+	o.n.Del(strings.Trim(strings.TrimSpace(name), "."))
+}
+
+func (o *logger) GetNamedLevels() map[string]loglvl.Level {
+	if o == nil || o.n == nil {
+		return make(map[string]loglvl.Level)
+	}
+
+	return o.n.List()
+}
+
+// getLogrusNamed return the logrus logger of the root logger for a root logger, or a logrus logger
+// sharing its hooks with the level of the named logger. The hooks are writing the logs, the output being discarded.
+func (o *logger) getLogrusNamed() *logrus.Logger {
+	var r = o.getLogrus()
+
+	if r == nil || len(o.p) < 1 {
+		return r
+	}
+
+	var l = o.GetLevel()
+
+	if l == loglvl.NilLevel {
+		return nil
+	}
+
+	return &logrus.Logger{
+		Out:          r.Out,
+		Hooks:        r.Hooks,
+		Formatter:    r.Formatter,
+		ReportCaller: r.ReportCaller,
+		Level:        l.Logrus(),
+		ExitFunc:     r.ExitFunc,
+		BufferPool:   r.BufferPool,
+	}
+}
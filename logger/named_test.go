@@ -0,0 +1,132 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package logger_test
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	liblog "github.com/nabbar/golib/logger"
+	logcfg "github.com/nabbar/golib/logger/config"
+	loglvl "github.com/nabbar/golib/logger/level"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Logger Named", func() {
+	Context("Resolving the level of named loggers", func() {
+		It("Must use the level of the nearest name or the root level", func() {
+			log := liblog.New(GetContext)
+			defer func() {
+				Expect(log.Close()).ToNot(HaveOccurred())
+			}()
+
+			log.SetLevel(loglvl.WarnLevel)
+
+			srv := log.Named("httpserver")
+			pol := srv.Named("pool")
+
+			Expect(srv.Name()).To(Equal("httpserver"))
+			Expect(pol.Name()).To(Equal("httpserver.pool"))
+			Expect(log.Name()).To(BeEmpty())
+			Expect(pol.GetLevel()).To(Equal(loglvl.WarnLevel))
+
+			log.SetNamedLevel("httpserver", loglvl.DebugLevel)
+			Expect(srv.GetLevel()).To(Equal(loglvl.DebugLevel))
+			Expect(pol.GetLevel()).To(Equal(loglvl.DebugLevel))
+			Expect(log.GetLevel()).To(Equal(loglvl.WarnLevel))
+
+			pol.SetLevel(loglvl.ErrorLevel)
+			Expect(pol.GetLevel()).To(Equal(loglvl.ErrorLevel))
+			Expect(srv.GetLevel()).To(Equal(loglvl.DebugLevel))
+			Expect(log.GetNamedLevels()).To(HaveLen(2))
+
+			log.DelNamedLevel("httpserver")
+			log.DelNamedLevel("httpserver.pool")
+			Expect(pol.GetLevel()).To(Equal(loglvl.WarnLevel))
+			Expect(log.GetNamedLevels()).To(BeEmpty())
+		})
+	})
+
+	Context("Logging with named loggers", func() {
+		It("Must write the entries allowed by the level of each logger", func() {
+			fsp, err := GetTempFile()
+			Expect(err).ToNot(HaveOccurred())
+
+			defer func() {
+				_ = DelTempFile(fsp)
+			}()
+
+			log := liblog.New(GetContext)
+			defer func() {
+				Expect(log.Close()).ToNot(HaveOccurred())
+			}()
+
+			log.SetLevel(loglvl.InfoLevel)
+			Expect(log.SetOptions(&logcfg.Options{
+				LogFile: []logcfg.OptionsFile{
+					{
+						Filepath:   fsp,
+						Create:     true,
+						CreatePath: true,
+					},
+				},
+			})).ToNot(HaveOccurred())
+
+			// waiting the file hook is running
+			time.Sleep(500 * time.Millisecond)
+
+			pol := log.Named("httpserver.pool")
+			log.SetNamedLevel("httpserver", loglvl.DebugLevel)
+
+			pol.Debug("named debug message", nil)
+			log.Debug("root debug message", nil)
+			log.Info("root info message", nil)
+
+			Expect(pol.Close()).ToNot(HaveOccurred())
+
+			Eventually(func() string {
+				b, _ := os.ReadFile(fsp)
+				return string(b)
+			}, 5*time.Second, 50*time.Millisecond).Should(And(
+				ContainSubstring("named debug message"),
+				ContainSubstring("root info message"),
+			))
+
+			b, err := os.ReadFile(fsp)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(b)).ToNot(ContainSubstring("root debug message"))
+
+			for _, l := range strings.Split(string(b), "\n") {
+				if strings.Contains(l, "named debug message") {
+					Expect(l).To(ContainSubstring("httpserver.pool"))
+				}
+			}
+		})
+	})
+})
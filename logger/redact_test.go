@@ -0,0 +1,131 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package logger_test
+
+import (
+	"os"
+	"time"
+
+	liblog "github.com/nabbar/golib/logger"
+	logcfg "github.com/nabbar/golib/logger/config"
+	loglvl "github.com/nabbar/golib/logger/level"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Logger Redact", func() {
+	Context("Checking the redaction rules", func() {
+		It("Must fail with an invalid pattern", func() {
+			opt := &logcfg.Options{
+				Redact: &logcfg.OptionsRedact{
+					Values: []string{"Bearer ("},
+				},
+			}
+
+			Expect(opt.Validate()).To(HaveOccurred())
+
+			log := liblog.New(GetContext)
+			defer func() {
+				Expect(log.Close()).ToNot(HaveOccurred())
+			}()
+
+			Expect(log.SetOptions(opt)).To(HaveOccurred())
+		})
+	})
+
+	Context("Logging with redaction rules", func() {
+		It("Must mask the sensitive fields and values before writing them", func() {
+			fsp, err := GetTempFile()
+			Expect(err).ToNot(HaveOccurred())
+
+			defer func() {
+				_ = DelTempFile(fsp)
+			}()
+
+			log := liblog.New(GetContext)
+			defer func() {
+				Expect(log.Close()).ToNot(HaveOccurred())
+			}()
+
+			log.SetLevel(loglvl.InfoLevel)
+			log.RegisterFuncRedact(func(key string, val interface{}) interface{} {
+				if key == "email" {
+					return "***"
+				}
+				return val
+			})
+
+			Expect(log.SetOptions(&logcfg.Options{
+				Redact: &logcfg.OptionsRedact{
+					Fields: []string{"password", "*token*"},
+					Values: []string{"Bearer [A-Za-z0-9._-]+"},
+				},
+				LogFile: []logcfg.OptionsFile{
+					{
+						Filepath:   fsp,
+						Create:     true,
+						CreatePath: true,
+					},
+				},
+			})).ToNot(HaveOccurred())
+
+			// waiting the file hook is running
+			time.Sleep(500 * time.Millisecond)
+
+			dat := map[string]interface{}{
+				"user":         "bob",
+				"Password":     "s3cr3t-pass",
+				"refreshToken": "r3fr3sh-tok",
+				"email":        "bob@example.com",
+				"header": map[string]string{
+					"Authorization": "Bearer abc.def-ghi",
+				},
+			}
+
+			log.Info("redacted info message", dat)
+
+			Eventually(func() string {
+				b, _ := os.ReadFile(fsp)
+				return string(b)
+			}, 5*time.Second, 50*time.Millisecond).Should(And(
+				ContainSubstring("redacted info message"),
+				ContainSubstring("bob"),
+				ContainSubstring(logcfg.RedactDefaultMask),
+			))
+
+			b, _ := os.ReadFile(fsp)
+			Expect(string(b)).ToNot(ContainSubstring("s3cr3t-pass"))
+			Expect(string(b)).ToNot(ContainSubstring("r3fr3sh-tok"))
+			Expect(string(b)).ToNot(ContainSubstring("abc.def-ghi"))
+			Expect(string(b)).ToNot(ContainSubstring("bob@example.com"))
+
+			// the data of the caller are not updated
+			Expect(dat["Password"]).To(Equal("s3cr3t-pass"))
+			Expect(dat["header"]).To(HaveKeyWithValue("Authorization", "Bearer abc.def-ghi"))
+		})
+	})
+})
@@ -0,0 +1,124 @@
+//go:build !windows
+// +build !windows
+
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package logger_test
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	liblog "github.com/nabbar/golib/logger"
+	logcfg "github.com/nabbar/golib/logger/config"
+	loglvl "github.com/nabbar/golib/logger/level"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Logger File Rotation", func() {
+	Context("Detecting the rotation of a log file", func() {
+		It("Must call the callback, compress the rotated file and keep only the newest", func() {
+			dir, err := os.MkdirTemp("", "logger-rotate")
+			Expect(err).ToNot(HaveOccurred())
+
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+
+			var (
+				fsp = filepath.Join(dir, "app.log")
+				mux sync.Mutex
+				old []string
+			)
+
+			log := liblog.New(GetContext)
+			defer func() {
+				Expect(log.Close()).ToNot(HaveOccurred())
+			}()
+
+			log.SetLevel(loglvl.InfoLevel)
+			log.RegisterFuncRotation(func(path, oldPath string, oldInode, newInode uint64) {
+				mux.Lock()
+				defer mux.Unlock()
+
+				Expect(path).To(Equal(fsp))
+				Expect(oldInode).ToNot(Equal(newInode))
+				old = append(old, oldPath)
+			})
+
+			Expect(log.SetOptions(&logcfg.Options{
+				Stdout: &logcfg.OptionsStd{DisableStandard: true},
+				LogFile: []logcfg.OptionsFile{
+					{
+						Filepath:       fsp,
+						Create:         true,
+						CreatePath:     true,
+						RotateCompress: "gzip",
+						RotateKeep:     1,
+					},
+				},
+			})).ToNot(HaveOccurred())
+
+			rotated := func() []string {
+				mux.Lock()
+				defer mux.Unlock()
+				return append(make([]string, 0, len(old)), old...)
+			}
+
+			// waiting the file hook is running
+			time.Sleep(500 * time.Millisecond)
+
+			log.Info("before rotation", nil)
+			Eventually(func() string {
+				b, _ := os.ReadFile(fsp)
+				return string(b)
+			}, 5*time.Second, 50*time.Millisecond).Should(ContainSubstring("before rotation"))
+
+			Expect(os.Rename(fsp, fsp+".1")).To(Succeed())
+
+			log.Info("after first rotation", nil)
+			Eventually(rotated, 5*time.Second, 50*time.Millisecond).Should(Equal([]string{fsp + ".1"}))
+			Eventually(fsp+".1.gz", 5*time.Second, 50*time.Millisecond).Should(BeAnExistingFile())
+			Expect(fsp + ".1").ToNot(BeAnExistingFile())
+
+			// the modification time of the rotated files are compared
+			time.Sleep(50 * time.Millisecond)
+			Expect(os.Rename(fsp, fsp+".2")).To(Succeed())
+
+			log.Info("after second rotation", nil)
+			Eventually(rotated, 5*time.Second, 50*time.Millisecond).Should(HaveLen(2))
+			Eventually(fsp+".2.gz", 5*time.Second, 50*time.Millisecond).Should(BeAnExistingFile())
+			Eventually(fsp+".1.gz", 5*time.Second, 50*time.Millisecond).ShouldNot(BeAnExistingFile())
+
+			b, _ := os.ReadFile(fsp)
+			Expect(string(b)).To(ContainSubstring("after second rotation"))
+		})
+	})
+})
@@ -0,0 +1,126 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package logger_test
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	liblog "github.com/nabbar/golib/logger"
+	logcfg "github.com/nabbar/golib/logger/config"
+	loglvl "github.com/nabbar/golib/logger/level"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Logger Routes", func() {
+	Context("Parsing routes", func() {
+		It("Must expand level modifiers and destinations", func() {
+			r, err := logcfg.ParseRoutes("error+ → file:/tmp/err.log, syslog; debug -> stdout\ninfo- => stderr")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(r).To(HaveLen(3))
+			Expect(r[0].Levels).To(ConsistOf(loglvl.PanicLevel, loglvl.FatalLevel, loglvl.ErrorLevel))
+			Expect(r[0].Destinations).To(Equal([]string{"file:/tmp/err.log", "syslog"}))
+			Expect(r[1].Levels).To(ConsistOf(loglvl.DebugLevel))
+			Expect(r[2].Levels).To(ConsistOf(loglvl.InfoLevel, loglvl.DebugLevel))
+		})
+
+		It("Must fail on invalid syntax", func() {
+			for _, s := range []string{
+				"error stdout",
+				"unknown -> stdout",
+				"error -> nowhere",
+				"error -> file:",
+				"error -> syslog:localhost",
+				"-> stdout",
+			} {
+				_, err := logcfg.ParseRoutes(s)
+				Expect(err).To(HaveOccurred(), s)
+			}
+
+			opt := logcfg.Options{Routes: "error -> nowhere"}
+			Expect(opt.Validate()).To(HaveOccurred())
+		})
+
+		It("Must merge levels routed to the same output", func() {
+			opt := logcfg.Options{Routes: "error+ -> file:/tmp/a.log?lock, syslog:udp://localhost:514?tag=app; warning -> file:/tmp/a.log, syslog:udp://localhost:514?tag=app; info -> stdout"}
+			cmp, err := opt.CompileRoutes()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(opt.LogFile).To(BeEmpty())
+			Expect(cmp.Routes).To(BeEmpty())
+			Expect(cmp.LogFile).To(HaveLen(1))
+			Expect(cmp.LogFile[0].LogLevel).To(ConsistOf("critical", "fatal", "error", "warning"))
+			Expect(cmp.LogFile[0].FileLock).To(BeTrue())
+			Expect(cmp.LogSyslog).To(HaveLen(1))
+			Expect(cmp.LogSyslog[0].Network).To(Equal("udp"))
+			Expect(cmp.LogSyslog[0].Host).To(Equal("localhost:514"))
+			Expect(cmp.LogSyslog[0].Tag).To(Equal("app"))
+			Expect(cmp.Stdout).ToNot(BeNil())
+			Expect(cmp.Stdout.LogLevelStdout).To(Equal([]string{"info"}))
+			Expect(cmp.Stdout.LogLevelStderr).To(BeEmpty())
+		})
+	})
+
+	Context("Logging with routes", func() {
+		It("Must write only the routed levels to the file", func() {
+			fsp, err := GetTempFile()
+			Expect(err).ToNot(HaveOccurred())
+
+			defer func() {
+				Expect(DelTempFile(fsp)).ToNot(HaveOccurred())
+			}()
+
+			log := liblog.New(GetContext)
+			log.SetLevel(loglvl.DebugLevel)
+
+			err = log.SetOptions(&logcfg.Options{
+				Routes: "error+ -> file:" + fsp,
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			// let the file hook start its run loop
+			time.Sleep(100 * time.Millisecond)
+
+			log.Entry(loglvl.InfoLevel, "routed info message").Log()
+			log.Entry(loglvl.ErrorLevel, "routed error message").Log()
+
+			time.Sleep(100 * time.Millisecond)
+			Expect(log.Close()).ToNot(HaveOccurred())
+
+			var b []byte
+
+			// buffer is flushed when the run loop exits
+			Eventually(func() bool {
+				b, err = os.ReadFile(fsp)
+				return err == nil && strings.Contains(string(b), "routed error message")
+			}, 3*time.Second, 50*time.Millisecond).Should(BeTrue())
+
+			Expect(strings.Contains(string(b), "routed info message")).To(BeFalse())
+		})
+	})
+})
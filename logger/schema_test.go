@@ -0,0 +1,96 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package logger_test
+
+import (
+	"encoding/json"
+
+	logcfg "github.com/nabbar/golib/logger/config"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+var _ = Describe("Logger Config Schema", func() {
+	Context("JSON Schema", func() {
+		It("Must describe all options", func() {
+			s := logcfg.JSONSchema()
+			Expect(s.Type).To(Equal("object"))
+			Expect(s.Properties).To(HaveKey("stdout"))
+			Expect(s.Properties).To(HaveKey("logFile"))
+			Expect(s.Properties).To(HaveKey("logSyslog"))
+			Expect(s.Properties["logFile"].Type).To(Equal("array"))
+			Expect(s.Properties["logFile"].Items.Type).To(Equal("object"))
+
+			var m map[string]interface{}
+			Expect(json.Unmarshal(logcfg.JSONSchemaIndent(""), &m)).To(Succeed())
+			Expect(m).To(HaveKey("$schema"))
+		})
+
+		It("Must validate a strict json config", func() {
+			Expect(logcfg.ValidateJSON(logcfg.DefaultConfig(""))).To(BeNil())
+			Expect(logcfg.ValidateJSON([]byte(`{"unknownField": true}`))).ToNot(BeNil())
+		})
+	})
+
+	Context("Sample config", func() {
+		It("Must generate a valid json sample", func() {
+			Expect(logcfg.ValidateJSON(logcfg.Sample(logcfg.SampleJSON))).To(BeNil())
+		})
+
+		It("Must generate a commented yaml sample", func() {
+			var (
+				p = logcfg.Sample(logcfg.SampleYAML)
+				m map[string]interface{}
+			)
+
+			Expect(string(p)).To(ContainSubstring("# "))
+			Expect(yaml.Unmarshal(p, &m)).To(Succeed())
+			Expect(m).To(HaveKey("stdout"))
+			Expect(m["logFile"]).To(HaveLen(1))
+		})
+
+		It("Must generate a commented toml sample", func() {
+			var (
+				p = logcfg.Sample(logcfg.SampleTOML)
+				m map[string]interface{}
+			)
+
+			Expect(string(p)).To(ContainSubstring("# "))
+			Expect(toml.Unmarshal(p, &m)).To(Succeed())
+			Expect(m).To(HaveKey("stdout"))
+			Expect(m["logFile"]).To(HaveLen(1))
+		})
+
+		It("Must parse the sample format", func() {
+			Expect(logcfg.ParseSampleFormat(".yml")).To(Equal(logcfg.SampleYAML))
+			Expect(logcfg.ParseSampleFormat("TOML")).To(Equal(logcfg.SampleTOML))
+			Expect(logcfg.ParseSampleFormat("")).To(Equal(logcfg.SampleJSON))
+		})
+	})
+})
@@ -37,4 +37,5 @@ const (
 	FieldMessage = "message"
 	FieldError   = "error"
 	FieldData    = "data"
+	FieldLogger  = "logger"
 )
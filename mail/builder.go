@@ -0,0 +1,447 @@
+/*
+ *  MIT License
+ *
+ *  Copyright (c) 2020 Nicolas JUHEL
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all
+ *  copies or substantial portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *  AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *  LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *  OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ *  SOFTWARE.
+ *
+ */
+
+package mail
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	libmail "net/mail"
+	"net/textproto"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	headerMessageID   = "Message-ID"
+	headerContentType = "Content-Type"
+	headerContentID   = "Content-ID"
+	headerContentDisp = "Content-Disposition"
+	headerContentEnc  = "Content-Transfer-Encoding"
+
+	base64LineLength = 76
+)
+
+// Template is the interface of the text/template and html/template templates used to render a body.
+type Template interface {
+	Execute(wr io.Writer, data interface{}) error
+}
+
+func (m *mail) AddBodyTemplate(ct ContentType, tpl Template, data interface{}) error {
+	var buf = bytes.NewBuffer(make([]byte, 0))
+
+	if tpl == nil {
+		return ErrorParamEmpty.Error(nil)
+	} else if e := tpl.Execute(buf, data); e != nil {
+		return ErrorMailTemplate.Error(e)
+	}
+
+	m.AddBody(ct, io.NopCloser(buf))
+	return nil
+}
+
+func (m *mail) SetDKIM(cfg *DKIMConfig) {
+	m.dkim = cfg
+}
+
+func (m *mail) GetDKIM() *DKIMConfig {
+	return m.dkim
+}
+
+// mimeNode is a MIME entity: its headers and the function writing its content.
+type mimeNode struct {
+	h textproto.MIMEHeader
+	f func(w io.Writer) error
+}
+
+// WriteTo streams the MIME message to the writer: a multipart/alternative of the bodies, into a
+// multipart/related with the inline files, into a multipart/mixed with the attachments.
+// The bodies and files are read once and closed, so the message can be written only once.
+func (m *mail) WriteTo(w io.Writer) (int64, error) {
+	var (
+		c = &countWriter{w: w}
+		n = m.mimeRoot()
+		h = m.mimeHeader()
+	)
+
+	for k, v := range n.h {
+		h[k] = v
+	}
+
+	if e := writeMimeHeader(c, h); e != nil {
+		return c.n, ErrorMailIOWrite.Error(e)
+	} else if e = n.f(c); e != nil {
+		return c.n, ErrorMailIOWrite.Error(e)
+	}
+
+	return c.n, nil
+}
+
+func (m *mail) mimeHeader() textproto.MIMEHeader {
+	var h = make(textproto.MIMEHeader)
+
+	for k, v := range m.GetHeaders() {
+		switch k {
+		case headerBcc:
+			continue
+		case textproto.CanonicalMIMEHeaderKey(headerMimeVersion):
+			h.Set(k, "1.0")
+		case headerFrom, headerSender, headerReplyTo, headerTo, headerCc:
+			h.Set(k, formatAddress(v))
+		case headerSubject:
+			h.Set(k, mime.QEncoding.Encode(m.charset, m.subject))
+		default:
+			h[k] = v
+		}
+	}
+
+	if m.date.IsZero() {
+		h.Set(headerDate, time.Now().Format(DateTimeLayout))
+	}
+
+	if len(h.Get(headerMessageID)) < 1 {
+		h.Set(headerMessageID, m.messageID())
+	}
+
+	return h
+}
+
+func (m *mail) messageID() string {
+	var (
+		b = make([]byte, 16)
+		d = "localhost"
+	)
+
+	_, _ = rand.Read(b)
+
+	if a, e := libmail.ParseAddress(m.address.GetFrom()); e == nil {
+		if i := strings.LastIndex(a.Address, "@"); i > 0 {
+			d = a.Address[i+1:]
+		}
+	}
+
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(b), d)
+}
+
+func (m *mail) mimeRoot() mimeNode {
+	var (
+		alt = make([]mimeNode, 0)
+		res mimeNode
+	)
+
+	for _, b := range m.body {
+		if b.contentType == ContentPlainText {
+			alt = append(alt, m.mimeBody(b))
+		}
+	}
+
+	for _, b := range m.body {
+		if b.contentType == ContentHTML {
+			alt = append(alt, m.mimeBody(b))
+		}
+	}
+
+	switch len(alt) {
+	case 0:
+		res = m.mimeBody(NewBody(ContentPlainText, bytes.NewReader(nil)))
+	case 1:
+		res = alt[0]
+	default:
+		res = mimeMultipart("alternative", alt)
+	}
+
+	if len(m.inline) > 0 {
+		lst := []mimeNode{res}
+
+		for _, f := range m.inline {
+			lst = append(lst, mimeFile(f, true))
+		}
+
+		res = mimeMultipart("related", lst)
+	}
+
+	if len(m.attach) > 0 {
+		lst := []mimeNode{res}
+
+		for _, f := range m.attach {
+			lst = append(lst, mimeFile(f, false))
+		}
+
+		res = mimeMultipart("mixed", lst)
+	}
+
+	return res
+}
+
+func (m *mail) mimeBody(b Body) mimeNode {
+	var (
+		h = make(textproto.MIMEHeader)
+		t = "text/plain"
+	)
+
+	if b.contentType == ContentHTML {
+		t = "text/html"
+	}
+
+	h.Set(headerContentType, mime.FormatMediaType(t, map[string]string{"charset": m.charset}))
+
+	switch m.encoding {
+	case EncodingBase64:
+		h.Set(headerContentEnc, "base64")
+	case EncodingQuotedPrintable:
+		h.Set(headerContentEnc, "quoted-printable")
+	default:
+		h.Set(headerContentEnc, "8bit")
+	}
+
+	return mimeNode{
+		h: h,
+		f: func(w io.Writer) error {
+			defer closeReader(b.body)
+
+			switch m.encoding {
+			case EncodingBase64:
+				return copyBase64(w, b.body)
+			case EncodingQuotedPrintable:
+				q := quotedprintable.NewWriter(w)
+
+				if _, e := io.Copy(q, b.body); e != nil {
+					_ = q.Close()
+					return e
+				}
+
+				return q.Close()
+			default:
+				_, e := io.Copy(&crlfWriter{w: w}, b.body)
+				return e
+			}
+		},
+	}
+}
+
+func mimeFile(f File, inline bool) mimeNode {
+	var (
+		h = make(textproto.MIMEHeader)
+		d = "attachment"
+		t = f.mime
+	)
+
+	if t == "" {
+		t = mimeDownload
+	}
+
+	if inline {
+		d = "inline"
+		h.Set(headerContentID, "<"+f.name+">")
+	}
+
+	h.Set(headerContentType, mime.FormatMediaType(t, map[string]string{"name": f.name}))
+	h.Set(headerContentDisp, mime.FormatMediaType(d, map[string]string{"filename": f.name}))
+	h.Set(headerContentEnc, "base64")
+
+	return mimeNode{
+		h: h,
+		f: func(w io.Writer) error {
+			defer closeReader(f.data)
+			return copyBase64(w, f.data)
+		},
+	}
+}
+
+func mimeMultipart(sub string, parts []mimeNode) mimeNode {
+	var (
+		h = make(textproto.MIMEHeader)
+		b = multipart.NewWriter(io.Discard).Boundary()
+	)
+
+	h.Set(headerContentType, mime.FormatMediaType("multipart/"+sub, map[string]string{"boundary": b}))
+
+	return mimeNode{
+		h: h,
+		f: func(w io.Writer) error {
+			var mw = multipart.NewWriter(w)
+
+			if e := mw.SetBoundary(b); e != nil {
+				return e
+			}
+
+			for _, p := range parts {
+				if pw, e := mw.CreatePart(p.h); e != nil {
+					return e
+				} else if e = p.f(pw); e != nil {
+					return e
+				}
+			}
+
+			return mw.Close()
+		},
+	}
+}
+
+func writeMimeHeader(w io.Writer, h textproto.MIMEHeader) error {
+	var key = make([]string, 0, len(h))
+
+	for k := range h {
+		key = append(key, k)
+	}
+
+	sort.Strings(key)
+
+	for _, k := range key {
+		for _, v := range h[k] {
+			if _, e := fmt.Fprintf(w, "%s: %s\r\n", k, v); e != nil {
+				return e
+			}
+		}
+	}
+
+	_, e := io.WriteString(w, "\r\n")
+	return e
+}
+
+func formatAddress(lst []string) string {
+	var res = make([]string, 0, len(lst))
+
+	for _, s := range lst {
+		if a, e := libmail.ParseAddress(s); e == nil {
+			res = append(res, a.String())
+		} else {
+			res = append(res, s)
+		}
+	}
+
+	return strings.Join(res, ", ")
+}
+
+func closeReader(r io.Reader) {
+	if c, ok := r.(io.Closer); ok {
+		_ = c.Close()
+	}
+}
+
+func copyBase64(w io.Writer, r io.Reader) error {
+	var (
+		l = &lineWriter{w: w, m: base64LineLength}
+		e = base64.NewEncoder(base64.StdEncoding, l)
+	)
+
+	if _, err := io.Copy(e, r); err != nil {
+		_ = e.Close()
+		return err
+	} else if err = e.Close(); err != nil {
+		return err
+	}
+
+	return l.end()
+}
+
+// countWriter counts the bytes written.
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, e := c.w.Write(p)
+	c.n += int64(n)
+	return n, e
+}
+
+// lineWriter breaks the stream into lines of m bytes ended by CRLF.
+type lineWriter struct {
+	w io.Writer
+	m int
+	n int
+}
+
+func (l *lineWriter) Write(p []byte) (int, error) {
+	var s = len(p)
+
+	for len(p) > 0 {
+		i := min(l.m-l.n, len(p))
+
+		if _, e := l.w.Write(p[:i]); e != nil {
+			return s - len(p), e
+		}
+
+		p = p[i:]
+		l.n += i
+
+		if l.n == l.m {
+			if _, e := io.WriteString(l.w, "\r\n"); e != nil {
+				return s - len(p), e
+			}
+
+			l.n = 0
+		}
+	}
+
+	return s, nil
+}
+
+func (l *lineWriter) end() error {
+	if l.n > 0 {
+		l.n = 0
+		_, e := io.WriteString(l.w, "\r\n")
+		return e
+	}
+
+	return nil
+}
+
+// crlfWriter converts the bare LF into CRLF.
+type crlfWriter struct {
+	w io.Writer
+	r bool // last byte written is CR
+}
+
+func (c *crlfWriter) Write(p []byte) (int, error) {
+	var (
+		b = make([]byte, 0, len(p)+8)
+	)
+
+	for _, x := range p {
+		if x == '\n' && !c.r {
+			b = append(b, '\r')
+		}
+
+		b = append(b, x)
+		c.r = x == '\r'
+	}
+
+	if _, e := c.w.Write(b); e != nil {
+		return 0, e
+	}
+
+	return len(p), nil
+}
@@ -83,6 +83,9 @@ type Config struct {
 
 	// Inline define a list of file to be attached to the mail, but inline the body of the mail and not as mail attachment
 	Inline []ConfigFile `json:"inline,omitempty" yaml:"inline,omitempty" toml:"inline,omitempty" mapstructure:"inline,omitempty" validate:"dive"`
+
+	// DKIM define the key used to sign the mail, the signature is disabled if not set
+	DKIM *DKIMConfig `json:"dkim,omitempty" yaml:"dkim,omitempty" toml:"dkim,omitempty" mapstructure:"dkim,omitempty" validate:"-"`
 }
 
 type ConfigFile struct {
@@ -105,6 +108,12 @@ func (c Config) Validate() liberr.Error {
 		}
 	}
 
+	if c.DKIM != nil {
+		if e := c.DKIM.Validate(); e != nil {
+			err.Add(e)
+		}
+	}
+
 	if err.HasParent() {
 		return err
 	}
@@ -130,6 +139,7 @@ func (c Config) NewMailer() (Mail, liberr.Error) {
 		attach: make([]File, 0),
 		inline: make([]File, 0),
 		body:   make([]Body, 0),
+		dkim:   c.DKIM,
 	}
 
 	m.headers.Set("MIME-Version", "1.0")
@@ -0,0 +1,292 @@
+/*
+ *  MIT License
+ *
+ *  Copyright (c) 2020 Nicolas JUHEL
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in all
+ *  copies or substantial portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *  AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *  LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *  OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ *  SOFTWARE.
+ *
+ */
+
+package mail
+
+import (
+	"bufio"
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	libval "github.com/go-playground/validator/v10"
+	liberr "github.com/nabbar/golib/errors"
+)
+
+const (
+	headerDKIM = "DKIM-Signature"
+)
+
+// DefaultDKIMHeaders is the list of headers signed if none is given into the DKIM config.
+func DefaultDKIMHeaders() []string {
+	return []string{"From", "Sender", "Reply-To", "Subject", "Date", "Message-ID", "To", "Cc", "MIME-Version", "Content-Type", "Content-Transfer-Encoding"}
+}
+
+type DKIMConfig struct {
+	// Domain is the signing domain (d= tag), the public key is published into '<selector>._domainkey.<domain>'.
+	Domain string `json:"domain" yaml:"domain" toml:"domain" mapstructure:"domain" validate:"required,fqdn"`
+
+	// Selector is the selector of the public key into the domain (s= tag).
+	Selector string `json:"selector" yaml:"selector" toml:"selector" mapstructure:"selector" validate:"required,printascii"`
+
+	// PrivateKey is the PEM encoded RSA or Ed25519 private key (PKCS#1 or PKCS#8).
+	PrivateKey string `json:"private-key" yaml:"private-key" toml:"private-key" mapstructure:"private-key" validate:"required"`
+
+	// Headers is the list of headers to sign. By default, DefaultDKIMHeaders.
+	Headers []string `json:"headers,omitempty" yaml:"headers,omitempty" toml:"headers,omitempty" mapstructure:"headers,omitempty"`
+}
+
+func (c DKIMConfig) Validate() liberr.Error {
+	err := ErrorMailConfigInvalid.Error(nil)
+
+	if er := libval.New().Struct(c); er != nil {
+		if e, ok := er.(*libval.InvalidValidationError); ok {
+			err.Add(e)
+		}
+
+		for _, e := range er.(libval.ValidationErrors) {
+			//nolint goerr113
+			err.Add(fmt.Errorf("config field '%s' is not validated by constraint '%s'", e.Namespace(), e.ActualTag()))
+		}
+	}
+
+	if len(c.PrivateKey) > 0 {
+		if _, _, e := c.key(); e != nil {
+			err.Add(e)
+		}
+	}
+
+	if err.HasParent() {
+		return err
+	}
+
+	return nil
+}
+
+// key returns the signer and the name of the algorithm for the a= tag.
+func (c DKIMConfig) key() (crypto.Signer, string, error) {
+	var (
+		b, _ = pem.Decode([]byte(c.PrivateKey))
+		k    interface{}
+		e    error
+	)
+
+	if b == nil {
+		//nolint goerr113
+		return nil, "", fmt.Errorf("dkim private key is not PEM encoded")
+	} else if k, e = x509.ParsePKCS8PrivateKey(b.Bytes); e != nil {
+		if k, e = x509.ParsePKCS1PrivateKey(b.Bytes); e != nil {
+			return nil, "", e
+		}
+	}
+
+	switch v := k.(type) {
+	case *rsa.PrivateKey:
+		return v, "rsa-sha256", nil
+	case ed25519.PrivateKey:
+		return v, "ed25519-sha256", nil
+	}
+
+	//nolint goerr113
+	return nil, "", fmt.Errorf("dkim private key type %T is not supported", k)
+}
+
+// sign reads the message and returns the DKIM-Signature header field to prepend to the message.
+// The message is canonicalized with the relaxed algorithm for both header and body.
+func (c DKIMConfig) sign(msg io.Reader) ([]byte, error) {
+	var (
+		r = bufio.NewReader(msg)
+		h = make([]string, 0)
+	)
+
+	key, alg, err := c.key()
+
+	if err != nil {
+		return nil, err
+	}
+
+	// header fields, with their continuation lines
+	for {
+		l, e := r.ReadString('\n')
+
+		if e != nil && e != io.EOF {
+			return nil, e
+		} else if strings.TrimRight(l, "\r\n") == "" {
+			break
+		} else if (l[0] == ' ' || l[0] == '\t') && len(h) > 0 {
+			h[len(h)-1] += l
+		} else {
+			h = append(h, l)
+		}
+
+		if e == io.EOF {
+			break
+		}
+	}
+
+	// body hash
+	var (
+		bdy = sha256.New()
+		emp int
+	)
+
+	for {
+		l, e := r.ReadBytes('\n')
+
+		if len(l) > 0 {
+			if l = relaxedBodyLine(l); len(l) == 0 {
+				emp++
+			} else {
+				for ; emp > 0; emp-- {
+					_, _ = bdy.Write([]byte("\r\n"))
+				}
+
+				_, _ = bdy.Write(l)
+				_, _ = bdy.Write([]byte("\r\n"))
+			}
+		}
+
+		if e == io.EOF {
+			break
+		} else if e != nil {
+			return nil, e
+		}
+	}
+
+	// selected headers, the last instance first
+	var (
+		hsh = sha256.New()
+		use = make([]bool, len(h))
+		nam = make([]string, 0)
+		lst = c.Headers
+	)
+
+	if len(lst) < 1 {
+		lst = DefaultDKIMHeaders()
+	}
+
+	for _, n := range lst {
+		for i := len(h) - 1; i >= 0; i-- {
+			if k, v := relaxedHeader(h[i]); !use[i] && k == strings.ToLower(n) {
+				use[i] = true
+				nam = append(nam, k)
+				_, _ = hsh.Write([]byte(k + ":" + v + "\r\n"))
+				break
+			}
+		}
+	}
+
+	val := strings.Join([]string{
+		"v=1",
+		"a=" + alg,
+		"c=relaxed/relaxed",
+		"d=" + c.Domain,
+		"s=" + c.Selector,
+		"t=" + strconv.FormatInt(time.Now().Unix(), 10),
+		"h=" + strings.Join(nam, ":"),
+		"bh=" + base64.StdEncoding.EncodeToString(bdy.Sum(nil)),
+		"b=",
+	}, ";\r\n\t")
+
+	k, v := relaxedHeader(headerDKIM + ": " + val)
+	_, _ = hsh.Write([]byte(k + ":" + v))
+
+	var (
+		sig []byte
+		dig = hsh.Sum(nil)
+	)
+
+	if _, ok := key.(ed25519.PrivateKey); ok {
+		sig, err = key.Sign(rand.Reader, dig, crypto.Hash(0))
+	} else {
+		sig, err = key.Sign(rand.Reader, dig, crypto.SHA256)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		b64 = base64.StdEncoding.EncodeToString(sig)
+		buf = bytes.NewBufferString(headerDKIM + ": " + val)
+	)
+
+	for len(b64) > 0 {
+		n := min(len(b64), 72)
+		buf.WriteString(b64[:n])
+		b64 = b64[n:]
+
+		if len(b64) > 0 {
+			buf.WriteString("\r\n\t")
+		}
+	}
+
+	buf.WriteString("\r\n")
+
+	return buf.Bytes(), nil
+}
+
+// relaxedHeader returns the lower name and the unfolded value with compressed white spaces of the header field.
+func relaxedHeader(field string) (string, string) {
+	k, v, _ := strings.Cut(field, ":")
+	v = strings.NewReplacer("\r\n", "", "\n", "").Replace(v)
+
+	return strings.ToLower(strings.TrimSpace(k)), strings.Join(strings.Fields(v), " ")
+}
+
+// relaxedBodyLine returns the line without its end of line, with compressed white spaces and no trailing white space.
+func relaxedBodyLine(l []byte) []byte {
+	var (
+		r = make([]byte, 0, len(l))
+		w bool
+	)
+
+	for _, c := range bytes.TrimRight(l, "\r\n") {
+		if c == ' ' || c == '\t' {
+			w = true
+			continue
+		}
+
+		if w {
+			r = append(r, ' ')
+			w = false
+		}
+
+		r = append(r, c)
+	}
+
+	return r
+}
@@ -43,6 +43,8 @@ const (
 	ErrorMailSmtpClient
 	ErrorMailSenderInit
 	ErrorFileOpenCreate
+	ErrorMailTemplate
+	ErrorMailDKIM
 )
 
 func init() {
@@ -70,6 +72,10 @@ func getMessage(code liberr.CodeError) (message string) {
 		return "error occurs while to preparing SMTP Email sender"
 	case ErrorFileOpenCreate:
 		return "cannot open/create file"
+	case ErrorMailTemplate:
+		return "error occurs while executing the body template"
+	case ErrorMailDKIM:
+		return "error occurs while signing the mail with DKIM"
 	}
 
 	return liberr.NullMessage
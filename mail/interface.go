@@ -64,9 +64,24 @@ type Mail interface {
 	AttachFile(filepath string, data io.ReadCloser, inline bool)
 	GetAttachment(inline bool) []File
 
+	// AddBodyTemplate renders the template with the data and adds the result as the body of the given content type.
+	AddBodyTemplate(ct ContentType, tpl Template, data interface{}) error
+
+	// SetDKIM defines the DKIM config used to sign the mail by the senders, nil disables the signature.
+	SetDKIM(cfg *DKIMConfig)
+	GetDKIM() *DKIMConfig
+
+	// WriterTo streams the MIME message without buffering the bodies and the files.
+	io.WriterTo
+
 	Email() Email
 
 	Sender() (Sender, error)
+
+	// SenderStream returns a sender streaming the MIME message to the SMTP server. The bodies and files
+	// are read during the sending, so the sender can be used only once, unless the DKIM signature is enabled:
+	// the message is then buffered into a temporary file to compute the signature.
+	SenderStream() (Sender, error)
 }
 
 func New() Mail {
@@ -113,6 +128,7 @@ func (m *mail) Clone() Mail {
 		},
 		encoding: m.encoding,
 		priority: m.priority,
+		dkim:     m.dkim,
 	}
 }
 
@@ -53,6 +53,7 @@ type mail struct {
 	address  *email
 	encoding Encoding
 	priority Priority
+	dkim     *DKIMConfig
 }
 
 func (m *mail) Email() Email {
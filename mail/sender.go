@@ -48,6 +48,8 @@ type Sender interface {
 
 type sender struct {
 	data libfpg.Progress
+	head []byte      // dkim signature header field written before data
+	strm io.WriterTo // streamed message, sent only once
 	from string
 	rcpt []string
 }
@@ -216,14 +218,61 @@ func (m *mail) Sender() (snd Sender, err error) {
 		return nil, ErrorMailSenderInit.Error(e.Error)
 	} else if _, er = tmp.Seek(0, io.SeekStart); er != nil {
 		return nil, ErrorMailIOWrite.Error(er)
+	} else if s.data = tmp; m.dkim == nil {
+		snd = s
+	} else if s.head, er = m.dkim.sign(tmp); er != nil {
+		return nil, ErrorMailDKIM.Error(er)
+	} else if _, er = tmp.Seek(0, io.SeekStart); er != nil {
+		return nil, ErrorMailIOWrite.Error(er)
 	} else {
-		s.data = tmp
 		snd = s
 	}
 
 	return
 }
 
+func (m *mail) SenderStream() (snd Sender, err error) {
+	s := &sender{
+		from: m.Email().GetFrom(),
+		rcpt: make([]string, 0),
+	}
+
+	s.rcpt = append(s.rcpt, m.Email().GetRecipients(RecipientTo)...)
+	s.rcpt = append(s.rcpt, m.Email().GetRecipients(RecipientCC)...)
+	s.rcpt = append(s.rcpt, m.Email().GetRecipients(RecipientBCC)...)
+
+	if m.dkim == nil {
+		s.strm = m
+		return s, nil
+	}
+
+	defer func() {
+		if err != nil || snd == nil {
+			_ = s.Close()
+		}
+	}()
+
+	tmp, er := libfpg.Temp("")
+
+	if er != nil {
+		return nil, ErrorFileOpenCreate.Error(er)
+	}
+
+	s.data = tmp
+
+	if _, er = m.WriteTo(tmp); er != nil {
+		return nil, ErrorMailIOWrite.Error(er)
+	} else if _, er = tmp.Seek(0, io.SeekStart); er != nil {
+		return nil, ErrorMailIOWrite.Error(er)
+	} else if s.head, er = m.dkim.sign(tmp); er != nil {
+		return nil, ErrorMailDKIM.Error(er)
+	} else if _, er = tmp.Seek(0, io.SeekStart); er != nil {
+		return nil, ErrorMailIOWrite.Error(er)
+	}
+
+	return s, nil
+}
+
 func (s *sender) SendClose(ctx context.Context, cli libsmtp.SMTP) error {
 	defer func() {
 		_ = s.Close()
@@ -249,12 +298,28 @@ func (s *sender) Send(ctx context.Context, cli libsmtp.SMTP) error {
 		return ErrorParamEmpty.Error(fmt.Errorf("parameters 'receipient' is not valid"))
 	}
 
-	e := cli.Send(ctx, s.from, s.rcpt, s.data)
+	var dat io.WriterTo
+
+	if s.strm != nil {
+		dat = s.strm
+		s.strm = nil
+	} else if s.data == nil {
+		//nolint #goerr113
+		return ErrorParamEmpty.Error(fmt.Errorf("streamed mail is already sent"))
+	} else if len(s.head) > 0 {
+		dat = &signedData{h: s.head, d: s.data}
+	} else {
+		dat = s.data
+	}
+
+	e := cli.Send(ctx, s.from, s.rcpt, dat)
 	if e != nil {
 		return e
 	}
 
-	if _, err := s.data.Seek(0, io.SeekStart); err != nil {
+	if s.data == nil {
+		return nil
+	} else if _, err := s.data.Seek(0, io.SeekStart); err != nil {
 		return ErrorMailIOWrite.Error(err)
 	}
 
@@ -262,5 +327,26 @@ func (s *sender) Send(ctx context.Context, cli libsmtp.SMTP) error {
 }
 
 func (s *sender) Close() error {
+	if s.data == nil {
+		return nil
+	}
+
 	return s.data.Close()
 }
+
+// signedData writes the DKIM signature header field before the message.
+type signedData struct {
+	h []byte
+	d io.WriterTo
+}
+
+func (s *signedData) WriteTo(w io.Writer) (int64, error) {
+	n, e := w.Write(s.h)
+
+	if e != nil {
+		return int64(n), e
+	}
+
+	m, e := s.d.WriteTo(w)
+	return int64(n) + m, e
+}
@@ -0,0 +1,103 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package check
+
+import (
+	"fmt"
+
+	libval "github.com/go-playground/validator/v10"
+	libtls "github.com/nabbar/golib/certificates"
+	libdur "github.com/nabbar/golib/duration"
+	liberr "github.com/nabbar/golib/errors"
+)
+
+// Kind is the type of check built from a Config.
+type Kind string
+
+const (
+	// KindTCP check that a TCP connection can be established to the address (host:port).
+	KindTCP Kind = "tcp"
+	// KindTLS check the TLS handshake with the address (host:port) and the expiry of the server certificates.
+	KindTLS Kind = "tls"
+	// KindUDP send the payload to the address (host:port) and wait for a response matching the expect regex.
+	KindUDP Kind = "udp"
+	// KindUnix check that a connection can be established to the unix socket file of the address.
+	KindUnix Kind = "unix"
+	// KindHTTP send a GET request to the address (URL) and check the response status and body.
+	KindHTTP Kind = "http"
+)
+
+// Config is the declarative configuration of a check.
+// The check has no timeout of its own and is bounded by the context given by the monitor (check-timeout).
+type Config struct {
+	// Kind is the type of the check: tcp, tls, udp, unix or http.
+	Kind Kind `json:"kind" yaml:"kind" toml:"kind" mapstructure:"kind" validate:"required,oneof=tcp tls udp unix http"`
+
+	// Address is the host:port for tcp, tls and udp kind, the socket file path for unix kind and the URL for http kind.
+	Address string `json:"address" yaml:"address" toml:"address" mapstructure:"address" validate:"required"`
+
+	// TLS is the tls configuration used by the tls kind. By default, the system root CA are used.
+	TLS *libtls.Config `json:"tls,omitempty" yaml:"tls,omitempty" toml:"tls,omitempty" mapstructure:"tls,omitempty"`
+
+	// ServerName is the name checked in the server certificate by the tls kind. Default is the host of the address.
+	ServerName string `json:"server-name,omitempty" yaml:"server-name,omitempty" toml:"server-name,omitempty" mapstructure:"server-name,omitempty"`
+
+	// ExpiryWarn define the min remaining validity of the server certificates for the tls kind. Zero disable this check.
+	ExpiryWarn libdur.Duration `json:"expiry-warn,omitempty" yaml:"expiry-warn,omitempty" toml:"expiry-warn,omitempty" mapstructure:"expiry-warn,omitempty"`
+
+	// Payload is the request sent by the udp kind.
+	Payload string `json:"payload,omitempty" yaml:"payload,omitempty" toml:"payload,omitempty" mapstructure:"payload,omitempty"`
+
+	// Expect is a regex matched against the response of the udp kind or the response body of the http kind.
+	Expect string `json:"expect,omitempty" yaml:"expect,omitempty" toml:"expect,omitempty" mapstructure:"expect,omitempty"`
+
+	// Status is the list of the http status accepted by the http kind. Default is any 2xx status.
+	Status []int `json:"status,omitempty" yaml:"status,omitempty" toml:"status,omitempty" mapstructure:"status,omitempty" validate:"omitempty,dive,gte=100,lte=599"`
+}
+
+func (o Config) Validate() liberr.Error {
+	var e = ErrorValidatorError.Error(nil)
+
+	if err := libval.New().Struct(o); err != nil {
+		if er, ok := err.(*libval.InvalidValidationError); ok {
+			e.Add(er)
+		}
+
+		if ers, ok := err.(libval.ValidationErrors); ok {
+			for _, er := range ers {
+				//nolint #goerr113
+				e.Add(fmt.Errorf("config field '%s' is not validated by constraint '%s'", er.Namespace(), er.ActualTag()))
+			}
+		}
+	}
+
+	if !e.HasParent() {
+		e = nil
+	}
+
+	return e
+}
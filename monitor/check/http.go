@@ -0,0 +1,100 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package check
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"slices"
+
+	libhtc "github.com/nabbar/golib/httpcli"
+)
+
+// maxBody is the max size of the response body matched with the expected regex.
+const maxBody = 1024 * 1024
+
+type htp struct {
+	u string
+	c libhtc.HttpClient
+	s []int
+	r *regexp.Regexp
+}
+
+func (o *htp) client() libhtc.HttpClient {
+	if o.c != nil {
+		return o.c
+	}
+
+	return libhtc.GetClient()
+}
+
+func (o *htp) isStatus(code int) bool {
+	if len(o.s) < 1 {
+		return code >= http.StatusOK && code < http.StatusMultipleChoices
+	}
+
+	return slices.Contains(o.s, code)
+}
+
+func (o *htp) check(ctx context.Context) error {
+	req, e := http.NewRequestWithContext(ctx, http.MethodGet, o.u, nil)
+
+	if e != nil {
+		return ErrorWrite.Error(e)
+	}
+
+	rsp, e := o.client().Do(req)
+
+	if e != nil {
+		return ErrorConnect.Error(e)
+	}
+
+	defer func() {
+		_ = rsp.Body.Close()
+	}()
+
+	if !o.isStatus(rsp.StatusCode) {
+		//nolint #goerr113
+		return ErrorResponse.Error(fmt.Errorf("response status '%s'", rsp.Status))
+	} else if o.r == nil {
+		return nil
+	}
+
+	b, e := io.ReadAll(io.LimitReader(rsp.Body, maxBody))
+
+	if e != nil {
+		return ErrorRead.Error(e)
+	} else if !o.r.Match(b) {
+		//nolint #goerr113
+		return ErrorResponse.Error(fmt.Errorf("response body does not match '%s'", o.r.String()))
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package check
+
+import (
+	"regexp"
+	"time"
+
+	libtls "github.com/nabbar/golib/certificates"
+	liberr "github.com/nabbar/golib/errors"
+	libhtc "github.com/nabbar/golib/httpcli"
+	montps "github.com/nabbar/golib/monitor/types"
+	libptc "github.com/nabbar/golib/network/protocol"
+)
+
+// New return the healthcheck function described by the given config.
+func New(cfg Config) (montps.HealthCheck, liberr.Error) {
+	if e := cfg.Validate(); e != nil {
+		return nil, e
+	}
+
+	switch cfg.Kind {
+	case KindTLS:
+		var t libtls.TLSConfig
+
+		if cfg.TLS != nil {
+			t = cfg.TLS.New()
+		}
+
+		return NewTLS(cfg.Address, t, cfg.ServerName, cfg.ExpiryWarn.Time())
+	case KindUDP:
+		return NewUDP(cfg.Address, []byte(cfg.Payload), cfg.Expect)
+	case KindUnix:
+		return NewUnix(cfg.Address)
+	case KindHTTP:
+		return NewHTTP(cfg.Address, nil, cfg.Status, cfg.Expect)
+	default:
+		return NewTCP(cfg.Address)
+	}
+}
+
+// NewTCP return a healthcheck connecting to the given TCP address (host:port).
+func NewTCP(address string) (montps.HealthCheck, liberr.Error) {
+	if len(address) < 1 {
+		return nil, ErrorParamEmpty.Error(nil)
+	}
+
+	return (&sck{p: libptc.NetworkTCP, a: address}).check, nil
+}
+
+// NewUnix return a healthcheck connecting to the given unix socket file.
+func NewUnix(path string) (montps.HealthCheck, liberr.Error) {
+	if len(path) < 1 {
+		return nil, ErrorParamEmpty.Error(nil)
+	}
+
+	return (&sck{p: libptc.NetworkUnix, a: path}).check, nil
+}
+
+// NewUDP return a healthcheck sending the payload to the given UDP address (host:port) and waiting for a response.
+// If expect is not empty, the response must match this regex.
+func NewUDP(address string, payload []byte, expect string) (montps.HealthCheck, liberr.Error) {
+	if len(address) < 1 || len(payload) < 1 {
+		return nil, ErrorParamEmpty.Error(nil)
+	}
+
+	r, e := compile(expect)
+
+	if e != nil {
+		return nil, e
+	}
+
+	return (&sck{p: libptc.NetworkUDP, a: address, w: payload, r: r}).echo, nil
+}
+
+// NewTLS return a healthcheck running a TLS handshake with the given address (host:port).
+// If the tls config is nil, the default one of the certificates package is used.
+// If expiry is not zero, the check fails when a server certificate expires before this delay.
+func NewTLS(address string, cfg libtls.TLSConfig, serverName string, expiry time.Duration) (montps.HealthCheck, liberr.Error) {
+	if len(address) < 1 {
+		return nil, ErrorParamEmpty.Error(nil)
+	}
+
+	return (&hsk{a: address, c: cfg, n: serverName, x: expiry}).check, nil
+}
+
+// NewHTTP return a healthcheck sending a GET request to the given URL.
+// If the client is nil, the default http client of the httpcli package is used.
+// The response status must be one of the given status (any 2xx if empty),
+// and the response body must match the expect regex if not empty.
+func NewHTTP(url string, cli libhtc.HttpClient, status []int, expect string) (montps.HealthCheck, liberr.Error) {
+	if len(url) < 1 {
+		return nil, ErrorParamEmpty.Error(nil)
+	}
+
+	r, e := compile(expect)
+
+	if e != nil {
+		return nil, e
+	}
+
+	return (&htp{u: url, c: cli, s: status, r: r}).check, nil
+}
+
+func compile(expect string) (*regexp.Regexp, liberr.Error) {
+	if len(expect) < 1 {
+		return nil, nil
+	}
+
+	r, e := regexp.Compile(expect)
+
+	if e != nil {
+		return nil, ErrorRegex.Error(e)
+	}
+
+	return r, nil
+}
@@ -0,0 +1,99 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package check
+
+import (
+	"context"
+	"regexp"
+
+	libptc "github.com/nabbar/golib/network/protocol"
+	sckcli "github.com/nabbar/golib/socket/client"
+)
+
+// maxResponse is the max size read from an udp response.
+const maxResponse = 64 * 1024
+
+type sck struct {
+	p libptc.NetworkProtocol
+	a string
+	w []byte         // request payload
+	r *regexp.Regexp // expected response
+}
+
+func (o *sck) check(ctx context.Context) error {
+	c, e := sckcli.New(o.p, o.a)
+
+	if e != nil {
+		return ErrorConnect.Error(e)
+	} else if e = c.Connect(ctx); e != nil {
+		return ErrorConnect.Error(e)
+	}
+
+	_ = c.Close()
+	return nil
+}
+
+func (o *sck) echo(ctx context.Context) error {
+	c, e := sckcli.New(o.p, o.a)
+
+	if e != nil {
+		return ErrorConnect.Error(e)
+	} else if e = c.Connect(ctx); e != nil {
+		return ErrorConnect.Error(e)
+	}
+
+	// closing the connection unblock the read at the end of the context
+	stp := context.AfterFunc(ctx, func() {
+		_ = c.Close()
+	})
+
+	defer func() {
+		if stp() {
+			_ = c.Close()
+		}
+	}()
+
+	if _, e = c.Write(o.w); e != nil {
+		return ErrorWrite.Error(e)
+	}
+
+	var (
+		b = make([]byte, maxResponse)
+		n int
+	)
+
+	if n, e = c.Read(b); e != nil {
+		if ctx.Err() != nil {
+			return ErrorRead.Error(ctx.Err())
+		}
+		return ErrorRead.Error(e)
+	} else if o.r != nil && !o.r.Match(b[:n]) {
+		return ErrorResponse.Error(nil)
+	}
+
+	return nil
+}
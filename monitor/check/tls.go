@@ -0,0 +1,106 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package check
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"time"
+
+	libtls "github.com/nabbar/golib/certificates"
+	libptc "github.com/nabbar/golib/network/protocol"
+)
+
+type hsk struct {
+	a string
+	c libtls.TLSConfig
+	n string
+	x time.Duration
+}
+
+func (o *hsk) config() *tls.Config {
+	var (
+		srv = o.n
+		cfg *tls.Config
+	)
+
+	if len(srv) < 1 {
+		srv, _, _ = net.SplitHostPort(o.a)
+	}
+
+	if o.c != nil {
+		cfg = o.c.TlsConfig(srv)
+	} else {
+		cfg = libtls.GetTLSConfig(srv)
+	}
+
+	if cfg == nil {
+		// #nosec
+		cfg = &tls.Config{}
+	}
+
+	if len(cfg.ServerName) < 1 {
+		cfg.ServerName = srv
+	}
+
+	return cfg
+}
+
+func (o *hsk) check(ctx context.Context) error {
+	d := &tls.Dialer{
+		Config: o.config(),
+	}
+
+	c, e := d.DialContext(ctx, libptc.NetworkTCP.Code(), o.a)
+
+	if e != nil {
+		return ErrorHandshake.Error(e)
+	}
+
+	defer func() {
+		_ = c.Close()
+	}()
+
+	t, k := c.(*tls.Conn)
+
+	if !k || o.x <= 0 {
+		return nil
+	}
+
+	lim := time.Now().Add(o.x)
+
+	for _, crt := range t.ConnectionState().PeerCertificates {
+		if crt.NotAfter.Before(lim) {
+			//nolint #goerr113
+			return ErrorCertExpiry.Error(fmt.Errorf("certificate '%s' expires at %s", crt.Subject.CommonName, crt.NotAfter.Format(time.RFC3339)))
+		}
+	}
+
+	return nil
+}
@@ -39,6 +39,9 @@ const (
 	ErrorLoggerError
 	ErrorTimeout
 	ErrorInvalid
+	ErrorCheckPanic
+	ErrorSelfUnhealthy
+	ErrorSLOBreached
 )
 
 func init() {
@@ -62,6 +65,12 @@ func getMessage(code liberr.CodeError) (message string) {
 		return "timeout error"
 	case ErrorInvalid:
 		return "invalid instance"
+	case ErrorCheckPanic:
+		return "healthcheck panic"
+	case ErrorSelfUnhealthy:
+		return "monitor engine unhealthy"
+	case ErrorSLOBreached:
+		return "service level objective breached"
 	}
 
 	return liberr.NullMessage
@@ -0,0 +1,265 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package group
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	monsts "github.com/nabbar/golib/monitor/status"
+)
+
+func (o *grp) Check() Result {
+	o.m.RLock()
+	var (
+		name = o.n
+		rule = o.r
+		quor = o.q
+		thok = o.o
+		thwn = o.w
+		keys = make([]string, len(o.k))
+		cmps = make(map[string]Component, len(o.c))
+	)
+
+	copy(keys, o.k)
+
+	for k, v := range o.c {
+		cmps[k] = v
+	}
+	o.m.RUnlock()
+
+	// retrieve the own status of each component outside the lock as sources can be slow or nested groups.
+	var res = Result{
+		Name:       name,
+		Rule:       rule,
+		Status:     monsts.KO,
+		Time:       time.Now(),
+		Components: make(map[string]ResultComponent, len(keys)),
+	}
+
+	for _, k := range keys {
+		c := cmps[k]
+		res.Components[k] = ResultComponent{
+			Status:    c.Source.Status(),
+			Effective: monsts.KO,
+			Message:   c.Source.Message(),
+			Weight:    c.Weight,
+			Critical:  c.Critical,
+			DependsOn: c.DependsOn,
+		}
+	}
+
+	var done = make(map[string]bool, len(keys))
+
+	for _, k := range keys {
+		o.effective(k, res.Components, make(map[string]bool), done)
+	}
+
+	res.Status, res.Score, res.Message = o.rollup(rule, quor, thok, thwn, keys, res.Components)
+
+	o.m.Lock()
+	var (
+		evt = make([]Event, 0)
+		prv = o.s.Status
+	)
+
+	for _, k := range keys {
+		c := res.Components[k]
+
+		if l, ok := o.l[k]; !ok || l != c.Effective {
+			if !ok {
+				l = monsts.KO
+			}
+
+			if !ok && c.Effective == monsts.KO {
+				o.l[k] = c.Effective
+				continue
+			}
+
+			evt = append(evt, newEvent(name, k, l, c.Effective, c.Message))
+		}
+
+		o.l[k] = c.Effective
+	}
+
+	if prv != res.Status {
+		evt = append(evt, newEvent(name, "", prv, res.Status, res.Message))
+	}
+
+	o.s = res
+	o.m.Unlock()
+
+	o.sendEvents(evt)
+
+	return res
+}
+
+// effective compute the effective status of a component, applying the status of its dependencies.
+func (o *grp) effective(name string, cmp map[string]ResultComponent, path, done map[string]bool) monsts.Status {
+	c, ok := cmp[name]
+
+	if !ok {
+		return monsts.KO
+	} else if done[name] {
+		return c.Effective
+	} else if path[name] {
+		// cycle are rejected on registration, this is only a safety guard.
+		return c.Status
+	}
+
+	path[name] = true
+	defer delete(path, name)
+
+	var (
+		sts = c.Status
+		msg = make([]string, 0)
+	)
+
+	for _, d := range c.DependsOn {
+		if _, k := cmp[d]; !k {
+			sts = monsts.KO
+			msg = append(msg, fmt.Sprintf("dependency '%s' not found", d))
+			continue
+		}
+
+		if s := o.effective(d, cmp, path, done); s < sts {
+			sts = s
+			msg = append(msg, fmt.Sprintf("dependency '%s' is %s", d, s.String()))
+		} else if s != monsts.OK {
+			msg = append(msg, fmt.Sprintf("dependency '%s' is %s", d, s.String()))
+		}
+	}
+
+	c.Effective = sts
+
+	if len(msg) > 0 {
+		if len(c.Message) > 0 {
+			msg = append([]string{c.Message}, msg...)
+		}
+		c.Message = strings.Join(msg, ", ")
+	}
+
+	cmp[name] = c
+	done[name] = true
+
+	return sts
+}
+
+func (o *grp) rollup(rule Rule, quorum int, thOK, thWarn float64, keys []string, cmp map[string]ResultComponent) (monsts.Status, float64, string) {
+	if len(keys) < 1 {
+		return monsts.KO, 0, "no component registered"
+	}
+
+	var (
+		nok int
+		nwn int
+		wgt float64
+		scr float64
+		wst = monsts.OK
+		crt = make([]string, 0)
+		bad = make([]string, 0)
+	)
+
+	for _, k := range keys {
+		c := cmp[k]
+
+		switch c.Effective {
+		case monsts.OK:
+			nok++
+			scr += c.Weight
+		case monsts.Warn:
+			nwn++
+			scr += c.Weight / 2
+			bad = append(bad, k)
+		default:
+			bad = append(bad, k)
+			if c.Critical {
+				crt = append(crt, k)
+			}
+		}
+
+		wgt += c.Weight
+
+		if c.Effective < wst {
+			wst = c.Effective
+		}
+	}
+
+	if wgt > 0 {
+		scr = scr / wgt
+	}
+
+	if len(crt) > 0 {
+		return monsts.KO, scr, fmt.Sprintf("critical component KO: %s", strings.Join(crt, ", "))
+	}
+
+	var (
+		sts monsts.Status
+		msg string
+	)
+
+	switch rule {
+	case RuleQuorum:
+		if quorum < 1 {
+			quorum = len(keys)/2 + 1
+		}
+
+		if nok >= quorum {
+			sts = monsts.OK
+		} else if nok+nwn >= quorum {
+			sts = monsts.Warn
+		} else {
+			sts = monsts.KO
+		}
+
+		msg = fmt.Sprintf("%d OK, %d Warn on %d components (quorum %d)", nok, nwn, len(keys), quorum)
+
+	case RuleWeighted:
+		if scr >= thOK {
+			sts = monsts.OK
+		} else if scr >= thWarn {
+			sts = monsts.Warn
+		} else {
+			sts = monsts.KO
+		}
+
+		msg = fmt.Sprintf("score %.2f (ok >= %.2f, warn >= %.2f)", scr, thOK, thWarn)
+
+	default:
+		sts = wst
+	}
+
+	if len(bad) > 0 {
+		if len(msg) > 0 {
+			msg += ", "
+		}
+		msg += fmt.Sprintf("degraded: %s", strings.Join(bad, ", "))
+	}
+
+	return sts, scr, msg
+}
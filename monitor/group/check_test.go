@@ -0,0 +1,298 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package group_test
+
+import (
+	liberr "github.com/nabbar/golib/errors"
+	mongrp "github.com/nabbar/golib/monitor/group"
+	monsts "github.com/nabbar/golib/monitor/status"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// src is a static source of status.
+type src struct {
+	n string
+	s monsts.Status
+}
+
+func (s *src) Name() string {
+	return s.n
+}
+
+func (s *src) Status() monsts.Status {
+	return s.s
+}
+
+func (s *src) Message() string {
+	return ""
+}
+
+// cmp describe a component of a table entry.
+type cmp struct {
+	n string        // name
+	s monsts.Status // own status
+	w float64       // weight
+	c bool          // critical
+	d []string      // dependencies
+}
+
+// rule describe the rollup settings of a table entry, zero values keeping the defaults.
+type rule struct {
+	r  mongrp.Rule
+	q  int
+	ok float64
+	wn float64
+}
+
+func newGroup(r rule, lst []cmp) mongrp.Group {
+	var g = mongrp.New("group", r.r)
+
+	if r.q > 0 {
+		g.SetQuorum(r.q)
+	}
+
+	if r.ok > 0 {
+		g.SetThreshold(r.ok, r.wn)
+	}
+
+	for _, c := range lst {
+		Expect(g.Add(mongrp.Component{
+			Source:    &src{n: c.n, s: c.s},
+			Weight:    c.w,
+			Critical:  c.c,
+			DependsOn: c.d,
+		})).ToNot(HaveOccurred())
+	}
+
+	return g
+}
+
+var _ = Describe("monitor/group check", func() {
+	var (
+		worst    = rule{r: mongrp.RuleWorstOf}
+		quorum   = rule{r: mongrp.RuleQuorum}
+		weighted = rule{r: mongrp.RuleWeighted, ok: 0.7, wn: 0.5}
+	)
+
+	DescribeTable("group status by rule",
+		func(r rule, lst []cmp, exp monsts.Status, score float64) {
+			res := newGroup(r, lst).Check()
+			Expect(res.Status).To(Equal(exp))
+			Expect(res.Score).To(BeNumerically("~", score, 0.001))
+		},
+		Entry("worst of: all OK", worst, []cmp{
+			{n: "a", s: monsts.OK}, {n: "b", s: monsts.OK}, {n: "c", s: monsts.OK},
+		}, monsts.OK, 1.0),
+		Entry("worst of: one Warn", worst, []cmp{
+			{n: "a", s: monsts.OK}, {n: "b", s: monsts.Warn}, {n: "c", s: monsts.OK},
+		}, monsts.Warn, 2.5/3),
+		Entry("worst of: one KO", worst, []cmp{
+			{n: "a", s: monsts.OK}, {n: "b", s: monsts.Warn}, {n: "c", s: monsts.KO},
+		}, monsts.KO, 1.5/3),
+
+		Entry("quorum: majority OK", quorum, []cmp{
+			{n: "a", s: monsts.OK}, {n: "b", s: monsts.OK}, {n: "c", s: monsts.KO},
+		}, monsts.OK, 2.0/3),
+		Entry("quorum: majority reached with Warn", quorum, []cmp{
+			{n: "a", s: monsts.OK}, {n: "b", s: monsts.Warn}, {n: "c", s: monsts.KO},
+		}, monsts.Warn, 1.5/3),
+		Entry("quorum: majority KO", quorum, []cmp{
+			{n: "a", s: monsts.OK}, {n: "b", s: monsts.KO}, {n: "c", s: monsts.KO},
+		}, monsts.KO, 1.0/3),
+		Entry("quorum: explicit quorum not reached by OK", rule{r: mongrp.RuleQuorum, q: 3}, []cmp{
+			{n: "a", s: monsts.OK}, {n: "b", s: monsts.OK}, {n: "c", s: monsts.Warn},
+		}, monsts.Warn, 2.5/3),
+		Entry("quorum: explicit quorum of one", rule{r: mongrp.RuleQuorum, q: 1}, []cmp{
+			{n: "a", s: monsts.OK}, {n: "b", s: monsts.KO}, {n: "c", s: monsts.KO},
+		}, monsts.OK, 1.0/3),
+		Entry("quorum: critical KO", quorum, []cmp{
+			{n: "a", s: monsts.OK}, {n: "b", s: monsts.OK}, {n: "c", s: monsts.KO, c: true},
+		}, monsts.KO, 2.0/3),
+
+		Entry("weighted: score above ok", weighted, []cmp{
+			{n: "a", s: monsts.OK, w: 3}, {n: "b", s: monsts.KO, w: 1},
+		}, monsts.OK, 0.75),
+		Entry("weighted: score above warn", weighted, []cmp{
+			{n: "a", s: monsts.OK, w: 1}, {n: "b", s: monsts.KO, w: 1},
+		}, monsts.Warn, 0.5),
+		Entry("weighted: score below warn", weighted, []cmp{
+			{n: "a", s: monsts.OK, w: 1}, {n: "b", s: monsts.KO, w: 3},
+		}, monsts.KO, 0.25),
+		Entry("weighted: Warn counting half", weighted, []cmp{
+			{n: "a", s: monsts.Warn, w: 2}, {n: "b", s: monsts.OK, w: 2},
+		}, monsts.OK, 0.75),
+		Entry("weighted: zero weight replaced by one", weighted, []cmp{
+			{n: "a", s: monsts.OK}, {n: "b", s: monsts.KO, w: 0}, {n: "c", s: monsts.OK, w: -5},
+		}, monsts.Warn, 2.0/3),
+		Entry("weighted: default thresholds", rule{r: mongrp.RuleWeighted}, []cmp{
+			{n: "a", s: monsts.OK, w: 9}, {n: "b", s: monsts.Warn, w: 1},
+		}, monsts.Warn, 0.95),
+		Entry("weighted: critical KO", weighted, []cmp{
+			{n: "a", s: monsts.OK, w: 10}, {n: "b", s: monsts.KO, w: 1, c: true},
+		}, monsts.KO, 10.0/11),
+
+		Entry("worst of: dependency KO", worst, []cmp{
+			{n: "db", s: monsts.Warn}, {n: "app", s: monsts.OK, d: []string{"db"}},
+		}, monsts.Warn, 0.5),
+		Entry("quorum: dependency KO removing an OK", quorum, []cmp{
+			{n: "db", s: monsts.KO}, {n: "web", s: monsts.OK, d: []string{"db"}}, {n: "app", s: monsts.OK},
+		}, monsts.KO, 1.0/3),
+		Entry("weighted: dependency Warn halving a weight", weighted, []cmp{
+			{n: "db", s: monsts.Warn, w: 1}, {n: "app", s: monsts.OK, w: 3, d: []string{"db"}},
+		}, monsts.Warn, 0.5),
+		Entry("critical: dependency KO making a critical component KO", weighted, []cmp{
+			{n: "db", s: monsts.KO, w: 0.1}, {n: "app", s: monsts.OK, w: 10, c: true, d: []string{"db"}},
+		}, monsts.KO, 0.0),
+	)
+
+	DescribeTable("effective status of the components",
+		func(lst []cmp, exp map[string]monsts.Status) {
+			res := newGroup(worst, lst).Check()
+			Expect(res.Components).To(HaveLen(len(exp)))
+
+			for k, s := range exp {
+				Expect(res.Components).To(HaveKey(k))
+				Expect(res.Components[k].Effective).To(Equal(s), "component %s", k)
+			}
+		},
+		Entry("own status without dependency", []cmp{
+			{n: "a", s: monsts.Warn}, {n: "b", s: monsts.OK},
+		}, map[string]monsts.Status{"a": monsts.Warn, "b": monsts.OK}),
+		Entry("dependency worse than the own status", []cmp{
+			{n: "db", s: monsts.KO}, {n: "app", s: monsts.OK, d: []string{"db"}},
+		}, map[string]monsts.Status{"db": monsts.KO, "app": monsts.KO}),
+		Entry("own status worse than the dependency", []cmp{
+			{n: "db", s: monsts.OK}, {n: "app", s: monsts.KO, d: []string{"db"}},
+		}, map[string]monsts.Status{"db": monsts.OK, "app": monsts.KO}),
+		Entry("transitive dependencies", []cmp{
+			{n: "c", s: monsts.Warn}, {n: "b", s: monsts.OK, d: []string{"c"}}, {n: "a", s: monsts.OK, d: []string{"b"}},
+		}, map[string]monsts.Status{"a": monsts.Warn, "b": monsts.Warn, "c": monsts.Warn}),
+		Entry("transitive dependencies registered before their dependencies", []cmp{
+			{n: "a", s: monsts.OK, d: []string{"b"}}, {n: "b", s: monsts.OK, d: []string{"c"}}, {n: "c", s: monsts.KO},
+		}, map[string]monsts.Status{"a": monsts.KO, "b": monsts.KO, "c": monsts.KO}),
+		Entry("worst of several dependencies", []cmp{
+			{n: "x", s: monsts.Warn}, {n: "y", s: monsts.KO}, {n: "a", s: monsts.OK, d: []string{"x", "y"}},
+		}, map[string]monsts.Status{"a": monsts.KO, "x": monsts.Warn, "y": monsts.KO}),
+		Entry("diamond dependencies", []cmp{
+			{n: "d", s: monsts.Warn},
+			{n: "b", s: monsts.OK, d: []string{"d"}},
+			{n: "c", s: monsts.OK, d: []string{"d"}},
+			{n: "a", s: monsts.OK, d: []string{"b", "c"}},
+		}, map[string]monsts.Status{"a": monsts.Warn, "b": monsts.Warn, "c": monsts.Warn, "d": monsts.Warn}),
+		Entry("unknown dependency", []cmp{
+			{n: "a", s: monsts.OK, d: []string{"missing"}}, {n: "b", s: monsts.OK},
+		}, map[string]monsts.Status{"a": monsts.KO, "b": monsts.OK}),
+	)
+
+	DescribeTable("dependency cycles",
+		func(lst []cmp, name string, deps []string) {
+			var g = newGroup(worst, lst)
+
+			old, ok := g.Get(name)
+			Expect(ok).To(BeTrue())
+
+			e := g.DependsOn(name, deps...)
+			Expect(e).To(HaveOccurred())
+			Expect(liberr.IsCode(e, mongrp.ErrorDependencyCycle)).To(BeTrue())
+
+			cur, ok := g.Get(name)
+			Expect(ok).To(BeTrue())
+			Expect(cur.DependsOn).To(Equal(old.DependsOn))
+		},
+		Entry("self dependency", []cmp{
+			{n: "a", s: monsts.OK},
+		}, "a", []string{"a"}),
+		Entry("direct cycle", []cmp{
+			{n: "a", s: monsts.OK}, {n: "b", s: monsts.OK, d: []string{"a"}},
+		}, "a", []string{"b"}),
+		Entry("indirect cycle", []cmp{
+			{n: "a", s: monsts.OK}, {n: "b", s: monsts.OK, d: []string{"a"}}, {n: "c", s: monsts.OK, d: []string{"b"}},
+		}, "a", []string{"c"}),
+		Entry("cycle among several dependencies", []cmp{
+			{n: "a", s: monsts.OK}, {n: "b", s: monsts.OK}, {n: "c", s: monsts.OK, d: []string{"a"}},
+		}, "a", []string{"b", "c"}),
+	)
+
+	It("must reject a component added with a dependency cycle", func() {
+		var g = newGroup(worst, []cmp{
+			{n: "a", s: monsts.OK, d: []string{"b"}},
+			{n: "b", s: monsts.OK},
+		})
+
+		e := g.Add(mongrp.Component{
+			Source:    &src{n: "b", s: monsts.KO},
+			DependsOn: []string{"a"},
+		})
+
+		Expect(liberr.IsCode(e, mongrp.ErrorDependencyCycle)).To(BeTrue())
+
+		// the previous registration is kept
+		c, ok := g.Get("b")
+		Expect(ok).To(BeTrue())
+		Expect(c.DependsOn).To(BeEmpty())
+		Expect(c.Source.Status()).To(Equal(monsts.OK))
+		Expect(g.Check().Status).To(Equal(monsts.OK))
+	})
+
+	It("must release the dependents of a removed component", func() {
+		var g = newGroup(worst, []cmp{
+			{n: "db", s: monsts.KO},
+			{n: "app", s: monsts.OK, d: []string{"db"}},
+		})
+
+		Expect(g.Check().Components["app"].Effective).To(Equal(monsts.KO))
+
+		g.Del("db")
+
+		res := g.Check()
+		Expect(res.Status).To(Equal(monsts.OK))
+		Expect(res.Components["app"].Effective).To(Equal(monsts.OK))
+	})
+
+	It("must be KO without component", func() {
+		Expect(mongrp.New("group", mongrp.RuleQuorum).Check().Status).To(Equal(monsts.KO))
+	})
+
+	It("must use the effective status of a nested group", func() {
+		var (
+			sub = newGroup(worst, []cmp{
+				{n: "db", s: monsts.KO},
+				{n: "app", s: monsts.OK, d: []string{"db"}},
+			})
+			top = mongrp.New("top", mongrp.RuleWorstOf)
+		)
+
+		Expect(sub.Check().Status).To(Equal(monsts.KO))
+		Expect(top.Add(mongrp.Component{Source: sub})).ToNot(HaveOccurred())
+		Expect(top.Add(mongrp.Component{Source: &src{n: "web", s: monsts.OK}, DependsOn: []string{"group"}})).ToNot(HaveOccurred())
+
+		res := top.Check()
+		Expect(res.Status).To(Equal(monsts.KO))
+		Expect(res.Components["web"].Effective).To(Equal(monsts.KO))
+	})
+})
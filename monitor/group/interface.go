@@ -0,0 +1,150 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package group
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"sync"
+	"time"
+
+	monsts "github.com/nabbar/golib/monitor/status"
+)
+
+// Source is the minimal interface of a component that can be aggregated into a group.
+// Any montps.Monitor and any Group implement this interface.
+type Source interface {
+	Name() string
+	Status() monsts.Status
+	Message() string
+}
+
+// Component define how a source is aggregated into the group.
+type Component struct {
+	// Source is the component status provider.
+	Source Source
+
+	// Weight is the weight of the component used by the RuleWeighted rule.
+	// A zero or negative weight is replaced by 1.
+	Weight float64
+
+	// Critical when true force the group status to KO if the effective status of this component is KO,
+	// whatever the rule applied.
+	Critical bool
+
+	// DependsOn is the list of components name this component depends on.
+	// The effective status of the component cannot be better than the worst effective status of its dependencies.
+	DependsOn []string
+}
+
+// Event is sent to all registered FuncEvent each time the status of the group or of one of its components changes.
+type Event struct {
+	Group     string        `json:"group"`
+	Component string        `json:"component,omitempty"`
+	Previous  monsts.Status `json:"previous"`
+	Current   monsts.Status `json:"current"`
+	Message   string        `json:"message,omitempty"`
+	Time      time.Time     `json:"time"`
+}
+
+// IsGroup return true if the event is related to the group aggregate status and not to one of its components.
+func (e Event) IsGroup() bool {
+	return len(e.Component) < 1
+}
+
+type FuncEvent func(evt Event)
+
+type Group interface {
+	encoding.TextMarshaler
+	json.Marshaler
+	Source
+
+	// SetRule define the rollup rule used to compute the aggregate status.
+	SetRule(rule Rule)
+
+	// GetRule return the current rollup rule.
+	GetRule() Rule
+
+	// SetQuorum define the minimum number of components to be OK for the RuleQuorum rule.
+	// A zero or negative value means the majority of components.
+	SetQuorum(min int)
+
+	// SetThreshold define the minimal weighted score (between 0 and 1) for the OK and Warn status
+	// used by the RuleWeighted rule.
+	SetThreshold(ok, warn float64)
+
+	// Add register or replace a component into the group.
+	Add(cmp Component) error
+
+	// Del remove a component from the group and from all dependencies lists.
+	Del(name string)
+
+	// Get return the component registered with the given name.
+	Get(name string) (Component, bool)
+
+	// List return the name of all registered components.
+	List() []string
+
+	// DependsOn add dependencies to the given component.
+	// An error is returned if the component or one dependency is not found or if a dependency cycle is detected.
+	DependsOn(name string, deps ...string) error
+
+	// RegisterEvent add a function called on each status change of the group or its components.
+	RegisterEvent(fct FuncEvent)
+
+	// Check compute the aggregate status, send the change events and return the result.
+	Check() Result
+
+	// Last return the last computed result without computing a new one.
+	Last() Result
+
+	// HealthCheck can be used as montps.HealthCheck to plug the group into a standard monitor.
+	HealthCheck(ctx context.Context) error
+}
+
+// New return a new group with the given name, using the given rule.
+func New(name string, rule Rule) Group {
+	return &grp{
+		m: sync.RWMutex{},
+		n: name,
+		r: rule,
+		q: 0,
+		o: defaultThresholdOK,
+		w: defaultThresholdWarn,
+		c: make(map[string]Component),
+		k: make([]string, 0),
+		f: make([]FuncEvent, 0),
+		l: make(map[string]monsts.Status),
+		s: Result{
+			Name:       name,
+			Rule:       rule,
+			Status:     monsts.KO,
+			Components: make(map[string]ResultComponent),
+		},
+	}
+}
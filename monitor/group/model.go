@@ -0,0 +1,366 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package group
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	monsts "github.com/nabbar/golib/monitor/status"
+)
+
+type grp struct {
+	m sync.RWMutex
+	n string  // name
+	r Rule    // rule
+	q int     // quorum
+	o float64 // threshold ok
+	w float64 // threshold warn
+
+	c map[string]Component     // components
+	k []string                 // components name in insertion order
+	f []FuncEvent              // event functions
+	l map[string]monsts.Status // last effective status of components
+	s Result                   // last result
+}
+
+func (o *grp) Name() string {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	return o.n
+}
+
+func (o *grp) Status() monsts.Status {
+	return o.Check().Status
+}
+
+func (o *grp) Message() string {
+	return o.Last().Message
+}
+
+func (o *grp) SetRule(rule Rule) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.r = rule
+}
+
+func (o *grp) GetRule() Rule {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	return o.r
+}
+
+func (o *grp) SetQuorum(min int) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.q = min
+}
+
+func (o *grp) SetThreshold(ok, warn float64) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if ok <= 0 || ok > 1 {
+		ok = defaultThresholdOK
+	}
+
+	if warn < 0 || warn > ok {
+		warn = defaultThresholdWarn
+	}
+
+	if warn > ok {
+		warn = ok
+	}
+
+	o.o = ok
+	o.w = warn
+}
+
+func (o *grp) Add(cmp Component) error {
+	if cmp.Source == nil {
+		return ErrorParamEmpty.Error(nil)
+	}
+
+	var name = cmp.Source.Name()
+
+	if len(name) < 1 {
+		return ErrorParamEmpty.Error(nil)
+	} else if cmp.Weight <= 0 {
+		cmp.Weight = 1
+	}
+
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	old, exist := o.c[name]
+	o.c[name] = cmp
+
+	if e := o.checkCycle(); e != nil {
+		if exist {
+			o.c[name] = old
+		} else {
+			delete(o.c, name)
+		}
+		return e
+	}
+
+	if !exist {
+		o.k = append(o.k, name)
+	}
+
+	return nil
+}
+
+func (o *grp) Del(name string) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if _, ok := o.c[name]; !ok {
+		return
+	}
+
+	delete(o.c, name)
+	delete(o.l, name)
+
+	var k = make([]string, 0, len(o.k))
+
+	for _, n := range o.k {
+		if n != name {
+			k = append(k, n)
+		}
+	}
+
+	o.k = k
+
+	for n, c := range o.c {
+		var d = make([]string, 0, len(c.DependsOn))
+
+		for _, i := range c.DependsOn {
+			if i != name {
+				d = append(d, i)
+			}
+		}
+
+		c.DependsOn = d
+		o.c[n] = c
+	}
+}
+
+func (o *grp) Get(name string) (Component, bool) {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	c, ok := o.c[name]
+	return c, ok
+}
+
+func (o *grp) List() []string {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	var res = make([]string, len(o.k))
+	copy(res, o.k)
+
+	return res
+}
+
+func (o *grp) DependsOn(name string, deps ...string) error {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	cmp, ok := o.c[name]
+
+	if !ok {
+		return ErrorComponentNotFound.Error(fmt.Errorf("component '%s'", name))
+	}
+
+	var old = cmp.DependsOn
+
+	for _, d := range deps {
+		if _, k := o.c[d]; !k {
+			return ErrorComponentNotFound.Error(fmt.Errorf("dependency '%s'", d))
+		} else if !stringInSlice(cmp.DependsOn, d) {
+			cmp.DependsOn = append(cmp.DependsOn, d)
+		}
+	}
+
+	o.c[name] = cmp
+
+	if e := o.checkCycle(); e != nil {
+		cmp.DependsOn = old
+		o.c[name] = cmp
+		return e
+	}
+
+	return nil
+}
+
+func (o *grp) RegisterEvent(fct FuncEvent) {
+	if fct == nil {
+		return
+	}
+
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.f = append(o.f, fct)
+}
+
+func (o *grp) Last() Result {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	return o.s
+}
+
+func (o *grp) HealthCheck(ctx context.Context) error {
+	if ctx != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	if r := o.Check(); r.Status == monsts.KO {
+		if len(r.Message) > 0 {
+			return fmt.Errorf("group '%s' is %s: %s", r.Name, r.Status.String(), r.Message)
+		}
+		return fmt.Errorf("group '%s' is %s", r.Name, r.Status.String())
+	}
+
+	return nil
+}
+
+func (o *grp) MarshalText() ([]byte, error) {
+	return o.Check().MarshalText()
+}
+
+func (o *grp) MarshalJSON() ([]byte, error) {
+	return json.Marshal(o.Check())
+}
+
+// checkCycle return an error if a dependency cycle exists. Must be called with the lock held.
+func (o *grp) checkCycle() error {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	var (
+		state = make(map[string]int, len(o.c))
+		visit func(name string, path []string) error
+	)
+
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case visiting:
+			return ErrorDependencyCycle.Error(fmt.Errorf("%v -> %s", path, name))
+		case visited:
+			return nil
+		}
+
+		state[name] = visiting
+
+		if c, ok := o.c[name]; ok {
+			for _, d := range c.DependsOn {
+				if e := visit(d, append(path, name)); e != nil {
+					return e
+				}
+			}
+		}
+
+		state[name] = visited
+		return nil
+	}
+
+	var key = make([]string, 0, len(o.c))
+
+	for k := range o.c {
+		key = append(key, k)
+	}
+
+	sort.Strings(key)
+
+	for _, k := range key {
+		if state[k] == unvisited {
+			if e := visit(k, make([]string, 0)); e != nil {
+				return e
+			}
+		}
+	}
+
+	return nil
+}
+
+func stringInSlice(list []string, s string) bool {
+	for _, i := range list {
+		if i == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (o *grp) sendEvents(evt []Event) {
+	if len(evt) < 1 {
+		return
+	}
+
+	o.m.RLock()
+	var fct = make([]FuncEvent, len(o.f))
+	copy(fct, o.f)
+	o.m.RUnlock()
+
+	for _, f := range fct {
+		for _, e := range evt {
+			func() {
+				defer func() {
+					_ = recover()
+				}()
+				f(e)
+			}()
+		}
+	}
+}
+
+func newEvent(group, component string, prev, curr monsts.Status, msg string) Event {
+	return Event{
+		Group:     group,
+		Component: component,
+		Previous:  prev,
+		Current:   curr,
+		Message:   msg,
+		Time:      time.Now(),
+	}
+}
@@ -0,0 +1,96 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package group
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	monsts "github.com/nabbar/golib/monitor/status"
+)
+
+// ResultComponent is the status of one component into a Result.
+type ResultComponent struct {
+	// Status is the status reported by the component itself.
+	Status monsts.Status `json:"status"`
+	// Effective is the status of the component after applying the status of its dependencies.
+	Effective monsts.Status `json:"effective"`
+	Message   string        `json:"message,omitempty"`
+	Weight    float64       `json:"weight"`
+	Critical  bool          `json:"critical,omitempty"`
+	DependsOn []string      `json:"depends_on,omitempty"`
+}
+
+// Result is the aggregate status of a group.
+type Result struct {
+	Name       string                     `json:"name"`
+	Rule       Rule                       `json:"rule"`
+	Status     monsts.Status              `json:"status"`
+	Score      float64                    `json:"score"`
+	Message    string                     `json:"message,omitempty"`
+	Time       time.Time                  `json:"time"`
+	Components map[string]ResultComponent `json:"components"`
+}
+
+func (r Result) MarshalText() ([]byte, error) {
+	var (
+		buf = bytes.NewBuffer(make([]byte, 0))
+		key = make([]string, 0, len(r.Components))
+	)
+
+	buf.WriteString(fmt.Sprintf("%s: %s (%s, score %.2f)", r.Status.String(), r.Name, r.Rule.String(), r.Score))
+
+	if len(r.Message) > 0 {
+		buf.WriteString(" | " + r.Message)
+	}
+
+	for k := range r.Components {
+		key = append(key, k)
+	}
+
+	sort.Strings(key)
+
+	for _, k := range key {
+		c := r.Components[k]
+		buf.WriteRune('\n')
+		buf.WriteString(fmt.Sprintf("  - %s: %s (self: %s)", k, c.Effective.String(), c.Status.String()))
+
+		if len(c.Message) > 0 {
+			buf.WriteString(" | " + c.Message)
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (r Result) MarshalJSON() ([]byte, error) {
+	type res Result
+	return json.Marshal(res(r))
+}
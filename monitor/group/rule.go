@@ -0,0 +1,96 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package group
+
+import (
+	"strconv"
+	"strings"
+)
+
+type Rule uint8
+
+const (
+	// RuleWorstOf set the group status to the worst effective status of its components.
+	RuleWorstOf Rule = iota
+	// RuleQuorum set the group status to OK if a minimum number of components are OK,
+	// to Warn if this minimum is reached with Warn components, otherwise to KO.
+	RuleQuorum
+	// RuleWeighted compute a score from the weight of each component (OK = 1, Warn = 0.5, KO = 0)
+	// and compare it with the OK and Warn thresholds.
+	RuleWeighted
+)
+
+const (
+	defaultThresholdOK   = 1.0
+	defaultThresholdWarn = 0.5
+)
+
+func ParseRule(s string) Rule {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case strings.ToLower(RuleQuorum.String()):
+		return RuleQuorum
+	case strings.ToLower(RuleWeighted.String()):
+		return RuleWeighted
+	default:
+		return RuleWorstOf
+	}
+}
+
+func (r Rule) String() string {
+	switch r {
+	case RuleQuorum:
+		return "Quorum"
+	case RuleWeighted:
+		return "Weighted"
+	default:
+		return "WorstOf"
+	}
+}
+
+func (r Rule) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(r.String())), nil
+}
+
+func (r *Rule) UnmarshalJSON(data []byte) error {
+	var str = string(data)
+
+	if s, e := strconv.Unquote(str); e == nil {
+		str = s
+	}
+
+	*r = ParseRule(str)
+	return nil
+}
+
+func (r Rule) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+func (r *Rule) UnmarshalText(data []byte) error {
+	*r = ParseRule(string(data))
+	return nil
+}
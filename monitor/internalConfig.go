@@ -46,6 +46,7 @@ type runCfg struct {
 	fallCountWarn uint8
 	riseCountKO   uint8
 	riseCountWarn uint8
+	slo           []runSLO
 }
 
 func (o *mon) defConfig() *runCfg {
@@ -125,6 +126,7 @@ func (o *mon) SetConfig(ctx libctx.FuncContext, cfg montps.Config) liberr.Error
 		fallCountWarn: cfg.FallCountWarn,
 		riseCountKO:   cfg.RiseCountKO,
 		riseCountWarn: cfg.RiseCountWarn,
+		slo:           newRunSLO(cfg.SLO),
 	}
 
 	if cnf.checkTimeout < 5*time.Second {
@@ -191,6 +193,11 @@ func (o *mon) GetConfig() montps.Config {
 		opt = &logcfg.Options{}
 	}
 
+	slo := make([]montps.ConfigSLO, 0, len(cfg.slo))
+	for _, s := range cfg.slo {
+		slo = append(slo, s.config())
+	}
+
 	return montps.Config{
 		Name:          o.getName(),
 		CheckTimeout:  libdur.ParseDuration(cfg.checkTimeout),
@@ -201,6 +208,7 @@ func (o *mon) GetConfig() montps.Config {
 		FallCountWarn: cfg.fallCountWarn,
 		RiseCountKO:   cfg.riseCountKO,
 		RiseCountWarn: cfg.riseCountWarn,
+		SLO:           slo,
 		Logger:        *opt,
 	}
 }
@@ -53,23 +53,28 @@ type lastRun struct {
 	latency  time.Duration
 
 	err error
+
+	sloStatus monsts.Status
+	sloErr    error
 }
 
 func newLastRun() *lastRun {
 	return &lastRun{
-		m:        sync.RWMutex{},
-		status:   monsts.KO,
-		runtime:  time.Now(),
-		isRise:   false,
-		isFall:   false,
-		cntRise:  0,
-		cntFall:  0,
-		uptime:   0,
-		downtime: 0,
-		riseTime: 0,
-		fallTime: 0,
-		latency:  0,
-		err:      fmt.Errorf("no healcheck still run"),
+		m:         sync.RWMutex{},
+		status:    monsts.KO,
+		runtime:   time.Now(),
+		isRise:    false,
+		isFall:    false,
+		cntRise:   0,
+		cntFall:   0,
+		uptime:    0,
+		downtime:  0,
+		riseTime:  0,
+		fallTime:  0,
+		latency:   0,
+		err:       fmt.Errorf("no healcheck still run"),
+		sloStatus: monsts.OK,
+		sloErr:    nil,
 	}
 }
 
@@ -106,6 +111,11 @@ func (o *lastRun) DownTime() time.Duration {
 func (o *lastRun) Status() monsts.Status {
 	o.m.RLock()
 	defer o.m.RUnlock()
+
+	if o.sloErr != nil && o.sloStatus < o.status {
+		return o.sloStatus
+	}
+
 	return o.status
 }
 
@@ -124,9 +134,22 @@ func (o *lastRun) IsFall() bool {
 func (o *lastRun) Error() error {
 	o.m.RLock()
 	defer o.m.RUnlock()
+
+	if o.err == nil {
+		return o.sloErr
+	}
+
 	return o.err
 }
 
+func (o *lastRun) setSLO(sts monsts.Status, err error) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.sloStatus = sts
+	o.sloErr = err
+}
+
 func (o *lastRun) setStatus(err error, dur time.Duration, cfg *runCfg) {
 	o.m.Lock()
 	defer o.m.Unlock()
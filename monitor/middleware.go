@@ -28,6 +28,7 @@ package monitor
 
 import (
 	"context"
+	"fmt"
 
 	montps "github.com/nabbar/golib/monitor/types"
 )
@@ -57,7 +58,18 @@ func newMiddleware(cfg *runCfg, fct montps.HealthCheck) middleWare {
 		mdl: make([]fctMiddleWare, 0),
 	}
 
-	o.Add(func(m middleWare) error {
+	o.Add(func(m middleWare) (err error) {
+		if fct == nil {
+			return ErrorMissingHealthCheck.Error(nil)
+		}
+
+		defer func() {
+			if r := recover(); r != nil {
+				slf.addPanic()
+				err = ErrorCheckPanic.Error(fmt.Errorf("%v", r))
+			}
+		}()
+
 		return fct(m.Context())
 	})
 
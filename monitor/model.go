@@ -46,6 +46,8 @@ const (
 	keyHealthCheck = "keyFct"
 	keyRun         = "keyRun"
 	keyLastRun     = "keyLastRun"
+	keyWindow      = "keyWindow"
+	keyReporter    = "keyReporter"
 
 	keyMetricsName = "keyMetricsName"
 	keyMetricsFunc = "keyMetricsFunc"
@@ -0,0 +1,149 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package probe
+
+import (
+	"fmt"
+	"time"
+
+	libval "github.com/go-playground/validator/v10"
+	libdur "github.com/nabbar/golib/duration"
+	liberr "github.com/nabbar/golib/errors"
+	libprm "github.com/nabbar/golib/file/perm"
+)
+
+const (
+	DefaultInterval    = 5 * time.Second
+	DefaultFileMode    = libprm.Perm(0644)
+	DefaultSocketMode  = libprm.Perm(0660)
+	DefaultReadTimeout = time.Second
+)
+
+// Config is the part of the configuration common to all adapters.
+type Config struct {
+	// Interval define the time waiting between 2 status check of the source. Default is 5 second.
+	Interval libdur.Duration `json:"interval" yaml:"interval" toml:"interval" mapstructure:"interval"`
+
+	// WarnIsReady define if a Warn status is considered as ready. By default, only OK status is ready.
+	WarnIsReady bool `json:"warn-is-ready" yaml:"warn-is-ready" toml:"warn-is-ready" mapstructure:"warn-is-ready"`
+
+	// KOIsNotLive define if a KO status also fails the liveness probe.
+	// By default, the liveness only reflects that the adapter is still running its checks.
+	KOIsNotLive bool `json:"ko-is-not-live" yaml:"ko-is-not-live" toml:"ko-is-not-live" mapstructure:"ko-is-not-live"`
+}
+
+// FileConfig is the configuration of the file adapter.
+// At least one of the files must be defined.
+type FileConfig struct {
+	Config `json:",inline" yaml:",inline" toml:",inline" mapstructure:",squash"`
+
+	// LiveFile is touched at each check while live, and removed otherwise.
+	// An exec probe can check its presence and freshness (ex: find <file> -mmin -1).
+	LiveFile string `json:"live-file,omitempty" yaml:"live-file,omitempty" toml:"live-file,omitempty" mapstructure:"live-file,omitempty"`
+
+	// ReadyFile exists only while the source is ready. An exec probe can check its presence (ex: cat <file>).
+	ReadyFile string `json:"ready-file,omitempty" yaml:"ready-file,omitempty" toml:"ready-file,omitempty" mapstructure:"ready-file,omitempty"`
+
+	// StatusFile is rewritten at each check with the last result encoded in JSON.
+	StatusFile string `json:"status-file,omitempty" yaml:"status-file,omitempty" toml:"status-file,omitempty" mapstructure:"status-file,omitempty"`
+
+	// FileMode define the permission of the created files. Default is 0644.
+	FileMode libprm.Perm `json:"file-mode,omitempty" yaml:"file-mode,omitempty" toml:"file-mode,omitempty" mapstructure:"file-mode,omitempty"`
+}
+
+// SocketConfig is the configuration of the unix admin socket adapter.
+type SocketConfig struct {
+	Config `json:",inline" yaml:",inline" toml:",inline" mapstructure:",squash"`
+
+	// Path is the path of the unix socket file.
+	Path string `json:"path" yaml:"path" toml:"path" mapstructure:"path" validate:"required"`
+
+	// FileMode define the permission of the socket file. Default is 0660.
+	FileMode libprm.Perm `json:"file-mode,omitempty" yaml:"file-mode,omitempty" toml:"file-mode,omitempty" mapstructure:"file-mode,omitempty"`
+
+	// GroupPerm define the group id owning the socket file. A negative value keep the group of the process.
+	GroupPerm int32 `json:"group-perm,omitempty" yaml:"group-perm,omitempty" toml:"group-perm,omitempty" mapstructure:"group-perm,omitempty"`
+}
+
+func validate(cfg interface{}) liberr.Error {
+	var e = ErrorValidatorError.Error(nil)
+
+	if err := libval.New().Struct(cfg); err != nil {
+		if er, ok := err.(*libval.InvalidValidationError); ok {
+			e.Add(er)
+		}
+
+		for _, er := range err.(libval.ValidationErrors) {
+			//nolint #goerr113
+			e.Add(fmt.Errorf("config field '%s' is not validated by constraint '%s'", er.Namespace(), er.ActualTag()))
+		}
+	}
+
+	if !e.HasParent() {
+		e = nil
+	}
+
+	return e
+}
+
+func (o FileConfig) Validate() liberr.Error {
+	if e := validate(o); e != nil {
+		return e
+	} else if len(o.LiveFile) < 1 && len(o.ReadyFile) < 1 && len(o.StatusFile) < 1 {
+		return ErrorValidatorError.Error(fmt.Errorf("at least one probe file must be defined"))
+	}
+
+	return nil
+}
+
+func (o SocketConfig) Validate() liberr.Error {
+	return validate(o)
+}
+
+func (o Config) getInterval() time.Duration {
+	if o.Interval.Time() > 0 {
+		return o.Interval.Time()
+	}
+
+	return DefaultInterval
+}
+
+func (o FileConfig) getFileMode() libprm.Perm {
+	if o.FileMode > 0 {
+		return o.FileMode
+	}
+
+	return DefaultFileMode
+}
+
+func (o SocketConfig) getFileMode() libprm.Perm {
+	if o.FileMode > 0 {
+		return o.FileMode
+	}
+
+	return DefaultSocketMode
+}
@@ -0,0 +1,135 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package probe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type fileOutput struct {
+	c FileConfig
+}
+
+func (o *fileOutput) start(ctx context.Context) error {
+	for _, f := range []string{o.c.LiveFile, o.c.ReadyFile, o.c.StatusFile} {
+		if len(f) < 1 {
+			continue
+		} else if e := os.MkdirAll(filepath.Dir(f), 0755); e != nil {
+			return ErrorFileWrite.Error(e)
+		}
+	}
+
+	return nil
+}
+
+func (o *fileOutput) stop(ctx context.Context, last Result) error {
+	var err = ErrorFileWrite.Error(nil)
+
+	for _, f := range []string{o.c.LiveFile, o.c.ReadyFile} {
+		if e := o.remove(f); e != nil {
+			err.Add(e)
+		}
+	}
+
+	if len(o.c.StatusFile) > 0 {
+		if e := o.writeStatus(last); e != nil {
+			err.Add(e)
+		}
+	}
+
+	if !err.HasParent() {
+		return nil
+	}
+
+	return err
+}
+
+func (o *fileOutput) update(res Result) {
+	if res.Live {
+		_ = o.write(o.c.LiveFile, []byte(res.Time.Format(time.RFC3339)+"\n"))
+	} else {
+		_ = o.remove(o.c.LiveFile)
+	}
+
+	if res.Ready {
+		_ = o.write(o.c.ReadyFile, []byte(res.Status.String()+"\n"))
+	} else {
+		_ = o.remove(o.c.ReadyFile)
+	}
+
+	_ = o.writeStatus(res)
+}
+
+func (o *fileOutput) writeStatus(res Result) error {
+	if len(o.c.StatusFile) < 1 {
+		return nil
+	}
+
+	p, e := json.Marshal(res)
+
+	if e != nil {
+		return e
+	}
+
+	return o.write(o.c.StatusFile, append(p, '\n'))
+}
+
+// write replace the file content through a rename, so a probe never reads a partial file.
+func (o *fileOutput) write(path string, p []byte) error {
+	if len(path) < 1 {
+		return nil
+	}
+
+	var tmp = filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+
+	if e := os.WriteFile(tmp, p, o.c.getFileMode().FileMode()); e != nil {
+		return e
+	} else if e = os.Chmod(tmp, o.c.getFileMode().FileMode()); e != nil {
+		_ = os.Remove(tmp)
+		return e
+	} else if e = os.Rename(tmp, path); e != nil {
+		_ = os.Remove(tmp)
+		return e
+	}
+
+	return nil
+}
+
+func (o *fileOutput) remove(path string) error {
+	if len(path) < 1 {
+		return nil
+	} else if e := os.Remove(path); e != nil && !errors.Is(e, os.ErrNotExist) {
+		return e
+	}
+
+	return nil
+}
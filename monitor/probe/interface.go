@@ -0,0 +1,115 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package probe
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	liberr "github.com/nabbar/golib/errors"
+	mongrp "github.com/nabbar/golib/monitor/group"
+	monsts "github.com/nabbar/golib/monitor/status"
+	libsrv "github.com/nabbar/golib/server"
+)
+
+// Source is the aggregate status provider exposed by the adapters.
+// Any montps.Monitor and any mongrp.Group implement this interface.
+type Source interface {
+	mongrp.Source
+}
+
+// Result is the state of the source at the last check, as exposed to the probes.
+type Result struct {
+	Name    string        `json:"name"`
+	Status  monsts.Status `json:"status"`
+	Message string        `json:"message,omitempty"`
+	Live    bool          `json:"live"`
+	Ready   bool          `json:"ready"`
+	Time    time.Time     `json:"time"`
+}
+
+// Adapter expose the status of a source to the kubernetes probes, without any http server.
+// The checks are run at each interval between Start and Stop.
+type Adapter interface {
+	libsrv.Server
+
+	// Check compute the result from the source status and update the exposed state.
+	Check(ctx context.Context) Result
+
+	// Last return the last computed result without computing a new one.
+	Last() Result
+
+	// IsLive return the liveness of the last result.
+	IsLive() bool
+
+	// IsReady return the readiness of the last result.
+	IsReady() bool
+}
+
+// NewFile return an adapter writing the status of the source to the configured files,
+// to be used by exec based probes.
+func NewFile(src Source, cfg FileConfig) (Adapter, liberr.Error) {
+	if src == nil {
+		return nil, ErrorParamEmpty.Error(nil)
+	} else if e := cfg.Validate(); e != nil {
+		return nil, e
+	}
+
+	o := newProbe(src, cfg.Config)
+	o.o = &fileOutput{c: cfg}
+
+	return o, nil
+}
+
+// NewSocket return an adapter answering the status of the source on a unix admin socket.
+// Each connection sends one command line ("live", "ready" or "status", default is "ready")
+// and receives one line: "OK <status>" or "KO <status>: <message>" for live and ready, the JSON result for status.
+func NewSocket(src Source, cfg SocketConfig) (Adapter, liberr.Error) {
+	if src == nil {
+		return nil, ErrorParamEmpty.Error(nil)
+	} else if e := cfg.Validate(); e != nil {
+		return nil, e
+	}
+
+	o := newProbe(src, cfg.Config)
+	o.o = &socketOutput{c: cfg, p: o}
+
+	return o, nil
+}
+
+func newProbe(src Source, cfg Config) *prb {
+	return &prb{
+		m: sync.RWMutex{},
+		s: src,
+		c: cfg,
+		l: Result{
+			Name:   src.Name(),
+			Status: monsts.KO,
+		},
+	}
+}
@@ -0,0 +1,90 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package probe
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	monsts "github.com/nabbar/golib/monitor/status"
+	librun "github.com/nabbar/golib/server/runner/ticker"
+)
+
+// output is the way an adapter expose the results.
+type output interface {
+	start(ctx context.Context) error
+	stop(ctx context.Context, last Result) error
+	update(res Result)
+}
+
+type prb struct {
+	m sync.RWMutex
+	s Source        // source
+	c Config        // config
+	o output        // output
+	l Result        // last result
+	r librun.Ticker // runner
+}
+
+func (o *prb) Check(ctx context.Context) Result {
+	var (
+		s = o.s.Status()
+		r = Result{
+			Name:    o.s.Name(),
+			Status:  s,
+			Message: o.s.Message(),
+			Time:    time.Now(),
+		}
+	)
+
+	r.Ready = s == monsts.OK || (s == monsts.Warn && o.c.WarnIsReady)
+	r.Live = ctx.Err() == nil && (s != monsts.KO || !o.c.KOIsNotLive)
+
+	o.m.Lock()
+	o.l = r
+	o.m.Unlock()
+
+	o.o.update(r)
+
+	return r
+}
+
+func (o *prb) Last() Result {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	return o.l
+}
+
+func (o *prb) IsLive() bool {
+	return o.IsRunning() && o.Last().Live
+}
+
+func (o *prb) IsReady() bool {
+	return o.IsRunning() && o.Last().Ready
+}
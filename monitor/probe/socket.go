@@ -0,0 +1,165 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package probe
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	libptc "github.com/nabbar/golib/network/protocol"
+	libsck "github.com/nabbar/golib/socket"
+	sckcfg "github.com/nabbar/golib/socket/config"
+)
+
+const (
+	CommandLive   = "live"
+	CommandReady  = "ready"
+	CommandStatus = "status"
+
+	maxCommandSize = 64
+	pollRunning    = 10 * time.Millisecond
+	timeoutRunning = 5 * time.Second
+)
+
+type socketOutput struct {
+	m sync.Mutex
+	c SocketConfig
+	p *prb
+	s libsck.Server
+}
+
+func (o *socketOutput) start(ctx context.Context) error {
+	var gid = o.c.GroupPerm
+
+	if gid <= 0 {
+		gid = -1
+	}
+
+	cfg := sckcfg.ServerConfig{
+		Network:   libptc.NetworkUnix,
+		Address:   o.c.Path,
+		PermFile:  o.c.getFileMode().FileMode(),
+		GroupPerm: gid,
+	}
+
+	srv, err := cfg.New(nil, o.handle)
+
+	if err != nil {
+		return ErrorSocketListen.Error(err)
+	}
+
+	o.m.Lock()
+	o.s = srv
+	o.m.Unlock()
+
+	go func() {
+		_ = srv.Listen(ctx)
+	}()
+
+	var tmo = time.After(timeoutRunning)
+
+	for !srv.IsRunning() {
+		select {
+		case <-tmo:
+			_ = srv.Close()
+			return ErrorSocketListen.Error(nil)
+		case <-ctx.Done():
+			_ = srv.Close()
+			return ErrorSocketListen.Error(ctx.Err())
+		case <-time.After(pollRunning):
+		}
+	}
+
+	return nil
+}
+
+func (o *socketOutput) stop(ctx context.Context, _ Result) error {
+	o.m.Lock()
+	srv := o.s
+	o.s = nil
+	o.m.Unlock()
+
+	if srv == nil {
+		return nil
+	}
+
+	return srv.Shutdown(ctx)
+}
+
+func (o *socketOutput) update(res Result) {}
+
+func (o *socketOutput) handle(request libsck.Reader, response libsck.Writer) {
+	defer func() {
+		_ = request.Close()
+		_ = response.Close()
+	}()
+
+	// a client not sending its command must not hold the connection
+	var t = time.AfterFunc(DefaultReadTimeout, func() {
+		_ = request.Close()
+	})
+
+	cmd, _ := bufio.NewReaderSize(io.LimitReader(request, maxCommandSize), maxCommandSize).ReadString('\n')
+	t.Stop()
+
+	_, _ = response.Write(o.answer(cmd))
+}
+
+func (o *socketOutput) answer(cmd string) []byte {
+	var (
+		res = o.p.Last()
+		ok  bool
+	)
+
+	if !o.p.IsRunning() {
+		res.Live = false
+		res.Ready = false
+	}
+
+	switch strings.ToLower(strings.TrimSpace(cmd)) {
+	case CommandStatus:
+		p, _ := json.Marshal(res)
+		return append(p, '\n')
+	case CommandLive:
+		ok = res.Live
+	default:
+		ok = res.Ready
+	}
+
+	if ok {
+		return []byte("OK " + res.Status.String() + "\n")
+	} else if len(res.Message) > 0 {
+		return []byte("KO " + res.Status.String() + ": " + strings.ReplaceAll(res.Message, "\n", " ") + "\n")
+	}
+
+	return []byte("KO " + res.Status.String() + "\n")
+}
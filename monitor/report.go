@@ -0,0 +1,136 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	loglvl "github.com/nabbar/golib/logger/level"
+	monsts "github.com/nabbar/golib/monitor/status"
+	montps "github.com/nabbar/golib/monitor/types"
+	libsrv "github.com/nabbar/golib/server"
+)
+
+type reporter struct {
+	r montps.Reporter
+	c bool          // report on status change
+	i time.Duration // report interval
+	l atomic.Int64  // last report time (unix nano)
+}
+
+func (r *reporter) isDue(chg bool, now time.Time) bool {
+	if chg && r.c {
+		return true
+	} else if r.i <= 0 {
+		return false
+	}
+
+	return now.Sub(time.Unix(0, r.l.Load())) >= r.i
+}
+
+func (o *mon) RegisterReporter(rep montps.Reporter, onChange bool, interval time.Duration) {
+	if rep == nil {
+		return
+	}
+
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	var l []*reporter
+
+	if i, k := o.x.Load(keyReporter); k {
+		if v, ok := i.([]*reporter); ok {
+			l = append(l, v...)
+		}
+	}
+
+	o.x.Store(keyReporter, append(l, &reporter{r: rep, c: onChange, i: interval}))
+}
+
+func (o *mon) CleanReporter() {
+	o.x.Delete(keyReporter)
+}
+
+func (o *mon) getReporter() []*reporter {
+	if i, l := o.x.Load(keyReporter); !l {
+		return nil
+	} else if v, k := i.([]*reporter); !k {
+		return nil
+	} else {
+		return v
+	}
+}
+
+// report send the status to the due reporters, without waiting for them.
+func (o *mon) report(prv monsts.Status, cfg *runCfg) {
+	var (
+		lst = o.getReporter()
+		now = time.Now()
+	)
+
+	if len(lst) < 1 {
+		return
+	}
+
+	rpt := montps.Report{
+		Name:     o.Name(),
+		Status:   o.Status(),
+		Previous: prv,
+		Message:  o.Message(),
+		Latency:  o.Latency(),
+		Time:     now,
+	}
+	rpt.Change = rpt.Status != prv
+
+	tmo := 5 * time.Second
+	if cfg != nil && cfg.checkTimeout > 0 {
+		tmo = cfg.checkTimeout
+	}
+
+	for _, r := range lst {
+		if !r.isDue(rpt.Change, now) {
+			continue
+		}
+
+		r.l.Store(now.UnixNano())
+		go o.sendReport(r.r, rpt, tmo)
+	}
+}
+
+func (o *mon) sendReport(rep montps.Reporter, rpt montps.Report, tmo time.Duration) {
+	defer libsrv.RecoveryCaller("golib/monitor/report", recover())
+
+	ctx, cnl := context.WithTimeout(context.Background(), tmo)
+	defer cnl()
+
+	if e := rep.Report(ctx, rpt); e != nil {
+		o.getLogger().Entry(loglvl.WarnLevel, fmt.Sprintf("cannot send status report of monitor '%s'", rpt.Name)).ErrorAdd(true, e).Log()
+	}
+}
@@ -0,0 +1,106 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package reporter
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	libval "github.com/go-playground/validator/v10"
+	libdur "github.com/nabbar/golib/duration"
+	liberr "github.com/nabbar/golib/errors"
+)
+
+const (
+	DefaultWebhookMethod      = http.MethodPost
+	DefaultWebhookContentType = "application/json"
+	DefaultWebhookTimeout     = 5 * time.Second
+	DefaultStatsdPrefix       = "monitor"
+)
+
+// WebhookConfig is the configuration of the http webhook reporter.
+type WebhookConfig struct {
+	// URL is the endpoint receiving the reports.
+	URL string `json:"url" yaml:"url" toml:"url" mapstructure:"url" validate:"required,url"`
+
+	// Method is the http method of the request. Default is POST.
+	Method string `json:"method,omitempty" yaml:"method,omitempty" toml:"method,omitempty" mapstructure:"method,omitempty"`
+
+	// Headers are added to each request.
+	Headers map[string]string `json:"headers,omitempty" yaml:"headers,omitempty" toml:"headers,omitempty" mapstructure:"headers,omitempty"`
+
+	// ContentType is the content type of the payload. Default is application/json.
+	ContentType string `json:"content-type,omitempty" yaml:"content-type,omitempty" toml:"content-type,omitempty" mapstructure:"content-type,omitempty"`
+
+	// Template is a text/template executed with the report to build the payload.
+	// By default, the payload is the report encoded in JSON.
+	Template string `json:"template,omitempty" yaml:"template,omitempty" toml:"template,omitempty" mapstructure:"template,omitempty"`
+
+	// Timeout define the max duration of a request. Default is 5 second.
+	Timeout libdur.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty" toml:"timeout,omitempty" mapstructure:"timeout,omitempty"`
+}
+
+func (o WebhookConfig) Validate() liberr.Error {
+	return validate(o)
+}
+
+// StatsdConfig is the configuration of the statsd reporter, sending the metrics over UDP.
+type StatsdConfig struct {
+	// Address is the host:port of the statsd server.
+	Address string `json:"address" yaml:"address" toml:"address" mapstructure:"address" validate:"required,hostname_port"`
+
+	// Prefix is added before the name of each metric. Default is "monitor".
+	Prefix string `json:"prefix,omitempty" yaml:"prefix,omitempty" toml:"prefix,omitempty" mapstructure:"prefix,omitempty"`
+}
+
+func (o StatsdConfig) Validate() liberr.Error {
+	return validate(o)
+}
+
+func validate(cfg interface{}) liberr.Error {
+	var e = ErrorValidatorError.Error(nil)
+
+	if err := libval.New().Struct(cfg); err != nil {
+		if er, ok := err.(*libval.InvalidValidationError); ok {
+			e.Add(er)
+		}
+
+		if ers, ok := err.(libval.ValidationErrors); ok {
+			for _, er := range ers {
+				//nolint #goerr113
+				e.Add(fmt.Errorf("config field '%s' is not validated by constraint '%s'", er.Namespace(), er.ActualTag()))
+			}
+		}
+	}
+
+	if !e.HasParent() {
+		e = nil
+	}
+
+	return e
+}
@@ -0,0 +1,105 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package reporter
+
+import (
+	"context"
+	"text/template"
+
+	libdur "github.com/nabbar/golib/duration"
+	liberr "github.com/nabbar/golib/errors"
+	libhtc "github.com/nabbar/golib/httpcli"
+	montps "github.com/nabbar/golib/monitor/types"
+)
+
+// FuncReport is a generic callback receiving the reports.
+type FuncReport func(ctx context.Context, rpt montps.Report) error
+
+// NewCallback return a reporter calling the given function for each report.
+func NewCallback(fct FuncReport) (montps.Reporter, liberr.Error) {
+	if fct == nil {
+		return nil, ErrorParamEmpty.Error(nil)
+	}
+
+	return &callback{f: fct}, nil
+}
+
+// NewWebhook return a reporter sending each report as an http request.
+// If the client is nil, the default http client of the httpcli package is used.
+func NewWebhook(cfg WebhookConfig, cli libhtc.HttpClient) (montps.Reporter, liberr.Error) {
+	if e := cfg.Validate(); e != nil {
+		return nil, e
+	}
+
+	var (
+		tpl *template.Template
+		err error
+	)
+
+	if len(cfg.Template) > 0 {
+		if tpl, err = template.New("webhook").Parse(cfg.Template); err != nil {
+			return nil, ErrorTemplate.Error(err)
+		}
+	}
+
+	if len(cfg.Method) < 1 {
+		cfg.Method = DefaultWebhookMethod
+	}
+
+	if len(cfg.ContentType) < 1 {
+		cfg.ContentType = DefaultWebhookContentType
+	}
+
+	if cfg.Timeout.Time() <= 0 {
+		cfg.Timeout = libdur.ParseDuration(DefaultWebhookTimeout)
+	}
+
+	return &webhook{c: cfg, t: tpl, h: cli}, nil
+}
+
+// NewStatsd return a reporter sending the status, the latency and the changes of each report
+// as statsd metrics over UDP: <prefix>.<name>.status (gauge), <prefix>.<name>.latency (timer)
+// and <prefix>.<name>.change (counter, only on status change).
+func NewStatsd(cfg StatsdConfig) (montps.Reporter, liberr.Error) {
+	if e := cfg.Validate(); e != nil {
+		return nil, e
+	}
+
+	if len(cfg.Prefix) < 1 {
+		cfg.Prefix = DefaultStatsdPrefix
+	}
+
+	return &statsd{c: cfg}, nil
+}
+
+type callback struct {
+	f FuncReport
+}
+
+func (o *callback) Report(ctx context.Context, rpt montps.Report) error {
+	return o.f(ctx, rpt)
+}
@@ -0,0 +1,90 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package reporter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	montps "github.com/nabbar/golib/monitor/types"
+	sckudp "github.com/nabbar/golib/socket/client/udp"
+)
+
+type statsd struct {
+	c StatsdConfig
+}
+
+func (o *statsd) normalizeName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '.':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
+
+func (o *statsd) payload(rpt montps.Report) []byte {
+	var (
+		buf = bytes.NewBuffer(make([]byte, 0))
+		pfx = o.c.Prefix + "." + o.normalizeName(rpt.Name)
+	)
+
+	_, _ = fmt.Fprintf(buf, "%s.status:%d|g\n", pfx, rpt.Status.Int())
+	_, _ = fmt.Fprintf(buf, "%s.latency:%d|ms\n", pfx, rpt.Latency.Milliseconds())
+
+	if rpt.Change {
+		_, _ = fmt.Fprintf(buf, "%s.change:1|c\n", pfx)
+	}
+
+	return buf.Bytes()
+}
+
+func (o *statsd) Report(ctx context.Context, rpt montps.Report) error {
+	c, e := sckudp.New(o.c.Address)
+
+	if e != nil {
+		return ErrorStatsdSend.Error(e)
+	} else if e = c.Connect(ctx); e != nil {
+		return ErrorStatsdSend.Error(e)
+	}
+
+	defer func() {
+		_ = c.Close()
+	}()
+
+	if _, e = c.Write(o.payload(rpt)); e != nil {
+		return ErrorStatsdSend.Error(e)
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package reporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"text/template"
+
+	libhtc "github.com/nabbar/golib/httpcli"
+	montps "github.com/nabbar/golib/monitor/types"
+)
+
+type webhook struct {
+	c WebhookConfig
+	t *template.Template
+	h libhtc.HttpClient
+}
+
+func (o *webhook) payload(rpt montps.Report) ([]byte, error) {
+	if o.t == nil {
+		return json.Marshal(rpt)
+	}
+
+	var buf = bytes.NewBuffer(make([]byte, 0))
+
+	if e := o.t.Execute(buf, rpt); e != nil {
+		return nil, ErrorTemplate.Error(e)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (o *webhook) client() libhtc.HttpClient {
+	if o.h != nil {
+		return o.h
+	}
+
+	return libhtc.GetClient()
+}
+
+func (o *webhook) Report(ctx context.Context, rpt montps.Report) error {
+	p, e := o.payload(rpt)
+
+	if e != nil {
+		return e
+	}
+
+	ctx, cnl := context.WithTimeout(ctx, o.c.Timeout.Time())
+	defer cnl()
+
+	req, e := http.NewRequestWithContext(ctx, o.c.Method, o.c.URL, bytes.NewReader(p))
+
+	if e != nil {
+		return ErrorWebhookRequest.Error(e)
+	}
+
+	req.Header.Set("Content-Type", o.c.ContentType)
+
+	for k, v := range o.c.Headers {
+		req.Header.Set(k, v)
+	}
+
+	rsp, e := o.client().Do(req)
+
+	if e != nil {
+		return ErrorWebhookRequest.Error(e)
+	}
+
+	defer func() {
+		_, _ = io.Copy(io.Discard, rsp.Body)
+		_ = rsp.Body.Close()
+	}()
+
+	if rsp.StatusCode < http.StatusOK || rsp.StatusCode >= http.StatusMultipleChoices {
+		//nolint #goerr113
+		return ErrorWebhookStatus.Error(fmt.Errorf("response status '%s'", rsp.Status))
+	}
+
+	return nil
+}
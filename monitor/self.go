@@ -0,0 +1,214 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	libctx "github.com/nabbar/golib/context"
+	moninf "github.com/nabbar/golib/monitor/info"
+	montps "github.com/nabbar/golib/monitor/types"
+)
+
+const (
+	// DefaultSelfName is the name of the monitor checking the monitor engine itself.
+	DefaultSelfName = "monitor engine"
+
+	// DefaultSelfQueueDelay is the default max delay between the scheduled time and the run of a check.
+	DefaultSelfQueueDelay = time.Second
+
+	keySelfSchedule = "keySelfSchedule"
+)
+
+// SelfMetrics are the internal metrics of the monitor engine, shared by all the monitors of the process.
+type SelfMetrics struct {
+	// Scheduled is the number of checks run.
+	Scheduled uint64 `json:"scheduled"`
+	// Overruns is the number of checks lasting more than their timeout or their interval.
+	Overruns uint64 `json:"overruns"`
+	// Panics is the number of checks ended by a panic.
+	Panics uint64 `json:"panics"`
+	// QueueDelay is the delay between the scheduled time and the run of the last check.
+	QueueDelay time.Duration `json:"queue_delay"`
+	// MaxQueueDelay is the max delay between the scheduled time and the run of a check.
+	MaxQueueDelay time.Duration `json:"max_queue_delay"`
+}
+
+func (s SelfMetrics) Info() map[string]interface{} {
+	return map[string]interface{}{
+		"scheduled":       s.Scheduled,
+		"overruns":        s.Overruns,
+		"panics":          s.Panics,
+		"queue_delay":     s.QueueDelay.String(),
+		"max_queue_delay": s.MaxQueueDelay.String(),
+	}
+}
+
+type selfCounter struct {
+	s atomic.Uint64 // scheduled
+	o atomic.Uint64 // overruns
+	p atomic.Uint64 // panics
+	d atomic.Int64  // last queue delay
+	x atomic.Int64  // max queue delay
+}
+
+var slf = &selfCounter{}
+
+func (c *selfCounter) addRun(delay time.Duration) {
+	if delay < 0 {
+		delay = 0
+	}
+
+	c.s.Add(1)
+	c.d.Store(int64(delay))
+
+	for {
+		if m := c.x.Load(); int64(delay) <= m || c.x.CompareAndSwap(m, int64(delay)) {
+			return
+		}
+	}
+}
+
+func (c *selfCounter) addOverrun() {
+	c.o.Add(1)
+}
+
+func (c *selfCounter) addPanic() {
+	c.p.Add(1)
+}
+
+// Self return the current internal metrics of the monitor engine.
+func Self() SelfMetrics {
+	return SelfMetrics{
+		Scheduled:     slf.s.Load(),
+		Overruns:      slf.o.Load(),
+		Panics:        slf.p.Load(),
+		QueueDelay:    time.Duration(slf.d.Load()),
+		MaxQueueDelay: time.Duration(slf.x.Load()),
+	}
+}
+
+// NewSelf return a monitor checking the monitor engine itself, so a failure of the health-check
+// machinery is detectable. The check fails if a check has panicked or overrun since the previous
+// self check, or if the queue delay of the last check is more than the given max delay
+// (DefaultSelfQueueDelay if zero). The monitor is not started.
+func NewSelf(ctx libctx.FuncContext, maxDelay time.Duration) (montps.Monitor, error) {
+	var (
+		e   error
+		inf moninf.Info
+		mon montps.Monitor
+	)
+
+	if maxDelay <= 0 {
+		maxDelay = DefaultSelfQueueDelay
+	}
+
+	if inf, e = moninf.New(DefaultSelfName); e != nil {
+		return nil, e
+	}
+
+	inf.RegisterInfo(func() (map[string]interface{}, error) {
+		return Self().Info(), nil
+	})
+
+	if mon, e = New(ctx, inf); e != nil {
+		return nil, e
+	}
+
+	mon.SetHealthCheck(selfHealthCheck(maxDelay))
+
+	return mon, nil
+}
+
+func selfHealthCheck(maxDelay time.Duration) montps.HealthCheck {
+	var (
+		m sync.Mutex
+		p = Self()
+	)
+
+	return func(ctx context.Context) error {
+		m.Lock()
+		defer m.Unlock()
+
+		var (
+			c = Self()
+			l = p
+		)
+
+		p = c
+
+		if n := c.Panics - l.Panics; n > 0 {
+			return ErrorSelfUnhealthy.Error(fmt.Errorf("%d checks panicked", n))
+		} else if n = c.Overruns - l.Overruns; n > 0 {
+			return ErrorSelfUnhealthy.Error(fmt.Errorf("%d checks overrun", n))
+		} else if c.QueueDelay > maxDelay {
+			return ErrorSelfUnhealthy.Error(fmt.Errorf("queue delay %s", c.QueueDelay.String()))
+		}
+
+		return nil
+	}
+}
+
+// schedule is the expected time of the next run of a monitor and the period of its ticker.
+type schedule struct {
+	n time.Time
+	p time.Duration
+}
+
+func (o *mon) getSchedule() schedule {
+	if i, l := o.x.Load(keySelfSchedule); !l {
+		return schedule{}
+	} else if v, k := i.(schedule); !k {
+		return schedule{}
+	} else {
+		return v
+	}
+}
+
+func (o *mon) setSchedule(s schedule) {
+	o.x.Store(keySelfSchedule, s)
+}
+
+// next return the schedule of the next run of a ticker not reset during the run.
+func (s schedule) next(end time.Time) schedule {
+	if s.p <= 0 || s.n.IsZero() {
+		return s
+	}
+
+	var n = s.n.Add(s.p)
+
+	// the ticker drops the ticks missed during a long run
+	for !n.After(end) {
+		n = n.Add(s.p)
+	}
+
+	return schedule{n: n, p: s.p}
+}
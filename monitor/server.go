@@ -154,6 +154,7 @@ func (o *mon) setRunner(ctx context.Context) {
 	)
 
 	o.r = librun.New(cfg.intervalCheck, o.runFunc)
+	o.setSchedule(schedule{n: time.Now().Add(cfg.intervalCheck), p: cfg.intervalCheck})
 }
 
 func (o *mon) delRunner(ctx context.Context) {
@@ -175,21 +176,47 @@ func (o *mon) runFunc(ctx context.Context, tck *time.Ticker) error {
 	var (
 		cfg = o.getCfg()
 		chg = false
+		tms = time.Now()
+		sch = o.getSchedule()
+		prv = o.Status()
 	)
 
+	if sch.n.IsZero() {
+		slf.addRun(0)
+	} else {
+		slf.addRun(tms.Sub(sch.n))
+	}
+
 	o.check(ctx, cfg)
+	o.report(prv, cfg)
+
+	var (
+		end = time.Now()
+		dur = end.Sub(tms)
+	)
+
+	if (sch.p > 0 && dur > sch.p) || (cfg != nil && dur > cfg.checkTimeout) {
+		slf.addOverrun()
+	}
 
 	if o.IsRise() {
 		tck.Reset(cfg.intervalRise)
+		sch = schedule{n: end.Add(cfg.intervalRise), p: cfg.intervalRise}
 		chg = true
 	} else if o.IsFall() {
 		tck.Reset(cfg.intervalFall)
+		sch = schedule{n: end.Add(cfg.intervalFall), p: cfg.intervalFall}
 		chg = true
 	} else if chg {
 		tck.Reset(cfg.intervalCheck)
+		sch = schedule{n: end.Add(cfg.intervalCheck), p: cfg.intervalCheck}
 		chg = false
+	} else {
+		sch = sch.next(end)
 	}
 
+	o.setSchedule(sch)
+
 	return nil
 }
 
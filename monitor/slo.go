@@ -0,0 +1,130 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package monitor
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	libdur "github.com/nabbar/golib/duration"
+	monsts "github.com/nabbar/golib/monitor/status"
+	montps "github.com/nabbar/golib/monitor/types"
+)
+
+type runSLO struct {
+	window     time.Duration
+	percentile float64
+	maxLatency time.Duration
+	minSuccess float64
+	minSamples int
+	status     monsts.Status
+}
+
+func newRunSLO(cfg []montps.ConfigSLO) []runSLO {
+	res := make([]runSLO, 0, len(cfg))
+
+	for _, c := range cfg {
+		if c.Window.Time() <= 0 {
+			continue
+		}
+
+		res = append(res, runSLO{
+			window:     c.Window.Time(),
+			percentile: c.GetPercentile(),
+			maxLatency: c.MaxLatency.Time(),
+			minSuccess: c.MinSuccess / 100,
+			minSamples: c.MinSamples,
+			status:     c.GetStatus(),
+		})
+	}
+
+	return res
+}
+
+func (s runSLO) config() montps.ConfigSLO {
+	return montps.ConfigSLO{
+		Window:     libdur.ParseDuration(s.window),
+		Percentile: s.percentile,
+		MaxLatency: libdur.ParseDuration(s.maxLatency),
+		MinSuccess: s.minSuccess * 100,
+		MinSamples: s.minSamples,
+		Status:     s.status.String(),
+	}
+}
+
+// check return an error if the objective is breached by the samples of the window.
+func (s runSLO) check(w *window) error {
+	lat, suc := w.collect(s.window)
+
+	if len(lat) < 1 || len(lat) < s.minSamples {
+		return nil
+	}
+
+	if s.minSuccess > 0 {
+		if r := float64(suc) / float64(len(lat)); r < s.minSuccess {
+			return fmt.Errorf("success ratio %.2f%% below %.2f%% on %s", r*100, s.minSuccess*100, s.window.String())
+		}
+	}
+
+	if s.maxLatency > 0 {
+		if l := percentile(lat, s.percentile); l > s.maxLatency {
+			return fmt.Errorf("latency p%g %s above %s on %s", s.percentile, l.String(), s.maxLatency.String(), s.window.String())
+		}
+	}
+
+	return nil
+}
+
+// checkSLO evaluate all the objectives and return the lowest status of the breached ones with their errors.
+func (o *mon) checkSLO(cfg *runCfg) (monsts.Status, error) {
+	if cfg == nil || len(cfg.slo) < 1 {
+		return monsts.OK, nil
+	}
+
+	var (
+		w = o.getWindow()
+		s = monsts.OK
+		m = make([]string, 0)
+	)
+
+	for _, c := range cfg.slo {
+		if e := c.check(w); e != nil {
+			m = append(m, e.Error())
+
+			if c.status < s {
+				s = c.status
+			}
+		}
+	}
+
+	if len(m) < 1 {
+		return monsts.OK, nil
+	}
+
+	return s, ErrorSLOBreached.Error(fmt.Errorf("%s", strings.Join(m, ", ")))
+}
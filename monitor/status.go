@@ -101,8 +101,12 @@ func (o *mon) mdlStatus(m middleWare) error {
 	ts := time.Now()
 	err := m.Next()
 
+	dur := time.Since(ts)
+	o.getWindow().add(dur, err == nil)
+
 	lst := o.getLastCheck()
-	lst.setStatus(err, time.Since(ts), m.Config())
+	lst.setStatus(err, dur, m.Config())
+	lst.setSLO(o.checkSLO(m.Config()))
 	o.setLastCheck(lst)
 
 	return err
@@ -48,6 +48,7 @@ var _defaultConfig = []byte(`{
   "fall-count-warn": "",
   "rise-count-ko": "",
   "rise-count-warn": "",
+  "slo": [],
   "logger": ` + string(logcfg.DefaultConfig(cfgtps.JSONIndent+cfgtps.JSONIndent)) + `
 }`)
 
@@ -92,6 +93,9 @@ type Config struct {
 	// RiseCountWarn define the number of OK when status is Warn before considerate the component as up.
 	RiseCountWarn uint8 `json:"rise-count-warn" yaml:"rise-count-warn" toml:"rise-count-warn" mapstructure:"rise-count-warn"`
 
+	// SLO define the service level objectives evaluated on the rolling windows of checks.
+	SLO []ConfigSLO `json:"slo,omitempty" yaml:"slo,omitempty" toml:"slo,omitempty" mapstructure:"slo,omitempty" validate:"omitempty,dive"`
+
 	// Logger define the logger options for current monitor log
 	Logger logcfg.Options `json:"logger" yaml:"logger" toml:"logger" mapstructure:"logger"`
 }
@@ -110,6 +114,13 @@ func (o Config) Validate() liberr.Error {
 		}
 	}
 
+	for i, s := range o.SLO {
+		if w := s.Window.Time(); w <= 0 || w > WindowHour {
+			//nolint #goerr113
+			e.Add(fmt.Errorf("config field 'Config.SLO[%d].Window' must be between 0 and %s", i, WindowHour.String()))
+		}
+	}
+
 	if !e.HasParent() {
 		e = nil
 	}
@@ -128,6 +139,7 @@ func (o Config) Clone() Config {
 		FallCountWarn: o.FallCountWarn,
 		RiseCountKO:   o.RiseCountKO,
 		RiseCountWarn: o.RiseCountWarn,
+		SLO:           append(make([]ConfigSLO, 0, len(o.SLO)), o.SLO...),
 		Logger:        o.Logger.Clone(),
 	}
 }
@@ -69,6 +69,12 @@ type MonitorStatus interface {
 
 	// Downtime return the total duration of downtime (KO status)
 	Downtime() time.Duration
+
+	// Window return the latency and availability statistics of the checks run in the given rolling window (up to 1h)
+	Window(win time.Duration) WindowStats
+
+	// Windows return the statistics of the default rolling windows (1m, 5m, 1h)
+	Windows() []WindowStats
 }
 
 type MonitorMetrics interface {
@@ -113,6 +119,13 @@ type Monitor interface {
 	// GetHealthCheck is used to retrieve the healthcheck func
 	GetHealthCheck() HealthCheck
 
+	// RegisterReporter add a reporter called after a check when the status change (if onChange is true)
+	// and at each interval (if not zero), to push the status to an external system.
+	RegisterReporter(rep Reporter, onChange bool, interval time.Duration)
+
+	// CleanReporter remove all the registered reporters
+	CleanReporter()
+
 	// Clone is used to clone monitor to another standalone instance
 	Clone(ctx context.Context) (Monitor, liberr.Error)
 }
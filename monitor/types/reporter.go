@@ -0,0 +1,58 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package types
+
+import (
+	"context"
+	"time"
+
+	monsts "github.com/nabbar/golib/monitor/status"
+)
+
+// Report is the status of a monitor sent to the reporters after a check.
+type Report struct {
+	// Name is the name of the monitor.
+	Name string `json:"name"`
+	// Status is the status of the monitor after the check.
+	Status monsts.Status `json:"status"`
+	// Previous is the status of the monitor before the check.
+	Previous monsts.Status `json:"previous"`
+	// Change is true if the status has changed with the check.
+	Change bool `json:"change"`
+	// Message is the last error, warning, message of the status.
+	Message string `json:"message,omitempty"`
+	// Latency is the latency of the check.
+	Latency time.Duration `json:"latency"`
+	// Time is the time of the report.
+	Time time.Time `json:"time"`
+}
+
+// Reporter push the status of a monitor to an external system.
+type Reporter interface {
+	// Report send the given status. The context is canceled at the check timeout.
+	Report(ctx context.Context, rpt Report) error
+}
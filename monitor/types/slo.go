@@ -0,0 +1,119 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package types
+
+import (
+	"strings"
+	"time"
+
+	libdur "github.com/nabbar/golib/duration"
+	monsts "github.com/nabbar/golib/monitor/status"
+)
+
+const (
+	// WindowMinute is the shortest rolling window of check statistics.
+	WindowMinute = time.Minute
+	// Window5Minutes is the medium rolling window of check statistics.
+	Window5Minutes = 5 * time.Minute
+	// WindowHour is the longest rolling window of check statistics, and so the retention of the samples.
+	WindowHour = time.Hour
+
+	// SLODefaultPercentile is the latency percentile used by an SLO when none is given.
+	SLODefaultPercentile = 99
+)
+
+// Windows return the list of rolling windows of check statistics kept by each monitor.
+func Windows() []time.Duration {
+	return []time.Duration{WindowMinute, Window5Minutes, WindowHour}
+}
+
+// WindowStats are the latency and availability statistics of the checks run in a rolling window.
+type WindowStats struct {
+	// Window is the duration of the rolling window.
+	Window time.Duration `json:"window"`
+	// Count is the number of checks run in the window.
+	Count int `json:"count"`
+	// Success is the ratio (between 0 and 1) of succeeded checks in the window.
+	Success float64 `json:"success"`
+	// P50 is the median latency of the checks in the window.
+	P50 time.Duration `json:"p50"`
+	// P90 is the 90th percentile latency of the checks in the window.
+	P90 time.Duration `json:"p90"`
+	// P99 is the 99th percentile latency of the checks in the window.
+	P99 time.Duration `json:"p99"`
+}
+
+func (s WindowStats) Info() map[string]interface{} {
+	return map[string]interface{}{
+		"window":  s.Window.String(),
+		"count":   s.Count,
+		"success": s.Success,
+		"p50":     s.P50.String(),
+		"p90":     s.P90.String(),
+		"p99":     s.P99.String(),
+	}
+}
+
+// ConfigSLO define a service level objective evaluated on a rolling window after each check.
+// When the objective is breached, the status of the monitor is lowered to the SLO status.
+type ConfigSLO struct {
+	// Window define the rolling window of the checks used to evaluate the objective (up to 1h).
+	Window libdur.Duration `json:"window" yaml:"window" toml:"window" mapstructure:"window"`
+
+	// Percentile define the latency percentile compared to MaxLatency. Default is 99.
+	Percentile float64 `json:"percentile,omitempty" yaml:"percentile,omitempty" toml:"percentile,omitempty" mapstructure:"percentile,omitempty" validate:"omitempty,gt=0,lte=100"`
+
+	// MaxLatency define the max latency allowed for the percentile. Zero disable the latency objective.
+	MaxLatency libdur.Duration `json:"max-latency,omitempty" yaml:"max-latency,omitempty" toml:"max-latency,omitempty" mapstructure:"max-latency,omitempty"`
+
+	// MinSuccess define the minimal percent (between 0 and 100) of succeeded checks. Zero disable the availability objective.
+	MinSuccess float64 `json:"min-success,omitempty" yaml:"min-success,omitempty" toml:"min-success,omitempty" mapstructure:"min-success,omitempty" validate:"omitempty,gte=0,lte=100"`
+
+	// MinSamples define the minimal number of checks in the window before evaluating the objective.
+	MinSamples int `json:"min-samples,omitempty" yaml:"min-samples,omitempty" toml:"min-samples,omitempty" mapstructure:"min-samples,omitempty" validate:"omitempty,gte=0"`
+
+	// Status define the status (Warn or KO) of the monitor when the objective is breached. Default is Warn.
+	Status string `json:"status,omitempty" yaml:"status,omitempty" toml:"status,omitempty" mapstructure:"status,omitempty" validate:"omitempty,oneof=Warn KO warn ko"`
+}
+
+// GetStatus return the status to apply when the objective is breached.
+func (o ConfigSLO) GetStatus() monsts.Status {
+	if strings.EqualFold(o.Status, monsts.KO.String()) {
+		return monsts.KO
+	}
+
+	return monsts.Warn
+}
+
+// GetPercentile return the latency percentile of the objective.
+func (o ConfigSLO) GetPercentile() float64 {
+	if o.Percentile <= 0 || o.Percentile > 100 {
+		return SLODefaultPercentile
+	}
+
+	return o.Percentile
+}
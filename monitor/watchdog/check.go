@@ -0,0 +1,149 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package watchdog
+
+import (
+	"context"
+	"math"
+	"time"
+
+	monsts "github.com/nabbar/golib/monitor/status"
+)
+
+func (o *wdg) Check(ctx context.Context) {
+	var (
+		sts = o.s.Status()
+		msg = o.s.Message()
+	)
+
+	if !o.isUnhealthy(sts) {
+		o.m.Lock()
+		var rec = o.n > 0 || o.z
+		o.n = 0
+		o.z = false
+		o.m.Unlock()
+
+		if rec {
+			o.sendEvent(o.newEvent(EventRecover, sts, msg, nil))
+		}
+
+		return
+	}
+
+	o.m.Lock()
+	if o.n < math.MaxUint8 {
+		o.n++
+	}
+
+	var now = time.Now()
+	o.cleanHistory(now)
+
+	var (
+		thr = o.n >= o.c.getThreshold()
+		cld = !o.l.IsZero() && now.Sub(o.l) < o.c.getCoolDown()
+		lim = o.c.MaxRestart > 0 && len(o.h) >= int(o.c.MaxRestart)
+		esc = o.z
+	)
+	o.m.Unlock()
+
+	o.sendEvent(o.newEvent(EventUnhealthy, sts, msg, nil))
+
+	if !thr {
+		return
+	} else if cld {
+		o.sendEvent(o.newEvent(EventCoolDown, sts, msg, nil))
+		return
+	} else if lim {
+		if !esc {
+			o.escalate(ctx, sts, msg)
+		}
+		return
+	}
+
+	o.action(ctx, sts, msg)
+}
+
+func (o *wdg) action(ctx context.Context, sts monsts.Status, msg string) {
+	o.m.Lock()
+	var (
+		srv = o.v
+		fct = o.f
+		tmo = o.c.getRestartTimeout()
+		now = time.Now()
+	)
+
+	o.l = now
+	o.h = append(o.h, now)
+	o.n = 0
+	o.m.Unlock()
+
+	if srv != nil {
+		var err error
+
+		func() {
+			x, n := context.WithTimeout(ctx, tmo)
+			defer n()
+
+			if e := srv.Restart(x); e != nil {
+				err = ErrorRestart.Error(e)
+			}
+		}()
+
+		o.sendEvent(o.newEvent(EventRestart, sts, msg, err))
+	}
+
+	if fct != nil {
+		var (
+			err error
+			evt = o.newEvent(EventCallback, sts, msg, nil)
+		)
+
+		if e := fct(ctx, evt); e != nil {
+			err = ErrorCallback.Error(e)
+		}
+
+		evt.Error = err
+		o.sendEvent(evt)
+	}
+}
+
+func (o *wdg) escalate(ctx context.Context, sts monsts.Status, msg string) {
+	o.m.Lock()
+	var fct = o.x
+	o.z = true
+	o.m.Unlock()
+
+	var evt = o.newEvent(EventEscalate, sts, msg, nil)
+
+	if fct != nil {
+		if e := fct(ctx, evt); e != nil {
+			evt.Error = ErrorCallback.Error(e)
+		}
+	}
+
+	o.sendEvent(evt)
+}
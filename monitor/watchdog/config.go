@@ -0,0 +1,129 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package watchdog
+
+import (
+	"fmt"
+	"time"
+
+	libval "github.com/go-playground/validator/v10"
+	libdur "github.com/nabbar/golib/duration"
+	liberr "github.com/nabbar/golib/errors"
+)
+
+const (
+	DefaultInterval       = 5 * time.Second
+	DefaultThreshold      = 3
+	DefaultCoolDown       = 30 * time.Second
+	DefaultRestartWindow  = 10 * time.Minute
+	DefaultRestartTimeout = 30 * time.Second
+)
+
+type Config struct {
+	// Interval define the time waiting between 2 status check of the source. Default is 5 second.
+	Interval libdur.Duration `json:"interval" yaml:"interval" toml:"interval" mapstructure:"interval"`
+
+	// Threshold define the number of consecutive unhealthy status before running the action. Default is 3.
+	Threshold uint8 `json:"threshold" yaml:"threshold" toml:"threshold" mapstructure:"threshold"`
+
+	// WarnIsUnhealthy define if a Warn status is considered as unhealthy. By default, only KO status is unhealthy.
+	WarnIsUnhealthy bool `json:"warn-is-unhealthy" yaml:"warn-is-unhealthy" toml:"warn-is-unhealthy" mapstructure:"warn-is-unhealthy"`
+
+	// CoolDown define the minimum time to wait after an action before running a new one. Default is 30 second.
+	CoolDown libdur.Duration `json:"cool-down" yaml:"cool-down" toml:"cool-down" mapstructure:"cool-down"`
+
+	// MaxRestart define the maximum number of action into the restart window before escalating.
+	// Zero means no limit and so no escalation.
+	MaxRestart uint8 `json:"max-restart" yaml:"max-restart" toml:"max-restart" mapstructure:"max-restart"`
+
+	// RestartWindow define the rolling window used to count the actions for MaxRestart. Default is 10 minutes.
+	RestartWindow libdur.Duration `json:"restart-window" yaml:"restart-window" toml:"restart-window" mapstructure:"restart-window"`
+
+	// RestartTimeout define the timeout given to the restart of the bound server. Default is 30 second.
+	RestartTimeout libdur.Duration `json:"restart-timeout" yaml:"restart-timeout" toml:"restart-timeout" mapstructure:"restart-timeout"`
+}
+
+func (o Config) Validate() liberr.Error {
+	var e = ErrorValidatorError.Error(nil)
+
+	if err := libval.New().Struct(o); err != nil {
+		if er, ok := err.(*libval.InvalidValidationError); ok {
+			e.Add(er)
+		}
+
+		for _, er := range err.(libval.ValidationErrors) {
+			//nolint #goerr113
+			e.Add(fmt.Errorf("config field '%s' is not validated by constraint '%s'", er.Namespace(), er.ActualTag()))
+		}
+	}
+
+	if !e.HasParent() {
+		e = nil
+	}
+
+	return e
+}
+
+func (o Config) getInterval() time.Duration {
+	if o.Interval.Time() > 0 {
+		return o.Interval.Time()
+	}
+
+	return DefaultInterval
+}
+
+func (o Config) getThreshold() uint8 {
+	if o.Threshold > 0 {
+		return o.Threshold
+	}
+
+	return DefaultThreshold
+}
+
+func (o Config) getCoolDown() time.Duration {
+	if o.CoolDown.Time() > 0 {
+		return o.CoolDown.Time()
+	}
+
+	return DefaultCoolDown
+}
+
+func (o Config) getRestartWindow() time.Duration {
+	if o.RestartWindow.Time() > 0 {
+		return o.RestartWindow.Time()
+	}
+
+	return DefaultRestartWindow
+}
+
+func (o Config) getRestartTimeout() time.Duration {
+	if o.RestartTimeout.Time() > 0 {
+		return o.RestartTimeout.Time()
+	}
+
+	return DefaultRestartTimeout
+}
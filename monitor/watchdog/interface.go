@@ -0,0 +1,161 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package watchdog
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	liberr "github.com/nabbar/golib/errors"
+	mongrp "github.com/nabbar/golib/monitor/group"
+	monsts "github.com/nabbar/golib/monitor/status"
+	libsrv "github.com/nabbar/golib/server"
+)
+
+// Source is the status provider supervised by the watchdog.
+// Any montps.Monitor and any mongrp.Group implement this interface.
+type Source interface {
+	mongrp.Source
+}
+
+type EventType uint8
+
+const (
+	// EventUnhealthy is sent each time an unhealthy status is counted.
+	EventUnhealthy EventType = iota
+	// EventRecover is sent when the source become healthy after at least one unhealthy status.
+	EventRecover
+	// EventRestart is sent after a restart of the bound server.
+	EventRestart
+	// EventCallback is sent after calling the callback action.
+	EventCallback
+	// EventEscalate is sent when the max restart limit is reached and the escalate action is called.
+	EventEscalate
+	// EventCoolDown is sent when the threshold is reached but the action is skipped because of the cool-down.
+	EventCoolDown
+)
+
+func (e EventType) String() string {
+	switch e {
+	case EventUnhealthy:
+		return "unhealthy"
+	case EventRecover:
+		return "recover"
+	case EventRestart:
+		return "restart"
+	case EventCallback:
+		return "callback"
+	case EventEscalate:
+		return "escalate"
+	case EventCoolDown:
+		return "cool-down"
+	default:
+		return "unknown"
+	}
+}
+
+type Event struct {
+	// Name is the name of the supervised source.
+	Name string
+	// Type is the type of event.
+	Type EventType
+	// Status is the last status of the source.
+	Status monsts.Status
+	// Message is the last message of the source.
+	Message string
+	// Failures is the number of consecutive unhealthy status.
+	Failures uint8
+	// Restarts is the number of action run into the current restart window.
+	Restarts uint8
+	// Error is the error returned by the action if any.
+	Error error
+	// Time is the time of the event.
+	Time time.Time
+}
+
+// FuncAction is an action called by the watchdog when the source is unhealthy.
+type FuncAction func(ctx context.Context, evt Event) error
+
+// FuncEvent is called for each event of the watchdog.
+type FuncEvent func(evt Event)
+
+type Watchdog interface {
+	libsrv.Server
+
+	// SetConfig is used to set or update the config of the watchdog.
+	SetConfig(cfg Config) liberr.Error
+
+	// GetConfig is used to retrieve the config of the watchdog.
+	GetConfig() Config
+
+	// RegisterServer define the server to restart when the threshold is reached.
+	RegisterServer(srv libsrv.Server)
+
+	// RegisterCallback define a function to call when the threshold is reached.
+	// The callback is called after the restart of the server if any.
+	RegisterCallback(fct FuncAction)
+
+	// RegisterEscalate define a function to call when the max restart limit is reached.
+	// The escalate action is called once until the source become healthy or Reset is called.
+	RegisterEscalate(fct FuncAction)
+
+	// RegisterEvent add a function called for each event of the watchdog.
+	RegisterEvent(fct FuncEvent)
+
+	// Failures return the current number of consecutive unhealthy status.
+	Failures() uint8
+
+	// Restarts return the number of action run into the current restart window.
+	Restarts() uint8
+
+	// IsEscalated return true if the escalate action has been called and the source is still unhealthy.
+	IsEscalated() bool
+
+	// Reset clean all counters and the escalated state.
+	Reset()
+
+	// Check run immediately one check of the source and the action if needed.
+	Check(ctx context.Context)
+}
+
+// New return a watchdog supervising the given source.
+func New(src Source, cfg Config) (Watchdog, liberr.Error) {
+	if src == nil {
+		return nil, ErrorParamEmpty.Error(nil)
+	} else if e := cfg.Validate(); e != nil {
+		return nil, e
+	}
+
+	return &wdg{
+		m: sync.RWMutex{},
+		s: src,
+		c: cfg,
+		e: make([]FuncEvent, 0),
+		h: make([]time.Time, 0),
+	}, nil
+}
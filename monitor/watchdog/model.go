@@ -0,0 +1,199 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package watchdog
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	liberr "github.com/nabbar/golib/errors"
+	monsts "github.com/nabbar/golib/monitor/status"
+	libsrv "github.com/nabbar/golib/server"
+	librun "github.com/nabbar/golib/server/runner/ticker"
+)
+
+type wdg struct {
+	m sync.RWMutex
+	s Source        // supervised source
+	c Config        // config
+	v libsrv.Server // server to restart
+	f FuncAction    // callback action
+	x FuncAction    // escalate action
+	e []FuncEvent   // event functions
+	n uint8         // consecutive failures
+	h []time.Time   // time of actions into the restart window
+	l time.Time     // time of last action
+	z bool          // escalated
+	r librun.Ticker // runner
+}
+
+func (o *wdg) SetConfig(cfg Config) liberr.Error {
+	if e := cfg.Validate(); e != nil {
+		return e
+	}
+
+	o.m.Lock()
+	o.c = cfg
+	o.m.Unlock()
+
+	if o.IsRunning() {
+		if e := o.Restart(context.Background()); e != nil {
+			return ErrorInvalid.Error(e)
+		}
+	}
+
+	return nil
+}
+
+func (o *wdg) GetConfig() Config {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	return o.c
+}
+
+func (o *wdg) RegisterServer(srv libsrv.Server) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.v = srv
+}
+
+func (o *wdg) RegisterCallback(fct FuncAction) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.f = fct
+}
+
+func (o *wdg) RegisterEscalate(fct FuncAction) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.x = fct
+}
+
+func (o *wdg) RegisterEvent(fct FuncEvent) {
+	if fct == nil {
+		return
+	}
+
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.e = append(o.e, fct)
+}
+
+func (o *wdg) Failures() uint8 {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	return o.n
+}
+
+func (o *wdg) Restarts() uint8 {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.cleanHistory(time.Now())
+	return uint8(len(o.h))
+}
+
+func (o *wdg) IsEscalated() bool {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	return o.z
+}
+
+func (o *wdg) Reset() {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.n = 0
+	o.h = make([]time.Time, 0)
+	o.l = time.Time{}
+	o.z = false
+}
+
+// cleanHistory remove actions out of the restart window. Must be called with the lock held.
+func (o *wdg) cleanHistory(now time.Time) {
+	var (
+		w = o.c.getRestartWindow()
+		h = make([]time.Time, 0, len(o.h))
+	)
+
+	for _, t := range o.h {
+		if now.Sub(t) < w {
+			h = append(h, t)
+		}
+	}
+
+	o.h = h
+}
+
+func (o *wdg) isUnhealthy(sts monsts.Status) bool {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	if sts == monsts.KO {
+		return true
+	}
+
+	return sts == monsts.Warn && o.c.WarnIsUnhealthy
+}
+
+func (o *wdg) newEvent(typ EventType, sts monsts.Status, msg string, err error) Event {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	return Event{
+		Name:     o.s.Name(),
+		Type:     typ,
+		Status:   sts,
+		Message:  msg,
+		Failures: o.n,
+		Restarts: uint8(len(o.h)),
+		Error:    err,
+		Time:     time.Now(),
+	}
+}
+
+func (o *wdg) sendEvent(evt Event) {
+	o.m.RLock()
+	var fct = make([]FuncEvent, len(o.e))
+	copy(fct, o.e)
+	o.m.RUnlock()
+
+	for _, f := range fct {
+		func() {
+			defer libsrv.RecoveryCaller("golib/monitor/watchdog", recover())
+			f(evt)
+		}()
+	}
+}
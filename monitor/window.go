@@ -0,0 +1,173 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package monitor
+
+import (
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	montps "github.com/nabbar/golib/monitor/types"
+)
+
+type sample struct {
+	t time.Time     // end time of the check
+	l time.Duration // latency of the check
+	s bool          // check succeeded
+}
+
+// window keeps the samples of checks for the longest rolling window.
+type window struct {
+	m sync.RWMutex
+	s []sample
+}
+
+func newWindow() *window {
+	return &window{
+		m: sync.RWMutex{},
+		s: make([]sample, 0),
+	}
+}
+
+func (w *window) add(lat time.Duration, ok bool) {
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	now := time.Now()
+	w.s = append(w.s, sample{t: now, l: lat, s: ok})
+
+	lim := now.Add(-montps.WindowHour)
+	idx := 0
+
+	for idx < len(w.s) && w.s[idx].t.Before(lim) {
+		idx++
+	}
+
+	if idx > 0 {
+		w.s = append(w.s[:0], w.s[idx:]...)
+	}
+}
+
+// collect return the sorted latencies and the number of succeeded checks run in the window.
+func (w *window) collect(win time.Duration) ([]time.Duration, int) {
+	w.m.RLock()
+	defer w.m.RUnlock()
+
+	lim := time.Now().Add(-win)
+	lat := make([]time.Duration, 0, len(w.s))
+	suc := 0
+
+	for _, s := range w.s {
+		if s.t.Before(lim) {
+			continue
+		}
+
+		lat = append(lat, s.l)
+
+		if s.s {
+			suc++
+		}
+	}
+
+	slices.Sort(lat)
+	return lat, suc
+}
+
+func (w *window) stats(win time.Duration) montps.WindowStats {
+	res := montps.WindowStats{
+		Window: win,
+	}
+
+	if win <= 0 {
+		return res
+	}
+
+	lat, suc := w.collect(win)
+
+	if len(lat) < 1 {
+		return res
+	}
+
+	res.Count = len(lat)
+	res.Success = float64(suc) / float64(len(lat))
+	res.P50 = percentile(lat, 50)
+	res.P90 = percentile(lat, 90)
+	res.P99 = percentile(lat, 99)
+
+	return res
+}
+
+// percentile return the nearest-rank percentile of a sorted list of latencies.
+func percentile(lat []time.Duration, pct float64) time.Duration {
+	if len(lat) < 1 {
+		return 0
+	}
+
+	idx := int(math.Ceil(pct/100*float64(len(lat)))) - 1
+
+	if idx < 0 {
+		idx = 0
+	} else if idx >= len(lat) {
+		idx = len(lat) - 1
+	}
+
+	return lat[idx]
+}
+
+func (o *mon) getWindow() *window {
+	if i, l := o.x.Load(keyWindow); l {
+		if v, k := i.(*window); k && v != nil {
+			return v
+		}
+	}
+
+	i, _ := o.x.LoadOrStore(keyWindow, newWindow())
+
+	if v, k := i.(*window); k && v != nil {
+		return v
+	}
+
+	return newWindow()
+}
+
+func (o *mon) Window(win time.Duration) montps.WindowStats {
+	return o.getWindow().stats(win)
+}
+
+func (o *mon) Windows() []montps.WindowStats {
+	var (
+		w = o.getWindow()
+		r = make([]montps.WindowStats, 0)
+	)
+
+	for _, d := range montps.Windows() {
+		r = append(r, w.stats(d))
+	}
+
+	return r
+}
@@ -29,7 +29,6 @@ package nats
 import (
 	"context"
 	"fmt"
-	"runtime"
 	"time"
 
 	libctx "github.com/nabbar/golib/context"
@@ -84,10 +83,9 @@ func (s *server) Monitor(ctx libctx.FuncContext, vrs libver.Version) (montps.Mon
 		return nil, fmt.Errorf("cannot load config")
 	}
 
-	res["runtime"] = runtime.Version()[2:]
-	res["release"] = vrs.GetRelease()
-	res["build"] = vrs.GetBuild()
-	res["date"] = vrs.GetDate()
+	for k, v := range vrs.GetInfoMap() {
+		res[k] = v
+	}
 	res["advertise"] = opt.ClientAdvertise
 
 	if inf, e = moninf.New(defaultNameMonitor); e != nil {
@@ -0,0 +1,249 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package resolver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	libval "github.com/go-playground/validator/v10"
+	libtls "github.com/nabbar/golib/certificates"
+	cfgcst "github.com/nabbar/golib/config/const"
+	libdur "github.com/nabbar/golib/duration"
+	liberr "github.com/nabbar/golib/errors"
+)
+
+const (
+	// SchemeUDP is a classic nameserver queried over udp, and over tcp for truncated answers.
+	SchemeUDP = "udp"
+	// SchemeTCP is a classic nameserver queried over tcp.
+	SchemeTCP = "tcp"
+	// SchemeTLS is a DNS over TLS nameserver (RFC 7858).
+	SchemeTLS = "tls"
+	// SchemeHTTPS is a DNS over HTTPS url (RFC 8484).
+	SchemeHTTPS = "https"
+
+	// DefaultTimeout is the maximum duration of one lookup if not defined.
+	DefaultTimeout = 5 * time.Second
+	// DefaultCacheEntries is the maximum number of hosts into the cache if not defined.
+	DefaultCacheEntries = 1024
+	// DefaultMaxTTL is the maximum duration a lookup is cached if not defined.
+	DefaultMaxTTL = time.Hour
+	// DefaultNegativeTTL is the duration a failed lookup (unknown host) is cached if not defined.
+	DefaultNegativeTTL = 30 * time.Second
+	// DefaultSystemTTL is the duration a lookup of the system resolver, without ttl, is cached if not defined.
+	DefaultSystemTTL = time.Minute
+)
+
+// CacheConfig define the cache of the lookups. The ttl of the answers is used, bounded by MinTTL and MaxTTL.
+type CacheConfig struct {
+	Enable bool `json:"enable" yaml:"enable" toml:"enable" mapstructure:"enable"`
+
+	// MaxEntries is the maximum number of hosts into the cache. If zero, DefaultCacheEntries is used.
+	MaxEntries int `json:"max-entries,omitempty" yaml:"max-entries,omitempty" toml:"max-entries,omitempty" mapstructure:"max-entries,omitempty" validate:"omitempty,min=0"`
+
+	// MinTTL is the minimum duration a lookup is cached, even with a lower ttl.
+	MinTTL libdur.Duration `json:"min-ttl,omitempty" yaml:"min-ttl,omitempty" toml:"min-ttl,omitempty" mapstructure:"min-ttl,omitempty" validate:"omitempty,min=0"`
+
+	// MaxTTL is the maximum duration a lookup is cached, even with a greater ttl. If zero, DefaultMaxTTL is used.
+	MaxTTL libdur.Duration `json:"max-ttl,omitempty" yaml:"max-ttl,omitempty" toml:"max-ttl,omitempty" mapstructure:"max-ttl,omitempty" validate:"omitempty,min=0"`
+
+	// NegativeTTL is the duration an unknown host is cached. If zero, DefaultNegativeTTL is used.
+	NegativeTTL libdur.Duration `json:"negative-ttl,omitempty" yaml:"negative-ttl,omitempty" toml:"negative-ttl,omitempty" mapstructure:"negative-ttl,omitempty" validate:"omitempty,min=0"`
+
+	// SystemTTL is the duration a lookup of the system resolver is cached, as it gives no ttl.
+	// If zero, DefaultSystemTTL is used.
+	SystemTTL libdur.Duration `json:"system-ttl,omitempty" yaml:"system-ttl,omitempty" toml:"system-ttl,omitempty" mapstructure:"system-ttl,omitempty" validate:"omitempty,min=0"`
+}
+
+// Config define the upstreams, timeout and cache of a Resolver.
+type Config struct {
+	// Upstreams is the ordered list of the nameservers, the next one is used if one fails.
+	// Each upstream is a classic nameserver as "ip:port" or "udp://ip:port", "tcp://ip:port",
+	// a DNS over TLS nameserver as "tls://host:port", or a DNS over HTTPS url as "https://host/dns-query".
+	// If empty, the system resolver is used.
+	Upstreams []string `json:"upstreams,omitempty" yaml:"upstreams,omitempty" toml:"upstreams,omitempty" mapstructure:"upstreams,omitempty"`
+
+	// Timeout is the maximum duration of one lookup, all upstreams included. If zero, DefaultTimeout is used.
+	Timeout libdur.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty" toml:"timeout,omitempty" mapstructure:"timeout,omitempty" validate:"omitempty,min=0"`
+
+	// TLS is the tls config used by the DNS over TLS and DNS over HTTPS upstreams.
+	TLS libtls.Config `json:"tls,omitempty" yaml:"tls,omitempty" toml:"tls,omitempty" mapstructure:"tls,omitempty"`
+
+	// Cache define the cache of the lookups.
+	Cache CacheConfig `json:"cache" yaml:"cache" toml:"cache" mapstructure:"cache"`
+}
+
+func DefaultConfig(indent string) []byte {
+	var (
+		res = bytes.NewBuffer(make([]byte, 0))
+		def = []byte(`{
+  "upstreams": [
+    "tls://1.1.1.1:853",
+    "https://dns.google/dns-query",
+    "9.9.9.9:53"
+  ],
+  "timeout": "5s",
+  "cache": {
+    "enable": true,
+    "max-entries": 1024,
+    "min-ttl": "0s",
+    "max-ttl": "1h",
+    "negative-ttl": "30s",
+    "system-ttl": "1m"
+  }
+}`)
+	)
+	if err := json.Indent(res, def, indent, cfgcst.JSONIndent); err != nil {
+		return def
+	} else {
+		return res.Bytes()
+	}
+}
+
+func (o Config) Validate() liberr.Error {
+	var e = ErrorValidatorError.Error(nil)
+
+	if err := libval.New().Struct(o); err != nil {
+		if er, ok := err.(*libval.InvalidValidationError); ok {
+			e.Add(er)
+		}
+
+		for _, er := range err.(libval.ValidationErrors) {
+			//nolint #goerr113
+			e.Add(fmt.Errorf("config field '%s' is not validated by constraint '%s'", er.Namespace(), er.ActualTag()))
+		}
+	}
+
+	for _, u := range o.Upstreams {
+		if _, err := parseUpstream(u); err != nil {
+			e.Add(err)
+		}
+	}
+
+	if o.Cache.MaxTTL > 0 && o.Cache.MinTTL > o.Cache.MaxTTL {
+		//nolint #goerr113
+		e.Add(fmt.Errorf("config field 'Config.Cache.MinTTL' must be lower than 'Config.Cache.MaxTTL'"))
+	}
+
+	if !e.HasParent() {
+		e = nil
+	}
+
+	return e
+}
+
+// normalize return the config with the default values applied.
+func (o Config) normalize() Config {
+	if o.Timeout <= 0 {
+		o.Timeout = libdur.ParseDuration(DefaultTimeout)
+	}
+
+	if o.Cache.MaxEntries < 1 {
+		o.Cache.MaxEntries = DefaultCacheEntries
+	}
+
+	if o.Cache.MaxTTL <= 0 {
+		o.Cache.MaxTTL = libdur.ParseDuration(DefaultMaxTTL)
+	}
+
+	if o.Cache.MinTTL > o.Cache.MaxTTL {
+		o.Cache.MinTTL = o.Cache.MaxTTL
+	}
+
+	if o.Cache.NegativeTTL <= 0 {
+		o.Cache.NegativeTTL = libdur.ParseDuration(DefaultNegativeTTL)
+	}
+
+	if o.Cache.SystemTTL <= 0 {
+		o.Cache.SystemTTL = libdur.ParseDuration(DefaultSystemTTL)
+	}
+
+	return o
+}
+
+// upstream is a parsed nameserver.
+type upstream struct {
+	s string // scheme
+	a string // address host:port, or url for https
+	h string // server name for tls
+}
+
+func (u upstream) String() string {
+	if u.s == SchemeHTTPS {
+		return u.a
+	}
+
+	return u.s + "://" + u.a
+}
+
+func parseUpstream(s string) (upstream, error) {
+	var (
+		res upstream
+		def = "53"
+	)
+
+	s = strings.TrimSpace(s)
+
+	if !strings.Contains(s, "://") {
+		s = SchemeUDP + "://" + s
+	}
+
+	u, e := url.Parse(s)
+
+	if e != nil {
+		return res, ErrorUpstreamInvalid.Error(e)
+	} else if len(u.Hostname()) < 1 {
+		return res, ErrorUpstreamInvalid.Error(fmt.Errorf("missing host into upstream '%s'", s))
+	}
+
+	res.s = strings.ToLower(u.Scheme)
+	res.h = u.Hostname()
+
+	switch res.s {
+	case SchemeHTTPS:
+		res.a = u.String()
+		return res, nil
+	case SchemeTLS:
+		def = "853"
+	case SchemeUDP, SchemeTCP:
+	default:
+		return res, ErrorUpstreamInvalid.Error(fmt.Errorf("unknown scheme '%s' into upstream '%s'", u.Scheme, s))
+	}
+
+	if p := u.Port(); len(p) > 0 {
+		res.a = net.JoinHostPort(res.h, p)
+	} else {
+		res.a = net.JoinHostPort(res.h, def)
+	}
+
+	return res, nil
+}
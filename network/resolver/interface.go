@@ -0,0 +1,92 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package resolver
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	libcch "github.com/nabbar/golib/cache"
+	"golang.org/x/sync/singleflight"
+)
+
+// Resolver resolve host names with its own upstreams, a ttl respecting cache and a timeout by lookup.
+// A Resolver is safe for concurrent use and can be shared between several clients.
+type Resolver interface {
+	// LookupIP return the ip addresses of the given host, the IPv4 addresses first.
+	// An ip address is returned as is. The "localhost" names are resolved to the loopback addresses.
+	LookupIP(ctx context.Context, host string) ([]net.IP, error)
+
+	// LookupHost return the ip addresses of the given host as strings, like net.Resolver.LookupHost.
+	LookupHost(ctx context.Context, host string) ([]string, error)
+
+	// DialContext return a dial function resolving the host of the address with this resolver, then
+	// dialing each address with the given dialer until one succeed. If the dialer is nil, a default one is used.
+	// The function can be used as the DialContext of a http.Transport.
+	DialContext(d *net.Dialer) func(ctx context.Context, network, address string) (net.Conn, error)
+
+	// Clean remove all the lookups from the cache.
+	Clean()
+
+	// Stats return the counters of the cache of the lookups.
+	Stats() libcch.Stats
+}
+
+// New return a Resolver using the upstreams, timeout and cache of the given config.
+// The host of a DNS over HTTPS url is resolved with the system resolver.
+func New(cfg Config) (Resolver, error) {
+	if e := cfg.Validate(); e != nil {
+		return nil, e
+	}
+
+	cfg = cfg.normalize()
+
+	var r = &rsv{
+		c: cfg,
+		u: make([]upstream, 0, len(cfg.Upstreams)),
+		k: libcch.NewTyped[string, *item](libcch.Options{
+			MaxEntries: cfg.Cache.MaxEntries,
+			Policy:     libcch.PolicyLRU,
+		}),
+		g: singleflight.Group{},
+	}
+
+	for _, s := range cfg.Upstreams {
+		u, _ := parseUpstream(s)
+		r.u = append(r.u, u)
+	}
+
+	r.h = &http.Client{
+		Transport: &http.Transport{
+			Proxy:             http.ProxyFromEnvironment,
+			TLSClientConfig:   r.tlsConfig(""),
+			ForceAttemptHTTP2: true,
+		},
+	}
+
+	return r, nil
+}
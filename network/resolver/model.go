@@ -0,0 +1,341 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package resolver
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	libcch "github.com/nabbar/golib/cache"
+	"golang.org/x/net/dns/dnsmessage"
+	"golang.org/x/sync/singleflight"
+)
+
+// item is a cached lookup.
+type item struct {
+	ip []net.IP
+	nx bool // unknown host
+}
+
+type rsv struct {
+	c Config
+	u []upstream
+	h *http.Client
+	k libcch.Typed[string, *item] // cache by host
+	g singleflight.Group
+}
+
+func (o *rsv) tlsConfig(serverName string) *tls.Config {
+	if t := o.c.TLS.New(); t != nil {
+		return t.TlsConfig(serverName)
+	}
+
+	// #nosec
+	return &tls.Config{
+		ServerName: serverName,
+		MinVersion: tls.VersionTLS12,
+	}
+}
+
+func (o *rsv) Clean() {
+	o.k.Clean()
+}
+
+func (o *rsv) Stats() libcch.Stats {
+	return o.k.Stats()
+}
+
+func (o *rsv) LookupHost(ctx context.Context, host string) ([]string, error) {
+	l, e := o.LookupIP(ctx, host)
+
+	if e != nil {
+		return nil, e
+	}
+
+	var res = make([]string, 0, len(l))
+
+	for _, i := range l {
+		res = append(res, i.String())
+	}
+
+	return res, nil
+}
+
+func (o *rsv) LookupIP(ctx context.Context, host string) ([]net.IP, error) {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+
+	if len(host) < 1 {
+		return nil, ErrorParamEmpty.Error(nil)
+	} else if i := net.ParseIP(host); i != nil {
+		return []net.IP{i}, nil
+	} else if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback}, nil
+	}
+
+	if i := o.load(host); i != nil {
+		return i.result(host)
+	}
+
+	// concurrent lookups of the same host share the same query, with their own cancellation
+	var c = o.g.DoChan(host, func() (interface{}, error) {
+		x, n := context.WithTimeout(context.WithoutCancel(ctx), o.c.Timeout.Time())
+		defer n()
+
+		return o.lookup(x, host)
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case r := <-c:
+		if r.Err != nil {
+			return nil, r.Err
+		}
+
+		return r.Val.(*item).result(host)
+	}
+}
+
+func (i *item) result(host string) ([]net.IP, error) {
+	if i.nx || len(i.ip) < 1 {
+		return nil, &net.DNSError{Err: errNotFound.Error(), Name: host, IsNotFound: true}
+	}
+
+	var res = make([]net.IP, len(i.ip))
+	copy(res, i.ip)
+
+	return res, nil
+}
+
+func (o *rsv) load(host string) *item {
+	if !o.c.Cache.Enable {
+		return nil
+	}
+
+	if i, k := o.k.Get(host); k {
+		return i
+	}
+
+	return nil
+}
+
+func (o *rsv) store(host string, i *item, ttl time.Duration) {
+	if !o.c.Cache.Enable {
+		return
+	}
+
+	if !i.nx {
+		if m := o.c.Cache.MinTTL.Time(); ttl < m {
+			ttl = m
+		}
+
+		if m := o.c.Cache.MaxTTL.Time(); ttl > m {
+			ttl = m
+		}
+	}
+
+	if ttl <= 0 {
+		return
+	}
+
+	o.k.SetTTL(host, i, ttl)
+}
+
+// lookup resolve the host with the system resolver or with each upstream until one answer.
+func (o *rsv) lookup(ctx context.Context, host string) (*item, error) {
+	if len(o.u) < 1 {
+		l, e := net.DefaultResolver.LookupIPAddr(ctx, host)
+
+		var d *net.DNSError
+		if errors.As(e, &d) && d.IsNotFound {
+			i := &item{nx: true}
+			o.store(host, i, o.c.Cache.NegativeTTL.Time())
+			return i, nil
+		} else if e != nil {
+			return nil, ErrorLookup.Error(e)
+		}
+
+		var i = &item{ip: make([]net.IP, 0, len(l))}
+
+		for _, a := range l {
+			i.ip = append(i.ip, a.IP)
+		}
+
+		i.ip = sortIP(i.ip)
+		o.store(host, i, o.c.Cache.SystemTTL.Time())
+
+		return i, nil
+	}
+
+	var err = ErrorLookup.Error(nil)
+
+	for _, u := range o.u {
+		a, e := o.query(ctx, u, host)
+
+		if e != nil {
+			err.Add(e)
+
+			if ctx.Err() != nil {
+				break
+			}
+
+			continue
+		}
+
+		var i = &item{ip: a.ip, nx: a.nx || len(a.ip) < 1}
+
+		if i.nx {
+			o.store(host, i, o.c.Cache.NegativeTTL.Time())
+		} else {
+			o.store(host, i, a.ttl)
+		}
+
+		return i, nil
+	}
+
+	return nil, err
+}
+
+// query send the A and AAAA queries in parallel to the upstream and merge their answers.
+func (o *rsv) query(ctx context.Context, u upstream, host string) (answer, error) {
+	type rsp struct {
+		a answer
+		e error
+	}
+
+	var (
+		c4 = make(chan rsp, 1)
+		c6 = make(chan rsp, 1)
+	)
+
+	go func() {
+		a, e := o.exchange(ctx, u, host, dnsmessage.TypeA)
+		c4 <- rsp{a: a, e: e}
+	}()
+
+	go func() {
+		a, e := o.exchange(ctx, u, host, dnsmessage.TypeAAAA)
+		c6 <- rsp{a: a, e: e}
+	}()
+
+	var (
+		r4  = <-c4
+		r6  = <-c6
+		res answer
+	)
+
+	if r4.e != nil && r6.e != nil {
+		return res, r4.e
+	} else if r4.a.nx || r6.a.nx {
+		res.nx = true
+		return res, nil
+	}
+
+	// one family failing is ignored when the other one answer
+	for _, r := range []rsp{r4, r6} {
+		if r.e != nil || len(r.a.ip) < 1 {
+			continue
+		} else if len(res.ip) < 1 || r.a.ttl < res.ttl {
+			res.ttl = r.a.ttl
+		}
+
+		res.ip = append(res.ip, r.a.ip...)
+	}
+
+	return res, nil
+}
+
+// sortIP return the IPv4 addresses first, then the IPv6 addresses.
+func sortIP(l []net.IP) []net.IP {
+	var res = make([]net.IP, 0, len(l))
+
+	for _, i := range l {
+		if i.To4() != nil {
+			res = append(res, i)
+		}
+	}
+
+	for _, i := range l {
+		if i.To4() == nil {
+			res = append(res, i)
+		}
+	}
+
+	return res
+}
+
+func (o *rsv) DialContext(d *net.Dialer) func(ctx context.Context, network, address string) (net.Conn, error) {
+	if d == nil {
+		d = &net.Dialer{}
+	}
+
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		host, port, e := net.SplitHostPort(address)
+
+		if e != nil {
+			return nil, e
+		}
+
+		l, e := o.LookupIP(ctx, host)
+
+		if e != nil {
+			return nil, e
+		}
+
+		var err error
+
+		for _, i := range l {
+			if strings.HasSuffix(network, "4") && i.To4() == nil {
+				continue
+			} else if strings.HasSuffix(network, "6") && i.To4() != nil {
+				continue
+			}
+
+			c, er := d.DialContext(ctx, network, net.JoinHostPort(i.String(), port))
+
+			if er == nil {
+				return c, nil
+			}
+
+			err = er
+
+			if ctx.Err() != nil {
+				break
+			}
+		}
+
+		if err == nil {
+			err = &net.DNSError{Err: "no address of the network " + network, Name: host, IsNotFound: true}
+		}
+
+		return nil, err
+	}
+}
@@ -0,0 +1,284 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package resolver_test
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/pem"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	libtls "github.com/nabbar/golib/certificates"
+	tlscas "github.com/nabbar/golib/certificates/ca"
+	libdur "github.com/nabbar/golib/duration"
+	libres "github.com/nabbar/golib/network/resolver"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// answer return the response of the given query: "known.test." has 10.0.0.1 with a ttl of 60s, other hosts are unknown.
+func answer(req []byte) []byte {
+	var p dnsmessage.Parser
+
+	h, err := p.Start(req)
+	Expect(err).ToNot(HaveOccurred())
+
+	q, err := p.Question()
+	Expect(err).ToNot(HaveOccurred())
+
+	var (
+		b = dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: h.ID, Response: true, RCode: dnsmessage.RCodeSuccess})
+		k = q.Name.String() == "known.test."
+	)
+
+	if !k {
+		b = dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: h.ID, Response: true, RCode: dnsmessage.RCodeNameError})
+	}
+
+	Expect(b.StartQuestions()).To(Succeed())
+	Expect(b.Question(q)).To(Succeed())
+	Expect(b.StartAnswers()).To(Succeed())
+
+	if k && q.Type == dnsmessage.TypeA {
+		Expect(b.AResource(dnsmessage.ResourceHeader{Name: q.Name, Class: dnsmessage.ClassINET, TTL: 60},
+			dnsmessage.AResource{A: [4]byte{10, 0, 0, 1}})).To(Succeed())
+	}
+
+	res, err := b.Finish()
+	Expect(err).ToNot(HaveOccurred())
+
+	return res
+}
+
+func serveUDP(cnt *atomic.Int32) (string, func()) {
+	con, err := net.ListenPacket("udp", "127.0.0.1:0")
+	Expect(err).ToNot(HaveOccurred())
+
+	go func() {
+		defer GinkgoRecover()
+
+		var buf = make([]byte, 1500)
+
+		for {
+			n, adr, e := con.ReadFrom(buf)
+			if e != nil {
+				return
+			}
+
+			cnt.Add(1)
+			_, _ = con.WriteTo(answer(buf[:n]), adr)
+		}
+	}()
+
+	return con.LocalAddr().String(), func() {
+		_ = con.Close()
+	}
+}
+
+func serveTCP(cnt *atomic.Int32) (string, func()) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).ToNot(HaveOccurred())
+
+	go func() {
+		defer GinkgoRecover()
+
+		for {
+			con, e := lis.Accept()
+			if e != nil {
+				return
+			}
+
+			var l = make([]byte, 2)
+			if _, e = io.ReadFull(con, l); e == nil {
+				var req = make([]byte, binary.BigEndian.Uint16(l))
+				if _, e = io.ReadFull(con, req); e == nil {
+					cnt.Add(1)
+					res := answer(req)
+					binary.BigEndian.PutUint16(l, uint16(len(res)))
+					_, _ = con.Write(append(l, res...))
+				}
+			}
+
+			_ = con.Close()
+		}
+	}()
+
+	return lis.Addr().String(), func() {
+		_ = lis.Close()
+	}
+}
+
+var _ = Describe("network/resolver", func() {
+	var (
+		cnt atomic.Int32
+		ctx context.Context
+		cnl context.CancelFunc
+	)
+
+	BeforeEach(func() {
+		cnt.Store(0)
+		ctx, cnl = context.WithTimeout(context.Background(), 10*time.Second)
+	})
+
+	AfterEach(func() {
+		cnl()
+	})
+
+	Context("Config", func() {
+		It("must validate the default config", func() {
+			Expect(libres.DefaultConfig("")).ToNot(BeEmpty())
+			Expect(libres.Config{Upstreams: []string{"9.9.9.9", "tcp://9.9.9.9", "tls://1.1.1.1", "https://dns.google/dns-query"}}.Validate()).To(BeNil())
+		})
+
+		It("must reject an invalid upstream", func() {
+			Expect(libres.Config{Upstreams: []string{"ftp://1.1.1.1"}}.Validate()).ToNot(BeNil())
+			Expect(libres.Config{Upstreams: []string{"udp://"}}.Validate()).ToNot(BeNil())
+		})
+	})
+
+	It("must resolve the literal and localhost names without upstream", func() {
+		r, err := libres.New(libres.Config{Upstreams: []string{"127.0.0.1:1"}})
+		Expect(err).ToNot(HaveOccurred())
+
+		l, err := r.LookupHost(ctx, "192.168.1.1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(l).To(Equal([]string{"192.168.1.1"}))
+
+		l, err = r.LookupHost(ctx, "localhost")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(l).To(ContainElement("127.0.0.1"))
+	})
+
+	It("must resolve with a classic nameserver and cache the answer", func() {
+		adr, cls := serveUDP(&cnt)
+		defer cls()
+
+		r, err := libres.New(libres.Config{
+			Upstreams: []string{adr},
+			Cache:     libres.CacheConfig{Enable: true},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		l, err := r.LookupHost(ctx, "known.test")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(l).To(Equal([]string{"10.0.0.1"}))
+		Expect(cnt.Load()).To(BeEquivalentTo(2))
+
+		_, err = r.LookupHost(ctx, "known.test")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cnt.Load()).To(BeEquivalentTo(2))
+
+		_, err = r.LookupHost(ctx, "unknown.test")
+		Expect(err).To(HaveOccurred())
+
+		var dns *net.DNSError
+		Expect(err).To(BeAssignableToTypeOf(dns))
+		Expect(err.(*net.DNSError).IsNotFound).To(BeTrue())
+
+		_, err = r.LookupHost(ctx, "unknown.test")
+		Expect(err).To(HaveOccurred())
+		Expect(cnt.Load()).To(BeEquivalentTo(4))
+
+		r.Clean()
+		_, err = r.LookupHost(ctx, "known.test")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cnt.Load()).To(BeEquivalentTo(6))
+	})
+
+	It("must use the next upstream when one fails", func() {
+		adr, cls := serveTCP(&cnt)
+		defer cls()
+
+		r, err := libres.New(libres.Config{
+			Upstreams: []string{"tcp://127.0.0.1:1", "tcp://" + adr},
+			Timeout:   libdur.ParseDuration(2 * time.Second),
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		l, err := r.LookupHost(ctx, "known.test")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(l).To(Equal([]string{"10.0.0.1"}))
+	})
+
+	It("must resolve with a DNS over HTTPS upstream", func() {
+		srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+
+			Expect(r.Method).To(Equal(http.MethodPost))
+			Expect(r.Header.Get("Content-Type")).To(Equal("application/dns-message"))
+
+			req, err := io.ReadAll(r.Body)
+			Expect(err).ToNot(HaveOccurred())
+
+			cnt.Add(1)
+			w.Header().Set("Content-Type", "application/dns-message")
+			_, _ = w.Write(answer(req))
+		}))
+		defer srv.Close()
+
+		ca, err := tlscas.Parse(string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})))
+		Expect(err).ToNot(HaveOccurred())
+
+		r, err := libres.New(libres.Config{
+			Upstreams: []string{srv.URL + "/dns-query"},
+			TLS:       libtls.Config{RootCA: []tlscas.Cert{ca}},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		l, err := r.LookupHost(ctx, "known.test")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(l).To(Equal([]string{"10.0.0.1"}))
+		Expect(cnt.Load()).To(BeEquivalentTo(2))
+	})
+
+	It("must dial the resolved address", func() {
+		adr, cls := serveUDP(&cnt)
+		defer cls()
+
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).ToNot(HaveOccurred())
+		defer func() {
+			_ = lis.Close()
+		}()
+
+		r, err := libres.New(libres.Config{Upstreams: []string{adr}})
+		Expect(err).ToNot(HaveOccurred())
+
+		_, prt, _ := net.SplitHostPort(lis.Addr().String())
+		con, err := r.DialContext(nil)(ctx, "tcp", net.JoinHostPort("localhost", prt))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(con.Close()).To(Succeed())
+
+		_, err = r.DialContext(nil)(ctx, "tcp", net.JoinHostPort("unknown.test", prt))
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,315 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package resolver
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const (
+	// udpSize is the EDNS0 buffer size announced over udp (DNS flag day 2020).
+	udpSize = 1232
+	// maxMessage is the maximum size of a dns message over tcp, tls and https.
+	maxMessage = 65535
+	// mimeDNS is the content type of the DNS over HTTPS messages.
+	mimeDNS = "application/dns-message"
+)
+
+var (
+	errNotFound  = errors.New("no such host")
+	errTruncated = errors.New("truncated answer")
+)
+
+// answer is the result of one query.
+type answer struct {
+	ip  []net.IP
+	ttl time.Duration
+	nx  bool // the host does not exist
+}
+
+// query return a new dns query of the given type for the given host.
+func query(host string, typ dnsmessage.Type, edns bool) (uint16, []byte, error) {
+	var r = make([]byte, 2)
+
+	if _, e := rand.Read(r); e != nil {
+		return 0, nil, e
+	}
+
+	var (
+		id = binary.BigEndian.Uint16(r)
+		b  = dnsmessage.NewBuilder(make([]byte, 0, 512), dnsmessage.Header{
+			ID:               id,
+			RecursionDesired: true,
+		})
+	)
+
+	b.EnableCompression()
+
+	n, e := dnsmessage.NewName(strings.TrimSuffix(host, ".") + ".")
+
+	if e != nil {
+		return 0, nil, e
+	} else if e = b.StartQuestions(); e != nil {
+		return 0, nil, e
+	} else if e = b.Question(dnsmessage.Question{Name: n, Type: typ, Class: dnsmessage.ClassINET}); e != nil {
+		return 0, nil, e
+	}
+
+	if edns {
+		var h dnsmessage.ResourceHeader
+
+		if e = h.SetEDNS0(udpSize, dnsmessage.RCodeSuccess, false); e != nil {
+			return 0, nil, e
+		} else if e = b.StartAdditionals(); e != nil {
+			return 0, nil, e
+		} else if e = b.OPTResource(h, dnsmessage.OPTResource{}); e != nil {
+			return 0, nil, e
+		}
+	}
+
+	m, e := b.Finish()
+	return id, m, e
+}
+
+// parse return the addresses of the given type of a dns response.
+func parse(id uint16, msg []byte, typ dnsmessage.Type) (answer, error) {
+	var (
+		p   dnsmessage.Parser
+		res answer
+	)
+
+	h, e := p.Start(msg)
+
+	if e != nil {
+		return res, e
+	} else if h.ID != id || !h.Response {
+		return res, fmt.Errorf("unexpected dns response id")
+	} else if h.Truncated {
+		return res, errTruncated
+	} else if h.RCode == dnsmessage.RCodeNameError {
+		res.nx = true
+		return res, nil
+	} else if h.RCode != dnsmessage.RCodeSuccess {
+		return res, fmt.Errorf("dns response code %s", h.RCode.String())
+	} else if e = p.SkipAllQuestions(); e != nil {
+		return res, e
+	}
+
+	for {
+		r, err := p.AnswerHeader()
+
+		if errors.Is(err, dnsmessage.ErrSectionDone) {
+			break
+		} else if err != nil {
+			return res, err
+		}
+
+		if r.Type != typ || r.Class != dnsmessage.ClassINET {
+			if err = p.SkipAnswer(); err != nil {
+				return res, err
+			}
+			continue
+		}
+
+		switch typ {
+		case dnsmessage.TypeA:
+			a, er := p.AResource()
+			if er != nil {
+				return res, er
+			}
+			res.ip = append(res.ip, net.IP(a.A[:]))
+		case dnsmessage.TypeAAAA:
+			a, er := p.AAAAResource()
+			if er != nil {
+				return res, er
+			}
+			res.ip = append(res.ip, net.IP(a.AAAA[:]))
+		}
+
+		if t := time.Duration(r.TTL) * time.Second; len(res.ip) == 1 || t < res.ttl {
+			res.ttl = t
+		}
+	}
+
+	return res, nil
+}
+
+// exchange send the query of the given type to the upstream and return its answer.
+func (o *rsv) exchange(ctx context.Context, u upstream, host string, typ dnsmessage.Type) (answer, error) {
+	var (
+		id  uint16
+		msg []byte
+		res []byte
+		err error
+	)
+
+	if id, msg, err = query(host, typ, u.s == SchemeUDP); err != nil {
+		return answer{}, err
+	}
+
+	switch u.s {
+	case SchemeUDP:
+		if res, err = o.sendUDP(ctx, u.a, msg); err == nil {
+			if a, e := parse(id, res, typ); !errors.Is(e, errTruncated) {
+				return a, e
+			}
+		}
+
+		// truncated or failed over udp, retry over tcp without edns
+		if id, msg, err = query(host, typ, false); err != nil {
+			return answer{}, err
+		}
+
+		res, err = o.sendStream(ctx, u, msg)
+	case SchemeTCP, SchemeTLS:
+		res, err = o.sendStream(ctx, u, msg)
+	case SchemeHTTPS:
+		res, err = o.sendHTTPS(ctx, u, msg)
+	}
+
+	if err != nil {
+		return answer{}, err
+	}
+
+	return parse(id, res, typ)
+}
+
+func (o *rsv) sendUDP(ctx context.Context, adr string, msg []byte) ([]byte, error) {
+	var d net.Dialer
+
+	c, e := d.DialContext(ctx, "udp", adr)
+
+	if e != nil {
+		return nil, e
+	}
+
+	defer func() {
+		_ = c.Close()
+	}()
+
+	if t, k := ctx.Deadline(); k {
+		_ = c.SetDeadline(t)
+	}
+
+	if _, e = c.Write(msg); e != nil {
+		return nil, e
+	}
+
+	var b = make([]byte, udpSize)
+
+	n, e := c.Read(b)
+
+	if e != nil {
+		return nil, e
+	}
+
+	return b[:n], nil
+}
+
+// sendStream send the message with its length prefix over tcp or tls (RFC 1035 section 4.2.2).
+func (o *rsv) sendStream(ctx context.Context, u upstream, msg []byte) ([]byte, error) {
+	var (
+		c net.Conn
+		e error
+		d = &net.Dialer{}
+	)
+
+	if u.s == SchemeTLS {
+		c, e = (&tls.Dialer{NetDialer: d, Config: o.tlsConfig(u.h)}).DialContext(ctx, "tcp", u.a)
+	} else {
+		c, e = d.DialContext(ctx, "tcp", u.a)
+	}
+
+	if e != nil {
+		return nil, e
+	}
+
+	defer func() {
+		_ = c.Close()
+	}()
+
+	if t, k := ctx.Deadline(); k {
+		_ = c.SetDeadline(t)
+	}
+
+	var b = make([]byte, 2, len(msg)+2)
+	binary.BigEndian.PutUint16(b, uint16(len(msg)))
+
+	if _, e = c.Write(append(b, msg...)); e != nil {
+		return nil, e
+	} else if _, e = io.ReadFull(c, b[:2]); e != nil {
+		return nil, e
+	}
+
+	var r = make([]byte, binary.BigEndian.Uint16(b[:2]))
+
+	if _, e = io.ReadFull(c, r); e != nil {
+		return nil, e
+	}
+
+	return r, nil
+}
+
+// sendHTTPS post the message to the DNS over HTTPS url (RFC 8484 section 4.1).
+func (o *rsv) sendHTTPS(ctx context.Context, u upstream, msg []byte) ([]byte, error) {
+	req, e := http.NewRequestWithContext(ctx, http.MethodPost, u.a, bytes.NewReader(msg))
+
+	if e != nil {
+		return nil, e
+	}
+
+	req.Header.Set("Content-Type", mimeDNS)
+	req.Header.Set("Accept", mimeDNS)
+
+	res, e := o.h.Do(req)
+
+	if e != nil {
+		return nil, e
+	}
+
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("dns over https response status %s", res.Status)
+	}
+
+	return io.ReadAll(io.LimitReader(res.Body, maxMessage))
+}
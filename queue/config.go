@@ -0,0 +1,143 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package queue
+
+import (
+	"fmt"
+	"time"
+
+	libval "github.com/go-playground/validator/v10"
+	libdur "github.com/nabbar/golib/duration"
+	quekfk "github.com/nabbar/golib/queue/kafka"
+	quemem "github.com/nabbar/golib/queue/memory"
+	quenat "github.com/nabbar/golib/queue/nats"
+	quetps "github.com/nabbar/golib/queue/types"
+)
+
+const (
+	DriverNats   = "nats"
+	DriverKafka  = "kafka"
+	DriverMemory = "memory"
+
+	// DefaultRetryDelay is the default delay before the first redelivery of a message not processed.
+	DefaultRetryDelay = time.Second
+	// DefaultRetryMaxDelay is the default maximum delay between two redeliveries of a message not processed.
+	DefaultRetryMaxDelay = time.Minute
+)
+
+type Config struct {
+	// Driver is the backend of the queue, one of 'nats', 'kafka' or 'memory'.
+	Driver string `json:"driver" yaml:"driver" toml:"driver" mapstructure:"driver" validate:"required,oneof=nats kafka memory"`
+
+	// Nats is the config of the nats jetstream backend, used only with the 'nats' driver.
+	Nats quenat.Config `json:"nats,omitempty" yaml:"nats,omitempty" toml:"nats,omitempty" mapstructure:"nats,omitempty" validate:"-"`
+
+	// Kafka is the config of the kafka backend, used only with the 'kafka' driver.
+	Kafka quekfk.Config `json:"kafka,omitempty" yaml:"kafka,omitempty" toml:"kafka,omitempty" mapstructure:"kafka,omitempty" validate:"-"`
+
+	// Memory is the config of the in memory backend, used only with the 'memory' driver.
+	Memory quemem.Config `json:"memory,omitempty" yaml:"memory,omitempty" toml:"memory,omitempty" mapstructure:"memory,omitempty"`
+
+	// Concurrency is the number of messages processed in parallel by each subscription. By default, 1.
+	Concurrency int `json:"concurrency,omitempty" yaml:"concurrency,omitempty" toml:"concurrency,omitempty" mapstructure:"concurrency,omitempty" validate:"gte=0"`
+
+	// MaxDeliver is the maximum number of deliveries of a message not processed before sending it
+	// to the dead letter topic (or dropping it if no dead letter topic is defined). By default, unlimited.
+	MaxDeliver int `json:"max-deliver,omitempty" yaml:"max-deliver,omitempty" toml:"max-deliver,omitempty" mapstructure:"max-deliver,omitempty" validate:"gte=0"`
+
+	// RetryDelay is the delay before the first redelivery of a message not processed,
+	// doubled on each new delivery. By default, 1s.
+	RetryDelay libdur.Duration `json:"retry-delay,omitempty" yaml:"retry-delay,omitempty" toml:"retry-delay,omitempty" mapstructure:"retry-delay,omitempty"`
+
+	// RetryMaxDelay is the maximum delay between two redeliveries of a message not processed. By default, 1m.
+	RetryMaxDelay libdur.Duration `json:"retry-max-delay,omitempty" yaml:"retry-max-delay,omitempty" toml:"retry-max-delay,omitempty" mapstructure:"retry-max-delay,omitempty"`
+
+	// DeadLetter is the topic receiving the messages reaching the max deliver.
+	DeadLetter string `json:"dead-letter,omitempty" yaml:"dead-letter,omitempty" toml:"dead-letter,omitempty" mapstructure:"dead-letter,omitempty"`
+}
+
+func (c Config) Validate() error {
+	err := ErrorValidatorError.Error(nil)
+
+	if er := libval.New().Struct(c); er != nil {
+		if e, ok := er.(*libval.InvalidValidationError); ok {
+			err.Add(e)
+		}
+
+		for _, e := range er.(libval.ValidationErrors) {
+			//nolint goerr113
+			err.Add(fmt.Errorf("config field '%s' is not validated by constraint '%s'", e.Namespace(), e.ActualTag()))
+		}
+	}
+
+	switch c.Driver {
+	case DriverNats:
+		if e := c.Nats.Validate(); e != nil {
+			err.Add(e)
+		}
+	case DriverKafka:
+		if e := c.Kafka.Validate(); e != nil {
+			err.Add(e)
+		}
+	}
+
+	if err.HasParent() {
+		return err
+	}
+
+	return nil
+}
+
+func (c *Config) normalize() {
+	if c.Concurrency < 1 {
+		c.Concurrency = 1
+	}
+
+	if c.RetryDelay <= 0 {
+		c.RetryDelay = libdur.ParseDuration(DefaultRetryDelay)
+	}
+
+	if c.RetryMaxDelay <= 0 {
+		c.RetryMaxDelay = libdur.ParseDuration(DefaultRetryMaxDelay)
+	}
+
+	if c.RetryMaxDelay < c.RetryDelay {
+		c.RetryMaxDelay = c.RetryDelay
+	}
+}
+
+func (c Config) driver() (quetps.Driver, error) {
+	switch c.Driver {
+	case DriverNats:
+		return quenat.New(c.Nats)
+	case DriverKafka:
+		return quekfk.New(c.Kafka)
+	case DriverMemory:
+		return quemem.New(c.Memory), nil
+	}
+
+	return nil, ErrorDriverUnknown.Error(nil)
+}
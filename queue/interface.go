@@ -0,0 +1,99 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package queue
+
+import (
+	"context"
+	"sync"
+
+	libctx "github.com/nabbar/golib/context"
+	liblog "github.com/nabbar/golib/logger"
+	montps "github.com/nabbar/golib/monitor/types"
+	quetps "github.com/nabbar/golib/queue/types"
+	libsrv "github.com/nabbar/golib/server"
+	librun "github.com/nabbar/golib/server/runner/startStop"
+	libver "github.com/nabbar/golib/version"
+)
+
+const (
+	// HeaderDeadTopic is the header of a dead letter message storing the original topic.
+	HeaderDeadTopic = "Queue-Dead-Topic"
+	// HeaderDeadError is the header of a dead letter message storing the last error of the handler.
+	HeaderDeadError = "Queue-Dead-Error"
+)
+
+type Publishing = quetps.Publishing
+type Message = quetps.Message
+type Handler = quetps.Handler
+
+// Queue is an at-least-once message queue: each message delivered to a subscription is acknowledged
+// if the handler returns nil, otherwise it is delivered again with an exponential backoff
+// until the max deliver is reached.
+type Queue interface {
+	libsrv.Server
+	quetps.Publisher
+
+	// Subscribe registers the handler for the topic into the consumer group. Each group receives all
+	// messages of the topic and the messages are shared between the subscribers of the same group.
+	// If the queue is running, the subscription starts immediately, otherwise on the next start.
+	Subscribe(topic, group string, hdl Handler) error
+
+	// SetLogger is used to define the logger used to trace the processing errors.
+	SetLogger(fct liblog.FuncLog)
+
+	// HealthCheck returns an error if the backend of the queue is not reachable.
+	HealthCheck(ctx context.Context) error
+
+	// Monitor returns a monitor of the queue backend.
+	Monitor(ctx libctx.FuncContext, vrs libver.Version) (montps.Monitor, error)
+}
+
+// New returns a queue with the backend defined into the config. The connection
+// to the backend is opened on start and closed on stop.
+func New(cfg Config) (Queue, error) {
+	if e := cfg.Validate(); e != nil {
+		return nil, e
+	}
+
+	cfg.normalize()
+
+	d, e := cfg.driver()
+
+	if e != nil {
+		return nil, e
+	}
+
+	o := &que{
+		m: sync.RWMutex{},
+		c: cfg,
+		d: d,
+		s: make([]subscription, 0),
+	}
+
+	o.r = librun.New(o.runStart, o.runStop)
+
+	return o, nil
+}
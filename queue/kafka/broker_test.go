@@ -0,0 +1,558 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package kafka_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	kfkprt "github.com/segmentio/kafka-go/protocol"
+	kfkver "github.com/segmentio/kafka-go/protocol/apiversions"
+	kfkfet "github.com/segmentio/kafka-go/protocol/fetch"
+	kfkfnd "github.com/segmentio/kafka-go/protocol/findcoordinator"
+	kfkhbt "github.com/segmentio/kafka-go/protocol/heartbeat"
+	kfkjoi "github.com/segmentio/kafka-go/protocol/joingroup"
+	kfklve "github.com/segmentio/kafka-go/protocol/leavegroup"
+	kfklst "github.com/segmentio/kafka-go/protocol/listoffsets"
+	kfkmet "github.com/segmentio/kafka-go/protocol/metadata"
+	kfkcmt "github.com/segmentio/kafka-go/protocol/offsetcommit"
+	kfkoft "github.com/segmentio/kafka-go/protocol/offsetfetch"
+	kfkprd "github.com/segmentio/kafka-go/protocol/produce"
+	kfksyn "github.com/segmentio/kafka-go/protocol/syncgroup"
+)
+
+const (
+	brokerNode = 1
+
+	errOffsetOutOfRange  = 1
+	errIllegalGeneration = 22
+	errUnknownMember     = 25
+)
+
+// brokerVersions are the versions of the apis served by the broker, the lowest versions used by the kafka-go client.
+var brokerVersions = []kfkver.ApiKeyResponse{
+	{ApiKey: int16(kfkprt.Produce), MinVersion: 3, MaxVersion: 3},
+	{ApiKey: int16(kfkprt.Fetch), MinVersion: 5, MaxVersion: 5},
+	{ApiKey: int16(kfkprt.ListOffsets), MinVersion: 1, MaxVersion: 1},
+	{ApiKey: int16(kfkprt.Metadata), MinVersion: 1, MaxVersion: 1},
+	{ApiKey: int16(kfkprt.OffsetCommit), MinVersion: 2, MaxVersion: 2},
+	{ApiKey: int16(kfkprt.OffsetFetch), MinVersion: 1, MaxVersion: 1},
+	{ApiKey: int16(kfkprt.FindCoordinator), MinVersion: 0, MaxVersion: 0},
+	{ApiKey: int16(kfkprt.JoinGroup), MinVersion: 1, MaxVersion: 2},
+	{ApiKey: int16(kfkprt.Heartbeat), MinVersion: 0, MaxVersion: 0},
+	{ApiKey: int16(kfkprt.LeaveGroup), MinVersion: 0, MaxVersion: 0},
+	{ApiKey: int16(kfkprt.SyncGroup), MinVersion: 0, MaxVersion: 0},
+	{ApiKey: int16(kfkprt.ApiVersions), MinVersion: 0, MaxVersion: 0},
+}
+
+type record struct {
+	t time.Time
+	k []byte
+	v []byte
+	h []kfkprt.Header
+}
+
+type group struct {
+	g int32            // generation
+	m string           // member, empty if none
+	o map[string]int64 // committed offsets by topic
+}
+
+// broker is a minimal in memory kafka broker of a single node, serving the requests of the kafka-go
+// writer and consumer group readers, with one partition by topic and one member by consumer group.
+type broker struct {
+	m sync.Mutex
+	l net.Listener
+	h string
+	p int32
+	t map[string][]record // logs by topic, created on first use
+	g map[string]*group   // consumer groups by id
+	n int                 // sequence of members
+	c map[net.Conn]struct{}
+	d chan struct{} // closed with the broker
+}
+
+func newBroker() (*broker, error) {
+	l, e := net.Listen("tcp", "127.0.0.1:0")
+
+	if e != nil {
+		return nil, e
+	}
+
+	a := l.Addr().(*net.TCPAddr)
+
+	o := &broker{
+		l: l,
+		h: a.IP.String(),
+		p: int32(a.Port),
+		t: make(map[string][]record),
+		g: make(map[string]*group),
+		c: make(map[net.Conn]struct{}),
+		d: make(chan struct{}),
+	}
+
+	go o.accept()
+
+	return o, nil
+}
+
+func (o *broker) addr() string {
+	return net.JoinHostPort(o.h, strconv.Itoa(int(o.p)))
+}
+
+func (o *broker) close() {
+	close(o.d)
+	_ = o.l.Close()
+
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	for c := range o.c {
+		_ = c.Close()
+	}
+}
+
+// committed returns the committed offset of the group of the topic, -1 if none.
+func (o *broker) committed(group, topic string) int64 {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if g, k := o.g[group]; !k {
+		return -1
+	} else if i, k := g.o[topic]; !k {
+		return -1
+	} else {
+		return i
+	}
+}
+
+// length returns the number of records of the topic.
+func (o *broker) length(topic string) int {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	return len(o.t[topic])
+}
+
+func (o *broker) accept() {
+	for {
+		c, e := o.l.Accept()
+
+		if e != nil {
+			return
+		}
+
+		o.m.Lock()
+		o.c[c] = struct{}{}
+		o.m.Unlock()
+
+		go o.serve(c)
+	}
+}
+
+func (o *broker) serve(c net.Conn) {
+	defer func() {
+		o.m.Lock()
+		delete(o.c, c)
+		o.m.Unlock()
+		_ = c.Close()
+	}()
+
+	var (
+		r = bufio.NewReader(c)
+		w = bufio.NewWriter(c)
+	)
+
+	for {
+		ver, cid, _, req, err := kfkprt.ReadRequest(r)
+
+		if err != nil {
+			return
+		}
+
+		if f, k := req.(*kfkfet.Request); k {
+			err = o.fetch(w, cid, f)
+		} else if res, e := o.handle(req); e != nil {
+			return
+		} else {
+			err = kfkprt.WriteResponse(w, ver, cid, res)
+		}
+
+		if err != nil || w.Flush() != nil {
+			return
+		}
+	}
+}
+
+func (o *broker) handle(req kfkprt.Message) (kfkprt.Message, error) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	switch r := req.(type) {
+	case *kfkver.Request:
+		return &kfkver.Response{ApiKeys: brokerVersions}, nil
+	case *kfkmet.Request:
+		return o.metadata(r), nil
+	case *kfkprd.Request:
+		return o.produce(r)
+	case *kfklst.Request:
+		return o.listOffsets(r), nil
+	case *kfkfnd.Request:
+		return &kfkfnd.Response{NodeID: brokerNode, Host: o.h, Port: o.p}, nil
+	case *kfkjoi.Request:
+		return o.joinGroup(r), nil
+	case *kfksyn.Request:
+		return o.syncGroup(r), nil
+	case *kfkhbt.Request:
+		return &kfkhbt.Response{ErrorCode: o.member(r.GroupID, r.GenerationID, r.MemberID)}, nil
+	case *kfklve.Request:
+		if g, k := o.g[r.GroupID]; k && g.m == r.MemberID {
+			g.m = ""
+		}
+		return &kfklve.Response{}, nil
+	case *kfkoft.Request:
+		return o.offsetFetch(r), nil
+	case *kfkcmt.Request:
+		return o.offsetCommit(r), nil
+	}
+
+	return nil, fmt.Errorf("unsupported request %s", req.ApiKey())
+}
+
+func (o *broker) metadata(req *kfkmet.Request) *kfkmet.Response {
+	var (
+		res = &kfkmet.Response{
+			Brokers:      []kfkmet.ResponseBroker{{NodeID: brokerNode, Host: o.h, Port: o.p}},
+			ControllerID: brokerNode,
+		}
+		lst = req.TopicNames
+	)
+
+	if lst == nil {
+		for t := range o.t {
+			lst = append(lst, t)
+		}
+	}
+
+	for _, t := range lst {
+		if _, k := o.t[t]; !k {
+			o.t[t] = make([]record, 0)
+		}
+
+		res.Topics = append(res.Topics, kfkmet.ResponseTopic{
+			Name: t,
+			Partitions: []kfkmet.ResponsePartition{{
+				LeaderID:     brokerNode,
+				ReplicaNodes: []int32{brokerNode},
+				IsrNodes:     []int32{brokerNode},
+			}},
+		})
+	}
+
+	return res
+}
+
+func (o *broker) produce(req *kfkprd.Request) (*kfkprd.Response, error) {
+	var res = &kfkprd.Response{}
+
+	for _, t := range req.Topics {
+		var rt = kfkprd.ResponseTopic{Topic: t.Topic}
+
+		for _, p := range t.Partitions {
+			var base = int64(len(o.t[t.Topic]))
+
+			for {
+				r, e := p.RecordSet.Records.ReadRecord()
+
+				if errors.Is(e, io.EOF) {
+					break
+				} else if e != nil {
+					return nil, e
+				}
+
+				k, _ := kfkprt.ReadAll(r.Key)
+				v, _ := kfkprt.ReadAll(r.Value)
+
+				o.t[t.Topic] = append(o.t[t.Topic], record{t: r.Time, k: k, v: v, h: r.Headers})
+			}
+
+			rt.Partitions = append(rt.Partitions, kfkprd.ResponsePartition{Partition: p.Partition, BaseOffset: base})
+		}
+
+		res.Topics = append(res.Topics, rt)
+	}
+
+	return res, nil
+}
+
+func (o *broker) listOffsets(req *kfklst.Request) *kfklst.Response {
+	var res = &kfklst.Response{}
+
+	for _, t := range req.Topics {
+		var rt = kfklst.ResponseTopic{Topic: t.Topic}
+
+		for _, p := range t.Partitions {
+			var i int64
+
+			if p.Timestamp != -2 {
+				i = int64(len(o.t[t.Topic]))
+			}
+
+			rt.Partitions = append(rt.Partitions, kfklst.ResponsePartition{Partition: p.Partition, Timestamp: -1, Offset: i})
+		}
+
+		res.Topics = append(res.Topics, rt)
+	}
+
+	return res
+}
+
+func (o *broker) joinGroup(req *kfkjoi.Request) *kfkjoi.Response {
+	g, k := o.g[req.GroupID]
+
+	if !k {
+		g = &group{o: make(map[string]int64)}
+		o.g[req.GroupID] = g
+	}
+
+	if req.MemberID == "" || req.MemberID != g.m {
+		o.n++
+		g.m = "member-" + strconv.Itoa(o.n)
+	}
+
+	g.g++
+
+	var res = &kfkjoi.Response{
+		GenerationID: g.g,
+		LeaderID:     g.m,
+		MemberID:     g.m,
+	}
+
+	if len(req.Protocols) > 0 {
+		res.ProtocolName = req.Protocols[0].Name
+		res.Members = []kfkjoi.ResponseMember{{MemberID: g.m, Metadata: req.Protocols[0].Metadata}}
+	}
+
+	return res
+}
+
+func (o *broker) syncGroup(req *kfksyn.Request) *kfksyn.Response {
+	if c := o.member(req.GroupID, req.GenerationID, req.MemberID); c != 0 {
+		return &kfksyn.Response{ErrorCode: c}
+	}
+
+	for _, a := range req.Assignments {
+		if a.MemberID == req.MemberID {
+			return &kfksyn.Response{Assignments: a.Assignment}
+		}
+	}
+
+	return &kfksyn.Response{}
+}
+
+// member returns the error code of the member of the group for the generation, zero if valid.
+func (o *broker) member(group string, generation int32, member string) int16 {
+	if g, k := o.g[group]; !k || g.m != member {
+		return errUnknownMember
+	} else if g.g != generation {
+		return errIllegalGeneration
+	}
+
+	return 0
+}
+
+func (o *broker) offsetFetch(req *kfkoft.Request) *kfkoft.Response {
+	var (
+		res = &kfkoft.Response{}
+		grp = o.g[req.GroupID]
+	)
+
+	for _, t := range req.Topics {
+		var rt = kfkoft.ResponseTopic{Name: t.Name}
+
+		for _, p := range t.PartitionIndexes {
+			var i int64 = -1
+
+			if grp != nil {
+				if c, k := grp.o[t.Name]; k {
+					i = c
+				}
+			}
+
+			rt.Partitions = append(rt.Partitions, kfkoft.ResponsePartition{PartitionIndex: p, CommittedOffset: i})
+		}
+
+		res.Topics = append(res.Topics, rt)
+	}
+
+	return res
+}
+
+func (o *broker) offsetCommit(req *kfkcmt.Request) *kfkcmt.Response {
+	var (
+		res = &kfkcmt.Response{}
+		err = o.member(req.GroupID, req.GenerationID, req.MemberID)
+	)
+
+	for _, t := range req.Topics {
+		var rt = kfkcmt.ResponseTopic{Name: t.Name}
+
+		for _, p := range t.Partitions {
+			if err == 0 {
+				o.g[req.GroupID].o[t.Name] = p.CommittedOffset
+			}
+
+			rt.Partitions = append(rt.Partitions, kfkcmt.ResponsePartition{PartitionIndex: p.PartitionIndex, ErrorCode: err})
+		}
+
+		res.Topics = append(res.Topics, rt)
+	}
+
+	return res
+}
+
+// fetch writes the fetch response, encoded here as the record batches of the protocol package
+// are always written with a base offset of zero. It waits for new records up to the max wait time.
+func (o *broker) fetch(w io.Writer, cid int32, req *kfkfet.Request) error {
+	var (
+		end = time.Now().Add(time.Duration(req.MaxWaitTime) * time.Millisecond)
+		buf []byte
+		num int
+	)
+
+	for {
+		buf, num = o.fetchBody(req)
+
+		if num > 0 || !time.Now().Before(end) {
+			break
+		}
+
+		select {
+		case <-o.d:
+			return io.EOF
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+
+	var hdr = make([]byte, 8)
+	binary.BigEndian.PutUint32(hdr[0:], uint32(len(buf)+4))
+	binary.BigEndian.PutUint32(hdr[4:], uint32(cid))
+
+	if _, e := w.Write(hdr); e != nil {
+		return e
+	}
+
+	_, e := w.Write(buf)
+	return e
+}
+
+// fetchBody returns the body of a fetch response in version 5 and its number of records.
+func (o *broker) fetchBody(req *kfkfet.Request) ([]byte, int) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	var (
+		b = &bytes.Buffer{}
+		n int
+	)
+
+	_ = binary.Write(b, binary.BigEndian, int32(0)) // throttle time
+	_ = binary.Write(b, binary.BigEndian, int32(len(req.Topics)))
+
+	for _, t := range req.Topics {
+		var log = o.t[t.Topic]
+
+		_ = binary.Write(b, binary.BigEndian, int16(len(t.Topic)))
+		b.WriteString(t.Topic)
+		_ = binary.Write(b, binary.BigEndian, int32(len(t.Partitions)))
+
+		for _, p := range t.Partitions {
+			var (
+				err int16
+				rec []byte
+				hwm = int64(len(log))
+			)
+
+			if p.FetchOffset > hwm || p.FetchOffset < 0 {
+				err = errOffsetOutOfRange
+			} else if p.FetchOffset < hwm {
+				rec = batch(p.FetchOffset, log[p.FetchOffset:])
+				n += len(log) - int(p.FetchOffset)
+			}
+
+			_ = binary.Write(b, binary.BigEndian, p.Partition)
+			_ = binary.Write(b, binary.BigEndian, err)
+			_ = binary.Write(b, binary.BigEndian, hwm) // high watermark
+			_ = binary.Write(b, binary.BigEndian, hwm) // last stable offset
+			_ = binary.Write(b, binary.BigEndian, int64(0))
+			_ = binary.Write(b, binary.BigEndian, int32(0)) // aborted transactions
+
+			if len(rec) < 1 {
+				_ = binary.Write(b, binary.BigEndian, int32(0))
+			} else {
+				b.Write(rec)
+			}
+		}
+	}
+
+	return b.Bytes(), n
+}
+
+// batch returns the size prefixed record batch of the records starting at the given offset.
+func batch(offset int64, log []record) []byte {
+	var (
+		b = &bytes.Buffer{}
+		l = make([]kfkprt.Record, 0, len(log))
+	)
+
+	for _, r := range log {
+		l = append(l, kfkprt.Record{
+			Time:    r.t,
+			Key:     kfkprt.NewBytes(r.k),
+			Value:   kfkprt.NewBytes(r.v),
+			Headers: r.h,
+		})
+	}
+
+	rs := kfkprt.RecordSet{Version: 2, Records: kfkprt.NewRecordReader(l...)}
+
+	if _, e := rs.WriteTo(b); e != nil {
+		return nil
+	}
+
+	var res = b.Bytes()
+	binary.BigEndian.PutUint64(res[4:], uint64(offset))
+
+	return res
+}
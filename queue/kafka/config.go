@@ -0,0 +1,112 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package kafka
+
+import (
+	"fmt"
+	"time"
+
+	libval "github.com/go-playground/validator/v10"
+	libtls "github.com/nabbar/golib/certificates"
+	libdur "github.com/nabbar/golib/duration"
+)
+
+const (
+	SASLPlain       = "plain"
+	SASLScramSHA256 = "scram-sha-256"
+	SASLScramSHA512 = "scram-sha-512"
+
+	OffsetFirst = "first"
+	OffsetLast  = "last"
+
+	// DefaultDialTimeout is the default timeout of the connections to the brokers.
+	DefaultDialTimeout = 10 * time.Second
+	// DefaultBatchTimeout is the default maximum delay to group the messages published together.
+	DefaultBatchTimeout = 10 * time.Millisecond
+	// DefaultMaxWait is the default maximum duration of a fetch request waiting for new messages.
+	DefaultMaxWait = time.Second
+)
+
+type Config struct {
+	// Brokers is the list of kafka brokers address, as host:port.
+	Brokers []string `json:"brokers" yaml:"brokers" toml:"brokers" mapstructure:"brokers" validate:"required,min=1,dive,hostname_port"`
+
+	// ClientID is an optional identifier sent to the brokers to identify the client.
+	ClientID string `json:"client-id,omitempty" yaml:"client-id,omitempty" toml:"client-id,omitempty" mapstructure:"client-id,omitempty"`
+
+	// Secure enables TLS connections with the TLS config.
+	Secure bool `json:"secure,omitempty" yaml:"secure,omitempty" toml:"secure,omitempty" mapstructure:"secure,omitempty"`
+
+	// TLS is the TLS configuration used if secure is enabled.
+	TLS libtls.Config `json:"tls,omitempty" yaml:"tls,omitempty" toml:"tls,omitempty" mapstructure:"tls,omitempty"`
+
+	// SASL is the authentication mechanism, one of 'plain', 'scram-sha-256' or 'scram-sha-512'. By default, none.
+	SASL string `json:"sasl,omitempty" yaml:"sasl,omitempty" toml:"sasl,omitempty" mapstructure:"sasl,omitempty" validate:"omitempty,oneof=plain scram-sha-256 scram-sha-512"`
+
+	// User sets the username used by the SASL authentication.
+	User string `json:"user,omitempty" yaml:"user,omitempty" toml:"user,omitempty" mapstructure:"user,omitempty" validate:"required_with=SASL"`
+
+	// Password sets the password used by the SASL authentication.
+	Password string `json:"password,omitempty" yaml:"password,omitempty" toml:"password,omitempty" mapstructure:"password,omitempty"`
+
+	// AutoCreateTopic allows the brokers to create the missing topics on publish,
+	// if enabled into the brokers config.
+	AutoCreateTopic bool `json:"auto-create-topic,omitempty" yaml:"auto-create-topic,omitempty" toml:"auto-create-topic,omitempty" mapstructure:"auto-create-topic,omitempty"`
+
+	// StartOffset is the offset of a new consumer group, 'first' to receive all the messages kept by the
+	// topic or 'last' to receive only the new messages. By default, first.
+	StartOffset string `json:"start-offset,omitempty" yaml:"start-offset,omitempty" toml:"start-offset,omitempty" mapstructure:"start-offset,omitempty" validate:"omitempty,oneof=first last"`
+
+	// DialTimeout is the timeout of the connections to the brokers. By default, 10s.
+	DialTimeout libdur.Duration `json:"dial-timeout,omitempty" yaml:"dial-timeout,omitempty" toml:"dial-timeout,omitempty" mapstructure:"dial-timeout,omitempty"`
+
+	// BatchTimeout is the maximum delay to group the messages published together. By default, 10ms.
+	BatchTimeout libdur.Duration `json:"batch-timeout,omitempty" yaml:"batch-timeout,omitempty" toml:"batch-timeout,omitempty" mapstructure:"batch-timeout,omitempty"`
+
+	// MaxWait is the maximum duration of a fetch request waiting for new messages. By default, 1s.
+	MaxWait libdur.Duration `json:"max-wait,omitempty" yaml:"max-wait,omitempty" toml:"max-wait,omitempty" mapstructure:"max-wait,omitempty"`
+}
+
+func (c Config) Validate() error {
+	err := ErrorValidatorError.Error(nil)
+
+	if er := libval.New().Struct(c); er != nil {
+		if e, ok := er.(*libval.InvalidValidationError); ok {
+			err.Add(e)
+		}
+
+		for _, e := range er.(libval.ValidationErrors) {
+			//nolint goerr113
+			err.Add(fmt.Errorf("config field '%s' is not validated by constraint '%s'", e.Namespace(), e.ActualTag()))
+		}
+	}
+
+	if err.HasParent() {
+		return err
+	}
+
+	return nil
+}
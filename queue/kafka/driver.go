@@ -0,0 +1,425 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package kafka
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	libdur "github.com/nabbar/golib/duration"
+	quetps "github.com/nabbar/golib/queue/types"
+	kfkcli "github.com/segmentio/kafka-go"
+	kfksas "github.com/segmentio/kafka-go/sasl"
+	kfkpln "github.com/segmentio/kafka-go/sasl/plain"
+	kfkscr "github.com/segmentio/kafka-go/sasl/scram"
+)
+
+// fetchRetryDelay is the delay before fetching again after a fetch error.
+const fetchRetryDelay = time.Second
+
+// New returns a kafka queue driver. Each consumer group of a topic is a kafka consumer group whose
+// offsets are committed on acknowledgement, so the messages not acknowledged are delivered again after
+// a restart or a rebalance. As the offsets of a partition are ordered, a message negatively acknowledged
+// is delivered again by the same consumer after the delay, holding the next messages of its partition.
+func New(cfg Config) (quetps.Driver, error) {
+	if e := cfg.Validate(); e != nil {
+		return nil, e
+	}
+
+	if len(cfg.StartOffset) < 1 {
+		cfg.StartOffset = OffsetFirst
+	}
+
+	if cfg.DialTimeout <= 0 {
+		cfg.DialTimeout = libdur.ParseDuration(DefaultDialTimeout)
+	}
+
+	if cfg.BatchTimeout <= 0 {
+		cfg.BatchTimeout = libdur.ParseDuration(DefaultBatchTimeout)
+	}
+
+	if cfg.MaxWait <= 0 {
+		cfg.MaxWait = libdur.ParseDuration(DefaultMaxWait)
+	}
+
+	return &drv{
+		c: cfg,
+		r: make(map[*kfkcli.Reader]struct{}),
+	}, nil
+}
+
+type drv struct {
+	m sync.RWMutex
+	c Config
+	t *kfkcli.Transport           // transport of the writer and the health checks
+	d *kfkcli.Dialer              // dialer of the readers
+	w *kfkcli.Writer              // writer of all topics
+	r map[*kfkcli.Reader]struct{} // readers of the consumers
+}
+
+func (o *drv) mechanism() (kfksas.Mechanism, error) {
+	switch o.c.SASL {
+	case SASLPlain:
+		return kfkpln.Mechanism{Username: o.c.User, Password: o.c.Password}, nil
+	case SASLScramSHA256:
+		return kfkscr.Mechanism(kfkscr.SHA256, o.c.User, o.c.Password)
+	case SASLScramSHA512:
+		return kfkscr.Mechanism(kfkscr.SHA512, o.c.User, o.c.Password)
+	}
+
+	return nil, nil
+}
+
+func (o *drv) client() *kfkcli.Client {
+	return &kfkcli.Client{
+		Addr:      kfkcli.TCP(o.c.Brokers...),
+		Transport: o.t,
+	}
+}
+
+func (o *drv) Connect(ctx context.Context) error {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if o.w != nil {
+		return nil
+	}
+
+	mec, err := o.mechanism()
+
+	if err != nil {
+		return ErrorConnect.Error(err)
+	}
+
+	o.t = &kfkcli.Transport{
+		ClientID:    o.c.ClientID,
+		DialTimeout: o.c.DialTimeout.Time(),
+		SASL:        mec,
+	}
+
+	o.d = &kfkcli.Dialer{
+		ClientID:      o.c.ClientID,
+		Timeout:       o.c.DialTimeout.Time(),
+		DualStack:     true,
+		SASLMechanism: mec,
+	}
+
+	if o.c.Secure {
+		if t := o.c.TLS.New(); t == nil {
+			return ErrorConnect.Error(nil)
+		} else {
+			o.t.TLS = t.TlsConfig("")
+			o.d.TLS = o.t.TLS
+		}
+	}
+
+	if _, e := o.client().Metadata(ctx, &kfkcli.MetadataRequest{}); e != nil {
+		o.t.CloseIdleConnections()
+		o.t, o.d = nil, nil
+		return ErrorConnect.Error(e)
+	}
+
+	o.w = &kfkcli.Writer{
+		Addr:                   kfkcli.TCP(o.c.Brokers...),
+		Balancer:               &kfkcli.Hash{},
+		BatchTimeout:           o.c.BatchTimeout.Time(),
+		RequiredAcks:           kfkcli.RequireAll,
+		Transport:              o.t,
+		AllowAutoTopicCreation: o.c.AutoCreateTopic,
+	}
+
+	return nil
+}
+
+func (o *drv) Close() error {
+	o.m.Lock()
+
+	var (
+		w = o.w
+		t = o.t
+		r = make([]*kfkcli.Reader, 0, len(o.r))
+	)
+
+	for i := range o.r {
+		r = append(r, i)
+	}
+
+	o.w, o.t, o.d = nil, nil, nil
+	o.r = make(map[*kfkcli.Reader]struct{})
+	o.m.Unlock()
+
+	if w == nil {
+		return nil
+	}
+
+	var err = w.Close()
+
+	for _, i := range r {
+		if e := i.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+
+	t.CloseIdleConnections()
+
+	if err != nil {
+		return ErrorConnect.Error(err)
+	}
+
+	return nil
+}
+
+func (o *drv) Health(ctx context.Context) error {
+	o.m.RLock()
+	t := o.t
+	o.m.RUnlock()
+
+	if t == nil {
+		return ErrorNotConnected.Error(nil)
+	} else if r, e := o.client().Metadata(ctx, &kfkcli.MetadataRequest{}); e != nil {
+		return ErrorNotConnected.Error(e)
+	} else if len(r.Brokers) < 1 {
+		return ErrorNotConnected.Error(nil)
+	}
+
+	return nil
+}
+
+func (o *drv) Publish(ctx context.Context, topic string, pub quetps.Publishing) error {
+	if len(topic) < 1 {
+		return ErrorParamEmpty.Error(nil)
+	}
+
+	o.m.RLock()
+	w := o.w
+	o.m.RUnlock()
+
+	if w == nil {
+		return ErrorNotConnected.Error(nil)
+	}
+
+	m := kfkcli.Message{
+		Topic:   topic,
+		Value:   pub.Data,
+		Headers: make([]kfkcli.Header, 0, len(pub.Header)),
+	}
+
+	if pub.Key != "" {
+		m.Key = []byte(pub.Key)
+	}
+
+	for k, v := range pub.Header {
+		m.Headers = append(m.Headers, kfkcli.Header{Key: k, Value: []byte(v)})
+	}
+
+	if e := w.WriteMessages(ctx, m); e != nil {
+		return ErrorPublish.Error(e)
+	}
+
+	return nil
+}
+
+func (o *drv) Consume(ctx context.Context, topic, group string, fct quetps.FuncDelivery) error {
+	if len(topic) < 1 || fct == nil {
+		return ErrorParamEmpty.Error(nil)
+	}
+
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if o.d == nil {
+		return ErrorNotConnected.Error(nil)
+	}
+
+	cfg := kfkcli.ReaderConfig{
+		Brokers:     o.c.Brokers,
+		GroupID:     groupID(group, topic),
+		Topic:       topic,
+		Dialer:      o.d,
+		MaxWait:     o.c.MaxWait.Time(),
+		StartOffset: kfkcli.FirstOffset,
+	}
+
+	if o.c.StartOffset == OffsetLast {
+		cfg.StartOffset = kfkcli.LastOffset
+	}
+
+	if e := cfg.Validate(); e != nil {
+		return ErrorConsumer.Error(e)
+	}
+
+	r := kfkcli.NewReader(cfg)
+	o.r[r] = struct{}{}
+
+	go o.consume(ctx, r, fct)
+
+	return nil
+}
+
+// consume fetches the messages of the reader and calls the function for each of them, until the context is
+// done or the reader is closed. A message negatively acknowledged is delivered again after the delay.
+func (o *drv) consume(ctx context.Context, r *kfkcli.Reader, fct quetps.FuncDelivery) {
+	defer o.release(r)
+
+	for {
+		m, e := r.FetchMessage(ctx)
+
+		if e != nil {
+			if ctx.Err() != nil || errors.Is(e, io.EOF) || !sleep(ctx, fetchRetryDelay) {
+				return
+			}
+			continue
+		}
+
+		for a := 1; ; a++ {
+			g := &msg{
+				x: ctx,
+				r: r,
+				m: m,
+				a: a,
+			}
+
+			fct(g)
+
+			if d, k := g.retry(); !k {
+				break
+			} else if !sleep(ctx, d) {
+				return
+			}
+		}
+	}
+}
+
+// release removes the reader of the driver and closes it if not already closed by the driver.
+func (o *drv) release(r *kfkcli.Reader) {
+	o.m.Lock()
+	_, k := o.r[r]
+	delete(o.r, r)
+	o.m.Unlock()
+
+	if k {
+		_ = r.Close()
+	}
+}
+
+// groupID returns the kafka consumer group id of the group of the topic.
+func groupID(group, topic string) string {
+	if group != "" {
+		return group + "-" + topic
+	}
+
+	return topic
+}
+
+// sleep waits for the delay and returns false if the context is done before.
+func sleep(ctx context.Context, delay time.Duration) bool {
+	if delay <= 0 {
+		return ctx.Err() == nil
+	}
+
+	t := time.NewTimer(delay)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+type msg struct {
+	x context.Context
+	r *kfkcli.Reader
+	m kfkcli.Message
+	a int
+	f atomic.Bool  // acknowledged or negatively acknowledged
+	n atomic.Bool  // negatively acknowledged
+	d atomic.Int64 // delay of redelivery
+}
+
+func (m *msg) ID() string {
+	return strconv.Itoa(m.m.Partition) + "-" + strconv.FormatInt(m.m.Offset, 10)
+}
+
+func (m *msg) Topic() string {
+	return m.m.Topic
+}
+
+func (m *msg) Key() string {
+	return string(m.m.Key)
+}
+
+func (m *msg) Data() []byte {
+	return m.m.Value
+}
+
+func (m *msg) Header() map[string]string {
+	var r = make(map[string]string, len(m.m.Headers))
+
+	for _, h := range m.m.Headers {
+		r[h.Key] = string(h.Value)
+	}
+
+	return r
+}
+
+func (m *msg) Timestamp() time.Time {
+	return m.m.Time
+}
+
+func (m *msg) Attempt() int {
+	return m.a
+}
+
+func (m *msg) Ack() error {
+	if !m.f.CompareAndSwap(false, true) {
+		return nil
+	} else if e := m.r.CommitMessages(m.x, m.m); e != nil {
+		return ErrorCommit.Error(e)
+	}
+
+	return nil
+}
+
+func (m *msg) Nack(delay time.Duration) error {
+	if m.f.CompareAndSwap(false, true) {
+		m.d.Store(int64(delay))
+		m.n.Store(true)
+	}
+
+	return nil
+}
+
+// retry returns the delay of redelivery and true if the message was negatively acknowledged.
+func (m *msg) retry() (time.Duration, bool) {
+	return time.Duration(m.d.Load()), m.n.Load()
+}
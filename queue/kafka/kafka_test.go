@@ -0,0 +1,266 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package kafka_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	libdur "github.com/nabbar/golib/duration"
+	libque "github.com/nabbar/golib/queue"
+	quekfk "github.com/nabbar/golib/queue/kafka"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func newConfig() quekfk.Config {
+	return quekfk.Config{
+		Brokers:         []string{brk.addr()},
+		ClientID:        "golib-test",
+		AutoCreateTopic: true,
+		BatchTimeout:    libdur.ParseDuration(time.Millisecond),
+		MaxWait:         libdur.ParseDuration(50 * time.Millisecond),
+	}
+}
+
+func newQueue(retry time.Duration) libque.Queue {
+	q, e := libque.New(libque.Config{
+		Driver:        libque.DriverKafka,
+		Kafka:         newConfig(),
+		RetryDelay:    libdur.ParseDuration(retry),
+		RetryMaxDelay: libdur.ParseDuration(retry),
+	})
+
+	Expect(e).ToNot(HaveOccurred())
+	Expect(q).ToNot(BeNil())
+
+	return q
+}
+
+// freeAddr returns the address of a closed local port.
+func freeAddr() string {
+	l, e := net.Listen("tcp", "127.0.0.1:0")
+	Expect(e).ToNot(HaveOccurred())
+
+	a := l.Addr().String()
+	Expect(l.Close()).ToNot(HaveOccurred())
+
+	return a
+}
+
+var _ = Describe("Kafka Queue", func() {
+	Context("config", func() {
+		DescribeTable("validation",
+			func(cfg quekfk.Config, valid bool) {
+				if e := cfg.Validate(); valid {
+					Expect(e).ToNot(HaveOccurred())
+				} else {
+					Expect(e).To(HaveOccurred())
+				}
+			},
+			Entry("without broker", quekfk.Config{}, false),
+			Entry("with a broker without port", quekfk.Config{Brokers: []string{"localhost"}}, false),
+			Entry("with a broker", quekfk.Config{Brokers: []string{"localhost:9092"}}, true),
+			Entry("with an unknown sasl mechanism", quekfk.Config{Brokers: []string{"localhost:9092"}, SASL: "gssapi", User: "u"}, false),
+			Entry("with a sasl mechanism without user", quekfk.Config{Brokers: []string{"localhost:9092"}, SASL: quekfk.SASLPlain}, false),
+			Entry("with a sasl mechanism and user", quekfk.Config{Brokers: []string{"localhost:9092"}, SASL: quekfk.SASLScramSHA512, User: "u", Password: "p"}, true),
+			Entry("with an unknown start offset", quekfk.Config{Brokers: []string{"localhost:9092"}, StartOffset: "middle"}, false),
+			Entry("with the last start offset", quekfk.Config{Brokers: []string{"localhost:9092"}, StartOffset: quekfk.OffsetLast}, true),
+		)
+	})
+
+	Context("driver", func() {
+		It("must fail to connect to an unreachable broker", func() {
+			cfg := newConfig()
+			cfg.Brokers = []string{freeAddr()}
+			cfg.DialTimeout = libdur.ParseDuration(500 * time.Millisecond)
+
+			d, e := quekfk.New(cfg)
+			Expect(e).ToNot(HaveOccurred())
+
+			x, n := context.WithTimeout(ctx, 2*time.Second)
+			defer n()
+
+			Expect(d.Connect(x)).To(HaveOccurred())
+			Expect(d.Health(x)).To(HaveOccurred())
+		})
+
+		It("must refuse to publish and consume if not connected", func() {
+			d, e := quekfk.New(newConfig())
+			Expect(e).ToNot(HaveOccurred())
+
+			Expect(d.Publish(ctx, "topic", libque.Publishing{Data: []byte("data")})).To(HaveOccurred())
+			Expect(d.Consume(ctx, "topic", "group", func(msg libque.Message) {})).To(HaveOccurred())
+			Expect(d.Close()).ToNot(HaveOccurred())
+		})
+	})
+
+	Context("queue", func() {
+		It("must connect on start and close on stop", func() {
+			q := newQueue(10 * time.Millisecond)
+
+			Expect(q.Start(ctx)).ToNot(HaveOccurred())
+			Eventually(q.IsRunning).Should(BeTrue())
+			Expect(q.HealthCheck(ctx)).ToNot(HaveOccurred())
+
+			Expect(q.Stop(ctx)).ToNot(HaveOccurred())
+			Expect(q.HealthCheck(ctx)).To(HaveOccurred())
+			Expect(q.Publish(ctx, "topic", libque.Publishing{Data: []byte("data")})).To(HaveOccurred())
+		})
+
+		It("must deliver key, data and header of messages to each group", func() {
+			var (
+				q = newQueue(10 * time.Millisecond)
+				m sync.Mutex
+				r = make(map[string][]libque.Message)
+			)
+
+			for _, g := range []string{"a", "b"} {
+				g := g
+				Expect(q.Subscribe("t-groups", g, func(ctx context.Context, msg libque.Message) error {
+					m.Lock()
+					defer m.Unlock()
+					r[g] = append(r[g], msg)
+					return nil
+				})).ToNot(HaveOccurred())
+			}
+
+			Expect(q.Start(ctx)).ToNot(HaveOccurred())
+			defer func() { _ = q.Stop(ctx) }()
+
+			Expect(q.Publish(ctx, "t-groups", libque.Publishing{
+				Key:    "key",
+				Data:   []byte("first"),
+				Header: map[string]string{"h": "v"},
+			})).ToNot(HaveOccurred())
+			Expect(q.Publish(ctx, "t-groups", libque.Publishing{Data: []byte("second")})).ToNot(HaveOccurred())
+
+			Eventually(func() int {
+				m.Lock()
+				defer m.Unlock()
+				return len(r["a"]) + len(r["b"])
+			}, 10*time.Second, 20*time.Millisecond).Should(Equal(4))
+
+			m.Lock()
+			defer m.Unlock()
+
+			for _, g := range []string{"a", "b"} {
+				Expect(r[g]).To(HaveLen(2))
+				Expect(r[g][0].Topic()).To(Equal("t-groups"))
+				Expect(r[g][0].Key()).To(Equal("key"))
+				Expect(string(r[g][0].Data())).To(Equal("first"))
+				Expect(r[g][0].Header()).To(Equal(map[string]string{"h": "v"}))
+				Expect(r[g][0].ID()).To(Equal("0-0"))
+				Expect(r[g][0].Attempt()).To(Equal(1))
+				Expect(string(r[g][1].Data())).To(Equal("second"))
+				Expect(r[g][1].ID()).To(Equal("0-1"))
+			}
+
+			Eventually(func() int64 {
+				return brk.committed("a-t-groups", "t-groups")
+			}, 5*time.Second, 20*time.Millisecond).Should(Equal(int64(2)))
+		})
+
+		It("must redeliver a message negatively acknowledged and commit it once processed", func() {
+			var (
+				q = newQueue(10 * time.Millisecond)
+				m sync.Mutex
+				a []int
+			)
+
+			Expect(q.Subscribe("t-retry", "g", func(ctx context.Context, msg libque.Message) error {
+				m.Lock()
+				defer m.Unlock()
+
+				if a = append(a, msg.Attempt()); msg.Attempt() < 3 {
+					return errors.New("not yet")
+				}
+
+				return nil
+			})).ToNot(HaveOccurred())
+
+			Expect(q.Start(ctx)).ToNot(HaveOccurred())
+			defer func() { _ = q.Stop(ctx) }()
+
+			Expect(q.Publish(ctx, "t-retry", libque.Publishing{Data: []byte("data")})).ToNot(HaveOccurred())
+
+			Eventually(func() int64 {
+				return brk.committed("g-t-retry", "t-retry")
+			}, 10*time.Second, 20*time.Millisecond).Should(Equal(int64(1)))
+
+			m.Lock()
+			defer m.Unlock()
+			Expect(a).To(Equal([]int{1, 2, 3}))
+		})
+
+		It("must deliver again the messages not acknowledged after a restart", func() {
+			var (
+				q = newQueue(time.Minute)
+				n atomic.Int32
+			)
+
+			Expect(q.Subscribe("t-restart", "g", func(ctx context.Context, msg libque.Message) error {
+				n.Add(1)
+				return errors.New("failed")
+			})).ToNot(HaveOccurred())
+
+			Expect(q.Start(ctx)).ToNot(HaveOccurred())
+			Expect(q.Publish(ctx, "t-restart", libque.Publishing{Data: []byte("data")})).ToNot(HaveOccurred())
+
+			Eventually(n.Load, 10*time.Second, 20*time.Millisecond).Should(Equal(int32(1)))
+			Expect(q.Stop(ctx)).ToNot(HaveOccurred())
+			Expect(brk.committed("g-t-restart", "t-restart")).To(Equal(int64(-1)))
+
+			var (
+				r = newQueue(10 * time.Millisecond)
+				d = make(chan libque.Message, 1)
+			)
+
+			Expect(r.Subscribe("t-restart", "g", func(ctx context.Context, msg libque.Message) error {
+				d <- msg
+				return nil
+			})).ToNot(HaveOccurred())
+
+			Expect(r.Start(ctx)).ToNot(HaveOccurred())
+			defer func() { _ = r.Stop(ctx) }()
+
+			var msg libque.Message
+			Eventually(d, 10*time.Second).Should(Receive(&msg))
+			Expect(string(msg.Data())).To(Equal("data"))
+			Expect(msg.Attempt()).To(Equal(1))
+
+			Eventually(func() int64 {
+				return brk.committed("g-t-restart", "t-restart")
+			}, 5*time.Second, 20*time.Millisecond).Should(Equal(int64(1)))
+			Expect(brk.length("t-restart")).To(Equal(1))
+		})
+	})
+})
@@ -0,0 +1,214 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package memory
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	quetps "github.com/nabbar/golib/queue/types"
+)
+
+// New returns an in memory queue driver. The messages are kept into the process only: a message published
+// on a topic is copied into each consumer group of the topic and is lost if no group is subscribed yet.
+func New(cfg Config) quetps.Driver {
+	if cfg.BufferSize < 1 {
+		cfg.BufferSize = DefaultBufferSize
+	}
+
+	return &drv{
+		s: cfg.BufferSize,
+		t: make(map[string]map[string]chan *msg),
+	}
+}
+
+type drv struct {
+	m sync.RWMutex
+	s int
+	c bool                            // connected
+	t map[string]map[string]chan *msg // topic / group / pending messages
+	i atomic.Uint64                   // message sequence
+	x context.Context
+	n context.CancelFunc
+}
+
+func (o *drv) Connect(ctx context.Context) error {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if o.c {
+		return nil
+	}
+
+	o.x, o.n = context.WithCancel(context.Background())
+	o.c = true
+
+	return nil
+}
+
+func (o *drv) Close() error {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if !o.c {
+		return nil
+	}
+
+	o.n()
+	o.c = false
+	o.t = make(map[string]map[string]chan *msg)
+
+	return nil
+}
+
+func (o *drv) Health(ctx context.Context) error {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	if !o.c {
+		return ErrorNotConnected.Error(nil)
+	}
+
+	return nil
+}
+
+func (o *drv) Publish(ctx context.Context, topic string, pub quetps.Publishing) error {
+	if len(topic) < 1 {
+		return ErrorParamEmpty.Error(nil)
+	}
+
+	o.m.RLock()
+
+	if !o.c {
+		o.m.RUnlock()
+		return ErrorNotConnected.Error(nil)
+	}
+
+	var lst = make([]chan *msg, 0, len(o.t[topic]))
+
+	for _, c := range o.t[topic] {
+		lst = append(lst, c)
+	}
+
+	x := o.x
+	o.m.RUnlock()
+
+	var (
+		id = strconv.FormatUint(o.i.Add(1), 10)
+		ts = time.Now()
+	)
+
+	for _, c := range lst {
+		m := &msg{
+			d: o,
+			c: c,
+			i: id,
+			t: topic,
+			p: pub,
+			s: ts,
+			a: 1,
+		}
+
+		select {
+		case c <- m:
+		case <-x.Done():
+			return ErrorNotConnected.Error(nil)
+		case <-ctx.Done():
+			return ErrorContext.Error(ctx.Err())
+		}
+	}
+
+	return nil
+}
+
+func (o *drv) Consume(ctx context.Context, topic, group string, fct quetps.FuncDelivery) error {
+	if len(topic) < 1 || fct == nil {
+		return ErrorParamEmpty.Error(nil)
+	}
+
+	o.m.Lock()
+
+	if !o.c {
+		o.m.Unlock()
+		return ErrorNotConnected.Error(nil)
+	}
+
+	if _, k := o.t[topic]; !k {
+		o.t[topic] = make(map[string]chan *msg)
+	}
+
+	c, k := o.t[topic][group]
+
+	if !k {
+		c = make(chan *msg, o.s)
+		o.t[topic][group] = c
+	}
+
+	x := o.x
+	o.m.Unlock()
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-x.Done():
+				return
+			case m := <-c:
+				fct(m)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// requeue puts back the message into its group after the delay.
+func (o *drv) requeue(m *msg, delay time.Duration) {
+	o.m.RLock()
+	x := o.x
+	o.m.RUnlock()
+
+	n := &msg{
+		d: o,
+		c: m.c,
+		i: m.i,
+		t: m.t,
+		p: m.p,
+		s: m.s,
+		a: m.a + 1,
+	}
+
+	time.AfterFunc(delay, func() {
+		select {
+		case n.c <- n:
+		case <-x.Done():
+		}
+	})
+}
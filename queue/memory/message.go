@@ -0,0 +1,85 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package memory
+
+import (
+	"sync/atomic"
+	"time"
+
+	quetps "github.com/nabbar/golib/queue/types"
+)
+
+type msg struct {
+	d *drv
+	c chan *msg
+	i string
+	t string
+	p quetps.Publishing
+	s time.Time
+	a int
+	f atomic.Bool // acknowledged or negatively acknowledged
+}
+
+func (m *msg) ID() string {
+	return m.i
+}
+
+func (m *msg) Topic() string {
+	return m.t
+}
+
+func (m *msg) Key() string {
+	return m.p.Key
+}
+
+func (m *msg) Data() []byte {
+	return m.p.Data
+}
+
+func (m *msg) Header() map[string]string {
+	return m.p.Header
+}
+
+func (m *msg) Timestamp() time.Time {
+	return m.s
+}
+
+func (m *msg) Attempt() int {
+	return m.a
+}
+
+func (m *msg) Ack() error {
+	m.f.Store(true)
+	return nil
+}
+
+func (m *msg) Nack(delay time.Duration) error {
+	if m.f.CompareAndSwap(false, true) {
+		m.d.requeue(m, delay)
+	}
+
+	return nil
+}
@@ -0,0 +1,279 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package queue
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	liblog "github.com/nabbar/golib/logger"
+	loglvl "github.com/nabbar/golib/logger/level"
+	quetps "github.com/nabbar/golib/queue/types"
+	librun "github.com/nabbar/golib/server/runner/startStop"
+)
+
+type subscription struct {
+	t string // topic
+	g string // group
+	h quetps.Handler
+}
+
+type que struct {
+	m sync.RWMutex
+	c Config
+	d quetps.Driver
+	s []subscription
+	r librun.StartStop
+	l liblog.FuncLog
+	x context.Context    // consumers context
+	n context.CancelFunc // consumers cancel
+}
+
+func (o *que) SetLogger(fct liblog.FuncLog) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.l = fct
+}
+
+func (o *que) logger() liblog.Logger {
+	o.m.RLock()
+	f := o.l
+	o.m.RUnlock()
+
+	if f != nil {
+		if l := f(); l != nil {
+			return l
+		}
+	}
+
+	return liblog.New(context.Background)
+}
+
+func (o *que) Start(ctx context.Context) error {
+	if o.r.IsRunning() {
+		if e := o.Stop(ctx); e != nil {
+			return e
+		}
+	}
+
+	if e := o.d.Connect(ctx); e != nil {
+		return e
+	}
+
+	o.m.Lock()
+	o.x, o.n = context.WithCancel(context.Background())
+	x, lst := o.x, append(make([]subscription, 0, len(o.s)), o.s...)
+	o.m.Unlock()
+
+	for _, s := range lst {
+		if e := o.consume(x, s); e != nil {
+			_ = o.runStop(ctx)
+			return e
+		}
+	}
+
+	return o.r.Start(ctx)
+}
+
+func (o *que) Stop(ctx context.Context) error {
+	if !o.r.IsRunning() {
+		return o.runStop(ctx)
+	}
+
+	return o.r.Stop(ctx)
+}
+
+func (o *que) Restart(ctx context.Context) error {
+	if e := o.Stop(ctx); e != nil {
+		return e
+	}
+
+	return o.Start(ctx)
+}
+
+func (o *que) IsRunning() bool {
+	return o.r.IsRunning()
+}
+
+func (o *que) Uptime() time.Duration {
+	return o.r.Uptime()
+}
+
+func (o *que) runStart(ctx context.Context) error {
+	<-ctx.Done()
+	return nil
+}
+
+func (o *que) runStop(ctx context.Context) error {
+	o.m.Lock()
+
+	if o.n != nil {
+		o.n()
+	}
+
+	o.x, o.n = nil, nil
+	o.m.Unlock()
+
+	return o.d.Close()
+}
+
+func (o *que) Publish(ctx context.Context, topic string, msg Publishing) error {
+	if len(topic) < 1 {
+		return ErrorParamEmpty.Error(nil)
+	}
+
+	return o.d.Publish(ctx, topic, msg)
+}
+
+func (o *que) Subscribe(topic, group string, hdl Handler) error {
+	if len(topic) < 1 || hdl == nil {
+		return ErrorParamEmpty.Error(nil)
+	}
+
+	var s = subscription{
+		t: topic,
+		g: group,
+		h: hdl,
+	}
+
+	o.m.Lock()
+	o.s = append(o.s, s)
+	x := o.x
+	o.m.Unlock()
+
+	if x == nil {
+		return nil
+	}
+
+	return o.consume(x, s)
+}
+
+func (o *que) consume(ctx context.Context, s subscription) error {
+	for i := 0; i < o.c.Concurrency; i++ {
+		if e := o.d.Consume(ctx, s.t, s.g, o.deliver(ctx, s)); e != nil {
+			return e
+		}
+	}
+
+	return nil
+}
+
+func (o *que) deliver(ctx context.Context, s subscription) quetps.FuncDelivery {
+	return func(msg quetps.Message) {
+		err := o.handle(ctx, s.h, msg)
+
+		if err == nil {
+			o.ack(msg)
+			return
+		}
+
+		ent := o.logger().Entry(loglvl.ErrorLevel, "processing message of queue topic '%s' (attempt %d)", msg.Topic(), msg.Attempt())
+		ent.FieldAdd("queue.id", msg.ID())
+		ent.FieldAdd("queue.group", s.g)
+		ent.ErrorAdd(true, err)
+		ent.Log()
+
+		if o.c.MaxDeliver > 0 && msg.Attempt() >= o.c.MaxDeliver {
+			if e := o.deadLetter(ctx, msg, err); e != nil {
+				ent = o.logger().Entry(loglvl.ErrorLevel, "dropping message of queue topic '%s'", msg.Topic())
+				ent.FieldAdd("queue.id", msg.ID())
+				ent.ErrorAdd(true, e)
+				ent.Log()
+			}
+
+			o.ack(msg)
+			return
+		}
+
+		if e := msg.Nack(o.backoff(msg.Attempt())); e != nil {
+			o.logger().Entry(loglvl.WarnLevel, "nack message of queue topic '%s'", msg.Topic()).ErrorAdd(true, e).Log()
+		}
+	}
+}
+
+func (o *que) ack(msg quetps.Message) {
+	if e := msg.Ack(); e != nil {
+		o.logger().Entry(loglvl.WarnLevel, "ack message of queue topic '%s'", msg.Topic()).ErrorAdd(true, e).Log()
+	}
+}
+
+func (o *que) handle(ctx context.Context, hdl quetps.Handler, msg quetps.Message) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = ErrorHandlerPanic.Error(fmt.Errorf("%v", r))
+		}
+	}()
+
+	return hdl(ctx, msg)
+}
+
+// backoff returns the delay before the next delivery of the message, doubled on each attempt.
+func (o *que) backoff(attempt int) time.Duration {
+	var (
+		d = o.c.RetryDelay.Time()
+		m = o.c.RetryMaxDelay.Time()
+	)
+
+	for i := 1; i < attempt && d < m; i++ {
+		d *= 2
+	}
+
+	if d > m {
+		return m
+	}
+
+	return d
+}
+
+// deadLetter publishes the message into the dead letter topic if defined. The original topic and
+// the last error are added into the header of the message.
+func (o *que) deadLetter(ctx context.Context, msg quetps.Message, err error) error {
+	if len(o.c.DeadLetter) < 1 {
+		return ErrorDeadLetter.Error(err)
+	}
+
+	var h = make(map[string]string)
+
+	for k, v := range msg.Header() {
+		h[k] = v
+	}
+
+	h[HeaderDeadTopic] = msg.Topic()
+	h[HeaderDeadError] = err.Error()
+
+	if e := o.d.Publish(ctx, o.c.DeadLetter, Publishing{
+		Key:    msg.Key(),
+		Data:   msg.Data(),
+		Header: h,
+	}); e != nil {
+		return ErrorDeadLetter.Error(e)
+	}
+
+	return nil
+}
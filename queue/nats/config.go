@@ -0,0 +1,100 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package nats
+
+import (
+	"fmt"
+	"time"
+
+	libval "github.com/go-playground/validator/v10"
+	libtls "github.com/nabbar/golib/certificates"
+	libdur "github.com/nabbar/golib/duration"
+)
+
+const (
+	// DefaultAckWait is the default duration a delivered message waits for an acknowledgement before redelivery.
+	DefaultAckWait = 30 * time.Second
+)
+
+type Config struct {
+	// Servers is the list of nats servers url.
+	Servers []string `json:"servers" yaml:"servers" toml:"servers" mapstructure:"servers" validate:"required,min=1,dive,url"`
+
+	// Name is an optional name label which will be sent to the server on CONNECT to identify the client.
+	Name string `json:"name,omitempty" yaml:"name,omitempty" toml:"name,omitempty" mapstructure:"name,omitempty"`
+
+	// User sets the username to be used when connecting to the server.
+	User string `json:"user,omitempty" yaml:"user,omitempty" toml:"user,omitempty" mapstructure:"user,omitempty"`
+
+	// Password sets the password to be used when connecting to a server.
+	Password string `json:"password,omitempty" yaml:"password,omitempty" toml:"password,omitempty" mapstructure:"password,omitempty"`
+
+	// Token sets the token to be used when connecting to a server.
+	Token string `json:"token,omitempty" yaml:"token,omitempty" toml:"token,omitempty" mapstructure:"token,omitempty"`
+
+	// Secure enables TLS connections with the TLS config.
+	Secure bool `json:"secure,omitempty" yaml:"secure,omitempty" toml:"secure,omitempty" mapstructure:"secure,omitempty"`
+
+	// TLS is the TLS configuration used if secure is enabled.
+	TLS libtls.Config `json:"tls,omitempty" yaml:"tls,omitempty" toml:"tls,omitempty" mapstructure:"tls,omitempty"`
+
+	// Stream is the name of the jetstream stream storing the messages, created or updated on connection.
+	Stream string `json:"stream" yaml:"stream" toml:"stream" mapstructure:"stream" validate:"required,printascii,excludesall=.*> "`
+
+	// Subjects is the list of subjects stored into the stream, the topics must match one of them.
+	// By default, the stream name followed by '.>'.
+	Subjects []string `json:"subjects,omitempty" yaml:"subjects,omitempty" toml:"subjects,omitempty" mapstructure:"subjects,omitempty"`
+
+	// Replicas is the number of replicas of the stream into a nats cluster. By default, 1.
+	Replicas int `json:"replicas,omitempty" yaml:"replicas,omitempty" toml:"replicas,omitempty" mapstructure:"replicas,omitempty" validate:"gte=0,lte=5"`
+
+	// MaxAge is the maximum age of the messages into the stream. By default, unlimited.
+	MaxAge libdur.Duration `json:"max-age,omitempty" yaml:"max-age,omitempty" toml:"max-age,omitempty" mapstructure:"max-age,omitempty"`
+
+	// AckWait is the duration a delivered message waits for an acknowledgement before redelivery. By default, 30s.
+	AckWait libdur.Duration `json:"ack-wait,omitempty" yaml:"ack-wait,omitempty" toml:"ack-wait,omitempty" mapstructure:"ack-wait,omitempty"`
+}
+
+func (c Config) Validate() error {
+	err := ErrorValidatorError.Error(nil)
+
+	if er := libval.New().Struct(c); er != nil {
+		if e, ok := er.(*libval.InvalidValidationError); ok {
+			err.Add(e)
+		}
+
+		for _, e := range er.(libval.ValidationErrors) {
+			//nolint goerr113
+			err.Add(fmt.Errorf("config field '%s' is not validated by constraint '%s'", e.Namespace(), e.ActualTag()))
+		}
+	}
+
+	if err.HasParent() {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,362 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package nats
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	libdur "github.com/nabbar/golib/duration"
+	quetps "github.com/nabbar/golib/queue/types"
+	natcli "github.com/nats-io/nats.go"
+	natjst "github.com/nats-io/nats.go/jetstream"
+)
+
+const (
+	// HeaderKey is the header of the nats message storing the key of the message.
+	HeaderKey = "Queue-Key"
+)
+
+// New returns a nats jetstream queue driver. Each consumer group of a topic is a durable pull consumer
+// of the stream filtered on the topic, so the messages are kept by the stream until acknowledged.
+func New(cfg Config) (quetps.Driver, error) {
+	if e := cfg.Validate(); e != nil {
+		return nil, e
+	}
+
+	if len(cfg.Subjects) < 1 {
+		cfg.Subjects = []string{cfg.Stream + ".>"}
+	}
+
+	if cfg.Replicas < 1 {
+		cfg.Replicas = 1
+	}
+
+	if cfg.AckWait <= 0 {
+		cfg.AckWait = libdur.ParseDuration(DefaultAckWait)
+	}
+
+	return &drv{
+		c: cfg,
+	}, nil
+}
+
+type drv struct {
+	m sync.RWMutex
+	c Config
+	n *natcli.Conn
+	j natjst.JetStream
+	s natjst.Stream
+}
+
+func (o *drv) options() (natcli.Options, error) {
+	opt := natcli.GetDefaultOptions()
+	opt.Servers = o.c.Servers
+	opt.AllowReconnect = true
+	opt.MaxReconnect = -1
+
+	if o.c.Name != "" {
+		opt.Name = o.c.Name
+	}
+
+	if o.c.User != "" {
+		opt.User = o.c.User
+	}
+
+	if o.c.Password != "" {
+		opt.Password = o.c.Password
+	}
+
+	if o.c.Token != "" {
+		opt.Token = o.c.Token
+	}
+
+	if o.c.Secure {
+		if t := o.c.TLS.New(); t == nil {
+			return opt, ErrorConnect.Error(nil)
+		} else {
+			opt.TLSConfig = t.TlsConfig("")
+		}
+
+		opt.Secure = true
+	}
+
+	return opt, nil
+}
+
+func (o *drv) Connect(ctx context.Context) error {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if o.n != nil && !o.n.IsClosed() {
+		return nil
+	}
+
+	opt, err := o.options()
+
+	if err != nil {
+		return err
+	}
+
+	n, e := opt.Connect()
+
+	if e != nil {
+		return ErrorConnect.Error(e)
+	}
+
+	j, e := natjst.New(n)
+
+	if e != nil {
+		n.Close()
+		return ErrorConnect.Error(e)
+	}
+
+	s, e := j.CreateOrUpdateStream(ctx, natjst.StreamConfig{
+		Name:     o.c.Stream,
+		Subjects: o.c.Subjects,
+		Storage:  natjst.FileStorage,
+		Replicas: o.c.Replicas,
+		MaxAge:   o.c.MaxAge.Time(),
+	})
+
+	if e != nil {
+		n.Close()
+		return ErrorStream.Error(e)
+	}
+
+	o.n = n
+	o.j = j
+	o.s = s
+
+	return nil
+}
+
+func (o *drv) Close() error {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if o.n == nil {
+		return nil
+	}
+
+	e := o.n.Drain()
+
+	o.n = nil
+	o.j = nil
+	o.s = nil
+
+	if e != nil {
+		return ErrorConnect.Error(e)
+	}
+
+	return nil
+}
+
+func (o *drv) Health(ctx context.Context) error {
+	o.m.RLock()
+	n, j := o.n, o.j
+	o.m.RUnlock()
+
+	if n == nil || j == nil {
+		return ErrorNotConnected.Error(nil)
+	} else if s := n.Status(); s != natcli.CONNECTED {
+		return ErrorNotConnected.Error(nil)
+	} else if _, e := j.AccountInfo(ctx); e != nil {
+		return ErrorNotConnected.Error(e)
+	}
+
+	return nil
+}
+
+func (o *drv) Publish(ctx context.Context, topic string, pub quetps.Publishing) error {
+	if len(topic) < 1 {
+		return ErrorParamEmpty.Error(nil)
+	}
+
+	o.m.RLock()
+	j := o.j
+	o.m.RUnlock()
+
+	if j == nil {
+		return ErrorNotConnected.Error(nil)
+	}
+
+	m := natcli.NewMsg(topic)
+	m.Data = pub.Data
+
+	for k, v := range pub.Header {
+		m.Header.Set(k, v)
+	}
+
+	if pub.Key != "" {
+		m.Header.Set(HeaderKey, pub.Key)
+	}
+
+	if _, e := j.PublishMsg(ctx, m); e != nil {
+		return ErrorPublish.Error(e)
+	}
+
+	return nil
+}
+
+func (o *drv) Consume(ctx context.Context, topic, group string, fct quetps.FuncDelivery) error {
+	if len(topic) < 1 || fct == nil {
+		return ErrorParamEmpty.Error(nil)
+	}
+
+	o.m.RLock()
+	s := o.s
+	o.m.RUnlock()
+
+	if s == nil {
+		return ErrorNotConnected.Error(nil)
+	}
+
+	c, e := s.CreateOrUpdateConsumer(ctx, natjst.ConsumerConfig{
+		Durable:       durableName(group, topic),
+		FilterSubject: topic,
+		DeliverPolicy: natjst.DeliverAllPolicy,
+		AckPolicy:     natjst.AckExplicitPolicy,
+		AckWait:       o.c.AckWait.Time(),
+	})
+
+	if e != nil {
+		return ErrorConsumer.Error(e)
+	}
+
+	r, e := c.Consume(func(m natjst.Msg) {
+		fct(newMessage(m))
+	})
+
+	if e != nil {
+		return ErrorConsumer.Error(e)
+	}
+
+	go func() {
+		<-ctx.Done()
+		r.Stop()
+	}()
+
+	return nil
+}
+
+// durableName returns a valid durable consumer name for the group of the topic.
+func durableName(group, topic string) string {
+	var n = topic
+
+	if group != "" {
+		n = group + "-" + topic
+	}
+
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '.', '*', '>', ' ', '\t', '/', '\\':
+			return '_'
+		}
+
+		return r
+	}, n)
+}
+
+type msg struct {
+	m natjst.Msg
+	d *natjst.MsgMetadata
+}
+
+func newMessage(m natjst.Msg) *msg {
+	d, _ := m.Metadata()
+
+	return &msg{
+		m: m,
+		d: d,
+	}
+}
+
+func (m *msg) ID() string {
+	if m.d == nil {
+		return ""
+	}
+
+	return strconv.FormatUint(m.d.Sequence.Stream, 10)
+}
+
+func (m *msg) Topic() string {
+	return m.m.Subject()
+}
+
+func (m *msg) Key() string {
+	return m.m.Headers().Get(HeaderKey)
+}
+
+func (m *msg) Data() []byte {
+	return m.m.Data()
+}
+
+func (m *msg) Header() map[string]string {
+	var (
+		h = m.m.Headers()
+		r = make(map[string]string, len(h))
+	)
+
+	for k := range h {
+		if k != HeaderKey {
+			r[k] = h.Get(k)
+		}
+	}
+
+	return r
+}
+
+func (m *msg) Timestamp() time.Time {
+	if m.d == nil {
+		return time.Time{}
+	}
+
+	return m.d.Timestamp
+}
+
+func (m *msg) Attempt() int {
+	if m.d == nil {
+		return 1
+	}
+
+	return int(m.d.NumDelivered)
+}
+
+func (m *msg) Ack() error {
+	return m.m.Ack()
+}
+
+func (m *msg) Nack(delay time.Duration) error {
+	if delay > 0 {
+		return m.m.NakWithDelay(delay)
+	}
+
+	return m.m.Nak()
+}
@@ -0,0 +1,217 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package queue_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	libdur "github.com/nabbar/golib/duration"
+	libque "github.com/nabbar/golib/queue"
+	quekfk "github.com/nabbar/golib/queue/kafka"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func newQueue(cfg libque.Config) libque.Queue {
+	cfg.Driver = libque.DriverMemory
+	cfg.RetryDelay = libdur.ParseDuration(10 * time.Millisecond)
+	cfg.RetryMaxDelay = libdur.ParseDuration(50 * time.Millisecond)
+
+	q, e := libque.New(cfg)
+	Expect(e).ToNot(HaveOccurred())
+	Expect(q).ToNot(BeNil())
+
+	return q
+}
+
+var _ = Describe("Queue", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	Context("config", func() {
+		It("must reject an unknown driver", func() {
+			_, e := libque.New(libque.Config{Driver: "amqp"})
+			Expect(e).To(HaveOccurred())
+		})
+
+		It("must validate the nats config with the nats driver", func() {
+			Expect(libque.Config{Driver: libque.DriverNats}.Validate()).To(HaveOccurred())
+			Expect(libque.Config{Driver: libque.DriverMemory}.Validate()).ToNot(HaveOccurred())
+		})
+
+		It("must validate the kafka config with the kafka driver", func() {
+			Expect(libque.Config{Driver: libque.DriverKafka}.Validate()).To(HaveOccurred())
+			Expect(libque.Config{Driver: libque.DriverKafka, Kafka: quekfk.Config{
+				Brokers: []string{"localhost:9092"},
+			}}.Validate()).ToNot(HaveOccurred())
+		})
+	})
+
+	Context("lifecycle", func() {
+		It("must connect on start and close on stop", func() {
+			q := newQueue(libque.Config{})
+
+			Expect(q.HealthCheck(ctx)).To(HaveOccurred())
+			Expect(q.Publish(ctx, "topic", libque.Publishing{Data: []byte("x")})).To(HaveOccurred())
+
+			Expect(q.Start(ctx)).ToNot(HaveOccurred())
+			Eventually(q.IsRunning).Should(BeTrue())
+			Expect(q.HealthCheck(ctx)).ToNot(HaveOccurred())
+
+			Expect(q.Stop(ctx)).ToNot(HaveOccurred())
+			Expect(q.IsRunning()).To(BeFalse())
+			Expect(q.HealthCheck(ctx)).To(HaveOccurred())
+		})
+	})
+
+	Context("delivery", func() {
+		It("must deliver each message to each group once", func() {
+			var (
+				q = newQueue(libque.Config{Concurrency: 2})
+				a atomic.Int32
+				b atomic.Int32
+			)
+
+			Expect(q.Subscribe("topic", "a", func(ctx context.Context, msg libque.Message) error {
+				a.Add(1)
+				return nil
+			})).ToNot(HaveOccurred())
+
+			Expect(q.Subscribe("topic", "b", func(ctx context.Context, msg libque.Message) error {
+				b.Add(1)
+				return nil
+			})).ToNot(HaveOccurred())
+
+			Expect(q.Start(ctx)).ToNot(HaveOccurred())
+			defer func() { _ = q.Stop(ctx) }()
+
+			for i := 0; i < 10; i++ {
+				Expect(q.Publish(ctx, "topic", libque.Publishing{Data: []byte("x")})).ToNot(HaveOccurred())
+			}
+
+			Eventually(a.Load).Should(Equal(int32(10)))
+			Eventually(b.Load).Should(Equal(int32(10)))
+			Consistently(a.Load, 100*time.Millisecond).Should(Equal(int32(10)))
+		})
+
+		It("must keep key, data and header of messages", func() {
+			var (
+				q = newQueue(libque.Config{})
+				c = make(chan libque.Message, 1)
+			)
+
+			Expect(q.Start(ctx)).ToNot(HaveOccurred())
+			defer func() { _ = q.Stop(ctx) }()
+
+			Expect(q.Subscribe("topic", "", func(ctx context.Context, msg libque.Message) error {
+				c <- msg
+				return nil
+			})).ToNot(HaveOccurred())
+
+			Expect(q.Publish(ctx, "topic", libque.Publishing{
+				Key:    "key",
+				Data:   []byte("data"),
+				Header: map[string]string{"h": "v"},
+			})).ToNot(HaveOccurred())
+
+			var m libque.Message
+			Eventually(c).Should(Receive(&m))
+			Expect(m.Topic()).To(Equal("topic"))
+			Expect(m.Key()).To(Equal("key"))
+			Expect(m.Data()).To(Equal([]byte("data")))
+			Expect(m.Header()).To(HaveKeyWithValue("h", "v"))
+			Expect(m.Attempt()).To(Equal(1))
+		})
+
+		It("must redeliver a message until processed", func() {
+			var (
+				q = newQueue(libque.Config{})
+				n atomic.Int32
+				d atomic.Bool
+			)
+
+			Expect(q.Subscribe("topic", "g", func(ctx context.Context, msg libque.Message) error {
+				if n.Add(1) < 3 {
+					return errors.New("retry")
+				}
+
+				d.Store(msg.Attempt() == 3)
+				return nil
+			})).ToNot(HaveOccurred())
+
+			Expect(q.Start(ctx)).ToNot(HaveOccurred())
+			defer func() { _ = q.Stop(ctx) }()
+
+			Expect(q.Publish(ctx, "topic", libque.Publishing{Data: []byte("x")})).ToNot(HaveOccurred())
+
+			Eventually(d.Load).Should(BeTrue())
+			Consistently(n.Load, 100*time.Millisecond).Should(Equal(int32(3)))
+		})
+
+		It("must send to the dead letter topic after the max deliver", func() {
+			var (
+				q = newQueue(libque.Config{MaxDeliver: 2, DeadLetter: "dead"})
+				n atomic.Int32
+				m sync.Mutex
+				h map[string]string
+			)
+
+			Expect(q.Subscribe("topic", "g", func(ctx context.Context, msg libque.Message) error {
+				n.Add(1)
+				panic("failure")
+			})).ToNot(HaveOccurred())
+
+			Expect(q.Subscribe("dead", "g", func(ctx context.Context, msg libque.Message) error {
+				m.Lock()
+				defer m.Unlock()
+				h = msg.Header()
+				return nil
+			})).ToNot(HaveOccurred())
+
+			Expect(q.Start(ctx)).ToNot(HaveOccurred())
+			defer func() { _ = q.Stop(ctx) }()
+
+			Expect(q.Publish(ctx, "topic", libque.Publishing{Data: []byte("x")})).ToNot(HaveOccurred())
+
+			Eventually(func() map[string]string {
+				m.Lock()
+				defer m.Unlock()
+				return h
+			}).Should(HaveKeyWithValue(libque.HeaderDeadTopic, "topic"))
+
+			Expect(h).To(HaveKey(libque.HeaderDeadError))
+			Consistently(n.Load, 100*time.Millisecond).Should(Equal(int32(2)))
+		})
+	})
+})
@@ -0,0 +1,102 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package types
+
+import (
+	"context"
+	"time"
+)
+
+// Publishing is a message to publish on a topic.
+type Publishing struct {
+	// Key is an optional key of the message, used by the backend to keep the order of messages with the same key.
+	Key string
+	// Data is the payload of the message.
+	Data []byte
+	// Header is an optional list of metadata of the message.
+	Header map[string]string
+}
+
+// Message is a message delivered to a subscriber. Each message must be acknowledged with Ack,
+// or negatively acknowledged with Nack to be delivered again after the given delay.
+type Message interface {
+	// ID returns the identifier of the message into the backend.
+	ID() string
+	// Topic returns the topic of the message.
+	Topic() string
+	// Key returns the key of the message.
+	Key() string
+	// Data returns the payload of the message.
+	Data() []byte
+	// Header returns the metadata of the message.
+	Header() map[string]string
+	// Timestamp returns the time the message was stored by the backend.
+	Timestamp() time.Time
+	// Attempt returns the number of deliveries of the message, starting at 1.
+	Attempt() int
+
+	// Ack acknowledges the message, it will not be delivered again.
+	Ack() error
+	// Nack negatively acknowledges the message, it will be delivered again after the delay.
+	Nack(delay time.Duration) error
+}
+
+// Handler is called for each message delivered to a subscriber. The message is acknowledged
+// if the handler returns nil and negatively acknowledged otherwise.
+type Handler func(ctx context.Context, msg Message) error
+
+// FuncDelivery is called by a driver for each message delivered to a consumer.
+type FuncDelivery func(msg Message)
+
+// Publisher is used to publish messages on topics.
+type Publisher interface {
+	// Publish sends the message on the topic and returns once the backend stored it.
+	Publish(ctx context.Context, topic string, msg Publishing) error
+}
+
+// Subscriber is used to register handlers on topics.
+type Subscriber interface {
+	// Subscribe registers the handler for the topic into the consumer group. Each group receives all
+	// messages of the topic and the messages are shared between the subscribers of the same group.
+	Subscribe(topic, group string, hdl Handler) error
+}
+
+// Driver is the interface implemented by the queue backends.
+type Driver interface {
+	// Connect opens the connection to the backend.
+	Connect(ctx context.Context) error
+	// Close closes the connection to the backend and stops all consumers.
+	Close() error
+	// Health returns an error if the backend is not reachable.
+	Health(ctx context.Context) error
+
+	// Publish sends the message on the topic and returns once the backend stored it.
+	Publish(ctx context.Context, topic string, msg Publishing) error
+
+	// Consume starts a consumer of the topic into the consumer group and calls the function for each
+	// delivered message, one at a time. The consumer is stopped when the context is done.
+	Consume(ctx context.Context, topic, group string, fct FuncDelivery) error
+}
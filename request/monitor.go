@@ -32,7 +32,6 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
-	"runtime"
 
 	logent "github.com/nabbar/golib/logger/entry"
 	loglvl "github.com/nabbar/golib/logger/level"
@@ -174,10 +173,9 @@ func (r *request) Monitor(ctx context.Context, vrs libver.Version) (montps.Monit
 		return nil, fmt.Errorf("cannot load options")
 	}
 
-	res["runtime"] = runtime.Version()[2:]
-	res["release"] = vrs.GetRelease()
-	res["build"] = vrs.GetBuild()
-	res["date"] = vrs.GetDate()
+	for k, v := range vrs.GetInfoMap() {
+		res[k] = v
+	}
 
 	if inf, e = moninf.New(defaultNameMonitor); e != nil {
 		return nil, e
@@ -36,6 +36,7 @@ const (
 	GinContextStartUnixNanoTime = "gin-ctx-start-unix-nano-time"
 	GinContextRequestPath       = "gin-ctx-request-path"
 	GinContextRequestUser       = "gin-ctx-request-user"
+	GinContextRequestID         = "gin-ctx-request-id"
 )
 
 var (
@@ -38,6 +38,7 @@ import (
 	liberr "github.com/nabbar/golib/errors"
 	liblog "github.com/nabbar/golib/logger"
 	loglvl "github.com/nabbar/golib/logger/level"
+	libtrc "github.com/nabbar/golib/tracing"
 )
 
 func GinLatencyContext(c *ginsdk.Context) {
@@ -77,6 +78,25 @@ func GinRequestContext(c *ginsdk.Context) {
 	c.Next()
 }
 
+// GinTracing store the request id and the W3C trace context of each request into the request context,
+// making them available to logger entries and to outgoing requests (see package tracing).
+func GinTracing(cfg libtrc.Config) ginsdk.HandlerFunc {
+	return func(c *ginsdk.Context) {
+		var t = libtrc.FromHeader(c.Request.Header, cfg.TrustIncoming)
+
+		if cfg.ResponseHeader {
+			c.Header(libtrc.HeaderRequestID, t.RequestID)
+			c.Header(libtrc.HeaderTraceParent, t.TraceParent())
+		}
+
+		c.Set(GinContextRequestID, t.RequestID)
+		c.Request = c.Request.WithContext(libtrc.WithContext(c.Request.Context(), t))
+
+		// Process request
+		c.Next()
+	}
+}
+
 func GinAccessLog(log liblog.FuncLog) ginsdk.HandlerFunc {
 	return func(c *ginsdk.Context) {
 		// Process request
@@ -153,12 +173,14 @@ func GinErrorLog(log liblog.FuncLog) ginsdk.HandlerFunc {
 				if len(c.Errors) > 0 {
 					for _, e := range c.Errors {
 						ent := l.Entry(loglvl.ErrorLevel, "error on request \"%s %s %s\"", c.Request.Method, path, c.Request.Proto)
+						ent.SetContext(c.Request.Context())
 						ent.ErrorAdd(true, e)
 						ent.Check(loglvl.NilLevel)
 					}
 				}
 				if rec != nil {
 					ent := l.Entry(loglvl.ErrorLevel, "error on request \"%s %s %s\"", c.Request.Method, path, c.Request.Proto)
+					ent.SetContext(c.Request.Context())
 					ent.ErrorAdd(true, rec)
 					ent.Check(loglvl.NilLevel)
 				}
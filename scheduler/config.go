@@ -0,0 +1,135 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package scheduler
+
+import (
+	"fmt"
+	"time"
+
+	libval "github.com/go-playground/validator/v10"
+	libdur "github.com/nabbar/golib/duration"
+	schcrn "github.com/nabbar/golib/scheduler/cron"
+)
+
+const (
+	// OverlapSkip skips a run if the previous run of the job is not finished.
+	OverlapSkip = "skip"
+	// OverlapAllow starts a run even if the previous runs of the job are not finished.
+	OverlapAllow = "allow"
+	// OverlapReplace cancels the context of the previous runs of the job not finished before starting a new run.
+	OverlapReplace = "replace"
+
+	// DefaultLockTTL is the duration of the distributed lock of a singleton job without timeout.
+	DefaultLockTTL = time.Hour
+)
+
+type JobConfig struct {
+	// Name is the unique name of the job.
+	Name string `json:"name" yaml:"name" toml:"name" mapstructure:"name" validate:"required,printascii"`
+
+	// Schedule is the cron expression of the job (see the cron package), required if no interval is defined.
+	Schedule string `json:"schedule,omitempty" yaml:"schedule,omitempty" toml:"schedule,omitempty" mapstructure:"schedule,omitempty" validate:"required_without=Interval"`
+
+	// Interval is the fixed duration between two runs of the job, used if no schedule is defined.
+	Interval libdur.Duration `json:"interval,omitempty" yaml:"interval,omitempty" toml:"interval,omitempty" mapstructure:"interval,omitempty"`
+
+	// TimeZone is the IANA name of the location of the cron expression. By default, the local time zone.
+	TimeZone string `json:"timezone,omitempty" yaml:"timezone,omitempty" toml:"timezone,omitempty" mapstructure:"timezone,omitempty" validate:"omitempty,timezone"`
+
+	// Timeout is the maximum duration of a run, the context of the run is cancelled after. By default, no timeout.
+	Timeout libdur.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty" toml:"timeout,omitempty" mapstructure:"timeout,omitempty"`
+
+	// Jitter is the maximum random delay added to each activation time to spread the runs.
+	Jitter libdur.Duration `json:"jitter,omitempty" yaml:"jitter,omitempty" toml:"jitter,omitempty" mapstructure:"jitter,omitempty"`
+
+	// Overlap is the policy applied when a run starts while a previous run is not finished,
+	// one of 'skip', 'allow' or 'replace'. By default, 'skip'.
+	Overlap string `json:"overlap,omitempty" yaml:"overlap,omitempty" toml:"overlap,omitempty" mapstructure:"overlap,omitempty" validate:"omitempty,oneof=skip allow replace"`
+
+	// Singleton enables the distributed lock of the scheduler to allow only one run
+	// of the job at a time across all instances sharing the locker.
+	Singleton bool `json:"singleton,omitempty" yaml:"singleton,omitempty" toml:"singleton,omitempty" mapstructure:"singleton,omitempty"`
+}
+
+func (c JobConfig) Validate() error {
+	err := ErrorValidatorError.Error(nil)
+
+	if er := libval.New().Struct(c); er != nil {
+		if e, ok := er.(*libval.InvalidValidationError); ok {
+			err.Add(e)
+		}
+
+		for _, e := range er.(libval.ValidationErrors) {
+			//nolint goerr113
+			err.Add(fmt.Errorf("config field '%s' is not validated by constraint '%s'", e.Namespace(), e.ActualTag()))
+		}
+	}
+
+	if c.Interval < 0 || c.Timeout < 0 || c.Jitter < 0 {
+		//nolint goerr113
+		err.Add(fmt.Errorf("config durations must not be negative"))
+	}
+
+	if len(c.Schedule) > 0 {
+		if _, e := c.schedule(); e != nil {
+			err.Add(e)
+		}
+	}
+
+	if err.HasParent() {
+		return err
+	}
+
+	return nil
+}
+
+// schedule returns the schedule of the job from the cron expression or the interval.
+func (c JobConfig) schedule() (schcrn.Schedule, error) {
+	if len(c.Schedule) < 1 {
+		return schcrn.Every(c.Interval.Time()), nil
+	}
+
+	var loc *time.Location
+
+	if len(c.TimeZone) > 0 {
+		if l, e := time.LoadLocation(c.TimeZone); e != nil {
+			return nil, e
+		} else {
+			loc = l
+		}
+	}
+
+	return schcrn.ParseInLocation(c.Schedule, loc)
+}
+
+// lockTTL returns the duration of the distributed lock of the job.
+func (c JobConfig) lockTTL() time.Duration {
+	if c.Timeout > 0 {
+		return c.Timeout.Time()
+	}
+
+	return DefaultLockTTL
+}
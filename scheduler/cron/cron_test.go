@@ -0,0 +1,92 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package cron_test
+
+import (
+	"time"
+
+	schcrn "github.com/nabbar/golib/scheduler/cron"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func date(s string) time.Time {
+	t, e := time.ParseInLocation("2006-01-02 15:04:05", s, time.UTC)
+	Expect(e).ToNot(HaveOccurred())
+	return t
+}
+
+var _ = Describe("Cron", func() {
+	DescribeTable("next activation",
+		func(expr, from, next string) {
+			s, e := schcrn.Parse(expr)
+			Expect(e).ToNot(HaveOccurred())
+			Expect(s.Next(date(from))).To(Equal(date(next)))
+		},
+		Entry("every minute", "* * * * *", "2024-03-10 10:20:30", "2024-03-10 10:21:00"),
+		Entry("every second", "* * * * * *", "2024-03-10 10:20:30", "2024-03-10 10:20:31"),
+		Entry("step of minutes", "*/15 * * * *", "2024-03-10 10:20:30", "2024-03-10 10:30:00"),
+		Entry("range and list", "0 9-17/4,22 * * *", "2024-03-10 13:00:00", "2024-03-10 17:00:00"),
+		Entry("next day", "30 8 * * *", "2024-03-10 10:20:30", "2024-03-11 08:30:00"),
+		Entry("day of week name", "0 0 * * MON", "2024-03-10 10:20:30", "2024-03-11 00:00:00"),
+		Entry("sunday as 7", "0 0 * * 7", "2024-03-11 10:20:30", "2024-03-17 00:00:00"),
+		Entry("month name", "0 0 1 JUN *", "2024-03-10 10:20:30", "2024-06-01 00:00:00"),
+		Entry("day of month or week", "0 0 15 * FRI", "2024-03-10 10:20:30", "2024-03-15 00:00:00"),
+		Entry("leap day", "0 0 29 2 *", "2024-03-10 10:20:30", "2028-02-29 00:00:00"),
+		Entry("yearly", "@yearly", "2024-03-10 10:20:30", "2025-01-01 00:00:00"),
+		Entry("hourly", "@hourly", "2024-03-10 10:20:30", "2024-03-10 11:00:00"),
+		Entry("every duration", "@every 90s", "2024-03-10 10:20:30", "2024-03-10 10:22:00"),
+	)
+
+	DescribeTable("invalid expression",
+		func(expr string) {
+			_, e := schcrn.Parse(expr)
+			Expect(e).To(HaveOccurred())
+		},
+		Entry("empty", ""),
+		Entry("too few fields", "* * * *"),
+		Entry("out of range", "60 * * * *"),
+		Entry("reversed range", "0 10-5 * * *"),
+		Entry("zero step", "*/0 * * * *"),
+		Entry("unknown name", "0 0 * FOO *"),
+		Entry("unknown descriptor", "@sometimes"),
+		Entry("invalid duration", "@every soon"),
+	)
+
+	It("must not find an impossible date", func() {
+		s, e := schcrn.Parse("0 0 30 2 *")
+		Expect(e).ToNot(HaveOccurred())
+		Expect(s.Next(date("2024-03-10 10:20:30")).IsZero()).To(BeTrue())
+	})
+
+	It("must compute into the given location", func() {
+		loc := time.FixedZone("UTC+2", 2*3600)
+		s, e := schcrn.ParseInLocation("0 8 * * *", loc)
+		Expect(e).ToNot(HaveOccurred())
+		Expect(s.Next(date("2024-03-10 10:20:30"))).To(Equal(date("2024-03-11 06:00:00")))
+	})
+})
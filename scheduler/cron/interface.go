@@ -0,0 +1,68 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package cron
+
+import (
+	"time"
+)
+
+// Schedule returns the next activation time of a job.
+type Schedule interface {
+	// Next returns the next activation time strictly after the given time,
+	// or the zero time if no activation can be found.
+	Next(t time.Time) time.Time
+}
+
+// Parse parses a cron expression into a schedule using the location of the given times. The expression is
+// either a descriptor or a list of 5 or 6 fields separated by spaces:
+//
+//	[second] minute hour day-of-month month day-of-week
+//
+// Each field accepts '*' (or '?'), a value, a range 'a-b', a list 'a,b' and a step '*/n' or 'a-b/n'.
+// Months and days of week accept the 3 letters english names (JAN-DEC, SUN-SAT), day of week 7 is sunday.
+//
+// The descriptors are '@yearly' (or '@annually'), '@monthly', '@weekly', '@daily' (or '@midnight'),
+// '@hourly' and '@every <duration>'.
+func Parse(expr string) (Schedule, error) {
+	return ParseInLocation(expr, nil)
+}
+
+// ParseInLocation parses a cron expression like Parse but computes the activation
+// times into the given location.
+func ParseInLocation(expr string, loc *time.Location) (Schedule, error) {
+	return parse(expr, loc)
+}
+
+// Every returns a schedule activating every given duration, rounded to the second if upper than one second.
+func Every(d time.Duration) Schedule {
+	if d <= 0 {
+		d = time.Second
+	}
+
+	return &every{
+		d: d,
+	}
+}
@@ -0,0 +1,229 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	starBit = 1 << 63
+)
+
+type bounds struct {
+	n string
+	l uint
+	h uint
+	m map[string]uint
+}
+
+var (
+	bndSecond = bounds{n: "second", l: 0, h: 59}
+	bndMinute = bounds{n: "minute", l: 0, h: 59}
+	bndHour   = bounds{n: "hour", l: 0, h: 23}
+	bndDom    = bounds{n: "day-of-month", l: 1, h: 31}
+	bndMonth  = bounds{n: "month", l: 1, h: 12, m: map[string]uint{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	bndDow = bounds{n: "day-of-week", l: 0, h: 7, m: map[string]uint{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 0 1 1 *",
+	"@annually": "0 0 0 1 1 *",
+	"@monthly":  "0 0 0 1 * *",
+	"@weekly":   "0 0 0 * * 0",
+	"@daily":    "0 0 0 * * *",
+	"@midnight": "0 0 0 * * *",
+	"@hourly":   "0 0 * * * *",
+}
+
+func parse(expr string, loc *time.Location) (Schedule, error) {
+	expr = strings.TrimSpace(expr)
+
+	if len(expr) < 1 {
+		return nil, ErrorParamEmpty.Error(nil)
+	}
+
+	if strings.HasPrefix(expr, "@") {
+		if strings.HasPrefix(expr, "@every ") {
+			d, e := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(expr, "@every ")))
+
+			if e != nil || d <= 0 {
+				return nil, ErrorParseDescriptor.Error(fmt.Errorf("invalid duration in '%s'", expr))
+			}
+
+			return Every(d), nil
+		} else if s, k := descriptors[strings.ToLower(expr)]; k {
+			expr = s
+		} else {
+			return nil, ErrorParseDescriptor.Error(fmt.Errorf("unknown descriptor '%s'", expr))
+		}
+	}
+
+	fld := strings.Fields(expr)
+
+	switch len(fld) {
+	case 5:
+		fld = append([]string{"0"}, fld...)
+	case 6:
+	default:
+		return nil, ErrorParseFields.Error(fmt.Errorf("found %d fields in '%s'", len(fld), expr))
+	}
+
+	var (
+		e error
+		s = &spec{
+			l: loc,
+		}
+	)
+
+	if s.sec, e = parseField(fld[0], bndSecond); e != nil {
+		return nil, e
+	} else if s.min, e = parseField(fld[1], bndMinute); e != nil {
+		return nil, e
+	} else if s.hour, e = parseField(fld[2], bndHour); e != nil {
+		return nil, e
+	} else if s.dom, e = parseField(fld[3], bndDom); e != nil {
+		return nil, e
+	} else if s.month, e = parseField(fld[4], bndMonth); e != nil {
+		return nil, e
+	} else if s.dow, e = parseField(fld[5], bndDow); e != nil {
+		return nil, e
+	}
+
+	// sunday could be 0 or 7
+	if s.dow&(1<<7) != 0 {
+		s.dow = (s.dow &^ (1 << 7)) | 1
+	}
+
+	return s, nil
+}
+
+// parseField returns the bits of the values matching the field, each item of the list is
+// a range with an optional step.
+func parseField(fld string, b bounds) (uint64, error) {
+	var res uint64
+
+	for _, r := range strings.Split(fld, ",") {
+		if v, e := parseRange(r, b); e != nil {
+			return 0, ErrorParseField.Error(fmt.Errorf("%s field '%s': %v", b.n, fld, e))
+		} else {
+			res |= v
+		}
+	}
+
+	return res, nil
+}
+
+func parseRange(expr string, b bounds) (uint64, error) {
+	var (
+		e    error
+		bgn  uint
+		end  uint
+		stp  uint = 1
+		ext  uint64
+		rng  = strings.Split(expr, "/")
+		part = strings.Split(rng[0], "-")
+	)
+
+	if len(rng) > 2 || len(part) > 2 {
+		return 0, fmt.Errorf("invalid range '%s'", expr)
+	}
+
+	if part[0] == "*" || part[0] == "?" {
+		if len(part) > 1 {
+			return 0, fmt.Errorf("invalid range '%s'", expr)
+		}
+
+		bgn, end, ext = b.l, b.h, starBit
+	} else if bgn, e = parseValue(part[0], b); e != nil {
+		return 0, e
+	} else if len(part) > 1 {
+		if end, e = parseValue(part[1], b); e != nil {
+			return 0, e
+		}
+	} else {
+		end = bgn
+	}
+
+	if len(rng) > 1 {
+		if stp, e = parseUint(rng[1]); e != nil {
+			return 0, e
+		} else if stp < 1 {
+			return 0, fmt.Errorf("step must be positive in '%s'", expr)
+		}
+
+		// 'a/n' means from a to the max by step of n
+		if len(part) == 1 && ext == 0 {
+			end = b.h
+		}
+
+		if stp > 1 {
+			ext = 0
+		}
+	}
+
+	if bgn < b.l || end > b.h {
+		return 0, fmt.Errorf("value out of range [%d-%d] in '%s'", b.l, b.h, expr)
+	} else if bgn > end {
+		return 0, fmt.Errorf("begin of range is upper than end in '%s'", expr)
+	}
+
+	var res = ext
+
+	for i := bgn; i <= end; i += stp {
+		res |= 1 << i
+	}
+
+	return res, nil
+}
+
+func parseValue(val string, b bounds) (uint, error) {
+	if b.m != nil {
+		if v, k := b.m[strings.ToLower(val)]; k {
+			return v, nil
+		}
+	}
+
+	return parseUint(val)
+}
+
+func parseUint(val string) (uint, error) {
+	v, e := strconv.ParseUint(val, 10, 8)
+
+	if e != nil {
+		return 0, fmt.Errorf("invalid number '%s'", val)
+	}
+
+	return uint(v), nil
+}
@@ -0,0 +1,167 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package cron
+
+import (
+	"time"
+)
+
+type spec struct {
+	sec   uint64
+	min   uint64
+	hour  uint64
+	dom   uint64
+	month uint64
+	dow   uint64
+	l     *time.Location
+}
+
+// Next returns the next activation time of the spec. The fields are searched from the month
+// to the second: when a field does not match, it's incremented and all lower fields are reset.
+func (s *spec) Next(t time.Time) time.Time {
+	var (
+		org = t.Location()
+		loc = s.l
+		add = false
+	)
+
+	if loc == nil {
+		loc = org
+	}
+
+	t = t.In(loc)
+	t = t.Add(time.Second - time.Duration(t.Nanosecond())*time.Nanosecond)
+
+	lim := t.Year() + 5
+
+wrap:
+	if t.Year() > lim {
+		return time.Time{}
+	}
+
+	for (1<<uint(t.Month()))&s.month == 0 {
+		if !add {
+			add = true
+			t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+		}
+
+		t = t.AddDate(0, 1, 0)
+
+		if t.Month() == time.January {
+			goto wrap
+		}
+	}
+
+	for !s.dayMatch(t) {
+		if !add {
+			add = true
+			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+		}
+
+		t = t.AddDate(0, 0, 1)
+
+		// daylight saving time could shift the midnight
+		if h := t.Hour(); h != 0 {
+			if h > 12 {
+				t = t.Add(time.Duration(24-h) * time.Hour)
+			} else {
+				t = t.Add(-time.Duration(h) * time.Hour)
+			}
+		}
+
+		if t.Day() == 1 {
+			goto wrap
+		}
+	}
+
+	for (1<<uint(t.Hour()))&s.hour == 0 {
+		if !add {
+			add = true
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, loc)
+		}
+
+		t = t.Add(time.Hour)
+
+		if t.Hour() == 0 {
+			goto wrap
+		}
+	}
+
+	for (1<<uint(t.Minute()))&s.min == 0 {
+		if !add {
+			add = true
+			t = t.Truncate(time.Minute)
+		}
+
+		t = t.Add(time.Minute)
+
+		if t.Minute() == 0 {
+			goto wrap
+		}
+	}
+
+	for (1<<uint(t.Second()))&s.sec == 0 {
+		if !add {
+			add = true
+			t = t.Truncate(time.Second)
+		}
+
+		t = t.Add(time.Second)
+
+		if t.Second() == 0 {
+			goto wrap
+		}
+	}
+
+	return t.In(org)
+}
+
+// dayMatch returns true if the day matches the day of month and the day of week. If both are
+// restricted, the day matches if one of them matches, as the standard cron.
+func (s *spec) dayMatch(t time.Time) bool {
+	var (
+		dom = (1<<uint(t.Day()))&s.dom > 0
+		dow = (1<<uint(t.Weekday()))&s.dow > 0
+	)
+
+	if s.dom&starBit > 0 || s.dow&starBit > 0 {
+		return dom && dow
+	}
+
+	return dom || dow
+}
+
+type every struct {
+	d time.Duration
+}
+
+func (e *every) Next(t time.Time) time.Time {
+	if e.d < time.Second {
+		return t.Add(e.d)
+	}
+
+	return t.Add(e.d - time.Duration(t.Nanosecond())*time.Nanosecond)
+}
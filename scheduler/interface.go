@@ -0,0 +1,101 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	libctx "github.com/nabbar/golib/context"
+	liblog "github.com/nabbar/golib/logger"
+	montps "github.com/nabbar/golib/monitor/types"
+	libsrv "github.com/nabbar/golib/server"
+	librun "github.com/nabbar/golib/server/runner/startStop"
+	libver "github.com/nabbar/golib/version"
+)
+
+// FuncJob is the function of a job, the context is cancelled on timeout, on stop of the scheduler
+// or when the run is replaced by a new one.
+type FuncJob func(ctx context.Context) error
+
+// JobStatus is the status of the runs of a job.
+type JobStatus struct {
+	Name         string
+	Running      int
+	Next         time.Time
+	LastStart    time.Time
+	LastEnd      time.Time
+	LastDuration time.Duration
+	LastError    error
+	Runs         uint64
+	Failures     uint64
+	Skipped      uint64
+}
+
+type Scheduler interface {
+	libsrv.Server
+
+	// SetLogger is used to define the logger used to trace the runs of the jobs.
+	SetLogger(fct liblog.FuncLog)
+
+	// SetLocker is used to define the distributed lock used by the singleton jobs.
+	// Without locker, the singleton jobs are only protected by their overlap policy.
+	SetLocker(lck Locker)
+
+	// Add registers a new job. If the scheduler is running, the job is scheduled immediately.
+	Add(cfg JobConfig, fct FuncJob) error
+
+	// Remove unregisters the job and cancels its runs not finished.
+	Remove(name string) error
+
+	// List returns the name of all registered jobs.
+	List() []string
+
+	// Status returns the status of the runs of the job.
+	Status(name string) (JobStatus, error)
+
+	// Trigger starts a run of the job now, out of its schedule, following its overlap policy.
+	Trigger(name string) error
+
+	// HealthCheck returns an error if the scheduler is not running or if the last run of a job has failed.
+	HealthCheck(ctx context.Context) error
+
+	// Monitor returns a monitor of the scheduler with the status of the jobs.
+	Monitor(ctx libctx.FuncContext, vrs libver.Version) (montps.Monitor, error)
+}
+
+// New returns a scheduler without job. The jobs are scheduled between start and stop.
+func New() Scheduler {
+	o := &sch{
+		m: sync.RWMutex{},
+		j: make(map[string]*job),
+	}
+
+	o.r = librun.New(o.runStart, o.runStop)
+
+	return o
+}
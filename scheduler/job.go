@@ -0,0 +1,186 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package scheduler
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	schcrn "github.com/nabbar/golib/scheduler/cron"
+)
+
+type job struct {
+	m sync.Mutex
+	c JobConfig
+	s schcrn.Schedule
+	f FuncJob
+	n context.CancelFunc            // schedule cancel
+	r map[uint64]context.CancelFunc // runs not finished
+	i uint64                        // run sequence
+	t JobStatus
+}
+
+// start launches the schedule of the job until the context is done.
+func (j *job) start(ctx context.Context, o *sch) {
+	x, n := context.WithCancel(ctx)
+
+	j.m.Lock()
+
+	if j.n != nil {
+		j.n()
+	}
+
+	j.n = n
+	j.m.Unlock()
+
+	go j.loop(x, o)
+}
+
+func (j *job) stop() {
+	j.m.Lock()
+	defer j.m.Unlock()
+
+	if j.n != nil {
+		j.n()
+		j.n = nil
+	}
+}
+
+// loop waits for each activation time of the schedule, delayed by the jitter, and runs the job.
+// The next activation is computed from the previous one (without jitter) to keep the schedule.
+func (j *job) loop(ctx context.Context, o *sch) {
+	var t = time.Now()
+
+	defer j.setNext(time.Time{})
+
+	for {
+		if n := time.Now(); t.Before(n) {
+			t = n
+		}
+
+		if t = j.s.Next(t); t.IsZero() {
+			return
+		}
+
+		a := t.Add(j.jitter())
+		j.setNext(a)
+
+		tck := time.NewTimer(time.Until(a))
+
+		select {
+		case <-ctx.Done():
+			tck.Stop()
+			return
+		case <-tck.C:
+			o.run(ctx, j)
+		}
+	}
+}
+
+func (j *job) jitter() time.Duration {
+	if j.c.Jitter <= 0 {
+		return 0
+	}
+
+	// #nosec
+	return time.Duration(rand.Int63n(int64(j.c.Jitter.Time())))
+}
+
+func (j *job) setNext(t time.Time) {
+	j.m.Lock()
+	defer j.m.Unlock()
+
+	j.t.Next = t
+}
+
+// begin registers a new run following the overlap policy and returns its context, or false if skipped.
+func (j *job) begin(ctx context.Context) (context.Context, context.CancelFunc, uint64, bool) {
+	j.m.Lock()
+	defer j.m.Unlock()
+
+	if len(j.r) > 0 {
+		switch j.c.Overlap {
+		case OverlapAllow:
+		case OverlapReplace:
+			for _, n := range j.r {
+				n()
+			}
+		default:
+			j.t.Skipped++
+			return nil, nil, 0, false
+		}
+	}
+
+	var (
+		x context.Context
+		n context.CancelFunc
+	)
+
+	if j.c.Timeout > 0 {
+		x, n = context.WithTimeout(ctx, j.c.Timeout.Time())
+	} else {
+		x, n = context.WithCancel(ctx)
+	}
+
+	j.i++
+	j.r[j.i] = n
+	j.t.Running = len(j.r)
+
+	return x, n, j.i, true
+}
+
+// end unregisters the run and updates the status with its result.
+func (j *job) end(id uint64, start time.Time, run bool, err error) {
+	j.m.Lock()
+	defer j.m.Unlock()
+
+	delete(j.r, id)
+	j.t.Running = len(j.r)
+
+	if !run {
+		j.t.Skipped++
+		return
+	}
+
+	j.t.Runs++
+	j.t.LastStart = start
+	j.t.LastEnd = time.Now()
+	j.t.LastDuration = j.t.LastEnd.Sub(start)
+	j.t.LastError = err
+
+	if err != nil {
+		j.t.Failures++
+	}
+}
+
+func (j *job) status() JobStatus {
+	j.m.Lock()
+	defer j.m.Unlock()
+
+	return j.t
+}
@@ -0,0 +1,75 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Locker is a distributed lock used by the singleton jobs to run on only one instance at a time.
+type Locker interface {
+	// TryLock tries to acquire the lock of the name for the given duration, it returns false
+	// without error if the lock is held by someone else.
+	TryLock(ctx context.Context, name string, ttl time.Duration) (bool, error)
+
+	// Unlock releases the lock of the name held by this locker.
+	Unlock(ctx context.Context, name string) error
+}
+
+// NewLocker returns a Locker kept into the memory of the process,
+// useful to share the locks between several schedulers of a same process.
+func NewLocker() Locker {
+	return &lck{
+		l: make(map[string]time.Time),
+	}
+}
+
+type lck struct {
+	m sync.Mutex
+	l map[string]time.Time // name / expiration
+}
+
+func (o *lck) TryLock(ctx context.Context, name string, ttl time.Duration) (bool, error) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if t, k := o.l[name]; k && time.Now().Before(t) {
+		return false, nil
+	}
+
+	o.l[name] = time.Now().Add(ttl)
+	return true, nil
+}
+
+func (o *lck) Unlock(ctx context.Context, name string) error {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	delete(o.l, name)
+	return nil
+}
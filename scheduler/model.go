@@ -0,0 +1,321 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	liblog "github.com/nabbar/golib/logger"
+	loglvl "github.com/nabbar/golib/logger/level"
+	librun "github.com/nabbar/golib/server/runner/startStop"
+)
+
+type sch struct {
+	m sync.RWMutex
+	j map[string]*job
+	r librun.StartStop
+	l liblog.FuncLog
+	k Locker
+	w sync.WaitGroup     // runs not finished
+	x context.Context    // jobs context
+	n context.CancelFunc // jobs cancel
+}
+
+func (o *sch) SetLogger(fct liblog.FuncLog) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.l = fct
+}
+
+func (o *sch) SetLocker(lck Locker) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.k = lck
+}
+
+func (o *sch) logger() liblog.Logger {
+	o.m.RLock()
+	f := o.l
+	o.m.RUnlock()
+
+	if f != nil {
+		if l := f(); l != nil {
+			return l
+		}
+	}
+
+	return liblog.New(context.Background)
+}
+
+func (o *sch) locker() Locker {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	return o.k
+}
+
+func (o *sch) Start(ctx context.Context) error {
+	if o.r.IsRunning() {
+		if e := o.Stop(ctx); e != nil {
+			return e
+		}
+	}
+
+	o.m.Lock()
+	o.x, o.n = context.WithCancel(context.Background())
+
+	for _, j := range o.j {
+		j.start(o.x, o)
+	}
+
+	o.m.Unlock()
+
+	return o.r.Start(ctx)
+}
+
+func (o *sch) Stop(ctx context.Context) error {
+	if !o.r.IsRunning() {
+		return o.runStop(ctx)
+	}
+
+	return o.r.Stop(ctx)
+}
+
+func (o *sch) Restart(ctx context.Context) error {
+	if e := o.Stop(ctx); e != nil {
+		return e
+	}
+
+	return o.Start(ctx)
+}
+
+func (o *sch) IsRunning() bool {
+	return o.r.IsRunning()
+}
+
+func (o *sch) Uptime() time.Duration {
+	return o.r.Uptime()
+}
+
+func (o *sch) runStart(ctx context.Context) error {
+	<-ctx.Done()
+	return nil
+}
+
+// runStop cancels the schedules and the runs not finished and waits for the end of the runs.
+func (o *sch) runStop(ctx context.Context) error {
+	o.m.Lock()
+
+	if o.n != nil {
+		o.n()
+	}
+
+	o.x, o.n = nil, nil
+	o.m.Unlock()
+
+	var c = make(chan struct{})
+
+	go func() {
+		o.w.Wait()
+		close(c)
+	}()
+
+	select {
+	case <-c:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (o *sch) Add(cfg JobConfig, fct FuncJob) error {
+	if fct == nil {
+		return ErrorParamEmpty.Error(nil)
+	} else if e := cfg.Validate(); e != nil {
+		return e
+	}
+
+	if len(cfg.Overlap) < 1 {
+		cfg.Overlap = OverlapSkip
+	}
+
+	s, e := cfg.schedule()
+
+	if e != nil {
+		return ErrorValidatorError.Error(e)
+	}
+
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if _, k := o.j[cfg.Name]; k {
+		return ErrorJobExists.Error(fmt.Errorf("job '%s'", cfg.Name))
+	}
+
+	j := &job{
+		c: cfg,
+		s: s,
+		f: fct,
+		r: make(map[uint64]context.CancelFunc),
+		t: JobStatus{
+			Name: cfg.Name,
+		},
+	}
+
+	o.j[cfg.Name] = j
+
+	if o.x != nil {
+		j.start(o.x, o)
+	}
+
+	return nil
+}
+
+func (o *sch) Remove(name string) error {
+	o.m.Lock()
+	j, k := o.j[name]
+	delete(o.j, name)
+	o.m.Unlock()
+
+	if !k {
+		return ErrorJobUnknown.Error(fmt.Errorf("job '%s'", name))
+	}
+
+	j.stop()
+	return nil
+}
+
+func (o *sch) List() []string {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	var res = make([]string, 0, len(o.j))
+
+	for n := range o.j {
+		res = append(res, n)
+	}
+
+	sort.Strings(res)
+	return res
+}
+
+func (o *sch) Status(name string) (JobStatus, error) {
+	o.m.RLock()
+	j, k := o.j[name]
+	o.m.RUnlock()
+
+	if !k {
+		return JobStatus{}, ErrorJobUnknown.Error(fmt.Errorf("job '%s'", name))
+	}
+
+	return j.status(), nil
+}
+
+func (o *sch) Trigger(name string) error {
+	o.m.RLock()
+	j, k := o.j[name]
+	x := o.x
+	o.m.RUnlock()
+
+	if !k {
+		return ErrorJobUnknown.Error(fmt.Errorf("job '%s'", name))
+	} else if x == nil {
+		return ErrorNotRunning.Error(nil)
+	}
+
+	o.run(x, j)
+	return nil
+}
+
+// run starts a run of the job following its overlap policy.
+func (o *sch) run(ctx context.Context, j *job) {
+	x, n, i, k := j.begin(ctx)
+
+	if !k {
+		o.logger().Entry(loglvl.DebugLevel, "skipping run of scheduler job '%s': previous run is not finished", j.c.Name).Log()
+		return
+	}
+
+	o.w.Add(1)
+
+	go func() {
+		defer o.w.Done()
+		defer n()
+
+		var (
+			s = time.Now()
+			r bool
+			e error
+		)
+
+		r, e = o.exec(x, j)
+		j.end(i, s, r, e)
+
+		if !r {
+			o.logger().Entry(loglvl.DebugLevel, "skipping run of scheduler job '%s': lock is held by another instance", j.c.Name).Log()
+		} else if e != nil {
+			ent := o.logger().Entry(loglvl.ErrorLevel, "running scheduler job '%s'", j.c.Name)
+			ent.FieldAdd("scheduler.duration", time.Since(s).String())
+			ent.ErrorAdd(true, e)
+			ent.Log()
+		} else {
+			ent := o.logger().Entry(loglvl.DebugLevel, "scheduler job '%s' done", j.c.Name)
+			ent.FieldAdd("scheduler.duration", time.Since(s).String())
+			ent.Log()
+		}
+	}()
+}
+
+// exec calls the function of the job, holding the distributed lock for the singleton jobs.
+// It returns false if the job has not been run because the lock is held elsewhere.
+func (o *sch) exec(ctx context.Context, j *job) (run bool, err error) {
+	if l := o.locker(); j.c.Singleton && l != nil {
+		if k, e := l.TryLock(ctx, j.c.Name, j.c.lockTTL()); e != nil {
+			return true, e
+		} else if !k {
+			return false, nil
+		}
+
+		defer func() {
+			_ = l.Unlock(context.Background(), j.c.Name)
+		}()
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			run = true
+			err = ErrorJobPanic.Error(fmt.Errorf("%v", r))
+		}
+	}()
+
+	return true, j.f(ctx)
+}
@@ -0,0 +1,142 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package scheduler
+
+import (
+	"context"
+	"fmt"
+
+	libctx "github.com/nabbar/golib/context"
+	libmon "github.com/nabbar/golib/monitor"
+	moninf "github.com/nabbar/golib/monitor/info"
+	montps "github.com/nabbar/golib/monitor/types"
+	libver "github.com/nabbar/golib/version"
+)
+
+const (
+	defaultNameMonitor = "Scheduler"
+)
+
+func (o *sch) HealthCheck(ctx context.Context) error {
+	if !o.IsRunning() {
+		return ErrorNotRunning.Error(nil)
+	}
+
+	var err = ErrorJobFailed.Error(nil)
+
+	for _, n := range o.List() {
+		if s, e := o.Status(n); e == nil && s.LastError != nil {
+			err.Add(fmt.Errorf("job '%s': %v", n, s.LastError))
+		}
+	}
+
+	if err.HasParent() {
+		return err
+	}
+
+	return nil
+}
+
+// Monitor is used to return the monitor of the scheduler with the status of the runs of each job.
+func (o *sch) Monitor(ctx libctx.FuncContext, vrs libver.Version) (montps.Monitor, error) {
+	var (
+		e   error
+		inf moninf.Info
+		mon montps.Monitor
+		res = make(map[string]interface{}, 0)
+	)
+
+	for k, v := range vrs.GetInfoMap() {
+		res[k] = v
+	}
+
+	if inf, e = moninf.New(defaultNameMonitor); e != nil {
+		return nil, e
+	} else {
+		inf.RegisterName(func() (string, error) {
+			return defaultNameMonitor, nil
+		})
+		inf.RegisterInfo(func() (map[string]interface{}, error) {
+			var r = make(map[string]interface{}, len(res)+2)
+
+			for k, v := range res {
+				r[k] = v
+			}
+
+			r["running"] = o.IsRunning()
+			r["jobs"] = o.infoJobs()
+
+			return r, nil
+		})
+	}
+
+	if mon, e = libmon.New(ctx, inf); e != nil {
+		return nil, e
+	}
+
+	mon.SetHealthCheck(o.HealthCheck)
+	if e = mon.Start(ctx()); e != nil {
+		return nil, e
+	}
+
+	return mon, nil
+}
+
+func (o *sch) infoJobs() map[string]interface{} {
+	var res = make(map[string]interface{})
+
+	for _, n := range o.List() {
+		s, e := o.Status(n)
+
+		if e != nil {
+			continue
+		}
+
+		i := map[string]interface{}{
+			"running":  s.Running,
+			"runs":     s.Runs,
+			"failures": s.Failures,
+			"skipped":  s.Skipped,
+		}
+
+		if !s.Next.IsZero() {
+			i["next"] = s.Next.String()
+		}
+
+		if !s.LastStart.IsZero() {
+			i["last"] = s.LastStart.String()
+			i["duration"] = s.LastDuration.String()
+		}
+
+		if s.LastError != nil {
+			i["error"] = s.LastError.Error()
+		}
+
+		res[n] = i
+	}
+
+	return res
+}
@@ -0,0 +1,243 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package scheduler_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	libdur "github.com/nabbar/golib/duration"
+	libsch "github.com/nabbar/golib/scheduler"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func interval(name string, d time.Duration) libsch.JobConfig {
+	return libsch.JobConfig{
+		Name:     name,
+		Interval: libdur.ParseDuration(d),
+	}
+}
+
+var _ = Describe("Scheduler", func() {
+	var (
+		ctx context.Context
+		sch libsch.Scheduler
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		sch = libsch.New()
+	})
+
+	AfterEach(func() {
+		Expect(sch.Stop(ctx)).ToNot(HaveOccurred())
+	})
+
+	Context("jobs", func() {
+		It("must validate the job config", func() {
+			fct := func(ctx context.Context) error { return nil }
+
+			Expect(sch.Add(libsch.JobConfig{Name: "none"}, fct)).To(HaveOccurred())
+			Expect(sch.Add(libsch.JobConfig{Name: "cron", Schedule: "* * *"}, fct)).To(HaveOccurred())
+			Expect(sch.Add(libsch.JobConfig{Name: "tz", Schedule: "@daily", TimeZone: "Nowhere/Unknown"}, fct)).To(HaveOccurred())
+			Expect(sch.Add(libsch.JobConfig{Name: "overlap", Schedule: "@daily", Overlap: "queue"}, fct)).To(HaveOccurred())
+			Expect(sch.Add(libsch.JobConfig{Name: "cron", Schedule: "@daily"}, nil)).To(HaveOccurred())
+			Expect(sch.Add(libsch.JobConfig{Name: "cron", Schedule: "@daily", TimeZone: "UTC"}, fct)).ToNot(HaveOccurred())
+			Expect(sch.Add(libsch.JobConfig{Name: "cron", Schedule: "@hourly"}, fct)).To(HaveOccurred())
+			Expect(sch.List()).To(Equal([]string{"cron"}))
+
+			Expect(sch.Remove("cron")).ToNot(HaveOccurred())
+			Expect(sch.Remove("cron")).To(HaveOccurred())
+			Expect(sch.List()).To(BeEmpty())
+		})
+
+		It("must run an interval job until stopped", func() {
+			var n atomic.Int32
+
+			Expect(sch.Add(interval("tick", 20*time.Millisecond), func(ctx context.Context) error {
+				n.Add(1)
+				return nil
+			})).ToNot(HaveOccurred())
+
+			Expect(sch.Start(ctx)).ToNot(HaveOccurred())
+			Eventually(n.Load).Should(BeNumerically(">=", 3))
+
+			s, e := sch.Status("tick")
+			Expect(e).ToNot(HaveOccurred())
+			Expect(s.Runs).To(BeNumerically(">=", 2))
+			Expect(s.Failures).To(BeZero())
+			Expect(s.Next.IsZero()).To(BeFalse())
+			Expect(sch.HealthCheck(ctx)).ToNot(HaveOccurred())
+
+			Expect(sch.Stop(ctx)).ToNot(HaveOccurred())
+			c := n.Load()
+			Consistently(n.Load, 100*time.Millisecond).Should(Equal(c))
+		})
+
+		It("must report failures and panics", func() {
+			Expect(sch.Add(interval("fail", time.Hour), func(ctx context.Context) error {
+				return errors.New("failure")
+			})).ToNot(HaveOccurred())
+			Expect(sch.Add(interval("panic", time.Hour), func(ctx context.Context) error {
+				panic("panic")
+			})).ToNot(HaveOccurred())
+
+			Expect(sch.Trigger("fail")).To(HaveOccurred())
+			Expect(sch.Start(ctx)).ToNot(HaveOccurred())
+			Expect(sch.Trigger("fail")).ToNot(HaveOccurred())
+			Expect(sch.Trigger("panic")).ToNot(HaveOccurred())
+			Expect(sch.Trigger("unknown")).To(HaveOccurred())
+
+			Eventually(func() uint64 {
+				s, _ := sch.Status("panic")
+				return s.Failures
+			}).Should(Equal(uint64(1)))
+
+			s, e := sch.Status("fail")
+			Expect(e).ToNot(HaveOccurred())
+			Expect(s.Failures).To(Equal(uint64(1)))
+			Expect(s.LastError).To(HaveOccurred())
+			Expect(sch.HealthCheck(ctx)).To(HaveOccurred())
+		})
+
+		It("must apply the timeout to the context of the run", func() {
+			var d atomic.Bool
+
+			cfg := interval("timeout", time.Hour)
+			cfg.Timeout = libdur.ParseDuration(20 * time.Millisecond)
+
+			Expect(sch.Add(cfg, func(ctx context.Context) error {
+				<-ctx.Done()
+				d.Store(true)
+				return ctx.Err()
+			})).ToNot(HaveOccurred())
+
+			Expect(sch.Start(ctx)).ToNot(HaveOccurred())
+			Expect(sch.Trigger("timeout")).ToNot(HaveOccurred())
+			Eventually(d.Load).Should(BeTrue())
+		})
+	})
+
+	Context("overlap", func() {
+		var blk = func(n *atomic.Int32) libsch.FuncJob {
+			return func(ctx context.Context) error {
+				n.Add(1)
+				<-ctx.Done()
+				return nil
+			}
+		}
+
+		It("must skip a run if the previous is not finished", func() {
+			var n atomic.Int32
+
+			Expect(sch.Add(interval("skip", time.Hour), blk(&n))).ToNot(HaveOccurred())
+			Expect(sch.Start(ctx)).ToNot(HaveOccurred())
+			Expect(sch.Trigger("skip")).ToNot(HaveOccurred())
+			Eventually(n.Load).Should(Equal(int32(1)))
+			Expect(sch.Trigger("skip")).ToNot(HaveOccurred())
+
+			s, _ := sch.Status("skip")
+			Expect(s.Skipped).To(Equal(uint64(1)))
+			Expect(s.Running).To(Equal(1))
+		})
+
+		It("must allow overlapping runs", func() {
+			var n atomic.Int32
+
+			cfg := interval("allow", time.Hour)
+			cfg.Overlap = libsch.OverlapAllow
+
+			Expect(sch.Add(cfg, blk(&n))).ToNot(HaveOccurred())
+			Expect(sch.Start(ctx)).ToNot(HaveOccurred())
+			Expect(sch.Trigger("allow")).ToNot(HaveOccurred())
+			Expect(sch.Trigger("allow")).ToNot(HaveOccurred())
+			Eventually(n.Load).Should(Equal(int32(2)))
+
+			s, _ := sch.Status("allow")
+			Expect(s.Running).To(Equal(2))
+		})
+
+		It("must cancel the previous run on replace", func() {
+			var n atomic.Int32
+
+			cfg := interval("replace", time.Hour)
+			cfg.Overlap = libsch.OverlapReplace
+
+			Expect(sch.Add(cfg, blk(&n))).ToNot(HaveOccurred())
+			Expect(sch.Start(ctx)).ToNot(HaveOccurred())
+			Expect(sch.Trigger("replace")).ToNot(HaveOccurred())
+			Eventually(n.Load).Should(Equal(int32(1)))
+			Expect(sch.Trigger("replace")).ToNot(HaveOccurred())
+			Eventually(n.Load).Should(Equal(int32(2)))
+
+			Eventually(func() uint64 {
+				s, _ := sch.Status("replace")
+				return s.Runs
+			}).Should(Equal(uint64(1)))
+		})
+	})
+
+	Context("singleton", func() {
+		It("must run the job on only one scheduler sharing the locker", func() {
+			var (
+				n   atomic.Int32
+				lck = libsch.NewLocker()
+				oth = libsch.New()
+				cfg = interval("single", time.Hour)
+			)
+
+			cfg.Singleton = true
+
+			sch.SetLocker(lck)
+			oth.SetLocker(lck)
+
+			defer func() { _ = oth.Stop(ctx) }()
+
+			for _, s := range []libsch.Scheduler{sch, oth} {
+				Expect(s.Add(cfg, func(ctx context.Context) error {
+					n.Add(1)
+					<-ctx.Done()
+					return nil
+				})).ToNot(HaveOccurred())
+				Expect(s.Start(ctx)).ToNot(HaveOccurred())
+			}
+
+			Expect(sch.Trigger("single")).ToNot(HaveOccurred())
+			Eventually(n.Load).Should(Equal(int32(1)))
+			Expect(oth.Trigger("single")).ToNot(HaveOccurred())
+
+			Eventually(func() uint64 {
+				s, _ := oth.Status("single")
+				return s.Skipped
+			}).Should(Equal(uint64(1)))
+			Expect(n.Load()).To(Equal(int32(1)))
+		})
+	})
+})
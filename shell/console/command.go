@@ -0,0 +1,152 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package console
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	liblog "github.com/nabbar/golib/logger"
+	loglvl "github.com/nabbar/golib/logger/level"
+	shlcmd "github.com/nabbar/golib/shell/command"
+	libsck "github.com/nabbar/golib/socket"
+)
+
+const (
+	CommandNameStatus      = "status"
+	CommandNameConfig      = "config"
+	CommandNameLogLevel    = "loglevel"
+	CommandNameConnections = "connections"
+
+	timeoutStatus = 10 * time.Second
+)
+
+// FuncCheck is a health check as the HealthCheck function of the golib components.
+type FuncCheck func(ctx context.Context) error
+
+// FuncConfig returns the configuration to dump.
+type FuncConfig func() interface{}
+
+func sortedKeys[V any](m map[string]V) []string {
+	var k = make([]string, 0, len(m))
+
+	for n := range m {
+		k = append(k, n)
+	}
+
+	sort.Strings(k)
+	return k
+}
+
+// CommandStatus returns the command "status" running each health check and printing its result.
+func CommandStatus(chk map[string]FuncCheck) shlcmd.Command {
+	return shlcmd.New(CommandNameStatus, "print the health status of the components", func(buf io.Writer, err io.Writer, args []string) {
+		ctx, cnl := context.WithTimeout(context.Background(), timeoutStatus)
+		defer cnl()
+
+		for _, n := range sortedKeys(chk) {
+			if chk[n] == nil {
+				continue
+			} else if e := chk[n](ctx); e != nil {
+				_, _ = fmt.Fprintf(buf, "%-20s KO: %v\n", n, e)
+			} else {
+				_, _ = fmt.Fprintf(buf, "%-20s OK\n", n)
+			}
+		}
+	})
+}
+
+// CommandConfig returns the command "config" printing the configuration as indented json.
+// Take care to not expose secrets into the returned configuration.
+func CommandConfig(fct FuncConfig) shlcmd.Command {
+	return shlcmd.New(CommandNameConfig, "dump the current configuration", func(buf io.Writer, err io.Writer, args []string) {
+		if fct == nil {
+			_, _ = fmt.Fprintf(err, "no configuration\n")
+			return
+		}
+
+		if p, e := json.MarshalIndent(fct(), "", "  "); e != nil {
+			_, _ = fmt.Fprintf(err, "cannot encode configuration: %v\n", e)
+		} else {
+			_, _ = fmt.Fprintf(buf, "%s\n", p)
+		}
+	})
+}
+
+// CommandLogLevel returns the command "loglevel" printing the current level of the logger,
+// or changing it if a level is given as argument.
+func CommandLogLevel(fct liblog.FuncLog) shlcmd.Command {
+	return shlcmd.New(CommandNameLogLevel, "print or change the log level ("+strings.Join(loglvl.ListLevels(), ", ")+")", func(buf io.Writer, err io.Writer, args []string) {
+		var l liblog.Logger
+
+		if fct != nil {
+			l = fct()
+		}
+
+		if l == nil {
+			_, _ = fmt.Fprintf(err, "no logger\n")
+			return
+		}
+
+		if len(args) > 0 {
+			var (
+				o = l.GetLevel()
+				n = loglvl.Parse(args[0])
+			)
+
+			if !strings.EqualFold(n.String(), args[0]) {
+				_, _ = fmt.Fprintf(err, "invalid log level '%s'\n", args[0])
+				return
+			}
+
+			l.SetLevel(n)
+			l.Entry(loglvl.InfoLevel, "log level changed from '%s' to '%s' by console", o.String(), n.String()).Log()
+		}
+
+		_, _ = fmt.Fprintf(buf, "%s\n", l.GetLevel().String())
+	})
+}
+
+// CommandConnections returns the command "connections" printing the open connections of each socket server.
+func CommandConnections(srv map[string]libsck.Server) shlcmd.Command {
+	return shlcmd.New(CommandNameConnections, "list the open connections of the socket servers", func(buf io.Writer, err io.Writer, args []string) {
+		for _, n := range sortedKeys(srv) {
+			if srv[n] == nil {
+				continue
+			}
+
+			s := srv[n].ConnectionsByState()
+			_, _ = fmt.Fprintf(buf, "%-20s running: %t, open: %d (reading: %d, handling: %d, writing: %d, idle: %d)\n",
+				n, srv[n].IsRunning(), srv[n].OpenConnections(), s.Reading, s.Handling, s.Writing, s.Idle)
+		}
+	})
+}
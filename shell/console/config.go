@@ -0,0 +1,90 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package console
+
+import (
+	"fmt"
+	"os"
+
+	libval "github.com/go-playground/validator/v10"
+)
+
+const (
+	// DefaultPerm is the default permission of the socket file: only the owner of the process can connect.
+	DefaultPerm os.FileMode = 0600
+
+	// DefaultPrompt is the default prompt sent before reading each command.
+	DefaultPrompt = "> "
+)
+
+type Config struct {
+	// Socket is the path of the unix socket file to listen on.
+	Socket string `json:"socket" yaml:"socket" toml:"socket" mapstructure:"socket" validate:"required"`
+
+	// PermFile is the permission of the socket file, the only access control of the console. By default, 0600.
+	PermFile os.FileMode `json:"perm-file,omitempty" yaml:"perm-file,omitempty" toml:"perm-file,omitempty" mapstructure:"perm-file,omitempty"`
+
+	// GroupPerm is the group id owning the socket file, or -1 to keep the group of the process.
+	GroupPerm int32 `json:"group-perm,omitempty" yaml:"group-perm,omitempty" toml:"group-perm,omitempty" mapstructure:"group-perm,omitempty" validate:"gte=-1"`
+
+	// Prompt is sent to the client before reading each command. By default, "> ".
+	Prompt string `json:"prompt,omitempty" yaml:"prompt,omitempty" toml:"prompt,omitempty" mapstructure:"prompt,omitempty"`
+
+	// Banner is an optional message sent to the client on each new session.
+	Banner string `json:"banner,omitempty" yaml:"banner,omitempty" toml:"banner,omitempty" mapstructure:"banner,omitempty"`
+}
+
+func (c Config) Validate() error {
+	err := ErrorValidatorError.Error(nil)
+
+	if er := libval.New().Struct(c); er != nil {
+		if e, ok := er.(*libval.InvalidValidationError); ok {
+			err.Add(e)
+		}
+
+		for _, e := range er.(libval.ValidationErrors) {
+			//nolint goerr113
+			err.Add(fmt.Errorf("config field '%s' is not validated by constraint '%s'", e.Namespace(), e.ActualTag()))
+		}
+	}
+
+	if err.HasParent() {
+		return err
+	}
+
+	return nil
+}
+
+func (c *Config) normalize() {
+	if c.PermFile == 0 {
+		c.PermFile = DefaultPerm
+	}
+
+	if len(c.Prompt) < 1 {
+		c.Prompt = DefaultPrompt
+	}
+}
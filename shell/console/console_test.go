@@ -0,0 +1,164 @@
+//go:build linux
+// +build linux
+
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package console_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	liblog "github.com/nabbar/golib/logger"
+	shlcmd "github.com/nabbar/golib/shell/command"
+	shlcns "github.com/nabbar/golib/shell/console"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func session(sck string, cmd string) string {
+	c, e := net.Dial("unix", sck)
+	Expect(e).ToNot(HaveOccurred())
+
+	defer func() {
+		_ = c.Close()
+	}()
+
+	_ = c.SetDeadline(time.Now().Add(10 * time.Second))
+
+	_, e = c.Write([]byte(cmd))
+	Expect(e).ToNot(HaveOccurred())
+
+	p, e := io.ReadAll(c)
+	Expect(e).ToNot(HaveOccurred())
+
+	return string(p)
+}
+
+var _ = Describe("shell/console", func() {
+	var (
+		dir string
+		sck string
+		cns shlcns.Console
+		ctx context.Context
+		cnl context.CancelFunc
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		dir, err = os.MkdirTemp("", "golib_console_*")
+		Expect(err).ToNot(HaveOccurred())
+
+		sck = filepath.Join(dir, "console.sock")
+		ctx, cnl = context.WithCancel(context.Background())
+
+		cns, err = shlcns.New(shlcns.Config{
+			Socket:    sck,
+			GroupPerm: -1,
+			Banner:    "golib console",
+		}, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		cns.Add("", shlcns.CommandStatus(map[string]shlcns.FuncCheck{
+			"alpha": func(ctx context.Context) error {
+				return nil
+			},
+			"beta": func(ctx context.Context) error {
+				return errors.New("beta is down")
+			},
+		}))
+
+		cns.Add("", shlcns.CommandConfig(func() interface{} {
+			return map[string]string{"name": "golib"}
+		}))
+
+		Expect(cns.Start(ctx)).ToNot(HaveOccurred())
+		Eventually(func() error {
+			return cns.HealthCheck(ctx)
+		}, 10*time.Second, 50*time.Millisecond).ShouldNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(cns.Stop(context.Background())).ToNot(HaveOccurred())
+		cnl()
+		_ = os.RemoveAll(dir)
+	})
+
+	It("must reject an invalid config", func() {
+		_, err := shlcns.New(shlcns.Config{}, nil)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("must create the socket with owner only permission", func() {
+		i, err := os.Stat(sck)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(i.Mode().Perm()).To(Equal(shlcns.DefaultPerm))
+	})
+
+	It("must run the registered commands", func() {
+		out := session(sck, "help\nstatus\nconfig\nunknown\nexit\n")
+
+		Expect(out).To(HavePrefix("golib console\n" + shlcns.DefaultPrompt))
+		Expect(out).To(ContainSubstring(shlcns.CommandNameStatus))
+		Expect(out).To(MatchRegexp(`alpha\s+OK`))
+		Expect(out).To(MatchRegexp(`beta\s+KO: beta is down`))
+		Expect(out).To(ContainSubstring(`"name": "golib"`))
+		Expect(out).To(ContainSubstring("Invalid command"))
+		Expect(out).To(HaveSuffix("Bye !\n"))
+	})
+
+	It("must run a command registering commands and changing the logger", func() {
+		cns.Add("", shlcmd.New("register", "register a new command", func(buf io.Writer, err io.Writer, args []string) {
+			cns.SetLogger(func() liblog.Logger {
+				return liblog.New(context.Background)
+			})
+			cns.Add("", shlcmd.New("hello", "say hello", func(buf io.Writer, err io.Writer, args []string) {
+				_, _ = buf.Write([]byte("hello world\n"))
+			}))
+		}))
+
+		out := session(sck, "register\nhello\nexit\n")
+
+		Expect(out).To(ContainSubstring("hello world"))
+		Expect(out).To(HaveSuffix("Bye !\n"))
+	})
+
+	It("must count the connected sessions", func() {
+		c, err := net.Dial("unix", sck)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(cns.Sessions, 5*time.Second, 50*time.Millisecond).Should(BeEquivalentTo(1))
+		_ = c.Close()
+		Eventually(cns.Sessions, 5*time.Second, 50*time.Millisecond).Should(BeEquivalentTo(0))
+	})
+})
@@ -0,0 +1,85 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package console
+
+import (
+	"context"
+	"sync/atomic"
+
+	liblog "github.com/nabbar/golib/logger"
+	libsrv "github.com/nabbar/golib/server"
+	librun "github.com/nabbar/golib/server/runner/startStop"
+	libshl "github.com/nabbar/golib/shell"
+	shlcmd "github.com/nabbar/golib/shell/command"
+)
+
+// Console is a local administration console: once started, it listens on a unix socket and
+// runs each received line as a command of the shell. The access is only controlled by the
+// permissions of the socket file.
+// The commands help, exit and quit are always available.
+type Console interface {
+	libsrv.Server
+
+	// Add registers the commands with the given prefix into the shell of the console.
+	Add(prefix string, cmd ...shlcmd.Command)
+
+	// Shell returns the shell holding the commands of the console.
+	Shell() libshl.Shell
+
+	// Sessions returns the number of connected sessions.
+	Sessions() int64
+
+	// SetLogger is used to define the logger used to trace sessions and commands.
+	SetLogger(fct liblog.FuncLog)
+
+	// HealthCheck returns an error if the console is not listening.
+	HealthCheck(ctx context.Context) error
+}
+
+// New returns a console listening on the socket of the config and running the commands of the given shell.
+// If the shell is nil, a new empty shell is used.
+func New(cfg Config, shl libshl.Shell) (Console, error) {
+	if e := cfg.Validate(); e != nil {
+		return nil, e
+	}
+
+	cfg.normalize()
+
+	if shl == nil {
+		shl = libshl.New()
+	}
+
+	o := &cns{
+		c: cfg,
+		h: shl,
+		n: new(atomic.Int64),
+	}
+
+	o.r = librun.New(o.runStart, o.runStop)
+
+	return o, nil
+}
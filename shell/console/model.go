@@ -0,0 +1,293 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package console
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	liblog "github.com/nabbar/golib/logger"
+	loglvl "github.com/nabbar/golib/logger/level"
+	libptc "github.com/nabbar/golib/network/protocol"
+	libsrv "github.com/nabbar/golib/server"
+	librun "github.com/nabbar/golib/server/runner/startStop"
+	libshl "github.com/nabbar/golib/shell"
+	shlcmd "github.com/nabbar/golib/shell/command"
+	libsck "github.com/nabbar/golib/socket"
+	scksrv "github.com/nabbar/golib/socket/server"
+)
+
+const (
+	cmdHelp = "help"
+	cmdExit = "exit"
+	cmdQuit = "quit"
+)
+
+type cns struct {
+	m sync.RWMutex
+	c Config
+	h libshl.Shell
+	r librun.StartStop
+	l liblog.FuncLog
+	s libsck.Server // current socket server
+	n *atomic.Int64 // connected sessions
+}
+
+func (o *cns) SetLogger(fct liblog.FuncLog) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.l = fct
+}
+
+func (o *cns) logger() liblog.Logger {
+	o.m.RLock()
+	f := o.l
+	o.m.RUnlock()
+
+	if f != nil {
+		if l := f(); l != nil {
+			return l
+		}
+	}
+
+	return liblog.New(context.Background)
+}
+
+func (o *cns) Add(prefix string, cmd ...shlcmd.Command) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.h.Add(prefix, cmd...)
+}
+
+func (o *cns) Shell() libshl.Shell {
+	return o.h
+}
+
+func (o *cns) Sessions() int64 {
+	return o.n.Load()
+}
+
+func (o *cns) server() libsck.Server {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	return o.s
+}
+
+func (o *cns) HealthCheck(ctx context.Context) error {
+	if s := o.server(); !o.IsRunning() || s == nil || !s.IsRunning() {
+		return ErrorNotRunning.Error(nil)
+	}
+
+	return nil
+}
+
+func (o *cns) Start(ctx context.Context) error {
+	return o.r.Start(ctx)
+}
+
+func (o *cns) Stop(ctx context.Context) error {
+	return o.r.Stop(ctx)
+}
+
+func (o *cns) Restart(ctx context.Context) error {
+	return o.r.Restart(ctx)
+}
+
+func (o *cns) IsRunning() bool {
+	return o.r.IsRunning()
+}
+
+func (o *cns) Uptime() time.Duration {
+	return o.r.Uptime()
+}
+
+func (o *cns) runStart(ctx context.Context) error {
+	// a closed socket server cannot listen again, so a new one is created on each start
+	s, e := scksrv.New(nil, o.handler, libptc.NetworkUnix, o.c.Socket, o.c.PermFile, o.c.GroupPerm)
+
+	if e != nil {
+		return ErrorSocket.Error(e)
+	}
+
+	s.RegisterFuncError(func(e ...error) {
+		for _, err := range e {
+			if err = libsck.ErrorFilter(err); err != nil {
+				o.logger().Entry(loglvl.ErrorLevel, "console socket error").ErrorAdd(true, err).Log()
+			}
+		}
+	})
+
+	o.m.Lock()
+	o.s = s
+	o.m.Unlock()
+
+	o.logger().Entry(loglvl.InfoLevel, "console listening on '%s'", o.c.Socket).Log()
+
+	return s.Listen(ctx)
+}
+
+func (o *cns) runStop(ctx context.Context) error {
+	o.m.Lock()
+	s := o.s
+	o.s = nil
+	o.m.Unlock()
+
+	if s == nil {
+		return nil
+	}
+
+	o.logger().Entry(loglvl.InfoLevel, "console stopping on '%s'", o.c.Socket).Log()
+
+	return s.Shutdown(ctx)
+}
+
+func (o *cns) handler(request libsck.Reader, response libsck.Writer) {
+	defer func() {
+		_ = request.Close()
+		_ = response.Close()
+	}()
+
+	o.n.Add(1)
+	defer o.n.Add(-1)
+
+	var (
+		scn = bufio.NewScanner(request)
+		out = &writer{w: response}
+	)
+
+	if len(o.c.Banner) > 0 {
+		out.printf("%s\n", strings.TrimRight(o.c.Banner, "\n"))
+	}
+
+	for {
+		out.printf("%s", o.c.Prompt)
+
+		if !scn.Scan() {
+			return
+		}
+
+		arg := strings.Fields(scn.Text())
+
+		if len(arg) < 1 {
+			continue
+		}
+
+		switch arg[0] {
+		case cmdExit, cmdQuit:
+			out.printf("Bye !\n")
+			return
+		case cmdHelp:
+			o.help(out, arg[1:])
+		default:
+			o.run(out, arg)
+		}
+
+		if out.e != nil {
+			return
+		}
+	}
+}
+
+func (o *cns) help(w io.Writer, arg []string) {
+	var flt string
+
+	if len(arg) > 0 {
+		flt = arg[0]
+	}
+
+	o.m.RLock()
+	d := o.h.Desc(flt)
+	o.m.RUnlock()
+
+	var k = make([]string, 0, len(d))
+
+	for n := range d {
+		k = append(k, n)
+	}
+
+	sort.Strings(k)
+
+	if len(flt) < 1 {
+		_, _ = fmt.Fprintf(w, "%-20s %s\n", cmdHelp, "list the available commands")
+		_, _ = fmt.Fprintf(w, "%-20s %s\n", cmdExit+", "+cmdQuit, "close the session")
+	}
+
+	for _, n := range k {
+		_, _ = fmt.Fprintf(w, "%-20s %s\n", n, d[n])
+	}
+}
+
+func (o *cns) run(w io.Writer, arg []string) {
+	// the lock is not kept while running the command, which may register new commands.
+	o.m.RLock()
+	h := o.h
+	o.m.RUnlock()
+
+	defer func() {
+		if r := recover(); r != nil {
+			libsrv.RecoveryCaller("golib/shell/console", r)
+			_, _ = fmt.Fprintf(w, "Command '%s' has failed\n", arg[0])
+		}
+	}()
+
+	if len(h.Get(arg[0])) < 1 {
+		_, _ = fmt.Fprintf(w, "Invalid command, try '%s'\n", cmdHelp)
+		return
+	}
+
+	o.logger().Entry(loglvl.InfoLevel, "console running command '%s'", arg[0]).Log()
+	h.Run(w, w, arg)
+}
+
+// writer keeps the first error of writing, to end the session when the client is gone.
+type writer struct {
+	w io.Writer
+	e error
+}
+
+func (o *writer) Write(p []byte) (n int, err error) {
+	if o.e != nil {
+		return 0, o.e
+	}
+
+	n, o.e = o.w.Write(p)
+	return n, o.e
+}
+
+func (o *writer) printf(format string, args ...interface{}) {
+	_, _ = fmt.Fprintf(o, format, args...)
+}
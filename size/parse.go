@@ -73,6 +73,25 @@ var unitMap = map[string]uint64{
 	"Eb": uint64(SizeExa),
 	"eB": uint64(SizeExa),
 	"EB": uint64(SizeExa),
+	// IEC notation, same binary multiple as above
+	"Ki":  uint64(SizeKilo),
+	"KiB": uint64(SizeKilo),
+	"kib": uint64(SizeKilo),
+	"Mi":  uint64(SizeMega),
+	"MiB": uint64(SizeMega),
+	"mib": uint64(SizeMega),
+	"Gi":  uint64(SizeGiga),
+	"GiB": uint64(SizeGiga),
+	"gib": uint64(SizeGiga),
+	"Ti":  uint64(SizeTera),
+	"TiB": uint64(SizeTera),
+	"tib": uint64(SizeTera),
+	"Pi":  uint64(SizePeta),
+	"PiB": uint64(SizePeta),
+	"pib": uint64(SizePeta),
+	"Ei":  uint64(SizeExa),
+	"EiB": uint64(SizeExa),
+	"eib": uint64(SizeExa),
 }
 
 func parseBytes(p []byte) (Size, error) {
@@ -0,0 +1,102 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package bytes_test
+
+import (
+	libsiz "github.com/nabbar/golib/size"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("size parse", func() {
+	DescribeTable("valid size",
+		func(s string, exp libsiz.Size) {
+			z, e := libsiz.Parse(s)
+			Expect(e).ToNot(HaveOccurred())
+			Expect(z).To(Equal(exp))
+		},
+		Entry("bytes", "10B", 10*libsiz.SizeUnit),
+		Entry("lower case bytes", "512b", 512*libsiz.SizeUnit),
+		Entry("kilo short", "1K", libsiz.SizeKilo),
+		Entry("kilo", "1KB", libsiz.SizeKilo),
+		Entry("kilo iec", "1KiB", libsiz.SizeKilo),
+		Entry("kilo iec short", "1Ki", libsiz.SizeKilo),
+		Entry("kilo iec lower case", "1kib", libsiz.SizeKilo),
+		Entry("mega", "1MB", libsiz.SizeMega),
+		Entry("mega iec", "1MiB", libsiz.SizeMega),
+		Entry("giga fraction", "1.5GB", libsiz.SizeGiga+libsiz.SizeGiga/2),
+		Entry("giga iec fraction", "1.5GiB", libsiz.SizeGiga+libsiz.SizeGiga/2),
+		Entry("tera", "2TB", 2*libsiz.SizeTera),
+		Entry("tera iec", "2TiB", 2*libsiz.SizeTera),
+		Entry("peta iec", "1PiB", libsiz.SizePeta),
+		Entry("exa", "1EB", libsiz.SizeExa),
+		Entry("exa iec", "1EiB", libsiz.SizeExa),
+		Entry("mixed notations", "1MiB512KB", libsiz.SizeMega+512*libsiz.SizeKilo),
+		Entry("plus sign", "+1KiB", libsiz.SizeKilo),
+		Entry("quotes", "'1MiB'", libsiz.SizeMega),
+		Entry("spaces", " 2 KB ", 2*libsiz.SizeKilo),
+		Entry("max", "7EiB", 7*libsiz.SizeExa),
+	)
+
+	DescribeTable("same size in decimal and iec notation",
+		func(dec, iec string) {
+			d, e := libsiz.Parse(dec)
+			Expect(e).ToNot(HaveOccurred())
+
+			i, e := libsiz.Parse(iec)
+			Expect(e).ToNot(HaveOccurred())
+
+			Expect(d).To(Equal(i))
+		},
+		Entry("kilo", "3KB", "3KiB"),
+		Entry("mega", "3MB", "3MiB"),
+		Entry("giga", "3GB", "3GiB"),
+		Entry("tera", "3TB", "3TiB"),
+		Entry("peta", "3PB", "3PiB"),
+		Entry("exa", "3EB", "3EiB"),
+	)
+
+	DescribeTable("invalid size",
+		func(s string) {
+			_, e := libsiz.Parse(s)
+			Expect(e).To(HaveOccurred())
+		},
+		Entry("empty", ""),
+		Entry("sign only", "-"),
+		Entry("unit only", "KB"),
+		Entry("missing unit", "1"),
+		Entry("unknown unit", "1XB"),
+		Entry("unknown iec unit", "1KiBB"),
+		Entry("exponent notation", "1e3"),
+		Entry("two dots", "1.2.3KB"),
+		Entry("letters", "abc"),
+		Entry("overflow of integer", "99999999999999999999B"),
+		Entry("overflow of int64", "9223372036854775808B"),
+		Entry("overflow of unit", "8EiB"),
+		Entry("overflow of sum", "7EiB1EiB"),
+	)
+})
@@ -0,0 +1,105 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package bytes
+
+import (
+	"reflect"
+
+	libval "github.com/go-playground/validator/v10"
+)
+
+const (
+	// ValidatorTag checks a string field can be parsed as a size.
+	ValidatorTag = "size"
+	// ValidatorTagMin checks a size or string field is at least the size given as param, e.g. `validate:"size_min=1MiB"`.
+	ValidatorTagMin = "size_min"
+	// ValidatorTagMax checks a size or string field is at most the size given as param, e.g. `validate:"size_max=10GiB"`.
+	ValidatorTagMax = "size_max"
+)
+
+// RegisterValidation adds the size tags to the given validator instance.
+func RegisterValidation(v *libval.Validate) error {
+	if e := v.RegisterValidation(ValidatorTag, validateSize); e != nil {
+		return e
+	} else if e = v.RegisterValidation(ValidatorTagMin, validateSizeMin); e != nil {
+		return e
+	} else if e = v.RegisterValidation(ValidatorTagMax, validateSizeMax); e != nil {
+		return e
+	}
+
+	return nil
+}
+
+func fieldSize(fl libval.FieldLevel) (Size, bool) {
+	f := fl.Field()
+
+	switch f.Kind() {
+	case reflect.String:
+		if s := f.String(); len(s) < 1 {
+			return SizeNul, true
+		} else if z, e := parseString(s); e != nil {
+			return SizeNul, false
+		} else {
+			return z, true
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Size(f.Uint()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if i := f.Int(); i < 0 {
+			return SizeNul, false
+		} else {
+			return Size(i), true
+		}
+	}
+
+	return SizeNul, false
+}
+
+func validateSize(fl libval.FieldLevel) bool {
+	_, ok := fieldSize(fl)
+	return ok
+}
+
+func validateSizeMin(fl libval.FieldLevel) bool {
+	if z, ok := fieldSize(fl); !ok {
+		return false
+	} else if p, e := parseString(fl.Param()); e != nil {
+		return false
+	} else {
+		return z >= p
+	}
+}
+
+func validateSizeMax(fl libval.FieldLevel) bool {
+	if z, ok := fieldSize(fl); !ok {
+		return false
+	} else if p, e := parseString(fl.Param()); e != nil {
+		return false
+	} else {
+		return z <= p
+	}
+}
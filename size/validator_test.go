@@ -0,0 +1,111 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package bytes_test
+
+import (
+	libval "github.com/go-playground/validator/v10"
+	libsiz "github.com/nabbar/golib/size"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("size validator", func() {
+	var val = libval.New()
+
+	BeforeEach(func() {
+		Expect(libsiz.RegisterValidation(val)).ToNot(HaveOccurred())
+	})
+
+	DescribeTable("validated struct",
+		func(obj interface{}, tag string) {
+			e := val.Struct(obj)
+
+			if len(tag) < 1 {
+				Expect(e).ToNot(HaveOccurred())
+				return
+			}
+
+			Expect(e).To(HaveOccurred())
+
+			l, k := e.(libval.ValidationErrors)
+			Expect(k).To(BeTrue())
+			Expect(l).To(HaveLen(1))
+			Expect(l[0].Tag()).To(Equal(tag))
+		},
+		Entry("valid string", struct {
+			V string `validate:"size"`
+		}{"1GiB"}, ""),
+		Entry("empty string", struct {
+			V string `validate:"size"`
+		}{""}, ""),
+		Entry("malformed string", struct {
+			V string `validate:"size"`
+		}{"1XB"}, libsiz.ValidatorTag),
+		Entry("positive int", struct {
+			V int `validate:"size"`
+		}{1024}, ""),
+		Entry("negative int", struct {
+			V int `validate:"size"`
+		}{-1}, libsiz.ValidatorTag),
+		Entry("unsupported kind", struct {
+			V float64 `validate:"size"`
+		}{1}, libsiz.ValidatorTag),
+		Entry("size above min", struct {
+			V libsiz.Size `validate:"size_min=1MiB"`
+		}{2 * libsiz.SizeMega}, ""),
+		Entry("size equal to min in decimal notation", struct {
+			V libsiz.Size `validate:"size_min=1MB"`
+		}{libsiz.SizeMega}, ""),
+		Entry("size below min", struct {
+			V libsiz.Size `validate:"size_min=1MiB"`
+		}{512 * libsiz.SizeKilo}, libsiz.ValidatorTagMin),
+		Entry("string below min", struct {
+			V string `validate:"size_min=1MiB"`
+		}{"1023KiB"}, libsiz.ValidatorTagMin),
+		Entry("malformed min", struct {
+			V libsiz.Size `validate:"size_min=abc"`
+		}{libsiz.SizeMega}, libsiz.ValidatorTagMin),
+		Entry("uint below max", struct {
+			V uint64 `validate:"size_max=10GiB"`
+		}{uint64(libsiz.SizeGiga)}, ""),
+		Entry("string equal to max", struct {
+			V string `validate:"size_max=10GiB"`
+		}{"10GB"}, ""),
+		Entry("string above max", struct {
+			V string `validate:"size_max=10GiB"`
+		}{"11GiB"}, libsiz.ValidatorTagMax),
+		Entry("malformed max", struct {
+			V libsiz.Size `validate:"size_max=10XB"`
+		}{libsiz.SizeKilo}, libsiz.ValidatorTagMax),
+		Entry("size into the range", struct {
+			V libsiz.Size `validate:"size_min=1KiB,size_max=1MiB"`
+		}{64 * libsiz.SizeKilo}, ""),
+		Entry("size above the range", struct {
+			V libsiz.Size `validate:"size_min=1KiB,size_max=1MiB"`
+		}{2 * libsiz.SizeMega}, libsiz.ValidatorTagMax),
+	)
+})
@@ -108,6 +108,20 @@ func (s *smtpClient) Check(ctx context.Context) error {
 // Send is used to initiate the smtp connection with the client and send a mail before closing the connection.
 // This function is based on smtp.SendMail function.
 func (s *smtpClient) Send(ctx context.Context, from string, to []string, data io.WriterTo) error {
+	s.mut.Lock()
+
+	defer func() {
+		//mandatory for SMTP protocol
+		s._close()
+
+		s.mut.Unlock()
+	}()
+
+	return s._send(ctx, from, to, data)
+}
+
+// _send run the mail transaction on the current connection without closing it.
+func (s *smtpClient) _send(ctx context.Context, from string, to []string, data io.WriterTo) error {
 	//from smtp.SendMail()
 
 	var (
@@ -116,17 +130,10 @@ func (s *smtpClient) Send(ctx context.Context, from string, to []string, data io
 		w io.WriteCloser
 	)
 
-	s.mut.Lock()
-
 	defer func() {
 		if w != nil {
 			_ = w.Close()
 		}
-
-		//mandatory for SMTP protocol
-		s._close()
-
-		s.mut.Unlock()
 	}()
 
 	if e = s._ValidateLine(from); e != nil {
@@ -165,5 +172,12 @@ func (s *smtpClient) Send(ctx context.Context, from string, to []string, data io
 		return ErrorSMTPClientWrite.Error(e)
 	}
 
+	e = w.Close()
+	w = nil
+
+	if e != nil {
+		return ErrorSMTPClientWrite.Error(e)
+	}
+
 	return nil
 }
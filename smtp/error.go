@@ -44,6 +44,7 @@ const (
 	ErrorSMTPClientData
 	ErrorSMTPClientWrite
 	ErrorSMTPLineCRLF
+	ErrorSMTPPoolContext
 )
 
 func init() {
@@ -77,6 +78,8 @@ func getMessage(code liberr.CodeError) (message string) {
 		return "cannot write data to send contents of mail"
 	case ErrorSMTPLineCRLF:
 		return "smtp: A line must not contain CR or LF"
+	case ErrorSMTPPoolContext:
+		return "context is done while waiting for a free connection of the SMTP pool"
 	}
 
 	return liberr.NullMessage
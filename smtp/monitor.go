@@ -29,12 +29,12 @@ package smtp
 import (
 	"context"
 	"fmt"
-	"runtime"
 
 	libctx "github.com/nabbar/golib/context"
 	libmon "github.com/nabbar/golib/monitor"
 	moninf "github.com/nabbar/golib/monitor/info"
 	montps "github.com/nabbar/golib/monitor/types"
+	smtpcf "github.com/nabbar/golib/smtp/config"
 	libver "github.com/nabbar/golib/version"
 )
 
@@ -49,6 +49,12 @@ func (s *smtpClient) HealthCheck(ctx context.Context) error {
 
 // Monitor is used to return the monitor of the SMTP to check the connection to the server.
 func (s *smtpClient) Monitor(ctx libctx.FuncContext, vrs libver.Version) (montps.Monitor, error) {
+	return newMonitor(ctx, vrs, func() smtpcf.SMTP {
+		return s.cfg
+	}, s.HealthCheck)
+}
+
+func newMonitor(ctx libctx.FuncContext, vrs libver.Version, cfg func() smtpcf.SMTP, hlt montps.HealthCheck) (montps.Monitor, error) {
 	var (
 		e   error
 		inf moninf.Info
@@ -56,16 +62,16 @@ func (s *smtpClient) Monitor(ctx libctx.FuncContext, vrs libver.Version) (montps
 		res = make(map[string]interface{}, 0)
 	)
 
-	res["runtime"] = runtime.Version()[2:]
-	res["release"] = vrs.GetRelease()
-	res["build"] = vrs.GetBuild()
-	res["date"] = vrs.GetDate()
+	for k, v := range vrs.GetInfoMap() {
+		res[k] = v
+	}
 
 	if inf, e = moninf.New(defaultNameMonitor); e != nil {
 		return nil, e
 	} else {
 		inf.RegisterName(func() (string, error) {
-			return fmt.Sprintf("%s [%s:%d]", defaultNameMonitor, s.cfg.GetHost(), s.cfg.GetPort()), nil
+			c := cfg()
+			return fmt.Sprintf("%s [%s:%d]", defaultNameMonitor, c.GetHost(), c.GetPort()), nil
 		})
 		inf.RegisterInfo(func() (map[string]interface{}, error) {
 			return res, nil
@@ -76,7 +82,7 @@ func (s *smtpClient) Monitor(ctx libctx.FuncContext, vrs libver.Version) (montps
 		return nil, e
 	}
 
-	mon.SetHealthCheck(s.HealthCheck)
+	mon.SetHealthCheck(hlt)
 	if e = mon.Start(ctx()); e != nil {
 		return nil, e
 	}
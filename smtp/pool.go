@@ -0,0 +1,290 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package smtp
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net/smtp"
+	"sync"
+	"time"
+
+	libctx "github.com/nabbar/golib/context"
+	montps "github.com/nabbar/golib/monitor/types"
+	smtpcf "github.com/nabbar/golib/smtp/config"
+	libver "github.com/nabbar/golib/version"
+)
+
+const (
+	// DefaultPoolMaxConn is the default number of simultaneous connections of a pool.
+	DefaultPoolMaxConn = 4
+	// DefaultPoolIdleTimeout is the default duration an idle connection is kept open.
+	DefaultPoolIdleTimeout = 30 * time.Second
+)
+
+// PoolConfig defined the limits of a pooled SMTP sender.
+type PoolConfig struct {
+	// MaxConn is the maximum number of simultaneous connections to the server. By default, 4.
+	MaxConn int `json:"max-conn" yaml:"max-conn" toml:"max-conn" mapstructure:"max-conn"`
+
+	// MaxIdle is the maximum number of connections kept open between two mails. By default, MaxConn.
+	MaxIdle int `json:"max-idle" yaml:"max-idle" toml:"max-idle" mapstructure:"max-idle"`
+
+	// IdleTimeout is the duration an idle connection is kept open. By default, 30s.
+	IdleTimeout time.Duration `json:"idle-timeout" yaml:"idle-timeout" toml:"idle-timeout" mapstructure:"idle-timeout"`
+}
+
+func (c PoolConfig) normalize() PoolConfig {
+	if c.MaxConn < 1 {
+		c.MaxConn = DefaultPoolMaxConn
+	}
+
+	if c.MaxIdle < 1 || c.MaxIdle > c.MaxConn {
+		c.MaxIdle = c.MaxConn
+	}
+
+	if c.IdleTimeout <= 0 {
+		c.IdleTimeout = DefaultPoolIdleTimeout
+	}
+
+	return c
+}
+
+type poolConn struct {
+	c *smtpClient
+	t time.Time
+}
+
+type smtpPool struct {
+	mut sync.Mutex
+	cfg smtpcf.SMTP
+	tls *tls.Config
+	pcf PoolConfig
+	sem chan struct{}
+	idl []poolConn
+}
+
+// NewPool return a SMTP interface keeping the connections open between two mails.
+// The number of simultaneous connections is limited by the pool config, a mail waits for a free connection.
+// Each mail is sent into its own transaction (RSET) on an idle connection or on a new one.
+func NewPool(cfg smtpcf.SMTP, tlsConfig *tls.Config, pcf PoolConfig) (SMTP, error) {
+	if tlsConfig == nil {
+		/* #nosec */
+		//nolint #nosec
+		tlsConfig = &tls.Config{}
+	}
+
+	if cfg == nil {
+		return nil, ErrorParamEmpty.Error(nil)
+	}
+
+	pcf = pcf.normalize()
+
+	return &smtpPool{
+		mut: sync.Mutex{},
+		cfg: cfg,
+		tls: tlsConfig,
+		pcf: pcf,
+		sem: make(chan struct{}, pcf.MaxConn),
+		idl: make([]poolConn, 0, pcf.MaxIdle),
+	}, nil
+}
+
+func (p *smtpPool) newClient() *smtpClient {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	return &smtpClient{
+		mut: sync.Mutex{},
+		cfg: p.cfg,
+		tls: p.tls,
+	}
+}
+
+func (p *smtpPool) acquire(ctx context.Context) error {
+	select {
+	case p.sem <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ErrorSMTPPoolContext.Error(ctx.Err())
+	}
+}
+
+func (p *smtpPool) release() {
+	<-p.sem
+}
+
+// get returns the most recent idle connection still valid or a new client.
+func (p *smtpPool) get() (*smtpClient, bool) {
+	var (
+		old = make([]*smtpClient, 0)
+		res *smtpClient
+	)
+
+	p.mut.Lock()
+
+	for len(p.idl) > 0 && res == nil {
+		i := p.idl[len(p.idl)-1]
+		p.idl = p.idl[:len(p.idl)-1]
+
+		if time.Since(i.t) > p.pcf.IdleTimeout {
+			old = append(old, i.c)
+		} else {
+			res = i.c
+		}
+	}
+
+	p.mut.Unlock()
+
+	for _, c := range old {
+		c._close()
+	}
+
+	if res != nil {
+		return res, true
+	}
+
+	return p.newClient(), false
+}
+
+func (p *smtpPool) put(c *smtpClient) {
+	p.mut.Lock()
+
+	if len(p.idl) < p.pcf.MaxIdle && c.cfg == p.cfg {
+		p.idl = append(p.idl, poolConn{c: c, t: time.Now()})
+		c = nil
+	}
+
+	p.mut.Unlock()
+
+	if c != nil {
+		c._close()
+	}
+}
+
+func (p *smtpPool) flush() {
+	p.mut.Lock()
+	lst := p.idl
+	p.idl = make([]poolConn, 0, p.pcf.MaxIdle)
+	p.mut.Unlock()
+
+	for _, i := range lst {
+		i.c._close()
+	}
+}
+
+// Clone is used to create a new pool with same config.
+func (p *smtpPool) Clone() SMTP {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	return &smtpPool{
+		mut: sync.Mutex{},
+		cfg: p.cfg,
+		tls: p.tls,
+		pcf: p.pcf,
+		sem: make(chan struct{}, p.pcf.MaxConn),
+		idl: make([]poolConn, 0, p.pcf.MaxIdle),
+	}
+}
+
+// Close terminates all idle connections of the pool.
+func (p *smtpPool) Close() {
+	p.flush()
+}
+
+// UpdConfig is used to update the config & TLS Config for the pool, idle connections are closed.
+func (p *smtpPool) UpdConfig(cfg smtpcf.SMTP, tslConfig *tls.Config) {
+	p.mut.Lock()
+	p.cfg = cfg
+	p.tls = tslConfig
+	p.mut.Unlock()
+
+	p.flush()
+}
+
+// Client returns a new SMTP client out of the pool, the caller must quit it.
+func (p *smtpPool) Client(ctx context.Context) (*smtp.Client, error) {
+	return p.newClient()._client(ctx)
+}
+
+// Check sends a noop command with an idle or new connection of the pool.
+func (p *smtpPool) Check(ctx context.Context) error {
+	if e := p.acquire(ctx); e != nil {
+		return e
+	}
+
+	defer p.release()
+
+	c, _ := p.get()
+
+	if cli, e := c._client(ctx); e != nil {
+		c._close()
+		return e
+	} else if e = cli.Noop(); e != nil {
+		c._close()
+		return ErrorSMTPClientNoop.Error(e)
+	}
+
+	p.put(c)
+	return nil
+}
+
+// Send is used to send a mail with an idle or new connection of the pool. The connection
+// is kept open for the next mail if the transaction succeeds.
+func (p *smtpPool) Send(ctx context.Context, from string, to []string, data io.WriterTo) error {
+	if e := p.acquire(ctx); e != nil {
+		return e
+	}
+
+	defer p.release()
+
+	c, r := p.get()
+
+	if r && c.cli != nil {
+		if e := c.cli.Reset(); e != nil {
+			c._close()
+		}
+	}
+
+	if e := c._send(ctx, from, to, data); e != nil {
+		c._close()
+		return e
+	}
+
+	p.put(c)
+	return nil
+}
+
+// Monitor is used to return the monitor of the SMTP to check the connection to the server.
+func (p *smtpPool) Monitor(ctx libctx.FuncContext, vrs libver.Version) (montps.Monitor, error) {
+	return newMonitor(ctx, vrs, func() smtpcf.SMTP {
+		p.mut.Lock()
+		defer p.mut.Unlock()
+		return p.cfg
+	}, p.Check)
+}
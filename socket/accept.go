@@ -0,0 +1,223 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package socket
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+// DefaultAcceptHandshakeTimeout is the max duration of the TLS handshake done to fill the AcceptInfo.
+const DefaultAcceptHandshakeTimeout = 10 * time.Second
+
+// AcceptTLS is the TLS state of an accepted connection.
+type AcceptTLS struct {
+	Version            string   `json:"version"`
+	CipherSuite        string   `json:"cipher_suite"`
+	ServerName         string   `json:"server_name,omitempty"`
+	NegotiatedProtocol string   `json:"negotiated_protocol,omitempty"`
+	Resumed            bool     `json:"resumed"`
+	PeerCertificates   []string `json:"peer_certificates,omitempty"`
+	// Error is the handshake error if any. The connection is still given to the handler.
+	Error string `json:"error,omitempty"`
+}
+
+// AcceptInfo is the record of an accepted connection, designed for audit logging.
+type AcceptInfo struct {
+	// ID is the identifier of the connection, unique for the server instance.
+	ID      uint64    `json:"id"`
+	Time    time.Time `json:"time"`
+	Network string    `json:"network"`
+	Local   string    `json:"local"`
+	Remote  string    `json:"remote"`
+	// TLS is nil for a connection without TLS.
+	TLS *AcceptTLS `json:"tls,omitempty"`
+}
+
+var (
+	// ErrAcceptDenied is returned by the AcceptFilterAllow filter for a remote address not allowed.
+	ErrAcceptDenied = errors.New("connection denied by accept filter")
+	// ErrAcceptRate is returned by the AcceptFilterRate filter when the rate of connections is exceeded.
+	ErrAcceptRate = errors.New("connection rate exceeded")
+)
+
+// AcceptFilter is called right after the accept of a connection, before the TLS handshake and the start of the handler.
+// A non nil error rejects the connection, closed at once. It must be fast as it blocks the accept loop.
+type AcceptFilter func(con net.Conn) error
+
+// AcceptFilters return an AcceptFilter rejecting the connections rejected by any of the given filters.
+func AcceptFilters(f ...AcceptFilter) AcceptFilter {
+	return func(con net.Conn) error {
+		for _, i := range f {
+			if i == nil {
+				continue
+			} else if e := i(con); e != nil {
+				return e
+			}
+		}
+
+		return nil
+	}
+}
+
+// AcceptFilterAllow return an AcceptFilter rejecting the connections with a remote ip address out of the given
+// networks (CIDR like 10.0.0.0/8 or single ip). The connections without ip address (unix) are accepted.
+func AcceptFilterAllow(cidr ...string) (AcceptFilter, error) {
+	var lst = make([]*net.IPNet, 0, len(cidr))
+
+	for _, c := range cidr {
+		if _, n, e := net.ParseCIDR(c); e == nil {
+			lst = append(lst, n)
+		} else if i := net.ParseIP(c); i == nil {
+			return nil, e
+		} else if i.To4() != nil {
+			lst = append(lst, &net.IPNet{IP: i, Mask: net.CIDRMask(32, 32)})
+		} else {
+			lst = append(lst, &net.IPNet{IP: i, Mask: net.CIDRMask(128, 128)})
+		}
+	}
+
+	return func(con net.Conn) error {
+		var ip net.IP
+
+		switch a := con.RemoteAddr().(type) {
+		case *net.TCPAddr:
+			ip = a.IP
+		case *net.UDPAddr:
+			ip = a.IP
+		default:
+			return nil
+		}
+
+		for _, n := range lst {
+			if n.Contains(ip) {
+				return nil
+			}
+		}
+
+		return ErrAcceptDenied
+	}, nil
+}
+
+// AcceptFilterRate return an AcceptFilter rejecting the connections over the given number of connections
+// accepted by period, for all the remote addresses.
+func AcceptFilterRate(max int, period time.Duration) AcceptFilter {
+	var (
+		m sync.Mutex
+		t time.Time // start of the current period
+		n int       // connections accepted in the current period
+	)
+
+	return func(con net.Conn) error {
+		if max < 1 || period <= 0 {
+			return nil
+		}
+
+		m.Lock()
+		defer m.Unlock()
+
+		if now := time.Now(); now.Sub(t) >= period {
+			t = now
+			n = 0
+		}
+
+		if n >= max {
+			return ErrAcceptRate
+		}
+
+		n++
+		return nil
+	}
+}
+
+// FuncAcceptInfo is called exactly once for each accepted connection, before the handler is run.
+type FuncAcceptInfo func(info AcceptInfo)
+
+// NewAcceptInfo return the AcceptInfo of the given connection accepted at the given time.
+// For a TLS connection, the handshake is done with the given timeout to get the TLS state.
+func NewAcceptInfo(ctx context.Context, id uint64, acc time.Time, con net.Conn, timeout time.Duration) AcceptInfo {
+	var inf = AcceptInfo{
+		ID:   id,
+		Time: acc,
+	}
+
+	if a := con.LocalAddr(); a != nil {
+		inf.Network = a.Network()
+		inf.Local = a.String()
+	}
+
+	if a := con.RemoteAddr(); a != nil {
+		inf.Remote = a.String()
+	}
+
+	if t, k := con.(*tls.Conn); k {
+		inf.TLS = acceptTLS(ctx, t, timeout)
+	}
+
+	return inf
+}
+
+func acceptTLS(ctx context.Context, con *tls.Conn, timeout time.Duration) *AcceptTLS {
+	var (
+		res = &AcceptTLS{}
+		cnl context.CancelFunc
+	)
+
+	if timeout <= 0 {
+		timeout = DefaultAcceptHandshakeTimeout
+	}
+
+	ctx, cnl = context.WithTimeout(ctx, timeout)
+	defer cnl()
+
+	if e := con.HandshakeContext(ctx); e != nil {
+		res.Error = e.Error()
+	}
+
+	s := con.ConnectionState()
+
+	if s.Version != 0 {
+		res.Version = tls.VersionName(s.Version)
+	}
+
+	if s.CipherSuite != 0 {
+		res.CipherSuite = tls.CipherSuiteName(s.CipherSuite)
+	}
+
+	res.ServerName = s.ServerName
+	res.NegotiatedProtocol = s.NegotiatedProtocol
+	res.Resumed = s.DidResume
+
+	for _, c := range s.PeerCertificates {
+		res.PeerCertificates = append(res.PeerCertificates, c.Subject.String())
+	}
+
+	return res
+}
@@ -0,0 +1,128 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package bench_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime"
+	"strings"
+
+	libptc "github.com/nabbar/golib/network/protocol"
+	libsiz "github.com/nabbar/golib/size"
+	sckbch "github.com/nabbar/golib/socket/bench"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("socket/bench", func() {
+	Context("building scenarios from a matrix", func() {
+		It("must skip unsupported combinations and keep a stable order", func() {
+			m := sckbch.Matrix{
+				Networks:     []libptc.NetworkProtocol{libptc.NetworkTCP, libptc.NetworkUnix, libptc.NetworkUDP},
+				TLS:          []bool{false, true},
+				PayloadSizes: []libsiz.Size{64, libsiz.SizeKilo},
+				Concurrency:  []int{1, 4},
+				Messages:     10,
+			}
+
+			s := m.Scenarios()
+
+			if runtime.GOOS == "linux" {
+				Expect(s).To(HaveLen(12))
+			} else {
+				Expect(s).To(HaveLen(8))
+			}
+
+			for _, i := range s {
+				Expect(i.Network).ToNot(Equal(libptc.NetworkUDP))
+				Expect(i.Network == libptc.NetworkUnix && i.TLS).To(BeFalse())
+			}
+
+			Expect(s[0].Name()).To(Equal(m.Scenarios()[0].Name()))
+			Expect(s[0].Name()).To(HavePrefix("tcp/plain/"))
+		})
+
+		It("must reject invalid scenario", func() {
+			_, err := sckbch.New(sckbch.Options{}).Run(ctx, sckbch.Scenario{Network: libptc.NetworkTCP})
+			Expect(err).To(MatchError(sckbch.ErrInvalidScenario))
+
+			_, err = sckbch.New(sckbch.Options{}).Run(ctx, sckbch.Scenario{Network: libptc.NetworkUDP, PayloadSize: 1, Concurrency: 1, Messages: 1})
+			Expect(err).To(MatchError(sckbch.ErrUnsupportedNetwork))
+		})
+	})
+
+	Context("running a small matrix", func() {
+		var rep sckbch.Report
+
+		It("must succeed without any error", func() {
+			var err error
+
+			m := sckbch.Matrix{
+				Networks:     []libptc.NetworkProtocol{libptc.NetworkTCP, libptc.NetworkUnix},
+				TLS:          []bool{false, true},
+				PayloadSizes: []libsiz.Size{64, 256 * libsiz.SizeKilo},
+				Concurrency:  []int{2},
+				Messages:     20,
+			}
+
+			rep, err = sckbch.New(sckbch.Options{Warmup: 2, TempDir: GinkgoT().TempDir()}).RunAll(ctx, m.Scenarios()...)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rep.Results).To(HaveLen(len(m.Scenarios())))
+
+			for _, r := range rep.Results {
+				Expect(r.Errors).To(BeZero())
+				Expect(r.Messages).To(BeEquivalentTo(40))
+				Expect(r.Bytes).To(BeEquivalentTo(40 * r.Scenario.PayloadSize.Int64()))
+				Expect(r.Latency.Min).To(BeNumerically("<=", r.Latency.P50))
+				Expect(r.Latency.P50).To(BeNumerically("<=", r.Latency.P99))
+				Expect(r.Latency.P99).To(BeNumerically("<=", r.Latency.Max))
+				Expect(r.MessagesPerSecond()).To(BeNumerically(">", 0))
+			}
+		})
+
+		It("must write machine readable results", func() {
+			var (
+				buf = bytes.NewBuffer(nil)
+				dec = make(map[string]interface{})
+			)
+
+			Expect(rep.WriteJSON(buf)).ToNot(HaveOccurred())
+			Expect(json.Unmarshal(buf.Bytes(), &dec)).ToNot(HaveOccurred())
+			Expect(dec).To(HaveKey("environment"))
+			Expect(buf.String()).To(ContainSubstring("messages_per_second"))
+
+			buf.Reset()
+			Expect(rep.WriteCSV(buf)).ToNot(HaveOccurred())
+			Expect(strings.Split(strings.TrimSpace(buf.String()), "\n")).To(HaveLen(len(rep.Results) + 1))
+
+			buf.Reset()
+			Expect(rep.WriteMarkdown(buf)).ToNot(HaveOccurred())
+			Expect(buf.String()).To(ContainSubstring("| Network | TLS |"))
+		})
+	})
+})
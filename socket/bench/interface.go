@@ -0,0 +1,86 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package bench
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// DefaultSeed is the seed used to generate the payloads if none is given.
+	DefaultSeed int64 = 1
+
+	// DefaultTimeout is the max duration of one scenario.
+	DefaultTimeout = 5 * time.Minute
+)
+
+// Options define the harness options.
+type Options struct {
+	// Seed is the seed of the payloads generator. Same seed, same payloads.
+	Seed int64
+
+	// Warmup is the number of messages sent by each client before the measure starts.
+	Warmup int
+
+	// Timeout is the max duration of one scenario.
+	Timeout time.Duration
+
+	// TempDir is the directory used to create the unix socket files. Default is os.TempDir.
+	TempDir string
+}
+
+// Bench is a reproducible benchmark harness for the socket servers.
+// Each scenario starts a new echo server, connects the clients, measures
+// the round trip of each message and shutdown the server.
+type Bench interface {
+	// Run execute the given scenario and return its result.
+	Run(ctx context.Context, sc Scenario) (Result, error)
+
+	// RunAll execute all given scenarios in order and return the report.
+	// The execution stops at the first scenario returning an error.
+	RunAll(ctx context.Context, sc ...Scenario) (Report, error)
+}
+
+// New return a new harness based on the given options.
+func New(opt Options) Bench {
+	if opt.Seed == 0 {
+		opt.Seed = DefaultSeed
+	}
+
+	if opt.Timeout <= 0 {
+		opt.Timeout = DefaultTimeout
+	}
+
+	if opt.Warmup < 0 {
+		opt.Warmup = 0
+	}
+
+	return &bch{
+		o: opt,
+	}
+}
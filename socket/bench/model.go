@@ -0,0 +1,324 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package bench
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	libptc "github.com/nabbar/golib/network/protocol"
+	libsck "github.com/nabbar/golib/socket"
+	sckcfg "github.com/nabbar/golib/socket/config"
+)
+
+const (
+	pollRunning    = 10 * time.Millisecond
+	timeoutRunning = 5 * time.Second
+)
+
+type bch struct {
+	o Options
+}
+
+type worker struct {
+	c net.Conn        // connection
+	p []byte          // payload
+	b []byte          // read buffer
+	l []time.Duration // latencies
+	e int64           // errors
+}
+
+func (o *bch) RunAll(ctx context.Context, sc ...Scenario) (Report, error) {
+	var rep = Report{
+		Environment: newEnvironment(o.o.Seed),
+		Start:       time.Now(),
+		Results:     make([]Result, 0, len(sc)),
+	}
+
+	if o == nil {
+		return rep, ErrInvalidInstance
+	}
+
+	for _, s := range sc {
+		if r, e := o.Run(ctx, s); e != nil {
+			return rep, fmt.Errorf("scenario '%s': %w", s.Name(), e)
+		} else {
+			rep.Results = append(rep.Results, r)
+		}
+	}
+
+	return rep, nil
+}
+
+func (o *bch) Run(ctx context.Context, sc Scenario) (Result, error) {
+	var res = Result{
+		Scenario: sc,
+		Name:     sc.Name(),
+	}
+
+	if o == nil {
+		return res, ErrInvalidInstance
+	} else if e := sc.Validate(); e != nil {
+		return res, e
+	}
+
+	x, n := context.WithTimeout(ctx, o.o.Timeout)
+	defer n()
+
+	adr, err := o.address(sc.Network)
+	if err != nil {
+		return res, err
+	}
+
+	var cli *tls.Config
+	srv, err := o.server(x, sc, adr, &cli)
+
+	if err != nil {
+		return res, err
+	}
+
+	defer func() {
+		_ = srv.Close()
+		if sc.Network == libptc.NetworkUnix {
+			_ = os.Remove(adr)
+		}
+	}()
+
+	wrk := make([]*worker, 0, sc.Concurrency)
+
+	defer func() {
+		for _, w := range wrk {
+			_ = w.c.Close()
+		}
+	}()
+
+	for i := 0; i < sc.Concurrency; i++ {
+		var c net.Conn
+
+		if c, err = o.dial(x, sc.Network, adr, cli); err != nil {
+			return res, err
+		}
+
+		w := &worker{
+			c: c,
+			p: o.payload(sc.PayloadSize.Int(), i),
+			b: make([]byte, sc.PayloadSize.Int()),
+			l: make([]time.Duration, 0, sc.Messages),
+		}
+
+		wrk = append(wrk, w)
+
+		for j := 0; j < o.o.Warmup; j++ {
+			if _, err = w.echo(); err != nil {
+				return res, err
+			}
+		}
+	}
+
+	var (
+		wg  sync.WaitGroup
+		beg = make(chan struct{})
+	)
+
+	for _, w := range wrk {
+		wg.Add(1)
+
+		go func(w *worker) {
+			defer wg.Done()
+			<-beg
+			w.run(x, sc.Messages)
+		}(w)
+	}
+
+	tms := time.Now()
+	close(beg)
+	wg.Wait()
+	res.Duration = time.Since(tms)
+
+	var lat = make([]time.Duration, 0, sc.Concurrency*sc.Messages)
+
+	for _, w := range wrk {
+		lat = append(lat, w.l...)
+		res.Errors += w.e
+	}
+
+	res.Messages = int64(len(lat))
+	res.Bytes = res.Messages * sc.PayloadSize.Int64()
+	res.Latency = newLatency(lat)
+
+	return res, x.Err()
+}
+
+func (o *bch) payload(size int, idx int) []byte {
+	// #nosec
+	var (
+		r = rand.New(rand.NewSource(o.o.Seed + int64(idx)))
+		p = make([]byte, size)
+	)
+
+	_, _ = r.Read(p)
+	return p
+}
+
+func (o *bch) address(proto libptc.NetworkProtocol) (string, error) {
+	if proto == libptc.NetworkUnix {
+		var dir = o.o.TempDir
+
+		if len(dir) < 1 {
+			dir = os.TempDir()
+		}
+
+		return filepath.Join(dir, fmt.Sprintf("golib-bench-%d-%d.sock", os.Getpid(), time.Now().UnixNano())), nil
+	}
+
+	l, e := net.Listen(proto.Code(), "localhost:0")
+	if e != nil {
+		return "", e
+	}
+
+	defer func() {
+		_ = l.Close()
+	}()
+
+	return l.Addr().String(), nil
+}
+
+func (o *bch) server(ctx context.Context, sc Scenario, adr string, cli **tls.Config) (libsck.Server, error) {
+	cfg := sckcfg.ServerConfig{
+		Network:   sc.Network,
+		Address:   adr,
+		PermFile:  0600,
+		GroupPerm: -1,
+	}
+
+	srv, err := cfg.New(nil, echo)
+	if err != nil {
+		return nil, err
+	}
+
+	if sc.TLS {
+		s, c, e := newTLS()
+		if e != nil {
+			return nil, e
+		} else if e = srv.SetTLS(true, s); e != nil {
+			return nil, e
+		}
+		*cli = c
+	}
+
+	go func() {
+		_ = srv.Listen(ctx)
+	}()
+
+	var tmo = time.After(timeoutRunning)
+
+	for !srv.IsRunning() {
+		select {
+		case <-tmo:
+			_ = srv.Close()
+			return nil, ErrServerNotRunning
+		case <-ctx.Done():
+			_ = srv.Close()
+			return nil, ctx.Err()
+		case <-time.After(pollRunning):
+		}
+	}
+
+	return srv, nil
+}
+
+func (o *bch) dial(ctx context.Context, proto libptc.NetworkProtocol, adr string, cfg *tls.Config) (net.Conn, error) {
+	var d = &net.Dialer{}
+
+	if cfg == nil {
+		return d.DialContext(ctx, proto.Code(), adr)
+	}
+
+	t := &tls.Dialer{
+		NetDialer: d,
+		Config:    cfg,
+	}
+
+	return t.DialContext(ctx, proto.Code(), adr)
+}
+
+func (w *worker) run(ctx context.Context, nbr int) {
+	for i := 0; i < nbr; i++ {
+		if ctx.Err() != nil {
+			atomic.AddInt64(&w.e, int64(nbr-i))
+			return
+		} else if d, e := w.echo(); e != nil {
+			// the connection state is unknown after an error, all remaining messages are lost
+			atomic.AddInt64(&w.e, int64(nbr-i))
+			return
+		} else {
+			w.l = append(w.l, d)
+		}
+	}
+}
+
+func (w *worker) echo() (time.Duration, error) {
+	var (
+		t = time.Now()
+		c = make(chan error, 1)
+	)
+
+	// writing and reading concurrently, otherwise payloads bigger than the socket buffers would block both sides
+	go func() {
+		_, e := w.c.Write(w.p)
+		c <- e
+	}()
+
+	if _, e := io.ReadFull(w.c, w.b); e != nil {
+		return 0, e
+	} else if e = <-c; e != nil {
+		return 0, e
+	} else if !bytes.Equal(w.p, w.b) {
+		return 0, ErrEchoMismatch
+	}
+
+	return time.Since(t), nil
+}
+
+func echo(request libsck.Reader, response libsck.Writer) {
+	defer func() {
+		_ = request.Close()
+		_ = response.Close()
+	}()
+
+	_, _ = io.Copy(response, request)
+}
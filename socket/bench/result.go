@@ -0,0 +1,224 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package bench
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Latency is the distribution of the round trip duration of the messages of a scenario.
+type Latency struct {
+	Min  time.Duration `json:"min_ns"`
+	Mean time.Duration `json:"mean_ns"`
+	P50  time.Duration `json:"p50_ns"`
+	P90  time.Duration `json:"p90_ns"`
+	P99  time.Duration `json:"p99_ns"`
+	Max  time.Duration `json:"max_ns"`
+}
+
+// Result is the measure of one scenario.
+type Result struct {
+	Scenario Scenario      `json:"scenario"`
+	Name     string        `json:"name"`
+	Messages int64         `json:"messages"`
+	Bytes    int64         `json:"bytes"`
+	Errors   int64         `json:"errors"`
+	Duration time.Duration `json:"duration_ns"`
+	Latency  Latency       `json:"latency"`
+}
+
+// MessagesPerSecond return the number of round trip done by second.
+func (r Result) MessagesPerSecond() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+
+	return float64(r.Messages) / r.Duration.Seconds()
+}
+
+// Throughput return the number of MB (1024*1024 bytes) sent and received by second.
+func (r Result) Throughput() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+
+	return float64(2*r.Bytes) / (1024 * 1024) / r.Duration.Seconds()
+}
+
+func (r Result) MarshalJSON() ([]byte, error) {
+	type res Result
+
+	return json.Marshal(struct {
+		res
+		MessagesPerSecond float64 `json:"messages_per_second"`
+		Throughput        float64 `json:"throughput_mb_per_second"`
+	}{
+		res:               res(r),
+		MessagesPerSecond: r.MessagesPerSecond(),
+		Throughput:        r.Throughput(),
+	})
+}
+
+// Environment describe the host running the benchmark, to compare results between hardware.
+type Environment struct {
+	GoVersion string `json:"go_version"`
+	GOOS      string `json:"goos"`
+	GOARCH    string `json:"goarch"`
+	NumCPU    int    `json:"num_cpu"`
+	Seed      int64  `json:"seed"`
+}
+
+func newEnvironment(seed int64) Environment {
+	return Environment{
+		GoVersion: runtime.Version(),
+		GOOS:      runtime.GOOS,
+		GOARCH:    runtime.GOARCH,
+		NumCPU:    runtime.NumCPU(),
+		Seed:      seed,
+	}
+}
+
+// Report is the list of results of a benchmark session.
+type Report struct {
+	Environment Environment `json:"environment"`
+	Start       time.Time   `json:"start"`
+	Results     []Result    `json:"results"`
+}
+
+// WriteJSON write the report as indented json.
+func (r Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteCSV write one line by result, with a header line. Durations are in nanoseconds.
+func (r Report) WriteCSV(w io.Writer) error {
+	var c = csv.NewWriter(w)
+
+	if e := c.Write([]string{
+		"name", "network", "tls", "payload_bytes", "concurrency", "messages", "errors", "duration_ns",
+		"messages_per_second", "throughput_mb_per_second",
+		"latency_min_ns", "latency_mean_ns", "latency_p50_ns", "latency_p90_ns", "latency_p99_ns", "latency_max_ns",
+	}); e != nil {
+		return e
+	}
+
+	for _, i := range r.Results {
+		if e := c.Write([]string{
+			i.Name,
+			i.Scenario.Network.Code(),
+			strconv.FormatBool(i.Scenario.TLS),
+			strconv.FormatInt(i.Scenario.PayloadSize.Int64(), 10),
+			strconv.Itoa(i.Scenario.Concurrency),
+			strconv.FormatInt(i.Messages, 10),
+			strconv.FormatInt(i.Errors, 10),
+			strconv.FormatInt(i.Duration.Nanoseconds(), 10),
+			strconv.FormatFloat(i.MessagesPerSecond(), 'f', 2, 64),
+			strconv.FormatFloat(i.Throughput(), 'f', 2, 64),
+			strconv.FormatInt(i.Latency.Min.Nanoseconds(), 10),
+			strconv.FormatInt(i.Latency.Mean.Nanoseconds(), 10),
+			strconv.FormatInt(i.Latency.P50.Nanoseconds(), 10),
+			strconv.FormatInt(i.Latency.P90.Nanoseconds(), 10),
+			strconv.FormatInt(i.Latency.P99.Nanoseconds(), 10),
+			strconv.FormatInt(i.Latency.Max.Nanoseconds(), 10),
+		}); e != nil {
+			return e
+		}
+	}
+
+	c.Flush()
+	return c.Error()
+}
+
+// WriteMarkdown write the report as a markdown table, as used into the documentation.
+func (r Report) WriteMarkdown(w io.Writer) error {
+	var err error
+
+	p := func(format string, args ...interface{}) {
+		if err == nil {
+			_, err = fmt.Fprintf(w, format, args...)
+		}
+	}
+
+	p("Go %s, %s/%s, %d CPU\n\n", r.Environment.GoVersion, r.Environment.GOOS, r.Environment.GOARCH, r.Environment.NumCPU)
+	p("| Network | TLS | Payload | Clients | Msg/s | MB/s | p50 | p99 | Errors |\n")
+	p("|---|---|---:|---:|---:|---:|---:|---:|---:|\n")
+
+	for _, i := range r.Results {
+		p("| %s | %t | %s | %d | %.0f | %.2f | %s | %s | %d |\n",
+			i.Scenario.Network.Code(), i.Scenario.TLS, i.Scenario.PayloadSize.String(), i.Scenario.Concurrency,
+			i.MessagesPerSecond(), i.Throughput(), i.Latency.P50.String(), i.Latency.P99.String(), i.Errors)
+	}
+
+	return err
+}
+
+func newLatency(lst []time.Duration) Latency {
+	if len(lst) < 1 {
+		return Latency{}
+	}
+
+	sort.Slice(lst, func(i, j int) bool {
+		return lst[i] < lst[j]
+	})
+
+	var sum time.Duration
+
+	for _, d := range lst {
+		sum += d
+	}
+
+	return Latency{
+		Min:  lst[0],
+		Mean: sum / time.Duration(len(lst)),
+		P50:  percentile(lst, 50),
+		P90:  percentile(lst, 90),
+		P99:  percentile(lst, 99),
+		Max:  lst[len(lst)-1],
+	}
+}
+
+// percentile return the nearest rank percentile of a sorted list.
+func percentile(lst []time.Duration, p int) time.Duration {
+	i := (len(lst)*p + 99) / 100
+
+	if i < 1 {
+		i = 1
+	} else if i > len(lst) {
+		i = len(lst)
+	}
+
+	return lst[i-1]
+}
@@ -0,0 +1,148 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package bench
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+
+	libptc "github.com/nabbar/golib/network/protocol"
+	libsiz "github.com/nabbar/golib/size"
+)
+
+// Scenario define one benchmark run: one echo server and Concurrency clients
+// sending each Messages payloads of PayloadSize bytes and waiting for the echo.
+type Scenario struct {
+	// Network is the protocol of the server, only stream protocols (tcp, unix) are supported.
+	Network libptc.NetworkProtocol `json:"network" yaml:"network" toml:"network" mapstructure:"network"`
+
+	// TLS enable the tls layer on the server and the clients (tcp only).
+	TLS bool `json:"tls" yaml:"tls" toml:"tls" mapstructure:"tls"`
+
+	// PayloadSize is the size of each message sent by the clients.
+	PayloadSize libsiz.Size `json:"payload_size" yaml:"payload_size" toml:"payload_size" mapstructure:"payload_size"`
+
+	// Concurrency is the number of clients connected at the same time.
+	Concurrency int `json:"concurrency" yaml:"concurrency" toml:"concurrency" mapstructure:"concurrency"`
+
+	// Messages is the number of messages sent by each client.
+	Messages int `json:"messages" yaml:"messages" toml:"messages" mapstructure:"messages"`
+}
+
+// Name return a stable name of the scenario, usable as benchmark or table row name.
+func (s Scenario) Name() string {
+	var t = "plain"
+
+	if s.TLS {
+		t = "tls"
+	}
+
+	return fmt.Sprintf("%s/%s/%s/c%d", s.Network.Code(), t, strings.ReplaceAll(s.PayloadSize.String(), " ", ""), s.Concurrency)
+}
+
+func (s Scenario) Validate() error {
+	switch s.Network {
+	case libptc.NetworkTCP, libptc.NetworkTCP4, libptc.NetworkTCP6:
+	case libptc.NetworkUnix:
+		if !strings.EqualFold(runtime.GOOS, "linux") {
+			return ErrUnsupportedNetwork
+		} else if s.TLS {
+			return ErrUnsupportedTLS
+		}
+	default:
+		return ErrUnsupportedNetwork
+	}
+
+	if s.PayloadSize < 1 || s.Concurrency < 1 || s.Messages < 1 {
+		return ErrInvalidScenario
+	}
+
+	return nil
+}
+
+// Matrix define a set of values for each parameter of a scenario.
+// The Scenarios function return the cartesian product of all values.
+type Matrix struct {
+	Networks     []libptc.NetworkProtocol `json:"networks" yaml:"networks" toml:"networks" mapstructure:"networks"`
+	TLS          []bool                   `json:"tls" yaml:"tls" toml:"tls" mapstructure:"tls"`
+	PayloadSizes []libsiz.Size            `json:"payload_sizes" yaml:"payload_sizes" toml:"payload_sizes" mapstructure:"payload_sizes"`
+	Concurrency  []int                    `json:"concurrency" yaml:"concurrency" toml:"concurrency" mapstructure:"concurrency"`
+	Messages     int                      `json:"messages" yaml:"messages" toml:"messages" mapstructure:"messages"`
+}
+
+// DefaultMatrix return the matrix used to build the performance tables of the documentation.
+func DefaultMatrix() Matrix {
+	var ntw = []libptc.NetworkProtocol{libptc.NetworkTCP}
+
+	if strings.EqualFold(runtime.GOOS, "linux") {
+		ntw = append(ntw, libptc.NetworkUnix)
+	}
+
+	return Matrix{
+		Networks:     ntw,
+		TLS:          []bool{false, true},
+		PayloadSizes: []libsiz.Size{64 * libsiz.SizeUnit, libsiz.SizeKilo, 16 * libsiz.SizeKilo, 256 * libsiz.SizeKilo},
+		Concurrency:  []int{1, 8, 64},
+		Messages:     1000,
+	}
+}
+
+// Scenarios return all valid combinations of the matrix, in a stable order.
+// Combinations not supported (like tls over unix socket) are skipped.
+func (m Matrix) Scenarios() []Scenario {
+	var (
+		res = make([]Scenario, 0)
+		tls = m.TLS
+	)
+
+	if len(tls) < 1 {
+		tls = []bool{false}
+	}
+
+	for _, n := range m.Networks {
+		for _, t := range tls {
+			for _, p := range m.PayloadSizes {
+				for _, c := range m.Concurrency {
+					s := Scenario{
+						Network:     n,
+						TLS:         t,
+						PayloadSize: p,
+						Concurrency: c,
+						Messages:    m.Messages,
+					}
+
+					if s.Validate() == nil {
+						res = append(res, s)
+					}
+				}
+			}
+		}
+	}
+
+	return res
+}
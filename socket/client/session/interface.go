@@ -0,0 +1,99 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package session
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	libsck "github.com/nabbar/golib/socket"
+	sckdlm "github.com/nabbar/golib/socket/delim"
+)
+
+// Session keep the connection of a socket client open to exchange several requests and responses,
+// without the reconnection of each Once call. The requests can be pipelined: they are written without
+// waiting for the previous responses, and the responses are read in the order of the requests.
+// Each response is a frame ended by the delimiter of the session.
+type Session interface {
+	io.Closer
+
+	// Send write the given request as is, and return without waiting for its response.
+	// The callback is called with the response frame, without its delimiter, in the order of the requests.
+	// The context apply to the request and its response: if it's done before the response, the session
+	// is closed, as the following responses cannot be matched anymore with their requests.
+	Send(ctx context.Context, request io.Reader, fct libsck.Response) error
+
+	// Call send the given request and wait for its response to be processed by the callback.
+	Call(ctx context.Context, request io.Reader, fct libsck.Response) error
+
+	// Wait block until all the responses of the pending requests are processed, or the context is done.
+	// It returns the error that closed the session if any.
+	Wait(ctx context.Context) error
+
+	// Pending return the number of requests waiting for their response.
+	Pending() int
+
+	// Err return the error that closed the session, or nil if the session is open.
+	// A session closed by Close return ErrClosed.
+	Err() error
+}
+
+// New connect the given socket client and return a session over its connection, using the given
+// frame delimiter for the responses (libsck.EOL if zero). Closing the session close the connection.
+func New(ctx context.Context, cli libsck.Client, delim rune) (Session, error) {
+	if cli == nil {
+		return nil, ErrInstance
+	} else if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if delim == 0 {
+		delim = rune(libsck.EOL)
+	}
+
+	if e := cli.Connect(ctx); e != nil {
+		return nil, e
+	}
+
+	o := &ssn{
+		c: cli,
+		d: delim,
+		w: sync.Mutex{},
+		m: sync.Mutex{},
+		n: sync.WaitGroup{},
+	}
+
+	o.k = sync.NewCond(&o.m)
+
+	// the frame reader must not close the socket client, the close is done by the Close function
+	o.b = sckdlm.New(io.NopCloser(cli), delim, 0)
+
+	go o.read()
+
+	return o, nil
+}
@@ -0,0 +1,263 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package session
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+
+	libsck "github.com/nabbar/golib/socket"
+	sckdlm "github.com/nabbar/golib/socket/delim"
+)
+
+type req struct {
+	c context.Context
+	f libsck.Response
+	s func() bool // stop the watch of the context
+	d chan error  // result
+}
+
+type ssn struct {
+	c libsck.Client
+	d rune
+	b sckdlm.BufferDelim
+
+	w sync.Mutex     // write lock, to keep the order of the queue and of the connection
+	m sync.Mutex     // queue lock
+	k *sync.Cond     // queue signal
+	q []*req         // requests waiting for their response
+	e error          // error that closed the session
+	n sync.WaitGroup // pending requests
+}
+
+func (o *ssn) Send(ctx context.Context, request io.Reader, fct libsck.Response) error {
+	_, e := o.send(ctx, request, fct)
+	return e
+}
+
+func (o *ssn) Call(ctx context.Context, request io.Reader, fct libsck.Response) error {
+	if r, e := o.send(ctx, request, fct); e != nil {
+		return e
+	} else {
+		return <-r.d
+	}
+}
+
+func (o *ssn) Wait(ctx context.Context) error {
+	if o == nil {
+		return ErrInstance
+	} else if ctx == nil {
+		ctx = context.Background()
+	}
+
+	var c = make(chan struct{})
+
+	go func() {
+		o.n.Wait()
+		close(c)
+	}()
+
+	select {
+	case <-c:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	if e := o.Err(); e != ErrClosed {
+		return e
+	}
+
+	return nil
+}
+
+func (o *ssn) Pending() int {
+	if o == nil {
+		return 0
+	}
+
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	return len(o.q)
+}
+
+func (o *ssn) Err() error {
+	if o == nil {
+		return ErrInstance
+	}
+
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	return o.e
+}
+
+func (o *ssn) Close() error {
+	if o == nil {
+		return ErrInstance
+	}
+
+	return o.fail(ErrClosed)
+}
+
+func (o *ssn) send(ctx context.Context, request io.Reader, fct libsck.Response) (*req, error) {
+	if o == nil || o.c == nil {
+		return nil, ErrInstance
+	} else if ctx == nil {
+		ctx = context.Background()
+	}
+
+	var r = &req{
+		c: ctx,
+		f: fct,
+		d: make(chan error, 1),
+	}
+
+	o.w.Lock()
+	defer o.w.Unlock()
+
+	o.m.Lock()
+	if o.e != nil {
+		o.m.Unlock()
+		return nil, o.e
+	}
+
+	r.s = context.AfterFunc(ctx, func() {
+		_ = o.fail(ctx.Err())
+	})
+
+	o.n.Add(1)
+	o.q = append(o.q, r)
+	o.k.Signal()
+	o.m.Unlock()
+
+	if request != nil {
+		if _, e := io.Copy(o.c, request); e != nil {
+			if c := ctx.Err(); c != nil {
+				e = c
+			}
+
+			_ = o.fail(e)
+			return nil, e
+		}
+	}
+
+	return r, nil
+}
+
+// fail close the session with the given error and end the pending requests.
+// A response being processed by its callback is ended by the reader.
+func (o *ssn) fail(err error) error {
+	o.m.Lock()
+
+	if o.e != nil {
+		o.m.Unlock()
+		return nil
+	}
+
+	var lst = o.q
+
+	o.e = err
+	o.q = nil
+	o.k.Broadcast()
+	o.m.Unlock()
+
+	e := o.c.Close()
+
+	for _, r := range lst {
+		o.end(r, err)
+	}
+
+	return libsck.ErrorFilter(e)
+}
+
+func (o *ssn) end(r *req, err error) {
+	if r.s != nil {
+		r.s()
+	}
+
+	r.d <- err
+	o.n.Done()
+}
+
+// read process the responses in the order of the requests, until the session is closed.
+func (o *ssn) read() {
+	for {
+		o.m.Lock()
+
+		for len(o.q) < 1 && o.e == nil {
+			o.k.Wait()
+		}
+
+		if o.e != nil {
+			o.m.Unlock()
+			return
+		}
+
+		// the request stays into the queue while its response is read,
+		// to be ended by fail if the session is closed before
+		r := o.q[0]
+		o.m.Unlock()
+
+		p, e := o.b.ReadBytes()
+
+		if len(p) > 0 && p[len(p)-1] == byte(o.d) {
+			p = p[:len(p)-1]
+		} else if e == nil {
+			e = io.ErrUnexpectedEOF
+		}
+
+		o.m.Lock()
+
+		if len(o.q) < 1 || o.q[0] != r {
+			// already ended by fail
+			o.m.Unlock()
+			return
+		}
+
+		o.q = o.q[1:]
+		o.m.Unlock()
+
+		if e != nil {
+			if c := r.c.Err(); c != nil {
+				e = c
+			}
+
+			o.end(r, e)
+			_ = o.fail(e)
+			return
+		}
+
+		if r.f != nil {
+			r.f(bytes.NewReader(p))
+		}
+
+		o.end(r, nil)
+	}
+}
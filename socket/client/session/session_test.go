@@ -0,0 +1,362 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package session_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	libptc "github.com/nabbar/golib/network/protocol"
+	sckclt "github.com/nabbar/golib/socket/client"
+	sckssn "github.com/nabbar/golib/socket/client/session"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const (
+	cmdHang = "hang" // the server stops replying
+	cmdDrop = "drop" // the server closes the connection
+)
+
+// echoServer starts a local tcp server replying each line as is, and return its address and its stop function.
+func echoServer() (string, func()) {
+	lis, err := net.Listen(libptc.NetworkTCP.Code(), "127.0.0.1:0")
+	Expect(err).ToNot(HaveOccurred())
+
+	var (
+		wg  sync.WaitGroup
+		mux sync.Mutex
+		con = make([]net.Conn, 0)
+	)
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		for {
+			c, e := lis.Accept()
+			if e != nil {
+				return
+			}
+
+			mux.Lock()
+			con = append(con, c)
+			mux.Unlock()
+
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+				defer func() {
+					_ = c.Close()
+				}()
+
+				var r = bufio.NewReader(c)
+
+				for {
+					l, e := r.ReadString('\n')
+					if e != nil {
+						return
+					}
+
+					switch strings.TrimSuffix(l, "\n") {
+					case cmdHang:
+						_, _ = io.Copy(io.Discard, r)
+						return
+					case cmdDrop:
+						return
+					}
+
+					if _, e = c.Write([]byte(l)); e != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+
+	return lis.Addr().String(), func() {
+		_ = lis.Close()
+
+		mux.Lock()
+		for _, c := range con {
+			_ = c.Close()
+		}
+		mux.Unlock()
+
+		wg.Wait()
+	}
+}
+
+func newSession(adr string) sckssn.Session {
+	cli, err := sckclt.New(libptc.NetworkTCP, adr)
+	Expect(err).ToNot(HaveOccurred())
+
+	s, err := sckssn.New(ctx, cli, 0)
+	Expect(err).ToNot(HaveOccurred())
+
+	return s
+}
+
+func line(s string) io.Reader {
+	return strings.NewReader(s + "\n")
+}
+
+// collect return a response callback storing the response into the given string.
+func collect(s *string) func(r io.Reader) {
+	return func(r io.Reader) {
+		p, _ := io.ReadAll(r)
+		*s = string(p)
+	}
+}
+
+var _ = Describe("socket/client/session", func() {
+	var (
+		adr string
+		stp func()
+	)
+
+	BeforeEach(func() {
+		adr, stp = echoServer()
+	})
+
+	AfterEach(func() {
+		stp()
+	})
+
+	Context("with several requests in flight", func() {
+		It("must match the responses with the requests in order", func() {
+			var (
+				ssn = newSession(adr)
+				nbr = 100
+				res = make([]string, 0, nbr)
+			)
+
+			defer func() {
+				Expect(ssn.Close()).ToNot(HaveOccurred())
+			}()
+
+			for i := 0; i < nbr; i++ {
+				Expect(ssn.Send(ctx, line("req-"+strconv.Itoa(i)), func(r io.Reader) {
+					p, _ := io.ReadAll(r)
+					res = append(res, string(p))
+				})).ToNot(HaveOccurred())
+			}
+
+			Expect(ssn.Wait(ctx)).ToNot(HaveOccurred())
+			Expect(ssn.Pending()).To(BeZero())
+			Expect(res).To(HaveLen(nbr))
+
+			for i := 0; i < nbr; i++ {
+				Expect(res[i]).To(Equal("req-" + strconv.Itoa(i)))
+			}
+		})
+
+		It("must give its own response to each concurrent call", func() {
+			var (
+				ssn = newSession(adr)
+				wg  sync.WaitGroup
+				res = make([]string, 50)
+				ers = make([]error, 50)
+			)
+
+			defer func() {
+				Expect(ssn.Close()).ToNot(HaveOccurred())
+			}()
+
+			for i := range res {
+				wg.Add(1)
+
+				go func(i int) {
+					defer wg.Done()
+					ers[i] = ssn.Call(ctx, line("call-"+strconv.Itoa(i)), collect(&res[i]))
+				}(i)
+			}
+
+			wg.Wait()
+
+			for i := range res {
+				Expect(ers[i]).ToNot(HaveOccurred())
+				Expect(res[i]).To(Equal("call-" + strconv.Itoa(i)))
+			}
+
+			Expect(ssn.Err()).ToNot(HaveOccurred())
+		})
+	})
+
+	Context("with a pending request", func() {
+		It("must end the request and close the session when its context is done", func() {
+			var (
+				ssn = newSession(adr)
+				res string
+				end = make(chan error, 1)
+			)
+
+			defer func() {
+				_ = ssn.Close()
+			}()
+
+			Expect(ssn.Call(ctx, line("first"), collect(&res))).ToNot(HaveOccurred())
+			Expect(res).To(Equal("first"))
+
+			x, c := context.WithCancel(ctx)
+
+			// a request sent after the cancelled one cannot be matched anymore and must be ended too
+			go func() {
+				end <- ssn.Call(x, line(cmdHang), nil)
+			}()
+
+			Eventually(ssn.Pending, 5*time.Second, 10*time.Millisecond).Should(Equal(1))
+			Expect(ssn.Send(ctx, line("next"), nil)).ToNot(HaveOccurred())
+
+			c()
+
+			Eventually(end, 5*time.Second).Should(Receive(MatchError(context.Canceled)))
+			Expect(ssn.Wait(ctx)).To(MatchError(context.Canceled))
+			Expect(ssn.Err()).To(MatchError(context.Canceled))
+			Expect(ssn.Pending()).To(BeZero())
+			Expect(ssn.Send(ctx, line("after"), nil)).To(HaveOccurred())
+		})
+
+		It("must return the deadline error of a call without response", func() {
+			var ssn = newSession(adr)
+
+			defer func() {
+				_ = ssn.Close()
+			}()
+
+			x, c := context.WithTimeout(ctx, 100*time.Millisecond)
+			defer c()
+
+			Expect(ssn.Call(x, line(cmdHang), nil)).To(MatchError(context.DeadlineExceeded))
+			Expect(ssn.Err()).To(MatchError(context.DeadlineExceeded))
+		})
+	})
+
+	Context("when the connection drops", func() {
+		It("must fail all the pending requests", func() {
+			var (
+				ssn = newSession(adr)
+				res = make([]string, 0)
+				ers = make(chan error, 4)
+				wg  sync.WaitGroup
+			)
+
+			defer func() {
+				_ = ssn.Close()
+			}()
+
+			Expect(ssn.Send(ctx, line("a"), func(r io.Reader) {
+				p, _ := io.ReadAll(r)
+				res = append(res, string(p))
+			})).ToNot(HaveOccurred())
+
+			Expect(ssn.Call(ctx, line("b"), nil)).ToNot(HaveOccurred())
+			Expect(ssn.Send(ctx, line(cmdDrop), nil)).ToNot(HaveOccurred())
+
+			// the calls sent after the drop wait in the queue and are never answered
+			for _, s := range []string{"c", "d", "e", "f"} {
+				wg.Add(1)
+
+				go func(s string) {
+					defer wg.Done()
+					ers <- ssn.Call(ctx, line(s), nil)
+				}(s)
+			}
+
+			wg.Wait()
+			close(ers)
+
+			for e := range ers {
+				Expect(e).To(HaveOccurred())
+			}
+
+			Expect(res).To(Equal([]string{"a"}))
+			Expect(ssn.Pending()).To(BeZero())
+			Expect(ssn.Err()).To(HaveOccurred())
+			Expect(ssn.Wait(ctx)).To(HaveOccurred())
+			Expect(ssn.Send(ctx, line("after"), nil)).To(HaveOccurred())
+		})
+	})
+
+	Context("closing the session", func() {
+		It("must end all the requests in flight with ErrClosed", func() {
+			var (
+				ssn = newSession(adr)
+				nbr = 20
+				ers = make(chan error, nbr)
+				wg  sync.WaitGroup
+			)
+
+			Expect(ssn.Send(ctx, line(cmdHang), nil)).ToNot(HaveOccurred())
+
+			for i := 0; i < nbr; i++ {
+				wg.Add(1)
+
+				go func(i int) {
+					defer wg.Done()
+					ers <- ssn.Call(ctx, line("call-"+strconv.Itoa(i)), nil)
+				}(i)
+			}
+
+			Eventually(ssn.Pending, 5*time.Second, 10*time.Millisecond).Should(Equal(nbr + 1))
+
+			var cls sync.WaitGroup
+
+			// concurrent closes must end each request once
+			for i := 0; i < 3; i++ {
+				cls.Add(1)
+
+				go func() {
+					defer cls.Done()
+					_ = ssn.Close()
+				}()
+			}
+
+			cls.Wait()
+			wg.Wait()
+			close(ers)
+
+			for e := range ers {
+				Expect(errors.Is(e, sckssn.ErrClosed)).To(BeTrue())
+			}
+
+			Expect(ssn.Pending()).To(BeZero())
+			Expect(ssn.Err()).To(MatchError(sckssn.ErrClosed))
+			Expect(ssn.Wait(ctx)).ToNot(HaveOccurred())
+			Expect(ssn.Call(ctx, line("after"), nil)).To(MatchError(sckssn.ErrClosed))
+		})
+	})
+})
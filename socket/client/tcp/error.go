@@ -32,4 +32,5 @@ var (
 	ErrInstance   = fmt.Errorf("invalid instance")
 	ErrConnection = fmt.Errorf("invalid connection")
 	ErrAddress    = fmt.Errorf("invalid dial address")
+	ErrHalfClose  = fmt.Errorf("half close not supported by the connection")
 )
@@ -31,11 +31,17 @@ import (
 	"sync/atomic"
 
 	libptc "github.com/nabbar/golib/network/protocol"
+	libres "github.com/nabbar/golib/network/resolver"
 	libsck "github.com/nabbar/golib/socket"
 )
 
 type ClientTCP interface {
 	libsck.Client
+	libsck.HalfCloser
+
+	// SetResolver define the resolver used to resolve the host of the address at each connection.
+	// A nil resolver restore the system resolver.
+	SetResolver(r libres.Resolver)
 }
 
 func New(address string) (ClientTCP, error) {
@@ -55,5 +61,6 @@ func New(address string) (ClientTCP, error) {
 		e: new(atomic.Value),
 		i: new(atomic.Value),
 		c: new(atomic.Value),
+		r: new(atomic.Pointer[libres.Resolver]),
 	}, nil
 }
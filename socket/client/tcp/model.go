@@ -38,6 +38,7 @@ import (
 
 	libtls "github.com/nabbar/golib/certificates"
 	libptc "github.com/nabbar/golib/network/protocol"
+	libres "github.com/nabbar/golib/network/resolver"
 	libsck "github.com/nabbar/golib/socket"
 )
 
@@ -52,6 +53,7 @@ type cli struct {
 	e *atomic.Value // function error
 	i *atomic.Value // function info
 	c *atomic.Value // net.Conn
+	r *atomic.Pointer[libres.Resolver]
 }
 
 func (o *cli) SetTLS(enable bool, config libtls.TLSConfig, serverName string) error {
@@ -143,6 +145,8 @@ func (o *cli) dial(ctx context.Context) (net.Conn, error) {
 		return nil, ErrAddress
 	} else if adr, ok := v.(string); !ok {
 		return nil, ErrAddress
+	} else if r := o.r.Load(); r != nil && *r != nil {
+		return o.dialResolver(ctx, *r, d, adr)
 	} else if i := o.t.Load(); i != nil {
 		if t, k := i.(*tlsCfg); k && t.enable {
 			u := &tls.Dialer{
@@ -158,6 +162,48 @@ func (o *cli) dial(ctx context.Context) (net.Conn, error) {
 	}
 }
 
+// dialResolver dial the address resolved by the given resolver, and run the tls handshake if enabled.
+func (o *cli) dialResolver(ctx context.Context, r libres.Resolver, d *net.Dialer, adr string) (net.Conn, error) {
+	con, err := r.DialContext(d)(ctx, libptc.NetworkTCP.Code(), adr)
+
+	if err != nil {
+		return nil, err
+	}
+
+	i := o.t.Load()
+
+	if i == nil {
+		return con, nil
+	} else if t, k := i.(*tlsCfg); !k || !t.enable {
+		return con, nil
+	} else {
+		var cfg = t.config.Clone()
+
+		if len(cfg.ServerName) < 1 {
+			cfg.ServerName, _, _ = net.SplitHostPort(adr)
+		}
+
+		c := tls.Client(con, cfg)
+
+		if err = c.HandshakeContext(ctx); err != nil {
+			_ = con.Close()
+			return nil, err
+		}
+
+		return c, nil
+	}
+}
+
+func (o *cli) SetResolver(r libres.Resolver) {
+	if o == nil {
+		return
+	} else if r == nil {
+		o.r.Store(nil)
+	} else {
+		o.r.Store(&r)
+	}
+}
+
 func (o *cli) IsConnected() bool {
 	if o == nil {
 		return false
@@ -235,6 +281,36 @@ func (o *cli) Close() error {
 	}
 }
 
+func (o *cli) CloseRead() error {
+	if o == nil {
+		return ErrInstance
+	} else if i := o.c.Load(); i == nil {
+		return ErrConnection
+	} else if c, k := i.(net.Conn); !k {
+		return ErrConnection
+	} else if r, l := c.(interface{ CloseRead() error }); !l {
+		return ErrHalfClose
+	} else {
+		o.fctInfo(c.LocalAddr(), c.RemoteAddr(), libsck.ConnectionCloseRead)
+		return r.CloseRead()
+	}
+}
+
+func (o *cli) CloseWrite() error {
+	if o == nil {
+		return ErrInstance
+	} else if i := o.c.Load(); i == nil {
+		return ErrConnection
+	} else if c, k := i.(net.Conn); !k {
+		return ErrConnection
+	} else if w, l := c.(interface{ CloseWrite() error }); !l {
+		return ErrHalfClose
+	} else {
+		o.fctInfo(c.LocalAddr(), c.RemoteAddr(), libsck.ConnectionCloseWrite)
+		return w.CloseWrite()
+	}
+}
+
 func (o *cli) Once(ctx context.Context, request io.Reader, fct libsck.Response) error {
 	if o == nil {
 		return ErrInstance
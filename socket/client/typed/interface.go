@@ -0,0 +1,84 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package typed
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	libsck "github.com/nabbar/golib/socket"
+	sckcdc "github.com/nabbar/golib/socket/codec"
+)
+
+// Client is a request / response client exchanging typed messages over a socket client.
+// Each message is marshalled with the codec and framed with the delimiter.
+type Client[TReq any, TResp any] interface {
+	io.Closer
+
+	// Connect establish the connection of the underlying socket client.
+	Connect(ctx context.Context) error
+
+	// IsConnected returns true if the connection of the underlying socket client is established.
+	IsConnected() bool
+
+	// Send marshal and write the given request.
+	Send(ctx context.Context, req TReq) error
+
+	// Receive read and unmarshal the next response.
+	Receive(ctx context.Context) (TResp, error)
+
+	// Call send the given request and wait for its response. Concurrent calls are serialized.
+	// If the context is done before the response, the connection is closed.
+	Call(ctx context.Context, req TReq) (TResp, error)
+
+	// Once connect, call with the given request and close the connection.
+	Once(ctx context.Context, req TReq) (TResp, error)
+}
+
+// New return a typed client over the given socket client, using the given codec (JSON if nil)
+// and the given frame delimiter (libsck.EOL if zero).
+func New[TReq any, TResp any](cli libsck.Client, cdc sckcdc.Codec, delim rune) (Client[TReq, TResp], error) {
+	if cli == nil {
+		return nil, ErrInstance
+	}
+
+	if cdc == nil {
+		cdc = sckcdc.JSON()
+	}
+
+	if delim == 0 {
+		delim = rune(libsck.EOL)
+	}
+
+	return &tpc[TReq, TResp]{
+		c: cli,
+		k: cdc,
+		d: delim,
+		r: sync.Mutex{},
+		w: sync.Mutex{},
+	}, nil
+}
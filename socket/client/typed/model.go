@@ -0,0 +1,214 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package typed
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+
+	libsck "github.com/nabbar/golib/socket"
+	sckcdc "github.com/nabbar/golib/socket/codec"
+	sckdlm "github.com/nabbar/golib/socket/delim"
+)
+
+type tpc[TReq any, TResp any] struct {
+	c libsck.Client
+	k sckcdc.Codec
+	d rune
+
+	r sync.Mutex         // read lock
+	w sync.Mutex         // write lock
+	b sckdlm.BufferDelim // frame reader of the current connection
+}
+
+func (o *tpc[TReq, TResp]) Connect(ctx context.Context) error {
+	if o == nil || o.c == nil {
+		return ErrInstance
+	}
+
+	o.r.Lock()
+	defer o.r.Unlock()
+
+	if e := o.c.Connect(ctx); e != nil {
+		return e
+	}
+
+	// the frame reader must not close the socket client, the close is done by the Close function
+	o.b = sckdlm.New(io.NopCloser(o.c), o.d, 0)
+
+	return nil
+}
+
+func (o *tpc[TReq, TResp]) IsConnected() bool {
+	if o == nil || o.c == nil {
+		return false
+	}
+
+	return o.c.IsConnected()
+}
+
+func (o *tpc[TReq, TResp]) Close() error {
+	if o == nil || o.c == nil {
+		return ErrInstance
+	}
+
+	return o.c.Close()
+}
+
+func (o *tpc[TReq, TResp]) Send(ctx context.Context, req TReq) error {
+	if o == nil || o.c == nil {
+		return ErrInstance
+	}
+
+	o.w.Lock()
+	defer o.w.Unlock()
+
+	return o.send(ctx, req)
+}
+
+func (o *tpc[TReq, TResp]) Receive(ctx context.Context) (TResp, error) {
+	if o == nil || o.c == nil {
+		var res TResp
+		return res, ErrInstance
+	}
+
+	o.r.Lock()
+	defer o.r.Unlock()
+
+	return o.receive(ctx)
+}
+
+func (o *tpc[TReq, TResp]) Call(ctx context.Context, req TReq) (TResp, error) {
+	var res TResp
+
+	if o == nil || o.c == nil {
+		return res, ErrInstance
+	}
+
+	o.r.Lock()
+	defer o.r.Unlock()
+
+	o.w.Lock()
+	err := o.send(ctx, req)
+	o.w.Unlock()
+
+	if err != nil {
+		return res, err
+	}
+
+	return o.receive(ctx)
+}
+
+func (o *tpc[TReq, TResp]) Once(ctx context.Context, req TReq) (TResp, error) {
+	var res TResp
+
+	if o == nil || o.c == nil {
+		return res, ErrInstance
+	} else if e := o.Connect(ctx); e != nil {
+		return res, e
+	}
+
+	defer func() {
+		_ = o.Close()
+	}()
+
+	return o.Call(ctx, req)
+}
+
+// send marshal and write the request. Must be called with the write lock held.
+func (o *tpc[TReq, TResp]) send(ctx context.Context, req TReq) error {
+	p, e := o.k.Marshal(req)
+
+	if e != nil {
+		return e
+	} else if bytes.IndexByte(p, byte(o.d)) >= 0 {
+		return ErrFrame
+	}
+
+	var stp = o.closeOnDone(ctx)
+	defer stp()
+
+	if _, e = o.c.Write(append(p, byte(o.d))); e != nil {
+		return o.ctxErr(ctx, e)
+	}
+
+	return nil
+}
+
+// receive read and unmarshal the next response. Must be called with the read lock held.
+func (o *tpc[TReq, TResp]) receive(ctx context.Context) (TResp, error) {
+	var res TResp
+
+	if o.b == nil {
+		return res, ErrConnection
+	}
+
+	var stp = o.closeOnDone(ctx)
+	defer stp()
+
+	p, e := o.b.ReadBytes()
+
+	if len(p) > 0 && p[len(p)-1] == byte(o.d) {
+		p = p[:len(p)-1]
+	} else if e == nil {
+		e = io.ErrUnexpectedEOF
+	}
+
+	if e != nil && len(p) < 1 {
+		return res, o.ctxErr(ctx, e)
+	} else if e != nil {
+		// a partial frame is not a valid message
+		return res, o.ctxErr(ctx, io.ErrUnexpectedEOF)
+	}
+
+	if e = o.k.Unmarshal(p, &res); e != nil {
+		return res, e
+	}
+
+	return res, nil
+}
+
+// closeOnDone close the connection if the context is done before the returned function is called,
+// to unblock a pending read or write.
+func (o *tpc[TReq, TResp]) closeOnDone(ctx context.Context) func() bool {
+	if ctx == nil {
+		return func() bool { return false }
+	}
+
+	return context.AfterFunc(ctx, func() {
+		_ = o.c.Close()
+	})
+}
+
+func (o *tpc[TReq, TResp]) ctxErr(ctx context.Context, err error) error {
+	if ctx != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	return err
+}
@@ -0,0 +1,371 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package typed_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	libptc "github.com/nabbar/golib/network/protocol"
+	sckclt "github.com/nabbar/golib/socket/client"
+	scktpc "github.com/nabbar/golib/socket/client/typed"
+	sckcdc "github.com/nabbar/golib/socket/codec"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const (
+	opAdd   = "add"   // reply the sum
+	opSplit = "split" // reply the sum, byte by byte
+	opBad   = "bad"   // reply a frame not decodable
+	opShort = "short" // reply a partial frame and close the connection
+	opClose = "close" // close the connection without reply
+	opHang  = "hang"  // never reply
+)
+
+type request struct {
+	Op string `json:"op"`
+	A  int    `json:"a"`
+	B  int    `json:"b"`
+}
+
+type response struct {
+	Sum int `json:"sum"`
+}
+
+// typedServer starts a local tcp server replying the json requests, and return its address and its stop function.
+func typedServer() (string, func()) {
+	lis, err := net.Listen(libptc.NetworkTCP.Code(), "127.0.0.1:0")
+	Expect(err).ToNot(HaveOccurred())
+
+	var (
+		wg  sync.WaitGroup
+		mux sync.Mutex
+		con = make([]net.Conn, 0)
+	)
+
+	var serve = func(c net.Conn) {
+		defer func() {
+			_ = c.Close()
+		}()
+
+		var r = bufio.NewReader(c)
+
+		for {
+			l, e := r.ReadBytes('\n')
+			if e != nil {
+				return
+			}
+
+			var q request
+
+			if e = json.Unmarshal(l, &q); e != nil {
+				return
+			}
+
+			p, _ := json.Marshal(response{Sum: q.A + q.B})
+			p = append(p, '\n')
+
+			switch q.Op {
+			case opSplit:
+				for _, b := range p {
+					if _, e = c.Write([]byte{b}); e != nil {
+						return
+					}
+					time.Sleep(time.Millisecond)
+				}
+				continue
+			case opBad:
+				p = []byte("not a json message\n")
+			case opShort:
+				_, _ = c.Write(p[:len(p)/2])
+				return
+			case opClose:
+				return
+			case opHang:
+				_, _ = io.Copy(io.Discard, r)
+				return
+			}
+
+			if _, e = c.Write(p); e != nil {
+				return
+			}
+		}
+	}
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		for {
+			c, e := lis.Accept()
+			if e != nil {
+				return
+			}
+
+			mux.Lock()
+			con = append(con, c)
+			mux.Unlock()
+
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+				serve(c)
+			}()
+		}
+	}()
+
+	return lis.Addr().String(), func() {
+		_ = lis.Close()
+
+		mux.Lock()
+		for _, c := range con {
+			_ = c.Close()
+		}
+		mux.Unlock()
+
+		wg.Wait()
+	}
+}
+
+// rawCodec marshal the operation of a request as is.
+type rawCodec struct{}
+
+func (c *rawCodec) Name() string {
+	return "raw"
+}
+
+func (c *rawCodec) Marshal(v any) ([]byte, error) {
+	if r, k := v.(request); k {
+		return []byte(r.Op), nil
+	}
+
+	return nil, errors.New("unsupported type")
+}
+
+func (c *rawCodec) Unmarshal(p []byte, v any) error {
+	return errors.New("unsupported type")
+}
+
+func newTyped(adr string, cdc sckcdc.Codec) scktpc.Client[request, response] {
+	cli, err := sckclt.New(libptc.NetworkTCP, adr)
+	Expect(err).ToNot(HaveOccurred())
+
+	t, err := scktpc.New[request, response](cli, cdc, 0)
+	Expect(err).ToNot(HaveOccurred())
+
+	return t
+}
+
+func connTyped(adr string) scktpc.Client[request, response] {
+	var t = newTyped(adr, nil)
+	Expect(t.Connect(ctx)).ToNot(HaveOccurred())
+	return t
+}
+
+var _ = Describe("socket/client/typed", func() {
+	var (
+		adr string
+		stp func()
+	)
+
+	BeforeEach(func() {
+		adr, stp = typedServer()
+	})
+
+	AfterEach(func() {
+		stp()
+	})
+
+	Context("exchanging messages with the server", func() {
+		It("must round trip several calls on the same connection", func() {
+			var cli = connTyped(adr)
+
+			defer func() {
+				_ = cli.Close()
+			}()
+
+			for i := 0; i < 10; i++ {
+				r, e := cli.Call(ctx, request{Op: opAdd, A: i, B: 100})
+				Expect(e).ToNot(HaveOccurred())
+				Expect(r.Sum).To(Equal(i + 100))
+			}
+		})
+
+		It("must receive the responses of the pipelined requests in order", func() {
+			var cli = connTyped(adr)
+
+			defer func() {
+				_ = cli.Close()
+			}()
+
+			for i := 0; i < 5; i++ {
+				Expect(cli.Send(ctx, request{Op: opAdd, A: i})).ToNot(HaveOccurred())
+			}
+
+			for i := 0; i < 5; i++ {
+				r, e := cli.Receive(ctx)
+				Expect(e).ToNot(HaveOccurred())
+				Expect(r.Sum).To(Equal(i))
+			}
+		})
+
+		It("must serialize the concurrent calls", func() {
+			var (
+				cli = connTyped(adr)
+				wg  sync.WaitGroup
+				res = make([]response, 20)
+				ers = make([]error, 20)
+			)
+
+			defer func() {
+				_ = cli.Close()
+			}()
+
+			for i := range res {
+				wg.Add(1)
+
+				go func(i int) {
+					defer wg.Done()
+					res[i], ers[i] = cli.Call(ctx, request{Op: opAdd, A: i, B: i})
+				}(i)
+			}
+
+			wg.Wait()
+
+			for i := range res {
+				Expect(ers[i]).ToNot(HaveOccurred())
+				Expect(res[i].Sum).To(Equal(2 * i))
+			}
+		})
+
+		It("must rebuild a response received by short reads", func() {
+			var cli = connTyped(adr)
+
+			defer func() {
+				_ = cli.Close()
+			}()
+
+			r, e := cli.Call(ctx, request{Op: opSplit, A: 12345, B: 54321})
+			Expect(e).ToNot(HaveOccurred())
+			Expect(r.Sum).To(Equal(66666))
+
+			r, e = cli.Call(ctx, request{Op: opAdd, A: 1, B: 2})
+			Expect(e).ToNot(HaveOccurred())
+			Expect(r.Sum).To(Equal(3))
+		})
+
+		It("must connect, call and close with Once", func() {
+			var cli = newTyped(adr, nil)
+
+			r, e := cli.Once(ctx, request{Op: opAdd, A: 40, B: 2})
+			Expect(e).ToNot(HaveOccurred())
+			Expect(r.Sum).To(Equal(42))
+			Expect(cli.IsConnected()).To(BeFalse())
+		})
+	})
+
+	Context("receiving invalid responses", func() {
+		It("must return the decode error and keep the next frames", func() {
+			var cli = connTyped(adr)
+
+			defer func() {
+				_ = cli.Close()
+			}()
+
+			_, e := cli.Call(ctx, request{Op: opBad})
+			Expect(e).To(HaveOccurred())
+			Expect(errors.Is(e, io.ErrUnexpectedEOF)).To(BeFalse())
+
+			r, e := cli.Call(ctx, request{Op: opAdd, A: 1, B: 1})
+			Expect(e).ToNot(HaveOccurred())
+			Expect(r.Sum).To(Equal(2))
+		})
+
+		It("must return an unexpected EOF on a partial frame", func() {
+			var cli = connTyped(adr)
+
+			defer func() {
+				_ = cli.Close()
+			}()
+
+			_, e := cli.Call(ctx, request{Op: opShort, A: 1000, B: 1000})
+			Expect(e).To(MatchError(io.ErrUnexpectedEOF))
+		})
+
+		It("must return an error on a connection closed without response", func() {
+			var cli = connTyped(adr)
+
+			defer func() {
+				_ = cli.Close()
+			}()
+
+			_, e := cli.Call(ctx, request{Op: opClose})
+			Expect(e).To(HaveOccurred())
+		})
+
+		It("must close the connection when the context is done before the response", func() {
+			var cli = connTyped(adr)
+
+			defer func() {
+				_ = cli.Close()
+			}()
+
+			x, c := context.WithTimeout(ctx, 100*time.Millisecond)
+			defer c()
+
+			_, e := cli.Call(x, request{Op: opHang})
+			Expect(e).To(MatchError(context.DeadlineExceeded))
+			Expect(cli.IsConnected()).To(BeFalse())
+		})
+	})
+
+	Context("using the client without valid state", func() {
+		It("must reject a nil socket client", func() {
+			_, e := scktpc.New[request, response](nil, nil, 0)
+			Expect(e).To(MatchError(scktpc.ErrInstance))
+		})
+
+		It("must reject a receive before the connection", func() {
+			_, e := newTyped(adr, nil).Receive(ctx)
+			Expect(e).To(MatchError(scktpc.ErrConnection))
+		})
+
+		It("must reject a request marshalled with the frame delimiter", func() {
+			var cli = newTyped(adr, &rawCodec{})
+			Expect(cli.Send(ctx, request{Op: "multi\nline"})).To(MatchError(scktpc.ErrFrame))
+		})
+	})
+})
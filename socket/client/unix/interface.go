@@ -37,8 +37,11 @@ import (
 
 type ClientUnix interface {
 	libsck.Client
+	libsck.HalfCloser
 }
 
+// New returns a client for the given unix socket file path.
+// A path starting with '@' is an address of the Linux abstract namespace, without any file system node.
 func New(unixfile string) ClientUnix {
 	var a = new(atomic.Value)
 
@@ -184,6 +184,36 @@ func (o *cli) Close() error {
 	}
 }
 
+func (o *cli) CloseRead() error {
+	if o == nil {
+		return ErrInstance
+	} else if i := o.c.Load(); i == nil {
+		return ErrConnection
+	} else if c, k := i.(net.Conn); !k {
+		return ErrConnection
+	} else if r, l := c.(interface{ CloseRead() error }); !l {
+		return ErrHalfClose
+	} else {
+		o.fctInfo(c.LocalAddr(), c.RemoteAddr(), libsck.ConnectionCloseRead)
+		return r.CloseRead()
+	}
+}
+
+func (o *cli) CloseWrite() error {
+	if o == nil {
+		return ErrInstance
+	} else if i := o.c.Load(); i == nil {
+		return ErrConnection
+	} else if c, k := i.(net.Conn); !k {
+		return ErrConnection
+	} else if w, l := c.(interface{ CloseWrite() error }); !l {
+		return ErrHalfClose
+	} else {
+		o.fctInfo(c.LocalAddr(), c.RemoteAddr(), libsck.ConnectionCloseWrite)
+		return w.CloseWrite()
+	}
+}
+
 func (o *cli) Once(ctx context.Context, request io.Reader, fct libsck.Response) error {
 	if o == nil {
 		return ErrInstance
@@ -39,6 +39,8 @@ type ClientUnix interface {
 	libsck.Client
 }
 
+// New returns a client for the given unix socket file path.
+// A path starting with '@' is an address of the Linux abstract namespace, without any file system node.
 func New(unixfile string) ClientUnix {
 	var a = new(atomic.Value)
 
@@ -0,0 +1,68 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package codec
+
+import (
+	libenc "github.com/nabbar/golib/encoding"
+	enchex "github.com/nabbar/golib/encoding/hexa"
+)
+
+// Codec marshal and unmarshal the messages exchanged over a socket.
+// As messages are framed with a delimiter, the marshalled message must not contain this delimiter:
+// binary codecs must be wrapped with a text coder (see WithCoder).
+type Codec interface {
+	// Name return the name of the codec.
+	Name() string
+
+	// Marshal return the encoding of the given value.
+	Marshal(v any) ([]byte, error)
+
+	// Unmarshal decode the given message into the given pointer.
+	Unmarshal(p []byte, v any) error
+}
+
+// JSON return a codec using the json encoding. Marshalled messages never contain a new line.
+func JSON() Codec {
+	return &cjs{}
+}
+
+// CBOR return a codec using the cbor encoding, hexadecimal encoded to be safe with any delimiter.
+func CBOR() Codec {
+	return WithCoder(&cbr{}, enchex.New())
+}
+
+// WithCoder return a codec encoding the messages marshalled by the given codec with the given coder,
+// like the hexa coder to make a binary codec safe with a delimiter or the aes coder to encrypt messages.
+func WithCoder(c Codec, e libenc.Coder) Codec {
+	if c == nil || e == nil {
+		return c
+	}
+
+	return &cwc{
+		c: c,
+		e: e,
+	}
+}
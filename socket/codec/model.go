@@ -0,0 +1,94 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package codec
+
+import (
+	"encoding/json"
+
+	libcbr "github.com/fxamacker/cbor/v2"
+	libenc "github.com/nabbar/golib/encoding"
+)
+
+type cjs struct{}
+
+func (o *cjs) Name() string {
+	return "json"
+}
+
+func (o *cjs) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (o *cjs) Unmarshal(p []byte, v any) error {
+	return json.Unmarshal(p, v)
+}
+
+type cbr struct{}
+
+func (o *cbr) Name() string {
+	return "cbor"
+}
+
+func (o *cbr) Marshal(v any) ([]byte, error) {
+	return libcbr.Marshal(v)
+}
+
+func (o *cbr) Unmarshal(p []byte, v any) error {
+	return libcbr.Unmarshal(p, v)
+}
+
+type cwc struct {
+	c Codec        // codec
+	e libenc.Coder // coder
+}
+
+func (o *cwc) Name() string {
+	if o == nil || o.c == nil {
+		return ""
+	}
+
+	return o.c.Name()
+}
+
+func (o *cwc) Marshal(v any) ([]byte, error) {
+	if o == nil || o.c == nil || o.e == nil {
+		return nil, ErrInstance
+	} else if p, e := o.c.Marshal(v); e != nil {
+		return nil, e
+	} else {
+		return o.e.Encode(p), nil
+	}
+}
+
+func (o *cwc) Unmarshal(p []byte, v any) error {
+	if o == nil || o.c == nil || o.e == nil {
+		return ErrInstance
+	} else if b, e := o.e.Decode(p); e != nil {
+		return e
+	} else {
+		return o.c.Unmarshal(b, v)
+	}
+}
@@ -44,11 +44,39 @@ type ServerConfig struct {
 	PermFile os.FileMode
 	// permission of group for socket file
 	GroupPerm int32
+	// drain of the open connections on shutdown, ignored by datagram servers
+	Drain libsck.Drain
+	// dispatch of the received datagrams to concurrent handlers, ignored by stream servers
+	Dispatch libsck.Dispatch
+	// number of listeners sharing the address with SO_REUSEPORT, ignored by unix servers
+	Shards int
+	// factory of the listeners of stream servers, net package if nil
+	Listener libsck.ListenerFactory
+	// factory of the sockets of datagram servers, net package if nil
+	PacketConn libsck.PacketConnFactory
 }
 
 // New returns a new server with the given handler and based on the ServerConfig
 // handler libsck.Handler
 // (libsck.Server, error)
 func (o ServerConfig) New(updateCon libsck.UpdateConn, handler libsck.Handler) (libsck.Server, error) {
-	return scksrv.New(updateCon, handler, o.Network, o.Address, o.PermFile, o.GroupPerm)
+	s, e := scksrv.New(updateCon, handler, o.Network, o.Address, o.PermFile, o.GroupPerm)
+
+	if e != nil {
+		return nil, e
+	}
+
+	s.SetDrain(o.Drain)
+	s.SetDispatch(o.Dispatch)
+	s.SetShards(o.Shards)
+
+	if o.Listener != nil {
+		s.RegisterListenerFactory(o.Listener)
+	}
+
+	if o.PacketConn != nil {
+		s.RegisterPacketConnFactory(o.PacketConn)
+	}
+
+	return s, nil
 }
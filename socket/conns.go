@@ -0,0 +1,129 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package socket
+
+import (
+	"sync/atomic"
+)
+
+// ConnectionsByState is the number of open connections by activity, derived from their ConnState events.
+// During a shutdown, it shows what the draining is waiting for: a slow peer (Reading), a slow handler
+// (Handling), a slow write (Writing) or a connection still open after its handler returned (Idle).
+type ConnectionsByState struct {
+	// Reading is the number of connections waiting for incoming data.
+	Reading int64 `json:"reading"`
+	// Handling is the number of connections with a running handler, not reading nor writing.
+	Handling int64 `json:"handling"`
+	// Writing is the number of connections writing outgoing data.
+	Writing int64 `json:"writing"`
+	// Idle is the number of connections without running handler (not started or returned).
+	Idle int64 `json:"idle"`
+}
+
+// Total returns the number of connections of all states.
+func (c ConnectionsByState) Total() int64 {
+	return c.Reading + c.Handling + c.Writing + c.Idle
+}
+
+const (
+	actIdle uint32 = iota
+	actReading
+	actHandling
+	actWriting
+	actClosed
+)
+
+// ConnStateCounter counts the open connections of a server by activity.
+// The zero value is ready to use.
+type ConnStateCounter struct {
+	c [actClosed]atomic.Int64
+}
+
+// Track registers a new open connection, idle until its first state, and returns its tracker.
+func (o *ConnStateCounter) Track() *ConnStateTrack {
+	o.c[actIdle].Add(1)
+
+	return &ConnStateTrack{
+		c: o,
+	}
+}
+
+// Get returns the number of open connections by activity.
+func (o *ConnStateCounter) Get() ConnectionsByState {
+	if o == nil {
+		return ConnectionsByState{}
+	}
+
+	return ConnectionsByState{
+		Reading:  o.c[actReading].Load(),
+		Handling: o.c[actHandling].Load(),
+		Writing:  o.c[actWriting].Load(),
+		Idle:     o.c[actIdle].Load(),
+	}
+}
+
+// ConnStateTrack is the activity of one connection counted into a ConnStateCounter.
+type ConnStateTrack struct {
+	c *ConnStateCounter
+	s atomic.Uint32
+}
+
+// Set moves the connection to the activity of the given state: ConnectionRead for Reading,
+// ConnectionHandler for Handling, ConnectionWrite for Writing and ConnectionNew for Idle.
+// The other states do not change the activity. ConnectionClose is done by Close.
+func (o *ConnStateTrack) Set(state ConnState) {
+	switch state {
+	case ConnectionNew:
+		o.move(actIdle)
+	case ConnectionRead:
+		o.move(actReading)
+	case ConnectionHandler:
+		o.move(actHandling)
+	case ConnectionWrite:
+		o.move(actWriting)
+	}
+}
+
+// Close removes the connection from the counter. Calling Close more than once does nothing.
+func (o *ConnStateTrack) Close() {
+	if p := o.s.Swap(actClosed); p != actClosed {
+		o.c.c[p].Add(-1)
+	}
+}
+
+func (o *ConnStateTrack) move(act uint32) {
+	for {
+		p := o.s.Load()
+
+		if p == act || p == actClosed {
+			return
+		} else if o.s.CompareAndSwap(p, act) {
+			o.c.c[p].Add(-1)
+			o.c.c[act].Add(1)
+			return
+		}
+	}
+}
@@ -0,0 +1,178 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package socket
+
+import (
+	"net"
+	"sync"
+)
+
+// ContextTagALPN is the tag of the Context storing the ALPN protocol negotiated by the TLS handshake,
+// set by the tcp server when handlers are registered by protocol.
+const ContextTagALPN = "alpn"
+
+// Context is the metadata store of a connection, shared by its Reader and Writer for the whole life
+// of the connection: authenticated identity, protocol state, ... It's safe for concurrent use.
+// The tags are string metadata surfaced to the FuncInfoContext callbacks and the published events.
+type Context interface {
+	// ID return the id of the connection, the same as the AcceptInfo.ID.
+	// Datagram servers (udp, unixgram) have no accepted connection and return 0.
+	ID() uint64
+
+	// SetValue store the given value with the given key.
+	SetValue(key string, val any)
+
+	// GetValue return the value stored with the given key and true, or nil and false. See also GetValue.
+	GetValue(key string) (any, bool)
+
+	// DelValue remove the value stored with the given key.
+	DelValue(key string)
+
+	// SetTag store the given tag. An empty value remove the tag.
+	SetTag(key, val string)
+
+	// Tag return the tag with the given key and true, or an empty string and false.
+	Tag(key string) (string, bool)
+
+	// Tags return a copy of all the tags, or nil if no tag is defined.
+	Tags() map[string]string
+}
+
+// FuncInfoContext is used like FuncInfo to process state connection information,
+// with the Context of the connection to get its id and tags.
+type FuncInfoContext func(ctx Context, local, remote net.Addr, state ConnState)
+
+// NewContext return a new empty Context for the connection with the given id.
+func NewContext(id uint64) Context {
+	return &cnx{
+		i: id,
+		m: sync.RWMutex{},
+	}
+}
+
+// GetValue return the value stored into the given Context with the given key, if it's of type T.
+func GetValue[T any](ctx Context, key string) (T, bool) {
+	var res T
+
+	if ctx == nil {
+		return res, false
+	} else if i, k := ctx.GetValue(key); !k {
+		return res, false
+	} else if v, l := i.(T); !l {
+		return res, false
+	} else {
+		return v, true
+	}
+}
+
+// SetContext share the given Context between the Reader and the Writer of a connection.
+// It's used by servers before running the handler.
+func SetContext(ctx Context, r Reader, w Writer) {
+	if c, k := r.(interface{ setContext(Context) }); k {
+		c.setContext(ctx)
+	}
+
+	if c, k := w.(interface{ setContext(Context) }); k {
+		c.setContext(ctx)
+	}
+}
+
+type cnx struct {
+	i uint64
+	m sync.RWMutex
+	v map[string]any
+	t map[string]string
+}
+
+func (o *cnx) ID() uint64 {
+	return o.i
+}
+
+func (o *cnx) SetValue(key string, val any) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if o.v == nil {
+		o.v = make(map[string]any)
+	}
+
+	o.v[key] = val
+}
+
+func (o *cnx) GetValue(key string) (any, bool) {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	v, k := o.v[key]
+	return v, k
+}
+
+func (o *cnx) DelValue(key string) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	delete(o.v, key)
+}
+
+func (o *cnx) SetTag(key, val string) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if len(val) < 1 {
+		delete(o.t, key)
+		return
+	} else if o.t == nil {
+		o.t = make(map[string]string)
+	}
+
+	o.t[key] = val
+}
+
+func (o *cnx) Tag(key string) (string, bool) {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	v, k := o.t[key]
+	return v, k
+}
+
+func (o *cnx) Tags() map[string]string {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	if len(o.t) < 1 {
+		return nil
+	}
+
+	var res = make(map[string]string, len(o.t))
+
+	for k, v := range o.t {
+		res[k] = v
+	}
+
+	return res
+}
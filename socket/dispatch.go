@@ -0,0 +1,187 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package socket
+
+import (
+	"context"
+	"hash/fnv"
+	"net"
+	"sync"
+)
+
+// DefaultDatagramSize is the size of the buffer used to receive a datagram when dispatching them to workers.
+const DefaultDatagramSize = 64 * 1024
+
+// Dispatch define how a datagram server (udp, unixgram) dispatches the received datagrams to its handler.
+// Stream servers have a handler by connection and ignore it.
+type Dispatch struct {
+	// Workers is the number of handlers running concurrently, each one receiving a datagram at a time
+	// with the address of its sender. Zero or one keeps a single handler reading the socket.
+	Workers int
+
+	// PerSender keeps the datagrams of a same sender in order, by dispatching them always to the same worker
+	// chosen by hashing the sender address. Otherwise, a datagram goes to the first worker available.
+	PerSender bool
+
+	// Queue is the number of datagrams waiting for a worker before the reading of the socket blocks.
+	// Zero uses the number of workers.
+	Queue int
+
+	// Size is the max size of a received datagram, the rest being truncated. Zero uses DefaultDatagramSize.
+	Size int
+
+	// BatchSize is the max number of datagrams received by a single system call (recvmmsg on linux),
+	// to lower the cost of each datagram on high rate of small datagrams. Zero or one receives the datagrams
+	// one by one. Only the udp server receives by batch, the other ones ignore it.
+	BatchSize int
+
+	// BatchHandler, if set with a BatchSize, receives each batch of datagrams in place of the handler and its
+	// workers, and returns the datagrams to send, written by a single system call (sendmmsg on linux).
+	// Only the udp server calls it.
+	BatchHandler BatchHandlerFunc
+
+	// GSO, with a BatchHandler, sends the consecutive datagrams returned to a same address with a same size
+	// (the last one can be shorter) as a single message segmented by the kernel or the network card
+	// (UDP generic segmentation offload). It is used only on linux 4.18 or later and by the udp server,
+	// and is disabled for the socket on the first segmented send refused by the kernel.
+	GSO bool
+}
+
+// BatchHandlerFunc receives a batch of datagrams and returns the datagrams to send, each one to its address.
+// The datagrams received are not reused after the call and can be kept.
+type BatchHandlerFunc func(in []Datagram) (out []Datagram)
+
+// Enabled returns true if the datagrams must be dispatched to a pool of workers or received by batch.
+func (d Dispatch) Enabled() bool {
+	return d.Workers > 1 || d.BatchSize > 1
+}
+
+// BufferSize returns the size of the buffer used to receive a datagram.
+func (d Dispatch) BufferSize() int {
+	if d.Size > 0 {
+		return d.Size
+	}
+
+	return DefaultDatagramSize
+}
+
+// Datagram is a received datagram with the address of its sender.
+type Datagram struct {
+	Data []byte
+	Addr net.Addr
+}
+
+// FuncDatagram is called by a worker of a DispatchPool for each datagram.
+type FuncDatagram func(d Datagram)
+
+// DispatchPool runs the workers of a Dispatch. Push and Close must be called from the same goroutine.
+type DispatchPool struct {
+	w sync.WaitGroup
+	p bool
+	c []chan Datagram
+}
+
+// NewDispatchPool starts the workers of the given Dispatch, each one calling the given function for each datagram.
+func NewDispatchPool(d Dispatch, fct FuncDatagram) *DispatchPool {
+	var (
+		nbr = d.Workers
+		que = d.Queue
+		res = &DispatchPool{
+			p: d.PerSender,
+		}
+	)
+
+	if nbr < 1 {
+		nbr = 1
+	}
+
+	if que < 1 {
+		que = nbr
+	}
+
+	if res.p {
+		res.c = make([]chan Datagram, nbr)
+
+		for i := range res.c {
+			res.c[i] = make(chan Datagram, que)
+		}
+	} else {
+		res.c = []chan Datagram{make(chan Datagram, que)}
+	}
+
+	for i := 0; i < nbr; i++ {
+		var c = res.c[0]
+
+		if res.p {
+			c = res.c[i]
+		}
+
+		res.w.Add(1)
+
+		go func() {
+			defer res.w.Done()
+
+			for g := range c {
+				fct(g)
+			}
+		}()
+	}
+
+	return res
+}
+
+// Push queues the given datagram for a worker. It blocks until a worker can take it
+// and returns false if the context is done before.
+func (o *DispatchPool) Push(ctx context.Context, d Datagram) bool {
+	var c = o.c[0]
+
+	if o.p && len(o.c) > 1 {
+		var h = fnv.New32a()
+
+		if d.Addr != nil {
+			_, _ = h.Write([]byte(d.Addr.String()))
+		}
+
+		c = o.c[h.Sum32()%uint32(len(o.c))]
+	}
+
+	select {
+	case c <- d:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Close stops the workers once the queued datagrams are handled and waits for them.
+func (o *DispatchPool) Close() {
+	for _, c := range o.c {
+		close(c)
+	}
+
+	o.w.Wait()
+}
@@ -0,0 +1,140 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package socket
+
+import (
+	"net"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Drain define how a stream server drains its open connections on Shutdown.
+// Datagram servers (udp, unixgram) have no accepted connection and ignore it.
+type Drain struct {
+	// Deadline is the time given to each open connection to end by itself once the shutdown started,
+	// before its context is cancelled. Zero cancels the connections immediately.
+	Deadline time.Duration
+
+	// ForceCloseAfter is the max duration of the drain: once reached, the connections still open are closed
+	// without waiting for their handler. Zero waits for the connections until the shutdown timeout.
+	ForceCloseAfter time.Duration
+
+	// OnTimeout is called with the connections still open when they are force closed.
+	OnTimeout FuncDrainTimeout
+}
+
+// FuncDrainTimeout is called with the connections still open at the end of the drain, before closing them.
+// The TLS state of the AcceptInfo is not filled.
+type FuncDrainTimeout func(open []AcceptInfo)
+
+// Timeout return the max duration of the drain, or the given default duration if it's longer.
+func (d Drain) Timeout(def time.Duration) time.Duration {
+	if d.ForceCloseAfter > 0 {
+		return d.ForceCloseAfter + time.Second
+	} else if d.Deadline > def {
+		return d.Deadline + time.Second
+	}
+
+	return def
+}
+
+// ConnRegistry tracks the open connections of a stream server, to list and close them at the end of a drain.
+// The zero value is ready to use.
+type ConnRegistry struct {
+	m sync.Mutex
+	c map[uint64]regConn
+}
+
+type regConn struct {
+	i AcceptInfo
+	c net.Conn
+}
+
+// Add registers the given connection accepted at the given time with the given id.
+func (o *ConnRegistry) Add(id uint64, acc time.Time, con net.Conn) {
+	var inf = AcceptInfo{
+		ID:   id,
+		Time: acc,
+	}
+
+	if a := con.LocalAddr(); a != nil {
+		inf.Network = a.Network()
+		inf.Local = a.String()
+	}
+
+	if a := con.RemoteAddr(); a != nil {
+		inf.Remote = a.String()
+	}
+
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if o.c == nil {
+		o.c = make(map[uint64]regConn)
+	}
+
+	o.c[id] = regConn{i: inf, c: con}
+}
+
+// Del removes the connection with the given id.
+func (o *ConnRegistry) Del(id uint64) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	delete(o.c, id)
+}
+
+// List returns the open connections, by order of id.
+func (o *ConnRegistry) List() []AcceptInfo {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	var res = make([]AcceptInfo, 0, len(o.c))
+
+	for _, c := range o.c {
+		res = append(res, c.i)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].ID < res[j].ID
+	})
+
+	return res
+}
+
+// CloseAll closes and removes all the open connections.
+func (o *ConnRegistry) CloseAll() {
+	o.m.Lock()
+	var lst = o.c
+	o.c = nil
+	o.m.Unlock()
+
+	for _, c := range lst {
+		_ = c.c.Close()
+	}
+}
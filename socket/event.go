@@ -0,0 +1,152 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package socket
+
+import (
+	"net"
+
+	libevt "github.com/nabbar/golib/events"
+)
+
+const (
+	// EventTopicConnection is the default topic used to publish EventConnection.
+	EventTopicConnection = "socket.connection"
+	// EventTopicServer is the default topic used to publish EventServer.
+	EventTopicServer = "socket.server"
+	// EventTopicError is the default topic used to publish EventError.
+	EventTopicError = "socket.error"
+)
+
+// EventConnection is published for each state change of a connection.
+type EventConnection struct {
+	Local  net.Addr
+	Remote net.Addr
+	State  ConnState
+	// ID is the id of the connection, only set by PublishFuncInfoContext.
+	ID uint64
+	// Tags are the tags of the connection Context, only set by PublishFuncInfoContext.
+	Tags map[string]string
+}
+
+// EventServer is published for each information message of the listening server.
+type EventServer struct {
+	Message string
+}
+
+// EventError is published for each error raised during the running process.
+type EventError struct {
+	Errors []error
+}
+
+// PublishFuncInfo return a FuncInfo publishing an EventConnection on the given topic of the bus.
+// As the FuncInfo is called synchronously by the server, the event is published without blocking: it is dropped
+// for a subscriber with a full buffer, so subscribers should use a buffer to not lose events.
+// If the topic is empty, EventTopicConnection is used.
+func PublishFuncInfo(bus libevt.Bus, topic string) FuncInfo {
+	if len(topic) < 1 {
+		topic = EventTopicConnection
+	}
+
+	return func(local, remote net.Addr, state ConnState) {
+		if bus != nil {
+			_ = bus.TryPublish(topic, EventConnection{
+				Local:  local,
+				Remote: remote,
+				State:  state,
+			})
+		}
+	}
+}
+
+// PublishFuncInfoContext return a FuncInfoContext publishing an EventConnection with the id and the tags
+// of the connection on the given topic of the bus, without blocking like PublishFuncInfo.
+// If the topic is empty, EventTopicConnection is used.
+func PublishFuncInfoContext(bus libevt.Bus, topic string) FuncInfoContext {
+	if len(topic) < 1 {
+		topic = EventTopicConnection
+	}
+
+	return func(ctx Context, local, remote net.Addr, state ConnState) {
+		if bus == nil {
+			return
+		}
+
+		var evt = EventConnection{
+			Local:  local,
+			Remote: remote,
+			State:  state,
+		}
+
+		if ctx != nil {
+			evt.ID = ctx.ID()
+			evt.Tags = ctx.Tags()
+		}
+
+		_ = bus.TryPublish(topic, evt)
+	}
+}
+
+// PublishFuncInfoServer return a FuncInfoSrv publishing an EventServer on the given topic of the bus.
+// If the topic is empty, EventTopicServer is used.
+func PublishFuncInfoServer(bus libevt.Bus, topic string) FuncInfoSrv {
+	if len(topic) < 1 {
+		topic = EventTopicServer
+	}
+
+	return func(msg string) {
+		if bus != nil {
+			_ = bus.TryPublish(topic, EventServer{
+				Message: msg,
+			})
+		}
+	}
+}
+
+// PublishFuncError return a FuncError publishing an EventError on the given topic of the bus.
+// Nil errors are filtered and no event is published if no error remains.
+// If the topic is empty, EventTopicError is used.
+func PublishFuncError(bus libevt.Bus, topic string) FuncError {
+	if len(topic) < 1 {
+		topic = EventTopicError
+	}
+
+	return func(e ...error) {
+		var l = make([]error, 0, len(e))
+
+		for _, i := range e {
+			if i != nil {
+				l = append(l, i)
+			}
+		}
+
+		if bus != nil && len(l) > 0 {
+			_ = bus.TryPublish(topic, EventError{
+				Errors: l,
+			})
+		}
+	}
+}
@@ -0,0 +1,49 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package socket
+
+import (
+	"context"
+	"net"
+)
+
+// ListenerFactory opens the listener of a stream server (tcp, unix) in place of the net package, to use a custom
+// listener (sockmap, AF_XDP, ...) or a fake one for the tests. It is called for each shard with the network code
+// and the address of the server. The unix server applies no permission on the socket file of a custom listener.
+type ListenerFactory func(ctx context.Context, network, address string) (net.Listener, error)
+
+// PacketConn is the socket of a datagram server (udp, unixgram), as *net.UDPConn and *net.UnixConn.
+type PacketConn interface {
+	net.PacketConn
+	net.Conn
+}
+
+// PacketConnFactory opens the socket of a datagram server (udp, unixgram) in place of the net package, to use a custom
+// socket or a fake one for the tests. It is called for each shard with the network code and the address of the server.
+// The udp server only receives by batch with a *net.UDPConn, the unixgram server applies no permission on the socket
+// file of a custom socket.
+type PacketConnFactory func(ctx context.Context, network, address string) (PacketConn, error)
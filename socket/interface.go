@@ -111,10 +111,60 @@ type Server interface {
 	// f FuncInfo - the FuncInfo to be registered.
 	RegisterFuncInfo(f FuncInfo)
 
+	// RegisterFuncInfoContext registers the given FuncInfoContext called like the FuncInfo for the states of a connection,
+	// with the Context of the connection to get its id and the tags set by the handler.
+	// f FuncInfoContext
+	RegisterFuncInfoContext(f FuncInfoContext)
+
 	// RegisterFuncInfoServer registers the given FuncInfoSrv used to process information about the listening server.
 	// f FuncInfoSrv parameter.
 	RegisterFuncInfoServer(f FuncInfoSrv)
 
+	// RegisterFuncAcceptInfo registers the given FuncAcceptInfo called exactly once for each accepted connection,
+	// with a structured record designed for audit logging. For a TLS connection, the handshake is done before
+	// calling it to give the TLS state. Datagram servers (udp, unixgram) have no accepted connection and never call it.
+	// f FuncAcceptInfo
+	RegisterFuncAcceptInfo(f FuncAcceptInfo)
+
+	// SetDrain defines how the open connections are drained by Shutdown: the deadline given to each connection
+	// to end by itself, the max duration before closing the connections still open and the callback listing them.
+	// Datagram servers (udp, unixgram) have no accepted connection and ignore it.
+	// d Drain
+	SetDrain(d Drain)
+
+	// SetDispatch defines how a datagram server dispatches the received datagrams: the number of handlers running
+	// concurrently, each one receiving a datagram with the address of its sender, and the ordering by sender.
+	// Stream servers (tcp, unix) run a handler by connection and ignore it.
+	// d Dispatch
+	SetDispatch(d Dispatch)
+
+	// SetShards defines the number of listeners opened on the same address with the SO_REUSEPORT option (linux only),
+	// each one running its own accept loop (tcp) or handler (udp), the kernel load-balancing the connections
+	// or datagrams between them. Zero or one opens a single listener. Unix servers (unix, unixgram) ignore it.
+	// n int
+	SetShards(n int)
+
+	// RegisterListenerFactory registers the given ListenerFactory used by stream servers (tcp, unix) to open
+	// their listeners in place of the net package. Datagram servers (udp, unixgram) never call it.
+	// f ListenerFactory
+	RegisterListenerFactory(f ListenerFactory)
+
+	// RegisterPacketConnFactory registers the given PacketConnFactory used by datagram servers (udp, unixgram)
+	// to open their sockets in place of the net package. Stream servers (tcp, unix) never call it.
+	// f PacketConnFactory
+	RegisterPacketConnFactory(f PacketConnFactory)
+
+	// RegisterAcceptFilter registers the given AcceptFilter called right after the accept of each connection,
+	// before the TLS handshake and the start of the handler. A rejected connection is closed at once.
+	// Datagram servers (udp, unixgram) have no accepted connection and never call it.
+	// f AcceptFilter
+	RegisterAcceptFilter(f AcceptFilter)
+
+	// Use appends the given middlewares around the handler of the server.
+	// The first middleware is the outermost. Middlewares are applied to each new connection.
+	// mw ...Middleware
+	Use(mw ...Middleware)
+
 	// SetTLS defines if the server should use TLS and if so, the configuration associated.
 	// Parameters:
 	//   - enable bool defines if the server should use TLS
@@ -139,6 +189,11 @@ type Server interface {
 	// Returns a boolean.
 	IsGone() bool
 
+	// IsClosed returns a boolean value indicating the server has been closed by Close or Shutdown.
+	// A closed server cannot listen again and all its methods return the ErrServerClosed error.
+	// Returns a boolean.
+	IsClosed() bool
+
 	// Done returns a read-only channel who's value is set when the shutdown process is reached.
 	// Returns <-chan struct{}.
 	Done() <-chan struct{}
@@ -146,6 +201,10 @@ type Server interface {
 	// OpenConnections returns the number of open connections.
 	// Returns an int64.
 	OpenConnections() int64
+
+	// ConnectionsByState returns the number of open connections by activity (reading, handling, writing, idle).
+	// Datagram servers (udp, unixgram) report their unique socket while running.
+	ConnectionsByState() ConnectionsByState
 }
 
 type Client interface {
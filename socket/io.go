@@ -51,12 +51,30 @@ type Reader interface {
 	io.ReadCloser
 	IsConnected() bool
 	Done() <-chan struct{}
+
+	// Context return the metadata store of the connection, shared with its Writer.
+	Context() Context
 }
 
 type Writer interface {
 	io.WriteCloser
 	IsConnected() bool
 	Done() <-chan struct{}
+
+	// Context return the metadata store of the connection, shared with its Reader.
+	Context() Context
+}
+
+// HalfCloser is implemented by the Reader and the Writer of the stream connections (tcp, unix)
+// and by the stream clients, to close only one direction of the connection.
+// CloseWrite send an EOF to the peer while still reading its response, CloseRead stop the reading
+// while still writing. The connection is closed when both directions are closed.
+type HalfCloser interface {
+	// CloseRead shut down the reading side of the connection.
+	CloseRead() error
+
+	// CloseWrite shut down the writing side of the connection.
+	CloseWrite() error
 }
 
 type wrt struct {
@@ -64,6 +82,7 @@ type wrt struct {
 	c FctClose
 	d FctDone
 	i FctCheck
+	x Context
 }
 
 func (o *wrt) Write(p []byte) (n int, err error) {
@@ -111,6 +130,7 @@ type rdr struct {
 	c FctClose
 	d FctDone
 	i FctCheck
+	x Context
 }
 
 func (o *rdr) Read(p []byte) (n int, err error) {
@@ -153,12 +173,37 @@ func (o *rdr) Done() <-chan struct{} {
 	}
 }
 
+func (o *wrt) Context() Context {
+	if o == nil {
+		return nil
+	}
+
+	return o.x
+}
+
+func (o *wrt) setContext(ctx Context) {
+	o.x = ctx
+}
+
+func (o *rdr) Context() Context {
+	if o == nil {
+		return nil
+	}
+
+	return o.x
+}
+
+func (o *rdr) setContext(ctx Context) {
+	o.x = ctx
+}
+
 func NewReader(fctRead FctReader, fctClose FctClose, fctCheck FctCheck, fctDone FctDone) Reader {
 	return &rdr{
 		r: fctRead,
 		c: fctClose,
 		d: fctDone,
 		i: fctCheck,
+		x: NewContext(0),
 	}
 }
 
@@ -168,5 +213,73 @@ func NewWriter(fctWrite FctWriter, fctClose FctClose, fctCheck FctCheck, fctDone
 		c: fctClose,
 		d: fctDone,
 		i: fctCheck,
+		x: NewContext(0),
+	}
+}
+
+type half struct {
+	r FctClose
+	w FctClose
+}
+
+func (o *half) CloseRead() error {
+	if o == nil || o.r == nil {
+		return ErrInvalidInstance
+	}
+
+	return o.r()
+}
+
+func (o *half) CloseWrite() error {
+	if o == nil || o.w == nil {
+		return ErrInvalidInstance
+	}
+
+	return o.w()
+}
+
+type rdrStream struct {
+	rdr
+	half
+}
+
+type wrtStream struct {
+	wrt
+	half
+}
+
+// NewStreamReader return a Reader implementing HalfCloser. The Close function of the reader
+// is the close read function.
+func NewStreamReader(fctRead FctReader, fctCloseRead, fctCloseWrite FctClose, fctCheck FctCheck, fctDone FctDone) Reader {
+	return &rdrStream{
+		rdr: rdr{
+			r: fctRead,
+			c: fctCloseRead,
+			d: fctDone,
+			i: fctCheck,
+			x: NewContext(0),
+		},
+		half: half{
+			r: fctCloseRead,
+			w: fctCloseWrite,
+		},
+	}
+}
+
+// NewStreamWriter return a Writer implementing HalfCloser. The Close function of the writer
+// is the close write function.
+func NewStreamWriter(fctWrite FctWriter, fctCloseRead, fctCloseWrite FctClose, fctCheck FctCheck, fctDone FctDone) Writer {
+	return &wrtStream{
+		wrt: wrt{
+			w: fctWrite,
+			c: fctCloseWrite,
+			d: fctDone,
+			i: fctCheck,
+			x: NewContext(0),
+		},
+		half: half{
+			r: fctCloseRead,
+			w: fctCloseWrite,
+		},
 	}
 }
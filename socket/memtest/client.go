@@ -0,0 +1,227 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package memtest
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+
+	libtls "github.com/nabbar/golib/certificates"
+	libsck "github.com/nabbar/golib/socket"
+)
+
+type cli struct {
+	m sync.Mutex
+	d func(ctx context.Context) (net.Conn, error) // dial
+	c net.Conn                                    // current connection
+	t *tls.Config                                 // tls config
+	e *atomic.Value                               // function error
+	i *atomic.Value                               // function info
+}
+
+func (o *cli) SetTLS(enable bool, config libtls.TLSConfig, serverName string) error {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if !enable {
+		o.t = nil
+		return nil
+	}
+
+	if config == nil {
+		return fmt.Errorf("invalid tls config")
+	} else if t := config.TlsConfig(serverName); t == nil {
+		return fmt.Errorf("invalid tls config")
+	} else {
+		o.t = t
+		return nil
+	}
+}
+
+func (o *cli) RegisterFuncError(f libsck.FuncError) {
+	if o == nil {
+		return
+	}
+
+	o.e.Store(f)
+}
+
+func (o *cli) RegisterFuncInfo(f libsck.FuncInfo) {
+	if o == nil {
+		return
+	}
+
+	o.i.Store(f)
+}
+
+func (o *cli) fctError(e error) {
+	if o == nil || e == nil {
+		return
+	}
+
+	v := o.e.Load()
+	if v != nil {
+		v.(libsck.FuncError)(e)
+	}
+}
+
+func (o *cli) fctInfo(local, remote net.Addr, state libsck.ConnState) {
+	if o == nil {
+		return
+	}
+
+	v := o.i.Load()
+	if v != nil {
+		v.(libsck.FuncInfo)(local, remote, state)
+	}
+}
+
+func (o *cli) getConn() net.Conn {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	return o.c
+}
+
+func (o *cli) Connect(ctx context.Context) error {
+	if o == nil {
+		return ErrInstance
+	}
+
+	o.fctInfo(addr(Network), addr(Network), libsck.ConnectionDial)
+
+	c, e := o.d(ctx)
+
+	if e != nil {
+		o.fctError(e)
+		return e
+	}
+
+	o.m.Lock()
+	t := o.t
+	o.m.Unlock()
+
+	if t != nil {
+		s := tls.Client(c, t)
+
+		if e = s.HandshakeContext(ctx); e != nil {
+			_ = c.Close()
+			o.fctError(e)
+			return e
+		}
+
+		c = s
+	}
+
+	o.fctInfo(c.LocalAddr(), c.RemoteAddr(), libsck.ConnectionNew)
+
+	o.m.Lock()
+	if o.c != nil {
+		_ = o.c.Close()
+	}
+	o.c = c
+	o.m.Unlock()
+
+	return nil
+}
+
+func (o *cli) IsConnected() bool {
+	return o.getConn() != nil
+}
+
+func (o *cli) Read(p []byte) (n int, err error) {
+	if o == nil {
+		return 0, ErrInstance
+	} else if c := o.getConn(); c == nil {
+		return 0, ErrNotConn
+	} else {
+		o.fctInfo(c.LocalAddr(), c.RemoteAddr(), libsck.ConnectionRead)
+		return c.Read(p)
+	}
+}
+
+func (o *cli) Write(p []byte) (n int, err error) {
+	if o == nil {
+		return 0, ErrInstance
+	} else if c := o.getConn(); c == nil {
+		return 0, ErrNotConn
+	} else {
+		o.fctInfo(c.LocalAddr(), c.RemoteAddr(), libsck.ConnectionWrite)
+		return c.Write(p)
+	}
+}
+
+func (o *cli) Close() error {
+	if o == nil {
+		return ErrInstance
+	}
+
+	o.m.Lock()
+	c := o.c
+	o.c = nil
+	o.m.Unlock()
+
+	if c == nil {
+		return ErrNotConn
+	}
+
+	o.fctInfo(c.LocalAddr(), c.RemoteAddr(), libsck.ConnectionClose)
+	return c.Close()
+}
+
+func (o *cli) Once(ctx context.Context, request io.Reader, fct libsck.Response) error {
+	if o == nil {
+		return ErrInstance
+	}
+
+	defer func() {
+		o.fctError(o.Close())
+	}()
+
+	if err := o.Connect(ctx); err != nil {
+		return err
+	}
+
+	if request != nil {
+		if _, err := io.Copy(o, request); err != nil && !errors.Is(err, io.EOF) {
+			o.fctError(err)
+			return err
+		}
+	}
+
+	if fct != nil {
+		fct(o)
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package memtest
+
+import (
+	"context"
+	"net"
+	"time"
+
+	libsck "github.com/nabbar/golib/socket"
+	scksrt "github.com/nabbar/golib/socket/server/tcp"
+)
+
+// Network is the network name of the in-memory addresses.
+const Network = "memtest"
+
+// Options define the latency and the faults injected into the client connections.
+type Options struct {
+	// Latency is added before each read and each write of the client connections.
+	Latency time.Duration
+
+	// DialError is returned by each dial when not nil, without reaching the server.
+	DialError error
+
+	// ReadFault is called before each read of a client connection with the number of bytes already read.
+	// A returned error is given to the reader in place of the data, and the connection is closed.
+	ReadFault func(n int64) error
+
+	// WriteFault is called before each write of a client connection with the number of bytes already written.
+	// A returned error is given to the writer in place of sending the data, and the connection is closed.
+	WriteFault func(n int64) error
+}
+
+// Server is a stream server of the socket package using an in-memory listener in place of a real port
+// or socket file. All the features of the server (handler, middlewares, ConnState callbacks, drain, tls, ...)
+// work as with a tcp server.
+type Server interface {
+	libsck.Server
+
+	// Dial open a new in-memory connection to the server, with the latency and faults of the options.
+	Dial(ctx context.Context) (net.Conn, error)
+
+	// NewClient return a client of the socket package connecting to the server through Dial.
+	NewClient() libsck.Client
+
+	// Addr return the in-memory address of the server.
+	Addr() net.Addr
+}
+
+// New return an in-memory server running the given handler. The server must be started with Listen.
+func New(upd libsck.UpdateConn, hdl libsck.Handler, opt Options) (Server, error) {
+	var (
+		l = newListener()
+		s = scksrt.New(upd, hdl)
+	)
+
+	// the address is only given to the listener factory, no port is bound.
+	if e := s.RegisterServer("127.0.0.1:0"); e != nil {
+		return nil, e
+	}
+
+	s.RegisterListenerFactory(func(ctx context.Context, network, address string) (net.Listener, error) {
+		return l.open()
+	})
+
+	return &srv{
+		Server: s,
+		l:      l,
+		o:      opt,
+	}, nil
+}
+
+// NewPipe return the two ends of an in-memory connection, the client end having the latency and faults of the options.
+// It allows to test a handler function without any server.
+func NewPipe(opt Options) (client net.Conn, server net.Conn) {
+	s, c := net.Pipe()
+	return newConn(c, opt), s
+}
@@ -0,0 +1,124 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package memtest
+
+import (
+	"context"
+	"net"
+	"sync"
+)
+
+type addr string
+
+func (a addr) Network() string {
+	return Network
+}
+
+func (a addr) String() string {
+	return string(a)
+}
+
+// listener gives the server side of the in-memory pipes. It can be reopened after a close
+// to allow the server to listen again.
+type listener struct {
+	m sync.Mutex
+	c chan net.Conn
+	d chan struct{}
+}
+
+func newListener() *listener {
+	d := make(chan struct{})
+	close(d)
+
+	return &listener{
+		c: make(chan net.Conn),
+		d: d,
+	}
+}
+
+func (o *listener) open() (net.Listener, error) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	select {
+	case <-o.d:
+		o.d = make(chan struct{})
+	default:
+	}
+
+	return o, nil
+}
+
+func (o *listener) done() <-chan struct{} {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	return o.d
+}
+
+func (o *listener) dial(ctx context.Context) (net.Conn, error) {
+	s, c := net.Pipe()
+
+	select {
+	case o.c <- s:
+		return c, nil
+	case <-o.done():
+		_ = s.Close()
+		_ = c.Close()
+		return nil, ErrClosed
+	case <-ctx.Done():
+		_ = s.Close()
+		_ = c.Close()
+		return nil, ctx.Err()
+	}
+}
+
+func (o *listener) Accept() (net.Conn, error) {
+	select {
+	case c := <-o.c:
+		return c, nil
+	case <-o.done():
+		return nil, net.ErrClosed
+	}
+}
+
+func (o *listener) Close() error {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	select {
+	case <-o.d:
+	default:
+		close(o.d)
+	}
+
+	return nil
+}
+
+func (o *listener) Addr() net.Addr {
+	return addr(Network)
+}
@@ -0,0 +1,198 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package memtest_test
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	libsck "github.com/nabbar/golib/socket"
+	sckmem "github.com/nabbar/golib/socket/memtest"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func Handler(request libsck.Reader, response libsck.Writer) {
+	defer func() {
+		_ = request.Close()
+		_ = response.Close()
+	}()
+	_, _ = io.Copy(response, request)
+}
+
+func listen(sck sckmem.Server) {
+	go func() {
+		defer GinkgoRecover()
+		_ = sck.Listen(ctx)
+	}()
+
+	Eventually(sck.IsRunning, 5*time.Second, 10*time.Millisecond).Should(BeTrue())
+}
+
+func echo(con net.Conn, msg string) (string, error) {
+	if _, e := con.Write([]byte(msg)); e != nil {
+		return "", e
+	}
+
+	return bufio.NewReader(con).ReadString('\n')
+}
+
+var _ = Describe("socket/memtest", func() {
+	Context("using an in-memory server", func() {
+		It("must serve the dialed connections and call the ConnState callbacks", func() {
+			var (
+				mux = sync.Mutex{}
+				sts = make(map[libsck.ConnState]bool)
+			)
+
+			sck, err := sckmem.New(nil, Handler, sckmem.Options{})
+			Expect(err).ToNot(HaveOccurred())
+
+			sck.RegisterFuncInfo(func(local, remote net.Addr, state libsck.ConnState) {
+				mux.Lock()
+				defer mux.Unlock()
+				sts[state] = true
+			})
+
+			listen(sck)
+			defer func() {
+				Expect(sck.Close()).ToNot(HaveOccurred())
+			}()
+
+			con, err := sck.Dial(ctx)
+			Expect(err).ToNot(HaveOccurred())
+
+			lin, err := echo(con, "hello\n")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lin).To(Equal("hello\n"))
+			Expect(con.Close()).ToNot(HaveOccurred())
+
+			Eventually(func() bool {
+				mux.Lock()
+				defer mux.Unlock()
+				return sts[libsck.ConnectionNew] && sts[libsck.ConnectionHandler] && sts[libsck.ConnectionClose]
+			}, 5*time.Second, 10*time.Millisecond).Should(BeTrue())
+			Expect(sck.Addr().Network()).To(Equal(sckmem.Network))
+		})
+
+		It("must serve the requests of its client", func() {
+			sck, err := sckmem.New(nil, Handler, sckmem.Options{})
+			Expect(err).ToNot(HaveOccurred())
+
+			listen(sck)
+			defer func() {
+				Expect(sck.Close()).ToNot(HaveOccurred())
+			}()
+
+			cli := sck.NewClient()
+			Expect(cli.Connect(ctx)).ToNot(HaveOccurred())
+			Expect(cli.IsConnected()).To(BeTrue())
+
+			_, err = cli.Write([]byte("ping\n"))
+			Expect(err).ToNot(HaveOccurred())
+
+			lin, err := bufio.NewReader(cli).ReadString('\n')
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lin).To(Equal("ping\n"))
+
+			Expect(cli.Close()).ToNot(HaveOccurred())
+			Expect(cli.IsConnected()).To(BeFalse())
+		})
+
+		It("must fail to dial a server not listening", func() {
+			sck, err := sckmem.New(nil, Handler, sckmem.Options{})
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = sck.Dial(ctx)
+			Expect(err).To(MatchError(sckmem.ErrClosed))
+		})
+	})
+
+	Context("using the latency and fault injection", func() {
+		It("must delay the reads and the writes", func() {
+			sck, err := sckmem.New(nil, Handler, sckmem.Options{Latency: 50 * time.Millisecond})
+			Expect(err).ToNot(HaveOccurred())
+
+			listen(sck)
+			defer func() {
+				Expect(sck.Close()).ToNot(HaveOccurred())
+			}()
+
+			con, err := sck.Dial(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			defer func() {
+				_ = con.Close()
+			}()
+
+			tms := time.Now()
+			lin, err := echo(con, "slow\n")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lin).To(Equal("slow\n"))
+			Expect(time.Since(tms)).To(BeNumerically(">=", 100*time.Millisecond))
+		})
+
+		It("must return the injected errors", func() {
+			var (
+				errDial  = errors.New("dial fault")
+				errWrite = errors.New("write fault")
+			)
+
+			sck, err := sckmem.New(nil, Handler, sckmem.Options{DialError: errDial})
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = sck.Dial(ctx)
+			Expect(err).To(MatchError(errDial))
+
+			cli, srv := sckmem.NewPipe(sckmem.Options{
+				WriteFault: func(n int64) error {
+					if n >= 4 {
+						return errWrite
+					}
+					return nil
+				},
+			})
+
+			defer func() {
+				_ = srv.Close()
+			}()
+
+			go func() {
+				_, _ = io.Copy(io.Discard, srv)
+			}()
+
+			_, err = cli.Write([]byte("four"))
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = cli.Write([]byte("more"))
+			Expect(err).To(MatchError(errWrite))
+		})
+	})
+})
@@ -0,0 +1,127 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package memtest
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"time"
+
+	libsck "github.com/nabbar/golib/socket"
+)
+
+type srv struct {
+	libsck.Server
+
+	l *listener
+	o Options
+}
+
+func (o *srv) Dial(ctx context.Context) (net.Conn, error) {
+	if o.o.DialError != nil {
+		return nil, o.o.DialError
+	}
+
+	c, e := o.l.dial(ctx)
+
+	if e != nil {
+		return nil, e
+	}
+
+	return newConn(c, o.o), nil
+}
+
+func (o *srv) NewClient() libsck.Client {
+	return &cli{
+		d: o.Dial,
+		e: new(atomic.Value),
+		i: new(atomic.Value),
+	}
+}
+
+func (o *srv) Addr() net.Addr {
+	return o.l.Addr()
+}
+
+// conn is the client side of an in-memory connection, injecting the latency and the faults.
+type conn struct {
+	net.Conn
+
+	o Options
+	r atomic.Int64 // bytes read
+	w atomic.Int64 // bytes written
+}
+
+func newConn(c net.Conn, opt Options) net.Conn {
+	if opt.Latency <= 0 && opt.ReadFault == nil && opt.WriteFault == nil {
+		return c
+	}
+
+	return &conn{
+		Conn: c,
+		o:    opt,
+	}
+}
+
+func (c *conn) delay() {
+	if c.o.Latency > 0 {
+		time.Sleep(c.o.Latency)
+	}
+}
+
+func (c *conn) Read(p []byte) (int, error) {
+	c.delay()
+
+	if c.o.ReadFault != nil {
+		if e := c.o.ReadFault(c.r.Load()); e != nil {
+			_ = c.Conn.Close()
+			return 0, e
+		}
+	}
+
+	n, e := c.Conn.Read(p)
+	c.r.Add(int64(n))
+
+	return n, e
+}
+
+func (c *conn) Write(p []byte) (int, error) {
+	c.delay()
+
+	if c.o.WriteFault != nil {
+		if e := c.o.WriteFault(c.w.Load()); e != nil {
+			_ = c.Conn.Close()
+			return 0, e
+		}
+	}
+
+	n, e := c.Conn.Write(p)
+	c.w.Add(int64(n))
+
+	return n, e
+}
@@ -0,0 +1,94 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package socket
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Middleware is used to wrap a Handler with a cross-cutting concern (logging, auth, framing, metrics, ...).
+type Middleware func(next Handler) Handler
+
+// Chain return the given handler wrapped by the given middlewares.
+// The first middleware is the outermost: it is the first to receive the request.
+func Chain(h Handler, mw ...Middleware) Handler {
+	if h == nil {
+		return nil
+	}
+
+	for i := len(mw) - 1; i >= 0; i-- {
+		if mw[i] == nil {
+			continue
+		} else if n := mw[i](h); n != nil {
+			h = n
+		}
+	}
+
+	return h
+}
+
+// Recovery return a middleware recovering any panic of the next handler.
+// The panic is sent as an error to the given FuncError if not nil.
+func Recovery(fct FuncError) Middleware {
+	return func(next Handler) Handler {
+		return func(request Reader, response Writer) {
+			defer func() {
+				if r := recover(); r != nil && fct != nil {
+					fct(fmt.Errorf("recovered panic in socket handler: %v", r))
+				}
+			}()
+
+			next(request, response)
+		}
+	}
+}
+
+// MiddlewareList is a concurrent safe list of middlewares, used by servers to implement Use.
+type MiddlewareList struct {
+	m sync.RWMutex
+	l []Middleware
+}
+
+// Add append the given middlewares at the end of the list. Nil middlewares are ignored.
+func (o *MiddlewareList) Add(mw ...Middleware) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	for _, m := range mw {
+		if m != nil {
+			o.l = append(o.l, m)
+		}
+	}
+}
+
+// Handler return the given handler wrapped by all the middlewares of the list.
+func (o *MiddlewareList) Handler(h Handler) Handler {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	return Chain(h, o.l...)
+}
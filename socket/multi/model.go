@@ -32,6 +32,16 @@ import (
 	"sync/atomic"
 )
 
+// wrt and rdc keep the type stored into the atomic values constant,
+// as the underlying writers and readers can have any type.
+type wrt struct {
+	w io.Writer
+}
+
+type rdc struct {
+	r io.ReadCloser
+}
+
 type mlt struct {
 	i *atomic.Value
 	d *atomic.Value
@@ -58,16 +68,16 @@ func (o *mlt) AddWriter(w ...io.Writer) {
 	})
 
 	if len(l) < 1 {
-		o.d.Store(io.Discard)
+		o.d.Store(wrt{w: io.Discard})
 	} else if len(l) == 1 {
-		o.d.Store(l[0])
+		o.d.Store(wrt{w: l[0]})
 	} else {
-		o.d.Store(io.MultiWriter(l...))
+		o.d.Store(wrt{w: io.MultiWriter(l...)})
 	}
 }
 
 func (o *mlt) Clean() {
-	o.d.Store(io.Discard)
+	o.d.Store(wrt{w: io.Discard})
 
 	var keys = make([]any, 0)
 
@@ -90,15 +100,35 @@ func (o *mlt) SetInput(i io.ReadCloser) {
 		i = DiscardCloser{}
 	}
 
-	o.i.Store(i)
+	o.i.Store(rdc{r: i})
+}
+
+func (o *mlt) getWriter() io.Writer {
+	if i := o.d.Load(); i == nil {
+		return nil
+	} else if v, k := i.(wrt); !k {
+		return nil
+	} else {
+		return v.w
+	}
+}
+
+func (o *mlt) getReader() io.ReadCloser {
+	if i := o.i.Load(); i == nil {
+		return nil
+	} else if v, k := i.(rdc); !k {
+		return nil
+	} else {
+		return v.r
+	}
 }
 
 func (o *mlt) Writer() io.Writer {
-	return o.d.Load().(io.Writer)
+	return o.getWriter()
 }
 
 func (o *mlt) Reader() io.ReadCloser {
-	return o.i.Load().(io.ReadCloser)
+	return o.getReader()
 }
 
 func (o *mlt) Copy() (n int64, err error) {
@@ -106,9 +136,7 @@ func (o *mlt) Copy() (n int64, err error) {
 }
 
 func (o *mlt) Read(p []byte) (n int, err error) {
-	if i := o.i.Load(); i == nil {
-		return 0, ErrInstance
-	} else if in, ok := i.(io.Reader); !ok {
+	if in := o.getReader(); in == nil {
 		return 0, ErrInstance
 	} else {
 		return in.Read(p)
@@ -116,9 +144,7 @@ func (o *mlt) Read(p []byte) (n int, err error) {
 }
 
 func (o *mlt) Write(p []byte) (n int, err error) {
-	if i := o.d.Load(); i == nil {
-		return 0, ErrInstance
-	} else if v, k := i.(io.Writer); !k {
+	if v := o.getWriter(); v == nil {
 		return 0, ErrInstance
 	} else {
 		return v.Write(p)
@@ -126,9 +152,7 @@ func (o *mlt) Write(p []byte) (n int, err error) {
 }
 
 func (o *mlt) WriteString(s string) (n int, err error) {
-	if i := o.d.Load(); i == nil {
-		return 0, ErrInstance
-	} else if v, k := i.(io.Writer); !k {
+	if v := o.getWriter(); v == nil {
 		return 0, ErrInstance
 	} else {
 		return io.WriteString(v, s)
@@ -136,9 +160,7 @@ func (o *mlt) WriteString(s string) (n int, err error) {
 }
 
 func (o *mlt) Close() error {
-	if i := o.i.Load(); i == nil {
-		return ErrInstance
-	} else if in, ok := i.(io.ReadCloser); !ok {
+	if in := o.getReader(); in == nil {
 		return ErrInstance
 	} else {
 		return in.Close()
@@ -0,0 +1,115 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package tcp_test
+
+import (
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	libptc "github.com/nabbar/golib/network/protocol"
+	libsck "github.com/nabbar/golib/socket"
+	sckcfg "github.com/nabbar/golib/socket/config"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("socket/server/tcp accept info", func() {
+	Context("using a tcp server with an accept info function", func() {
+		var (
+			sck libsck.Server
+			adr = "127.0.0.1:" + strconv.Itoa(GetFreePort(libptc.NetworkTCP))
+			mux sync.Mutex
+			inf []libsck.AcceptInfo
+		)
+
+		It("Create and listen a new server must succeed", func() {
+			var (
+				cfg = &sckcfg.ServerConfig{
+					Network: libptc.NetworkTCP,
+					Address: adr,
+				}
+				err error
+			)
+
+			sck, err = cfg.New(nil, func(request libsck.Reader, response libsck.Writer) {
+				_ = request.Close()
+				_ = response.Close()
+			})
+
+			Expect(err).ToNot(HaveOccurred())
+
+			sck.RegisterFuncAcceptInfo(func(i libsck.AcceptInfo) {
+				mux.Lock()
+				defer mux.Unlock()
+				inf = append(inf, i)
+			})
+
+			listenClosingServer(sck)
+		})
+
+		It("The accept info must be sent once for each connection", func() {
+			for i := 0; i < 3; i++ {
+				con, err := net.Dial(libptc.NetworkTCP.Code(), adr)
+				Expect(err).ToNot(HaveOccurred())
+				_ = con.Close()
+			}
+
+			Eventually(func() int {
+				mux.Lock()
+				defer mux.Unlock()
+				return len(inf)
+			}, 5*time.Second, 10*time.Millisecond).Should(Equal(3))
+
+			Consistently(func() int {
+				mux.Lock()
+				defer mux.Unlock()
+				return len(inf)
+			}, 200*time.Millisecond, 20*time.Millisecond).Should(Equal(3))
+
+			mux.Lock()
+			defer mux.Unlock()
+
+			var ids = make(map[uint64]bool)
+
+			for _, i := range inf {
+				ids[i.ID] = true
+				Expect(i.Network).To(Equal("tcp"))
+				Expect(i.Local).To(Equal(adr))
+				Expect(i.Remote).ToNot(BeEmpty())
+				Expect(i.Time.IsZero()).To(BeFalse())
+				Expect(i.TLS).To(BeNil())
+			}
+
+			Expect(ids).To(HaveLen(3))
+		})
+
+		It("Closing the server must succeed", func() {
+			Expect(sck.Close()).ToNot(HaveOccurred())
+		})
+	})
+})
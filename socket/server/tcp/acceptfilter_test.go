@@ -0,0 +1,137 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package tcp_test
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	libptc "github.com/nabbar/golib/network/protocol"
+	libsck "github.com/nabbar/golib/socket"
+	sckcfg "github.com/nabbar/golib/socket/config"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("socket/server/tcp accept filter", func() {
+	Context("using a tcp server with an accept filter", func() {
+		It("The rejected connections must be closed before the accept info and the handler", func() {
+			var (
+				adr = "127.0.0.1:" + strconv.Itoa(GetFreePort(libptc.NetworkTCP))
+				cfg = &sckcfg.ServerConfig{
+					Network: libptc.NetworkTCP,
+					Address: adr,
+				}
+				nbi = new(atomic.Int32)
+				nbh = new(atomic.Int32)
+				nbf = new(atomic.Int32)
+			)
+
+			sck, err := cfg.New(nil, func(request libsck.Reader, response libsck.Writer) {
+				nbh.Add(1)
+				_ = request.Close()
+				_ = response.Close()
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			sck.RegisterFuncAcceptInfo(func(i libsck.AcceptInfo) {
+				nbi.Add(1)
+			})
+
+			// accept only the first connection
+			sck.RegisterAcceptFilter(func(con net.Conn) error {
+				if nbf.Add(1) > 1 {
+					return errors.New("rejected")
+				}
+				return nil
+			})
+
+			listenClosingServer(sck)
+
+			defer func() {
+				_ = sck.Close()
+			}()
+
+			for i := 0; i < 3; i++ {
+				con, e := net.Dial(libptc.NetworkTCP.Code(), adr)
+				Expect(e).ToNot(HaveOccurred())
+
+				if i > 0 {
+					// the rejected connection is closed by the server
+					_ = con.SetReadDeadline(time.Now().Add(5 * time.Second))
+					_, e = con.Read(make([]byte, 1))
+					Expect(e).To(HaveOccurred())
+				}
+
+				_ = con.Close()
+			}
+
+			Eventually(nbf.Load, 5*time.Second, 10*time.Millisecond).Should(BeNumerically("==", 3))
+			Eventually(nbh.Load, 5*time.Second, 10*time.Millisecond).Should(BeNumerically("==", 1))
+			Consistently(nbi.Load, 200*time.Millisecond, 20*time.Millisecond).Should(BeNumerically("==", 1))
+		})
+	})
+
+	Context("using the accept filter helpers", func() {
+		It("The allow filter must reject the addresses out of the networks", func() {
+			f, err := libsck.AcceptFilterAllow("10.0.0.0/8", "::1")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(f(&addrConn{r: &net.TCPAddr{IP: net.ParseIP("10.1.2.3")}})).ToNot(HaveOccurred())
+			Expect(f(&addrConn{r: &net.TCPAddr{IP: net.ParseIP("::1")}})).ToNot(HaveOccurred())
+			Expect(f(&addrConn{r: &net.TCPAddr{IP: net.ParseIP("192.168.1.1")}})).To(MatchError(libsck.ErrAcceptDenied))
+			Expect(f(&addrConn{r: &net.UnixAddr{Name: "@", Net: "unix"}})).ToNot(HaveOccurred())
+
+			_, err = libsck.AcceptFilterAllow("not an ip")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("The rate filter must reject the connections over the rate", func() {
+			var (
+				f = libsck.AcceptFilterRate(2, time.Hour)
+				c = &addrConn{r: &net.TCPAddr{IP: net.ParseIP("127.0.0.1")}}
+			)
+
+			Expect(f(c)).ToNot(HaveOccurred())
+			Expect(f(c)).ToNot(HaveOccurred())
+			Expect(f(c)).To(MatchError(libsck.ErrAcceptRate))
+			Expect(libsck.AcceptFilters(nil, f)(c)).To(MatchError(libsck.ErrAcceptRate))
+		})
+	})
+})
+
+type addrConn struct {
+	net.Conn
+	r net.Addr
+}
+
+func (o *addrConn) RemoteAddr() net.Addr {
+	return o.r
+}
@@ -0,0 +1,213 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package tcp_test
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	libtls "github.com/nabbar/golib/certificates"
+	libptc "github.com/nabbar/golib/network/protocol"
+	libsck "github.com/nabbar/golib/socket"
+	scksrv "github.com/nabbar/golib/socket/server/tcp"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// newTLS generate an ephemeral self-signed certificate and return the server config and the matching client config.
+func newTLS() (libtls.TLSConfig, *tls.Config) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).ToNot(HaveOccurred())
+
+	tpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, &key.PublicKey, key)
+	Expect(err).ToNot(HaveOccurred())
+
+	pkc, err := x509.MarshalECPrivateKey(key)
+	Expect(err).ToNot(HaveOccurred())
+
+	crt, err := x509.ParseCertificate(der)
+	Expect(err).ToNot(HaveOccurred())
+
+	var (
+		srv  = libtls.New()
+		pool = x509.NewCertPool()
+	)
+
+	err = srv.AddCertificatePairString(
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: pkc})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+	)
+	Expect(err).ToNot(HaveOccurred())
+
+	pool.AddCert(crt)
+
+	return srv, &tls.Config{
+		RootCAs:    pool,
+		ServerName: "localhost",
+		MinVersion: tls.VersionTLS12,
+	}
+}
+
+func alpnHandler(name string) libsck.Handler {
+	return func(request libsck.Reader, response libsck.Writer) {
+		defer func() {
+			_ = request.Close()
+			_ = response.Close()
+		}()
+
+		if _, e := bufio.NewReader(request).ReadString('\n'); e == nil {
+			p, _ := request.Context().Tag(libsck.ContextTagALPN)
+			_, _ = response.Write([]byte(name + ":" + p + "\n"))
+		}
+	}
+}
+
+var _ = Describe("socket/server/tcp alpn", func() {
+	Context("using a tls tcp server with handlers by alpn protocol", func() {
+		It("The handler must be selected by the negotiated protocol", func() {
+			var (
+				adr      = "127.0.0.1:" + strconv.Itoa(GetFreePort(libptc.NetworkTCP))
+				srv, cli = newTLS()
+				sck      = scksrv.New(nil, alpnHandler("default"))
+			)
+
+			Expect(sck.RegisterServer(adr)).ToNot(HaveOccurred())
+			Expect(sck.SetTLS(true, srv)).ToNot(HaveOccurred())
+			sck.RegisterALPN("custom/1", alpnHandler("custom"))
+			sck.RegisterALPN("removed/1", alpnHandler("removed"))
+			sck.RegisterALPN("removed/1", nil)
+
+			listenClosingServer(sck)
+
+			defer func() {
+				_ = sck.Close()
+			}()
+
+			var call = func(proto ...string) (string, string) {
+				var c = cli.Clone()
+				c.NextProtos = proto
+
+				con, err := tls.Dial(libptc.NetworkTCP.Code(), adr, c)
+				Expect(err).ToNot(HaveOccurred())
+
+				defer func() {
+					_ = con.Close()
+				}()
+
+				_, err = con.Write([]byte("hello\n"))
+				Expect(err).ToNot(HaveOccurred())
+
+				_ = con.SetReadDeadline(time.Now().Add(5 * time.Second))
+				res, err := bufio.NewReader(con).ReadString('\n')
+				Expect(err).ToNot(HaveOccurred())
+
+				return res, con.ConnectionState().NegotiatedProtocol
+			}
+
+			res, neg := call("custom/1", "http/1.1")
+			Expect(neg).To(Equal("custom/1"))
+			Expect(res).To(Equal("custom:custom/1\n"))
+
+			res, neg = call()
+			Expect(neg).To(BeEmpty())
+			Expect(res).To(Equal("default:\n"))
+
+			_, err := tls.Dial(libptc.NetworkTCP.Code(), adr, &tls.Config{
+				RootCAs:    cli.RootCAs,
+				ServerName: cli.ServerName,
+				NextProtos: []string{"removed/1"},
+			})
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("The concurrent registrations must all be kept", func() {
+			var (
+				adr      = "127.0.0.1:" + strconv.Itoa(GetFreePort(libptc.NetworkTCP))
+				srv, cli = newTLS()
+				sck      = scksrv.New(nil, alpnHandler("default"))
+				wgp      = sync.WaitGroup{}
+				stt      = make(chan struct{})
+				nbr      = 100
+			)
+
+			Expect(sck.RegisterServer(adr)).ToNot(HaveOccurred())
+			Expect(sck.SetTLS(true, srv)).ToNot(HaveOccurred())
+
+			for i := 0; i < nbr; i++ {
+				wgp.Add(1)
+
+				go func(p string) {
+					defer wgp.Done()
+					<-stt
+					sck.RegisterALPN(p, alpnHandler(p))
+				}("proto/" + strconv.Itoa(i))
+			}
+
+			close(stt)
+			wgp.Wait()
+			listenClosingServer(sck)
+
+			defer func() {
+				_ = sck.Close()
+			}()
+
+			for i := 0; i < nbr; i++ {
+				var c = cli.Clone()
+				c.NextProtos = []string{"proto/" + strconv.Itoa(i)}
+
+				con, err := tls.Dial(libptc.NetworkTCP.Code(), adr, c)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(con.ConnectionState().NegotiatedProtocol).To(Equal(c.NextProtos[0]))
+				_ = con.Close()
+			}
+		})
+	})
+})
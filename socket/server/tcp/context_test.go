@@ -0,0 +1,123 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package tcp_test
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	libptc "github.com/nabbar/golib/network/protocol"
+	libsck "github.com/nabbar/golib/socket"
+	sckcfg "github.com/nabbar/golib/socket/config"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type ctxIdentity struct {
+	Name string
+}
+
+var _ = Describe("socket/server/tcp context", func() {
+	Context("using the context of a connection", func() {
+		It("The values and tags set by the handler must be shared and surfaced to the info callback", func() {
+			var (
+				mu  = sync.Mutex{}
+				tag map[string]string
+				cid uint64
+				idt ctxIdentity
+				val bool
+			)
+
+			var cfg = &sckcfg.ServerConfig{
+				Network: libptc.NetworkTCP,
+				Address: "127.0.0.1:" + strconv.Itoa(GetFreePort(libptc.NetworkTCP)),
+			}
+
+			sck, err := cfg.New(nil, func(request libsck.Reader, response libsck.Writer) {
+				defer func() {
+					_ = request.Close()
+					_ = response.Close()
+				}()
+
+				if l, e := bufio.NewReader(request).ReadString('\n'); e == nil {
+					request.Context().SetValue("identity", ctxIdentity{Name: l[:len(l)-1]})
+					request.Context().SetTag("user", l[:len(l)-1])
+
+					mu.Lock()
+					idt, val = libsck.GetValue[ctxIdentity](response.Context(), "identity")
+					_, k := libsck.GetValue[string](response.Context(), "identity")
+					val = val && !k
+					mu.Unlock()
+
+					_, _ = response.Write([]byte(l))
+				}
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			sck.RegisterFuncInfoContext(func(ctx libsck.Context, local, remote net.Addr, state libsck.ConnState) {
+				if state == libsck.ConnectionClose {
+					mu.Lock()
+					defer mu.Unlock()
+					tag = ctx.Tags()
+					cid = ctx.ID()
+				}
+			})
+
+			listenClosingServer(sck)
+
+			defer func() {
+				_ = sck.Close()
+			}()
+
+			con, err := net.Dial(libptc.NetworkTCP.Code(), cfg.Address)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = con.Write([]byte("alice\n"))
+			Expect(err).ToNot(HaveOccurred())
+
+			_ = con.SetReadDeadline(time.Now().Add(5 * time.Second))
+			_, err = bufio.NewReader(con).ReadString('\n')
+			Expect(err).ToNot(HaveOccurred())
+			_ = con.Close()
+
+			Eventually(func() map[string]string {
+				mu.Lock()
+				defer mu.Unlock()
+				return tag
+			}, 10*time.Second, 50*time.Millisecond).Should(Equal(map[string]string{"user": "alice"}))
+
+			mu.Lock()
+			defer mu.Unlock()
+			Expect(cid).To(BeNumerically(">", 0))
+			Expect(val).To(BeTrue())
+			Expect(idt.Name).To(Equal("alice"))
+		})
+	})
+})
@@ -0,0 +1,147 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package tcp_test
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	libptc "github.com/nabbar/golib/network/protocol"
+	libsck "github.com/nabbar/golib/socket"
+	sckcfg "github.com/nabbar/golib/socket/config"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func newDrainServer(h libsck.Handler, d libsck.Drain) (libsck.Server, string) {
+	var cfg = &sckcfg.ServerConfig{
+		Network: libptc.NetworkTCP,
+		Address: "127.0.0.1:" + strconv.Itoa(GetFreePort(libptc.NetworkTCP)),
+		Drain:   d,
+	}
+
+	sck, err := cfg.New(nil, h)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(sck).ToNot(BeNil())
+
+	listenClosingServer(sck)
+
+	return sck, cfg.Address
+}
+
+var _ = Describe("socket/server/tcp drain", func() {
+	Context("shutting down a tcp server with a handler still running", func() {
+		It("The open connections must be force closed and listed after ForceCloseAfter", func() {
+			var (
+				mu  = sync.Mutex{}
+				lst []libsck.AcceptInfo
+			)
+
+			sck, adr := newDrainServer(Handler, libsck.Drain{
+				Deadline:        10 * time.Second,
+				ForceCloseAfter: 300 * time.Millisecond,
+				OnTimeout: func(open []libsck.AcceptInfo) {
+					mu.Lock()
+					defer mu.Unlock()
+					lst = open
+				},
+			})
+
+			con, err := net.Dial(libptc.NetworkTCP.Code(), adr)
+			Expect(err).ToNot(HaveOccurred())
+
+			defer func() {
+				_ = con.Close()
+			}()
+
+			Eventually(sck.OpenConnections, 5*time.Second, 10*time.Millisecond).Should(BeNumerically("==", 1))
+
+			var start = time.Now()
+			Expect(sck.Shutdown(ctx)).ToNot(HaveOccurred())
+			Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+			Expect(sck.OpenConnections()).To(BeNumerically("==", 0))
+
+			mu.Lock()
+			Expect(lst).To(HaveLen(1))
+			Expect(lst[0].Remote).To(Equal(con.LocalAddr().String()))
+			mu.Unlock()
+
+			_ = con.SetReadDeadline(time.Now().Add(5 * time.Second))
+			_, err = con.Read(make([]byte, 1))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("shutting down a tcp server with a request in progress", func() {
+		It("The handler must end its request before the drain deadline", func() {
+			var cnt = 0
+
+			sck, adr := newDrainServer(func(request libsck.Reader, response libsck.Writer) {
+				defer func() {
+					_ = request.Close()
+					_ = response.Close()
+				}()
+
+				if l, e := bufio.NewReader(request).ReadString('\n'); e == nil {
+					time.Sleep(300 * time.Millisecond)
+					_, _ = response.Write([]byte(l))
+				}
+			}, libsck.Drain{
+				Deadline: 5 * time.Second,
+				OnTimeout: func(open []libsck.AcceptInfo) {
+					cnt++
+				},
+			})
+
+			con, err := net.Dial(libptc.NetworkTCP.Code(), adr)
+			Expect(err).ToNot(HaveOccurred())
+
+			defer func() {
+				_ = con.Close()
+			}()
+
+			_, err = con.Write([]byte("hello\n"))
+			Expect(err).ToNot(HaveOccurred())
+			Eventually(sck.OpenConnections, 5*time.Second, 10*time.Millisecond).Should(BeNumerically("==", 1))
+
+			go func() {
+				defer GinkgoRecover()
+				Expect(sck.Shutdown(ctx)).ToNot(HaveOccurred())
+			}()
+
+			_ = con.SetReadDeadline(time.Now().Add(5 * time.Second))
+			res, err := io.ReadAll(con)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(res)).To(Equal("hello\n"))
+			Expect(cnt).To(BeZero())
+		})
+	})
+})
@@ -0,0 +1,101 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package tcp_test
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	libevt "github.com/nabbar/golib/events"
+	libptc "github.com/nabbar/golib/network/protocol"
+	libsck "github.com/nabbar/golib/socket"
+	sckcfg "github.com/nabbar/golib/socket/config"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("socket/server/tcp events", func() {
+	Context("publishing the events on a bus with a subscriber never reading", func() {
+		It("The connections and the shutdown must not be blocked and the events must be dropped", func() {
+			var (
+				bus = libevt.New(ctx)
+				blk = make(chan struct{})
+				drp = new(atomic.Int64)
+				cfg = &sckcfg.ServerConfig{
+					Network: libptc.NetworkTCP,
+					Address: "127.0.0.1:" + strconv.Itoa(GetFreePort(libptc.NetworkTCP)),
+				}
+			)
+
+			defer func() {
+				close(blk)
+				_ = bus.Close()
+			}()
+
+			bus.RegisterFuncError(func(topic string, err error) {
+				drp.Add(1)
+			})
+
+			_, err := bus.Subscribe(ctx, libevt.TopicAll, 0, func(ctx context.Context, topic string, evt any) {
+				<-blk
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			sck, err := cfg.New(nil, Handler)
+			Expect(err).ToNot(HaveOccurred())
+
+			sck.RegisterFuncInfo(libsck.PublishFuncInfo(bus, ""))
+			sck.RegisterFuncInfoServer(libsck.PublishFuncInfoServer(bus, ""))
+			sck.RegisterFuncError(libsck.PublishFuncError(bus, ""))
+
+			listenClosingServer(sck)
+
+			for i := 0; i < 3; i++ {
+				con, e := net.Dial(libptc.NetworkTCP.Code(), cfg.Address)
+				Expect(e).ToNot(HaveOccurred())
+
+				_ = con.SetDeadline(time.Now().Add(2 * time.Second))
+				_, e = con.Write([]byte("hello\n"))
+				Expect(e).ToNot(HaveOccurred())
+
+				l, e := bufio.NewReader(con).ReadString('\n')
+				Expect(e).ToNot(HaveOccurred())
+				Expect(l).To(Equal("hello\n"))
+
+				_ = con.Close()
+			}
+
+			var start = time.Now()
+			Expect(sck.Shutdown(ctx)).ToNot(HaveOccurred())
+			Expect(time.Since(start)).To(BeNumerically("<", 2*time.Second))
+			Expect(drp.Load()).To(BeNumerically(">", 0))
+		})
+	})
+})
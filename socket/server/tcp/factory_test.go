@@ -0,0 +1,130 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package tcp_test
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"sync/atomic"
+	"time"
+
+	libptc "github.com/nabbar/golib/network/protocol"
+	libsck "github.com/nabbar/golib/socket"
+	sckcfg "github.com/nabbar/golib/socket/config"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// pipeListener is a fake listener giving the server side of in-memory pipes.
+type pipeListener struct {
+	c chan net.Conn
+	d chan struct{}
+	s atomic.Bool
+}
+
+func newPipeListener() *pipeListener {
+	return &pipeListener{
+		c: make(chan net.Conn),
+		d: make(chan struct{}),
+	}
+}
+
+func (o *pipeListener) Dial() net.Conn {
+	srv, cli := net.Pipe()
+	o.c <- srv
+	return cli
+}
+
+func (o *pipeListener) Accept() (net.Conn, error) {
+	select {
+	case c := <-o.c:
+		return c, nil
+	case <-o.d:
+		return nil, net.ErrClosed
+	}
+}
+
+func (o *pipeListener) Close() error {
+	if o.s.CompareAndSwap(false, true) {
+		close(o.d)
+	}
+	return nil
+}
+
+func (o *pipeListener) Addr() net.Addr {
+	return &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)}
+}
+
+var _ = Describe("socket/server/tcp listener factory", func() {
+	Context("using a custom listener factory", func() {
+		It("must serve the connections of the custom listener", func() {
+			var (
+				lis = newPipeListener()
+				nbr = new(atomic.Int32)
+				cfg = &sckcfg.ServerConfig{
+					Network: libptc.NetworkTCP,
+					Address: "127.0.0.1:1",
+					Listener: func(ctx context.Context, network, address string) (net.Listener, error) {
+						Expect(network).To(Equal(libptc.NetworkTCP.Code()))
+						Expect(address).To(Equal("127.0.0.1:1"))
+						nbr.Add(1)
+						return lis, nil
+					},
+					PacketConn: func(ctx context.Context, network, address string) (libsck.PacketConn, error) {
+						Fail("packet conn factory must not be called by a stream server")
+						return nil, nil
+					},
+				}
+			)
+
+			sck, err := cfg.New(nil, Handler)
+			Expect(err).ToNot(HaveOccurred())
+			listenClosingServer(sck)
+
+			defer func() {
+				Expect(sck.Close()).ToNot(HaveOccurred())
+			}()
+
+			Expect(nbr.Load()).To(BeEquivalentTo(1))
+
+			con := lis.Dial()
+
+			defer func() {
+				_ = con.Close()
+			}()
+
+			Expect(con.SetDeadline(time.Now().Add(5 * time.Second))).ToNot(HaveOccurred())
+			_, err = con.Write([]byte("hello\n"))
+			Expect(err).ToNot(HaveOccurred())
+
+			lin, err := bufio.NewReader(con).ReadString('\n')
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lin).To(Equal("hello\n"))
+		})
+	})
+})
@@ -0,0 +1,135 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package tcp_test
+
+import (
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	libptc "github.com/nabbar/golib/network/protocol"
+	libsck "github.com/nabbar/golib/socket"
+	sckcfg "github.com/nabbar/golib/socket/config"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("socket/server/tcp half close", func() {
+	Context("using a tcp server reading the request until the end of stream", func() {
+		var (
+			sck libsck.Server
+			adr = "127.0.0.1:" + strconv.Itoa(GetFreePort(libptc.NetworkTCP))
+			mux sync.Mutex
+			stt = make(map[libsck.ConnState]int)
+			lte error
+		)
+
+		It("Create and listen a new server must succeed", func() {
+			var (
+				cfg = &sckcfg.ServerConfig{
+					Network: libptc.NetworkTCP,
+					Address: adr,
+				}
+				err error
+			)
+
+			sck, err = cfg.New(nil, func(request libsck.Reader, response libsck.Writer) {
+				p, _ := io.ReadAll(request)
+				_ = request.(libsck.HalfCloser).CloseRead()
+
+				_, _ = response.Write([]byte("received " + strconv.Itoa(len(p))))
+				_ = response.(libsck.HalfCloser).CloseWrite()
+
+				_, e := response.Write([]byte("late"))
+
+				mux.Lock()
+				defer mux.Unlock()
+				lte = e
+			})
+
+			Expect(err).ToNot(HaveOccurred())
+
+			sck.RegisterFuncInfo(func(local, remote net.Addr, state libsck.ConnState) {
+				mux.Lock()
+				defer mux.Unlock()
+				stt[state]++
+			})
+
+			listenClosingServer(sck)
+		})
+
+		It("The client must read the response after closing its write side", func() {
+			cli := &sckcfg.ClientConfig{
+				Network: libptc.NetworkTCP,
+				Address: adr,
+			}
+
+			clt, err := cli.New()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(clt.Connect(ctx)).ToNot(HaveOccurred())
+
+			defer func() {
+				_ = clt.Close()
+			}()
+
+			_, err = clt.Write([]byte("hello world"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(clt.(libsck.HalfCloser).CloseWrite()).ToNot(HaveOccurred())
+
+			res, err := io.ReadAll(clt)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(res)).To(Equal("received 11"))
+		})
+
+		It("The state of each closed direction must be sent once", func() {
+			Eventually(func() int {
+				mux.Lock()
+				defer mux.Unlock()
+				return stt[libsck.ConnectionClose]
+			}, 5*time.Second, 10*time.Millisecond).Should(Equal(1))
+
+			mux.Lock()
+			defer mux.Unlock()
+
+			Expect(stt[libsck.ConnectionCloseRead]).To(Equal(1))
+			Expect(stt[libsck.ConnectionCloseWrite]).To(Equal(1))
+		})
+
+		It("A write after the close of the write side must fail", func() {
+			mux.Lock()
+			defer mux.Unlock()
+
+			Expect(lte).To(MatchError(io.ErrClosedPipe))
+		})
+
+		It("Closing the server must succeed", func() {
+			Expect(sck.Close()).ToNot(HaveOccurred())
+		})
+	})
+})
@@ -27,6 +27,7 @@
 package tcp
 
 import (
+	"sync"
 	"sync/atomic"
 
 	libsck "github.com/nabbar/golib/socket"
@@ -35,6 +36,13 @@ import (
 type ServerTcp interface {
 	libsck.Server
 	RegisterServer(address string) error
+
+	// RegisterALPN registers the handler of the TLS connections negotiating the given ALPN protocol.
+	// The registered protocols are added to the TLS config at the start of Listen. The connections without
+	// protocol are given to the default handler, the connections offering only unknown protocols fail the handshake.
+	// A nil handler remove the protocol.
+	// The negotiated protocol is stored into the libsck.ContextTagALPN tag of the connection Context.
+	RegisterALPN(proto string, h libsck.Handler)
 }
 
 func New(u libsck.UpdateConn, h libsck.Handler) ServerTcp {
@@ -47,19 +55,36 @@ func New(u libsck.UpdateConn, h libsck.Handler) ServerTcp {
 	r := new(atomic.Value)
 	r.Store(make(chan struct{}))
 
+	f := new(atomic.Value)
+	f.Store(make(chan struct{}))
+
 	return &srv{
 		ssl: new(atomic.Value),
 		upd: u,
 		hdl: h,
+		mdw: new(libsck.MiddlewareList),
 		msg: c,
 		stp: s,
 		rst: r,
 		run: new(atomic.Bool),
+		cls: new(atomic.Bool),
 		gon: new(atomic.Bool),
 		fe:  new(atomic.Value),
 		fi:  new(atomic.Value),
 		fs:  new(atomic.Value),
+		fa:  new(atomic.Value),
+		fx:  new(atomic.Value),
+		ff:  new(atomic.Value),
+		lf:  new(atomic.Value),
+		sh:  new(atomic.Int64),
+		ap:  new(atomic.Value),
+		am:  new(sync.Mutex),
 		ad:  new(atomic.Value),
 		nc:  new(atomic.Int64),
+		ci:  new(atomic.Uint64),
+		cs:  new(libsck.ConnStateCounter),
+		cr:  new(libsck.ConnRegistry),
+		dr:  new(atomic.Value),
+		fc:  f,
 	}
 }
@@ -29,7 +29,11 @@ package tcp
 import (
 	"context"
 	"crypto/tls"
+	"io"
+	"maps"
 	"net"
+	"slices"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -47,15 +51,33 @@ func (o *srv) getAddress() string {
 	return ""
 }
 
-func (o *srv) getListen(addr string) (net.Listener, error) {
+func (o *srv) getListen(addr string, shard bool) (net.Listener, error) {
 	var (
 		lis net.Listener
 		err error
 	)
 
-	if lis, err = net.Listen(libptc.NetworkTCP.Code(), addr); err != nil {
+	if f := o.getListenerFactory(); f != nil {
+		lis, err = f(context.Background(), libptc.NetworkTCP.Code(), addr)
+	} else if shard {
+		lis, err = libsck.ListenReusePort(context.Background(), libptc.NetworkTCP.Code(), addr)
+	} else {
+		lis, err = net.Listen(libptc.NetworkTCP.Code(), addr)
+	}
+
+	if err != nil {
 		return lis, err
 	} else if t := o.getTLS(); t != nil {
+		if l := o.getALPN(); len(l) > 0 {
+			t = t.Clone()
+
+			for _, p := range slices.Sorted(maps.Keys(l)) {
+				if !slices.Contains(t.NextProtos, p) {
+					t.NextProtos = append(t.NextProtos, p)
+				}
+			}
+		}
+
 		lis = tls.NewListener(lis, t)
 		o.fctInfoSrv("starting listening socket 'TLS %s %s'", libptc.NetworkTCP.String(), addr)
 	} else {
@@ -65,21 +87,62 @@ func (o *srv) getListen(addr string) (net.Listener, error) {
 	return lis, nil
 }
 
+// getListeners opens the listeners of each shard, sharing the address with SO_REUSEPORT if more than one.
+func (o *srv) getListeners(addr string) ([]net.Listener, error) {
+	var (
+		n = o.getShards()
+		r = make([]net.Listener, 0, max(n, 1))
+	)
+
+	if n < 2 {
+		if l, e := o.getListen(addr, false); e != nil {
+			return nil, e
+		} else {
+			return append(r, l), nil
+		}
+	}
+
+	for i := 0; i < n; i++ {
+		l, e := o.getListen(addr, true)
+
+		if e != nil {
+			for _, c := range r {
+				_ = c.Close()
+			}
+
+			return nil, e
+		}
+
+		// the next shards use the port given to the first one
+		if i == 0 {
+			addr = l.Addr().String()
+		}
+
+		r = append(r, l)
+	}
+
+	return r, nil
+}
+
 func (o *srv) Listen(ctx context.Context) error {
 	var (
 		e error              // error
-		l net.Listener       // socket listener
+		l []net.Listener     // socket listeners
 		a = o.getAddress()   // address
 		s = new(atomic.Bool) // running
 	)
 
+	if o.IsClosed() {
+		return ErrServerClosed
+	}
+
 	if len(a) == 0 {
 		o.fctError(ErrInvalidHandler)
 		return ErrInvalidAddress
 	} else if o.hdl == nil {
 		o.fctError(ErrInvalidHandler)
 		return ErrInvalidHandler
-	} else if l, e = o.getListen(a); e != nil {
+	} else if l, e = o.getListeners(a); e != nil {
 		o.fctError(e)
 		return e
 	}
@@ -89,8 +152,8 @@ func (o *srv) Listen(ctx context.Context) error {
 	defer func() {
 		o.fctInfoSrv("closing listen socket '%s %s'", libptc.NetworkTCP.String(), a)
 
-		if l != nil {
-			_ = l.Close()
+		for _, i := range l {
+			_ = i.Close()
 		}
 
 		go func() {
@@ -101,20 +164,26 @@ func (o *srv) Listen(ctx context.Context) error {
 	}()
 
 	o.rst.Store(make(chan struct{}))
+	o.fc.Store(make(chan struct{}))
 	o.stp.Store(make(chan struct{}))
 	o.run.Store(true)
 	o.gon.Store(false)
 
+	// the server could have been closed before the stop channel has been replaced
+	if o.IsClosed() {
+		return ErrServerClosed
+	}
+
 	go func() {
 		defer func() {
 			s.Store(true)
 
-			if l != nil {
-				o.fctError(l.Close())
+			for _, i := range l {
+				o.fctError(i.Close())
 			}
 
 			go func() {
-				_ = o.Shutdown(context.Background())
+				_ = o.shutdown(context.Background())
 			}()
 		}()
 
@@ -126,34 +195,58 @@ func (o *srv) Listen(ctx context.Context) error {
 		}
 	}()
 
+	// each shard runs its own accept loop
+	var wg sync.WaitGroup
+
+	for _, i := range l[1:] {
+		wg.Add(1)
+
+		go func(lis net.Listener) {
+			defer wg.Done()
+			o.accept(ctx, lis, s)
+		}(i)
+	}
+
+	o.accept(ctx, l[0], s)
+	wg.Wait()
+
+	return nil
+}
+
+// accept the new connections of the listener until the stop trigger
+func (o *srv) accept(ctx context.Context, l net.Listener, s *atomic.Bool) {
 	// Accept new connection or stop if context or shutdown trigger
 	for l != nil && !s.Load() {
 		if co, ce := l.Accept(); ce != nil && !s.Load() {
 			o.fctError(ce)
-		} else if co != nil {
+		} else if co != nil && o.acceptFilter(co) {
 			o.fctInfo(co.LocalAddr(), co.RemoteAddr(), libsck.ConnectionNew)
 			go o.Conn(ctx, co)
 		}
 	}
-
-	return nil
 }
 
 func (o *srv) Conn(ctx context.Context, con net.Conn) {
 	var (
+		acc = time.Now()
 		cnl context.CancelFunc
 		cor libsck.Reader
 		cow libsck.Writer
 	)
 
 	o.nc.Add(1) // inc nb connection
+	trk := o.cs.Track()
 
 	if o.upd != nil {
 		o.upd(con)
 	}
 
+	id := o.fctAcceptInfo(ctx, acc, con)
+	cx := libsck.NewContext(id)
+	o.cr.Add(id, acc, con)
+
 	ctx, cnl = context.WithCancel(ctx)
-	cor, cow = o.getReadWriter(ctx, cnl, con)
+	cor, cow = o.getReadWriter(ctx, cnl, con, trk, cx)
 
 	defer func() {
 		// cancel context for connection
@@ -161,6 +254,7 @@ func (o *srv) Conn(ctx context.Context, con net.Conn) {
 
 		// dec nb connection
 		o.nc.Add(-1)
+		trk.Close()
 
 		// close connection writer
 		_ = cow.Close()
@@ -178,17 +272,26 @@ func (o *srv) Conn(ctx context.Context, con net.Conn) {
 		_ = cor.Close()
 
 		// send info about connection closing
-		o.fctInfo(con.LocalAddr(), con.RemoteAddr(), libsck.ConnectionClose)
+		o.fctInfoConn(cx, con.LocalAddr(), con.RemoteAddr(), libsck.ConnectionClose)
 
 		// close connection
 		_ = con.Close()
+		o.cr.Del(id)
 	}()
 
 	// get handler or exit if nil
 	if o.hdl == nil {
 		return
 	} else {
-		go o.hdl(cor, cow)
+		go func() {
+			o.fctInfoConn(cx, con.LocalAddr(), con.RemoteAddr(), libsck.ConnectionHandler)
+			trk.Set(libsck.ConnectionHandler)
+
+			o.handlerConn(ctx, con, cx)(cor, cow)
+
+			// the connection stay open until both directions are closed
+			trk.Set(libsck.ConnectionNew)
+		}()
 	}
 
 	for {
@@ -196,65 +299,74 @@ func (o *srv) Conn(ctx context.Context, con net.Conn) {
 		case <-ctx.Done():
 			return
 		case <-o.Gone():
+			o.drain(ctx)
 			return
 		}
 	}
 }
 
-func (o *srv) getReadWriter(ctx context.Context, cnl context.CancelFunc, con net.Conn) (libsck.Reader, libsck.Writer) {
+func (o *srv) getReadWriter(ctx context.Context, cnl context.CancelFunc, con net.Conn, trk *libsck.ConnStateTrack, cx libsck.Context) (libsck.Reader, libsck.Writer) {
 	var (
 		rc = new(atomic.Bool)
 		rw = new(atomic.Bool)
 	)
 
+	// each direction is closed once, the connection context is cancelled when both are closed
 	rdrClose := func() error {
+		if rc.Swap(true) {
+			return nil
+		}
+
 		defer func() {
 			if rw.Load() {
 				cnl()
 			}
 		}()
 
-		if cr, ok := con.(*net.TCPConn); ok {
-			rc.Store(true)
-			o.fctInfo(con.LocalAddr(), con.RemoteAddr(), libsck.ConnectionCloseRead)
+		o.fctInfoConn(cx, con.LocalAddr(), con.RemoteAddr(), libsck.ConnectionCloseRead)
+
+		if cr, ok := con.(interface{ CloseRead() error }); ok {
 			return libsck.ErrorFilter(cr.CloseRead())
-		} else {
-			rc.Store(true)
-			rw.Store(true)
-			o.fctInfo(con.LocalAddr(), con.RemoteAddr(), libsck.ConnectionClose)
-			return libsck.ErrorFilter(con.Close())
 		}
+
+		return nil
 	}
 
 	wrtClose := func() error {
+		if rw.Swap(true) {
+			return nil
+		}
+
 		defer func() {
 			if rc.Load() {
 				cnl()
 			}
 		}()
 
-		if cr, ok := con.(*net.TCPConn); ok {
-			rw.Store(true)
-			o.fctInfo(con.LocalAddr(), con.RemoteAddr(), libsck.ConnectionCloseWrite)
-			return libsck.ErrorFilter(cr.CloseRead())
-		} else {
-			rc.Store(true)
-			rw.Store(true)
-			o.fctInfo(con.LocalAddr(), con.RemoteAddr(), libsck.ConnectionClose)
-			return libsck.ErrorFilter(con.Close())
+		o.fctInfoConn(cx, con.LocalAddr(), con.RemoteAddr(), libsck.ConnectionCloseWrite)
+
+		if cw, ok := con.(interface{ CloseWrite() error }); ok {
+			return libsck.ErrorFilter(cw.CloseWrite())
 		}
+
+		return nil
 	}
 
-	rdr := libsck.NewReader(
+	rdr := libsck.NewStreamReader(
 		func(p []byte) (n int, err error) {
-			if ctx.Err() != nil {
+			if rc.Load() {
+				return 0, io.EOF
+			} else if ctx.Err() != nil {
 				_ = rdrClose()
 				return 0, ctx.Err()
 			}
-			o.fctInfo(con.LocalAddr(), con.RemoteAddr(), libsck.ConnectionRead)
+			o.fctInfoConn(cx, con.LocalAddr(), con.RemoteAddr(), libsck.ConnectionRead)
+			trk.Set(libsck.ConnectionRead)
+			defer trk.Set(libsck.ConnectionHandler)
 			return con.Read(p)
 		},
 		rdrClose,
+		wrtClose,
 		func() bool {
 			if ctx.Err() != nil {
 				_ = rdrClose()
@@ -275,15 +387,20 @@ func (o *srv) getReadWriter(ctx context.Context, cnl context.CancelFunc, con net
 		},
 	)
 
-	wrt := libsck.NewWriter(
+	wrt := libsck.NewStreamWriter(
 		func(p []byte) (n int, err error) {
-			if ctx.Err() != nil {
+			if rw.Load() {
+				return 0, io.ErrClosedPipe
+			} else if ctx.Err() != nil {
 				_ = wrtClose()
 				return 0, ctx.Err()
 			}
-			o.fctInfo(con.LocalAddr(), con.RemoteAddr(), libsck.ConnectionWrite)
+			o.fctInfoConn(cx, con.LocalAddr(), con.RemoteAddr(), libsck.ConnectionWrite)
+			trk.Set(libsck.ConnectionWrite)
+			defer trk.Set(libsck.ConnectionHandler)
 			return con.Write(p)
 		},
+		rdrClose,
 		wrtClose,
 		func() bool {
 			if ctx.Err() != nil {
@@ -305,5 +422,7 @@ func (o *srv) getReadWriter(ctx context.Context, cnl context.CancelFunc, con net
 		},
 	)
 
+	libsck.SetContext(cx, rdr, wrt)
+
 	return rdr, wrt
 }
@@ -0,0 +1,137 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package tcp_test
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	libptc "github.com/nabbar/golib/network/protocol"
+	libsck "github.com/nabbar/golib/socket"
+	sckcfg "github.com/nabbar/golib/socket/config"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func prefixMiddleware(p string) libsck.Middleware {
+	return func(next libsck.Handler) libsck.Handler {
+		return func(request libsck.Reader, response libsck.Writer) {
+			_, _ = response.Write([]byte(p))
+			next(request, response)
+		}
+	}
+}
+
+var _ = Describe("socket/server/tcp middleware", func() {
+	Context("using a tcp server with middlewares", func() {
+		var (
+			sck libsck.Server
+			adr = "127.0.0.1:" + strconv.Itoa(GetFreePort(libptc.NetworkTCP))
+			mux sync.Mutex
+			rec []error
+		)
+
+		It("Create and listen a new server with middlewares must succeed", func() {
+			var (
+				cfg = &sckcfg.ServerConfig{
+					Network: libptc.NetworkTCP,
+					Address: adr,
+				}
+				err error
+			)
+
+			sck, err = cfg.New(nil, func(request libsck.Reader, response libsck.Writer) {
+				defer func() {
+					_ = request.Close()
+					_ = response.Close()
+				}()
+
+				var buf = bufio.NewReader(request)
+
+				if l, e := buf.ReadString(libsck.EOL); e != nil {
+					return
+				} else if l == "panic\n" {
+					panic("boom")
+				} else {
+					_, _ = response.Write([]byte(l))
+				}
+			})
+
+			Expect(err).ToNot(HaveOccurred())
+
+			sck.Use(libsck.Recovery(func(e ...error) {
+				mux.Lock()
+				defer mux.Unlock()
+				rec = append(rec, e...)
+			}))
+			sck.Use(prefixMiddleware("a:"), nil, prefixMiddleware("b:"))
+
+			listenClosingServer(sck)
+		})
+
+		It("Middlewares must be applied in order around the handler", func() {
+			con, err := net.Dial(libptc.NetworkTCP.Code(), adr)
+			Expect(err).ToNot(HaveOccurred())
+
+			defer func() {
+				_ = con.Close()
+			}()
+
+			_, err = con.Write([]byte("hello\n"))
+			Expect(err).ToNot(HaveOccurred())
+
+			_ = con.SetReadDeadline(time.Now().Add(5 * time.Second))
+			res, err := bufio.NewReader(con).ReadString(libsck.EOL)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal("a:b:hello\n"))
+		})
+
+		It("A panic of the handler must be recovered and reported", func() {
+			con, err := net.Dial(libptc.NetworkTCP.Code(), adr)
+			Expect(err).ToNot(HaveOccurred())
+
+			defer func() {
+				_ = con.Close()
+			}()
+
+			_, err = con.Write([]byte("panic\n"))
+			Expect(err).ToNot(HaveOccurred())
+
+			Eventually(func() int {
+				mux.Lock()
+				defer mux.Unlock()
+				return len(rec)
+			}, 5*time.Second, 10*time.Millisecond).Should(Equal(1))
+		})
+
+		It("Closing the server must succeed", func() {
+			Expect(sck.Close()).ToNot(HaveOccurred())
+		})
+	})
+})
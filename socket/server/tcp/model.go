@@ -31,6 +31,7 @@ import (
 	"crypto/tls"
 	"fmt"
 	"net"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -56,22 +57,41 @@ type srv struct {
 	stp *atomic.Value     // chan struct{}
 	rst *atomic.Value     // chan struct{}
 	run *atomic.Bool      // is Running
+	cls *atomic.Bool      // is Closed
 	gon *atomic.Bool      // is Running
 
 	fe *atomic.Value // function error
 	fi *atomic.Value // function info
 	fs *atomic.Value // function info server
+	fa *atomic.Value // function accept info
+	fx *atomic.Value // function info context
+	ff *atomic.Value // function accept filter
+	lf *atomic.Value // listener factory
+	sh *atomic.Int64 // number of shards
+	ap *atomic.Value // map[string]libsck.Handler alpn handlers
+	am *sync.Mutex   // serialize the alpn handlers updates
 
 	sr *atomic.Int32 // read buffer size
 	ad *atomic.Value // Server address url
 
-	nc *atomic.Int64 // Counter Connection
+	nc *atomic.Int64            // Counter Connection
+	ci *atomic.Uint64           // last connection id
+	cs *libsck.ConnStateCounter // connections by state
+	cr *libsck.ConnRegistry     // open connections
+	dr *atomic.Value            // drain options
+	fc *atomic.Value            // chan struct{} closed when the open connections are force closed
+
+	mdw *libsck.MiddlewareList // middlewares
 }
 
 func (o *srv) OpenConnections() int64 {
 	return o.nc.Load()
 }
 
+func (o *srv) ConnectionsByState() libsck.ConnectionsByState {
+	return o.cs.Get()
+}
+
 func (o *srv) IsRunning() bool {
 	return o.run.Load()
 }
@@ -109,8 +129,24 @@ func (o *srv) Gone() <-chan struct{} {
 	return closedChanStruct
 }
 
+// Close shutdown the server and release it. Calling Close on an already closed server does nothing.
 func (o *srv) Close() error {
-	return o.Shutdown(context.Background())
+	if o == nil {
+		return ErrInvalidInstance
+	} else if !o.cls.CompareAndSwap(false, true) {
+		return nil
+	}
+
+	return o.shutdown(context.Background())
+}
+
+// IsClosed returns true if the server has been closed by Close or Shutdown.
+func (o *srv) IsClosed() bool {
+	if o == nil {
+		return true
+	}
+
+	return o.cls.Load()
 }
 
 func (o *srv) StopGone(ctx context.Context) error {
@@ -120,29 +156,39 @@ func (o *srv) StopGone(ctx context.Context) error {
 
 	o.gon.Store(true)
 
-	if i := o.rst.Load(); i != nil {
+	if i := o.rst.Swap(closedChanStruct); i != nil {
 		if c, k := i.(chan struct{}); k && c != closedChanStruct {
 			close(c)
 		}
 	}
-	o.rst.Store(closedChanStruct)
 
 	var (
 		tck = time.NewTicker(5 * time.Millisecond)
 		cnl context.CancelFunc
 	)
 
-	ctx, cnl = context.WithTimeout(ctx, 10*time.Second)
+	ctx, cnl = context.WithTimeout(ctx, o.getDrain().Timeout(10*time.Second))
 
 	defer func() {
 		tck.Stop()
 		cnl()
 	}()
 
+	var frc <-chan time.Time
+
+	if d := o.getDrain(); d.ForceCloseAfter > 0 {
+		t := time.NewTimer(d.ForceCloseAfter)
+		defer t.Stop()
+		frc = t.C
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
 			return ErrGoneTimeout
+		case <-frc:
+			frc = nil
+			o.forceClose()
 		case <-tck.C:
 			if o.OpenConnections() > 0 {
 				continue
@@ -158,12 +204,11 @@ func (o *srv) StopListen(ctx context.Context) error {
 		return ErrInvalidInstance
 	}
 
-	if i := o.stp.Load(); i != nil {
+	if i := o.stp.Swap(closedChanStruct); i != nil {
 		if c, k := i.(chan struct{}); k && c != closedChanStruct {
 			close(c)
 		}
 	}
-	o.stp.Store(closedChanStruct)
 
 	var (
 		tck = time.NewTicker(5 * time.Millisecond)
@@ -191,13 +236,20 @@ func (o *srv) StopListen(ctx context.Context) error {
 
 }
 
+// Shutdown stops the server and release it. Calling Shutdown on an already closed server returns ErrServerClosed.
 func (o *srv) Shutdown(ctx context.Context) error {
 	if o == nil {
 		return ErrInvalidInstance
+	} else if !o.cls.CompareAndSwap(false, true) {
+		return ErrServerClosed
 	}
 
+	return o.shutdown(ctx)
+}
+
+func (o *srv) shutdown(ctx context.Context) error {
 	var cnl context.CancelFunc
-	ctx, cnl = context.WithTimeout(ctx, 25*time.Second)
+	ctx, cnl = context.WithTimeout(ctx, o.getDrain().Timeout(10*time.Second)+15*time.Second)
 	defer cnl()
 
 	e := o.StopGone(ctx)
@@ -209,6 +261,10 @@ func (o *srv) Shutdown(ctx context.Context) error {
 }
 
 func (o *srv) SetTLS(enable bool, config libtls.TLSConfig) error {
+	if o.IsClosed() {
+		return ErrServerClosed
+	}
+
 	if !enable {
 		// #nosec
 		o.ssl.Store(&tls.Config{})
@@ -243,6 +299,57 @@ func (o *srv) RegisterFuncInfo(f libsck.FuncInfo) {
 	o.fi.Store(f)
 }
 
+func (o *srv) RegisterFuncInfoContext(f libsck.FuncInfoContext) {
+	if o == nil {
+		return
+	}
+
+	o.fx.Store(f)
+}
+
+func (o *srv) RegisterListenerFactory(f libsck.ListenerFactory) {
+	if o == nil {
+		return
+	}
+
+	o.lf.Store(f)
+}
+
+func (o *srv) getListenerFactory() libsck.ListenerFactory {
+	if i := o.lf.Load(); i != nil {
+		if f, k := i.(libsck.ListenerFactory); k && f != nil {
+			return f
+		}
+	}
+
+	return nil
+}
+
+// RegisterPacketConnFactory does nothing, a stream server opens a listener.
+func (o *srv) RegisterPacketConnFactory(f libsck.PacketConnFactory) {}
+
+func (o *srv) RegisterAcceptFilter(f libsck.AcceptFilter) {
+	if o == nil {
+		return
+	}
+
+	o.ff.Store(f)
+}
+
+// acceptFilter return false if the given connection is rejected by the accept filter.
+func (o *srv) acceptFilter(con net.Conn) bool {
+	if v := o.ff.Load(); v == nil {
+		return true
+	} else if f, k := v.(libsck.AcceptFilter); !k || f == nil {
+		return true
+	} else if e := f(con); e != nil {
+		_ = con.Close()
+		return false
+	}
+
+	return true
+}
+
 func (o *srv) RegisterFuncInfoServer(f libsck.FuncInfoSrv) {
 	if o == nil {
 		return
@@ -251,7 +358,19 @@ func (o *srv) RegisterFuncInfoServer(f libsck.FuncInfoSrv) {
 	o.fs.Store(f)
 }
 
+func (o *srv) RegisterFuncAcceptInfo(f libsck.FuncAcceptInfo) {
+	if o == nil {
+		return
+	}
+
+	o.fa.Store(f)
+}
+
 func (o *srv) RegisterServer(address string) error {
+	if o.IsClosed() {
+		return ErrServerClosed
+	}
+
 	if len(address) < 1 {
 		return ErrInvalidAddress
 	} else if _, err := net.ResolveTCPAddr(libptc.NetworkTCP.Code(), address); err != nil {
@@ -284,6 +403,38 @@ func (o *srv) fctInfo(local, remote net.Addr, state libsck.ConnState) {
 	}
 }
 
+// fctInfoConn send the state connection information to the FuncInfo, and to the FuncInfoContext with the Context of the connection.
+func (o *srv) fctInfoConn(cx libsck.Context, local, remote net.Addr, state libsck.ConnState) {
+	if o == nil {
+		return
+	}
+
+	o.fctInfo(local, remote, state)
+
+	if v := o.fx.Load(); v != nil {
+		if f, k := v.(libsck.FuncInfoContext); k && f != nil {
+			f(cx, local, remote, state)
+		}
+	}
+}
+
+// fctAcceptInfo assign a new id to the given connection, send its accept information and return the id.
+func (o *srv) fctAcceptInfo(ctx context.Context, acc time.Time, con net.Conn) uint64 {
+	if o == nil {
+		return 0
+	}
+
+	var id = o.ci.Add(1)
+
+	if v := o.fa.Load(); v != nil {
+		if f, k := v.(libsck.FuncAcceptInfo); k && f != nil {
+			f(libsck.NewAcceptInfo(ctx, id, acc, con, 0))
+		}
+	}
+
+	return id
+}
+
 func (o *srv) fctInfoSrv(msg string, args ...interface{}) {
 	if o == nil {
 		return
@@ -308,3 +459,162 @@ func (o *srv) getTLS() *tls.Config {
 		return t
 	}
 }
+
+func (o *srv) SetShards(n int) {
+	if o == nil {
+		return
+	}
+
+	o.sh.Store(int64(n))
+}
+
+func (o *srv) getShards() int {
+	return int(o.sh.Load())
+}
+
+// SetDispatch does nothing, a stream server runs a handler by connection.
+func (o *srv) SetDispatch(d libsck.Dispatch) {}
+
+func (o *srv) SetDrain(d libsck.Drain) {
+	if o == nil {
+		return
+	}
+
+	o.dr.Store(d)
+}
+
+func (o *srv) getDrain() libsck.Drain {
+	if i := o.dr.Load(); i != nil {
+		if d, k := i.(libsck.Drain); k {
+			return d
+		}
+	}
+
+	return libsck.Drain{}
+}
+
+// forced return a channel closed when the open connections are force closed at the end of the drain.
+func (o *srv) forced() <-chan struct{} {
+	if i := o.fc.Load(); i != nil {
+		if c, k := i.(chan struct{}); k {
+			return c
+		}
+	}
+
+	return closedChanStruct
+}
+
+// forceClose send the list of the open connections to the drain callback and close them.
+func (o *srv) forceClose() {
+	if i := o.fc.Swap(closedChanStruct); i != nil {
+		if c, k := i.(chan struct{}); k && c != closedChanStruct {
+			close(c)
+		}
+	}
+
+	if l := o.cr.List(); len(l) > 0 {
+		if f := o.getDrain().OnTimeout; f != nil {
+			f(l)
+		}
+
+		o.fctInfoSrv("drain timeout reached, closing %d open connections", len(l))
+		o.cr.CloseAll()
+	}
+}
+
+// drain wait for the connection to end by itself until the drain deadline or the force close of the connections.
+func (o *srv) drain(ctx context.Context) {
+	var d = o.getDrain()
+
+	if d.Deadline <= 0 {
+		return
+	}
+
+	var t = time.NewTimer(d.Deadline)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+	case <-t.C:
+	case <-o.forced():
+	}
+}
+
+func (o *srv) Use(mw ...libsck.Middleware) {
+	o.mdw.Add(mw...)
+}
+
+func (o *srv) RegisterALPN(proto string, h libsck.Handler) {
+	if o == nil || len(proto) < 1 {
+		return
+	}
+
+	// the map is copied on write: the lock prevent concurrent registrations to lose an update.
+	o.am.Lock()
+	defer o.am.Unlock()
+
+	var (
+		old = o.getALPN()
+		lst = make(map[string]libsck.Handler, len(old)+1)
+	)
+
+	for k, v := range old {
+		lst[k] = v
+	}
+
+	if h == nil {
+		delete(lst, proto)
+	} else {
+		lst[proto] = h
+	}
+
+	o.ap.Store(lst)
+}
+
+func (o *srv) getALPN() map[string]libsck.Handler {
+	if i := o.ap.Load(); i != nil {
+		if l, k := i.(map[string]libsck.Handler); k {
+			return l
+		}
+	}
+
+	return nil
+}
+
+// handlerConn return the handler registered for the ALPN protocol negotiated by the TLS handshake
+// of the given connection, or the default handler, wrapped by the registered middlewares.
+func (o *srv) handlerConn(ctx context.Context, con net.Conn, cx libsck.Context) libsck.Handler {
+	var lst = o.getALPN()
+
+	t, k := con.(*tls.Conn)
+
+	if !k || len(lst) < 1 {
+		return o.handler()
+	}
+
+	var cnl context.CancelFunc
+	ctx, cnl = context.WithTimeout(ctx, libsck.DefaultAcceptHandshakeTimeout)
+	defer cnl()
+
+	if e := t.HandshakeContext(ctx); e != nil {
+		o.fctError(e)
+		return o.handler()
+	}
+
+	var p = t.ConnectionState().NegotiatedProtocol
+
+	if len(p) > 0 {
+		cx.SetTag(libsck.ContextTagALPN, p)
+	}
+
+	if h, ok := lst[p]; ok {
+		return o.mdw.Handler(h)
+	}
+
+	return o.handler()
+}
+
+// handler return the handler of the server wrapped by the registered middlewares.
+func (o *srv) handler() libsck.Handler {
+	return o.mdw.Handler(o.hdl)
+}
@@ -0,0 +1,87 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package tcp_test
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	libptc "github.com/nabbar/golib/network/protocol"
+	sckcfg "github.com/nabbar/golib/socket/config"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("socket/server/tcp shards", func() {
+	Context("listening with several shards", func() {
+		It("must open a listener by shard on the same address and serve each connection", func() {
+			var (
+				nbr = new(atomic.Int32)
+				cfg = &sckcfg.ServerConfig{
+					Network: libptc.NetworkTCP,
+					Address: "127.0.0.1:" + strconv.Itoa(GetFreePort(libptc.NetworkTCP)),
+					Shards:  4,
+				}
+			)
+
+			sck, err := cfg.New(nil, Handler)
+			Expect(err).ToNot(HaveOccurred())
+
+			sck.RegisterFuncInfoServer(func(msg string) {
+				if strings.HasPrefix(msg, "starting listening") {
+					nbr.Add(1)
+				}
+			})
+
+			listenClosingServer(sck)
+
+			defer func() {
+				Expect(sck.Close()).ToNot(HaveOccurred())
+			}()
+
+			Expect(nbr.Load()).To(BeEquivalentTo(4))
+
+			for i := 0; i < 20; i++ {
+				con, err := net.Dial(libptc.NetworkTCP.Code(), cfg.Address)
+				Expect(err).ToNot(HaveOccurred())
+
+				_, err = con.Write([]byte("msg-" + strconv.Itoa(i) + "\n"))
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(con.SetReadDeadline(time.Now().Add(5 * time.Second))).ToNot(HaveOccurred())
+				lin, err := bufio.NewReader(con).ReadString('\n')
+				Expect(err).ToNot(HaveOccurred())
+				Expect(lin).To(Equal("msg-" + strconv.Itoa(i) + "\n"))
+				Expect(con.Close()).ToNot(HaveOccurred())
+			}
+		})
+	})
+})
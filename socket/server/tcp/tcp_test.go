@@ -167,6 +167,12 @@ var _ = Describe("socket/server/tcp", func() {
 			Expect(sck.OpenConnections()).To(BeEquivalentTo(1))
 		})
 
+		It("Socket must be having its open connection waiting for incoming data", func() {
+			stt := sck.ConnectionsByState()
+			Expect(stt.Total()).To(BeEquivalentTo(1))
+			Expect(stt.Reading).To(BeEquivalentTo(1))
+		})
+
 		It("Writing on the client must succeed", func() {
 			msg = append([]byte("Hello World"), libsck.EOL)
 			nbr, err = clt.Write(msg)
@@ -190,6 +196,7 @@ var _ = Describe("socket/server/tcp", func() {
 		It("Socket must not having any opened connections", func() {
 			time.Sleep(10 * time.Second) // Adding delay for better testing synchronization
 			Expect(sck.OpenConnections()).To(BeEquivalentTo(0))
+			Expect(sck.ConnectionsByState()).To(BeZero())
 		})
 
 		It("Closing the socket must succeed", func() {
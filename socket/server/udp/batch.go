@@ -0,0 +1,261 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package udp
+
+import (
+	"context"
+	"net"
+	"time"
+
+	libsck "github.com/nabbar/golib/socket"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+// batchConn is the batched io of a udp socket: recvmmsg and sendmmsg on linux,
+// a datagram by system call on the other os. The messages of ipv4 and ipv6 are the same type.
+type batchConn interface {
+	ReadBatch(ms []ipv4.Message, flags int) (int, error)
+	WriteBatch(ms []ipv4.Message, flags int) (int, error)
+}
+
+// newBatchConn returns the batched io of the socket or nil if it's not a *net.UDPConn.
+func newBatchConn(con libsck.PacketConn) batchConn {
+	if u, k := con.(*net.UDPConn); !k {
+		return nil
+	} else if a, k := u.LocalAddr().(*net.UDPAddr); k && a.IP.To4() == nil && len(a.IP) == net.IPv6len {
+		return ipv6.NewPacketConn(u)
+	}
+
+	return ipv4.NewPacketConn(con)
+}
+
+// dispatchBatch reads the datagrams of the socket by batch until the socket is closed and gives them
+// to the batch handler if any or to the workers of the pool.
+func (o *srv) dispatchBatch(ctx context.Context, d libsck.Dispatch, bcn batchConn, con libsck.PacketConn, loc net.Addr, trk *libsck.ConnStateTrack) {
+	var (
+		msg = make([]ipv4.Message, d.BatchSize)
+		fct func(lst []libsck.Datagram) bool
+		gso = d.GSO && d.BatchHandler != nil && gsoSupported(con)
+		dly time.Duration
+	)
+
+	for i := range msg {
+		msg[i].Buffers = [][]byte{make([]byte, d.BufferSize())}
+	}
+
+	if d.BatchHandler != nil {
+		fct = func(lst []libsck.Datagram) bool {
+			trk.Set(libsck.ConnectionHandler)
+			out := d.BatchHandler(lst)
+
+			trk.Set(libsck.ConnectionWrite)
+			o.writeBatch(bcn, out, &gso)
+
+			return true
+		}
+	} else {
+		pool := o.newPool(ctx, d, con, loc)
+		defer pool.Close()
+
+		fct = func(lst []libsck.Datagram) bool {
+			for _, g := range lst {
+				if !pool.Push(ctx, g) {
+					return false
+				}
+			}
+
+			return true
+		}
+	}
+
+	for {
+		trk.Set(libsck.ConnectionRead)
+		n, err := bcn.ReadBatch(msg, 0)
+		trk.Set(libsck.ConnectionNew)
+
+		if err != nil {
+			if !o.readError(ctx, err, &dly) {
+				return
+			}
+
+			continue
+		}
+
+		dly = 0
+
+		var lst = make([]libsck.Datagram, n)
+
+		for i := 0; i < n; i++ {
+			var p = make([]byte, msg[i].N)
+			copy(p, msg[i].Buffers[0][:msg[i].N])
+
+			lst[i] = libsck.Datagram{
+				Data: p,
+				Addr: msg[i].Addr,
+			}
+		}
+
+		if !fct(lst) {
+			return
+		}
+	}
+}
+
+// writeBatch sends the given datagrams with as few system calls as possible, segmented by the kernel
+// if gso is true. The datagrams without address are dropped.
+func (o *srv) writeBatch(bcn batchConn, lst []libsck.Datagram, gso *bool) {
+	var out = make([]libsck.Datagram, 0, len(lst))
+
+	for _, g := range lst {
+		if g.Addr == nil {
+			o.fctError(ErrInvalidSender)
+			continue
+		}
+
+		out = append(out, g)
+	}
+
+	if *gso {
+		var (
+			grp = gsoGroups(out)
+			msg = make([]ipv4.Message, 0, len(grp))
+		)
+
+		for _, l := range grp {
+			msg = append(msg, gsoMessage(l))
+		}
+
+		n, err := sendBatch(bcn, msg)
+
+		if err == nil {
+			return
+		}
+
+		// segmentation refused by the kernel or the network card: the rest is sent datagram by datagram
+		o.fctError(err)
+		*gso = false
+		out = out[:0]
+
+		for _, l := range grp[n:] {
+			out = append(out, l...)
+		}
+	}
+
+	var msg = make([]ipv4.Message, 0, len(out))
+
+	for _, g := range out {
+		msg = append(msg, ipv4.Message{
+			Buffers: [][]byte{g.Data},
+			Addr:    g.Addr,
+		})
+	}
+
+	if _, err := sendBatch(bcn, msg); err != nil {
+		o.fctError(err)
+	}
+}
+
+// sendBatch sends the given messages and returns the number of messages sent before an error.
+func sendBatch(bcn batchConn, msg []ipv4.Message) (int, error) {
+	var s = 0
+
+	for s < len(msg) {
+		n, err := bcn.WriteBatch(msg[s:], 0)
+
+		if err != nil {
+			return s, err
+		} else if n < 1 {
+			return s, nil
+		}
+
+		s += n
+	}
+
+	return s, nil
+}
+
+// gsoGroups groups the consecutive datagrams to a same address with the size of the first one,
+// the last one being possibly shorter, within the limits of a segmented message.
+func gsoGroups(lst []libsck.Datagram) [][]libsck.Datagram {
+	var (
+		res = make([][]libsck.Datagram, 0, len(lst))
+		cur []libsck.Datagram
+		tot int
+	)
+
+	for _, g := range lst {
+		if n := len(cur); n > 0 && n < gsoMaxSegments && len(cur[0].Data) > 0 &&
+			len(cur[n-1].Data) == len(cur[0].Data) && len(g.Data) > 0 && len(g.Data) <= len(cur[0].Data) &&
+			tot+len(g.Data) <= gsoMaxSize && sameAddr(cur[0].Addr, g.Addr) {
+			cur = append(cur, g)
+			tot += len(g.Data)
+			continue
+		}
+
+		if len(cur) > 0 {
+			res = append(res, cur)
+		}
+
+		cur = []libsck.Datagram{g}
+		tot = len(g.Data)
+	}
+
+	if len(cur) > 0 {
+		res = append(res, cur)
+	}
+
+	return res
+}
+
+// gsoMessage returns the message sending the given datagrams, segmented by their size if more than one.
+func gsoMessage(lst []libsck.Datagram) ipv4.Message {
+	var msg = ipv4.Message{
+		Buffers: make([][]byte, 0, len(lst)),
+		Addr:    lst[0].Addr,
+	}
+
+	for _, g := range lst {
+		msg.Buffers = append(msg.Buffers, g.Data)
+	}
+
+	if len(lst) > 1 {
+		msg.OOB = gsoControl(len(lst[0].Data))
+	}
+
+	return msg
+}
+
+func sameAddr(a, b net.Addr) bool {
+	if x, k := a.(*net.UDPAddr); !k {
+		return a.String() == b.String()
+	} else if y, k := b.(*net.UDPAddr); !k {
+		return false
+	} else {
+		return x.Port == y.Port && x.Zone == y.Zone && x.IP.Equal(y.IP)
+	}
+}
@@ -0,0 +1,203 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package udp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	libsck "github.com/nabbar/golib/socket"
+)
+
+const (
+	readDelayMin = 5 * time.Millisecond
+	readDelayMax = time.Second
+)
+
+func (o *srv) getDispatch() libsck.Dispatch {
+	if i := o.dp.Load(); i != nil {
+		if d, k := i.(libsck.Dispatch); k {
+			return d
+		}
+	}
+
+	return libsck.Dispatch{}
+}
+
+// dispatch reads the datagrams of the socket and hands each one to a worker of the pool
+// until the socket is closed.
+func (o *srv) dispatch(ctx context.Context, d libsck.Dispatch, con libsck.PacketConn, loc net.Addr, trk *libsck.ConnStateTrack) {
+	if d.BatchSize > 1 {
+		if b := newBatchConn(con); b != nil {
+			o.dispatchBatch(ctx, d, b, con, loc, trk)
+			return
+		}
+	}
+
+	var (
+		buf  = make([]byte, d.BufferSize())
+		pool = o.newPool(ctx, d, con, loc)
+		dly  time.Duration
+	)
+
+	defer pool.Close()
+
+	for {
+		trk.Set(libsck.ConnectionRead)
+		n, a, err := con.ReadFrom(buf)
+		trk.Set(libsck.ConnectionNew)
+
+		if err != nil {
+			if !o.readError(ctx, err, &dly) {
+				return
+			}
+
+			continue
+		}
+
+		dly = 0
+
+		var p = make([]byte, n)
+		copy(p, buf[:n])
+
+		if !pool.Push(ctx, libsck.Datagram{Data: p, Addr: a}) {
+			return
+		}
+	}
+}
+
+// readError reports the given read error and waits before the next read, the delay being doubled on each
+// consecutive error from readDelayMin up to readDelayMax, to not spin on a persistent error.
+// It returns false to stop reading if the socket is closed or the context done.
+func (o *srv) readError(ctx context.Context, err error, dly *time.Duration) bool {
+	if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+		return false
+	}
+
+	o.fctError(err)
+
+	if *dly < readDelayMin {
+		*dly = readDelayMin
+	} else if *dly *= 2; *dly > readDelayMax {
+		*dly = readDelayMax
+	}
+
+	t := time.NewTimer(*dly)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+// newPool starts the workers running the handler for each datagram.
+func (o *srv) newPool(ctx context.Context, d libsck.Dispatch, con libsck.PacketConn, loc net.Addr) *libsck.DispatchPool {
+	var hdl = o.handler()
+
+	return libsck.NewDispatchPool(d, func(g libsck.Datagram) {
+		t := o.cs.Track()
+		defer t.Close()
+
+		r, w := o.getDatagramReadWriter(ctx, con, loc, g, t)
+		t.Set(libsck.ConnectionHandler)
+		hdl(r, w)
+	})
+}
+
+// getDatagramReadWriter returns the reader of the given datagram and the writer replying to its sender.
+// Closing them does not close the socket shared by all the workers.
+func (o *srv) getDatagramReadWriter(ctx context.Context, con libsck.PacketConn, loc net.Addr, g libsck.Datagram, trk *libsck.ConnStateTrack) (libsck.Reader, libsck.Writer) {
+	var (
+		cx  = libsck.NewContext(0)
+		buf = bytes.NewReader(g.Data)
+		rem = g.Addr
+		cls sync.Once
+	)
+
+	if rem == nil {
+		rem = &net.UDPAddr{}
+	}
+
+	fctClose := func() error {
+		cls.Do(func() {
+			o.fctInfoConn(cx, loc, rem, libsck.ConnectionClose)
+		})
+		return nil
+	}
+
+	fctCheck := func() bool {
+		return ctx.Err() == nil
+	}
+
+	fctDone := func() <-chan struct{} {
+		return ctx.Done()
+	}
+
+	rdr := libsck.NewReader(
+		func(p []byte) (n int, err error) {
+			if ctx.Err() != nil {
+				return 0, ctx.Err()
+			}
+
+			o.fctInfoConn(cx, loc, rem, libsck.ConnectionRead)
+			return buf.Read(p)
+		},
+		fctClose,
+		fctCheck,
+		fctDone,
+	)
+
+	wrt := libsck.NewWriter(
+		func(p []byte) (n int, err error) {
+			if ctx.Err() != nil {
+				return 0, ctx.Err()
+			} else if g.Addr == nil {
+				return 0, ErrInvalidSender
+			}
+
+			trk.Set(libsck.ConnectionWrite)
+			defer trk.Set(libsck.ConnectionHandler)
+
+			o.fctInfoConn(cx, loc, rem, libsck.ConnectionWrite)
+			return con.WriteTo(p, g.Addr)
+		},
+		fctClose,
+		fctCheck,
+		fctDone,
+	)
+
+	libsck.SetContext(cx, rdr, wrt)
+
+	return rdr, wrt
+}
@@ -0,0 +1,305 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package udp_test
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	libptc "github.com/nabbar/golib/network/protocol"
+	libsck "github.com/nabbar/golib/socket"
+	sckcfg "github.com/nabbar/golib/socket/config"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func newDispatchServer(d libsck.Dispatch, h libsck.Handler, shards int, fct libsck.FuncInfoSrv) (libsck.Server, string) {
+	l, err := net.ListenUDP(libptc.NetworkUDP.Code(), &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	Expect(err).ToNot(HaveOccurred())
+
+	var (
+		a   = l.LocalAddr().String()
+		cfg = &sckcfg.ServerConfig{
+			Network:  libptc.NetworkUDP,
+			Address:  a,
+			Dispatch: d,
+			Shards:   shards,
+		}
+	)
+
+	Expect(l.Close()).ToNot(HaveOccurred())
+
+	sck, err := cfg.New(nil, h)
+	Expect(err).ToNot(HaveOccurred())
+
+	if fct != nil {
+		sck.RegisterFuncInfoServer(fct)
+	}
+
+	go func() {
+		defer GinkgoRecover()
+		_ = sck.Listen(ctx)
+	}()
+
+	Eventually(sck.IsRunning, 5*time.Second, 10*time.Millisecond).Should(BeTrue())
+
+	return sck, a
+}
+
+func dialDispatch(a string) *net.UDPConn {
+	r, err := net.ResolveUDPAddr(libptc.NetworkUDP.Code(), a)
+	Expect(err).ToNot(HaveOccurred())
+
+	c, err := net.DialUDP(libptc.NetworkUDP.Code(), nil, r)
+	Expect(err).ToNot(HaveOccurred())
+
+	return c
+}
+
+var _ = Describe("socket/server/udp dispatch", func() {
+	Context("dispatching datagrams to a pool of workers", func() {
+		It("must run the handlers concurrently and reply to each sender", func() {
+			var (
+				cur = new(atomic.Int32)
+				max = new(atomic.Int32)
+			)
+
+			sck, a := newDispatchServer(libsck.Dispatch{Workers: 4}, func(r libsck.Reader, w libsck.Writer) {
+				defer func() {
+					_ = r.Close()
+					_ = w.Close()
+				}()
+
+				n := cur.Add(1)
+				defer cur.Add(-1)
+
+				for {
+					m := max.Load()
+					if n <= m || max.CompareAndSwap(m, n) {
+						break
+					}
+				}
+
+				time.Sleep(100 * time.Millisecond)
+				_, _ = io.Copy(w, r)
+			}, 0, nil)
+
+			defer func() {
+				Expect(sck.Close()).ToNot(HaveOccurred())
+			}()
+
+			var cli = make([]*net.UDPConn, 4)
+
+			for i := range cli {
+				cli[i] = dialDispatch(a)
+				_, err := cli[i].Write([]byte(fmt.Sprintf("msg-%d", i)))
+				Expect(err).ToNot(HaveOccurred())
+			}
+
+			for i, c := range cli {
+				var buf = make([]byte, 64)
+
+				Expect(c.SetReadDeadline(time.Now().Add(5 * time.Second))).ToNot(HaveOccurred())
+				n, err := c.Read(buf)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(string(buf[:n])).To(Equal(fmt.Sprintf("msg-%d", i)))
+				Expect(c.Close()).ToNot(HaveOccurred())
+			}
+
+			Expect(max.Load()).To(BeNumerically(">", 1))
+		})
+
+		It("must keep the datagrams of a sender in order", func() {
+			sck, a := newDispatchServer(libsck.Dispatch{Workers: 4, PerSender: true}, Handler, 0, nil)
+
+			defer func() {
+				Expect(sck.Close()).ToNot(HaveOccurred())
+			}()
+
+			var c = dialDispatch(a)
+
+			defer func() {
+				_ = c.Close()
+			}()
+
+			for i := 0; i < 50; i++ {
+				_, err := c.Write([]byte(strconv.Itoa(i)))
+				Expect(err).ToNot(HaveOccurred())
+			}
+
+			for i := 0; i < 50; i++ {
+				var buf = make([]byte, 64)
+
+				Expect(c.SetReadDeadline(time.Now().Add(5 * time.Second))).ToNot(HaveOccurred())
+				n, err := c.Read(buf)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(string(buf[:n])).To(Equal(strconv.Itoa(i)))
+			}
+		})
+	})
+
+	Context("receiving datagrams by batch", func() {
+		It("must give the batches to the batch handler and send its replies", func() {
+			var nbr = new(atomic.Int32)
+
+			sck, a := newDispatchServer(libsck.Dispatch{
+				BatchSize: 16,
+				BatchHandler: func(in []libsck.Datagram) []libsck.Datagram {
+					nbr.Add(int32(len(in)))
+
+					for i := range in {
+						in[i].Data = append([]byte("re:"), in[i].Data...)
+					}
+
+					return in
+				},
+			}, Handler, 0, nil)
+
+			defer func() {
+				Expect(sck.Close()).ToNot(HaveOccurred())
+			}()
+
+			var c = dialDispatch(a)
+
+			defer func() {
+				_ = c.Close()
+			}()
+
+			for i := 0; i < 20; i++ {
+				_, err := c.Write([]byte(strconv.Itoa(i)))
+				Expect(err).ToNot(HaveOccurred())
+			}
+
+			for i := 0; i < 20; i++ {
+				var buf = make([]byte, 64)
+
+				Expect(c.SetReadDeadline(time.Now().Add(5 * time.Second))).ToNot(HaveOccurred())
+				n, err := c.Read(buf)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(string(buf[:n])).To(Equal("re:" + strconv.Itoa(i)))
+			}
+
+			Expect(nbr.Load()).To(BeEquivalentTo(20))
+		})
+
+		It("must send the replies of a same size to a sender as segmented datagrams with gso", func() {
+			sck, a := newDispatchServer(libsck.Dispatch{
+				BatchSize: 16,
+				GSO:       true,
+				BatchHandler: func(in []libsck.Datagram) []libsck.Datagram {
+					var out = make([]libsck.Datagram, 0)
+
+					for _, g := range in {
+						for i := 0; i < 5; i++ {
+							out = append(out, libsck.Datagram{
+								Data: []byte(fmt.Sprintf("%s:%d:%s", g.Data, i, strings.Repeat("x", 32))),
+								Addr: g.Addr,
+							})
+						}
+
+						out = append(out, libsck.Datagram{
+							Data: append([]byte("end:"), g.Data...),
+							Addr: g.Addr,
+						})
+					}
+
+					return out
+				},
+			}, Handler, 0, nil)
+
+			var ers = new(atomic.Int32)
+
+			// a segmented send refused by the kernel is reported before sending the datagrams one by one
+			sck.RegisterFuncError(func(errs ...error) {
+				ers.Add(int32(len(errs)))
+			})
+
+			defer func() {
+				Expect(sck.Close()).ToNot(HaveOccurred())
+			}()
+
+			var c = dialDispatch(a)
+
+			defer func() {
+				_ = c.Close()
+			}()
+
+			for i := 0; i < 3; i++ {
+				_, err := c.Write([]byte(strconv.Itoa(i)))
+				Expect(err).ToNot(HaveOccurred())
+
+				for j := 0; j < 5; j++ {
+					var buf = make([]byte, 1024)
+
+					Expect(c.SetReadDeadline(time.Now().Add(5 * time.Second))).ToNot(HaveOccurred())
+					n, err := c.Read(buf)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(string(buf[:n])).To(Equal(fmt.Sprintf("%d:%d:%s", i, j, strings.Repeat("x", 32))))
+				}
+
+				var buf = make([]byte, 1024)
+
+				Expect(c.SetReadDeadline(time.Now().Add(5 * time.Second))).ToNot(HaveOccurred())
+				n, err := c.Read(buf)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(string(buf[:n])).To(Equal("end:" + strconv.Itoa(i)))
+			}
+
+			Expect(ers.Load()).To(BeZero())
+		})
+
+		It("must give the datagrams to the handler without batch handler", func() {
+			sck, a := newDispatchServer(libsck.Dispatch{BatchSize: 8}, Handler, 0, nil)
+
+			defer func() {
+				Expect(sck.Close()).ToNot(HaveOccurred())
+			}()
+
+			var (
+				c   = dialDispatch(a)
+				buf = make([]byte, 64)
+			)
+
+			defer func() {
+				_ = c.Close()
+			}()
+
+			_, err := c.Write([]byte("hello"))
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(c.SetReadDeadline(time.Now().Add(5 * time.Second))).ToNot(HaveOccurred())
+			n, err := c.Read(buf)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(buf[:n])).To(Equal("hello"))
+		})
+	})
+})
@@ -36,4 +36,5 @@ var (
 	ErrShutdownTimeout = fmt.Errorf("timeout on stopping socket")
 	ErrGoneTimeout     = fmt.Errorf("timeout on closing connections")
 	ErrInvalidInstance = fmt.Errorf("invalid socket instance")
+	ErrInvalidSender   = fmt.Errorf("invalid datagram sender address")
 )
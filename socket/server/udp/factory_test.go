@@ -0,0 +1,161 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package udp_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"time"
+
+	libptc "github.com/nabbar/golib/network/protocol"
+	libsck "github.com/nabbar/golib/socket"
+	sckcfg "github.com/nabbar/golib/socket/config"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// wrapConn hides the *net.UDPConn type as a custom packet socket.
+type wrapConn struct {
+	*net.UDPConn
+}
+
+// errConn is a packet socket failing each read.
+type errConn struct {
+	*net.UDPConn
+}
+
+func (c *errConn) ReadFrom(_ []byte) (int, net.Addr, error) {
+	return 0, nil, errRead
+}
+
+var errRead = errors.New("read failure")
+
+var _ = Describe("socket/server/udp packet conn factory", func() {
+	Context("using a custom packet conn factory", func() {
+		It("must serve the datagrams of the custom socket, even by batch", func() {
+			var (
+				nbr = new(atomic.Int32)
+				adr string
+				cfg = &sckcfg.ServerConfig{
+					Network:  libptc.NetworkUDP,
+					Address:  "127.0.0.1:0",
+					Dispatch: libsck.Dispatch{BatchSize: 8},
+					PacketConn: func(ctx context.Context, network, address string) (libsck.PacketConn, error) {
+						nbr.Add(1)
+
+						c, e := net.ListenUDP(network, &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+						if e != nil {
+							return nil, e
+						}
+
+						adr = c.LocalAddr().String()
+						return &wrapConn{UDPConn: c}, nil
+					},
+				}
+			)
+
+			sck, err := cfg.New(nil, Handler)
+			Expect(err).ToNot(HaveOccurred())
+
+			go func() {
+				defer GinkgoRecover()
+				_ = sck.Listen(ctx)
+			}()
+
+			Eventually(sck.IsRunning, 5*time.Second, 10*time.Millisecond).Should(BeTrue())
+
+			defer func() {
+				Expect(sck.Close()).ToNot(HaveOccurred())
+			}()
+
+			Expect(nbr.Load()).To(BeEquivalentTo(1))
+
+			var (
+				c   = dialDispatch(adr)
+				buf = make([]byte, 64)
+			)
+
+			defer func() {
+				_ = c.Close()
+			}()
+
+			_, err = c.Write([]byte("hello"))
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(c.SetReadDeadline(time.Now().Add(5 * time.Second))).ToNot(HaveOccurred())
+			n, err := c.Read(buf)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(buf[:n])).To(Equal("hello"))
+		})
+
+		It("must wait between the reads failing instead of spinning", func() {
+			var (
+				nbr = new(atomic.Int32)
+				cfg = &sckcfg.ServerConfig{
+					Network:  libptc.NetworkUDP,
+					Address:  "127.0.0.1:0",
+					Dispatch: libsck.Dispatch{Workers: 2},
+					PacketConn: func(ctx context.Context, network, address string) (libsck.PacketConn, error) {
+						c, e := net.ListenUDP(network, &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+						if e != nil {
+							return nil, e
+						}
+
+						return &errConn{UDPConn: c}, nil
+					},
+				}
+			)
+
+			sck, err := cfg.New(nil, Handler)
+			Expect(err).ToNot(HaveOccurred())
+
+			sck.RegisterFuncError(func(errs ...error) {
+				for _, e := range errs {
+					if errors.Is(e, errRead) {
+						nbr.Add(1)
+					}
+				}
+			})
+
+			go func() {
+				defer GinkgoRecover()
+				_ = sck.Listen(ctx)
+			}()
+
+			Eventually(sck.IsRunning, 5*time.Second, 10*time.Millisecond).Should(BeTrue())
+			Eventually(nbr.Load, 5*time.Second, 10*time.Millisecond).Should(BeNumerically(">", 0))
+
+			time.Sleep(300 * time.Millisecond)
+			Expect(sck.Close()).ToNot(HaveOccurred())
+
+			// 5ms doubled on each failure: less than ten reads in 300ms, thousands without delay
+			Expect(nbr.Load()).To(BeNumerically("<", 10))
+		})
+	})
+})
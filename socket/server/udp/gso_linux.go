@@ -0,0 +1,87 @@
+//go:build linux
+// +build linux
+
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package udp
+
+import (
+	"encoding/binary"
+	"net"
+	"unsafe"
+
+	libsck "github.com/nabbar/golib/socket"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// gsoMaxSegments is the max number of segments of a message (UDP_MAX_SEGMENTS of the kernel before 5.x).
+	gsoMaxSegments = 64
+	// gsoMaxSize is the max size of the payload of a segmented message.
+	gsoMaxSize = 65507
+)
+
+// gsoSupported returns true if the kernel supports the UDP_SEGMENT option on the socket.
+func gsoSupported(con libsck.PacketConn) bool {
+	var (
+		ok bool
+		u  *net.UDPConn
+	)
+
+	if c, k := con.(*net.UDPConn); !k {
+		return false
+	} else {
+		u = c
+	}
+
+	if r, e := u.SyscallConn(); e != nil {
+		return false
+	} else if e = r.Control(func(fd uintptr) {
+		_, err := unix.GetsockoptInt(int(fd), unix.IPPROTO_UDP, unix.UDP_SEGMENT)
+		ok = err == nil
+	}); e != nil {
+		return false
+	}
+
+	return ok
+}
+
+// gsoControl returns the control message segmenting a message by the given size.
+func gsoControl(size int) []byte {
+	var b = make([]byte, unix.CmsgSpace(2))
+
+	// #nosec
+	h := (*unix.Cmsghdr)(unsafe.Pointer(&b[0]))
+	h.Level = unix.IPPROTO_UDP
+	h.Type = unix.UDP_SEGMENT
+	h.SetLen(unix.CmsgLen(2))
+
+	// #nosec
+	binary.NativeEndian.PutUint16(b[unix.CmsgLen(0):], uint16(size))
+
+	return b
+}
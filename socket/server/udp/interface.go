@@ -47,12 +47,21 @@ func New(u libsck.UpdateConn, h libsck.Handler) ServerTcp {
 	return &srv{
 		upd: u,
 		hdl: h,
+		mdw: new(libsck.MiddlewareList),
+		cs:  new(libsck.ConnStateCounter),
 		msg: c,
 		stp: s,
 		run: new(atomic.Bool),
+		cls: new(atomic.Bool),
 		fe:  new(atomic.Value),
 		fi:  new(atomic.Value),
 		fs:  new(atomic.Value),
+		fa:  new(atomic.Value),
+		fx:  new(atomic.Value),
+		ff:  new(atomic.Value),
+		pf:  new(atomic.Value),
+		sh:  new(atomic.Int64),
+		dp:  new(atomic.Value),
 		ad:  new(atomic.Value),
 	}
 }
@@ -45,7 +45,7 @@ func (o *srv) getAddress() string {
 	return ""
 }
 
-func (o *srv) getListen(addr string) (*net.UDPAddr, *net.UDPConn, error) {
+func (o *srv) getListen(addr string, shard bool) (*net.UDPAddr, libsck.PacketConn, error) {
 	var (
 		adr *net.UDPAddr
 		lis *net.UDPConn
@@ -54,31 +54,98 @@ func (o *srv) getListen(addr string) (*net.UDPAddr, *net.UDPConn, error) {
 
 	if adr, err = net.ResolveUDPAddr(libptc.NetworkUDP.Code(), addr); err != nil {
 		return nil, nil, err
+	} else if f := o.getPacketConnFactory(); f != nil {
+		var c libsck.PacketConn
+
+		if c, err = f(context.Background(), libptc.NetworkUDP.Code(), addr); err != nil {
+			return nil, nil, err
+		}
+
+		o.fctInfoSrv("starting listening socket '%s %s'", libptc.NetworkUDP.String(), addr)
+		return adr, c, nil
+	} else if shard {
+		if lis, err = o.getListenShard(adr); err != nil {
+			return nil, nil, err
+		}
 	} else if lis, err = net.ListenUDP(libptc.NetworkUDP.Code(), adr); err != nil {
 		if lis != nil {
 			_ = lis.Close()
 		}
 		return nil, nil, err
-	} else {
-		o.fctInfoSrv("starting listening socket '%s %s'", libptc.NetworkUDP.String(), addr)
 	}
 
+	o.fctInfoSrv("starting listening socket '%s %s'", libptc.NetworkUDP.String(), addr)
+
 	return adr, lis, nil
 }
 
+func (o *srv) getListenShard(adr *net.UDPAddr) (*net.UDPConn, error) {
+	if c, e := libsck.ListenPacketReusePort(context.Background(), libptc.NetworkUDP.Code(), adr.String()); e != nil {
+		return nil, e
+	} else if u, k := c.(*net.UDPConn); !k {
+		_ = c.Close()
+		return nil, ErrInvalidInstance
+	} else {
+		return u, nil
+	}
+}
+
+// getListeners opens the socket of each shard, sharing the address with SO_REUSEPORT if more than one.
+func (o *srv) getListeners(addr string) (*net.UDPAddr, []libsck.PacketConn, error) {
+	var (
+		n = o.getShards()
+		r = make([]libsck.PacketConn, 0, max(n, 1))
+	)
+
+	if n < 2 {
+		if a, c, e := o.getListen(addr, false); e != nil {
+			return nil, nil, e
+		} else {
+			return a, append(r, c), nil
+		}
+	}
+
+	var loc *net.UDPAddr
+
+	for i := 0; i < n; i++ {
+		a, c, e := o.getListen(addr, true)
+
+		if e != nil {
+			for _, x := range r {
+				_ = x.Close()
+			}
+
+			return nil, nil, e
+		}
+
+		// the next shards use the port given to the first one
+		if i == 0 {
+			loc = a
+			addr = c.LocalAddr().String()
+		}
+
+		r = append(r, c)
+	}
+
+	return loc, r, nil
+}
+
 func (o *srv) Listen(ctx context.Context) error {
 	var (
 		e error
 		s = new(atomic.Bool)
 		a = o.getAddress()
+		f = make([]func(), 0)
 
 		loc *net.UDPAddr
-		con *net.UDPConn
+		lst []libsck.PacketConn
 		cnl context.CancelFunc
-		cor libsck.Reader
-		cow libsck.Writer
 	)
 
+	if o.IsClosed() {
+		return ErrServerClosed
+	}
+
 	s.Store(false)
 
 	if len(a) == 0 {
@@ -87,17 +154,12 @@ func (o *srv) Listen(ctx context.Context) error {
 	} else if o.hdl == nil {
 		o.fctError(ErrInvalidInstance)
 		return ErrInvalidHandler
-	} else if loc, con, e = o.getListen(a); e != nil {
+	} else if loc, lst, e = o.getListeners(a); e != nil {
 		o.fctError(e)
 		return e
 	}
 
-	if o.upd != nil {
-		o.upd(con)
-	}
-
 	ctx, cnl = context.WithCancel(ctx)
-	cor, cow = o.getReadWriter(ctx, con, loc)
 
 	o.stp.Store(make(chan struct{}))
 	o.run.Store(true)
@@ -106,18 +168,28 @@ func (o *srv) Listen(ctx context.Context) error {
 		// cancel context for connection
 		cnl()
 
-		// send info about connection closing
-		o.fctInfo(loc, &net.UDPAddr{}, libsck.ConnectionClose)
 		o.fctInfoSrv("closing listen socket '%s %s'", libptc.NetworkUDP.String(), a)
 
-		// close connection
-		_ = con.Close()
+		// close the socket of each shard
+		for _, c := range lst {
+			_ = c.Close()
+		}
+
+		for _, c := range f {
+			c()
+		}
 
 		o.run.Store(false)
 	}()
 
-	// get handler or exit if nil
-	go o.hdl(cor, cow)
+	// the server could have been closed before the stop channel has been replaced
+	if o.IsClosed() {
+		return ErrServerClosed
+	}
+
+	for _, c := range lst {
+		f = append(f, o.serve(ctx, loc, c))
+	}
 
 	for {
 		select {
@@ -129,7 +201,39 @@ func (o *srv) Listen(ctx context.Context) error {
 	}
 }
 
-func (o *srv) getReadWriter(ctx context.Context, con *net.UDPConn, loc net.Addr) (libsck.Reader, libsck.Writer) {
+// serve starts the handler of the socket of a shard and returns the function to call once the socket is closed.
+func (o *srv) serve(ctx context.Context, loc *net.UDPAddr, con libsck.PacketConn) func() {
+	if o.upd != nil {
+		o.upd(con)
+	}
+
+	trk := o.cs.Track()
+	cx := libsck.NewContext(0)
+	cor, cow := o.getReadWriter(ctx, con, loc, trk, cx)
+
+	if d := o.getDispatch(); d.Enabled() {
+		// dispatch each datagram to a pool of handlers
+		go o.dispatch(ctx, d, con, loc, trk)
+	} else {
+		// get handler or exit if nil
+		go func() {
+			o.fctInfoConn(cx, loc, &net.UDPAddr{}, libsck.ConnectionHandler)
+			trk.Set(libsck.ConnectionHandler)
+
+			o.handler()(cor, cow)
+
+			trk.Set(libsck.ConnectionNew)
+		}()
+	}
+
+	return func() {
+		// send info about connection closing
+		o.fctInfoConn(cx, loc, &net.UDPAddr{}, libsck.ConnectionClose)
+		trk.Close()
+	}
+}
+
+func (o *srv) getReadWriter(ctx context.Context, con libsck.PacketConn, loc net.Addr, trk *libsck.ConnStateTrack, cx libsck.Context) (libsck.Reader, libsck.Writer) {
 	var (
 		re = &net.UDPAddr{}
 		ra = new(atomic.Value)
@@ -145,7 +249,7 @@ func (o *srv) getReadWriter(ctx context.Context, con *net.UDPConn, loc net.Addr)
 	ra.Store(re)
 
 	fctClose := func() error {
-		o.fctInfo(loc, fg(), libsck.ConnectionClose)
+		o.fctInfoConn(cx, loc, fg(), libsck.ConnectionClose)
 		return libsck.ErrorFilter(con.Close())
 	}
 
@@ -157,7 +261,9 @@ func (o *srv) getReadWriter(ctx context.Context, con *net.UDPConn, loc net.Addr)
 			}
 
 			var a net.Addr
+			trk.Set(libsck.ConnectionRead)
 			n, a, err = con.ReadFrom(p)
+			trk.Set(libsck.ConnectionHandler)
 
 			if a != nil {
 				ra.Store(a)
@@ -165,7 +271,7 @@ func (o *srv) getReadWriter(ctx context.Context, con *net.UDPConn, loc net.Addr)
 				ra.Store(re)
 			}
 
-			o.fctInfo(loc, fg(), libsck.ConnectionRead)
+			o.fctInfoConn(cx, loc, fg(), libsck.ConnectionRead)
 			return n, err
 		},
 		fctClose,
@@ -194,12 +300,15 @@ func (o *srv) getReadWriter(ctx context.Context, con *net.UDPConn, loc net.Addr)
 				return 0, ctx.Err()
 			}
 
+			trk.Set(libsck.ConnectionWrite)
+			defer trk.Set(libsck.ConnectionHandler)
+
 			if a := fg(); a != nil && a != re {
-				o.fctInfo(loc, a, libsck.ConnectionWrite)
+				o.fctInfoConn(cx, loc, a, libsck.ConnectionWrite)
 				return con.WriteTo(p, a)
 			}
 
-			o.fctInfo(loc, fg(), libsck.ConnectionWrite)
+			o.fctInfoConn(cx, loc, fg(), libsck.ConnectionWrite)
 			return con.Write(p)
 		},
 		fctClose,
@@ -222,5 +331,7 @@ func (o *srv) getReadWriter(ctx context.Context, con *net.UDPConn, loc net.Addr)
 		},
 	)
 
+	libsck.SetContext(cx, rdr, wrt)
+
 	return rdr, wrt
 }
@@ -53,12 +53,22 @@ type srv struct {
 	msg *atomic.Value     // chan []byte
 	stp *atomic.Value     // chan struct{}
 	run *atomic.Bool      // is Running
+	cls *atomic.Bool      // is Closed
 
 	fe *atomic.Value // function error
 	fi *atomic.Value // function info
 	fs *atomic.Value // function info server
+	fa *atomic.Value // function accept info
+	fx *atomic.Value // function info context
+	ff *atomic.Value // function accept filter
+	pf *atomic.Value // packet conn factory
+	sh *atomic.Int64 // number of shards
+	dp *atomic.Value // dispatch
 
 	ad *atomic.Value // Server address url
+
+	mdw *libsck.MiddlewareList   // middlewares
+	cs  *libsck.ConnStateCounter // socket by state
 }
 
 func (o *srv) OpenConnections() int64 {
@@ -69,6 +79,10 @@ func (o *srv) OpenConnections() int64 {
 	return 0
 }
 
+func (o *srv) ConnectionsByState() libsck.ConnectionsByState {
+	return o.cs.Get()
+}
+
 func (o *srv) IsRunning() bool {
 	return o.run.Load()
 }
@@ -95,8 +109,24 @@ func (o *srv) Gone() <-chan struct{} {
 	return closedChanStruct
 }
 
+// Close shutdown the server and release it. Calling Close on an already closed server does nothing.
 func (o *srv) Close() error {
-	return o.Shutdown(context.Background())
+	if o == nil {
+		return ErrInvalidInstance
+	} else if !o.cls.CompareAndSwap(false, true) {
+		return nil
+	}
+
+	return o.shutdown(context.Background())
+}
+
+// IsClosed returns true if the server has been closed by Close or Shutdown.
+func (o *srv) IsClosed() bool {
+	if o == nil {
+		return true
+	}
+
+	return o.cls.Load()
 }
 
 func (o *srv) StopListen(ctx context.Context) error {
@@ -104,12 +134,11 @@ func (o *srv) StopListen(ctx context.Context) error {
 		return ErrInvalidInstance
 	}
 
-	if i := o.stp.Load(); i != nil {
+	if i := o.stp.Swap(closedChanStruct); i != nil {
 		if c, k := i.(chan struct{}); k && c != closedChanStruct {
 			close(c)
 		}
 	}
-	o.stp.Store(closedChanStruct)
 
 	var (
 		tck = time.NewTicker(5 * time.Millisecond)
@@ -137,11 +166,18 @@ func (o *srv) StopListen(ctx context.Context) error {
 
 }
 
+// Shutdown stops the server and release it. Calling Shutdown on an already closed server returns ErrServerClosed.
 func (o *srv) Shutdown(ctx context.Context) error {
 	if o == nil {
 		return ErrInvalidInstance
+	} else if !o.cls.CompareAndSwap(false, true) {
+		return ErrServerClosed
 	}
 
+	return o.shutdown(ctx)
+}
+
+func (o *srv) shutdown(ctx context.Context) error {
 	var cnl context.CancelFunc
 	ctx, cnl = context.WithTimeout(ctx, 25*time.Second)
 	defer cnl()
@@ -149,6 +185,10 @@ func (o *srv) Shutdown(ctx context.Context) error {
 }
 
 func (o *srv) SetTLS(enable bool, config libtls.TLSConfig) error {
+	if o.IsClosed() {
+		return ErrServerClosed
+	}
+
 	return nil
 }
 
@@ -168,6 +208,44 @@ func (o *srv) RegisterFuncInfo(f libsck.FuncInfo) {
 	o.fi.Store(f)
 }
 
+func (o *srv) RegisterFuncInfoContext(f libsck.FuncInfoContext) {
+	if o == nil {
+		return
+	}
+
+	o.fx.Store(f)
+}
+
+// RegisterListenerFactory does nothing, a datagram server opens a packet socket.
+func (o *srv) RegisterListenerFactory(f libsck.ListenerFactory) {}
+
+func (o *srv) RegisterPacketConnFactory(f libsck.PacketConnFactory) {
+	if o == nil {
+		return
+	}
+
+	o.pf.Store(f)
+}
+
+func (o *srv) getPacketConnFactory() libsck.PacketConnFactory {
+	if i := o.pf.Load(); i != nil {
+		if f, k := i.(libsck.PacketConnFactory); k && f != nil {
+			return f
+		}
+	}
+
+	return nil
+}
+
+// RegisterAcceptFilter registers the function, never called by a datagram server without accepted connection.
+func (o *srv) RegisterAcceptFilter(f libsck.AcceptFilter) {
+	if o == nil {
+		return
+	}
+
+	o.ff.Store(f)
+}
+
 func (o *srv) RegisterFuncInfoServer(f libsck.FuncInfoSrv) {
 	if o == nil {
 		return
@@ -176,7 +254,43 @@ func (o *srv) RegisterFuncInfoServer(f libsck.FuncInfoSrv) {
 	o.fs.Store(f)
 }
 
+// RegisterFuncAcceptInfo registers the function, never called by a datagram server without accepted connection.
+func (o *srv) RegisterFuncAcceptInfo(f libsck.FuncAcceptInfo) {
+	if o == nil {
+		return
+	}
+
+	o.fa.Store(f)
+}
+
+func (o *srv) SetShards(n int) {
+	if o == nil {
+		return
+	}
+
+	o.sh.Store(int64(n))
+}
+
+func (o *srv) getShards() int {
+	return int(o.sh.Load())
+}
+
+func (o *srv) SetDispatch(d libsck.Dispatch) {
+	if o == nil {
+		return
+	}
+
+	o.dp.Store(d)
+}
+
+// SetDrain does nothing, a datagram server has no accepted connection to drain.
+func (o *srv) SetDrain(d libsck.Drain) {}
+
 func (o *srv) RegisterServer(address string) error {
+	if o.IsClosed() {
+		return ErrServerClosed
+	}
+
 	if len(address) < 1 {
 		return ErrInvalidAddress
 	} else if _, err := net.ResolveUDPAddr(libptc.NetworkUDP.Code(), address); err != nil {
@@ -209,6 +323,21 @@ func (o *srv) fctInfo(local, remote net.Addr, state libsck.ConnState) {
 	}
 }
 
+// fctInfoConn send the state connection information to the FuncInfo, and to the FuncInfoContext with the Context of the connection.
+func (o *srv) fctInfoConn(cx libsck.Context, local, remote net.Addr, state libsck.ConnState) {
+	if o == nil {
+		return
+	}
+
+	o.fctInfo(local, remote, state)
+
+	if v := o.fx.Load(); v != nil {
+		if f, k := v.(libsck.FuncInfoContext); k && f != nil {
+			f(cx, local, remote, state)
+		}
+	}
+}
+
 func (o *srv) fctInfoSrv(msg string, args ...interface{}) {
 	if o == nil {
 		return
@@ -219,3 +348,12 @@ func (o *srv) fctInfoSrv(msg string, args ...interface{}) {
 		v.(libsck.FuncInfoSrv)(fmt.Sprintf(msg, args...))
 	}
 }
+
+func (o *srv) Use(mw ...libsck.Middleware) {
+	o.mdw.Add(mw...)
+}
+
+// handler return the handler of the server wrapped by the registered middlewares.
+func (o *srv) handler() libsck.Handler {
+	return o.mdw.Handler(o.hdl)
+}
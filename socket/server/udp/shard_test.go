@@ -0,0 +1,74 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package udp_test
+
+import (
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	libsck "github.com/nabbar/golib/socket"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("socket/server/udp shards", func() {
+	Context("listening with several shards", func() {
+		It("must open a socket by shard on the same address and reply to each sender", func() {
+			var nbr = new(atomic.Int32)
+
+			sck, a := newDispatchServer(libsck.Dispatch{}, Handler, 4, func(msg string) {
+				if strings.HasPrefix(msg, "starting listening") {
+					nbr.Add(1)
+				}
+			})
+
+			defer func() {
+				Expect(sck.Close()).ToNot(HaveOccurred())
+			}()
+
+			Expect(nbr.Load()).To(BeEquivalentTo(4))
+
+			for i := 0; i < 20; i++ {
+				var (
+					c   = dialDispatch(a)
+					buf = make([]byte, 64)
+				)
+
+				_, err := c.Write([]byte("msg-" + strconv.Itoa(i)))
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(c.SetReadDeadline(time.Now().Add(5 * time.Second))).ToNot(HaveOccurred())
+				n, err := c.Read(buf)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(string(buf[:n])).To(Equal("msg-" + strconv.Itoa(i)))
+				Expect(c.Close()).ToNot(HaveOccurred())
+			}
+		})
+	})
+})
@@ -0,0 +1,103 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package unix_test
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	libptc "github.com/nabbar/golib/network/protocol"
+	libsck "github.com/nabbar/golib/socket"
+	sckcfg "github.com/nabbar/golib/socket/config"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("socket/server/unix abstract", func() {
+	Context("using an abstract unix socket", func() {
+		It("must listen without any file system node and echo the request", func() {
+			var (
+				adr = fmt.Sprintf("@golib_sck_%d", time.Now().UnixNano())
+				cfg = &sckcfg.ServerConfig{
+					Network: libptc.NetworkUnix,
+					Address: adr,
+				}
+			)
+
+			Expect(libsck.IsAbstractUnix(adr)).To(BeTrue())
+
+			sck, err := cfg.New(nil, Handler)
+			Expect(err).ToNot(HaveOccurred())
+			listenClosingServer(sck)
+
+			defer func() {
+				Expect(sck.Close()).ToNot(HaveOccurred())
+			}()
+
+			_, err = os.Stat(adr)
+			Expect(os.IsNotExist(err)).To(BeTrue())
+
+			con, err := net.Dial(libptc.NetworkUnix.Code(), adr)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = con.Write([]byte("hello\n"))
+			Expect(err).ToNot(HaveOccurred())
+
+			lin, err := bufio.NewReader(con).ReadString('\n')
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lin).To(Equal("hello\n"))
+			Expect(con.Close()).ToNot(HaveOccurred())
+		})
+	})
+
+	Context("using a socketpair", func() {
+		It("must return two connected unix stream sockets", func() {
+			c1, c2, err := libsck.Socketpair(libptc.NetworkUnix)
+			Expect(err).ToNot(HaveOccurred())
+
+			defer func() {
+				_ = c1.Close()
+				_ = c2.Close()
+			}()
+
+			_, err = c1.Write([]byte("ping\n"))
+			Expect(err).ToNot(HaveOccurred())
+
+			lin, err := bufio.NewReader(c2).ReadString('\n')
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lin).To(Equal("ping\n"))
+		})
+
+		It("must return an error with a not unix protocol", func() {
+			_, _, err := libsck.Socketpair(libptc.NetworkTCP)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -53,6 +53,9 @@ func New(u libsck.UpdateConn, h libsck.Handler) ServerUnix {
 	r := new(atomic.Value)
 	r.Store(make(chan struct{}))
 
+	f := new(atomic.Value)
+	f.Store(make(chan struct{}))
+
 	// socket file
 	sf := new(atomic.Value)
 	sf.Store("")
@@ -68,17 +71,28 @@ func New(u libsck.UpdateConn, h libsck.Handler) ServerUnix {
 	return &srv{
 		upd: u,
 		hdl: h,
+		mdw: new(libsck.MiddlewareList),
 		msg: c,
 		stp: s,
 		rst: r,
 		run: new(atomic.Bool),
+		cls: new(atomic.Bool),
 		gon: new(atomic.Bool),
 		fe:  new(atomic.Value),
 		fi:  new(atomic.Value),
 		fs:  new(atomic.Value),
+		fa:  new(atomic.Value),
+		fx:  new(atomic.Value),
+		ff:  new(atomic.Value),
+		lf:  new(atomic.Value),
 		sf:  sf,
 		sp:  sp,
 		sg:  sg,
 		nc:  new(atomic.Int64),
+		ci:  new(atomic.Uint64),
+		cs:  new(libsck.ConnStateCounter),
+		cr:  new(libsck.ConnRegistry),
+		dr:  new(atomic.Value),
+		fc:  f,
 	}
 }
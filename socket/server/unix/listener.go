@@ -33,6 +33,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"net"
 	"os"
@@ -80,6 +81,9 @@ func (o *srv) getSocketGroup() int {
 func (o *srv) checkFile(unixFile string) (string, error) {
 	if len(unixFile) < 1 {
 		return unixFile, fmt.Errorf("missing socket file path")
+	} else if libsck.IsAbstractUnix(unixFile) {
+		// abstract socket without file system node
+		return unixFile, nil
 	} else {
 		unixFile = filepath.Join(filepath.Dir(unixFile), filepath.Base(unixFile))
 	}
@@ -105,6 +109,22 @@ func (o *srv) getListen(uxf string) (net.Listener, error) {
 		lis net.Listener
 	)
 
+	if f := o.getListenerFactory(); f != nil {
+		if lis, err = f(context.Background(), libptc.NetworkUnix.Code(), uxf); err != nil {
+			return nil, err
+		}
+
+		o.fctInfoSrv("starting listening socket '%s %s'", libptc.NetworkUnix.String(), uxf)
+		return lis, nil
+	} else if libsck.IsAbstractUnix(uxf) {
+		if lis, err = net.Listen(libptc.NetworkUnix.Code(), uxf); err != nil {
+			return nil, err
+		}
+
+		o.fctInfoSrv("starting listening abstract socket '%s %s'", libptc.NetworkUnix.String(), uxf)
+		return lis, nil
+	}
+
 	old = syscall.Umask(int(prm))
 	defer func() {
 		syscall.Umask(old)
@@ -143,6 +163,10 @@ func (o *srv) Listen(ctx context.Context) error {
 		s = new(atomic.Bool)
 	)
 
+	if o.IsClosed() {
+		return ErrServerClosed
+	}
+
 	if f, e = o.getSocketFile(); e != nil {
 		o.fctError(e)
 		return e
@@ -163,7 +187,9 @@ func (o *srv) Listen(ctx context.Context) error {
 			_ = l.Close()
 		}
 
-		if _, e = os.Stat(f); e == nil {
+		if libsck.IsAbstractUnix(f) {
+			// abstract socket: no file system node to remove
+		} else if _, e = os.Stat(f); e == nil {
 			o.fctError(os.Remove(f))
 		}
 
@@ -171,10 +197,16 @@ func (o *srv) Listen(ctx context.Context) error {
 	}()
 
 	o.rst.Store(make(chan struct{}))
+	o.fc.Store(make(chan struct{}))
 	o.stp.Store(make(chan struct{}))
 	o.run.Store(true)
 	o.gon.Store(false)
 
+	// the server could have been closed before the stop channel has been replaced
+	if o.IsClosed() {
+		return ErrServerClosed
+	}
+
 	go func() {
 		defer func() {
 			s.Store(true)
@@ -184,7 +216,7 @@ func (o *srv) Listen(ctx context.Context) error {
 			}
 
 			go func() {
-				_ = o.Shutdown(context.Background())
+				_ = o.shutdown(context.Background())
 			}()
 		}()
 
@@ -200,7 +232,7 @@ func (o *srv) Listen(ctx context.Context) error {
 	for l != nil && !s.Load() {
 		if co, ce := l.Accept(); ce != nil && !s.Load() {
 			o.fctError(ce)
-		} else if co != nil {
+		} else if co != nil && o.acceptFilter(co) {
 			o.fctInfo(co.LocalAddr(), co.RemoteAddr(), libsck.ConnectionNew)
 			go o.Conn(ctx, co)
 		}
@@ -211,19 +243,25 @@ func (o *srv) Listen(ctx context.Context) error {
 
 func (o *srv) Conn(ctx context.Context, con net.Conn) {
 	var (
+		acc = time.Now()
 		cnl context.CancelFunc
 		cor libsck.Reader
 		cow libsck.Writer
 	)
 
 	o.nc.Add(1) // inc nb connection
+	trk := o.cs.Track()
 
 	if o.upd != nil {
 		o.upd(con)
 	}
 
+	id := o.fctAcceptInfo(ctx, acc, con)
+	cx := libsck.NewContext(id)
+	o.cr.Add(id, acc, con)
+
 	ctx, cnl = context.WithCancel(ctx)
-	cor, cow = o.getReadWriter(ctx, cnl, con)
+	cor, cow = o.getReadWriter(ctx, cnl, con, trk, cx)
 
 	defer func() {
 		// cancel context for connection
@@ -231,6 +269,7 @@ func (o *srv) Conn(ctx context.Context, con net.Conn) {
 
 		// dec nb connection
 		o.nc.Add(-1)
+		trk.Close()
 
 		// close connection writer
 		_ = cow.Close()
@@ -248,17 +287,26 @@ func (o *srv) Conn(ctx context.Context, con net.Conn) {
 		_ = cor.Close()
 
 		// send info about connection closing
-		o.fctInfo(con.LocalAddr(), con.RemoteAddr(), libsck.ConnectionClose)
+		o.fctInfoConn(cx, con.LocalAddr(), con.RemoteAddr(), libsck.ConnectionClose)
 
 		// close connection
 		_ = con.Close()
+		o.cr.Del(id)
 	}()
 
 	// get handler or exit if nil
 	if o.hdl == nil {
 		return
 	} else {
-		go o.hdl(cor, cow)
+		go func() {
+			o.fctInfoConn(cx, con.LocalAddr(), con.RemoteAddr(), libsck.ConnectionHandler)
+			trk.Set(libsck.ConnectionHandler)
+
+			o.handler()(cor, cow)
+
+			// the connection stay open until both directions are closed
+			trk.Set(libsck.ConnectionNew)
+		}()
 	}
 
 	for {
@@ -266,65 +314,74 @@ func (o *srv) Conn(ctx context.Context, con net.Conn) {
 		case <-ctx.Done():
 			return
 		case <-o.Gone():
+			o.drain(ctx)
 			return
 		}
 	}
 }
 
-func (o *srv) getReadWriter(ctx context.Context, cnl context.CancelFunc, con net.Conn) (libsck.Reader, libsck.Writer) {
+func (o *srv) getReadWriter(ctx context.Context, cnl context.CancelFunc, con net.Conn, trk *libsck.ConnStateTrack, cx libsck.Context) (libsck.Reader, libsck.Writer) {
 	var (
 		rc = new(atomic.Bool)
 		rw = new(atomic.Bool)
 	)
 
+	// each direction is closed once, the connection context is cancelled when both are closed
 	rdrClose := func() error {
+		if rc.Swap(true) {
+			return nil
+		}
+
 		defer func() {
 			if rw.Load() {
 				cnl()
 			}
 		}()
 
-		if cr, ok := con.(*net.UnixConn); ok {
-			rc.Store(true)
-			o.fctInfo(con.LocalAddr(), con.RemoteAddr(), libsck.ConnectionCloseRead)
+		o.fctInfoConn(cx, con.LocalAddr(), con.RemoteAddr(), libsck.ConnectionCloseRead)
+
+		if cr, ok := con.(interface{ CloseRead() error }); ok {
 			return libsck.ErrorFilter(cr.CloseRead())
-		} else {
-			rc.Store(true)
-			rw.Store(true)
-			o.fctInfo(con.LocalAddr(), con.RemoteAddr(), libsck.ConnectionClose)
-			return libsck.ErrorFilter(con.Close())
 		}
+
+		return nil
 	}
 
 	wrtClose := func() error {
+		if rw.Swap(true) {
+			return nil
+		}
+
 		defer func() {
 			if rc.Load() {
 				cnl()
 			}
 		}()
 
-		if cr, ok := con.(*net.UnixConn); ok {
-			rw.Store(true)
-			o.fctInfo(con.LocalAddr(), con.RemoteAddr(), libsck.ConnectionCloseWrite)
-			return libsck.ErrorFilter(cr.CloseRead())
-		} else {
-			rc.Store(true)
-			rw.Store(true)
-			o.fctInfo(con.LocalAddr(), con.RemoteAddr(), libsck.ConnectionClose)
-			return libsck.ErrorFilter(con.Close())
+		o.fctInfoConn(cx, con.LocalAddr(), con.RemoteAddr(), libsck.ConnectionCloseWrite)
+
+		if cw, ok := con.(interface{ CloseWrite() error }); ok {
+			return libsck.ErrorFilter(cw.CloseWrite())
 		}
+
+		return nil
 	}
 
-	rdr := libsck.NewReader(
+	rdr := libsck.NewStreamReader(
 		func(p []byte) (n int, err error) {
-			if ctx.Err() != nil {
+			if rc.Load() {
+				return 0, io.EOF
+			} else if ctx.Err() != nil {
 				_ = rdrClose()
 				return 0, ctx.Err()
 			}
-			o.fctInfo(con.LocalAddr(), con.RemoteAddr(), libsck.ConnectionRead)
+			o.fctInfoConn(cx, con.LocalAddr(), con.RemoteAddr(), libsck.ConnectionRead)
+			trk.Set(libsck.ConnectionRead)
+			defer trk.Set(libsck.ConnectionHandler)
 			return con.Read(p)
 		},
 		rdrClose,
+		wrtClose,
 		func() bool {
 			if ctx.Err() != nil {
 				_ = rdrClose()
@@ -345,15 +402,20 @@ func (o *srv) getReadWriter(ctx context.Context, cnl context.CancelFunc, con net
 		},
 	)
 
-	wrt := libsck.NewWriter(
+	wrt := libsck.NewStreamWriter(
 		func(p []byte) (n int, err error) {
-			if ctx.Err() != nil {
+			if rw.Load() {
+				return 0, io.ErrClosedPipe
+			} else if ctx.Err() != nil {
 				_ = wrtClose()
 				return 0, ctx.Err()
 			}
-			o.fctInfo(con.LocalAddr(), con.RemoteAddr(), libsck.ConnectionWrite)
+			o.fctInfoConn(cx, con.LocalAddr(), con.RemoteAddr(), libsck.ConnectionWrite)
+			trk.Set(libsck.ConnectionWrite)
+			defer trk.Set(libsck.ConnectionHandler)
 			return con.Write(p)
 		},
+		rdrClose,
 		wrtClose,
 		func() bool {
 			if ctx.Err() != nil {
@@ -375,5 +437,7 @@ func (o *srv) getReadWriter(ctx context.Context, cnl context.CancelFunc, con net
 		},
 	)
 
+	libsck.SetContext(cx, rdr, wrt)
+
 	return rdr, wrt
 }
@@ -0,0 +1,160 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2023 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package unixgram_test
+
+import (
+	"errors"
+
+	"sync"
+	"time"
+
+	libptc "github.com/nabbar/golib/network/protocol"
+	libsck "github.com/nabbar/golib/socket"
+	sckcfg "github.com/nabbar/golib/socket/config"
+	scksrv "github.com/nabbar/golib/socket/server/unixgram"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func newClosingServer() libsck.Server {
+	var cfg = &sckcfg.ServerConfig{
+		Network:   libptc.NetworkUnixGram,
+		Address:   getUnixFileTemp(),
+		PermFile:  0,
+		GroupPerm: 0,
+	}
+
+	sck, err := cfg.New(nil, Handler)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(sck).ToNot(BeNil())
+
+	return sck
+}
+
+func listenClosingServer(sck libsck.Server) {
+	go func() {
+		defer GinkgoRecover()
+		_ = sck.Listen(ctx)
+	}()
+
+	Eventually(sck.IsRunning, 5*time.Second, 10*time.Millisecond).Should(BeTrue())
+}
+
+var _ = Describe("socket/server/unixgram closing", func() {
+	Context("using a unixgram server after closing it", func() {
+		var sck libsck.Server
+
+		It("Create and listen a new server must succeed", func() {
+			sck = newClosingServer()
+			listenClosingServer(sck)
+			Expect(sck.IsClosed()).To(BeFalse())
+		})
+
+		It("Closing the server must succeed", func() {
+			Expect(sck.Close()).ToNot(HaveOccurred())
+			Expect(sck.IsClosed()).To(BeTrue())
+			Eventually(sck.IsRunning, 5*time.Second, 10*time.Millisecond).Should(BeFalse())
+		})
+
+		It("Closing the server twice must succeed", func() {
+			Expect(sck.Close()).ToNot(HaveOccurred())
+			Expect(sck.IsClosed()).To(BeTrue())
+		})
+
+		It("Shutdown after close must return ErrServerClosed", func() {
+			Expect(errors.Is(sck.Shutdown(ctx), scksrv.ErrServerClosed)).To(BeTrue())
+		})
+
+		It("Listen after close must return ErrServerClosed", func() {
+			Expect(errors.Is(sck.Listen(ctx), scksrv.ErrServerClosed)).To(BeTrue())
+			Expect(sck.IsRunning()).To(BeFalse())
+		})
+
+		It("SetTLS after close must return ErrServerClosed", func() {
+			Expect(errors.Is(sck.SetTLS(false, nil), scksrv.ErrServerClosed)).To(BeTrue())
+		})
+	})
+
+	Context("closing a unixgram server never started", func() {
+		It("Listen after close must return ErrServerClosed", func() {
+			var sck = newClosingServer()
+			Expect(sck.Close()).ToNot(HaveOccurred())
+			Expect(errors.Is(sck.Listen(ctx), scksrv.ErrServerClosed)).To(BeTrue())
+		})
+	})
+
+	Context("concurrent shutdown and close of a unixgram server", func() {
+		It("Only one call must stop the server and the others must not fail", func() {
+			var (
+				sck = newClosingServer()
+				wg  = sync.WaitGroup{}
+				mu  = sync.Mutex{}
+				res = make([]error, 0)
+			)
+
+			listenClosingServer(sck)
+
+			for i := 0; i < 10; i++ {
+				wg.Add(2)
+
+				go func() {
+					defer GinkgoRecover()
+					defer wg.Done()
+
+					e := sck.Shutdown(ctx)
+
+					mu.Lock()
+					res = append(res, e)
+					mu.Unlock()
+				}()
+
+				go func() {
+					defer GinkgoRecover()
+					defer wg.Done()
+
+					Expect(sck.Close()).ToNot(HaveOccurred())
+				}()
+			}
+
+			wg.Wait()
+
+			var nbr = 0
+
+			for _, e := range res {
+				if e == nil {
+					nbr++
+				} else {
+					Expect(errors.Is(e, scksrv.ErrServerClosed)).To(BeTrue())
+				}
+			}
+
+			Expect(nbr).To(BeNumerically("<=", 1))
+			Expect(sck.IsClosed()).To(BeTrue())
+			Eventually(sck.IsRunning, 5*time.Second, 10*time.Millisecond).Should(BeFalse())
+		})
+	})
+})
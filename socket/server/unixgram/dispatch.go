@@ -0,0 +1,159 @@
+//go:build linux
+// +build linux
+
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package unixgram
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"sync"
+
+	libsck "github.com/nabbar/golib/socket"
+)
+
+func (o *srv) getDispatch() libsck.Dispatch {
+	if i := o.dp.Load(); i != nil {
+		if d, k := i.(libsck.Dispatch); k {
+			return d
+		}
+	}
+
+	return libsck.Dispatch{}
+}
+
+// dispatch reads the datagrams of the socket and hands each one to a worker of the pool
+// until the socket is closed.
+func (o *srv) dispatch(ctx context.Context, d libsck.Dispatch, con libsck.PacketConn, loc net.Addr, trk *libsck.ConnStateTrack) {
+	var (
+		buf = make([]byte, d.BufferSize())
+		hdl = o.handler()
+	)
+
+	pool := libsck.NewDispatchPool(d, func(g libsck.Datagram) {
+		t := o.cs.Track()
+		defer t.Close()
+
+		r, w := o.getDatagramReadWriter(ctx, con, loc, g, t)
+		t.Set(libsck.ConnectionHandler)
+		hdl(r, w)
+	})
+
+	defer pool.Close()
+
+	for {
+		trk.Set(libsck.ConnectionRead)
+		n, a, err := con.ReadFrom(buf)
+		trk.Set(libsck.ConnectionNew)
+
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return
+			}
+
+			o.fctError(err)
+			continue
+		}
+
+		var p = make([]byte, n)
+		copy(p, buf[:n])
+
+		if !pool.Push(ctx, libsck.Datagram{Data: p, Addr: a}) {
+			return
+		}
+	}
+}
+
+// getDatagramReadWriter returns the reader of the given datagram and the writer replying to its sender.
+// Closing them does not close the socket shared by all the workers.
+func (o *srv) getDatagramReadWriter(ctx context.Context, con libsck.PacketConn, loc net.Addr, g libsck.Datagram, trk *libsck.ConnStateTrack) (libsck.Reader, libsck.Writer) {
+	var (
+		cx  = libsck.NewContext(0)
+		buf = bytes.NewReader(g.Data)
+		rem = g.Addr
+		cls sync.Once
+	)
+
+	if rem == nil {
+		rem = &net.UnixAddr{}
+	}
+
+	fctClose := func() error {
+		cls.Do(func() {
+			o.fctInfoConn(cx, loc, rem, libsck.ConnectionClose)
+		})
+		return nil
+	}
+
+	fctCheck := func() bool {
+		return ctx.Err() == nil
+	}
+
+	fctDone := func() <-chan struct{} {
+		return ctx.Done()
+	}
+
+	rdr := libsck.NewReader(
+		func(p []byte) (n int, err error) {
+			if ctx.Err() != nil {
+				return 0, ctx.Err()
+			}
+
+			o.fctInfoConn(cx, loc, rem, libsck.ConnectionRead)
+			return buf.Read(p)
+		},
+		fctClose,
+		fctCheck,
+		fctDone,
+	)
+
+	wrt := libsck.NewWriter(
+		func(p []byte) (n int, err error) {
+			if ctx.Err() != nil {
+				return 0, ctx.Err()
+			} else if g.Addr == nil {
+				return 0, ErrInvalidSender
+			}
+
+			trk.Set(libsck.ConnectionWrite)
+			defer trk.Set(libsck.ConnectionHandler)
+
+			o.fctInfoConn(cx, loc, rem, libsck.ConnectionWrite)
+			return con.WriteTo(p, g.Addr)
+		},
+		fctClose,
+		fctCheck,
+		fctDone,
+	)
+
+	libsck.SetContext(cx, rdr, wrt)
+
+	return rdr, wrt
+}
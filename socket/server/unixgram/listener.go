@@ -79,6 +79,9 @@ func (o *srv) getSocketGroup() int {
 func (o *srv) checkFile(unixFile string) (string, error) {
 	if len(unixFile) < 1 {
 		return unixFile, fmt.Errorf("missing socket file path")
+	} else if libsck.IsAbstractUnix(unixFile) {
+		// abstract socket without file system node
+		return unixFile, nil
 	} else {
 		unixFile = filepath.Join(filepath.Dir(unixFile), filepath.Base(unixFile))
 	}
@@ -94,7 +97,7 @@ func (o *srv) checkFile(unixFile string) (string, error) {
 	return unixFile, nil
 }
 
-func (o *srv) getListen(uxf string, adr *net.UnixAddr) (*net.UnixConn, error) {
+func (o *srv) getListen(uxf string, adr *net.UnixAddr) (libsck.PacketConn, error) {
 	var (
 		err error
 		prm = o.getSocketPerm()
@@ -104,6 +107,24 @@ func (o *srv) getListen(uxf string, adr *net.UnixAddr) (*net.UnixConn, error) {
 		lis *net.UnixConn
 	)
 
+	if f := o.getPacketConnFactory(); f != nil {
+		var c libsck.PacketConn
+
+		if c, err = f(context.Background(), libptc.NetworkUnixGram.Code(), uxf); err != nil {
+			return nil, err
+		}
+
+		o.fctInfoSrv("starting listening socket '%s %s'", libptc.NetworkUnixGram.String(), uxf)
+		return c, nil
+	} else if libsck.IsAbstractUnix(uxf) {
+		if lis, err = net.ListenUnixgram(libptc.NetworkUnixGram.Code(), adr); err != nil {
+			return nil, err
+		}
+
+		o.fctInfoSrv("starting listening abstract socket '%s %s'", libptc.NetworkUnixGram.String(), uxf)
+		return lis, nil
+	}
+
 	old = syscall.Umask(int(prm))
 	defer func() {
 		syscall.Umask(old)
@@ -141,12 +162,16 @@ func (o *srv) Listen(ctx context.Context) error {
 		s = new(atomic.Bool)
 
 		loc *net.UnixAddr
-		con *net.UnixConn
+		con libsck.PacketConn
 		cnl context.CancelFunc
 		cor libsck.Reader
 		cow libsck.Writer
 	)
 
+	if o.IsClosed() {
+		return ErrServerClosed
+	}
+
 	s.Store(false)
 
 	if u, e = o.getSocketFile(); e != nil {
@@ -168,7 +193,9 @@ func (o *srv) Listen(ctx context.Context) error {
 	}
 
 	ctx, cnl = context.WithCancel(ctx)
-	cor, cow = o.getReadWriter(ctx, con, loc)
+	trk := o.cs.Track()
+	cx := libsck.NewContext(0)
+	cor, cow = o.getReadWriter(ctx, con, loc, trk, cx)
 
 	o.stp.Store(make(chan struct{}))
 	o.run.Store(true)
@@ -178,21 +205,41 @@ func (o *srv) Listen(ctx context.Context) error {
 		cnl()
 
 		// send info about connection closing
-		o.fctInfo(loc, &net.UnixAddr{}, libsck.ConnectionClose)
+		o.fctInfoConn(cx, loc, &net.UnixAddr{}, libsck.ConnectionClose)
 		o.fctInfoSrv("closing listen socket '%s %s'", libptc.NetworkUnixGram.String(), u)
 
 		// close connection
 		_ = con.Close()
+		trk.Close()
 
-		if _, e = os.Stat(u); e == nil {
+		if libsck.IsAbstractUnix(u) {
+			// abstract socket: no file system node to remove
+		} else if _, e = os.Stat(u); e == nil {
 			o.fctError(os.Remove(u))
 		}
 
 		o.run.Store(false)
 	}()
 
-	// get handler or exit if nil
-	go o.hdl(cor, cow)
+	// the server could have been closed before the stop channel has been replaced
+	if o.IsClosed() {
+		return ErrServerClosed
+	}
+
+	if d := o.getDispatch(); d.Enabled() {
+		// dispatch each datagram to a pool of handlers
+		go o.dispatch(ctx, d, con, loc, trk)
+	} else {
+		// get handler or exit if nil
+		go func() {
+			o.fctInfoConn(cx, loc, &net.UnixAddr{}, libsck.ConnectionHandler)
+			trk.Set(libsck.ConnectionHandler)
+
+			o.handler()(cor, cow)
+
+			trk.Set(libsck.ConnectionNew)
+		}()
+	}
 
 	for {
 		select {
@@ -204,7 +251,7 @@ func (o *srv) Listen(ctx context.Context) error {
 	}
 }
 
-func (o *srv) getReadWriter(ctx context.Context, con *net.UnixConn, loc net.Addr) (libsck.Reader, libsck.Writer) {
+func (o *srv) getReadWriter(ctx context.Context, con libsck.PacketConn, loc net.Addr, trk *libsck.ConnStateTrack, cx libsck.Context) (libsck.Reader, libsck.Writer) {
 	var (
 		re = &net.UDPAddr{}
 		ra = new(atomic.Value)
@@ -220,7 +267,7 @@ func (o *srv) getReadWriter(ctx context.Context, con *net.UnixConn, loc net.Addr
 	ra.Store(re)
 
 	fctClose := func() error {
-		o.fctInfo(loc, fg(), libsck.ConnectionClose)
+		o.fctInfoConn(cx, loc, fg(), libsck.ConnectionClose)
 		return libsck.ErrorFilter(con.Close())
 	}
 
@@ -232,7 +279,9 @@ func (o *srv) getReadWriter(ctx context.Context, con *net.UnixConn, loc net.Addr
 			}
 
 			var a net.Addr
+			trk.Set(libsck.ConnectionRead)
 			n, a, err = con.ReadFrom(p)
+			trk.Set(libsck.ConnectionHandler)
 
 			if a != nil {
 				ra.Store(a)
@@ -240,7 +289,7 @@ func (o *srv) getReadWriter(ctx context.Context, con *net.UnixConn, loc net.Addr
 				ra.Store(re)
 			}
 
-			o.fctInfo(loc, fg(), libsck.ConnectionRead)
+			o.fctInfoConn(cx, loc, fg(), libsck.ConnectionRead)
 			return n, err
 		},
 		fctClose,
@@ -269,12 +318,15 @@ func (o *srv) getReadWriter(ctx context.Context, con *net.UnixConn, loc net.Addr
 				return 0, ctx.Err()
 			}
 
+			trk.Set(libsck.ConnectionWrite)
+			defer trk.Set(libsck.ConnectionHandler)
+
 			if a := fg(); a != nil && a != re {
-				o.fctInfo(loc, a, libsck.ConnectionWrite)
+				o.fctInfoConn(cx, loc, a, libsck.ConnectionWrite)
 				return con.WriteTo(p, a)
 			}
 
-			o.fctInfo(loc, fg(), libsck.ConnectionWrite)
+			o.fctInfoConn(cx, loc, fg(), libsck.ConnectionWrite)
 			return con.Write(p)
 		},
 		fctClose,
@@ -296,5 +348,7 @@ func (o *srv) getReadWriter(ctx context.Context, con *net.UnixConn, loc net.Addr
 		},
 	)
 
+	libsck.SetContext(cx, rdr, wrt)
+
 	return rdr, wrt
 }
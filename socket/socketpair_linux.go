@@ -0,0 +1,95 @@
+//go:build linux
+// +build linux
+
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package socket
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+
+	libptc "github.com/nabbar/golib/network/protocol"
+)
+
+// Socketpair returns two connected unix sockets of the given protocol (unix for a stream, unixgram for datagrams),
+// without address nor file system node. It's designed for in-process or parent/child IPC and testing:
+// a side can be given to a child process with its File function.
+func Socketpair(proto libptc.NetworkProtocol) (*net.UnixConn, *net.UnixConn, error) {
+	var typ int
+
+	switch proto {
+	case libptc.NetworkUnix:
+		typ = syscall.SOCK_STREAM
+	case libptc.NetworkUnixGram:
+		typ = syscall.SOCK_DGRAM
+	default:
+		return nil, nil, fmt.Errorf("invalid socketpair protocol '%s'", proto.String())
+	}
+
+	fd, err := syscall.Socketpair(syscall.AF_UNIX, typ|syscall.SOCK_CLOEXEC, 0)
+
+	if err != nil {
+		return nil, nil, os.NewSyscallError("socketpair", err)
+	}
+
+	c1, err := fileConn(fd[0], "socketpair-0")
+
+	if err != nil {
+		_ = syscall.Close(fd[1])
+		return nil, nil, err
+	}
+
+	c2, err := fileConn(fd[1], "socketpair-1")
+
+	if err != nil {
+		_ = c1.Close()
+		return nil, nil, err
+	}
+
+	return c1, c2, nil
+}
+
+// fileConn return the unix connection of the given file descriptor, closing the descriptor.
+func fileConn(fd int, name string) (*net.UnixConn, error) {
+	var f = os.NewFile(uintptr(fd), name)
+
+	defer func() {
+		_ = f.Close()
+	}()
+
+	if c, e := net.FileConn(f); e != nil {
+		return nil, e
+	} else if u, k := c.(*net.UnixConn); !k {
+		_ = c.Close()
+		return nil, fmt.Errorf("invalid socketpair connection")
+	} else {
+		return u, nil
+	}
+}
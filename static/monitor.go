@@ -29,7 +29,6 @@ package static
 import (
 	"context"
 	"io/fs"
-	"runtime"
 
 	libctx "github.com/nabbar/golib/context"
 
@@ -46,10 +45,9 @@ const (
 
 func (s *staticHandler) Monitor(ctx libctx.FuncContext, cfg montps.Config, vrs libver.Version) (montps.Monitor, error) {
 	res := make(map[string]interface{}, 0)
-	res["runtime"] = runtime.Version()[2:]
-	res["release"] = vrs.GetRelease()
-	res["build"] = vrs.GetBuild()
-	res["date"] = vrs.GetDate()
+	for k, v := range vrs.GetInfoMap() {
+		res[k] = v
+	}
 
 	var (
 		e   error
@@ -0,0 +1,82 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package about
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	ginsdk "github.com/gin-gonic/gin"
+	logcfg "github.com/nabbar/golib/logger/config"
+	montps "github.com/nabbar/golib/monitor/types"
+	libver "github.com/nabbar/golib/version"
+)
+
+// FuncConfig return the current value of a config. Only a fingerprint of its JSON encoding is exposed.
+type FuncConfig func() interface{}
+
+// FuncInfo return an additional section of the about document.
+type FuncInfo func() map[string]interface{}
+
+// About is the standard operational endpoint of a service, composing in one JSON document
+// the version and build info, the uptime, the fingerprints of the configs and the summary of the monitors.
+// The fields and values matching the redaction rules are masked at any depth of the document.
+type About interface {
+	http.Handler
+	json.Marshaler
+
+	// SetVersion define the version exposed in the document.
+	SetVersion(vrs libver.Version)
+
+	// RegisterPool define the pool of the monitors summarized in the document.
+	RegisterPool(fct montps.FuncPool)
+
+	// RegisterConfig add a config identified by its name, exposed only by the sha256 fingerprint of its JSON encoding.
+	RegisterConfig(name string, fct FuncConfig)
+
+	// RegisterInfo add a section identified by its name with the result of the given function.
+	RegisterInfo(name string, fct FuncInfo)
+
+	// SetRedact define the redaction rules applied to the whole document.
+	// An error is returned if a pattern of the options is invalid.
+	SetRedact(opt *logcfg.OptionsRedact) error
+
+	// GinHandler is the gin handler of the document, to be registered on a router.
+	GinHandler(c *ginsdk.Context)
+}
+
+// New return an about endpoint, using the current time as start time for the uptime.
+func New() About {
+	return &abt{
+		m: sync.RWMutex{},
+		t: time.Now(),
+		c: make(map[string]FuncConfig),
+		i: make(map[string]FuncInfo),
+	}
+}
@@ -0,0 +1,271 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package about
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	ginsdk "github.com/gin-gonic/gin"
+	logcfg "github.com/nabbar/golib/logger/config"
+	loghkr "github.com/nabbar/golib/logger/hookredact"
+	monsts "github.com/nabbar/golib/monitor/status"
+	montps "github.com/nabbar/golib/monitor/types"
+	libver "github.com/nabbar/golib/version"
+	"github.com/sirupsen/logrus"
+)
+
+const fingerprintPrefix = "sha256:"
+
+type abt struct {
+	m sync.RWMutex
+	t time.Time             // start time
+	v libver.Version        // version
+	p montps.FuncPool       // monitor pool
+	c map[string]FuncConfig // configs
+	i map[string]FuncInfo   // additional sections
+	r loghkr.HookRedact     // redaction
+}
+
+type monitor struct {
+	Name     string                 `json:"name"`
+	Status   monsts.Status          `json:"status"`
+	Message  string                 `json:"message,omitempty"`
+	Latency  string                 `json:"latency"`
+	Uptime   string                 `json:"uptime"`
+	Downtime string                 `json:"downtime"`
+	Info     map[string]interface{} `json:"info,omitempty"`
+}
+
+type document struct {
+	Status   monsts.Status                     `json:"status"`
+	Time     time.Time                         `json:"time"`
+	Started  time.Time                         `json:"started"`
+	Uptime   string                            `json:"uptime"`
+	Version  libver.Version                    `json:"version,omitempty"`
+	Config   map[string]string                 `json:"config,omitempty"`
+	Monitors []monitor                         `json:"monitors,omitempty"`
+	Info     map[string]map[string]interface{} `json:"info,omitempty"`
+}
+
+func (o *abt) SetVersion(vrs libver.Version) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.v = vrs
+}
+
+func (o *abt) RegisterPool(fct montps.FuncPool) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.p = fct
+}
+
+func (o *abt) RegisterConfig(name string, fct FuncConfig) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if fct == nil {
+		delete(o.c, name)
+	} else {
+		o.c[name] = fct
+	}
+}
+
+func (o *abt) RegisterInfo(name string, fct FuncInfo) {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if fct == nil {
+		delete(o.i, name)
+	} else {
+		o.i[name] = fct
+	}
+}
+
+func (o *abt) SetRedact(opt *logcfg.OptionsRedact) error {
+	if opt == nil {
+		o.m.Lock()
+		o.r = nil
+		o.m.Unlock()
+		return nil
+	}
+
+	h, e := loghkr.New(opt, nil)
+
+	if e != nil {
+		return e
+	}
+
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	o.r = h
+	return nil
+}
+
+func fingerprint(cfg interface{}) string {
+	p, e := json.Marshal(cfg)
+
+	if e != nil {
+		return ""
+	}
+
+	s := sha256.Sum256(p)
+	return fingerprintPrefix + hex.EncodeToString(s[:])
+}
+
+func (o *abt) getPool() montps.Pool {
+	if o.p == nil {
+		return nil
+	}
+
+	return o.p()
+}
+
+func (o *abt) document() document {
+	o.m.RLock()
+	defer o.m.RUnlock()
+
+	var (
+		now = time.Now()
+		doc = document{
+			Status:  monsts.OK,
+			Time:    now,
+			Started: o.t,
+			Uptime:  now.Sub(o.t).Truncate(time.Second).String(),
+			Version: o.v,
+		}
+	)
+
+	if len(o.c) > 0 {
+		doc.Config = make(map[string]string, len(o.c))
+
+		for k, f := range o.c {
+			doc.Config[k] = fingerprint(f())
+		}
+	}
+
+	if len(o.i) > 0 {
+		doc.Info = make(map[string]map[string]interface{}, len(o.i))
+
+		for k, f := range o.i {
+			doc.Info[k] = f()
+		}
+	}
+
+	if p := o.getPool(); p != nil {
+		p.MonitorWalk(func(name string, val montps.Monitor) bool {
+			s := val.Status()
+
+			if s < doc.Status {
+				doc.Status = s
+			}
+
+			doc.Monitors = append(doc.Monitors, monitor{
+				Name:     name,
+				Status:   s,
+				Message:  val.Message(),
+				Latency:  val.Latency().Truncate(time.Millisecond).String(),
+				Uptime:   val.Uptime().Truncate(time.Second).String(),
+				Downtime: val.Downtime().Truncate(time.Second).String(),
+				Info:     val.InfoMap(),
+			})
+
+			return true
+		})
+	}
+
+	return doc
+}
+
+// redact decode the JSON document in a map to apply the redaction rules at any depth.
+func (o *abt) redact(p []byte) ([]byte, error) {
+	o.m.RLock()
+	h := o.r
+	o.m.RUnlock()
+
+	if h == nil {
+		return p, nil
+	}
+
+	var m = make(map[string]interface{})
+
+	if e := json.Unmarshal(p, &m); e != nil {
+		return nil, e
+	}
+
+	ent := &logrus.Entry{Data: m}
+
+	if e := h.Fire(ent); e != nil {
+		return nil, e
+	}
+
+	return json.Marshal(ent.Data)
+}
+
+func (o *abt) MarshalJSON() ([]byte, error) {
+	p, e := json.Marshal(o.document())
+
+	if e != nil {
+		return nil, e
+	}
+
+	return o.redact(p)
+}
+
+func (o *abt) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", http.MethodGet+", "+http.MethodHead)
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	p, e := o.MarshalJSON()
+
+	if e != nil {
+		http.Error(w, e.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+
+	if r.Method == http.MethodGet {
+		_, _ = w.Write(p)
+	}
+}
+
+func (o *abt) GinHandler(c *ginsdk.Context) {
+	o.ServeHTTP(c.Writer, c.Request)
+}
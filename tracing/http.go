@@ -0,0 +1,132 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package tracing
+
+import (
+	"context"
+	"net/http"
+)
+
+// Config define how the correlation ids are read from and sent back to the http caller.
+type Config struct {
+	// TrustIncoming when true keep the request id and the traceparent given by the caller if they are valid.
+	// Otherwise new ids are always generated and the incoming values are ignored.
+	TrustIncoming bool `mapstructure:"trust_incoming" json:"trust_incoming" yaml:"trust_incoming" toml:"trust_incoming"`
+
+	// ResponseHeader when true send back the request id and the traceparent into the response headers.
+	ResponseHeader bool `mapstructure:"response_header" json:"response_header" yaml:"response_header" toml:"response_header"`
+}
+
+// FromHeader return the trace for a received request with the given headers.
+// If trust is true and the headers carry a valid traceparent, the returned trace is a child of it.
+// If trust is true and the headers carry a valid request id, it is kept.
+// Any other value is generated.
+func FromHeader(h http.Header, trust bool) Trace {
+	var t Trace
+
+	if !trust || h == nil {
+		return New()
+	}
+
+	if p, e := ParseTraceParent(h.Get(HeaderTraceParent)); e == nil {
+		t = p.Child()
+		t.State = h.Get(HeaderTraceState)
+	} else {
+		t = New()
+	}
+
+	if r := h.Get(HeaderRequestID); isValidRequestID(r) {
+		t.RequestID = r
+	}
+
+	return t
+}
+
+// Inject set the correlation ids of the trace carried by the given context into the given headers,
+// to propagate them on an outgoing request. Nothing is done if the context has no trace.
+func Inject(ctx context.Context, h http.Header) {
+	if h == nil {
+		return
+	} else if t, ok := FromContext(ctx); ok {
+		setHeader(h, t)
+	}
+}
+
+func setHeader(h http.Header, t Trace) {
+	if len(t.RequestID) > 0 {
+		h.Set(HeaderRequestID, t.RequestID)
+	}
+
+	if p := t.TraceParent(); len(p) > 0 {
+		h.Set(HeaderTraceParent, p)
+	}
+
+	if len(t.State) > 0 {
+		h.Set(HeaderTraceState, t.State)
+	}
+}
+
+// Middleware return a http middleware storing the trace of each request into its context.
+func Middleware(cfg Config) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var t = FromHeader(r.Header, cfg.TrustIncoming)
+
+			if cfg.ResponseHeader {
+				w.Header().Set(HeaderRequestID, t.RequestID)
+				w.Header().Set(HeaderTraceParent, t.TraceParent())
+			}
+
+			next.ServeHTTP(w, r.WithContext(WithContext(r.Context(), t)))
+		})
+	}
+}
+
+type transport struct {
+	r http.RoundTripper
+}
+
+// Transport return a http.RoundTripper injecting the trace of each request context into its headers.
+// If the given round tripper is nil, the http.DefaultTransport is used.
+func Transport(rt http.RoundTripper) http.RoundTripper {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+
+	return &transport{
+		r: rt,
+	}
+}
+
+func (o *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t, ok := FromContext(req.Context()); ok {
+		// a RoundTripper must not modify the given request
+		req = req.Clone(req.Context())
+		setHeader(req.Header, t)
+	}
+
+	return o.r.RoundTrip(req)
+}
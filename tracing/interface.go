@@ -0,0 +1,237 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+const (
+	// HeaderRequestID is the http header used to carry the request id.
+	HeaderRequestID = "X-Request-ID"
+	// HeaderTraceParent is the W3C trace context header carrying the trace id, the span id and the flags.
+	HeaderTraceParent = "traceparent"
+	// HeaderTraceState is the W3C trace context header carrying the vendor specific trace data.
+	HeaderTraceState = "tracestate"
+
+	// FieldRequestID is the logger field name used for the request id.
+	FieldRequestID = "request_id"
+	// FieldTraceID is the logger field name used for the trace id.
+	FieldTraceID = "trace_id"
+	// FieldSpanID is the logger field name used for the span id.
+	FieldSpanID = "span_id"
+	// FieldParentID is the logger field name used for the parent span id.
+	FieldParentID = "parent_id"
+
+	// FlagSampled is the W3C trace flag set when the caller may have recorded the trace.
+	FlagSampled byte = 0x01
+
+	// MaxRequestIDLength is the max length of an incoming request id kept as is.
+	MaxRequestIDLength = 128
+)
+
+type ctxKey struct{}
+
+// Trace is the set of correlation ids carried by a request across http, logger and socket.
+type Trace struct {
+	RequestID string `json:"request_id"`
+	TraceID   string `json:"trace_id"`
+	SpanID    string `json:"span_id"`
+	ParentID  string `json:"parent_id,omitempty"`
+	Flags     byte   `json:"flags"`
+	State     string `json:"state,omitempty"`
+}
+
+// New return a new root trace with random ids and the sampled flag.
+func New() Trace {
+	return Trace{
+		RequestID: NewRequestID(),
+		TraceID:   randomHex(16),
+		SpanID:    randomHex(8),
+		Flags:     FlagSampled,
+	}
+}
+
+// NewRequestID return a new random request id formatted as an uuid v4.
+func NewRequestID() string {
+	var b = make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	var s = hex.EncodeToString(b)
+	return s[0:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:32]
+}
+
+// IsValid return true if the trace id and the span id are well formed.
+func (t Trace) IsValid() bool {
+	return isValidID(t.TraceID, 32) && isValidID(t.SpanID, 16)
+}
+
+// IsSampled return true if the sampled flag is set.
+func (t Trace) IsSampled() bool {
+	return t.Flags&FlagSampled == FlagSampled
+}
+
+// Child return a new trace into the same trace and request with a new span id,
+// the current span becoming the parent.
+func (t Trace) Child() Trace {
+	if !t.IsValid() {
+		n := New()
+
+		if len(t.RequestID) > 0 {
+			n.RequestID = t.RequestID
+		}
+
+		return n
+	}
+
+	return Trace{
+		RequestID: t.RequestID,
+		TraceID:   t.TraceID,
+		SpanID:    randomHex(8),
+		ParentID:  t.SpanID,
+		Flags:     t.Flags,
+		State:     t.State,
+	}
+}
+
+// Fields return the correlation ids as logger fields. Empty values are omitted.
+func (t Trace) Fields() map[string]interface{} {
+	var res = make(map[string]interface{}, 4)
+
+	if len(t.RequestID) > 0 {
+		res[FieldRequestID] = t.RequestID
+	}
+
+	if len(t.TraceID) > 0 {
+		res[FieldTraceID] = t.TraceID
+	}
+
+	if len(t.SpanID) > 0 {
+		res[FieldSpanID] = t.SpanID
+	}
+
+	if len(t.ParentID) > 0 {
+		res[FieldParentID] = t.ParentID
+	}
+
+	return res
+}
+
+// WithContext return a copy of the given context carrying the given trace.
+func WithContext(ctx context.Context, t Trace) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return context.WithValue(ctx, ctxKey{}, t)
+}
+
+// FromContext return the trace carried by the given context if any.
+func FromContext(ctx context.Context) (Trace, bool) {
+	if ctx == nil {
+		return Trace{}, false
+	}
+
+	t, ok := ctx.Value(ctxKey{}).(Trace)
+	return t, ok
+}
+
+// Ensure return the trace carried by the given context, or a new trace stored into a copy of the context.
+func Ensure(ctx context.Context) (context.Context, Trace) {
+	if t, ok := FromContext(ctx); ok {
+		return ctx, t
+	}
+
+	var t = New()
+	return WithContext(ctx, t), t
+}
+
+func randomHex(n int) string {
+	var b = make([]byte, n)
+
+	for {
+		_, _ = rand.Read(b)
+
+		for _, c := range b {
+			if c != 0 {
+				return hex.EncodeToString(b)
+			}
+		}
+	}
+}
+
+func isValidID(s string, l int) bool {
+	if len(s) != l {
+		return false
+	}
+
+	var zero = true
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+
+		switch {
+		case c >= '0' && c <= '9':
+		case c >= 'a' && c <= 'f':
+		default:
+			return false
+		}
+
+		if c != '0' {
+			zero = false
+		}
+	}
+
+	return !zero
+}
+
+// isValidRequestID return true if the given request id is not empty, not too long
+// and contains only safe characters to be logged or sent back into a header.
+func isValidRequestID(s string) bool {
+	if len(s) < 1 || len(s) > MaxRequestIDLength {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+
+		switch {
+		case c >= '0' && c <= '9':
+		case c >= 'a' && c <= 'z':
+		case c >= 'A' && c <= 'Z':
+		case c == '-' || c == '_' || c == '.' || c == ':' || c == '/' || c == '+' || c == '=':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,93 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package tracing
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+)
+
+// SocketPrefix is the prefix of the header line carrying the correlation ids on a socket.
+// The line format is: "TRACE <traceparent> [<request id>]\n".
+const SocketPrefix = "TRACE "
+
+// WriteSocket write the trace carried by the given context as a header line before the payload of a socket request.
+// Nothing is written if the context has no valid trace.
+func WriteSocket(ctx context.Context, w io.Writer) error {
+	t, ok := FromContext(ctx)
+
+	if !ok || !t.IsValid() {
+		return nil
+	}
+
+	p, e := t.MarshalText()
+
+	if e != nil {
+		return e
+	}
+
+	var buf = make([]byte, 0, len(SocketPrefix)+len(p)+1)
+	buf = append(buf, SocketPrefix...)
+	buf = append(buf, p...)
+	buf = append(buf, '\n')
+
+	_, e = w.Write(buf)
+	return e
+}
+
+// ReadSocket read the optional trace header line of a socket request.
+// The returned context carries a child of the received trace, or a new trace if no header line is found.
+// The returned reader must be used to read the payload of the request.
+func ReadSocket(ctx context.Context, r io.Reader) (context.Context, io.Reader, error) {
+	var (
+		b  *bufio.Reader
+		ok bool
+	)
+
+	if b, ok = r.(*bufio.Reader); !ok {
+		b = bufio.NewReader(r)
+	}
+
+	if p, e := b.Peek(len(SocketPrefix)); e != nil || !bytes.Equal(p, []byte(SocketPrefix)) {
+		return WithContext(ctx, New()), b, nil
+	}
+
+	l, e := b.ReadSlice('\n')
+
+	if e != nil {
+		return WithContext(ctx, New()), b, ErrInvalidTraceHeader
+	}
+
+	var t Trace
+
+	if e = t.UnmarshalText(bytes.TrimSpace(l[len(SocketPrefix):])); e != nil {
+		return WithContext(ctx, New()), b, e
+	}
+
+	return WithContext(ctx, t.Child()), b, nil
+}
@@ -0,0 +1,122 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package tracing
+
+import (
+	"encoding/hex"
+	"strings"
+)
+
+const traceParentVersion = "00"
+
+// TraceParent return the W3C traceparent value of the trace, or an empty string if the trace is not valid.
+func (t Trace) TraceParent() string {
+	if !t.IsValid() {
+		return ""
+	}
+
+	return traceParentVersion + "-" + t.TraceID + "-" + t.SpanID + "-" + hex.EncodeToString([]byte{t.Flags})
+}
+
+// ParseTraceParent parse a W3C traceparent value. The span id of the value is returned as the SpanID
+// of the trace: use Child to create the span of the receiver.
+func ParseTraceParent(s string) (Trace, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+
+	// version-traceid-spanid-flags: 2 + 1 + 32 + 1 + 16 + 1 + 2
+	if len(s) < 55 || s[2] != '-' || s[35] != '-' || s[52] != '-' {
+		return Trace{}, ErrInvalidTraceParent
+	} else if v := s[0:2]; v == "ff" || !isHex(v) {
+		return Trace{}, ErrInvalidTraceParent
+	} else if v == traceParentVersion && len(s) != 55 {
+		return Trace{}, ErrInvalidTraceParent
+	} else if len(s) > 55 && s[55] != '-' {
+		return Trace{}, ErrInvalidTraceParent
+	}
+
+	var t = Trace{
+		TraceID: s[3:35],
+		SpanID:  s[36:52],
+	}
+
+	if !t.IsValid() {
+		return Trace{}, ErrInvalidTraceParent
+	} else if f, e := hex.DecodeString(s[53:55]); e != nil {
+		return Trace{}, ErrInvalidTraceParent
+	} else {
+		t.Flags = f[0]
+	}
+
+	return t, nil
+}
+
+// MarshalText return the trace as a single line "<traceparent> <request id>",
+// used to carry the correlation ids on a socket.
+func (t Trace) MarshalText() ([]byte, error) {
+	var p = t.TraceParent()
+
+	if len(p) < 1 {
+		return nil, ErrInvalidTraceParent
+	}
+
+	if isValidRequestID(t.RequestID) {
+		p += " " + t.RequestID
+	}
+
+	return []byte(p), nil
+}
+
+func (t *Trace) UnmarshalText(p []byte) error {
+	var (
+		s = strings.Fields(string(p))
+		r Trace
+		e error
+	)
+
+	if len(s) < 1 || len(s) > 2 {
+		return ErrInvalidTraceHeader
+	} else if r, e = ParseTraceParent(s[0]); e != nil {
+		return e
+	}
+
+	if len(s) > 1 && isValidRequestID(s[1]) {
+		r.RequestID = s[1]
+	}
+
+	*t = r
+	return nil
+}
+
+func isHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+
+	return true
+}
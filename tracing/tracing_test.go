@@ -0,0 +1,141 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+package tracing_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	libtrc "github.com/nabbar/golib/tracing"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const tstParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+var _ = Describe("tracing", func() {
+	Context("Parsing a traceparent", func() {
+		It("must accept a valid value and format it back", func() {
+			t, e := libtrc.ParseTraceParent(tstParent)
+			Expect(e).ToNot(HaveOccurred())
+			Expect(t.TraceID).To(Equal("4bf92f3577b34da6a3ce929d0e0e4736"))
+			Expect(t.SpanID).To(Equal("00f067aa0ba902b7"))
+			Expect(t.IsSampled()).To(BeTrue())
+			Expect(t.TraceParent()).To(Equal(tstParent))
+		})
+
+		It("must reject invalid values", func() {
+			for _, s := range []string{
+				"",
+				"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+				"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+				"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+				"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+				"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+				"00-4bf92f3577b34da6a3ce929d0e0e473z-00f067aa0ba902b7-01",
+			} {
+				_, e := libtrc.ParseTraceParent(s)
+				Expect(e).To(MatchError(libtrc.ErrInvalidTraceParent), s)
+			}
+		})
+	})
+
+	Context("Using the http middleware", func() {
+		var (
+			got libtrc.Trace
+			hdl = libtrc.Middleware(libtrc.Config{TrustIncoming: true, ResponseHeader: true})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got, _ = libtrc.FromContext(r.Context())
+			}))
+		)
+
+		It("must continue the incoming trace and keep the request id", func() {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(libtrc.HeaderTraceParent, tstParent)
+			req.Header.Set(libtrc.HeaderRequestID, "abc-123")
+
+			rsp := httptest.NewRecorder()
+			hdl.ServeHTTP(rsp, req)
+
+			Expect(got.RequestID).To(Equal("abc-123"))
+			Expect(got.TraceID).To(Equal("4bf92f3577b34da6a3ce929d0e0e4736"))
+			Expect(got.ParentID).To(Equal("00f067aa0ba902b7"))
+			Expect(got.SpanID).ToNot(Equal(got.ParentID))
+			Expect(rsp.Header().Get(libtrc.HeaderRequestID)).To(Equal("abc-123"))
+			Expect(rsp.Header().Get(libtrc.HeaderTraceParent)).To(Equal(got.TraceParent()))
+		})
+
+		It("must replace an unsafe request id", func() {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(libtrc.HeaderRequestID, "bad id\r\n")
+
+			hdl.ServeHTTP(httptest.NewRecorder(), req)
+
+			Expect(got.RequestID).ToNot(Equal("bad id\r\n"))
+			Expect(got.RequestID).To(HaveLen(36))
+			Expect(got.IsValid()).To(BeTrue())
+		})
+	})
+
+	Context("Carrying the trace on a socket", func() {
+		It("must write and read back the header line before the payload", func() {
+			var (
+				buf = bytes.NewBuffer(make([]byte, 0))
+				src = libtrc.New()
+			)
+
+			Expect(libtrc.WriteSocket(libtrc.WithContext(context.Background(), src), buf)).To(Succeed())
+			buf.WriteString("payload\n")
+
+			ctx, r, e := libtrc.ReadSocket(context.Background(), buf)
+			Expect(e).ToNot(HaveOccurred())
+
+			t, ok := libtrc.FromContext(ctx)
+			Expect(ok).To(BeTrue())
+			Expect(t.RequestID).To(Equal(src.RequestID))
+			Expect(t.TraceID).To(Equal(src.TraceID))
+			Expect(t.ParentID).To(Equal(src.SpanID))
+
+			p, e := io.ReadAll(r)
+			Expect(e).ToNot(HaveOccurred())
+			Expect(string(p)).To(Equal("payload\n"))
+		})
+
+		It("must keep the payload untouched without header line", func() {
+			ctx, r, e := libtrc.ReadSocket(context.Background(), bytes.NewBufferString("payload\n"))
+			Expect(e).ToNot(HaveOccurred())
+
+			t, ok := libtrc.FromContext(ctx)
+			Expect(ok).To(BeTrue())
+			Expect(t.IsValid()).To(BeTrue())
+
+			p, _ := io.ReadAll(r)
+			Expect(string(p)).To(Equal("payload\n"))
+		})
+	})
+})
@@ -0,0 +1,168 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package version
+
+import (
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+const (
+	buildSettingVCS      = "vcs"
+	buildSettingRevision = "vcs.revision"
+	buildSettingTime     = "vcs.time"
+	buildSettingModified = "vcs.modified"
+
+	develVersion = "(devel)"
+	shortBuild   = 7
+)
+
+var (
+	bldOnce sync.Once
+	bldInfo BuildInfo
+)
+
+// Module is a module embedded in the binary.
+type Module struct {
+	Path    string  `json:"path"`
+	Version string  `json:"version"`
+	Sum     string  `json:"sum,omitempty"`
+	Replace *Module `json:"replace,omitempty"`
+}
+
+// BuildInfo is the build information embedded by the go toolchain in the binary.
+type BuildInfo struct {
+	// GoVersion is the version of the go toolchain used to build the binary.
+	GoVersion string `json:"go_version"`
+	// Path is the package path of the main package.
+	Path string `json:"path"`
+	// Main is the main module.
+	Main Module `json:"main"`
+	// Deps are the dependency modules.
+	Deps []Module `json:"deps,omitempty"`
+	// VCS is the version control system of the sources (git, hg, ...).
+	VCS string `json:"vcs,omitempty"`
+	// Revision is the revision of the sources in the version control system.
+	Revision string `json:"revision,omitempty"`
+	// Time is the time of the revision.
+	Time time.Time `json:"time"`
+	// Modified is true if the sources have local changes not committed.
+	Modified bool `json:"modified"`
+	// Settings are all the build settings (flags, env, vcs, ...).
+	Settings map[string]string `json:"settings,omitempty"`
+}
+
+// ReadBuildInfo return the build information embedded in the running binary.
+// The result is empty if the binary is built without module support.
+func ReadBuildInfo() BuildInfo {
+	bldOnce.Do(func() {
+		if i, ok := debug.ReadBuildInfo(); ok && i != nil {
+			bldInfo = newBuildInfo(i)
+		}
+	})
+
+	return bldInfo
+}
+
+func newModule(m *debug.Module) Module {
+	var r = Module{
+		Path:    m.Path,
+		Version: m.Version,
+		Sum:     m.Sum,
+	}
+
+	if m.Replace != nil {
+		p := newModule(m.Replace)
+		r.Replace = &p
+	}
+
+	return r
+}
+
+func newBuildInfo(i *debug.BuildInfo) BuildInfo {
+	var r = BuildInfo{
+		GoVersion: i.GoVersion,
+		Path:      i.Path,
+		Main:      newModule(&i.Main),
+		Deps:      make([]Module, 0, len(i.Deps)),
+		Settings:  make(map[string]string, len(i.Settings)),
+	}
+
+	for _, d := range i.Deps {
+		if d != nil {
+			r.Deps = append(r.Deps, newModule(d))
+		}
+	}
+
+	for _, s := range i.Settings {
+		r.Settings[s.Key] = s.Value
+
+		switch s.Key {
+		case buildSettingVCS:
+			r.VCS = s.Value
+		case buildSettingRevision:
+			r.Revision = s.Value
+		case buildSettingModified:
+			r.Modified = s.Value == "true"
+		case buildSettingTime:
+			if t, e := time.Parse(time.RFC3339, s.Value); e == nil {
+				r.Time = t
+			}
+		}
+	}
+
+	return r
+}
+
+// GetBuild return the short revision of the sources, suffixed by "-dirty" for local changes.
+func (b BuildInfo) GetBuild() string {
+	if len(b.Revision) < 1 {
+		return ""
+	}
+
+	var r = b.Revision
+
+	if len(r) > shortBuild {
+		r = r[:shortBuild]
+	}
+
+	if b.Modified {
+		r += "-dirty"
+	}
+
+	return r
+}
+
+// GetRelease return the version of the main module, or an empty string for a development build.
+func (b BuildInfo) GetRelease() string {
+	if b.Main.Version == develVersion {
+		return ""
+	}
+
+	return b.Main.Version
+}
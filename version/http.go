@@ -0,0 +1,131 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Nicolas JUHEL
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ */
+
+package version
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"time"
+)
+
+type versionRuntime struct {
+	OS   string `json:"os"`
+	Arch string `json:"arch"`
+	Go   string `json:"go"`
+}
+
+type versionJson struct {
+	Package     string         `json:"package"`
+	Description string         `json:"description,omitempty"`
+	Release     string         `json:"release"`
+	Build       string         `json:"build"`
+	Date        time.Time      `json:"date"`
+	Author      string         `json:"author,omitempty"`
+	Source      string         `json:"source,omitempty"`
+	License     string         `json:"license,omitempty"`
+	Runtime     versionRuntime `json:"runtime"`
+	BuildInfo   versionBuild   `json:"build_info"`
+}
+
+// versionBuild is the public part of the build info: the build settings (ldflags, cgo flags, paths)
+// are never exposed, and the dependency modules only on demand.
+type versionBuild struct {
+	GoVersion string    `json:"go_version"`
+	Path      string    `json:"path"`
+	Main      Module    `json:"main"`
+	Deps      []Module  `json:"deps,omitempty"`
+	VCS       string    `json:"vcs,omitempty"`
+	Revision  string    `json:"revision,omitempty"`
+	Time      time.Time `json:"time"`
+	Modified  bool      `json:"modified"`
+}
+
+func (v versionModel) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.getJson(false))
+}
+
+func (v versionModel) getJson(withDeps bool) versionJson {
+	var b = v.GetBuildInfo()
+
+	r := versionJson{
+		Package:     v.versionPackage,
+		Description: v.versionDescription,
+		Release:     v.versionRelease,
+		Build:       v.versionBuild,
+		Date:        v.versionTime,
+		Author:      v.versionAuthor,
+		Source:      v.versionSource,
+		License:     v.GetLicenseName(),
+		Runtime: versionRuntime{
+			OS:   runtime.GOOS,
+			Arch: runtime.GOARCH,
+			Go:   runtime.Version()[2:],
+		},
+		BuildInfo: versionBuild{
+			GoVersion: b.GoVersion,
+			Path:      b.Path,
+			Main:      b.Main,
+			VCS:       b.VCS,
+			Revision:  b.Revision,
+			Time:      b.Time,
+			Modified:  b.Modified,
+		},
+	}
+
+	if withDeps {
+		r.BuildInfo.Deps = b.Deps
+	}
+
+	return r
+}
+
+// Handler return an http handler serving the version and the build info encoded in JSON.
+// The dependency modules are listed only if withDeps is true.
+func (v versionModel) Handler(withDeps bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", http.MethodGet+", "+http.MethodHead)
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		p, e := json.Marshal(v.getJson(withDeps))
+
+		if e != nil {
+			http.Error(w, e.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(p)
+		}
+	})
+}
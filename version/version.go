@@ -26,7 +26,9 @@ package version
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"net/http"
 	"path/filepath"
 	"reflect"
 	"runtime"
@@ -50,6 +52,8 @@ type versionModel struct {
 }
 
 type Version interface {
+	json.Marshaler
+
 	CheckGo(RequireGoVersion, RequireGoContraint string) liberr.Error
 
 	GetAppId() string
@@ -65,6 +69,15 @@ type Version interface {
 	GetPrefix() string
 	GetRelease() string
 
+	// GetBuildInfo return the build information embedded by the go toolchain in the binary.
+	GetBuildInfo() BuildInfo
+	// GetInfoMap return the runtime, release, build and date, with the vcs revision if known, as used by the monitors info.
+	GetInfoMap() map[string]interface{}
+	// Handler return an http handler serving the version and the build info encoded in JSON, as the
+	// JSON encoding of the version. The build settings (ldflags, cgo flags, paths) are never exposed,
+	// only the vcs revision, time and modified flag. The dependency modules are listed only if withDeps is true.
+	Handler(withDeps bool) http.Handler
+
 	GetLicenseName() string
 	GetLicenseLegal(addMoreLicence ...license) string
 	GetLicenseFull(addMoreLicence ...license) string
@@ -88,11 +101,23 @@ func NewVersion(License license, Package, Description, Date, Build, Release, Aut
 		Package = filepath.Base(Source)
 	}
 
+	bld := ReadBuildInfo()
+
+	if Build == "" {
+		Build = bld.GetBuild()
+	}
+
+	if Release == "" {
+		Release = bld.GetRelease()
+	}
+
 	var timeBuild time.Time
-	if ts, err := time.Parse(time.RFC3339, Date); err != nil {
-		timeBuild = time.Now()
-	} else {
+	if ts, err := time.Parse(time.RFC3339, Date); err == nil {
 		timeBuild = ts
+	} else if !bld.Time.IsZero() {
+		timeBuild = bld.Time
+	} else {
+		timeBuild = time.Now()
 	}
 
 	return &versionModel{
@@ -188,6 +213,29 @@ func (v versionModel) GetRelease() string {
 	return v.versionRelease
 }
 
+func (v versionModel) GetBuildInfo() BuildInfo {
+	return ReadBuildInfo()
+}
+
+func (v versionModel) GetInfoMap() map[string]interface{} {
+	var (
+		bld = v.GetBuildInfo()
+		res = map[string]interface{}{
+			"runtime": runtime.Version()[2:],
+			"release": v.versionRelease,
+			"build":   v.versionBuild,
+			"date":    v.GetDate(),
+		}
+	)
+
+	if len(bld.Revision) > 0 {
+		res["revision"] = bld.Revision
+		res["modified"] = bld.Modified
+	}
+
+	return res
+}
+
 func (v versionModel) GetLicenseName() string {
 	return v.licenceType.GetLicenseName()
 }